@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github-issue-ai-bot/internal/config"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+const commandUsage = `Usage:
+  server                    start the bot
+  server metrics-catalog    print the metric catalog and recommended alerting rules
+  server config doctor      print the effective configuration and check it
+`
+
+// runCommand runs a command given on the command line and returns the exit
+// code. Only config doctor reads the configuration.
+func runCommand(args []string) int {
+	switch {
+	case args[0] == "metrics-catalog":
+		return metricsCatalog(args[1:], os.Stdout, os.Stderr)
+	case args[0] == "config" && len(args) > 1 && args[1] == "doctor":
+		return configDoctor(args[2:], os.Stdout, os.Stderr)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], commandUsage)
+		return 2
+	}
+}
+
+// metricsCatalog prints the metric catalog and alerting rules as JSON, or
+// only the rules as a Prometheus rule file with -rules
+func metricsCatalog(args []string, stdout, stderr io.Writer) int {
+	options := monitor.DefaultAlertOptions()
+	flags := flag.NewFlagSet("metrics-catalog", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	rulesOnly := flags.Bool("rules", false, "print only the alerting rules, as a Prometheus rule file")
+	flags.Float64Var(&options.ErrorRate, "error-rate", options.ErrorRate, "share of failed issues, webhooks or OpenAI requests that alerts")
+	flags.DurationVar(&options.LatencyTarget, "latency-target", options.LatencyTarget, "webhook-to-Slack latency the 95th percentile should stay under")
+	flags.IntVar(&options.QueueSize, "queue-size", options.QueueSize, "WEBHOOK_QUEUE_SIZE of the deployment")
+	flags.Float64Var(&options.CostSpikeFactor, "cost-spike", options.CostSpikeFactor, "hourly OpenAI cost, as a multiple of the previous day's hourly average, that alerts")
+	flags.Float64Var(&options.MinHourlyCost, "min-hourly-cost", options.MinHourlyCost, "hourly OpenAI cost in US dollars below which spikes are ignored")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	report := monitor.NewCatalogReport(options)
+	if *rulesOnly {
+		body, err := report.AlertRules.YAML()
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to render alerting rules: %v\n", err)
+			return 1
+		}
+		stdout.Write(body)
+		return 0
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(stderr, "failed to encode metric catalog: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// configDoctor prints every environment setting with its effective value,
+// secrets redacted, and where the value came from, then the deprecated
+// settings in use and every problem that would stop the bot from starting.
+// It exits with 1 when there are problems.
+func configDoctor(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("config doctor", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	changed := flags.Bool("changed", false, "print only the settings that are not at their defaults")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "SETTING\tVALUE\tSOURCE")
+	for _, value := range cfg.Settings() {
+		if value.Source == config.SourceDefault && (*changed || value.Deprecated != "") {
+			continue
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", value.Name, value.Display(), value.Source)
+	}
+	table.Flush()
+
+	file := cfg.File()
+	if file == "" {
+		file = "none"
+	}
+	fmt.Fprintf(stdout, "\nConfig file: %s\n", file)
+
+	if warnings := cfg.Warnings(); len(warnings) > 0 {
+		fmt.Fprintln(stdout, "\nWarnings:")
+		for _, warning := range warnings {
+			fmt.Fprintf(stdout, "  - %s\n", warning)
+		}
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		fmt.Fprintln(stdout, "\nThe configuration is valid.")
+		return 0
+	}
+	fmt.Fprintln(stdout, "\nProblems:")
+	problems, ok := err.(config.SettingErrors)
+	if !ok {
+		problems = config.SettingErrors{err.Error()}
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(stdout, "  - %s\n", problem)
+	}
+	return 1
+}
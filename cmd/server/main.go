@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -15,11 +16,39 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github-issue-ai-bot/internal/admin"
 	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/breaker"
+	"github-issue-ai-bot/internal/broker"
+	"github-issue-ai-bot/internal/canary"
+	"github-issue-ai-bot/internal/capture"
 	"github-issue-ai-bot/internal/config"
+	"github-issue-ai-bot/internal/dashboard"
+	"github-issue-ai-bot/internal/diagnostics"
+	"github-issue-ai-bot/internal/drift"
+	"github-issue-ai-bot/internal/export"
 	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/history"
+	"github-issue-ai-bot/internal/identity"
+	"github-issue-ai-bot/internal/inspect"
+	"github-issue-ai-bot/internal/leaderboard"
 	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/onboard"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/ratelimit"
+	"github-issue-ai-bot/internal/resolution"
+	"github-issue-ai-bot/internal/resources"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/shadow"
 	"github-issue-ai-bot/internal/slack"
+	"github-issue-ai-bot/internal/store"
+	"github-issue-ai-bot/internal/styles"
+	"github-issue-ai-bot/internal/summarylog"
+	"github-issue-ai-bot/internal/support"
+	"github-issue-ai-bot/internal/teams"
+	"github-issue-ai-bot/internal/tracker"
+	"github-issue-ai-bot/internal/usage"
 )
 
 // Version, BuildDate, and GitCommit will be set during build
@@ -86,6 +115,11 @@ func printBanner() {
 
 
 func main() {
+	// Commands print to stdout and exit without starting the server
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// Print banner
 	printBanner()
 
@@ -105,13 +139,21 @@ func main() {
 		logger.Fatal("Failed to load configuration", zap.Error(err))
 	}
 
+	for _, warning := range cfg.Warnings() {
+		logger.Warn("Deprecated setting", zap.String("warning", warning))
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		logger.Fatal("Invalid configuration", zap.Error(err))
 	}
 
-	// Initialize metrics
-	metrics := monitor.NewMetrics()
+	// Initialize metrics. With tenants, every metric carries a tenant label.
+	metrics := monitor.NewMetricsWithOptions(cfg.Monitor.Metrics)
+	if len(cfg.Tenants) > 0 {
+		metrics = monitor.NewTenantMetrics(config.DefaultTenant, cfg.Monitor.Metrics)
+	}
+	metrics.SetSLOTargets(cfg.Monitor.SLOAvailabilityTarget, cfg.Monitor.SLOLatencyTarget)
 
 	// Initialize GitHub handler
 	githubHandler := github.NewHandler(
@@ -120,6 +162,34 @@ func main() {
 		logger,
 		metrics,
 	)
+	githubHandler.EnableCloseSuggestions(cfg.GitHub.CloseSuggestions)
+	githubHandler.SetCommentLimit(cfg.GitHub.CommentLimit)
+	githubHandler.SetAttachments(cfg.GitHub.Attachments)
+	githubHandler.SetRegressionHints(cfg.GitHub.Regressions)
+	githubHandler.SetCIFailures(cfg.GitHub.CIFailures)
+	githubHandler.SetStackDetection(cfg.GitHub.Stack)
+	githubHandler.SetCommands(cfg.GitHub.Commands)
+	githubHandler.SetChecks(cfg.GitHub.Checks)
+	githubHandler.SetEnrichTimeout(cfg.Timeouts.Enrich)
+	githubHandler.SetEnrichment(cfg.GitHub.Enrichment)
+
+	actionMatrix, err := github.NewActionMatrix(cfg.GitHub.ActionRules)
+	if err != nil {
+		logger.Fatal("Invalid event rules", zap.Error(err))
+	}
+	githubHandler.SetActionMatrix(actionMatrix)
+
+	commentFormatter, err := github.NewCommentFormatter(cfg.GitHub.CommentTemplates)
+	if err != nil {
+		logger.Fatal("Invalid comment templates", zap.Error(err))
+	}
+	githubHandler.SetCommentFormatter(commentFormatter)
+
+	workQueue, err := github.NewWorkQueue(cfg.GitHub.Queue)
+	if err != nil {
+		logger.Fatal("Invalid webhook queue configuration", zap.Error(err))
+	}
+	githubHandler.SetWorkQueue(workQueue)
 
 	// Initialize AI summarizer with prompt style
 	var summarizer *ai.Summarizer
@@ -148,6 +218,51 @@ func main() {
 		logger.Info("Using default prompt style")
 	}
 
+	if err := summarizer.SetClientOptions(cfg.OpenAI.Client); err != nil {
+		logger.Fatal("Invalid OpenAI client configuration", zap.Error(err))
+	}
+
+	// Size prompts to the model's context window
+	models, err := ai.NewModelRegistry(cfg.OpenAI.Models)
+	if err != nil {
+		logger.Fatal("Invalid OpenAI models", zap.Error(err))
+	}
+	summarizer.SetModelRegistry(models)
+	if info, ok := models.Lookup(cfg.OpenAI.Model); ok {
+		logger.Info("Using OpenAI model",
+			zap.String("model", cfg.OpenAI.Model),
+			zap.Int("context_window", info.ContextWindow),
+			zap.Bool("json_mode", info.JSONMode),
+		)
+	} else {
+		logger.Warn("Unknown OpenAI model, prompts are not sized to its context window; describe it under openai.models",
+			zap.String("model", cfg.OpenAI.Model))
+	}
+
+	// Priorities and categories, with their emojis and GitHub labels
+	taxonomy, err := ai.NewTaxonomy(cfg.Pipeline.Taxonomy)
+	if err != nil {
+		logger.Fatal("Invalid taxonomy", zap.Error(err))
+	}
+	summarizer.SetTaxonomy(taxonomy)
+
+	// Load custom Slack message template
+	if cfg.Slack.MessageTemplate != "" {
+		slackTemplate, err := ai.LoadSlackTemplate(cfg.Slack.MessageTemplate)
+		if err != nil {
+			logger.Fatal("Failed to load Slack message template", zap.Error(err))
+		}
+		summarizer.SetSlackTemplate(slackTemplate)
+		logger.Info("Using custom Slack message template", zap.String("path", cfg.Slack.MessageTemplate))
+	}
+	if cfg.Slack.FallbackTemplate != "" {
+		fallbackTemplate, err := ai.ParseFallbackTemplate(cfg.Slack.FallbackTemplate)
+		if err != nil {
+			logger.Fatal("Invalid Slack fallback template", zap.Error(err))
+		}
+		summarizer.SetFallbackTemplate(fallbackTemplate)
+	}
+
 	// Initialize Slack notifier
 	slackNotifier := slack.NewNotifier(
 		cfg.Slack.BotToken,
@@ -159,6 +274,54 @@ func main() {
 		githubHandler,
 	)
 
+	slackNotifier.SetTimeouts(cfg.Timeouts.AI, cfg.Timeouts.Slack)
+
+	// In shadow mode the pipeline runs in full, but what it would post to
+	// Slack or change on GitHub is only kept in the store for review. It is
+	// set before the breakers and usage counting wrap the API clients.
+	issueStore := store.NewMemoryStore()
+	if cfg.Server.Shadow {
+		shadowRecorder := shadow.NewRecorder(issueStore, logger)
+		githubHandler.SetShadow(shadowRecorder)
+		slackNotifier.SetShadow(shadowRecorder)
+		logger.Warn("Shadow mode: nothing is posted to Slack or changed on GitHub")
+	}
+
+	// Background processing outlives the webhook request, so it runs under a
+	// context that is cancelled on shutdown instead
+	processCtx, stopProcessing := context.WithCancel(context.Background())
+	defer stopProcessing()
+	githubHandler.SetBaseContext(processCtx)
+	slackNotifier.SetBaseContext(processCtx)
+
+	// Accept webhooks only from GitHub's published addresses, if enabled
+	var webhookSources *github.SourceAllowlist
+	if cfg.GitHub.Sources.Enabled {
+		webhookSources, err = github.NewSourceAllowlist(githubHandler, cfg.GitHub.Sources)
+		if err != nil {
+			logger.Fatal("Invalid webhook source allowlist", zap.Error(err))
+		}
+		githubHandler.SetSourceAllowlist(webhookSources)
+		go webhookSources.Run(processCtx)
+		logger.Info("Restricting webhook sources",
+			zap.Bool("github_meta", cfg.GitHub.Sources.Meta),
+			zap.Strings("allowed", cfg.GitHub.Sources.AllowedCIDRs),
+		)
+	}
+
+	// Initialize channel routing
+	issueRouter, err := routing.NewRouter(cfg.Routing.Rules, cfg.Slack.ChannelID, cfg.Routing.DefaultLayout)
+	if err != nil {
+		logger.Fatal("Invalid routing configuration", zap.Error(err))
+	}
+	logger.Info("Loaded routing rules",
+		zap.Int("rules", len(cfg.Routing.Rules)),
+		zap.String("default_layout", cfg.Routing.DefaultLayout),
+	)
+
+	// Tenants sharing the deployment, by name
+	tenants := make(map[string]*tenant, len(cfg.Tenants))
+
 	// Set up Gin router
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
@@ -171,6 +334,25 @@ func main() {
 		})).ServeHTTP(c.Writer, c.Request)
 	})
 
+	// Rate limit the webhook and API endpoints. The proxies trusted for the
+	// webhook source allowlist are trusted for client addresses here too.
+	if cfg.Limits.Enabled {
+		proxies, _ := github.ParseCIDRs(cfg.GitHub.Sources.TrustedProxies)
+		limiter := ratelimit.New(cfg.Limits, func(r *http.Request) net.IP {
+			return github.ClientIP(r, proxies)
+		}, metrics, logger)
+		router.Use(func(c *gin.Context) {
+			if !limiter.Check(c.Writer, c.Request) {
+				c.Abort()
+			}
+		})
+		logger.Info("Rate limiting webhook and API endpoints",
+			zap.Float64("webhook_rate", cfg.Limits.Webhook.Rate),
+			zap.Float64("api_rate", cfg.Limits.API.Rate),
+			zap.Float64("global_rate", cfg.Limits.Global.Rate),
+		)
+	}
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -182,11 +364,43 @@ func main() {
 	// Metrics endpoint
 	router.GET(cfg.Monitor.MetricsPath, gin.WrapH(metrics.Handler()))
 
+	// SLO summary endpoint
+	router.GET("/api/slo", func(c *gin.Context) {
+		c.JSON(http.StatusOK, metrics.SLOSummary())
+	})
+
+	// Metric catalog and recommended alerting rules for the bot itself
+	alertOptions := monitor.DefaultAlertOptions()
+	alertOptions.LatencyTarget = cfg.Monitor.SLOLatencyTarget
+	alertOptions.QueueSize = cfg.GitHub.Queue.Size
+	router.GET("/api/metrics-catalog", gin.WrapF(monitor.CatalogHandler(alertOptions)))
+
+	// Confidence calibration endpoint, comparing summary confidence with later human overrides
+	router.GET("/api/calibration", func(c *gin.Context) {
+		c.JSON(http.StatusOK, metrics.CalibrationReport())
+	})
+
+	// Runtime prompt style changes are tried on a share of issues first, if
+	// enabled, and rolled back if they parse or classify worse
+	var promptCanary *canary.Manager
+	if cfg.OpenAI.Canary.Enabled() {
+		promptCanary = canary.NewManager(cfg.OpenAI.Canary, cfg.OpenAI.PromptStyle, func(name string, style ai.PromptStyle) {
+			summarizer.SetPromptStyle(style)
+			for _, t := range tenants {
+				t.summarizer.SetPromptStyle(style)
+			}
+		}, slackNotifier, metrics, logger)
+		promptCanary.SetBaseContext(processCtx)
+		summarizer.SetStyleSelector(promptCanary)
+		router.GET("/api/prompt-canary", gin.WrapF(promptCanary.ServeStatus))
+	}
+
 	// Prompt styles endpoint
 	router.GET("/api/prompt-styles", func(c *gin.Context) {
 		styles := ai.ListPromptStyles()
 		c.JSON(http.StatusOK, gin.H{
 			"available_styles": styles,
+			"custom_styles":    ai.CustomPromptStyles(),
 			"current_style":    cfg.OpenAI.PromptStyle,
 		})
 	})
@@ -202,8 +416,21 @@ func main() {
 			return
 		}
 
-		if promptStyle, exists := ai.GetPromptStyle(request.Style); exists {
+		if promptStyle, exists := ai.GetPromptStyle(request.Style); exists && promptCanary != nil {
+			if err := promptCanary.Start(request.Style, promptStyle); err != nil {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusAccepted, gin.H{
+				"message": "Prompt style canary started",
+				"style":   request.Style,
+				"percent": cfg.OpenAI.Canary.Percent,
+			})
+		} else if exists {
 			summarizer.SetPromptStyle(promptStyle)
+			for _, t := range tenants {
+				t.summarizer.SetPromptStyle(promptStyle)
+			}
 			logger.Info("Changed prompt style", zap.String("style", request.Style))
 			c.JSON(http.StatusOK, gin.H{
 				"message": "Prompt style changed successfully",
@@ -227,12 +454,613 @@ func main() {
 		slackNotifier.HandleInteractiveMessage(c.Writer, c.Request)
 	})
 
+	// Slack Events API endpoint, which runs the Workflow Builder steps
+	if cfg.Slack.WorkflowSteps {
+		router.POST("/webhook/slack/events", func(c *gin.Context) {
+			slackNotifier.HandleEvent(c.Writer, c.Request)
+		})
+	}
+
+	// The admin token, shared by the admin endpoints so a rotated secret
+	// reaches all of them
+	adminToken := admin.NewToken(cfg.Server.AdminToken)
+
+	// What shadow mode held back
+	if cfg.Server.Shadow {
+		router.GET("/api/shadow/actions", gin.WrapF(shadow.NewHandler(issueStore, adminToken).ServePreview))
+	}
+
+	// Summary export endpoints, for pulling summaries into a data warehouse
+	var exporter *export.Handler
+	if cfg.Server.AdminToken != "" {
+		exporter = export.NewHandler(issueStore, adminToken, cfg.Server.ExportSigningKey, cfg.Server.PublicURL, logger)
+		router.GET("/api/export", gin.WrapF(exporter.ServeExport))
+		router.POST("/api/export/sign", gin.WrapF(exporter.ServeSign))
+		router.GET("/api/issues", gin.WrapF(exporter.ServeSearch))
+
+		// Custom prompt style endpoints
+		promptStyles := styles.NewHandler(issueStore, adminToken, logger)
+		promptStyles.SetModels(models, cfg.OpenAI.Model, cfg.OpenAI.MaxTokens)
+		router.POST("/api/prompt-styles", gin.WrapF(promptStyles.ServeCreate))
+		router.PUT("/api/prompt-styles/:name", gin.WrapF(promptStyles.ServeUpdate))
+		router.DELETE("/api/prompt-styles/:name", gin.WrapF(promptStyles.ServeDelete))
+
+		// Historical import, so analytics have context from the first day.
+		// Imported issues are stored without notifying Slack.
+		importHandler := history.NewHandler(history.NewImporter(issueStore, githubHandler, logger), adminToken, logger)
+		importHandler.SetBaseContext(processCtx)
+		router.POST("/api/import", gin.WrapF(importHandler.ServeStart))
+		router.GET("/api/import", gin.WrapF(importHandler.ServeStatus))
+		router.POST("/api/import/archive", gin.WrapF(importHandler.ServeArchive))
+	}
+
+	// Per-team rollups of the repositories each team owns
+	teamDirectory, err := teams.NewDirectory(cfg.Teams.Teams)
+	if err != nil {
+		logger.Fatal("Invalid teams", zap.Error(err))
+	}
+	if len(cfg.Teams.Teams) > 0 {
+		router.GET("/api/teams/:team/stats", gin.WrapF(teams.NewHandler(teamDirectory, issueStore).ServeStats))
+	}
+
+	// Drift of the priorities and categories the AI assigns to each repository
+	driftDetector := drift.NewDetector(issueStore, slackNotifier, metrics, cfg.Monitor.Drift, logger)
+	router.GET("/api/drift", func(c *gin.Context) {
+		c.JSON(http.StatusOK, driftDetector.Report())
+	})
+
+	// Resolution times and misclassifications by the priority the AI assigned
+	resolutionReporter := resolution.NewReporter(issueStore, githubHandler, slackNotifier, cfg.Monitor.Resolution, logger)
+	router.GET("/api/reports/resolution", gin.WrapF(resolutionReporter.ServeReport))
+
+	// OpenAI, Slack and GitHub usage by repository and prompt style
+	usageTracker := usage.NewTracker()
+	githubHandler.SetAPIUsage(usageTracker)
+	usageReporter := usage.NewReporter(usageTracker, slackNotifier, cfg.Monitor.Usage, adminToken, logger)
+	if cfg.Server.AdminToken != "" {
+		router.GET("/api/usage", gin.WrapF(usageReporter.ServeReport))
+	}
+
 	// Create issue processor
-	issueProcessor := NewIssueProcessor(githubHandler, summarizer, slackNotifier, logger, metrics)
+	issueProcessor := pipeline.NewIssueProcessor(summarizer, slackNotifier, issueRouter, issueStore, logger, metrics)
+	if err := configurePipeline(cfg, issueProcessor, summarizer, slackNotifier, githubHandler, taxonomy, logger); err != nil {
+		logger.Fatal("Invalid pipeline configuration", zap.Error(err))
+	}
+	if err := addNotifiers(issueProcessor, cfg.Notifiers); err != nil {
+		logger.Fatal("Invalid notifier configuration", zap.Error(err))
+	}
+	if promptCanary != nil {
+		issueProcessor.SetPromptFeedback(promptCanary)
+	}
+	issueProcessor.SetUsageRecorder(usageTracker)
+	if len(cfg.Notifiers) > 0 {
+		logger.Info("Sending summaries to notifiers", zap.Strings("notifiers", issueProcessor.NotifierNames()))
+	}
+
+	// GitHub accounts of Slack users, for assignee mentions and to attribute
+	// button clicks to the right GitHub account
+	identities, err := identity.NewDirectory(cfg.Identity.Identities)
+	if err != nil {
+		logger.Fatal("Invalid identities", zap.Error(err))
+	}
+	if cfg.Identity.EmailMatching {
+		identities.SetEmailMatching(githubHandler, slackNotifier, logger)
+	}
+	issueProcessor.SetIdentities(identities)
+	slackNotifier.SetIdentities(identities)
+	if len(cfg.Identity.Identities) > 0 || cfg.Identity.EmailMatching || cfg.Server.AdminToken != "" {
+		summarizer.SetAssignButton(true)
+	}
+
+	// Who acknowledged, assigned and closed the most issues, and fastest
+	leaderboardReporter := leaderboard.NewReporter(issueStore, identities, slackNotifier, cfg.Monitor.Leaderboard, logger)
+	router.GET("/api/leaderboard", gin.WrapF(leaderboardReporter.ServeLeaderboard))
+	if cfg.Server.AdminToken != "" {
+		identityHandler := identity.NewHandler(identities, issueStore, adminToken, logger)
+		router.GET("/api/identities", gin.WrapF(identityHandler.ServeList))
+		router.POST("/api/identities", gin.WrapF(identityHandler.ServeSave))
+		router.DELETE("/api/identities/:github", gin.WrapF(identityHandler.ServeDelete))
+	}
+
+	// Linear and Shortcut tickets for actionable issues, with their state
+	// changes reported back on GitHub and in Slack
+	var trackerSyncer *tracker.Syncer
+	if len(cfg.Trackers.Mappings) > 0 {
+		var trackers []tracker.Tracker
+		if cfg.Trackers.LinearAPIKey != "" {
+			trackers = append(trackers, tracker.NewLinear(cfg.Trackers.LinearAPIKey))
+		}
+		if cfg.Trackers.ShortcutAPIToken != "" {
+			trackers = append(trackers, tracker.NewShortcut(cfg.Trackers.ShortcutAPIToken))
+		}
+		trackerSyncer, err = tracker.NewSyncer(cfg.Trackers.Mappings, trackers, issueStore, githubHandler, slackNotifier, logger)
+		if err != nil {
+			logger.Fatal("Invalid tracker mappings", zap.Error(err))
+		}
+		issueProcessor.SetTicketLinker(trackerSyncer)
+		if cfg.Trackers.LinearWebhookSecret != "" {
+			trackerSyncer.SetWebhookSecret(tracker.TypeLinear, cfg.Trackers.LinearWebhookSecret)
+			router.POST("/webhook/linear", gin.WrapF(trackerSyncer.ServeLinear))
+		}
+		if cfg.Trackers.ShortcutWebhookSecret != "" {
+			trackerSyncer.SetWebhookSecret(tracker.TypeShortcut, cfg.Trackers.ShortcutWebhookSecret)
+			router.POST("/webhook/shortcut", gin.WrapF(trackerSyncer.ServeShortcut))
+		}
+		logger.Info("Linking actionable issues to tracker tickets", zap.Int("mappings", len(cfg.Trackers.Mappings)))
+	}
+
+	// Support tickets from Zendesk and Intercom, optionally filed as GitHub issues
+	var supportHandler *support.Handler
+	if cfg.Support.Enabled() {
+		supportHandler = support.NewHandler(issueProcessor, githubHandler, cfg.Support, logger, metrics)
+		supportHandler.SetBaseContext(processCtx)
+		if cfg.Support.ZendeskSecret != "" {
+			router.POST("/webhook/zendesk", gin.WrapF(supportHandler.ServeZendesk))
+		}
+		if cfg.Support.IntercomSecret != "" {
+			router.POST("/webhook/intercom", gin.WrapF(supportHandler.ServeIntercom))
+		}
+		logger.Info("Accepting support tickets", zap.String("file_repository", cfg.Support.FileRepository))
+	}
+
+	// Admin dashboard with recent events, error rates, cost and settings
+	if cfg.Server.AdminToken != "" {
+		eventLog := dashboard.NewEventLog(dashboard.DefaultEventCapacity)
+		issueProcessor.SetEventRecorder(eventLog)
+		dash := dashboard.NewHandler(eventLog, metrics, cfg.Public(), adminToken)
+		dash.SetActionResolver(actionMatrix)
+		dash.SetRouter(issueRouter)
+		router.GET("/dashboard/*filepath", gin.WrapH(dash.Static("/dashboard/")))
+		router.GET("/api/dashboard/overview", gin.WrapF(dash.ServeOverview))
+		router.GET("/api/dashboard/events", gin.WrapF(dash.ServeEvents))
+		router.GET("/api/dashboard/settings", gin.WrapF(dash.ServeSettings))
+	}
+
+	// Fail fast while GitHub, OpenAI or Slack is down instead of waiting
+	// for timeouts, and probe for recovery after a cooldown
+	var breakers breaker.Set
+	if cfg.Breaker.Enabled() {
+		for _, name := range []string{breaker.GitHub, breaker.OpenAI, breaker.Slack} {
+			breakers = append(breakers, breaker.New(name, cfg.Breaker, metrics, logger))
+		}
+		setBreakers(breakers, githubHandler, summarizer, slackNotifier)
+	}
+
+	// Live queue, in-flight issue and error state, for debugging why nothing
+	// reaches Slack without reading logs
+	var inspector *inspect.Handler
+	if cfg.Server.AdminToken != "" {
+		inspector = inspect.NewHandler(adminToken)
+		inspector.AddPipeline("default", githubHandler, issueProcessor)
+		inspector.AddBreakers(breakers)
+		inspector.Publish("pipeline")
+		router.GET("/debug/pipeline", gin.WrapF(inspector.ServePipeline))
+		router.GET("/debug/vars", gin.WrapF(inspector.ServeVars))
+	}
+
+	// Keep the last deliveries, sanitized, to reproduce what the bot posted
+	// by replaying them
+	var captures *capture.Store
+	var captureHandler *capture.Handler
+	if cfg.Server.Capture {
+		defaultTenant := ""
+		if len(cfg.Tenants) > 0 {
+			defaultTenant = config.DefaultTenant
+		}
+		captures = capture.NewStore(cfg.Server.CaptureSize)
+		captureHandler = capture.NewHandler(captures, adminToken, logger)
+		githubHandler.SetCapture(captures.Tenant(defaultTenant))
+		captureHandler.AddPipeline(defaultTenant, githubHandler, issueProcessor)
+		router.GET("/api/captures", gin.WrapF(captureHandler.ServeCaptures))
+		router.GET("/api/captures/:id", gin.WrapF(captureHandler.ServeCapture))
+		router.POST("/api/captures/:id/replay", gin.WrapF(captureHandler.ServeReplay))
+		logger.Info("Capturing webhook deliveries", zap.Int("size", cfg.Server.CaptureSize))
+	}
 
 	// Set up the issue processing callback
 	githubHandler.SetIssueProcessor(issueProcessor)
 
+	// Serve each tenant at its own webhook paths, with its own credentials
+	for _, tc := range cfg.Tenants {
+		t, err := newTenant(processCtx, cfg, tc, summarizer, actionMatrix, taxonomy, logger)
+		if err != nil {
+			logger.Fatal("Invalid tenant configuration", zap.String("tenant", tc.Name), zap.Error(err))
+		}
+		setBreakers(breakers, t.github, t.summarizer, t.slack)
+		t.github.SetSourceAllowlist(webhookSources)
+		t.github.SetCommentFormatter(commentFormatter)
+		tenantUsage := usageTracker.Tenant(tc.Name)
+		t.github.SetAPIUsage(tenantUsage)
+		t.processor.SetUsageRecorder(tenantUsage)
+		t.processor.SetIdentities(identities)
+		t.slack.SetIdentities(identities)
+		if promptCanary != nil {
+			t.summarizer.SetStyleSelector(promptCanary)
+			t.processor.SetPromptFeedback(promptCanary)
+		}
+		if inspector != nil {
+			inspector.AddPipeline(tc.Name, t.github, t.processor)
+		}
+		if captures != nil {
+			t.github.SetCapture(captures.Tenant(tc.Name))
+			captureHandler.AddPipeline(tc.Name, t.github, t.processor)
+		}
+		tenants[tc.Name] = t
+	}
+	if len(tenants) > 0 {
+		router.POST("/webhook/github/:tenant", func(c *gin.Context) {
+			t, ok := tenants[c.Param("tenant")]
+			if !ok {
+				c.String(http.StatusNotFound, "unknown tenant")
+				return
+			}
+			t.github.HandleWebhook(c.Writer, c.Request)
+		})
+		router.POST("/webhook/slack/:tenant", func(c *gin.Context) {
+			t, ok := tenants[c.Param("tenant")]
+			if !ok {
+				c.String(http.StatusNotFound, "unknown tenant")
+				return
+			}
+			t.slack.HandleInteractiveMessage(c.Writer, c.Request)
+		})
+		if cfg.Slack.WorkflowSteps {
+			router.POST("/webhook/slack/:tenant/events", func(c *gin.Context) {
+				t, ok := tenants[c.Param("tenant")]
+				if !ok {
+					c.String(http.StatusNotFound, "unknown tenant")
+					return
+				}
+				t.slack.HandleEvent(c.Writer, c.Request)
+			})
+		}
+		logger.Info("Serving tenants", zap.Int("tenants", len(tenants)))
+	}
+
+	// Write a JSON record of every processed issue for SIEM and warehouse ingestion
+	if cfg.SummaryLog != "" {
+		summaryLog, err := summarylog.Open(cfg.SummaryLog, logger)
+		if err != nil {
+			logger.Fatal("Failed to open summary log", zap.Error(err))
+		}
+		defer summaryLog.Close()
+		if len(tenants) > 0 {
+			issueProcessor.SetSummaryLog(summaryLog.Tenant(config.DefaultTenant))
+		} else {
+			issueProcessor.SetSummaryLog(summaryLog)
+		}
+		for name, t := range tenants {
+			t.processor.SetSummaryLog(summaryLog.Tenant(name))
+		}
+		logger.Info("Writing summary records", zap.String("path", cfg.SummaryLog))
+	}
+
+	// Apply rotated credentials to the running components without a restart
+	applySecret := func(key, value string) {
+		switch key {
+		case "GITHUB_WEBHOOK_SECRET":
+			githubHandler.SetWebhookSecret(value)
+		case "GITHUB_ACCESS_TOKEN":
+			githubHandler.SetAccessToken(value)
+		case "OPENAI_API_KEY":
+			summarizer.SetAPIKey(value)
+			for _, t := range tenants {
+				if t.config.OpenAIAPIKey == "" {
+					t.summarizer.SetAPIKey(value)
+				}
+			}
+		case "SLACK_BOT_TOKEN":
+			slackNotifier.SetBotToken(value)
+		case "SLACK_SIGNING_SECRET":
+			slackNotifier.SetSigningSecret(value)
+		case "ADMIN_TOKEN":
+			adminToken.Set(value)
+		case "EXPORT_SIGNING_KEY":
+			if exporter == nil {
+				return
+			}
+			exporter.SetSigningKey(value)
+		case "LINEAR_WEBHOOK_SECRET", "SHORTCUT_WEBHOOK_SECRET":
+			if trackerSyncer == nil {
+				return
+			}
+			if key == "LINEAR_WEBHOOK_SECRET" {
+				trackerSyncer.SetWebhookSecret(tracker.TypeLinear, value)
+			} else {
+				trackerSyncer.SetWebhookSecret(tracker.TypeShortcut, value)
+			}
+		case "ZENDESK_WEBHOOK_SECRET", "INTERCOM_CLIENT_SECRET":
+			if supportHandler == nil {
+				return
+			}
+			if key == "ZENDESK_WEBHOOK_SECRET" {
+				supportHandler.SetSecret(support.SourceZendesk, value)
+			} else {
+				supportHandler.SetSecret(support.SourceIntercom, value)
+			}
+		default:
+			return
+		}
+		logger.Info("Reloaded rotated secret", zap.String("secret", key))
+	}
+
+	secretsCtx, stopSecrets := context.WithCancel(context.Background())
+	defer stopSecrets()
+
+	// Watch file-backed secrets
+	if len(cfg.Secrets.Files) > 0 && cfg.Secrets.ReloadInterval > 0 {
+		secretWatcher := config.NewSecretWatcher(cfg.Secrets.Files, cfg.Secrets.ReloadInterval, applySecret)
+		go secretWatcher.Run(secretsCtx)
+		logger.Info("Watching file-backed secrets",
+			zap.Int("count", len(cfg.Secrets.Files)),
+			zap.Duration("interval", cfg.Secrets.ReloadInterval),
+		)
+	}
+
+	// Refresh secrets from an external provider before their lease expires
+	if cfg.Secrets.ProviderValues != nil {
+		provider, err := config.NewSecretsProvider(cfg.Secrets)
+		if err != nil {
+			logger.Fatal("Failed to create secrets provider", zap.Error(err))
+		}
+		refresher := config.NewSecretsRefresher(
+			provider,
+			cfg.Secrets.ProviderValues,
+			cfg.Secrets.ProviderLease,
+			cfg.Secrets.ProviderRefresh,
+			applySecret,
+			func(err error) {
+				logger.Error("Failed to refresh secrets", zap.Error(err))
+			},
+		)
+		go refresher.Run(secretsCtx)
+		logger.Info("Loaded secrets from provider",
+			zap.String("provider", provider.Name()),
+			zap.Duration("lease", cfg.Secrets.ProviderLease),
+		)
+	}
+
+	// Poll posted issues for 👍 reactions, which GitHub sends no webhooks for.
+	// Receivers only forward webhooks and have no posted issues to poll.
+	if cfg.Pipeline.ReactionBoost.Threshold > 0 && cfg.Ingest.Mode != broker.ModeReceiver {
+		boostCtx, stopBoost := context.WithCancel(context.Background())
+		defer stopBoost()
+		go issueProcessor.RunReactionBoost(boostCtx)
+		for _, t := range tenants {
+			go t.processor.RunReactionBoost(boostCtx)
+		}
+		logger.Info("Boosting popular issues",
+			zap.Int("threshold", cfg.Pipeline.ReactionBoost.Threshold),
+			zap.Duration("interval", cfg.Pipeline.ReactionBoost.Interval),
+		)
+	}
+
+	// Re-analyze long-lived important issues, whose comments nobody re-reads
+	if cfg.Pipeline.Reanalysis.Every > 0 && cfg.Ingest.Mode != broker.ModeReceiver {
+		reanalysisCtx, stopReanalysis := context.WithCancel(context.Background())
+		defer stopReanalysis()
+		go issueProcessor.RunReanalysis(reanalysisCtx)
+		for _, t := range tenants {
+			go t.processor.RunReanalysis(reanalysisCtx)
+		}
+		logger.Info("Re-analyzing open issues",
+			zap.Duration("every", cfg.Pipeline.Reanalysis.Every),
+			zap.String("priority", cfg.Pipeline.Reanalysis.Priority),
+		)
+	}
+
+	// Escalate important issues nobody engaged with in Slack
+	if cfg.Pipeline.Engagement.Enabled && cfg.Ingest.Mode != broker.ModeReceiver {
+		engagementCtx, stopEngagement := context.WithCancel(context.Background())
+		defer stopEngagement()
+		go issueProcessor.RunEngagement(engagementCtx)
+		for _, t := range tenants {
+			go t.processor.RunEngagement(engagementCtx)
+		}
+		logger.Info("Escalating issues without engagement",
+			zap.Duration("window", cfg.Pipeline.Engagement.Window),
+			zap.String("priority", cfg.Pipeline.Engagement.Priority),
+		)
+	}
+
+	// Post each team's weekly digest to its channel
+	if len(cfg.Teams.Teams) > 0 && cfg.Teams.Digest.Weekday != "" && cfg.Ingest.Mode != broker.ModeReceiver {
+		issueProcessor.SetTriage(githubHandler)
+		slackNotifier.SetTriage(issueProcessor)
+		reporter := teams.NewReporter(teamDirectory, issueStore, slackNotifier, cfg.Teams.Digest, logger)
+		go reporter.Run(processCtx)
+		logger.Info("Posting weekly team digests",
+			zap.Int("teams", len(cfg.Teams.Teams)),
+			zap.String("weekday", cfg.Teams.Digest.Weekday),
+			zap.Int("hour", cfg.Teams.Digest.Hour),
+			zap.String("timezone", cfg.Teams.Digest.Timezone),
+		)
+	}
+
+	// Alert when the AI's assignments shift abruptly, e.g. after a model update
+	if cfg.Monitor.Drift.Window > 0 && cfg.Ingest.Mode != broker.ModeReceiver {
+		go driftDetector.Run(processCtx)
+		logger.Info("Watching for drift in AI assignments",
+			zap.Duration("window", cfg.Monitor.Drift.Window),
+			zap.Float64("threshold", cfg.Monitor.Drift.Threshold),
+			zap.String("channel", cfg.Monitor.Drift.Channel),
+		)
+	}
+
+	// Post last month's resolution report on the 1st of each month
+	if cfg.Monitor.Resolution.Channel != "" && cfg.Ingest.Mode != broker.ModeReceiver {
+		go resolutionReporter.Run(processCtx)
+		logger.Info("Posting monthly resolution reports",
+			zap.String("channel", cfg.Monitor.Resolution.Channel),
+			zap.Int("hour", cfg.Monitor.Resolution.Hour),
+			zap.String("timezone", cfg.Monitor.Resolution.Timezone),
+		)
+	}
+
+	// Celebrate last month's top triagers on the 1st of each month
+	if cfg.Monitor.Leaderboard.Channel != "" && cfg.Ingest.Mode != broker.ModeReceiver {
+		go leaderboardReporter.Run(processCtx)
+		logger.Info("Posting monthly triage leaderboards",
+			zap.String("channel", cfg.Monitor.Leaderboard.Channel),
+			zap.Int("hour", cfg.Monitor.Leaderboard.Hour),
+			zap.String("timezone", cfg.Monitor.Leaderboard.Timezone),
+		)
+	}
+
+	// Post the week's bot usage for budget owners
+	if cfg.Monitor.Usage.Channel != "" && cfg.Monitor.Usage.Weekday != "" && cfg.Ingest.Mode != broker.ModeReceiver {
+		go usageReporter.Run(processCtx)
+		logger.Info("Posting weekly usage reports",
+			zap.String("channel", cfg.Monitor.Usage.Channel),
+			zap.String("weekday", cfg.Monitor.Usage.Weekday),
+			zap.Int("hour", cfg.Monitor.Usage.Hour),
+			zap.String("timezone", cfg.Monitor.Usage.Timezone),
+		)
+	}
+
+	// Sample goroutines, memory and queued webhooks, and enrich fewer issues
+	// at once under memory pressure
+	if cfg.Monitor.Resources.Interval > 0 {
+		resourceMonitor := resources.NewMonitor(cfg.Monitor.Resources, metrics, logger)
+		githubHandler.SetEnrichLimiter(resourceMonitor.Limiter())
+		resourceMonitor.AddQueue(githubHandler)
+		for _, t := range tenants {
+			t.github.SetEnrichLimiter(resourceMonitor.Limiter())
+			resourceMonitor.AddQueue(t.github)
+		}
+		go resourceMonitor.Run(processCtx)
+		logger.Info("Monitoring resources",
+			zap.Duration("interval", cfg.Monitor.Resources.Interval),
+			zap.Uint64("memory_limit", resourceMonitor.MemoryLimit()),
+			zap.Int("enrich_concurrency", cfg.Monitor.Resources.MaxEnrichments),
+		)
+	}
+
+	// Read issues through the REST API for repositories without webhooks.
+	// Polled issues are processed here, so receivers do not poll.
+	if len(cfg.GitHub.Poll.Repositories) > 0 && cfg.Ingest.Mode != broker.ModeReceiver {
+		pollCtx, stopPolling := context.WithCancel(processCtx)
+		defer stopPolling()
+		go githubHandler.RunPolling(pollCtx, cfg.GitHub.Poll, issueStore)
+		logger.Info("Polling repositories",
+			zap.Strings("repositories", cfg.GitHub.Poll.Repositories),
+			zap.Duration("interval", cfg.GitHub.Poll.Interval),
+		)
+	}
+
+	// Decouple webhook receipt from processing through a message broker
+	switch cfg.Ingest.Mode {
+	case broker.ModeReceiver:
+		publisher, err := broker.NewPublisher(cfg.Ingest.Broker)
+		if err != nil {
+			logger.Fatal("Failed to create broker publisher", zap.Error(err))
+		}
+		defer publisher.Close()
+		githubHandler.SetPublisher(publisher)
+		logger.Info("Running in receiver mode", zap.String("broker", cfg.Ingest.Broker.Type))
+	case broker.ModeWorker:
+		consumer, err := broker.NewConsumer(cfg.Ingest.Broker, apperrors.IsRetryable, logger)
+		if err != nil {
+			logger.Fatal("Failed to create broker consumer", zap.Error(err))
+		}
+		defer consumer.Close()
+
+		consumeCtx, stopConsuming := context.WithCancel(context.Background())
+		defer stopConsuming()
+		go func() {
+			if err := consumer.Consume(consumeCtx, githubHandler.HandleEvent); err != nil {
+				logger.Fatal("Broker consumer stopped", zap.Error(err))
+			}
+		}()
+		logger.Info("Running in worker mode", zap.String("broker", cfg.Ingest.Broker.Type))
+	}
+
+	// Refuse to start with a token that lacks permissions the enabled
+	// features need, instead of failing on every issue. Shadow mode holds
+	// writes back, so only reads are checked there.
+	tokenNeeds := github.TokenNeeds{Write: !cfg.Server.Shadow, Checks: cfg.GitHub.Checks.Mode}
+	permissionRepository := cfg.PermissionRepository()
+	if cfg.GitHub.PermissionCheck && cfg.Ingest.Mode != broker.ModeReceiver {
+		if permissionRepository == "" {
+			logger.Warn("No repository to check the GitHub token's permissions on; set GITHUB_PERMISSION_CHECK_REPOSITORY")
+		} else {
+			checkCtx, cancelCheck := context.WithTimeout(processCtx, 30*time.Second)
+			missing, err := githubHandler.MissingPermissions(checkCtx, permissionRepository, tokenNeeds)
+			cancelCheck()
+			switch {
+			case err != nil:
+				logger.Warn("Could not check the GitHub token's permissions", zap.String("repository", permissionRepository), zap.Error(err))
+			case len(missing) > 0:
+				logger.Fatal("GitHub token is missing permissions",
+					zap.String("repository", permissionRepository),
+					zap.Strings("missing", missing),
+				)
+			default:
+				logger.Info("GitHub token has the permissions it needs", zap.String("repository", permissionRepository))
+			}
+		}
+	}
+
+	// Check credentials, scopes and channel membership up front, so
+	// misconfiguration is reported before the first message fails
+	checkers := []diagnostics.Checker{githubHandler.Diagnose}
+	if cfg.Ingest.Mode != broker.ModeReceiver {
+		checkers = append(checkers, githubHandler.DiagnosePermissions(permissionRepository, tokenNeeds))
+		slackScopes := slack.RequiredScopes(cfg.Pipeline.IncidentChannels)
+		if cfg.Identity.EmailMatching {
+			slackScopes = append(slackScopes, "users:read", "users:read.email")
+		}
+		if cfg.Slack.IssueShortcut.Enabled() {
+			slackScopes = append(slackScopes, "commands")
+		}
+		if cfg.Pipeline.Engagement.Enabled {
+			slackScopes = append(slackScopes, slack.EngagementScopes...)
+		}
+		if cfg.Slack.WorkflowSteps {
+			slackScopes = append(slackScopes, slack.WorkflowStepScopes...)
+		}
+		checkers = append(checkers, func(ctx context.Context) []diagnostics.Check {
+			return slackNotifier.Diagnose(ctx, issueRouter.Channels(), slackScopes)
+		})
+	}
+	for _, t := range tenants {
+		t := t
+		checkers = append(checkers, func(ctx context.Context) []diagnostics.Check {
+			return t.diagnose(ctx, cfg.Pipeline.IncidentChannels)
+		})
+	}
+	runDiagnostics := func(ctx context.Context) diagnostics.Report {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		return diagnostics.Run(ctx, checkers...)
+	}
+	go func() {
+		runDiagnostics(processCtx).Log(logger)
+	}()
+	if cfg.Server.AdminToken != "" {
+		router.GET("/api/diagnostics", func(c *gin.Context) {
+			if !admin.Require(c.Writer, c.Request, adminToken.Value()) {
+				return
+			}
+			report := runDiagnostics(c.Request.Context())
+			status := http.StatusOK
+			if !report.Healthy {
+				status = http.StatusServiceUnavailable
+			}
+			c.JSON(status, report)
+		})
+	}
+
+	// One-call readiness check for adding a repository
+	if cfg.Server.AdminToken != "" && cfg.Ingest.Mode != broker.ModeReceiver {
+		onboardConfig := onboard.Config{Needs: tokenNeeds}
+		if cfg.Server.PublicURL != "" {
+			onboardConfig.WebhookURL = strings.TrimSuffix(cfg.Server.PublicURL, "/") + "/webhook/github"
+		}
+		onboarder := onboard.NewOnboarder(githubHandler, issueProcessor, issueRouter, slackNotifier, onboardConfig)
+		router.POST("/api/onboard", gin.WrapF(onboard.NewHandler(onboarder, adminToken).ServeOnboard))
+	}
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -268,71 +1096,3 @@ func main() {
 
 	logger.Info("Server exited")
 }
-
-// IssueProcessor handles the processing of GitHub issues
-type IssueProcessor struct {
-	githubHandler *github.Handler
-	summarizer    *ai.Summarizer
-	slackNotifier *slack.Notifier
-	logger        *zap.Logger
-	metrics       *monitor.Metrics
-}
-
-// NewIssueProcessor creates a new issue processor
-func NewIssueProcessor(
-	githubHandler *github.Handler,
-	summarizer *ai.Summarizer,
-	slackNotifier *slack.Notifier,
-	logger *zap.Logger,
-	metrics *monitor.Metrics,
-) *IssueProcessor {
-	return &IssueProcessor{
-		githubHandler: githubHandler,
-		summarizer:    summarizer,
-		slackNotifier: slackNotifier,
-		logger:        logger,
-		metrics:       metrics,
-	}
-}
-
-// ProcessIssue processes a GitHub issue
-func (p *IssueProcessor) ProcessIssue(issueData *github.IssueData) {
-	start := time.Now()
-
-	p.logger.Info("Processing issue",
-		zap.String("repository", issueData.Repository.GetFullName()),
-		zap.Int("issue_number", issueData.Issue.GetNumber()),
-		zap.String("action", issueData.Action),
-	)
-
-	// Generate AI summary
-	summary, err := p.summarizer.SummarizeIssue(context.Background(), issueData)
-	if err != nil {
-		p.logger.Error("Failed to generate summary", zap.Error(err))
-		p.metrics.RecordIssueProcessed(issueData.Repository.GetFullName(), "issue", "error", time.Since(start))
-		return
-	}
-
-	// Generate Slack message
-	slackMessage := p.summarizer.GenerateSlackMessage(issueData, summary)
-
-	// Send to Slack
-	if err := p.slackNotifier.SendIssueSummary(context.Background(), slackMessage); err != nil {
-		p.logger.Error("Failed to send Slack message", zap.Error(err))
-		p.metrics.RecordIssueProcessed(issueData.Repository.GetFullName(), "issue", "error", time.Since(start))
-		return
-	}
-
-	// Record successful processing
-	duration := time.Since(start)
-	p.metrics.RecordIssueProcessed(issueData.Repository.GetFullName(), "issue", "success", duration)
-	p.metrics.RecordIssueSummaryGenerated(issueData.Repository.GetFullName(), "issue")
-
-	p.logger.Info("Successfully processed issue",
-		zap.String("repository", issueData.Repository.GetFullName()),
-		zap.Int("issue_number", issueData.Issue.GetNumber()),
-		zap.String("priority", summary.Priority),
-		zap.String("category", summary.Category),
-		zap.Duration("processing_time", duration),
-	)
-}
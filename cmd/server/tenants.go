@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/breaker"
+	"github-issue-ai-bot/internal/components"
+	"github-issue-ai-bot/internal/config"
+	"github-issue-ai-bot/internal/diagnostics"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/notify"
+	"github-issue-ai-bot/internal/oncall"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/postprocess"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/slack"
+	"github-issue-ai-bot/internal/store"
+)
+
+// tenant is the processing stack of one tenant in multi-tenant mode, with its
+// own credentials, store and metrics
+type tenant struct {
+	config     config.TenantConfig
+	github     *github.Handler
+	summarizer *ai.Summarizer
+	slack      *slack.Notifier
+	router     *routing.Router
+	processor  *pipeline.IssueProcessor
+}
+
+// newTenant builds a tenant's stack. The summarizer uses the tenant's own
+// OpenAI key, or the deployment's key within the tenant's daily quota.
+func newTenant(ctx context.Context, cfg *config.Config, tc config.TenantConfig, base *ai.Summarizer, actions *github.ActionMatrix, taxonomy *ai.Taxonomy, logger *zap.Logger) (*tenant, error) {
+	logger = logger.With(zap.String("tenant", tc.Name))
+	metrics := monitor.NewTenantMetrics(tc.Name, cfg.Monitor.Metrics)
+	metrics.SetSLOTargets(cfg.Monitor.SLOAvailabilityTarget, cfg.Monitor.SLOLatencyTarget)
+
+	githubHandler := github.NewHandler(tc.GitHubAccessToken, tc.GitHubWebhookSecret, logger, metrics)
+	githubHandler.EnableCloseSuggestions(cfg.GitHub.CloseSuggestions)
+	githubHandler.SetCommentLimit(cfg.GitHub.CommentLimit)
+	githubHandler.SetAttachments(cfg.GitHub.Attachments)
+	githubHandler.SetRegressionHints(cfg.GitHub.Regressions)
+	githubHandler.SetCIFailures(cfg.GitHub.CIFailures)
+	githubHandler.SetStackDetection(cfg.GitHub.Stack)
+	githubHandler.SetCommands(cfg.GitHub.Commands)
+	githubHandler.SetChecks(cfg.GitHub.Checks)
+	githubHandler.SetEnrichTimeout(cfg.Timeouts.Enrich)
+	githubHandler.SetEnrichment(cfg.GitHub.Enrichment)
+	githubHandler.SetActionMatrix(actions)
+	workQueue, err := github.NewWorkQueue(cfg.GitHub.Queue)
+	if err != nil {
+		return nil, err
+	}
+	githubHandler.SetWorkQueue(workQueue)
+	githubHandler.SetBaseContext(ctx)
+
+	apiKey := tc.OpenAIAPIKey
+	if apiKey == "" {
+		apiKey = cfg.OpenAI.APIKey
+	}
+	summarizer := base.ForTenant(apiKey, metrics)
+	if tc.OpenAIAPIKey == "" && tc.OpenAIDailyTokens > 0 {
+		summarizer.SetTokenQuota(ai.NewTokenQuota(tc.OpenAIDailyTokens))
+	}
+
+	slackNotifier := slack.NewNotifier(tc.SlackBotToken, tc.SlackChannelID, tc.SlackSigningSecret, logger, metrics, summarizer, githubHandler)
+	slackNotifier.SetTimeouts(cfg.Timeouts.AI, cfg.Timeouts.Slack)
+	slackNotifier.SetBaseContext(ctx)
+
+	issueRouter, err := routing.NewRouter(tc.RoutingRules, tc.SlackChannelID, cfg.Routing.DefaultLayout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid routing rules: %w", err)
+	}
+
+	issueProcessor := pipeline.NewIssueProcessor(summarizer, slackNotifier, issueRouter, store.NewMemoryStore(), logger, metrics)
+	if err := configurePipeline(cfg, issueProcessor, summarizer, slackNotifier, githubHandler, taxonomy, logger); err != nil {
+		return nil, err
+	}
+	if err := addNotifiers(issueProcessor, tc.Notifiers); err != nil {
+		return nil, err
+	}
+	githubHandler.SetIssueProcessor(issueProcessor)
+
+	return &tenant{
+		config:     tc,
+		github:     githubHandler,
+		summarizer: summarizer,
+		slack:      slackNotifier,
+		router:     issueRouter,
+		processor:  issueProcessor,
+	}, nil
+}
+
+// setBreakers guards a stack's GitHub, OpenAI and Slack clients with the
+// deployment's circuit breakers. Tenants share them, since an outage of a
+// dependency affects every tenant.
+func setBreakers(breakers breaker.Set, githubHandler *github.Handler, summarizer *ai.Summarizer, slackNotifier *slack.Notifier) {
+	for _, b := range breakers {
+		switch b.Name() {
+		case breaker.GitHub:
+			githubHandler.SetBreaker(b)
+		case breaker.OpenAI:
+			summarizer.SetBreaker(b)
+		case breaker.Slack:
+			slackNotifier.SetBreaker(b)
+		}
+	}
+}
+
+// addNotifiers sends a stack's summaries to its notifiers besides Slack
+func addNotifiers(issueProcessor *pipeline.IssueProcessor, configs []notify.Config) error {
+	for _, notifierConfig := range configs {
+		notifier, err := notify.New(notifierConfig)
+		if err != nil {
+			return fmt.Errorf("invalid notifier: %w", err)
+		}
+		issueProcessor.AddNotifier(notifier)
+	}
+	return nil
+}
+
+// configurePipeline applies the pipeline settings, which every tenant shares
+func configurePipeline(cfg *config.Config, issueProcessor *pipeline.IssueProcessor, summarizer *ai.Summarizer, slackNotifier *slack.Notifier, githubHandler *github.Handler, taxonomy *ai.Taxonomy, logger *zap.Logger) error {
+	issueProcessor.SetChangeThreshold(cfg.Pipeline.ChangeThreshold)
+	issueProcessor.SetMemoryLimit(cfg.Pipeline.MemoryMaxTokens)
+	issueProcessor.SetTimeouts(cfg.Timeouts.AI, cfg.Timeouts.Slack)
+	issueProcessor.SetTaxonomy(taxonomy)
+	slackNotifier.SetIssueMemory(issueProcessor)
+	slackNotifier.SetSummaryExplainer(issueProcessor)
+	if cfg.GitHub.LinkedIssues.Enabled {
+		linkedIssues := github.NewLinkedIssueCache(githubHandler, cfg.GitHub.LinkedIssues)
+		linkedIssues.SetSummaryLookup(issueProcessor)
+		githubHandler.SetLinkedIssues(linkedIssues)
+		slackNotifier.SetLinkedIssues(linkedIssues)
+	}
+	issueProcessor.SetCommands(githubHandler, summarizer, slackNotifier)
+	issueProcessor.SetMoveNotes(slackNotifier)
+	githubHandler.SetIssueMover(issueProcessor)
+	if cfg.GitHub.Checks.Mode != "" {
+		issueProcessor.SetCheckPublisher(githubHandler)
+	}
+	if cfg.Pipeline.PrefilterEnabled {
+		issueProcessor.SetPrefilter(pipeline.NewPrefilter(cfg.Pipeline.Prefilter))
+	}
+	if cfg.Pipeline.RateLimit > 0 {
+		issueProcessor.SetCoalescer(pipeline.NewCoalescer(cfg.Pipeline.RateLimit, cfg.Pipeline.RateWindow))
+	}
+	if cfg.Pipeline.IncidentChannels {
+		issueProcessor.SetIncidents(slackNotifier, cfg.Pipeline.Incidents)
+	}
+	if cfg.Pipeline.TranslationEnabled {
+		issueProcessor.SetTranslator(summarizer)
+	}
+	if cfg.Pipeline.AutoLabel {
+		issueProcessor.SetAutoLabeler(githubHandler, taxonomy)
+	}
+	if cfg.Pipeline.Acknowledgement.Enabled {
+		issueProcessor.SetAcknowledgement(githubHandler, cfg.Pipeline.Acknowledgement)
+	}
+	if cfg.Pipeline.InfoRequests.Enabled {
+		issueProcessor.SetInfoRequests(githubHandler, githubHandler, cfg.Pipeline.InfoRequests)
+	}
+	if cfg.Pipeline.ReactionBoost.Threshold > 0 {
+		issueProcessor.SetReactionBoost(githubHandler, githubHandler, cfg.Pipeline.ReactionBoost)
+	}
+	if cfg.Pipeline.Reanalysis.Every > 0 {
+		issueProcessor.SetReanalysis(githubHandler, slackNotifier, cfg.Pipeline.Reanalysis)
+	}
+	if cfg.Pipeline.Engagement.Enabled {
+		issueProcessor.SetEngagement(slackNotifier, cfg.Pipeline.Engagement)
+		slackNotifier.SetEngagement(issueProcessor)
+	}
+	if cfg.Pipeline.Moderation.Enabled() {
+		issueProcessor.SetModeration(githubHandler, cfg.Pipeline.Moderation)
+		slackNotifier.SetModeration(issueProcessor)
+	}
+	if cfg.Slack.Urgency.Enabled {
+		urgency, err := pipeline.NewUrgency(cfg.Slack.Urgency)
+		if err != nil {
+			return fmt.Errorf("invalid Slack urgency levels: %w", err)
+		}
+		issueProcessor.SetUrgency(urgency)
+	}
+	issueProcessor.SetAccessibility(cfg.Slack.Accessibility)
+	postProcessors, err := postprocess.New(cfg.Pipeline.PostProcessors)
+	if err != nil {
+		return fmt.Errorf("invalid summary post-processors: %w", err)
+	}
+	for _, processor := range postProcessors {
+		issueProcessor.AddPostProcessor(processor)
+	}
+	if cfg.Slack.IssueShortcut.Enabled() {
+		slackNotifier.SetIssueShortcut(cfg.Slack.IssueShortcut, issueProcessor)
+	}
+	slackNotifier.SetRequireWriteAccess(cfg.Slack.RequireWriteAccess)
+	if len(cfg.OnCall.Schedules) > 0 {
+		scheduler, err := oncall.NewScheduler(cfg.OnCall.Schedules)
+		if err != nil {
+			return fmt.Errorf("invalid on-call schedules: %w", err)
+		}
+		issueProcessor.SetOnCall(scheduler, cfg.OnCall.MentionPriority)
+		logger.Info("Loaded on-call schedules", zap.Int("schedules", len(cfg.OnCall.Schedules)))
+	}
+	if len(cfg.Pipeline.Components) > 0 {
+		detector, err := components.NewDetector(cfg.Pipeline.Components)
+		if err != nil {
+			return fmt.Errorf("invalid component mappings: %w", err)
+		}
+		issueProcessor.SetComponentDetector(detector)
+		logger.Info("Loaded component mappings", zap.Int("components", len(cfg.Pipeline.Components)))
+	}
+	if cfg.Pipeline.SummaryMode == config.SummaryModeTwoStage {
+		summarizer.SetTriageModel(cfg.OpenAI.TriageModel)
+		issueProcessor.SetTwoStage(cfg.Pipeline.DeepAnalysisPriority)
+		slackNotifier.SetDeepAnalyzer(issueProcessor)
+		logger.Info("Using two-stage summarization",
+			zap.String("triage_model", cfg.OpenAI.TriageModel),
+			zap.String("deep_analysis_priority", cfg.Pipeline.DeepAnalysisPriority),
+		)
+	}
+	return nil
+}
+
+// diagnose checks the tenant's credentials, naming each check after the tenant
+func (t *tenant) diagnose(ctx context.Context, incidentChannels bool) []diagnostics.Check {
+	checks := t.github.Diagnose(ctx)
+	checks = append(checks, t.slack.Diagnose(ctx, t.router.Channels(), slack.RequiredScopes(incidentChannels))...)
+	for i := range checks {
+		checks[i].Name = t.config.Name + "/" + checks[i].Name
+	}
+	return checks
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Token is the admin token shared by the admin endpoints. It is replaced when
+// its mounted secret is rotated.
+type Token struct {
+	value atomic.Pointer[string]
+}
+
+// NewToken creates a token holding value, empty for none
+func NewToken(value string) *Token {
+	t := &Token{}
+	t.Set(value)
+	return t
+}
+
+// Set replaces the token
+func (t *Token) Set(value string) {
+	t.value.Store(&value)
+}
+
+// Value returns the current token. A nil token is empty.
+func (t *Token) Value() string {
+	if t == nil {
+		return ""
+	}
+	if value := t.value.Load(); value != nil {
+		return *value
+	}
+	return ""
+}
+
+// Authorized reports whether a request carries the admin token as a bearer
+// token. An empty token authorizes nothing.
+func Authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
+
+// Require answers 401 to a request without the admin token, and reports
+// whether the request may go on
+func Require(w http.ResponseWriter, r *http.Request, token string) bool {
+	if Authorized(r, token) {
+		return true
+	}
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+	return false
+}
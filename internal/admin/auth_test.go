@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorized(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   bool
+	}{
+		{"valid token", "secret", "Bearer secret", true},
+		{"wrong token", "secret", "Bearer other", false},
+		{"missing token", "secret", "", false},
+		{"no admin token configured", "", "Bearer ", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if got := Authorized(req, tt.token); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	if Require(w, req, "secret") || w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the token, got %d", w.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	if !Require(w, req, "secret") || w.Body.Len() != 0 {
+		t.Errorf("Expected the request let through untouched, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTokenRotation(t *testing.T) {
+	token := NewToken("old")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer new")
+	if Authorized(req, token.Value()) {
+		t.Error("Expected the new token rejected before rotation")
+	}
+	token.Set("new")
+	if !Authorized(req, token.Value()) {
+		t.Error("Expected the rotated token accepted")
+	}
+	if (*Token)(nil).Value() != "" {
+		t.Error("Expected a nil token to be empty")
+	}
+}
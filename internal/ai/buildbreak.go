@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// maxBuildBreakErrors caps the error excerpt of a build break card, within
+// Slack's 3000 character limit for a section
+const maxBuildBreakErrors = 2500
+
+// GenerateBuildBreakSlackMessage generates the card for an issue opened by
+// CI: the failed workflow, job and step, the errors from the step's log and
+// the commit that probably broke the build, without an AI summary
+func (s *Summarizer) GenerateBuildBreakSlackMessage(issueData *gh.IssueData, failure *gh.CIFailure) map[string]interface{} {
+	repoName := "Unknown Repository"
+	if issueData.Repository != nil {
+		repoName = issueData.Repository.GetFullName()
+	}
+	number := issueData.Issue.GetNumber()
+
+	workflow := failure.Workflow
+	if workflow == "" {
+		workflow = "CI"
+	}
+	status := ""
+	if issueData.Issue.GetState() == "closed" {
+		status = " · :lock: Closed"
+	}
+	header := fmt.Sprintf(":rotating_light: *Build break:* <%s|%s>", failure.RunURL, workflow)
+	if failure.Branch != "" {
+		header += fmt.Sprintf(" on `%s`", failure.Branch)
+	}
+	header += fmt.Sprintf("%s\n*<%s|%s#%d: %s>*", status, issueData.Issue.GetHTMLURL(), repoName, number,
+		utils.TruncateText(issueData.Issue.GetTitle(), compactSummaryLength))
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": header},
+		},
+	}
+
+	var fields []map[string]interface{}
+	if failure.Job != "" {
+		fields = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*Failed Job:*\n<%s|%s>", failure.JobURL, failure.Job),
+		})
+	}
+	if failure.Step != "" {
+		fields = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*Failed Step:*\n%s", failure.Step),
+		})
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+	}
+
+	if len(failure.Errors) > 0 {
+		excerpt := utils.TruncateText(strings.ReplaceAll(strings.Join(failure.Errors, "\n"), "```", "'''"), maxBuildBreakErrors)
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{
+				"type": "mrkdwn",
+				"text": fmt.Sprintf("*Error:*\n```%s```", excerpt),
+			},
+		})
+	}
+
+	if failure.Commit.SHA != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{
+				"type": "mrkdwn",
+				"text": "*Probable Offending Commit:*\n" + commitLine(failure.Commit),
+			},
+		})
+	}
+	if len(failure.Suspects) > 1 {
+		lines := make([]string, 0, len(failure.Suspects))
+		for _, commit := range failure.Suspects {
+			lines = append(lines, "• "+commitLine(commit))
+		}
+		blocks = append(blocks, map[string]interface{}{
+			"type": "context",
+			"elements": []map[string]interface{}{
+				{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("Commits since the last green build (`%s`):\n%s", failure.LastGreen, strings.Join(lines, "\n")),
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"text":   utils.TruncateText(fmt.Sprintf("Build break in %s#%d: %s", repoName, number, issueData.Issue.GetTitle()), maxFallbackText),
+		"blocks": blocks,
+	}
+}
+
+// commitLine formats a commit as its linked short SHA, message and author
+func commitLine(commit gh.CICommit) string {
+	line := fmt.Sprintf("<%s|`%s`> %s", commit.URL, commit.SHA, utils.TruncateText(commit.Message, compactSummaryLength))
+	if commit.Author != "" {
+		line += " · " + commit.Author
+	}
+	return line
+}
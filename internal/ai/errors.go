@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"errors"
+	"net/http"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// classifyError maps go-openai errors onto the shared error classes
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		if code, _ := apiErr.Code.(string); code == "context_length_exceeded" {
+			return apperrors.Wrap(apperrors.ErrContextTooLong, err)
+		}
+		return apperrors.Wrap(classForStatus(apiErr.HTTPStatusCode), err)
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return apperrors.Wrap(classForStatus(reqErr.HTTPStatusCode), err)
+	}
+
+	return err
+}
+
+// classForStatus returns the error class for an HTTP status code, or nil
+func classForStatus(code int) error {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return apperrors.ErrRateLimited
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return apperrors.ErrAuth
+	case code == http.StatusNotFound:
+		return apperrors.ErrNotFound
+	case code >= http.StatusInternalServerError:
+		return apperrors.ErrUnavailable
+	default:
+		return nil
+	}
+}
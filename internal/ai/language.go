@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// LanguageEnglish is the language summaries are written in
+const LanguageEnglish = "en"
+
+// minLanguageWords is how many words detection needs to make a call
+const minLanguageWords = 5
+
+// languageNames maps the detected ISO 639-1 codes to display names
+var languageNames = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"pt": "Portuguese",
+	"it": "Italian",
+	"nl": "Dutch",
+	"ru": "Russian",
+	"el": "Greek",
+	"ar": "Arabic",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"th": "Thai",
+	"ko": "Korean",
+	"ja": "Japanese",
+	"zh": "Chinese",
+}
+
+// scriptLanguages maps non-Latin scripts to the language they most likely are
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopwords are common function words that tell Latin-script languages apart
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "when", "with", "this", "that", "not", "to", "of", "it", "for", "have", "but", "does", "i", "on"},
+	"es": {"el", "los", "las", "que", "cuando", "con", "para", "una", "es", "pero", "por", "del", "esto", "se", "al", "muy", "está", "funciona"},
+	"fr": {"le", "les", "des", "est", "une", "quand", "avec", "pour", "pas", "dans", "sur", "ce", "cette", "je", "nous", "il", "fonctionne", "mais"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "wenn", "mit", "ein", "eine", "ich", "auf", "bei", "für", "auch", "es", "funktioniert", "aber"},
+	"pt": {"o", "os", "que", "não", "quando", "com", "para", "uma", "um", "é", "mas", "em", "do", "da", "isso", "está", "funciona"},
+	"it": {"il", "lo", "gli", "che", "non", "quando", "con", "per", "una", "è", "ma", "della", "questo", "sono", "nel", "funziona", "anche"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "wanneer", "met", "voor", "ik", "op", "bij", "ook", "maar", "werkt", "dat", "van"},
+}
+
+var (
+	// codeFencePattern matches fenced code blocks, whose contents are not prose
+	codeFencePattern = regexp.MustCompile("(?s)```.*?```")
+	// inlineNoisePattern matches inline code and URLs
+	inlineNoisePattern = regexp.MustCompile("`[^`]*`|https?://\\S+")
+)
+
+// LanguageName returns the display name for a detected language code
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return strings.ToUpper(code)
+}
+
+// DetectLanguage guesses the ISO 639-1 code of the prose in an issue, ignoring
+// code blocks and URLs. It returns an empty string when there is too little
+// text to tell.
+func DetectLanguage(text string) string {
+	text = codeFencePattern.ReplaceAllString(text, " ")
+	text = inlineNoisePattern.ReplaceAllString(text, " ")
+
+	// Non-Latin scripts identify the language on their own
+	var letters, latin int
+	scripts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for _, script := range scriptLanguages {
+			if unicode.Is(script.table, r) {
+				scripts[script.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	if float64(letters-latin)/float64(letters) > 0.3 {
+		// Kana means Japanese even when most characters are Han
+		if scripts["ja"] > 0 {
+			return "ja"
+		}
+		best := ""
+		for _, script := range scriptLanguages {
+			if scripts[script.language] > scripts[best] {
+				best = script.language
+			}
+		}
+		return best
+	}
+
+	// Latin scripts are told apart by their most common words
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) < minLanguageWords {
+		return ""
+	}
+	counts := make(map[string]int)
+	for _, word := range words {
+		for language, list := range stopwords {
+			for _, stopword := range list {
+				if word == stopword {
+					counts[language]++
+					break
+				}
+			}
+		}
+	}
+
+	// English wins ties, so mixed text is not translated needlessly
+	best := LanguageEnglish
+	for language, count := range counts {
+		if count > counts[best] || (count == counts[best] && language < best && best != LanguageEnglish) {
+			best = language
+		}
+	}
+	if counts[best] == 0 {
+		return ""
+	}
+	return best
+}
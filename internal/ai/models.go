@@ -0,0 +1,193 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// minPromptTokens is the least room a model must leave for the prompt after
+// the completion, so an issue and the instructions fit
+const minPromptTokens = 1000
+
+// omissionAllowance is room kept for the note in a shortened prompt
+const omissionAllowance = 100
+
+// ModelInfo describes what an OpenAI model can do
+type ModelInfo struct {
+	Name            string `mapstructure:"name" json:"name"`                           // Model name, also matching its dated versions, e.g. gpt-4o matches gpt-4o-2024-08-06
+	ContextWindow   int    `mapstructure:"context_window" json:"context_window"`       // Tokens of prompt and completion together
+	MaxOutputTokens int    `mapstructure:"max_output_tokens" json:"max_output_tokens"` // Largest completion the model returns
+	JSONMode        bool   `mapstructure:"json_mode" json:"json_mode"`                 // Accepts the json_object response format
+	Vision          bool   `mapstructure:"vision" json:"vision"`                       // Accepts images
+	Chat            bool   `mapstructure:"chat" json:"chat"`                           // Serves chat completions, which the bot needs
+
+	Price *monitor.ModelPrice `mapstructure:"-" json:"price,omitempty"` // List price, when known
+}
+
+// DefaultModels are the OpenAI models the bot knows the limits of
+var DefaultModels = []ModelInfo{
+	{Name: "gpt-4.1", ContextWindow: 1047576, MaxOutputTokens: 32768, JSONMode: true, Vision: true, Chat: true},
+	{Name: "gpt-4.1-mini", ContextWindow: 1047576, MaxOutputTokens: 32768, JSONMode: true, Vision: true, Chat: true},
+	{Name: "gpt-4.1-nano", ContextWindow: 1047576, MaxOutputTokens: 32768, JSONMode: true, Vision: true, Chat: true},
+	{Name: "gpt-4o", ContextWindow: 128000, MaxOutputTokens: 16384, JSONMode: true, Vision: true, Chat: true},
+	{Name: "gpt-4o-mini", ContextWindow: 128000, MaxOutputTokens: 16384, JSONMode: true, Vision: true, Chat: true},
+	{Name: "gpt-4-turbo", ContextWindow: 128000, MaxOutputTokens: 4096, JSONMode: true, Vision: true, Chat: true},
+	{Name: "gpt-4-1106", ContextWindow: 128000, MaxOutputTokens: 4096, JSONMode: true, Chat: true},
+	{Name: "gpt-4-0125", ContextWindow: 128000, MaxOutputTokens: 4096, JSONMode: true, Chat: true},
+	{Name: "gpt-4-32k", ContextWindow: 32768, MaxOutputTokens: 32768, Chat: true},
+	{Name: "gpt-4", ContextWindow: 8192, MaxOutputTokens: 8192, Chat: true},
+	{Name: "gpt-3.5-turbo", ContextWindow: 16385, MaxOutputTokens: 4096, JSONMode: true, Chat: true},
+	{Name: "gpt-3.5-turbo-instruct", ContextWindow: 4096, MaxOutputTokens: 4096},
+	{Name: "text-embedding-3-small"},
+	{Name: "text-embedding-3-large"},
+	{Name: "text-embedding-ada-002"},
+	{Name: "dall-e-3"},
+	{Name: "whisper-1"},
+	{Name: "tts-1"},
+}
+
+// ModelRegistry looks up the limits and features of models
+type ModelRegistry struct {
+	models []ModelInfo
+}
+
+// NewModelRegistry validates custom models and creates a registry of them
+// and the defaults. Custom models replace defaults of the same name.
+func NewModelRegistry(custom []ModelInfo) (*ModelRegistry, error) {
+	registry := &ModelRegistry{}
+	names := make(map[string]bool, len(custom))
+	for i, model := range custom {
+		if model.Name == "" {
+			return nil, fmt.Errorf("model %d: name is required", i)
+		}
+		if model.Chat && (model.ContextWindow <= 0 || model.MaxOutputTokens <= 0) {
+			return nil, fmt.Errorf("model %s: context window and max output tokens must be positive", model.Name)
+		}
+		if model.MaxOutputTokens > model.ContextWindow {
+			return nil, fmt.Errorf("model %s: max output tokens exceed the context window", model.Name)
+		}
+		names[model.Name] = true
+		registry.models = append(registry.models, model)
+	}
+	for _, model := range DefaultModels {
+		if !names[model.Name] {
+			registry.models = append(registry.models, model)
+		}
+	}
+	return registry, nil
+}
+
+// DefaultModelRegistry returns a registry of the default models
+func DefaultModelRegistry() *ModelRegistry {
+	registry, _ := NewModelRegistry(nil)
+	return registry
+}
+
+// Lookup finds a model by name. Dated versions, e.g. gpt-4o-2024-08-06, and
+// fine-tuned models, e.g. ft:gpt-4o-mini:org::id, match their base model.
+func (r *ModelRegistry) Lookup(name string) (ModelInfo, bool) {
+	base := strings.TrimPrefix(name, "ft:")
+	if i := strings.Index(base, ":"); i >= 0 {
+		base = base[:i]
+	}
+
+	var best *ModelInfo
+	for i, model := range r.models {
+		if base != model.Name && !strings.HasPrefix(base, model.Name+"-") {
+			continue
+		}
+		if best == nil || len(model.Name) > len(best.Name) {
+			best = &r.models[i]
+		}
+	}
+	if best == nil {
+		return ModelInfo{}, false
+	}
+	info := *best
+	if price, ok := monitor.PriceOf(base); ok {
+		info.Price = &price
+	}
+	return info, true
+}
+
+// Validate reports whether a model can summarize issues with completions of
+// up to maxTokens. Unknown models are accepted, as they may be newer than
+// the registry; their prompts are sent as built.
+func (r *ModelRegistry) Validate(name string, maxTokens int) error {
+	info, ok := r.Lookup(name)
+	if !ok {
+		return nil
+	}
+	if !info.Chat {
+		return fmt.Errorf("model %s does not serve chat completions", name)
+	}
+	if maxTokens > info.MaxOutputTokens {
+		return fmt.Errorf("model %s returns at most %d tokens, but %d were requested", name, info.MaxOutputTokens, maxTokens)
+	}
+	if info.ContextWindow-maxTokens < minPromptTokens {
+		return fmt.Errorf("model %s has a context window of %d tokens, which leaves no room for the prompt after %d completion tokens", name, info.ContextWindow, maxTokens)
+	}
+	return nil
+}
+
+// SetModelRegistry sets the registry prompts are sized with. Without one the
+// default models are used.
+func (s *Summarizer) SetModelRegistry(registry *ModelRegistry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models = registry
+}
+
+// modelInfo looks up a model in the summarizer's registry
+func (s *Summarizer) modelInfo(model string) (ModelInfo, bool) {
+	s.mu.RLock()
+	registry := s.models
+	s.mu.RUnlock()
+	if registry == nil {
+		registry = DefaultModelRegistry()
+	}
+	return registry.Lookup(model)
+}
+
+// responseFormat asks models that support it for a JSON object, so their
+// answers always parse
+func (s *Summarizer) responseFormat(model string) *openai.ChatCompletionResponseFormat {
+	if info, ok := s.modelInfo(model); ok && info.JSONMode {
+		return &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+	return nil
+}
+
+// fitPrompt shortens a prompt that would not fit the model's context window
+// next to the system prompt and the completion. The start, with the issue
+// itself, and the end, with its activity and event context, are kept.
+func (s *Summarizer) fitPrompt(model, system, prompt string, maxTokens int) string {
+	info, ok := s.modelInfo(model)
+	if !ok || !info.Chat {
+		return prompt
+	}
+	// Leave a tenth of the window for the estimate being off
+	budget := info.ContextWindow*9/10 - maxTokens - memory.EstimateTokens(system)
+	tokens := memory.EstimateTokens(prompt)
+	if tokens <= budget {
+		return prompt
+	}
+
+	runes := []rune(prompt)
+	keep := max(len(runes)*budget/tokens-omissionAllowance, 0)
+	head, tail := keep*2/3, keep-keep*2/3
+	omitted := len(runes) - head - tail
+	s.logger.Warn("Shortened prompt to fit the model's context window",
+		zap.String("model", model),
+		zap.Int("context_window", info.ContextWindow),
+		zap.Int("prompt_tokens", tokens),
+		zap.Int("omitted_characters", omitted))
+	return fmt.Sprintf("%s\n\n[... %d characters omitted to fit the %s context window ...]\n\n%s",
+		string(runes[:head]), omitted, model, string(runes[len(runes)-tail:]))
+}
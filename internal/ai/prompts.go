@@ -1,5 +1,14 @@
 package ai
 
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	gh "github-issue-ai-bot/internal/github"
+)
+
 // PredefinedPromptStyles provides ready-to-use prompt styles
 var PredefinedPromptStyles = map[string]PromptStyle{
 	"master_analyst": {
@@ -56,6 +65,18 @@ var PredefinedPromptStyles = map[string]PromptStyle{
 		Tone:          "concise",
 		DetailLevel:   "concise",
 		CustomFields:  make(map[string]string),
+		Model:         "gpt-4o-mini",
+		Temperature:   temperature(0.3),
+		MaxTokens:     800,
+	},
+
+	// Support tickets from Zendesk or Intercom, and the issues filed from them
+	gh.TicketPromptStyle: {
+		Personality:   "SUPPORT ENGINEER",
+		AnalysisFocus: "customer_impact",
+		Tone:          "professional",
+		DetailLevel:   "moderate",
+		CustomFields:  make(map[string]string),
 	},
 
 	"performance_focused": {
@@ -108,24 +129,115 @@ var PredefinedPromptStyles = map[string]PromptStyle{
 			"Compliance":     "Strict regulatory requirements",
 			"Response Time":  "Immediate action required",
 		},
+		Model:       "gpt-4o",
+		Temperature: temperature(0.2),
 	},
 }
 
-// GetPromptStyle returns a predefined prompt style by name
+// temperature returns a temperature override for a prompt style
+func temperature(t float32) *float32 {
+	return &t
+}
+
+// promptStyleName limits custom style names to what fits a URL path and a
+// Slack option value
+var promptStyleName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// customPromptStyles are the styles created at runtime, by name
+var customPromptStyles = struct {
+	sync.RWMutex
+	styles map[string]PromptStyle
+}{styles: make(map[string]PromptStyle)}
+
+// GetPromptStyle returns a predefined or custom prompt style by name
 func GetPromptStyle(name string) (PromptStyle, bool) {
-	style, exists := PredefinedPromptStyles[name]
+	if style, exists := PredefinedPromptStyles[name]; exists {
+		style.Name = name
+		return style, true
+	}
+	customPromptStyles.RLock()
+	defer customPromptStyles.RUnlock()
+	style, exists := customPromptStyles.styles[name]
+	if exists {
+		style.Name = name
+	}
 	return style, exists
 }
 
-// ListPromptStyles returns all available prompt style names
+// ListPromptStyles returns all available prompt style names, predefined
+// ones first
 func ListPromptStyles() []string {
 	var styles []string
 	for name := range PredefinedPromptStyles {
 		styles = append(styles, name)
 	}
+	customPromptStyles.RLock()
+	defer customPromptStyles.RUnlock()
+	custom := make([]string, 0, len(customPromptStyles.styles))
+	for name := range customPromptStyles.styles {
+		custom = append(custom, name)
+	}
+	sort.Strings(custom)
+	return append(styles, custom...)
+}
+
+// CustomPromptStyles returns a copy of the styles created at runtime
+func CustomPromptStyles() map[string]PromptStyle {
+	customPromptStyles.RLock()
+	defer customPromptStyles.RUnlock()
+	styles := make(map[string]PromptStyle, len(customPromptStyles.styles))
+	for name, style := range customPromptStyles.styles {
+		styles[name] = style
+	}
 	return styles
 }
 
+// ValidatePromptStyle checks a custom style before it is saved. Predefined
+// styles cannot be replaced. Unknown focuses and tones are allowed, as they
+// fall back to general instructions.
+func ValidatePromptStyle(name string, style PromptStyle) error {
+	if !promptStyleName.MatchString(name) {
+		return fmt.Errorf("invalid prompt style name %q: use up to 64 lowercase letters, digits, - and _", name)
+	}
+	if _, predefined := PredefinedPromptStyles[name]; predefined {
+		return fmt.Errorf("prompt style %s is predefined and cannot be changed", name)
+	}
+	if style.Personality == "" {
+		return fmt.Errorf("prompt style %s: personality is required", name)
+	}
+	if style.DetailLevel != "" && !validDetailLevel(style.DetailLevel) {
+		return fmt.Errorf("prompt style %s: detail level must be one of %v", name, DetailLevels)
+	}
+	if style.Temperature != nil && (*style.Temperature < 0 || *style.Temperature > 2) {
+		return fmt.Errorf("prompt style %s: temperature must be between 0 and 2", name)
+	}
+	if style.MaxTokens < 0 {
+		return fmt.Errorf("prompt style %s: max tokens must not be negative", name)
+	}
+	return nil
+}
+
+// SetCustomPromptStyle validates and adds or replaces a custom style
+func SetCustomPromptStyle(name string, style PromptStyle) error {
+	if err := ValidatePromptStyle(name, style); err != nil {
+		return err
+	}
+	customPromptStyles.Lock()
+	defer customPromptStyles.Unlock()
+	customPromptStyles.styles[name] = style
+	return nil
+}
+
+// DeleteCustomPromptStyle removes a custom style. Summarizers using it keep
+// it until another style is set.
+func DeleteCustomPromptStyle(name string) bool {
+	customPromptStyles.Lock()
+	defer customPromptStyles.Unlock()
+	_, exists := customPromptStyles.styles[name]
+	delete(customPromptStyles.styles, name)
+	return exists
+}
+
 // CreateCustomPromptStyle creates a custom prompt style
 func CreateCustomPromptStyle(personality, analysisFocus, tone, detailLevel string, customFields map[string]string) PromptStyle {
 	return PromptStyle{
@@ -136,3 +248,13 @@ func CreateCustomPromptStyle(personality, analysisFocus, tone, detailLevel strin
 		CustomFields:  customFields,
 	}
 }
+
+// validDetailLevel reports whether level is one of DetailLevels
+func validDetailLevel(level string) bool {
+	for _, known := range DetailLevels {
+		if level == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// TokenQuota caps the OpenAI tokens a summarizer may use per UTC day, e.g. a
+// tenant sharing the deployment's API key. A request is allowed while any of
+// the day's quota is left, so the last one may overshoot it.
+type TokenQuota struct {
+	mu    sync.Mutex
+	limit int
+	day   string
+	used  int
+}
+
+// NewTokenQuota creates a quota of limit tokens per UTC day
+func NewTokenQuota(limit int) *TokenQuota {
+	return &TokenQuota{limit: limit}
+}
+
+// Used returns the tokens used today
+func (q *TokenQuota) Used() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollover()
+	return q.used
+}
+
+// Limit returns the tokens allowed per day
+func (q *TokenQuota) Limit() int {
+	return q.limit
+}
+
+// allow reports whether any of today's quota is left
+func (q *TokenQuota) allow() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollover()
+	if q.used >= q.limit {
+		return fmt.Errorf("daily OpenAI quota of %d tokens used up: %w", q.limit, apperrors.ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// add counts tokens used by a completed request
+func (q *TokenQuota) add(tokens int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollover()
+	q.used += tokens
+}
+
+// rollover resets the count on a new UTC day. The caller holds the lock.
+func (q *TokenQuota) rollover() {
+	if day := time.Now().UTC().Format("2006-01-02"); day != q.day {
+		q.day, q.used = day, 0
+	}
+}
+
+// quotaCompleter refuses requests once the quota is used up. Streamed
+// responses carry no usage, so only completions count against the quota.
+type quotaCompleter struct {
+	ChatCompleter
+	quota *TokenQuota
+}
+
+func (c quotaCompleter) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if err := c.quota.allow(); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	resp, err := c.ChatCompleter.CreateChatCompletion(ctx, request)
+	if err == nil {
+		c.quota.add(resp.Usage.TotalTokens)
+	}
+	return resp, err
+}
+
+func (c quotaCompleter) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	if err := c.quota.allow(); err != nil {
+		return nil, err
+	}
+	return c.ChatCompleter.CreateChatCompletionStream(ctx, request)
+}
+
+// SetTokenQuota limits the tokens the summarizer may use per day. Requests
+// over the quota fail with apperrors.ErrQuotaExceeded.
+func (s *Summarizer) SetTokenQuota(quota *TokenQuota) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quota = quota
+}
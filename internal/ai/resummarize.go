@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gh "github-issue-ai-bot/internal/github"
+)
+
+// DetailLevels are the detail levels prompt styles understand
+var DetailLevels = []string{"concise", "moderate", "comprehensive", "executive"}
+
+// ResummarizeOptions change how a single summary is generated, e.g. from the
+// "Re-summarize…" modal in Slack. The zero value uses the summarizer's
+// settings without code context.
+type ResummarizeOptions struct {
+	Style       string // Predefined prompt style; empty keeps the current one
+	DetailLevel string // One of DetailLevels; empty keeps the style's
+	Language    string // Language to write the summary in; empty for English
+	CodeContext bool   // Include related commits and code changes
+}
+
+// Describe lists the options that differ from the defaults, for replies
+func (o ResummarizeOptions) Describe() string {
+	var parts []string
+	if o.Style != "" {
+		parts = append(parts, o.Style+" style")
+	}
+	if o.DetailLevel != "" {
+		parts = append(parts, o.DetailLevel+" detail")
+	}
+	if o.Language != "" {
+		parts = append(parts, "in "+o.Language)
+	}
+	if o.CodeContext {
+		parts = append(parts, "with code context")
+	} else {
+		parts = append(parts, "without code context")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ResummarizeIssue generates a summary with options that apply to this
+// request only; the summarizer's own settings are left unchanged
+func (s *Summarizer) ResummarizeIssue(ctx context.Context, issueData *gh.IssueData, options ResummarizeOptions) (*IssueSummary, error) {
+	request := s.clone()
+	if options.Style != "" {
+		style, ok := GetPromptStyle(options.Style)
+		if !ok {
+			return nil, fmt.Errorf("unknown prompt style %q", options.Style)
+		}
+		request.style = style
+	}
+	if options.DetailLevel != "" {
+		request.style.DetailLevel = options.DetailLevel
+	}
+	request.language = options.Language
+
+	if !options.CodeContext {
+		withoutCode := *issueData
+		withoutCode.Commits, withoutCode.Files, withoutCode.RegressionHints = nil, nil, nil
+		issueData = &withoutCode
+		request.omitCode = true
+	}
+	return request.SummarizeIssue(ctx, issueData)
+}
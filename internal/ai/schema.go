@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// SummarySchemaVersion is the version of the IssueSummary format. Bump it,
+// and add a migration to summaryMigrations, whenever a field is added that
+// older summaries need a value for, or a field changes meaning.
+const SummarySchemaVersion = 1
+
+// summaryMigrations upgrade stored summaries one version at a time:
+// summaryMigrations[v] turns a version v summary into version v+1
+var summaryMigrations = []func(summary *IssueSummary){
+	// Summaries stored before versioning may predate the components,
+	// reasoning and missing info. Lists are empty rather than nil, so
+	// consumers see [] instead of null.
+	func(summary *IssueSummary) {
+		summary.ActionItems = emptyIfNil(summary.ActionItems)
+		summary.Reasoning = emptyIfNil(summary.Reasoning)
+		summary.Components = emptyIfNil(summary.Components)
+		summary.MissingInfo = emptyIfNil(summary.MissingInfo)
+	},
+}
+
+// MigrateSummary returns a summary upgraded to SummarySchemaVersion. The
+// summary it is given is not modified.
+func MigrateSummary(summary IssueSummary) IssueSummary {
+	for summary.SchemaVersion < SummarySchemaVersion {
+		summaryMigrations[summary.SchemaVersion](&summary)
+		summary.SchemaVersion++
+	}
+	return summary
+}
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// Kinds of summary response fields
+const (
+	kindString  = "a string"
+	kindNumber  = "a number"
+	kindStrings = "a list of strings"
+)
+
+// schemaField is a field of the JSON object the AI answers with
+type schemaField struct {
+	name     string
+	kind     string
+	required bool
+}
+
+// summaryResponseSchema is the JSON object the summary prompts ask for
+var summaryResponseSchema = []schemaField{
+	{"title", kindString, true},
+	{"summary", kindString, true},
+	{"priority", kindString, false},
+	{"category", kindString, false},
+	{"action_items", kindStrings, false},
+	{"code_context", kindString, false},
+	{"confidence", kindNumber, false},
+	{"reasoning", kindStrings, false},
+	{"suggested_fix", kindString, false},
+	{"regression_hint", kindString, false},
+	{"missing_info", kindStrings, false},
+}
+
+// SchemaError lists how an AI response differs from the summary schema
+type SchemaError struct {
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("response does not match summary schema v%d: %s", SummarySchemaVersion, strings.Join(e.Problems, "; "))
+}
+
+// validateSummaryResponse checks an AI response against the summary schema
+// and names every field that is missing or of the wrong type. Keys match
+// case-insensitively, like when the response is decoded; unknown fields are
+// ignored.
+func validateSummaryResponse(response []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(response, &object); err != nil {
+		return &SchemaError{Problems: []string{"not a JSON object: " + err.Error()}}
+	}
+	fields := make(map[string]json.RawMessage, len(object))
+	for key, value := range object {
+		fields[strings.ToLower(key)] = value
+	}
+
+	var problems []string
+	for _, field := range summaryResponseSchema {
+		value, ok := fields[field.name]
+		if !ok || string(value) == "null" {
+			if field.required {
+				problems = append(problems, field.name+" is required")
+			}
+			continue
+		}
+		var err error
+		switch field.kind {
+		case kindString:
+			var s string
+			if err = json.Unmarshal(value, &s); err == nil && field.required && strings.TrimSpace(s) == "" {
+				problems = append(problems, field.name+" must not be empty")
+				continue
+			}
+		case kindNumber:
+			var f float64
+			err = json.Unmarshal(value, &f)
+		case kindStrings:
+			var list []string
+			err = json.Unmarshal(value, &list)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be %s, got %s", field.name, field.kind, utils.TruncateText(string(value), 40)))
+		}
+	}
+	if len(problems) > 0 {
+		return &SchemaError{Problems: problems}
+	}
+	return nil
+}
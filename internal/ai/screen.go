@@ -0,0 +1,160 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// redacted replaces credentials removed from fix suggestions and captures
+const redacted = "[REDACTED]"
+
+// fixWarning starts the notes screening adds to fix suggestions
+const fixWarning = ":warning: "
+
+// secretPatterns find credentials by the format of well-known tokens. A
+// pattern with a group only redacts the group, e.g. the value of an
+// assignment.
+var secretPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"Stripe key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{20,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"connection string password", regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@]+:([^\s@/]{4,})@`)},
+	{"hard-coded secret", regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|client_?secret|api_?key|access_?key|auth_?token|token)\b["']?\s*[:=]\s*["']([^"'\s]{8,})["']`)},
+}
+
+// placeholderWords mark example values the model was asked to fill in, which
+// are not leaked credentials
+var placeholderWords = []string{"<", "${", "$(", "{{", "your", "example", "changeme", "xxxx", "redacted", "placeholder", "..."}
+
+// dangerousPatterns find commands that destroy data or run unreviewed code
+var dangerousPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"rm -rf on a root, home or wildcard path", regexp.MustCompile(`\brm\s+(?:-[a-zA-Z]*\s+)*-[a-zA-Z]*(?:r[a-zA-Z]*f|f[a-zA-Z]*r)[a-zA-Z]*\s+(?:-[-a-zA-Z]+\s+)*(?:/|/\*|~|~/|\*|\$HOME|\.\.?/?)(?:\s|$|;|&|\|)`)},
+	{"rm --no-preserve-root", regexp.MustCompile(`--no-preserve-root`)},
+	{"download piped to a shell", regexp.MustCompile(`(?i)\b(?:curl|wget)\b[^\n|]*\|\s*(?:sudo\s+)?(?:ba|z|da|k)?sh\b`)},
+	{"DROP TABLE", regexp.MustCompile(`(?i)\bDROP\s+(?:TABLE|DATABASE|SCHEMA)\b`)},
+	{"TRUNCATE TABLE", regexp.MustCompile(`(?i)\bTRUNCATE\s+TABLE\b`)},
+	{"filesystem format", regexp.MustCompile(`\bmkfs(?:\.[a-z0-9]+)?\s+/dev/`)},
+	{"raw disk write", regexp.MustCompile(`\bdd\b[^\n]*\bof=/dev/(?:sd|nvme|hd|disk|xvd)`)},
+	{"fork bomb", regexp.MustCompile(`:\(\)\s*\{\s*:\|:&\s*\};:`)},
+	{"recursive chmod 777 on root", regexp.MustCompile(`\bchmod\s+-R\s+0?777\s+/(?:\s|$)`)},
+}
+
+// FixScreening is what screening found in a fix suggestion
+type FixScreening struct {
+	Secrets   []string // Kinds of credentials that were redacted
+	Dangerous []string // Kinds of destructive commands that withheld the fix
+	Injection []string // Signs of a prompt injection that withheld the fix
+}
+
+// Blocked reports whether the fix was withheld
+func (f FixScreening) Blocked() bool {
+	return len(f.Dangerous) > 0 || len(f.Injection) > 0
+}
+
+// Clean reports whether nothing was found
+func (f FixScreening) Clean() bool {
+	return len(f.Secrets) == 0 && !f.Blocked()
+}
+
+// ScreenSuggestedFix makes a fix suggestion safe to post. Credentials are
+// redacted with a note, and a fix with destructive commands, e.g. rm -rf / or
+// curl piped to bash, or one that leaks the prompt or sends the environment
+// somewhere, is replaced by a warning.
+func ScreenSuggestedFix(fix string) (string, FixScreening) {
+	screening := FixScreening{Injection: screenInjection(fix)}
+	for _, dangerous := range dangerousPatterns {
+		if dangerous.pattern.MatchString(fix) {
+			screening.Dangerous = append(screening.Dangerous, dangerous.kind)
+		}
+	}
+
+	fix, screening.Secrets = RedactSecrets(fix)
+
+	if len(screening.Injection) > 0 {
+		return fmt.Sprintf("%sSuggested fix withheld: it looks like the result of a prompt injection in the issue (%s). Review the issue content before acting on it.",
+			fixWarning, strings.Join(screening.Injection, ", ")), screening
+	}
+	if screening.Blocked() {
+		return fmt.Sprintf("%sSuggested fix withheld: it contains a potentially destructive command (%s). Review the issue before running anything it suggests.",
+			fixWarning, strings.Join(screening.Dangerous, ", ")), screening
+	}
+	if len(screening.Secrets) > 0 {
+		if !strings.Contains(fix, "```") {
+			fix = fmt.Sprintf("```\n%s\n```", strings.TrimSpace(fix))
+		}
+		fix += fmt.Sprintf("\n%sCredentials were redacted from this suggestion (%s). Never paste real secrets into issues or code.",
+			fixWarning, strings.Join(screening.Secrets, ", "))
+	}
+	return fix, screening
+}
+
+// RedactSecrets replaces credentials in well-known formats with
+// [REDACTED], leaving placeholders such as <your-api-key> alone. It returns
+// the kinds of credentials it replaced.
+func RedactSecrets(text string) (string, []string) {
+	var kinds []string
+	for _, secret := range secretPatterns {
+		found := false
+		text = secret.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			value := match
+			if groups := secret.pattern.FindStringSubmatch(match); len(groups) > 1 {
+				value = groups[1]
+			}
+			if isPlaceholder(value) {
+				return match
+			}
+			found = true
+			return strings.Replace(match, value, redacted, 1)
+		})
+		if found {
+			kinds = append(kinds, secret.kind)
+		}
+	}
+	return text, kinds
+}
+
+// screenFix screens a fix suggestion, logging what was found but never the
+// credentials themselves
+func (s *Summarizer) screenFix(fix string) string {
+	screened, screening := ScreenSuggestedFix(fix)
+	if !screening.Clean() {
+		s.logger.Warn("Screened suggested fix",
+			zap.Strings("secrets", screening.Secrets),
+			zap.Strings("dangerous", screening.Dangerous),
+			zap.Strings("injection", screening.Injection))
+	}
+	for _, kind := range screening.Injection {
+		s.metrics.RecordPromptInjection("suggested_fix", kind)
+	}
+	return screened
+}
+
+// FixWithheld reports whether screening replaced a fix with a warning
+func FixWithheld(fix string) bool {
+	return strings.HasPrefix(strings.TrimSpace(fix), fixWarning)
+}
+
+// isPlaceholder reports whether a matched value is an example, not a secret
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, word := range placeholderWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// suggestFixPrompt asks for a fix as readable markdown so partial output can be shown while streaming
+const suggestFixPrompt = `You are a senior engineer helping a teammate fix a GitHub issue.
+Explain the most likely root cause in one or two sentences, then give a practical, copy-paste-ready fix.
+Put all code in a single fenced code block. If a code fix is not possible, give the most actionable next steps as a short numbered list.
+Respond in plain markdown, not JSON.
+
+` + untrustedNotice
+
+// maxFixContinuations bounds the follow-up requests for a fix cut off at the
+// completion limit
+const maxFixContinuations = 2
+
+// continueFixPrompt asks for the rest of a fix cut off at the completion limit
+const continueFixPrompt = "Your answer was cut off. Continue exactly where it stopped, without repeating anything or starting over."
+
+// StreamSuggestedFix generates a fix suggestion for an issue, calling onUpdate
+// with the accumulated text as tokens arrive. It returns the complete text.
+// Both are screened for credentials and destructive commands; once a partial
+// fix is withheld, onUpdate is not called again. A fix cut off at the
+// completion limit is continued in further requests and stitched together;
+// if it is still cut off, its code block is closed and the cut noted.
+func (s *Summarizer) StreamSuggestedFix(ctx context.Context, issueData *gh.IssueData, onUpdate func(text string)) (string, error) {
+	model, maxTokens, temp := s.requestSettings()
+	prompt := s.buildPrompt(issueData)
+
+	var text strings.Builder
+	withheld := false
+	truncated := false
+	for part := 0; part <= maxFixContinuations; part++ {
+		messages := []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: suggestFixPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: s.fitPrompt(model, suggestFixPrompt, prompt, maxTokens)},
+		}
+		if part > 0 {
+			// The partial fix takes up context too
+			messages[1].Content = s.fitPrompt(model, suggestFixPrompt+text.String()+continueFixPrompt, prompt, maxTokens)
+			messages = append(messages,
+				openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: text.String()},
+				openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: continueFixPrompt},
+			)
+		}
+
+		finishReason, err := s.streamFixPart(ctx, openai.ChatCompletionRequest{
+			Model:       model,
+			Messages:    messages,
+			MaxTokens:   maxTokens,
+			Temperature: temp,
+			Stream:      true,
+		}, func(delta string) {
+			text.WriteString(delta)
+			if onUpdate != nil && !withheld {
+				partial, screening := ScreenSuggestedFix(text.String())
+				withheld = screening.Blocked()
+				onUpdate(partial)
+			}
+		})
+		if err != nil {
+			return text.String(), err
+		}
+		truncated = finishReason == openai.FinishReasonLength
+		if !truncated {
+			break
+		}
+		s.logger.Info("Fix suggestion reached the completion limit",
+			zap.String("repository", issueData.Repository.GetFullName()),
+			zap.Int("issue_number", issueData.Issue.GetNumber()),
+			zap.Int("max_tokens", maxTokens),
+			zap.Bool("continuing", part < maxFixContinuations),
+		)
+	}
+
+	fix := text.String()
+	if truncated {
+		fix = closeTruncatedFix(fix)
+	}
+	s.logger.Info("Streamed fix suggestion",
+		zap.String("repository", issueData.Repository.GetFullName()),
+		zap.Int("issue_number", issueData.Issue.GetNumber()),
+		zap.Int("length", len(fix)),
+	)
+
+	return s.screenFix(fix), nil
+}
+
+// streamFixPart streams one completion, passing each piece of content to
+// onDelta, and returns why the completion finished
+func (s *Summarizer) streamFixPart(ctx context.Context, request openai.ChatCompletionRequest, onDelta func(delta string)) (openai.FinishReason, error) {
+	start := time.Now()
+	stream, err := s.openaiClient().CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return "", s.recordStreamError(request.Model, err, start)
+	}
+	defer stream.Close()
+
+	var finishReason openai.FinishReason
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", s.recordStreamError(request.Model, err, start)
+		}
+		if len(resp.Choices) == 0 {
+			continue
+		}
+		if resp.Choices[0].FinishReason != "" {
+			finishReason = resp.Choices[0].FinishReason
+		}
+		if resp.Choices[0].Delta.Content != "" {
+			onDelta(resp.Choices[0].Delta.Content)
+		}
+	}
+
+	s.metrics.RecordOpenAIRequest(request.Model, "success", time.Since(start))
+	return finishReason, nil
+}
+
+// closeTruncatedFix closes a code block left open by a fix cut off at the
+// completion limit, so Slack does not render the rest of the message as code,
+// and notes the cut
+func closeTruncatedFix(fix string) string {
+	fix = strings.TrimRight(fix, " \n")
+	if strings.Count(fix, "```")%2 == 1 {
+		fix += "\n```"
+	}
+	return fix + "\n_The suggestion was cut off at the length limit._"
+}
+
+// recordStreamError classifies and records a failed streaming request
+func (s *Summarizer) recordStreamError(model string, err error, start time.Time) error {
+	err = classifyError(err)
+	s.metrics.RecordOpenAIRequest(model, "error", time.Since(start))
+	s.metrics.RecordOpenAIError(apperrors.Classify(err))
+	s.logger.Error("OpenAI streaming error", zap.Error(err))
+	return fmt.Errorf("failed to stream fix suggestion: %w", err)
+}
+
+// FormatSuggestedFix wraps a fix in a code block unless the model already used
+// one or the fix was withheld. Markdown around the model's code blocks is
+// converted to Slack's.
+func FormatSuggestedFix(fix string) string {
+	fix = strings.TrimSpace(fix)
+	if fix == "" {
+		fix = "No fix suggestion provided."
+	}
+	if strings.Contains(fix, "```") {
+		fix = utils.MarkdownToSlack(fix)
+	} else if !FixWithheld(fix) {
+		fix = fmt.Sprintf("```\n%s\n```", fix)
+	}
+	return ":wrench: *Suggested Fix:*\n" + fix
+}
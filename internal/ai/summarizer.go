@@ -3,34 +3,86 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 	"go.uber.org/zap"
 
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/breaker"
 	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/pkg/utils"
 )
 
 // Summarizer handles AI-powered issue summarization
 type Summarizer struct {
-	client    *openai.Client
+	mu        sync.RWMutex
+	client    ChatCompleter
 	model     string
 	maxTokens int
 	temp      float32
 	logger    *zap.Logger
 	metrics   MetricsRecorder
 	style     PromptStyle
+
+	triageModel      string
+	slackTemplate    *SlackTemplate
+	fallbackTemplate *FallbackTemplate
+	taxonomy         *Taxonomy
+	assignButton     bool // Whether Slack messages offer "Assign to me"
+	styleSelector    StyleSelector
+
+	apiKey     string
+	options    ClientOptions
+	httpClient *http.Client     // Built from options, nil for the default client
+	breaker    *breaker.Breaker // Guards OpenAI requests, nil for none
+	quota      *TokenQuota      // Daily token limit, nil for none
+	models     *ModelRegistry   // Limits prompts are sized with, nil for the default models
+
+	// Per-request overrides, set only on the copies made by ResummarizeIssue
+	language string // Language to write the summary in
+	omitCode bool   // Related commits and code changes were left out
 }
 
 // PromptStyle defines the AI's analysis style and personality
 type PromptStyle struct {
-	Personality   string            // The AI's role/personality
-	AnalysisFocus string            // What aspects to focus on
-	Tone          string            // Communication tone
-	DetailLevel   string            // How detailed the analysis should be
-	CustomFields  map[string]string // Additional custom fields
+	Personality   string            `json:"personality"`             // The AI's role, or for custom styles the personality text itself
+	AnalysisFocus string            `json:"analysis_focus"`          // What aspects to focus on
+	Tone          string            `json:"tone"`                    // Communication tone
+	DetailLevel   string            `json:"detail_level"`            // How detailed the analysis should be
+	CustomFields  map[string]string `json:"custom_fields,omitempty"` // Additional custom fields
+
+	// Overrides of the OpenAI settings for summaries in this style; zero
+	// values use the summarizer's
+	Model       string   `json:"model,omitempty"`       // e.g. a cheaper model for quick triage
+	Temperature *float32 `json:"temperature,omitempty"` // nil keeps the summarizer's temperature
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // Completion limit
+
+	// Name is the name the style was looked up by, e.g. for usage reports;
+	// empty for the built-in default
+	Name string `json:"-"`
+}
+
+// requestSettings resolves the model, completion limit and temperature of a
+// summary request from the prompt style's overrides and the summarizer's
+// settings
+func (s *Summarizer) requestSettings() (string, int, float32) {
+	model, maxTokens, temp := s.model, s.maxTokens, s.temp
+	if s.style.Model != "" {
+		model = s.style.Model
+	}
+	if s.style.MaxTokens > 0 {
+		maxTokens = s.style.MaxTokens
+	}
+	if s.style.Temperature != nil {
+		temp = *s.style.Temperature
+	}
+	return model, maxTokens, temp
 }
 
 // MetricsRecorder interface for recording metrics
@@ -38,6 +90,7 @@ type MetricsRecorder interface {
 	RecordOpenAIRequest(model, status string, duration time.Duration)
 	RecordOpenAITokens(model, tokenType string, count int)
 	RecordOpenAIError(errorType string)
+	RecordPromptInjection(source, kind string)
 }
 
 // IssueSummary contains the AI-generated summary
@@ -46,31 +99,83 @@ type IssueSummary struct {
 	Summary      string
 	Priority     string
 	Category     string
-	ActionItems  []string
-	CodeContext  string
+	ActionItems  []string `json:"action_items"`
+	CodeContext  string   `json:"code_context"`
 	Confidence   float64
-	SuggestedFix string `json:"suggested_fix"`
+	Reasoning    []string // Signals from the issue that justify the priority and confidence
+	SuggestedFix string   `json:"suggested_fix"`
+	Components   []string `json:"-"` // Affected components, detected from changed files and labels
+	Triage       bool     `json:"-"` // Only the quick triage ran, without deep analysis
+	Language     string   `json:"-"` // Language the issue was written in, when it was translated
+	Original     string   `json:"-"` // Excerpt of the untranslated issue body
+	BoostedFrom  string   `json:"-"` // Priority the AI assigned before 👍 reactions raised it
+	EscalatedBy  string   `json:"-"` // Label whose addition escalated the issue to its priority
+	Usage        []Usage  `json:"-"` // OpenAI requests that produced the summary
+
+	// PromptVersion is the version of a prompt style under trial that
+	// produced the summary, empty outside trials
+	PromptVersion string `json:"-"`
+
+	// PromptStyle is the name of the prompt style that produced the summary,
+	// empty for the built-in default
+	PromptStyle string `json:"-"`
+
+	// Regression names the release that may have introduced the bug, from
+	// the issue's regression hints; empty when none of them fits
+	Regression string `json:"regression_hint"`
+
+	// MissingInfo names the details a bug report lacks to be reproduced,
+	// e.g. "version" or "steps to reproduce"; empty for complete reports
+	MissingInfo []string `json:"missing_info"`
+
+	// SchemaVersion is the SummarySchemaVersion the summary was generated
+	// with, 0 for summaries stored before versioning. MigrateSummary
+	// upgrades older ones.
+	SchemaVersion int `json:"schema_version,omitempty"`
+}
+
+// StyleSelector picks the prompt style of each summary and learns whether
+// its response could be parsed, e.g. to trial a new version of the style on
+// a share of issues. A nil style keeps the summarizer's own.
+type StyleSelector interface {
+	SelectStyle(issueData *gh.IssueData) (version string, style *PromptStyle)
+	RecordSummary(version string, parsed bool)
+}
+
+// errUnparseable is returned for AI responses that are not a valid summary
+var errUnparseable = errors.New("failed to parse summary response")
+
+// Usage is the tokens one OpenAI request used
+type Usage struct {
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// ChatCompleter is the part of the OpenAI API the summarizer uses.
+// *openai.Client implements it.
+type ChatCompleter interface {
+	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error)
 }
 
 // NewSummarizer creates a new AI summarizer
 func NewSummarizer(apiKey, model string, maxTokens int, temp float32, logger *zap.Logger, metrics MetricsRecorder) *Summarizer {
-	client := openai.NewClient(apiKey)
-
-	return &Summarizer{
-		client:    client,
-		model:     model,
-		maxTokens: maxTokens,
-		temp:      temp,
-		logger:    logger,
-		metrics:   metrics,
-		style:     DefaultPromptStyle(),
-	}
+	return NewSummarizerWithStyle(apiKey, model, maxTokens, temp, logger, metrics, DefaultPromptStyle())
 }
 
 // NewSummarizerWithStyle creates a new AI summarizer with custom prompt style
 func NewSummarizerWithStyle(apiKey, model string, maxTokens int, temp float32, logger *zap.Logger, metrics MetricsRecorder, style PromptStyle) *Summarizer {
-	client := openai.NewClient(apiKey)
+	s := NewSummarizerWithClient(openai.NewClient(apiKey), model, maxTokens, temp, logger, metrics)
+	s.apiKey = apiKey
+	s.style = style
+	return s
+}
 
+// NewSummarizerWithClient creates an AI summarizer that sends its requests to
+// client, e.g. a fake in tests. SetAPIKey and SetClientOptions replace it with
+// an OpenAI client.
+func NewSummarizerWithClient(client ChatCompleter, model string, maxTokens int, temp float32, logger *zap.Logger, metrics MetricsRecorder) *Summarizer {
 	return &Summarizer{
 		client:    client,
 		model:     model,
@@ -78,7 +183,8 @@ func NewSummarizerWithStyle(apiKey, model string, maxTokens int, temp float32, l
 		temp:      temp,
 		logger:    logger,
 		metrics:   metrics,
-		style:     style,
+		style:     DefaultPromptStyle(),
+		taxonomy:  DefaultTaxonomy(),
 	}
 }
 
@@ -98,50 +204,208 @@ func (s *Summarizer) SetPromptStyle(style PromptStyle) {
 	s.style = style
 }
 
-// SummarizeIssue generates an AI summary of a GitHub issue
+// SetSlackTemplate sets a custom template for Slack messages; nil restores
+// the built-in layout
+func (s *Summarizer) SetSlackTemplate(tmpl *SlackTemplate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slackTemplate = tmpl
+}
+
+// SetFallbackTemplate sets a custom template for the plain-text fallback of
+// Slack messages; nil restores the built-in text
+func (s *Summarizer) SetFallbackTemplate(tmpl *FallbackTemplate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallbackTemplate = tmpl
+}
+
+// SetTaxonomy sets the priorities and categories summaries are classified
+// into, and their emojis in Slack messages
+func (s *Summarizer) SetTaxonomy(taxonomy *Taxonomy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taxonomy = taxonomy
+}
+
+// SetAssignButton adds an "Assign to me" button to the Slack messages of
+// open GitHub issues, which assigns the issue to the clicker's GitHub
+// account
+func (s *Summarizer) SetAssignButton(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assignButton = enabled
+}
+
+// SetStyleSelector lets selector pick the prompt style of summaries of
+// issues that do not name their own. Re-summaries with chosen options do
+// not use it, and tenants' copies of the summarizer do not inherit it.
+func (s *Summarizer) SetStyleSelector(selector StyleSelector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.styleSelector = selector
+}
+
+// currentTaxonomy returns the taxonomy in use
+func (s *Summarizer) currentTaxonomy() *Taxonomy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.taxonomy
+}
+
+// SetAPIKey replaces the OpenAI API key, e.g. after a mounted secret has been rotated
+func (s *Summarizer) SetAPIKey(apiKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = apiKey
+	s.client = s.newClient(apiKey)
+}
+
+// ForTenant returns a summarizer with the same model, prompt style, taxonomy,
+// template and client options that sends its requests with apiKey and
+// records to metrics, for one tenant of a multi-tenant deployment
+func (s *Summarizer) ForTenant(apiKey string, metrics MetricsRecorder) *Summarizer {
+	tenant := s.clone()
+	tenant.apiKey, tenant.metrics, tenant.quota = apiKey, metrics, nil
+	tenant.client = tenant.newClient(apiKey)
+	return tenant
+}
+
+// clone copies the summarizer's settings and client
+func (s *Summarizer) clone() *Summarizer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &Summarizer{
+		client:           s.client,
+		model:            s.model,
+		maxTokens:        s.maxTokens,
+		temp:             s.temp,
+		logger:           s.logger,
+		metrics:          s.metrics,
+		style:            s.style,
+		triageModel:      s.triageModel,
+		slackTemplate:    s.slackTemplate,
+		fallbackTemplate: s.fallbackTemplate,
+		taxonomy:         s.taxonomy,
+		assignButton:     s.assignButton,
+		apiKey:           s.apiKey,
+		options:          s.options,
+		httpClient:       s.httpClient,
+		breaker:          s.breaker,
+		quota:            s.quota,
+		models:           s.models,
+		language:         s.language,
+		omitCode:         s.omitCode,
+	}
+}
+
+// openaiClient returns the current OpenAI client
+func (s *Summarizer) openaiClient() ChatCompleter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.quota != nil {
+		return quotaCompleter{ChatCompleter: s.client, quota: s.quota}
+	}
+	return s.client
+}
+
+// SummarizeIssue generates an AI summary of a GitHub issue, in the issue's
+// own prompt style if it names one
 func (s *Summarizer) SummarizeIssue(ctx context.Context, issueData *gh.IssueData) (*IssueSummary, error) {
+	s.mu.RLock()
+	selector := s.styleSelector
+	s.mu.RUnlock()
+	if selector != nil && issueData.PromptStyle == "" {
+		return s.summarizeSelected(ctx, issueData, selector)
+	}
+	return s.forIssue(issueData).summarizeIssue(ctx, issueData)
+}
+
+// summarizeSelected summarizes an issue in the style the selector picks and
+// tells it whether the response could be parsed. Failed requests say
+// nothing about the style, so they are not reported.
+func (s *Summarizer) summarizeSelected(ctx context.Context, issueData *gh.IssueData, selector StyleSelector) (*IssueSummary, error) {
+	request := s
+	version, style := selector.SelectStyle(issueData)
+	if style != nil {
+		request = s.clone()
+		request.style = *style
+	}
+	summary, err := request.summarizeIssue(ctx, issueData)
+	switch {
+	case errors.Is(err, errUnparseable):
+		selector.RecordSummary(version, false)
+	case err == nil:
+		summary.PromptVersion = version
+		selector.RecordSummary(version, true)
+	}
+	return summary, err
+}
+
+// forIssue returns a copy of the summarizer in the issue's prompt style, e.g.
+// for support tickets, or the summarizer itself for issues without one
+func (s *Summarizer) forIssue(issueData *gh.IssueData) *Summarizer {
+	if issueData.PromptStyle == "" {
+		return s
+	}
+	style, ok := GetPromptStyle(issueData.PromptStyle)
+	if !ok {
+		s.logger.Warn("Unknown prompt style for issue, using the configured one",
+			zap.String("prompt_style", issueData.PromptStyle))
+		return s
+	}
+	styled := s.clone()
+	styled.style = style
+	return styled
+}
+
+func (s *Summarizer) summarizeIssue(ctx context.Context, issueData *gh.IssueData) (*IssueSummary, error) {
 	start := time.Now()
+	model, maxTokens, temp := s.requestSettings()
 
-	// Build the prompt
-	prompt := s.buildPrompt(issueData)
+	// Build the prompt, shortened to fit the model if needed
+	system := s.getSystemPrompt()
+	prompt := s.fitPrompt(model, system, s.buildPrompt(issueData), maxTokens)
 
 	// Call OpenAI API
-	resp, err := s.client.CreateChatCompletion(
+	resp, err := s.openaiClient().CreateChatCompletion(
 		ctx,
 		openai.ChatCompletionRequest{
-			Model: s.model,
+			Model: model,
 			Messages: []openai.ChatCompletionMessage{
 				{
 					Role:    openai.ChatMessageRoleSystem,
-					Content: s.getSystemPrompt(),
+					Content: system,
 				},
 				{
 					Role:    openai.ChatMessageRoleUser,
 					Content: prompt,
 				},
 			},
-			MaxTokens:   s.maxTokens,
-			Temperature: s.temp,
+			MaxTokens:      maxTokens,
+			Temperature:    temp,
+			ResponseFormat: s.responseFormat(model),
 		},
 	)
 
 	duration := time.Since(start)
 
 	if err != nil {
-		s.metrics.RecordOpenAIRequest(s.model, "error", duration)
-		s.metrics.RecordOpenAIError("api_error")
+		err = classifyError(err)
+		s.metrics.RecordOpenAIRequest(model, "error", duration)
+		s.metrics.RecordOpenAIError(apperrors.Classify(err))
 		s.logger.Error("OpenAI API error", zap.Error(err))
 		return nil, fmt.Errorf("failed to generate summary: %w", err)
 	}
 
 	// Record successful request
-	s.metrics.RecordOpenAIRequest(s.model, "success", duration)
+	s.metrics.RecordOpenAIRequest(model, "success", duration)
 
 	// Record token usage
 	if resp.Usage.PromptTokens > 0 {
-		s.metrics.RecordOpenAITokens(s.model, "prompt", resp.Usage.PromptTokens)
-		s.metrics.RecordOpenAITokens(s.model, "completion", resp.Usage.CompletionTokens)
-		s.metrics.RecordOpenAITokens(s.model, "total", resp.Usage.TotalTokens)
+		s.metrics.RecordOpenAITokens(model, "prompt", resp.Usage.PromptTokens)
+		s.metrics.RecordOpenAITokens(model, "completion", resp.Usage.CompletionTokens)
+		s.metrics.RecordOpenAITokens(model, "total", resp.Usage.TotalTokens)
 	}
 
 	// Parse the response
@@ -149,8 +413,10 @@ func (s *Summarizer) SummarizeIssue(ctx context.Context, issueData *gh.IssueData
 	if err != nil {
 		s.metrics.RecordOpenAIError("parse_error")
 		s.logger.Error("Failed to parse AI response", zap.Error(err))
-		return nil, fmt.Errorf("failed to parse summary response: %w", err)
+		return nil, fmt.Errorf("%w: %w", errUnparseable, err)
 	}
+	summary.Usage = []Usage{{Model: model, PromptTokens: resp.Usage.PromptTokens, CompletionTokens: resp.Usage.CompletionTokens}}
+	summary.PromptStyle = s.style.Name
 
 	s.logger.Info("Generated issue summary",
 		zap.String("repository", issueData.Repository.GetFullName()),
@@ -169,7 +435,7 @@ func (s *Summarizer) buildPrompt(issueData *gh.IssueData) string {
 	// Issue basic information
 	parts = append(parts, fmt.Sprintf("## Issue Information\n"))
 	parts = append(parts, fmt.Sprintf("Repository: %s", issueData.Repository.GetFullName()))
-	parts = append(parts, fmt.Sprintf("Issue #%d: %s", issueData.Issue.GetNumber(), issueData.Issue.GetTitle()))
+	parts = append(parts, fmt.Sprintf("Issue #%d: %s", issueData.Issue.GetNumber(), s.filterUntrusted("issue_title", issueData.Issue.GetTitle())))
 	parts = append(parts, fmt.Sprintf("State: %s", issueData.Issue.GetState()))
 	parts = append(parts, fmt.Sprintf("Created by: %s", issueData.Issue.GetUser().GetLogin()))
 	parts = append(parts, fmt.Sprintf("Created at: %s", issueData.Issue.GetCreatedAt().Format(time.RFC3339)))
@@ -187,20 +453,54 @@ func (s *Summarizer) buildPrompt(issueData *gh.IssueData) string {
 		parts = append(parts, fmt.Sprintf("Labels: %s", strings.Join(labelNames, ", ")))
 	}
 
-	// Issue description
-	parts = append(parts, fmt.Sprintf("\n## Issue Description\n%s", issueData.Issue.GetBody()))
+	// Issue description, as structured fields when the issue came from an issue form
+	if len(issueData.FormFields) > 0 {
+		parts = append(parts, "\n## Issue Form Fields")
+		for _, field := range issueData.FormFields {
+			parts = append(parts, fmt.Sprintf("\n### %s\n%s", field.Label, s.untrusted("issue_form", field.Value)))
+		}
+	} else {
+		parts = append(parts, fmt.Sprintf("\n## Issue Description\n%s", s.untrusted("issue_body", issueData.Issue.GetBody())))
+	}
+
+	// Errors and warnings from attached log files
+	if len(issueData.Attachments) > 0 {
+		parts = append(parts, "\n## Diagnostics")
+		for _, attachment := range issueData.Attachments {
+			heading := fmt.Sprintf("\n### Attachment: %s", attachment.Name)
+			if !attachment.Matched {
+				heading += " (last lines, no errors or warnings found)"
+			}
+			if attachment.Truncated {
+				heading += " (truncated)"
+			}
+			parts = append(parts, heading, s.untrusted("attachment", "```\n"+strings.Join(attachment.Lines, "\n")+"\n```"))
+		}
+	}
 
 	// Comments
 	if len(issueData.Comments) > 0 {
 		parts = append(parts, "\n## Recent Comments")
-		for i, comment := range issueData.Comments {
-			if i >= 5 { // Limit to 5 most recent comments
-				break
-			}
+		comments := gh.SelectComments(issueData.Comments, 5) // The first and the 4 most recent
+		if total := max(issueData.Issue.GetComments(), len(issueData.Comments)); total > len(comments) {
+			parts = append(parts, fmt.Sprintf("(The first and the %d most recent of %d comments)", len(comments)-1, total))
+		}
+		for _, comment := range comments {
 			parts = append(parts, fmt.Sprintf("\n### Comment by %s (%s):",
 				comment.GetUser().GetLogin(),
 				comment.GetCreatedAt().Format(time.RFC3339)))
-			parts = append(parts, comment.GetBody())
+			parts = append(parts, s.untrusted("comment", comment.GetBody()))
+		}
+	}
+
+	// What the repository is written in, so suggested fixes use its languages
+	if issueData.Stack != nil {
+		if lines := issueData.Stack.Lines(); len(lines) > 0 {
+			parts = append(parts, "\n## Repository Stack")
+			parts = append(parts, "Write suggested fixes and code in the repository's languages and frameworks, following their idioms. Only use another language when the issue is about code written in it.")
+			for _, line := range lines {
+				parts = append(parts, "- "+line)
+			}
 		}
 	}
 
@@ -213,7 +513,7 @@ func (s *Summarizer) buildPrompt(issueData *gh.IssueData) string {
 			}
 			parts = append(parts, fmt.Sprintf("\n### Commit: %s", commit.GetSHA()[:8]))
 			parts = append(parts, fmt.Sprintf("Author: %s", commit.GetCommit().GetAuthor().GetName()))
-			parts = append(parts, fmt.Sprintf("Message: %s", commit.GetCommit().GetMessage()))
+			parts = append(parts, fmt.Sprintf("Message: %s", s.filterUntrusted("commit_message", commit.GetCommit().GetMessage())))
 		}
 	}
 
@@ -232,6 +532,32 @@ func (s *Summarizer) buildPrompt(issueData *gh.IssueData) string {
 		}
 	}
 
+	// Releases that changed files the report mentions, to date a regression
+	if len(issueData.RegressionHints) > 0 {
+		parts = append(parts, "\n## Possible Regressions")
+		parts = append(parts, `Recent releases changed files this report mentions. If the issue is a bug that one of them plausibly introduced, add a "regression_hint" field to the JSON, e.g. "possibly introduced in v1.4.2 (commit abc1234 touched auth.go 3 days before the first report)". Otherwise leave it out.`)
+		for _, hint := range issueData.RegressionHints {
+			parts = append(parts, "- "+hint.String())
+		}
+	}
+
+	// Earlier analyses, so the assessment evolves instead of starting over
+	if len(issueData.Memory) > 0 {
+		parts = append(parts, "\n## Previous Analysis")
+		parts = append(parts, "Earlier assessments and follow-ups for this issue, oldest first. Build on them and say what changed, e.g. when new information raises the priority.")
+		for _, entry := range issueData.Memory {
+			parts = append(parts, fmt.Sprintf("- [%s] %s: %s", entry.At.Format(time.RFC3339), entry.Kind, entry.Text))
+		}
+	}
+
+	// Activity context, so stale, neglected or popular issues are weighed accordingly
+	if issueData.Activity != nil {
+		parts = append(parts, "\n## Issue Activity")
+		for _, line := range issueData.Activity.Lines() {
+			parts = append(parts, "- "+line)
+		}
+	}
+
 	// Event context
 	parts = append(parts, fmt.Sprintf("\n## Event Context\n"))
 	parts = append(parts, fmt.Sprintf("Event Type: %s", issueData.EventType))
@@ -242,7 +568,14 @@ func (s *Summarizer) buildPrompt(issueData *gh.IssueData) string {
 
 // getSystemPrompt returns the system prompt for the AI model
 func (s *Summarizer) getSystemPrompt() string {
-	return s.buildSystemPrompt()
+	prompt := s.buildSystemPrompt() + "\n\n" + untrustedNotice
+	if s.omitCode {
+		prompt += "\n\nRelated commits and code changes were left out on request, so leave 'code_context' empty and base the suggested fix on the issue alone."
+	}
+	if s.language != "" {
+		prompt += fmt.Sprintf("\n\nWrite the title, summary, action items, suggested fix and reasoning in %s. Keep the JSON keys and the priority and category values exactly as listed above.", s.language)
+	}
+	return prompt
 }
 
 // buildSystemPrompt builds the system prompt based on the current style
@@ -252,6 +585,7 @@ func (s *Summarizer) buildSystemPrompt() string {
 	tone := s.getTonePrompt()
 	detailLevel := s.getDetailLevelPrompt()
 	customFields := s.getCustomFieldsPrompt()
+	taxonomy := s.currentTaxonomy()
 
 	return fmt.Sprintf(`%s
 
@@ -268,18 +602,21 @@ Please analyze the provided GitHub issue data and respond with a structured summ
 {
   "title": "%s",
   "summary": "%s",
-  "priority": "high|medium|low - based on your assessment of severity, urgency, and impact",
-  "category": "bug|feature|enhancement|documentation|security|performance|infrastructure|architecture|technical-debt|other",
+  "priority": "%s - based on your assessment of severity, urgency, and impact",
+  "category": "%s",
   "action_items": ["Specific, actionable recommendations with implementation guidance"],
   "code_context": "%s",
   "suggested_fix": "A practical, copy-paste-ready code snippet or clear step-by-step fix instructions for resolving the issue.",
-  "confidence": 0.85
+  "confidence": 0.85,
+  "reasoning": ["Short signals from the issue that justify the priority and confidence"]
 }
 
+%s
+
 Analysis Guidelines:
 %s
 
-In addition to your analysis, always provide a 'suggested_fix' field with a practical, copy-paste-ready code snippet or clear step-by-step instructions for resolving the issue. If a code fix is not possible, provide the most actionable next steps. Respond only with valid JSON that demonstrates your analytical capabilities.`,
+In addition to your analysis, always provide a 'suggested_fix' field with a practical, copy-paste-ready code snippet or clear step-by-step instructions for resolving the issue. If a code fix is not possible, provide the most actionable next steps. List two to four 'reasoning' signals, such as quoted error messages, the number of affected users or missing reproduction steps, that explain the priority and confidence you chose. Weigh the Issue Activity section: many 👍 reactions mean many affected users, and an old issue without a maintainer response deserves attention. If the issue is a bug report that lacks details needed to reproduce it, such as the affected version or the steps to reproduce, add a 'missing_info' list naming each missing detail in a few words, e.g. ["version", "steps to reproduce"]; leave it out for complete reports and for issues that are not bugs. Respond only with valid JSON that demonstrates your analytical capabilities.`,
 		personality,
 		analysisFocus,
 		tone,
@@ -287,7 +624,10 @@ In addition to your analysis, always provide a 'suggested_fix' field with a prac
 		customFields,
 		s.getTitlePrompt(),
 		s.getSummaryPrompt(),
+		promptValues(taxonomy.Priorities()),
+		promptValues(taxonomy.Categories()),
 		s.getCodeContextPrompt(),
+		taxonomy.promptDefinitions(),
 		s.getGuidelinesPrompt())
 }
 
@@ -344,8 +684,22 @@ Your expertise includes:
 - Compliance frameworks and security standards
 - Incident response and security monitoring`
 
-	default:
+	case "SUPPORT ENGINEER":
+		return `You are a SUPPORT ENGINEER with 8+ years of experience escalating customer reports to engineering teams. You read tickets written by customers who do not know the product's internals, and turn them into reports engineers can act on.
+
+Your expertise includes:
+- Separating the customer's actual problem from their guesses about its cause
+- Recognizing known issues, misconfigurations and how-to questions
+- Judging how many customers and how much revenue a problem affects
+- Spotting missing details engineering will need to reproduce a problem
+- Writing clear, blame-free escalations`
+
+	case "":
 		return `You are an experienced software professional with deep knowledge of software development, DevOps practices, and technical project management. You have analyzed numerous GitHub issues and can provide valuable insights and recommendations.`
+
+	default:
+		// Custom styles describe the personality in their own words
+		return s.style.Personality
 	}
 }
 
@@ -376,6 +730,14 @@ func (s *Summarizer) getAnalysisFocusPrompt() string {
 4. **Threat Modeling**: Identify potential threats and mitigation strategies
 5. **Security Best Practices**: Recommend secure implementation approaches`
 
+	case "customer_impact":
+		return `Your analysis methodology focuses on customer impact:
+1. **Customer Impact Assessment**: Evaluate how the problem blocks the customer and whether others are likely affected
+2. **Problem Classification**: Tell product defects apart from configuration mistakes, how-to questions and feature requests
+3. **Reproduction Details**: Identify the environment, steps and account details engineering needs, and note what is missing
+4. **Workarounds**: Suggest anything support can offer the customer while a fix is pending
+5. **Escalation**: Judge whether engineering needs to act, and how urgently`
+
 	case "performance_optimization":
 		return `Your analysis methodology focuses on performance optimization:
 1. **Performance Impact Assessment**: Evaluate the issue's effect on system performance
@@ -460,6 +822,8 @@ func (s *Summarizer) getTitlePrompt() string {
 		return "A security-focused title that highlights the security implications and risk level"
 	case "DEVOPS ENGINEER":
 		return "An operational title that captures the infrastructure and deployment impact"
+	case "SUPPORT ENGINEER":
+		return "A title stating the customer's problem in product terms, without their guesses about its cause"
 	default:
 		return "A precise, technical title that captures the core issue and its impact"
 	}
@@ -474,6 +838,8 @@ func (s *Summarizer) getSummaryPrompt() string {
 		return "A security-focused analysis including vulnerability assessment, risk analysis, and security implications"
 	case "DEVOPS ENGINEER":
 		return "An operational analysis including infrastructure impact, deployment considerations, and operational implications"
+	case "SUPPORT ENGINEER":
+		return "An escalation-ready analysis including the customer's problem, how many customers it may affect, reproduction details given or missing, and any workaround"
 	default:
 		return "A comprehensive technical analysis including problem statement, root cause assessment, system impact, and technical context"
 	}
@@ -528,6 +894,14 @@ func (s *Summarizer) getGuidelinesPrompt() string {
 - Include user feedback and stakeholder considerations
 - Consider resource allocation and timeline implications`
 
+	case "SUPPORT ENGINEER":
+		return `- Focus on what the customer cannot do and how badly it blocks them
+- Treat the customer's wording as a symptom report, not a diagnosis
+- Raise the priority for outages, data loss, billing errors and many affected customers
+- Treat how-to questions and configuration mistakes as low priority
+- List missing reproduction details as action items for support
+- Confidence should reflect how clearly the ticket describes the problem`
+
 	case "SECURITY EXPERT":
 		return `- Focus on security vulnerabilities and threat assessment
 - Consider compliance requirements and regulatory impact
@@ -545,6 +919,30 @@ func (s *Summarizer) getGuidelinesPrompt() string {
 	}
 }
 
+// maxMissingInfo is how many missing details a summary keeps, and
+// maxMissingInfoLength how long each may be, since they are quoted to the
+// reporter on GitHub
+const (
+	maxMissingInfo       = 5
+	maxMissingInfoLength = 80
+)
+
+// cleanMissingInfo keeps the first few missing details, each on one line
+func cleanMissingInfo(missing []string) []string {
+	var cleaned []string
+	for _, item := range missing {
+		item = strings.Join(strings.Fields(item), " ")
+		if item == "" {
+			continue
+		}
+		if len(cleaned) == maxMissingInfo {
+			break
+		}
+		cleaned = append(cleaned, utils.TruncateText(item, maxMissingInfoLength))
+	}
+	return cleaned
+}
+
 // parseSummaryResponse parses the AI response into a structured summary
 func (s *Summarizer) parseSummaryResponse(response string) (*IssueSummary, error) {
 	// Clean the response
@@ -559,24 +957,35 @@ func (s *Summarizer) parseSummaryResponse(response string) (*IssueSummary, error
 	}
 	response = strings.TrimSpace(response)
 
-	// Parse JSON response
+	// Check the response against the schema before decoding it, so errors
+	// name the fields that are wrong
+	if err := validateSummaryResponse([]byte(response)); err != nil {
+		return nil, err
+	}
 	var summary IssueSummary
 	if err := json.Unmarshal([]byte(response), &summary); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON response: %w", err)
 	}
-
-	// Validate required fields
-	if summary.Title == "" || summary.Summary == "" {
-		return nil, fmt.Errorf("missing required fields in AI response")
+	summary.SchemaVersion = SummarySchemaVersion
+
+	// Map the priority and category onto the taxonomy, falling back for
+	// answers that match nothing
+	taxonomy := s.currentTaxonomy()
+	priority, ok := taxonomy.Priority(summary.Priority)
+	if !ok && summary.Priority != "" {
+		s.logger.Warn("Unknown priority in AI response, using fallback",
+			zap.String("priority", summary.Priority),
+			zap.String("fallback", priority.Name))
 	}
+	category, ok := taxonomy.Category(summary.Category)
+	if !ok && summary.Category != "" {
+		s.logger.Warn("Unknown category in AI response, using fallback",
+			zap.String("category", summary.Category),
+			zap.String("fallback", category.Name))
+	}
+	summary.Priority, summary.Category = priority.Name, category.Name
 
 	// Set defaults for optional fields
-	if summary.Priority == "" {
-		summary.Priority = "medium"
-	}
-	if summary.Category == "" {
-		summary.Category = "other"
-	}
 	if summary.ActionItems == nil {
 		summary.ActionItems = []string{}
 	}
@@ -589,102 +998,269 @@ func (s *Summarizer) parseSummaryResponse(response string) (*IssueSummary, error
 	if summary.SuggestedFix == "" {
 		summary.SuggestedFix = "No fix suggestion provided."
 	}
+	summary.SuggestedFix = s.screenFix(summary.SuggestedFix)
+	summary.MissingInfo = emptyIfNil(cleanMissingInfo(summary.MissingInfo))
+	summary.Reasoning = emptyIfNil(summary.Reasoning)
 	return &summary, nil
 }
 
-// GenerateSlackMessage generates a Slack message from the issue summary
-func (s *Summarizer) GenerateSlackMessage(issueData *gh.IssueData, summary *IssueSummary) map[string]interface{} {
-	// Priority emoji mapping
-	priorityEmoji := map[string]string{
-		"high":   "🔴",
-		"medium": "🟡",
-		"low":    "🟢",
+// FormatExplanation renders the signals behind a summary's priority and
+// confidence, for the reply to the "Why?" button
+func FormatExplanation(summary *IssueSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":thinking_face: *Why %s priority?* (%s, %.0f%% confidence)\n",
+		summary.Priority, summary.Category, summary.Confidence*100)
+	if len(summary.Reasoning) == 0 {
+		b.WriteString("The AI gave no reasoning for this summary.\n")
 	}
-
-	// Category emoji mapping
-	categoryEmoji := map[string]string{
-		"bug":            "🐛",
-		"feature":        "✨",
-		"enhancement":    "🚀",
-		"documentation":  "📚",
-		"security":       "🔒",
-		"performance":    "⚡",
-		"infrastructure": "🏗️",
-		"other":          "📋",
+	for _, signal := range summary.Reasoning {
+		fmt.Fprintf(&b, "• %s\n", signal)
+	}
+	if summary.BoostedFrom != "" {
+		fmt.Fprintf(&b, "• Raised from %s because many people reacted with 👍\n", summary.BoostedFrom)
+	}
+	if summary.EscalatedBy != "" {
+		fmt.Fprintf(&b, "• Escalated when the `%s` label was added\n", summary.EscalatedBy)
 	}
+	if summary.Triage {
+		b.WriteString("_Only the quick triage ran; a deep analysis may weigh the issue differently._\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
 
-	emoji := priorityEmoji[summary.Priority]
-	if emoji == "" {
-		emoji = "📋"
+// FormatLinkedIssues renders the issues an issue references, for the reply
+// to the "Linked Issues" button. Issues still being fetched are listed by
+// reference only.
+func FormatLinkedIssues(number int, issues []gh.LinkedIssue) string {
+	if len(issues) == 0 {
+		return fmt.Sprintf(":information_source: #%d references no other issues.", number)
 	}
 
-	catEmoji := categoryEmoji[summary.Category]
-	if catEmoji == "" {
-		catEmoji = "📋"
+	var b strings.Builder
+	fmt.Fprintf(&b, ":link: *Issues referenced by #%d*\n", number)
+	for _, issue := range issues {
+		if !issue.Cached {
+			fmt.Fprintf(&b, "• %s _(still loading, try again in a moment)_\n", issue.String())
+			continue
+		}
+		state := ":large_green_circle:"
+		if issue.State == "closed" {
+			state = ":white_circle:"
+		}
+		fmt.Fprintf(&b, "• %s <%s|%s>: %s\n", state, issue.URL, issue.String(), issue.Title)
+		if issue.Summary != "" {
+			fmt.Fprintf(&b, "    _%s_\n", issue.Summary)
+		}
 	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
 
-	// Build action items text
-	actionItemsText := "None specified"
-	if len(summary.ActionItems) > 0 {
-		actionItemsText = strings.Join(summary.ActionItems, "\n• ")
-		actionItemsText = "• " + actionItemsText
+// compactSummaryLength caps the one-line summary in the compact layout
+const compactSummaryLength = 150
+
+// priorityText renders a summary's priority, noting when a label or
+// reactions raised it
+func priorityText(summary *IssueSummary) string {
+	if summary.EscalatedBy != "" {
+		return fmt.Sprintf("%s (escalated by `%s`)", strings.Title(summary.Priority), summary.EscalatedBy)
+	}
+	if summary.BoostedFrom == "" {
+		return strings.Title(summary.Priority)
 	}
+	return fmt.Sprintf("%s (raised from %s by 👍)", strings.Title(summary.Priority), strings.Title(summary.BoostedFrom))
+}
+
+// summaryEmojis returns the priority and category emoji for a summary
+func (s *Summarizer) summaryEmojis(summary *IssueSummary) (string, string) {
+	return s.currentTaxonomy().Emojis(summary.Priority, summary.Category)
+}
+
+// GenerateCompactSlackMessage generates a single-section Slack message with the
+// title, priority, a one-line summary and a link, for high-volume channels
+func (s *Summarizer) GenerateCompactSlackMessage(issueData *gh.IssueData, summary *IssueSummary) map[string]interface{} {
+	emoji, catEmoji := s.summaryEmojis(summary)
 
-	// Safely get repository name
 	repoName := "Unknown Repository"
 	if issueData.Repository != nil {
 		repoName = issueData.Repository.GetFullName()
 	}
 
+	oneLine := utils.TruncateText(utils.FirstLine(utils.MarkdownToSlack(summary.Summary)), compactSummaryLength)
+
+	status := priorityText(summary)
+	if len(summary.Components) > 0 {
+		status += " · " + strings.Join(summary.Components, ", ")
+	}
+	if summary.Language != "" {
+		status += " · :globe_with_meridians: " + LanguageName(summary.Language)
+	}
+	if issueData.Issue.GetState() == "closed" {
+		status += " · :lock: Closed"
+	}
+
 	return map[string]interface{}{
+		"text": s.fallbackText(issueData, summary),
 		"blocks": []map[string]interface{}{
 			{
-				"type": "header",
+				"type": "section",
 				"text": map[string]interface{}{
-					"type": "plain_text",
-					"text": fmt.Sprintf("%s %s Issue #%d: %s", emoji, catEmoji, issueData.Issue.GetNumber(), summary.Title),
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("%s %s *<%s|%s#%d: %s>* · %s\n%s",
+						emoji, catEmoji,
+						issueData.Issue.GetHTMLURL(), repoName, issueData.Issue.GetNumber(), summary.Title,
+						status,
+						oneLine,
+					),
 				},
 			},
-			{
-				"type": "section",
-				"fields": []map[string]interface{}{
-					{
-						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Repository:*\n%s", repoName),
-					},
-					{
-						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Priority:*\n%s", strings.Title(summary.Priority)),
-					},
-					{
-						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Category:*\n%s", strings.Title(summary.Category)),
-					},
-					{
-						"type": "mrkdwn",
-						"text": fmt.Sprintf("*Confidence:*\n%.0f%%", summary.Confidence*100),
-					},
-				},
+		},
+	}
+}
+
+// GeneratePlainSlackMessage generates an accessible text-only Slack message
+// without emoji: labeled sentences in reading order instead of the detailed
+// layout's field grid and buttons, which screen readers announce out of
+// context
+func (s *Summarizer) GeneratePlainSlackMessage(issueData *gh.IssueData, summary *IssueSummary) map[string]interface{} {
+	repoName := "Unknown Repository"
+	if issueData.Repository != nil {
+		repoName = issueData.Repository.GetFullName()
+	}
+
+	priority := priorityText(summary)
+	if summary.EscalatedBy == "" && summary.BoostedFrom != "" {
+		priority = fmt.Sprintf("%s, raised from %s by upvotes", strings.Title(summary.Priority), strings.Title(summary.BoostedFrom))
+	}
+	details := []string{
+		fmt.Sprintf("Priority: %s.", priority),
+		fmt.Sprintf("Category: %s.", strings.Title(summary.Category)),
+	}
+	if len(summary.Components) > 0 {
+		details = append(details, fmt.Sprintf("Components: %s.", strings.Join(summary.Components, ", ")))
+	}
+	if assignees := assigneeLogins(issueData); len(assignees) > 0 {
+		details = append(details, fmt.Sprintf("Assigned to %s.", strings.Join(assignees, ", ")))
+	}
+	if milestone := issueData.Issue.GetMilestone().GetTitle(); milestone != "" {
+		details = append(details, fmt.Sprintf("Milestone: %s.", milestone))
+	}
+	closed := issueData.Issue.GetState() == "closed"
+	if closed {
+		details = append(details, "This issue has been closed.")
+	}
+
+	paragraphs := []string{
+		fmt.Sprintf("*<%s|%s#%d: %s>*", issueData.Issue.GetHTMLURL(), repoName, issueData.Issue.GetNumber(), utils.StripEmoji(summary.Title)),
+		strings.Join(details, " "),
+		"Summary: " + utils.MarkdownToSlack(summary.Summary),
+	}
+	if summary.Language != "" {
+		paragraphs = append(paragraphs, fmt.Sprintf("Translated from %s.", LanguageName(summary.Language)))
+	}
+	if !summary.Triage && len(summary.ActionItems) > 0 {
+		items := make([]string, len(summary.ActionItems))
+		for i, item := range summary.ActionItems {
+			items[i] = fmt.Sprintf("%d. %s", i+1, utils.MarkdownToSlack(item))
+		}
+		paragraphs = append(paragraphs, "Action items:\n"+strings.Join(items, "\n"))
+	}
+	if !summary.Triage && summary.Regression != "" {
+		paragraphs = append(paragraphs, "Possible regression: "+summary.Regression)
+	}
+	if suggestion := issueData.CloseSuggestion; suggestion != nil && !closed {
+		paragraphs = append(paragraphs, fmt.Sprintf("Suggested close (%s): %s", suggestion.Reason, suggestion.Explanation))
+	}
+
+	blocks := make([]map[string]interface{}, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{
+				"type": "mrkdwn",
+				"text": utils.StripEmoji(paragraph),
 			},
+		})
+	}
+	return map[string]interface{}{
+		"text":   utils.StripEmoji(s.fallbackText(issueData, summary)),
+		"blocks": blocks,
+	}
+}
+
+// GenerateSkippedSlackMessage creates a minimal note for an issue that was
+// not sent to the AI, e.g. because the pre-filter found it trivial
+func (s *Summarizer) GenerateSkippedSlackMessage(issueData *gh.IssueData, reason string) map[string]interface{} {
+	repoName := "Unknown Repository"
+	if issueData.Repository != nil {
+		repoName = issueData.Repository.GetFullName()
+	}
+
+	status := "Not analyzed"
+	if issueData.Issue.GetState() == "closed" {
+		status += " · :lock: Closed"
+	}
+
+	return map[string]interface{}{
+		"text": s.fallbackText(issueData, nil),
+		"blocks": []map[string]interface{}{
 			{
 				"type": "section",
 				"text": map[string]interface{}{
 					"type": "mrkdwn",
-					"text": fmt.Sprintf("*Summary:*\n%s", summary.Summary),
+					"text": fmt.Sprintf(":zzz: *<%s|%s#%d: %s>* · %s\nSkipped AI analysis because %s.",
+						issueData.Issue.GetHTMLURL(), repoName, issueData.Issue.GetNumber(),
+						utils.TruncateText(issueData.Issue.GetTitle(), compactSummaryLength),
+						status, reason,
+					),
 				},
 			},
+		},
+	}
+}
+
+// GenerateDegradedSlackMessage generates a message for an issue the AI failed
+// to summarize, with the issue's title, author and labels and a button to try
+// the summary again, so the issue still reaches Slack
+func (s *Summarizer) GenerateDegradedSlackMessage(issueData *gh.IssueData, reason string) map[string]interface{} {
+	repoName := "Unknown Repository"
+	if issueData.Repository != nil {
+		repoName = issueData.Repository.GetFullName()
+	}
+	number := issueData.Issue.GetNumber()
+
+	details := []string{"Opened by @" + issueData.Issue.GetUser().GetLogin()}
+	if labels := issueData.Issue.Labels; len(labels) > 0 {
+		names := make([]string, 0, len(labels))
+		for _, label := range labels {
+			names = append(names, "`"+label.GetName()+"`")
+		}
+		details = append(details, strings.Join(names, " "))
+	}
+	if issueData.Issue.GetState() == "closed" {
+		details = append(details, ":lock: Closed")
+	}
+
+	return map[string]interface{}{
+		"text": s.fallbackText(issueData, nil),
+		"blocks": []map[string]interface{}{
 			{
 				"type": "section",
 				"text": map[string]interface{}{
 					"type": "mrkdwn",
-					"text": fmt.Sprintf("*Action Items:*\n%s", actionItemsText),
+					"text": fmt.Sprintf("*<%s|%s#%d: %s>*\n%s",
+						issueData.Issue.GetHTMLURL(), repoName, number,
+						utils.TruncateText(issueData.Issue.GetTitle(), compactSummaryLength),
+						strings.Join(details, " · "),
+					),
 				},
 			},
 			{
-				"type": "section",
-				"text": map[string]interface{}{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("*Code Context:*\n%s", summary.CodeContext),
+				"type": "context",
+				"elements": []map[string]interface{}{
+					{
+						"type": "mrkdwn",
+						"text": fmt.Sprintf(":warning: No AI summary: %s.", reason),
+					},
 				},
 			},
 			{
@@ -697,18 +1273,17 @@ func (s *Summarizer) GenerateSlackMessage(issueData *gh.IssueData, summary *Issu
 							"text": "Review Issue",
 						},
 						"action_id": "review_issue",
-						"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
-						"style":     "primary",
+						"value":     fmt.Sprintf("%s:%d", repoName, number),
 						"url":       issueData.Issue.GetHTMLURL(),
 					},
 					{
 						"type": "button",
 						"text": map[string]interface{}{
 							"type": "plain_text",
-							"text": "Suggest Fix",
+							"text": "Retry AI summary",
 						},
-						"action_id": "suggest_fix",
-						"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+						"action_id": "retry_summary",
+						"value":     fmt.Sprintf("%s:%d", repoName, number),
 						"style":     "primary",
 					},
 				},
@@ -716,3 +1291,393 @@ func (s *Summarizer) GenerateSlackMessage(issueData *gh.IssueData, summary *Issu
 		},
 	}
 }
+
+// regressionText links the release and commit a regression hint names
+func regressionText(text string, hints []gh.RegressionHint) string {
+	for _, hint := range hints {
+		if !strings.Contains(text, hint.Commit) {
+			continue
+		}
+		if hint.CommitURL != "" {
+			text = strings.Replace(text, hint.Commit, fmt.Sprintf("<%s|%s>", hint.CommitURL, hint.Commit), 1)
+		}
+		if hint.ReleaseURL != "" && strings.Contains(text, hint.Release) {
+			text = strings.Replace(text, hint.Release, fmt.Sprintf("<%s|%s>", hint.ReleaseURL, hint.Release), 1)
+		}
+		break
+	}
+	return text
+}
+
+// assigneeLogins returns the logins of everyone assigned to an issue, as
+// Slack mentions for assignees mapped to Slack users
+func assigneeLogins(issueData *gh.IssueData) []string {
+	var logins []string
+	for _, login := range gh.AssigneeLogins(issueData.Issue) {
+		if userID := issueData.Mentions[login]; userID != "" {
+			logins = append(logins, fmt.Sprintf("<@%s>", userID))
+		} else {
+			logins = append(logins, "@"+login)
+		}
+	}
+	return logins
+}
+
+// GenerateSlackMessage generates a Slack message from the issue summary
+func (s *Summarizer) GenerateSlackMessage(issueData *gh.IssueData, summary *IssueSummary) map[string]interface{} {
+	emoji, catEmoji := s.summaryEmojis(summary)
+
+	// Prefer the configured template, falling back to the built-in layout
+	s.mu.RLock()
+	tmpl := s.slackTemplate
+	s.mu.RUnlock()
+	if tmpl != nil {
+		message, err := tmpl.Render(newSlackTemplateData(issueData, summary, emoji, catEmoji))
+		if err == nil {
+			if text, _ := message["text"].(string); text == "" {
+				message["text"] = s.fallbackText(issueData, summary)
+			}
+			return message
+		}
+		s.logger.Error("Failed to render Slack template, using default layout", zap.Error(err))
+	}
+
+	// Build action items text
+	actionItemsText := "None specified"
+	if len(summary.ActionItems) > 0 {
+		items := make([]string, len(summary.ActionItems))
+		for i, item := range summary.ActionItems {
+			items[i] = utils.MarkdownToSlack(item)
+		}
+		actionItemsText = "• " + strings.Join(items, "\n• ")
+	}
+
+	// Safely get repository name
+	repoName := "Unknown Repository"
+	if issueData.Repository != nil {
+		repoName = issueData.Repository.GetFullName()
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": fmt.Sprintf("%s %s Issue #%d: %s", emoji, catEmoji, issueData.Issue.GetNumber(), summary.Title),
+			},
+		},
+		{
+			"type": "section",
+			"fields": []map[string]interface{}{
+				{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("*Repository:*\n%s", repoName),
+				},
+				{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("*Priority:*\n%s", priorityText(summary)),
+				},
+				{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("*Category:*\n%s", strings.Title(summary.Category)),
+				},
+				{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("*Confidence:*\n%.0f%%", summary.Confidence*100),
+				},
+			},
+		},
+	}
+	if len(summary.Components) > 0 {
+		fields := blocks[1]["fields"].([]map[string]interface{})
+		blocks[1]["fields"] = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*Components:*\n%s", strings.Join(summary.Components, ", ")),
+		})
+	}
+	if assignees := assigneeLogins(issueData); len(assignees) > 0 {
+		fields := blocks[1]["fields"].([]map[string]interface{})
+		blocks[1]["fields"] = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*Assignee:*\n%s", strings.Join(assignees, ", ")),
+		})
+	}
+	if milestone := issueData.Issue.GetMilestone().GetTitle(); milestone != "" {
+		fields := blocks[1]["fields"].([]map[string]interface{})
+		blocks[1]["fields"] = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*Milestone:*\n%s", milestone),
+		})
+	}
+
+	// Issue age, maintainer engagement, reporter history and reactions
+	if issueData.Activity != nil {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{
+				"type": "mrkdwn",
+				"text": "*Context:*\n" + strings.Join(issueData.Activity.Lines(), " · "),
+			},
+		})
+	}
+
+	// Reported issue form fields, or the environment mentioned in a
+	// free-form issue; forms already show their environment fields
+	if len(issueData.FormFields) > 0 {
+		blocks = append(blocks, map[string]interface{}{
+			"type":   "section",
+			"fields": formFieldBlocks(issueData.FormFields),
+		})
+	} else if environment := issueData.Environment.Fields(); len(environment) > 0 {
+		blocks = append(blocks, map[string]interface{}{
+			"type":   "section",
+			"fields": formFieldBlocks(environment),
+		})
+	}
+
+	blocks = append(blocks, map[string]interface{}{
+		"type": "section",
+		"text": map[string]interface{}{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*Summary:*\n%s", utils.MarkdownToSlack(summary.Summary)),
+		},
+	})
+
+	// Show what the reporter actually wrote next to the English summary
+	if summary.Language != "" {
+		original := fmt.Sprintf(":globe_with_meridians: Translated from %s", LanguageName(summary.Language))
+		if summary.Original != "" {
+			original += fmt.Sprintf("\n>%s", summary.Original)
+		}
+		blocks = append(blocks, map[string]interface{}{
+			"type": "context",
+			"elements": []map[string]interface{}{
+				{
+					"type": "mrkdwn",
+					"text": original,
+				},
+			},
+		})
+	}
+
+	// Triage-only summaries have no deep analysis to show yet
+	if !summary.Triage {
+		blocks = append(blocks,
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("*Action Items:*\n%s", actionItemsText),
+				},
+			},
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("*Code Context:*\n%s", utils.MarkdownToSlack(summary.CodeContext)),
+				},
+			},
+		)
+		if summary.Regression != "" {
+			blocks = append(blocks, map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{
+					"type": "mrkdwn",
+					"text": fmt.Sprintf("*Possible Regression:*\n%s", regressionText(summary.Regression, issueData.RegressionHints)),
+				},
+			})
+		}
+	}
+
+	actions := []map[string]interface{}{
+		{
+			"type": "button",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": "Review Issue",
+			},
+			"action_id": "review_issue",
+			"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+			"style":     "primary",
+			"url":       issueData.Issue.GetHTMLURL(),
+		},
+		{
+			"type": "button",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": "Suggest Fix",
+			},
+			"action_id": "suggest_fix",
+			"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+			"style":     "primary",
+		},
+		{
+			"type": "button",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": "Re-summarize…",
+			},
+			"action_id": "resummarize",
+			"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+		},
+	}
+
+	if len(summary.Reasoning) > 0 {
+		actions = append(actions, map[string]interface{}{
+			"type": "button",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": "Why?",
+			},
+			"action_id": "explain_summary",
+			"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+		})
+	}
+
+	if len(issueData.Links) > 0 {
+		actions = append(actions, map[string]interface{}{
+			"type": "button",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": fmt.Sprintf("Linked Issues (%d)", len(issueData.Links)),
+			},
+			"action_id": "linked_issues",
+			"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+		})
+	}
+
+	if summary.Triage {
+		actions = append(actions, map[string]interface{}{
+			"type": "button",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": "Deep Analysis",
+			},
+			"action_id": "deep_analysis",
+			"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+		})
+	}
+
+	closed := issueData.Issue.GetState() == "closed"
+	s.mu.RLock()
+	assignButton := s.assignButton
+	s.mu.RUnlock()
+	if assignButton && !closed && issueData.Source == "" {
+		actions = append(actions, map[string]interface{}{
+			"type": "button",
+			"text": map[string]interface{}{
+				"type": "plain_text",
+				"text": "Assign to Me",
+			},
+			"action_id": "assign_issue",
+			"value":     fmt.Sprintf("%s:%d", repoName, issueData.Issue.GetNumber()),
+		})
+	}
+
+	// Offer to close issues that already look resolved or duplicated
+	if suggestion := issueData.CloseSuggestion; suggestion != nil && !closed {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{
+				"type": "mrkdwn",
+				"text": fmt.Sprintf("*Suggested Close (%s):*\n%s", suggestion.Reason, suggestion.Explanation),
+			},
+		})
+		actions = append(actions, closeIssueButton(repoName, issueData.Issue.GetNumber(), suggestion))
+	}
+
+	// Mark messages for issues that have since been closed
+	if closed {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "context",
+			"elements": []map[string]interface{}{
+				{
+					"type": "mrkdwn",
+					"text": ":lock: This issue has been closed",
+				},
+			},
+		})
+	}
+
+	blocks = append(blocks, map[string]interface{}{
+		"type":     "actions",
+		"elements": actions,
+	})
+
+	return map[string]interface{}{"text": s.fallbackText(issueData, summary), "blocks": blocks}
+}
+
+// fallbackText summarizes an issue in one line, e.g. "High priority bug in
+// acme/api#42: Login fails — Tokens expire at once". Slack shows a message's
+// text in notifications, and screen readers read it instead of the blocks.
+// summary is nil for issues that were not analyzed.
+func (s *Summarizer) fallbackText(issueData *gh.IssueData, summary *IssueSummary) string {
+	repoName := "Unknown Repository"
+	if issueData.Repository != nil {
+		repoName = issueData.Repository.GetFullName()
+	}
+	number := issueData.Issue.GetNumber()
+	if summary == nil {
+		return utils.TruncateText(fmt.Sprintf("Issue in %s#%d: %s (not analyzed)", repoName, number, issueData.Issue.GetTitle()), maxFallbackText)
+	}
+
+	s.mu.RLock()
+	tmpl := s.fallbackTemplate
+	s.mu.RUnlock()
+	if tmpl != nil {
+		emoji, catEmoji := s.summaryEmojis(summary)
+		text, err := tmpl.Render(newSlackTemplateData(issueData, summary, emoji, catEmoji))
+		if err == nil {
+			return text
+		}
+		s.logger.Error("Failed to render fallback template, using default text", zap.Error(err))
+	}
+
+	text := fmt.Sprintf("%s priority %s in %s#%d: %s", strings.Title(summary.Priority), summary.Category, repoName, number, summary.Title)
+	if issueData.Issue.GetState() == "closed" {
+		text += " (closed)"
+	}
+	if line := utils.FirstLine(summary.Summary); line != "" {
+		text += " — " + line
+	}
+	return utils.TruncateText(text, maxFallbackText)
+}
+
+// maxFormFields is Slack's limit on fields in a section block
+const maxFormFields = 10
+
+// formFieldBlocks renders issue form fields as labeled Slack section fields
+func formFieldBlocks(formFields []gh.FormField) []map[string]interface{} {
+	var fields []map[string]interface{}
+	for i, field := range formFields {
+		if i >= maxFormFields {
+			break
+		}
+		fields = append(fields, map[string]interface{}{
+			"type": "mrkdwn",
+			"text": utils.TruncateText(fmt.Sprintf("*%s:*\n%s", field.Label, field.Value), 300),
+		})
+	}
+	return fields
+}
+
+// closeIssueButton builds the "Close as ..." button. Slack asks the user to
+// confirm before the click is delivered.
+func closeIssueButton(repoName string, number int, suggestion *gh.CloseSuggestion) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "button",
+		"text": map[string]interface{}{
+			"type": "plain_text",
+			"text": fmt.Sprintf("Close as %s", suggestion.Reason),
+		},
+		"action_id": "close_issue",
+		"value":     fmt.Sprintf("%s:%d:%s", repoName, number, suggestion.Reason),
+		"style":     "danger",
+		"confirm": map[string]interface{}{
+			"title":   "Close issue?",
+			"text":    fmt.Sprintf("This posts a comment on #%d explaining why and closes it as %s.", number, suggestion.Reason),
+			"confirm": "Close issue",
+			"deny":    "Cancel",
+		},
+	}
+}
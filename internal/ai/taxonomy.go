@@ -0,0 +1,390 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultEmoji is shown for priorities and categories without their own emoji
+const defaultEmoji = "📋"
+
+// TaxonomyEntry is a priority or category the AI can assign
+type TaxonomyEntry struct {
+	Name        string   `mapstructure:"name" json:"name"`
+	Description string   `mapstructure:"description" json:"description,omitempty"` // Tells the AI when to use the entry
+	Emoji       string   `mapstructure:"emoji" json:"emoji,omitempty"`
+	Aliases     []string `mapstructure:"aliases" json:"aliases,omitempty"` // Other names the AI may answer with, e.g. "docs"
+	Labels      []string `mapstructure:"labels" json:"labels,omitempty"`   // GitHub labels added when auto-labeling
+}
+
+// TaxonomyConfig defines the priorities and categories summaries are
+// classified into. Empty lists keep the defaults.
+type TaxonomyConfig struct {
+	Priorities       []TaxonomyEntry `mapstructure:"priorities" json:"priorities,omitempty"`
+	Categories       []TaxonomyEntry `mapstructure:"categories" json:"categories,omitempty"`
+	FallbackPriority string          `mapstructure:"fallback_priority" json:"fallback_priority,omitempty"` // For answers that match no priority, defaults to medium
+	FallbackCategory string          `mapstructure:"fallback_category" json:"fallback_category,omitempty"` // For answers that match no category, defaults to other
+}
+
+// Taxonomy validates the priorities and categories in AI answers and
+// describes how they are rendered and labeled
+type Taxonomy struct {
+	priorities       []TaxonomyEntry
+	categories       []TaxonomyEntry
+	fallbackPriority TaxonomyEntry
+	fallbackCategory TaxonomyEntry
+}
+
+// defaultPriorities are the built-in priorities, highest first
+var defaultPriorities = []TaxonomyEntry{
+	{Name: "critical", Description: "Outage, data loss or an exploitable security hole; needs attention now", Emoji: "🚨"},
+	{Name: "high", Description: "Major functionality broken or many users affected", Emoji: "🔴"},
+	{Name: "medium", Description: "Noticeable problem with a workaround, or a valuable improvement", Emoji: "🟡"},
+	{Name: "low", Description: "Minor issue, cosmetic problem or nice-to-have", Emoji: "🟢"},
+}
+
+// defaultCategories are the built-in categories
+var defaultCategories = []TaxonomyEntry{
+	{Name: "bug", Description: "Something does not work as intended", Emoji: "🐛", Aliases: []string{"defect", "regression", "crash"}},
+	{Name: "feature", Description: "A new capability", Emoji: "✨", Aliases: []string{"feature-request"}},
+	{Name: "enhancement", Description: "An improvement to an existing capability", Emoji: "🚀", Aliases: []string{"improvement"}},
+	{Name: "documentation", Description: "Docs, examples or comments", Emoji: "📚", Aliases: []string{"docs", "doc"}},
+	{Name: "security", Description: "Vulnerabilities, credentials or access control", Emoji: "🔒", Aliases: []string{"vulnerability"}},
+	{Name: "performance", Description: "Speed, memory or resource usage", Emoji: "⚡", Aliases: []string{"perf"}},
+	{Name: "infrastructure", Description: "Build, CI/CD, deployment or hosting", Emoji: "🏗️", Aliases: []string{"ci", "devops", "build"}},
+	{Name: "architecture", Description: "System design and structure"},
+	{Name: "technical-debt", Description: "Refactoring, cleanup or outdated dependencies", Aliases: []string{"tech-debt", "refactor"}},
+	{Name: "other", Description: "Anything else"},
+}
+
+// DefaultTaxonomy returns the built-in priorities and categories
+func DefaultTaxonomy() *Taxonomy {
+	taxonomy, err := NewTaxonomy(TaxonomyConfig{})
+	if err != nil {
+		// The defaults are valid, so this cannot happen
+		panic(err)
+	}
+	return taxonomy
+}
+
+// NewTaxonomy validates the configuration and creates a taxonomy. Priority
+// names must be critical, high, medium or low, since thresholds such as
+// DEEP_ANALYSIS_PRIORITY compare them; their descriptions, emojis, aliases and
+// labels can be customized.
+func NewTaxonomy(config TaxonomyConfig) (*Taxonomy, error) {
+	priorities := config.Priorities
+	if len(priorities) == 0 {
+		priorities = defaultPriorities
+	}
+	categories := config.Categories
+	if len(categories) == 0 {
+		categories = defaultCategories
+	}
+
+	priorities, err := normalizeEntries("priority", priorities)
+	if err != nil {
+		return nil, err
+	}
+	for _, priority := range priorities {
+		if _, ok := priorityRanks[priority.Name]; !ok {
+			return nil, fmt.Errorf("priority %q: must be one of critical, high, medium or low", priority.Name)
+		}
+	}
+	// Highest first, so prompts list them in order of severity
+	sort.SliceStable(priorities, func(i, j int) bool {
+		return priorityRanks[priorities[i].Name] > priorityRanks[priorities[j].Name]
+	})
+
+	categories, err = normalizeEntries("category", categories)
+	if err != nil {
+		return nil, err
+	}
+
+	taxonomy := &Taxonomy{priorities: priorities, categories: categories}
+	if taxonomy.fallbackPriority, err = fallbackEntry("priority", priorities, config.FallbackPriority, "medium"); err != nil {
+		return nil, err
+	}
+	if taxonomy.fallbackCategory, err = fallbackEntry("category", categories, config.FallbackCategory, "other"); err != nil {
+		return nil, err
+	}
+	return taxonomy, nil
+}
+
+// normalizeEntries copies the entries with normalized names and aliases, and
+// rejects empty and duplicate names
+func normalizeEntries(kind string, entries []TaxonomyEntry) ([]TaxonomyEntry, error) {
+	seen := make(map[string]string)
+	normalized := make([]TaxonomyEntry, 0, len(entries))
+	for i, entry := range entries {
+		entry.Name = normalizeTaxonomyName(entry.Name)
+		if entry.Name == "" {
+			return nil, fmt.Errorf("%s %d: name is required", kind, i)
+		}
+		aliases := make([]string, 0, len(entry.Aliases))
+		for _, alias := range entry.Aliases {
+			if alias = normalizeTaxonomyName(alias); alias != "" {
+				aliases = append(aliases, alias)
+			}
+		}
+		entry.Aliases = aliases
+
+		for _, name := range append([]string{entry.Name}, aliases...) {
+			if other, ok := seen[name]; ok {
+				return nil, fmt.Errorf("%s %q: %q is already used by %q", kind, entry.Name, name, other)
+			}
+			seen[name] = entry.Name
+		}
+		normalized = append(normalized, entry)
+	}
+	return normalized, nil
+}
+
+// fallbackEntry finds the configured fallback. Without one, preferred is used
+// if it exists, or else the last entry.
+func fallbackEntry(kind string, entries []TaxonomyEntry, configured, preferred string) (TaxonomyEntry, error) {
+	if configured != "" {
+		if entry, ok := findEntry(entries, normalizeTaxonomyName(configured)); ok {
+			return entry, nil
+		}
+		return TaxonomyEntry{}, fmt.Errorf("fallback %s %q is not defined", kind, configured)
+	}
+	if entry, ok := findEntry(entries, preferred); ok {
+		return entry, nil
+	}
+	return entries[len(entries)-1], nil
+}
+
+// findEntry returns the entry with the name or alias
+func findEntry(entries []TaxonomyEntry, name string) (TaxonomyEntry, bool) {
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, true
+		}
+		for _, alias := range entry.Aliases {
+			if alias == name {
+				return entry, true
+			}
+		}
+	}
+	return TaxonomyEntry{}, false
+}
+
+// Priorities returns the priorities, highest first
+func (t *Taxonomy) Priorities() []TaxonomyEntry {
+	return t.priorities
+}
+
+// Categories returns the categories in configuration order
+func (t *Taxonomy) Categories() []TaxonomyEntry {
+	return t.categories
+}
+
+// Raise returns the configured priority one level above priority. The
+// highest and unknown priorities are returned unchanged.
+func (t *Taxonomy) Raise(priority string) string {
+	for i, entry := range t.priorities {
+		if entry.Name == normalizeTaxonomyName(priority) {
+			if i == 0 {
+				return priority
+			}
+			return t.priorities[i-1].Name
+		}
+	}
+	return priority
+}
+
+// Priority resolves an AI answer to a priority. Answers that match no
+// priority, even loosely, resolve to the fallback and report false.
+func (t *Taxonomy) Priority(answer string) (TaxonomyEntry, bool) {
+	if entry, ok := matchEntry(t.priorities, answer); ok {
+		return entry, true
+	}
+	return t.fallbackPriority, false
+}
+
+// Category resolves an AI answer to a category. Answers that match no
+// category, even loosely, resolve to the fallback and report false.
+func (t *Taxonomy) Category(answer string) (TaxonomyEntry, bool) {
+	if entry, ok := matchEntry(t.categories, answer); ok {
+		return entry, true
+	}
+	return t.fallbackCategory, false
+}
+
+// Emojis returns the emojis for a priority and category
+func (t *Taxonomy) Emojis(priority, category string) (string, string) {
+	priorityEmoji, categoryEmoji := defaultEmoji, defaultEmoji
+	if entry, ok := findEntry(t.priorities, normalizeTaxonomyName(priority)); ok && entry.Emoji != "" {
+		priorityEmoji = entry.Emoji
+	}
+	if entry, ok := findEntry(t.categories, normalizeTaxonomyName(category)); ok && entry.Emoji != "" {
+		categoryEmoji = entry.Emoji
+	}
+	return priorityEmoji, categoryEmoji
+}
+
+// Labels returns the GitHub labels for a priority and category
+func (t *Taxonomy) Labels(priority, category string) []string {
+	var labels []string
+	if entry, ok := findEntry(t.priorities, normalizeTaxonomyName(priority)); ok {
+		labels = append(labels, entry.Labels...)
+	}
+	if entry, ok := findEntry(t.categories, normalizeTaxonomyName(category)); ok {
+		labels = append(labels, entry.Labels...)
+	}
+	return labels
+}
+
+// promptValues lists the entry names for the JSON format in prompts, e.g.
+// "high|medium|low"
+func promptValues(entries []TaxonomyEntry) string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	return strings.Join(names, "|")
+}
+
+// promptDefinitions describes the priorities and categories that have a
+// description, so the AI applies them consistently
+func (t *Taxonomy) promptDefinitions() string {
+	var parts []string
+	for _, group := range []struct {
+		title   string
+		entries []TaxonomyEntry
+	}{
+		{"Priorities", t.priorities},
+		{"Categories", t.categories},
+	} {
+		var lines []string
+		for _, entry := range group.entries {
+			if entry.Description != "" {
+				lines = append(lines, fmt.Sprintf("- %s: %s", entry.Name, entry.Description))
+			}
+		}
+		if len(lines) > 0 {
+			parts = append(parts, group.title+":\n"+strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// normalizeTaxonomyName lowercases a name and joins its words with hyphens,
+// so "Technical Debt" and "technical_debt" match technical-debt
+func normalizeTaxonomyName(name string) string {
+	name = strings.ToLower(strings.Trim(strings.TrimSpace(name), `"'.`))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "-")
+}
+
+// matchEntry matches an answer to an entry by name or alias, then by prefix,
+// then allowing a small number of typos. Answers that echo several options,
+// such as "bug|feature", match their first option that fits.
+func matchEntry(entries []TaxonomyEntry, answer string) (TaxonomyEntry, bool) {
+	options := strings.FieldsFunc(answer, func(r rune) bool {
+		return r == '|' || r == ',' || r == '/'
+	})
+	for _, match := range []func([]TaxonomyEntry, string) (TaxonomyEntry, bool){findEntry, prefixEntry, closestEntry} {
+		for _, option := range options {
+			option = normalizeTaxonomyName(option)
+			if option == "" {
+				continue
+			}
+			if entry, ok := match(entries, option); ok {
+				return entry, true
+			}
+		}
+	}
+	return TaxonomyEntry{}, false
+}
+
+// minPrefixLength is the shortest answer matched as a prefix
+const minPrefixLength = 3
+
+// prefixEntry matches an answer that abbreviates a name or alias, such as
+// "perf", or extends one, such as "security-vulnerability". The longest
+// matching name wins.
+func prefixEntry(entries []TaxonomyEntry, answer string) (TaxonomyEntry, bool) {
+	var best TaxonomyEntry
+	bestLength := 0
+	for _, entry := range entries {
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if len(name) < minPrefixLength || len(answer) < minPrefixLength {
+				continue
+			}
+			if (strings.HasPrefix(name, answer) || strings.HasPrefix(answer, name+"-")) && len(name) > bestLength {
+				best, bestLength = entry, len(name)
+			}
+		}
+	}
+	return best, bestLength > 0
+}
+
+// closestEntry matches an answer within a few typos of a name or alias, e.g.
+// "perfomance". One edit is allowed per four characters.
+func closestEntry(entries []TaxonomyEntry, answer string) (TaxonomyEntry, bool) {
+	var best TaxonomyEntry
+	bestDistance := -1
+	for _, entry := range entries {
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			distance := editDistance(name, answer)
+			if distance > len(name)/4 {
+				continue
+			}
+			if bestDistance < 0 || distance < bestDistance {
+				best, bestDistance = entry, distance
+			}
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// FromLabels finds the priority and category that GitHub labels assign,
+// matching the configured labels first and then names and aliases, so a plain
+// "bug" label counts too. Empty results mean no label matched.
+func (t *Taxonomy) FromLabels(labels []string) (string, string) {
+	return labelEntry(t.priorities, labels), labelEntry(t.categories, labels)
+}
+
+// labelEntry returns the name of the first entry one of the labels assigns
+func labelEntry(entries []TaxonomyEntry, labels []string) string {
+	for _, label := range labels {
+		for _, entry := range entries {
+			for _, entryLabel := range entry.Labels {
+				if strings.EqualFold(label, entryLabel) {
+					return entry.Name
+				}
+			}
+		}
+	}
+	for _, label := range labels {
+		if entry, ok := findEntry(entries, normalizeTaxonomyName(label)); ok {
+			return entry.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// SlackTemplate renders Slack messages from a user-supplied Go template. The
+// template must produce JSON: either {"blocks": [...]} or a bare block array.
+type SlackTemplate struct {
+	tmpl *template.Template
+}
+
+// SlackTemplateData is the data available to Slack message templates
+type SlackTemplateData struct {
+	Repository    string
+	IssueNumber   int
+	IssueTitle    string
+	IssueURL      string
+	IssueState    string
+	Author        string
+	Assignee      string
+	Milestone     string
+	Labels        []string
+	FormFields    []gh.FormField
+	Action        string
+	EventType     string
+	PriorityEmoji string
+	CategoryEmoji string
+	Summary       *IssueSummary
+
+	Activity        *gh.IssueActivity   // Age, maintainer engagement, reporter history and reactions; may be nil
+	CloseSuggestion *gh.CloseSuggestion // Nil unless the issue looks resolved or duplicated
+}
+
+// slackTemplateFuncs are the helper functions available to templates
+var slackTemplateFuncs = template.FuncMap{
+	// json encodes a value as a JSON literal, so strings are quoted and escaped
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"title": strings.Title,
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"percent": func(f float64) string {
+		return fmt.Sprintf("%.0f%%", f*100)
+	},
+}
+
+// ParseSlackTemplate parses a Slack message template
+func ParseSlackTemplate(name, text string) (*SlackTemplate, error) {
+	tmpl, err := template.New(name).Funcs(slackTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse slack template %s: %w", name, err)
+	}
+	return &SlackTemplate{tmpl: tmpl}, nil
+}
+
+// LoadSlackTemplate reads and parses a Slack message template file
+func LoadSlackTemplate(path string) (*SlackTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read slack template: %w", err)
+	}
+	return ParseSlackTemplate(path, string(data))
+}
+
+// Render executes the template and decodes the result into a Slack message
+func (t *SlackTemplate) Render(data SlackTemplateData) (map[string]interface{}, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render slack template: %w", err)
+	}
+
+	output := bytes.TrimSpace(buf.Bytes())
+	if bytes.HasPrefix(output, []byte("[")) {
+		var blocks []interface{}
+		if err := json.Unmarshal(output, &blocks); err != nil {
+			return nil, fmt.Errorf("slack template did not produce valid JSON: %w", err)
+		}
+		return map[string]interface{}{"blocks": blocks}, nil
+	}
+
+	var message map[string]interface{}
+	if err := json.Unmarshal(output, &message); err != nil {
+		return nil, fmt.Errorf("slack template did not produce valid JSON: %w", err)
+	}
+	if _, ok := message["blocks"]; !ok {
+		return nil, fmt.Errorf("slack template output is missing blocks")
+	}
+	return message, nil
+}
+
+// newSlackTemplateData collects the template fields for an issue summary
+func newSlackTemplateData(issueData *gh.IssueData, summary *IssueSummary, priorityEmoji, categoryEmoji string) SlackTemplateData {
+	data := SlackTemplateData{
+		Repository:    "Unknown Repository",
+		IssueNumber:   issueData.Issue.GetNumber(),
+		IssueTitle:    issueData.Issue.GetTitle(),
+		IssueURL:      issueData.Issue.GetHTMLURL(),
+		IssueState:    issueData.Issue.GetState(),
+		Author:        issueData.Issue.GetUser().GetLogin(),
+		Assignee:      issueData.Issue.GetAssignee().GetLogin(),
+		Milestone:     issueData.Issue.GetMilestone().GetTitle(),
+		Labels:        []string{},
+		FormFields:    issueData.FormFields,
+		Action:        issueData.Action,
+		EventType:     issueData.EventType,
+		PriorityEmoji: priorityEmoji,
+		CategoryEmoji: categoryEmoji,
+		Summary:       summary,
+
+		Activity:        issueData.Activity,
+		CloseSuggestion: issueData.CloseSuggestion,
+	}
+	if issueData.Repository != nil {
+		data.Repository = issueData.Repository.GetFullName()
+	}
+	for _, label := range issueData.Issue.Labels {
+		data.Labels = append(data.Labels, label.GetName())
+	}
+	return data
+}
+
+// maxFallbackText bounds a message's fallback text, which Slack shows in
+// notifications and reads out to screen readers
+const maxFallbackText = 300
+
+// FallbackTemplate renders the plain-text fallback of Slack messages from a
+// Go template over SlackTemplateData, e.g.
+// "{{.Summary.Priority}}: {{.Repository}}#{{.IssueNumber}} {{.IssueTitle}}"
+type FallbackTemplate struct {
+	tmpl *template.Template
+}
+
+// ParseFallbackTemplate parses a fallback text template
+func ParseFallbackTemplate(text string) (*FallbackTemplate, error) {
+	tmpl, err := template.New("fallback").Funcs(slackTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fallback template: %w", err)
+	}
+	return &FallbackTemplate{tmpl: tmpl}, nil
+}
+
+// Render executes the template, collapsing the output onto one line
+func (t *FallbackTemplate) Render(data SlackTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render fallback template: %w", err)
+	}
+	text := strings.Join(strings.Fields(buf.String()), " ")
+	if text == "" {
+		return "", fmt.Errorf("fallback template rendered no text")
+	}
+	return utils.TruncateText(text, maxFallbackText), nil
+}
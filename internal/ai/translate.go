@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// originalSnippetLength caps the original-language excerpt shown in Slack
+const originalSnippetLength = 280
+
+// translatePrompt asks for a faithful translation that keeps code untouched
+const translatePrompt = `You translate GitHub issues into English.
+Translate the title and body faithfully, keeping Markdown structure, code blocks, stack traces, file paths and identifiers unchanged.
+Respond with only a JSON object of this shape:
+{
+  "title": "The English title",
+  "body": "The English body"
+}`
+
+// Translation is an issue's title and body translated into English
+type Translation struct {
+	Language string // ISO 639-1 code of the original text
+	Title    string
+	Body     string
+	Snippet  string // Excerpt of the original body, for showing next to the summary
+}
+
+// TranslateIssue translates an issue written in language into English
+func (s *Summarizer) TranslateIssue(ctx context.Context, issueData *gh.IssueData, language string) (*Translation, error) {
+	start := time.Now()
+
+	content, err := json.Marshal(map[string]string{
+		"title": issueData.Issue.GetTitle(),
+		"body":  issueData.Issue.GetBody(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode issue for translation: %w", err)
+	}
+
+	resp, err := s.openaiClient().CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: s.model,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: translatePrompt,
+				},
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: fmt.Sprintf("Source language: %s\n\n%s", LanguageName(language), content),
+				},
+			},
+			MaxTokens:   s.maxTokens,
+			Temperature: 0,
+		},
+	)
+
+	duration := time.Since(start)
+
+	if err != nil {
+		err = classifyError(err)
+		s.metrics.RecordOpenAIRequest(s.model, "error", duration)
+		s.metrics.RecordOpenAIError(apperrors.Classify(err))
+		s.logger.Error("OpenAI translation error", zap.Error(err))
+		return nil, fmt.Errorf("failed to translate issue: %w", err)
+	}
+
+	s.metrics.RecordOpenAIRequest(s.model, "success", duration)
+	if resp.Usage.PromptTokens > 0 {
+		s.metrics.RecordOpenAITokens(s.model, "prompt", resp.Usage.PromptTokens)
+		s.metrics.RecordOpenAITokens(s.model, "completion", resp.Usage.CompletionTokens)
+		s.metrics.RecordOpenAITokens(s.model, "total", resp.Usage.TotalTokens)
+	}
+
+	response := strings.TrimSpace(resp.Choices[0].Message.Content)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimSuffix(response, "```")
+
+	var translated struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &translated); err != nil {
+		s.metrics.RecordOpenAIError("parse_error")
+		return nil, fmt.Errorf("failed to parse translation response: %w", err)
+	}
+	if translated.Title == "" {
+		s.metrics.RecordOpenAIError("parse_error")
+		return nil, fmt.Errorf("missing title in translation response")
+	}
+
+	s.logger.Info("Translated issue",
+		zap.String("repository", issueData.Repository.GetFullName()),
+		zap.Int("issue_number", issueData.Issue.GetNumber()),
+		zap.String("language", language),
+	)
+
+	return &Translation{
+		Language: language,
+		Title:    translated.Title,
+		Body:     translated.Body,
+		Snippet:  OriginalSnippet(issueData.Issue.GetBody()),
+	}, nil
+}
+
+// OriginalSnippet returns the first paragraph of the original prose, without
+// code blocks, cut to a length that fits under a summary
+func OriginalSnippet(body string) string {
+	body = strings.TrimSpace(codeFencePattern.ReplaceAllString(body, " "))
+	if i := strings.Index(body, "\n\n"); i >= 0 {
+		body = body[:i]
+	}
+	return utils.TruncateText(strings.Join(strings.Fields(body), " "), originalSnippetLength)
+}
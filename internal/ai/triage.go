@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	gh "github-issue-ai-bot/internal/github"
+)
+
+// TriageMaxTokens caps the triage response, which is a handful of short fields
+const TriageMaxTokens = 400
+
+// triagePrompt asks for the quick classification only
+func triagePrompt(taxonomy *Taxonomy) string {
+	prompt := fmt.Sprintf(`You triage GitHub issues quickly.
+Respond with only a JSON object of this shape:
+{
+  "title": "A short, precise title for the issue",
+  "summary": "One sentence describing the problem or request",
+  "priority": "%s",
+  "category": "%s",
+  "confidence": 0.0,
+  "reasoning": ["Up to three short signals from the issue that justify the priority and confidence"]
+}
+Confidence is between 0 and 1 and reflects how certain the classification is.`,
+		promptValues(taxonomy.Priorities()),
+		promptValues(taxonomy.Categories()))
+	if definitions := taxonomy.promptDefinitions(); definitions != "" {
+		prompt += "\n\n" + definitions
+	}
+	return prompt + "\n\n" + untrustedNotice
+}
+
+// priorityRanks orders priorities for threshold comparisons
+var priorityRanks = map[string]int{
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// PriorityAtLeast reports whether priority is at or above threshold. Unknown
+// priorities never meet a threshold.
+func PriorityAtLeast(priority, threshold string) bool {
+	rank, ok := priorityRanks[strings.ToLower(priority)]
+	return ok && rank >= priorityRanks[strings.ToLower(threshold)]
+}
+
+// SetTriageModel sets the cheaper model used by TriageIssue. Without one the
+// main model is used.
+func (s *Summarizer) SetTriageModel(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.triageModel = model
+}
+
+// TriageIssue runs the quick first stage: priority, category and a one-line
+// summary. The returned summary has Triage set and no deep analysis fields.
+func (s *Summarizer) TriageIssue(ctx context.Context, issueData *gh.IssueData) (*IssueSummary, error) {
+	start := time.Now()
+
+	s.mu.RLock()
+	model := s.triageModel
+	s.mu.RUnlock()
+	if model == "" {
+		model = s.model
+	}
+
+	system := triagePrompt(s.currentTaxonomy())
+	resp, err := s.openaiClient().CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleSystem,
+					Content: system,
+				},
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: s.fitPrompt(model, system, s.buildPrompt(issueData), TriageMaxTokens),
+				},
+			},
+			MaxTokens:      TriageMaxTokens,
+			Temperature:    0.2,
+			ResponseFormat: s.responseFormat(model),
+		},
+	)
+
+	duration := time.Since(start)
+
+	if err != nil {
+		err = classifyError(err)
+		s.metrics.RecordOpenAIRequest(model, "error", duration)
+		s.metrics.RecordOpenAIError(apperrors.Classify(err))
+		s.logger.Error("OpenAI triage error", zap.Error(err))
+		return nil, fmt.Errorf("failed to triage issue: %w", err)
+	}
+
+	s.metrics.RecordOpenAIRequest(model, "success", duration)
+	if resp.Usage.PromptTokens > 0 {
+		s.metrics.RecordOpenAITokens(model, "prompt", resp.Usage.PromptTokens)
+		s.metrics.RecordOpenAITokens(model, "completion", resp.Usage.CompletionTokens)
+		s.metrics.RecordOpenAITokens(model, "total", resp.Usage.TotalTokens)
+	}
+
+	summary, err := s.parseSummaryResponse(resp.Choices[0].Message.Content)
+	if err != nil {
+		s.metrics.RecordOpenAIError("parse_error")
+		s.logger.Error("Failed to parse triage response", zap.Error(err))
+		return nil, fmt.Errorf("failed to parse triage response: %w", err)
+	}
+	summary.Triage = true
+	summary.Usage = []Usage{{Model: model, PromptTokens: resp.Usage.PromptTokens, CompletionTokens: resp.Usage.CompletionTokens}}
+	summary.PromptStyle = s.style.Name
+
+	s.logger.Info("Triaged issue",
+		zap.String("repository", issueData.Repository.GetFullName()),
+		zap.Int("issue_number", issueData.Issue.GetNumber()),
+		zap.String("model", model),
+		zap.String("priority", summary.Priority),
+		zap.String("category", summary.Category),
+	)
+
+	return summary, nil
+}
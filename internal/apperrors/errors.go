@@ -0,0 +1,111 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// Error classes shared by the GitHub, OpenAI and Slack integrations. Callers
+// match them with errors.Is; the original upstream error stays reachable
+// through errors.As.
+var (
+	ErrRateLimited          = errors.New("rate limited")
+	ErrAuth                 = errors.New("authentication failed")
+	ErrNotFound             = errors.New("not found")
+	ErrContextTooLong       = errors.New("context length exceeded")
+	ErrSlackChannelNotFound = errors.New("slack channel not found")
+	ErrUnavailable          = errors.New("service unavailable")
+	ErrTimeout              = errors.New("timeout")
+	ErrQuotaExceeded        = errors.New("quota exceeded")
+	ErrCircuitOpen          = errors.New("circuit breaker open") // The dependency is failing, so calls are not attempted
+)
+
+// Error attaches a class to an upstream error
+type Error struct {
+	Class      error         // One of the Err* classes above
+	Err        error         // The original error
+	RetryAfter time.Duration // Server-provided back-off, if any
+}
+
+// Wrap classifies err. A nil class returns err unchanged.
+func Wrap(class, err error) error {
+	if err == nil || class == nil {
+		return err
+	}
+	return &Error{Class: class, Err: err}
+}
+
+// WrapRetryAfter classifies err and records the back-off requested by the server
+func WrapRetryAfter(class, err error, retryAfter time.Duration) error {
+	if err == nil || class == nil {
+		return err
+	}
+	return &Error{Class: class, Err: err, RetryAfter: retryAfter}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap exposes both the class and the original error to errors.Is/As
+func (e *Error) Unwrap() []error {
+	return []error{e.Class, e.Err}
+}
+
+// Classify returns a metrics-friendly label for an error
+func Classify(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrAuth):
+		return "auth"
+	case errors.Is(err, ErrSlackChannelNotFound):
+		return "channel_not_found"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrContextTooLong):
+		return "context_too_long"
+	case errors.Is(err, ErrQuotaExceeded):
+		return "quota_exceeded"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrUnavailable):
+		return "unavailable"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+
+	return "api_error"
+}
+
+// IsRetryable reports whether retrying the failed call may succeed. Auth,
+// not-found and context-length failures will fail the same way again.
+func IsRetryable(err error) bool {
+	switch Classify(err) {
+	case "rate_limited", "timeout", "unavailable", "network":
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryAfter returns the back-off requested by the server, or zero
+func RetryAfter(err error) time.Duration {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.RetryAfter
+	}
+	return 0
+}
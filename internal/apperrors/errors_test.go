@@ -0,0 +1,69 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	upstream := errors.New("upstream failure")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"unclassified", upstream, "api_error"},
+		{"rate limited", Wrap(ErrRateLimited, upstream), "rate_limited"},
+		{"auth", Wrap(ErrAuth, upstream), "auth"},
+		{"not found", Wrap(ErrNotFound, upstream), "not_found"},
+		{"context too long", Wrap(ErrContextTooLong, upstream), "context_too_long"},
+		{"channel not found", Wrap(ErrSlackChannelNotFound, upstream), "channel_not_found"},
+		{"unavailable", Wrap(ErrUnavailable, upstream), "unavailable"},
+		{"quota exceeded", ErrQuotaExceeded, "quota_exceeded"},
+		{"circuit open", fmt.Errorf("openai: %w", ErrCircuitOpen), "circuit_open"},
+		{"deadline exceeded", fmt.Errorf("call failed: %w", context.DeadlineExceeded), "timeout"},
+		{"wrapped class", fmt.Errorf("failed to fetch issue: %w", Wrap(ErrNotFound, upstream)), "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Classify(tt.err))
+		})
+	}
+}
+
+func TestWrapPreservesUpstreamError(t *testing.T) {
+	upstream := errors.New("upstream failure")
+	err := fmt.Errorf("context: %w", Wrap(ErrAuth, upstream))
+
+	assert.True(t, errors.Is(err, ErrAuth))
+	assert.True(t, errors.Is(err, upstream))
+	assert.Equal(t, "context: upstream failure", err.Error())
+	assert.Nil(t, Wrap(ErrAuth, nil))
+	assert.Equal(t, upstream, Wrap(nil, upstream))
+}
+
+func TestIsRetryable(t *testing.T) {
+	upstream := errors.New("upstream failure")
+
+	assert.True(t, IsRetryable(Wrap(ErrRateLimited, upstream)))
+	assert.True(t, IsRetryable(Wrap(ErrUnavailable, upstream)))
+	assert.False(t, IsRetryable(Wrap(ErrAuth, upstream)))
+	assert.False(t, IsRetryable(Wrap(ErrContextTooLong, upstream)))
+	assert.False(t, IsRetryable(ErrCircuitOpen))
+	assert.False(t, IsRetryable(upstream))
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := fmt.Errorf("send failed: %w", WrapRetryAfter(ErrRateLimited, errors.New("slow down"), 30*time.Second))
+
+	assert.Equal(t, 30*time.Second, RetryAfter(err))
+	assert.Equal(t, time.Duration(0), RetryAfter(errors.New("other")))
+}
@@ -0,0 +1,211 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// Breaker states
+const (
+	Closed   = "closed"    // Calls go through
+	Open     = "open"      // Calls fail fast until the cooldown has passed
+	HalfOpen = "half_open" // One probe call decides whether to close again
+)
+
+// Dependencies the bot guards with breakers
+const (
+	GitHub = "github"
+	OpenAI = "openai"
+	Slack  = "slack"
+)
+
+// Config sets when breakers open and how long they stay open
+type Config struct {
+	Failures int           // Consecutive failures that open a breaker, 0 disables breakers
+	Cooldown time.Duration // How long a breaker stays open before probing the dependency
+}
+
+// Enabled reports whether breakers should be used
+func (c Config) Enabled() bool {
+	return c.Failures > 0
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	if c.Failures < 0 {
+		return fmt.Errorf("failures must not be negative")
+	}
+	if c.Enabled() && c.Cooldown <= 0 {
+		return fmt.Errorf("cooldown must be positive")
+	}
+	return nil
+}
+
+// State is a breaker's current state, for debugging
+type State struct {
+	Name   string    `json:"name"`
+	State  string    `json:"state"`
+	Since  time.Time `json:"since"`
+	Detail string    `json:"detail,omitempty"` // The failure that opened the breaker
+}
+
+// MetricsRecorder tracks breaker states
+type MetricsRecorder interface {
+	RecordBreakerState(dependency, state string)
+}
+
+// Breaker stops calls to a dependency that keeps failing, so an outage does
+// not tie up workers waiting for timeouts, and lets a single probe through
+// after a cooldown to find out whether it has recovered
+type Breaker struct {
+	name    string
+	config  Config
+	metrics MetricsRecorder
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	since    time.Time
+	failures int
+	probing  bool // A half-open probe is in flight
+	detail   string
+}
+
+// New creates a closed breaker for a dependency
+func New(name string, config Config, metrics MetricsRecorder, logger *zap.Logger) *Breaker {
+	b := &Breaker{name: name, config: config, metrics: metrics, logger: logger, now: time.Now, state: Closed, since: time.Now()}
+	metrics.RecordBreakerState(name, Closed)
+	return b
+}
+
+// Name returns the dependency the breaker guards
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow reports whether a call may be made, failing with
+// apperrors.ErrCircuitOpen while the breaker is open. Once the cooldown has
+// passed, one call at a time is let through as a probe.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.since) < b.config.Cooldown {
+			return fmt.Errorf("%s: %w", b.name, apperrors.ErrCircuitOpen)
+		}
+		b.transition(HalfOpen, b.detail)
+		b.probing = true
+	case HalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s: %w", b.name, apperrors.ErrCircuitOpen)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success reports a call that reached the dependency, which closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures, b.probing = 0, false
+	if b.state != Closed {
+		b.transition(Closed, "")
+	}
+}
+
+// Failure reports a call that suggests the dependency is down. Enough of
+// them in a row, or a failed probe, open the breaker.
+func (b *Breaker) Failure(detail string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == HalfOpen || (b.state == Closed && b.failures >= b.config.Failures) {
+		b.transition(Open, detail)
+	}
+}
+
+// Cancel reports a call abandoned by its caller, which tells nothing about
+// the dependency
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// transition changes the state. The caller holds the lock.
+func (b *Breaker) transition(state, detail string) {
+	b.state, b.since, b.detail = state, b.now(), detail
+	b.metrics.RecordBreakerState(b.name, state)
+	switch state {
+	case Open:
+		b.logger.Warn("Circuit breaker opened, failing calls fast",
+			zap.String("dependency", b.name),
+			zap.String("detail", detail),
+			zap.Duration("cooldown", b.config.Cooldown))
+	case Closed:
+		b.logger.Info("Circuit breaker closed, dependency recovered", zap.String("dependency", b.name))
+	}
+}
+
+// State returns the breaker's current state
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return State{Name: b.name, State: b.state, Since: b.since, Detail: b.detail}
+}
+
+// Transport guards HTTP calls made with next, or the default transport if
+// nil. Network errors, timeouts and 5xx responses count as failures.
+func (b *Breaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{breaker: b, next: next}
+}
+
+type transport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		t.breaker.Cancel()
+	case err != nil:
+		t.breaker.Failure(err.Error())
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.breaker.Failure(resp.Status)
+	default:
+		t.breaker.Success()
+	}
+	return resp, err
+}
+
+// Set is the breakers of a deployment's dependencies
+type Set []*Breaker
+
+// BreakerStates returns the state of every breaker
+func (s Set) BreakerStates() []State {
+	states := make([]State, 0, len(s))
+	for _, b := range s {
+		states = append(states, b.State())
+	}
+	return states
+}
@@ -0,0 +1,141 @@
+package breaker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+type fakeMetrics struct{ states []string }
+
+func (f *fakeMetrics) RecordBreakerState(dependency, state string) {
+	f.states = append(f.states, state)
+}
+
+func newTestBreaker(failures int) (*Breaker, *fakeMetrics, *time.Time) {
+	metrics := &fakeMetrics{}
+	b := New(OpenAI, Config{Failures: failures, Cooldown: time.Minute}, metrics, zap.NewNop())
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	return b, metrics, &now
+}
+
+func TestBreaker(t *testing.T) {
+	b, metrics, now := newTestBreaker(3)
+
+	b.Failure("500 Internal Server Error")
+	b.Failure("500 Internal Server Error")
+	b.Success()
+	b.Failure("500 Internal Server Error")
+	b.Failure("500 Internal Server Error")
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a success to reset the failure count, got %v", err)
+	}
+
+	b.Failure("connection refused")
+	err := b.Allow()
+	if !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Fatalf("Expected the breaker to open after 3 failures, got %v", err)
+	}
+	if state := b.State(); state.State != Open || state.Detail != "connection refused" {
+		t.Errorf("Expected the open state with the failure, got %+v", state)
+	}
+
+	// After the cooldown one probe goes through, and a failed probe reopens
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a probe after the cooldown, got %v", err)
+	}
+	if err := b.Allow(); err == nil {
+		t.Error("Expected a single probe at a time")
+	}
+	b.Failure("connection refused")
+	if err := b.Allow(); err == nil {
+		t.Error("Expected a failed probe to reopen the breaker")
+	}
+
+	// A successful probe closes it
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a probe after the cooldown, got %v", err)
+	}
+	b.Success()
+	if err := b.Allow(); err != nil || b.State().State != Closed {
+		t.Errorf("Expected the breaker closed, got %v", err)
+	}
+
+	expected := []string{Closed, Open, HalfOpen, Open, HalfOpen, Closed}
+	if len(metrics.states) != len(expected) {
+		t.Fatalf("Expected states %v, got %v", expected, metrics.states)
+	}
+	for i := range expected {
+		if metrics.states[i] != expected[i] {
+			t.Fatalf("Expected states %v, got %v", expected, metrics.states)
+		}
+	}
+}
+
+func TestBreakerCancelledProbe(t *testing.T) {
+	b, _, now := newTestBreaker(1)
+	b.Failure("timeout")
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Cancel()
+	if err := b.Allow(); err != nil {
+		t.Errorf("Expected a cancelled probe to let another through, got %v", err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	b, _, _ := newTestBreaker(2)
+	client := &http.Client{Transport: b.Transport(nil)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(server.URL); !errors.Is(err, apperrors.ErrCircuitOpen) {
+		t.Fatalf("Expected requests to fail fast after two 500s, got %v", err)
+	}
+
+	// Client errors say nothing about the dependency's health
+	b, _, _ = newTestBreaker(2)
+	client = &http.Client{Transport: b.Transport(nil)}
+	status = http.StatusNotFound
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected 404s to keep the breaker closed, got %v", err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{}).Validate(); err != nil {
+		t.Errorf("Expected disabled breakers to be valid, got %v", err)
+	}
+	if err := (Config{Failures: 5}).Validate(); err == nil {
+		t.Error("Expected an error without a cooldown")
+	}
+	if err := (Config{Failures: -1}).Validate(); err == nil {
+		t.Error("Expected an error for negative failures")
+	}
+}
@@ -0,0 +1,102 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Ingestion modes
+const (
+	ModeMonolith = "monolith" // Receive and process webhooks in the same process
+	ModeReceiver = "receiver" // Verify webhooks and publish them to the broker
+	ModeWorker   = "worker"   // Consume webhooks from the broker and process them
+)
+
+// Message is a verified GitHub webhook delivery travelling through the broker
+type Message struct {
+	EventType  string    `json:"event_type"`
+	DeliveryID string    `json:"delivery_id"`
+	Payload    []byte    `json:"payload"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// Publisher sends webhook deliveries to the broker
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// HandlerFunc processes one delivery. Returning an error asks the broker to
+// redeliver the message when retryable reports true for it.
+type HandlerFunc func(ctx context.Context, msg Message) error
+
+// Consumer reads webhook deliveries from the broker until ctx is cancelled
+type Consumer interface {
+	Consume(ctx context.Context, handler HandlerFunc) error
+	Close() error
+}
+
+// Config selects and configures the message broker
+type Config struct {
+	Type string // kafka or nats
+
+	KafkaBrokers []string
+	KafkaTopic   string
+	KafkaGroupID string
+
+	// KafkaDeadLetterTopic receives deliveries that gave up, empty to only
+	// log them
+	KafkaDeadLetterTopic string
+
+	NATSURL     string
+	NATSStream  string
+	NATSSubject string
+	NATSDurable string
+
+	MaxRedeliveries int // Attempts before a failing message gives up
+}
+
+// NewPublisher creates a publisher for the configured broker
+func NewPublisher(cfg Config) (Publisher, error) {
+	switch cfg.Type {
+	case "kafka":
+		return newKafkaPublisher(cfg)
+	case "nats":
+		return newNATSPublisher(cfg)
+	default:
+		return nil, fmt.Errorf("unknown broker type %q", cfg.Type)
+	}
+}
+
+// NewConsumer creates a consumer for the configured broker. Deliveries that
+// give up are logged at error level.
+func NewConsumer(cfg Config, retryable func(error) bool, logger *zap.Logger) (Consumer, error) {
+	switch cfg.Type {
+	case "kafka":
+		return newKafkaConsumer(cfg, retryable, logger)
+	case "nats":
+		return newNATSConsumer(cfg, retryable, logger)
+	default:
+		return nil, fmt.Errorf("unknown broker type %q", cfg.Type)
+	}
+}
+
+func encodeMessage(msg Message) ([]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode broker message: %w", err)
+	}
+	return data, nil
+}
+
+func decodeMessage(data []byte) (Message, error) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Message{}, fmt.Errorf("failed to decode broker message: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,155 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	msg := Message{
+		EventType:  "issues",
+		DeliveryID: "72d3162e-cc78-11e3-81ab-4c9367dc0958",
+		Payload:    []byte(`{"action":"opened"}`),
+		ReceivedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	data, err := encodeMessage(msg)
+	if err != nil {
+		t.Fatalf("encodeMessage failed: %v", err)
+	}
+	decoded, err := decodeMessage(data)
+	if err != nil {
+		t.Fatalf("decodeMessage failed: %v", err)
+	}
+
+	if decoded.EventType != msg.EventType || decoded.DeliveryID != msg.DeliveryID ||
+		string(decoded.Payload) != string(msg.Payload) || !decoded.ReceivedAt.Equal(msg.ReceivedAt) {
+		t.Errorf("Round trip mismatch: %+v", decoded)
+	}
+}
+
+func TestNewPublisherValidation(t *testing.T) {
+	if _, err := NewPublisher(Config{Type: "rabbitmq"}); err == nil {
+		t.Error("Expected error for unknown broker type")
+	}
+	if _, err := NewPublisher(Config{Type: "kafka"}); err == nil {
+		t.Error("Expected error for kafka without brokers")
+	}
+	if _, err := NewConsumer(Config{Type: "kafka", KafkaBrokers: []string{"localhost:9092"}, KafkaTopic: "webhooks"}, nil, zap.NewNop()); err == nil {
+		t.Error("Expected error for kafka consumer without group")
+	}
+	if _, err := NewConsumer(Config{Type: "nats", NATSURL: "nats://localhost:4222"}, nil, zap.NewNop()); err == nil {
+		t.Error("Expected error for nats consumer without durable")
+	}
+}
+
+var errPermanent = errors.New("permanent")
+
+// fakeKafka serves messages to a consumer, then cancels it once they have
+// all been fetched
+type fakeKafka struct {
+	messages  []kafka.Message
+	committed []int64
+	written   []kafka.Message
+	cancel    context.CancelFunc
+}
+
+func (f *fakeKafka) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(f.messages) == 0 {
+		f.cancel()
+		return kafka.Message{}, context.Canceled
+	}
+	msg := f.messages[0]
+	f.messages = f.messages[1:]
+	return msg, nil
+}
+
+func (f *fakeKafka) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		f.committed = append(f.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (f *fakeKafka) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.written = append(f.written, msgs...)
+	return nil
+}
+
+func (f *fakeKafka) Close() error { return nil }
+
+func TestKafkaConsumerDeadLetter(t *testing.T) {
+	ok, _ := encodeMessage(Message{EventType: "issues", DeliveryID: "ok"})
+	failing, _ := encodeMessage(Message{EventType: "issues", DeliveryID: "failing"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fake := &fakeKafka{
+		messages: []kafka.Message{{Offset: 1, Value: ok}, {Offset: 2, Value: []byte("not json")}, {Offset: 3, Value: failing}},
+		cancel:   cancel,
+	}
+	consumer := &kafkaConsumer{
+		reader:          fake,
+		deadLetter:      fake,
+		retryable:       func(err error) bool { return !errors.Is(err, errPermanent) },
+		maxRedeliveries: 3,
+		logger:          zap.NewNop(),
+	}
+
+	attempts := 0
+	err := consumer.Consume(ctx, func(ctx context.Context, msg Message) error {
+		if msg.DeliveryID == "failing" {
+			attempts++
+			return errPermanent
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Consume failed: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a permanent error not retried, got %d attempts", attempts)
+	}
+	if len(fake.committed) != 3 {
+		t.Errorf("Expected every message committed, got %v", fake.committed)
+	}
+	if len(fake.written) != 2 || string(fake.written[0].Value) != "not json" || string(fake.written[1].Value) != string(failing) {
+		t.Fatalf("Expected the unreadable and failing messages dead-lettered, got %+v", fake.written)
+	}
+	if headers := fake.written[1].Headers; len(headers) != 2 || string(headers[0].Value) != "permanent" {
+		t.Errorf("Expected the error in the headers, got %+v", headers)
+	}
+}
+
+func TestKafkaConsumerShutdownDuringRetry(t *testing.T) {
+	failing, _ := encodeMessage(Message{EventType: "issues", DeliveryID: "failing"})
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := &fakeKafka{messages: []kafka.Message{{Offset: 1, Value: failing}}, cancel: cancel}
+	consumer := &kafkaConsumer{
+		reader:          fake,
+		deadLetter:      fake,
+		retryable:       func(error) bool { return true },
+		maxRedeliveries: 5,
+		logger:          zap.NewNop(),
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- consumer.Consume(ctx, func(ctx context.Context, msg Message) error {
+			cancel()
+			return errors.New("timeout")
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil || len(fake.committed) != 0 || len(fake.written) != 0 {
+			t.Errorf("Expected the delivery left uncommitted, got %v, %v, %d dead letters", err, fake.committed, len(fake.written))
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Expected shutdown not to wait for the retry backoff")
+	}
+}
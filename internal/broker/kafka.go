@@ -0,0 +1,180 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// kafkaPublisher writes deliveries to a Kafka topic, keyed by delivery ID
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func newKafkaPublisher(cfg Config) (*kafkaPublisher, error) {
+	if len(cfg.KafkaBrokers) == 0 || cfg.KafkaTopic == "" {
+		return nil, fmt.Errorf("kafka broker requires KAFKA_BROKERS and KAFKA_TOPIC")
+	}
+
+	return &kafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.KafkaBrokers...),
+			Topic:        cfg.KafkaTopic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, msg Message) error {
+	data, err := encodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(msg.DeliveryID),
+		Value: data,
+		Time:  msg.ReceivedAt,
+	})
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// kafkaReader fetches and commits the messages of a consumer group
+type kafkaReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// kafkaWriter writes messages to a topic
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// kafkaConsumer reads deliveries with a consumer group and commits offsets
+// only after a message has been handled, or written to the dead-letter
+// topic once it gave up
+type kafkaConsumer struct {
+	reader          kafkaReader
+	deadLetter      kafkaWriter // nil without a dead-letter topic
+	retryable       func(error) bool
+	maxRedeliveries int
+	logger          *zap.Logger
+}
+
+func newKafkaConsumer(cfg Config, retryable func(error) bool, logger *zap.Logger) (*kafkaConsumer, error) {
+	if len(cfg.KafkaBrokers) == 0 || cfg.KafkaTopic == "" || cfg.KafkaGroupID == "" {
+		return nil, fmt.Errorf("kafka consumer requires KAFKA_BROKERS, KAFKA_TOPIC and KAFKA_GROUP_ID")
+	}
+
+	consumer := &kafkaConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.KafkaBrokers,
+			Topic:   cfg.KafkaTopic,
+			GroupID: cfg.KafkaGroupID,
+		}),
+		retryable:       retryable,
+		maxRedeliveries: cfg.MaxRedeliveries,
+		logger:          logger,
+	}
+	if cfg.KafkaDeadLetterTopic != "" {
+		consumer.deadLetter = &kafka.Writer{
+			Addr:         kafka.TCP(cfg.KafkaBrokers...),
+			Topic:        cfg.KafkaDeadLetterTopic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		}
+	}
+	return consumer, nil
+}
+
+func (c *kafkaConsumer) Consume(ctx context.Context, handler HandlerFunc) error {
+	for {
+		record, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch kafka message: %w", err)
+		}
+
+		msg, err := decodeMessage(record.Value)
+		if err != nil {
+			c.logger.Error("Failed to decode kafka message",
+				zap.Int("partition", record.Partition),
+				zap.Int64("offset", record.Offset),
+				zap.Error(err))
+		} else if err = c.handle(ctx, handler, msg); err != nil {
+			if ctx.Err() != nil {
+				// Left uncommitted, so the next worker in the group picks it up
+				return nil
+			}
+			c.logger.Error("Giving up on webhook delivery",
+				zap.String("event_type", msg.EventType),
+				zap.String("delivery_id", msg.DeliveryID),
+				zap.Error(err))
+		}
+		if err != nil {
+			if err := c.writeDeadLetter(ctx, record, err); err != nil {
+				return err
+			}
+		}
+
+		if err := c.reader.CommitMessages(ctx, record); err != nil && !errors.Is(err, context.Canceled) {
+			return fmt.Errorf("failed to commit kafka message: %w", err)
+		}
+	}
+}
+
+// handle runs handler on a delivery, retrying retryable errors in place up
+// to maxRedeliveries times, since Kafka has no per-message redelivery
+func (c *kafkaConsumer) handle(ctx context.Context, handler HandlerFunc, msg Message) error {
+	for attempt := 1; ; attempt++ {
+		err := handler(ctx, msg)
+		if err == nil || !c.retryable(err) || attempt >= c.maxRedeliveries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
+	}
+}
+
+// writeDeadLetter copies a message that gave up to the dead-letter topic,
+// with the error in its headers. Without the topic, the log is all that is
+// left of it.
+func (c *kafkaConsumer) writeDeadLetter(ctx context.Context, record kafka.Message, cause error) error {
+	if c.deadLetter == nil {
+		return nil
+	}
+	err := c.deadLetter.WriteMessages(ctx, kafka.Message{
+		Key:   record.Key,
+		Value: record.Value,
+		Headers: append(record.Headers,
+			kafka.Header{Key: "error", Value: []byte(cause.Error())},
+			kafka.Header{Key: "offset", Value: []byte(strconv.FormatInt(record.Offset, 10))}),
+	})
+	if err != nil {
+		// Left uncommitted, so it is consumed again after a restart
+		return fmt.Errorf("failed to write kafka message to the dead-letter topic: %w", err)
+	}
+	return nil
+}
+
+func (c *kafkaConsumer) Close() error {
+	if c.deadLetter != nil {
+		c.deadLetter.Close()
+	}
+	return c.reader.Close()
+}
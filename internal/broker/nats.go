@@ -0,0 +1,167 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// natsPublisher publishes deliveries to a JetStream stream. The delivery ID is
+// used as the message ID so GitHub redeliveries are de-duplicated.
+type natsPublisher struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+}
+
+// connectJetStream connects to NATS and makes sure the stream exists
+func connectJetStream(cfg Config) (*nats.Conn, nats.JetStreamContext, error) {
+	if cfg.NATSURL == "" || cfg.NATSStream == "" || cfg.NATSSubject == "" {
+		return nil, nil, fmt.Errorf("nats broker requires NATS_URL, NATS_STREAM and NATS_SUBJECT")
+	}
+
+	conn, err := nats.Connect(cfg.NATSURL, nats.Name("notifyops"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+
+	if _, err := js.StreamInfo(cfg.NATSStream); errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:     cfg.NATSStream,
+			Subjects: []string{cfg.NATSSubject},
+			Storage:  nats.FileStorage,
+		})
+		if err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("failed to create stream %s: %w", cfg.NATSStream, err)
+		}
+	} else if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to look up stream %s: %w", cfg.NATSStream, err)
+	}
+
+	return conn, js, nil
+}
+
+func newNATSPublisher(cfg Config) (*natsPublisher, error) {
+	conn, js, err := connectJetStream(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &natsPublisher{conn: conn, js: js, subject: cfg.NATSSubject}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, msg Message) error {
+	data, err := encodeMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	opts := []nats.PubOpt{nats.Context(ctx)}
+	if msg.DeliveryID != "" {
+		opts = append(opts, nats.MsgId(msg.DeliveryID))
+	}
+	_, err = p.js.Publish(p.subject, data, opts...)
+	return err
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+// natsConsumer pulls deliveries with a durable consumer and acknowledges them
+// only after they have been handled
+type natsConsumer struct {
+	conn            *nats.Conn
+	sub             *nats.Subscription
+	retryable       func(error) bool
+	maxRedeliveries int
+	logger          *zap.Logger
+}
+
+func newNATSConsumer(cfg Config, retryable func(error) bool, logger *zap.Logger) (*natsConsumer, error) {
+	if cfg.NATSDurable == "" {
+		return nil, fmt.Errorf("nats consumer requires NATS_DURABLE")
+	}
+
+	conn, js, err := connectJetStream(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := js.PullSubscribe(cfg.NATSSubject, cfg.NATSDurable,
+		nats.BindStream(cfg.NATSStream),
+		nats.ManualAck(),
+		nats.MaxDeliver(cfg.MaxRedeliveries),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", cfg.NATSSubject, err)
+	}
+
+	return &natsConsumer{conn: conn, sub: sub, retryable: retryable, maxRedeliveries: cfg.MaxRedeliveries, logger: logger}, nil
+}
+
+func (c *natsConsumer) Consume(ctx context.Context, handler HandlerFunc) error {
+	for {
+		records, err := c.sub.Fetch(10, nats.Context(ctx))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout) {
+				continue
+			}
+			return fmt.Errorf("failed to fetch nats messages: %w", err)
+		}
+
+		for _, record := range records {
+			msg, err := decodeMessage(record.Data)
+			if err != nil {
+				c.logger.Error("Failed to decode nats message", zap.String("subject", record.Subject), zap.Error(err))
+				record.Term()
+				continue
+			}
+
+			err = handler(ctx, msg)
+			if err != nil && ctx.Err() != nil {
+				// Left unacknowledged, so JetStream redelivers it to another worker
+				return nil
+			}
+			if err != nil && c.retryable(err) && !c.lastDelivery(record) {
+				record.NakWithDelay(5 * time.Second)
+				continue
+			}
+			if err != nil {
+				c.logger.Error("Giving up on webhook delivery",
+					zap.String("event_type", msg.EventType),
+					zap.String("delivery_id", msg.DeliveryID),
+					zap.Error(err))
+			}
+			record.Ack()
+		}
+	}
+}
+
+// lastDelivery reports whether JetStream will not redeliver a message again
+func (c *natsConsumer) lastDelivery(record *nats.Msg) bool {
+	metadata, err := record.Metadata()
+	return err == nil && c.maxRedeliveries > 0 && metadata.NumDelivered >= uint64(c.maxRedeliveries)
+}
+
+func (c *natsConsumer) Close() error {
+	if err := c.sub.Drain(); err != nil {
+		return err
+	}
+	return c.conn.Drain()
+}
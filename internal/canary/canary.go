@@ -0,0 +1,405 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// Versions compared during a canary
+const (
+	VersionStable = "stable"
+	VersionCanary = "canary"
+)
+
+// Actions recorded in the audit trail
+const (
+	ActionStarted    = "started"
+	ActionPromoted   = "promoted"
+	ActionRolledBack = "rolled_back"
+)
+
+// maxHistory bounds the audit trail kept in memory
+const maxHistory = 100
+
+// ErrInProgress is returned when a canary is started while another runs
+var ErrInProgress = errors.New("a prompt style canary is already running")
+
+// Config controls how runtime prompt style changes are rolled out. With a
+// zero Percent changes apply at once.
+type Config struct {
+	Percent      int     // Share of issues summarized with the new version, 1 to 99
+	MinSamples   int     // Summaries each version needs before they are compared
+	PromoteAfter int     // Summaries of the new version after which it replaces the old one
+	Tolerance    float64 // How much higher the new version's parse error or override rate may be
+	Channel      string  // Slack channel told about promotions and rollbacks, empty for logs only
+}
+
+// Enabled reports whether style changes are canaried
+func (c Config) Enabled() bool {
+	return c.Percent > 0
+}
+
+// Validate checks the share, sample sizes and tolerance
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.Percent >= 100 {
+		return fmt.Errorf("canary percent must be between 1 and 99")
+	}
+	if c.MinSamples < 1 {
+		return fmt.Errorf("canary minimum samples must be positive")
+	}
+	if c.PromoteAfter < c.MinSamples {
+		return fmt.Errorf("canary promotion must wait for at least the minimum samples")
+	}
+	if c.Tolerance < 0 || c.Tolerance >= 1 {
+		return fmt.Errorf("canary tolerance must be between 0 and 1")
+	}
+	return nil
+}
+
+// Poster posts a message to a Slack channel
+type Poster interface {
+	PostMessage(ctx context.Context, channelID, kind, text string) error
+}
+
+// MetricsRecorder records the outcome of canaried summaries
+type MetricsRecorder interface {
+	RecordPromptCanary(version, outcome string)
+}
+
+// Stats are a version's summaries during a canary
+type Stats struct {
+	Summaries   int `json:"summaries"`
+	ParseErrors int `json:"parse_errors"` // Responses that were not a valid summary, not counted in Summaries
+	Overrides   int `json:"overrides"`    // Summaries whose priority or category a human changed
+}
+
+// ParseErrorRate is the share of responses that could not be parsed
+func (s Stats) ParseErrorRate() float64 {
+	if s.Summaries+s.ParseErrors == 0 {
+		return 0
+	}
+	return float64(s.ParseErrors) / float64(s.Summaries+s.ParseErrors)
+}
+
+// OverrideRate is the share of summaries a human overrode
+func (s Stats) OverrideRate() float64 {
+	if s.Summaries == 0 {
+		return 0
+	}
+	return float64(s.Overrides) / float64(s.Summaries)
+}
+
+// Entry is one step of the audit trail of style changes
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Action   string    `json:"action"`
+	Style    string    `json:"style"`    // Style being rolled out
+	Previous string    `json:"previous"` // Style it replaces
+	Reason   string    `json:"reason,omitempty"`
+	Stable   Stats     `json:"stable"`
+	Canary   Stats     `json:"canary"`
+}
+
+// Status is the current style, the canary if one runs, and the audit trail
+type Status struct {
+	Style     string    `json:"style"`
+	Canary    string    `json:"canary,omitempty"`
+	Percent   int       `json:"percent,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Stable    *Stats    `json:"stable,omitempty"`
+	Candidate *Stats    `json:"candidate,omitempty"`
+	History   []Entry   `json:"history"` // Oldest first
+}
+
+// trial is a running canary
+type trial struct {
+	id      int
+	name    string
+	style   ai.PromptStyle
+	started time.Time
+	stats   map[string]*Stats // By version
+}
+
+// Manager rolls out prompt style changes to a share of issues, compares the
+// new version's parse error and override rates with the old one's, and
+// promotes it or rolls it back. It selects the style of each summary.
+type Manager struct {
+	mu      sync.Mutex
+	config  Config
+	style   string
+	trial   *trial
+	trials  int
+	history []Entry
+
+	apply   func(name string, style ai.PromptStyle)
+	poster  Poster
+	metrics MetricsRecorder
+	logger  *zap.Logger
+	baseCtx context.Context
+	now     func() time.Time
+}
+
+// NewManager creates a manager for the style named style. Promoted styles
+// are set with apply, e.g. on every summarizer.
+func NewManager(config Config, style string, apply func(name string, style ai.PromptStyle), poster Poster, metrics MetricsRecorder, logger *zap.Logger) *Manager {
+	return &Manager{
+		config:  config,
+		style:   style,
+		apply:   apply,
+		poster:  poster,
+		metrics: metrics,
+		logger:  logger,
+		baseCtx: context.Background(),
+		now:     time.Now,
+	}
+}
+
+// SetBaseContext sets the context promotions and rollbacks are posted
+// under, which is cancelled on shutdown
+func (m *Manager) SetBaseContext(ctx context.Context) {
+	m.baseCtx = ctx
+}
+
+// Start rolls a new version of a style out to the configured share of
+// issues. The new version may have the current style's name, e.g. after the
+// custom style was updated.
+func (m *Manager) Start(name string, style ai.PromptStyle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.trial != nil {
+		return ErrInProgress
+	}
+	m.trials++
+	m.trial = &trial{
+		id:      m.trials,
+		name:    name,
+		style:   style,
+		started: m.now(),
+		stats:   map[string]*Stats{VersionStable: {}, VersionCanary: {}},
+	}
+	m.append(Entry{Time: m.now(), Action: ActionStarted, Style: name, Previous: m.style})
+	m.logger.Info("Started prompt style canary",
+		zap.String("style", name),
+		zap.String("previous", m.style),
+		zap.Int("percent", m.config.Percent))
+	return nil
+}
+
+// SelectStyle picks the version of the style for an issue. An issue keeps
+// its version throughout a canary, so re-summaries are comparable.
+func (m *Manager) SelectStyle(issueData *github.IssueData) (string, *ai.PromptStyle) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.trial == nil {
+		return "", nil
+	}
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s#%d", strings.ToLower(issueData.Repository.GetFullName()), issueData.Issue.GetNumber())
+	if int(hash.Sum32()%100) < m.config.Percent {
+		style := m.trial.style
+		return versionTag(VersionCanary, m.trial.id), &style
+	}
+	return versionTag(VersionStable, m.trial.id), nil
+}
+
+// RecordSummary counts a summary of a version, or a response that could
+// not be parsed, and settles the canary once there is enough evidence
+func (m *Manager) RecordSummary(version string, parsed bool) {
+	outcome := "parsed"
+	if !parsed {
+		outcome = "parse_error"
+	}
+	m.record(version, outcome, func(stats *Stats) {
+		if parsed {
+			stats.Summaries++
+		} else {
+			stats.ParseErrors++
+		}
+	})
+}
+
+// RecordOverride counts a summary of a version whose priority or category
+// a human changed
+func (m *Manager) RecordOverride(version string) {
+	m.record(version, "overridden", func(stats *Stats) {
+		stats.Overrides++
+	})
+}
+
+// record updates the stats of a version of the running canary. Versions of
+// earlier canaries are ignored.
+func (m *Manager) record(version, outcome string, update func(*Stats)) {
+	name, id, ok := parseVersion(version)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	if m.trial == nil || m.trial.id != id {
+		m.mu.Unlock()
+		return
+	}
+	update(m.trial.stats[name])
+	m.metrics.RecordPromptCanary(name, outcome)
+	action, reason := m.evaluate()
+	var settled *trial
+	var entry Entry
+	if action != "" {
+		settled, m.trial = m.trial, nil
+		entry = m.settle(settled, action, reason)
+	}
+	m.mu.Unlock()
+
+	if settled != nil {
+		m.announce(settled, entry)
+	}
+}
+
+// evaluate decides whether the running canary is promoted or rolled back.
+// The caller holds the lock.
+func (m *Manager) evaluate() (string, string) {
+	stable, canary := *m.trial.stats[VersionStable], *m.trial.stats[VersionCanary]
+	canarySamples := canary.Summaries + canary.ParseErrors
+	if canarySamples < m.config.MinSamples || stable.Summaries+stable.ParseErrors < m.config.MinSamples {
+		return "", ""
+	}
+	if canary.ParseErrorRate() > stable.ParseErrorRate()+m.config.Tolerance {
+		return ActionRolledBack, fmt.Sprintf("parse error rate %.0f%% against %.0f%%", 100*canary.ParseErrorRate(), 100*stable.ParseErrorRate())
+	}
+	if canary.OverrideRate() > stable.OverrideRate()+m.config.Tolerance {
+		return ActionRolledBack, fmt.Sprintf("override rate %.0f%% against %.0f%%", 100*canary.OverrideRate(), 100*stable.OverrideRate())
+	}
+	if canarySamples >= m.config.PromoteAfter {
+		return ActionPromoted, ""
+	}
+	return "", ""
+}
+
+// settle records the end of a canary and adopts a promoted style. The
+// caller holds the lock.
+func (m *Manager) settle(settled *trial, action, reason string) Entry {
+	previous := m.style
+	if action == ActionPromoted {
+		m.style = settled.name
+	}
+	entry := Entry{
+		Time:     m.now(),
+		Action:   action,
+		Style:    settled.name,
+		Previous: previous,
+		Reason:   reason,
+		Stable:   *settled.stats[VersionStable],
+		Canary:   *settled.stats[VersionCanary],
+	}
+	m.append(entry)
+	return entry
+}
+
+// announce applies a promoted style, then logs the outcome and posts it to
+// the channel
+func (m *Manager) announce(settled *trial, entry Entry) {
+	if entry.Action == ActionPromoted {
+		m.apply(settled.name, settled.style)
+		m.logger.Info("Promoted prompt style canary", zap.String("style", entry.Style), zap.String("previous", entry.Previous))
+	} else {
+		m.logger.Warn("Rolled back prompt style canary",
+			zap.String("style", entry.Style),
+			zap.String("previous", entry.Previous),
+			zap.String("reason", entry.Reason))
+	}
+	if m.config.Channel == "" {
+		return
+	}
+	if err := m.poster.PostMessage(m.baseCtx, m.config.Channel, "prompt_canary", FormatEntry(entry)); err != nil {
+		m.logger.Warn("Failed to post prompt style canary result", zap.Error(err))
+	}
+}
+
+// Rollback ends the running canary and keeps the current style, reporting
+// whether one was running
+func (m *Manager) Rollback(reason string) bool {
+	m.mu.Lock()
+	if m.trial == nil {
+		m.mu.Unlock()
+		return false
+	}
+	settled := m.trial
+	m.trial = nil
+	entry := m.settle(settled, ActionRolledBack, reason)
+	m.mu.Unlock()
+
+	m.announce(settled, entry)
+	return true
+}
+
+// Status returns the current style, the running canary and the audit trail
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := Status{Style: m.style, History: append([]Entry{}, m.history...)}
+	if m.trial != nil {
+		stable, canary := *m.trial.stats[VersionStable], *m.trial.stats[VersionCanary]
+		status.Canary, status.Percent, status.StartedAt = m.trial.name, m.config.Percent, m.trial.started
+		status.Stable, status.Candidate = &stable, &canary
+	}
+	return status
+}
+
+// ServeStatus returns the Status
+func (m *Manager) ServeStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(m.Status()); err != nil {
+		m.logger.Error("Failed to write prompt canary status", zap.Error(err))
+	}
+}
+
+// append adds an entry to the audit trail, dropping the oldest past
+// maxHistory. The caller holds the lock.
+func (m *Manager) append(entry Entry) {
+	m.history = append(m.history, entry)
+	if len(m.history) > maxHistory {
+		m.history = m.history[len(m.history)-maxHistory:]
+	}
+}
+
+// FormatEntry renders a promotion or rollback as a Slack message
+func FormatEntry(entry Entry) string {
+	stats := fmt.Sprintf("New version: %d summaries, %.0f%% parse errors, %.0f%% overridden. Old version: %d summaries, %.0f%% parse errors, %.0f%% overridden.",
+		entry.Canary.Summaries, 100*entry.Canary.ParseErrorRate(), 100*entry.Canary.OverrideRate(),
+		entry.Stable.Summaries, 100*entry.Stable.ParseErrorRate(), 100*entry.Stable.OverrideRate())
+	if entry.Action == ActionPromoted {
+		return fmt.Sprintf(":white_check_mark: *Prompt style `%s` promoted* after its canary, replacing `%s`.\n%s", entry.Style, entry.Previous, stats)
+	}
+	return fmt.Sprintf(":rewind: *Prompt style `%s` rolled back* (%s); `%s` stays in use.\n%s", entry.Style, entry.Reason, entry.Previous, stats)
+}
+
+// versionTag names a version of a canary, so results that arrive after it
+// ended are not counted towards the next one
+func versionTag(name string, id int) string {
+	return name + "/" + strconv.Itoa(id)
+}
+
+// parseVersion reads a version tag
+func parseVersion(version string) (string, int, bool) {
+	name, id, ok := strings.Cut(version, "/")
+	if !ok || (name != VersionStable && name != VersionCanary) {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(id)
+	return name, n, err == nil
+}
@@ -0,0 +1,206 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakePoster struct {
+	messages []string
+}
+
+func (f *fakePoster) PostMessage(ctx context.Context, channelID, kind, text string) error {
+	f.messages = append(f.messages, channelID+": "+text)
+	return nil
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) RecordPromptCanary(version, outcome string) {}
+
+// newTestManager returns a manager and the styles it has applied
+func newTestManager(config Config) (*Manager, *fakePoster, *[]string) {
+	var applied []string
+	poster := &fakePoster{}
+	manager := NewManager(config, "master_analyst", func(name string, style ai.PromptStyle) {
+		applied = append(applied, name)
+	}, poster, nopMetrics{}, zap.NewNop())
+	return manager, poster, &applied
+}
+
+func issue(number int) *github.IssueData {
+	return &github.IssueData{
+		Issue:      &gogithub.Issue{Number: gogithub.Int(number)},
+		Repository: &gogithub.Repository{FullName: gogithub.String("o/r")},
+	}
+}
+
+// versions returns the versions the manager selects for issues 1 to n
+func versions(manager *Manager, n int) map[string][]int {
+	selected := make(map[string][]int)
+	for number := 1; number <= n; number++ {
+		version, _ := manager.SelectStyle(issue(number))
+		selected[version] = append(selected[version], number)
+	}
+	return selected
+}
+
+var testConfig = Config{Percent: 20, MinSamples: 5, PromoteAfter: 10, Tolerance: 0.1, Channel: "C-AI"}
+
+func TestSelectStyle(t *testing.T) {
+	manager, _, _ := newTestManager(testConfig)
+	if version, style := manager.SelectStyle(issue(1)); version != "" || style != nil {
+		t.Errorf("Expected no selection without a canary, got %q", version)
+	}
+
+	candidate := ai.PromptStyle{Personality: "new"}
+	if err := manager.Start("master_analyst", candidate); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Start("security_expert", candidate); err != ErrInProgress {
+		t.Errorf("Expected ErrInProgress, got %v", err)
+	}
+
+	selected := versions(manager, 1000)
+	if canary := len(selected["canary/1"]); canary < 150 || canary > 250 {
+		t.Errorf("Expected about 20%% of issues on the canary, got %d", canary)
+	}
+	if len(selected["canary/1"])+len(selected["stable/1"]) != 1000 {
+		t.Errorf("Expected every issue on a version, got %v", selected)
+	}
+	number := selected["canary/1"][0]
+	for i := 0; i < 3; i++ {
+		if version, style := manager.SelectStyle(issue(number)); version != "canary/1" || style.Personality != "new" {
+			t.Fatalf("Expected issue %d to stay on the canary, got %q", number, version)
+		}
+	}
+}
+
+func TestCanaryPromoted(t *testing.T) {
+	manager, poster, applied := newTestManager(testConfig)
+	manager.Start("security_expert", ai.PromptStyle{Personality: "new"})
+
+	for i := 0; i < testConfig.PromoteAfter; i++ {
+		manager.RecordSummary("stable/1", true)
+		manager.RecordSummary("canary/1", true)
+	}
+	if strings.Join(*applied, ",") != "security_expert" {
+		t.Errorf("Expected the new style applied, got %v", *applied)
+	}
+	status := manager.Status()
+	if status.Style != "security_expert" || status.Canary != "" {
+		t.Errorf("Expected the canary promoted, got %+v", status)
+	}
+	if len(status.History) != 2 || status.History[1].Action != ActionPromoted || status.History[1].Canary.Summaries != 10 {
+		t.Errorf("Expected started and promoted entries, got %+v", status.History)
+	}
+	if len(poster.messages) != 1 || !strings.Contains(poster.messages[0], "C-AI: :white_check_mark: *Prompt style `security_expert` promoted*") {
+		t.Errorf("Expected the promotion posted, got %v", poster.messages)
+	}
+
+	// Results of the finished canary are not counted towards the next
+	manager.Start("master_analyst", ai.PromptStyle{})
+	manager.RecordSummary("canary/1", false)
+	if status := manager.Status(); status.Candidate.ParseErrors != 0 {
+		t.Errorf("Expected stale results ignored, got %+v", status.Candidate)
+	}
+}
+
+func TestCanaryRolledBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		record func(m *Manager)
+		reason string
+	}{
+		{
+			name: "parse errors",
+			record: func(m *Manager) {
+				for i := 0; i < 5; i++ {
+					m.RecordSummary("stable/1", true)
+					m.RecordSummary("canary/1", i%2 == 0)
+				}
+			},
+			reason: "parse error rate 40% against 0%",
+		},
+		{
+			name: "overrides",
+			record: func(m *Manager) {
+				for i := 0; i < 4; i++ {
+					m.RecordSummary("stable/1", true)
+					m.RecordSummary("canary/1", true)
+				}
+				m.RecordOverride("canary/1")
+				m.RecordOverride("canary/1")
+				m.RecordSummary("stable/1", true)
+				m.RecordSummary("canary/1", true)
+			},
+			reason: "override rate 40% against 0%",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, poster, applied := newTestManager(testConfig)
+			manager.Start("security_expert", ai.PromptStyle{Personality: "new"})
+			tt.record(manager)
+
+			if len(*applied) != 0 {
+				t.Errorf("Expected no style applied, got %v", *applied)
+			}
+			status := manager.Status()
+			last := status.History[len(status.History)-1]
+			if status.Style != "master_analyst" || status.Canary != "" || last.Action != ActionRolledBack || last.Reason != tt.reason {
+				t.Errorf("Expected a rollback for %q, got %+v", tt.reason, status)
+			}
+			if len(poster.messages) != 1 || !strings.Contains(poster.messages[0], fmt.Sprintf("rolled back* (%s)", tt.reason)) {
+				t.Errorf("Expected the rollback posted, got %v", poster.messages)
+			}
+			if version, _ := manager.SelectStyle(issue(1)); version != "" {
+				t.Errorf("Expected no canary after the rollback, got %q", version)
+			}
+		})
+	}
+}
+
+func TestCanaryWaitsForSamples(t *testing.T) {
+	manager, _, _ := newTestManager(testConfig)
+	manager.Start("security_expert", ai.PromptStyle{})
+	// The canary parses badly, but the old version has too few summaries to
+	// compare with
+	for i := 0; i < testConfig.PromoteAfter; i++ {
+		manager.RecordSummary("canary/1", false)
+	}
+	manager.RecordSummary("stable/1", true)
+	if status := manager.Status(); status.Canary == "" {
+		t.Errorf("Expected the canary to keep running, got %+v", status)
+	}
+	if !manager.Rollback("stopped by an operator") || manager.Rollback("again") {
+		t.Error("Expected the running canary rolled back once")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		config Config
+		valid  bool
+	}{
+		{Config{}, true},
+		{testConfig, true},
+		{Config{Percent: 100, MinSamples: 5, PromoteAfter: 10}, false},
+		{Config{Percent: 10, MinSamples: 0, PromoteAfter: 10}, false},
+		{Config{Percent: 10, MinSamples: 20, PromoteAfter: 10}, false},
+		{Config{Percent: 10, MinSamples: 5, PromoteAfter: 10, Tolerance: 1}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.config.Validate(); (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tt.config, err, tt.valid)
+		}
+	}
+}
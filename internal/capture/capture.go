@@ -0,0 +1,193 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+// redacted replaces the values of sensitive fields
+const redacted = "[REDACTED]"
+
+// sensitiveKey matches payload fields whose values are always stripped,
+// whatever they look like. Fields ending in _url only hold API links.
+var sensitiveKey = regexp.MustCompile(`(?i)(secret|token|password|passwd|credential|private_key|api_key|authorization|signature)`)
+
+// Capture is a webhook delivery as it was received, with secrets stripped
+type Capture struct {
+	ID         int       `json:"id"`
+	Tenant     string    `json:"tenant,omitempty"`
+	EventType  string    `json:"event_type"`
+	DeliveryID string    `json:"delivery_id,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	Repository string    `json:"repository,omitempty"`
+	Number     int       `json:"number,omitempty"` // Issue number, zero for repository events
+	ReceivedAt time.Time `json:"received_at"`
+	Redacted   []string  `json:"redacted,omitempty"` // Kinds of secrets stripped from the payload
+
+	// Payload is omitted from listings
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Store keeps the last deliveries in memory, oldest dropped first
+type Store struct {
+	mu       sync.Mutex
+	captures []Capture
+	size     int
+	nextID   int
+}
+
+// NewStore creates a store for the last size deliveries
+func NewStore(size int) *Store {
+	return &Store{size: size, nextID: 1}
+}
+
+// Recorder captures the deliveries of one tenant into a store
+type Recorder struct {
+	store  *Store
+	tenant string
+}
+
+// Tenant returns a recorder that tags the deliveries it captures with
+// tenant, empty for a single-tenant deployment
+func (s *Store) Tenant(name string) *Recorder {
+	return &Recorder{store: s, tenant: name}
+}
+
+// Capture sanitizes and stores a delivery. Payloads that are not JSON are
+// not kept.
+func (r *Recorder) Capture(eventType, deliveryID string, payload []byte, receivedAt time.Time) {
+	sanitized, kinds, err := Sanitize(payload)
+	if err != nil {
+		return
+	}
+	var event struct {
+		Action     string `json:"action"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+		Issue struct {
+			Number int `json:"number"`
+		} `json:"issue"`
+	}
+	json.Unmarshal(sanitized, &event)
+
+	r.store.add(Capture{
+		Tenant:     r.tenant,
+		EventType:  eventType,
+		DeliveryID: deliveryID,
+		Action:     event.Action,
+		Repository: event.Repository.FullName,
+		Number:     event.Issue.Number,
+		ReceivedAt: receivedAt,
+		Redacted:   kinds,
+		Payload:    sanitized,
+	})
+}
+
+// add numbers and stores a capture, dropping the oldest once the store is
+// full
+func (s *Store) add(capture Capture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	capture.ID = s.nextID
+	s.nextID++
+	s.captures = append(s.captures, capture)
+	if len(s.captures) > s.size {
+		s.captures = s.captures[len(s.captures)-s.size:]
+	}
+}
+
+// List returns the captures of repository, and of issue number in it,
+// newest first and without payloads. Empty filters match every capture.
+func (s *Store) List(repository string, number int) []Capture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	captures := []Capture{}
+	for i := len(s.captures) - 1; i >= 0; i-- {
+		capture := s.captures[i]
+		if repository != "" && !strings.EqualFold(capture.Repository, repository) {
+			continue
+		}
+		if number != 0 && capture.Number != number {
+			continue
+		}
+		capture.Payload = nil
+		captures = append(captures, capture)
+	}
+	return captures
+}
+
+// Get returns a capture with its payload
+func (s *Store) Get(id int) (Capture, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, capture := range s.captures {
+		if capture.ID == id {
+			return capture, true
+		}
+	}
+	return Capture{}, false
+}
+
+// Sanitize strips secrets from a JSON payload: the values of sensitive
+// fields, and credentials in well-known formats anywhere else, such as a
+// token pasted into an issue. It returns the kinds of secrets stripped.
+func Sanitize(payload []byte) (json.RawMessage, []string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[string]bool)
+	value = sanitize(value, "", found)
+	var sanitized bytes.Buffer
+	encoder := json.NewEncoder(&sanitized)
+	encoder.SetEscapeHTML(false) // Keep issue text as it was received
+	if err := encoder.Encode(value); err != nil {
+		return nil, nil, err
+	}
+
+	var kinds []string
+	for kind := range found {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return bytes.TrimSpace(sanitized.Bytes()), kinds, nil
+}
+
+// sanitize strips secrets from a decoded JSON value found under key
+func sanitize(value interface{}, key string, found map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = sanitize(child, k, found)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = sanitize(child, key, found)
+		}
+		return v
+	case string:
+		if v != "" && sensitiveKey.MatchString(key) && !strings.HasSuffix(key, "_url") {
+			found[key+" field"] = true
+			return redacted
+		}
+		text, kinds := ai.RedactSecrets(v)
+		for _, kind := range kinds {
+			found[kind] = true
+		}
+		return text
+	default:
+		return v
+	}
+}
@@ -0,0 +1,190 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// Replayer processes captured deliveries again
+type Replayer interface {
+	Replay(ctx context.Context, eventType, deliveryID string, payload []byte) (*github.IssueData, error)
+	EnrichDelivery(ctx context.Context, eventType string, payload []byte) (*github.IssueData, error)
+}
+
+// DryRunner shows what processing an issue would post, without posting it
+type DryRunner interface {
+	DryRun(ctx context.Context, issueData *github.IssueData) (*pipeline.DryRun, error)
+}
+
+type replayPipeline struct {
+	replayer  Replayer
+	dryRunner DryRunner
+}
+
+// Replay is the outcome of replaying a capture
+type Replay struct {
+	Capture    int              `json:"capture"`
+	Repository string           `json:"repository,omitempty"`
+	Number     int              `json:"number,omitempty"`
+	Skipped    bool             `json:"skipped,omitempty"` // The bot does not process the delivery
+	DryRun     *pipeline.DryRun `json:"dry_run,omitempty"`
+}
+
+// Handler lists captured deliveries and replays them through the pipeline of
+// the tenant that received them. It requires the admin token.
+type Handler struct {
+	mu         sync.Mutex
+	store      *Store
+	pipelines  map[string]replayPipeline
+	adminToken *admin.Token
+	logger     *zap.Logger
+}
+
+// NewHandler creates a handler for the captures in store, with no pipelines
+func NewHandler(store *Store, adminToken *admin.Token, logger *zap.Logger) *Handler {
+	return &Handler{
+		store:      store,
+		pipelines:  make(map[string]replayPipeline),
+		adminToken: adminToken,
+		logger:     logger,
+	}
+}
+
+// AddPipeline replays the captures of tenant name, empty for a
+// single-tenant deployment
+func (h *Handler) AddPipeline(name string, replayer Replayer, dryRunner DryRunner) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pipelines[name] = replayPipeline{replayer: replayer, dryRunner: dryRunner}
+}
+
+// ServeCaptures lists the captures, newest first, filtered by the optional
+// repository and number parameters
+func (h *Handler) ServeCaptures(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	query := r.URL.Query()
+	number := 0
+	if value := query.Get("number"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "number must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		number = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(h.store.List(query.Get("repository"), number))
+}
+
+// ServeCapture returns the capture named by the last path segment, e.g.
+// /api/captures/12, with its payload
+func (h *Handler) ServeCapture(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	capture, ok := h.capture(w, path.Base(r.URL.Path))
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(capture)
+}
+
+// ServeReplay replays the capture named by the path segment before the last,
+// e.g. /api/captures/12/replay. The issue is processed in the background and
+// posted as usual, or with ?dry_run=true only summarized and routed, and the
+// Slack message it would post returned. A replay is turned away with 503
+// while the webhook queue is full.
+func (h *Handler) ServeReplay(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	capture, ok := h.capture(w, path.Base(path.Dir(r.URL.Path)))
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	p, ok := h.pipelines[capture.Tenant]
+	h.mu.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no pipeline for tenant %q", capture.Tenant), http.StatusNotFound)
+		return
+	}
+
+	replay := Replay{Capture: capture.ID}
+	status := http.StatusAccepted
+	if dryRun {
+		issueData, err := p.replayer.EnrichDelivery(r.Context(), capture.EventType, capture.Payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if replay.DryRun, err = p.dryRunner.DryRun(r.Context(), issueData); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		replay.Repository, replay.Number = issueData.Repository.GetFullName(), issueData.Issue.GetNumber()
+		status = http.StatusOK
+	} else {
+		issueData, err := p.replayer.Replay(r.Context(), capture.EventType, capture.DeliveryID, capture.Payload)
+		if errors.Is(err, github.ErrQueueFull) {
+			http.Error(w, "webhook queue is full, try the replay again later", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if issueData == nil {
+			replay.Skipped = true
+			status = http.StatusOK
+		} else {
+			replay.Repository, replay.Number = issueData.Repository.GetFullName(), issueData.Issue.GetNumber()
+		}
+	}
+
+	h.logger.Info("Replayed captured webhook",
+		zap.Int("capture", capture.ID),
+		zap.String("tenant", capture.Tenant),
+		zap.String("event_type", capture.EventType),
+		zap.String("delivery_id", capture.DeliveryID),
+		zap.Bool("dry_run", dryRun),
+		zap.Bool("skipped", replay.Skipped))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(replay)
+}
+
+// capture looks up a capture by its ID, answering 400 or 404 if there is
+// none
+func (h *Handler) capture(w http.ResponseWriter, value string) (Capture, bool) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		http.Error(w, "capture id must be an integer", http.StatusBadRequest)
+		return Capture{}, false
+	}
+	capture, ok := h.store.Get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no capture %d; only the last deliveries are kept", id), http.StatusNotFound)
+		return Capture{}, false
+	}
+	return capture, true
+}
@@ -0,0 +1,99 @@
+package components
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Component maps source paths and labels to a named area of the codebase
+type Component struct {
+	Name   string   `mapstructure:"name" json:"name"`
+	Paths  []string `mapstructure:"paths" json:"paths"`   // Globs, "**" matches any number of directories, e.g. "internal/slack/**"
+	Labels []string `mapstructure:"labels" json:"labels"` // Issue labels that tag the component, e.g. "area/slack"
+}
+
+// Detector tags issues with the components they affect
+type Detector struct {
+	components []Component
+}
+
+// NewDetector validates the component mapping and creates a detector
+func NewDetector(components []Component) (*Detector, error) {
+	for i, component := range components {
+		if component.Name == "" {
+			return nil, fmt.Errorf("component %d: name is required", i)
+		}
+		for _, pattern := range component.Paths {
+			for _, segment := range strings.Split(pattern, "/") {
+				if _, err := path.Match(segment, ""); err != nil {
+					return nil, fmt.Errorf("component %s: invalid path pattern %q: %w", component.Name, pattern, err)
+				}
+			}
+		}
+	}
+	return &Detector{components: components}, nil
+}
+
+// Detect returns the components touched by the changed files or tagged by
+// the labels, in configuration order
+func (d *Detector) Detect(files, labels []string) []string {
+	var detected []string
+	for _, component := range d.components {
+		if component.matchesLabels(labels) || component.matchesFiles(files) {
+			detected = append(detected, component.Name)
+		}
+	}
+	return detected
+}
+
+func (c Component) matchesLabels(labels []string) bool {
+	for _, label := range labels {
+		for _, want := range c.Labels {
+			if strings.EqualFold(label, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c Component) matchesFiles(files []string) bool {
+	for _, file := range files {
+		for _, pattern := range c.Paths {
+			if MatchPath(pattern, file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether a slash-separated file path matches a glob
+// pattern. Besides path.Match syntax within a segment, a "**" segment
+// matches zero or more directories.
+func MatchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(strings.TrimPrefix(name, "/"), "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try every possible number of directories for the wildcard
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
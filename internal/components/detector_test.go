@@ -0,0 +1,60 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"internal/slack/**", "internal/slack/notifier.go", true},
+		{"internal/slack/**", "internal/slack/blocks/section.go", true},
+		{"internal/slack/**", "internal/slackbot/main.go", false},
+		{"**/*_test.go", "internal/ai/summarizer_test.go", true},
+		{"**/*_test.go", "main_test.go", true},
+		{"cmd/*/main.go", "cmd/server/main.go", true},
+		{"cmd/*/main.go", "cmd/server/sub/main.go", false},
+		{"k8s/**/*.yaml", "k8s/base/namespace.yaml", true},
+		{"Dockerfile", "Dockerfile", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	detector, err := NewDetector([]Component{
+		{Name: "notifications", Paths: []string{"internal/slack/**"}, Labels: []string{"area/slack"}},
+		{Name: "ai", Paths: []string{"internal/ai/**"}},
+		{Name: "deploy", Paths: []string{"k8s/**", "Dockerfile"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := detector.Detect([]string{"k8s/base/rbac.yaml", "internal/ai/prompts.go"}, []string{"Area/Slack"})
+	want := []string{"notifications", "ai", "deploy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if got := detector.Detect([]string{"README.md"}, nil); got != nil {
+		t.Errorf("Expected no components, got %v", got)
+	}
+}
+
+func TestNewDetectorValidation(t *testing.T) {
+	if _, err := NewDetector([]Component{{Paths: []string{"a/**"}}}); err == nil {
+		t.Error("Expected missing name to be rejected")
+	}
+	if _, err := NewDetector([]Component{{Name: "bad", Paths: []string{"a/[/b"}}}); err == nil {
+		t.Error("Expected invalid pattern to be rejected")
+	}
+}
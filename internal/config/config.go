@@ -1,13 +1,40 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/breaker"
+	"github-issue-ai-bot/internal/broker"
+	"github-issue-ai-bot/internal/canary"
+	"github-issue-ai-bot/internal/components"
+	"github-issue-ai-bot/internal/drift"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/identity"
+	"github-issue-ai-bot/internal/leaderboard"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/notify"
+	"github-issue-ai-bot/internal/oncall"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/postprocess"
+	"github-issue-ai-bot/internal/ratelimit"
+	"github-issue-ai-bot/internal/resolution"
+	"github-issue-ai-bot/internal/resources"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/slack"
+	"github-issue-ai-bot/internal/support"
+	"github-issue-ai-bot/internal/teams"
+	"github-issue-ai-bot/internal/tracker"
+	"github-issue-ai-bot/internal/usage"
 )
 
 // Config holds all configuration for the application
@@ -17,7 +44,36 @@ type Config struct {
 	OpenAI   OpenAIConfig
 	Slack    SlackConfig
 	Monitor  MonitorConfig
+	Secrets  SecretsConfig
+	Routing  RoutingConfig
+	Ingest   IngestConfig
+	Pipeline PipelineConfig
+	OnCall   OnCallConfig
+	Teams    TeamsConfig
+	Timeouts TimeoutConfig
+	Breaker  breaker.Config   // Circuit breakers around the GitHub, OpenAI and Slack clients
+	Limits   ratelimit.Config // Rate limits of the webhook and API endpoints
+	Support  support.Config   // Zendesk and Intercom tickets
+	Trackers TrackerConfig
+	Identity IdentityConfig
 	LogLevel string
+
+	// SummaryLog receives one JSON record per processed issue: stdout,
+	// stderr or a file path. Empty disables it.
+	SummaryLog string
+
+	// Tenants share the deployment in multi-tenant mode. They are read from
+	// the tenants key of the config file.
+	Tenants []TenantConfig
+
+	// Notifiers receive summaries besides Slack, e.g. webhooks. They are
+	// read from the notifiers key of the config file.
+	Notifiers []notify.Config
+
+	settings []Value       // Effective environment settings, as read by Load
+	problems SettingErrors // Invalid settings found by Load
+	warnings []string      // Deprecated settings in use
+	file     string        // Config file read, empty for none
 }
 
 // ServerConfig holds server-related configuration
@@ -26,13 +82,77 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	AdminToken       string // Bearer token for the admin and export APIs, empty disables them
+	ExportSigningKey string // HMAC key for signed export download URLs
+	PublicURL        string // Externally reachable base URL, used in signed URLs
+
+	// Shadow runs the whole pipeline but holds back everything it would
+	// post to Slack or change on GitHub, for review through the API
+	Shadow bool
+
+	// Capture keeps the sanitized payloads of the last CaptureSize webhook
+	// deliveries, to list and replay through the admin API
+	Capture     bool
+	CaptureSize int
 }
 
 // GitHubConfig holds GitHub-related configuration
 type GitHubConfig struct {
-	WebhookSecret string
-	AccessToken   string
-	BaseURL       string
+	WebhookSecret    string
+	AccessToken      string
+	BaseURL          string
+	CloseSuggestions bool // Offer to close issues that look resolved or duplicated
+	CommentLimit     int  // Comments fetched per issue: the first and the most recent
+
+	// Sources restricts the addresses webhooks are accepted from
+	Sources github.SourceConfig
+
+	// Attachments reads log files linked from issues into the prompt
+	Attachments github.AttachmentConfig
+
+	// LinkedIssues prefetches the issues an issue references for Slack
+	LinkedIssues github.LinkedIssueConfig
+
+	// Regressions hint at the release that may have introduced a bug
+	Regressions github.RegressionConfig
+
+	// CIFailures posts issues opened by CI as build break cards
+	CIFailures github.CIConfig
+
+	// Stack detects the languages and frameworks of repositories for the prompt
+	Stack github.StackConfig
+
+	// Commands runs "/notifyops" commands from issue comments
+	Commands github.CommandConfig
+
+	// Checks publishes triage results on the pull requests linked to issues
+	Checks github.CheckConfig
+
+	// Queue bounds background processing of webhooks in monolith mode
+	Queue github.QueueConfig
+
+	// Poll reads issues through the REST API for repositories without webhooks
+	Poll github.PollConfig
+
+	// PermissionCheck probes the access token's permissions on a repository
+	// at startup and refuses to start when any the enabled features need
+	// are missing
+	PermissionCheck           bool
+	PermissionCheckRepository string // owner/name, defaults to the first configured repository
+
+	// ActionRules override the default event × action behaviors. They are
+	// read from the events.rules key of the config file.
+	ActionRules []github.ActionRule
+
+	// Enrichment orders the enrichment stages per repository. Its rules and
+	// per-stage timeouts are read from the enrichment key of the config file.
+	Enrichment github.EnrichmentConfig
+
+	// CommentTemplates set the Markdown of the bot's issue comments per
+	// repository. They are read from the comments.templates key of the
+	// config file.
+	CommentTemplates []github.CommentTemplate
 }
 
 // OpenAIConfig holds OpenAI-related configuration
@@ -42,27 +162,155 @@ type OpenAIConfig struct {
 	MaxTokens   int
 	Temperature float64
 	PromptStyle string // Name of the prompt style to use
+	TriageModel string // Cheaper model for the triage stage in two-stage mode
+
+	// Models add to or override the known model limits. They are read from
+	// the openai.models key of the config file.
+	Models []ai.ModelInfo
+
+	// Client sets the organization and project usage is billed to, and how
+	// the API is reached: base URL, outbound proxy and TLS trust
+	Client ai.ClientOptions
+
+	// Canary rolls prompt style changes made at runtime out to a share of
+	// issues first
+	Canary canary.Config
 }
 
 // SlackConfig holds Slack-related configuration
 type SlackConfig struct {
-	BotToken      string
-	SigningSecret string
-	ChannelID     string
+	BotToken        string
+	SigningSecret   string
+	ChannelID       string
+	MessageTemplate string // Path to a Go template that renders the Slack message blocks
+	// FallbackTemplate is an inline Go template for the plain-text fallback
+	// shown in notifications and read by screen readers; empty for the default
+	FallbackTemplate string
+	Accessibility    pipeline.AccessibilityConfig
+	// Urgency mentions and colors messages by priority. Levels are read from
+	// the slack.levels key of the config file, and channels' timezones for
+	// quiet hours from slack.timezones.
+	Urgency pipeline.UrgencyConfig
+	// IssueShortcut offers repositories and labels to the "Create GitHub
+	// issue" message shortcut, which is enabled when repositories are set
+	IssueShortcut slack.IssueShortcutConfig
+	// RequireWriteAccess makes buttons and shortcuts that change GitHub check
+	// that the user's linked GitHub account has write access to the repository
+	RequireWriteAccess bool
+	// WorkflowSteps serves the "Summarize issue" and "Get suggested fix"
+	// Workflow Builder steps at /webhook/slack/events
+	WorkflowSteps bool
+}
+
+// RoutingConfig holds per-channel routing rules. Rules are read from the
+// routing.rules key of the config file.
+type RoutingConfig struct {
+	DefaultLayout string // Layout for issues that match no rule: detailed, compact or plain
+	Rules         []routing.Rule
+}
+
+// Summary modes
+const (
+	SummaryModeFull     = "full"      // Run the full analysis for every issue
+	SummaryModeTwoStage = "two_stage" // Triage every issue, analyze in depth above a priority or on demand
+)
+
+// PipelineConfig holds issue processing settings
+type PipelineConfig struct {
+	SummaryMode          string
+	DeepAnalysisPriority string        // Lowest triage priority that gets the deep analysis in two-stage mode
+	ChangeThreshold      float64       // Significance score at which an edited issue is re-summarized
+	PrefilterEnabled     bool          // Note trivial issues in Slack without calling OpenAI
+	TranslationEnabled   bool          // Translate non-English issues into English before analysis
+	MemoryMaxTokens      int           // Token budget for each issue's memory of earlier analyses
+	IncidentChannels     bool          // Open a dedicated Slack channel for incidents
+	RateLimit            int           // Messages each repository may post per RateWindow, 0 for no limit
+	RateWindow           time.Duration // Sliding window for RateLimit
+	AutoLabel            bool          // Add the taxonomy's labels for the AI priority and category to issues
+	Acknowledgement      pipeline.AckConfig
+	InfoRequests         pipeline.InfoRequestConfig
+	Incidents            pipeline.IncidentConfig
+	Prefilter            pipeline.PrefilterConfig
+	ReactionBoost        pipeline.BoostConfig
+	Reanalysis           pipeline.ReanalysisConfig
+	Engagement           pipeline.EngagementConfig
+	Moderation           pipeline.ModerationConfig
+	Components           []components.Component // Read from the components key of the config file
+	Taxonomy             ai.TaxonomyConfig      // Read from the taxonomy key of the config file
+	PostProcessors       []postprocess.Config   // Read, in order, from the postprocessors key of the config file
+}
+
+// OnCallConfig holds on-call schedules. Schedules are read from the
+// oncall.schedules key of the config file.
+type OnCallConfig struct {
+	MentionPriority string // Lowest priority that mentions the on-call engineer
+	Schedules       []oncall.Schedule
+}
+
+// TeamsConfig maps repositories to teams for rollups. Teams are read from
+// the teams key of the config file.
+type TeamsConfig struct {
+	Teams  []teams.Team
+	Digest schedule.Schedule // When each team's weekly digest is posted to its channel; no weekday disables digests
+}
+
+// TrackerConfig links actionable issues to Linear or Shortcut tickets.
+// Mappings are read from the trackers key of the config file.
+type TrackerConfig struct {
+	Mappings              []tracker.Mapping
+	LinearAPIKey          string
+	LinearWebhookSecret   string
+	ShortcutAPIToken      string
+	ShortcutWebhookSecret string
+}
+
+// IdentityConfig maps GitHub accounts to Slack users, for mentions and to
+// attribute button clicks. Identities are read from the identities key of
+// the config file.
+type IdentityConfig struct {
+	Identities    []identity.Identity
+	EmailMatching bool // Match unmapped accounts by their GitHub and Slack emails
+}
+
+// TimeoutConfig limits each stage of processing an issue. Zero disables a limit.
+type TimeoutConfig struct {
+	Enrich time.Duration // Fetching comments, commits and files from GitHub
+	AI     time.Duration // Translation and analysis, or generating a fix suggestion
+	Slack  time.Duration // Posting and updating messages and incident channels
+}
+
+// IngestConfig selects how webhooks reach the processing pipeline
+type IngestConfig struct {
+	Mode   string // monolith, receiver or worker
+	Broker broker.Config
 }
 
 // MonitorConfig holds monitoring-related configuration
 type MonitorConfig struct {
-	MetricsPort string
-	MetricsPath string
+	MetricsPort           string
+	MetricsPath           string
+	SLOAvailabilityTarget float64       // Share of issues that must reach Slack, e.g. 0.99
+	SLOLatencyTarget      time.Duration // Webhook-to-Slack latency objective
+	Metrics               monitor.Options
+	Drift                 drift.Config       // Shifts in the priorities and categories the AI assigns
+	Resources             resources.Config   // Goroutine and memory sampling, and throttling under memory pressure
+	Resolution            resolution.Config  // Monthly report of resolution times by AI priority
+	Leaderboard           leaderboard.Config // Monthly ranking of who triaged the most issues
+	Usage                 usage.Config       // Weekly report of OpenAI, Slack and GitHub usage
 }
 
 // Load loads configuration from environment variables and files
 func Load() (*Config, error) {
-	// Load .env file if it exists
+	// Load .env file if it exists, noting which variables were set before it
+	exported := make(map[string]bool)
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		exported[name] = true
+	}
 	if err := godotenv.Load(); err != nil {
 		// It's okay if .env file doesn't exist
 	}
+	env := newEnv(exported)
 
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -83,51 +331,595 @@ func Load() (*Config, error) {
 	// Environment variables override config file
 	viper.AutomaticEnv()
 
+	secrets := SecretsConfig{
+		Dir:            env.String("SECRETS_DIR"),
+		ReloadInterval: env.Duration("SECRETS_RELOAD_INTERVAL"),
+		Files:          make(map[string]string),
+
+		Provider:        env.String("SECRETS_PROVIDER"),
+		ProviderRefresh: env.Duration("SECRETS_PROVIDER_REFRESH_INTERVAL"),
+	}
+	env.secrets = &secrets
+	secrets.Vault = VaultConfig{
+		Address:    env.String("VAULT_ADDR"),
+		Token:      env.Secret("VAULT_TOKEN"),
+		Role:       env.String("VAULT_ROLE"),
+		AuthPath:   env.String("VAULT_AUTH_PATH"),
+		SecretPath: env.String("VAULT_SECRET_PATH"),
+		Namespace:  env.String("VAULT_NAMESPACE"),
+	}
+	secrets.AWS = AWSSecretsConfig{
+		Region:          env.String("AWS_REGION"),
+		SecretID:        env.String("AWS_SECRET_ID"),
+		Endpoint:        env.String("AWS_SECRETS_ENDPOINT"),
+		AccessKeyID:     env.String("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: env.Secret("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    env.Secret("AWS_SESSION_TOKEN"),
+	}
+
 	config := &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8080"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 30*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
-			IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			Port:         env.String("SERVER_PORT"),
+			ReadTimeout:  env.Duration("SERVER_READ_TIMEOUT"),
+			WriteTimeout: env.Duration("SERVER_WRITE_TIMEOUT"),
+			IdleTimeout:  env.Duration("SERVER_IDLE_TIMEOUT"),
+
+			AdminToken:       env.Secret("ADMIN_TOKEN"),
+			ExportSigningKey: env.Secret("EXPORT_SIGNING_KEY"),
+			PublicURL:        env.String("PUBLIC_URL"),
+
+			Shadow: env.Bool("SHADOW_MODE"),
+
+			Capture:     env.Bool("WEBHOOK_CAPTURE"),
+			CaptureSize: env.Int("WEBHOOK_CAPTURE_SIZE"),
 		},
 		GitHub: GitHubConfig{
-			WebhookSecret: getEnv("GITHUB_WEBHOOK_SECRET", ""),
-			AccessToken:   getEnv("GITHUB_ACCESS_TOKEN", ""),
-			BaseURL:       getEnv("GITHUB_BASE_URL", "https://api.github.com"),
+			WebhookSecret:    env.Secret("GITHUB_WEBHOOK_SECRET"),
+			AccessToken:      env.Secret("GITHUB_ACCESS_TOKEN"),
+			BaseURL:          env.String("GITHUB_BASE_URL"),
+			CloseSuggestions: env.Bool("GITHUB_CLOSE_SUGGESTIONS"),
+			CommentLimit:     env.Int("GITHUB_COMMENT_LIMIT"),
+
+			PermissionCheck:           env.Bool("GITHUB_PERMISSION_CHECK"),
+			PermissionCheckRepository: env.String("GITHUB_PERMISSION_CHECK_REPOSITORY"),
+
+			Attachments: github.AttachmentConfig{
+				Enabled:  env.Bool("GITHUB_FETCH_ATTACHMENTS"),
+				MaxBytes: int64(env.Int("ATTACHMENT_MAX_BYTES")),
+				MaxFiles: env.Int("ATTACHMENT_MAX_FILES"),
+				MaxLines: env.Int("ATTACHMENT_MAX_LINES"),
+			},
+			Sources: github.SourceConfig{
+				Enabled:        env.Bool("GITHUB_WEBHOOK_IP_ALLOWLIST"),
+				Meta:           env.Bool("GITHUB_WEBHOOK_META"),
+				Refresh:        env.Duration("GITHUB_META_REFRESH"),
+				AllowedCIDRs:   env.List("GITHUB_WEBHOOK_ALLOWED_IPS"),
+				TrustedProxies: env.List("GITHUB_WEBHOOK_TRUSTED_PROXIES"),
+			},
+			LinkedIssues: github.LinkedIssueConfig{
+				Enabled:  env.Bool("GITHUB_PREFETCH_LINKED"),
+				TTL:      env.Duration("LINKED_ISSUE_TTL"),
+				MaxLinks: env.Int("LINKED_ISSUE_MAX"),
+			},
+			Regressions: github.RegressionConfig{
+				Enabled: env.Bool("GITHUB_REGRESSION_HINTS"),
+				Window:  env.Duration("REGRESSION_WINDOW"),
+			},
+			Stack: github.StackConfig{
+				Enabled: env.Bool("GITHUB_DETECT_STACK"),
+				TTL:     env.Duration("STACK_CACHE_TTL"),
+			},
+			CIFailures: github.CIConfig{
+				Enabled:  env.Bool("CI_FAILURE_ENABLED"),
+				Labels:   env.List("CI_FAILURE_LABELS"),
+				Authors:  env.List("CI_FAILURE_AUTHORS"),
+				MaxBytes: int64(env.Int("CI_LOG_MAX_BYTES")),
+				MaxLines: env.Int("CI_LOG_MAX_LINES"),
+			},
+			Commands: github.CommandConfig{
+				Enabled:    env.Bool("GITHUB_COMMANDS"),
+				Permission: env.String("GITHUB_COMMAND_PERMISSION"),
+			},
+			Checks: github.CheckConfig{
+				Mode: env.String("GITHUB_CHECKS"),
+			},
+			Enrichment: github.EnrichmentConfig{
+				StageTimeout: env.Duration("ENRICHMENT_STAGE_TIMEOUT"),
+			},
+			Queue: github.QueueConfig{
+				Workers:    env.Int("WEBHOOK_WORKERS"),
+				Size:       env.Int("WEBHOOK_QUEUE_SIZE"),
+				Policy:     env.String("WEBHOOK_SATURATION_POLICY"),
+				SpoolSize:  env.Int("WEBHOOK_SPOOL_SIZE"),
+				RetryAfter: env.Duration("WEBHOOK_RETRY_AFTER"),
+			},
+			Poll: github.PollConfig{
+				Repositories: env.List("GITHUB_POLL_REPOSITORIES"),
+				Interval:     env.Duration("GITHUB_POLL_INTERVAL"),
+				Lookback:     env.Duration("GITHUB_POLL_LOOKBACK"),
+			},
 		},
 		OpenAI: OpenAIConfig{
-			APIKey:      getEnv("OPENAI_API_KEY", ""),
-			Model:       getEnv("OPENAI_MODEL", "gpt-4"),
-			MaxTokens:   getIntEnv("OPENAI_MAX_TOKENS", 2000),
-			Temperature: getFloatEnv("OPENAI_TEMPERATURE", 0.7),
-			PromptStyle: getEnv("OPENAI_PROMPT_STYLE", "master_analyst"),
+			APIKey:      env.Secret("OPENAI_API_KEY"),
+			Model:       env.String("OPENAI_MODEL"),
+			MaxTokens:   env.Int("OPENAI_MAX_TOKENS"),
+			Temperature: env.Float("OPENAI_TEMPERATURE"),
+			PromptStyle: env.String("OPENAI_PROMPT_STYLE"),
+			TriageModel: env.String("OPENAI_TRIAGE_MODEL"),
+			Client: ai.ClientOptions{
+				OrgID:              env.String("OPENAI_ORG_ID"),
+				ProjectID:          env.String("OPENAI_PROJECT_ID"),
+				BaseURL:            env.String("OPENAI_BASE_URL"),
+				ProxyURL:           env.String("OPENAI_PROXY_URL"),
+				CACertFile:         env.String("OPENAI_CA_CERT_FILE"),
+				InsecureSkipVerify: env.Bool("OPENAI_TLS_INSECURE_SKIP_VERIFY"),
+			},
+			Canary: canary.Config{
+				Percent:      env.Int("PROMPT_CANARY_PERCENT"),
+				MinSamples:   env.Int("PROMPT_CANARY_MIN_SAMPLES"),
+				PromoteAfter: env.Int("PROMPT_CANARY_PROMOTE_AFTER"),
+				Tolerance:    env.Float("PROMPT_CANARY_TOLERANCE"),
+				Channel:      env.String("PROMPT_CANARY_CHANNEL"),
+			},
 		},
 		Slack: SlackConfig{
-			BotToken:      getEnv("SLACK_BOT_TOKEN", ""),
-			SigningSecret: getEnv("SLACK_SIGNING_SECRET", ""),
-			ChannelID:     getEnv("SLACK_CHANNEL_ID", ""),
+			BotToken:         env.Secret("SLACK_BOT_TOKEN"),
+			SigningSecret:    env.Secret("SLACK_SIGNING_SECRET"),
+			ChannelID:        env.String("SLACK_CHANNEL_ID"),
+			MessageTemplate:  env.String("SLACK_MESSAGE_TEMPLATE"),
+			FallbackTemplate: env.String("SLACK_FALLBACK_TEMPLATE"),
+			Accessibility: pipeline.AccessibilityConfig{
+				NoEmojiChannels:   env.List("SLACK_NO_EMOJI_CHANNELS"),
+				PlainTextChannels: env.List("SLACK_PLAIN_TEXT_CHANNELS"),
+			},
+			Urgency: pipeline.UrgencyConfig{
+				Enabled:       env.Bool("SLACK_URGENCY"),
+				QuietHours:    env.String("SLACK_QUIET_HOURS"),
+				QuietTimezone: env.String("SLACK_QUIET_TIMEZONE"),
+			},
+			IssueShortcut: slack.IssueShortcutConfig{
+				Repositories: env.List("SLACK_ISSUE_REPOSITORIES"),
+				Labels:       env.List("SLACK_ISSUE_LABELS"),
+			},
+			RequireWriteAccess: env.Bool("SLACK_REQUIRE_WRITE_ACCESS"),
+			WorkflowSteps:      env.Bool("SLACK_WORKFLOW_STEPS"),
 		},
 		Monitor: MonitorConfig{
-			MetricsPort: getEnv("METRICS_PORT", "9090"),
-			MetricsPath: getEnv("METRICS_PATH", "/metrics"),
+			MetricsPort:           env.String("METRICS_PORT"),
+			MetricsPath:           env.String("METRICS_PATH"),
+			SLOAvailabilityTarget: env.Float("SLO_AVAILABILITY_TARGET"),
+			SLOLatencyTarget:      env.Duration("SLO_LATENCY_TARGET"),
+			Metrics: monitor.Options{
+				DropLabels: env.List("METRICS_DROP_LABELS"),
+			},
+			Drift: drift.Config{
+				Window:    env.Duration("DRIFT_WINDOW"),
+				Baseline:  env.Duration("DRIFT_BASELINE"),
+				Threshold: env.Float("DRIFT_THRESHOLD"),
+				MinIssues: env.Int("DRIFT_MIN_ISSUES"),
+				Channel:   env.String("DRIFT_ALERT_CHANNEL"),
+			},
+			Resources: resources.Config{
+				Interval:       env.Duration("RESOURCE_SAMPLE_INTERVAL"),
+				MemoryLimit:    uint64(max(0, env.Int("RESOURCE_MEMORY_LIMIT_MB"))) << 20,
+				Pressure:       env.Float("RESOURCE_MEMORY_PRESSURE"),
+				MaxEnrichments: env.Int("ENRICH_CONCURRENCY"),
+			},
+			Resolution: resolution.Config{
+				Channel: env.String("RESOLUTION_REPORT_CHANNEL"),
+				Schedule: schedule.Schedule{
+					Hour:     env.Int("RESOLUTION_REPORT_HOUR"),
+					Timezone: env.String("RESOLUTION_REPORT_TIMEZONE"),
+				},
+			},
+			Leaderboard: leaderboard.Config{
+				Channel: env.String("LEADERBOARD_CHANNEL"),
+				Size:    env.Int("LEADERBOARD_SIZE"),
+				Schedule: schedule.Schedule{
+					Hour:     env.Int("LEADERBOARD_HOUR"),
+					Timezone: env.String("LEADERBOARD_TIMEZONE"),
+				},
+			},
+			Usage: usage.Config{
+				Channel: env.String("USAGE_REPORT_CHANNEL"),
+				Schedule: schedule.Schedule{
+					Weekday:  env.String("USAGE_REPORT_DAY"),
+					Hour:     env.Int("USAGE_REPORT_HOUR"),
+					Timezone: env.String("USAGE_REPORT_TIMEZONE"),
+				},
+			},
+		},
+		Secrets: secrets,
+		Routing: RoutingConfig{
+			DefaultLayout: env.String("SLACK_LAYOUT"),
+		},
+		Ingest: IngestConfig{
+			Mode: env.String("INGEST_MODE"),
+			Broker: broker.Config{
+				Type:            env.String("BROKER_TYPE"),
+				KafkaBrokers:    env.List("KAFKA_BROKERS"),
+				KafkaTopic:      env.String("KAFKA_TOPIC"),
+				KafkaGroupID:    env.String("KAFKA_GROUP_ID"),
+				NATSURL:         env.String("NATS_URL"),
+				NATSStream:      env.String("NATS_STREAM"),
+				NATSSubject:     env.String("NATS_SUBJECT"),
+				NATSDurable:     env.String("NATS_DURABLE"),
+				MaxRedeliveries: env.Int("BROKER_MAX_REDELIVERIES"),
+
+				KafkaDeadLetterTopic: env.String("KAFKA_DEAD_LETTER_TOPIC"),
+			},
+		},
+		Pipeline: PipelineConfig{
+			SummaryMode:          env.String("SUMMARY_MODE"),
+			DeepAnalysisPriority: env.String("DEEP_ANALYSIS_PRIORITY"),
+			ChangeThreshold:      env.Float("EDIT_CHANGE_THRESHOLD"),
+			PrefilterEnabled:     env.Bool("PREFILTER_ENABLED"),
+			TranslationEnabled:   env.Bool("TRANSLATION_ENABLED"),
+			MemoryMaxTokens:      env.Int("ISSUE_MEMORY_MAX_TOKENS"),
+			IncidentChannels:     env.Bool("INCIDENT_CHANNELS_ENABLED"),
+			RateLimit:            env.Int("NOTIFY_RATE_LIMIT"),
+			RateWindow:           env.Duration("NOTIFY_RATE_WINDOW"),
+			AutoLabel:            env.Bool("AUTO_LABEL_ENABLED"),
+			Acknowledgement: pipeline.AckConfig{
+				Enabled:        env.Bool("ISSUE_ACK_ENABLED"),
+				Reaction:       env.String("ISSUE_ACK_REACTION"),
+				FailedReaction: env.String("ISSUE_ACK_FAILED_REACTION"),
+			},
+			InfoRequests: pipeline.InfoRequestConfig{
+				Enabled: env.Bool("NEEDS_INFO_ENABLED"),
+				Label:   env.String("NEEDS_INFO_LABEL"),
+			},
+			Incidents: pipeline.IncidentConfig{
+				Categories: env.List("INCIDENT_CATEGORIES"),
+				Priority:   env.String("INCIDENT_PRIORITY"),
+				Responders: env.List("INCIDENT_RESPONDERS"),
+				Prefix:     env.String("INCIDENT_CHANNEL_PREFIX"),
+			},
+			Prefilter: pipeline.PrefilterConfig{
+				Labels:        env.List("PREFILTER_LABELS"),
+				TitleKeywords: env.List("PREFILTER_TITLE_KEYWORDS"),
+				MinBodyLength: env.Int("PREFILTER_MIN_BODY_LENGTH"),
+			},
+			ReactionBoost: pipeline.BoostConfig{
+				Threshold: env.Int("REACTION_BOOST_THRESHOLD"),
+				Interval:  env.Duration("REACTION_BOOST_INTERVAL"),
+				Relabel:   env.Bool("REACTION_BOOST_RELABEL"),
+			},
+			Reanalysis: pipeline.ReanalysisConfig{
+				Every:    env.Duration("REANALYSIS_INTERVAL"),
+				Priority: env.String("REANALYSIS_PRIORITY"),
+			},
+			Engagement: pipeline.EngagementConfig{
+				Enabled:  env.Bool("ENGAGEMENT_ESCALATION"),
+				Window:   env.Duration("ENGAGEMENT_WINDOW"),
+				Priority: env.String("ENGAGEMENT_PRIORITY"),
+				Channel:  env.String("ENGAGEMENT_ESCALATION_CHANNEL"),
+				DM:       env.String("ENGAGEMENT_ESCALATION_DM"),
+			},
+			Moderation: pipeline.ModerationConfig{
+				Repositories: env.List("MODERATION_REPOSITORIES"),
+				Moderator:    env.String("MODERATION_REVIEWER"),
+			},
+		},
+		OnCall: OnCallConfig{
+			MentionPriority: env.String("ONCALL_MENTION_PRIORITY"),
+		},
+		Teams: TeamsConfig{
+			Digest: schedule.Schedule{
+				Weekday:  env.String("TEAM_DIGEST_DAY"),
+				Hour:     env.Int("TEAM_DIGEST_HOUR"),
+				Timezone: env.String("TEAM_DIGEST_TIMEZONE"),
+			},
+		},
+		Timeouts: TimeoutConfig{
+			Enrich: env.Duration("ENRICH_TIMEOUT"),
+			AI:     env.Duration("AI_TIMEOUT"),
+			Slack:  env.Duration("SLACK_TIMEOUT"),
+		},
+		Breaker: breaker.Config{
+			Failures: env.Int("BREAKER_FAILURES"),
+			Cooldown: env.Duration("BREAKER_COOLDOWN"),
+		},
+		Limits: ratelimit.Config{
+			Enabled: env.Bool("RATE_LIMIT_ENABLED"),
+			Webhook: ratelimit.Limit{Rate: env.Float("RATE_LIMIT_WEBHOOK_RATE"), Burst: env.Int("RATE_LIMIT_WEBHOOK_BURST")},
+			API:     ratelimit.Limit{Rate: env.Float("RATE_LIMIT_API_RATE"), Burst: env.Int("RATE_LIMIT_API_BURST")},
+			Global:  ratelimit.Limit{Rate: env.Float("RATE_LIMIT_GLOBAL_RATE"), Burst: env.Int("RATE_LIMIT_GLOBAL_BURST")},
 		},
-		LogLevel: getEnv("LOG_LEVEL", "info"),
+		Trackers: TrackerConfig{
+			LinearAPIKey:          env.Secret("LINEAR_API_KEY"),
+			LinearWebhookSecret:   env.Secret("LINEAR_WEBHOOK_SECRET"),
+			ShortcutAPIToken:      env.Secret("SHORTCUT_API_TOKEN"),
+			ShortcutWebhookSecret: env.Secret("SHORTCUT_WEBHOOK_SECRET"),
+		},
+		Identity: IdentityConfig{
+			EmailMatching: env.Bool("IDENTITY_EMAIL_MATCHING"),
+		},
+		Support: support.Config{
+			ZendeskSecret:  env.Secret("ZENDESK_WEBHOOK_SECRET"),
+			IntercomSecret: env.Secret("INTERCOM_CLIENT_SECRET"),
+			Owner:          env.String("SUPPORT_REPOSITORY_OWNER"),
+			FileRepository: env.String("SUPPORT_FILE_REPOSITORY"),
+			FileLabels:     env.List("SUPPORT_FILE_LABELS"),
+		},
+		LogLevel:   env.String("LOG_LEVEL"),
+		SummaryLog: env.String("SUMMARY_LOG"),
+	}
+
+	// Day counts of deprecated settings apply when their durations are not set
+	if days := env.Int("DRIFT_BASELINE_DAYS"); days > 0 && !env.IsSet("DRIFT_BASELINE") {
+		config.Monitor.Drift.Baseline = time.Duration(days) * 24 * time.Hour
+	}
+	if days := env.Int("REANALYSIS_DAYS"); days > 0 && !env.IsSet("REANALYSIS_INTERVAL") {
+		config.Pipeline.Reanalysis.Every = time.Duration(days) * 24 * time.Hour
+	}
+
+	config.Monitor.Metrics.Buckets = getBucketsEnv(env)
+
+	if err := viper.UnmarshalKey("routing.rules", &config.Routing.Rules); err != nil {
+		return nil, fmt.Errorf("invalid routing rules: %w", err)
+	}
+	if err := viper.UnmarshalKey("slack.levels", &config.Slack.Urgency.Levels); err != nil {
+		return nil, fmt.Errorf("invalid Slack urgency levels: %w", err)
+	}
+	if err := viper.UnmarshalKey("slack.timezones", &config.Slack.Urgency.Timezones); err != nil {
+		return nil, fmt.Errorf("invalid Slack channel timezones: %w", err)
+	}
+	if err := viper.UnmarshalKey("openai.models", &config.OpenAI.Models); err != nil {
+		return nil, fmt.Errorf("invalid OpenAI models: %w", err)
+	}
+	if err := viper.UnmarshalKey("events.rules", &config.GitHub.ActionRules); err != nil {
+		return nil, fmt.Errorf("invalid event rules: %w", err)
+	}
+	if err := viper.UnmarshalKey("enrichment.rules", &config.GitHub.Enrichment.Rules); err != nil {
+		return nil, fmt.Errorf("invalid enrichment rules: %w", err)
+	}
+	if err := viper.UnmarshalKey("enrichment.timeouts", &config.GitHub.Enrichment.Timeouts); err != nil {
+		return nil, fmt.Errorf("invalid enrichment timeouts: %w", err)
+	}
+	if err := viper.UnmarshalKey("comments.templates", &config.GitHub.CommentTemplates); err != nil {
+		return nil, fmt.Errorf("invalid comment templates: %w", err)
+	}
+	if err := viper.UnmarshalKey("oncall.schedules", &config.OnCall.Schedules); err != nil {
+		return nil, fmt.Errorf("invalid on-call schedules: %w", err)
+	}
+	if err := viper.UnmarshalKey("trackers", &config.Trackers.Mappings); err != nil {
+		return nil, fmt.Errorf("invalid tracker mappings: %w", err)
+	}
+	if err := viper.UnmarshalKey("identities", &config.Identity.Identities); err != nil {
+		return nil, fmt.Errorf("invalid identities: %w", err)
+	}
+	if err := viper.UnmarshalKey("teams", &config.Teams.Teams); err != nil {
+		return nil, fmt.Errorf("invalid teams: %w", err)
+	}
+	if err := viper.UnmarshalKey("components", &config.Pipeline.Components); err != nil {
+		return nil, fmt.Errorf("invalid component mappings: %w", err)
+	}
+	if err := viper.UnmarshalKey("taxonomy", &config.Pipeline.Taxonomy); err != nil {
+		return nil, fmt.Errorf("invalid taxonomy: %w", err)
+	}
+	if err := viper.UnmarshalKey("postprocessors", &config.Pipeline.PostProcessors); err != nil {
+		return nil, fmt.Errorf("invalid postprocessors: %w", err)
+	}
+	if err := viper.UnmarshalKey("tenants", &config.Tenants); err != nil {
+		return nil, fmt.Errorf("invalid tenants: %w", err)
+	}
+	for i := range config.Tenants {
+		config.Tenants[i].expandCredentials()
+	}
+	if err := viper.UnmarshalKey("notifiers", &config.Notifiers); err != nil {
+		return nil, fmt.Errorf("invalid notifiers: %w", err)
+	}
+	expandNotifiers(config.Notifiers)
+
+	// Credentials from an external secrets provider take precedence over the environment
+	if err := config.loadProviderSecrets(env); err != nil {
+		return nil, err
 	}
 
+	config.settings = env.settings()
+	config.problems = env.problems
+	config.warnings = env.warnings
+	config.file = viper.ConfigFileUsed()
 	return config, nil
 }
 
-// Validate checks if the configuration is valid
+// loadProviderSecrets fetches credentials from the configured secrets provider
+func (c *Config) loadProviderSecrets(env *env) error {
+	if c.Secrets.Provider == "" || c.Secrets.Provider == "env" {
+		return nil
+	}
+
+	provider, err := NewSecretsProvider(c.Secrets)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	values, lease, err := provider.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch secrets from %s: %w", provider.Name(), err)
+	}
+
+	for key, value := range values {
+		if c.ApplySecret(key, value) {
+			env.provide(key, value, provider.Name())
+		}
+	}
+	c.Secrets.ProviderValues = values
+	c.Secrets.ProviderLease = lease
+	return nil
+}
+
+// Settings returns the effective value of every environment setting and
+// where it came from, in the schema's order. It is empty for configurations
+// not read by Load.
+func (c *Config) Settings() []Value {
+	return c.settings
+}
+
+// Warnings returns the deprecated settings in use
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// File returns the config file Load read, empty for none
+func (c *Config) File() string {
+	return c.file
+}
+
+// Validate checks if the configuration is valid. Invalid settings found by
+// Load are reported together; the checks between settings run once there
+// are none.
 func (c *Config) Validate() error {
+	if len(c.problems) > 0 {
+		return c.problems
+	}
+	return c.validate()
+}
+
+func (c *Config) validate() error {
 	if c.GitHub.WebhookSecret == "" {
 		return fmt.Errorf("GITHUB_WEBHOOK_SECRET is required")
 	}
 	if c.GitHub.AccessToken == "" {
 		return fmt.Errorf("GITHUB_ACCESS_TOKEN is required")
 	}
+	if err := c.validateTenants(); err != nil {
+		return err
+	}
+	if err := c.Monitor.Metrics.Validate(); err != nil {
+		return fmt.Errorf("invalid metrics options: %w", err)
+	}
+	if err := c.validateSources(); err != nil {
+		return err
+	}
+	if err := c.Breaker.Validate(); err != nil {
+		return fmt.Errorf("BREAKER_FAILURES and BREAKER_COOLDOWN: %w", err)
+	}
+	if c.Limits.Enabled {
+		if err := c.Limits.Validate(); err != nil {
+			return fmt.Errorf("invalid rate limits: %w", err)
+		}
+	}
+	if c.Support.Enabled() && c.Ingest.Mode != "" && c.Ingest.Mode != broker.ModeMonolith {
+		return fmt.Errorf("support tickets are only supported in monolith mode")
+	}
+	if err := c.validateTrackers(); err != nil {
+		return err
+	}
+	if c.Server.Shadow {
+		if len(c.Tenants) > 0 {
+			return fmt.Errorf("SHADOW_MODE is not supported with tenants")
+		}
+		if c.Server.AdminToken == "" {
+			return fmt.Errorf("SHADOW_MODE needs ADMIN_TOKEN to review what was held back")
+		}
+		// Only Slack and GitHub calls are held back
+		if len(c.Notifiers) > 0 || len(c.Trackers.Mappings) > 0 {
+			return fmt.Errorf("SHADOW_MODE does not hold back notifiers or ticket trackers; remove them while evaluating")
+		}
+	}
+
+	if c.Server.Capture && c.Server.AdminToken == "" {
+		return fmt.Errorf("WEBHOOK_CAPTURE needs ADMIN_TOKEN to list and replay captures")
+	}
+
+	switch c.Ingest.Mode {
+	case "", broker.ModeMonolith:
+	case broker.ModeReceiver, broker.ModeWorker:
+		if c.Ingest.Broker.Type == "" {
+			return fmt.Errorf("BROKER_TYPE is required in %s mode", c.Ingest.Mode)
+		}
+		// Receivers only verify and publish webhooks
+		if c.Ingest.Mode == broker.ModeReceiver {
+			return nil
+		}
+	default:
+		return fmt.Errorf("invalid INGEST_MODE %q", c.Ingest.Mode)
+	}
+
 	if c.OpenAI.APIKey == "" {
 		return fmt.Errorf("OPENAI_API_KEY is required")
 	}
+	if attachments := c.GitHub.Attachments; attachments.Enabled && (attachments.MaxBytes <= 0 || attachments.MaxFiles <= 0 || attachments.MaxLines <= 0) {
+		return fmt.Errorf("ATTACHMENT_MAX_BYTES, ATTACHMENT_MAX_FILES and ATTACHMENT_MAX_LINES must be positive")
+	}
+	if linked := c.GitHub.LinkedIssues; linked.Enabled && (linked.TTL <= 0 || linked.MaxLinks <= 0) {
+		return fmt.Errorf("LINKED_ISSUE_TTL and LINKED_ISSUE_MAX must be positive")
+	}
+	if regressions := c.GitHub.Regressions; regressions.Enabled && regressions.Window <= 0 {
+		return fmt.Errorf("REGRESSION_WINDOW must be positive")
+	}
+	if stack := c.GitHub.Stack; stack.Enabled && stack.TTL <= 0 {
+		return fmt.Errorf("STACK_CACHE_TTL must be positive")
+	}
+	if err := c.GitHub.Enrichment.Validate(); err != nil {
+		return fmt.Errorf("ENRICHMENT_STAGE_TIMEOUT and enrichment: %w", err)
+	}
+	if ci := c.GitHub.CIFailures; ci.Enabled {
+		if len(ci.Labels) == 0 && len(ci.Authors) == 0 {
+			return fmt.Errorf("CI_FAILURE_LABELS or CI_FAILURE_AUTHORS is required with CI_FAILURE_ENABLED")
+		}
+		if ci.MaxBytes <= 0 || ci.MaxLines <= 0 {
+			return fmt.Errorf("CI_LOG_MAX_BYTES and CI_LOG_MAX_LINES must be positive")
+		}
+	}
+	if c.GitHub.Commands.Enabled && !github.ValidPermission(c.GitHub.Commands.Permission) {
+		return fmt.Errorf("GITHUB_COMMAND_PERMISSION must be read, write or admin, got %q", c.GitHub.Commands.Permission)
+	}
+	if repo := c.GitHub.PermissionCheckRepository; repo != "" {
+		if parts := strings.Split(repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("GITHUB_PERMISSION_CHECK_REPOSITORY must be owner/name, got %q", repo)
+		}
+	}
+	if c.GitHub.CommentLimit < 0 {
+		return fmt.Errorf("GITHUB_COMMENT_LIMIT must not be negative, got %d", c.GitHub.CommentLimit)
+	}
+	if !github.ValidCheckMode(c.GitHub.Checks.Mode) {
+		return fmt.Errorf("GITHUB_CHECKS must be status, check_run or empty, got %q", c.GitHub.Checks.Mode)
+	}
+	if len(c.GitHub.Poll.Repositories) > 0 {
+		if c.GitHub.Poll.Interval <= 0 || c.GitHub.Poll.Lookback < 0 {
+			return fmt.Errorf("GITHUB_POLL_INTERVAL must be positive and GITHUB_POLL_LOOKBACK must not be negative")
+		}
+		for _, repo := range c.GitHub.Poll.Repositories {
+			if parts := strings.Split(repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("GITHUB_POLL_REPOSITORIES must list owner/name repositories, got %q", repo)
+			}
+		}
+	}
+	switch c.Pipeline.SummaryMode {
+	case "", SummaryModeFull, SummaryModeTwoStage:
+	default:
+		return fmt.Errorf("invalid SUMMARY_MODE %q", c.Pipeline.SummaryMode)
+	}
+	models, err := ai.NewModelRegistry(c.OpenAI.Models)
+	if err != nil {
+		return fmt.Errorf("invalid OpenAI models: %w", err)
+	}
+	if err := models.Validate(c.OpenAI.Model, c.OpenAI.MaxTokens); err != nil {
+		return fmt.Errorf("OPENAI_MODEL: %w", err)
+	}
+	if style, ok := ai.GetPromptStyle(c.OpenAI.PromptStyle); ok && (style.Model != "" || style.MaxTokens > 0) {
+		model, maxTokens := c.OpenAI.Model, c.OpenAI.MaxTokens
+		if style.Model != "" {
+			model = style.Model
+		}
+		if style.MaxTokens > 0 {
+			maxTokens = style.MaxTokens
+		}
+		if err := models.Validate(model, maxTokens); err != nil {
+			return fmt.Errorf("OPENAI_PROMPT_STYLE %s: %w", c.OpenAI.PromptStyle, err)
+		}
+	}
+	if c.Pipeline.SummaryMode == SummaryModeTwoStage {
+		if err := models.Validate(c.OpenAI.TriageModel, ai.TriageMaxTokens); err != nil {
+			return fmt.Errorf("OPENAI_TRIAGE_MODEL: %w", err)
+		}
+	}
 	if c.Slack.BotToken == "" {
 		return fmt.Errorf("SLACK_BOT_TOKEN is required")
 	}
@@ -137,6 +929,119 @@ func (c *Config) Validate() error {
 	if c.Slack.ChannelID == "" {
 		return fmt.Errorf("SLACK_CHANNEL_ID is required")
 	}
+	if err := c.Slack.IssueShortcut.Validate(); err != nil {
+		return fmt.Errorf("SLACK_ISSUE_REPOSITORIES: %w", err)
+	}
+	if err := c.Pipeline.Acknowledgement.Validate(); err != nil {
+		return fmt.Errorf("invalid issue acknowledgement: %w", err)
+	}
+	if err := c.Pipeline.Engagement.Validate(); err != nil {
+		return fmt.Errorf("ENGAGEMENT_WINDOW, ENGAGEMENT_PRIORITY and ENGAGEMENT_ESCALATION_DM: %w", err)
+	}
+	if err := c.Pipeline.Moderation.Validate(); err != nil {
+		return fmt.Errorf("MODERATION_REPOSITORIES and MODERATION_REVIEWER: %w", err)
+	}
+	if err := c.Monitor.Resources.Validate(); err != nil {
+		return fmt.Errorf("RESOURCE_SAMPLE_INTERVAL, RESOURCE_MEMORY_PRESSURE and ENRICH_CONCURRENCY: %w", err)
+	}
+	if err := c.Monitor.Drift.Validate(); err != nil {
+		return fmt.Errorf("DRIFT_WINDOW, DRIFT_BASELINE, DRIFT_THRESHOLD and DRIFT_MIN_ISSUES: %w", err)
+	}
+	if err := c.OpenAI.Canary.Validate(); err != nil {
+		return fmt.Errorf("PROMPT_CANARY_PERCENT, PROMPT_CANARY_MIN_SAMPLES, PROMPT_CANARY_PROMOTE_AFTER and PROMPT_CANARY_TOLERANCE: %w", err)
+	}
+	if err := c.Monitor.Resolution.Validate(); err != nil {
+		return fmt.Errorf("RESOLUTION_REPORT_HOUR and RESOLUTION_REPORT_TIMEZONE: %w", err)
+	}
+	if err := c.Monitor.Leaderboard.Validate(); err != nil {
+		return fmt.Errorf("LEADERBOARD_HOUR, LEADERBOARD_TIMEZONE and LEADERBOARD_SIZE: %w", err)
+	}
+	if err := c.Monitor.Usage.Validate(); err != nil {
+		return fmt.Errorf("USAGE_REPORT_DAY, USAGE_REPORT_HOUR and USAGE_REPORT_TIMEZONE: %w", err)
+	}
+	if _, err := postprocess.New(c.Pipeline.PostProcessors); err != nil {
+		return fmt.Errorf("invalid postprocessors: %w", err)
+	}
+	if _, err := teams.NewDirectory(c.Teams.Teams); err != nil {
+		return fmt.Errorf("invalid teams: %w", err)
+	}
+	if c.Teams.Digest.Weekday != "" {
+		if err := c.Teams.Digest.Validate("digest"); err != nil {
+			return fmt.Errorf("TEAM_DIGEST_DAY, TEAM_DIGEST_HOUR and TEAM_DIGEST_TIMEZONE: %w", err)
+		}
+	}
+	if c.Support.Enabled() {
+		if err := c.Support.Validate(); err != nil {
+			return fmt.Errorf("SUPPORT_REPOSITORY_OWNER and SUPPORT_FILE_REPOSITORY: %w", err)
+		}
+	}
+	return c.validateNotifiers()
+}
+
+// PermissionRepository returns the repository the access token's permissions
+// are checked on: GITHUB_PERMISSION_CHECK_REPOSITORY, or else the first
+// polled, shortcut, support or routing rule repository without wildcards.
+// It is empty when no repository is configured.
+func (c *Config) PermissionRepository() string {
+	if c.GitHub.PermissionCheckRepository != "" {
+		return c.GitHub.PermissionCheckRepository
+	}
+	candidates := append([]string{}, c.GitHub.Poll.Repositories...)
+	candidates = append(candidates, c.Slack.IssueShortcut.Repositories...)
+	candidates = append(candidates, c.Support.FileRepository)
+	for _, rule := range c.Routing.Rules {
+		candidates = append(candidates, rule.Repositories...)
+	}
+	for _, repo := range candidates {
+		if parts := strings.Split(repo, "/"); len(parts) == 2 && parts[0] != "" && parts[1] != "" && !strings.ContainsAny(repo, "*?[") {
+			return repo
+		}
+	}
+	return ""
+}
+
+// validateTrackers checks the tracker mappings and that each tracker they
+// name has credentials. Links are kept in memory, so tracker sync needs the
+// process that triages issues to also receive the trackers' webhooks.
+func (c *Config) validateTrackers() error {
+	if len(c.Trackers.Mappings) == 0 {
+		return nil
+	}
+	if c.Ingest.Mode != "" && c.Ingest.Mode != broker.ModeMonolith {
+		return fmt.Errorf("tracker sync is only supported in monolith mode")
+	}
+	if err := tracker.ValidateMappings(c.Trackers.Mappings); err != nil {
+		return err
+	}
+	for i, mapping := range c.Trackers.Mappings {
+		if mapping.Tracker == tracker.TypeLinear && c.Trackers.LinearAPIKey == "" {
+			return fmt.Errorf("tracker mapping %d: LINEAR_API_KEY is required", i)
+		}
+		if mapping.Tracker == tracker.TypeShortcut && c.Trackers.ShortcutAPIToken == "" {
+			return fmt.Errorf("tracker mapping %d: SHORTCUT_API_TOKEN is required", i)
+		}
+	}
+	return nil
+}
+
+// validateSources checks the webhook source allowlist
+func (c *Config) validateSources() error {
+	sources := c.GitHub.Sources
+	if !sources.Enabled {
+		return nil
+	}
+	if !sources.Meta && len(sources.AllowedCIDRs) == 0 {
+		return fmt.Errorf("GITHUB_WEBHOOK_IP_ALLOWLIST needs GITHUB_WEBHOOK_META or GITHUB_WEBHOOK_ALLOWED_IPS")
+	}
+	if sources.Meta && sources.Refresh < time.Minute {
+		return fmt.Errorf("GITHUB_META_REFRESH must be at least 1m")
+	}
+	if _, err := github.ParseCIDRs(sources.AllowedCIDRs); err != nil {
+		return fmt.Errorf("GITHUB_WEBHOOK_ALLOWED_IPS: %w", err)
+	}
+	if _, err := github.ParseCIDRs(sources.TrustedProxies); err != nil {
+		return fmt.Errorf("GITHUB_WEBHOOK_TRUSTED_PROXIES: %w", err)
+	}
 	return nil
 }
 
@@ -153,36 +1058,37 @@ func setDefaults() {
 	viper.SetDefault("log_level", "info")
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
 		}
 	}
-	return defaultValue
+	return values
 }
 
-func getFloatEnv(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
-		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
-			return floatValue
+// getBucketsEnv reads histogram bucket overrides from METRICS_BUCKETS_<GROUP>,
+// e.g. METRICS_BUCKETS_OPENAI=1,5,10,30,60,120. Groups with a value that is
+// not a number keep their default buckets.
+func getBucketsEnv(env *env) map[string][]float64 {
+	buckets := make(map[string][]float64)
+	for group := range monitor.DefaultBuckets {
+		key := "METRICS_BUCKETS_" + strings.ToUpper(group)
+		values := env.List(key)
+		if len(values) == 0 {
+			continue
 		}
-	}
-	return defaultValue
-}
-
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+		for _, value := range values {
+			bucket, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				env.invalid(env.schema[key], env.values[key].Raw, fmt.Errorf("has %q, which is not a number of seconds", value))
+				delete(buckets, group)
+				break
+			}
+			buckets[group] = append(buckets[group], bucket)
 		}
 	}
-	return defaultValue
+	return buckets
 }
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github-issue-ai-bot/internal/notify"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// expandNotifiers replaces ${NAME} references in notifier URLs and secrets
+// with environment variables
+func expandNotifiers(notifiers []notify.Config) {
+	for i := range notifiers {
+		notifiers[i].URL = os.ExpandEnv(notifiers[i].URL)
+		notifiers[i].Secret = os.ExpandEnv(notifiers[i].Secret)
+	}
+}
+
+// validateNotifiers checks the notifiers and that routing rules only name
+// configured ones. Tenant rules name the tenant's own notifiers.
+func (c *Config) validateNotifiers() error {
+	if err := notify.Validate(c.Notifiers); err != nil {
+		return err
+	}
+
+	names := map[string]bool{pipeline.SlackNotifierName: true}
+	for _, notifier := range c.Notifiers {
+		names[notifier.Name] = true
+	}
+	if err := checkRuleNotifiers(c.Routing.Rules, names); err != nil {
+		return err
+	}
+	for _, tenant := range c.Tenants {
+		if err := notify.Validate(tenant.Notifiers); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant.Name, err)
+		}
+		names := map[string]bool{pipeline.SlackNotifierName: true}
+		for _, notifier := range tenant.Notifiers {
+			names[notifier.Name] = true
+		}
+		if err := checkRuleNotifiers(tenant.RoutingRules, names); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant.Name, err)
+		}
+	}
+	return nil
+}
+
+func checkRuleNotifiers(rules []routing.Rule, names map[string]bool) error {
+	for i, rule := range rules {
+		for _, name := range rule.Notifiers {
+			if !names[name] {
+				return fmt.Errorf("routing rule %d (%s): unknown notifier %q", i, rule.Name, name)
+			}
+		}
+	}
+	return nil
+}
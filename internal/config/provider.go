@@ -0,0 +1,419 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SecretsProvider fetches credentials from an external secret store
+type SecretsProvider interface {
+	// Name identifies the provider in logs and errors
+	Name() string
+	// Fetch returns secret values keyed by setting name (e.g. GITHUB_ACCESS_TOKEN)
+	// and how long they stay valid; a zero duration means they do not expire
+	Fetch(ctx context.Context) (map[string]string, time.Duration, error)
+}
+
+// VaultConfig holds HashiCorp Vault settings
+type VaultConfig struct {
+	Address    string // Vault server address, e.g. https://vault.internal:8200
+	Token      string // Static Vault token
+	Role       string // Kubernetes auth role, used when no token is set
+	AuthPath   string // Mount path of the Kubernetes auth method
+	SecretPath string // Path of the secret, e.g. secret/data/notifyops
+	Namespace  string // Vault Enterprise namespace
+}
+
+// AWSSecretsConfig holds AWS Secrets Manager settings
+type AWSSecretsConfig struct {
+	Region          string
+	SecretID        string // Name or ARN of the secret
+	Endpoint        string // Optional endpoint override (VPC endpoints, LocalStack)
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// NewSecretsProvider creates the provider selected by SecretsConfig.Provider
+func NewSecretsProvider(cfg SecretsConfig) (SecretsProvider, error) {
+	switch cfg.Provider {
+	case "vault":
+		if cfg.Vault.Address == "" || cfg.Vault.SecretPath == "" {
+			return nil, fmt.Errorf("VAULT_ADDR and VAULT_SECRET_PATH are required for the vault secrets provider")
+		}
+		return &VaultProvider{config: cfg.Vault, httpClient: &http.Client{Timeout: 10 * time.Second}}, nil
+	case "aws":
+		if cfg.AWS.Region == "" || cfg.AWS.SecretID == "" {
+			return nil, fmt.Errorf("AWS_REGION and AWS_SECRET_ID are required for the aws secrets provider")
+		}
+		if cfg.AWS.AccessKeyID == "" || cfg.AWS.SecretAccessKey == "" {
+			return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for the aws secrets provider")
+		}
+		return &AWSSecretsManagerProvider{config: cfg.AWS, httpClient: &http.Client{Timeout: 10 * time.Second}, now: time.Now}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider: %s", cfg.Provider)
+	}
+}
+
+// ApplySecret sets the configuration field backing a secret setting and
+// reports whether the key was recognised
+func (c *Config) ApplySecret(key, value string) bool {
+	switch key {
+	case "GITHUB_WEBHOOK_SECRET":
+		c.GitHub.WebhookSecret = value
+	case "GITHUB_ACCESS_TOKEN":
+		c.GitHub.AccessToken = value
+	case "OPENAI_API_KEY":
+		c.OpenAI.APIKey = value
+	case "SLACK_BOT_TOKEN":
+		c.Slack.BotToken = value
+	case "SLACK_SIGNING_SECRET":
+		c.Slack.SigningSecret = value
+	default:
+		return false
+	}
+	return true
+}
+
+// normalizeSecretValues converts a decoded secret document into setting
+// names, accepting both GITHUB_ACCESS_TOKEN and github-access-token style keys
+func normalizeSecretValues(data map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, raw := range data {
+		value, ok := raw.(string)
+		if !ok || value == "" {
+			continue
+		}
+		values[strings.ToUpper(strings.ReplaceAll(key, "-", "_"))] = value
+	}
+	return values
+}
+
+// VaultProvider reads secrets from a HashiCorp Vault KV (v1 or v2) engine
+type VaultProvider struct {
+	config     VaultConfig
+	httpClient *http.Client
+}
+
+// Name returns the provider name
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+// Fetch reads the configured secret path. The returned lease is the shorter
+// of the secret lease and the token lease obtained via Kubernetes auth.
+func (p *VaultProvider) Fetch(ctx context.Context) (map[string]string, time.Duration, error) {
+	token := p.config.Token
+	var tokenLease time.Duration
+	if token == "" {
+		var err error
+		token, tokenLease, err = p.kubernetesLogin(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var resp struct {
+		LeaseDuration int                    `json:"lease_duration"`
+		Data          map[string]interface{} `json:"data"`
+	}
+	if err := p.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(p.config.SecretPath, "/"), token, nil, &resp); err != nil {
+		return nil, 0, fmt.Errorf("failed to read vault secret: %w", err)
+	}
+
+	// KV v2 nests the payload under data.data alongside data.metadata
+	data := resp.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = inner
+		}
+	}
+
+	lease := time.Duration(resp.LeaseDuration) * time.Second
+	if tokenLease > 0 && (lease == 0 || tokenLease < lease) {
+		lease = tokenLease
+	}
+
+	return normalizeSecretValues(data), lease, nil
+}
+
+// kubernetesLogin exchanges the pod's service account token for a Vault token
+func (p *VaultProvider) kubernetesLogin(ctx context.Context) (string, time.Duration, error) {
+	if p.config.Role == "" {
+		return "", 0, fmt.Errorf("either VAULT_TOKEN or VAULT_ROLE must be set")
+	}
+
+	jwt, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/token")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	authPath := p.config.AuthPath
+	if authPath == "" {
+		authPath = "kubernetes"
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role": p.config.Role, "jwt": strings.TrimSpace(string(jwt))}
+	if err := p.do(ctx, http.MethodPost, "/v1/auth/"+authPath+"/login", "", body, &resp); err != nil {
+		return "", 0, fmt.Errorf("vault kubernetes login failed: %w", err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", 0, fmt.Errorf("vault kubernetes login returned no token")
+	}
+
+	return resp.Auth.ClientToken, time.Duration(resp.Auth.LeaseDuration) * time.Second, nil
+}
+
+// do performs a Vault API request and decodes the JSON response
+func (p *VaultProvider) do(ctx context.Context, method, path, token string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(p.config.Address, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.config.Namespace)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// AWSSecretsManagerProvider reads a JSON secret from AWS Secrets Manager
+type AWSSecretsManagerProvider struct {
+	config     AWSSecretsConfig
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// Name returns the provider name
+func (p *AWSSecretsManagerProvider) Name() string {
+	return "aws"
+}
+
+// Fetch calls GetSecretValue. Secrets Manager has no leases, so the returned
+// duration is always zero and refreshes follow the configured interval.
+func (p *AWSSecretsManagerProvider) Fetch(ctx context.Context) (map[string]string, time.Duration, error) {
+	endpoint := p.config.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", p.config.Region)
+	}
+
+	payload, err := json.Marshal(map[string]string{"SecretId": p.config.SecretID})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signAWSRequest(req, payload, p.config, "secretsmanager", p.now())
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to call secrets manager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("secrets manager returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode secrets manager response: %w", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(result.SecretString), &data); err != nil {
+		return nil, 0, fmt.Errorf("secret %s is not a JSON object: %w", p.config.SecretID, err)
+	}
+
+	return normalizeSecretValues(data), 0, nil
+}
+
+// signAWSRequest adds AWS Signature Version 4 headers to a request
+func signAWSRequest(req *http.Request, payload []byte, cfg AWSSecretsConfig, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, cfg.Region, service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), dateStamp)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// SecretsRefresher re-fetches provider secrets before their lease expires
+// (or on a fixed interval for providers without leases) and reports changes
+type SecretsRefresher struct {
+	provider SecretsProvider
+	lease    time.Duration
+	interval time.Duration
+	values   map[string]string
+	onChange func(key, value string)
+	onError  func(err error)
+}
+
+// NewSecretsRefresher creates a refresher seeded with the values and lease
+// obtained at startup
+func NewSecretsRefresher(provider SecretsProvider, initial map[string]string, lease, interval time.Duration, onChange func(key, value string), onError func(err error)) *SecretsRefresher {
+	values := make(map[string]string, len(initial))
+	for key, value := range initial {
+		values[key] = value
+	}
+	return &SecretsRefresher{
+		provider: provider,
+		lease:    lease,
+		interval: interval,
+		values:   values,
+		onChange: onChange,
+		onError:  onError,
+	}
+}
+
+// Run refreshes secrets until the context is cancelled
+func (r *SecretsRefresher) Run(ctx context.Context) {
+	for {
+		wait := r.nextRefresh()
+		if wait <= 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		if err := r.Refresh(ctx); err != nil && r.onError != nil {
+			r.onError(err)
+		}
+	}
+}
+
+// Refresh fetches the secrets once and reports changed values
+func (r *SecretsRefresher) Refresh(ctx context.Context) error {
+	values, lease, err := r.provider.Fetch(ctx)
+	if err != nil {
+		// Retry well before the current lease runs out
+		r.lease = r.lease / 2
+		return fmt.Errorf("failed to refresh secrets from %s: %w", r.provider.Name(), err)
+	}
+
+	r.lease = lease
+	for key, value := range values {
+		if r.values[key] == value {
+			continue
+		}
+		r.values[key] = value
+		if r.onChange != nil {
+			r.onChange(key, value)
+		}
+	}
+	return nil
+}
+
+// nextRefresh returns how long to wait before the next refresh: at 80% of
+// the lease, or the fixed interval when there is no lease
+func (r *SecretsRefresher) nextRefresh() time.Duration {
+	if r.lease > 0 {
+		wait := r.lease * 4 / 5
+		if wait < 10*time.Second {
+			wait = 10 * time.Second
+		}
+		return wait
+	}
+	return r.interval
+}
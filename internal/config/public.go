@@ -0,0 +1,231 @@
+package config
+
+import (
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/components"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/notify"
+	"github-issue-ai-bot/internal/oncall"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/postprocess"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/support"
+	"github-issue-ai-bot/internal/teams"
+	"github-issue-ai-bot/internal/tracker"
+)
+
+// PublicSettings is the configuration without credentials, safe to show on
+// the admin dashboard
+type PublicSettings struct {
+	IngestMode           string   `json:"ingest_mode"`
+	Model                string   `json:"model"`
+	TriageModel          string   `json:"triage_model,omitempty"`
+	PromptStyle          string   `json:"prompt_style"`
+	SummaryMode          string   `json:"summary_mode"`
+	DeepAnalysisPriority string   `json:"deep_analysis_priority,omitempty"`
+	ChangeThreshold      float64  `json:"change_threshold"`
+	PrefilterEnabled     bool     `json:"prefilter_enabled"`
+	TranslationEnabled   bool     `json:"translation_enabled"`
+	MemoryMaxTokens      int      `json:"memory_max_tokens"`
+	IncidentChannels     bool     `json:"incident_channels"`
+	RateLimit            int      `json:"rate_limit"`
+	RateWindow           string   `json:"rate_window"`
+	DefaultChannel       string   `json:"default_channel"`
+	DefaultLayout        string   `json:"default_layout"`
+	MentionPriority      string   `json:"mention_priority"`
+	AutoLabel            bool     `json:"auto_label"`
+	ReactionBoost        int      `json:"reaction_boost_threshold"`
+	Reanalysis           string   `json:"reanalysis_every,omitempty"`
+	ReanalysisPriority   string   `json:"reanalysis_priority,omitempty"`
+	WebhookWorkers       int      `json:"webhook_workers"`
+	WebhookQueueSize     int      `json:"webhook_queue_size"`
+	SaturationPolicy     string   `json:"saturation_policy"`
+	Tenants              []string `json:"tenants,omitempty"`
+	SummaryLog           string   `json:"summary_log,omitempty"`
+	FetchAttachments     bool     `json:"fetch_attachments"`
+	PrefetchLinked       bool     `json:"prefetch_linked"`
+	RegressionHints      bool     `json:"regression_hints"`
+	CIFailures           bool     `json:"ci_failures"`
+	WebhookIPAllowlist   bool     `json:"webhook_ip_allowlist"`
+	HTTPRateLimits       bool     `json:"http_rate_limits"`
+	CommandPermission    string   `json:"command_permission,omitempty"`
+	CheckMode            string   `json:"check_mode,omitempty"`
+	AckReaction          string   `json:"ack_reaction,omitempty"`
+	NeedsInfo            bool     `json:"needs_info"`
+	NeedsInfoLabel       string   `json:"needs_info_label,omitempty"`
+	QuietHours           string   `json:"quiet_hours,omitempty"`
+	QuietTimezone        string   `json:"quiet_timezone,omitempty"`
+	PollRepositories     []string `json:"poll_repositories,omitempty"`
+	PollInterval         string   `json:"poll_interval,omitempty"`
+	MetricsDropLabels    []string `json:"metrics_drop_labels,omitempty"`
+	DriftThreshold       float64  `json:"drift_threshold"`
+	DriftAlertChannel    string   `json:"drift_alert_channel,omitempty"`
+	ResolutionChannel    string   `json:"resolution_report_channel,omitempty"`
+	ResolutionTimezone   string   `json:"resolution_report_timezone,omitempty"`
+	LeaderboardChannel   string   `json:"leaderboard_channel,omitempty"`
+	UsageChannel         string   `json:"usage_report_channel,omitempty"`
+	UsageTimezone        string   `json:"usage_report_timezone,omitempty"`
+	DigestTimezone       string   `json:"team_digest_timezone,omitempty"`
+	SupportSources       []string `json:"support_sources,omitempty"`
+	SupportFileRepo      string   `json:"support_file_repository,omitempty"`
+	Identities           int      `json:"identities"` // Configured mappings; the accounts themselves are not shown
+	IdentityEmailMatch   bool     `json:"identity_email_matching"`
+	PromptCanaryPercent  int      `json:"prompt_canary_percent"`
+
+	RoutingRules    []routing.Rule          `json:"routing_rules"`
+	EventRules      []github.ActionRule     `json:"event_rules"`
+	OnCallSchedules []oncall.Schedule       `json:"oncall_schedules"`
+	Teams           []teams.Team            `json:"teams"`
+	Components      []components.Component  `json:"components"`
+	Taxonomy        ai.TaxonomyConfig       `json:"taxonomy"`
+	Notifiers       []notify.Config         `json:"notifiers"` // Without URLs and secrets
+	TrackerMappings []tracker.Mapping       `json:"tracker_mappings,omitempty"`
+	UrgencyLevels   []pipeline.UrgencyLevel `json:"urgency_levels,omitempty"`
+	PostProcessors  []postprocess.Config    `json:"postprocessors,omitempty"`
+
+	NoEmojiChannels   []string `json:"no_emoji_channels,omitempty"`
+	PlainTextChannels []string `json:"plain_text_channels,omitempty"`
+
+	ChannelTimezones []pipeline.ChannelTimezone `json:"channel_timezones,omitempty"`
+
+	EngagementWindow   string `json:"engagement_window,omitempty"`
+	EngagementPriority string `json:"engagement_priority,omitempty"`
+	EngagementChannel  string `json:"engagement_channel,omitempty"`
+	EngagementDM       string `json:"engagement_dm,omitempty"`
+
+	ModeratedRepositories []string `json:"moderated_repositories,omitempty"`
+	ModerationReviewer    string   `json:"moderation_reviewer,omitempty"`
+
+	IssueShortcutRepositories []string `json:"issue_shortcut_repositories,omitempty"`
+	IssueShortcutLabels       []string `json:"issue_shortcut_labels,omitempty"`
+	RequireWriteAccess        bool     `json:"require_write_access"`
+	WorkflowSteps             bool     `json:"workflow_steps"`
+	ShadowMode                bool     `json:"shadow_mode"`
+	WebhookCapture            bool     `json:"webhook_capture"`
+
+	PermissionCheckRepository string `json:"permission_check_repository,omitempty"`
+}
+
+// Public returns the settings that can be shown to operators
+func (c *Config) Public() PublicSettings {
+	settings := PublicSettings{
+		IngestMode:          c.Ingest.Mode,
+		Model:               c.OpenAI.Model,
+		PromptStyle:         c.OpenAI.PromptStyle,
+		SummaryMode:         c.Pipeline.SummaryMode,
+		ChangeThreshold:     c.Pipeline.ChangeThreshold,
+		PrefilterEnabled:    c.Pipeline.PrefilterEnabled,
+		TranslationEnabled:  c.Pipeline.TranslationEnabled,
+		MemoryMaxTokens:     c.Pipeline.MemoryMaxTokens,
+		IncidentChannels:    c.Pipeline.IncidentChannels,
+		RateLimit:           c.Pipeline.RateLimit,
+		RateWindow:          c.Pipeline.RateWindow.String(),
+		DefaultChannel:      c.Slack.ChannelID,
+		DefaultLayout:       c.Routing.DefaultLayout,
+		MentionPriority:     c.OnCall.MentionPriority,
+		AutoLabel:           c.Pipeline.AutoLabel,
+		ReactionBoost:       c.Pipeline.ReactionBoost.Threshold,
+		WebhookWorkers:      c.GitHub.Queue.Workers,
+		WebhookQueueSize:    c.GitHub.Queue.Size,
+		SaturationPolicy:    c.GitHub.Queue.Policy,
+		SummaryLog:          c.SummaryLog,
+		FetchAttachments:    c.GitHub.Attachments.Enabled,
+		PrefetchLinked:      c.GitHub.LinkedIssues.Enabled,
+		RegressionHints:     c.GitHub.Regressions.Enabled,
+		CIFailures:          c.GitHub.CIFailures.Enabled,
+		WebhookIPAllowlist:  c.GitHub.Sources.Enabled,
+		HTTPRateLimits:      c.Limits.Enabled,
+		CheckMode:           c.GitHub.Checks.Mode,
+		DriftThreshold:      c.Monitor.Drift.Threshold,
+		DriftAlertChannel:   c.Monitor.Drift.Channel,
+		ResolutionChannel:   c.Monitor.Resolution.Channel,
+		ResolutionTimezone:  c.Monitor.Resolution.Timezone,
+		LeaderboardChannel:  c.Monitor.Leaderboard.Channel,
+		UsageChannel:        c.Monitor.Usage.Channel,
+		UsageTimezone:       c.Monitor.Usage.Timezone,
+		DigestTimezone:      c.Teams.Digest.Timezone,
+		Identities:          len(c.Identity.Identities),
+		IdentityEmailMatch:  c.Identity.EmailMatching,
+		PromptCanaryPercent: c.OpenAI.Canary.Percent,
+
+		RoutingRules:    c.Routing.Rules,
+		EventRules:      c.GitHub.ActionRules,
+		OnCallSchedules: c.OnCall.Schedules,
+		Teams:           c.Teams.Teams,
+		Components:      c.Pipeline.Components,
+		Taxonomy:        c.Pipeline.Taxonomy,
+		Notifiers:       c.Notifiers,
+		TrackerMappings: c.Trackers.Mappings,
+		PostProcessors:  c.Pipeline.PostProcessors,
+
+		NoEmojiChannels:   c.Slack.Accessibility.NoEmojiChannels,
+		PlainTextChannels: c.Slack.Accessibility.PlainTextChannels,
+
+		IssueShortcutRepositories: c.Slack.IssueShortcut.Repositories,
+		IssueShortcutLabels:       c.Slack.IssueShortcut.Labels,
+		RequireWriteAccess:        c.Slack.RequireWriteAccess,
+		WorkflowSteps:             c.Slack.WorkflowSteps,
+		ShadowMode:                c.Server.Shadow,
+		WebhookCapture:            c.Server.Capture,
+	}
+	for _, tenant := range c.Tenants {
+		settings.Tenants = append(settings.Tenants, tenant.Name)
+	}
+	if c.Slack.Urgency.Enabled {
+		settings.UrgencyLevels = c.Slack.Urgency.Levels
+		if len(settings.UrgencyLevels) == 0 {
+			settings.UrgencyLevels = pipeline.DefaultUrgencyLevels
+		}
+		if c.Slack.Urgency.QuietHours != "" {
+			settings.QuietHours = c.Slack.Urgency.QuietHours
+			settings.QuietTimezone = c.Slack.Urgency.QuietTimezone
+			settings.ChannelTimezones = c.Slack.Urgency.Timezones
+		}
+	}
+	if len(c.GitHub.Poll.Repositories) > 0 {
+		settings.PollRepositories = c.GitHub.Poll.Repositories
+		settings.PollInterval = c.GitHub.Poll.Interval.String()
+	}
+	settings.MetricsDropLabels = c.Monitor.Metrics.DropLabels
+	if c.Support.ZendeskSecret != "" {
+		settings.SupportSources = append(settings.SupportSources, support.SourceZendesk)
+	}
+	if c.Support.IntercomSecret != "" {
+		settings.SupportSources = append(settings.SupportSources, support.SourceIntercom)
+	}
+	if c.Support.Enabled() {
+		settings.SupportFileRepo = c.Support.FileRepository
+	}
+	if c.GitHub.Commands.Enabled {
+		settings.CommandPermission = c.GitHub.Commands.Permission
+	}
+	if c.Pipeline.Acknowledgement.Enabled {
+		settings.AckReaction = c.Pipeline.Acknowledgement.Reaction
+	}
+	if c.Pipeline.InfoRequests.Enabled {
+		settings.NeedsInfo = true
+		settings.NeedsInfoLabel = c.Pipeline.InfoRequests.Label
+	}
+	if c.Pipeline.Reanalysis.Every > 0 {
+		settings.Reanalysis = c.Pipeline.Reanalysis.Every.String()
+		settings.ReanalysisPriority = c.Pipeline.Reanalysis.Priority
+	}
+	if c.GitHub.PermissionCheck {
+		settings.PermissionCheckRepository = c.PermissionRepository()
+	}
+	if c.Pipeline.Engagement.Enabled {
+		settings.EngagementWindow = c.Pipeline.Engagement.Window.String()
+		settings.EngagementPriority = c.Pipeline.Engagement.Priority
+		settings.EngagementChannel = c.Pipeline.Engagement.Channel
+		settings.EngagementDM = c.Pipeline.Engagement.DM
+	}
+	if c.Pipeline.Moderation.Enabled() {
+		settings.ModeratedRepositories = c.Pipeline.Moderation.Repositories
+		settings.ModerationReviewer = c.Pipeline.Moderation.Moderator
+	}
+	if c.Pipeline.SummaryMode == SummaryModeTwoStage {
+		settings.TriageModel = c.OpenAI.TriageModel
+		settings.DeepAnalysisPriority = c.Pipeline.DeepAnalysisPriority
+	}
+	return settings
+}
@@ -0,0 +1,512 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github-issue-ai-bot/internal/broker"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// Kinds of settings, by how their values are parsed
+const (
+	KindString   = "string"
+	KindBool     = "bool" // true or false, or anything else strconv.ParseBool accepts
+	KindInt      = "int"
+	KindFloat    = "float"
+	KindDuration = "duration" // e.g. 30s or 24h, never negative
+	KindList     = "list"     // Comma-separated
+)
+
+// Setting declares an environment variable the bot reads
+type Setting struct {
+	Name       string
+	Kind       string
+	Default    string // In the variable's own syntax, e.g. "30s"; empty for none
+	Min, Max   string // Inclusive bounds of numbers, empty for none
+	Required   bool
+	Secret     bool   // Redacted by config doctor; credentials are also read from KEY_FILE or SECRETS_DIR
+	Deprecated string // The setting that replaces this one, which is still read
+}
+
+// Schema declares every environment variable, in the order config doctor
+// lists them. Settings in config.yaml, such as routing rules, are not
+// environment variables and are validated as they are read.
+var Schema = []Setting{
+	// Secrets
+	{Name: "SECRETS_DIR", Kind: KindString},
+	{Name: "SECRETS_RELOAD_INTERVAL", Kind: KindDuration, Default: "1m"},
+	{Name: "SECRETS_PROVIDER", Kind: KindString, Default: "env"},
+	{Name: "SECRETS_PROVIDER_REFRESH_INTERVAL", Kind: KindDuration, Default: "15m"},
+	{Name: "VAULT_ADDR", Kind: KindString},
+	{Name: "VAULT_TOKEN", Kind: KindString, Secret: true},
+	{Name: "VAULT_ROLE", Kind: KindString},
+	{Name: "VAULT_AUTH_PATH", Kind: KindString, Default: "kubernetes"},
+	{Name: "VAULT_SECRET_PATH", Kind: KindString},
+	{Name: "VAULT_NAMESPACE", Kind: KindString},
+	{Name: "AWS_REGION", Kind: KindString},
+	{Name: "AWS_SECRET_ID", Kind: KindString},
+	{Name: "AWS_SECRETS_ENDPOINT", Kind: KindString},
+	{Name: "AWS_ACCESS_KEY_ID", Kind: KindString},
+	{Name: "AWS_SECRET_ACCESS_KEY", Kind: KindString, Secret: true},
+	{Name: "AWS_SESSION_TOKEN", Kind: KindString, Secret: true},
+
+	// Server
+	{Name: "SERVER_PORT", Kind: KindString, Default: "8080"},
+	{Name: "SERVER_READ_TIMEOUT", Kind: KindDuration, Default: "30s"},
+	{Name: "SERVER_WRITE_TIMEOUT", Kind: KindDuration, Default: "30s"},
+	{Name: "SERVER_IDLE_TIMEOUT", Kind: KindDuration, Default: "1m"},
+	{Name: "ADMIN_TOKEN", Kind: KindString, Secret: true},
+	{Name: "EXPORT_SIGNING_KEY", Kind: KindString, Secret: true},
+	{Name: "PUBLIC_URL", Kind: KindString},
+	{Name: "SHADOW_MODE", Kind: KindBool, Default: "false"},
+	{Name: "WEBHOOK_CAPTURE", Kind: KindBool, Default: "false"},
+	{Name: "WEBHOOK_CAPTURE_SIZE", Kind: KindInt, Default: "100", Min: "1"},
+
+	// GitHub
+	{Name: "GITHUB_WEBHOOK_SECRET", Kind: KindString, Required: true, Secret: true},
+	{Name: "GITHUB_ACCESS_TOKEN", Kind: KindString, Required: true, Secret: true},
+	{Name: "GITHUB_BASE_URL", Kind: KindString, Default: "https://api.github.com"},
+	{Name: "GITHUB_CLOSE_SUGGESTIONS", Kind: KindBool, Default: "true"},
+	{Name: "GITHUB_COMMENT_LIMIT", Kind: KindInt, Default: "100"},
+	{Name: "GITHUB_PERMISSION_CHECK", Kind: KindBool, Default: "true"},
+	{Name: "GITHUB_PERMISSION_CHECK_REPOSITORY", Kind: KindString},
+	{Name: "GITHUB_FETCH_ATTACHMENTS", Kind: KindBool, Default: "false"},
+	{Name: "ATTACHMENT_MAX_BYTES", Kind: KindInt, Default: "1048576"},
+	{Name: "ATTACHMENT_MAX_FILES", Kind: KindInt, Default: "3"},
+	{Name: "ATTACHMENT_MAX_LINES", Kind: KindInt, Default: "40"},
+	{Name: "GITHUB_WEBHOOK_IP_ALLOWLIST", Kind: KindBool, Default: "false"},
+	{Name: "GITHUB_WEBHOOK_META", Kind: KindBool, Default: "true"},
+	{Name: "GITHUB_META_REFRESH", Kind: KindDuration, Default: "1h"},
+	{Name: "GITHUB_WEBHOOK_ALLOWED_IPS", Kind: KindList},
+	{Name: "GITHUB_WEBHOOK_TRUSTED_PROXIES", Kind: KindList},
+	{Name: "GITHUB_PREFETCH_LINKED", Kind: KindBool, Default: "true"},
+	{Name: "LINKED_ISSUE_TTL", Kind: KindDuration, Default: "1h"},
+	{Name: "LINKED_ISSUE_MAX", Kind: KindInt, Default: "10"},
+	{Name: "GITHUB_REGRESSION_HINTS", Kind: KindBool, Default: "false"},
+	{Name: "REGRESSION_WINDOW", Kind: KindDuration, Default: "720h"},
+	{Name: "GITHUB_DETECT_STACK", Kind: KindBool, Default: "true"},
+	{Name: "STACK_CACHE_TTL", Kind: KindDuration, Default: "24h"},
+	{Name: "CI_FAILURE_ENABLED", Kind: KindBool, Default: "false"},
+	{Name: "CI_FAILURE_LABELS", Kind: KindList, Default: "ci-failure,build-failure"},
+	{Name: "CI_FAILURE_AUTHORS", Kind: KindList, Default: "github-actions[bot]"},
+	{Name: "CI_LOG_MAX_BYTES", Kind: KindInt, Default: "4194304"},
+	{Name: "CI_LOG_MAX_LINES", Kind: KindInt, Default: "20"},
+	{Name: "GITHUB_COMMANDS", Kind: KindBool, Default: "true"},
+	{Name: "GITHUB_COMMAND_PERMISSION", Kind: KindString, Default: "write"},
+	{Name: "GITHUB_CHECKS", Kind: KindString},
+	{Name: "ENRICHMENT_STAGE_TIMEOUT", Kind: KindDuration, Default: "0s"},
+	{Name: "WEBHOOK_WORKERS", Kind: KindInt, Default: "10", Min: "1"},
+	{Name: "WEBHOOK_QUEUE_SIZE", Kind: KindInt, Default: "100", Min: "0"},
+	{Name: "WEBHOOK_SATURATION_POLICY", Kind: KindString, Default: github.SaturationReject},
+	{Name: "WEBHOOK_SPOOL_SIZE", Kind: KindInt, Default: "1000", Min: "0"},
+	{Name: "WEBHOOK_RETRY_AFTER", Kind: KindDuration, Default: "1m"},
+	{Name: "GITHUB_POLL_REPOSITORIES", Kind: KindList},
+	{Name: "GITHUB_POLL_INTERVAL", Kind: KindDuration, Default: "5m"},
+	{Name: "GITHUB_POLL_LOOKBACK", Kind: KindDuration, Default: "0s"},
+
+	// OpenAI
+	{Name: "OPENAI_API_KEY", Kind: KindString, Secret: true},
+	{Name: "OPENAI_MODEL", Kind: KindString, Default: "gpt-4"},
+	{Name: "OPENAI_MAX_TOKENS", Kind: KindInt, Default: "2000", Min: "1"},
+	{Name: "OPENAI_TEMPERATURE", Kind: KindFloat, Default: "0.7", Min: "0", Max: "2"},
+	{Name: "OPENAI_PROMPT_STYLE", Kind: KindString, Default: "master_analyst"},
+	{Name: "OPENAI_TRIAGE_MODEL", Kind: KindString, Default: "gpt-4o-mini"},
+	{Name: "OPENAI_ORG_ID", Kind: KindString},
+	{Name: "OPENAI_PROJECT_ID", Kind: KindString},
+	{Name: "OPENAI_BASE_URL", Kind: KindString},
+	{Name: "OPENAI_PROXY_URL", Kind: KindString},
+	{Name: "OPENAI_CA_CERT_FILE", Kind: KindString},
+	{Name: "OPENAI_TLS_INSECURE_SKIP_VERIFY", Kind: KindBool, Default: "false"},
+	{Name: "PROMPT_CANARY_PERCENT", Kind: KindInt, Default: "0"},
+	{Name: "PROMPT_CANARY_MIN_SAMPLES", Kind: KindInt, Default: "20"},
+	{Name: "PROMPT_CANARY_PROMOTE_AFTER", Kind: KindInt, Default: "100"},
+	{Name: "PROMPT_CANARY_TOLERANCE", Kind: KindFloat, Default: "0.1"},
+	{Name: "PROMPT_CANARY_CHANNEL", Kind: KindString},
+
+	// Slack
+	{Name: "SLACK_BOT_TOKEN", Kind: KindString, Secret: true},
+	{Name: "SLACK_SIGNING_SECRET", Kind: KindString, Secret: true},
+	{Name: "SLACK_CHANNEL_ID", Kind: KindString},
+	{Name: "SLACK_MESSAGE_TEMPLATE", Kind: KindString},
+	{Name: "SLACK_FALLBACK_TEMPLATE", Kind: KindString},
+	{Name: "SLACK_NO_EMOJI_CHANNELS", Kind: KindList},
+	{Name: "SLACK_PLAIN_TEXT_CHANNELS", Kind: KindList},
+	{Name: "SLACK_URGENCY", Kind: KindBool, Default: "true"},
+	{Name: "SLACK_QUIET_HOURS", Kind: KindString},
+	{Name: "SLACK_QUIET_TIMEZONE", Kind: KindString, Default: "UTC"},
+	{Name: "SLACK_ISSUE_REPOSITORIES", Kind: KindList},
+	{Name: "SLACK_ISSUE_LABELS", Kind: KindList},
+	{Name: "SLACK_REQUIRE_WRITE_ACCESS", Kind: KindBool, Default: "true"},
+	{Name: "SLACK_WORKFLOW_STEPS", Kind: KindBool, Default: "false"},
+
+	// Monitoring and reports
+	{Name: "METRICS_PORT", Kind: KindString, Default: "9090"},
+	{Name: "METRICS_PATH", Kind: KindString, Default: "/metrics"},
+	{Name: "SLO_AVAILABILITY_TARGET", Kind: KindFloat, Default: "0.99", Min: "0", Max: "1"},
+	{Name: "SLO_LATENCY_TARGET", Kind: KindDuration, Default: "1m"},
+	{Name: "METRICS_DROP_LABELS", Kind: KindList},
+	{Name: "METRICS_BUCKETS_HTTP", Kind: KindList},
+	{Name: "METRICS_BUCKETS_GITHUB", Kind: KindList},
+	{Name: "METRICS_BUCKETS_OPENAI", Kind: KindList},
+	{Name: "METRICS_BUCKETS_SLACK", Kind: KindList},
+	{Name: "METRICS_BUCKETS_PROCESSING", Kind: KindList},
+	{Name: "METRICS_BUCKETS_DELIVERY", Kind: KindList},
+	{Name: "DRIFT_WINDOW", Kind: KindDuration, Default: "24h"},
+	{Name: "DRIFT_BASELINE", Kind: KindDuration, Default: "336h"},
+	{Name: "DRIFT_BASELINE_DAYS", Kind: KindInt, Deprecated: "DRIFT_BASELINE"},
+	{Name: "DRIFT_THRESHOLD", Kind: KindFloat, Default: "0.3"},
+	{Name: "DRIFT_MIN_ISSUES", Kind: KindInt, Default: "10"},
+	{Name: "DRIFT_ALERT_CHANNEL", Kind: KindString},
+	{Name: "RESOURCE_SAMPLE_INTERVAL", Kind: KindDuration, Default: "15s"},
+	{Name: "RESOURCE_MEMORY_LIMIT_MB", Kind: KindInt, Default: "0", Min: "0"},
+	{Name: "RESOURCE_MEMORY_PRESSURE", Kind: KindFloat, Default: "0.8"},
+	{Name: "ENRICH_CONCURRENCY", Kind: KindInt, Default: "16"},
+	{Name: "RESOLUTION_REPORT_CHANNEL", Kind: KindString},
+	{Name: "RESOLUTION_REPORT_HOUR", Kind: KindInt, Default: "9"},
+	{Name: "RESOLUTION_REPORT_TIMEZONE", Kind: KindString, Default: "UTC"},
+	{Name: "LEADERBOARD_CHANNEL", Kind: KindString},
+	{Name: "LEADERBOARD_HOUR", Kind: KindInt, Default: "9"},
+	{Name: "LEADERBOARD_TIMEZONE", Kind: KindString, Default: "UTC"},
+	{Name: "LEADERBOARD_SIZE", Kind: KindInt, Default: "5", Min: "1"},
+	{Name: "USAGE_REPORT_CHANNEL", Kind: KindString},
+	{Name: "USAGE_REPORT_DAY", Kind: KindString, Default: "monday"},
+	{Name: "USAGE_REPORT_HOUR", Kind: KindInt, Default: "9"},
+	{Name: "USAGE_REPORT_TIMEZONE", Kind: KindString, Default: "UTC"},
+
+	// Routing
+	{Name: "SLACK_LAYOUT", Kind: KindString, Default: routing.LayoutDetailed},
+
+	// Ingestion
+	{Name: "INGEST_MODE", Kind: KindString, Default: broker.ModeMonolith},
+	{Name: "BROKER_TYPE", Kind: KindString},
+	{Name: "KAFKA_BROKERS", Kind: KindList},
+	{Name: "KAFKA_TOPIC", Kind: KindString, Default: "notifyops.webhooks"},
+	{Name: "KAFKA_GROUP_ID", Kind: KindString, Default: "notifyops-workers"},
+	{Name: "KAFKA_DEAD_LETTER_TOPIC", Kind: KindString, Default: "notifyops.webhooks.dead-letter"},
+	{Name: "NATS_URL", Kind: KindString},
+	{Name: "NATS_STREAM", Kind: KindString, Default: "NOTIFYOPS"},
+	{Name: "NATS_SUBJECT", Kind: KindString, Default: "notifyops.webhooks"},
+	{Name: "NATS_DURABLE", Kind: KindString, Default: "notifyops-workers"},
+	{Name: "BROKER_MAX_REDELIVERIES", Kind: KindInt, Default: "5", Min: "0"},
+
+	// Pipeline
+	{Name: "SUMMARY_MODE", Kind: KindString, Default: SummaryModeFull},
+	{Name: "DEEP_ANALYSIS_PRIORITY", Kind: KindString, Default: "high"},
+	{Name: "EDIT_CHANGE_THRESHOLD", Kind: KindFloat, Default: "0.15", Min: "0", Max: "1"},
+	{Name: "PREFILTER_ENABLED", Kind: KindBool, Default: "true"},
+	{Name: "TRANSLATION_ENABLED", Kind: KindBool, Default: "true"},
+	{Name: "ISSUE_MEMORY_MAX_TOKENS", Kind: KindInt, Default: strconv.Itoa(memory.DefaultMaxTokens), Min: "0"},
+	{Name: "INCIDENT_CHANNELS_ENABLED", Kind: KindBool, Default: "false"},
+	{Name: "NOTIFY_RATE_LIMIT", Kind: KindInt, Default: "0", Min: "0"},
+	{Name: "NOTIFY_RATE_WINDOW", Kind: KindDuration, Default: "1h"},
+	{Name: "AUTO_LABEL_ENABLED", Kind: KindBool, Default: "false"},
+	{Name: "ISSUE_ACK_ENABLED", Kind: KindBool, Default: "false"},
+	{Name: "ISSUE_ACK_REACTION", Kind: KindString, Default: "eyes"},
+	{Name: "ISSUE_ACK_FAILED_REACTION", Kind: KindString},
+	{Name: "NEEDS_INFO_ENABLED", Kind: KindBool, Default: "false"},
+	{Name: "NEEDS_INFO_LABEL", Kind: KindString, Default: "needs-info"},
+	{Name: "INCIDENT_CATEGORIES", Kind: KindList, Default: "security"},
+	{Name: "INCIDENT_PRIORITY", Kind: KindString, Default: "high"},
+	{Name: "INCIDENT_RESPONDERS", Kind: KindList},
+	{Name: "INCIDENT_CHANNEL_PREFIX", Kind: KindString, Default: "inc"},
+	{Name: "PREFILTER_LABELS", Kind: KindList, Default: "invalid,spam"},
+	{Name: "PREFILTER_TITLE_KEYWORDS", Kind: KindList, Default: "test,testing,test issue,asdf,ignore"},
+	{Name: "PREFILTER_MIN_BODY_LENGTH", Kind: KindInt, Default: "20", Min: "0"},
+	{Name: "REACTION_BOOST_THRESHOLD", Kind: KindInt, Default: "0", Min: "0"},
+	{Name: "REACTION_BOOST_INTERVAL", Kind: KindDuration, Default: "30m"},
+	{Name: "REACTION_BOOST_RELABEL", Kind: KindBool, Default: "false"},
+	{Name: "REANALYSIS_INTERVAL", Kind: KindDuration, Default: "0s"},
+	{Name: "REANALYSIS_DAYS", Kind: KindInt, Deprecated: "REANALYSIS_INTERVAL"},
+	{Name: "REANALYSIS_PRIORITY", Kind: KindString, Default: "high"},
+	{Name: "ENGAGEMENT_ESCALATION", Kind: KindBool, Default: "false"},
+	{Name: "ENGAGEMENT_WINDOW", Kind: KindDuration, Default: "4h"},
+	{Name: "ENGAGEMENT_PRIORITY", Kind: KindString, Default: "high"},
+	{Name: "ENGAGEMENT_ESCALATION_CHANNEL", Kind: KindString},
+	{Name: "ENGAGEMENT_ESCALATION_DM", Kind: KindString},
+	{Name: "MODERATION_REPOSITORIES", Kind: KindList},
+	{Name: "MODERATION_REVIEWER", Kind: KindString},
+
+	// On-call
+	{Name: "ONCALL_MENTION_PRIORITY", Kind: KindString, Default: "high"},
+
+	// Teams
+	{Name: "TEAM_DIGEST_DAY", Kind: KindString, Default: "monday"},
+	{Name: "TEAM_DIGEST_HOUR", Kind: KindInt, Default: "9"},
+	{Name: "TEAM_DIGEST_TIMEZONE", Kind: KindString, Default: "UTC"},
+
+	// Timeouts
+	{Name: "ENRICH_TIMEOUT", Kind: KindDuration, Default: "30s"},
+	{Name: "AI_TIMEOUT", Kind: KindDuration, Default: "2m"},
+	{Name: "SLACK_TIMEOUT", Kind: KindDuration, Default: "15s"},
+
+	// Circuit breakers
+	{Name: "BREAKER_FAILURES", Kind: KindInt, Default: "5"},
+	{Name: "BREAKER_COOLDOWN", Kind: KindDuration, Default: "30s"},
+
+	// Rate limits
+	{Name: "RATE_LIMIT_ENABLED", Kind: KindBool, Default: "true"},
+	{Name: "RATE_LIMIT_WEBHOOK_RATE", Kind: KindFloat, Default: "10", Min: "0"},
+	{Name: "RATE_LIMIT_WEBHOOK_BURST", Kind: KindInt, Default: "200", Min: "1"},
+	{Name: "RATE_LIMIT_API_RATE", Kind: KindFloat, Default: "2", Min: "0"},
+	{Name: "RATE_LIMIT_API_BURST", Kind: KindInt, Default: "20", Min: "1"},
+	{Name: "RATE_LIMIT_GLOBAL_RATE", Kind: KindFloat, Default: "50", Min: "0"},
+	{Name: "RATE_LIMIT_GLOBAL_BURST", Kind: KindInt, Default: "500", Min: "1"},
+
+	// Issue trackers
+	{Name: "LINEAR_API_KEY", Kind: KindString, Secret: true},
+	{Name: "LINEAR_WEBHOOK_SECRET", Kind: KindString, Secret: true},
+	{Name: "SHORTCUT_API_TOKEN", Kind: KindString, Secret: true},
+	{Name: "SHORTCUT_WEBHOOK_SECRET", Kind: KindString, Secret: true},
+
+	// Identity
+	{Name: "IDENTITY_EMAIL_MATCHING", Kind: KindBool, Default: "false"},
+
+	// Support tickets
+	{Name: "ZENDESK_WEBHOOK_SECRET", Kind: KindString, Secret: true},
+	{Name: "INTERCOM_CLIENT_SECRET", Kind: KindString, Secret: true},
+	{Name: "SUPPORT_REPOSITORY_OWNER", Kind: KindString, Default: "support"},
+	{Name: "SUPPORT_FILE_REPOSITORY", Kind: KindString},
+	{Name: "SUPPORT_FILE_LABELS", Kind: KindList, Default: "support"},
+
+	// Logging
+	{Name: "LOG_LEVEL", Kind: KindString, Default: "info"},
+	{Name: "SUMMARY_LOG", Kind: KindString},
+}
+
+// Sources of a setting's value besides the files and secrets providers it
+// may be read from
+const (
+	SourceEnv     = "env"
+	SourceDotEnv  = ".env"
+	SourceDefault = "default"
+)
+
+// Value is the effective value of a setting and where it came from
+type Value struct {
+	Setting
+	Raw    string // As read, before parsing; the default when the value was invalid
+	Source string // env, .env, default, "file <path>" or "provider <name>"
+}
+
+// Display returns the value as config doctor prints it, with secrets
+// redacted down to their last four characters
+func (v Value) Display() string {
+	switch {
+	case v.Raw == "":
+		return "-"
+	case !v.Secret:
+		return v.Raw
+	case len(v.Raw) >= 16:
+		return "****" + v.Raw[len(v.Raw)-4:]
+	default:
+		return "****"
+	}
+}
+
+// SettingErrors lists every invalid setting, so they can all be fixed at once
+type SettingErrors []string
+
+func (e SettingErrors) Error() string {
+	if len(e) == 1 {
+		return e[0]
+	}
+	return fmt.Sprintf("%d invalid settings: %s", len(e), strings.Join(e, "; "))
+}
+
+// env reads the settings of the schema, recording where each value came from
+// and every invalid value. Invalid values fall back to their defaults, so
+// loading completes and all problems are reported together.
+type env struct {
+	schema   map[string]Setting
+	exported map[string]bool // Variables set before the .env file was loaded
+	secrets  *SecretsConfig  // Where credentials are also read from, set once SECRETS_DIR is read
+	values   map[string]Value
+	problems SettingErrors
+	warnings []string
+}
+
+func newEnv(exported map[string]bool) *env {
+	schema := make(map[string]Setting, len(Schema))
+	for _, setting := range Schema {
+		schema[setting.Name] = setting
+	}
+	return &env{
+		schema:   schema,
+		exported: exported,
+		secrets:  &SecretsConfig{Files: make(map[string]string)},
+		values:   make(map[string]Value, len(Schema)),
+	}
+}
+
+// setting returns a declared setting. Reading an undeclared one is a bug.
+func (e *env) setting(name, kind string) Setting {
+	setting, ok := e.schema[name]
+	if !ok || setting.Kind != kind {
+		panic(fmt.Sprintf("config: %s is not a declared %s setting", name, kind))
+	}
+	return setting
+}
+
+// lookup returns a setting's raw value, or its default when it is unset
+func (e *env) lookup(name, kind string) (Setting, string) {
+	setting := e.setting(name, kind)
+	raw := os.Getenv(name)
+	source := SourceEnv
+	switch {
+	case raw == "":
+		raw, source = setting.Default, SourceDefault
+	case !e.exported[name]:
+		source = SourceDotEnv
+	}
+	e.values[name] = Value{Setting: setting, Raw: raw, Source: source}
+	if setting.Deprecated != "" && source != SourceDefault {
+		e.warnings = append(e.warnings, fmt.Sprintf("%s is deprecated, use %s instead", name, setting.Deprecated))
+	}
+	return setting, raw
+}
+
+// invalid records a value that could not be used and falls back to the
+// default, which is returned
+func (e *env) invalid(setting Setting, raw string, err error) string {
+	e.problems = append(e.problems, fmt.Sprintf("%s=%q %v", setting.Name, raw, err))
+	value := e.values[setting.Name]
+	value.Raw, value.Source = setting.Default, SourceDefault+" ("+value.Source+" value is invalid)"
+	e.values[setting.Name] = value
+	return setting.Default
+}
+
+// IsSet reports whether a setting read so far was given a value
+func (e *env) IsSet(name string) bool {
+	value, ok := e.values[name]
+	return ok && value.Source != SourceDefault
+}
+
+func (e *env) String(name string) string {
+	_, raw := e.lookup(name, KindString)
+	return raw
+}
+
+// Secret reads a credential from the environment, a KEY_FILE variant or the
+// secrets directory. A file that cannot be read is reported as a problem.
+func (e *env) Secret(name string) string {
+	setting := e.setting(name, KindString)
+	value, err := getSecretEnv(name, e.secrets.Dir, e.secrets.Files)
+	if err != nil {
+		e.problems = append(e.problems, err.Error())
+	}
+	source := SourceEnv
+	if path, ok := e.secrets.Files[name]; ok {
+		source = "file " + path
+	} else if value == "" {
+		source = SourceDefault
+	} else if !e.exported[name] {
+		source = SourceDotEnv
+	}
+	e.values[name] = Value{Setting: setting, Raw: value, Source: source}
+	return value
+}
+
+func (e *env) Bool(name string) bool {
+	setting, raw := e.lookup(name, KindBool)
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		value, _ = strconv.ParseBool(e.invalid(setting, raw, fmt.Errorf("must be true or false")))
+	}
+	return value
+}
+
+func (e *env) Int(name string) int {
+	setting, raw := e.lookup(name, KindInt)
+	if raw == "" {
+		return 0
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		err = fmt.Errorf("is not a whole number")
+	} else {
+		err = setting.inRange(float64(value))
+	}
+	if err != nil {
+		value, _ = strconv.Atoi(e.invalid(setting, raw, err))
+	}
+	return value
+}
+
+func (e *env) Float(name string) float64 {
+	setting, raw := e.lookup(name, KindFloat)
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		err = fmt.Errorf("is not a number")
+	} else {
+		err = setting.inRange(value)
+	}
+	if err != nil {
+		value, _ = strconv.ParseFloat(e.invalid(setting, raw, err), 64)
+	}
+	return value
+}
+
+func (e *env) Duration(name string) time.Duration {
+	setting, raw := e.lookup(name, KindDuration)
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		err = fmt.Errorf("is not a duration such as 30s, 15m or 24h")
+	} else if value < 0 {
+		err = fmt.Errorf("must not be negative")
+	}
+	if err != nil {
+		value, _ = time.ParseDuration(e.invalid(setting, raw, err))
+	}
+	return value
+}
+
+func (e *env) List(name string) []string {
+	_, raw := e.lookup(name, KindList)
+	return splitList(raw)
+}
+
+// provide records a credential fetched from a secrets provider, which takes
+// precedence over the environment
+func (e *env) provide(name, value, provider string) {
+	if setting, ok := e.schema[name]; ok {
+		e.values[name] = Value{Setting: setting, Raw: value, Source: "provider " + provider}
+	}
+}
+
+// settings checks that required settings have values and returns every
+// setting read, in the schema's order
+func (e *env) settings() []Value {
+	values := make([]Value, 0, len(e.values))
+	for _, setting := range Schema {
+		value, ok := e.values[setting.Name]
+		if !ok {
+			continue
+		}
+		if setting.Required && value.Raw == "" {
+			e.problems = append(e.problems, fmt.Sprintf("%s is required", setting.Name))
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// inRange checks a number against the setting's bounds
+func (s Setting) inRange(value float64) error {
+	min, errMin := strconv.ParseFloat(s.Min, 64)
+	max, errMax := strconv.ParseFloat(s.Max, 64)
+	switch {
+	case errMin == nil && errMax == nil && (value < min || value > max):
+		return fmt.Errorf("must be between %s and %s", s.Min, s.Max)
+	case errMin == nil && value < min:
+		return fmt.Errorf("must be at least %s", s.Min)
+	case errMax == nil && value > max:
+		return fmt.Errorf("must be at most %s", s.Max)
+	}
+	return nil
+}
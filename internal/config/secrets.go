@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SecretsConfig holds settings for file-backed secrets. Every credential can
+// be supplied directly, through a KEY_FILE variant pointing at a file, or as a
+// file named after the key inside Dir (e.g. a mounted Kubernetes secret).
+type SecretsConfig struct {
+	Dir            string            // Directory of mounted secret files
+	ReloadInterval time.Duration     // How often file-backed secrets are re-read (0 disables)
+	Files          map[string]string // Secret key -> file it was loaded from
+
+	Provider        string            // External secrets provider: env (default), vault or aws
+	ProviderRefresh time.Duration     // Refresh interval for provider secrets without a lease
+	ProviderValues  map[string]string // Values fetched from the provider at startup
+	ProviderLease   time.Duration     // Lease reported by the provider at startup
+	Vault           VaultConfig
+	AWS             AWSSecretsConfig
+}
+
+// getSecretEnv resolves a secret from the environment, a KEY_FILE variant or
+// the secrets directory, in that order. The backing file, if any, is recorded
+// in files so it can be watched for rotation. A backing file that cannot be
+// read is an error rather than an empty secret.
+func getSecretEnv(key, secretsDir string, files map[string]string) (string, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+
+	path := secretFilePath(key, secretsDir)
+	if path == "" {
+		return "", nil
+	}
+
+	value, err := readSecretFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s file %q could not be read: %w", key, path, err)
+	}
+	files[key] = path
+	return value, nil
+}
+
+// secretFilePath returns the file backing a secret, or "" if there is none
+func secretFilePath(key, secretsDir string) string {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		return path
+	}
+
+	if secretsDir == "" {
+		return ""
+	}
+
+	// Kubernetes secret keys are commonly either the env var name itself or
+	// its lower-case, dash-separated form
+	candidates := []string{
+		key,
+		strings.ToLower(strings.ReplaceAll(key, "_", "-")),
+	}
+	for _, name := range candidates {
+		path := filepath.Join(secretsDir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// readSecretFile reads a secret file, trimming the trailing newline most
+// editors and `kubectl create secret --from-file` leave behind
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SecretWatcher periodically re-reads file-backed secrets and reports changes,
+// so rotated credentials are picked up without restarting the pod
+type SecretWatcher struct {
+	interval time.Duration
+	files    map[string]string
+	values   map[string]string
+	onChange func(key, value string)
+}
+
+// NewSecretWatcher creates a watcher for the given secret files. onChange is
+// invoked with the new value whenever a file's contents change.
+func NewSecretWatcher(files map[string]string, interval time.Duration, onChange func(key, value string)) *SecretWatcher {
+	w := &SecretWatcher{
+		interval: interval,
+		files:    make(map[string]string, len(files)),
+		values:   make(map[string]string, len(files)),
+		onChange: onChange,
+	}
+
+	for key, path := range files {
+		w.files[key] = path
+		if value, err := readSecretFile(path); err == nil {
+			w.values[key] = value
+		}
+	}
+
+	return w
+}
+
+// Run polls the secret files until the context is cancelled
+func (w *SecretWatcher) Run(ctx context.Context) {
+	if w.interval <= 0 || len(w.files) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check re-reads every watched file once and reports changed values.
+// Unreadable or empty files are ignored so a half-written rotation never
+// replaces a working credential with an empty one.
+func (w *SecretWatcher) Check() {
+	for key, path := range w.files {
+		value, err := readSecretFile(path)
+		if err != nil || value == "" {
+			continue
+		}
+		if value == w.values[key] {
+			continue
+		}
+		w.values[key] = value
+		if w.onChange != nil {
+			w.onChange(key, value)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github-issue-ai-bot/internal/broker"
+	"github-issue-ai-bot/internal/notify"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// TenantConfig holds the credentials of one tenant of a multi-tenant
+// deployment. Each tenant receives webhooks at /webhook/github/<name> and
+// /webhook/slack/<name> and has its own GitHub credentials, Slack workspace
+// and metrics; pipeline settings are shared. Tenants are read from the
+// tenants key of the config file, where credentials may reference
+// environment variables as ${NAME}.
+type TenantConfig struct {
+	Name string `mapstructure:"name"` // Lowercase letters, digits and dashes, used in webhook paths and metric labels
+
+	GitHubAccessToken   string `mapstructure:"github_access_token"`
+	GitHubWebhookSecret string `mapstructure:"github_webhook_secret"`
+
+	OpenAIAPIKey      string `mapstructure:"openai_api_key"`      // Own key; empty shares the deployment's key
+	OpenAIDailyTokens int    `mapstructure:"openai_daily_tokens"` // Daily token quota on the shared key, 0 for none
+
+	SlackBotToken      string `mapstructure:"slack_bot_token"`
+	SlackSigningSecret string `mapstructure:"slack_signing_secret"`
+	SlackChannelID     string `mapstructure:"slack_channel_id"`
+
+	RoutingRules []routing.Rule  `mapstructure:"routing_rules"` // Defaults to none, so every issue goes to SlackChannelID
+	Notifiers    []notify.Config `mapstructure:"notifiers"`     // Receive the tenant's summaries besides Slack
+}
+
+// DefaultTenant names the tenant served by the unprefixed webhook paths with
+// the deployment's own credentials
+const DefaultTenant = "default"
+
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// expandCredentials replaces ${NAME} references in the tenant's credentials
+// with environment variables
+func (t *TenantConfig) expandCredentials() {
+	for _, value := range []*string{
+		&t.GitHubAccessToken, &t.GitHubWebhookSecret, &t.OpenAIAPIKey,
+		&t.SlackBotToken, &t.SlackSigningSecret, &t.SlackChannelID,
+	} {
+		*value = os.ExpandEnv(*value)
+	}
+	expandNotifiers(t.Notifiers)
+}
+
+// validateTenants checks that tenant names are unique and usable in paths,
+// and that each tenant has its credentials
+func (c *Config) validateTenants() error {
+	if len(c.Tenants) == 0 {
+		return nil
+	}
+	if c.Ingest.Mode != "" && c.Ingest.Mode != broker.ModeMonolith {
+		return fmt.Errorf("tenants are only supported in monolith mode")
+	}
+
+	seen := map[string]bool{DefaultTenant: true}
+	for _, tenant := range c.Tenants {
+		if !tenantNamePattern.MatchString(tenant.Name) {
+			return fmt.Errorf("invalid tenant name %q", tenant.Name)
+		}
+		if seen[tenant.Name] {
+			return fmt.Errorf("duplicate tenant %q", tenant.Name)
+		}
+		if tenant.Name == "events" && c.Slack.WorkflowSteps {
+			return fmt.Errorf("tenant name %q is taken by the Slack events endpoint", tenant.Name)
+		}
+		seen[tenant.Name] = true
+
+		switch {
+		case tenant.GitHubAccessToken == "":
+			return fmt.Errorf("tenant %s: github_access_token is required", tenant.Name)
+		case tenant.GitHubWebhookSecret == "":
+			return fmt.Errorf("tenant %s: github_webhook_secret is required", tenant.Name)
+		case tenant.SlackBotToken == "":
+			return fmt.Errorf("tenant %s: slack_bot_token is required", tenant.Name)
+		case tenant.SlackSigningSecret == "":
+			return fmt.Errorf("tenant %s: slack_signing_secret is required", tenant.Name)
+		case tenant.SlackChannelID == "":
+			return fmt.Errorf("tenant %s: slack_channel_id is required", tenant.Name)
+		case tenant.OpenAIDailyTokens < 0:
+			return fmt.Errorf("tenant %s: openai_daily_tokens must not be negative", tenant.Name)
+		}
+	}
+	return nil
+}
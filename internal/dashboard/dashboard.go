@@ -0,0 +1,245 @@
+package dashboard
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// Event API limits
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 500
+)
+
+// staticFiles holds the dashboard page. The page itself holds no data; it
+// asks for the admin token and calls the JSON APIs with it.
+//
+//go:embed static
+var staticFiles embed.FS
+
+// repoActions are the event actions shown in each repository's settings
+var repoActions = []struct{ event, action string }{
+	{"issues", "opened"},
+	{"issues", "edited"},
+	{"issues", "closed"},
+	{"issues", "reopened"},
+	{"issues", "labeled"},
+	{"issues", "assigned"},
+	{"issues", "milestoned"},
+	{"issue_comment", "created"},
+}
+
+// repoPriorities are the priorities shown in each repository's routes
+var repoPriorities = []string{"critical", "high", "medium", "low"}
+
+// StatsSource reports pipeline health and OpenAI usage
+type StatsSource interface {
+	SLOSummary() monitor.SLOSummary
+	UsageSummary() monitor.UsageSummary
+}
+
+// ActionResolver decides the pipeline behavior for an event action
+type ActionResolver interface {
+	Behavior(repository, eventType, action string) github.Behavior
+}
+
+// ChannelRouter picks the Slack channel and layout for an issue
+type ChannelRouter interface {
+	Route(issue routing.Issue) routing.Route
+}
+
+// Overview is the dashboard's headline status
+type Overview struct {
+	StartedAt    time.Time            `json:"started_at"`
+	Uptime       string               `json:"uptime"`
+	Totals       RepoStats            `json:"totals"`
+	SLO          monitor.SLOSummary   `json:"slo"`
+	Usage        monitor.UsageSummary `json:"usage"`
+	Repositories []RepoStats          `json:"repositories"`
+}
+
+// RepoRoute is where a repository's issues of one priority are posted
+type RepoRoute struct {
+	Priority string `json:"priority"`
+	Rule     string `json:"rule,omitempty"`
+	Channel  string `json:"channel"`
+	Layout   string `json:"layout"`
+}
+
+// RepoSettings are the effective settings for one repository
+type RepoSettings struct {
+	Repository string            `json:"repository"`
+	Behaviors  map[string]string `json:"behaviors"` // By "event.action"
+	Routes     []RepoRoute       `json:"routes"`
+}
+
+// Handler serves the admin dashboard and its JSON APIs. The APIs require the
+// admin token.
+type Handler struct {
+	events     *EventLog
+	stats      StatsSource
+	settings   interface{}
+	actions    ActionResolver
+	router     ChannelRouter
+	adminToken *admin.Token
+	started    time.Time
+}
+
+// NewHandler creates a dashboard handler. settings is shown as is, so it must
+// not contain credentials.
+func NewHandler(events *EventLog, stats StatsSource, settings interface{}, adminToken *admin.Token) *Handler {
+	return &Handler{
+		events:     events,
+		stats:      stats,
+		settings:   settings,
+		adminToken: adminToken,
+		started:    time.Now(),
+	}
+}
+
+// SetActionResolver shows each repository's event behaviors in its settings
+func (h *Handler) SetActionResolver(actions ActionResolver) {
+	h.actions = actions
+}
+
+// SetRouter shows each repository's channel routes in its settings
+func (h *Handler) SetRouter(router ChannelRouter) {
+	h.router = router
+}
+
+// Static serves the dashboard page and its assets under prefix
+func (h *Handler) Static(prefix string) http.Handler {
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		// The directory is embedded at build time, so this cannot happen
+		panic(err)
+	}
+	return http.StripPrefix(prefix, http.FileServer(http.FS(static)))
+}
+
+// ServeOverview returns processing totals, error rates, SLO compliance and
+// the estimated OpenAI cost
+func (h *Handler) ServeOverview(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+
+	repos := h.events.Repositories()
+	totals := RepoStats{Repository: "all"}
+	for _, repo := range repos {
+		totals.Processed += repo.Processed
+		totals.Succeeded += repo.Succeeded
+		totals.Skipped += repo.Skipped
+		totals.Failed += repo.Failed
+		if repo.LastEvent.After(totals.LastEvent) {
+			totals.LastEvent = repo.LastEvent
+		}
+	}
+	if totals.Processed > 0 {
+		totals.ErrorRate = float64(totals.Failed) / float64(totals.Processed)
+	}
+
+	writeJSON(w, Overview{
+		StartedAt:    h.started.UTC(),
+		Uptime:       time.Since(h.started).Round(time.Second).String(),
+		Totals:       totals,
+		SLO:          h.stats.SLOSummary(),
+		Usage:        h.stats.UsageSummary(),
+		Repositories: repos,
+	})
+}
+
+// ServeEvents returns recent pipeline events, newest first. Query parameters:
+// repository, status and limit.
+func (h *Handler) ServeEvents(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+
+	params := r.URL.Query()
+	filter := EventFilter{
+		Repository: params.Get("repository"),
+		Status:     params.Get("status"),
+		Limit:      defaultEventLimit,
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxEventLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxEventLimit), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"events": h.events.Recent(filter),
+	})
+}
+
+// ServeSettings returns the configuration and the effective settings of each
+// repository seen, or of the repository query parameter
+func (h *Handler) ServeSettings(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+
+	var names []string
+	if repository := r.URL.Query().Get("repository"); repository != "" {
+		names = []string{repository}
+	} else {
+		for _, repo := range h.events.Repositories() {
+			names = append(names, repo.Repository)
+		}
+	}
+
+	repos := make([]RepoSettings, 0, len(names))
+	for _, name := range names {
+		repos = append(repos, h.repoSettings(name))
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"settings":     h.settings,
+		"repositories": repos,
+	})
+}
+
+// repoSettings resolves the behaviors and routes that apply to a repository
+func (h *Handler) repoSettings(repository string) RepoSettings {
+	settings := RepoSettings{
+		Repository: repository,
+		Behaviors:  make(map[string]string),
+		Routes:     make([]RepoRoute, 0),
+	}
+	if h.actions != nil {
+		for _, ea := range repoActions {
+			settings.Behaviors[ea.event+"."+ea.action] = string(h.actions.Behavior(repository, ea.event, ea.action))
+		}
+	}
+	if h.router != nil {
+		for _, priority := range repoPriorities {
+			route := h.router.Route(routing.Issue{Repository: repository, Priority: priority})
+			settings.Routes = append(settings.Routes, RepoRoute{
+				Priority: priority,
+				Rule:     route.Rule,
+				Channel:  route.Channel,
+				Layout:   route.Layout,
+			})
+		}
+	}
+	return settings
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(value)
+}
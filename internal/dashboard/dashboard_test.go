@@ -0,0 +1,167 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/routing"
+)
+
+type fakeStats struct{}
+
+func (fakeStats) SLOSummary() monitor.SLOSummary {
+	return monitor.SLOSummary{Total: 4, Succeeded: 3, Failed: 1, Availability: 0.75}
+}
+
+func (fakeStats) UsageSummary() monitor.UsageSummary {
+	return monitor.UsageSummary{EstimatedCost: 1.25}
+}
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	events := NewEventLog(10)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, status := range []string{"success", "error", "skipped", "success"} {
+		repository := "owner/api"
+		if i == 3 {
+			repository = "owner/web"
+		}
+		events.RecordEvent(pipeline.Event{Time: start.Add(time.Duration(i) * time.Minute), Repository: repository, Number: i + 1, Status: status})
+	}
+
+	router, err := routing.NewRouter([]routing.Rule{{Name: "urgent", Priorities: []string{"critical", "high"}, Channel: "C-URGENT"}}, "C-DEFAULT", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matrix, err := github.NewActionMatrix([]github.ActionRule{{Repositories: []string{"owner/web"}, Event: "issues", Actions: []string{"edited"}, Behavior: github.BehaviorIgnore}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(events, fakeStats{}, map[string]string{"summary_mode": "full"}, admin.NewToken("admin-token"))
+	h.SetActionResolver(matrix)
+	h.SetRouter(router)
+	return h
+}
+
+func get(handler http.HandlerFunc, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestEventLogKeepsLatestEvents(t *testing.T) {
+	events := NewEventLog(2)
+	for i := 1; i <= 3; i++ {
+		events.RecordEvent(pipeline.Event{Repository: "owner/api", Number: i, Status: "success"})
+	}
+
+	recent := events.Recent(EventFilter{})
+	if len(recent) != 2 || recent[0].Number != 3 || recent[1].Number != 2 {
+		t.Errorf("Expected the two newest events, newest first, got %+v", recent)
+	}
+	// Counts cover every event, not just the ones kept
+	if repos := events.Repositories(); len(repos) != 1 || repos[0].Processed != 3 {
+		t.Errorf("Expected three processed events, got %+v", repos)
+	}
+}
+
+func TestServeOverview(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := get(h.ServeOverview, "/api/dashboard/overview", "admin-token")
+	var overview Overview
+	if err := json.Unmarshal(rec.Body.Bytes(), &overview); err != nil {
+		t.Fatalf("Invalid overview %d: %s", rec.Code, rec.Body.String())
+	}
+	if overview.Totals.Processed != 4 || overview.Totals.Failed != 1 || overview.Totals.ErrorRate != 0.25 {
+		t.Errorf("Unexpected totals %+v", overview.Totals)
+	}
+	if len(overview.Repositories) != 2 || overview.Repositories[0].Repository != "owner/api" || overview.Repositories[0].Skipped != 1 {
+		t.Errorf("Unexpected repositories %+v", overview.Repositories)
+	}
+	if overview.SLO.Availability != 0.75 || overview.Usage.EstimatedCost != 1.25 {
+		t.Errorf("Expected SLO and usage to be included, got %+v %+v", overview.SLO, overview.Usage)
+	}
+}
+
+func TestServeEvents(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := get(h.ServeEvents, "/api/dashboard/events?repository=owner/api&status=error", "admin-token")
+	var body struct {
+		Events []pipeline.Event `json:"events"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || len(body.Events) != 1 || body.Events[0].Number != 2 {
+		t.Errorf("Expected the one failed event, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := get(h.ServeEvents, "/api/dashboard/events?limit=1000", "admin-token"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a limit over the maximum, got %d", rec.Code)
+	}
+}
+
+func TestServeSettings(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := get(h.ServeSettings, "/api/dashboard/settings", "admin-token")
+	var body struct {
+		Settings     map[string]string `json:"settings"`
+		Repositories []RepoSettings    `json:"repositories"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid settings %d: %s", rec.Code, rec.Body.String())
+	}
+	if body.Settings["summary_mode"] != "full" || len(body.Repositories) != 2 {
+		t.Fatalf("Unexpected settings %+v", body)
+	}
+
+	api, web := body.Repositories[0], body.Repositories[1]
+	if api.Behaviors["issues.edited"] != "resummarize" || web.Behaviors["issues.edited"] != "ignore" {
+		t.Errorf("Expected per-repository behaviors, got %v and %v", api.Behaviors, web.Behaviors)
+	}
+	if len(api.Routes) != 4 || api.Routes[0].Channel != "C-URGENT" || api.Routes[3].Channel != "C-DEFAULT" {
+		t.Errorf("Unexpected routes %+v", api.Routes)
+	}
+}
+
+func TestAPIsRequireAdminToken(t *testing.T) {
+	h := newTestHandler(t)
+
+	for _, handler := range []http.HandlerFunc{h.ServeOverview, h.ServeEvents, h.ServeSettings} {
+		if rec := get(handler, "/api/dashboard/", ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without a token, got %d", rec.Code)
+		}
+		if rec := get(handler, "/api/dashboard/", "wrong"); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 with the wrong token, got %d", rec.Code)
+		}
+	}
+}
+
+func TestStaticServesEmbeddedPage(t *testing.T) {
+	h := newTestHandler(t)
+	static := h.Static("/dashboard/")
+
+	for path, want := range map[string]string{
+		"/dashboard/":       "<title>NotifyOps dashboard</title>",
+		"/dashboard/app.js": "api/dashboard/",
+	} {
+		rec := httptest.NewRecorder()
+		static.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("Expected %s to serve %q, got %d", path, want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package dashboard
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// DefaultEventCapacity is how many recent events the log keeps
+const DefaultEventCapacity = 500
+
+// RepoStats counts the processed events of one repository since startup
+type RepoStats struct {
+	Repository string    `json:"repository"`
+	Processed  int64     `json:"processed"`
+	Succeeded  int64     `json:"succeeded"`
+	Skipped    int64     `json:"skipped"`
+	Failed     int64     `json:"failed"`
+	ErrorRate  float64   `json:"error_rate"`
+	LastEvent  time.Time `json:"last_event"`
+}
+
+// EventLog keeps the most recent pipeline events and per-repository counts.
+// It is in memory only, so it starts empty after a restart.
+type EventLog struct {
+	mu       sync.Mutex
+	capacity int
+	events   []pipeline.Event // Oldest first
+	repos    map[string]*RepoStats
+}
+
+// NewEventLog creates a log holding up to capacity events
+func NewEventLog(capacity int) *EventLog {
+	if capacity <= 0 {
+		capacity = DefaultEventCapacity
+	}
+	return &EventLog{
+		capacity: capacity,
+		repos:    make(map[string]*RepoStats),
+	}
+}
+
+// RecordEvent adds an event, dropping the oldest once the log is full
+func (l *EventLog) RecordEvent(event pipeline.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event)
+	if len(l.events) > l.capacity {
+		l.events = append(l.events[:0], l.events[len(l.events)-l.capacity:]...)
+	}
+
+	key := strings.ToLower(event.Repository)
+	stats, ok := l.repos[key]
+	if !ok {
+		stats = &RepoStats{Repository: event.Repository}
+		l.repos[key] = stats
+	}
+	stats.Processed++
+	switch event.Status {
+	case "success":
+		stats.Succeeded++
+	case "skipped":
+		stats.Skipped++
+	case "error":
+		stats.Failed++
+	}
+	stats.ErrorRate = float64(stats.Failed) / float64(stats.Processed)
+	if event.Time.After(stats.LastEvent) {
+		stats.LastEvent = event.Time
+	}
+}
+
+// EventFilter selects events from the log. Empty fields match everything.
+type EventFilter struct {
+	Repository string
+	Status     string
+	Limit      int
+}
+
+// Recent returns matching events, newest first
+func (l *EventLog) Recent(filter EventFilter) []pipeline.Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := make([]pipeline.Event, 0)
+	for i := len(l.events) - 1; i >= 0; i-- {
+		event := l.events[i]
+		if filter.Repository != "" && !strings.EqualFold(event.Repository, filter.Repository) {
+			continue
+		}
+		if filter.Status != "" && event.Status != filter.Status {
+			continue
+		}
+		events = append(events, event)
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+	}
+	return events
+}
+
+// Repositories returns the counts of every repository seen, by name
+func (l *EventLog) Repositories() []RepoStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	repos := make([]RepoStats, 0, len(l.repos))
+	for _, stats := range l.repos {
+		repos = append(repos, *stats)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return strings.ToLower(repos[i].Repository) < strings.ToLower(repos[j].Repository)
+	})
+	return repos
+}
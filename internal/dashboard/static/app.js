@@ -0,0 +1,174 @@
+// NotifyOps dashboard. The admin token is kept in session storage and sent
+// as a bearer token to the JSON APIs.
+(function () {
+  'use strict';
+
+  const tokenKey = 'notifyops-admin-token';
+  const api = '../api/dashboard/';
+
+  const $ = (id) => document.getElementById(id);
+
+  function token() {
+    return sessionStorage.getItem(tokenKey);
+  }
+
+  async function get(path) {
+    const response = await fetch(api + path, {
+      headers: { Authorization: 'Bearer ' + token() },
+    });
+    if (response.status === 401) {
+      throw new Error('unauthorized');
+    }
+    if (!response.ok) {
+      throw new Error(await response.text());
+    }
+    return response.json();
+  }
+
+  function cell(row, text, className) {
+    const td = row.insertCell();
+    td.textContent = text;
+    if (className) {
+      td.className = className;
+    }
+  }
+
+  function fill(tableID, rows, render) {
+    const body = $(tableID).tBodies[0];
+    body.replaceChildren();
+    rows.forEach((item) => render(body.insertRow(), item));
+  }
+
+  const percent = (value) => (value * 100).toFixed(1) + '%';
+  const dollars = (value) => '$' + value.toFixed(2);
+  const time = (value) => (value && !value.startsWith('0001') ? new Date(value).toLocaleString() : '–');
+
+  function renderOverview(overview) {
+    $('uptime').textContent = 'up ' + overview.uptime;
+    $('processed').textContent = overview.totals.processed;
+    $('error-rate').textContent = percent(overview.totals.error_rate);
+    $('availability').textContent = overview.slo.total ? percent(overview.slo.availability) : '–';
+    $('cost').textContent = dollars(overview.usage.estimated_cost_usd);
+
+    fill('repositories', overview.repositories, (row, repo) => {
+      cell(row, repo.repository);
+      cell(row, repo.processed);
+      cell(row, repo.succeeded);
+      cell(row, repo.skipped);
+      cell(row, repo.failed);
+      cell(row, percent(repo.error_rate), repo.failed ? 'status-error' : '');
+      cell(row, time(repo.last_event));
+    });
+
+    fill('failures', Object.entries(overview.slo.failures_by_stage || {}), (row, [stage, count]) => {
+      cell(row, stage);
+      cell(row, count);
+    });
+
+    fill('usage', overview.usage.models, (row, model) => {
+      cell(row, model.model);
+      cell(row, model.requests);
+      cell(row, model.errors);
+      cell(row, model.prompt_tokens);
+      cell(row, model.completion_tokens);
+      cell(row, model.priced ? dollars(model.estimated_cost_usd) : 'unknown price');
+    });
+  }
+
+  function renderEvents(events) {
+    fill('events', events, (row, event) => {
+      cell(row, time(event.time));
+      cell(row, event.repository + '#' + event.number);
+      cell(row, [event.event_type, event.action].filter(Boolean).join('.'));
+      cell(row, event.stage ? event.status + ' (' + event.stage + ')' : event.status, 'status-' + event.status);
+      cell(row, event.priority || '');
+      cell(row, event.detail || '');
+      cell(row, (event.duration_ns / 1e6).toFixed(0) + ' ms');
+    });
+  }
+
+  function renderSettings(result) {
+    $('settings').textContent = JSON.stringify(result.settings, null, 2);
+
+    const container = $('repo-settings');
+    container.replaceChildren();
+    result.repositories.forEach((repo) => {
+      const heading = document.createElement('h3');
+      heading.textContent = repo.repository;
+      container.appendChild(heading);
+
+      const table = document.createElement('table');
+      const head = table.createTHead().insertRow();
+      ['Setting', 'Value'].forEach((text) => cell(head, text));
+      const body = table.createTBody();
+      Object.entries(repo.behaviors).sort().forEach(([action, behavior]) => {
+        const row = body.insertRow();
+        cell(row, action);
+        cell(row, behavior);
+      });
+      repo.routes.forEach((route) => {
+        const row = body.insertRow();
+        cell(row, route.priority + ' priority');
+        cell(row, route.channel + ' (' + route.layout + (route.rule ? ', rule ' + route.rule : '') + ')');
+      });
+      container.appendChild(table);
+    });
+  }
+
+  async function refresh() {
+    try {
+      const status = $('status-filter').value;
+      const [overview, events, settings] = await Promise.all([
+        get('overview'),
+        get('events?limit=100' + (status ? '&status=' + encodeURIComponent(status) : '')),
+        get('settings'),
+      ]);
+      renderOverview(overview);
+      renderEvents(events.events);
+      renderSettings(settings);
+      $('login').hidden = true;
+      $('dashboard').hidden = false;
+    } catch (err) {
+      if (err.message === 'unauthorized') {
+        sessionStorage.removeItem(tokenKey);
+        showLogin('');
+        return;
+      }
+      console.error(err);
+    }
+  }
+
+  function showLogin(message) {
+    $('dashboard').hidden = true;
+    $('login').hidden = false;
+    $('login-error').textContent = message || '';
+  }
+
+  $('login').addEventListener('submit', (event) => {
+    event.preventDefault();
+    sessionStorage.setItem(tokenKey, $('token').value);
+    $('token').value = '';
+    refresh().then(() => {
+      if (token() === null) {
+        showLogin('Invalid admin token');
+      }
+    });
+  });
+  $('signout').addEventListener('click', () => {
+    sessionStorage.removeItem(tokenKey);
+    showLogin('');
+  });
+  $('refresh').addEventListener('click', refresh);
+  $('status-filter').addEventListener('change', refresh);
+
+  if (token() === null) {
+    showLogin('');
+  } else {
+    refresh();
+  }
+  setInterval(() => {
+    if (token() !== null) {
+      refresh();
+    }
+  }, 30000);
+})();
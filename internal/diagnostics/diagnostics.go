@@ -0,0 +1,92 @@
+package diagnostics
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Check statuses
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning" // Works, but some features may fail
+	StatusError   = "error"   // Messages or API calls will fail until fixed
+)
+
+// Check is the outcome of one self-diagnostic, with an actionable message
+// for anything that is not ok
+type Check struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Checker runs a group of checks, e.g. against the Slack API
+type Checker func(ctx context.Context) []Check
+
+// Report collects the checks of every checker
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Healthy   bool      `json:"healthy"` // No check reported an error
+	Checks    []Check   `json:"checks"`
+}
+
+// Run runs the checkers in order
+func Run(ctx context.Context, checkers ...Checker) Report {
+	report := Report{CheckedAt: time.Now(), Healthy: true, Checks: []Check{}}
+	for _, checker := range checkers {
+		for _, check := range checker(ctx) {
+			if check.Status == StatusError {
+				report.Healthy = false
+			}
+			report.Checks = append(report.Checks, check)
+		}
+	}
+	return report
+}
+
+// OK is a passing check
+func OK(name, message string) Check {
+	return Check{Name: name, Status: StatusOK, Message: message}
+}
+
+// Warning is a check that passed with a caveat
+func Warning(name, message string) Check {
+	return Check{Name: name, Status: StatusWarning, Message: message}
+}
+
+// Error is a failing check
+func Error(name, message string) Check {
+	return Check{Name: name, Status: StatusError, Message: message}
+}
+
+// MissingScopes returns the required scopes that are not granted
+func MissingScopes(granted, required []string) []string {
+	have := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		have[scope] = true
+	}
+	var missing []string
+	for _, scope := range required {
+		if !have[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// Log logs each check at a level matching its status
+func (r Report) Log(logger *zap.Logger) {
+	for _, check := range r.Checks {
+		fields := []zap.Field{zap.String("check", check.Name), zap.String("message", check.Message)}
+		switch check.Status {
+		case StatusError:
+			logger.Error("Self-diagnostic failed", fields...)
+		case StatusWarning:
+			logger.Warn("Self-diagnostic warning", fields...)
+		default:
+			logger.Info("Self-diagnostic passed", fields...)
+		}
+	}
+}
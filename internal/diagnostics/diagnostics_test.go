@@ -0,0 +1,33 @@
+package diagnostics
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	passing := func(ctx context.Context) []Check {
+		return []Check{OK("a", "fine"), Warning("b", "degraded")}
+	}
+	failing := func(ctx context.Context) []Check {
+		return []Check{Error("c", "broken")}
+	}
+
+	report := Run(context.Background(), passing)
+	if !report.Healthy || len(report.Checks) != 2 {
+		t.Errorf("Expected warnings to keep the report healthy, got %+v", report)
+	}
+
+	report = Run(context.Background(), passing, failing)
+	if report.Healthy || len(report.Checks) != 3 || report.Checks[2].Name != "c" {
+		t.Errorf("Expected an unhealthy report with every check in order, got %+v", report)
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	missing := MissingScopes([]string{"chat:write", "users:read"}, []string{"chat:write", "channels:read", "channels:manage"})
+	if want := []string{"channels:read", "channels:manage"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("Expected %v, got %v", want, missing)
+	}
+}
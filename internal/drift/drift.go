@@ -0,0 +1,321 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/store"
+)
+
+// checkInterval is how often the distributions are compared
+const checkInterval = time.Hour
+
+// Fields whose distributions are tracked
+var fields = []string{"priority", "category"}
+
+// Config sets the periods compared and when a shift alerts
+type Config struct {
+	Window    time.Duration // Recent period compared with the baseline, 0 to disable
+	Baseline  time.Duration // Period before the window that recent assignments should resemble
+	Threshold float64       // Total variation distance that alerts, between 0 and 1
+	MinIssues int           // Fewest summaries in both periods for a repository to be compared
+	Channel   string        // Slack channel for alerts, empty for metrics only
+}
+
+// Validate checks the periods, threshold and sample size
+func (c Config) Validate() error {
+	if c.Window == 0 {
+		return nil
+	}
+	if c.Window < 0 || c.Baseline < c.Window {
+		return fmt.Errorf("drift window must not be negative and the baseline must be at least as long")
+	}
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		return fmt.Errorf("drift threshold must be above 0 and at most 1, got %g", c.Threshold)
+	}
+	if c.MinIssues < 1 {
+		return fmt.Errorf("drift minimum issues must be positive, got %d", c.MinIssues)
+	}
+	return nil
+}
+
+// IssueLister lists the stored issue records
+type IssueLister interface {
+	ListIssues(query store.Query) ([]store.IssueRecord, string)
+}
+
+// Poster posts a message to a Slack channel
+type Poster interface {
+	PostMessage(ctx context.Context, channelID, kind, text string) error
+}
+
+// MetricsRecorder records the measured drift
+type MetricsRecorder interface {
+	RecordSummaryDrift(repository, field string, distance float64)
+}
+
+// Shift compares a repository's recent priorities or categories with its
+// baseline
+type Shift struct {
+	Repository string         `json:"repository"`
+	Field      string         `json:"field"`
+	Distance   float64        `json:"distance"` // Total variation distance, 0 for identical shares and 1 for disjoint ones
+	Window     map[string]int `json:"window"`
+	Baseline   map[string]int `json:"baseline"`
+	Alerting   bool           `json:"alerting"`
+}
+
+// Report is the result of the last check
+type Report struct {
+	CheckedAt     time.Time `json:"checked_at"`
+	WindowStart   time.Time `json:"window_start"`
+	BaselineStart time.Time `json:"baseline_start"`
+	Shifts        []Shift   `json:"shifts"` // Repositories with enough summaries in both periods
+}
+
+// Detector compares the priorities and categories the AI assigned to each
+// repository's issues recently with those of the period before. An abrupt
+// change often follows a prompt regression or an upstream model update
+// rather than a change in the issues themselves.
+type Detector struct {
+	issues  IssueLister
+	poster  Poster
+	metrics MetricsRecorder
+	config  Config
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	report   Report
+	alerting map[string]bool // Repository and field pairs alerted on and still above the threshold
+}
+
+// NewDetector creates a detector. poster may be nil when config has no
+// channel.
+func NewDetector(issues IssueLister, poster Poster, metrics MetricsRecorder, config Config, logger *zap.Logger) *Detector {
+	return &Detector{
+		issues:   issues,
+		poster:   poster,
+		metrics:  metrics,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+		report:   Report{Shifts: []Shift{}},
+		alerting: make(map[string]bool),
+	}
+}
+
+// Run checks for drift every hour until the context is cancelled
+func (d *Detector) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Check(ctx)
+		}
+	}
+}
+
+// Report returns the result of the last check
+func (d *Detector) Report() Report {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.report
+}
+
+// Check compares each repository's distributions, records the distances and
+// alerts once for each shift past the threshold. A shift alerts again only
+// after falling back below it.
+func (d *Detector) Check(ctx context.Context) {
+	now := d.now()
+	windowStart := now.Add(-d.config.Window)
+	baselineStart := windowStart.Add(-d.config.Baseline)
+
+	// Records are updated after they are analyzed, so this keeps every
+	// record analyzed since the baseline started
+	records, _ := d.issues.ListIssues(store.Query{Since: baselineStart})
+	type counts struct{ window, baseline map[string]map[string]int }
+	repositories := make(map[string]*counts)
+	for _, record := range records {
+		if record.Summary == nil || record.AnalyzedAt.Before(baselineStart) || record.AnalyzedAt.After(now) {
+			continue
+		}
+		repository := strings.ToLower(record.Repository)
+		c, ok := repositories[repository]
+		if !ok {
+			c = &counts{window: newFieldCounts(), baseline: newFieldCounts()}
+			repositories[repository] = c
+		}
+		period := c.baseline
+		if !record.AnalyzedAt.Before(windowStart) {
+			period = c.window
+		}
+		period["priority"][strings.ToLower(record.Summary.Priority)]++
+		period["category"][strings.ToLower(record.Summary.Category)]++
+	}
+
+	report := Report{CheckedAt: now, WindowStart: windowStart, BaselineStart: baselineStart, Shifts: []Shift{}}
+	var alerts []Shift
+	d.mu.Lock()
+	for repository, c := range repositories {
+		for _, field := range fields {
+			if total(c.window[field]) < d.config.MinIssues || total(c.baseline[field]) < d.config.MinIssues {
+				continue
+			}
+			shift := Shift{
+				Repository: repository,
+				Field:      field,
+				Distance:   distance(c.window[field], c.baseline[field]),
+				Window:     c.window[field],
+				Baseline:   c.baseline[field],
+			}
+			key := repository + " " + field
+			if shift.Distance < d.config.Threshold {
+				delete(d.alerting, key)
+			} else {
+				if !d.alerting[key] {
+					alerts = append(alerts, shift)
+				}
+				d.alerting[key] = true
+				shift.Alerting = true
+			}
+			report.Shifts = append(report.Shifts, shift)
+		}
+	}
+	sort.Slice(report.Shifts, func(i, j int) bool {
+		if report.Shifts[i].Repository != report.Shifts[j].Repository {
+			return report.Shifts[i].Repository < report.Shifts[j].Repository
+		}
+		return report.Shifts[i].Field > report.Shifts[j].Field
+	})
+	d.report = report
+	d.mu.Unlock()
+
+	for _, shift := range report.Shifts {
+		d.metrics.RecordSummaryDrift(shift.Repository, shift.Field, shift.Distance)
+	}
+	for _, shift := range alerts {
+		d.alert(ctx, shift)
+	}
+}
+
+// alert logs a shift past the threshold and posts it to the alert channel
+func (d *Detector) alert(ctx context.Context, shift Shift) {
+	d.logger.Warn("AI assignments drifted",
+		zap.String("repository", shift.Repository),
+		zap.String("field", shift.Field),
+		zap.Float64("distance", shift.Distance))
+	if d.config.Channel == "" || d.poster == nil {
+		return
+	}
+	if err := d.poster.PostMessage(ctx, d.config.Channel, "drift_alert", d.FormatAlert(shift)); err != nil {
+		d.logger.Warn("Failed to post drift alert",
+			zap.String("repository", shift.Repository),
+			zap.String("channel", d.config.Channel),
+			zap.Error(err))
+	}
+}
+
+// FormatAlert describes a shift for operators, with the values whose share
+// changed most
+func (d *Detector) FormatAlert(shift Shift) string {
+	windowTotal, baselineTotal := float64(total(shift.Window)), float64(total(shift.Baseline))
+	type change struct {
+		value         string
+		before, after float64
+	}
+	var changes []change
+	for value := range union(shift.Window, shift.Baseline) {
+		changes = append(changes, change{value, float64(shift.Baseline[value]) / baselineTotal, float64(shift.Window[value]) / windowTotal})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		di, dj := math.Abs(changes[i].after-changes[i].before), math.Abs(changes[j].after-changes[j].before)
+		return di > dj || di == dj && changes[i].value < changes[j].value
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ":warning: *%s drift in %s*: the %s assigned over the last %s differ from the %s before (distance %.2f).\n",
+		strings.ToUpper(shift.Field[:1])+shift.Field[1:], shift.Repository, pluralField(shift.Field),
+		formatPeriod(d.config.Window), formatPeriod(d.config.Baseline), shift.Distance)
+	for i, c := range changes {
+		if i == 3 {
+			break
+		}
+		value := c.value
+		if value == "" {
+			value = "(none)"
+		}
+		fmt.Fprintf(&b, "• %s: %.0f%% → %.0f%%\n", value, c.before*100, c.after*100)
+	}
+	b.WriteString("Check for recent prompt, prompt style or model changes before trusting new assignments.")
+	return b.String()
+}
+
+func newFieldCounts() map[string]map[string]int {
+	counts := make(map[string]map[string]int, len(fields))
+	for _, field := range fields {
+		counts[field] = make(map[string]int)
+	}
+	return counts
+}
+
+// distance is the total variation distance between two distributions: half
+// the sum of the absolute differences in each value's share
+func distance(a, b map[string]int) float64 {
+	totalA, totalB := float64(total(a)), float64(total(b))
+	var sum float64
+	for value := range union(a, b) {
+		sum += math.Abs(float64(a[value])/totalA - float64(b[value])/totalB)
+	}
+	return sum / 2
+}
+
+func total(counts map[string]int) int {
+	n := 0
+	for _, count := range counts {
+		n += count
+	}
+	return n
+}
+
+func union(a, b map[string]int) map[string]bool {
+	values := make(map[string]bool, len(a)+len(b))
+	for value := range a {
+		values[value] = true
+	}
+	for value := range b {
+		values[value] = true
+	}
+	return values
+}
+
+func pluralField(field string) string {
+	if field == "category" {
+		return "categories"
+	}
+	return field + "s"
+}
+
+// formatPeriod writes whole days as days, e.g. "14 days", and shorter periods
+// as durations
+func formatPeriod(period time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case period == day:
+		return "day"
+	case period%day == 0:
+		return fmt.Sprintf("%d days", period/day)
+	default:
+		return period.String()
+	}
+}
@@ -0,0 +1,142 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/store"
+)
+
+type fakePoster struct {
+	texts []string
+}
+
+func (p *fakePoster) PostMessage(ctx context.Context, channelID, kind, text string) error {
+	p.texts = append(p.texts, text)
+	return nil
+}
+
+type fakeMetrics struct {
+	distances map[string]float64
+}
+
+func (m *fakeMetrics) RecordSummaryDrift(repository, field string, distance float64) {
+	m.distances[repository+" "+field] = distance
+}
+
+var now = time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+
+// addIssues stores count summaries of repository analyzed ago before now
+func addIssues(issues *store.MemoryStore, repository string, first, count int, priority, category string, ago time.Duration) {
+	for i := 0; i < count; i++ {
+		issues.SaveIssue(&store.IssueRecord{
+			Repository: repository,
+			Number:     first + i,
+			Summary:    &ai.IssueSummary{Priority: priority, Category: category},
+			AnalyzedAt: now.Add(-ago),
+			UpdatedAt:  now.Add(-ago),
+		})
+	}
+}
+
+func newTestDetector(issues *store.MemoryStore) (*Detector, *fakePoster, *fakeMetrics) {
+	poster := &fakePoster{}
+	metrics := &fakeMetrics{distances: make(map[string]float64)}
+	d := NewDetector(issues, poster, metrics, Config{
+		Window:    24 * time.Hour,
+		Baseline:  14 * 24 * time.Hour,
+		Threshold: 0.3,
+		MinIssues: 5,
+		Channel:   "C-OPS",
+	}, zap.NewNop())
+	d.now = func() time.Time { return now }
+	return d, poster, metrics
+}
+
+func TestCheckAlertsOnShift(t *testing.T) {
+	issues := store.NewMemoryStore()
+	// my-org/api: security jumps from 1 in 20 to 6 in 10
+	addIssues(issues, "my-org/api", 1, 19, "medium", "bug", 5*24*time.Hour)
+	addIssues(issues, "my-org/api", 20, 1, "medium", "security", 3*24*time.Hour)
+	addIssues(issues, "my-org/api", 30, 4, "medium", "bug", time.Hour)
+	addIssues(issues, "my-org/api", 40, 6, "medium", "security", 2*time.Hour)
+	// my-org/web: steady
+	addIssues(issues, "my-org/web", 1, 10, "low", "feature", 4*24*time.Hour)
+	addIssues(issues, "my-org/web", 20, 5, "low", "feature", 3*time.Hour)
+	// my-org/cli: too few recent summaries to compare
+	addIssues(issues, "my-org/cli", 1, 10, "low", "bug", 4*24*time.Hour)
+	addIssues(issues, "my-org/cli", 20, 2, "critical", "security", time.Hour)
+	// Before the baseline
+	addIssues(issues, "my-org/api", 100, 30, "critical", "security", 30*24*time.Hour)
+
+	d, poster, metrics := newTestDetector(issues)
+	d.Check(context.Background())
+
+	if len(poster.texts) != 1 {
+		t.Fatalf("Expected one alert, got %v", poster.texts)
+	}
+	for _, want := range []string{"Category drift in my-org/api", "last day", "14 days", "security: 5% → 60%", "bug: 95% → 40%"} {
+		if !strings.Contains(poster.texts[0], want) {
+			t.Errorf("Expected the alert to contain %q:\n%s", want, poster.texts[0])
+		}
+	}
+	if got := metrics.distances["my-org/api category"]; fmt.Sprintf("%.2f", got) != "0.55" {
+		t.Errorf("Expected a category distance of 0.55, got %v", got)
+	}
+	if got, ok := metrics.distances["my-org/api priority"]; !ok || got != 0 {
+		t.Errorf("Expected no priority drift, got %v", got)
+	}
+	if _, ok := metrics.distances["my-org/cli category"]; ok {
+		t.Error("Expected no distance for a repository with too few summaries")
+	}
+
+	report := d.Report()
+	if len(report.Shifts) != 4 || report.Shifts[0].Repository != "my-org/api" || !report.Shifts[1].Alerting {
+		t.Errorf("Unexpected report %+v", report.Shifts)
+	}
+
+	// The shift alerted once and does not alert again while it lasts
+	d.Check(context.Background())
+	if len(poster.texts) != 1 {
+		t.Errorf("Expected no repeated alert, got %d", len(poster.texts))
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b map[string]int
+		want float64
+	}{
+		{map[string]int{"bug": 3}, map[string]int{"bug": 30}, 0},
+		{map[string]int{"bug": 1}, map[string]int{"security": 4}, 1},
+		{map[string]int{"bug": 1, "security": 1}, map[string]int{"bug": 3, "security": 1}, 0.25},
+	}
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, valid := range []Config{{Window: time.Hour, Baseline: 24 * time.Hour, Threshold: 0.3, MinIssues: 10}, {}} {
+		if err := valid.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", valid, err)
+		}
+	}
+	for name, config := range map[string]Config{
+		"baseline shorter than window": {Window: 48 * time.Hour, Baseline: 24 * time.Hour, Threshold: 0.3, MinIssues: 10},
+		"threshold above 1":            {Window: time.Hour, Baseline: 24 * time.Hour, Threshold: 1.5, MinIssues: 10},
+		"no minimum":                   {Window: time.Hour, Baseline: 24 * time.Hour, Threshold: 0.3},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
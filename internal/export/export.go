@@ -0,0 +1,413 @@
+package export
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// SchemaVersion is the version of the exported row format. It is bumped
+// whenever a field changes meaning or is removed; new fields may be added
+// without a bump.
+const SchemaVersion = 1
+
+// Export limits
+const (
+	defaultPageSize = 1000
+	maxPageSize     = 10000
+	defaultURLTTL   = time.Hour
+	maxURLTTL       = 7 * 24 * time.Hour
+)
+
+// Formats
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// IssueLister lists stored issue records
+type IssueLister interface {
+	ListIssues(query store.Query) ([]store.IssueRecord, string)
+}
+
+// Row is one exported issue summary
+type Row struct {
+	SchemaVersion int       `json:"schema_version"`
+	Repository    string    `json:"repository"`
+	Number        int       `json:"number"`
+	Title         string    `json:"title"`
+	State         string    `json:"state"`
+	Priority      string    `json:"priority,omitempty"`
+	Category      string    `json:"category,omitempty"`
+	Summary       string    `json:"summary,omitempty"`
+	Confidence    float64   `json:"confidence,omitempty"`
+	Components    []string  `json:"components,omitempty"`
+	Language      string    `json:"language,omitempty"`
+	SkipReason    string    `json:"skip_reason,omitempty"`
+	Channel       string    `json:"channel,omitempty"`
+	MessageTS     string    `json:"message_ts,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	OS            string    `json:"os,omitempty"`
+	Browser       string    `json:"browser,omitempty"`
+	Version       string    `json:"version,omitempty"`
+	GoVersion     string    `json:"go_version,omitempty"`
+
+	// SummarySchemaVersion is the ai.SummarySchemaVersion of the summary
+	// fields, 0 without a summary. Columns are only ever appended, so
+	// consumers of older exports keep working.
+	SummarySchemaVersion int `json:"summary_schema_version,omitempty"`
+}
+
+// csvHeader lists the CSV columns, in Row field order
+var csvHeader = []string{
+	"schema_version", "repository", "number", "title", "state", "priority", "category",
+	"summary", "confidence", "components", "language", "skip_reason", "channel", "message_ts", "updated_at",
+	"os", "browser", "version", "go_version", "summary_schema_version",
+}
+
+// Handler serves stored summaries for analytics. Requests are authorized by
+// the admin token or, for download links, by an HMAC signature.
+type Handler struct {
+	issues     IssueLister
+	adminToken *admin.Token
+	baseURL    string
+	logger     *zap.Logger
+
+	mu         sync.RWMutex
+	signingKey []byte
+}
+
+// NewHandler creates an export handler. Without a signing key, signed
+// download URLs are disabled. baseURL makes signed URLs absolute.
+func NewHandler(issues IssueLister, adminToken *admin.Token, signingKey, baseURL string, logger *zap.Logger) *Handler {
+	return &Handler{
+		issues:     issues,
+		adminToken: adminToken,
+		signingKey: []byte(signingKey),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		logger:     logger,
+	}
+}
+
+// SetSigningKey replaces the key download URLs are signed with, e.g. after a
+// mounted secret has been rotated. URLs signed with the old key stop working.
+func (h *Handler) SetSigningKey(signingKey string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.signingKey = []byte(signingKey)
+}
+
+// key returns the signing key, empty without one
+func (h *Handler) key() []byte {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.signingKey
+}
+
+// NewRow converts a stored record into an export row
+func NewRow(record store.IssueRecord) Row {
+	row := Row{
+		SchemaVersion: SchemaVersion,
+		Repository:    record.Repository,
+		Number:        record.Number,
+		Title:         record.Title,
+		State:         record.State,
+		Language:      record.Language,
+		SkipReason:    record.SkipReason,
+		Channel:       record.Channel,
+		MessageTS:     record.MessageTS,
+		UpdatedAt:     record.UpdatedAt.UTC(),
+		OS:            record.Environment.OS,
+		Browser:       record.Environment.Browser,
+		Version:       record.Environment.Version,
+		GoVersion:     record.Environment.GoVersion,
+	}
+	if summary := record.Summary; summary != nil {
+		row.Priority = summary.Priority
+		row.Category = summary.Category
+		row.Summary = summary.Summary
+		row.Confidence = summary.Confidence
+		row.Components = summary.Components
+		row.SummarySchemaVersion = summary.SchemaVersion
+	}
+	return row
+}
+
+// ServeExport streams summaries as NDJSON or CSV. Query parameters:
+// format, repository, priority and category (comma-separated), since, until
+// (RFC 3339 or YYYY-MM-DD), q, os, browser, version, go_version, limit and
+// cursor. The cursor for the next page is returned in
+// the X-Next-Cursor header and a Link header.
+func (h *Handler) ServeExport(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	if !h.validSignature(r.URL.Path, params, time.Now()) && !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+
+	format, query, err := parseQuery(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, next := h.issues.ListIssues(query)
+
+	w.Header().Set("X-Export-Schema-Version", strconv.Itoa(SchemaVersion))
+	if next != "" {
+		// The cursor is not signed, so signed URLs can be paged through too
+		cursor := base64.RawURLEncoding.EncodeToString([]byte(next))
+		nextParams := copyParams(params)
+		nextParams.Set("cursor", cursor)
+		w.Header().Set("X-Next-Cursor", cursor)
+		w.Header().Set("Link", fmt.Sprintf("<%s?%s>; rel=\"next\"", r.URL.Path, nextParams.Encode()))
+	}
+
+	if format == FormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="notifyops-export.csv"`)
+		err = writeCSV(w, records)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		err = writeNDJSON(w, records)
+	}
+	if err != nil {
+		// Headers are already sent, so the client sees a truncated body
+		h.logger.Error("Failed to write export", zap.Error(err))
+		return
+	}
+
+	h.logger.Info("Exported summaries",
+		zap.String("format", format),
+		zap.Int("rows", len(records)),
+		zap.Bool("more", next != ""),
+	)
+}
+
+// ServeSign returns a signed download URL for the export with the same query
+// parameters, valid for the ttl parameter (default 1h, at most 7 days)
+func (h *Handler) ServeSign(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	key := h.key()
+	if len(key) == 0 {
+		http.Error(w, "signed export URLs are not configured", http.StatusNotImplemented)
+		return
+	}
+
+	params := unsignedParams(r.URL.Query())
+	ttl := defaultURLTTL
+	if value := params.Get("ttl"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxURLTTL {
+			http.Error(w, "ttl must be a positive duration of at most 168h", http.StatusBadRequest)
+			return
+		}
+		ttl = parsed
+	}
+	params.Del("ttl")
+	if _, _, err := parseQuery(params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	expires := time.Now().Add(ttl).UTC()
+	path := strings.TrimSuffix(r.URL.Path, "/sign")
+	params.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	params.Set("signature", sign(key, path, params))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"url":        fmt.Sprintf("%s%s?%s", h.baseURL, path, params.Encode()),
+		"expires_at": expires,
+	})
+}
+
+// sign computes the signature over the path and the parameters other than
+// the signature itself and the page cursor
+func sign(key []byte, path string, params url.Values) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(path + "?" + unsignedParams(params).Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validSignature checks a signed download URL and its expiry
+func (h *Handler) validSignature(path string, params url.Values, now time.Time) bool {
+	key := h.key()
+	signature := params.Get("signature")
+	if len(key) == 0 || signature == "" {
+		return false
+	}
+	expires, err := strconv.ParseInt(params.Get("expires"), 10, 64)
+	if err != nil || now.Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(sign(key, path, params)))
+}
+
+// unsignedParams returns a copy of params without the signature and cursor
+func unsignedParams(params url.Values) url.Values {
+	unsigned := copyParams(params)
+	unsigned.Del("signature")
+	unsigned.Del("cursor")
+	return unsigned
+}
+
+func copyParams(params url.Values) url.Values {
+	copied := url.Values{}
+	for key, values := range params {
+		copied[key] = append([]string(nil), values...)
+	}
+	return copied
+}
+
+// parseQuery reads the export format and filters from query parameters
+func parseQuery(params url.Values) (string, store.Query, error) {
+	query := store.Query{
+		Repository: params.Get("repository"),
+		Limit:      defaultPageSize,
+	}
+
+	format := strings.ToLower(params.Get("format"))
+	if format == "" {
+		format = FormatNDJSON
+	}
+	if format != FormatNDJSON && format != FormatCSV {
+		return "", query, fmt.Errorf("format must be %s or %s", FormatNDJSON, FormatCSV)
+	}
+
+	err := parseFilters(params, &query, maxPageSize)
+	return format, query, err
+}
+
+// parseFilters reads the filters, page size and cursor shared by exports and
+// searches: repository, priority and category (comma-separated), since and
+// until, q, the reported environment, limit and cursor
+func parseFilters(params url.Values, query *store.Query, maxLimit int) error {
+	query.Priorities = splitList(params.Get("priority"))
+	query.Categories = splitList(params.Get("category"))
+	query.Text = strings.TrimSpace(params.Get("q"))
+	query.Environment = github.Environment{
+		OS:        strings.TrimSpace(params.Get("os")),
+		Browser:   strings.TrimSpace(params.Get("browser")),
+		Version:   strings.TrimSpace(params.Get("version")),
+		GoVersion: strings.TrimSpace(params.Get("go_version")),
+	}
+
+	var err error
+	if query.Since, err = parseTime(params.Get("since"), false); err != nil {
+		return fmt.Errorf("invalid since: %w", err)
+	}
+	if query.Until, err = parseTime(params.Get("until"), true); err != nil {
+		return fmt.Errorf("invalid until: %w", err)
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			return fmt.Errorf("limit must be between 1 and %d", maxLimit)
+		}
+		query.Limit = limit
+	}
+
+	if value := params.Get("cursor"); value != "" {
+		after, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("invalid cursor")
+		}
+		query.After = string(after)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated parameter, dropping empty values
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseTime accepts RFC 3339 timestamps or dates. A date used as the end of a
+// range covers the whole day.
+func parseTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 or YYYY-MM-DD")
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func writeNDJSON(w http.ResponseWriter, records []store.IssueRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(NewRow(record)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w http.ResponseWriter, records []store.IssueRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := NewRow(record)
+		if err := writer.Write([]string{
+			strconv.Itoa(row.SchemaVersion),
+			row.Repository,
+			strconv.Itoa(row.Number),
+			row.Title,
+			row.State,
+			row.Priority,
+			row.Category,
+			row.Summary,
+			strconv.FormatFloat(row.Confidence, 'f', -1, 64),
+			strings.Join(row.Components, ";"),
+			row.Language,
+			row.SkipReason,
+			row.Channel,
+			row.MessageTS,
+			row.UpdatedAt.Format(time.RFC3339),
+			row.OS,
+			row.Browser,
+			row.Version,
+			row.GoVersion,
+			strconv.Itoa(row.SummarySchemaVersion),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,148 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	issues := store.NewMemoryStore()
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, priority := range []string{"high", "low", "high"} {
+		issues.SaveIssue(&store.IssueRecord{
+			Repository: "owner/repo",
+			Number:     i + 1,
+			Title:      "Crash, on start",
+			State:      "open",
+			Summary:    &ai.IssueSummary{Priority: priority, Category: "bug", Summary: "It crashes", Components: []string{"ai", "slack"}},
+			UpdatedAt:  day.AddDate(0, 0, i),
+		})
+	}
+	record, _ := issues.GetIssue("owner/repo", 3)
+	record.Environment = github.Environment{OS: "Windows 11", Version: "2.3.1"}
+	issues.SaveIssue(record)
+	return NewHandler(issues, admin.NewToken("admin-token"), "signing-key", "https://bot.example.com", zap.NewNop())
+}
+
+func get(handler http.HandlerFunc, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestServeExportNDJSONWithPagination(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := get(h.ServeExport, "/api/export?priority=high&limit=1", "admin-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var row Row
+	if err := json.Unmarshal(rec.Body.Bytes(), &row); err != nil {
+		t.Fatalf("Invalid NDJSON row: %v", err)
+	}
+	if row.SchemaVersion != SchemaVersion || row.Number != 1 || row.Priority != "high" || len(row.Components) != 2 {
+		t.Errorf("Unexpected row %+v", row)
+	}
+	cursor := rec.Header().Get("X-Next-Cursor")
+	if cursor == "" || !strings.Contains(rec.Header().Get("Link"), "cursor="+cursor) {
+		t.Fatalf("Expected a next page, got headers %v", rec.Header())
+	}
+
+	rec = get(h.ServeExport, "/api/export?priority=high&limit=1&cursor="+cursor, "admin-token")
+	if err := json.Unmarshal(rec.Body.Bytes(), &row); err != nil || row.Number != 3 {
+		t.Errorf("Expected issue 3 on the second page, got %+v (%v)", row, err)
+	}
+	if rec.Header().Get("X-Next-Cursor") != "" {
+		t.Error("Expected the second page to be the last")
+	}
+}
+
+func TestServeExportCSV(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := get(h.ServeExport, "/api/export?format=csv&since=2024-05-02&until=2024-05-02", "admin-token")
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(rows) != 2 || strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		t.Fatalf("Expected a header and one row, got %v", rows)
+	}
+	if rows[1][2] != "2" || rows[1][3] != "Crash, on start" || rows[1][9] != "ai;slack" {
+		t.Errorf("Unexpected CSV row %v", rows[1])
+	}
+}
+
+func TestServeExportRejectsBadRequests(t *testing.T) {
+	h := newTestHandler(t)
+
+	if rec := get(h.ServeExport, "/api/export", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+	if rec := get(h.ServeExport, "/api/export", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with the wrong token, got %d", rec.Code)
+	}
+	for _, query := range []string{"format=xml", "since=yesterday", "limit=0", "cursor=***"} {
+		if rec := get(h.ServeExport, "/api/export?"+query, "admin-token"); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestSignedExportURL(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := get(h.ServeSign, "/api/export/sign?format=csv&priority=high&ttl=10m", "admin-token")
+	var signed struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &signed); err != nil || !strings.HasPrefix(signed.URL, "https://bot.example.com/api/export?") {
+		t.Fatalf("Unexpected sign response %d: %s", rec.Code, rec.Body.String())
+	}
+	target := strings.TrimPrefix(signed.URL, "https://bot.example.com")
+
+	// The signed URL works without a token
+	if rec := get(h.ServeExport, target, ""); rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "schema_version,") {
+		t.Errorf("Expected the signed URL to export CSV, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Tampering with the filters invalidates it
+	if rec := get(h.ServeExport, strings.Replace(target, "priority=high", "priority=low", 1), ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a tampered URL, got %d", rec.Code)
+	}
+
+	// So does expiry
+	parsed, _ := url.Parse(target)
+	if h.validSignature(parsed.Path, parsed.Query(), time.Now().Add(11*time.Minute)) {
+		t.Error("Expected the signature to expire")
+	}
+
+	if rec := get(h.ServeSign, "/api/export/sign", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected signing to require the admin token, got %d", rec.Code)
+	}
+
+	// Rotating the key invalidates URLs signed with the old one
+	h.SetSigningKey("rotated-key")
+	if rec := get(h.ServeExport, target, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a URL signed with the old key, got %d", rec.Code)
+	}
+}
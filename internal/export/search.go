@@ -0,0 +1,53 @@
+package export
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/store"
+)
+
+// Search limits
+const (
+	defaultSearchPageSize = 50
+	maxSearchPageSize     = 500
+)
+
+// SearchResult is one page of matching summaries
+type SearchResult struct {
+	Issues     []Row  `json:"issues"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ServeSearch returns a page of summaries as JSON, e.g. every high-priority
+// issue mentioning auth this month. It takes the export's filters, where q
+// matches words in the issue title and summary, with limit defaulting to 50.
+func (h *Handler) ServeSearch(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+
+	params := r.URL.Query()
+	query := store.Query{Repository: params.Get("repository"), Limit: defaultSearchPageSize}
+	if query.Repository == "" {
+		query.Repository = params.Get("repo")
+	}
+	if err := parseFilters(params, &query, maxSearchPageSize); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, next := h.issues.ListIssues(query)
+	result := SearchResult{Issues: make([]Row, 0, len(records))}
+	for _, record := range records {
+		result.Issues = append(result.Issues, NewRow(record))
+	}
+	if next != "" {
+		result.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(next))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
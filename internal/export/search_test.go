@@ -0,0 +1,46 @@
+package export
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestServeSearch(t *testing.T) {
+	h := newTestHandler(t)
+
+	if rec := get(h.ServeSearch, "/api/issues", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without the admin token, got %d", rec.Code)
+	}
+
+	rec := get(h.ServeSearch, "/api/issues?repo=owner/repo&priority=high&category=bug&q=CRASH&since=2024-05-01&limit=1", "admin-token")
+	var result SearchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Number != 1 || result.NextCursor == "" {
+		t.Fatalf("Expected issue 1 and a next page, got %+v", result)
+	}
+
+	rec = get(h.ServeSearch, "/api/issues?priority=high&q=crash&cursor="+result.NextCursor, "admin-token")
+	result = SearchResult{}
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if len(result.Issues) != 1 || result.Issues[0].Number != 3 || result.NextCursor != "" {
+		t.Errorf("Expected only issue 3 on the last page, got %+v", result)
+	}
+
+	rec = get(h.ServeSearch, "/api/issues?q=crash&os=windows&version=v2.3", "admin-token")
+	result = SearchResult{}
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if len(result.Issues) != 1 || result.Issues[0].Number != 3 || result.Issues[0].OS != "Windows 11" {
+		t.Errorf("Expected only issue 3 reported on Windows with 2.3, got %+v", result)
+	}
+
+	rec = get(h.ServeSearch, "/api/issues?q=timeout", "admin-token")
+	if rec.Body.String() != "{\"issues\":[]}\n" {
+		t.Errorf("Expected an empty list, got %s", rec.Body.String())
+	}
+	if rec := get(h.ServeSearch, "/api/issues?limit=1000", "admin-token"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a limit above 500, got %d", rec.Code)
+	}
+}
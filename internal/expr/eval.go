@@ -0,0 +1,402 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// activation holds the variables in scope, with macro variables chained on
+// top of the caller's
+type activation struct {
+	variables map[string]interface{}
+	parent    *activation
+}
+
+func (a *activation) lookup(name string) (interface{}, bool) {
+	for ; a != nil; a = a.parent {
+		if value, ok := a.variables[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func eval(n node, vars *activation) (interface{}, error) {
+	switch n := n.(type) {
+	case literalNode:
+		return n.value, nil
+	case identNode:
+		value, ok := vars.lookup(n.name)
+		if !ok {
+			return nil, fmt.Errorf("variable %q is not set", n.name)
+		}
+		return normalize(value), nil
+	case listNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			value, err := eval(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case selectNode:
+		operand, err := eval(n.operand, vars)
+		if err != nil {
+			return nil, err
+		}
+		fields, ok := operand.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s has no field %q", typeName(operand), n.field)
+		}
+		value, ok := fields[n.field]
+		if !ok {
+			return nil, fmt.Errorf("no such field %q", n.field)
+		}
+		return normalize(value), nil
+	case indexNode:
+		return evalIndex(n, vars)
+	case unaryNode:
+		operand, err := eval(n.operand, vars)
+		if err != nil {
+			return nil, err
+		}
+		switch value := operand.(type) {
+		case bool:
+			if n.op == "!" {
+				return !value, nil
+			}
+		case int64:
+			if n.op == "-" {
+				return -value, nil
+			}
+		case float64:
+			if n.op == "-" {
+				return -value, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot apply %s to %s", n.op, typeName(operand))
+	case binaryNode:
+		if n.op == "&&" || n.op == "||" {
+			return evalLogic(n, vars)
+		}
+		left, err := eval(n.left, vars)
+		if err != nil {
+			return nil, err
+		}
+		right, err := eval(n.right, vars)
+		if err != nil {
+			return nil, err
+		}
+		return compare(n.op, left, right)
+	case callNode:
+		return evalCall(n, vars)
+	case macroNode:
+		return evalMacro(n, vars)
+	}
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+// evalLogic evaluates && and || like CEL: an error on one side is ignored
+// when the other side decides the result
+func evalLogic(n binaryNode, vars *activation) (interface{}, error) {
+	decisive := n.op == "||" // true decides ||, false decides &&
+	left, leftErr := evalBool(n.left, vars)
+	if leftErr == nil && left == decisive {
+		return decisive, nil
+	}
+	right, rightErr := evalBool(n.right, vars)
+	if rightErr == nil && right == decisive {
+		return decisive, nil
+	}
+	if leftErr != nil {
+		return nil, leftErr
+	}
+	if rightErr != nil {
+		return nil, rightErr
+	}
+	return !decisive, nil
+}
+
+func evalBool(n node, vars *activation) (bool, error) {
+	value, err := eval(n, vars)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a condition, got %s", typeName(value))
+	}
+	return result, nil
+}
+
+func evalIndex(n indexNode, vars *activation) (interface{}, error) {
+	operand, err := eval(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	index, err := eval(n.index, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch operand := operand.(type) {
+	case []interface{}:
+		i, ok := index.(int64)
+		if !ok {
+			return nil, fmt.Errorf("list index must be an integer, got %s", typeName(index))
+		}
+		if i < 0 || i >= int64(len(operand)) {
+			return nil, fmt.Errorf("index %d out of range of a list of %d", i, len(operand))
+		}
+		return normalize(operand[i]), nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be a string, got %s", typeName(index))
+		}
+		value, ok := operand[key]
+		if !ok {
+			return nil, fmt.Errorf("no such key %q", key)
+		}
+		return normalize(value), nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(operand))
+}
+
+func evalCall(n callNode, vars *activation) (interface{}, error) {
+	argNodes := n.args
+	if n.target != nil {
+		argNodes = append([]node{n.target}, n.args...)
+	}
+	args := make([]interface{}, 0, len(argNodes))
+	for _, arg := range argNodes {
+		value, err := eval(arg, vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	if n.function == "size" {
+		switch value := args[0].(type) {
+		case string:
+			return int64(utf8.RuneCountInString(value)), nil
+		case []interface{}:
+			return int64(len(value)), nil
+		case map[string]interface{}:
+			return int64(len(value)), nil
+		}
+		return nil, fmt.Errorf("size of %s is undefined", typeName(args[0]))
+	}
+
+	target, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s needs a string, got %s", n.function, typeName(args[0]))
+	}
+	switch n.function {
+	case "lowerAscii":
+		return strings.ToLower(target), nil
+	case "upperAscii":
+		return strings.ToUpper(target), nil
+	}
+	arg, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s needs a string argument, got %s", n.function, typeName(args[1]))
+	}
+	switch n.function {
+	case "startsWith":
+		return strings.HasPrefix(target, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(target, arg), nil
+	case "contains":
+		return strings.Contains(target, arg), nil
+	case "matches":
+		pattern := n.pattern
+		if pattern == nil {
+			var err error
+			if pattern, err = regexp.Compile(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+		}
+		return pattern.MatchString(target), nil
+	}
+	return nil, fmt.Errorf("unknown function %q", n.function)
+}
+
+func evalMacro(n macroNode, vars *activation) (interface{}, error) {
+	target, err := eval(n.target, vars)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := target.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s needs a list, got %s", n.function, typeName(target))
+	}
+	// Like && and ||, an error is ignored when another element decides
+	decisive := n.function == "exists"
+	var firstErr error
+	for _, item := range list {
+		result, err := evalBool(n.predicate, &activation{variables: map[string]interface{}{n.variable: item}, parent: vars})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if result == decisive {
+			return decisive, nil
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return !decisive, nil
+}
+
+// compare applies a comparison or in
+func compare(op string, left, right interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		switch container := right.(type) {
+		case []interface{}:
+			for _, item := range container {
+				if equal(left, normalize(item)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			key, ok := left.(string)
+			if !ok {
+				return false, nil
+			}
+			_, found := container[key]
+			return found, nil
+		}
+		return nil, fmt.Errorf("cannot test membership in %s", typeName(right))
+	}
+
+	var order int
+	if a, b, ok := numbers(left, right); ok {
+		switch {
+		case a < b:
+			order = -1
+		case a > b:
+			order = 1
+		}
+	} else if a, ok := left.(string); ok {
+		b, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string with %s", typeName(right))
+		}
+		order = strings.Compare(a, b)
+	} else {
+		return nil, fmt.Errorf("cannot compare %s with %s", typeName(left), typeName(right))
+	}
+	switch op {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	}
+	return order >= 0, nil
+}
+
+func equal(left, right interface{}) bool {
+	if a, b, ok := numbers(left, right); ok {
+		return a == b
+	}
+	if a, ok := left.([]interface{}); ok {
+		b, ok := right.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(normalize(a[i]), normalize(b[i])) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// numbers returns both values as floats if both are numbers
+func numbers(left, right interface{}) (float64, float64, bool) {
+	a, ok := number(left)
+	if !ok {
+		return 0, 0, false
+	}
+	b, ok := number(right)
+	return a, b, ok
+}
+
+func number(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// normalize converts the Go values callers pass into the types expressions
+// work with: int64, float64, string, bool, nil, []interface{} and
+// map[string]interface{}
+func normalize(value interface{}) interface{} {
+	switch value := value.(type) {
+	case int:
+		return int64(value)
+	case int32:
+		return int64(value)
+	case float32:
+		return float64(value)
+	case []string:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = item
+		}
+		return items
+	case map[string]string:
+		fields := make(map[string]interface{}, len(value))
+		for key, field := range value {
+			fields[key] = field
+		}
+		return fields
+	}
+	return value
+}
+
+// typeName names a value's type in error messages
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case bool:
+		return "bool"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	case unknown:
+		return "value"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,229 @@
+// Package expr evaluates filter conditions written in a small subset of
+// CEL, e.g. issue.labels.exists(l, l == "security") &&
+// repo.startsWith("org/payments"), so rules can express conditions their
+// fixed criteria cannot.
+//
+// Expressions support string, integer, float, boolean, null and list
+// literals; field access and indexing; !, -, &&, ||, ==, !=, <, <=, >, >=
+// and in; size(); the string methods startsWith, endsWith, contains,
+// matches, lowerAscii and upperAscii; and the list macros exists and all.
+package expr
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Variables declares the variables an expression may use, by name, with an
+// example value of each. Fields of maps are declared the same way, and a list
+// is declared with an example element, e.g. []string{} or
+// []interface{}{map[string]interface{}{"login": ""}}. Expressions that use
+// an undeclared variable or field fail to compile.
+type Variables map[string]interface{}
+
+// Program is a compiled expression, safe for concurrent use
+type Program struct {
+	source string
+	root   node
+}
+
+// String returns the expression's source
+func (p *Program) String() string {
+	return p.source
+}
+
+// Compiler compiles expressions over a set of variables. Programs are cached
+// by source, so rules that share a condition, or are built again after a
+// reload, compile it once.
+type Compiler struct {
+	variables Variables
+	cache     sync.Map // Source to *Program
+}
+
+// NewCompiler creates a compiler for expressions over variables
+func NewCompiler(variables Variables) *Compiler {
+	return &Compiler{variables: variables}
+}
+
+// Compile parses an expression and checks the variables and fields it uses
+func (c *Compiler) Compile(source string) (*Program, error) {
+	if cached, ok := c.cache.Load(source); ok {
+		return cached.(*Program), nil
+	}
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+	scope := make(map[string]interface{}, len(c.variables))
+	for name, example := range c.variables {
+		scope[name] = shapeOf(example)
+	}
+	if _, err := check(root, scope); err != nil {
+		return nil, err
+	}
+	program := &Program{source: source, root: root}
+	c.cache.Store(source, program)
+	return program, nil
+}
+
+// Match evaluates the program with the given variables, which must all be
+// set, and reports whether it is true. Expressions that are not boolean are
+// an error.
+func (p *Program) Match(variables map[string]interface{}) (bool, error) {
+	value, err := p.Eval(variables)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%q is not a condition: it evaluates to %s", p.source, typeName(value))
+	}
+	return result, nil
+}
+
+// Eval evaluates the program with the given variables
+func (p *Program) Eval(variables map[string]interface{}) (interface{}, error) {
+	return eval(p.root, &activation{variables: variables})
+}
+
+// unknown is the shape of values whose fields are not declared, e.g. map
+// elements, which are not checked
+type unknown struct{}
+
+// check verifies that an expression only uses the declared variables and
+// fields, and returns the shape of its value
+func check(n node, scope map[string]interface{}) (interface{}, error) {
+	switch n := n.(type) {
+	case literalNode:
+		return n.value, nil
+	case identNode:
+		shape, ok := scope[n.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %q", n.name)
+		}
+		return shape, nil
+	case listNode:
+		for _, item := range n.items {
+			if _, err := check(item, scope); err != nil {
+				return nil, err
+			}
+		}
+		return unknown{}, nil
+	case selectNode:
+		shape, err := check(n.operand, scope)
+		if err != nil {
+			return nil, err
+		}
+		switch shape := shape.(type) {
+		case unknown:
+			return unknown{}, nil
+		case map[string]interface{}:
+			field, ok := shape[n.field]
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q", n.field)
+			}
+			return shapeOf(field), nil
+		}
+		return nil, fmt.Errorf("%s has no field %q", typeName(shape), n.field)
+	case indexNode:
+		shape, err := check(n.operand, scope)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := check(n.index, scope); err != nil {
+			return nil, err
+		}
+		return elementShape(shape), nil
+	case unaryNode:
+		if _, err := check(n.operand, scope); err != nil {
+			return nil, err
+		}
+		if n.op == "!" {
+			return false, nil
+		}
+		return unknown{}, nil
+	case binaryNode:
+		if _, err := check(n.left, scope); err != nil {
+			return nil, err
+		}
+		if _, err := check(n.right, scope); err != nil {
+			return nil, err
+		}
+		return false, nil
+	case callNode:
+		return checkCall(n, scope)
+	case macroNode:
+		shape, err := check(n.target, scope)
+		if err != nil {
+			return nil, err
+		}
+		inner := make(map[string]interface{}, len(scope)+1)
+		for name, s := range scope {
+			inner[name] = s
+		}
+		inner[n.variable] = elementShape(shape)
+		if _, err := check(n.predicate, inner); err != nil {
+			return nil, err
+		}
+		return false, nil
+	}
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+// functions are the supported functions and methods by name, with the
+// number of arguments besides the target and an example result
+var functions = map[string]struct {
+	args   int
+	result interface{}
+}{
+	"size":       {0, int64(0)},
+	"startsWith": {1, false},
+	"endsWith":   {1, false},
+	"contains":   {1, false},
+	"matches":    {1, false},
+	"lowerAscii": {0, ""},
+	"upperAscii": {0, ""},
+}
+
+func checkCall(n callNode, scope map[string]interface{}) (interface{}, error) {
+	fn, ok := functions[n.function]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", n.function)
+	}
+	args := fn.args
+	if n.target == nil {
+		// Global calls take the target as their first argument, e.g. size(x)
+		if n.function != "size" {
+			return nil, fmt.Errorf("%s must be called as a method", n.function)
+		}
+		args++
+	} else if _, err := check(n.target, scope); err != nil {
+		return nil, err
+	}
+	if len(n.args) != args {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", n.function, args, len(n.args))
+	}
+	for _, arg := range n.args {
+		if _, err := check(arg, scope); err != nil {
+			return nil, err
+		}
+	}
+	return fn.result, nil
+}
+
+// elementShape returns the shape of a list's elements
+func elementShape(shape interface{}) interface{} {
+	if list, ok := shape.([]interface{}); ok && len(list) > 0 {
+		return shapeOf(list[0])
+	}
+	return unknown{}
+}
+
+// shapeOf returns the shape of a declared example value, in which a list of
+// strings has a string element
+func shapeOf(example interface{}) interface{} {
+	if _, ok := example.([]string); ok {
+		return []interface{}{""}
+	}
+	return normalize(example)
+}
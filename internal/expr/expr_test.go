@@ -0,0 +1,130 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+var testVariables = Variables{
+	"repo": "",
+	"issue": map[string]interface{}{
+		"number":    0,
+		"title":     "",
+		"labels":    []string{},
+		"assignees": []interface{}{map[string]interface{}{"login": ""}},
+	},
+}
+
+func testIssue() map[string]interface{} {
+	return map[string]interface{}{
+		"repo": "org/payments-api",
+		"issue": map[string]interface{}{
+			"number":    42,
+			"title":     "Refund fails with a 500",
+			"labels":    []string{"bug", "security"},
+			"assignees": []interface{}{map[string]interface{}{"login": "octocat"}},
+		},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	compiler := NewCompiler(testVariables)
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{`issue.labels.exists(l, l == "security") && repo.startsWith("org/payments")`, true},
+		{`issue.labels.exists(l, l == "security") && repo.startsWith("org/billing")`, false},
+		{`"bug" in issue.labels || false`, true},
+		{`!("wontfix" in issue.labels)`, true},
+		{`issue.labels.all(l, l.size() > 2)`, true},
+		{`size(issue.labels) == 2 && issue.labels[0] == "bug"`, true},
+		{`issue.number >= 40 && issue.number < 50.5`, true},
+		{`issue.title.lowerAscii().contains("refund")`, true},
+		{`issue.title.matches("^Refund .* [0-9]{3}$")`, true},
+		{`repo.endsWith("-api") && repo in ["org/payments-api", "org/other"]`, true},
+		{`issue.assignees.exists(a, a.login == "octocat")`, true},
+		{`issue.number != -42`, true},
+		{`'single' == "single"`, true},
+		// The decided side of && and || hides an error on the other
+		{`false && issue.labels[5] == "x"`, false},
+		{`issue.labels[5] == "x" || true`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := compiler.Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+			got, err := program.Match(testIssue())
+			if err != nil {
+				t.Fatalf("Match failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	compiler := NewCompiler(testVariables)
+	tests := map[string]string{
+		`issue.lables.exists(l, l == "bug")`:       `unknown field "lables"`,
+		`repository == "org/api"`:                  `unknown variable "repository"`,
+		`issue.assignees.exists(a, a.name == "x")`: `unknown field "name"`,
+		`repo.title == "x"`:                        `string has no field "title"`,
+		`repo.startsWith()`:                        "startsWith takes 1 arguments",
+		`repo.reverse()`:                           `unknown function "reverse"`,
+		`repo.matches("(")`:                        "invalid pattern",
+		`repo == "unterminated`:                    "unterminated string",
+		`repo == `:                                 "unexpected end of expression",
+		`(repo == "x"`:                             `expected ")"`,
+		`repo == "x" repo`:                         `unexpected "repo"`,
+		`repo # 1`:                                 "unexpected '#'",
+	}
+	for source, want := range tests {
+		t.Run(source, func(t *testing.T) {
+			_, err := compiler.Compile(source)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected an error containing %q, got %v", want, err)
+			}
+		})
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	compiler := NewCompiler(testVariables)
+	tests := map[string]string{
+		`repo`:                         "is not a condition",
+		`issue.labels[5] == "x"`:       "out of range",
+		`repo < 3`:                     "cannot compare string with int",
+		`issue.number.startsWith("4")`: "startsWith needs a string",
+	}
+	for source, want := range tests {
+		t.Run(source, func(t *testing.T) {
+			program, err := compiler.Compile(source)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+			if _, err := program.Match(testIssue()); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected an error containing %q, got %v", want, err)
+			}
+		})
+	}
+}
+
+func TestCompileCaches(t *testing.T) {
+	compiler := NewCompiler(testVariables)
+	first, err := compiler.Compile(`repo == "org/api"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := compiler.Compile(`repo == "org/api"`)
+	if first != second {
+		t.Error("Expected the compiled program to be reused")
+	}
+	if first.String() != `repo == "org/api"` {
+		t.Errorf("Expected the source, got %q", first.String())
+	}
+}
@@ -0,0 +1,383 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind is the kind of a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenInt
+	tokenFloat
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string // Operator or identifier, or the unquoted string
+	pos   int
+	value interface{} // Parsed literal
+}
+
+// operators are the operators and punctuation, longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ",", ".", "-"}
+
+// lex splits source into tokens
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			text, end, err := lexString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i, value: text})
+			i = end
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(source) && (source[end] >= '0' && source[end] <= '9' || source[end] == '.') {
+				end++
+			}
+			text := source[i:end]
+			if strings.Contains(text, ".") {
+				value, err := strconv.ParseFloat(text, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid number %q at %d", text, i)
+				}
+				tokens = append(tokens, token{kind: tokenFloat, text: text, pos: i, value: value})
+			} else {
+				value, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid number %q at %d", text, i)
+				}
+				tokens = append(tokens, token{kind: tokenInt, text: text, pos: i, value: value})
+			}
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i
+			for end < len(source) && (source[end] == '_' || unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[i:end], pos: i})
+			i = end
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// lexString reads the quoted string starting at start and returns it
+// unquoted with the position after its closing quote
+func lexString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var b strings.Builder
+	for i := start + 1; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(source):
+			i++
+			switch escaped := source[i]; escaped {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(escaped)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at %d", start)
+}
+
+// node is a parsed expression
+type node interface{}
+
+type (
+	literalNode struct{ value interface{} }
+	identNode   struct{ name string }
+	listNode    struct{ items []node }
+	selectNode  struct {
+		operand node
+		field   string
+	}
+	indexNode struct{ operand, index node }
+	unaryNode struct {
+		op      string
+		operand node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	// callNode calls a function, or a method when target is set
+	callNode struct {
+		target   node
+		function string
+		args     []node
+		pattern  *regexp.Regexp // Compiled pattern of matches with a literal
+	}
+	// macroNode evaluates a predicate for each element of a list, with the
+	// element bound to variable
+	macroNode struct {
+		target    node
+		function  string // exists or all
+		variable  string
+		predicate node
+	}
+)
+
+// parser is a recursive descent parser over the tokens of an expression.
+// Precedence, lowest first: ||, &&, comparisons and in, unary ! and -,
+// member access.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", next.text, next.pos)
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the operator op
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOperator && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		if t.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of expression", op)
+		}
+		return fmt.Errorf("expected %q at %d, got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	for err == nil && p.accept("||") {
+		var right node
+		right, err = p.and()
+		left = binaryNode{op: "||", left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.relation()
+	for err == nil && p.accept("&&") {
+		var right node
+		right, err = p.relation()
+		left = binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *parser) relation() (node, error) {
+	left, err := p.unary()
+	for err == nil {
+		t := p.peek()
+		isOp := t.kind == tokenOperator && (t.text == "==" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">=")
+		if !isOp && !(t.kind == tokenIdent && t.text == "in") {
+			break
+		}
+		p.next()
+		var right node
+		right, err = p.unary()
+		left = binaryNode{op: t.text, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			operand, err := p.unary()
+			return unaryNode{op: op, operand: operand}, err
+		}
+	}
+	return p.member()
+}
+
+func (p *parser) member() (node, error) {
+	operand, err := p.primary()
+	for err == nil {
+		switch {
+		case p.accept("."):
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field or method name at %d", name.pos)
+			}
+			if !p.accept("(") {
+				operand = selectNode{operand: operand, field: name.text}
+				continue
+			}
+			operand, err = p.call(operand, name.text)
+		case p.accept("["):
+			var index node
+			if index, err = p.or(); err == nil {
+				err = p.expect("]")
+			}
+			operand = indexNode{operand: operand, index: index}
+		default:
+			return operand, nil
+		}
+	}
+	return nil, err
+}
+
+// call parses the arguments of a function or method call whose opening
+// parenthesis has been read
+func (p *parser) call(target node, function string) (node, error) {
+	if target != nil && (function == "exists" || function == "all") {
+		variable := p.next()
+		if variable.kind != tokenIdent {
+			return nil, fmt.Errorf("%s needs a variable name at %d", function, variable.pos)
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		predicate, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return macroNode{target: target, function: function, variable: variable.text, predicate: predicate}, p.expect(")")
+	}
+
+	var args []node
+	if !p.accept(")") {
+		for {
+			arg, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+	call := callNode{target: target, function: function, args: args}
+	if function == "matches" && len(args) == 1 {
+		if literal, ok := args[0].(literalNode); ok {
+			pattern, isString := literal.value.(string)
+			if !isString {
+				return nil, fmt.Errorf("matches needs a string pattern")
+			}
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			call.pattern = compiled
+		}
+	}
+	return call, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString, tokenInt, tokenFloat:
+		return literalNode{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return literalNode{value: t.text == "true"}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.call(nil, t.text)
+		}
+		return identNode{name: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			inner, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			var items []node
+			if p.accept("]") {
+				return listNode{}, nil
+			}
+			for {
+				item, err := p.or()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if p.accept("]") {
+					return listNode{items: items}, nil
+				}
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
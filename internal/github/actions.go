@@ -0,0 +1,230 @@
+package github
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/expr"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// Behavior is what the pipeline does for an event action
+type Behavior string
+
+// Pipeline behaviors an event action can map to
+const (
+	BehaviorSummarize   Behavior = "summarize"   // Summarize the issue and post a new Slack message
+	BehaviorResummarize Behavior = "resummarize" // Re-summarize only if the issue changed materially, updating the posted message
+	BehaviorUpdate      Behavior = "update"      // Refresh the posted Slack message without calling the AI
+	BehaviorEscalate    Behavior = "escalate"    // Raise the last summary to the highest priority and route the issue again, without calling the AI
+	BehaviorIgnore      Behavior = "ignore"      // Skip the event
+)
+
+// defaultActions is the event type × action matrix used when no rule matches.
+// Actions that are not listed are ignored.
+var defaultActions = map[string]map[string]Behavior{
+	"issues": {
+		"opened":   BehaviorSummarize,
+		"reopened": BehaviorSummarize,
+		"edited":   BehaviorResummarize,
+		"closed":   BehaviorUpdate,
+
+		// Labels, assignees and milestones show in the posted message
+		"labeled":      BehaviorUpdate,
+		"unlabeled":    BehaviorUpdate,
+		"assigned":     BehaviorUpdate,
+		"unassigned":   BehaviorUpdate,
+		"milestoned":   BehaviorUpdate,
+		"demilestoned": BehaviorUpdate,
+	},
+	"issue_comment": {
+		"created": BehaviorSummarize,
+	},
+}
+
+// defaultRules apply after the configured rules and before defaultActions.
+// Adding a security label escalates the issue.
+var defaultRules = []ActionRule{
+	{Event: "issues", Actions: []string{"labeled"}, Labels: []string{"security"}, Behavior: BehaviorEscalate},
+}
+
+// ActionRule overrides the behavior of matching events. Empty criteria match
+// everything. Rules are evaluated in order and the first match wins.
+type ActionRule struct {
+	Repositories []string `mapstructure:"repositories" json:"repositories"` // Glob patterns, e.g. "my-org/*"
+	Event        string   `mapstructure:"event" json:"event"`               // issues or issue_comment
+	Actions      []string `mapstructure:"actions" json:"actions"`
+	Labels       []string `mapstructure:"labels" json:"labels,omitempty"` // Label added or removed by labeled and unlabeled actions
+	Behavior     Behavior `mapstructure:"behavior" json:"behavior"`
+
+	// When is a condition the event must also meet, in the expression
+	// language of package expr, e.g. issue.labels.exists(l, l == "security")
+	// && repo.startsWith("org/payments")
+	When string `mapstructure:"when" json:"when,omitempty"`
+}
+
+// actionConditions compiles the When conditions of action rules
+var actionConditions = expr.NewCompiler(expr.Variables{
+	"repo":   "",
+	"event":  "",
+	"action": "",
+	"label":  "",
+	"sender": "",
+	"issue": map[string]interface{}{
+		"number":       0,
+		"title":        "",
+		"body":         "",
+		"state":        "",
+		"author":       "",
+		"labels":       []string{},
+		"assignees":    []string{},
+		"comments":     0,
+		"pull_request": false,
+	},
+})
+
+// ActionEvent is an event action rules are matched against
+type ActionEvent struct {
+	Repository string
+	Type       string // issues or issue_comment
+	Action     string
+	Label      string        // Label added or removed by labeled and unlabeled actions
+	Sender     string        // GitHub login of who triggered the event
+	Issue      *github.Issue // Nil when there is no issue, e.g. on the dashboard
+}
+
+// variables returns the event as the variables of a When condition
+func (e ActionEvent) variables() map[string]interface{} {
+	issue := e.Issue
+	if issue == nil {
+		issue = &github.Issue{}
+	}
+	labels := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	assignees := make([]string, 0, len(issue.Assignees))
+	for _, assignee := range issue.Assignees {
+		assignees = append(assignees, assignee.GetLogin())
+	}
+	return map[string]interface{}{
+		"repo":   e.Repository,
+		"event":  e.Type,
+		"action": e.Action,
+		"label":  e.Label,
+		"sender": e.Sender,
+		"issue": map[string]interface{}{
+			"number":       issue.GetNumber(),
+			"title":        issue.GetTitle(),
+			"body":         issue.GetBody(),
+			"state":        issue.GetState(),
+			"author":       issue.GetUser().GetLogin(),
+			"labels":       labels,
+			"assignees":    assignees,
+			"comments":     issue.GetComments(),
+			"pull_request": issue.IsPullRequest(),
+		},
+	}
+}
+
+// ActionMatrix decides the pipeline behavior for each repository, event type and action
+type ActionMatrix struct {
+	rules []ActionRule
+	when  []*expr.Program // Compiled When condition of each rule, nil for none
+}
+
+// NewActionMatrix validates the rules and creates a matrix on top of the defaults
+func NewActionMatrix(rules []ActionRule) (*ActionMatrix, error) {
+	when := make([]*expr.Program, len(rules))
+	for i, rule := range rules {
+		switch rule.Behavior {
+		case BehaviorSummarize, BehaviorResummarize, BehaviorUpdate, BehaviorEscalate, BehaviorIgnore:
+		default:
+			return nil, fmt.Errorf("action rule %d: invalid behavior %q", i, rule.Behavior)
+		}
+		if _, ok := defaultActions[rule.Event]; rule.Event != "" && !ok {
+			return nil, fmt.Errorf("action rule %d: unsupported event %q", i, rule.Event)
+		}
+		for _, pattern := range rule.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("action rule %d: invalid repository pattern %q: %w", i, pattern, err)
+			}
+		}
+		if rule.When != "" {
+			program, err := actionConditions.Compile(rule.When)
+			if err != nil {
+				return nil, fmt.Errorf("action rule %d: invalid condition: %w", i, err)
+			}
+			when[i] = program
+		}
+	}
+	return &ActionMatrix{rules: rules, when: when}, nil
+}
+
+// Behavior returns what to do for an action. A nil matrix uses the defaults.
+func (m *ActionMatrix) Behavior(repository, eventType, action string) Behavior {
+	return m.LabelBehavior(repository, eventType, action, "")
+}
+
+// LabelBehavior returns what to do for an action that added or removed label.
+// Rules with labels only match such actions.
+func (m *ActionMatrix) LabelBehavior(repository, eventType, action, label string) Behavior {
+	return m.EventBehavior(ActionEvent{Repository: repository, Type: eventType, Action: action, Label: label})
+}
+
+// EventBehavior returns what to do for an event, with rule conditions
+// evaluated against its issue and sender
+func (m *ActionMatrix) EventBehavior(event ActionEvent) Behavior {
+	var rules []ActionRule
+	var when []*expr.Program
+	if m != nil {
+		rules, when = m.rules, m.when
+	}
+	var variables map[string]interface{}
+	for i, rule := range append(rules[:len(rules):len(rules)], defaultRules...) {
+		if !rule.matches(event.Repository, event.Type, event.Action, event.Label) {
+			continue
+		}
+		if i < len(when) && when[i] != nil {
+			if variables == nil {
+				variables = event.variables()
+			}
+			// Conditions that fail to evaluate, e.g. on an index out of
+			// range, do not match
+			if ok, err := when[i].Match(variables); err != nil || !ok {
+				continue
+			}
+		}
+		return rule.Behavior
+	}
+
+	if behavior, ok := defaultActions[event.Type][event.Action]; ok {
+		return behavior
+	}
+	return BehaviorIgnore
+}
+
+func (rule ActionRule) matches(repository, eventType, action, label string) bool {
+	if rule.Event != "" && rule.Event != eventType {
+		return false
+	}
+	if len(rule.Labels) > 0 && !utils.ContainsFold(rule.Labels, label) {
+		return false
+	}
+	if len(rule.Actions) > 0 && !utils.ContainsFold(rule.Actions, action) {
+		return false
+	}
+	if len(rule.Repositories) > 0 {
+		repository = strings.ToLower(repository)
+		for _, pattern := range rule.Repositories {
+			if ok, _ := path.Match(strings.ToLower(pattern), repository); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
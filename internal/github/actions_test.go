@@ -0,0 +1,78 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionMatrixRules(t *testing.T) {
+	matrix, err := NewActionMatrix([]ActionRule{
+		{Repositories: []string{"my-org/docs"}, Event: "issues", Actions: []string{"edited"}, Behavior: BehaviorIgnore},
+		{Repositories: []string{"My-Org/*"}, Event: "issues", Actions: []string{"labeled"}, Behavior: BehaviorUpdate},
+		{Event: "issue_comment", Actions: []string{"edited"}, Behavior: BehaviorResummarize},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, BehaviorIgnore, matrix.Behavior("my-org/docs", "issues", "edited"))
+	assert.Equal(t, BehaviorResummarize, matrix.Behavior("my-org/api", "issues", "edited"))
+	assert.Equal(t, BehaviorUpdate, matrix.Behavior("my-org/api", "issues", "labeled"))
+	assert.Equal(t, BehaviorUpdate, matrix.Behavior("other/api", "issues", "assigned"))
+	assert.Equal(t, BehaviorIgnore, matrix.Behavior("other/api", "issues", "transferred"))
+	assert.Equal(t, BehaviorResummarize, matrix.Behavior("other/api", "issue_comment", "edited"))
+	assert.Equal(t, BehaviorSummarize, matrix.Behavior("other/api", "issues", "opened"))
+}
+
+func TestActionMatrixLabels(t *testing.T) {
+	matrix, err := NewActionMatrix([]ActionRule{
+		{Repositories: []string{"my-org/sandbox"}, Labels: []string{"security"}, Behavior: BehaviorUpdate},
+		{Event: "issues", Actions: []string{"labeled"}, Labels: []string{"P0", "outage"}, Behavior: BehaviorEscalate},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, BehaviorEscalate, matrix.LabelBehavior("my-org/api", "issues", "labeled", "Security"))
+	assert.Equal(t, BehaviorUpdate, matrix.LabelBehavior("my-org/sandbox", "issues", "labeled", "security"))
+	assert.Equal(t, BehaviorEscalate, matrix.LabelBehavior("my-org/api", "issues", "labeled", "p0"))
+	assert.Equal(t, BehaviorUpdate, matrix.LabelBehavior("my-org/api", "issues", "labeled", "docs"))
+	assert.Equal(t, BehaviorUpdate, matrix.LabelBehavior("my-org/api", "issues", "unlabeled", "security"))
+	// Rules with labels never match actions without one
+	assert.Equal(t, BehaviorUpdate, matrix.Behavior("my-org/api", "issues", "labeled"))
+	assert.Equal(t, BehaviorEscalate, (*ActionMatrix)(nil).LabelBehavior("my-org/api", "issues", "labeled", "security"))
+}
+
+func TestActionMatrixConditions(t *testing.T) {
+	matrix, err := NewActionMatrix([]ActionRule{
+		{Event: "issues", Actions: []string{"opened"}, When: `issue.labels.exists(l, l == "security") && repo.startsWith("org/payments")`, Behavior: BehaviorEscalate},
+		{Event: "issue_comment", When: `sender.endsWith("[bot]")`, Behavior: BehaviorIgnore},
+	})
+	require.NoError(t, err)
+
+	security := &github.Issue{Labels: []*github.Label{{Name: github.String("bug")}, {Name: github.String("security")}}}
+	opened := ActionEvent{Repository: "org/payments-api", Type: "issues", Action: "opened", Issue: security}
+	assert.Equal(t, BehaviorEscalate, matrix.EventBehavior(opened))
+	opened.Repository = "org/docs"
+	assert.Equal(t, BehaviorSummarize, matrix.EventBehavior(opened))
+	opened.Repository, opened.Issue = "org/payments-api", &github.Issue{}
+	assert.Equal(t, BehaviorSummarize, matrix.EventBehavior(opened))
+
+	comment := ActionEvent{Repository: "org/api", Type: "issue_comment", Action: "created", Sender: "dependabot[bot]"}
+	assert.Equal(t, BehaviorIgnore, matrix.EventBehavior(comment))
+	comment.Sender = "octocat"
+	assert.Equal(t, BehaviorSummarize, matrix.EventBehavior(comment))
+}
+
+func TestNewActionMatrixValidation(t *testing.T) {
+	_, err := NewActionMatrix([]ActionRule{{Behavior: "notify"}})
+	assert.Error(t, err)
+
+	_, err = NewActionMatrix([]ActionRule{{Event: "pull_request", Behavior: BehaviorSummarize}})
+	assert.Error(t, err)
+
+	_, err = NewActionMatrix([]ActionRule{{Repositories: []string{"["}, Behavior: BehaviorIgnore}})
+	assert.Error(t, err)
+
+	_, err = NewActionMatrix([]ActionRule{{When: `issue.lables.exists(l, l == "bug")`, Behavior: BehaviorIgnore}})
+	assert.ErrorContains(t, err, `unknown field "lables"`)
+}
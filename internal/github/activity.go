@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// IssueActivity is triage context computed from an issue and its comments:
+// how old it is, whether maintainers have engaged, who reported it and how
+// many people are affected
+type IssueActivity struct {
+	Age                 time.Duration // Since the issue was opened
+	MaintainerResponded bool          // Whether an owner, member or collaborator other than the reporter has commented
+	SinceMaintainer     time.Duration // Since the last maintainer comment; unset without one
+	ReporterAssociation string        // GitHub's author_association of the reporter, e.g. FIRST_TIME_CONTRIBUTOR
+	ThumbsUp            int           // 👍 reactions on the issue
+	Reactions           int           // All reactions on the issue
+}
+
+// maintainerAssociations are the author associations of people who triage
+// issues in the repository
+var maintainerAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+}
+
+// ComputeActivity derives the activity context of an issue at now from data
+// already fetched with the issue, so it costs no extra API calls
+func ComputeActivity(issue *github.Issue, comments []*github.IssueComment, now time.Time) *IssueActivity {
+	if issue == nil {
+		return nil
+	}
+
+	activity := &IssueActivity{
+		ReporterAssociation: issue.GetAuthorAssociation(),
+		ThumbsUp:            issue.GetReactions().GetPlusOne(),
+		Reactions:           issue.GetReactions().GetTotalCount(),
+	}
+	if created := issue.GetCreatedAt().Time; !created.IsZero() {
+		activity.Age = now.Sub(created)
+	}
+
+	reporter := issue.GetUser().GetLogin()
+	var lastResponse time.Time
+	for _, comment := range comments {
+		if !maintainerAssociations[comment.GetAuthorAssociation()] || comment.GetUser().GetLogin() == reporter {
+			continue
+		}
+		if created := comment.GetCreatedAt().Time; created.After(lastResponse) {
+			lastResponse = created
+		}
+	}
+	if !lastResponse.IsZero() {
+		activity.MaintainerResponded = true
+		activity.SinceMaintainer = now.Sub(lastResponse)
+	}
+	return activity
+}
+
+// FirstMaintainerResponse returns when an owner, member or collaborator
+// other than the reporter first commented on an issue, or zero if none has
+func FirstMaintainerResponse(issue *github.Issue, comments []*github.IssueComment) time.Time {
+	reporter := issue.GetUser().GetLogin()
+	var first time.Time
+	for _, comment := range comments {
+		if !maintainerAssociations[comment.GetAuthorAssociation()] || comment.GetUser().GetLogin() == reporter {
+			continue
+		}
+		if created := comment.GetCreatedAt().Time; !created.IsZero() && (first.IsZero() || created.Before(first)) {
+			first = created
+		}
+	}
+	return first
+}
+
+// IssueThumbsUp returns the number of 👍 reactions on an issue. It costs one
+// API call, so posted issues can be polled for reactions, which GitHub does
+// not send webhooks for.
+func (h *Handler) IssueThumbsUp(ctx context.Context, repo string, number int) (int, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	issue, _, err := h.githubClient().Issues.Get(ctx, parts[0], parts[1], number)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("fetch_reactions", apperrors.Classify(err))
+		return 0, fmt.Errorf("failed to fetch issue reactions: %w", err)
+	}
+	return issue.GetReactions().GetPlusOne(), nil
+}
+
+// FirstTimeReporter reports whether the reporter has never contributed to
+// the repository
+func (a *IssueActivity) FirstTimeReporter() bool {
+	switch a.ReporterAssociation {
+	case "FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER", "NONE":
+		return true
+	}
+	return false
+}
+
+// Reporter describes the reporter's history in the repository
+func (a *IssueActivity) Reporter() string {
+	switch a.ReporterAssociation {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return "maintainer"
+	case "CONTRIBUTOR":
+		return "previous contributor"
+	case "FIRST_TIME_CONTRIBUTOR":
+		return "first-time contributor"
+	case "FIRST_TIMER":
+		return "first-time contributor on GitHub"
+	case "NONE":
+		return "no previous contributions"
+	}
+	return "unknown"
+}
+
+// Lines renders the activity as short human-readable facts, used both in
+// the AI prompt and in Slack messages
+func (a *IssueActivity) Lines() []string {
+	lines := []string{fmt.Sprintf("Opened %s ago", FormatAge(a.Age))}
+	if a.MaintainerResponded {
+		lines = append(lines, fmt.Sprintf("Last maintainer response %s ago", FormatAge(a.SinceMaintainer)))
+	} else {
+		lines = append(lines, "No maintainer response yet")
+	}
+	lines = append(lines, fmt.Sprintf("Reporter: %s", a.Reporter()))
+	lines = append(lines, fmt.Sprintf("👍 %d (%d reactions)", a.ThumbsUp, a.Reactions))
+	return lines
+}
+
+// FormatAge rounds a duration to the largest sensible unit, e.g. "3 days"
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 48*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/(24*time.Hour)), "day")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestComputeActivity(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	comment := func(login, association string, ago time.Duration) *github.IssueComment {
+		return &github.IssueComment{
+			User:              &github.User{Login: github.String(login)},
+			AuthorAssociation: github.String(association),
+			CreatedAt:         &github.Timestamp{Time: now.Add(-ago)},
+		}
+	}
+	issue := &github.Issue{
+		User:              &github.User{Login: github.String("reporter")},
+		AuthorAssociation: github.String("FIRST_TIME_CONTRIBUTOR"),
+		CreatedAt:         &github.Timestamp{Time: now.Add(-5 * 24 * time.Hour)},
+		Reactions:         &github.Reactions{PlusOne: github.Int(12), TotalCount: github.Int(15)},
+	}
+
+	comments := []*github.IssueComment{
+		comment("maintainer", "MEMBER", 4*24*time.Hour),
+		comment("owner", "OWNER", 3*time.Hour),
+		comment("bystander", "NONE", time.Hour),
+	}
+	activity := ComputeActivity(issue, comments, now)
+
+	if !activity.MaintainerResponded || activity.SinceMaintainer != 3*time.Hour {
+		t.Errorf("Expected the last maintainer response 3h ago, got %+v", activity)
+	}
+	if first := FirstMaintainerResponse(issue, comments); !first.Equal(now.Add(-4 * 24 * time.Hour)) {
+		t.Errorf("Expected the first maintainer response 4 days ago, got %v", first)
+	}
+	if !activity.FirstTimeReporter() {
+		t.Error("Expected a first-time reporter")
+	}
+	want := []string{
+		"Opened 5 days ago",
+		"Last maintainer response 3 hours ago",
+		"Reporter: first-time contributor",
+		"👍 12 (15 reactions)",
+	}
+	if got := activity.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+
+	// A maintainer commenting on their own issue is not a response
+	issue.AuthorAssociation = github.String("MEMBER")
+	issue.User.Login = github.String("maintainer")
+	activity = ComputeActivity(issue, []*github.IssueComment{comment("maintainer", "MEMBER", time.Hour)}, now)
+	if activity.MaintainerResponded {
+		t.Errorf("Expected no maintainer response, got %+v", activity)
+	}
+	if activity.FirstTimeReporter() || activity.Lines()[1] != "No maintainer response yet" {
+		t.Errorf("Unexpected activity for a maintainer's issue: %q", activity.Lines())
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:     "0 minutes",
+		time.Minute:          "1 minute",
+		45 * time.Minute:     "45 minutes",
+		time.Hour:            "1 hour",
+		47 * time.Hour:       "47 hours",
+		50 * time.Hour:       "2 days",
+		400 * 24 * time.Hour: "400 days",
+	}
+	for d, want := range tests {
+		if got := FormatAge(d); got != want {
+			t.Errorf("FormatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// AttachmentConfig controls downloading log files linked from issues
+type AttachmentConfig struct {
+	Enabled  bool
+	MaxBytes int64 // Download limit per attachment; longer files are cut off
+	MaxFiles int   // Attachments read per issue
+	MaxLines int   // Lines kept per attachment
+}
+
+// Attachment is the relevant part of a log file linked from an issue
+type Attachment struct {
+	Name      string
+	URL       string
+	Lines     []string // Error and warning lines, or the last lines without any
+	Matched   bool     // Lines are errors and warnings rather than the tail of the file
+	Truncated bool     // The file was longer than the download limit
+}
+
+// attachmentPattern matches files uploaded to GitHub issues and gists. Other
+// hosts are never fetched.
+var attachmentPattern = regexp.MustCompile(`https://(?:github\.com/(?:[\w.-]+/[\w.-]+|user-attachments)/files/\d+/[^\s)\]"'<>]+|gist\.github\.com/[\w-]+/[0-9a-f]+|gist\.githubusercontent\.com/[\w-]+/[0-9a-f]+/raw/[^\s)\]"'<>]*)`)
+
+// attachmentExtensions are the uploaded file types worth reading
+var attachmentExtensions = map[string]bool{".log": true, ".txt": true, ".json": true}
+
+// logLinePattern matches lines that report errors and warnings
+var logLinePattern = regexp.MustCompile(`(?i)\b(error|fatal|panic|exception|fail(ed|ure)?|warn(ing)?|traceback|critical|segfault|denied|timed? ?out)\b`)
+
+// maxLineLength cuts off very long log lines, e.g. minified JSON
+const maxLineLength = 300
+
+// SetAttachments configures reading of log files linked from issues
+func (h *Handler) SetAttachments(config AttachmentConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.attachments = config
+}
+
+func (h *Handler) attachmentConfig() AttachmentConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.attachments
+}
+
+// attachmentLinks returns the downloadable attachments linked from text, with
+// gist pages rewritten to their raw content
+func attachmentLinks(text string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, link := range attachmentPattern.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:")
+		switch {
+		case strings.HasPrefix(link, "https://gist.github.com/"):
+			link = "https://gist.githubusercontent.com/" + strings.TrimPrefix(link, "https://gist.github.com/") + "/raw"
+		case strings.HasPrefix(link, "https://github.com/"):
+			if !attachmentExtensions[strings.ToLower(path.Ext(link))] {
+				continue
+			}
+		}
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// fetchAttachments downloads the log files linked from an issue body. Files
+// that cannot be read are skipped.
+func (h *Handler) fetchAttachments(ctx context.Context, body string) []Attachment {
+	config := h.attachmentConfig()
+	if !config.Enabled {
+		return nil
+	}
+
+	var attachments []Attachment
+	for _, link := range attachmentLinks(body) {
+		if len(attachments) >= config.MaxFiles {
+			break
+		}
+		attachment, err := h.fetchAttachment(ctx, link, config)
+		if err != nil {
+			h.metrics.RecordGitHubAPIError("fetch_attachment", apperrors.Classify(err))
+			h.logger.Warn("Failed to fetch issue attachment", zap.String("url", link), zap.Error(err))
+			continue
+		}
+		if len(attachment.Lines) > 0 {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments
+}
+
+// fetchAttachment downloads up to config.MaxBytes of a file and keeps its
+// most relevant lines
+func (h *Handler) fetchAttachment(ctx context.Context, link string, config AttachmentConfig) (Attachment, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return Attachment{}, err
+	}
+	resp, err := h.attachmentClient.Do(req)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to download attachment: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Attachment{}, fmt.Errorf("attachment download returned status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, config.MaxBytes+1))
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	truncated := int64(len(content)) > config.MaxBytes
+	if truncated {
+		content = content[:config.MaxBytes]
+	}
+
+	lines, matched := relevantLines(string(content), config.MaxLines)
+	name := path.Base(link)
+	if name == "raw" {
+		name = "gist " + path.Base(path.Dir(link))
+	}
+	return Attachment{Name: name, URL: link, Lines: lines, Matched: matched, Truncated: truncated}, nil
+}
+
+// relevantLines returns up to maxLines error and warning lines of a log, or
+// its last lines when it reports none, since failures usually end a log
+func relevantLines(content string, maxLines int) ([]string, bool) {
+	var all, matched []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > maxLineLength {
+			line = line[:maxLineLength] + "…"
+		}
+		all = append(all, line)
+		if logLinePattern.MatchString(line) && !seen[line] && len(matched) < maxLines {
+			seen[line] = true
+			matched = append(matched, line)
+		}
+	}
+	if len(matched) > 0 {
+		return matched, true
+	}
+	if len(all) > maxLines {
+		all = all[len(all)-maxLines:]
+	}
+	return all, false
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// roundTripFunc serves attachment downloads without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestAttachmentLinks(t *testing.T) {
+	body := `Crashes on start, logs attached:
+[server.log](https://github.com/owner/repo/files/123/server.log).
+Config: https://github.com/user-attachments/files/456/config.json
+Screenshot: https://github.com/owner/repo/files/789/screen.png
+Full trace in https://gist.github.com/octocat/aa5a315d61ae9438b18d and
+https://github.com/owner/repo/files/123/server.log again.
+Not fetched: https://example.com/files/1/evil.log`
+
+	want := []string{
+		"https://github.com/owner/repo/files/123/server.log",
+		"https://github.com/user-attachments/files/456/config.json",
+		"https://gist.githubusercontent.com/octocat/aa5a315d61ae9438b18d/raw",
+	}
+	if got := attachmentLinks(body); !reflect.DeepEqual(got, want) {
+		t.Errorf("attachmentLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestRelevantLines(t *testing.T) {
+	log := "starting server\nlistening on :8080\nWARN cache miss rate high\nERROR failed to connect to db\nERROR failed to connect to db\npanic: nil pointer dereference\n"
+	lines, matched := relevantLines(log, 10)
+	want := []string{"WARN cache miss rate high", "ERROR failed to connect to db", "panic: nil pointer dereference"}
+	if !matched || !reflect.DeepEqual(lines, want) {
+		t.Errorf("relevantLines() = %q, %v, want %q", lines, matched, want)
+	}
+
+	lines, matched = relevantLines("one\ntwo\nthree\nfour", 2)
+	if matched || !reflect.DeepEqual(lines, []string{"three", "four"}) {
+		t.Errorf("Expected the last lines without errors, got %q, %v", lines, matched)
+	}
+}
+
+func TestFetchAttachments(t *testing.T) {
+	metrics := &MockMetricsRecorder{}
+	metrics.On("RecordGitHubAPIError", "fetch_attachment", mock.Anything).Return()
+	var requested []string
+	handler := &Handler{
+		logger:  zap.NewNop(),
+		metrics: metrics,
+		attachmentClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requested = append(requested, req.URL.String())
+			if strings.HasSuffix(req.URL.Path, "missing.log") {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			content := "ok\n" + strings.Repeat("x", 100) + "\nERROR disk full\n"
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(content))}, nil
+		})},
+	}
+	body := "https://github.com/owner/repo/files/1/missing.log https://github.com/owner/repo/files/2/app.log"
+
+	if attachments := handler.fetchAttachments(context.Background(), body); attachments != nil {
+		t.Fatalf("Expected nothing while disabled, got %+v", attachments)
+	}
+
+	handler.SetAttachments(AttachmentConfig{Enabled: true, MaxBytes: 50, MaxFiles: 3, MaxLines: 10})
+	attachments := handler.fetchAttachments(context.Background(), body)
+	if len(requested) != 2 || len(attachments) != 1 {
+		t.Fatalf("Expected one of two attachments, got %d requests and %+v", len(requested), attachments)
+	}
+	// The error line is past the download limit
+	if got := attachments[0]; got.Name != "app.log" || !got.Truncated || got.Matched {
+		t.Errorf("Expected a truncated attachment without errors, got %+v", got)
+	}
+	metrics.AssertCalled(t, "RecordGitHubAPIError", "fetch_attachment", mock.Anything)
+}
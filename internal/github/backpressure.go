@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQueueFull is returned for a replayed delivery that arrived while the
+// queue and spool were full
+var ErrQueueFull = errors.New("webhook queue is full")
+
+// Saturation policies, for webhooks that arrive while every worker is busy
+// and the queue is full
+const (
+	SaturationReject = "reject" // Answer 503 with Retry-After, so the delivery fails and can be redelivered
+	SaturationSpool  = "spool"  // Accept and hold the delivery in memory until a worker is free
+)
+
+// QueueConfig bounds how many webhooks are processed at once and how many
+// may wait for a worker
+type QueueConfig struct {
+	Workers    int           // Issues processed concurrently, 0 for no limit
+	Size       int           // Accepted webhooks that may wait for a worker
+	Policy     string        // What to do once the queue is full: reject or spool
+	SpoolSize  int           // Webhooks spooled beyond the queue with the spool policy
+	RetryAfter time.Duration // Sent as Retry-After with rejected webhooks
+}
+
+// WorkQueue admits webhooks for background processing while there is room,
+// so a slow OpenAI or Slack cannot pile up unbounded work
+type WorkQueue struct {
+	config   QueueConfig
+	admitted chan struct{} // One token per accepted webhook that has not finished processing
+	workers  chan struct{} // One token per webhook being processed
+}
+
+// NewWorkQueue validates the configuration and creates a queue. It returns
+// nil without an error when Workers is 0, so every webhook is accepted.
+func NewWorkQueue(config QueueConfig) (*WorkQueue, error) {
+	if config.Workers == 0 {
+		return nil, nil
+	}
+	if config.Workers < 0 || config.Size < 0 || config.SpoolSize < 0 {
+		return nil, fmt.Errorf("webhook workers, queue size and spool size must not be negative")
+	}
+	switch config.Policy {
+	case SaturationReject:
+		config.SpoolSize = 0
+	case SaturationSpool:
+		if config.SpoolSize == 0 {
+			return nil, fmt.Errorf("the %s saturation policy needs a spool size", SaturationSpool)
+		}
+	default:
+		return nil, fmt.Errorf("invalid saturation policy %q", config.Policy)
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Minute
+	}
+
+	return &WorkQueue{
+		config:   config,
+		admitted: make(chan struct{}, config.Workers+config.Size+config.SpoolSize),
+		workers:  make(chan struct{}, config.Workers),
+	}, nil
+}
+
+// admit reserves room for a webhook. It reports false when the queue and
+// spool are full, and whether the webhook only fit into the spool.
+func (q *WorkQueue) admit() (spooled, ok bool) {
+	select {
+	case q.admitted <- struct{}{}:
+	default:
+		return false, false
+	}
+	return len(q.admitted) > q.config.Workers+q.config.Size, true
+}
+
+// release frees the room reserved for a webhook
+func (q *WorkQueue) release() {
+	<-q.admitted
+}
+
+// run waits for a free worker and processes an admitted webhook with it. The
+// wait ends early when ctx is cancelled, e.g. on shutdown.
+func (q *WorkQueue) run(ctx context.Context, process func()) {
+	defer q.release()
+	select {
+	case q.workers <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-q.workers }()
+	process()
+}
+
+// Depth returns the number of accepted webhooks that have not finished processing
+func (q *WorkQueue) Depth() int {
+	return len(q.admitted)
+}
+
+// QueueStats is how busy a webhook queue is
+type QueueStats struct {
+	Limited    bool `json:"limited"`            // False without a queue, when webhooks are processed as they arrive
+	Workers    int  `json:"workers,omitempty"`  // Webhooks processed at once
+	Capacity   int  `json:"capacity,omitempty"` // Webhooks accepted at once, including the spool
+	Processing int  `json:"processing"`
+	Waiting    int  `json:"waiting"` // Accepted webhooks waiting for a worker
+}
+
+// Stats returns the queue's current load
+func (q *WorkQueue) Stats() QueueStats {
+	processing := len(q.workers)
+	return QueueStats{
+		Limited:    true,
+		Workers:    q.config.Workers,
+		Capacity:   cap(q.admitted),
+		Processing: processing,
+		Waiting:    max(0, len(q.admitted)-processing),
+	}
+}
+
+// retryAfterSeconds is the Retry-After header value for rejected webhooks
+func (q *WorkQueue) retryAfterSeconds() string {
+	return fmt.Sprintf("%d", max(1, int(q.config.RetryAfter.Round(time.Second)/time.Second)))
+}
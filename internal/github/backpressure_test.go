@@ -0,0 +1,161 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// blockingProcessor holds every issue until it is released
+type blockingProcessor struct {
+	started chan int
+	release chan struct{}
+}
+
+func (p *blockingProcessor) ProcessIssue(ctx context.Context, issueData *IssueData) {
+	p.started <- issueData.Issue.GetNumber()
+	<-p.release
+}
+
+func newQueueTestHandler(t *testing.T, config QueueConfig) (*Handler, *blockingProcessor, *MockMetricsRecorder) {
+	t.Helper()
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	metrics := &MockMetricsRecorder{}
+	metrics.On("RecordGitHubWebhook", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	metrics.On("RecordGitHubAPIError", mock.Anything, mock.Anything).Return()
+	metrics.On("RecordWebhookQueueDepth", mock.Anything).Return()
+	metrics.On("RecordWebhookSaturation", mock.Anything).Return()
+
+	queue, err := NewWorkQueue(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor := &blockingProcessor{started: make(chan int, 10), release: make(chan struct{})}
+	handler := NewHandlerWithClient(client, "test-secret", zap.NewNop(), metrics)
+	handler.SetIssueProcessor(processor)
+	handler.SetWorkQueue(queue)
+	return handler, processor, metrics
+}
+
+func issuePayload(number int) []byte {
+	payload, _ := json.Marshal(github.IssuesEvent{
+		Action: github.String("opened"),
+		Issue:  &github.Issue{Number: github.Int(number), Title: github.String("Crash")},
+		Repo:   &github.Repository{FullName: github.String("test/repo"), Name: github.String("repo"), Owner: &github.User{Login: github.String("test")}},
+	})
+	return payload
+}
+
+func sendIssueWebhook(handler *Handler, number int) *httptest.ResponseRecorder {
+	payload := issuePayload(number)
+	req := httptest.NewRequest("POST", "/webhook/github", bytes.NewBuffer(payload))
+	req.Header.Set("X-GitHub-Event", "issues")
+	req.Header.Set("X-Hub-Signature-256", generateSignature("test-secret", payload))
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+	return w
+}
+
+func waitForStart(t *testing.T, processor *blockingProcessor) int {
+	t.Helper()
+	select {
+	case number := <-processor.started:
+		return number
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for processing to start")
+		return 0
+	}
+}
+
+func TestWorkQueueRejectsWhenSaturated(t *testing.T) {
+	handler, processor, metrics := newQueueTestHandler(t, QueueConfig{Workers: 1, Size: 1, Policy: SaturationReject, RetryAfter: 30 * time.Second})
+
+	assert.Equal(t, http.StatusOK, sendIssueWebhook(handler, 1).Code)
+	assert.Equal(t, 1, waitForStart(t, processor))
+	assert.Equal(t, http.StatusOK, sendIssueWebhook(handler, 2).Code)
+	assert.Equal(t, QueueStats{Limited: true, Workers: 1, Capacity: 2, Processing: 1, Waiting: 1}, handler.QueueStats())
+
+	// The worker is busy and the queue is full
+	w := sendIssueWebhook(handler, 3)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	metrics.AssertCalled(t, "RecordWebhookSaturation", "rejected")
+	metrics.AssertCalled(t, "RecordGitHubWebhook", "issues", "", "saturated", mock.Anything)
+
+	// Queued work runs once the worker is free, making room again
+	processor.release <- struct{}{}
+	assert.Equal(t, 2, waitForStart(t, processor))
+	assert.Equal(t, http.StatusOK, sendIssueWebhook(handler, 4).Code)
+	processor.release <- struct{}{}
+	assert.Equal(t, 4, waitForStart(t, processor))
+	processor.release <- struct{}{}
+}
+
+func TestWorkQueueSpoolsWhenSaturated(t *testing.T) {
+	handler, processor, metrics := newQueueTestHandler(t, QueueConfig{Workers: 1, Policy: SaturationSpool, SpoolSize: 1})
+
+	assert.Equal(t, http.StatusOK, sendIssueWebhook(handler, 1).Code)
+	assert.Equal(t, 1, waitForStart(t, processor))
+
+	// Beyond the queue deliveries are spooled, and rejected once the spool is full
+	assert.Equal(t, http.StatusAccepted, sendIssueWebhook(handler, 2).Code)
+	metrics.AssertCalled(t, "RecordWebhookSaturation", "spooled")
+	w := sendIssueWebhook(handler, 3)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	processor.release <- struct{}{}
+	assert.Equal(t, 2, waitForStart(t, processor))
+	processor.release <- struct{}{}
+}
+
+func TestReplayUsesWorkQueue(t *testing.T) {
+	handler, processor, metrics := newQueueTestHandler(t, QueueConfig{Workers: 1, Policy: SaturationReject})
+
+	assert.Equal(t, http.StatusOK, sendIssueWebhook(handler, 1).Code)
+	assert.Equal(t, 1, waitForStart(t, processor))
+
+	// The worker is busy, so the replay is turned away like a live delivery
+	_, err := handler.Replay(context.Background(), "issues", "D2", issuePayload(2))
+	assert.ErrorIs(t, err, ErrQueueFull)
+	metrics.AssertCalled(t, "RecordWebhookSaturation", "rejected")
+
+	processor.release <- struct{}{}
+	assert.Eventually(t, func() bool { return handler.QueueStats().Processing == 0 }, time.Second, 10*time.Millisecond)
+	issueData, err := handler.Replay(context.Background(), "issues", "D2", issuePayload(2))
+	assert.NoError(t, err)
+	assert.Equal(t, "D2", issueData.DeliveryID)
+	assert.Equal(t, 2, waitForStart(t, processor))
+	assert.Equal(t, 1, handler.QueueStats().Processing)
+	processor.release <- struct{}{}
+}
+
+func TestNewWorkQueueValidation(t *testing.T) {
+	queue, err := NewWorkQueue(QueueConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, queue, "Expected no queue without workers")
+
+	for _, config := range []QueueConfig{
+		{Workers: 1, Policy: "drop"},
+		{Workers: 1, Policy: SaturationSpool},
+		{Workers: -1, Policy: SaturationReject},
+		{Workers: 1, Size: -1, Policy: SaturationReject},
+	} {
+		_, err := NewWorkQueue(config)
+		assert.Error(t, err, "Expected %+v to be rejected", config)
+	}
+}
@@ -0,0 +1,203 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// Ways of publishing triage results on pull requests
+const (
+	CheckModeStatus   = "status"    // Commit status, which works with personal access tokens
+	CheckModeCheckRun = "check_run" // Check run with the full analysis, which needs a GitHub App token
+)
+
+// CheckName names the check run and commit status context of triage results
+const CheckName = "NotifyOps triage"
+
+// maxStatusDescription is the longest description GitHub accepts for a commit status
+const maxStatusDescription = 140
+
+// CheckConfig controls publishing triage results on the pull requests linked
+// to an issue
+type CheckConfig struct {
+	Mode string // CheckModeStatus, CheckModeCheckRun or empty for none
+}
+
+// ValidCheckMode reports whether mode is a way of publishing triage results
+func ValidCheckMode(mode string) bool {
+	return mode == "" || mode == CheckModeStatus || mode == CheckModeCheckRun
+}
+
+// TriageCheck is an issue's triage result as shown on its pull requests
+type TriageCheck struct {
+	Title   string // One-line verdict, e.g. "High priority bug"
+	Summary string // Markdown shown at the top of the check run
+	Text    string // Markdown details, e.g. the suggested fix
+	URL     string // Link from the commit status, e.g. to the issue
+}
+
+// SetChecks configures publishing of triage results on pull requests
+func (h *Handler) SetChecks(config CheckConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = config
+}
+
+func (h *Handler) checkConfig() CheckConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.checks
+}
+
+// PublishTriageCheck publishes an issue's triage result on the head commit of
+// each open pull request linked to it, or of the issue itself when it is a
+// pull request. It returns the number of pull requests published to.
+func (h *Handler) PublishTriageCheck(ctx context.Context, repo string, number int, check TriageCheck) (int, error) {
+	mode := h.checkConfig().Mode
+	if mode == "" {
+		return 0, nil
+	}
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid repo format: %s", repo)
+	}
+	owner, name := parts[0], parts[1]
+
+	pulls, err := h.linkedPullRequests(ctx, owner, name, number)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	var errs []error
+	for _, pull := range pulls {
+		sha := pull.GetHead().GetSHA()
+		if mode == CheckModeCheckRun {
+			err = h.createCheckRun(ctx, owner, name, sha, check)
+		} else {
+			err = h.createStatus(ctx, owner, name, sha, check)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pull request #%d: %w", pull.GetNumber(), err))
+			continue
+		}
+		published++
+		h.logger.Info("Published triage result",
+			zap.String("repository", repo),
+			zap.Int("issue_number", number),
+			zap.Int("pull_request", pull.GetNumber()),
+			zap.String("mode", mode),
+		)
+	}
+	return published, errors.Join(errs...)
+}
+
+// linkedPullRequests returns the open pull requests in the repository that
+// mention the issue, found through the issue's cross-references
+func (h *Handler) linkedPullRequests(ctx context.Context, owner, repo string, number int) ([]*github.PullRequest, error) {
+	var numbers []int
+	seen := map[int]bool{}
+	issue, _, err := h.githubClient().Issues.Get(ctx, owner, repo, number)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("get_issue", apperrors.Classify(err))
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+	if issue.IsPullRequest() {
+		numbers = append(numbers, number)
+		seen[number] = true
+	}
+
+	events, _, err := h.githubClient().Issues.ListIssueTimeline(ctx, owner, repo, number, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_timeline", apperrors.Classify(err))
+		return nil, fmt.Errorf("failed to list issue timeline: %w", err)
+	}
+	fullName := owner + "/" + repo
+	for _, event := range events {
+		source := event.GetSource().GetIssue()
+		if event.GetEvent() != "cross-referenced" || !source.IsPullRequest() || source.GetState() != "open" || seen[source.GetNumber()] {
+			continue
+		}
+		// Pull requests in other repositories cannot carry this repository's checks
+		if repository := source.GetRepository(); repository != nil && !strings.EqualFold(repository.GetFullName(), fullName) {
+			continue
+		}
+		numbers = append(numbers, source.GetNumber())
+		seen[source.GetNumber()] = true
+	}
+
+	var pulls []*github.PullRequest
+	for _, n := range numbers {
+		pull, _, err := h.githubClient().PullRequests.Get(ctx, owner, repo, n)
+		if err != nil {
+			err = classifyError(err)
+			h.metrics.RecordGitHubAPIError("get_pull_request", apperrors.Classify(err))
+			return nil, fmt.Errorf("failed to get pull request #%d: %w", n, err)
+		}
+		if pull.GetState() == "open" {
+			pulls = append(pulls, pull)
+		}
+	}
+	return pulls, nil
+}
+
+// createCheckRun adds a completed, neutral check run so the triage result
+// never blocks merging
+func (h *Handler) createCheckRun(ctx context.Context, owner, repo, sha string, check TriageCheck) error {
+	_, _, err := h.githubClient().Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:       CheckName,
+		HeadSHA:    sha,
+		Status:     github.String("completed"),
+		Conclusion: github.String("neutral"),
+		DetailsURL: optionalString(check.URL),
+		Output: &github.CheckRunOutput{
+			Title:   github.String(check.Title),
+			Summary: github.String(check.Summary),
+			Text:    optionalString(check.Text),
+		},
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("create_check_run", apperrors.Classify(err))
+		return fmt.Errorf("failed to create check run: %w", err)
+	}
+	return nil
+}
+
+// createStatus sets a successful commit status, replacing the previous
+// triage result on the commit
+func (h *Handler) createStatus(ctx context.Context, owner, repo, sha string, check TriageCheck) error {
+	description := check.Title
+	if runes := []rune(description); len(runes) > maxStatusDescription {
+		description = string(runes[:maxStatusDescription-1]) + "…"
+	}
+	_, _, err := h.githubClient().Repositories.CreateStatus(ctx, owner, repo, sha, &github.RepoStatus{
+		State:       github.String("success"),
+		Description: github.String(description),
+		Context:     github.String(CheckName),
+		TargetURL:   optionalString(check.URL),
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("create_status", apperrors.Classify(err))
+		return fmt.Errorf("failed to create commit status: %w", err)
+	}
+	return nil
+}
+
+// optionalString omits empty strings from API requests
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return github.String(s)
+}
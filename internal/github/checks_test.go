@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestPublishTriageCheck(t *testing.T) {
+	var published []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/o/r/issues/7":
+			json.NewEncoder(w).Encode(github.Issue{Number: github.Int(7)})
+		case r.URL.Path == "/repos/o/r/issues/7/timeline":
+			pull := func(number int, state, repo string) *github.Timeline {
+				return &github.Timeline{Event: github.String("cross-referenced"), Source: &github.Source{Issue: &github.Issue{
+					Number:           github.Int(number),
+					State:            github.String(state),
+					PullRequestLinks: &github.PullRequestLinks{},
+					Repository:       &github.Repository{FullName: github.String(repo)},
+				}}}
+			}
+			json.NewEncoder(w).Encode([]*github.Timeline{
+				pull(10, "open", "o/r"),
+				pull(11, "closed", "o/r"),
+				pull(12, "open", "fork/r"),
+				{Event: github.String("labeled")},
+			})
+		case r.URL.Path == "/repos/o/r/pulls/10":
+			json.NewEncoder(w).Encode(github.PullRequest{Number: github.Int(10), State: github.String("open"), Head: &github.PullRequestBranch{SHA: github.String("abc123")}})
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/repos/o/r/"):
+			published = append(published, strings.TrimPrefix(r.URL.Path, "/repos/o/r/"))
+			w.Write([]byte("{}"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	check := TriageCheck{Title: "High priority bug", Summary: "Crashes on start", URL: "https://github.com/o/r/issues/7"}
+	if n, err := handler.PublishTriageCheck(context.Background(), "o/r", 7, check); n != 0 || err != nil {
+		t.Fatalf("Expected nothing published while disabled, got %d, %v", n, err)
+	}
+
+	handler.SetChecks(CheckConfig{Mode: CheckModeStatus})
+	if n, err := handler.PublishTriageCheck(context.Background(), "o/r", 7, check); n != 1 || err != nil {
+		t.Fatalf("Expected the open pull request published to, got %d, %v", n, err)
+	}
+	handler.SetChecks(CheckConfig{Mode: CheckModeCheckRun})
+	handler.PublishTriageCheck(context.Background(), "o/r", 7, check)
+	if want := []string{"statuses/abc123", "check-runs"}; !reflect.DeepEqual(published, want) {
+		t.Errorf("Expected %v, got %v", want, published)
+	}
+}
@@ -0,0 +1,341 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// maxSuspectCommits is how many commits since the last green build a build
+// break lists, newest first
+const maxSuspectCommits = 5
+
+// CIConfig controls the build break pipeline for issues opened by CI, e.g.
+// by a workflow that files an issue when the main branch fails
+type CIConfig struct {
+	Enabled  bool
+	Labels   []string // Labels that mark an issue as a CI failure, e.g. "ci-failure"
+	Authors  []string // Logins that open CI failure issues, e.g. "github-actions[bot]"
+	MaxBytes int64    // Download limit per job log; longer logs keep their end
+	MaxLines int      // Error lines kept from the failing step
+}
+
+// CIFailure is a failed GitHub Actions run linked from an issue, digested
+// from its jobs and logs
+type CIFailure struct {
+	Workflow  string
+	RunURL    string
+	Branch    string
+	Job       string     // First failed job
+	JobURL    string     // Page of the failed job
+	Step      string     // Step that failed in the job, empty when unknown
+	Errors    []string   // Error lines from the failing step's log
+	Commit    CICommit   // Probable offending commit
+	Suspects  []CICommit // Commits since the last green build, newest first, when known
+	LastGreen string     // Short SHA of the last commit the workflow passed on, empty when unknown
+}
+
+// CICommit is a commit that may have broken the build
+type CICommit struct {
+	SHA     string // Short SHA
+	URL     string
+	Author  string
+	Message string // First line of the message
+}
+
+// runLinkPattern matches links to GitHub Actions runs, and optionally to one
+// of their jobs
+var runLinkPattern = regexp.MustCompile(`https://github\.com/([\w.-]+)/([\w.-]+)/actions/runs/(\d+)(?:/job/(\d+))?`)
+
+// logTimestampPattern matches the timestamp GitHub prefixes to job log lines
+var logTimestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T[\d:.]+Z ?`)
+
+// SetCIFailures configures the build break pipeline for CI failure issues
+func (h *Handler) SetCIFailures(config CIConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ci = config
+}
+
+func (h *Handler) ciConfig() CIConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.ci
+}
+
+// IsCIFailure reports whether an issue was opened by CI, by its author or
+// one of its labels
+func (c CIConfig) IsCIFailure(issue *github.Issue) bool {
+	if !c.Enabled {
+		return false
+	}
+	for _, author := range c.Authors {
+		if strings.EqualFold(author, issue.GetUser().GetLogin()) {
+			return true
+		}
+	}
+	for _, label := range issue.Labels {
+		for _, ciLabel := range c.Labels {
+			if strings.EqualFold(ciLabel, label.GetName()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// digestCIFailure finds the failed job of the run a CI failure issue links
+// to, extracts the failing step and its errors from the job's log, and picks
+// the commit that probably broke the build. Issues without a run link, or
+// whose run cannot be read, get nil and go through the usual pipeline.
+func (h *Handler) digestCIFailure(ctx context.Context, issue *github.Issue) *CIFailure {
+	config := h.ciConfig()
+	if !config.IsCIFailure(issue) {
+		return nil
+	}
+	match := runLinkPattern.FindStringSubmatch(issue.GetBody())
+	if match == nil {
+		h.logger.Info("CI failure issue links no workflow run", zap.Int("issue_number", issue.GetNumber()))
+		return nil
+	}
+	owner, repo := match[1], match[2]
+	runID, _ := strconv.ParseInt(match[3], 10, 64)
+	jobID, _ := strconv.ParseInt(match[4], 10, 64)
+
+	run, _, err := h.githubClient().Actions.GetWorkflowRunByID(ctx, owner, repo, runID)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("get_workflow_run", apperrors.Classify(err))
+		h.logger.Warn("Failed to fetch workflow run of CI failure", zap.Int64("run_id", runID), zap.Error(err))
+		return nil
+	}
+	failure := &CIFailure{
+		Workflow: run.GetName(),
+		RunURL:   run.GetHTMLURL(),
+		Branch:   run.GetHeadBranch(),
+		Commit: CICommit{
+			SHA:     shortSHA(run.GetHeadSHA()),
+			URL:     fmt.Sprintf("https://github.com/%s/%s/commit/%s", owner, repo, run.GetHeadSHA()),
+			Author:  run.GetHeadCommit().GetAuthor().GetName(),
+			Message: utils.FirstLine(run.GetHeadCommit().GetMessage()),
+		},
+	}
+
+	if job := h.failedJob(ctx, owner, repo, runID, jobID); job != nil {
+		failure.Job, failure.JobURL = job.GetName(), job.GetHTMLURL()
+		for _, step := range job.Steps {
+			if step.GetConclusion() == "failure" {
+				failure.Step = step.GetName()
+				break
+			}
+		}
+		failure.Errors = h.jobErrors(ctx, owner, repo, job.GetID(), config)
+	}
+	h.findSuspects(ctx, owner, repo, run, failure)
+	return failure
+}
+
+// failedJob returns the linked job, or the run's first failed job
+func (h *Handler) failedJob(ctx context.Context, owner, repo string, runID, jobID int64) *github.WorkflowJob {
+	jobs, _, err := h.githubClient().Actions.ListWorkflowJobs(ctx, owner, repo, runID, &github.ListWorkflowJobsOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_workflow_jobs", apperrors.Classify(err))
+		h.logger.Warn("Failed to list jobs of CI failure", zap.Int64("run_id", runID), zap.Error(err))
+		return nil
+	}
+	var failed *github.WorkflowJob
+	for _, job := range jobs.Jobs {
+		if job.GetID() == jobID {
+			return job
+		}
+		if failed == nil && job.GetConclusion() == "failure" {
+			failed = job
+		}
+	}
+	return failed
+}
+
+// jobErrors downloads the end of a job's log and returns the error lines of
+// the failing step. Logs are downloaded from a redirect, which is not an API
+// call.
+func (h *Handler) jobErrors(ctx context.Context, owner, repo string, jobID int64, config CIConfig) []string {
+	logURL, _, err := h.githubClient().Actions.GetWorkflowJobLogs(ctx, owner, repo, jobID, 1)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("get_job_logs", apperrors.Classify(err))
+		h.logger.Warn("Failed to find log of CI failure", zap.Int64("job_id", jobID), zap.Error(err))
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logURL.String(), nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := h.attachmentClient.Do(req)
+	if err != nil {
+		h.logger.Warn("Failed to download log of CI failure", zap.Int64("job_id", jobID), zap.Error(err))
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		h.logger.Warn("Log download of CI failure failed", zap.Int64("job_id", jobID), zap.Int("status", resp.StatusCode))
+		return nil
+	}
+	content, err := readTail(resp.Body, config.MaxBytes)
+	if err != nil {
+		h.logger.Warn("Failed to read log of CI failure", zap.Int64("job_id", jobID), zap.Error(err))
+		return nil
+	}
+	return FailingStepErrors(string(content), config.MaxLines)
+}
+
+// FailingStepErrors returns up to maxLines error lines from the output of the
+// step that failed in a GitHub Actions job log: the last group opened before
+// the first error annotation, or the end of the log without one
+func FailingStepErrors(log string, maxLines int) []string {
+	lines := strings.Split(log, "\n")
+	for i, line := range lines {
+		lines[i] = logTimestampPattern.ReplaceAllString(strings.TrimRight(line, "\r"), "")
+	}
+	end := len(lines)
+	for i, line := range lines {
+		if strings.HasPrefix(line, "##[error]") {
+			end = i + 1
+			break
+		}
+	}
+	start := 0
+	for i := end - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], "##[group]") {
+			start = i
+			break
+		}
+	}
+
+	var output []string
+	for _, line := range lines[start:end] {
+		if strings.HasPrefix(line, "##[group]") || strings.HasPrefix(line, "##[endgroup]") {
+			continue
+		}
+		output = append(output, strings.TrimPrefix(line, "##[error]"))
+	}
+	errors, _ := relevantLines(strings.Join(output, "\n"), maxLines)
+	return errors
+}
+
+// findSuspects lists the commits between the last successful run of the
+// workflow on the branch and the failed one. Of several, the newest that
+// touched a file the errors mention is the probable offender; otherwise the
+// failed run's commit is.
+func (h *Handler) findSuspects(ctx context.Context, owner, repo string, run *github.WorkflowRun, failure *CIFailure) {
+	runs, _, err := h.githubClient().Actions.ListWorkflowRunsByID(ctx, owner, repo, run.GetWorkflowID(), &github.ListWorkflowRunsOptions{
+		Branch:      run.GetHeadBranch(),
+		Status:      "success",
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_workflow_runs", apperrors.Classify(err))
+		h.logger.Warn("Failed to find last green build of CI failure", zap.Int64("run_id", run.GetID()), zap.Error(err))
+		return
+	}
+	if len(runs.WorkflowRuns) == 0 {
+		return
+	}
+	green := runs.WorkflowRuns[0].GetHeadSHA()
+	comparison, _, err := h.githubClient().Repositories.CompareCommits(ctx, owner, repo, green, run.GetHeadSHA(), &github.ListOptions{PerPage: 100})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("compare_commits", apperrors.Classify(err))
+		h.logger.Warn("Failed to compare CI failure with the last green build", zap.Error(err))
+		return
+	}
+	failure.LastGreen = shortSHA(green)
+
+	// Compared commits are oldest first
+	var suspects []*github.RepositoryCommit
+	for i := len(comparison.Commits) - 1; i >= 0 && len(suspects) < maxSuspectCommits; i-- {
+		suspects = append(suspects, comparison.Commits[i])
+	}
+	for _, commit := range suspects {
+		failure.Suspects = append(failure.Suspects, ciCommit(commit))
+	}
+
+	mentioned := MentionedFiles(strings.Join(failure.Errors, "\n"))
+	if len(suspects) < 2 || len(mentioned) == 0 {
+		return
+	}
+	for _, suspect := range suspects {
+		commit, _, err := h.githubClient().Repositories.GetCommit(ctx, owner, repo, suspect.GetSHA(), nil)
+		if err != nil {
+			err = classifyError(err)
+			h.metrics.RecordGitHubAPIError("fetch_files", apperrors.Classify(err))
+			continue
+		}
+		for _, file := range commit.Files {
+			if matchesMentioned(file.GetFilename(), mentioned) {
+				failure.Commit = ciCommit(suspect)
+				return
+			}
+		}
+	}
+}
+
+func ciCommit(commit *github.RepositoryCommit) CICommit {
+	author := commit.GetAuthor().GetLogin()
+	if author == "" {
+		author = commit.GetCommit().GetAuthor().GetName()
+	}
+	return CICommit{
+		SHA:     shortSHA(commit.GetSHA()),
+		URL:     commit.GetHTMLURL(),
+		Author:  author,
+		Message: utils.FirstLine(commit.GetCommit().GetMessage()),
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// readTail reads r to the end and returns its last n bytes
+func readTail(r io.Reader, n int64) ([]byte, error) {
+	var tail []byte
+	cut := false
+	chunk := make([]byte, 32*1024)
+	for {
+		read, err := r.Read(chunk)
+		tail = append(tail, chunk[:read]...)
+		if int64(len(tail)) > 2*n {
+			tail, cut = append([]byte(nil), tail[int64(len(tail))-n:]...), true
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if int64(len(tail)) > n {
+		tail, cut = tail[int64(len(tail))-n:], true
+	}
+	// Start at a whole line
+	if i := bytes.IndexByte(tail, '\n'); cut && i >= 0 {
+		tail = tail[i+1:]
+	}
+	return tail, nil
+}
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+const testJobLog = `2024-01-15T10:00:00.0000000Z ##[group]Run actions/checkout@v4
+2024-01-15T10:00:01.0000000Z Fetching the repository
+2024-01-15T10:00:02.0000000Z ##[endgroup]
+2024-01-15T10:00:03.0000000Z ##[group]Run go test ./...
+2024-01-15T10:00:03.1000000Z go test ./...
+2024-01-15T10:00:03.2000000Z ##[endgroup]
+2024-01-15T10:00:10.0000000Z ok  	example.com/app/api	0.2s
+2024-01-15T10:00:11.0000000Z --- FAIL: TestRefund (0.00s)
+2024-01-15T10:00:11.1000000Z     refund_test.go:42: refund failed: expected 200, got 500
+2024-01-15T10:00:12.0000000Z FAIL	example.com/app/billing	0.1s
+2024-01-15T10:00:13.0000000Z ##[error]Process completed with exit code 1.
+2024-01-15T10:00:14.0000000Z Post job cleanup.
+`
+
+func TestFailingStepErrors(t *testing.T) {
+	want := []string{
+		"--- FAIL: TestRefund (0.00s)",
+		"refund_test.go:42: refund failed: expected 200, got 500",
+		"FAIL	example.com/app/billing	0.1s",
+	}
+	if got := FailingStepErrors(testJobLog, 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("FailingStepErrors() = %q, want %q", got, want)
+	}
+
+	// Without an error annotation the end of the log is used
+	if got := FailingStepErrors("##[group]Run make\nbuilding\nsegfault in worker\n", 10); !reflect.DeepEqual(got, []string{"segfault in worker"}) {
+		t.Errorf("Expected the last step's errors, got %q", got)
+	}
+}
+
+func TestCIConfigIsCIFailure(t *testing.T) {
+	config := CIConfig{Enabled: true, Labels: []string{"ci-failure"}, Authors: []string{"github-actions[bot]"}}
+	tests := []struct {
+		name  string
+		issue *github.Issue
+		want  bool
+	}{
+		{"by author", &github.Issue{User: &github.User{Login: github.String("GitHub-Actions[bot]")}}, true},
+		{"by label", &github.Issue{Labels: []*github.Label{{Name: github.String("CI-Failure")}}}, true},
+		{"other issue", &github.Issue{User: &github.User{Login: github.String("octocat")}}, false},
+	}
+	for _, tt := range tests {
+		if got := config.IsCIFailure(tt.issue); got != tt.want {
+			t.Errorf("%s: IsCIFailure() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	config.Enabled = false
+	if config.IsCIFailure(tests[0].issue) {
+		t.Error("Expected no CI failures when disabled")
+	}
+}
+
+func TestDigestCIFailure(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/actions/runs/100":
+			json.NewEncoder(w).Encode(github.WorkflowRun{
+				ID: github.Int64(100), Name: github.String("CI"), WorkflowID: github.Int64(9),
+				HeadBranch: github.String("main"), HeadSHA: github.String("ccccccc0000"),
+				HTMLURL:    github.String("https://github.com/o/r/actions/runs/100"),
+				HeadCommit: &github.HeadCommit{Message: github.String("Tidy up\n\nDetails"), Author: &github.CommitAuthor{Name: github.String("Carol")}},
+			})
+		case "/repos/o/r/actions/runs/100/jobs":
+			json.NewEncoder(w).Encode(github.Jobs{Jobs: []*github.WorkflowJob{
+				{ID: github.Int64(1), Name: github.String("lint"), Conclusion: github.String("success")},
+				{ID: github.Int64(2), Name: github.String("test"), Conclusion: github.String("failure"), HTMLURL: github.String("https://github.com/o/r/actions/runs/100/job/2"),
+					Steps: []*github.TaskStep{{Name: github.String("Checkout"), Conclusion: github.String("success")}, {Name: github.String("Run tests"), Conclusion: github.String("failure")}}},
+			}})
+		case "/repos/o/r/actions/jobs/2/logs":
+			http.Redirect(w, r, server.URL+"/logs/2.txt", http.StatusFound)
+		case "/logs/2.txt":
+			w.Write([]byte(testJobLog))
+		case "/repos/o/r/actions/workflows/9/runs":
+			if r.URL.Query().Get("status") != "success" || r.URL.Query().Get("branch") != "main" {
+				t.Errorf("Expected the last green run on main, got %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode(github.WorkflowRuns{WorkflowRuns: []*github.WorkflowRun{{HeadSHA: github.String("aaaaaaa0000")}}})
+		case "/repos/o/r/compare/aaaaaaa0000...ccccccc0000":
+			json.NewEncoder(w).Encode(github.CommitsComparison{Commits: []*github.RepositoryCommit{
+				{SHA: github.String("bbbbbbb0000"), HTMLURL: github.String("https://github.com/o/r/commit/bbbbbbb0000"), Author: &github.User{Login: github.String("bob")}, Commit: &github.Commit{Message: github.String("Change refunds")}},
+				{SHA: github.String("ccccccc0000"), HTMLURL: github.String("https://github.com/o/r/commit/ccccccc0000"), Author: &github.User{Login: github.String("carol")}, Commit: &github.Commit{Message: github.String("Tidy up")}},
+			}})
+		case "/repos/o/r/commits/ccccccc0000":
+			json.NewEncoder(w).Encode(github.RepositoryCommit{Files: []*github.CommitFile{{Filename: github.String("README.md")}}})
+		case "/repos/o/r/commits/bbbbbbb0000":
+			json.NewEncoder(w).Encode(github.RepositoryCommit{Files: []*github.CommitFile{{Filename: github.String("billing/refund_test.go")}}})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	handler.attachmentClient = server.Client()
+	handler.SetCIFailures(CIConfig{Enabled: true, Labels: []string{"ci-failure"}, MaxBytes: 1 << 20, MaxLines: 10})
+	issue := &github.Issue{
+		Number: github.Int(7),
+		Labels: []*github.Label{{Name: github.String("ci-failure")}},
+		Body:   github.String("The build failed: https://github.com/o/r/actions/runs/100"),
+	}
+
+	failure := handler.digestCIFailure(context.Background(), issue)
+	if failure == nil {
+		t.Fatal("Expected a digest of the failed run")
+	}
+	if failure.Workflow != "CI" || failure.Branch != "main" || failure.Job != "test" || failure.Step != "Run tests" {
+		t.Errorf("Expected the failed job and step, got %+v", failure)
+	}
+	if len(failure.Errors) != 3 || !strings.Contains(failure.Errors[0], "TestRefund") {
+		t.Errorf("Expected the failing step's errors, got %q", failure.Errors)
+	}
+	if failure.LastGreen != "aaaaaaa" || len(failure.Suspects) != 2 || failure.Suspects[0].SHA != "ccccccc" {
+		t.Errorf("Expected the commits since the last green build, newest first, got %s %+v", failure.LastGreen, failure.Suspects)
+	}
+	// The older commit touched the file the errors mention
+	if failure.Commit.SHA != "bbbbbbb" || failure.Commit.Author != "bob" {
+		t.Errorf("Expected the commit that touched refund_test.go, got %+v", failure.Commit)
+	}
+
+	issue.Body = github.String("The build failed")
+	if handler.digestCIFailure(context.Background(), issue) != nil {
+		t.Error("Expected no digest without a run link")
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	content, err := readTail(strings.NewReader(strings.Repeat("x", 100)+"\nfirst\nsecond\n"), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "second\n" {
+		t.Errorf("Expected the last whole lines, got %q", content)
+	}
+}
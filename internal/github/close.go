@@ -0,0 +1,183 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// Reasons an issue can be suggested for closing
+const (
+	CloseReasonResolved  = "resolved"
+	CloseReasonDuplicate = "duplicate"
+)
+
+// CloseSuggestion explains why an open issue looks ready to be closed
+type CloseSuggestion struct {
+	Reason      string   // CloseReasonResolved or CloseReasonDuplicate
+	Explanation string   // Human-readable reason, also used in the closing comment
+	References  []string // URLs of the commits, pull requests or issue backing the suggestion
+	DuplicateOf int      // Number of the closed issue this one duplicates
+}
+
+// closingKeywordPattern matches GitHub's closing keywords followed by an issue reference
+func closingKeywordPattern(number int) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?i)\b(close[sd]?|fix(e[sd])?|resolve[sd]?)\s*:?\s+(\S+/\S+)?#%d\b`, number))
+}
+
+// SetCommentFormatter sets the templates of the bot's comments on issues
+func (h *Handler) SetCommentFormatter(formatter *CommentFormatter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.comments = formatter
+}
+
+func (h *Handler) commentFormatter() *CommentFormatter {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.comments
+}
+
+// EnableCloseSuggestions turns detection of resolved and duplicate issues on or off
+func (h *Handler) EnableCloseSuggestions(enabled bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closeSuggestions = enabled
+}
+
+func (h *Handler) closeSuggestionsEnabled() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.closeSuggestions
+}
+
+// detectCloseSuggestion looks for merged work that closes the issue, then for
+// a closed issue with the same title
+func (h *Handler) detectCloseSuggestion(ctx context.Context, owner, repo string, issue *github.Issue, commits []*github.RepositoryCommit) *CloseSuggestion {
+	if !h.closeSuggestionsEnabled() || issue.GetState() == "closed" || issue.IsPullRequest() {
+		return nil
+	}
+
+	if suggestion := h.detectResolved(ctx, owner, repo, issue.GetNumber(), commits); suggestion != nil {
+		return suggestion
+	}
+	return h.detectDuplicate(ctx, owner, repo, issue)
+}
+
+// detectResolved checks related commits and merged pull requests for closing keywords
+func (h *Handler) detectResolved(ctx context.Context, owner, repo string, number int, commits []*github.RepositoryCommit) *CloseSuggestion {
+	pattern := closingKeywordPattern(number)
+
+	var refs []string
+	for _, commit := range commits {
+		if pattern.MatchString(commit.GetCommit().GetMessage()) {
+			refs = append(refs, commit.GetHTMLURL())
+		}
+	}
+
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged %d", owner, repo, number)
+	result, _, err := h.githubClient().Search.Issues(ctx, query, &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: 10},
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("search_closing_prs", apperrors.Classify(err))
+		h.logger.Warn("Failed to search merged pull requests", zap.Error(err))
+	} else {
+		for _, pr := range result.Issues {
+			if pattern.MatchString(pr.GetTitle()) || pattern.MatchString(pr.GetBody()) {
+				refs = append(refs, pr.GetHTMLURL())
+			}
+		}
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+	return &CloseSuggestion{
+		Reason:      CloseReasonResolved,
+		Explanation: fmt.Sprintf("Merged changes reference this issue with a closing keyword: %s", strings.Join(refs, ", ")),
+		References:  refs,
+	}
+}
+
+// detectDuplicate looks for a closed issue with an identical title
+func (h *Handler) detectDuplicate(ctx context.Context, owner, repo string, issue *github.Issue) *CloseSuggestion {
+	title := strings.TrimSpace(issue.GetTitle())
+	if title == "" {
+		return nil
+	}
+
+	query := fmt.Sprintf("repo:%s/%s is:issue is:closed in:title %q", owner, repo, strings.ReplaceAll(title, `"`, ""))
+	result, _, err := h.githubClient().Search.Issues(ctx, query, &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: 10},
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("search_duplicates", apperrors.Classify(err))
+		h.logger.Warn("Failed to search for duplicate issues", zap.Error(err))
+		return nil
+	}
+
+	for _, candidate := range result.Issues {
+		if candidate.GetNumber() == issue.GetNumber() || !strings.EqualFold(strings.TrimSpace(candidate.GetTitle()), title) {
+			continue
+		}
+		return &CloseSuggestion{
+			Reason:      CloseReasonDuplicate,
+			Explanation: fmt.Sprintf("Identical to closed issue #%d", candidate.GetNumber()),
+			References:  []string{candidate.GetHTMLURL()},
+			DuplicateOf: candidate.GetNumber(),
+		}
+	}
+	return nil
+}
+
+// CloseIssue posts an explanatory comment and closes the issue
+func (h *Handler) CloseIssue(ctx context.Context, repo string, number int, suggestion *CloseSuggestion, closedBy string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+	owner, repoName := parts[0], parts[1]
+
+	data := CommentData{Repository: repo, IssueNumber: number, ClosedBy: closedBy, Suggestion: suggestion}
+	comment, err := h.commentFormatter().Render(CommentClose, data)
+	if err != nil {
+		// A broken repository template should not keep the issue open
+		h.logger.Warn("Failed to render close comment, using the built-in one", zap.String("repository", repo), zap.Error(err))
+		comment, _ = (*CommentFormatter)(nil).Render(CommentClose, data)
+	}
+
+	if _, _, err := h.githubClient().Issues.CreateComment(ctx, owner, repoName, number, &github.IssueComment{Body: github.String(comment)}); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("close_comment", apperrors.Classify(err))
+		return fmt.Errorf("failed to comment on issue: %w", err)
+	}
+
+	stateReason := "completed"
+	if suggestion.Reason == CloseReasonDuplicate {
+		stateReason = "not_planned"
+	}
+	if _, _, err := h.githubClient().Issues.Edit(ctx, owner, repoName, number, &github.IssueRequest{
+		State:       github.String("closed"),
+		StateReason: github.String(stateReason),
+	}); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("close_issue", apperrors.Classify(err))
+		return fmt.Errorf("failed to close issue: %w", err)
+	}
+
+	h.logger.Info("Closed issue from Slack suggestion",
+		zap.String("repository", repo),
+		zap.Int("issue_number", number),
+		zap.String("reason", suggestion.Reason),
+	)
+	return nil
+}
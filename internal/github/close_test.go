@@ -0,0 +1,137 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+)
+
+func TestClosingKeywordPattern(t *testing.T) {
+	pattern := closingKeywordPattern(42)
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Fixes #42", true},
+		{"closes: #42 and tidies up", true},
+		{"Resolved my-org/repo#42", true},
+		{"Refs #42", false},
+		{"Fixes #421", false},
+		{"prefix#42", false},
+	}
+
+	for _, tt := range tests {
+		if got := pattern.MatchString(tt.text); got != tt.want {
+			t.Errorf("MatchString(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+// newCloseTestHandler returns a handler whose GitHub client talks to server
+func newCloseTestHandler(server *httptest.Server) *Handler {
+	client := github.NewClient(nil)
+	baseURL, _ := url.Parse(server.URL + "/")
+	client.BaseURL = baseURL
+
+	return &Handler{
+		client:           client,
+		logger:           zap.NewNop(),
+		metrics:          &MockMetricsRecorder{},
+		closeSuggestions: true,
+	}
+}
+
+func TestDetectCloseSuggestion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		var issues []*github.Issue
+		switch {
+		case strings.Contains(query, "is:merged"):
+			issues = []*github.Issue{
+				{Number: github.Int(50), Title: github.String("Unrelated"), Body: github.String("See #7")},
+			}
+		case strings.Contains(query, "is:closed"):
+			issues = []*github.Issue{
+				{Number: github.Int(3), Title: github.String("crash on save "), HTMLURL: github.String("https://github.com/o/r/issues/3")},
+			}
+		}
+		json.NewEncoder(w).Encode(github.IssuesSearchResult{Total: github.Int(len(issues)), Issues: issues})
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	issue := &github.Issue{Number: github.Int(7), Title: github.String("Crash on save"), State: github.String("open")}
+
+	// A commit with a closing keyword wins over the duplicate search
+	commits := []*github.RepositoryCommit{{
+		HTMLURL: github.String("https://github.com/o/r/commit/abc"),
+		Commit:  &github.Commit{Message: github.String("Fix save crash\n\nFixes #7")},
+	}}
+	suggestion := handler.detectCloseSuggestion(context.Background(), "o", "r", issue, commits)
+	if suggestion == nil || suggestion.Reason != CloseReasonResolved {
+		t.Fatalf("Expected resolved suggestion, got %+v", suggestion)
+	}
+
+	// Without closing references the identical closed issue is reported
+	suggestion = handler.detectCloseSuggestion(context.Background(), "o", "r", issue, nil)
+	if suggestion == nil || suggestion.Reason != CloseReasonDuplicate || suggestion.DuplicateOf != 3 {
+		t.Fatalf("Expected duplicate of #3, got %+v", suggestion)
+	}
+
+	// Closed issues and disabled detection produce no suggestion
+	closed := &github.Issue{Number: github.Int(7), Title: github.String("Crash on save"), State: github.String("closed")}
+	if s := handler.detectCloseSuggestion(context.Background(), "o", "r", closed, commits); s != nil {
+		t.Errorf("Expected no suggestion for closed issue, got %+v", s)
+	}
+	handler.EnableCloseSuggestions(false)
+	if s := handler.detectCloseSuggestion(context.Background(), "o", "r", issue, commits); s != nil {
+		t.Errorf("Expected no suggestion when disabled, got %+v", s)
+	}
+}
+
+func TestCloseIssue(t *testing.T) {
+	var comment, state, stateReason string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/issues/7/comments":
+			var body github.IssueComment
+			json.NewDecoder(r.Body).Decode(&body)
+			comment = body.GetBody()
+			json.NewEncoder(w).Encode(body)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/o/r/issues/7":
+			var body github.IssueRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			state, stateReason = body.GetState(), body.GetStateReason()
+			json.NewEncoder(w).Encode(github.Issue{Number: github.Int(7)})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	err := handler.CloseIssue(context.Background(), "o/r", 7, &CloseSuggestion{
+		Reason:      CloseReasonDuplicate,
+		Explanation: "Identical to closed issue #3",
+		DuplicateOf: 3,
+	}, "alice")
+	if err != nil {
+		t.Fatalf("CloseIssue failed: %v", err)
+	}
+
+	if !strings.HasPrefix(comment, "Duplicate of #3.") || !strings.Contains(comment, "alice") {
+		t.Errorf("Unexpected closing comment %q", comment)
+	}
+	if state != "closed" || stateReason != "not_planned" {
+		t.Errorf("Expected closed/not_planned, got %s/%s", state, stateReason)
+	}
+}
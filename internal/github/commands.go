@@ -0,0 +1,178 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// CommandPrefix starts a command in an issue comment, e.g. "/notifyops fix"
+const CommandPrefix = "/notifyops"
+
+// Commands issue comments can run
+const (
+	CommandSummarize = "summarize" // Summarize the issue again and update its Slack message
+	CommandPriority  = "priority"  // Set the priority, e.g. "/notifyops priority high"
+	CommandFix       = "fix"       // Post a fix suggestion in the issue's Slack thread
+)
+
+// Comment reactions acknowledging commands
+const (
+	ReactionDone   = "+1"
+	ReactionFailed = "confused"
+)
+
+// CommandConfig controls commands in issue comments
+type CommandConfig struct {
+	Enabled    bool
+	Permission string // Minimum repository permission of the commenter: read, write or admin
+}
+
+// permissionRanks orders GitHub's repository permission levels
+var permissionRanks = map[string]int{"none": 0, "read": 1, "write": 2, "admin": 3}
+
+// ValidPermission reports whether permission is a level commands can require
+func ValidPermission(permission string) bool {
+	return permissionRanks[permission] > 0
+}
+
+// Command is a command from an issue comment
+type Command struct {
+	Name      string   // CommandSummarize, CommandPriority or CommandFix
+	Args      []string // Words after the command name
+	Author    string   // Login of the commenter
+	CommentID int64
+}
+
+// ParseCommand finds the first line of a comment that starts with
+// CommandPrefix. ok is false for comments without one; the returned command's
+// name may still be unknown.
+func ParseCommand(body string) (command Command, ok bool) {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], CommandPrefix) {
+			continue
+		}
+		if len(fields) > 1 {
+			command.Name = strings.ToLower(fields[1])
+			command.Args = fields[2:]
+		}
+		return command, true
+	}
+	return Command{}, false
+}
+
+// known reports whether the command exists and has the arguments it needs
+func (c Command) known() bool {
+	switch c.Name {
+	case CommandSummarize, CommandFix:
+		return true
+	case CommandPriority:
+		return len(c.Args) == 1
+	}
+	return false
+}
+
+// behavior is the pipeline behavior that runs the command
+func (c Command) behavior() Behavior {
+	switch c.Name {
+	case CommandSummarize:
+		return BehaviorResummarize
+	case CommandPriority:
+		return BehaviorUpdate
+	}
+	return BehaviorIgnore
+}
+
+// SetCommands configures commands in issue comments
+func (h *Handler) SetCommands(config CommandConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.commands = config
+}
+
+func (h *Handler) commandConfig() CommandConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.commands
+}
+
+// handleCommand turns a command comment into issue data for the pipeline.
+// Unknown commands and commenters without permission get a 😕 reaction.
+func (h *Handler) handleCommand(ctx context.Context, event *github.IssueCommentEvent, command Command) (*IssueData, string, error) {
+	repo := event.GetRepo().GetFullName()
+	command.Author = event.GetComment().GetUser().GetLogin()
+	command.CommentID = event.GetComment().GetID()
+	logger := h.logger.With(
+		zap.String("repository", repo),
+		zap.Int("issue_number", event.GetIssue().GetNumber()),
+		zap.String("command", command.Name),
+		zap.String("author", command.Author),
+	)
+
+	if !command.known() {
+		logger.Info("Unknown issue comment command", zap.Strings("args", command.Args))
+		h.reactToCommand(ctx, repo, command.CommentID, ReactionFailed)
+		return nil, "skipped", nil
+	}
+	allowed, err := h.commenterAllowed(ctx, repo, command.Author, h.commandConfig().Permission)
+	if err != nil {
+		return nil, "error", err
+	}
+	if !allowed {
+		logger.Warn("Commenter is not allowed to run commands")
+		h.reactToCommand(ctx, repo, command.CommentID, ReactionFailed)
+		return nil, "skipped", nil
+	}
+
+	issueData, err := h.enrich(ctx, event.GetIssue(), event.GetRepo(), event.GetAction(), "issue_comment")
+	if err != nil {
+		return nil, "error", err
+	}
+	issueData.Behavior = command.behavior()
+	issueData.Command = &command
+	logger.Info("Running issue comment command", zap.Strings("args", command.Args))
+	return issueData, "success", nil
+}
+
+// commenterAllowed reports whether user has at least permission in repo
+func (h *Handler) commenterAllowed(ctx context.Context, repo, user, permission string) (bool, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return false, fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	level, _, err := h.githubClient().Repositories.GetPermissionLevel(ctx, parts[0], parts[1], user)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("get_permission", apperrors.Classify(err))
+		return false, fmt.Errorf("failed to check commenter permission: %w", err)
+	}
+	return permissionRanks[level.GetPermission()] >= permissionRanks[permission], nil
+}
+
+// ReactToComment adds a reaction to an issue comment, e.g. to acknowledge a command
+func (h *Handler) ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	if _, _, err := h.githubClient().Reactions.CreateIssueCommentReaction(ctx, parts[0], parts[1], commentID, reaction); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("add_reaction", apperrors.Classify(err))
+		return fmt.Errorf("failed to react to comment: %w", err)
+	}
+	return nil
+}
+
+func (h *Handler) reactToCommand(ctx context.Context, repo string, commentID int64, reaction string) {
+	if err := h.ReactToComment(ctx, repo, commentID, reaction); err != nil {
+		h.logger.Warn("Failed to react to command", zap.String("repository", repo), zap.Error(err))
+	}
+}
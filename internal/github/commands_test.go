@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		body string
+		want Command
+		ok   bool
+	}{
+		{"/notifyops summarize", Command{Name: CommandSummarize, Args: []string{}}, true},
+		{"Thanks!\n/NotifyOps Priority high\n/notifyops fix", Command{Name: CommandPriority, Args: []string{"high"}}, true},
+		{"/notifyops", Command{}, true},
+		{"Run /notifyops fix please", Command{}, false},
+		{"/notifyopsfix", Command{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseCommand(tt.body)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCommand(%q) = %+v, %v, want %+v, %v", tt.body, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHandleCommand(t *testing.T) {
+	permissions := map[string]string{"maintainer": "write", "drive-by": "read"}
+	var reactions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/permission"):
+			user := strings.Split(r.URL.Path, "/")[5]
+			json.NewEncoder(w).Encode(github.RepositoryPermissionLevel{Permission: github.String(permissions[user])})
+		case strings.HasSuffix(r.URL.Path, "/reactions"):
+			var reaction struct{ Content string }
+			json.NewDecoder(r.Body).Decode(&reaction)
+			reactions = append(reactions, reaction.Content)
+			json.NewEncoder(w).Encode(github.Reaction{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	handler.SetCommands(CommandConfig{Enabled: true, Permission: "write"})
+	event := func(user, body string) *github.IssueCommentEvent {
+		return &github.IssueCommentEvent{
+			Action: github.String("created"),
+			Repo:   &github.Repository{FullName: github.String("o/r")},
+			Issue:  &github.Issue{Number: github.Int(7)},
+			Comment: &github.IssueComment{
+				ID:   github.Int64(99),
+				Body: github.String(body),
+				User: &github.User{Login: github.String(user)},
+			},
+		}
+	}
+
+	issueData, status, err := handler.handleCommand(context.Background(), event("drive-by", "/notifyops fix"), Command{Name: CommandFix})
+	if err != nil || status != "skipped" || issueData != nil {
+		t.Fatalf("Expected a read-only commenter to be skipped, got %v, %q, %v", issueData, status, err)
+	}
+	_, status, _ = handler.handleCommand(context.Background(), event("maintainer", "/notifyops priority"), Command{Name: CommandPriority})
+	if status != "skipped" {
+		t.Errorf("Expected a priority without an answer to be skipped, got %q", status)
+	}
+	if !reflect.DeepEqual(reactions, []string{ReactionFailed, ReactionFailed}) {
+		t.Errorf("Expected rejected commands to be reacted to, got %v", reactions)
+	}
+
+	issueData, status, err = handler.handleCommand(context.Background(), event("maintainer", "/notifyops summarize"), Command{Name: CommandSummarize})
+	if err != nil || status != "success" {
+		t.Fatalf("Expected the command to run, got %q, %v", status, err)
+	}
+	if issueData.Behavior != BehaviorResummarize || issueData.Command.Author != "maintainer" || issueData.Command.CommentID != 99 {
+		t.Errorf("Unexpected issue data for the command: %+v, %+v", issueData.Behavior, issueData.Command)
+	}
+}
@@ -0,0 +1,135 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// Comments the bot writes on issues, each rendered by the template of the
+// same name
+const (
+	CommentClose       = "close"        // Explains why an issue was closed from a Slack suggestion
+	CommentTicketState = "ticket_state" // Reports a state change of the issue's linked tracker ticket
+	CommentNeedsInfo   = "needs_info"   // Asks the reporter for details the bug report is missing
+)
+
+// defaultComments are the built-in comment templates. Repository templates
+// are parsed on top of them, so they only need to define the comments they
+// change.
+const defaultComments = `{{define "close"}}{{if eq .Suggestion.Reason "duplicate"}}Duplicate of #{{.Suggestion.DuplicateOf}}.
+
+{{end}}Closing as {{.Suggestion.Reason}}. {{.Suggestion.Explanation}}{{if .ClosedBy}}
+
+_Confirmed by {{.ClosedBy}} from Slack._{{end}}{{end}}
+{{define "ticket_state"}}The linked {{.Ticket.Tracker}} ticket [{{.Ticket.Key}}]({{.Ticket.URL}}) moved to **{{.Ticket.State}}**.{{end}}
+{{define "needs_info"}}Thanks for the report{{if .Reporter}}, @{{.Reporter}}{{end}}! To help us reproduce it, could you add the following?
+
+{{range .MissingInfo}}- {{.}}
+{{end}}
+We'll take another look once you update the issue or reply here.{{end}}`
+
+// CommentTemplate sets the Markdown template of the bot's comments in
+// matching repositories. The file defines a template for each comment it
+// changes, e.g. {{define "close"}}...{{end}}.
+type CommentTemplate struct {
+	Repositories []string `mapstructure:"repositories" json:"repositories,omitempty"` // Glob patterns, e.g. "my-org/*"; empty matches every repository
+	Path         string   `mapstructure:"path" json:"path"`
+}
+
+// CommentData is the data available to comment templates
+type CommentData struct {
+	Repository  string
+	IssueNumber int
+	ClosedBy    string           // Slack user who confirmed a close
+	Suggestion  *CloseSuggestion // Why the issue is being closed, for close comments
+	Ticket      *TicketUpdate    // The linked ticket's new state, for ticket_state comments
+	Reporter    string           // GitHub login of the issue's author, for needs_info comments
+	MissingInfo []string         // Details the report lacks, for needs_info comments
+}
+
+// commentFuncs are the helper functions available to comment templates
+var commentFuncs = template.FuncMap{
+	// details folds text into a collapsible block titled summary
+	"details": func(summary, text string) string {
+		return fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s\n\n</details>", summary, strings.TrimSpace(text))
+	},
+	// quote prefixes every line of text with "> "
+	"quote": func(text string) string {
+		return "> " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n> ")
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+var defaultCommentTemplate = template.Must(template.New("comments").Funcs(commentFuncs).Parse(defaultComments))
+
+type commentRule struct {
+	repositories []string
+	tmpl         *template.Template
+}
+
+// CommentFormatter renders the bot's comments with the first template whose
+// repositories match, or the built-in one
+type CommentFormatter struct {
+	rules []commentRule
+}
+
+// NewCommentFormatter reads and parses the templates
+func NewCommentFormatter(templates []CommentTemplate) (*CommentFormatter, error) {
+	f := &CommentFormatter{}
+	for i, t := range templates {
+		for _, pattern := range t.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("comment template %d: invalid repository pattern %q: %w", i, pattern, err)
+			}
+		}
+		data, err := os.ReadFile(t.Path)
+		if err != nil {
+			return nil, fmt.Errorf("comment template %d: %w", i, err)
+		}
+		tmpl, err := template.Must(defaultCommentTemplate.Clone()).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("comment template %d: failed to parse %s: %w", i, t.Path, err)
+		}
+		f.rules = append(f.rules, commentRule{repositories: t.Repositories, tmpl: tmpl})
+	}
+	return f, nil
+}
+
+// Render writes the comment named kind for data.Repository. A nil formatter
+// uses the built-in templates.
+func (f *CommentFormatter) Render(kind string, data CommentData) (string, error) {
+	tmpl := defaultCommentTemplate
+	if f != nil {
+		for _, rule := range f.rules {
+			if rule.matches(data.Repository) {
+				tmpl = rule.tmpl
+				break
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, kind, data); err != nil {
+		return "", fmt.Errorf("failed to render %s comment: %w", kind, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (rule commentRule) matches(repository string) bool {
+	if len(rule.repositories) == 0 {
+		return true
+	}
+	repository = strings.ToLower(repository)
+	for _, pattern := range rule.repositories {
+		if ok, _ := path.Match(strings.ToLower(pattern), repository); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommentFormatterDefault(t *testing.T) {
+	data := CommentData{
+		Repository:  "o/r",
+		IssueNumber: 7,
+		ClosedBy:    "alice",
+		Suggestion:  &CloseSuggestion{Reason: CloseReasonDuplicate, Explanation: "Identical to closed issue #3", DuplicateOf: 3},
+	}
+	comment, err := (*CommentFormatter)(nil).Render(CommentClose, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Duplicate of #3.\n\nClosing as duplicate. Identical to closed issue #3\n\n_Confirmed by alice from Slack._"
+	if comment != want {
+		t.Errorf("Expected the built-in comment %q, got %q", want, comment)
+	}
+}
+
+func TestCommentFormatterRepositoryTemplates(t *testing.T) {
+	dir := t.TempDir()
+	friendly := filepath.Join(dir, "friendly.md.tmpl")
+	os.WriteFile(friendly, []byte(`{{define "close"}}Thanks for the report! This looks {{.Suggestion.Reason}}.
+
+{{details "Why" (quote .Suggestion.Explanation)}}{{end}}`), 0o644)
+	other := filepath.Join(dir, "other.md.tmpl")
+	os.WriteFile(other, []byte(`{{/* Keeps the built-in comments */}}`), 0o644)
+
+	formatter, err := NewCommentFormatter([]CommentTemplate{
+		{Repositories: []string{"acme/*"}, Path: friendly},
+		{Path: other},
+	})
+	if err != nil {
+		t.Fatalf("NewCommentFormatter: %v", err)
+	}
+
+	suggestion := &CloseSuggestion{Reason: CloseReasonResolved, Explanation: "Fixed by #12\nand released"}
+	comment, err := formatter.Render(CommentClose, CommentData{Repository: "Acme/API", Suggestion: suggestion})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Thanks for the report! This looks resolved.\n\n<details>\n<summary>Why</summary>\n\n> Fixed by #12\n> and released\n\n</details>"
+	if comment != want {
+		t.Errorf("Expected the repository's comment %q, got %q", want, comment)
+	}
+
+	// A template that does not define a comment falls back to the built-in one
+	comment, _ = formatter.Render(CommentClose, CommentData{Repository: "other/repo", Suggestion: suggestion})
+	if comment != "Closing as resolved. Fixed by #12\nand released" {
+		t.Errorf("Expected the built-in comment, got %q", comment)
+	}
+
+	for name, templates := range map[string][]CommentTemplate{
+		"invalid pattern": {{Repositories: []string{"acme/[a"}, Path: friendly}},
+		"missing file":    {{Path: filepath.Join(dir, "missing.tmpl")}},
+	} {
+		if _, err := NewCommentFormatter(templates); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
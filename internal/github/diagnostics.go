@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/diagnostics"
+)
+
+// Diagnose checks that the access token is accepted and, for classic tokens,
+// that it has the scopes to read, comment on, label and close issues
+func (h *Handler) Diagnose(ctx context.Context) []diagnostics.Check {
+	client := h.githubClient()
+	user, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		err = classifyError(err)
+		switch {
+		case errors.Is(err, apperrors.ErrAuth) && resp != nil && resp.StatusCode == http.StatusForbidden:
+			// GitHub App installation tokens cannot read the authenticated user
+			return []diagnostics.Check{diagnostics.Warning("github_auth",
+				fmt.Sprintf("Could not identify the token's user (%v), so its permissions were not checked", err))}
+		case errors.Is(err, apperrors.ErrAuth):
+			return []diagnostics.Check{diagnostics.Error("github_auth",
+				fmt.Sprintf("GitHub rejected GITHUB_ACCESS_TOKEN (%v); check that it has not expired or been revoked", err))}
+		}
+		return []diagnostics.Check{diagnostics.Error("github_auth",
+			fmt.Sprintf("Could not reach the GitHub API at %s: %v", client.BaseURL, err))}
+	}
+	checks := []diagnostics.Check{diagnostics.OK("github_auth", "Authenticated as "+user.GetLogin())}
+
+	// Only classic tokens list their scopes
+	header := resp.Header.Values("X-OAuth-Scopes")
+	if len(header) == 0 {
+		return append(checks, diagnostics.OK("github_scopes",
+			"Fine-grained tokens do not list their permissions; they need read and write access to Issues and read access to Contents"))
+	}
+	var scopes []string
+	for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	switch {
+	case len(diagnostics.MissingScopes(scopes, []string{"repo"})) == 0:
+		checks = append(checks, diagnostics.OK("github_scopes", "The token has the repo scope"))
+	case len(diagnostics.MissingScopes(scopes, []string{"public_repo"})) == 0:
+		checks = append(checks, diagnostics.Warning("github_scopes",
+			"The token only has the public_repo scope; issues in private repositories cannot be read"))
+	default:
+		checks = append(checks, diagnostics.Error("github_scopes",
+			fmt.Sprintf("The token has %q but needs the repo scope (public_repo for public repositories only) to read, comment on, label and close issues", strings.Join(scopes, ", "))))
+	}
+	return checks
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/diagnostics"
+)
+
+func newDiagnosticsTestHandler(t *testing.T, handler http.HandlerFunc) *Handler {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return NewHandlerWithClient(client, "secret", zap.NewNop(), &MockMetricsRecorder{})
+}
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		scopes     []string // X-OAuth-Scopes headers, nil for a fine-grained token
+		wantAuth   string
+		wantScopes string // Empty when the scopes are not checked
+	}{
+		{"classic token with repo", http.StatusOK, []string{"repo, read:org"}, diagnostics.StatusOK, diagnostics.StatusOK},
+		{"classic token with public_repo", http.StatusOK, []string{"public_repo"}, diagnostics.StatusOK, diagnostics.StatusWarning},
+		{"classic token without repo", http.StatusOK, []string{""}, diagnostics.StatusOK, diagnostics.StatusError},
+		{"fine-grained token", http.StatusOK, nil, diagnostics.StatusOK, diagnostics.StatusOK},
+		{"revoked token", http.StatusUnauthorized, nil, diagnostics.StatusError, ""},
+		{"app installation token", http.StatusForbidden, nil, diagnostics.StatusWarning, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newDiagnosticsTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/user", r.URL.Path)
+				for _, scopes := range tt.scopes {
+					w.Header().Add("X-OAuth-Scopes", scopes)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"login": "notifyops-bot", "message": "nope"}`))
+			})
+
+			checks := handler.Diagnose(context.Background())
+			assert.Equal(t, "github_auth", checks[0].Name)
+			assert.Equal(t, tt.wantAuth, checks[0].Status, checks[0].Message)
+			if tt.wantScopes == "" {
+				assert.Len(t, checks, 1)
+				return
+			}
+			assert.Len(t, checks, 2)
+			assert.Equal(t, tt.wantScopes, checks[1].Status, checks[1].Message)
+		})
+	}
+}
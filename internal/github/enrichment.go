@@ -0,0 +1,280 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// Enrichment stages. Each adds one kind of data to an issue before it is
+// summarized.
+const (
+	EnrichComments        = "comments"         // The issue's comments
+	EnrichCommits         = "commits"          // Commits that reference the issue
+	EnrichFiles           = "files"            // Files changed by the first of those commits
+	EnrichCloseSuggestion = "close_suggestion" // Whether the issue looks resolved or duplicated
+	EnrichRegressions     = "regressions"      // Releases that may have introduced the bug
+	EnrichAttachments     = "attachments"      // Log files linked from the issue
+	EnrichCIFailure       = "ci_failure"       // The failed run of an issue opened by CI
+	EnrichStack           = "stack"            // Languages and frameworks of the repository
+)
+
+// DefaultEnrichStages is the order stages run in for repositories no rule
+// matches
+var DefaultEnrichStages = []string{
+	EnrichComments,
+	EnrichCommits,
+	EnrichFiles,
+	EnrichCloseSuggestion,
+	EnrichRegressions,
+	EnrichAttachments,
+	EnrichCIFailure,
+	EnrichStack,
+}
+
+// enrichStage fetches one kind of data into an issue being enriched
+type enrichStage struct {
+	needsRepository bool // Skipped for issues whose repository is unknown
+	needs           string
+	run             func(h *Handler, ctx context.Context, e *enrichment) error
+}
+
+// enrichStages by name. A stage's needs, if any, must run before it for the
+// stage to find anything.
+var enrichStages = map[string]enrichStage{
+	EnrichComments:        {needsRepository: true, run: (*Handler).enrichComments},
+	EnrichCommits:         {needsRepository: true, run: (*Handler).enrichCommits},
+	EnrichFiles:           {needsRepository: true, needs: EnrichCommits, run: (*Handler).enrichFiles},
+	EnrichCloseSuggestion: {needsRepository: true, run: (*Handler).enrichCloseSuggestion},
+	EnrichRegressions:     {needsRepository: true, run: (*Handler).enrichRegressions},
+	EnrichAttachments:     {run: (*Handler).enrichAttachments},
+	EnrichCIFailure:       {run: (*Handler).enrichCIFailure},
+	EnrichStack:           {needsRepository: true, run: (*Handler).enrichStack},
+}
+
+// EnrichmentRule sets the stages that run, and their order, for matching
+// repositories. Rules are evaluated in order and the first match wins.
+type EnrichmentRule struct {
+	Repositories []string `mapstructure:"repositories" json:"repositories"` // Glob patterns, e.g. "my-org/docs-*"
+	Stages       []string `mapstructure:"stages" json:"stages"`             // In order; stages not listed are skipped
+}
+
+// matches reports whether the rule applies to a repository
+func (r EnrichmentRule) matches(repository string) bool {
+	repository = strings.ToLower(repository)
+	for _, pattern := range r.Repositories {
+		if ok, _ := path.Match(strings.ToLower(pattern), repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EnrichmentConfig orders the enrichment stages per repository and bounds
+// how long each may take. Rules and timeouts are read from the
+// enrichment.rules and enrichment.timeouts keys of the config file.
+type EnrichmentConfig struct {
+	Rules        []EnrichmentRule
+	StageTimeout time.Duration            // Default timeout of each stage, 0 for none beyond the enrichment timeout
+	Timeouts     map[string]time.Duration // Timeouts of individual stages, by name
+}
+
+// Validate checks the rules' patterns and stages, and the stage timeouts
+func (c EnrichmentConfig) Validate() error {
+	for i, rule := range c.Rules {
+		if len(rule.Repositories) == 0 {
+			return fmt.Errorf("enrichment rule %d has no repositories", i)
+		}
+		for _, pattern := range rule.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("enrichment rule %d: invalid repository pattern %q: %w", i, pattern, err)
+			}
+		}
+		seen := make(map[string]bool, len(rule.Stages))
+		for _, name := range rule.Stages {
+			stage, ok := enrichStages[name]
+			if !ok {
+				return fmt.Errorf("enrichment rule %d: unknown stage %q", i, name)
+			}
+			if seen[name] {
+				return fmt.Errorf("enrichment rule %d: stage %s is listed twice", i, name)
+			}
+			if stage.needs != "" && !seen[stage.needs] {
+				return fmt.Errorf("enrichment rule %d: stage %s needs %s before it", i, name, stage.needs)
+			}
+			seen[name] = true
+		}
+	}
+	if c.StageTimeout < 0 {
+		return fmt.Errorf("enrichment stage timeout must not be negative")
+	}
+	for name, timeout := range c.Timeouts {
+		if _, ok := enrichStages[name]; !ok {
+			return fmt.Errorf("enrichment timeout for unknown stage %q", name)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("enrichment timeout of %s must be positive", name)
+		}
+	}
+	return nil
+}
+
+// stages returns the stages to run for a repository, in order
+func (c EnrichmentConfig) stages(repository string) []string {
+	for _, rule := range c.Rules {
+		if rule.matches(repository) {
+			return rule.Stages
+		}
+	}
+	return DefaultEnrichStages
+}
+
+// timeout returns how long a stage may take, 0 for no limit of its own
+func (c EnrichmentConfig) timeout(stage string) time.Duration {
+	if timeout, ok := c.Timeouts[stage]; ok {
+		return timeout
+	}
+	return c.StageTimeout
+}
+
+// SetEnrichment sets which enrichment stages run for each repository, in
+// which order, and how long each may take
+func (h *Handler) SetEnrichment(config EnrichmentConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.enrichment = config
+}
+
+func (h *Handler) enrichmentConfig() EnrichmentConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.enrichment
+}
+
+// enrichment is an issue being enriched. Each stage adds to data.
+type enrichment struct {
+	issue       *github.Issue
+	owner, repo string // Empty when the repository is unknown
+	data        *IssueData
+}
+
+// runEnrichStages runs the repository's enrichment stages in order. A stage
+// that fails, times out or panics is logged and recorded, and the issue is
+// enriched without its data.
+func (h *Handler) runEnrichStages(ctx context.Context, e *enrichment) {
+	config := h.enrichmentConfig()
+	for _, name := range config.stages(e.data.Repository.GetFullName()) {
+		stage := enrichStages[name]
+		if stage.needsRepository && (e.owner == "" || e.repo == "") {
+			h.logger.Info("Skipping enrichment stage due to missing repository information", zap.String("stage", name))
+			continue
+		}
+		h.runEnrichStage(ctx, name, stage, config.timeout(name), e)
+	}
+}
+
+// runEnrichStage runs one stage within its timeout
+func (h *Handler) runEnrichStage(ctx context.Context, name string, stage enrichStage, timeout time.Duration, e *enrichment) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return stage.run(h, ctx, e)
+	}()
+
+	status := "success"
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		status = "timeout"
+	case err != nil:
+		status = "error"
+	}
+	h.metrics.RecordEnrichmentStage(name, status, time.Since(start))
+	if status != "success" {
+		h.logger.Warn("Enrichment stage failed, continuing without its data",
+			zap.String("stage", name),
+			zap.String("repository", e.data.Repository.GetFullName()),
+			zap.Int("issue_number", e.issue.GetNumber()),
+			zap.String("status", status),
+			zap.Error(err))
+	}
+}
+
+func (h *Handler) enrichComments(ctx context.Context, e *enrichment) error {
+	comments, err := h.fetchIssueComments(ctx, e.owner, e.repo, e.issue.GetNumber())
+	if err != nil {
+		h.metrics.RecordGitHubAPIError("fetch_comments", apperrors.Classify(err))
+		return fmt.Errorf("failed to fetch issue comments: %w", err)
+	}
+	e.data.Comments = comments
+	return nil
+}
+
+func (h *Handler) enrichCommits(ctx context.Context, e *enrichment) error {
+	commits, err := h.fetchRelatedCommits(ctx, e.owner, e.repo, e.issue.GetNumber())
+	if err != nil {
+		h.metrics.RecordGitHubAPIError("fetch_commits", apperrors.Classify(err))
+		return fmt.Errorf("failed to fetch related commits: %w", err)
+	}
+	e.data.Commits = commits
+	return nil
+}
+
+func (h *Handler) enrichFiles(ctx context.Context, e *enrichment) error {
+	if len(e.data.Commits) == 0 {
+		return nil
+	}
+	files, err := h.fetchCommitFiles(ctx, e.owner, e.repo, e.data.Commits[0].GetSHA())
+	if err != nil {
+		h.metrics.RecordGitHubAPIError("fetch_files", apperrors.Classify(err))
+		return fmt.Errorf("failed to fetch commit files: %w", err)
+	}
+	e.data.Files = files
+	return nil
+}
+
+func (h *Handler) enrichCloseSuggestion(ctx context.Context, e *enrichment) error {
+	e.data.CloseSuggestion = h.detectCloseSuggestion(ctx, e.owner, e.repo, e.issue, e.data.Commits)
+	return nil
+}
+
+func (h *Handler) enrichRegressions(ctx context.Context, e *enrichment) error {
+	e.data.RegressionHints = h.detectRegressions(ctx, e.owner, e.repo, e.issue)
+	return nil
+}
+
+func (h *Handler) enrichAttachments(ctx context.Context, e *enrichment) error {
+	e.data.Attachments = h.fetchAttachments(ctx, e.issue.GetBody())
+	return nil
+}
+
+func (h *Handler) enrichCIFailure(ctx context.Context, e *enrichment) error {
+	e.data.CIFailure = h.digestCIFailure(ctx, e.issue)
+	return nil
+}
+
+func (h *Handler) enrichStack(ctx context.Context, e *enrichment) error {
+	stack, err := h.detectStack(ctx, e.owner, e.repo)
+	if err != nil {
+		return err
+	}
+	e.data.Stack = stack
+	return nil
+}
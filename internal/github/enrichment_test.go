@@ -0,0 +1,115 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+)
+
+// stageRecorder records the outcome of each enrichment stage
+type stageRecorder struct {
+	MockMetricsRecorder
+	stages []string
+}
+
+func (r *stageRecorder) RecordEnrichmentStage(stage, status string, duration time.Duration) {
+	r.stages = append(r.stages, stage+" "+status)
+}
+
+func (r *stageRecorder) RecordGitHubAPIError(operation, errorType string) {}
+
+func TestEnrichmentConfigValidate(t *testing.T) {
+	valid := EnrichmentConfig{
+		Rules:    []EnrichmentRule{{Repositories: []string{"acme/docs-*"}, Stages: []string{EnrichComments, EnrichCommits, EnrichFiles}}},
+		Timeouts: map[string]time.Duration{EnrichCommits: time.Second},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid config, got %v", err)
+	}
+	for _, config := range []EnrichmentConfig{
+		{Rules: []EnrichmentRule{{Stages: []string{EnrichComments}}}},
+		{Rules: []EnrichmentRule{{Repositories: []string{"acme/[docs"}}}},
+		{Rules: []EnrichmentRule{{Repositories: []string{"acme/*"}, Stages: []string{"timeline"}}}},
+		{Rules: []EnrichmentRule{{Repositories: []string{"acme/*"}, Stages: []string{EnrichComments, EnrichComments}}}},
+		{Rules: []EnrichmentRule{{Repositories: []string{"acme/*"}, Stages: []string{EnrichFiles, EnrichCommits}}}},
+		{Timeouts: map[string]time.Duration{"timeline": time.Second}},
+		{Timeouts: map[string]time.Duration{EnrichComments: 0}},
+		{StageTimeout: -time.Second},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
+
+func TestEnrichmentStagesPerRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/o/r/issues/7/comments" {
+			json.NewEncoder(w).Encode([]*github.IssueComment{{Body: github.String("Same here")}})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	recorder := &stageRecorder{}
+	handler := newCloseTestHandler(server)
+	handler.metrics = recorder
+	handler.SetEnrichment(EnrichmentConfig{Rules: []EnrichmentRule{{Repositories: []string{"O/*"}, Stages: []string{EnrichAttachments, EnrichComments}}}})
+
+	issue := &github.Issue{Number: github.Int(7), Body: github.String("It crashes")}
+	repository := &github.Repository{FullName: github.String("o/r"), Name: github.String("r"), Owner: &github.User{Login: github.String("o")}}
+	issueData, err := handler.enrichIssueData(context.Background(), issue, repository, "opened", "issues")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"attachments success", "comments success"}; !reflect.DeepEqual(recorder.stages, want) {
+		t.Errorf("Expected only the rule's stages in its order, got %v", recorder.stages)
+	}
+	if len(issueData.Comments) != 1 || issueData.Activity == nil {
+		t.Errorf("Expected the comment fetched and the activity computed, got %d comments", len(issueData.Comments))
+	}
+
+	// Other repositories run every stage; failed ones are skipped
+	recorder.stages = nil
+	repository = &github.Repository{FullName: github.String("x/y"), Name: github.String("y"), Owner: &github.User{Login: github.String("x")}}
+	if _, err := handler.enrichIssueData(context.Background(), issue, repository, "opened", "issues"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recorder.stages) != len(DefaultEnrichStages) || recorder.stages[0] != "comments error" {
+		t.Errorf("Expected every stage recorded, got %v", recorder.stages)
+	}
+}
+
+func TestRunEnrichStageIsolation(t *testing.T) {
+	recorder := &stageRecorder{}
+	handler := &Handler{logger: zap.NewNop(), metrics: recorder}
+	e := &enrichment{issue: &github.Issue{Number: github.Int(7)}, data: &IssueData{}}
+
+	handler.runEnrichStage(context.Background(), "panics", enrichStage{run: func(h *Handler, ctx context.Context, e *enrichment) error {
+		panic("boom")
+	}}, 0, e)
+	handler.runEnrichStage(context.Background(), "slow", enrichStage{run: func(h *Handler, ctx context.Context, e *enrichment) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}, time.Millisecond, e)
+	handler.runEnrichStage(context.Background(), "works", enrichStage{run: func(h *Handler, ctx context.Context, e *enrichment) error {
+		e.data.PromptStyle = "ok"
+		return nil
+	}}, time.Second, e)
+
+	if want := "panics error,slow timeout,works success"; strings.Join(recorder.stages, ",") != want {
+		t.Errorf("Expected %s, got %v", want, recorder.stages)
+	}
+	if e.data.PromptStyle != "ok" {
+		t.Error("Expected the stage after the failures to run")
+	}
+}
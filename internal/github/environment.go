@@ -0,0 +1,190 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Environment is where a reporter says an issue happens. Empty fields were
+// not mentioned.
+type Environment struct {
+	OS        string `json:"os,omitempty"`
+	Browser   string `json:"browser,omitempty"`
+	Version   string `json:"version,omitempty"` // Version of the project the issue is about
+	GoVersion string `json:"go_version,omitempty"`
+}
+
+// maxEnvironmentValue bounds an extracted value, so a pasted log under an
+// "OS:" line does not end up in the field
+const maxEnvironmentValue = 80
+
+var (
+	// A "Key: value" line, allowing list markers and bold keys, e.g.
+	// "- **OS:** Windows 11"
+	environmentLinePattern = regexp.MustCompile(`^\s*(?:[-*+]\s+)?(?:\*\*|__)?([A-Za-z][A-Za-z .]{0,30}?)(?:\*\*|__)?\s*:\s*(?:\*\*|__)?\s*(.+?)\s*$`)
+
+	goVersionPattern = regexp.MustCompile(`(?i)\bgo\s?(1\.\d+(?:\.\d+)?(?:rc\d+)?)\b`)
+	versionPattern   = regexp.MustCompile(`\bv(\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.]+)?)\b`)
+	osPattern        = regexp.MustCompile(`(?i)\b(windows(?: server)?(?: (?:\d+|xp|vista))?|mac ?os(?: ?x)?(?: \d+(?:\.\d+)*)?|os x|ubuntu(?: \d+\.\d+)?|debian(?: \d+)?|fedora(?: \d+)?|alpine(?: \d+\.\d+)?|centos(?: \d+)?|linux|freebsd|android(?: \d+)?|ios \d+(?:\.\d+)*)\b`)
+	browserPattern   = regexp.MustCompile(`(?i)\b(chrome|chromium|firefox|safari|microsoft edge|opera)(?:[ /](\d+(?:\.\d+)*))?\b`)
+)
+
+// ExtractEnvironment finds the OS, browser, project version and Go version
+// an issue was reported on: first from issue form fields and "Key: value"
+// lines, then from mentions in the body, e.g. "go1.21.3" or "v2.3.1". Code
+// blocks are only searched for `go version` output.
+func ExtractEnvironment(body string, fields []FormField) Environment {
+	var env Environment
+	for _, field := range fields {
+		env.set(field.Label, field.Value)
+	}
+
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	var prose []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			if env.GoVersion == "" && strings.Contains(line, "go version") {
+				env.GoVersion = matchGroup(goVersionPattern, line)
+			}
+			continue
+		}
+		prose = append(prose, line)
+		if match := environmentLinePattern.FindStringSubmatch(line); match != nil {
+			env.set(match[1], match[2])
+		}
+	}
+
+	text := strings.Join(prose, "\n")
+	if env.GoVersion == "" {
+		env.GoVersion = matchGroup(goVersionPattern, text)
+	}
+	if env.Version == "" {
+		env.Version = matchGroup(versionPattern, text)
+	}
+	if env.OS == "" {
+		env.OS = matchGroup(osPattern, text)
+	}
+	if env.Browser == "" {
+		if match := browserPattern.FindStringSubmatch(text); match != nil {
+			env.Browser = strings.TrimSpace(match[1] + " " + match[2])
+		}
+	}
+	return env
+}
+
+// set fills the field a label names, if it is not set yet. Labels are
+// matched loosely, e.g. "Operating System", "OS version" or "Package version".
+func (e *Environment) set(label, value string) {
+	value = environmentValue(value)
+	if value == "" {
+		return
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	var field *string
+	switch {
+	case label == "go" || strings.HasPrefix(label, "go ") || strings.Contains(label, "golang"):
+		field = &e.GoVersion
+	case label == "os" || strings.HasPrefix(label, "os ") || strings.Contains(label, "operating system") || label == "platform":
+		field = &e.OS
+	case strings.Contains(label, "browser"):
+		field = &e.Browser
+	case label == "version" || strings.HasSuffix(label, " version") || label == "release":
+		field = &e.Version
+	default:
+		return
+	}
+	if *field != "" {
+		return
+	}
+	if field == &e.GoVersion {
+		// Keep the version of "go version go1.21.3 linux/amd64"
+		if version := matchGroup(goVersionPattern, value); version != "" {
+			value = version
+		}
+	}
+	if field == &e.Version {
+		value = strings.TrimPrefix(strings.TrimPrefix(value, "v"), "V")
+	}
+	*field = value
+}
+
+// environmentValue is the first line of a field's value, without Markdown
+// emphasis, or empty for unanswered and overly long values
+func environmentValue(value string) string {
+	value, _, _ = strings.Cut(strings.TrimSpace(value), "\n")
+	if value = strings.TrimSpace(value); value == "_No response_" {
+		return ""
+	}
+	value = strings.TrimSpace(strings.Trim(value, "*_`"))
+	if strings.EqualFold(value, "n/a") || len(value) > maxEnvironmentValue {
+		return ""
+	}
+	return value
+}
+
+func matchGroup(pattern *regexp.Regexp, text string) string {
+	if match := pattern.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// IsZero reports whether no environment was found
+func (e Environment) IsZero() bool {
+	return e == Environment{}
+}
+
+// Fields returns the environment as labeled fields, for display
+func (e Environment) Fields() []FormField {
+	var fields []FormField
+	for _, field := range []FormField{
+		{Label: "OS", Value: e.OS},
+		{Label: "Browser", Value: e.Browser},
+		{Label: "Version", Value: e.Version},
+		{Label: "Go", Value: e.GoVersion},
+	} {
+		if field.Value != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Matches reports whether the environment satisfies every non-empty field of
+// filter. OS and browser match words in the value ignoring case, so "windows"
+// matches "Windows 11"; versions match whole leading components, so "2.3"
+// matches "2.3.1" but not "2.30".
+func (e Environment) Matches(filter Environment) bool {
+	return containsWords(e.OS, filter.OS) &&
+		containsWords(e.Browser, filter.Browser) &&
+		versionMatches(e.Version, filter.Version) &&
+		versionMatches(e.GoVersion, filter.GoVersion)
+}
+
+func containsWords(value, words string) bool {
+	value = strings.ToLower(value)
+	for _, word := range strings.Fields(strings.ToLower(words)) {
+		if !strings.Contains(value, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func versionMatches(version, prefix string) bool {
+	prefix = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(prefix)), "v")
+	if prefix == "" {
+		return true
+	}
+	version = strings.TrimPrefix(strings.ToLower(version), "v")
+	if !strings.HasPrefix(version, prefix) {
+		return false
+	}
+	rest := version[len(prefix):]
+	return rest == "" || rest[0] == '.' || rest[0] == '-'
+}
@@ -0,0 +1,76 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractEnvironment(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Environment
+	}{
+		{
+			name: "issue form",
+			body: strings.Join([]string{
+				"### Package version", "", "v2.3.1", "",
+				"### Operating System", "", "Windows 11", "",
+				"### Browser", "", "_No response_", "",
+				"### Go version", "", "go version go1.21.3 windows/amd64",
+			}, "\n"),
+			want: Environment{OS: "Windows 11", Version: "2.3.1", GoVersion: "1.21.3"},
+		},
+		{
+			name: "key value lines",
+			body: "It crashes on save.\n\n- **OS:** macOS 14.2\n- **Browser**: Firefox 121\n- Version: 2.4.0-beta.1",
+			want: Environment{OS: "macOS 14.2", Browser: "Firefox 121", Version: "2.4.0-beta.1"},
+		},
+		{
+			name: "mentions",
+			body: "Since upgrading to v2.3 the export fails on Ubuntu 22.04 in Chrome/120.0 with go1.22.\n\n```\nsee v9.9.9 on windows\n```",
+			want: Environment{OS: "Ubuntu 22.04", Browser: "Chrome 120.0", Version: "2.3", GoVersion: "1.22"},
+		},
+		{
+			name: "go version in logs",
+			body: "Output:\n```\n$ go version\ngo version go1.20.5 linux/amd64\n```",
+			want: Environment{GoVersion: "1.20.5"},
+		},
+		{
+			name: "nothing",
+			body: "The button is misaligned in an edge case.\n\nNote: see the screenshot",
+			want: Environment{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractEnvironment(tt.body, ParseIssueForm(tt.body)); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEnvironmentMatches(t *testing.T) {
+	env := Environment{OS: "Windows 11", Browser: "Chrome 120", Version: "2.3.1", GoVersion: "1.21.3"}
+	tests := []struct {
+		filter Environment
+		want   bool
+	}{
+		{Environment{}, true},
+		{Environment{OS: "windows", Version: "v2.3"}, true},
+		{Environment{OS: "windows 11", GoVersion: "1.21"}, true},
+		{Environment{OS: "linux"}, false},
+		{Environment{Version: "2.30"}, false},
+		{Environment{Version: "2.3.1.4"}, false},
+		{Environment{Browser: "firefox"}, false},
+	}
+	for _, tt := range tests {
+		if got := env.Matches(tt.filter); got != tt.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+	if (Environment{}).Matches(Environment{OS: "windows"}) {
+		t.Error("Expected an unknown OS not to match")
+	}
+}
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// classifyError maps go-github errors onto the shared error classes
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return apperrors.WrapRetryAfter(apperrors.ErrRateLimited, err, time.Until(rateErr.Rate.Reset.Time))
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return apperrors.WrapRetryAfter(apperrors.ErrRateLimited, err, abuseErr.GetRetryAfter())
+	}
+
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil {
+		switch code := respErr.Response.StatusCode; {
+		case code == http.StatusUnauthorized, code == http.StatusForbidden:
+			return apperrors.Wrap(apperrors.ErrAuth, err)
+		case code == http.StatusNotFound, code == http.StatusGone:
+			return apperrors.Wrap(apperrors.ErrNotFound, err)
+		case code >= http.StatusInternalServerError:
+			return apperrors.Wrap(apperrors.ErrUnavailable, err)
+		}
+	}
+
+	return err
+}
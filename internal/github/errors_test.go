@@ -0,0 +1,43 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+func TestClassifyError(t *testing.T) {
+	responseErr := func(code int) error {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: code, Request: &http.Request{}}}
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"unauthorized", responseErr(http.StatusUnauthorized), apperrors.ErrAuth},
+		{"forbidden", responseErr(http.StatusForbidden), apperrors.ErrAuth},
+		{"not found", responseErr(http.StatusNotFound), apperrors.ErrNotFound},
+		{"server error", responseErr(http.StatusBadGateway), apperrors.ErrUnavailable},
+		{"rate limit", &github.RateLimitError{Response: &http.Response{Request: &http.Request{}}}, apperrors.ErrRateLimited},
+		{"abuse rate limit", &github.AbuseRateLimitError{Response: &http.Response{Request: &http.Request{}}}, apperrors.ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+			assert.True(t, errors.Is(err, tt.expected), "expected %v, got %v", tt.expected, err)
+			assert.True(t, errors.Is(err, tt.err), "original error should be preserved")
+		})
+	}
+
+	assert.Nil(t, classifyError(nil))
+	plain := errors.New("plain")
+	assert.Equal(t, plain, classifyError(plain))
+}
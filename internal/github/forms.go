@@ -0,0 +1,69 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FormField is one "### Label" section of an issue created from an issue form
+type FormField struct {
+	Label string
+	Value string
+}
+
+var formHeadingPattern = regexp.MustCompile(`^###\s+(.+?)\s*$`)
+
+// ParseIssueForm extracts the fields of an issue created from a GitHub issue
+// form. Bodies that do not start with a "### " heading are not treated as
+// forms and yield nil. Unanswered fields ("_No response_") are skipped.
+func ParseIssueForm(body string) []FormField {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	if !strings.HasPrefix(strings.TrimSpace(body), "### ") {
+		return nil
+	}
+
+	var fields []FormField
+	var current *FormField
+	var value []string
+	inFence := false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Value = strings.TrimSpace(strings.Join(value, "\n"))
+		if current.Value != "" && current.Value != "_No response_" {
+			fields = append(fields, *current)
+		}
+		current, value = nil, nil
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence {
+			if match := formHeadingPattern.FindStringSubmatch(line); match != nil {
+				flush()
+				current = &FormField{Label: match[1]}
+				continue
+			}
+		}
+		if current != nil {
+			value = append(value, line)
+		}
+	}
+	flush()
+
+	return fields
+}
+
+// FormValue returns the value of the form field with the given label, ignoring case
+func FormValue(fields []FormField, label string) string {
+	for _, field := range fields {
+		if strings.EqualFold(field.Label, label) {
+			return field.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseIssueForm(t *testing.T) {
+	body := strings.Join([]string{
+		"### Version",
+		"",
+		"v1.4.2",
+		"",
+		"### Operating System",
+		"",
+		"_No response_",
+		"",
+		"### Steps to reproduce",
+		"",
+		"1. Run the bot",
+		"```",
+		"### not a heading",
+		"```",
+		"2. Observe the crash",
+		"",
+		"### Code of Conduct",
+		"",
+		"- [X] I agree",
+	}, "\r\n")
+
+	fields := ParseIssueForm(body)
+	if len(fields) != 3 {
+		t.Fatalf("Expected 3 fields, got %d: %+v", len(fields), fields)
+	}
+
+	if fields[0].Label != "Version" || fields[0].Value != "v1.4.2" {
+		t.Errorf("Unexpected first field %+v", fields[0])
+	}
+	steps := FormValue(fields, "steps to reproduce")
+	if !strings.Contains(steps, "### not a heading") || !strings.HasSuffix(steps, "2. Observe the crash") {
+		t.Errorf("Expected code fence to stay inside the value, got %q", steps)
+	}
+	if FormValue(fields, "Operating System") != "" {
+		t.Error("Expected unanswered field to be skipped")
+	}
+}
+
+func TestParseIssueFormFreeText(t *testing.T) {
+	if fields := ParseIssueForm("The bot crashes.\n\n### Logs\n\npanic"); fields != nil {
+		t.Errorf("Expected free-form body not to be parsed, got %+v", fields)
+	}
+}
@@ -1,62 +1,149 @@
 package github
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v57/github"
 	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/breaker"
+	"github-issue-ai-bot/internal/broker"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/monitor"
 )
 
 // IssueData contains all the data needed for AI summarization
 type IssueData struct {
-	Issue      *github.Issue
-	Comments   []*github.IssueComment
-	Commits    []*github.RepositoryCommit
-	Files      []*github.CommitFile
-	Repository *github.Repository
-	EventType  string
-	Action     string
+	Issue       *github.Issue
+	Comments    []*github.IssueComment
+	Commits     []*github.RepositoryCommit
+	Files       []*github.CommitFile
+	Repository  *github.Repository
+	FormFields  []FormField // Parsed issue form sections, nil for free-form issues
+	Environment Environment // OS, browser and versions the reporter mentioned
+	EventType   string
+	Action      string
+	Behavior    Behavior           // What the pipeline should do for this action
+	Changes     *github.EditChange // Previous title and body for edited issues
+	Label       string             // Label added or removed by labeled and unlabeled actions
+	Sender      string             // GitHub login of who triggered the event, empty when unknown
+	DeliveryID  string             // X-GitHub-Delivery header of the webhook
+	ReceivedAt  time.Time          // When the webhook was received
+
+	// Source is where an issue that is not on GitHub was reported, e.g.
+	// zendesk; actions on the GitHub issue are skipped for it. Empty for
+	// GitHub issues.
+	Source      string
+	PromptStyle string // Predefined prompt style to analyze the issue in instead of the configured one, empty for that
+
+	Activity        *IssueActivity   // Age, maintainer engagement, reporter history and reactions
+	Attachments     []Attachment     // Relevant lines of log files linked from the issue
+	Command         *Command         // Set when a comment asked the pipeline to run a command
+	CloseSuggestion *CloseSuggestion // Set when the issue looks resolved or duplicated
+	RegressionHints []RegressionHint // Recent releases that changed files the report mentions, newest first
+	CIFailure       *CIFailure       // Digest of the failed run a CI failure issue links to, nil for other issues
+	Stack           *RepositoryStack // Languages and frameworks of the repository, nil when not detected
+	Memory          memory.Memory    // Earlier analyses and follow-ups for this issue, oldest first
+	Links           []IssueReference // Other issues the issue references, set when they are prefetched
+
+	// Mentions are the Slack user IDs of the issue's assignees by GitHub
+	// login, for the assignees mapped to Slack users
+	Mentions map[string]string
 }
 
 // Handler handles GitHub webhook events
 type Handler struct {
-	client         *github.Client
-	webhookSecret  string
-	logger         *zap.Logger
-	metrics        MetricsRecorder
-	issueProcessor IssueProcessor
+	mu               sync.RWMutex
+	client           *github.Client
+	breaker          *breaker.Breaker // Guards API calls, nil for none
+	apiUsage         APIUsageRecorder // Counts API calls per repository, nil for none
+	shadow           Shadow           // Holds back writes in shadow mode, nil for none
+	webhookSecret    string
+	logger           *zap.Logger
+	metrics          MetricsRecorder
+	issueProcessor   IssueProcessor
+	publisher        broker.Publisher
+	actions          *ActionMatrix
+	closeSuggestions bool
+	comments         *CommentFormatter // Templates of the bot's comments, nil for the built-in ones
+	baseCtx          context.Context   // Parent of background processing, cancelled on shutdown
+	enrichTimeout    time.Duration
+	commentLimit     int           // Comments fetched per issue, 0 for the default
+	enrichLimiter    EnrichLimiter // Bounds concurrent enrichment, nil for no limit
+	queue            *WorkQueue    // Bounds background processing, nil for no limit
+	attachments      AttachmentConfig
+	commands         CommandConfig
+	checks           CheckConfig
+	regressions      RegressionConfig
+	ci               CIConfig
+	attachmentClient *http.Client      // Downloads attachments, which are not API calls
+	linked           *LinkedIssueCache // Prefetches referenced issues, nil to disable
+	sources          *SourceAllowlist  // Addresses webhooks are accepted from, nil for any
+	mover            IssueMover        // Follows transferred issues and renamed repositories, nil to skip them
+	enrichment       EnrichmentConfig  // Enrichment stages per repository and their timeouts
+	stack            StackConfig
+	stacks           *stackCache // Detected stacks by repository, nil until stack detection is set
+	capture          Capturer    // Keeps processed deliveries for replay, nil to keep none
 }
 
 // MetricsRecorder interface for recording metrics
 type MetricsRecorder interface {
 	RecordGitHubWebhook(eventType, action, status string, duration time.Duration)
 	RecordGitHubAPIError(operation, errorType string)
+	RecordStageTimeout(stage string)
+	RecordWebhookQueueDepth(depth int)
+	RecordWebhookSaturation(outcome string)
+	RecordEnrichmentStage(stage, status string, duration time.Duration)
+}
+
+// EnrichLimiter bounds how many issues are enriched at once
+type EnrichLimiter interface {
+	Acquire(ctx context.Context) error
+	Release()
 }
 
-// IssueProcessor interface for processing issue data
+// IssueProcessor interface for processing issue data. Processing stops early
+// when ctx is cancelled.
 type IssueProcessor interface {
-	ProcessIssue(issueData *IssueData)
+	ProcessIssue(ctx context.Context, issueData *IssueData)
+}
+
+// DeliveryProcessor is an IssueProcessor that reports why an issue failed,
+// so broker deliveries that could not be processed are redelivered
+type DeliveryProcessor interface {
+	ProcessDelivery(ctx context.Context, issueData *IssueData) error
 }
 
 // NewHandler creates a new GitHub handler
 func NewHandler(accessToken, webhookSecret string, logger *zap.Logger, metrics MetricsRecorder) *Handler {
-	client := github.NewClient(nil).WithAuthToken(accessToken)
+	return NewHandlerWithClient(github.NewClient(nil).WithAuthToken(accessToken), webhookSecret, logger, metrics)
+}
 
+// NewHandlerWithClient creates a GitHub handler that makes its API calls with
+// client, e.g. one pointed at GitHub Enterprise or a test server
+func NewHandlerWithClient(client *github.Client, webhookSecret string, logger *zap.Logger, metrics MetricsRecorder) *Handler {
 	return &Handler{
 		client:         client,
 		webhookSecret:  webhookSecret,
 		logger:         logger,
 		metrics:        metrics,
 		issueProcessor: nil,
+		baseCtx:        context.Background(),
+
+		attachmentClient: &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -64,6 +151,18 @@ func NewHandler(accessToken, webhookSecret string, logger *zap.Logger, metrics M
 func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
+	// Turn away deliveries from addresses GitHub does not send webhooks from
+	if sources := h.sourceAllowlist(); sources != nil {
+		if ip, ok := sources.Allowed(r); !ok {
+			h.logger.Warn("Rejected webhook from a disallowed address",
+				zap.Stringer("source", ip),
+				zap.String("delivery_id", r.Header.Get("X-GitHub-Delivery")))
+			h.metrics.RecordGitHubWebhook(r.Header.Get("X-GitHub-Event"), "", "forbidden", time.Since(start))
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	}
+
 	// Read the request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -88,27 +187,53 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		zap.String("delivery_id", deliveryID),
 	)
 
-	// Handle different event types
-	var issueData *IssueData
-	var status string
+	// In receiver mode, hand supported events to the broker and return
+	if h.publisher != nil && isSupportedEvent(eventType) {
+		h.publishWebhook(r.Context(), w, broker.Message{
+			EventType:  eventType,
+			DeliveryID: deliveryID,
+			Payload:    body,
+			ReceivedAt: start,
+		})
+		return
+	}
 
-	switch eventType {
-	case "issues":
-		issueData, status, err = h.handleIssuesEvent(body)
-	case "issue_comment":
-		issueData, status, err = h.handleIssueCommentEvent(body)
-	default:
+	// Handle different event types
+	if !isSupportedEvent(eventType) {
 		h.logger.Info("Unsupported event type", zap.String("event_type", eventType))
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	if err != nil {
+	// Reserve room for the work before enriching the event, and turn the
+	// delivery away if there is none
+	spooled := false
+	if h.queue != nil {
+		var ok bool
+		if spooled, ok = h.queue.admit(); !ok {
+			h.rejectSaturated(w, eventType, deliveryID, start)
+			return
+		}
+	}
+
+	h.captureDelivery(eventType, deliveryID, body, start)
+	issueData, status, err := h.dispatchEvent(r.Context(), eventType, body)
+	if errors.Is(err, errInvalidPayload) {
+		// Redelivering the same payload would fail the same way
+		h.logger.Warn("Invalid webhook payload",
+			zap.String("event_type", eventType),
+			zap.String("delivery_id", deliveryID),
+			zap.Error(err))
+		status = "invalid"
+		http.Error(w, "Invalid webhook payload", http.StatusBadRequest)
+	} else if err != nil {
 		h.logger.Error("Failed to process webhook",
 			zap.String("event_type", eventType),
 			zap.Error(err))
 		status = "error"
 		http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+	} else if spooled && issueData != nil {
+		w.WriteHeader(http.StatusAccepted)
 	} else {
 		w.WriteHeader(http.StatusOK)
 	}
@@ -122,9 +247,248 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	h.metrics.RecordGitHubWebhook(eventType, action, status, duration)
 
 	// If we have issue data, process it further
-	if issueData != nil && err == nil {
-		go h.processIssueData(issueData)
+	if issueData == nil || err != nil {
+		if h.queue != nil {
+			h.queue.release()
+		}
+		return
+	}
+	issueData.DeliveryID = deliveryID
+	issueData.ReceivedAt = start
+	h.enqueue(eventType, issueData, spooled)
+}
+
+// enqueue processes the issue of an admitted delivery in the background, on
+// a worker of the queue if there is one
+func (h *Handler) enqueue(eventType string, issueData *IssueData, spooled bool) {
+	if h.queue == nil {
+		go h.processIssueData(h.baseCtx, issueData)
+		return
+	}
+
+	if spooled {
+		h.metrics.RecordWebhookSaturation("spooled")
+		h.logger.Warn("Webhook queue is full, spooling delivery",
+			zap.String("event_type", eventType),
+			zap.String("delivery_id", issueData.DeliveryID),
+			zap.Int("depth", h.queue.Depth()))
+	}
+	h.metrics.RecordWebhookQueueDepth(h.queue.Depth())
+	go func() {
+		h.queue.run(h.baseCtx, func() { h.processIssueData(h.baseCtx, issueData) })
+		h.metrics.RecordWebhookQueueDepth(h.queue.Depth())
+	}()
+}
+
+// SetWorkQueue bounds background processing of webhooks. Without a queue
+// every webhook is processed as soon as it arrives.
+func (h *Handler) SetWorkQueue(queue *WorkQueue) {
+	h.queue = queue
+}
+
+// QueueStats returns the load of the webhook queue
+func (h *Handler) QueueStats() QueueStats {
+	if h.queue == nil {
+		return QueueStats{}
+	}
+	return h.queue.Stats()
+}
+
+// rejectSaturated answers a webhook that arrived while the queue was full with
+// 503 and Retry-After, so GitHub records the delivery as failed instead of it
+// being accepted and dropped
+func (h *Handler) rejectSaturated(w http.ResponseWriter, eventType, deliveryID string, start time.Time) {
+	h.logger.Warn("Webhook queue is full, rejecting delivery",
+		zap.String("event_type", eventType),
+		zap.String("delivery_id", deliveryID),
+		zap.Int("depth", h.queue.Depth()))
+	w.Header().Set("Retry-After", h.queue.retryAfterSeconds())
+	http.Error(w, "Webhook queue is full", http.StatusServiceUnavailable)
+	h.metrics.RecordWebhookSaturation("rejected")
+	h.metrics.RecordGitHubWebhook(eventType, "", "saturated", time.Since(start))
+}
+
+// SetPublisher switches the handler to receiver mode: verified webhooks are
+// published to the broker instead of being processed in this process
+func (h *Handler) SetPublisher(publisher broker.Publisher) {
+	h.publisher = publisher
+}
+
+// publishWebhook publishes a verified delivery. Failures return 503 so GitHub
+// retries the delivery instead of it being lost.
+func (h *Handler) publishWebhook(ctx context.Context, w http.ResponseWriter, msg broker.Message) {
+	status := "queued"
+	if err := h.publisher.Publish(ctx, msg); err != nil {
+		h.logger.Error("Failed to publish webhook",
+			zap.String("event_type", msg.EventType),
+			zap.String("delivery_id", msg.DeliveryID),
+			zap.Error(err))
+		status = "error"
+		http.Error(w, "Failed to queue webhook", http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	h.metrics.RecordGitHubWebhook(msg.EventType, "", status, time.Since(msg.ReceivedAt))
+}
+
+// HandleEvent processes a webhook delivery consumed from the broker. Unlike
+// HandleWebhook the issue is processed before returning, so the broker only
+// acknowledges deliveries that have been handled, and redelivers those that
+// failed with a retryable error, e.g. while OpenAI or Slack is down.
+func (h *Handler) HandleEvent(ctx context.Context, msg broker.Message) error {
+	start := time.Now()
+	if !isSupportedEvent(msg.EventType) {
+		return nil
+	}
+
+	h.captureDelivery(msg.EventType, msg.DeliveryID, msg.Payload, msg.ReceivedAt)
+	issueData, status, err := h.dispatchEvent(ctx, msg.EventType, msg.Payload)
+	action := ""
+	if issueData != nil {
+		action = issueData.Action
+	}
+	if errors.Is(err, errInvalidPayload) {
+		// Retrying would fail the same way, so the delivery is dropped
+		h.metrics.RecordGitHubWebhook(msg.EventType, action, "invalid", time.Since(start))
+		h.logger.Warn("Dropping invalid queued webhook",
+			zap.String("event_type", msg.EventType),
+			zap.String("delivery_id", msg.DeliveryID),
+			zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		status = "error"
+	}
+	h.metrics.RecordGitHubWebhook(msg.EventType, action, status, time.Since(start))
+
+	if err != nil {
+		h.logger.Error("Failed to process queued webhook",
+			zap.String("event_type", msg.EventType),
+			zap.String("delivery_id", msg.DeliveryID),
+			zap.Error(err))
+		return err
+	}
+
+	if issueData != nil {
+		issueData.DeliveryID = msg.DeliveryID
+		issueData.ReceivedAt = msg.ReceivedAt
+		if err := h.processIssueData(ctx, issueData); err != nil {
+			h.logger.Error("Failed to process queued issue",
+				zap.String("event_type", msg.EventType),
+				zap.String("delivery_id", msg.DeliveryID),
+				zap.String("repository", issueData.Repository.GetFullName()),
+				zap.Int("issue_number", issueData.Issue.GetNumber()),
+				zap.Bool("retryable", apperrors.IsRetryable(err)),
+				zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// isSupportedEvent reports whether the bot handles the event type.
+// Repository events are only followed for renames and transfers.
+func isSupportedEvent(eventType string) bool {
+	return eventType == "issues" || eventType == "issue_comment" || eventType == "repository"
+}
+
+// errInvalidPayload marks deliveries that cannot be processed however often
+// they are retried, e.g. an empty body or an event without an issue
+var errInvalidPayload = errors.New("invalid webhook payload")
+
+// dispatchEvent parses and enriches a supported event
+func (h *Handler) dispatchEvent(ctx context.Context, eventType string, body []byte) (*IssueData, string, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, "invalid", fmt.Errorf("%w: empty body", errInvalidPayload)
+	}
+	switch eventType {
+	case "issue_comment":
+		return h.handleIssueCommentEvent(ctx, body)
+	case "repository":
+		return h.handleRepositoryEvent(ctx, body)
 	}
+	return h.handleIssuesEvent(ctx, body)
+}
+
+// SetAccessToken replaces the token used for GitHub API calls, e.g. after a
+// mounted secret has been rotated. The new client keeps the base URLs.
+func (h *Handler) SetAccessToken(accessToken string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	client := github.NewClient(nil).WithAuthToken(accessToken)
+	client.BaseURL = h.client.BaseURL
+	client.UploadURL = h.client.UploadURL
+	h.client = withBreaker(withAPIUsage(withShadow(client, h.shadow), h.apiUsage), h.breaker)
+}
+
+// SetBreaker guards GitHub API calls with a circuit breaker, so they fail
+// fast while GitHub is down
+func (h *Handler) SetBreaker(b *breaker.Breaker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.breaker = b
+	h.client = withBreaker(h.client, b)
+}
+
+// withBreaker returns a copy of client whose requests go through b
+func withBreaker(client *github.Client, b *breaker.Breaker) *github.Client {
+	if b == nil {
+		return client
+	}
+	httpClient := client.Client()
+	httpClient.Transport = b.Transport(httpClient.Transport)
+	guarded := github.NewClient(httpClient)
+	guarded.BaseURL, guarded.UploadURL = client.BaseURL, client.UploadURL
+	return guarded
+}
+
+// SetWebhookSecret replaces the secret used to verify webhook signatures
+func (h *Handler) SetWebhookSecret(webhookSecret string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.webhookSecret = webhookSecret
+}
+
+// githubClient returns the current GitHub API client
+func (h *Handler) githubClient() *github.Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.client
+}
+
+// SetActionMatrix sets the per-repository event × action behaviors. Without
+// one the default matrix is used.
+func (h *Handler) SetActionMatrix(actions *ActionMatrix) {
+	h.actions = actions
+}
+
+// SetBaseContext sets the parent context of issues processed in the
+// background after a webhook has been acknowledged. Cancelling it, e.g. on
+// shutdown, cancels their in-flight API calls.
+func (h *Handler) SetBaseContext(ctx context.Context) {
+	h.baseCtx = ctx
+}
+
+// SetEnrichTimeout limits how long fetching an issue's comments and commits
+// may take. Zero means no limit beyond the parent context.
+func (h *Handler) SetEnrichTimeout(timeout time.Duration) {
+	h.enrichTimeout = timeout
+}
+
+// SetEnrichLimiter bounds how many issues are enriched at once, e.g. fewer
+// under memory pressure. Enrichment waits for a slot before its timeout
+// starts.
+func (h *Handler) SetEnrichLimiter(limiter EnrichLimiter) {
+	h.enrichLimiter = limiter
+}
+
+// QueueDepth returns the number of accepted webhooks that have not finished
+// processing, 0 without a work queue
+func (h *Handler) QueueDepth() int {
+	if h.queue == nil {
+		return 0
+	}
+	return h.queue.Depth()
 }
 
 // SetIssueProcessor sets the issue processor
@@ -133,7 +497,7 @@ func (h *Handler) SetIssueProcessor(processor IssueProcessor) {
 }
 
 // handleIssuesEvent processes GitHub issues events
-func (h *Handler) handleIssuesEvent(body []byte) (*IssueData, string, error) {
+func (h *Handler) handleIssuesEvent(ctx context.Context, body []byte) (*IssueData, string, error) {
 	var event github.IssuesEvent
 	if err := json.Unmarshal(body, &event); err != nil {
 		return nil, "error", fmt.Errorf("failed to unmarshal issues event: %w", err)
@@ -146,162 +510,145 @@ func (h *Handler) handleIssuesEvent(body []byte) (*IssueData, string, error) {
 		zap.Any("sender", event.Sender),
 	)
 
-	// Only process certain actions
-	if event.Action == nil || !h.shouldProcessAction(*event.Action) {
+	// Transfers move the issue's history rather than processing it
+	if event.GetAction() == "transferred" {
+		return h.handleTransfer(ctx, &event, body)
+	}
+
+	// Only process actions the matrix does not ignore
+	behavior := h.actions.EventBehavior(ActionEvent{
+		Repository: event.GetRepo().GetFullName(),
+		Type:       "issues",
+		Action:     event.GetAction(),
+		Label:      event.GetLabel().GetName(),
+		Sender:     event.GetSender().GetLogin(),
+		Issue:      event.GetIssue(),
+	})
+	if event.Action == nil || behavior == BehaviorIgnore {
+		return nil, "skipped", nil
+	}
+	if event.Issue == nil {
+		return nil, "invalid", fmt.Errorf("%w: issues event without an issue", errInvalidPayload)
+	}
+
+	// Issues filed from support tickets and Slack messages were processed
+	// when they were filed
+	if body := event.GetIssue().GetBody(); event.GetAction() == "opened" && (IsFiledTicket(body) || IsFiledFromSlack(body)) {
 		return nil, "skipped", nil
 	}
 
-	issueData, err := h.enrichIssueData(context.Background(), event.GetIssue(), *event.Action, "issues")
+	issueData, err := h.enrich(ctx, event.GetIssue(), event.GetRepo(), event.GetAction(), "issues")
 	if err != nil {
 		return nil, "error", err
 	}
+	issueData.Behavior = behavior
+	issueData.Changes = event.GetChanges()
+	issueData.Label = event.GetLabel().GetName()
+	issueData.Sender = event.GetSender().GetLogin()
 
 	return issueData, "success", nil
 }
 
 // handleIssueCommentEvent processes GitHub issue comment events
-func (h *Handler) handleIssueCommentEvent(body []byte) (*IssueData, string, error) {
+func (h *Handler) handleIssueCommentEvent(ctx context.Context, body []byte) (*IssueData, string, error) {
 	var event github.IssueCommentEvent
 	if err := json.Unmarshal(body, &event); err != nil {
 		return nil, "error", fmt.Errorf("failed to unmarshal issue comment event: %w", err)
 	}
+	if event.Issue == nil {
+		return nil, "invalid", fmt.Errorf("%w: issue_comment event without an issue", errInvalidPayload)
+	}
 
-	// Only process certain actions
-	if event.Action == nil || !h.shouldProcessAction(*event.Action) {
+	// Commands run whatever the matrix does with comments
+	if event.GetAction() == "created" && h.commandConfig().Enabled && !event.GetIssue().IsPullRequest() {
+		if command, ok := ParseCommand(event.GetComment().GetBody()); ok {
+			return h.handleCommand(ctx, &event, command)
+		}
+	}
+
+	// Only process actions the matrix does not ignore
+	behavior := h.actions.EventBehavior(ActionEvent{
+		Repository: event.GetRepo().GetFullName(),
+		Type:       "issue_comment",
+		Action:     event.GetAction(),
+		Sender:     event.GetSender().GetLogin(),
+		Issue:      event.GetIssue(),
+	})
+	if event.Action == nil || behavior == BehaviorIgnore {
 		return nil, "skipped", nil
 	}
 
-	issueData, err := h.enrichIssueData(context.Background(), event.GetIssue(), *event.Action, "issue_comment")
+	issueData, err := h.enrich(ctx, event.GetIssue(), event.GetRepo(), event.GetAction(), "issue_comment")
 	if err != nil {
 		return nil, "error", err
 	}
+	issueData.Behavior = behavior
+	issueData.Sender = event.GetSender().GetLogin()
 
 	return issueData, "success", nil
 }
 
-// shouldProcessAction determines if we should process a specific action
-func (h *Handler) shouldProcessAction(action string) bool {
-	processableActions := []string{
-		"opened", "edited", "reopened", "closed", "created", "updated",
+// enrich runs enrichIssueData within the enrichment timeout. Fetch failures
+// are not fatal, so an issue that times out is processed with what was
+// fetched in time.
+func (h *Handler) enrich(ctx context.Context, issue *github.Issue, repository *github.Repository, action, eventType string) (*IssueData, error) {
+	if h.enrichLimiter != nil {
+		if err := h.enrichLimiter.Acquire(ctx); err != nil {
+			return nil, fmt.Errorf("gave up waiting to enrich issue: %w", err)
+		}
+		defer h.enrichLimiter.Release()
+	}
+	if h.enrichTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.enrichTimeout)
+		defer cancel()
 	}
 
-	for _, a := range processableActions {
-		if action == a {
-			return true
-		}
+	issueData, err := h.enrichIssueData(ctx, issue, repository, action, eventType)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		h.metrics.RecordStageTimeout(monitor.StageEnrich)
 	}
-	return false
+	return issueData, err
 }
 
-// enrichIssueData fetches additional data for an issue
-func (h *Handler) enrichIssueData(ctx context.Context, issue *github.Issue, action, eventType string) (*IssueData, error) {
+// enrichIssueData fetches additional data for an issue. Issues events carry
+// the repository in the event rather than in the issue, so it is passed in;
+// without it the issue's own repository or repository URL is used.
+func (h *Handler) enrichIssueData(ctx context.Context, issue *github.Issue, repository *github.Repository, action, eventType string) (*IssueData, error) {
 	if issue == nil {
 		return nil, fmt.Errorf("issue is nil")
 	}
 
-	// Extract repository information with better error handling
-	var repoOwner, repoName string
+	repository = resolveRepository(issue, repository)
+	repoOwner, repoName := repository.GetOwner().GetLogin(), repository.GetName()
 
-	if issue.GetRepository() != nil {
-		repoOwner = issue.GetRepository().GetOwner().GetLogin()
-		repoName = issue.GetRepository().GetName()
-	} else {
-		// Fallback: extract from RepositoryURL if Repository object is nil
-		repoURL := issue.GetRepositoryURL()
-		if repoURL != "" {
-			// Extract owner/repo from URL like "https://api.github.com/repos/owner/repo"
-			parts := strings.Split(repoURL, "/")
-			if len(parts) >= 5 {
-				repoOwner = parts[len(parts)-2]
-				repoName = parts[len(parts)-1]
-			}
-		}
-	}
-
-	// Add debugging and validation
-	h.logger.Info("Enriching issue data",
-		zap.String("repo_owner", repoOwner),
-		zap.String("repo_name", repoName),
+	h.logger.Debug("Enriching issue data",
+		zap.String("repository", repository.GetFullName()),
 		zap.Int("issue_number", issue.GetNumber()),
 		zap.String("action", action),
-		zap.Any("repository", issue.GetRepository()),
 	)
 
-	// Validate repository information
 	if repoOwner == "" || repoName == "" {
-		h.logger.Error("Invalid repository information",
-			zap.String("repo_owner", repoOwner),
-			zap.String("repo_name", repoName),
-			zap.Any("repository", issue.GetRepository()),
+		// Continue without the stages that need the repository
+		h.logger.Warn("Enriching issue without repository information",
+			zap.Int("issue_number", issue.GetNumber()),
+			zap.String("repository_url", issue.GetRepositoryURL()),
 		)
-		// Instead of failing, continue with empty repository info
-		h.logger.Warn("Continuing without repository information")
-	}
-
-	// Fetch comments (only if we have repository info)
-	var comments []*github.IssueComment
-	if repoOwner != "" && repoName != "" {
-		var err error
-		comments, err = h.fetchIssueComments(ctx, repoOwner, repoName, issue.GetNumber())
-		if err != nil {
-			h.metrics.RecordGitHubAPIError("fetch_comments", "api_error")
-			h.logger.Error("Failed to fetch issue comments", zap.Error(err))
-			// Continue without comments
-		}
-	} else {
-		h.logger.Info("Skipping comment fetch due to missing repository information")
-	}
-
-	// Fetch related commits (only if we have repository info)
-	var commits []*github.RepositoryCommit
-	if repoOwner != "" && repoName != "" {
-		var err error
-		commits, err = h.fetchRelatedCommits(ctx, repoOwner, repoName, issue.GetNumber())
-		if err != nil {
-			h.metrics.RecordGitHubAPIError("fetch_commits", "api_error")
-			h.logger.Error("Failed to fetch related commits", zap.Error(err))
-			// Continue without commits
-		}
-	} else {
-		h.logger.Info("Skipping commit fetch due to missing repository information")
-	}
-
-	// Fetch commit files
-	var files []*github.CommitFile
-	if len(commits) > 0 && repoOwner != "" && repoName != "" {
-		var err error
-		files, err = h.fetchCommitFiles(ctx, repoOwner, repoName, commits[0].GetSHA())
-		if err != nil {
-			h.metrics.RecordGitHubAPIError("fetch_files", "api_error")
-			h.logger.Error("Failed to fetch commit files", zap.Error(err))
-			// Continue without files
-		}
 	}
 
-	// Create a mock repository object if the original is nil
-	var repository *github.Repository
-	if issue.GetRepository() != nil {
-		repository = issue.GetRepository()
-	} else if repoOwner != "" && repoName != "" {
-		// Create a minimal repository object with the extracted info
-		repository = &github.Repository{
-			FullName: github.String(fmt.Sprintf("%s/%s", repoOwner, repoName)),
-			Owner: &github.User{
-				Login: github.String(repoOwner),
-			},
-			Name: github.String(repoName),
-		}
+	formFields := ParseIssueForm(issue.GetBody())
+	issueData := &IssueData{
+		Issue:       issue,
+		Repository:  repository,
+		FormFields:  formFields,
+		Environment: ExtractEnvironment(issue.GetBody(), formFields),
+		EventType:   eventType,
+		Action:      action,
+		PromptStyle: ticketPromptStyle(issue.GetBody()),
 	}
-
-	return &IssueData{
-		Issue:      issue,
-		Comments:   comments,
-		Commits:    commits,
-		Files:      files,
-		Repository: repository,
-		EventType:  eventType,
-		Action:     action,
-	}, nil
+	h.runEnrichStages(ctx, &enrichment{issue: issue, owner: repoOwner, repo: repoName, data: issueData})
+	issueData.Activity = ComputeActivity(issue, issueData.Comments, time.Now())
+	return issueData, nil
 }
 
 // FetchEnrichedIssueData fetches and enriches issue data by repo and issue number
@@ -315,25 +662,19 @@ func (h *Handler) FetchEnrichedIssueData(ctx context.Context, repo string, numbe
 	repoName := parts[1]
 
 	// Fetch the issue
-	issue, _, err := h.client.Issues.Get(ctx, owner, repoName, number)
+	issue, _, err := h.githubClient().Issues.Get(ctx, owner, repoName, number)
 	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("fetch_issue", apperrors.Classify(err))
 		return nil, fmt.Errorf("failed to fetch issue: %w", err)
 	}
 
 	// Enrich the issue data (action and eventType are not known, use defaults)
-	return h.enrichIssueData(ctx, issue, "opened", "issues")
-}
-
-// fetchIssueComments fetches comments for an issue
-func (h *Handler) fetchIssueComments(ctx context.Context, owner, repo string, issueNumber int) ([]*github.IssueComment, error) {
-	if owner == "" || repo == "" {
-		return nil, fmt.Errorf("invalid repository: owner=%s, repo=%s", owner, repo)
-	}
-
-	comments, _, err := h.client.Issues.ListComments(ctx, owner, repo, issueNumber, &github.IssueListCommentsOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
-	})
-	return comments, err
+	return h.enrich(ctx, issue, &github.Repository{
+		FullName: github.String(repo),
+		Owner:    &github.User{Login: github.String(owner)},
+		Name:     github.String(repoName),
+	}, "opened", "issues")
 }
 
 // fetchRelatedCommits fetches commits related to an issue
@@ -344,17 +685,17 @@ func (h *Handler) fetchRelatedCommits(ctx context.Context, owner, repo string, i
 
 	// Search for commits that reference this issue
 	query := fmt.Sprintf("repo:%s/%s issue:%d", owner, repo, issueNumber)
-	commits, _, err := h.client.Search.Commits(ctx, query, &github.SearchOptions{
+	commits, _, err := h.githubClient().Search.Commits(ctx, query, &github.SearchOptions{
 		ListOptions: github.ListOptions{PerPage: 10},
 	})
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
 
 	// Convert search results to repository commits
 	var repoCommits []*github.RepositoryCommit
 	for _, commit := range commits.Commits {
-		repoCommit, _, err := h.client.Repositories.GetCommit(ctx, owner, repo, commit.GetSHA(), nil)
+		repoCommit, _, err := h.githubClient().Repositories.GetCommit(ctx, owner, repo, commit.GetSHA(), nil)
 		if err != nil {
 			continue // Skip commits we can't fetch
 		}
@@ -366,16 +707,20 @@ func (h *Handler) fetchRelatedCommits(ctx context.Context, owner, repo string, i
 
 // fetchCommitFiles fetches files changed in a commit
 func (h *Handler) fetchCommitFiles(ctx context.Context, owner, repo, sha string) ([]*github.CommitFile, error) {
-	commit, _, err := h.client.Repositories.GetCommit(ctx, owner, repo, sha, nil)
+	commit, _, err := h.githubClient().Repositories.GetCommit(ctx, owner, repo, sha, nil)
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
 	return commit.Files, nil
 }
 
 // verifySignature verifies the GitHub webhook signature
 func (h *Handler) verifySignature(payload []byte, signature string) bool {
-	if h.webhookSecret == "" {
+	h.mu.RLock()
+	webhookSecret := h.webhookSecret
+	h.mu.RUnlock()
+
+	if webhookSecret == "" {
 		return true // Skip verification if no secret is configured
 	}
 
@@ -386,17 +731,29 @@ func (h *Handler) verifySignature(payload []byte, signature string) bool {
 	expectedSignature := signature[7:] // Remove "sha256=" prefix
 
 	// Create HMAC
-	mac := hmac.New(sha256.New, []byte(h.webhookSecret))
+	mac := hmac.New(sha256.New, []byte(webhookSecret))
 	mac.Write(payload)
 	actualSignature := hex.EncodeToString(mac.Sum(nil))
 
 	return hmac.Equal([]byte(actualSignature), []byte(expectedSignature))
 }
 
-// processIssueData processes the enriched issue data
-func (h *Handler) processIssueData(issueData *IssueData) {
+// processIssueData processes the enriched issue data, returning the error
+// that stopped it when the processor reports one
+func (h *Handler) processIssueData(ctx context.Context, issueData *IssueData) error {
+	if issueData == nil || issueData.Issue == nil {
+		h.logger.Warn("Skipping issue data without an issue")
+		return nil
+	}
+	// Referenced issues are fetched in the background, outliving the webhook
+	if linked := h.linkedIssues(); linked != nil {
+		issueData.Links = linked.Prefetch(h.baseCtx, issueData)
+	}
+	if processor, ok := h.issueProcessor.(DeliveryProcessor); ok {
+		return processor.ProcessDelivery(ctx, issueData)
+	}
 	if h.issueProcessor != nil {
-		h.issueProcessor.ProcessIssue(issueData)
+		h.issueProcessor.ProcessIssue(ctx, issueData)
 	} else {
 		h.logger.Info("Issue data ready for processing (no processor set)",
 			zap.String("repository", issueData.Repository.GetFullName()),
@@ -406,4 +763,35 @@ func (h *Handler) processIssueData(issueData *IssueData) {
 			zap.Int("commits_count", len(issueData.Commits)),
 		)
 	}
+	return nil
+}
+
+// resolveRepository returns the repository of an issue: the one given, e.g.
+// from the event envelope, else the issue's own, else one built from the
+// issue's repository URL. The result has an owner, name and full name where
+// any source had them, and is nil when none did.
+func resolveRepository(issue *github.Issue, repository *github.Repository) *github.Repository {
+	if repository.GetName() == "" || repository.GetOwner().GetLogin() == "" {
+		repository = issue.GetRepository()
+	}
+	if repository.GetName() != "" && repository.GetOwner().GetLogin() != "" {
+		if repository.GetFullName() == "" {
+			filled := *repository
+			filled.FullName = github.String(repository.GetOwner().GetLogin() + "/" + repository.GetName())
+			repository = &filled
+		}
+		return repository
+	}
+
+	// e.g. https://api.github.com/repos/owner/repo
+	parts := strings.Split(strings.TrimSuffix(issue.GetRepositoryURL(), "/"), "/")
+	if len(parts) < 5 || parts[len(parts)-3] != "repos" {
+		return repository
+	}
+	owner, name := parts[len(parts)-2], parts[len(parts)-1]
+	return &github.Repository{
+		FullName: github.String(owner + "/" + name),
+		Owner:    &github.User{Login: github.String(owner)},
+		Name:     github.String(name),
+	}
 }
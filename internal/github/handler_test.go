@@ -31,6 +31,22 @@ func (m *MockMetricsRecorder) RecordGitHubAPIError(operation, errorType string)
 	m.Called(operation, errorType)
 }
 
+func (m *MockMetricsRecorder) RecordStageTimeout(stage string) {
+	m.Called(stage)
+}
+
+func (m *MockMetricsRecorder) RecordWebhookQueueDepth(depth int) {
+	m.Called(depth)
+}
+
+func (m *MockMetricsRecorder) RecordWebhookSaturation(outcome string) {
+	m.Called(outcome)
+}
+
+// RecordEnrichmentStage is not mocked, as every enriched issue records its
+// stages
+func (m *MockMetricsRecorder) RecordEnrichmentStage(stage, status string, duration time.Duration) {}
+
 // generateSignature generates a valid GitHub webhook signature
 func generateSignature(secret string, payload []byte) string {
 	mac := hmac.New(sha256.New, []byte(secret))
@@ -114,8 +130,8 @@ func TestHandleWebhook(t *testing.T) {
 			// Set up mock expectations based on event type
 			if tt.eventType == "issues" {
 				mockMetrics.On("RecordGitHubWebhook", tt.eventType, mock.Anything, mock.Anything, mock.Anything).Return()
-				mockMetrics.On("RecordGitHubAPIError", "fetch_comments", "api_error").Return()
-				mockMetrics.On("RecordGitHubAPIError", "fetch_commits", "api_error").Return()
+				mockMetrics.On("RecordGitHubAPIError", "fetch_comments", mock.AnythingOfType("string")).Return()
+				mockMetrics.On("RecordGitHubAPIError", "fetch_commits", mock.AnythingOfType("string")).Return()
 			}
 			// For unsupported events, no metrics are recorded since handler returns early
 
@@ -131,31 +147,33 @@ func TestHandleWebhook(t *testing.T) {
 	}
 }
 
-// TestShouldProcessAction tests the action filtering logic
-func TestShouldProcessAction(t *testing.T) {
+// TestDefaultActionBehaviors tests the default event × action matrix
+func TestDefaultActionBehaviors(t *testing.T) {
 	handler := &Handler{}
 
 	tests := []struct {
-		action        string
-		shouldProcess bool
+		eventType string
+		action    string
+		behavior  Behavior
 	}{
-		{"opened", true},
-		{"edited", true},
-		{"reopened", true},
-		{"closed", true},
-		{"created", true},
-		{"updated", true},
-		{"deleted", false},
-		{"assigned", false},
-		{"unassigned", false},
-		{"labeled", false},
-		{"unlabeled", false},
+		{"issues", "opened", BehaviorSummarize},
+		{"issues", "reopened", BehaviorSummarize},
+		{"issues", "edited", BehaviorResummarize},
+		{"issues", "closed", BehaviorUpdate},
+		{"issues", "deleted", BehaviorIgnore},
+		{"issues", "assigned", BehaviorUpdate},
+		{"issues", "labeled", BehaviorUpdate},
+		{"issues", "milestoned", BehaviorUpdate},
+		{"issues", "transferred", BehaviorIgnore},
+		{"issue_comment", "created", BehaviorSummarize},
+		{"issue_comment", "edited", BehaviorIgnore},
+		{"issue_comment", "deleted", BehaviorIgnore},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.action, func(t *testing.T) {
-			result := handler.shouldProcessAction(tt.action)
-			assert.Equal(t, tt.shouldProcess, result)
+		t.Run(tt.eventType+"/"+tt.action, func(t *testing.T) {
+			result := handler.actions.Behavior("owner/repo", tt.eventType, tt.action)
+			assert.Equal(t, tt.behavior, result)
 		})
 	}
 }
@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// historyRateReserve is how many API calls a historical import leaves in the
+// rate limit, so importing a large repository does not starve the webhooks
+// processed meanwhile
+const historyRateReserve = 500
+
+// ListIssueHistory reads a repository's issues and pull requests updated
+// since a time, oldest first, passing each page to visit. Reading stops with
+// an error wrapping apperrors.ErrRateLimited when the rate limit runs low;
+// the pages visited until then are complete.
+func (h *Handler) ListIssueHistory(ctx context.Context, repo string, since time.Time, visit func(issues []*github.Issue)) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	opts := &github.IssueListByRepoOptions{
+		State:       "all",
+		Sort:        "updated",
+		Direction:   "asc",
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		issues, resp, err := h.githubClient().Issues.ListByRepo(ctx, parts[0], parts[1], opts)
+		if err != nil {
+			err = classifyError(err)
+			h.metrics.RecordGitHubAPIError("history_issues", apperrors.Classify(err))
+			return fmt.Errorf("failed to list issues: %w", err)
+		}
+		visit(issues)
+		if resp.NextPage == 0 {
+			return nil
+		}
+		if err := historyRateCheck(resp); err != nil {
+			return err
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ListCommentHistory reads a repository's issue comments created since a
+// time, oldest first, passing each page to visit. It stops like
+// ListIssueHistory when the rate limit runs low.
+func (h *Handler) ListCommentHistory(ctx context.Context, repo string, since time.Time, visit func(comments []*github.IssueComment)) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	sort, direction := "created", "asc"
+	opts := &github.IssueListCommentsOptions{
+		Sort:        &sort,
+		Direction:   &direction,
+		Since:       &since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		comments, resp, err := h.githubClient().Issues.ListComments(ctx, parts[0], parts[1], 0, opts)
+		if err != nil {
+			err = classifyError(err)
+			h.metrics.RecordGitHubAPIError("history_comments", apperrors.Classify(err))
+			return fmt.Errorf("failed to list comments: %w", err)
+		}
+		visit(comments)
+		if resp.NextPage == 0 {
+			return nil
+		}
+		if err := historyRateCheck(resp); err != nil {
+			return err
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// historyRateCheck reports an error when too few API calls are left to read
+// another page of history
+func historyRateCheck(resp *github.Response) error {
+	if resp.Rate.Limit > 0 && resp.Rate.Remaining < historyRateReserve {
+		return apperrors.WrapRetryAfter(apperrors.ErrRateLimited,
+			fmt.Errorf("stopped with %d API calls left until %s", resp.Rate.Remaining, resp.Rate.Reset.Format(time.RFC3339)),
+			time.Until(resp.Rate.Reset.Time))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+func TestListIssueHistory(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != "all" || query.Get("sort") != "updated" || query.Get("direction") != "asc" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		switch query.Get("page") {
+		case "":
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/issues?page=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"number":1},{"number":2}]`)
+		case "2":
+			// Too few calls left to read page 3
+			w.Header().Set("X-RateLimit-Remaining", "100")
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/issues?page=3>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"number":3}]`)
+		default:
+			t.Errorf("Expected the import to stop before page %s", query.Get("page"))
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	var numbers []int
+	err := newCloseTestHandler(server).ListIssueHistory(context.Background(), "o/r", time.Time{}, func(issues []*github.Issue) {
+		for _, issue := range issues {
+			numbers = append(numbers, issue.GetNumber())
+		}
+	})
+	if !errors.Is(err, apperrors.ErrRateLimited) {
+		t.Errorf("Expected a rate limit error, got %v", err)
+	}
+	if len(numbers) != 3 {
+		t.Errorf("Expected the pages read before stopping visited, got %v", numbers)
+	}
+}
@@ -0,0 +1,36 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// RequestInfo comments on an issue to ask its reporter for the details the
+// bug report is missing
+func (h *Handler) RequestInfo(ctx context.Context, repo string, number int, reporter string, missing []string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+	owner, repoName := parts[0], parts[1]
+
+	data := CommentData{Repository: repo, IssueNumber: number, Reporter: reporter, MissingInfo: missing}
+	comment, err := h.commentFormatter().Render(CommentNeedsInfo, data)
+	if err != nil {
+		h.logger.Warn("Failed to render needs info comment, using the built-in one", zap.String("repository", repo), zap.Error(err))
+		comment, _ = (*CommentFormatter)(nil).Render(CommentNeedsInfo, data)
+	}
+
+	if _, _, err := h.githubClient().Issues.CreateComment(ctx, owner, repoName, number, &github.IssueComment{Body: github.String(comment)}); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("needs_info_comment", apperrors.Classify(err))
+		return fmt.Errorf("failed to comment on issue: %w", err)
+	}
+	return nil
+}
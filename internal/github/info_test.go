@@ -0,0 +1,36 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestRequestInfo(t *testing.T) {
+	var comment string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/o/r/issues/7/comments" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body github.IssueComment
+		json.NewDecoder(r.Body).Decode(&body)
+		comment = body.GetBody()
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	if err := handler.RequestInfo(context.Background(), "o/r", 7, "octocat", []string{"version", "steps to reproduce"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Thanks for the report, @octocat! To help us reproduce it, could you add the following?\n\n- version\n- steps to reproduce\n\nWe'll take another look once you update the issue or reply here."
+	if comment != want {
+		t.Errorf("Expected the built-in comment %q, got %q", want, comment)
+	}
+}
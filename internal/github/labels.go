@@ -0,0 +1,61 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// AddLabels adds labels to an issue, e.g. the labels mapped to its AI
+// priority and category. Labels the repository does not have yet are created
+// by GitHub.
+func (h *Handler) AddLabels(ctx context.Context, repo string, number int, labels []string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	if _, _, err := h.githubClient().Issues.AddLabelsToIssue(ctx, parts[0], parts[1], number, labels); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("add_labels", apperrors.Classify(err))
+		return fmt.Errorf("failed to label issue: %w", err)
+	}
+
+	h.logger.Info("Labeled issue",
+		zap.String("repository", repo),
+		zap.Int("issue_number", number),
+		zap.Strings("labels", labels),
+	)
+	return nil
+}
+
+// RemoveLabel removes a label from an issue, e.g. the label of a priority
+// the issue no longer has. Labels the issue does not have are ignored.
+func (h *Handler) RemoveLabel(ctx context.Context, repo string, number int, label string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	resp, err := h.githubClient().Issues.RemoveLabelForIssue(ctx, parts[0], parts[1], number, label)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("remove_label", apperrors.Classify(err))
+		return fmt.Errorf("failed to remove label: %w", err)
+	}
+
+	h.logger.Info("Removed issue label",
+		zap.String("repository", repo),
+		zap.Int("issue_number", number),
+		zap.String("label", label),
+	)
+	return nil
+}
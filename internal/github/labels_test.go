@@ -0,0 +1,81 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddLabels(t *testing.T) {
+	var path string
+	var labels []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		json.NewDecoder(r.Body).Decode(&labels)
+		json.NewEncoder(w).Encode([]*github.Label{})
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	if err := handler.AddLabels(context.Background(), "o/r", 7, []string{"bug", "P1"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if path != "POST /repos/o/r/issues/7/labels" {
+		t.Errorf("Unexpected request %s", path)
+	}
+	if len(labels) != 2 || labels[0] != "bug" || labels[1] != "P1" {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+
+	if err := handler.AddLabels(context.Background(), "invalid", 7, []string{"bug"}); err == nil {
+		t.Error("Expected an error for an invalid repository")
+	}
+}
+
+func TestAddLabelsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	metrics := &MockMetricsRecorder{}
+	metrics.On("RecordGitHubAPIError", "add_labels", mock.Anything).Return()
+	handler.metrics = metrics
+
+	if err := handler.AddLabels(context.Background(), "o/r", 7, []string{"bug"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	metrics.AssertExpectations(t)
+}
+
+func TestRemoveLabel(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/repos/o/r/issues/7/labels/missing" {
+			http.Error(w, `{"message": "Label does not exist"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]*github.Label{})
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	if err := handler.RemoveLabel(context.Background(), "o/r", 7, "P1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Labels the issue does not have are not an error
+	if err := handler.RemoveLabel(context.Background(), "o/r", 7, "missing"); err != nil {
+		t.Fatalf("Unexpected error for a missing label: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "DELETE /repos/o/r/issues/7/labels/P1" {
+		t.Errorf("Unexpected requests %v", paths)
+	}
+}
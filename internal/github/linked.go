@@ -0,0 +1,295 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// maxLinkedCacheEntries bounds the linked issue cache. Expired entries are
+// dropped first, then the oldest.
+const maxLinkedCacheEntries = 2000
+
+// LinkedIssueConfig controls prefetching the issues an issue references
+type LinkedIssueConfig struct {
+	Enabled  bool
+	TTL      time.Duration // How long a fetched issue is served from the cache
+	MaxLinks int           // References prefetched per issue
+}
+
+// IssueReference names an issue, e.g. #456 or acme/api#456
+type IssueReference struct {
+	Repository string
+	Number     int
+}
+
+// String formats the reference the way GitHub does
+func (r IssueReference) String() string {
+	return fmt.Sprintf("%s#%d", r.Repository, r.Number)
+}
+
+// LinkedIssue is the cached state of a referenced issue
+type LinkedIssue struct {
+	IssueReference
+	Title   string
+	State   string
+	URL     string
+	Summary string // The bot's last summary of the issue, if it posted one
+	Cached  bool   // False while the issue is still being fetched
+}
+
+// SummaryLookup finds the bot's last summary of an issue
+type SummaryLookup interface {
+	IssueSummary(repository string, number int) (string, bool)
+}
+
+// issueReferencePattern matches #456, acme/api#456 and links to issues and
+// pull requests. Numbers are capped at seven digits so colors such as
+// #ff0000 and #123456 in CSS snippets are rarely mistaken for issues.
+var issueReferencePattern = regexp.MustCompile(`(?:https://github\.com/([\w.-]+/[\w.-]+)/(?:issues|pull)/(\d+)|(?:^|[\s(\[,;])(?:([\w.-]+/[\w.-]+))?#(\d{1,7})\b)`)
+
+// IssueReferences returns the issues text references, in order of first
+// mention. Short references such as #456 belong to repository.
+func IssueReferences(repository, text string) []IssueReference {
+	var refs []IssueReference
+	seen := make(map[IssueReference]bool)
+	for _, match := range issueReferencePattern.FindAllStringSubmatch(text, -1) {
+		repo, number := match[1], match[2]
+		if number == "" {
+			repo, number = match[3], match[4]
+		}
+		if repo == "" {
+			repo = repository
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil || n <= 0 || repo == "" {
+			continue
+		}
+		ref := IssueReference{Repository: repo, Number: n}
+		key := IssueReference{Repository: strings.ToLower(repo), Number: n}
+		if !seen[key] {
+			seen[key] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// linkedEntry is a cached issue, or a failed fetch that is not retried until
+// it expires
+type linkedEntry struct {
+	issue     LinkedIssue
+	failed    bool
+	fetchedAt time.Time
+}
+
+// LinkedIssueCache keeps the titles and states of referenced issues, fetched
+// in the background when an issue is processed. Slack interactions must be
+// answered within three seconds, so they read the cache instead of calling
+// GitHub.
+type LinkedIssueCache struct {
+	mu        sync.Mutex
+	handler   *Handler
+	config    LinkedIssueConfig
+	summaries SummaryLookup
+	entries   map[IssueReference]*linkedEntry
+	pending   map[IssueReference]bool
+	links     map[IssueReference][]IssueReference // References by the referencing issue
+	now       func() time.Time
+}
+
+// NewLinkedIssueCache creates a cache that fetches issues with handler's client
+func NewLinkedIssueCache(handler *Handler, config LinkedIssueConfig) *LinkedIssueCache {
+	return &LinkedIssueCache{
+		handler: handler,
+		config:  config,
+		entries: make(map[IssueReference]*linkedEntry),
+		pending: make(map[IssueReference]bool),
+		links:   make(map[IssueReference][]IssueReference),
+		now:     time.Now,
+	}
+}
+
+// SetSummaryLookup sets where the bot's summaries of linked issues are found
+func (c *LinkedIssueCache) SetSummaryLookup(summaries SummaryLookup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.summaries = summaries
+}
+
+// SetLinkedIssues enables prefetching the issues that processed issues reference
+func (h *Handler) SetLinkedIssues(cache *LinkedIssueCache) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.linked = cache
+}
+
+func (h *Handler) linkedIssues() *LinkedIssueCache {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.linked
+}
+
+// Prefetch records the issues issueData references and fetches the ones not
+// cached in the background. It returns the references.
+func (c *LinkedIssueCache) Prefetch(ctx context.Context, issueData *IssueData) []IssueReference {
+	repository := issueData.Repository.GetFullName()
+	text := issueData.Issue.GetTitle() + "\n" + issueData.Issue.GetBody()
+	for _, comment := range issueData.Comments {
+		text += "\n" + comment.GetBody()
+	}
+
+	source := cacheKey(repository, issueData.Issue.GetNumber())
+	var refs, missing []IssueReference
+	for _, ref := range IssueReferences(repository, text) {
+		if cacheKey(ref.Repository, ref.Number) == source {
+			continue
+		}
+		if c.config.MaxLinks > 0 && len(refs) >= c.config.MaxLinks {
+			break
+		}
+		refs = append(refs, ref)
+	}
+
+	c.mu.Lock()
+	if _, ok := c.links[source]; !ok && len(c.links) >= maxLinkedCacheEntries {
+		for old := range c.links {
+			delete(c.links, old)
+			break
+		}
+	}
+	c.links[source] = refs
+	for _, ref := range refs {
+		key := cacheKey(ref.Repository, ref.Number)
+		if !c.fresh(key) && !c.pending[key] {
+			c.pending[key] = true
+			missing = append(missing, ref)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(missing) > 0 {
+		go c.fetch(ctx, missing)
+	}
+	return refs
+}
+
+// Linked returns the issues an issue references, as far as they are cached.
+// It never calls GitHub.
+func (c *LinkedIssueCache) Linked(repository string, number int) ([]LinkedIssue, bool) {
+	c.mu.Lock()
+	refs, ok := c.links[cacheKey(repository, number)]
+	summaries := c.summaries
+	issues := make([]LinkedIssue, 0, len(refs))
+	for _, ref := range refs {
+		entry, cached := c.entries[cacheKey(ref.Repository, ref.Number)]
+		switch {
+		case !cached:
+			issues = append(issues, LinkedIssue{IssueReference: ref})
+		case !entry.failed:
+			issues = append(issues, entry.issue)
+		}
+	}
+	c.mu.Unlock()
+
+	if summaries != nil {
+		for i := range issues {
+			if summary, found := summaries.IssueSummary(issues[i].Repository, issues[i].Number); found {
+				issues[i].Summary = summary
+			}
+		}
+	}
+	return issues, ok
+}
+
+// fetch loads issues into the cache one at a time
+func (c *LinkedIssueCache) fetch(ctx context.Context, refs []IssueReference) {
+	for _, ref := range refs {
+		entry := &linkedEntry{fetchedAt: c.now()}
+		issue, err := c.handler.fetchLinkedIssue(ctx, ref)
+		if err != nil {
+			entry.failed = true
+			c.handler.metrics.RecordGitHubAPIError("linked_issue", apperrors.Classify(err))
+			c.handler.logger.Debug("Failed to prefetch linked issue",
+				zap.String("issue", ref.String()),
+				zap.Error(err))
+		} else {
+			entry.issue = issue
+		}
+
+		key := cacheKey(ref.Repository, ref.Number)
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.entries[key] = entry
+		c.prune()
+		c.mu.Unlock()
+	}
+}
+
+// fresh reports whether an issue is cached and not expired. c.mu must be held.
+func (c *LinkedIssueCache) fresh(key IssueReference) bool {
+	entry, ok := c.entries[key]
+	return ok && (c.config.TTL <= 0 || c.now().Sub(entry.fetchedAt) < c.config.TTL)
+}
+
+// prune keeps the cache within maxLinkedCacheEntries. c.mu must be held.
+func (c *LinkedIssueCache) prune() {
+	if len(c.entries) <= maxLinkedCacheEntries {
+		return
+	}
+	keys := make([]IssueReference, 0, len(c.entries))
+	for key := range c.entries {
+		if !c.fresh(key) {
+			delete(c.entries, key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) <= maxLinkedCacheEntries {
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].fetchedAt.Before(c.entries[keys[j]].fetchedAt)
+	})
+	for _, key := range keys[:len(keys)-maxLinkedCacheEntries] {
+		delete(c.entries, key)
+	}
+}
+
+// fetchLinkedIssue reads the title and state of an issue
+func (h *Handler) fetchLinkedIssue(ctx context.Context, ref IssueReference) (LinkedIssue, error) {
+	owner, name, ok := strings.Cut(ref.Repository, "/")
+	if !ok {
+		return LinkedIssue{}, fmt.Errorf("invalid repository %q", ref.Repository)
+	}
+	if h.enrichTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.enrichTimeout)
+		defer cancel()
+	}
+	issue, _, err := h.githubClient().Issues.Get(ctx, owner, name, ref.Number)
+	if err != nil {
+		return LinkedIssue{}, err
+	}
+	return LinkedIssue{
+		IssueReference: ref,
+		Title:          issue.GetTitle(),
+		State:          issue.GetState(),
+		URL:            issue.GetHTMLURL(),
+		Cached:         true,
+	}, nil
+}
+
+// cacheKey identifies an issue regardless of the repository name's case
+func cacheKey(repository string, number int) IssueReference {
+	return IssueReference{Repository: strings.ToLower(repository), Number: number}
+}
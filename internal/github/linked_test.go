@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIssueReferences(t *testing.T) {
+	text := "Duplicate of #456, see also acme/api#12 and https://github.com/acme/web/issues/7.\n" +
+		"Again #456. Color: `#ff0000`, anchor docs#install and issue#99"
+	want := []IssueReference{
+		{Repository: "acme/app", Number: 456},
+		{Repository: "acme/api", Number: 12},
+		{Repository: "acme/web", Number: 7},
+	}
+	if got := IssueReferences("acme/app", text); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// summaryLookup is an in-memory SummaryLookup
+type summaryLookup map[int]string
+
+func (s summaryLookup) IssueSummary(repository string, number int) (string, bool) {
+	summary, ok := s[number]
+	return summary, ok
+}
+
+func TestLinkedIssueCache(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/repos/o/r/issues/2":
+			json.NewEncoder(w).Encode(github.Issue{Number: github.Int(2), Title: github.String("Login fails"), State: github.String("closed"), HTMLURL: github.String("https://github.com/o/r/issues/2")})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	handler.metrics.(*MockMetricsRecorder).On("RecordGitHubAPIError", mock.Anything, mock.Anything).Maybe()
+	cache := NewLinkedIssueCache(handler, LinkedIssueConfig{Enabled: true, TTL: time.Hour, MaxLinks: 10})
+	cache.SetSummaryLookup(summaryLookup{2: "Sessions expire immediately"})
+
+	issueData := &IssueData{
+		Issue:      &github.Issue{Number: github.Int(1), Body: github.String("Same as #2, unlike #3. Fixes #1")},
+		Repository: &github.Repository{FullName: github.String("o/r")},
+	}
+	refs := cache.Prefetch(context.Background(), issueData)
+	if want := []IssueReference{{"o/r", 2}, {"o/r", 3}}; !reflect.DeepEqual(refs, want) {
+		t.Fatalf("Expected %v without the issue itself, got %v", want, refs)
+	}
+
+	var linked []LinkedIssue
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		linked, _ = cache.Linked("o/r", 1)
+		if len(linked) == 1 {
+			break
+		}
+	}
+	// #3 does not exist, so only #2 is listed once both are fetched
+	if len(linked) != 1 || !linked[0].Cached || linked[0].Title != "Login fails" || linked[0].Summary != "Sessions expire immediately" {
+		t.Fatalf("Expected #2 with its summary, got %+v", linked)
+	}
+
+	// Cached issues are not fetched again
+	before := calls.Load()
+	cache.Prefetch(context.Background(), issueData)
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() != before {
+		t.Errorf("Expected no GitHub calls for cached issues, got %d", calls.Load()-before)
+	}
+
+	if _, ok := cache.Linked("o/r", 99); ok {
+		t.Error("Expected nothing for an issue that was never processed")
+	}
+}
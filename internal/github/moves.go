@@ -0,0 +1,115 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+)
+
+// IssueMover follows issues and repositories that moved on GitHub, so their
+// stored summaries and Slack threads keep working under the new names
+type IssueMover interface {
+	TransferIssue(ctx context.Context, transfer Transfer)
+	RenameRepository(ctx context.Context, from, to string)
+}
+
+// Transfer is an issue moved to another repository, which gives it a new
+// number
+type Transfer struct {
+	FromRepository string
+	FromNumber     int
+	ToRepository   string
+	ToNumber       int
+	URL            string // The issue's new URL
+	Sender         string // Login of the user who transferred it
+}
+
+// SetIssueMover follows issue transfers and repository renames and
+// transfers. Without one those events are skipped.
+func (h *Handler) SetIssueMover(mover IssueMover) {
+	h.mover = mover
+}
+
+// transferredEvent is an issues event with the transferred action, which
+// go-github does not model: changes name the issue in its new repository
+type transferredEvent struct {
+	Changes struct {
+		NewIssue      *github.Issue      `json:"new_issue"`
+		NewRepository *github.Repository `json:"new_repository"`
+	} `json:"changes"`
+}
+
+// handleTransfer follows an issue transferred out of event's repository
+func (h *Handler) handleTransfer(ctx context.Context, event *github.IssuesEvent, body []byte) (*IssueData, string, error) {
+	if h.mover == nil {
+		return nil, "skipped", nil
+	}
+	var transferred transferredEvent
+	if err := json.Unmarshal(body, &transferred); err != nil {
+		return nil, "error", fmt.Errorf("failed to unmarshal transferred issue: %w", err)
+	}
+	newIssue := transferred.Changes.NewIssue
+	newRepository := resolveRepository(newIssue, transferred.Changes.NewRepository)
+	from := resolveRepository(event.GetIssue(), event.GetRepo())
+	if newIssue.GetNumber() == 0 || newRepository.GetFullName() == "" || from.GetFullName() == "" {
+		return nil, "invalid", fmt.Errorf("%w: transferred issue without its old or new repository and number", errInvalidPayload)
+	}
+
+	transfer := Transfer{
+		FromRepository: from.GetFullName(),
+		FromNumber:     event.GetIssue().GetNumber(),
+		ToRepository:   newRepository.GetFullName(),
+		ToNumber:       newIssue.GetNumber(),
+		URL:            newIssue.GetHTMLURL(),
+		Sender:         event.GetSender().GetLogin(),
+	}
+	h.logger.Info("Issue transferred",
+		zap.String("repository", transfer.FromRepository),
+		zap.Int("issue_number", transfer.FromNumber),
+		zap.String("new_repository", transfer.ToRepository),
+		zap.Int("new_issue_number", transfer.ToNumber))
+	h.mover.TransferIssue(ctx, transfer)
+	return nil, "success", nil
+}
+
+// handleRepositoryEvent follows renamed repositories and repositories
+// transferred to another owner. Other repository actions are skipped.
+func (h *Handler) handleRepositoryEvent(ctx context.Context, body []byte) (*IssueData, string, error) {
+	var event github.RepositoryEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, "error", fmt.Errorf("failed to unmarshal repository event: %w", err)
+	}
+	if h.mover == nil || (event.GetAction() != "renamed" && event.GetAction() != "transferred") {
+		return nil, "skipped", nil
+	}
+
+	repository := event.GetRepo()
+	owner, name := repository.GetOwner().GetLogin(), repository.GetName()
+	if owner == "" || name == "" {
+		return nil, "invalid", fmt.Errorf("%w: repository event without a repository", errInvalidPayload)
+	}
+	oldOwner, oldName := owner, name
+	if changes := event.GetChanges(); changes != nil {
+		if from := changes.GetRepo().GetName().GetFrom(); from != "" {
+			oldName = from
+		}
+		if info := changes.GetOwner().GetOwnerInfo(); info != nil {
+			if login := info.GetUser().GetLogin(); login != "" {
+				oldOwner = login
+			} else if login := info.GetOrg().GetLogin(); login != "" {
+				oldOwner = login
+			}
+		}
+	}
+
+	from, to := oldOwner+"/"+oldName, owner+"/"+name
+	if from == to {
+		return nil, "skipped", nil
+	}
+	h.logger.Info("Repository moved", zap.String("repository", from), zap.String("new_repository", to))
+	h.mover.RenameRepository(ctx, from, to)
+	return nil, "success", nil
+}
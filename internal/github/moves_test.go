@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type fakeMover struct {
+	transfers []Transfer
+	renames   [][2]string
+}
+
+func (f *fakeMover) TransferIssue(ctx context.Context, transfer Transfer) {
+	f.transfers = append(f.transfers, transfer)
+}
+
+func (f *fakeMover) RenameRepository(ctx context.Context, from, to string) {
+	f.renames = append(f.renames, [2]string{from, to})
+}
+
+func TestHandleTransferredIssue(t *testing.T) {
+	mover := &fakeMover{}
+	handler := &Handler{logger: zap.NewNop(), mover: mover}
+
+	body := `{
+		"action": "transferred",
+		"issue": {"number": 12},
+		"repository": {"name": "api", "full_name": "acme/api", "owner": {"login": "acme"}},
+		"sender": {"login": "octocat"},
+		"changes": {
+			"new_issue": {"number": 3, "html_url": "https://github.com/acme/backend/issues/3"},
+			"new_repository": {"name": "backend", "full_name": "acme/backend", "owner": {"login": "acme"}}
+		}
+	}`
+	issueData, status, err := handler.dispatchEvent(context.Background(), "issues", []byte(body))
+	if err != nil || issueData != nil || status != "success" {
+		t.Fatalf("Expected the transfer handled without processing, got %v, %q, %v", issueData, status, err)
+	}
+	want := Transfer{
+		FromRepository: "acme/api",
+		FromNumber:     12,
+		ToRepository:   "acme/backend",
+		ToNumber:       3,
+		URL:            "https://github.com/acme/backend/issues/3",
+		Sender:         "octocat",
+	}
+	if len(mover.transfers) != 1 || mover.transfers[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, mover.transfers)
+	}
+
+	if _, _, err := handler.dispatchEvent(context.Background(), "issues", []byte(`{"action": "transferred", "issue": {"number": 12}, "changes": {}}`)); err == nil {
+		t.Error("Expected an error for a transfer without its new issue")
+	}
+}
+
+func TestHandleRepositoryEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status string
+		rename [2]string
+	}{
+		{
+			name:   "renamed",
+			body:   `{"action": "renamed", "repository": {"name": "backend", "owner": {"login": "acme"}}, "changes": {"repository": {"name": {"from": "api"}}}}`,
+			status: "success",
+			rename: [2]string{"acme/api", "acme/backend"},
+		},
+		{
+			name:   "transferred",
+			body:   `{"action": "transferred", "repository": {"name": "api", "owner": {"login": "acme-platform"}}, "changes": {"owner": {"from": {"organization": {"login": "acme"}}}}}`,
+			status: "success",
+			rename: [2]string{"acme/api", "acme-platform/api"},
+		},
+		{
+			name:   "other action",
+			body:   `{"action": "archived", "repository": {"name": "api", "owner": {"login": "acme"}}}`,
+			status: "skipped",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mover := &fakeMover{}
+			handler := &Handler{logger: zap.NewNop(), mover: mover}
+			issueData, status, err := handler.dispatchEvent(context.Background(), "repository", []byte(tt.body))
+			if err != nil || issueData != nil || status != tt.status {
+				t.Fatalf("Expected status %q, got %v, %q, %v", tt.status, issueData, status, err)
+			}
+			if tt.rename == ([2]string{}) {
+				if len(mover.renames) != 0 {
+					t.Errorf("Expected no rename, got %v", mover.renames)
+				}
+				return
+			}
+			if len(mover.renames) != 1 || mover.renames[0] != tt.rename {
+				t.Errorf("Expected %v, got %v", tt.rename, mover.renames)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// Webhook is a repository or organization webhook
+type Webhook struct {
+	ID           int64    `json:"id"`
+	URL          string   `json:"url"`
+	Active       bool     `json:"active"`
+	Events       []string `json:"events"`
+	Organization bool     `json:"organization"`            // Set on the repository's organization
+	LastResponse int      `json:"last_response,omitempty"` // HTTP status of the last delivery, 0 when unknown
+}
+
+// Delivers reports whether the webhook sends events of type event
+func (w Webhook) Delivers(event string) bool {
+	for _, e := range w.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// FindWebhook returns the webhook of a repository, or else of its
+// organization, that delivers to url. With url empty, the first one that
+// delivers issue events is returned. Listing webhooks needs the Webhooks read
+// permission (admin:repo_hook for classic tokens); organization webhooks are
+// skipped when they cannot be listed.
+func (h *Handler) FindWebhook(ctx context.Context, repo, url string) (Webhook, bool, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return Webhook{}, false, fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	client := h.githubClient()
+	hooks, _, err := client.Repositories.ListHooks(ctx, parts[0], parts[1], &github.ListOptions{PerPage: 100})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_hooks", apperrors.Classify(err))
+		return Webhook{}, false, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	if webhook, ok := matchWebhook(hooks, url, false); ok {
+		return webhook, true, nil
+	}
+
+	// User repositories have no organization
+	orgHooks, _, err := client.Organizations.ListHooks(ctx, parts[0], &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return Webhook{}, false, nil
+	}
+	webhook, ok := matchWebhook(orgHooks, url, true)
+	return webhook, ok, nil
+}
+
+// matchWebhook returns the first hook that delivers to url, or with url
+// empty the first that delivers issue events
+func matchWebhook(hooks []*github.Hook, url string, organization bool) (Webhook, bool) {
+	url = strings.TrimSuffix(url, "/")
+	for _, hook := range hooks {
+		hookURL, _ := hook.Config["url"].(string)
+		webhook := Webhook{
+			ID:           hook.GetID(),
+			URL:          strings.TrimSuffix(hookURL, "/"),
+			Active:       hook.GetActive(),
+			Events:       hook.Events,
+			Organization: organization,
+		}
+		if code, ok := hook.LastResponse["code"].(float64); ok {
+			webhook.LastResponse = int(code)
+		}
+		if url != "" && strings.EqualFold(webhook.URL, url) || url == "" && webhook.Delivers("issues") {
+			return webhook, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// LatestIssueNumber returns the number of the repository's most recently
+// opened issue, 0 when it has none. Pull requests are skipped.
+func (h *Handler) LatestIssueNumber(ctx context.Context, repo string) (int, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	issues, _, err := h.githubClient().Issues.ListByRepo(ctx, parts[0], parts[1], &github.IssueListByRepoOptions{
+		State:       "all",
+		Sort:        "created",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 20},
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_issues", apperrors.Classify(err))
+		return 0, fmt.Errorf("failed to list issues: %w", err)
+	}
+	for _, issue := range issues {
+		if !issue.IsPullRequest() {
+			return issue.GetNumber(), nil
+		}
+	}
+	return 0, nil
+}
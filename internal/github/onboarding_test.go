@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindWebhook(t *testing.T) {
+	handler := newDiagnosticsTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/hooks":
+			w.Write([]byte(`[{"id": 1, "active": true, "events": ["push"], "config": {"url": "https://ci.example.com/hook"}}]`))
+		case "/orgs/o/hooks":
+			w.Write([]byte(`[{"id": 2, "active": true, "events": ["issues", "issue_comment"], "config": {"url": "https://notifyops.example.com/webhook/github/"}, "last_response": {"code": 401, "status": "active"}}]`))
+		case "/repos/u/r/hooks":
+			w.Write([]byte(`[]`))
+		case "/orgs/u/hooks":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	})
+
+	webhook, ok, err := handler.FindWebhook(context.Background(), "o/r", "https://notifyops.example.com/webhook/github")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Webhook{ID: 2, URL: "https://notifyops.example.com/webhook/github", Active: true, Events: []string{"issues", "issue_comment"}, Organization: true, LastResponse: 401}, webhook)
+
+	// Without a URL any webhook with issue events will do
+	webhook, ok, _ = handler.FindWebhook(context.Background(), "o/r", "")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), webhook.ID)
+
+	// User repositories have no organization webhooks
+	_, ok, err = handler.FindWebhook(context.Background(), "u/r", "")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLatestIssueNumber(t *testing.T) {
+	handler := newDiagnosticsTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/o/r/issues", r.URL.Path)
+		assert.Equal(t, "created", r.URL.Query().Get("sort"))
+		w.Write([]byte(`[{"number": 9, "pull_request": {"url": "https://api.github.com/repos/o/r/pulls/9"}}, {"number": 8}]`))
+	})
+
+	number, err := handler.LatestIssueNumber(context.Background(), "o/r")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, number)
+}
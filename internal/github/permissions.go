@@ -0,0 +1,153 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/diagnostics"
+)
+
+// zeroSHA is a commit that never exists, so probing with it changes nothing
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// TokenNeeds is what the enabled features need the access token to do
+type TokenNeeds struct {
+	Write  bool   // Comment on, label, react to and close issues
+	Checks string // CheckConfig.Mode of triage results on pull requests
+}
+
+// permissionProbe is a request GitHub refuses without a permission
+type permissionProbe struct {
+	permission string // As named in the fine-grained token settings
+	write      bool   // Sent invalid, so it is rejected after the permission check
+	request    func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error)
+}
+
+// probes returns the requests that check the permissions needs calls for
+func (needs TokenNeeds) probes() []permissionProbe {
+	page := github.ListOptions{PerPage: 1}
+	probes := []permissionProbe{
+		{"Issues: read (issues)", false, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+			_, resp, err := client.Issues.ListByRepo(ctx, owner, repo, &github.IssueListByRepoOptions{ListOptions: page})
+			return resp, err
+		}},
+		{"Issues: read (comments)", false, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+			_, resp, err := client.Issues.ListComments(ctx, owner, repo, 0, &github.IssueListCommentsOptions{ListOptions: page})
+			return resp, err
+		}},
+		{"Contents: read (commits)", false, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+			_, resp, err := client.Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{ListOptions: page})
+			return resp, err
+		}},
+	}
+	if needs.Write {
+		// Issue 0 never exists
+		probes = append(probes,
+			permissionProbe{"Issues: write (comments and reactions)", true, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+				_, resp, err := client.Issues.CreateComment(ctx, owner, repo, 0, &github.IssueComment{})
+				return resp, err
+			}},
+			permissionProbe{"Issues: write (labels)", true, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+				_, resp, err := client.Issues.AddLabelsToIssue(ctx, owner, repo, 0, []string{})
+				return resp, err
+			}})
+	}
+	if needs.Checks != "" {
+		probes = append(probes, permissionProbe{"Pull requests: read (linked pull requests)", false, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+			_, resp, err := client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{ListOptions: page})
+			return resp, err
+		}})
+	}
+	switch needs.Checks {
+	case CheckModeStatus:
+		probes = append(probes, permissionProbe{"Commit statuses: write (triage statuses)", true, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+			_, resp, err := client.Repositories.CreateStatus(ctx, owner, repo, zeroSHA, &github.RepoStatus{})
+			return resp, err
+		}})
+	case CheckModeCheckRun:
+		probes = append(probes, permissionProbe{"Checks: write (triage check runs)", true, func(ctx context.Context, client *github.Client, owner, repo string) (*github.Response, error) {
+			_, resp, err := client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{HeadSHA: zeroSHA})
+			return resp, err
+		}})
+	}
+	return probes
+}
+
+// MissingPermissions returns the permissions the access token lacks on
+// repository for needs, by sending requests GitHub refuses without them.
+// Writes are probed with invalid requests, so nothing is changed. Other
+// failures, e.g. GitHub being unreachable, are returned as errors.
+func (h *Handler) MissingPermissions(ctx context.Context, repository string, needs TokenNeeds) ([]string, error) {
+	parts := strings.Split(repository, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid repository %q", repository)
+	}
+
+	client := h.githubClient()
+	var missing []string
+	for _, probe := range needs.probes() {
+		resp, err := probe.request(ctx, client, parts[0], parts[1])
+		granted, err := permissionGranted(resp, err, probe.write)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", probe.permission, classifyError(err))
+		}
+		if !granted {
+			missing = append(missing, probe.permission)
+		}
+	}
+	return missing, nil
+}
+
+// permissionGranted reads a probe's response. Reads are refused with 403 or,
+// for private repositories, 404. Writes get past the permission check when
+// GitHub rejects them as invalid or for the issue not existing.
+func permissionGranted(resp *github.Response, err error, write bool) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateErr) || errors.As(err, &abuseErr) || resp == nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		return false, nil
+	case http.StatusNotFound:
+		return write, nil
+	case http.StatusUnprocessableEntity:
+		if write {
+			return true, nil
+		}
+	case http.StatusConflict:
+		// Listing the commits of an empty repository
+		return true, nil
+	}
+	return false, err
+}
+
+// DiagnosePermissions returns a checker of the permissions the access token
+// has on repository
+func (h *Handler) DiagnosePermissions(repository string, needs TokenNeeds) diagnostics.Checker {
+	return func(ctx context.Context) []diagnostics.Check {
+		if repository == "" {
+			return []diagnostics.Check{diagnostics.Warning("github_permissions",
+				"No repository to check the token's permissions on; set GITHUB_PERMISSION_CHECK_REPOSITORY")}
+		}
+		missing, err := h.MissingPermissions(ctx, repository, needs)
+		if err != nil {
+			return []diagnostics.Check{diagnostics.Warning("github_permissions",
+				fmt.Sprintf("Could not check the token's permissions on %s: %v", repository, err))}
+		}
+		if len(missing) > 0 {
+			return []diagnostics.Check{diagnostics.Error("github_permissions",
+				fmt.Sprintf("The token is missing permissions on %s: %s", repository, strings.Join(missing, ", ")))}
+		}
+		return []diagnostics.Check{diagnostics.OK("github_permissions", "The token has the permissions it needs on "+repository)}
+	}
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github-issue-ai-bot/internal/diagnostics"
+)
+
+func TestMissingPermissions(t *testing.T) {
+	// A token that can read issues and comment, but not read commits or set
+	// commit statuses
+	handler := newDiagnosticsTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/o/r/issues", "GET /repos/o/r/issues/comments", "GET /repos/o/r/pulls":
+			w.Write([]byte(`[]`))
+		case "GET /repos/o/r/commits":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		case "POST /repos/o/r/issues/0/comments":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "Validation Failed"}`))
+		case "POST /repos/o/r/issues/0/labels":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		case "POST /repos/o/r/statuses/" + zeroSHA:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Resource not accessible by personal access token"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	missing, err := handler.MissingPermissions(context.Background(), "o/r", TokenNeeds{Write: true, Checks: CheckModeStatus})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Contents: read (commits)", "Commit statuses: write (triage statuses)"}, missing)
+
+	checks := handler.DiagnosePermissions("o/r", TokenNeeds{Write: true, Checks: CheckModeStatus})(context.Background())
+	assert.Equal(t, diagnostics.StatusError, checks[0].Status)
+	assert.Contains(t, checks[0].Message, "Contents: read (commits), Commit statuses: write")
+
+	_, err = handler.MissingPermissions(context.Background(), "o", TokenNeeds{})
+	assert.Error(t, err)
+}
+
+func TestMissingPermissionsUnavailable(t *testing.T) {
+	handler := newDiagnosticsTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	// Outages are not missing permissions
+	_, err := handler.MissingPermissions(context.Background(), "o/r", TokenNeeds{Write: true})
+	assert.Error(t, err)
+	checks := handler.DiagnosePermissions("o/r", TokenNeeds{Write: true})(context.Background())
+	assert.Equal(t, diagnostics.StatusWarning, checks[0].Status)
+
+	checks = handler.DiagnosePermissions("", TokenNeeds{Write: true})(context.Background())
+	assert.Equal(t, diagnostics.StatusWarning, checks[0].Status)
+}
@@ -0,0 +1,253 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// maxPollPages bounds the pages of issues and comments read per poll. Both
+// are read oldest first, so whatever is left is read by the next poll.
+const maxPollPages = 10
+
+// PollConfig lists repositories whose issues are read through the REST API,
+// for repositories where webhooks cannot be installed
+type PollConfig struct {
+	Repositories []string      // owner/name, empty to disable polling
+	Interval     time.Duration // How often each repository is polled
+	Lookback     time.Duration // How far back the first poll of a repository reads, 0 for only new activity
+}
+
+// PollCursors keeps how far each polled repository has been read, so a poll
+// continues where the previous one stopped
+type PollCursors interface {
+	PollCursor(repository string) (time.Time, bool)
+	SavePollCursor(repository string, cursor time.Time)
+}
+
+// RunPolling polls the configured repositories at once and then at each
+// interval until the context is cancelled
+func (h *Handler) RunPolling(ctx context.Context, config PollConfig, cursors PollCursors) {
+	if len(config.Repositories) == 0 || config.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	for {
+		for _, repo := range config.Repositories {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := h.PollRepository(ctx, repo, cursors, config.Lookback); err != nil {
+				h.logger.Warn("Failed to poll repository", zap.String("repository", repo), zap.Error(err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollRepository reads the issues and comments updated since the
+// repository's cursor and feeds them to the pipeline as the webhooks GitHub
+// would have sent. New issues arrive as opened, closed ones as closed, and
+// other changes as edited; comments arrive as created or edited.
+func (h *Handler) PollRepository(ctx context.Context, repo string, cursors PollCursors, lookback time.Duration) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+	owner, name := parts[0], parts[1]
+
+	since, ok := cursors.PollCursor(repo)
+	if !ok {
+		since = time.Now().Add(-lookback)
+	}
+	issues, err := h.listUpdatedIssues(ctx, owner, name, since)
+	if err != nil {
+		return err
+	}
+	comments, err := h.listUpdatedComments(ctx, owner, name, since)
+	if err != nil {
+		return err
+	}
+
+	repository := &github.Repository{
+		FullName: github.String(repo),
+		Name:     github.String(name),
+		Owner:    &github.User{Login: github.String(owner)},
+	}
+	commented := make(map[int]bool, len(comments))
+	for _, comment := range comments {
+		commented[CommentIssueNumber(comment)] = true
+	}
+
+	cursor := since
+	polled := make(map[int]*github.Issue, len(issues))
+	for _, issue := range issues {
+		if updated := issue.GetUpdatedAt().Time; updated.After(cursor) {
+			cursor = updated
+		}
+		if issue.Repository == nil {
+			issue.Repository = repository
+		}
+		polled[issue.GetNumber()] = issue
+		if issue.IsPullRequest() {
+			continue
+		}
+
+		// An issue updated only by new comments is handled with the comments
+		action := polledAction(issue, since)
+		if action == "edited" && commented[issue.GetNumber()] {
+			continue
+		}
+		h.deliverPolled(ctx, "issues", &github.IssuesEvent{Action: github.String(action), Issue: issue, Repo: repository})
+	}
+
+	for _, comment := range comments {
+		if updated := comment.GetUpdatedAt().Time; updated.After(cursor) {
+			cursor = updated
+		}
+		number := CommentIssueNumber(comment)
+		issue, ok := polled[number]
+		if !ok {
+			issue, _, err = h.githubClient().Issues.Get(ctx, owner, name, number)
+			if err != nil {
+				err = classifyError(err)
+				h.metrics.RecordGitHubAPIError("poll_issue", apperrors.Classify(err))
+				h.logger.Warn("Failed to fetch commented issue",
+					zap.String("repository", repo),
+					zap.Int("issue_number", number),
+					zap.Error(err))
+				continue
+			}
+			issue.Repository = repository
+		}
+		if issue.IsPullRequest() {
+			continue
+		}
+
+		action := "created"
+		if !comment.GetCreatedAt().After(since) {
+			action = "edited"
+		}
+		h.deliverPolled(ctx, "issue_comment", &github.IssueCommentEvent{Action: github.String(action), Issue: issue, Comment: comment, Repo: repository})
+	}
+
+	cursors.SavePollCursor(repo, cursor)
+	return nil
+}
+
+// polledAction is the webhook action a polled issue most likely caused
+func polledAction(issue *github.Issue, since time.Time) string {
+	switch {
+	case issue.GetCreatedAt().After(since):
+		return "opened"
+	case issue.GetState() == "closed" && issue.GetClosedAt().After(since):
+		return "closed"
+	default:
+		return "edited"
+	}
+}
+
+// CommentIssueNumber reads the issue number from a comment's issue URL, 0
+// if it has none
+func CommentIssueNumber(comment *github.IssueComment) int {
+	number, _ := strconv.Atoi(path.Base(comment.GetIssueURL()))
+	return number
+}
+
+// deliverPolled processes a polled event the way HandleEvent processes a
+// webhook, before returning
+func (h *Handler) deliverPolled(ctx context.Context, eventType string, event interface{}) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error("Failed to encode polled event", zap.String("event_type", eventType), zap.Error(err))
+		return
+	}
+	issueData, _, err := h.dispatchEvent(ctx, eventType, body)
+	if err != nil {
+		h.logger.Error("Failed to process polled event", zap.String("event_type", eventType), zap.Error(err))
+		return
+	}
+	if issueData == nil {
+		return
+	}
+	issueData.ReceivedAt = time.Now()
+	h.processIssueData(ctx, issueData)
+}
+
+// listUpdatedIssues lists the issues and pull requests updated after since,
+// oldest first
+func (h *Handler) listUpdatedIssues(ctx context.Context, owner, repo string, since time.Time) ([]*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:       "all",
+		Sort:        "updated",
+		Direction:   "asc",
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var updated []*github.Issue
+	for page := 0; page < maxPollPages; page++ {
+		issues, resp, err := h.githubClient().Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			err = classifyError(err)
+			h.metrics.RecordGitHubAPIError("poll_issues", apperrors.Classify(err))
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		// since is inclusive, and the issue at the cursor was read last time
+		for _, issue := range issues {
+			if issue.GetUpdatedAt().After(since) {
+				updated = append(updated, issue)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return updated, nil
+}
+
+// listUpdatedComments lists the repository's issue comments updated after
+// since, oldest first
+func (h *Handler) listUpdatedComments(ctx context.Context, owner, repo string, since time.Time) ([]*github.IssueComment, error) {
+	sort, direction := "updated", "asc"
+	opts := &github.IssueListCommentsOptions{
+		Sort:        &sort,
+		Direction:   &direction,
+		Since:       &since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var updated []*github.IssueComment
+	for page := 0; page < maxPollPages; page++ {
+		comments, resp, err := h.githubClient().Issues.ListComments(ctx, owner, repo, 0, opts)
+		if err != nil {
+			err = classifyError(err)
+			h.metrics.RecordGitHubAPIError("poll_comments", apperrors.Classify(err))
+			return nil, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, comment := range comments {
+			if comment.GetUpdatedAt().After(since) {
+				updated = append(updated, comment)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return updated, nil
+}
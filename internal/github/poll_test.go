@@ -0,0 +1,105 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingProcessor records the issues it is asked to process
+type recordingProcessor struct {
+	events []string
+}
+
+func (p *recordingProcessor) ProcessIssue(ctx context.Context, issueData *IssueData) {
+	p.events = append(p.events, fmt.Sprintf("%s.%s #%d", issueData.EventType, issueData.Action, issueData.Issue.GetNumber()))
+}
+
+// pollCursors is an in-memory PollCursors
+type pollCursors map[string]time.Time
+
+func (c pollCursors) PollCursor(repository string) (time.Time, bool) {
+	cursor, ok := c[repository]
+	return cursor, ok
+}
+
+func (c pollCursors) SavePollCursor(repository string, cursor time.Time) {
+	c[repository] = cursor
+}
+
+func TestPollRepository(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) *github.Timestamp {
+		return &github.Timestamp{Time: since.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/issues":
+			if r.URL.Query().Get("since") != since.Format(time.RFC3339) {
+				t.Errorf("Expected issues since the cursor, got %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode([]*github.Issue{
+				{Number: github.Int(1), State: github.String("open"), CreatedAt: at(-60), UpdatedAt: at(0)}, // Read by the previous poll
+				{Number: github.Int(2), State: github.String("open"), CreatedAt: at(1), UpdatedAt: at(1)},
+				{Number: github.Int(3), State: github.String("closed"), CreatedAt: at(-60), UpdatedAt: at(2), ClosedAt: at(2)},
+				{Number: github.Int(4), State: github.String("open"), CreatedAt: at(-60), UpdatedAt: at(3)},
+				{Number: github.Int(5), State: github.String("open"), CreatedAt: at(-60), UpdatedAt: at(4)},
+				{Number: github.Int(6), State: github.String("open"), CreatedAt: at(5), UpdatedAt: at(5), PullRequestLinks: &github.PullRequestLinks{}},
+			})
+		case "/repos/o/r/issues/comments":
+			json.NewEncoder(w).Encode([]*github.IssueComment{
+				{ID: github.Int64(10), Body: github.String("Same here"), IssueURL: github.String("https://api.github.com/repos/o/r/issues/5"), CreatedAt: at(4), UpdatedAt: at(4)},
+				{ID: github.Int64(11), Body: github.String("Still broken"), IssueURL: github.String("https://api.github.com/repos/o/r/issues/8"), CreatedAt: at(6), UpdatedAt: at(6)},
+			})
+		case "/repos/o/r/issues/8":
+			json.NewEncoder(w).Encode(github.Issue{Number: github.Int(8), State: github.String("open")})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	handler.closeSuggestions = false
+	handler.metrics.(*MockMetricsRecorder).On("RecordGitHubAPIError", mock.Anything, mock.Anything).Maybe()
+	processor := &recordingProcessor{}
+	handler.SetIssueProcessor(processor)
+
+	cursors := pollCursors{"o/r": since}
+	if err := handler.PollRepository(context.Background(), "o/r", cursors, 0); err != nil {
+		t.Fatalf("PollRepository: %v", err)
+	}
+
+	want := []string{
+		"issues.opened #2",
+		"issues.closed #3",
+		"issues.edited #4",
+		"issue_comment.created #5",
+		"issue_comment.created #8",
+	}
+	if !reflect.DeepEqual(processor.events, want) {
+		t.Errorf("Expected %v, got %v", want, processor.events)
+	}
+	if got := cursors["o/r"]; !got.Equal(at(6).Time) {
+		t.Errorf("Expected the cursor at the last update %v, got %v", at(6).Time, got)
+	}
+}
+
+func TestPollRepositoryInvalid(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	if err := handler.PollRepository(context.Background(), "no-slash", pollCursors{}, 0); err == nil {
+		t.Error("Expected an error for a repository without an owner")
+	}
+}
@@ -0,0 +1,72 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// reactions are the reaction contents GitHub accepts
+var reactions = map[string]bool{
+	"+1": true, "-1": true, "laugh": true, "confused": true,
+	"heart": true, "hooray": true, "rocket": true, "eyes": true,
+}
+
+// ValidReaction reports whether GitHub accepts reaction, e.g. "eyes" for 👀
+func ValidReaction(reaction string) bool {
+	return reactions[reaction]
+}
+
+// ReactToIssue adds a reaction to an issue, e.g. to show it has been triaged.
+// Adding a reaction the bot already left does nothing.
+func (h *Handler) ReactToIssue(ctx context.Context, repo string, number int, reaction string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	if _, _, err := h.githubClient().Reactions.CreateIssueReaction(ctx, parts[0], parts[1], number, reaction); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("add_reaction", apperrors.Classify(err))
+		return fmt.Errorf("failed to react to issue: %w", err)
+	}
+	return nil
+}
+
+// RemoveIssueReaction removes a reaction the bot left on an issue. GitHub
+// returns the bot's existing reaction when it is added again, which is how
+// its ID is found without listing every reaction on the issue.
+func (h *Handler) RemoveIssueReaction(ctx context.Context, repo string, number int, reaction string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	existing, _, err := h.githubClient().Reactions.CreateIssueReaction(ctx, parts[0], parts[1], number, reaction)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("remove_reaction", apperrors.Classify(err))
+		return fmt.Errorf("failed to find issue reaction: %w", err)
+	}
+	resp, err := h.githubClient().Reactions.DeleteIssueReaction(ctx, parts[0], parts[1], number, existing.GetID())
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("remove_reaction", apperrors.Classify(err))
+		return fmt.Errorf("failed to remove issue reaction: %w", err)
+	}
+
+	h.logger.Debug("Removed issue reaction",
+		zap.String("repository", repo),
+		zap.Int("issue_number", number),
+		zap.String("reaction", reaction),
+	)
+	return nil
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestIssueReactions(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reaction struct{ Content string }
+		json.NewDecoder(r.Body).Decode(&reaction)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+reaction.Content)
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(github.Reaction{ID: github.Int64(42), Content: github.String(reaction.Content)})
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	if err := handler.ReactToIssue(context.Background(), "o/r", 7, "eyes"); err != nil {
+		t.Fatalf("ReactToIssue: %v", err)
+	}
+	if err := handler.RemoveIssueReaction(context.Background(), "o/r", 7, "eyes"); err != nil {
+		t.Fatalf("RemoveIssueReaction: %v", err)
+	}
+	want := []string{
+		"POST /repos/o/r/issues/7/reactions eyes",
+		"POST /repos/o/r/issues/7/reactions eyes",
+		"DELETE /repos/o/r/issues/7/reactions/42 ",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("Unexpected requests %q", requests)
+	}
+
+	if ValidReaction("thumbsup") || !ValidReaction("eyes") {
+		t.Error("Expected only GitHub's reaction contents to be valid")
+	}
+}
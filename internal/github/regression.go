@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// Limits of regression detection, which costs a few API calls per issue
+const (
+	maxMentionedFiles   = 10 // Files taken from the report
+	maxRegressionChecks = 3  // Releases compared with the release before them
+	maxRegressionHints  = 3
+)
+
+// RegressionConfig controls hints about the release that may have
+// introduced a reported bug
+type RegressionConfig struct {
+	Enabled bool
+	Window  time.Duration // How long before the first report releases are considered
+}
+
+// RegressionHint is a commit to a file the report mentions that shipped in a
+// release shortly before the issue was first reported
+type RegressionHint struct {
+	Release    string        // Tag of the release, e.g. v1.4.2
+	ReleaseURL string        // Release page
+	Commit     string        // Short SHA of the last commit to the file in the release
+	CommitURL  string        // Commit page
+	File       string        // Path of the mentioned file in the repository
+	Before     time.Duration // Time from the commit to the first report
+}
+
+// String describes the hint, e.g. "possibly introduced in v1.4.2 (commit
+// abc1234 touched auth.go 3 days before the first report)"
+func (h RegressionHint) String() string {
+	return fmt.Sprintf("possibly introduced in %s (commit %s touched %s %s before the first report)",
+		h.Release, h.Commit, path.Base(h.File), humanizeAge(h.Before))
+}
+
+// humanizeAge formats a duration in days, or hours when under a day
+func humanizeAge(d time.Duration) string {
+	if hours := int(d.Hours()); hours < 24 {
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	if days := int(d.Hours() / 24); days != 1 {
+		return fmt.Sprintf("%d days", days)
+	}
+	return "1 day"
+}
+
+// mentionedFilePattern matches source file paths, including those in stack
+// traces such as "internal/auth/auth.go:42"
+var mentionedFilePattern = regexp.MustCompile(`(?:[\w.-]+/)*[\w-][\w.-]*\.(?:go|py|js|jsx|mjs|ts|tsx|rb|java|kt|rs|c|cc|cpp|h|hpp|cs|php|swift|scala|sh|sql|vue|svelte|ya?ml|toml)\b`)
+
+// MentionedFiles returns the source files an issue body mentions, in order
+func MentionedFiles(body string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, match := range mentionedFilePattern.FindAllString(body, -1) {
+		match = strings.TrimPrefix(match, "./")
+		if seen[match] || len(files) >= maxMentionedFiles {
+			continue
+		}
+		seen[match] = true
+		files = append(files, match)
+	}
+	return files
+}
+
+// matchesMentioned reports whether a repository path is one of the mentioned
+// files, which may be given as a bare file name or a partial path
+func matchesMentioned(filename string, mentioned []string) bool {
+	for _, m := range mentioned {
+		if filename == m || strings.HasSuffix(filename, "/"+m) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRegressionHints sets how regression hints are detected
+func (h *Handler) SetRegressionHints(config RegressionConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.regressions = config
+}
+
+func (h *Handler) regressionConfig() RegressionConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.regressions
+}
+
+// detectRegressions looks for the files the report mentions in the releases
+// published within the window before the issue was opened. Each release is
+// compared with the one before it, and the last commit to a mentioned file
+// in it becomes a hint, newest release first.
+func (h *Handler) detectRegressions(ctx context.Context, owner, repo string, issue *github.Issue) []RegressionHint {
+	config := h.regressionConfig()
+	if !config.Enabled || issue.GetState() == "closed" || issue.IsPullRequest() {
+		return nil
+	}
+	mentioned := MentionedFiles(issue.GetBody())
+	if len(mentioned) == 0 {
+		return nil
+	}
+	reported := issue.GetCreatedAt().Time
+
+	releases, _, err := h.githubClient().Repositories.ListReleases(ctx, owner, repo, &github.ListOptions{PerPage: 20})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_releases", apperrors.Classify(err))
+		h.logger.Warn("Failed to list releases for regression hints", zap.Error(err))
+		return nil
+	}
+	published := releases[:0]
+	for _, release := range releases {
+		if !release.GetDraft() && !release.GetPublishedAt().IsZero() && release.GetPublishedAt().Before(reported) {
+			published = append(published, release)
+		}
+	}
+	sort.Slice(published, func(i, j int) bool {
+		return published[i].GetPublishedAt().After(published[j].GetPublishedAt().Time)
+	})
+
+	var hints []RegressionHint
+	for i := 0; i+1 < len(published) && i < maxRegressionChecks && len(hints) < maxRegressionHints; i++ {
+		release, base := published[i], published[i+1]
+		if reported.Sub(release.GetPublishedAt().Time) > config.Window {
+			break
+		}
+		comparison, _, err := h.githubClient().Repositories.CompareCommits(ctx, owner, repo, base.GetTagName(), release.GetTagName(), &github.ListOptions{PerPage: 100})
+		if err != nil {
+			err = classifyError(err)
+			h.metrics.RecordGitHubAPIError("compare_releases", apperrors.Classify(err))
+			h.logger.Warn("Failed to compare releases for regression hints",
+				zap.String("release", release.GetTagName()),
+				zap.Error(err))
+			continue
+		}
+		for _, file := range comparison.Files {
+			if len(hints) >= maxRegressionHints {
+				break
+			}
+			if !matchesMentioned(file.GetFilename(), mentioned) {
+				continue
+			}
+			if hint, ok := h.regressionHint(ctx, owner, repo, release, base, file.GetFilename(), reported); ok {
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return hints
+}
+
+// regressionHint finds the last commit to file between two releases
+func (h *Handler) regressionHint(ctx context.Context, owner, repo string, release, base *github.RepositoryRelease, file string, reported time.Time) (RegressionHint, bool) {
+	commits, _, err := h.githubClient().Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{
+		SHA:         release.GetTagName(),
+		Path:        file,
+		Since:       base.GetPublishedAt().Time,
+		Until:       release.GetPublishedAt().Time,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_commits", apperrors.Classify(err))
+		h.logger.Warn("Failed to list commits for regression hints", zap.String("file", file), zap.Error(err))
+		return RegressionHint{}, false
+	}
+	if len(commits) == 0 {
+		return RegressionHint{}, false
+	}
+	commit := commits[0]
+	sha := commit.GetSHA()
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	return RegressionHint{
+		Release:    release.GetTagName(),
+		ReleaseURL: release.GetHTMLURL(),
+		Commit:     sha,
+		CommitURL:  commit.GetHTMLURL(),
+		File:       file,
+		Before:     reported.Sub(commit.GetCommit().GetCommitter().GetDate().Time),
+	}, true
+}
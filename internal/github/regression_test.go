@@ -0,0 +1,98 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestMentionedFiles(t *testing.T) {
+	body := "Login fails since the update.\n\n```\npanic: nil map\n  at internal/auth/auth.go:42\n  at ./cmd/server/main.go:10\n```\nAlso see auth.go and README.md, version 1.4.2."
+	want := []string{"internal/auth/auth.go", "cmd/server/main.go", "auth.go"}
+	if got := MentionedFiles(body); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestRegressionHintString(t *testing.T) {
+	hint := RegressionHint{Release: "v1.4.2", Commit: "abc1234", File: "internal/auth/auth.go", Before: 3*24*time.Hour + 5*time.Hour}
+	if got := hint.String(); got != "possibly introduced in v1.4.2 (commit abc1234 touched auth.go 3 days before the first report)" {
+		t.Errorf("Unexpected hint %q", got)
+	}
+}
+
+func TestDetectRegressions(t *testing.T) {
+	reported := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	release := func(tag string, published time.Time) *github.RepositoryRelease {
+		return &github.RepositoryRelease{TagName: github.String(tag), PublishedAt: &github.Timestamp{Time: published}, HTMLURL: github.String("https://github.com/o/r/releases/" + tag)}
+	}
+
+	var compared, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/releases":
+			json.NewEncoder(w).Encode([]*github.RepositoryRelease{
+				release("v1.5.0", reported.Add(24*time.Hour)), // After the report
+				release("v1.4.1", reported.Add(-20*24*time.Hour)),
+				release("v1.4.2", reported.Add(-2*24*time.Hour)),
+				{TagName: github.String("v1.6.0-draft"), Draft: github.Bool(true)},
+			})
+		case "/repos/o/r/compare/v1.4.1...v1.4.2":
+			compared = r.URL.Path
+			json.NewEncoder(w).Encode(github.CommitsComparison{Files: []*github.CommitFile{
+				{Filename: github.String("docs/auth.md")},
+				{Filename: github.String("internal/auth/auth.go")},
+			}})
+		case "/repos/o/r/commits":
+			path = r.URL.Query().Get("path")
+			json.NewEncoder(w).Encode([]*github.RepositoryCommit{{
+				SHA:     github.String("abc1234def"),
+				HTMLURL: github.String("https://github.com/o/r/commit/abc1234def"),
+				Commit:  &github.Commit{Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: reported.Add(-3 * 24 * time.Hour)}}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	issue := &github.Issue{
+		Number:    github.Int(7),
+		State:     github.String("open"),
+		Body:      github.String("Crash in auth.go after upgrading"),
+		CreatedAt: &github.Timestamp{Time: reported},
+	}
+	if hints := handler.detectRegressions(context.Background(), "o", "r", issue); hints != nil {
+		t.Fatalf("Expected no hints while disabled, got %+v", hints)
+	}
+
+	handler.SetRegressionHints(RegressionConfig{Enabled: true, Window: 7 * 24 * time.Hour})
+	hints := handler.detectRegressions(context.Background(), "o", "r", issue)
+	if compared == "" || path != "internal/auth/auth.go" {
+		t.Fatalf("Expected v1.4.2 compared with v1.4.1 and the file's history read, got %q and %q", compared, path)
+	}
+	want := []RegressionHint{{
+		Release:    "v1.4.2",
+		ReleaseURL: "https://github.com/o/r/releases/v1.4.2",
+		Commit:     "abc1234",
+		CommitURL:  "https://github.com/o/r/commit/abc1234def",
+		File:       "internal/auth/auth.go",
+		Before:     3 * 24 * time.Hour,
+	}}
+	if !reflect.DeepEqual(hints, want) {
+		t.Errorf("Expected %+v, got %+v", want, hints)
+	}
+
+	// Releases older than the window are not considered
+	handler.SetRegressionHints(RegressionConfig{Enabled: true, Window: time.Hour})
+	if hints := handler.detectRegressions(context.Background(), "o", "r", issue); len(hints) != 0 {
+		t.Errorf("Expected no hints outside the window, got %+v", hints)
+	}
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+)
+
+// Capturer keeps the payloads of processed deliveries, for replaying them
+// while debugging
+type Capturer interface {
+	Capture(eventType, deliveryID string, payload []byte, receivedAt time.Time)
+}
+
+// SetCapture keeps the payload of every supported delivery this handler
+// processes. Deliveries published to a broker are captured by the worker
+// that processes them.
+func (h *Handler) SetCapture(capturer Capturer) {
+	h.capture = capturer
+}
+
+// captureDelivery hands a delivery to the capturer, if there is one
+func (h *Handler) captureDelivery(eventType, deliveryID string, payload []byte, receivedAt time.Time) {
+	if h.capture != nil {
+		h.capture.Capture(eventType, deliveryID, payload, receivedAt)
+	}
+}
+
+// Replay processes a delivery again, as if it had just been received, and
+// returns its issue data, nil for events the bot skips. The issue is
+// processed in the background through the work queue like a live delivery,
+// and ErrQueueFull returned when there is no room in it. The replay is not
+// captured.
+func (h *Handler) Replay(ctx context.Context, eventType, deliveryID string, payload []byte) (*IssueData, error) {
+	if !isSupportedEvent(eventType) {
+		return nil, fmt.Errorf("unsupported event type %q", eventType)
+	}
+	spooled := false
+	if h.queue != nil {
+		var ok bool
+		if spooled, ok = h.queue.admit(); !ok {
+			h.logger.Warn("Webhook queue is full, rejecting replay",
+				zap.String("event_type", eventType),
+				zap.String("delivery_id", deliveryID),
+				zap.Int("depth", h.queue.Depth()))
+			h.metrics.RecordWebhookSaturation("rejected")
+			return nil, ErrQueueFull
+		}
+	}
+
+	issueData, _, err := h.dispatchEvent(ctx, eventType, payload)
+	if err != nil || issueData == nil {
+		if h.queue != nil {
+			h.queue.release()
+		}
+		return nil, err
+	}
+	issueData.DeliveryID = deliveryID
+	issueData.ReceivedAt = time.Now()
+	h.enqueue(eventType, issueData, spooled)
+	return issueData, nil
+}
+
+// EnrichDelivery reads the issue of an issues or issue_comment delivery and
+// enriches it, without acting on the event: transfers, commands and the
+// action matrix are not applied. Used to dry-run a captured delivery.
+func (h *Handler) EnrichDelivery(ctx context.Context, eventType string, payload []byte) (*IssueData, error) {
+	if eventType != "issues" && eventType != "issue_comment" {
+		return nil, fmt.Errorf("%s events have no issue to dry-run", eventType)
+	}
+	var event struct {
+		Action     string             `json:"action"`
+		Issue      *github.Issue      `json:"issue"`
+		Repository *github.Repository `json:"repository"`
+		Sender     *github.User       `json:"sender"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+	if event.Issue == nil {
+		return nil, fmt.Errorf("%w: %s event without an issue", errInvalidPayload, eventType)
+	}
+
+	issueData, err := h.enrich(ctx, event.Issue, event.Repository, event.Action, eventType)
+	if err != nil {
+		return nil, err
+	}
+	issueData.Sender = event.Sender.GetLogin()
+	return issueData, nil
+}
@@ -0,0 +1,30 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// FetchResolution returns when an issue was closed and GitHub's state reason,
+// e.g. completed or not_planned. Both are empty while the issue is open.
+func (h *Handler) FetchResolution(ctx context.Context, repo string, number int) (time.Time, string, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	issue, _, err := h.githubClient().Issues.Get(ctx, parts[0], parts[1], number)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("fetch_resolution", apperrors.Classify(err))
+		return time.Time{}, "", fmt.Errorf("failed to fetch issue: %w", err)
+	}
+	if issue.GetState() != "closed" {
+		return time.Time{}, "", nil
+	}
+	return issue.GetClosedAt().Time, issue.GetStateReason(), nil
+}
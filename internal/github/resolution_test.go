@@ -0,0 +1,42 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestFetchResolution(t *testing.T) {
+	closedAt := time.Date(2026, 9, 14, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issue := &github.Issue{State: github.String("open")}
+		if r.URL.Path == "/repos/o/r/issues/7" {
+			issue = &github.Issue{
+				State:       github.String("closed"),
+				StateReason: github.String("not_planned"),
+				ClosedAt:    &github.Timestamp{Time: closedAt},
+			}
+		}
+		json.NewEncoder(w).Encode(issue)
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	at, reason, err := handler.FetchResolution(context.Background(), "o/r", 7)
+	if err != nil {
+		t.Fatalf("FetchResolution: %v", err)
+	}
+	if !at.Equal(closedAt) || reason != "not_planned" {
+		t.Errorf("Expected the issue closed as not planned at %s, got %q at %s", closedAt, reason, at)
+	}
+
+	at, reason, err = handler.FetchResolution(context.Background(), "o/r", 8)
+	if err != nil || !at.IsZero() || reason != "" {
+		t.Errorf("Expected no resolution for an open issue, got %q at %s (%v)", reason, at, err)
+	}
+}
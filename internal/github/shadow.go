@@ -0,0 +1,35 @@
+package github
+
+import (
+	"net/http"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Shadow holds back the API calls that would change something, e.g. in
+// shadow mode, and answers them itself
+type Shadow interface {
+	GitHubTransport(base http.RoundTripper) http.RoundTripper
+}
+
+// SetShadow sends the handler's API calls through shadow, including those
+// made after the access token is rotated. Set it before the breaker and API
+// usage, so held back calls are counted like the real ones would be.
+func (h *Handler) SetShadow(shadow Shadow) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shadow = shadow
+	h.client = withShadow(h.client, shadow)
+}
+
+// withShadow returns a copy of client whose requests go through shadow
+func withShadow(client *github.Client, shadow Shadow) *github.Client {
+	if shadow == nil {
+		return client
+	}
+	httpClient := client.Client()
+	httpClient.Transport = shadow.GitHubTransport(httpClient.Transport)
+	shadowed := github.NewClient(httpClient)
+	shadowed.BaseURL, shadowed.UploadURL = client.BaseURL, client.UploadURL
+	return shadowed
+}
@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// SourceConfig restricts the addresses webhooks are accepted from, in
+// addition to their signatures
+type SourceConfig struct {
+	Enabled        bool
+	Meta           bool          // Accept GitHub's published hook ranges, read from the /meta API
+	Refresh        time.Duration // How often the /meta ranges are read again
+	AllowedCIDRs   []string      // Further ranges, e.g. of a GitHub Enterprise Server appliance
+	TrustedProxies []string      // Proxies in front of the bot whose X-Forwarded-For is believed
+}
+
+// SourceAllowlist decides whether a webhook comes from an allowed address.
+// Until the /meta ranges are first read every address is allowed, so a
+// GitHub outage at startup does not drop deliveries that still carry valid
+// signatures.
+type SourceAllowlist struct {
+	mu      sync.RWMutex
+	handler *Handler
+	config  SourceConfig
+	static  []*net.IPNet
+	proxies []*net.IPNet
+	hooks   []*net.IPNet // From /meta, nil until first read
+}
+
+// NewSourceAllowlist parses the configured ranges. The /meta ranges are read
+// with handler's client by Run.
+func NewSourceAllowlist(handler *Handler, config SourceConfig) (*SourceAllowlist, error) {
+	static, err := ParseCIDRs(config.AllowedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("allowed webhook sources: %w", err)
+	}
+	proxies, err := ParseCIDRs(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	return &SourceAllowlist{handler: handler, config: config, static: static, proxies: proxies}, nil
+}
+
+// ParseCIDRs parses ranges in CIDR notation. A bare address is a range of
+// one.
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", value)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q", value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// SetSourceAllowlist turns away webhooks from addresses sources does not
+// allow. nil accepts webhooks from anywhere.
+func (h *Handler) SetSourceAllowlist(sources *SourceAllowlist) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sources = sources
+}
+
+func (h *Handler) sourceAllowlist() *SourceAllowlist {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.sources
+}
+
+// Run reads the /meta ranges at once and then at each refresh interval until
+// the context is cancelled. A failed read keeps the previous ranges.
+func (a *SourceAllowlist) Run(ctx context.Context) {
+	if !a.config.Meta {
+		return
+	}
+	interval := a.config.Refresh
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := a.Refresh(ctx); err != nil {
+			a.handler.logger.Warn("Failed to read GitHub's webhook addresses", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reads GitHub's hook ranges from the /meta API
+func (a *SourceAllowlist) Refresh(ctx context.Context) error {
+	meta, _, err := a.handler.githubClient().Meta.Get(ctx)
+	if err != nil {
+		a.handler.metrics.RecordGitHubAPIError("meta", apperrors.Classify(err))
+		return err
+	}
+	hooks, err := ParseCIDRs(meta.Hooks)
+	if err != nil {
+		return fmt.Errorf("GitHub /meta hooks: %w", err)
+	}
+	if len(hooks) == 0 {
+		return fmt.Errorf("GitHub /meta lists no hook addresses")
+	}
+
+	a.mu.Lock()
+	a.hooks = hooks
+	a.mu.Unlock()
+	a.handler.logger.Debug("Read GitHub's webhook addresses", zap.Int("ranges", len(hooks)))
+	return nil
+}
+
+// Allowed reports whether a request comes from an allowed address, and
+// which address it comes from
+func (a *SourceAllowlist) Allowed(r *http.Request) (net.IP, bool) {
+	ip := a.clientIP(r)
+
+	a.mu.RLock()
+	hooks := a.hooks
+	a.mu.RUnlock()
+	if a.config.Meta && hooks == nil {
+		return ip, true
+	}
+	if ip == nil {
+		return nil, false
+	}
+	return ip, inNetworks(a.static, ip) || inNetworks(hooks, ip)
+}
+
+func (a *SourceAllowlist) clientIP(r *http.Request) net.IP {
+	return ClientIP(r, a.proxies)
+}
+
+// ClientIP is the address a request came from. Behind trusted proxies it is
+// the last X-Forwarded-For entry that is not a trusted proxy, as earlier
+// entries can be set by the client.
+func ClientIP(r *http.Request, proxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !inNetworks(proxies, ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if hop == nil {
+			return nil
+		}
+		ip = hop
+		if !inNetworks(proxies, hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// inNetworks reports whether any of networks contains ip
+func inNetworks(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
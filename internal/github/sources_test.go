@@ -0,0 +1,120 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func sourceRequest(remoteAddr, forwardedFor string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", strings.NewReader("{}"))
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	return req
+}
+
+func TestSourceAllowlist(t *testing.T) {
+	metaCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/meta" {
+			http.NotFound(w, r)
+			return
+		}
+		metaCalls++
+		w.Write([]byte(`{"hooks": ["192.30.252.0/22", "2a0a:a440::/29"]}`))
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	sources, err := NewSourceAllowlist(handler, SourceConfig{
+		Enabled:        true,
+		Meta:           true,
+		AllowedCIDRs:   []string{"10.20.0.5"},
+		TrustedProxies: []string{"172.16.0.0/12"},
+	})
+	if err != nil {
+		t.Fatalf("NewSourceAllowlist: %v", err)
+	}
+
+	// Until the ranges are read, deliveries are not turned away
+	if _, ok := sources.Allowed(sourceRequest("203.0.113.9:4000", "")); !ok {
+		t.Error("Expected every address allowed before /meta is read")
+	}
+	if err := sources.Refresh(context.Background()); err != nil || metaCalls != 1 {
+		t.Fatalf("Refresh: %v after %d calls", err, metaCalls)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		allowed      bool
+	}{
+		{"GitHub IPv4", "192.30.252.41:4000", "", true},
+		{"GitHub IPv6", "[2a0a:a440::10]:4000", "", true},
+		{"static", "10.20.0.5:4000", "", true},
+		{"other", "203.0.113.9:4000", "", false},
+		{"forwarded by a trusted proxy", "172.16.0.2:4000", "192.30.252.41", true},
+		{"spoofed before a trusted proxy", "172.16.0.2:4000", "192.30.252.41, 203.0.113.9", false},
+		{"through two trusted proxies", "172.16.0.2:4000", "192.30.252.41, 172.16.0.3", true},
+		{"header from an untrusted client", "203.0.113.9:4000", "192.30.252.41", false},
+		{"trusted proxy without the header", "172.16.0.2:4000", "", false},
+	}
+	for _, tt := range tests {
+		if ip, ok := sources.Allowed(sourceRequest(tt.remoteAddr, tt.forwardedFor)); ok != tt.allowed {
+			t.Errorf("%s: Allowed = %v (%v), want %v", tt.name, ok, ip, tt.allowed)
+		}
+	}
+
+	// A failed refresh keeps the ranges read before
+	server.Close()
+	handler.metrics.(*MockMetricsRecorder).On("RecordGitHubAPIError", "meta", mock.Anything).Once()
+	if err := sources.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected an error with GitHub unreachable")
+	}
+	if _, ok := sources.Allowed(sourceRequest("192.30.252.41:4000", "")); !ok {
+		t.Error("Expected the previous ranges kept")
+	}
+}
+
+func TestSourceAllowlistRejectsWebhook(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	handler := newCloseTestHandler(server)
+	sources, err := NewSourceAllowlist(handler, SourceConfig{Enabled: true, AllowedCIDRs: []string{"10.20.0.0/16"}})
+	if err != nil {
+		t.Fatalf("NewSourceAllowlist: %v", err)
+	}
+	handler.SetSourceAllowlist(sources)
+	handler.metrics.(*MockMetricsRecorder).On("RecordGitHubWebhook", "issues", "", "forbidden", mock.Anything).Once()
+
+	req := sourceRequest("203.0.113.9:4000", "")
+	req.Header.Set("X-GitHub-Event", "issues")
+	rec := httptest.NewRecorder()
+	handler.HandleWebhook(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d", rec.Code)
+	}
+	handler.metrics.(*MockMetricsRecorder).AssertExpectations(t)
+}
+
+func TestParseCIDRs(t *testing.T) {
+	networks, err := ParseCIDRs([]string{"10.0.0.0/8", " 192.168.1.7 ", "::1", ""})
+	if err != nil || len(networks) != 3 {
+		t.Fatalf("ParseCIDRs = %v, %v", networks, err)
+	}
+	if networks[1].String() != "192.168.1.7/32" || networks[2].String() != "::1/128" {
+		t.Errorf("Expected single addresses as ranges of one, got %v and %v", networks[1], networks[2])
+	}
+	for _, invalid := range []string{"10.0.0.0/33", "github.com"} {
+		if _, err := ParseCIDRs([]string{invalid}); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// Limits of stack detection
+const (
+	maxStackLanguages     = 3    // Languages listed, largest first
+	minLanguagePercent    = 10   // Share of the code below which other languages are left out
+	maxStackCacheEntries  = 1000 // Repositories whose stack is cached. Expired entries are dropped first.
+	maxStackManifestBytes = 256 << 10
+)
+
+// StackConfig controls detecting the languages and frameworks of the
+// repositories issues are filed against
+type StackConfig struct {
+	Enabled bool
+	TTL     time.Duration // How long a repository's stack is cached
+}
+
+// LanguageShare is a language and its share of a repository's code
+type LanguageShare struct {
+	Name    string
+	Percent int
+}
+
+// RepositoryStack is what a repository is written in and built with
+type RepositoryStack struct {
+	Languages  []LanguageShare // Largest first
+	Tooling    []string        // Runtimes and build tools, e.g. "Go 1.21 modules" or "Docker"
+	Frameworks []string        // Frameworks the manifests depend on, e.g. "Gin" or "React"
+}
+
+// Lines describes the stack, one line per kind
+func (s *RepositoryStack) Lines() []string {
+	var lines []string
+	if len(s.Languages) > 0 {
+		languages := make([]string, len(s.Languages))
+		for i, language := range s.Languages {
+			languages[i] = fmt.Sprintf("%s (%d%%)", language.Name, language.Percent)
+		}
+		lines = append(lines, "Languages: "+strings.Join(languages, ", "))
+	}
+	if len(s.Tooling) > 0 {
+		lines = append(lines, "Tooling: "+strings.Join(s.Tooling, ", "))
+	}
+	if len(s.Frameworks) > 0 {
+		lines = append(lines, "Frameworks: "+strings.Join(s.Frameworks, ", "))
+	}
+	return lines
+}
+
+// stackFiles are files at a repository's root that name its runtime or build
+// tool, in the order they are listed
+var stackFiles = []struct {
+	name    string
+	tooling string
+}{
+	{"go.mod", "Go modules"},
+	{"package.json", "Node.js"},
+	{"tsconfig.json", "TypeScript"},
+	{"pyproject.toml", "Python (pyproject)"},
+	{"requirements.txt", "Python (pip)"},
+	{"Pipfile", "Python (Pipenv)"},
+	{"Gemfile", "Ruby (Bundler)"},
+	{"Cargo.toml", "Rust (Cargo)"},
+	{"pom.xml", "Java (Maven)"},
+	{"build.gradle", "Gradle"},
+	{"build.gradle.kts", "Gradle (Kotlin DSL)"},
+	{"composer.json", "PHP (Composer)"},
+	{"mix.exs", "Elixir (Mix)"},
+	{"Package.swift", "Swift Package Manager"},
+	{"CMakeLists.txt", "CMake"},
+	{"Dockerfile", "Docker"},
+}
+
+// goFrameworks are Go modules worth naming, by module path prefix
+var goFrameworks = []struct {
+	module string
+	name   string
+}{
+	{"github.com/gin-gonic/gin", "Gin"},
+	{"github.com/labstack/echo", "Echo"},
+	{"github.com/gofiber/fiber", "Fiber"},
+	{"github.com/go-chi/chi", "chi"},
+	{"github.com/gorilla/mux", "Gorilla mux"},
+	{"google.golang.org/grpc", "gRPC"},
+	{"gorm.io/gorm", "GORM"},
+	{"github.com/spf13/cobra", "Cobra"},
+}
+
+// nodeFrameworks are npm packages worth naming
+var nodeFrameworks = []struct {
+	module string
+	name   string
+}{
+	{"next", "Next.js"},
+	{"react", "React"},
+	{"vue", "Vue"},
+	{"@angular/core", "Angular"},
+	{"svelte", "Svelte"},
+	{"express", "Express"},
+	{"@nestjs/core", "NestJS"},
+	{"electron", "Electron"},
+}
+
+// goDirectivePattern matches the go directive of a go.mod file
+var goDirectivePattern = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+
+// cachedStack is a detected stack and when it was detected
+type cachedStack struct {
+	stack      *RepositoryStack
+	detectedAt time.Time
+}
+
+// stackCache keeps detected stacks per repository, which rarely change
+type stackCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedStack
+}
+
+// SetStackDetection sets how the languages and frameworks of repositories
+// are detected
+func (h *Handler) SetStackDetection(config StackConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stack = config
+	h.stacks = &stackCache{entries: make(map[string]cachedStack)}
+}
+
+func (h *Handler) stackDetection() (StackConfig, *stackCache) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.stack, h.stacks
+}
+
+// detectStack returns the stack of a repository, from the cache when it was
+// detected within the TTL. Languages come from GitHub's languages API;
+// tooling and frameworks from the files at the repository's root and the
+// go.mod and package.json manifests. Failing to read the files only leaves
+// them out.
+func (h *Handler) detectStack(ctx context.Context, owner, repo string) (*RepositoryStack, error) {
+	config, cache := h.stackDetection()
+	if !config.Enabled || cache == nil {
+		return nil, nil
+	}
+	key := strings.ToLower(owner + "/" + repo)
+	now := time.Now()
+	cache.mu.Lock()
+	entry, ok := cache.entries[key]
+	cache.mu.Unlock()
+	if ok && now.Sub(entry.detectedAt) < config.TTL {
+		return entry.stack, nil
+	}
+
+	languages, _, err := h.githubClient().Repositories.ListLanguages(ctx, owner, repo)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_languages", apperrors.Classify(err))
+		return nil, fmt.Errorf("failed to list repository languages: %w", err)
+	}
+	stack := &RepositoryStack{Languages: languageShares(languages)}
+	h.detectTooling(ctx, owner, repo, stack)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if _, ok := cache.entries[key]; !ok && len(cache.entries) >= maxStackCacheEntries {
+		for old, entry := range cache.entries {
+			if now.Sub(entry.detectedAt) >= config.TTL {
+				delete(cache.entries, old)
+			}
+		}
+		for old := range cache.entries {
+			if len(cache.entries) < maxStackCacheEntries {
+				break
+			}
+			delete(cache.entries, old)
+		}
+	}
+	cache.entries[key] = cachedStack{stack: stack, detectedAt: now}
+	return stack, nil
+}
+
+// languageShares turns bytes of code per language into the largest shares
+func languageShares(languages map[string]int) []LanguageShare {
+	total := 0
+	for _, bytes := range languages {
+		total += bytes
+	}
+	if total == 0 {
+		return nil
+	}
+	var shares []LanguageShare
+	for name, bytes := range languages {
+		shares = append(shares, LanguageShare{Name: name, Percent: bytes * 100 / total})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Percent != shares[j].Percent {
+			return shares[i].Percent > shares[j].Percent
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	kept := 1
+	for kept < len(shares) && kept < maxStackLanguages && shares[kept].Percent >= minLanguagePercent {
+		kept++
+	}
+	return shares[:kept]
+}
+
+// detectTooling adds the tooling named by the files at the repository's
+// root, and the frameworks its go.mod and package.json depend on
+func (h *Handler) detectTooling(ctx context.Context, owner, repo string, stack *RepositoryStack) {
+	_, root, _, err := h.githubClient().Repositories.GetContents(ctx, owner, repo, "", nil)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("list_root_files", apperrors.Classify(err))
+		h.logger.Warn("Failed to list repository files for stack detection", zap.String("repository", owner+"/"+repo), zap.Error(err))
+		return
+	}
+	present := make(map[string]bool, len(root))
+	for _, file := range root {
+		if file.GetType() == "file" {
+			present[file.GetName()] = true
+		}
+	}
+
+	for _, file := range stackFiles {
+		if !present[file.name] {
+			continue
+		}
+		tooling := file.tooling
+		switch file.name {
+		case "go.mod":
+			manifest := h.readManifest(ctx, owner, repo, file.name)
+			if match := goDirectivePattern.FindStringSubmatch(manifest); match != nil {
+				tooling = fmt.Sprintf("Go %s modules", match[1])
+			}
+			for _, framework := range goFrameworks {
+				if strings.Contains(manifest, framework.module+" ") || strings.Contains(manifest, framework.module+"/") {
+					stack.Frameworks = append(stack.Frameworks, framework.name)
+				}
+			}
+		case "package.json":
+			var manifest struct {
+				Engines         map[string]string `json:"engines"`
+				Dependencies    map[string]string `json:"dependencies"`
+				DevDependencies map[string]string `json:"devDependencies"`
+			}
+			if err := json.Unmarshal([]byte(h.readManifest(ctx, owner, repo, file.name)), &manifest); err != nil {
+				break
+			}
+			if node := manifest.Engines["node"]; node != "" {
+				tooling = "Node.js " + node
+			}
+			for _, framework := range nodeFrameworks {
+				if _, ok := manifest.Dependencies[framework.module]; ok {
+					stack.Frameworks = append(stack.Frameworks, framework.name)
+				} else if _, ok := manifest.DevDependencies[framework.module]; ok {
+					stack.Frameworks = append(stack.Frameworks, framework.name)
+				}
+			}
+		}
+		stack.Tooling = append(stack.Tooling, tooling)
+	}
+}
+
+// readManifest returns a file at the repository's root, empty when it cannot
+// be read or is too large to be a manifest
+func (h *Handler) readManifest(ctx context.Context, owner, repo, name string) string {
+	file, _, _, err := h.githubClient().Repositories.GetContents(ctx, owner, repo, name, nil)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("get_manifest", apperrors.Classify(err))
+		h.logger.Warn("Failed to read manifest for stack detection", zap.String("file", name), zap.Error(err))
+		return ""
+	}
+	if file.GetSize() > maxStackManifestBytes {
+		return ""
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return ""
+	}
+	return content
+}
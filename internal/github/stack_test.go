@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestLanguageShares(t *testing.T) {
+	shares := languageShares(map[string]int{"Go": 8500, "Shell": 1000, "Makefile": 400, "Dockerfile": 100})
+	if want := []LanguageShare{{"Go", 85}, {"Shell", 10}}; !reflect.DeepEqual(shares, want) {
+		t.Errorf("Expected %v, got %v", want, shares)
+	}
+	if shares := languageShares(map[string]int{"Python": 1}); len(shares) != 1 || shares[0].Percent != 100 {
+		t.Errorf("Expected a single language at 100%%, got %v", shares)
+	}
+	if shares := languageShares(nil); shares != nil {
+		t.Errorf("Expected no languages, got %v", shares)
+	}
+}
+
+func TestDetectStack(t *testing.T) {
+	var languageCalls int32
+	file := func(name, content string) *github.RepositoryContent {
+		return &github.RepositoryContent{
+			Type:     github.String("file"),
+			Name:     github.String(name),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/languages":
+			atomic.AddInt32(&languageCalls, 1)
+			json.NewEncoder(w).Encode(map[string]int{"Go": 9000, "TypeScript": 1000})
+		case "/repos/o/r/contents/":
+			json.NewEncoder(w).Encode([]*github.RepositoryContent{
+				{Type: github.String("file"), Name: github.String("go.mod")},
+				{Type: github.String("file"), Name: github.String("package.json")},
+				{Type: github.String("file"), Name: github.String("Dockerfile")},
+				{Type: github.String("dir"), Name: github.String("Cargo.toml")},
+			})
+		case "/repos/o/r/contents/go.mod":
+			json.NewEncoder(w).Encode(file("go.mod", "module example.com/r\n\ngo 1.21\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.1\n\tgo.uber.org/zap v1.26.0\n)\n"))
+		case "/repos/o/r/contents/package.json":
+			json.NewEncoder(w).Encode(file("package.json", `{"engines": {"node": ">=18"}, "dependencies": {"react": "^18.2.0"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	handler.metrics = &stageRecorder{}
+	if stack, err := handler.detectStack(context.Background(), "o", "r"); stack != nil || err != nil {
+		t.Fatalf("Expected nothing detected while disabled, got %v, %v", stack, err)
+	}
+	handler.SetStackDetection(StackConfig{Enabled: true, TTL: time.Hour})
+
+	stack, err := handler.detectStack(context.Background(), "o", "r")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := &RepositoryStack{
+		Languages:  []LanguageShare{{"Go", 90}, {"TypeScript", 10}},
+		Tooling:    []string{"Go 1.21 modules", "Node.js >=18", "Docker"},
+		Frameworks: []string{"Gin", "React"},
+	}
+	if !reflect.DeepEqual(stack, want) {
+		t.Errorf("Expected %+v, got %+v", want, stack)
+	}
+
+	// Detected stacks are cached per repository
+	if _, err := handler.detectStack(context.Background(), "O", "R"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls := atomic.LoadInt32(&languageCalls); calls != 1 {
+		t.Errorf("Expected the cached stack reused, got %d calls", calls)
+	}
+
+	if _, err := handler.detectStack(context.Background(), "o", "missing"); err == nil {
+		t.Error("Expected an error when the languages cannot be listed")
+	}
+}
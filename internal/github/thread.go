@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+)
+
+// defaultCommentLimit is how many comments are kept per issue unless
+// SetCommentLimit says otherwise
+const defaultCommentLimit = 100
+
+// commentRateReserve is how many API calls must be left in the rate limit to
+// fetch another page of comments, so long threads do not starve the calls
+// other issues need
+const commentRateReserve = 100
+
+// commentsPerPage is the most comments GitHub returns per page
+const commentsPerPage = 100
+
+// SetCommentLimit sets how many comments are fetched per issue: the first
+// one and the most recent. Zero means the default of 100.
+func (h *Handler) SetCommentLimit(limit int) {
+	h.commentLimit = limit
+}
+
+// SelectComments keeps the first comment, which often holds the first
+// response or a reproduction, and the most recent ones, up to limit comments
+// in chronological order
+func SelectComments(comments []*github.IssueComment, limit int) []*github.IssueComment {
+	if limit <= 0 || len(comments) <= limit {
+		return comments
+	}
+	if limit == 1 {
+		return comments[:1]
+	}
+	selected := make([]*github.IssueComment, 0, limit)
+	selected = append(selected, comments[0])
+	return append(selected, comments[len(comments)-limit+1:]...)
+}
+
+// fetchIssueComments fetches the first comment of an issue and its most
+// recent ones, up to the comment limit. The issue comments endpoint only
+// lists oldest first and ignores sort and direction, so after the first
+// page the pages are read backwards from the last one. Paging stops early
+// when the rate limit runs low.
+func (h *Handler) fetchIssueComments(ctx context.Context, owner, repo string, issueNumber int) ([]*github.IssueComment, error) {
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("invalid repository: owner=%s, repo=%s", owner, repo)
+	}
+	limit := h.commentLimit
+	if limit <= 0 {
+		limit = defaultCommentLimit
+	}
+
+	first, resp, err := h.listComments(ctx, owner, repo, issueNumber, 0)
+	if err != nil || resp.LastPage == 0 {
+		return SelectComments(first, limit), err
+	}
+
+	// Pages after the first, newest first, until enough comments are read
+	var recent []*github.IssueComment
+	for page := resp.LastPage; page > 1 && len(recent) < limit-1; page-- {
+		if resp.Rate.Limit > 0 && resp.Rate.Remaining < commentRateReserve {
+			h.logger.Warn("Rate limit low, keeping the comments fetched so far",
+				zap.String("repository", owner+"/"+repo),
+				zap.Int("issue_number", issueNumber),
+				zap.Int("rate_remaining", resp.Rate.Remaining),
+				zap.Int("comments", len(first)+len(recent)))
+			break
+		}
+		var comments []*github.IssueComment
+		comments, resp, err = h.listComments(ctx, owner, repo, issueNumber, page)
+		if err != nil {
+			// Keep what was read: the first page and any later ones
+			h.logger.Warn("Failed to fetch a page of comments",
+				zap.Int("issue_number", issueNumber),
+				zap.Int("page", page),
+				zap.Error(err))
+			break
+		}
+		recent = append(comments, recent...)
+	}
+	return SelectComments(append(first, recent...), limit), nil
+}
+
+// listComments reads one page of an issue's comments, oldest first. Page 0
+// is the first page.
+func (h *Handler) listComments(ctx context.Context, owner, repo string, issueNumber, page int) ([]*github.IssueComment, *github.Response, error) {
+	comments, resp, err := h.githubClient().Issues.ListComments(ctx, owner, repo, issueNumber, &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: commentsPerPage, Page: page},
+	})
+	return comments, resp, classifyError(err)
+}
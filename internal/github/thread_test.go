@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestSelectComments(t *testing.T) {
+	var comments []*github.IssueComment
+	for i := int64(1); i <= 6; i++ {
+		comments = append(comments, &github.IssueComment{ID: github.Int64(i)})
+	}
+	ids := func(comments []*github.IssueComment) []int64 {
+		var ids []int64
+		for _, c := range comments {
+			ids = append(ids, c.GetID())
+		}
+		return ids
+	}
+	if got := ids(SelectComments(comments, 3)); fmt.Sprint(got) != "[1 5 6]" {
+		t.Errorf("Expected the first and the 2 most recent comments, got %v", got)
+	}
+	if got := ids(SelectComments(comments, 10)); len(got) != 6 {
+		t.Errorf("Expected every comment under the limit, got %v", got)
+	}
+}
+
+// commentServer serves total comments in pages of 100, numbered from 1, and
+// records the pages requested
+func commentServer(total, rateRemaining int, pages *[]int) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		*pages = append(*pages, page)
+		last := (total + 99) / 100
+		if last > 1 {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d&per_page=100>; rel="last"`, server.URL, r.URL.Path, last))
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(rateRemaining))
+		var comments []*github.IssueComment
+		for id := (page-1)*100 + 1; id <= page*100 && id <= total; id++ {
+			comments = append(comments, &github.IssueComment{ID: github.Int64(int64(id))})
+		}
+		json.NewEncoder(w).Encode(comments)
+	}))
+	return server
+}
+
+func TestFetchIssueCommentsPaginates(t *testing.T) {
+	var pages []int
+	server := commentServer(250, 4000, &pages)
+	defer server.Close()
+	handler := newCloseTestHandler(server)
+	handler.SetCommentLimit(120)
+
+	comments, err := handler.fetchIssueComments(context.Background(), "o", "r", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(pages) != "[1 3 2]" {
+		t.Errorf("Expected the first page, then the last pages newest first, got %v", pages)
+	}
+	if len(comments) != 120 || comments[0].GetID() != 1 || comments[1].GetID() != 132 || comments[119].GetID() != 250 {
+		t.Errorf("Expected the first and the 119 most recent comments, got %d from %d to %d",
+			len(comments), comments[0].GetID(), comments[len(comments)-1].GetID())
+	}
+
+	// A short thread takes one request
+	pages = nil
+	short := commentServer(30, 4000, &pages)
+	defer short.Close()
+	comments, _ = newCloseTestHandler(short).fetchIssueComments(context.Background(), "o", "r", 7)
+	if len(comments) != 30 || fmt.Sprint(pages) != "[1]" {
+		t.Errorf("Expected all 30 comments from one page, got %d from pages %v", len(comments), pages)
+	}
+}
+
+func TestFetchIssueCommentsStopsOnLowRateLimit(t *testing.T) {
+	var pages []int
+	server := commentServer(250, 50, &pages)
+	defer server.Close()
+
+	comments, err := newCloseTestHandler(server).fetchIssueComments(context.Background(), "o", "r", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(pages) != "[1]" || len(comments) != 100 {
+		t.Errorf("Expected only the first page with the rate limit low, got pages %v and %d comments", pages, len(comments))
+	}
+}
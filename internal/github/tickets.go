@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// TicketPromptStyle is the predefined prompt style support tickets are
+// analyzed in, including after they are filed as GitHub issues
+const TicketPromptStyle = "support_ticket"
+
+// ticketMarkerPattern matches the hidden marker in the body of issues filed
+// from support tickets, e.g. <!-- notifyops:ticket zendesk/35436 -->
+var ticketMarkerPattern = regexp.MustCompile(`<!-- notifyops:ticket ([a-z]+)/(\S+) -->`)
+
+// TicketMarker returns the hidden marker identifying an issue filed from a
+// support ticket
+func TicketMarker(source, id string) string {
+	return fmt.Sprintf("<!-- notifyops:ticket %s/%s -->", source, id)
+}
+
+// IsFiledTicket reports whether an issue body carries a support ticket marker
+func IsFiledTicket(body string) bool {
+	return ticketMarkerPattern.MatchString(body)
+}
+
+// ticketPromptStyle returns the prompt style of issues filed from support
+// tickets, and empty for others
+func ticketPromptStyle(body string) string {
+	if IsFiledTicket(body) {
+		return TicketPromptStyle
+	}
+	return ""
+}
+
+// slackMarkerPattern matches the hidden marker in the body of issues created
+// from Slack messages, e.g. <!-- notifyops:slack C123/1700000000.000100 -->
+var slackMarkerPattern = regexp.MustCompile(`<!-- notifyops:slack \S+/\S+ -->`)
+
+// SlackMarker returns the hidden marker identifying an issue created from a
+// Slack message
+func SlackMarker(channel, ts string) string {
+	return fmt.Sprintf("<!-- notifyops:slack %s/%s -->", channel, ts)
+}
+
+// IsFiledFromSlack reports whether an issue body carries a Slack message marker
+func IsFiledFromSlack(body string) bool {
+	return slackMarkerPattern.MatchString(body)
+}
+
+// CreateIssue opens an issue, e.g. to file a support ticket for engineering
+func (h *Handler) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*github.Issue, *github.Repository, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return nil, nil, fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	request := &github.IssueRequest{Title: github.String(title), Body: github.String(body)}
+	if len(labels) > 0 {
+		request.Labels = &labels
+	}
+	issue, _, err := h.githubClient().Issues.Create(ctx, parts[0], parts[1], request)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("create_issue", apperrors.Classify(err))
+		return nil, nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return issue, &github.Repository{
+		FullName: github.String(repo),
+		Owner:    &github.User{Login: github.String(parts[0])},
+		Name:     github.String(parts[1]),
+	}, nil
+}
@@ -0,0 +1,92 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateIssue(t *testing.T) {
+	var created github.IssueRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/support/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		json.NewEncoder(w).Encode(github.Issue{Number: github.Int(42), Title: created.Title, Body: created.Body})
+	}))
+	defer server.Close()
+
+	body := "Checkout fails\n\n" + TicketMarker("zendesk", "35436")
+	issue, repository, err := newCloseTestHandler(server).CreateIssue(context.Background(), "acme/support", "Checkout fails", body, []string{"support"})
+	if err != nil {
+		t.Fatalf("CreateIssue: %v", err)
+	}
+	if issue.GetNumber() != 42 || repository.GetFullName() != "acme/support" || repository.GetOwner().GetLogin() != "acme" {
+		t.Errorf("Unexpected issue %d in %s", issue.GetNumber(), repository.GetFullName())
+	}
+	if created.Labels == nil || !reflect.DeepEqual(*created.Labels, []string{"support"}) {
+		t.Errorf("Expected the support label, got %v", created.Labels)
+	}
+
+	if !IsFiledTicket(issue.GetBody()) || ticketPromptStyle(issue.GetBody()) != TicketPromptStyle {
+		t.Error("Expected the filed issue to be recognized as a ticket")
+	}
+	if IsFiledTicket("Checkout fails <!-- notifyops:ticket -->") || ticketPromptStyle("Checkout fails") != "" {
+		t.Error("Expected other issues not to be recognized as tickets")
+	}
+}
+
+func TestFiledTicketOpenedEventSkipped(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	handler := newCloseTestHandler(server)
+	handler.closeSuggestions = false
+	handler.metrics.(*MockMetricsRecorder).On("RecordGitHubAPIError", mock.Anything, mock.Anything).Return()
+
+	event := github.IssuesEvent{
+		Action: github.String("opened"),
+		Issue:  &github.Issue{Number: github.Int(42), Body: github.String("Checkout fails\n\n" + TicketMarker("intercom", "123"))},
+		Repo:   &github.Repository{FullName: github.String("acme/support"), Name: github.String("support"), Owner: &github.User{Login: github.String("acme")}},
+	}
+	body, _ := json.Marshal(event)
+	if issueData, status, err := handler.handleIssuesEvent(context.Background(), body); issueData != nil || status != "skipped" || err != nil {
+		t.Errorf("Expected the filed ticket's opened event skipped, got %q (%v)", status, err)
+	}
+
+	// Later changes are analyzed in the ticket's prompt style
+	event.Action = github.String("edited")
+	body, _ = json.Marshal(event)
+	issueData, _, err := handler.handleIssuesEvent(context.Background(), body)
+	if err != nil || issueData == nil || issueData.PromptStyle != TicketPromptStyle {
+		t.Errorf("Expected the edit analyzed as a ticket, got %+v (%v)", issueData, err)
+	}
+}
+
+func TestSlackIssueOpenedEventSkipped(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	handler := newCloseTestHandler(server)
+	handler.closeSuggestions = false
+
+	if !IsFiledFromSlack("Export hangs\n\n"+SlackMarker("C123", "1700000000.000100")) || IsFiledFromSlack("Export hangs") {
+		t.Fatal("Expected only issues with the marker to be recognized as created from Slack")
+	}
+
+	event := github.IssuesEvent{
+		Action: github.String("opened"),
+		Issue:  &github.Issue{Number: github.Int(42), Body: github.String("Export hangs\n\n" + SlackMarker("C123", "1700000000.000100"))},
+		Repo:   &github.Repository{FullName: github.String("acme/app"), Name: github.String("app"), Owner: &github.User{Login: github.String("acme")}},
+	}
+	body, _ := json.Marshal(event)
+	if issueData, status, err := handler.handleIssuesEvent(context.Background(), body); issueData != nil || status != "skipped" || err != nil {
+		t.Errorf("Expected the opened event of an issue created from Slack skipped, got %q (%v)", status, err)
+	}
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// TicketUpdate is a state change of the tracker ticket linked to an issue,
+// e.g. a Linear issue or Shortcut story
+type TicketUpdate struct {
+	Tracker  string // Display name, e.g. Linear
+	Key      string // e.g. ENG-123
+	URL      string
+	State    string // Name of the new state, e.g. Done
+	Canceled bool   // The ticket was canceled rather than completed
+}
+
+// CommentTicketState reports a linked ticket's new state on the issue, and
+// closes the issue when close is set: as completed, or as not planned for
+// canceled tickets
+func (h *Handler) CommentTicketState(ctx context.Context, repo string, number int, update TicketUpdate, close bool) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+	owner, repoName := parts[0], parts[1]
+
+	data := CommentData{Repository: repo, IssueNumber: number, Ticket: &update}
+	comment, err := h.commentFormatter().Render(CommentTicketState, data)
+	if err != nil {
+		h.logger.Warn("Failed to render ticket state comment, using the built-in one", zap.String("repository", repo), zap.Error(err))
+		comment, _ = (*CommentFormatter)(nil).Render(CommentTicketState, data)
+	}
+
+	if _, _, err := h.githubClient().Issues.CreateComment(ctx, owner, repoName, number, &github.IssueComment{Body: github.String(comment)}); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("ticket_comment", apperrors.Classify(err))
+		return fmt.Errorf("failed to comment on issue: %w", err)
+	}
+	if !close {
+		return nil
+	}
+
+	stateReason := "completed"
+	if update.Canceled {
+		stateReason = "not_planned"
+	}
+	if _, _, err := h.githubClient().Issues.Edit(ctx, owner, repoName, number, &github.IssueRequest{
+		State:       github.String("closed"),
+		StateReason: github.String(stateReason),
+	}); err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("ticket_close", apperrors.Classify(err))
+		return fmt.Errorf("failed to close issue: %w", err)
+	}
+	return nil
+}
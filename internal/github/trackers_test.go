@@ -0,0 +1,52 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestCommentTicketState(t *testing.T) {
+	var comment, state, stateReason string
+	edits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/issues/7/comments":
+			var body github.IssueComment
+			json.NewDecoder(r.Body).Decode(&body)
+			comment = body.GetBody()
+			json.NewEncoder(w).Encode(body)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/o/r/issues/7":
+			var body github.IssueRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			edits++
+			state, stateReason = body.GetState(), body.GetStateReason()
+			json.NewEncoder(w).Encode(github.Issue{Number: github.Int(7)})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	update := TicketUpdate{Tracker: "Linear", Key: "ENG-12", URL: "https://linear.app/acme/issue/ENG-12", State: "In Progress"}
+	if err := handler.CommentTicketState(context.Background(), "o/r", 7, update, false); err != nil {
+		t.Fatal(err)
+	}
+	if comment != "The linked Linear ticket [ENG-12](https://linear.app/acme/issue/ENG-12) moved to **In Progress**." || edits != 0 {
+		t.Errorf("Expected only a comment, got %q and %d edits", comment, edits)
+	}
+
+	update.State, update.Canceled = "Canceled", true
+	if err := handler.CommentTicketState(context.Background(), "o/r", 7, update, true); err != nil {
+		t.Fatal(err)
+	}
+	if state != "closed" || stateReason != "not_planned" {
+		t.Errorf("Expected the issue closed as not planned, got %s/%s", state, stateReason)
+	}
+}
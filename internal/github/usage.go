@@ -0,0 +1,65 @@
+package github
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// APIUsageRecorder counts GitHub API calls per repository, e.g. for usage
+// reports
+type APIUsageRecorder interface {
+	RecordGitHubCall(repository string)
+}
+
+// SetAPIUsage counts the handler's API calls with recorder, including those
+// made after the access token is rotated
+func (h *Handler) SetAPIUsage(recorder APIUsageRecorder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.apiUsage = recorder
+	h.client = withAPIUsage(h.client, recorder)
+}
+
+// withAPIUsage returns a copy of client whose requests are counted by
+// recorder
+func withAPIUsage(client *github.Client, recorder APIUsageRecorder) *github.Client {
+	if recorder == nil {
+		return client
+	}
+	httpClient := client.Client()
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient.Transport = &usageTransport{base: base, recorder: recorder}
+	counted := github.NewClient(httpClient)
+	counted.BaseURL, counted.UploadURL = client.BaseURL, client.UploadURL
+	return counted
+}
+
+// usageTransport counts each request against the repository in its path
+type usageTransport struct {
+	base     http.RoundTripper
+	recorder APIUsageRecorder
+}
+
+func (t *usageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.recorder.RecordGitHubCall(APIRepository(req.URL.Path))
+	return t.base.RoundTrip(req)
+}
+
+// APIRepository returns the owner/name of an API path under /repos/, e.g. on
+// GitHub Enterprise /api/v3/repos/o/r/issues, or empty for other paths
+func APIRepository(path string) string {
+	_, rest, ok := strings.Cut(path, "/repos/")
+	if !ok {
+		return ""
+	}
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
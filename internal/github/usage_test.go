@@ -0,0 +1,57 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeAPIUsage struct {
+	calls map[string]int
+}
+
+func (f *fakeAPIUsage) RecordGitHubCall(repository string) {
+	f.calls[repository]++
+}
+
+func TestAPIUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/issues" {
+			fmt.Fprint(w, `{"total_count":0,"items":[]}`)
+			return
+		}
+		fmt.Fprint(w, `{"number":1,"state":"open"}`)
+	}))
+	defer server.Close()
+
+	usage := &fakeAPIUsage{calls: map[string]int{}}
+	handler := newCloseTestHandler(server)
+	handler.SetAPIUsage(usage)
+	handler.githubClient().Issues.Get(context.Background(), "acme", "api", 1)
+	handler.githubClient().Issues.Get(context.Background(), "acme", "api", 2)
+	handler.githubClient().Search.Issues(context.Background(), "repo:acme/api crash", nil)
+
+	// Rotating the token keeps counting
+	handler.SetAccessToken("rotated")
+	handler.githubClient().Issues.Get(context.Background(), "acme", "web", 1)
+
+	if usage.calls["acme/api"] != 2 || usage.calls["acme/web"] != 1 || usage.calls[""] != 1 {
+		t.Errorf("Unexpected API calls %v", usage.calls)
+	}
+}
+
+func TestAPIRepository(t *testing.T) {
+	for path, want := range map[string]string{
+		"/repos/acme/api/issues/1":        "acme/api",
+		"/api/v3/repos/acme/api/contents": "acme/api",
+		"/repos/acme/api":                 "acme/api",
+		"/repos/acme":                     "",
+		"/search/issues":                  "",
+	} {
+		if got := APIRepository(path); got != want {
+			t.Errorf("APIRepository(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// AddAssignee assigns an issue to login, keeping its other assignees.
+// GitHub silently skips logins that cannot be assigned, so the returned
+// issue is checked for the new assignee.
+func (h *Handler) AddAssignee(ctx context.Context, repo string, number int, login string) error {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	issue, _, err := h.githubClient().Issues.AddAssignees(ctx, parts[0], parts[1], number, []string{login})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("add_assignee", apperrors.Classify(err))
+		return fmt.Errorf("failed to assign issue: %w", err)
+	}
+	for _, assignee := range issue.Assignees {
+		if strings.EqualFold(assignee.GetLogin(), login) {
+			h.logger.Info("Assigned issue",
+				zap.String("repository", repo),
+				zap.Int("issue_number", number),
+				zap.String("assignee", login),
+			)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s cannot be assigned issues in %s", login, repo)
+}
+
+// HasWriteAccess reports whether a GitHub user can push to a repository,
+// whether as a collaborator, through a team or as an organization owner.
+// GitHub reports the maintain role as write.
+func (h *Handler) HasWriteAccess(ctx context.Context, repo, login string) (bool, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return false, fmt.Errorf("invalid repo format: %s", repo)
+	}
+
+	level, _, err := h.githubClient().Repositories.GetPermissionLevel(ctx, parts[0], parts[1], login)
+	if err != nil {
+		err = classifyError(err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return false, nil
+		}
+		h.metrics.RecordGitHubAPIError("get_permission", apperrors.Classify(err))
+		return false, fmt.Errorf("failed to get permission: %w", err)
+	}
+	switch level.GetPermission() {
+	case "admin", "write":
+		return true, nil
+	}
+	return false, nil
+}
+
+// UserEmail returns the public email of a GitHub user, empty if they have
+// none
+func (h *Handler) UserEmail(ctx context.Context, login string) (string, error) {
+	user, _, err := h.githubClient().Users.Get(ctx, login)
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("get_user", apperrors.Classify(err))
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.GetEmail(), nil
+}
+
+// LoginByEmail finds the GitHub user whose public email is email, returning
+// an empty login unless exactly one matches
+func (h *Handler) LoginByEmail(ctx context.Context, email string) (string, error) {
+	result, _, err := h.githubClient().Search.Users(ctx, fmt.Sprintf("%q in:email", email), &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: 2},
+	})
+	if err != nil {
+		err = classifyError(err)
+		h.metrics.RecordGitHubAPIError("search_users", apperrors.Classify(err))
+		return "", fmt.Errorf("failed to search users: %w", err)
+	}
+	if len(result.Users) != 1 {
+		return "", nil
+	}
+	return result.Users[0].GetLogin(), nil
+}
+
+// AssigneeLogins returns the logins of everyone assigned to an issue
+func AssigneeLogins(issue *github.Issue) []string {
+	var logins []string
+	for _, assignee := range issue.Assignees {
+		logins = append(logins, assignee.GetLogin())
+	}
+	if len(logins) == 0 && issue.GetAssignee() != nil {
+		logins = append(logins, issue.GetAssignee().GetLogin())
+	}
+	return logins
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestAddAssignee(t *testing.T) {
+	var assignable bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct{ Assignees []string }
+		json.NewDecoder(r.Body).Decode(&request)
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/o/r/issues/7/assignees" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		issue := github.Issue{Number: github.Int(7), Assignees: []*github.User{{Login: github.String("octocat")}}}
+		if assignable {
+			for _, login := range request.Assignees {
+				issue.Assignees = append(issue.Assignees, &github.User{Login: github.String(login)})
+			}
+		}
+		json.NewEncoder(w).Encode(issue)
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	if err := handler.AddAssignee(context.Background(), "o/r", 7, "Hubot"); err == nil {
+		t.Error("Expected an error when GitHub skips the assignee")
+	}
+	assignable = true
+	if err := handler.AddAssignee(context.Background(), "o/r", 7, "hubot"); err != nil {
+		t.Errorf("AddAssignee: %v", err)
+	}
+}
+
+func TestUserEmails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/octocat":
+			json.NewEncoder(w).Encode(github.User{Login: github.String("octocat"), Email: github.String("octo@example.com")})
+		case "/search/users":
+			result := github.UsersSearchResult{}
+			if r.URL.Query().Get("q") == `"octo@example.com" in:email` {
+				result.Users = []*github.User{{Login: github.String("octocat")}}
+			}
+			json.NewEncoder(w).Encode(result)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	email, err := handler.UserEmail(context.Background(), "octocat")
+	if err != nil || email != "octo@example.com" {
+		t.Errorf("Expected octocat's email, got %q, %v", email, err)
+	}
+	login, err := handler.LoginByEmail(context.Background(), "octo@example.com")
+	if err != nil || login != "octocat" {
+		t.Errorf("Expected octocat, got %q, %v", login, err)
+	}
+	if login, _ := handler.LoginByEmail(context.Background(), "nobody@example.com"); login != "" {
+		t.Errorf("Expected no login for an unknown email, got %q", login)
+	}
+}
+
+func TestHasWriteAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permissions := map[string]string{
+			"/repos/o/r/collaborators/owner/permission":    "admin",
+			"/repos/o/r/collaborators/octocat/permission":  "write",
+			"/repos/o/r/collaborators/reporter/permission": "read",
+		}
+		permission, ok := permissions[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(github.RepositoryPermissionLevel{Permission: github.String(permission)})
+	}))
+	defer server.Close()
+
+	handler := newCloseTestHandler(server)
+	for login, want := range map[string]bool{"owner": true, "octocat": true, "reporter": false, "ghost": false} {
+		if got, err := handler.HasWriteAccess(context.Background(), "o/r", login); err != nil || got != want {
+			t.Errorf("Expected write access %v for %s, got %v, %v", want, login, got, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package harness
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Fixture is a recorded scenario: the webhooks GitHub sent, what the GitHub
+// and OpenAI APIs answered while they were processed, and the Slack calls the
+// server made in response
+type Fixture struct {
+	Description string                     `json:"description,omitempty"`
+	Webhooks    []Webhook                  `json:"webhooks"`
+	GitHub      map[string]json.RawMessage `json:"github,omitempty"` // Responses by "METHOD /path?query"; other requests get a 404
+	OpenAI      []string                   `json:"openai,omitempty"` // Chat completion contents, in request order
+	Slack       []SlackCall                `json:"slack"`            // Expected Slack API calls, in order
+}
+
+// Webhook is one recorded GitHub webhook delivery
+type Webhook struct {
+	Event   string          `json:"event"` // X-GitHub-Event header, e.g. issues
+	Payload json.RawMessage `json:"payload"`
+}
+
+// SlackCall is one Slack Web API call, with the JSON form fields decoded so
+// fixtures can be read and diffed
+type SlackCall struct {
+	Method      string      `json:"method"` // e.g. chat.postMessage
+	Channel     string      `json:"channel,omitempty"`
+	TS          string      `json:"ts,omitempty"`
+	ThreadTS    string      `json:"thread_ts,omitempty"`
+	Text        string      `json:"text,omitempty"`
+	Blocks      interface{} `json:"blocks,omitempty"`
+	Attachments interface{} `json:"attachments,omitempty"`
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes the fixture as indented JSON, so re-recorded fixtures diff well
+func (f *Fixture) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(f); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
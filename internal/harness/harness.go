@@ -0,0 +1,386 @@
+// Package harness runs the NotifyOps server end to end against fake GitHub,
+// Slack and OpenAI APIs, so recorded webhooks can be replayed and the Slack
+// messages they produce compared with what was recorded.
+package harness
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gogithub "github.com/google/go-github/v57/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/slack"
+	"github-issue-ai-bot/internal/store"
+)
+
+// RecordEnv enables recording mode when set to 1. Replayed fixtures are then
+// rewritten with the Slack calls the server made, and GitHub and OpenAI
+// requests the fixture has no answer for go to the real APIs, authenticated
+// with GITHUB_ACCESS_TOKEN and OPENAI_API_KEY, and their answers are kept.
+const RecordEnv = "NOTIFYOPS_RECORD_FIXTURES"
+
+// Settings of the server under test
+const (
+	WebhookSecret = "harness-webhook-secret"
+	ChannelID     = "C-HARNESS"
+)
+
+// Upstream APIs used in recording mode
+var (
+	GitHubAPIURL = "https://api.github.com"
+	OpenAIAPIURL = "https://api.openai.com/v1"
+)
+
+// processTimeout bounds how long a delivered webhook may take to process
+const processTimeout = 10 * time.Second
+
+// Harness is a running server with fake APIs behind it
+type Harness struct {
+	Server    *httptest.Server // Serves the webhook endpoints like the real server
+	Processor *pipeline.IssueProcessor
+	Store     *store.MemoryStore
+
+	t        testing.TB
+	fixture  *Fixture
+	record   bool
+	queue    *github.WorkQueue
+	delivery int
+
+	mu         sync.Mutex
+	slackCalls []SlackCall
+	completion int
+}
+
+// Recording reports whether fixtures are being recorded rather than checked
+func Recording() bool {
+	return os.Getenv(RecordEnv) == "1"
+}
+
+// New starts a server whose GitHub and OpenAI APIs answer from the fixture.
+// Everything is shut down when the test ends.
+func New(t testing.TB, fixture *Fixture, record bool) *Harness {
+	t.Helper()
+	if fixture.GitHub == nil {
+		fixture.GitHub = map[string]json.RawMessage{}
+	}
+	h := &Harness{t: t, fixture: fixture, record: record}
+
+	githubAPI := httptest.NewServer(http.HandlerFunc(h.serveGitHub))
+	t.Cleanup(githubAPI.Close)
+	openaiAPI := httptest.NewServer(http.HandlerFunc(h.serveOpenAI))
+	t.Cleanup(openaiAPI.Close)
+	slackAPI := httptest.NewServer(http.HandlerFunc(h.serveSlack))
+	t.Cleanup(slackAPI.Close)
+
+	logger := zap.NewNop()
+	metrics := monitor.NewRegistryMetrics(prometheus.NewRegistry())
+
+	client := gogithub.NewClient(nil)
+	client.BaseURL, _ = url.Parse(githubAPI.URL + "/")
+	githubHandler := github.NewHandlerWithClient(client, WebhookSecret, logger, metrics)
+	actions, err := github.NewActionMatrix(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	githubHandler.SetActionMatrix(actions)
+	// One worker processes deliveries in order, like a quiet production server
+	h.queue, err = github.NewWorkQueue(github.QueueConfig{Workers: 1, Size: 100, Policy: github.SaturationReject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	githubHandler.SetWorkQueue(h.queue)
+
+	summarizer := ai.NewSummarizer("harness-openai-key", "gpt-4", 2000, 0.3, logger, metrics)
+	if err := summarizer.SetClientOptions(ai.ClientOptions{BaseURL: openaiAPI.URL + "/v1"}); err != nil {
+		t.Fatal(err)
+	}
+	taxonomy, err := ai.NewTaxonomy(ai.TaxonomyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summarizer.SetTaxonomy(taxonomy)
+
+	slackNotifier := slack.NewNotifier("xoxb-harness", ChannelID, "harness-signing-secret", logger, metrics, summarizer, githubHandler)
+	slackNotifier.SetAPIURL(slackAPI.URL + "/")
+
+	router, err := routing.NewRouter(nil, ChannelID, routing.LayoutDetailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Store = store.NewMemoryStore()
+	h.Processor = pipeline.NewIssueProcessor(summarizer, slackNotifier, router, h.Store, logger, metrics)
+	h.Processor.SetTaxonomy(taxonomy)
+	slackNotifier.SetIssueMemory(h.Processor)
+	slackNotifier.SetSummaryExplainer(h.Processor)
+	githubHandler.SetIssueProcessor(h.Processor)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	})
+	engine.POST("/webhook/github", func(c *gin.Context) {
+		githubHandler.HandleWebhook(c.Writer, c.Request)
+	})
+	engine.POST("/webhook/slack", func(c *gin.Context) {
+		slackNotifier.HandleInteractiveMessage(c.Writer, c.Request)
+	})
+	h.Server = httptest.NewServer(engine)
+	t.Cleanup(h.Server.Close)
+	return h
+}
+
+// Deliver sends a signed webhook to the server and waits until it has been
+// processed. It returns the status code of the webhook response.
+func (h *Harness) Deliver(webhook Webhook) int {
+	h.t.Helper()
+	h.delivery++
+	mac := hmac.New(sha256.New, []byte(WebhookSecret))
+	mac.Write(webhook.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, h.Server.URL+"/webhook/github", bytes.NewReader(webhook.Payload))
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", webhook.Event)
+	req.Header.Set("X-GitHub-Delivery", fmt.Sprintf("harness-%d", h.delivery))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		h.t.Fatalf("Failed to deliver %s webhook: %v", webhook.Event, err)
+	}
+	resp.Body.Close()
+
+	h.wait()
+	return resp.StatusCode
+}
+
+// wait blocks until every accepted webhook has been processed
+func (h *Harness) wait() {
+	h.t.Helper()
+	deadline := time.Now().Add(processTimeout)
+	for h.queue.Depth() > 0 {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("Webhooks still processing after %s", processTimeout)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// SlackCalls returns the Slack API calls made so far
+func (h *Harness) SlackCalls() []SlackCall {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]SlackCall(nil), h.slackCalls...)
+}
+
+// Replay delivers a fixture's webhooks and checks the Slack calls against the
+// recorded ones. In recording mode the fixture is rewritten instead.
+func Replay(t *testing.T, path string) {
+	t.Helper()
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := Recording()
+	h := New(t, fixture, record)
+	for _, webhook := range fixture.Webhooks {
+		if status := h.Deliver(webhook); status >= 300 {
+			t.Fatalf("Expected the %s webhook to be accepted, got %d", webhook.Event, status)
+		}
+	}
+
+	calls := h.SlackCalls()
+	if record {
+		fixture.Slack = calls
+		if err := fixture.Save(path); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("Recorded %d Slack calls to %s", len(calls), path)
+		return
+	}
+	if !sameCalls(calls, fixture.Slack) {
+		got, _ := json.MarshalIndent(calls, "", "  ")
+		want, _ := json.MarshalIndent(fixture.Slack, "", "  ")
+		t.Errorf("Slack calls differ from %s (set %s=1 to re-record)\ngot:\n%s\nwant:\n%s", path, RecordEnv, got, want)
+	}
+}
+
+// sameCalls compares Slack calls as JSON, the way fixtures store them
+func sameCalls(got, want []SlackCall) bool {
+	var a, b interface{}
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	json.Unmarshal(gotJSON, &a)
+	json.Unmarshal(wantJSON, &b)
+	return reflect.DeepEqual(a, b)
+}
+
+// serveGitHub answers from the fixture, or from the real API in recording mode
+func (h *Harness) serveGitHub(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	if r.URL.RawQuery != "" {
+		key += "?" + r.URL.RawQuery
+	}
+
+	h.mu.Lock()
+	response, ok := h.fixture.GitHub[key]
+	h.mu.Unlock()
+	if !ok && h.record {
+		response, ok = h.recordGitHub(r, key)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+		return
+	}
+	w.Write(response)
+}
+
+// recordGitHub fetches a response from the real GitHub API and keeps it
+func (h *Harness) recordGitHub(r *http.Request, key string) (json.RawMessage, bool) {
+	body, _ := io.ReadAll(r.Body)
+	req, err := http.NewRequest(r.Method, GitHubAPIURL+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("Accept", r.Header.Get("Accept"))
+	if token := os.Getenv("GITHUB_ACCESS_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, ok := fetch(req)
+	if ok {
+		h.mu.Lock()
+		h.fixture.GitHub[key] = response
+		h.mu.Unlock()
+	}
+	return response, ok
+}
+
+// serveOpenAI answers chat completions with the fixture's contents in order,
+// or from the real API in recording mode once they run out
+func (h *Harness) serveOpenAI(w http.ResponseWriter, r *http.Request) {
+	var request openai.ChatCompletionRequest
+	body, _ := io.ReadAll(r.Body)
+	if r.URL.Path != "/v1/chat/completions" || json.Unmarshal(body, &request) != nil || request.Stream {
+		http.Error(w, `{"error": {"message": "not supported by the harness"}}`, http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	index := h.completion
+	h.completion++
+	var content string
+	ok := index < len(h.fixture.OpenAI)
+	if ok {
+		content = h.fixture.OpenAI[index]
+	}
+	h.mu.Unlock()
+	if !ok && h.record {
+		content, ok = h.recordOpenAI(body)
+	}
+	if !ok {
+		http.Error(w, `{"error": {"message": "no recorded completion"}}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+		Model: request.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+			FinishReason: openai.FinishReasonStop,
+		}},
+		Usage: openai.Usage{PromptTokens: 1000, CompletionTokens: 200, TotalTokens: 1200},
+	})
+}
+
+// recordOpenAI sends a chat completion to the real API and keeps its content
+func (h *Harness) recordOpenAI(body []byte) (string, bool) {
+	req, err := http.NewRequest(http.MethodPost, OpenAIAPIURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("OPENAI_API_KEY"))
+	response, ok := fetch(req)
+	var completion openai.ChatCompletionResponse
+	if !ok || json.Unmarshal(response, &completion) != nil || len(completion.Choices) == 0 {
+		return "", false
+	}
+
+	content := completion.Choices[0].Message.Content
+	h.mu.Lock()
+	h.fixture.OpenAI = append(h.fixture.OpenAI, content)
+	h.mu.Unlock()
+	return content, true
+}
+
+// fetch returns the body of a successful response
+func fetch(req *http.Request) (json.RawMessage, bool) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK || !json.Valid(body) {
+		return nil, false
+	}
+	return body, true
+}
+
+// serveSlack records Web API calls and answers each one successfully
+func (h *Harness) serveSlack(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	call := SlackCall{
+		Method:   strings.TrimPrefix(r.URL.Path, "/"),
+		Channel:  r.FormValue("channel"),
+		TS:       r.FormValue("ts"),
+		ThreadTS: r.FormValue("thread_ts"),
+		Text:     r.FormValue("text"),
+	}
+	if blocks := r.FormValue("blocks"); blocks != "" {
+		json.Unmarshal([]byte(blocks), &call.Blocks)
+	}
+	if attachments := r.FormValue("attachments"); attachments != "" {
+		json.Unmarshal([]byte(attachments), &call.Attachments)
+	}
+
+	h.mu.Lock()
+	h.slackCalls = append(h.slackCalls, call)
+	ts := fmt.Sprintf("1700000000.%06d", len(h.slackCalls))
+	h.mu.Unlock()
+	if call.TS != "" {
+		ts = call.TS
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": call.Channel, "ts": ts})
+}
@@ -0,0 +1,47 @@
+package harness
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestHarnessCapturesSlackCalls(t *testing.T) {
+	fixture := &Fixture{OpenAI: []string{`{"title": "Crash", "summary": "It crashes", "priority": "low", "category": "bug", "confidence": 0.5}`}}
+	h := New(t, fixture, false)
+
+	payload := `{"action": "opened", "issue": {"number": 1, "title": "Crash", "state": "open", "user": {"login": "octocat"},
+		"repository_url": "https://api.github.com/repos/owner/repo"},
+		"repository": {"name": "repo", "full_name": "owner/repo", "owner": {"login": "owner"}}}`
+	if status := h.Deliver(Webhook{Event: "issues", Payload: json.RawMessage(payload)}); status != 200 {
+		t.Fatalf("Expected the webhook accepted, got %d", status)
+	}
+	calls := h.SlackCalls()
+	if len(calls) != 1 || calls[0].Method != "chat.postMessage" || calls[0].Channel != ChannelID || calls[0].Blocks == nil {
+		t.Fatalf("Expected one posted message, got %+v", calls)
+	}
+	if record, ok := h.Store.GetIssue("owner/repo", 1); !ok || record.MessageTS != "1700000000.000001" {
+		t.Errorf("Expected the message stored, got %+v", record)
+	}
+}
+
+func TestFixtureRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	fixture := &Fixture{
+		Webhooks: []Webhook{{Event: "issues", Payload: json.RawMessage(`{"action":"opened"}`)}},
+		Slack:    []SlackCall{{Method: "chat.postMessage", Blocks: []interface{}{map[string]interface{}{"type": "divider"}}}},
+	}
+	if err := fixture.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameCalls(loaded.Slack, fixture.Slack) || string(loaded.Webhooks[0].Payload) == "" {
+		t.Errorf("Expected the fixture back, got %+v", loaded)
+	}
+	if sameCalls(loaded.Slack, nil) {
+		t.Error("Expected missing calls to differ")
+	}
+}
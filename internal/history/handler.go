@@ -0,0 +1,161 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+)
+
+// maxRequestBytes bounds the body of an API import request
+const maxRequestBytes = 16 << 10
+
+// maxArchiveBytes bounds an uploaded archive. An hour of GH Archive is
+// around 100 MB gzipped.
+const maxArchiveBytes = 1 << 30
+
+// ImportRequest starts an API import
+type ImportRequest struct {
+	Repositories []string  `json:"repositories"` // owner/name
+	Since        time.Time `json:"since"`        // Issues updated since, zero for every issue
+}
+
+// Status is the state of the last API import
+type Status struct {
+	Running    bool      `json:"running"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Results    []Result  `json:"results"`
+}
+
+// Handler starts imports and reports on them. Requests are authorized by
+// the admin token.
+type Handler struct {
+	importer   *Importer
+	adminToken *admin.Token
+	logger     *zap.Logger
+	baseCtx    context.Context
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewHandler creates an import handler
+func NewHandler(importer *Importer, adminToken *admin.Token, logger *zap.Logger) *Handler {
+	return &Handler{importer: importer, adminToken: adminToken, logger: logger, baseCtx: context.Background(), status: Status{Results: []Result{}}}
+}
+
+// SetBaseContext sets the parent context of API imports, which outlive the
+// request that started them. Cancelling it, e.g. on shutdown, stops them.
+func (h *Handler) SetBaseContext(ctx context.Context) {
+	h.baseCtx = ctx
+}
+
+// ServeStart starts importing the requested repositories through the API in
+// the background, one after another. Only one import runs at a time.
+func (h *Handler) ServeStart(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	var request ImportRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, "invalid import request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validRepositories(request.Repositories); err != "" {
+		http.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	if h.status.Running {
+		h.mu.Unlock()
+		http.Error(w, "an import is already running", http.StatusConflict)
+		return
+	}
+	h.status = Status{Running: true, StartedAt: time.Now(), Results: []Result{}}
+	status := h.status
+	h.mu.Unlock()
+
+	go h.run(request)
+	h.write(w, http.StatusAccepted, status)
+}
+
+func (h *Handler) run(request ImportRequest) {
+	for _, repo := range request.Repositories {
+		if h.baseCtx.Err() != nil {
+			break
+		}
+		result, err := h.importer.ImportRepository(h.baseCtx, repo, request.Since)
+		if err != nil {
+			h.logger.Warn("Import stopped early", zap.String("repository", repo), zap.Error(err))
+		}
+		h.mu.Lock()
+		h.status.Results = append(h.status.Results, result)
+		h.mu.Unlock()
+	}
+
+	h.mu.Lock()
+	h.status.Running = false
+	h.status.FinishedAt = time.Now()
+	h.mu.Unlock()
+}
+
+// ServeStatus returns the state of the last API import
+func (h *Handler) ServeStatus(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	h.mu.Lock()
+	status := h.status
+	status.Results = append([]Result{}, h.status.Results...)
+	h.mu.Unlock()
+	h.write(w, http.StatusOK, status)
+}
+
+// ServeArchive imports the issues of the repositories given by the
+// repository parameter, which may repeat, from a GH Archive export in the
+// request body
+func (h *Handler) ServeArchive(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	repositories := r.URL.Query()["repository"]
+	if err := validRepositories(repositories); err != "" {
+		http.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.importer.ImportArchive(r.Context(), http.MaxBytesReader(w, r.Body, maxArchiveBytes), repositories)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.write(w, http.StatusOK, results)
+}
+
+// validRepositories describes what is wrong with the repositories to
+// import, or returns empty if nothing is
+func validRepositories(repositories []string) string {
+	if len(repositories) == 0 {
+		return "at least one repository is required"
+	}
+	for _, repo := range repositories {
+		if parts := strings.Split(repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return "repositories must be owner/name, got " + repo
+		}
+	}
+	return ""
+}
+
+func (h *Handler) write(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,259 @@
+// Package history imports issues from before the bot was adopted, so
+// analytics, rollups and searches have context from the first day
+package history
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// IssueStore keeps the imported records
+type IssueStore interface {
+	GetIssue(repository string, number int) (*store.IssueRecord, bool)
+	SaveIssue(record *store.IssueRecord)
+}
+
+// Source reads a repository's history through the GitHub API
+type Source interface {
+	ListIssueHistory(ctx context.Context, repo string, since time.Time, visit func(issues []*github.Issue)) error
+	ListCommentHistory(ctx context.Context, repo string, since time.Time, visit func(comments []*github.IssueComment)) error
+}
+
+// Result counts what an import did for a repository
+type Result struct {
+	Repository   string `json:"repository"`
+	Imported     int    `json:"imported"`      // New records
+	Existing     int    `json:"existing"`      // Issues the bot already has a record of, left as they are
+	PullRequests int    `json:"pull_requests"` // Pull requests, which are not imported
+	Error        string `json:"error,omitempty"`
+}
+
+// Importer writes past issues to the store as records without a summary.
+// It never runs the pipeline, so nothing is posted to Slack, and it never
+// replaces a record of an issue the bot has processed.
+type Importer struct {
+	store  IssueStore
+	source Source
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewImporter creates an importer reading the API through source
+func NewImporter(store IssueStore, source Source, logger *zap.Logger) *Importer {
+	return &Importer{store: store, source: source, logger: logger, now: time.Now}
+}
+
+// pending is an issue read from the history and the maintainer comments on
+// it, until it is saved
+type pending struct {
+	repository string
+	issue      *github.Issue
+	comments   []*github.IssueComment
+}
+
+// ImportRepository imports the issues of repo updated since a time through
+// the API. Maintainer comments are read first, for the time each issue was
+// first answered. When the rate limit runs low the import stops, keeping
+// what was read, and the result carries the error.
+func (i *Importer) ImportRepository(ctx context.Context, repo string, since time.Time) (Result, error) {
+	result := Result{Repository: repo}
+
+	comments := make(map[int][]*github.IssueComment)
+	err := i.source.ListCommentHistory(ctx, repo, since, func(page []*github.IssueComment) {
+		for _, comment := range page {
+			if maintainerComment(comment) {
+				number := gh.CommentIssueNumber(comment)
+				comments[number] = append(comments[number], comment)
+			}
+		}
+	})
+	if err != nil {
+		// Issues without response times are still worth importing
+		i.logger.Warn("Failed to read comment history, importing issues without response times",
+			zap.String("repository", repo),
+			zap.Error(err))
+	}
+
+	err = i.source.ListIssueHistory(ctx, repo, since, func(page []*github.Issue) {
+		for _, issue := range page {
+			i.save(&result, pending{repository: repo, issue: issue, comments: comments[issue.GetNumber()]})
+		}
+	})
+	if err != nil {
+		result.Error = err.Error()
+	}
+	i.logImported(result)
+	return result, err
+}
+
+// archiveEvent is the part of a GH Archive event the importer reads
+type archiveEvent struct {
+	Type string `json:"type"`
+	Repo struct {
+		Name string `json:"name"`
+	} `json:"repo"`
+	Payload struct {
+		Issue   *github.Issue        `json:"issue"`
+		Comment *github.IssueComment `json:"comment"`
+	} `json:"payload"`
+}
+
+// ImportArchive imports the issues of the given repositories from a GH
+// Archive export (https://www.gharchive.org): newline-delimited JSON events,
+// optionally gzipped. Each issue is imported as of its latest event in the
+// export. Events of other repositories are ignored.
+func (i *Importer) ImportArchive(ctx context.Context, r io.Reader, repositories []string) ([]Result, error) {
+	if len(repositories) == 0 {
+		return nil, errors.New("no repositories to import")
+	}
+	wanted := make(map[string]string, len(repositories))
+	for _, repo := range repositories {
+		wanted[strings.ToLower(repo)] = repo
+	}
+
+	reader, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	issues := make(map[string]*pending)
+	var order []string
+	decoder := json.NewDecoder(reader)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var event archiveEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		repo, ok := wanted[strings.ToLower(event.Repo.Name)]
+		if !ok || event.Payload.Issue == nil || (event.Type != "IssuesEvent" && event.Type != "IssueCommentEvent") {
+			continue
+		}
+
+		issue := event.Payload.Issue
+		key := fmt.Sprintf("%s#%d", strings.ToLower(repo), issue.GetNumber())
+		p, ok := issues[key]
+		if !ok {
+			p = &pending{repository: repo, issue: issue}
+			issues[key] = p
+			order = append(order, key)
+		}
+		if !issue.GetUpdatedAt().Before(p.issue.GetUpdatedAt().Time) {
+			p.issue = issue
+		}
+		if comment := event.Payload.Comment; comment != nil && maintainerComment(comment) {
+			p.comments = append(p.comments, comment)
+		}
+	}
+
+	results := make(map[string]*Result, len(repositories))
+	for _, repo := range repositories {
+		results[strings.ToLower(repo)] = &Result{Repository: repo}
+	}
+	for _, key := range order {
+		p := issues[key]
+		i.save(results[strings.ToLower(p.repository)], *p)
+	}
+	list := make([]Result, 0, len(repositories))
+	for _, repo := range repositories {
+		result := *results[strings.ToLower(repo)]
+		i.logImported(result)
+		list = append(list, result)
+	}
+	return list, nil
+}
+
+// save stores an issue unless it is a pull request or already has a record
+func (i *Importer) save(result *Result, p pending) {
+	if p.issue.IsPullRequest() {
+		result.PullRequests++
+		return
+	}
+	if _, ok := i.store.GetIssue(p.repository, p.issue.GetNumber()); ok {
+		result.Existing++
+		return
+	}
+	i.store.SaveIssue(issueRecord(p, i.now()))
+	result.Imported++
+}
+
+// issueRecord is the record of an imported issue. Its UpdatedAt is when the
+// issue was last updated on GitHub, so time-bounded queries place it in its
+// own time rather than at the import.
+func issueRecord(p pending, importedAt time.Time) *store.IssueRecord {
+	issue := p.issue
+	labels := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	record := &store.IssueRecord{
+		Repository:     p.repository,
+		Number:         issue.GetNumber(),
+		Title:          issue.GetTitle(),
+		Body:           issue.GetBody(),
+		State:          issue.GetState(),
+		Environment:    gh.ExtractEnvironment(issue.GetBody(), nil),
+		Labels:         labels,
+		OpenedAt:       issue.GetCreatedAt().Time,
+		AcknowledgedAt: gh.FirstMaintainerResponse(issue, p.comments),
+		ImportedAt:     importedAt,
+		UpdatedAt:      issue.GetUpdatedAt().Time,
+	}
+	if issue.GetState() == "closed" {
+		record.ClosedAt = issue.GetClosedAt().Time
+		record.CloseReason = issue.GetStateReason()
+	}
+	return record
+}
+
+func (i *Importer) logImported(result Result) {
+	i.logger.Info("Imported issue history",
+		zap.String("repository", result.Repository),
+		zap.Int("imported", result.Imported),
+		zap.Int("existing", result.Existing),
+		zap.Int("pull_requests", result.PullRequests),
+		zap.String("error", result.Error))
+}
+
+// maintainerComment reports whether a comment could be a maintainer's
+// response; FirstMaintainerResponse also leaves out the reporter's own
+func maintainerComment(comment *github.IssueComment) bool {
+	switch comment.GetAuthorAssociation() {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return true
+	}
+	return false
+}
+
+// decompress returns a reader of r's content, gunzipping it if it is gzipped
+func decompress(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzipped archive: %w", err)
+		}
+		return gz, nil
+	}
+	return buffered, nil
+}
@@ -0,0 +1,188 @@
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/store"
+)
+
+type fakeSource struct {
+	issues   []*github.Issue
+	comments []*github.IssueComment
+}
+
+func (f fakeSource) ListIssueHistory(ctx context.Context, repo string, since time.Time, visit func([]*github.Issue)) error {
+	visit(f.issues)
+	return nil
+}
+
+func (f fakeSource) ListCommentHistory(ctx context.Context, repo string, since time.Time, visit func([]*github.IssueComment)) error {
+	visit(f.comments)
+	return nil
+}
+
+var opened = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func testIssue(number int, state string, updated time.Time) *github.Issue {
+	return &github.Issue{
+		Number:    github.Int(number),
+		Title:     github.String("Crash on save"),
+		Body:      github.String("Saving crashes the app"),
+		State:     github.String(state),
+		User:      &github.User{Login: github.String("reporter")},
+		Labels:    []*github.Label{{Name: github.String("bug")}},
+		CreatedAt: &github.Timestamp{Time: opened},
+		UpdatedAt: &github.Timestamp{Time: updated},
+	}
+}
+
+func TestImportRepository(t *testing.T) {
+	closed := testIssue(2, "closed", opened.Add(48*time.Hour))
+	closed.ClosedAt = &github.Timestamp{Time: opened.Add(48 * time.Hour)}
+	closed.StateReason = github.String("completed")
+	source := fakeSource{
+		issues: []*github.Issue{
+			testIssue(1, "open", opened.Add(time.Hour)),
+			closed,
+			{Number: github.Int(3), PullRequestLinks: &github.PullRequestLinks{URL: github.String("https://api.github.com/repos/o/r/pulls/3")}},
+			testIssue(4, "open", opened),
+		},
+		comments: []*github.IssueComment{
+			{IssueURL: github.String("https://api.github.com/repos/o/r/issues/1"), AuthorAssociation: github.String("NONE"), User: &github.User{Login: github.String("someone")}, CreatedAt: &github.Timestamp{Time: opened.Add(10 * time.Minute)}},
+			{IssueURL: github.String("https://api.github.com/repos/o/r/issues/1"), AuthorAssociation: github.String("MEMBER"), User: &github.User{Login: github.String("maintainer")}, CreatedAt: &github.Timestamp{Time: opened.Add(30 * time.Minute)}},
+		},
+	}
+
+	issues := store.NewMemoryStore()
+	issues.SaveIssue(&store.IssueRecord{Repository: "o/r", Number: 4, MessageTS: "1700000000.000100"})
+	importer := NewImporter(issues, source, zap.NewNop())
+	importedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	importer.now = func() time.Time { return importedAt }
+
+	result, err := importer.ImportRepository(context.Background(), "o/r", time.Time{})
+	if err != nil {
+		t.Fatalf("ImportRepository: %v", err)
+	}
+	if want := (Result{Repository: "o/r", Imported: 2, Existing: 1, PullRequests: 1}); result != want {
+		t.Errorf("Expected %+v, got %+v", want, result)
+	}
+
+	record, ok := issues.GetIssue("o/r", 1)
+	if !ok {
+		t.Fatal("Expected #1 imported")
+	}
+	if record.Title != "Crash on save" || record.State != "open" || !record.OpenedAt.Equal(opened) ||
+		!record.AcknowledgedAt.Equal(opened.Add(30*time.Minute)) || !record.ImportedAt.Equal(importedAt) ||
+		!record.UpdatedAt.Equal(opened.Add(time.Hour)) || record.Summary != nil || record.MessageTS != "" {
+		t.Errorf("Unexpected imported record %+v", record)
+	}
+	if record, _ := issues.GetIssue("o/r", 2); !record.ClosedAt.Equal(opened.Add(48*time.Hour)) || record.CloseReason != "completed" {
+		t.Errorf("Expected #2 imported as closed, got %+v", record)
+	}
+	if record, _ := issues.GetIssue("o/r", 4); record.MessageTS == "" || !record.ImportedAt.IsZero() {
+		t.Errorf("Expected the bot's own record of #4 kept, got %+v", record)
+	}
+}
+
+// archive encodes events as a gzipped GH Archive export
+func archive(t *testing.T, events ...map[string]interface{}) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gz.Close()
+	return &buf
+}
+
+func exportEvent(kind, repo string, payload map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": kind, "repo": map[string]string{"name": repo}, "payload": payload}
+}
+
+func TestImportArchive(t *testing.T) {
+	closed := testIssue(7, "closed", opened.Add(24*time.Hour))
+	export := archive(t,
+		exportEvent("IssuesEvent", "Acme/API", map[string]interface{}{"action": "opened", "issue": testIssue(7, "open", opened)}),
+		exportEvent("IssueCommentEvent", "acme/api", map[string]interface{}{
+			"action": "created",
+			"issue":  testIssue(7, "open", opened.Add(time.Hour)),
+			"comment": github.IssueComment{
+				AuthorAssociation: github.String("OWNER"),
+				User:              &github.User{Login: github.String("maintainer")},
+				CreatedAt:         &github.Timestamp{Time: opened.Add(time.Hour)},
+			},
+		}),
+		exportEvent("IssuesEvent", "acme/api", map[string]interface{}{"action": "closed", "issue": closed}),
+		exportEvent("WatchEvent", "acme/api", map[string]interface{}{"action": "started"}),
+		exportEvent("IssuesEvent", "other/repo", map[string]interface{}{"action": "opened", "issue": testIssue(1, "open", opened)}),
+	)
+
+	issues := store.NewMemoryStore()
+	results, err := NewImporter(issues, nil, zap.NewNop()).ImportArchive(context.Background(), export, []string{"acme/api"})
+	if err != nil {
+		t.Fatalf("ImportArchive: %v", err)
+	}
+	if len(results) != 1 || results[0].Imported != 1 {
+		t.Errorf("Expected one issue imported, got %+v", results)
+	}
+	record, ok := issues.GetIssue("acme/api", 7)
+	if !ok || record.State != "closed" || !record.AcknowledgedAt.Equal(opened.Add(time.Hour)) {
+		t.Errorf("Expected #7 imported as of its last event, got %+v", record)
+	}
+	if _, ok := issues.GetIssue("other/repo", 1); ok {
+		t.Error("Expected other repositories ignored")
+	}
+
+	// Plain exports are read too
+	plain := `{"type":"IssuesEvent","repo":{"name":"acme/api"},"payload":{"issue":{"number":8,"state":"open"}}}`
+	results, err = NewImporter(issues, nil, zap.NewNop()).ImportArchive(context.Background(), strings.NewReader(plain), []string{"acme/api"})
+	if err != nil || results[0].Imported != 1 {
+		t.Errorf("Expected the plain export imported, got %+v, %v", results, err)
+	}
+}
+
+func TestServeArchive(t *testing.T) {
+	handler := NewHandler(NewImporter(store.NewMemoryStore(), nil, zap.NewNop()), admin.NewToken("admin-token"), zap.NewNop())
+	export := archive(t, exportEvent("IssuesEvent", "acme/api", map[string]interface{}{"issue": testIssue(7, "open", opened)}))
+
+	request := httptest.NewRequest(http.MethodPost, "/api/import/archive?repository=acme/api", export)
+	recorder := httptest.NewRecorder()
+	handler.ServeArchive(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized without the admin token, got %d", recorder.Code)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/api/import/archive?repository=acme/api", export)
+	request.Header.Set("Authorization", "Bearer admin-token")
+	recorder = httptest.NewRecorder()
+	handler.ServeArchive(recorder, request)
+	var results []Result
+	json.NewDecoder(recorder.Body).Decode(&results)
+	if recorder.Code != http.StatusOK || len(results) != 1 || results[0].Imported != 1 {
+		t.Errorf("Expected one issue imported, got %d %+v", recorder.Code, results)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/api/import/archive?repository=acme", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-token")
+	recorder = httptest.NewRecorder()
+	handler.ServeArchive(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid repository rejected, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,107 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+)
+
+// maxRequestBytes bounds the body of an identity request
+const maxRequestBytes = 16 << 10
+
+// Store persists the identities mapped through the API
+type Store interface {
+	Identities() []Identity
+	SaveIdentities(identities []Identity)
+}
+
+// Handler lists, maps and unmaps identities at runtime. Requests are
+// authorized by the admin token.
+type Handler struct {
+	directory  *Directory
+	store      Store
+	adminToken *admin.Token
+	logger     *zap.Logger
+}
+
+// NewHandler creates an identity handler and maps the stored identities in
+// directory. Stored identities replace configured ones for the same
+// accounts.
+func NewHandler(directory *Directory, store Store, adminToken *admin.Token, logger *zap.Logger) *Handler {
+	for _, identity := range store.Identities() {
+		if err := directory.Put(identity); err != nil {
+			logger.Warn("Skipping stored identity", zap.String("github", identity.GitHub), zap.Error(err))
+		}
+	}
+	return &Handler{directory: directory, store: store, adminToken: adminToken, logger: logger}
+}
+
+// ServeList returns every mapped identity, including those matched by email
+func (h *Handler) ServeList(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	h.write(w, http.StatusOK, h.directory.List())
+}
+
+// ServeSave maps the GitHub login and Slack user of an Identity, replacing
+// any mapping of either
+func (h *Handler) ServeSave(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	var identity Identity
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&identity); err != nil {
+		http.Error(w, "invalid identity: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	identity.Source = SourceAPI
+	if err := h.directory.Put(identity); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.persist()
+	h.logger.Info("Mapped identity", zap.String("github", identity.GitHub), zap.String("slack", identity.Slack))
+	h.write(w, http.StatusOK, identity)
+}
+
+// ServeDelete unmaps the GitHub login named by the last path segment. A
+// login matched by email may be matched again.
+func (h *Handler) ServeDelete(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	login := path.Base(r.URL.Path)
+	if !h.directory.Delete(login) {
+		http.Error(w, fmt.Sprintf("no identity for %s", login), http.StatusNotFound)
+		return
+	}
+	h.persist()
+	h.logger.Info("Unmapped identity", zap.String("github", login))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// persist stores the identities mapped through the API
+func (h *Handler) persist() {
+	var identities []Identity
+	for _, identity := range h.directory.List() {
+		if identity.Source == SourceAPI {
+			identities = append(identities, identity)
+		}
+	}
+	h.store.SaveIdentities(identities)
+}
+
+// write encodes a JSON response
+func (h *Handler) write(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to write identities", zap.Error(err))
+	}
+}
@@ -0,0 +1,246 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Sources of an identity
+const (
+	SourceConfig = "config" // Read from the identities key of the config file
+	SourceAPI    = "api"    // Saved through /api/identities
+	SourceEmail  = "email"  // Matched by email
+)
+
+// unmatchedRetry is how long a failed email match is remembered before the
+// user is looked up again, e.g. after they make their email public
+const unmatchedRetry = 24 * time.Hour
+
+var (
+	githubLogin = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9]|-[A-Za-z0-9]){0,38}$`)
+	slackUserID = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+)
+
+// Identity is one person's GitHub and Slack accounts. Identities are read
+// from the identities key of the config file and managed at runtime through
+// /api/identities.
+type Identity struct {
+	GitHub string `mapstructure:"github" json:"github"` // GitHub login
+	Slack  string `mapstructure:"slack" json:"slack"`   // Slack user ID, e.g. U024BE7LH
+	Email  string `mapstructure:"email" json:"email,omitempty"`
+	Source string `mapstructure:"-" json:"source"`
+}
+
+// Validate checks the accounts of an identity
+func (i Identity) Validate() error {
+	if !githubLogin.MatchString(i.GitHub) {
+		return fmt.Errorf("invalid GitHub login %q", i.GitHub)
+	}
+	if !slackUserID.MatchString(i.Slack) {
+		return fmt.Errorf("identity %s: invalid Slack user ID %q", i.GitHub, i.Slack)
+	}
+	return nil
+}
+
+// GitHubUsers finds the email of GitHub users and the other way round. An
+// empty result with no error means no match.
+type GitHubUsers interface {
+	UserEmail(ctx context.Context, login string) (string, error)
+	LoginByEmail(ctx context.Context, email string) (string, error)
+}
+
+// SlackUsers finds the email of Slack users and the other way round. An
+// empty result with no error means no match.
+type SlackUsers interface {
+	UserEmail(ctx context.Context, userID string) (string, error)
+	UserIDByEmail(ctx context.Context, email string) (string, error)
+}
+
+// Directory maps GitHub logins to Slack users and back. Logins are matched
+// case-insensitively. With email matching, accounts that are not mapped are
+// matched by the email both sides report.
+type Directory struct {
+	mu       sync.RWMutex
+	byGitHub map[string]Identity // By lowercase login
+	bySlack  map[string]Identity
+
+	github    GitHubUsers
+	slack     SlackUsers
+	unmatched map[string]time.Time // Accounts email matching found no match for, by key
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewDirectory validates the configured identities and creates a directory
+// of them
+func NewDirectory(identities []Identity) (*Directory, error) {
+	d := &Directory{
+		byGitHub:  make(map[string]Identity),
+		bySlack:   make(map[string]Identity),
+		unmatched: make(map[string]time.Time),
+		logger:    zap.NewNop(),
+		now:       time.Now,
+	}
+	for i, identity := range identities {
+		if err := identity.Validate(); err != nil {
+			return nil, fmt.Errorf("identity %d: %w", i, err)
+		}
+		if _, exists := d.byGitHub[strings.ToLower(identity.GitHub)]; exists {
+			return nil, fmt.Errorf("GitHub login %s is mapped twice", identity.GitHub)
+		}
+		if _, exists := d.bySlack[identity.Slack]; exists {
+			return nil, fmt.Errorf("Slack user %s is mapped twice", identity.Slack)
+		}
+		identity.Source = SourceConfig
+		d.put(identity)
+	}
+	return d, nil
+}
+
+// SetEmailMatching matches accounts that are not mapped by their email.
+// Only GitHub users with a public email can be matched.
+func (d *Directory) SetEmailMatching(github GitHubUsers, slack SlackUsers, logger *zap.Logger) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.github, d.slack, d.logger = github, slack, logger
+}
+
+// SlackUser returns the Slack user ID of a GitHub login
+func (d *Directory) SlackUser(ctx context.Context, login string) (string, bool) {
+	d.mu.RLock()
+	identity, ok := d.byGitHub[strings.ToLower(login)]
+	d.mu.RUnlock()
+	if ok {
+		return identity.Slack, true
+	}
+	identity, ok = d.match(ctx, "github:"+strings.ToLower(login), func(ctx context.Context) (Identity, error) {
+		email, err := d.github.UserEmail(ctx, login)
+		if err != nil || email == "" {
+			return Identity{}, err
+		}
+		userID, err := d.slack.UserIDByEmail(ctx, email)
+		return Identity{GitHub: login, Slack: userID, Email: email}, err
+	})
+	return identity.Slack, ok
+}
+
+// GitHubLogin returns the GitHub login of a Slack user
+func (d *Directory) GitHubLogin(ctx context.Context, userID string) (string, bool) {
+	d.mu.RLock()
+	identity, ok := d.bySlack[userID]
+	d.mu.RUnlock()
+	if ok {
+		return identity.GitHub, true
+	}
+	identity, ok = d.match(ctx, "slack:"+userID, func(ctx context.Context) (Identity, error) {
+		email, err := d.slack.UserEmail(ctx, userID)
+		if err != nil || email == "" {
+			return Identity{}, err
+		}
+		login, err := d.github.LoginByEmail(ctx, email)
+		return Identity{GitHub: login, Slack: userID, Email: email}, err
+	})
+	return identity.GitHub, ok
+}
+
+// match looks up an unmapped account by email and maps it when both sides
+// match. Misses are remembered for a day so every message does not repeat
+// the lookups; lookup errors are not.
+func (d *Directory) match(ctx context.Context, key string, lookup func(context.Context) (Identity, error)) (Identity, bool) {
+	d.mu.RLock()
+	enabled := d.github != nil && d.slack != nil
+	missed, wasMissed := d.unmatched[key]
+	d.mu.RUnlock()
+	if !enabled || (wasMissed && d.now().Sub(missed) < unmatchedRetry) {
+		return Identity{}, false
+	}
+
+	identity, err := lookup(ctx)
+	if err != nil {
+		d.logger.Warn("Failed to match identity by email", zap.String("account", key), zap.Error(err))
+		return Identity{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if identity.Validate() != nil {
+		d.unmatched[key] = d.now()
+		return Identity{}, false
+	}
+	// Explicit mappings win over a match made concurrently
+	if _, exists := d.byGitHub[strings.ToLower(identity.GitHub)]; exists {
+		return Identity{}, false
+	}
+	if _, exists := d.bySlack[identity.Slack]; exists {
+		return Identity{}, false
+	}
+	identity.Source = SourceEmail
+	d.put(identity)
+	d.logger.Info("Matched identity by email",
+		zap.String("github", identity.GitHub),
+		zap.String("slack", identity.Slack))
+	return identity, true
+}
+
+// Put maps an identity, replacing any mapping of either of its accounts
+func (d *Directory) Put(identity Identity) error {
+	if err := identity.Validate(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if previous, ok := d.byGitHub[strings.ToLower(identity.GitHub)]; ok {
+		d.remove(previous)
+	}
+	if previous, ok := d.bySlack[identity.Slack]; ok {
+		d.remove(previous)
+	}
+	d.put(identity)
+	return nil
+}
+
+// Delete removes the mapping of a GitHub login, reporting whether it had one
+func (d *Directory) Delete(login string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	identity, ok := d.byGitHub[strings.ToLower(login)]
+	if ok {
+		d.remove(identity)
+	}
+	return ok
+}
+
+// List returns the mapped identities sorted by GitHub login
+func (d *Directory) List() []Identity {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	identities := make([]Identity, 0, len(d.byGitHub))
+	for _, identity := range d.byGitHub {
+		identities = append(identities, identity)
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		return strings.ToLower(identities[i].GitHub) < strings.ToLower(identities[j].GitHub)
+	})
+	return identities
+}
+
+// put maps an identity. The caller holds the lock.
+func (d *Directory) put(identity Identity) {
+	d.byGitHub[strings.ToLower(identity.GitHub)] = identity
+	d.bySlack[identity.Slack] = identity
+	delete(d.unmatched, "github:"+strings.ToLower(identity.GitHub))
+	delete(d.unmatched, "slack:"+identity.Slack)
+}
+
+// remove unmaps an identity. The caller holds the lock.
+func (d *Directory) remove(identity Identity) {
+	delete(d.byGitHub, strings.ToLower(identity.GitHub))
+	delete(d.bySlack, identity.Slack)
+}
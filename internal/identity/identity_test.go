@@ -0,0 +1,207 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+)
+
+type fakeGitHub struct {
+	emails  map[string]string // By login
+	lookups int
+}
+
+func (f *fakeGitHub) UserEmail(ctx context.Context, login string) (string, error) {
+	f.lookups++
+	if login == "broken" {
+		return "", errors.New("rate limited")
+	}
+	return f.emails[login], nil
+}
+
+func (f *fakeGitHub) LoginByEmail(ctx context.Context, email string) (string, error) {
+	for login, e := range f.emails {
+		if e == email {
+			return login, nil
+		}
+	}
+	return "", nil
+}
+
+type fakeSlack struct {
+	emails map[string]string // By user ID
+}
+
+func (f *fakeSlack) UserEmail(ctx context.Context, userID string) (string, error) {
+	return f.emails[userID], nil
+}
+
+func (f *fakeSlack) UserIDByEmail(ctx context.Context, email string) (string, error) {
+	for userID, e := range f.emails {
+		if e == email {
+			return userID, nil
+		}
+	}
+	return "", nil
+}
+
+type memoryStore struct {
+	identities []Identity
+}
+
+func (s *memoryStore) Identities() []Identity               { return s.identities }
+func (s *memoryStore) SaveIdentities(identities []Identity) { s.identities = identities }
+
+func TestNewDirectory(t *testing.T) {
+	tests := []struct {
+		name       string
+		identities []Identity
+		wantErr    string
+	}{
+		{"valid", []Identity{{GitHub: "octocat", Slack: "U024BE7LH"}, {GitHub: "hubot", Slack: "W012A3CDE"}}, ""},
+		{"invalid login", []Identity{{GitHub: "-octocat", Slack: "U024BE7LH"}}, "invalid GitHub login"},
+		{"invalid Slack user", []Identity{{GitHub: "octocat", Slack: "@octocat"}}, "invalid Slack user ID"},
+		{"login mapped twice", []Identity{{GitHub: "octocat", Slack: "U024BE7LH"}, {GitHub: "Octocat", Slack: "U0G9QF9C6"}}, "mapped twice"},
+		{"Slack user mapped twice", []Identity{{GitHub: "octocat", Slack: "U024BE7LH"}, {GitHub: "hubot", Slack: "U024BE7LH"}}, "mapped twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDirectory(tt.identities)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDirectoryLookups(t *testing.T) {
+	directory, err := NewDirectory([]Identity{{GitHub: "OctoCat", Slack: "U024BE7LH"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if userID, ok := directory.SlackUser(ctx, "octocat"); !ok || userID != "U024BE7LH" {
+		t.Errorf("Expected octocat's Slack user, got %q, %v", userID, ok)
+	}
+	if login, ok := directory.GitHubLogin(ctx, "U024BE7LH"); !ok || login != "OctoCat" {
+		t.Errorf("Expected OctoCat, got %q, %v", login, ok)
+	}
+	if _, ok := directory.SlackUser(ctx, "hubot"); ok {
+		t.Error("Expected no Slack user for an unmapped login without email matching")
+	}
+
+	// Mapping the Slack user to another login replaces the old mapping
+	if err := directory.Put(Identity{GitHub: "hubot", Slack: "U024BE7LH", Source: SourceAPI}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := directory.SlackUser(ctx, "octocat"); ok {
+		t.Error("Expected octocat to be unmapped")
+	}
+	if login, _ := directory.GitHubLogin(ctx, "U024BE7LH"); login != "hubot" {
+		t.Errorf("Expected hubot, got %q", login)
+	}
+	if !directory.Delete("HUBOT") || directory.Delete("hubot") {
+		t.Error("Expected hubot to be deleted once")
+	}
+	if len(directory.List()) != 0 {
+		t.Errorf("Expected no identities, got %v", directory.List())
+	}
+}
+
+func TestEmailMatching(t *testing.T) {
+	directory, _ := NewDirectory(nil)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	directory.now = func() time.Time { return now }
+	github := &fakeGitHub{emails: map[string]string{"octocat": "octo@example.com", "hubot": "hubot@example.com"}}
+	slack := &fakeSlack{emails: map[string]string{"U024BE7LH": "octo@example.com", "U0G9QF9C6": "other@example.com"}}
+	directory.SetEmailMatching(github, slack, zap.NewNop())
+	ctx := context.Background()
+
+	if userID, ok := directory.SlackUser(ctx, "octocat"); !ok || userID != "U024BE7LH" {
+		t.Errorf("Expected octocat to be matched by email, got %q, %v", userID, ok)
+	}
+	if identities := directory.List(); len(identities) != 1 || identities[0].Source != SourceEmail {
+		t.Errorf("Expected the match to be mapped, got %v", identities)
+	}
+	if login, ok := directory.GitHubLogin(ctx, "U0G9QF9C6"); ok {
+		t.Errorf("Expected no login for a Slack email without a GitHub user, got %q", login)
+	}
+
+	// Misses are not looked up again until the retry period passes
+	lookups := github.lookups
+	directory.SlackUser(ctx, "hubot")
+	directory.SlackUser(ctx, "hubot")
+	if github.lookups != lookups+1 {
+		t.Errorf("Expected one lookup for repeated misses, got %d", github.lookups-lookups)
+	}
+	now = now.Add(unmatchedRetry)
+	directory.SlackUser(ctx, "hubot")
+	if github.lookups != lookups+2 {
+		t.Errorf("Expected a lookup after the retry period, got %d", github.lookups-lookups)
+	}
+
+	// Errors are not remembered as misses
+	directory.SlackUser(ctx, "broken")
+	directory.SlackUser(ctx, "broken")
+	if github.lookups != lookups+4 {
+		t.Errorf("Expected failed lookups to be retried, got %d", github.lookups-lookups)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	directory, _ := NewDirectory([]Identity{{GitHub: "octocat", Slack: "U024BE7LH"}})
+	store := &memoryStore{identities: []Identity{{GitHub: "hubot", Slack: "W012A3CDE", Source: SourceAPI}}}
+	handler := NewHandler(directory, store, admin.NewToken("secret"), zap.NewNop())
+	if _, ok := directory.SlackUser(context.Background(), "hubot"); !ok {
+		t.Fatal("Expected stored identities to be mapped")
+	}
+
+	serve := func(serve http.HandlerFunc, method, target, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		serve(rec, req)
+		return rec
+	}
+
+	if rec := serve(handler.ServeList, http.MethodGet, "/api/identities", "", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", rec.Code)
+	}
+	if rec := serve(handler.ServeSave, http.MethodPost, "/api/identities", `{"github":"mona","slack":"nope"}`, "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid Slack user, got %d", rec.Code)
+	}
+	if rec := serve(handler.ServeSave, http.MethodPost, "/api/identities", `{"github":"mona","slack":"U0G9QF9C6","source":"config"}`, "secret"); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	rec := serve(handler.ServeList, http.MethodGet, "/api/identities", "", "secret")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"github":"mona","slack":"U0G9QF9C6","source":"api"`) {
+		t.Errorf("Expected mona in the list, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(store.identities) != 2 {
+		t.Errorf("Expected the API identities to be stored, got %v", store.identities)
+	}
+
+	if rec := serve(handler.ServeDelete, http.MethodDelete, "/api/identities/hubot", "", "secret"); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rec.Code)
+	}
+	if rec := serve(handler.ServeDelete, http.MethodDelete, "/api/identities/hubot", "", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unmapped login, got %d", rec.Code)
+	}
+	if len(store.identities) != 1 || store.identities[0].GitHub != "mona" {
+		t.Errorf("Expected only mona to be stored, got %v", store.identities)
+	}
+}
@@ -0,0 +1,138 @@
+package inspect
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sync"
+	"time"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/breaker"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// QueueSource reports the load of a webhook queue
+type QueueSource interface {
+	QueueStats() github.QueueStats
+}
+
+// PipelineSource reports the issues being processed and recent failures
+type PipelineSource interface {
+	InFlight() []pipeline.InFlight
+	RecentErrors() []pipeline.Event
+}
+
+// BreakerSource reports the states of circuit breakers
+type BreakerSource interface {
+	BreakerStates() []breaker.State
+}
+
+// Pipeline is the live state of one processing stack: the deployment's, or
+// a tenant's in multi-tenant mode
+type Pipeline struct {
+	Name         string              `json:"name"`
+	Queue        github.QueueStats   `json:"queue"`
+	InFlight     []pipeline.InFlight `json:"in_flight"`
+	RecentErrors []pipeline.Event    `json:"recent_errors"`
+}
+
+// Snapshot is the live internal state of the bot
+type Snapshot struct {
+	Time      time.Time       `json:"time"`
+	Uptime    float64         `json:"uptime_seconds"`
+	Pipelines []Pipeline      `json:"pipelines"`
+	Breakers  []breaker.State `json:"breakers"`
+}
+
+type source struct {
+	name      string
+	queue     QueueSource
+	processor PipelineSource
+}
+
+// Handler serves live pipeline state, for finding out why nothing is posted
+// to Slack without reading logs. It requires the admin token.
+type Handler struct {
+	mu         sync.Mutex
+	sources    []source
+	breakers   []BreakerSource
+	adminToken *admin.Token
+	started    time.Time
+}
+
+// NewHandler creates a handler with no pipelines
+func NewHandler(adminToken *admin.Token) *Handler {
+	return &Handler{adminToken: adminToken, started: time.Now()}
+}
+
+// AddPipeline shows the queue and processor of a processing stack
+func (h *Handler) AddPipeline(name string, queue QueueSource, processor PipelineSource) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sources = append(h.sources, source{name: name, queue: queue, processor: processor})
+}
+
+// AddBreakers shows the states of a set of circuit breakers
+func (h *Handler) AddBreakers(breakers BreakerSource) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.breakers = append(h.breakers, breakers)
+}
+
+// Snapshot collects the current state of every pipeline and breaker
+func (h *Handler) Snapshot() Snapshot {
+	h.mu.Lock()
+	sources, breakers := h.sources, h.breakers
+	h.mu.Unlock()
+
+	now := time.Now()
+	snapshot := Snapshot{
+		Time:      now,
+		Uptime:    now.Sub(h.started).Seconds(),
+		Pipelines: make([]Pipeline, 0, len(sources)),
+		Breakers:  []breaker.State{},
+	}
+	for _, source := range sources {
+		snapshot.Pipelines = append(snapshot.Pipelines, Pipeline{
+			Name:         source.name,
+			Queue:        source.queue.QueueStats(),
+			InFlight:     source.processor.InFlight(),
+			RecentErrors: source.processor.RecentErrors(),
+		})
+	}
+	for _, source := range breakers {
+		snapshot.Breakers = append(snapshot.Breakers, source.BreakerStates()...)
+	}
+	return snapshot
+}
+
+// Publish exposes the snapshot as the expvar variable name. Like every
+// expvar, it may only be published once per process.
+func (h *Handler) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return h.Snapshot()
+	}))
+}
+
+// ServePipeline returns the current snapshot
+func (h *Handler) ServePipeline(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(h.Snapshot())
+}
+
+// ServeVars returns every published expvar, including the Go runtime's
+// memory statistics
+func (h *Handler) ServeVars(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	expvar.Handler().ServeHTTP(w, r)
+}
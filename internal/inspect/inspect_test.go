@@ -0,0 +1,99 @@
+package inspect
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/breaker"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+type fakeQueue struct{ stats github.QueueStats }
+
+func (f fakeQueue) QueueStats() github.QueueStats { return f.stats }
+
+type fakePipeline struct {
+	inFlight []pipeline.InFlight
+	errors   []pipeline.Event
+}
+
+func (f fakePipeline) InFlight() []pipeline.InFlight  { return f.inFlight }
+func (f fakePipeline) RecentErrors() []pipeline.Event { return f.errors }
+
+type fakeBreakers []breaker.State
+
+func (f fakeBreakers) BreakerStates() []breaker.State { return f }
+
+func TestServePipeline(t *testing.T) {
+	handler := NewHandler(admin.NewToken("secret"))
+	handler.AddPipeline("default",
+		fakeQueue{github.QueueStats{Limited: true, Workers: 2, Capacity: 10, Processing: 2, Waiting: 3}},
+		fakePipeline{
+			inFlight: []pipeline.InFlight{{Repository: "owner/repo", Number: 7, Stage: "summarize", Elapsed: 42}},
+			errors:   []pipeline.Event{{Repository: "owner/repo", Number: 6, Status: "error", Stage: "notify", Detail: "channel_not_found"}},
+		})
+	handler.AddPipeline("acme", fakeQueue{}, fakePipeline{})
+	handler.AddBreakers(fakeBreakers{{Name: breaker.OpenAI, State: breaker.Open, Since: time.Now()}})
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pipeline", nil)
+	w := httptest.NewRecorder()
+	handler.ServePipeline(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the admin token, got %d", w.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	handler.ServePipeline(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Pipelines) != 2 || snapshot.Pipelines[1].Name != "acme" {
+		t.Fatalf("Expected both pipelines, got %+v", snapshot.Pipelines)
+	}
+	deployment := snapshot.Pipelines[0]
+	if deployment.Queue.Waiting != 3 || len(deployment.InFlight) != 1 || deployment.InFlight[0].Stage != "summarize" {
+		t.Errorf("Expected the queue and in-flight issue, got %+v", deployment)
+	}
+	if len(deployment.RecentErrors) != 1 || deployment.RecentErrors[0].Detail != "channel_not_found" {
+		t.Errorf("Expected the recent error, got %+v", deployment.RecentErrors)
+	}
+	if len(snapshot.Breakers) != 1 || snapshot.Breakers[0].State != breaker.Open {
+		t.Errorf("Expected the open breaker, got %+v", snapshot.Breakers)
+	}
+}
+
+func TestServeVars(t *testing.T) {
+	handler := NewHandler(admin.NewToken("secret"))
+	handler.Publish("inspect_test")
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+	w := httptest.NewRecorder()
+	handler.ServeVars(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the admin token, got %d", w.Code)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	handler.ServeVars(w, req)
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &vars); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vars["inspect_test"]; !ok {
+		t.Error("Expected the published snapshot")
+	}
+	if _, ok := vars["memstats"]; !ok {
+		t.Error("Expected the runtime memory statistics")
+	}
+}
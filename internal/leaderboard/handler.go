@@ -0,0 +1,26 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ServeLeaderboard returns the leaderboard of the month given by the month
+// parameter, e.g. ?month=2026-09, or of the current month so far by default
+func (r *Reporter) ServeLeaderboard(w http.ResponseWriter, req *http.Request) {
+	location := r.config.Location()
+	now := r.now().In(location)
+	month := now
+	if value := req.URL.Query().Get("month"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, location)
+		if err != nil || parsed.After(now) {
+			http.Error(w, "month must be a past or current month, e.g. 2026-09", http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.Build(req.Context(), month))
+}
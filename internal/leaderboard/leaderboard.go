@@ -0,0 +1,261 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/store"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+const (
+	// minFastest is how many issues a triager must have handled to be named
+	// the fastest, so one lucky click does not win it
+	minFastest = 3
+)
+
+// medals are shown next to the top three triagers in Slack
+var medals = []string{":first_place_medal:", ":second_place_medal:", ":third_place_medal:"}
+
+// Config sets where and when the monthly leaderboard is posted
+type Config struct {
+	Channel string // Slack channel for the leaderboard on the 1st of each month, empty to serve it from the API only
+	Size    int    // Triagers named in the Slack post
+	schedule.Schedule
+}
+
+// Validate checks the hour and timezone, and the size of posted
+// leaderboards
+func (c Config) Validate() error {
+	if err := c.Schedule.Validate("leaderboard"); err != nil {
+		return err
+	}
+	if c.Channel != "" && c.Size < 1 {
+		return fmt.Errorf("leaderboard size must be at least 1, got %d", c.Size)
+	}
+	return nil
+}
+
+// IssueLister lists the stored issue records
+type IssueLister interface {
+	ListIssues(query store.Query) ([]store.IssueRecord, string)
+}
+
+// Identities maps Slack users to GitHub accounts and back, so a person's
+// acknowledgements in Slack and actions on GitHub are counted together
+type Identities interface {
+	GitHubLogin(ctx context.Context, userID string) (string, bool)
+	SlackUser(ctx context.Context, login string) (string, bool)
+}
+
+// Poster posts a message to a Slack channel
+type Poster interface {
+	PostMessage(ctx context.Context, channelID, kind, text string) error
+}
+
+// Triager is what one person triaged in the period
+type Triager struct {
+	GitHub       string  `json:"github,omitempty"` // GitHub login, empty for Slack users without a linked account
+	Slack        string  `json:"slack,omitempty"`  // Slack user ID, empty for GitHub users without a linked account
+	Acknowledged int     `json:"acknowledged"`     // Issues acknowledged from team digests
+	Assigned     int     `json:"assigned"`         // Issues first assigned by them on GitHub
+	Closed       int     `json:"closed"`           // Issues closed by them on GitHub
+	Total        int     `json:"total"`
+	MedianHours  float64 `json:"median_hours"` // From an issue being opened to their acknowledging, assigning or closing it
+}
+
+// name is how the triager is shown in Slack: a mention when their Slack
+// account is known
+func (t Triager) name() string {
+	if t.Slack != "" {
+		return "<@" + t.Slack + ">"
+	}
+	return "@" + t.GitHub
+}
+
+// Leaderboard ranks who acknowledged, assigned and closed issues over one
+// month
+type Leaderboard struct {
+	Month    string    `json:"month"` // e.g. 2026-09
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+	Actions  int       `json:"actions"`  // Acknowledgements, assignments and closes in the month
+	Triagers []Triager `json:"triagers"` // Most issues first, then fastest
+
+	// Fastest is the triager with the lowest median time among those who
+	// handled at least minFastest issues, nil if nobody did
+	Fastest *Triager `json:"fastest,omitempty"`
+}
+
+// Reporter builds monthly leaderboards and posts them to Slack
+type Reporter struct {
+	issues     IssueLister
+	identities Identities
+	poster     Poster
+	config     Config
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewReporter creates a reporter. identities may be nil to count Slack and
+// GitHub accounts separately, and poster may be nil when config has no
+// channel.
+func NewReporter(issues IssueLister, identities Identities, poster Poster, config Config, logger *zap.Logger) *Reporter {
+	return &Reporter{
+		issues:     issues,
+		identities: identities,
+		poster:     poster,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run posts the leaderboard of the month before on the 1st of each month
+// until the context is cancelled. A post due before Run started is not made,
+// so a restart does not post the month's leaderboard again.
+func (r *Reporter) Run(ctx context.Context) {
+	if r.config.Channel == "" || r.poster == nil {
+		return
+	}
+	r.config.Run(ctx, r.now, func(due time.Time) {
+		r.PostLeaderboard(ctx, due.AddDate(0, -1, 0))
+	})
+}
+
+// PostLeaderboard posts the leaderboard of the month containing month to the
+// channel
+func (r *Reporter) PostLeaderboard(ctx context.Context, month time.Time) {
+	board := r.Build(ctx, month)
+	if err := r.poster.PostMessage(ctx, r.config.Channel, "triage_leaderboard", FormatLeaderboard(board, r.config.Size)); err != nil {
+		r.logger.Warn("Failed to post triage leaderboard",
+			zap.String("month", board.Month),
+			zap.String("channel", r.config.Channel),
+			zap.Error(err))
+	}
+}
+
+// Build ranks the triage of the month containing month, in the
+// leaderboard's timezone
+func (r *Reporter) Build(ctx context.Context, month time.Time) Leaderboard {
+	location := r.config.Location()
+	month = month.In(location)
+	since := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, location)
+	board := Leaderboard{Month: since.Format("2006-01"), Since: since, Until: since.AddDate(0, 1, 0), Triagers: []Triager{}}
+
+	records, _ := r.issues.ListIssues(store.Query{})
+	Rank(&board, records, r.resolver(ctx))
+	return board
+}
+
+// resolver returns the GitHub login and Slack user of an account given by
+// either, using the identities when there are any
+func (r *Reporter) resolver(ctx context.Context) func(login, userID string) (string, string) {
+	return func(login, userID string) (string, string) {
+		if r.identities == nil {
+			return login, userID
+		}
+		if login == "" {
+			if mapped, ok := r.identities.GitHubLogin(ctx, userID); ok {
+				login = mapped
+			}
+		} else if userID == "" {
+			if mapped, ok := r.identities.SlackUser(ctx, login); ok {
+				userID = mapped
+			}
+		}
+		return login, userID
+	}
+}
+
+// Rank fills in board from the acknowledgements, assignments and closes in
+// records between board.Since and board.Until. resolve links a GitHub login
+// or Slack user to the other account of the same person, if known.
+func Rank(board *Leaderboard, records []store.IssueRecord, resolve func(login, userID string) (string, string)) {
+	triagers := make(map[string]*Triager)
+	elapsed := make(map[string][]time.Duration)
+	credit := func(login, userID string, at, openedAt time.Time, count func(*Triager)) {
+		if at.Before(board.Since) || !at.Before(board.Until) || (login == "" && userID == "") {
+			return
+		}
+		login, userID = resolve(login, userID)
+		key := "slack:" + userID
+		if login != "" {
+			key = strings.ToLower(login)
+		}
+		t, ok := triagers[key]
+		if !ok {
+			t = &Triager{GitHub: login, Slack: userID}
+			triagers[key] = t
+		}
+		count(t)
+		t.Total++
+		board.Actions++
+		if !openedAt.IsZero() && at.After(openedAt) {
+			elapsed[key] = append(elapsed[key], at.Sub(openedAt))
+		}
+	}
+	for _, record := range records {
+		credit("", record.TriagedBy, record.TriagedAt, record.OpenedAt, func(t *Triager) { t.Acknowledged++ })
+		credit(record.AssignedBy, "", record.AssignedAt, record.OpenedAt, func(t *Triager) { t.Assigned++ })
+		credit(record.ClosedBy, "", record.ClosedAt, record.OpenedAt, func(t *Triager) { t.Closed++ })
+	}
+
+	for key, t := range triagers {
+		t.MedianHours = utils.MedianDuration(elapsed[key]).Hours()
+		board.Triagers = append(board.Triagers, *t)
+	}
+	sort.Slice(board.Triagers, func(i, j int) bool {
+		a, b := board.Triagers[i], board.Triagers[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.MedianHours != b.MedianHours {
+			return a.MedianHours < b.MedianHours
+		}
+		return a.GitHub+a.Slack < b.GitHub+b.Slack
+	})
+	for i, t := range board.Triagers {
+		if t.Total >= minFastest && (board.Fastest == nil || t.MedianHours < board.Fastest.MedianHours) {
+			board.Fastest = &board.Triagers[i]
+		}
+	}
+}
+
+// FormatLeaderboard writes a leaderboard as a Slack message naming the top
+// size triagers
+func FormatLeaderboard(board Leaderboard, size int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Triage leaderboard for %s*\n", board.Since.Format("January 2006"))
+	if board.Actions == 0 {
+		b.WriteString("Nobody acknowledged, assigned or closed an issue this month.")
+		return b.String()
+	}
+
+	people := "people"
+	if len(board.Triagers) == 1 {
+		people = "person"
+	}
+	fmt.Fprintf(&b, ":tada: %d issues acknowledged, assigned or closed by %d %s. Thank you all!\n", board.Actions, len(board.Triagers), people)
+	for i, t := range board.Triagers {
+		if i == size {
+			break
+		}
+		rank := fmt.Sprintf("%d.", i+1)
+		if i < len(medals) {
+			rank = medals[i]
+		}
+		fmt.Fprintf(&b, "%s %s: %d (%d acknowledged, %d assigned, %d closed), a median %s after opening\n",
+			rank, t.name(), t.Total, t.Acknowledged, t.Assigned, t.Closed, utils.FormatHours(t.MedianHours))
+	}
+	if board.Fastest != nil {
+		fmt.Fprintf(&b, ":zap: Fastest triager: %s, a median %s after opening", board.Fastest.name(), utils.FormatHours(board.Fastest.MedianHours))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
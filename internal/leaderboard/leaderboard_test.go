@@ -0,0 +1,138 @@
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/store"
+)
+
+var september = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+type fakeIdentities map[string]string // GitHub login by Slack user
+
+func (f fakeIdentities) GitHubLogin(ctx context.Context, userID string) (string, bool) {
+	login, ok := f[userID]
+	return login, ok
+}
+
+func (f fakeIdentities) SlackUser(ctx context.Context, login string) (string, bool) {
+	for userID, l := range f {
+		if strings.EqualFold(l, login) {
+			return userID, true
+		}
+	}
+	return "", false
+}
+
+type fakePoster struct{ posts []string }
+
+func (p *fakePoster) PostMessage(ctx context.Context, channelID, kind, text string) error {
+	p.posts = append(p.posts, channelID+" "+kind+" "+text)
+	return nil
+}
+
+func testReporter() (*Reporter, *fakePoster) {
+	issues := store.NewMemoryStore()
+	day := 24 * time.Hour
+	opened := func(number int, at time.Time) store.IssueRecord {
+		return store.IssueRecord{Repository: "acme/api", Number: number, State: "open", OpenedAt: at}
+	}
+	records := []store.IssueRecord{opened(1, september), opened(2, september.Add(day)), opened(3, september.Add(2*day)),
+		opened(4, september.Add(3*day)), opened(5, september.AddDate(0, 0, -10)), opened(6, september.Add(4*day))}
+	// U-ANA is @ana on GitHub, acknowledging in Slack and closing on GitHub
+	records[0].TriagedAt, records[0].TriagedBy = september.Add(time.Hour), "U-ANA"
+	records[0].ClosedAt, records[0].ClosedBy = september.Add(5*time.Hour), "Ana"
+	records[1].TriagedAt, records[1].TriagedBy = september.Add(day+3*time.Hour), "U-ANA"
+	records[2].AssignedAt, records[2].AssignedBy = september.Add(2*day+30*time.Minute), "bo"
+	records[3].AssignedAt, records[3].AssignedBy = september.Add(3*day+time.Hour), "bo"
+	records[3].ClosedAt, records[3].ClosedBy = september.Add(4*day), "bo"
+	records[4].TriagedAt, records[4].TriagedBy = september.Add(-time.Hour), "U-CY" // In August
+	records[5].TriagedAt, records[5].TriagedBy = september.Add(4*day+2*time.Hour), "U-CY"
+	for i := range records {
+		issues.SaveIssue(&records[i])
+	}
+	poster := &fakePoster{}
+	reporter := NewReporter(issues, fakeIdentities{"U-ANA": "ana"}, poster, Config{Channel: "C-TRIAGE", Size: 2, Schedule: schedule.Schedule{Hour: 9}}, zap.NewNop())
+	return reporter, poster
+}
+
+func TestBuild(t *testing.T) {
+	reporter, _ := testReporter()
+	board := reporter.Build(context.Background(), september.Add(15*24*time.Hour))
+
+	if board.Month != "2026-09" || board.Actions != 7 || len(board.Triagers) != 3 {
+		t.Fatalf("Expected 7 actions by 3 triagers in 2026-09, got %+v", board)
+	}
+	bo := board.Triagers[0]
+	if bo.GitHub != "bo" || bo.Assigned != 2 || bo.Closed != 1 || bo.MedianHours != 1 {
+		t.Errorf("Expected bo ranked first on speed, got %+v", bo)
+	}
+	ana := board.Triagers[1]
+	if ana.GitHub != "ana" || ana.Slack != "U-ANA" || ana.Acknowledged != 2 || ana.Closed != 1 || ana.MedianHours != 3 {
+		t.Errorf("Expected ana's Slack and GitHub triage counted together, got %+v", ana)
+	}
+	if cy := board.Triagers[2]; cy.Slack != "U-CY" || cy.GitHub != "" || cy.Total != 1 {
+		t.Errorf("Expected only cy's September acknowledgement, got %+v", cy)
+	}
+	if board.Fastest == nil || board.Fastest.GitHub != "bo" {
+		t.Errorf("Expected bo to be the fastest, got %+v", board.Fastest)
+	}
+}
+
+func TestPostLeaderboard(t *testing.T) {
+	reporter, poster := testReporter()
+	reporter.PostLeaderboard(context.Background(), september)
+
+	want := "C-TRIAGE triage_leaderboard *Triage leaderboard for September 2026*\n" +
+		":tada: 7 issues acknowledged, assigned or closed by 3 people. Thank you all!\n" +
+		":first_place_medal: @bo: 3 (0 acknowledged, 2 assigned, 1 closed), a median 1.0 hours after opening\n" +
+		":second_place_medal: <@U-ANA>: 3 (2 acknowledged, 0 assigned, 1 closed), a median 3.0 hours after opening\n" +
+		":zap: Fastest triager: @bo, a median 1.0 hours after opening"
+	if len(poster.posts) != 1 || poster.posts[0] != want {
+		t.Errorf("Unexpected post %q", poster.posts)
+	}
+
+	poster.posts = nil
+	reporter.PostLeaderboard(context.Background(), september.AddDate(0, -3, 0))
+	if !strings.HasSuffix(poster.posts[0], "Nobody acknowledged, assigned or closed an issue this month.") {
+		t.Errorf("Expected an empty leaderboard, got %q", poster.posts[0])
+	}
+}
+
+func TestServeLeaderboard(t *testing.T) {
+	reporter, _ := testReporter()
+	reporter.now = func() time.Time { return september.AddDate(0, 1, 3) }
+
+	w := httptest.NewRecorder()
+	reporter.ServeLeaderboard(w, httptest.NewRequest(http.MethodGet, "/api/leaderboard?month=2026-09", nil))
+	var board Leaderboard
+	if err := json.NewDecoder(w.Body).Decode(&board); err != nil {
+		t.Fatalf("Failed to decode leaderboard: %v", err)
+	}
+	if board.Month != "2026-09" || len(board.Triagers) != 3 {
+		t.Errorf("Expected September's leaderboard, got %+v", board)
+	}
+
+	w = httptest.NewRecorder()
+	reporter.ServeLeaderboard(w, httptest.NewRequest(http.MethodGet, "/api/leaderboard", nil))
+	if err := json.NewDecoder(w.Body).Decode(&board); err != nil || board.Month != "2026-10" {
+		t.Errorf("Expected the current month by default, got %+v (%v)", board, err)
+	}
+
+	for _, month := range []string{"2026-12", "September"} {
+		w = httptest.NewRecorder()
+		reporter.ServeLeaderboard(w, httptest.NewRequest(http.MethodGet, "/api/leaderboard?month="+month, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for month %s, got %d", month, w.Code)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMaxTokens bounds the memory kept for one issue
+const DefaultMaxTokens = 1000
+
+// Entry kinds
+const (
+	KindSummary  = "summary"
+	KindQuestion = "question"
+	KindAnswer   = "answer"
+)
+
+// charsPerToken is a rough estimate for English text, which is close enough
+// for budgeting without pulling in a tokenizer
+const charsPerToken = 4
+
+// Entry is one remembered analysis or follow-up exchange
+type Entry struct {
+	Kind string
+	Text string
+	At   time.Time
+}
+
+// Memory is an issue's rolling history of analyses and follow-ups, oldest first
+type Memory []Entry
+
+// EstimateTokens approximates the number of model tokens in text
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Tokens approximates the number of model tokens in the memory
+func (m Memory) Tokens() int {
+	total := 0
+	for _, entry := range m {
+		total += EstimateTokens(entry.Text)
+	}
+	return total
+}
+
+// Append returns a copy of the memory with the entries added, dropping the
+// oldest entries until it fits in maxTokens. An entry that is too large on
+// its own is cut short. A maxTokens of zero or less keeps no memory.
+func (m Memory) Append(maxTokens int, entries ...Entry) Memory {
+	if maxTokens <= 0 {
+		return nil
+	}
+
+	// Copy so records that share the old slice are not modified
+	updated := make(Memory, 0, len(m)+len(entries))
+	updated = append(updated, m...)
+	updated = append(updated, entries...)
+
+	for len(updated) > 1 && updated.Tokens() > maxTokens {
+		updated = updated[1:]
+	}
+	if len(updated) == 1 && updated.Tokens() > maxTokens {
+		runes := []rune(updated[0].Text)
+		updated[0].Text = string(runes[:maxTokens*charsPerToken])
+	}
+	return updated
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"añoñ", 1},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAppendDropsOldest(t *testing.T) {
+	var m Memory
+	m = m.Append(8, Entry{Kind: KindSummary, Text: strings.Repeat("a", 16)})
+	m = m.Append(8, Entry{Kind: KindQuestion, Text: strings.Repeat("b", 8)})
+	if len(m) != 2 || m.Tokens() != 6 {
+		t.Fatalf("Expected both entries to fit, got %+v", m)
+	}
+
+	original := m
+	m = m.Append(8, Entry{Kind: KindAnswer, Text: strings.Repeat("c", 12)})
+	if len(m) != 2 || m[0].Kind != KindQuestion || m[1].Kind != KindAnswer {
+		t.Errorf("Expected the oldest entry to be dropped, got %+v", m)
+	}
+	if len(original) != 2 || original[0].Kind != KindSummary {
+		t.Errorf("Expected the original memory to be unchanged, got %+v", original)
+	}
+}
+
+func TestAppendTruncatesOversizedEntry(t *testing.T) {
+	m := Memory{{Kind: KindSummary, Text: "old"}}.Append(2, Entry{Kind: KindAnswer, Text: strings.Repeat("x", 20)})
+	if len(m) != 1 || m[0].Text != strings.Repeat("x", 8) {
+		t.Errorf("Expected one entry cut to the budget, got %+v", m)
+	}
+
+	if m := m.Append(0, Entry{Text: "a"}); m != nil {
+		t.Errorf("Expected no memory with a zero budget, got %+v", m)
+	}
+}
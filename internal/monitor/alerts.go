@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AlertOptions sets the thresholds of the generated alerting rules
+type AlertOptions struct {
+	ErrorRate       float64       // Share of failed issues, webhooks or OpenAI requests that alerts
+	LatencyTarget   time.Duration // Delivery latency the 95th percentile should stay under
+	QueueSize       int           // Webhook queue capacity; the backlog alert fires at 80% of it
+	CostSpikeFactor float64       // Hourly OpenAI cost, as a multiple of the previous day's hourly average, that alerts
+	MinHourlyCost   float64       // Hourly OpenAI cost in US dollars below which spikes are ignored
+}
+
+// DefaultAlertOptions returns thresholds matching the default configuration
+func DefaultAlertOptions() AlertOptions {
+	return AlertOptions{
+		ErrorRate:       0.05,
+		LatencyTarget:   time.Minute,
+		QueueSize:       100,
+		CostSpikeFactor: 3,
+		MinHourlyCost:   1,
+	}
+}
+
+// RuleFile is a Prometheus rule file
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups" json:"groups"`
+}
+
+// RuleGroup is a named group of rules in a rule file
+type RuleGroup struct {
+	Name  string      `yaml:"name" json:"name"`
+	Rules []AlertRule `yaml:"rules" json:"rules"`
+}
+
+// AlertRule is a Prometheus alerting rule
+type AlertRule struct {
+	Alert       string            `yaml:"alert" json:"alert"`
+	Expr        string            `yaml:"expr" json:"expr"`
+	For         string            `yaml:"for,omitempty" json:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels" json:"labels"`
+	Annotations map[string]string `yaml:"annotations" json:"annotations"`
+}
+
+// AlertRules generates the recommended alerting rules for the bot's own
+// metrics. Ratios are computed per tenant, which is a single empty group
+// outside multi-tenant mode.
+func AlertRules(options AlertOptions) RuleFile {
+	rule := func(name, severity, expr, wait, summary, description string) AlertRule {
+		return AlertRule{
+			Alert:       name,
+			Expr:        expr,
+			For:         wait,
+			Labels:      map[string]string{"severity": severity, "team": "notifyops"},
+			Annotations: map[string]string{"summary": summary, "description": description},
+		}
+	}
+	percent := options.ErrorRate * 100
+
+	return RuleFile{Groups: []RuleGroup{{
+		Name: "notifyops.bot",
+		Rules: []AlertRule{
+			rule("NotifyOpsPipelineErrorRate", "critical",
+				fmt.Sprintf(`sum by (tenant) (rate(issue_pipeline_outcomes_total{outcome="failure"}[15m])) / sum by (tenant) (rate(issue_pipeline_outcomes_total[15m])) > %g`, options.ErrorRate),
+				"15m", "Issues are failing to reach Slack",
+				fmt.Sprintf("More than %g%% of issues failed in the pipeline over the last 15 minutes; see issue_pipeline_outcomes_total by stage.", percent)),
+			rule("NotifyOpsWebhookErrorRate", "warning",
+				fmt.Sprintf(`sum by (tenant) (rate(github_webhooks_total{status="error"}[15m])) / sum by (tenant) (rate(github_webhooks_total[15m])) > %g`, options.ErrorRate),
+				"15m", "GitHub webhooks are failing",
+				fmt.Sprintf("More than %g%% of GitHub webhooks failed over the last 15 minutes.", percent)),
+			rule("NotifyOpsOpenAIErrorRate", "warning",
+				fmt.Sprintf(`sum by (tenant) (rate(openai_requests_total{status="error"}[15m])) / sum by (tenant) (rate(openai_requests_total[15m])) > %g`, options.ErrorRate),
+				"15m", "OpenAI requests are failing",
+				fmt.Sprintf("More than %g%% of OpenAI requests failed over the last 15 minutes; see openai_api_errors_total by error_type.", percent)),
+			rule("NotifyOpsDeliveryLatency", "warning",
+				fmt.Sprintf(`histogram_quantile(0.95, sum by (tenant, le) (rate(issue_delivery_latency_seconds_bucket[15m]))) > %g`, options.LatencyTarget.Seconds()),
+				"15m", "Issues are slow to reach Slack",
+				fmt.Sprintf("The 95th percentile webhook-to-Slack latency is above %s.", options.LatencyTarget)),
+			rule("NotifyOpsWebhookBacklog", "warning",
+				fmt.Sprintf(`github_webhook_queue_depth >= %d and delta(github_webhook_queue_depth[15m]) > 0`, max(1, options.QueueSize*8/10)),
+				"10m", "The webhook queue is filling up",
+				"The webhook queue is over 80% full and still growing; webhooks will soon be rejected or spooled."),
+			rule("NotifyOpsWebhooksDropped", "critical",
+				`sum by (tenant, outcome) (increase(github_webhook_saturation_total[15m])) > 0`,
+				"", "Webhooks arrived while the queue was full",
+				"Webhooks were {{ $labels.outcome }} because the processing queue was full; rejected webhooks must be redelivered from GitHub."),
+			rule("NotifyOpsOpenAICostSpike", "warning",
+				fmt.Sprintf(`sum by (tenant) (increase(openai_estimated_cost_usd_total[1h])) > %g * sum by (tenant) (increase(openai_estimated_cost_usd_total[1d] offset 1h)) / 24 and sum by (tenant) (increase(openai_estimated_cost_usd_total[1h])) > %g`, options.CostSpikeFactor, options.MinHourlyCost),
+				"15m", "OpenAI spend spiked",
+				fmt.Sprintf("The estimated OpenAI cost of the last hour is over %gx the hourly average of the previous day.", options.CostSpikeFactor)),
+		},
+	}}}
+}
+
+// YAML renders the rule file for Prometheus' rule_files
+func (f RuleFile) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CatalogReport is the metric catalog with alerting rules for it
+type CatalogReport struct {
+	Metrics    []MetricInfo `json:"metrics"`
+	AlertRules RuleFile     `json:"alert_rules"`
+}
+
+// NewCatalogReport returns the catalog with rules generated with options
+func NewCatalogReport(options AlertOptions) CatalogReport {
+	return CatalogReport{Metrics: Catalog(), AlertRules: AlertRules(options)}
+}
+
+// CatalogHandler serves the metric catalog and the alerting rules generated
+// with options as JSON, or only the rules as a Prometheus rule file with
+// format=rules
+func CatalogHandler(options AlertOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := NewCatalogReport(options)
+		if r.URL.Query().Get("format") == "rules" {
+			body, err := report.AlertRules.YAML()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(body)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
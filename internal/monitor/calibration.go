@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// calibrationBuckets splits confidence into tenths
+const calibrationBuckets = 10
+
+// CalibrationBucket compares the AI's stated confidence with how often its
+// summaries in that confidence range went uncorrected
+type CalibrationBucket struct {
+	Confidence     string  `json:"confidence"` // Confidence range, e.g. "0.8-0.9"
+	Summaries      int64   `json:"summaries"`
+	Overridden     int64   `json:"overridden"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	Accuracy       float64 `json:"accuracy"` // Share of summaries no human overrode
+	Gap            float64 `json:"gap"`      // Mean confidence minus accuracy; positive when overconfident
+}
+
+// CalibrationReport reports how well summary confidence predicts whether
+// humans later change the priority or category, since startup
+type CalibrationReport struct {
+	Since            time.Time           `json:"since"`
+	Summaries        int64               `json:"summaries"`
+	Overridden       int64               `json:"overridden"`
+	OverridesByField map[string]int64    `json:"overrides_by_field"`
+	MeanConfidence   float64             `json:"mean_confidence"`
+	Accuracy         float64             `json:"accuracy"`
+	CalibrationError float64             `json:"expected_calibration_error"` // Summary-weighted mean of the absolute bucket gaps
+	Buckets          []CalibrationBucket `json:"buckets"`
+}
+
+// calibrationCounts aggregates summaries in one confidence bucket
+type calibrationCounts struct {
+	summaries     int64
+	overridden    int64
+	confidenceSum float64
+}
+
+// CalibrationTracker counts summaries and human overrides by confidence.
+// Counts are kept in memory and reset on restart.
+type CalibrationTracker struct {
+	mu        sync.Mutex
+	since     time.Time
+	buckets   [calibrationBuckets]calibrationCounts
+	overrides map[string]int64
+}
+
+// NewCalibrationTracker creates an empty tracker
+func NewCalibrationTracker() *CalibrationTracker {
+	return &CalibrationTracker{
+		since:     time.Now(),
+		overrides: make(map[string]int64),
+	}
+}
+
+// calibrationBucket returns the bucket index for a confidence
+func calibrationBucket(confidence float64) int {
+	index := int(confidence * calibrationBuckets)
+	return max(0, min(index, calibrationBuckets-1))
+}
+
+// RecordSummary records a summary generated with the given confidence
+func (t *CalibrationTracker) RecordSummary(confidence float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bucket := &t.buckets[calibrationBucket(confidence)]
+	bucket.summaries++
+	bucket.confidenceSum += confidence
+}
+
+// RecordOverride records a human changing the given fields, such as
+// "priority", of a summary generated with the given confidence. Each summary
+// should be reported as overridden once.
+func (t *CalibrationTracker) RecordOverride(confidence float64, fields []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buckets[calibrationBucket(confidence)].overridden++
+	for _, field := range fields {
+		t.overrides[field]++
+	}
+}
+
+// Report returns the calibration of every confidence bucket with summaries
+func (t *CalibrationTracker) Report() CalibrationReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := CalibrationReport{
+		Since:            t.since,
+		OverridesByField: make(map[string]int64, len(t.overrides)),
+		Buckets:          []CalibrationBucket{},
+	}
+	for field, count := range t.overrides {
+		report.OverridesByField[field] = count
+	}
+
+	var confidenceSum, gapSum float64
+	for i, counts := range t.buckets {
+		if counts.summaries == 0 {
+			continue
+		}
+		// Overrides of summaries from before a restart can outnumber the summaries
+		overridden := min(counts.overridden, counts.summaries)
+		bucket := CalibrationBucket{
+			Confidence:     fmt.Sprintf("%.1f-%.1f", float64(i)/calibrationBuckets, float64(i+1)/calibrationBuckets),
+			Summaries:      counts.summaries,
+			Overridden:     overridden,
+			MeanConfidence: counts.confidenceSum / float64(counts.summaries),
+			Accuracy:       1 - float64(overridden)/float64(counts.summaries),
+		}
+		bucket.Gap = bucket.MeanConfidence - bucket.Accuracy
+		report.Buckets = append(report.Buckets, bucket)
+
+		report.Summaries += counts.summaries
+		report.Overridden += overridden
+		confidenceSum += counts.confidenceSum
+		gapSum += float64(counts.summaries) * math.Abs(bucket.Gap)
+	}
+
+	if report.Summaries > 0 {
+		report.MeanConfidence = confidenceSum / float64(report.Summaries)
+		report.Accuracy = 1 - float64(report.Overridden)/float64(report.Summaries)
+		report.CalibrationError = gapSum / float64(report.Summaries)
+	}
+	return report
+}
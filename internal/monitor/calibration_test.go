@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalibrationTrackerReport(t *testing.T) {
+	tracker := NewCalibrationTracker()
+
+	// Four confident summaries, one of them overridden
+	for i := 0; i < 4; i++ {
+		tracker.RecordSummary(0.9)
+	}
+	tracker.RecordOverride(0.9, []string{"priority"})
+
+	// Two unsure summaries, both overridden
+	tracker.RecordSummary(0.3)
+	tracker.RecordSummary(0.3)
+	tracker.RecordOverride(0.3, []string{"priority", "category"})
+	tracker.RecordOverride(0.3, []string{"category"})
+
+	// Confidence 1 belongs to the top bucket
+	tracker.RecordSummary(1)
+
+	report := tracker.Report()
+	if report.Summaries != 7 || report.Overridden != 3 {
+		t.Fatalf("Expected 7 summaries and 3 overrides, got %+v", report)
+	}
+	if report.OverridesByField["priority"] != 2 || report.OverridesByField["category"] != 2 {
+		t.Errorf("Unexpected overrides by field %v", report.OverridesByField)
+	}
+	if len(report.Buckets) != 2 {
+		t.Fatalf("Expected two buckets, got %+v", report.Buckets)
+	}
+
+	low, high := report.Buckets[0], report.Buckets[1]
+	if low.Confidence != "0.3-0.4" || low.Accuracy != 0 || math.Abs(low.Gap-0.3) > 1e-9 {
+		t.Errorf("Unexpected low bucket %+v", low)
+	}
+	if high.Confidence != "0.9-1.0" || high.Summaries != 5 || math.Abs(high.Accuracy-0.8) > 1e-9 || math.Abs(high.MeanConfidence-0.92) > 1e-9 {
+		t.Errorf("Unexpected high bucket %+v", high)
+	}
+
+	// (2*0.3 + 5*0.12) / 7
+	if math.Abs(report.CalibrationError-1.2/7) > 1e-9 {
+		t.Errorf("Expected calibration error %f, got %f", 1.2/7, report.CalibrationError)
+	}
+}
+
+func TestCalibrationTrackerOverridesBeforeRestart(t *testing.T) {
+	tracker := NewCalibrationTracker()
+	tracker.RecordSummary(0.7)
+	tracker.RecordOverride(0.7, []string{"priority"})
+	tracker.RecordOverride(0.7, []string{"priority"})
+	tracker.RecordOverride(0.2, []string{"category"})
+
+	report := tracker.Report()
+	if report.Summaries != 1 || report.Overridden != 1 || report.Accuracy != 0 {
+		t.Errorf("Expected overrides capped at the summaries, got %+v", report)
+	}
+	if len(report.Buckets) != 1 {
+		t.Errorf("Expected only buckets with summaries, got %+v", report.Buckets)
+	}
+}
@@ -0,0 +1,74 @@
+package monitor
+
+// Metric types in the catalog
+const (
+	MetricCounter   = "counter"
+	MetricGauge     = "gauge"
+	MetricHistogram = "histogram"
+)
+
+// MetricInfo describes one exposed metric. In multi-tenant mode every metric
+// but the http_* ones also carries a tenant label.
+type MetricInfo struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Help   string   `json:"help"`
+	Labels []string `json:"labels"`
+}
+
+// catalog lists the metrics registered by NewMetrics, in registration order
+var catalog = []MetricInfo{
+	{Name: "http_requests_total", Type: MetricCounter, Help: "Total number of HTTP requests", Labels: []string{"method", "endpoint", "status"}},
+	{Name: "http_request_duration_seconds", Type: MetricHistogram, Help: "HTTP request duration in seconds", Labels: []string{"method", "endpoint"}},
+	{Name: "http_requests_in_flight", Type: MetricGauge, Help: "Current number of HTTP requests being processed", Labels: []string{"method", "endpoint"}},
+	{Name: "http_rate_limited_total", Type: MetricCounter, Help: "Total number of requests turned away with 429 by the webhook and API rate limits", Labels: []string{"class", "scope"}},
+	{Name: "github_webhooks_total", Type: MetricCounter, Help: "Total number of GitHub webhooks received", Labels: []string{"event_type", "action", "status"}},
+	{Name: "github_webhook_duration_seconds", Type: MetricHistogram, Help: "GitHub webhook processing duration in seconds", Labels: []string{"event_type", "action"}},
+	{Name: "github_api_errors_total", Type: MetricCounter, Help: "Total number of GitHub API errors", Labels: []string{"operation", "error_type"}},
+	{Name: "github_webhook_queue_depth", Type: MetricGauge, Help: "Accepted webhooks waiting for or being processed"},
+	{Name: "github_webhook_saturation_total", Type: MetricCounter, Help: "Total number of webhooks that arrived while the processing queue was full, by outcome", Labels: []string{"outcome"}},
+	{Name: "github_enrichment_stage_duration_seconds", Type: MetricHistogram, Help: "Duration of each issue enrichment stage in seconds, by outcome", Labels: []string{"stage", "status"}},
+	{Name: "support_tickets_total", Type: MetricCounter, Help: "Total number of support ticket webhooks received from Zendesk or Intercom, by outcome", Labels: []string{"source", "status"}},
+	{Name: "openai_requests_total", Type: MetricCounter, Help: "Total number of OpenAI API requests", Labels: []string{"model", "status"}},
+	{Name: "openai_request_duration_seconds", Type: MetricHistogram, Help: "OpenAI API request duration in seconds", Labels: []string{"model"}},
+	{Name: "openai_tokens_used_total", Type: MetricCounter, Help: "Total number of OpenAI tokens used", Labels: []string{"model", "token_type"}},
+	{Name: "openai_api_errors_total", Type: MetricCounter, Help: "Total number of OpenAI API errors", Labels: []string{"error_type"}},
+	{Name: "openai_estimated_cost_usd_total", Type: MetricCounter, Help: "Estimated OpenAI cost in US dollars at list prices, for models with a known price", Labels: []string{"model"}},
+	{Name: "openai_prompt_injections_total", Type: MetricCounter, Help: "Total number of prompt injection attempts found in issue content or in suggested fixes", Labels: []string{"source", "kind"}},
+	{Name: "slack_messages_sent_total", Type: MetricCounter, Help: "Total number of Slack messages sent", Labels: []string{"channel", "message_type", "status"}},
+	{Name: "slack_message_duration_seconds", Type: MetricHistogram, Help: "Slack message sending duration in seconds", Labels: []string{"message_type"}},
+	{Name: "slack_api_errors_total", Type: MetricCounter, Help: "Total number of Slack API errors", Labels: []string{"operation", "error_type"}},
+	{Name: "issues_processed_total", Type: MetricCounter, Help: "Total number of issues processed", Labels: []string{"repository", "issue_type", "status"}},
+	{Name: "issue_processing_duration_seconds", Type: MetricHistogram, Help: "Issue processing duration in seconds", Labels: []string{"issue_type"}},
+	{Name: "issue_summaries_generated_total", Type: MetricCounter, Help: "Total number of issue summaries generated", Labels: []string{"repository", "issue_type"}},
+	{Name: "issues_prefiltered_total", Type: MetricCounter, Help: "Total number of issues noted without AI analysis by the pre-filter", Labels: []string{"repository", "rule"}},
+	{Name: "issues_translated_total", Type: MetricCounter, Help: "Total number of non-English issues translated before analysis", Labels: []string{"repository", "language"}},
+	{Name: "notifications_coalesced_total", Type: MetricCounter, Help: "Total number of issues coalesced into a rolling message after the repository's rate limit was reached", Labels: []string{"repository"}},
+	{Name: "pipeline_stage_timeouts_total", Type: MetricCounter, Help: "Total number of pipeline stages that ran past their timeout", Labels: []string{"stage"}},
+	{Name: "issue_summary_confidence", Type: MetricHistogram, Help: "Confidence the AI reported for generated issue summaries"},
+	{Name: "issue_summary_overrides_total", Type: MetricCounter, Help: "Total number of AI-assigned priorities and categories later changed by a human", Labels: []string{"field"}},
+	{Name: "issue_summary_assignments_total", Type: MetricCounter, Help: "Total number of priorities and categories assigned by generated issue summaries", Labels: []string{"repository", "field", "value"}},
+	{Name: "issue_summary_drift", Type: MetricGauge, Help: "Total variation distance between the recent and baseline distributions of AI-assigned priorities and categories", Labels: []string{"repository", "field"}},
+	{Name: "prompt_canary_summaries_total", Type: MetricCounter, Help: "Total number of summaries generated during prompt style canaries, by version and outcome", Labels: []string{"version", "outcome"}},
+	{Name: "issue_priority_boosts_total", Type: MetricCounter, Help: "Total number of issue priorities raised because of 👍 reactions", Labels: []string{"repository"}},
+	{Name: "issue_notifications_total", Type: MetricCounter, Help: "Total number of issue summaries sent by each notifier, by status", Labels: []string{"notifier", "status"}},
+	{Name: "issue_delivery_latency_seconds", Type: MetricHistogram, Help: "End-to-end latency from webhook receipt to Slack delivery in seconds", Labels: []string{"event_type"}},
+	{Name: "issue_pipeline_outcomes_total", Type: MetricCounter, Help: "Issues handled by the pipeline by outcome and failure stage", Labels: []string{"outcome", "stage"}},
+	{Name: "resource_goroutines", Type: MetricGauge, Help: "Goroutines in the process when resources were last sampled"},
+	{Name: "resource_heap_bytes", Type: MetricGauge, Help: "Bytes of heap objects when resources were last sampled"},
+	{Name: "resource_queued_webhooks", Type: MetricGauge, Help: "Accepted webhooks not yet processed across all webhook queues when resources were last sampled"},
+	{Name: "resource_enrichment_limit", Type: MetricGauge, Help: "Issues that may be enriched at once, lowered under memory pressure"},
+	{Name: "circuit_breaker_state", Type: MetricGauge, Help: "1 for the current state of each dependency's circuit breaker, 0 for the others", Labels: []string{"dependency", "state"}},
+}
+
+// Catalog returns every metric the bot exposes
+func Catalog() []MetricInfo {
+	metrics := make([]MetricInfo, len(catalog))
+	for i, metric := range catalog {
+		if metric.Labels == nil {
+			metric.Labels = []string{}
+		}
+		metrics[i] = metric
+	}
+	return metrics
+}
@@ -0,0 +1,115 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+// collectingRegisterer keeps the collectors registered with it
+type collectingRegisterer struct {
+	collectors []prometheus.Collector
+}
+
+func (r *collectingRegisterer) Register(c prometheus.Collector) error {
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+func (r *collectingRegisterer) MustRegister(cs ...prometheus.Collector) {
+	r.collectors = append(r.collectors, cs...)
+}
+
+func (r *collectingRegisterer) Unregister(prometheus.Collector) bool {
+	return false
+}
+
+func TestCatalogMatchesRegisteredMetrics(t *testing.T) {
+	registerer := &collectingRegisterer{}
+	newMetrics(registerer, Options{})
+	shared := sharedHTTPMetrics(Options{})
+	collectors := append([]prometheus.Collector{shared.requestsTotal, shared.requestDuration, shared.requestsInFlight, shared.rateLimited}, registerer.collectors...)
+
+	registered := make(map[string]bool)
+	for _, collector := range collectors {
+		descs := make(chan *prometheus.Desc, 1)
+		go func() {
+			collector.Describe(descs)
+			close(descs)
+		}()
+		for desc := range descs {
+			registered[desc.String()] = true
+		}
+	}
+
+	catalog := Catalog()
+	if len(catalog) != len(registered) {
+		t.Errorf("Expected %d metrics in the catalog, got %d", len(registered), len(catalog))
+	}
+	for _, metric := range catalog {
+		want := fmt.Sprintf("Desc{fqName: %q, help: %q, constLabels: {}, variableLabels: {%s}}", metric.Name, metric.Help, strings.Join(metric.Labels, ","))
+		if !registered[want] {
+			t.Errorf("Catalog entry %s does not match a registered metric", metric.Name)
+		}
+	}
+}
+
+func TestAlertRules(t *testing.T) {
+	rules := AlertRules(DefaultAlertOptions())
+	body, err := rules.YAML()
+	if err != nil {
+		t.Fatalf("Failed to render rules: %v", err)
+	}
+	var parsed RuleFile
+	if err := yaml.Unmarshal(body, &parsed); err != nil || len(parsed.Groups) != 1 {
+		t.Fatalf("Expected one rule group, got %+v, %v", parsed, err)
+	}
+
+	// Every rule only queries metrics the bot exposes
+	known := make(map[string]bool)
+	for _, metric := range Catalog() {
+		known[metric.Name] = true
+		if metric.Type == MetricHistogram {
+			known[metric.Name+"_bucket"] = true
+		}
+	}
+	metricName := regexp.MustCompile(`[a-z_]+_(total|seconds_bucket|depth)\b`)
+	for _, rule := range parsed.Groups[0].Rules {
+		names := metricName.FindAllString(rule.Expr, -1)
+		if len(names) == 0 {
+			t.Errorf("%s queries no metric: %s", rule.Alert, rule.Expr)
+		}
+		for _, name := range names {
+			if !known[name] {
+				t.Errorf("%s queries unknown metric %s", rule.Alert, name)
+			}
+		}
+		if rule.Labels["severity"] == "" || rule.Annotations["summary"] == "" {
+			t.Errorf("%s needs a severity and summary, got %+v", rule.Alert, rule)
+		}
+	}
+}
+
+func TestCatalogHandler(t *testing.T) {
+	handler := CatalogHandler(DefaultAlertOptions())
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/metrics-catalog", nil))
+	var report CatalogReport
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil || len(report.Metrics) != len(catalog) || len(report.AlertRules.Groups) != 1 {
+		t.Errorf("Expected the catalog and rules, got %+v, %v", report, err)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/metrics-catalog?format=rules", nil))
+	if !strings.HasPrefix(recorder.Body.String(), "groups:\n") || recorder.Header().Get("Content-Type") != "application/yaml" {
+		t.Errorf("Expected a rule file, got %q", recorder.Body.String())
+	}
+}
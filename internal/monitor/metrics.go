@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,17 +15,24 @@ type Metrics struct {
 	httpRequestsTotal    *prometheus.CounterVec
 	httpRequestDuration  *prometheus.HistogramVec
 	httpRequestsInFlight *prometheus.GaugeVec
+	httpRateLimited      *prometheus.CounterVec
 
 	// GitHub webhook metrics
 	githubWebhooksTotal   *prometheus.CounterVec
 	githubWebhookDuration *prometheus.HistogramVec
 	githubAPIErrors       *prometheus.CounterVec
+	webhookQueueDepth     prometheus.Gauge
+	webhookSaturation     *prometheus.CounterVec
+	enrichmentStages      *prometheus.HistogramVec
+	supportTickets        *prometheus.CounterVec
 
 	// OpenAI API metrics
 	openaiRequestsTotal   *prometheus.CounterVec
 	openaiRequestDuration *prometheus.HistogramVec
 	openaiTokensUsed      *prometheus.CounterVec
 	openaiAPIErrors       *prometheus.CounterVec
+	openaiEstimatedCost   *prometheus.CounterVec
+	promptInjections      *prometheus.CounterVec
 
 	// Slack metrics
 	slackMessagesSent    *prometheus.CounterVec
@@ -35,34 +43,128 @@ type Metrics struct {
 	issuesProcessed         *prometheus.CounterVec
 	issueProcessingDuration *prometheus.HistogramVec
 	issueSummariesGenerated *prometheus.CounterVec
+	issuesPrefiltered       *prometheus.CounterVec
+	issuesTranslated        *prometheus.CounterVec
+	notificationsCoalesced  *prometheus.CounterVec
+	stageTimeouts           *prometheus.CounterVec
+	summaryConfidence       prometheus.Histogram
+	summaryOverrides        *prometheus.CounterVec
+	summaryAssignments      *prometheus.CounterVec
+	summaryDrift            *prometheus.GaugeVec
+	promptCanary            *prometheus.CounterVec
+	priorityBoosts          *prometheus.CounterVec
+	notificationsSent       *prometheus.CounterVec
+
+	// SLI metrics
+	issueDeliveryLatency *prometheus.HistogramVec
+	pipelineOutcomes     *prometheus.CounterVec
+
+	// Resource metrics
+	goroutines      prometheus.Gauge
+	heapBytes       prometheus.Gauge
+	queuedWebhooks  prometheus.Gauge
+	enrichmentLimit prometheus.Gauge
+
+	// Circuit breaker metrics
+	breakerState *prometheus.GaugeVec
+
+	slo         *SLOTracker
+	usage       *UsageTracker
+	calibration *CalibrationTracker
+
+	options     Options // Buckets and dropped labels of the metrics above
+	httpOptions Options // Options the shared HTTP metrics were created with
+}
+
+// httpMetrics count requests to the server. They are shared by all tenants,
+// since requests are counted before their tenant is known.
+type httpMetrics struct {
+	requestsTotal    *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	requestsInFlight *prometheus.GaugeVec
+	rateLimited      *prometheus.CounterVec
+	options          Options
+}
+
+var (
+	sharedHTTPOnce sync.Once
+	sharedHTTP     httpMetrics
+)
+
+// sharedHTTPMetrics creates and registers the HTTP metrics on first use, with
+// the options of the first caller
+func sharedHTTPMetrics(options Options) httpMetrics {
+	sharedHTTPOnce.Do(func() {
+		sharedHTTP = httpMetrics{
+			requestsTotal: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "http_requests_total",
+					Help: "Total number of HTTP requests",
+				},
+				options.labelNames("method", "endpoint", "status"),
+			),
+			requestDuration: prometheus.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Name:    "http_request_duration_seconds",
+					Help:    "HTTP request duration in seconds",
+					Buckets: options.buckets(BucketsHTTP),
+				},
+				options.labelNames("method", "endpoint"),
+			),
+			requestsInFlight: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: "http_requests_in_flight",
+					Help: "Current number of HTTP requests being processed",
+				},
+				options.labelNames("method", "endpoint"),
+			),
+			rateLimited: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "http_rate_limited_total",
+					Help: "Total number of requests turned away with 429 by the webhook and API rate limits",
+				},
+				options.labelNames("class", "scope"),
+			),
+			options: options,
+		}
+		prometheus.MustRegister(sharedHTTP.requestsTotal, sharedHTTP.requestDuration, sharedHTTP.requestsInFlight, sharedHTTP.rateLimited)
+	})
+	return sharedHTTP
 }
 
 // NewMetrics creates and registers all Prometheus metrics
 func NewMetrics() *Metrics {
+	return newMetrics(prometheus.DefaultRegisterer, Options{})
+}
+
+// NewMetricsWithOptions creates and registers all Prometheus metrics with
+// custom histogram buckets or dropped labels
+func NewMetricsWithOptions(options Options) *Metrics {
+	return newMetrics(prometheus.DefaultRegisterer, options)
+}
+
+// NewTenantMetrics creates and registers the metrics of one tenant of a
+// multi-tenant deployment, labeled with the tenant's name. A metric cannot be
+// registered both with and without the label, so in multi-tenant mode the
+// default tenant uses tenant metrics too.
+func NewTenantMetrics(tenant string, options Options) *Metrics {
+	return newMetrics(prometheus.WrapRegistererWith(prometheus.Labels{"tenant": tenant}, prometheus.DefaultRegisterer), options)
+}
+
+// NewRegistryMetrics creates metrics registered with registerer rather than
+// the default registry, e.g. for servers started by tests
+func NewRegistryMetrics(registerer prometheus.Registerer) *Metrics {
+	return newMetrics(registerer, Options{})
+}
+
+// newMetrics creates the metrics and registers them with registerer
+func newMetrics(registerer prometheus.Registerer, options Options) *Metrics {
+	shared := sharedHTTPMetrics(options)
 	m := &Metrics{
-		// HTTP request metrics
-		httpRequestsTotal: prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: "http_requests_total",
-				Help: "Total number of HTTP requests",
-			},
-			[]string{"method", "endpoint", "status"},
-		),
-		httpRequestDuration: prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name:    "http_request_duration_seconds",
-				Help:    "HTTP request duration in seconds",
-				Buckets: prometheus.DefBuckets,
-			},
-			[]string{"method", "endpoint"},
-		),
-		httpRequestsInFlight: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Name: "http_requests_in_flight",
-				Help: "Current number of HTTP requests being processed",
-			},
-			[]string{"method", "endpoint"},
-		),
+		httpRequestsTotal:    shared.requestsTotal,
+		httpRequestDuration:  shared.requestDuration,
+		httpRequestsInFlight: shared.requestsInFlight,
+		httpRateLimited:      shared.rateLimited,
 
 		// GitHub webhook metrics
 		githubWebhooksTotal: prometheus.NewCounterVec(
@@ -70,22 +172,51 @@ func NewMetrics() *Metrics {
 				Name: "github_webhooks_total",
 				Help: "Total number of GitHub webhooks received",
 			},
-			[]string{"event_type", "action", "status"},
+			options.labelNames("event_type", "action", "status"),
 		),
 		githubWebhookDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "github_webhook_duration_seconds",
 				Help:    "GitHub webhook processing duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Buckets: options.buckets(BucketsGitHub),
 			},
-			[]string{"event_type", "action"},
+			options.labelNames("event_type", "action"),
 		),
 		githubAPIErrors: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "github_api_errors_total",
 				Help: "Total number of GitHub API errors",
 			},
-			[]string{"operation", "error_type"},
+			options.labelNames("operation", "error_type"),
+		),
+
+		webhookQueueDepth: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "github_webhook_queue_depth",
+				Help: "Accepted webhooks waiting for or being processed",
+			},
+		),
+		webhookSaturation: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "github_webhook_saturation_total",
+				Help: "Total number of webhooks that arrived while the processing queue was full, by outcome",
+			},
+			options.labelNames("outcome"),
+		),
+		enrichmentStages: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "github_enrichment_stage_duration_seconds",
+				Help:    "Duration of each issue enrichment stage in seconds, by outcome",
+				Buckets: options.buckets(BucketsGitHub),
+			},
+			options.labelNames("stage", "status"),
+		),
+		supportTickets: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "support_tickets_total",
+				Help: "Total number of support ticket webhooks received from Zendesk or Intercom, by outcome",
+			},
+			options.labelNames("source", "status"),
 		),
 
 		// OpenAI API metrics
@@ -94,29 +225,43 @@ func NewMetrics() *Metrics {
 				Name: "openai_requests_total",
 				Help: "Total number of OpenAI API requests",
 			},
-			[]string{"model", "status"},
+			options.labelNames("model", "status"),
 		),
 		openaiRequestDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "openai_request_duration_seconds",
 				Help:    "OpenAI API request duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Buckets: options.buckets(BucketsOpenAI),
 			},
-			[]string{"model"},
+			options.labelNames("model"),
 		),
 		openaiTokensUsed: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "openai_tokens_used_total",
 				Help: "Total number of OpenAI tokens used",
 			},
-			[]string{"model", "token_type"},
+			options.labelNames("model", "token_type"),
 		),
 		openaiAPIErrors: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "openai_api_errors_total",
 				Help: "Total number of OpenAI API errors",
 			},
-			[]string{"error_type"},
+			options.labelNames("error_type"),
+		),
+		openaiEstimatedCost: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "openai_estimated_cost_usd_total",
+				Help: "Estimated OpenAI cost in US dollars at list prices, for models with a known price",
+			},
+			options.labelNames("model"),
+		),
+		promptInjections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "openai_prompt_injections_total",
+				Help: "Total number of prompt injection attempts found in issue content or in suggested fixes",
+			},
+			options.labelNames("source", "kind"),
 		),
 
 		// Slack metrics
@@ -125,22 +270,22 @@ func NewMetrics() *Metrics {
 				Name: "slack_messages_sent_total",
 				Help: "Total number of Slack messages sent",
 			},
-			[]string{"channel", "message_type", "status"},
+			options.labelNames("channel", "message_type", "status"),
 		),
 		slackMessageDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "slack_message_duration_seconds",
 				Help:    "Slack message sending duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Buckets: options.buckets(BucketsSlack),
 			},
-			[]string{"message_type"},
+			options.labelNames("message_type"),
 		),
 		slackAPIErrors: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "slack_api_errors_total",
 				Help: "Total number of Slack API errors",
 			},
-			[]string{"operation", "error_type"},
+			options.labelNames("operation", "error_type"),
 		),
 
 		// Business logic metrics
@@ -149,43 +294,196 @@ func NewMetrics() *Metrics {
 				Name: "issues_processed_total",
 				Help: "Total number of issues processed",
 			},
-			[]string{"repository", "issue_type", "status"},
+			options.labelNames("repository", "issue_type", "status"),
 		),
 		issueProcessingDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "issue_processing_duration_seconds",
 				Help:    "Issue processing duration in seconds",
-				Buckets: prometheus.DefBuckets,
+				Buckets: options.buckets(BucketsProcessing),
 			},
-			[]string{"issue_type"},
+			options.labelNames("issue_type"),
 		),
 		issueSummariesGenerated: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "issue_summaries_generated_total",
 				Help: "Total number of issue summaries generated",
 			},
-			[]string{"repository", "issue_type"},
+			options.labelNames("repository", "issue_type"),
+		),
+		issuesPrefiltered: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "issues_prefiltered_total",
+				Help: "Total number of issues noted without AI analysis by the pre-filter",
+			},
+			options.labelNames("repository", "rule"),
+		),
+		issuesTranslated: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "issues_translated_total",
+				Help: "Total number of non-English issues translated before analysis",
+			},
+			options.labelNames("repository", "language"),
+		),
+		notificationsCoalesced: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "notifications_coalesced_total",
+				Help: "Total number of issues coalesced into a rolling message after the repository's rate limit was reached",
+			},
+			options.labelNames("repository"),
+		),
+		stageTimeouts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "pipeline_stage_timeouts_total",
+				Help: "Total number of pipeline stages that ran past their timeout",
+			},
+			options.labelNames("stage"),
+		),
+		summaryConfidence: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "issue_summary_confidence",
+				Help:    "Confidence the AI reported for generated issue summaries",
+				Buckets: prometheus.LinearBuckets(0.1, 0.1, 10),
+			},
+		),
+		summaryOverrides: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "issue_summary_overrides_total",
+				Help: "Total number of AI-assigned priorities and categories later changed by a human",
+			},
+			options.labelNames("field"),
+		),
+		summaryAssignments: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "issue_summary_assignments_total",
+				Help: "Total number of priorities and categories assigned by generated issue summaries",
+			},
+			options.labelNames("repository", "field", "value"),
+		),
+		summaryDrift: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "issue_summary_drift",
+				Help: "Total variation distance between the recent and baseline distributions of AI-assigned priorities and categories",
+			},
+			options.labelNames("repository", "field"),
+		),
+		promptCanary: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "prompt_canary_summaries_total",
+				Help: "Total number of summaries generated during prompt style canaries, by version and outcome",
+			},
+			options.labelNames("version", "outcome"),
+		),
+		priorityBoosts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "issue_priority_boosts_total",
+				Help: "Total number of issue priorities raised because of 👍 reactions",
+			},
+			options.labelNames("repository"),
+		),
+		notificationsSent: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "issue_notifications_total",
+				Help: "Total number of issue summaries sent by each notifier, by status",
+			},
+			options.labelNames("notifier", "status"),
+		),
+
+		// SLI metrics
+		issueDeliveryLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "issue_delivery_latency_seconds",
+				Help:    "End-to-end latency from webhook receipt to Slack delivery in seconds",
+				Buckets: options.buckets(BucketsDelivery),
+			},
+			options.labelNames("event_type"),
+		),
+		pipelineOutcomes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "issue_pipeline_outcomes_total",
+				Help: "Issues handled by the pipeline by outcome and failure stage",
+			},
+			options.labelNames("outcome", "stage"),
+		),
+
+		// Resource metrics
+		goroutines: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "resource_goroutines",
+				Help: "Goroutines in the process when resources were last sampled",
+			},
+		),
+		heapBytes: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "resource_heap_bytes",
+				Help: "Bytes of heap objects when resources were last sampled",
+			},
+		),
+		queuedWebhooks: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "resource_queued_webhooks",
+				Help: "Accepted webhooks not yet processed across all webhook queues when resources were last sampled",
+			},
 		),
+		enrichmentLimit: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "resource_enrichment_limit",
+				Help: "Issues that may be enriched at once, lowered under memory pressure",
+			},
+		),
+		breakerState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "circuit_breaker_state",
+				Help: "1 for the current state of each dependency's circuit breaker, 0 for the others",
+			},
+			options.labelNames("dependency", "state"),
+		),
+		slo:         NewSLOTracker(7*24*time.Hour, 0.99, time.Minute),
+		usage:       NewUsageTracker(DefaultModelPrices),
+		calibration: NewCalibrationTracker(),
+		options:     options,
+		httpOptions: shared.options,
 	}
 
 	// Register all metrics
-	prometheus.MustRegister(
-		m.httpRequestsTotal,
-		m.httpRequestDuration,
-		m.httpRequestsInFlight,
+	registerer.MustRegister(
 		m.githubWebhooksTotal,
 		m.githubWebhookDuration,
 		m.githubAPIErrors,
+		m.webhookQueueDepth,
+		m.webhookSaturation,
+		m.enrichmentStages,
+		m.supportTickets,
 		m.openaiRequestsTotal,
 		m.openaiRequestDuration,
 		m.openaiTokensUsed,
 		m.openaiAPIErrors,
+		m.openaiEstimatedCost,
+		m.promptInjections,
 		m.slackMessagesSent,
 		m.slackMessageDuration,
 		m.slackAPIErrors,
 		m.issuesProcessed,
 		m.issueProcessingDuration,
 		m.issueSummariesGenerated,
+		m.issuesPrefiltered,
+		m.issuesTranslated,
+		m.notificationsCoalesced,
+		m.stageTimeouts,
+		m.summaryConfidence,
+		m.summaryOverrides,
+		m.summaryAssignments,
+		m.summaryDrift,
+		m.promptCanary,
+		m.priorityBoosts,
+		m.notificationsSent,
+		m.issueDeliveryLatency,
+		m.pipelineOutcomes,
+		m.goroutines,
+		m.heapBytes,
+		m.queuedWebhooks,
+		m.enrichmentLimit,
+		m.breakerState,
 	)
 
 	return m
@@ -197,8 +495,8 @@ func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
 		start := time.Now()
 
 		// Track in-flight requests
-		m.httpRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Inc()
-		defer m.httpRequestsInFlight.WithLabelValues(r.Method, r.URL.Path).Dec()
+		m.httpRequestsInFlight.With(m.httpOptions.labels(prometheus.Labels{"method": r.Method, "endpoint": r.URL.Path})).Inc()
+		defer m.httpRequestsInFlight.With(m.httpOptions.labels(prometheus.Labels{"method": r.Method, "endpoint": r.URL.Path})).Dec()
 
 		// Create a response writer that captures the status code
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: 200}
@@ -207,63 +505,244 @@ func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
 
 		// Record metrics
 		duration := time.Since(start).Seconds()
-		m.httpRequestsTotal.WithLabelValues(r.Method, r.URL.Path, string(rune(wrapped.statusCode))).Inc()
-		m.httpRequestDuration.WithLabelValues(r.Method, r.URL.Path).Observe(duration)
+		m.httpRequestsTotal.With(m.httpOptions.labels(prometheus.Labels{"method": r.Method, "endpoint": r.URL.Path, "status": string(rune(wrapped.statusCode))})).Inc()
+		m.httpRequestDuration.With(m.httpOptions.labels(prometheus.Labels{"method": r.Method, "endpoint": r.URL.Path})).Observe(duration)
 	})
 }
 
 // RecordGitHubWebhook records GitHub webhook metrics
 func (m *Metrics) RecordGitHubWebhook(eventType, action, status string, duration time.Duration) {
-	m.githubWebhooksTotal.WithLabelValues(eventType, action, status).Inc()
-	m.githubWebhookDuration.WithLabelValues(eventType, action).Observe(duration.Seconds())
+	m.githubWebhooksTotal.With(m.options.labels(prometheus.Labels{"event_type": eventType, "action": action, "status": status})).Inc()
+	m.githubWebhookDuration.With(m.options.labels(prometheus.Labels{"event_type": eventType, "action": action})).Observe(duration.Seconds())
 }
 
 // RecordGitHubAPIError records GitHub API error metrics
 func (m *Metrics) RecordGitHubAPIError(operation, errorType string) {
-	m.githubAPIErrors.WithLabelValues(operation, errorType).Inc()
+	m.githubAPIErrors.With(m.options.labels(prometheus.Labels{"operation": operation, "error_type": errorType})).Inc()
+}
+
+// RecordWebhookQueueDepth records the number of webhooks waiting for or being processed
+func (m *Metrics) RecordWebhookQueueDepth(depth int) {
+	m.webhookQueueDepth.Set(float64(depth))
+}
+
+// RecordWebhookSaturation records a webhook that arrived while the queue was
+// full, and whether it was rejected or spooled
+func (m *Metrics) RecordWebhookSaturation(outcome string) {
+	m.webhookSaturation.With(m.options.labels(prometheus.Labels{"outcome": outcome})).Inc()
+}
+
+// RecordEnrichmentStage records how long an issue enrichment stage took and
+// whether it succeeded, failed or timed out
+func (m *Metrics) RecordEnrichmentStage(stage, status string, duration time.Duration) {
+	m.enrichmentStages.With(m.options.labels(prometheus.Labels{"stage": stage, "status": status})).Observe(duration.Seconds())
+}
+
+// RecordSupportTicket records a support ticket webhook and whether it was
+// summarized, filed, skipped or rejected
+func (m *Metrics) RecordSupportTicket(source, status string) {
+	m.supportTickets.With(m.options.labels(prometheus.Labels{"source": source, "status": status})).Inc()
 }
 
 // RecordOpenAIRequest records OpenAI API request metrics
 func (m *Metrics) RecordOpenAIRequest(model, status string, duration time.Duration) {
-	m.openaiRequestsTotal.WithLabelValues(model, status).Inc()
-	m.openaiRequestDuration.WithLabelValues(model).Observe(duration.Seconds())
+	m.openaiRequestsTotal.With(m.options.labels(prometheus.Labels{"model": model, "status": status})).Inc()
+	m.openaiRequestDuration.With(m.options.labels(prometheus.Labels{"model": model})).Observe(duration.Seconds())
+	m.usage.RecordRequest(model, status)
 }
 
 // RecordOpenAITokens records OpenAI token usage metrics
 func (m *Metrics) RecordOpenAITokens(model, tokenType string, count int) {
-	m.openaiTokensUsed.WithLabelValues(model, tokenType).Add(float64(count))
+	m.openaiTokensUsed.With(m.options.labels(prometheus.Labels{"model": model, "token_type": tokenType})).Add(float64(count))
+	m.usage.RecordTokens(model, tokenType, count)
+
+	var cost float64
+	var priced bool
+	switch tokenType {
+	case "prompt":
+		cost, priced = EstimateCost(model, count, 0)
+	case "completion":
+		cost, priced = EstimateCost(model, 0, count)
+	}
+	if priced {
+		m.openaiEstimatedCost.With(m.options.labels(prometheus.Labels{"model": model})).Add(cost)
+	}
 }
 
 // RecordOpenAIError records OpenAI API error metrics
 func (m *Metrics) RecordOpenAIError(errorType string) {
-	m.openaiAPIErrors.WithLabelValues(errorType).Inc()
+	m.openaiAPIErrors.With(m.options.labels(prometheus.Labels{"error_type": errorType})).Inc()
+}
+
+// RecordPromptInjection records a prompt injection attempt found in a part of
+// an issue, or signs of one in a suggested fix
+func (m *Metrics) RecordPromptInjection(source, kind string) {
+	m.promptInjections.With(m.options.labels(prometheus.Labels{"source": source, "kind": kind})).Inc()
 }
 
 // RecordSlackMessage records Slack message metrics
 func (m *Metrics) RecordSlackMessage(channel, messageType, status string, duration time.Duration) {
-	m.slackMessagesSent.WithLabelValues(channel, messageType, status).Inc()
-	m.slackMessageDuration.WithLabelValues(messageType).Observe(duration.Seconds())
+	m.slackMessagesSent.With(m.options.labels(prometheus.Labels{"channel": channel, "message_type": messageType, "status": status})).Inc()
+	m.slackMessageDuration.With(m.options.labels(prometheus.Labels{"message_type": messageType})).Observe(duration.Seconds())
 }
 
 // RecordSlackError records Slack API error metrics
 func (m *Metrics) RecordSlackError(operation, errorType string) {
-	m.slackAPIErrors.WithLabelValues(operation, errorType).Inc()
+	m.slackAPIErrors.With(m.options.labels(prometheus.Labels{"operation": operation, "error_type": errorType})).Inc()
 }
 
 // RecordIssueProcessed records issue processing metrics
 func (m *Metrics) RecordIssueProcessed(repository, issueType, status string, duration time.Duration) {
-	m.issuesProcessed.WithLabelValues(repository, issueType, status).Inc()
-	m.issueProcessingDuration.WithLabelValues(issueType).Observe(duration.Seconds())
+	m.issuesProcessed.With(m.options.labels(prometheus.Labels{"repository": repository, "issue_type": issueType, "status": status})).Inc()
+	m.issueProcessingDuration.With(m.options.labels(prometheus.Labels{"issue_type": issueType})).Observe(duration.Seconds())
 }
 
 // RecordIssueSummaryGenerated records issue summary generation metrics
 func (m *Metrics) RecordIssueSummaryGenerated(repository, issueType string) {
-	m.issueSummariesGenerated.WithLabelValues(repository, issueType).Inc()
+	m.issueSummariesGenerated.With(m.options.labels(prometheus.Labels{"repository": repository, "issue_type": issueType})).Inc()
+}
+
+// RecordIssuePrefiltered records an issue the pre-filter kept away from the AI
+func (m *Metrics) RecordIssuePrefiltered(repository, rule string) {
+	m.issuesPrefiltered.With(m.options.labels(prometheus.Labels{"repository": repository, "rule": rule})).Inc()
+}
+
+// RecordIssueTranslated records an issue translated from the detected language
+func (m *Metrics) RecordIssueTranslated(repository, language string) {
+	m.issuesTranslated.With(m.options.labels(prometheus.Labels{"repository": repository, "language": language})).Inc()
+}
+
+// RecordNotificationCoalesced records an issue folded into a rolling message
+func (m *Metrics) RecordNotificationCoalesced(repository string) {
+	m.notificationsCoalesced.With(m.options.labels(prometheus.Labels{"repository": repository})).Inc()
 }
 
-// Handler returns the Prometheus metrics handler
+// RecordStageTimeout records a pipeline stage that ran past its timeout
+func (m *Metrics) RecordStageTimeout(stage string) {
+	m.stageTimeouts.With(m.options.labels(prometheus.Labels{"stage": stage})).Inc()
+}
+
+// RecordSummaryConfidence records the confidence of a generated summary
+func (m *Metrics) RecordSummaryConfidence(confidence float64) {
+	m.summaryConfidence.Observe(confidence)
+	m.calibration.RecordSummary(confidence)
+}
+
+// RecordSummaryOverride records a human changing the fields of a summary
+// generated with the given confidence
+func (m *Metrics) RecordSummaryOverride(confidence float64, fields []string) {
+	for _, field := range fields {
+		m.summaryOverrides.With(m.options.labels(prometheus.Labels{"field": field})).Inc()
+	}
+	m.calibration.RecordOverride(confidence, fields)
+}
+
+// RecordSummaryAssignment records the priority and category a generated
+// summary assigned to an issue
+func (m *Metrics) RecordSummaryAssignment(repository, priority, category string) {
+	m.summaryAssignments.With(m.options.labels(prometheus.Labels{"repository": repository, "field": "priority", "value": priority})).Inc()
+	m.summaryAssignments.With(m.options.labels(prometheus.Labels{"repository": repository, "field": "category", "value": category})).Inc()
+}
+
+// RecordSummaryDrift records how far a repository's recent priorities or
+// categories have drifted from its baseline
+func (m *Metrics) RecordSummaryDrift(repository, field string, distance float64) {
+	m.summaryDrift.With(m.options.labels(prometheus.Labels{"repository": repository, "field": field})).Set(distance)
+}
+
+// RecordPromptCanary records the outcome of a summary generated during a
+// prompt style canary: parsed, parse_error or overridden
+func (m *Metrics) RecordPromptCanary(version, outcome string) {
+	m.promptCanary.With(m.options.labels(prometheus.Labels{"version": version, "outcome": outcome})).Inc()
+}
+
+// RecordResourceUsage records a sample of the process's resources and the
+// enrichment limit set from it
+func (m *Metrics) RecordResourceUsage(goroutines int, heapBytes uint64, queued, enrichmentLimit int) {
+	m.goroutines.Set(float64(goroutines))
+	m.heapBytes.Set(float64(heapBytes))
+	m.queuedWebhooks.Set(float64(queued))
+	m.enrichmentLimit.Set(float64(enrichmentLimit))
+}
+
+// RecordRateLimited records a request turned away by a rate limit: the
+// client's own, or the one shared by every client
+func (m *Metrics) RecordRateLimited(class, scope string) {
+	m.httpRateLimited.With(m.httpOptions.labels(prometheus.Labels{"class": class, "scope": scope})).Inc()
+}
+
+// breakerStates are the states a circuit breaker can be in
+var breakerStates = []string{"closed", "half_open", "open"}
+
+// RecordBreakerState records the state a dependency's circuit breaker moved to
+func (m *Metrics) RecordBreakerState(dependency, state string) {
+	for _, s := range breakerStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		m.breakerState.With(m.options.labels(prometheus.Labels{"dependency": dependency, "state": s})).Set(value)
+	}
+}
+
+// RecordPriorityBoost records an issue's priority raised by reactions
+func (m *Metrics) RecordPriorityBoost(repository string) {
+	m.priorityBoosts.With(m.options.labels(prometheus.Labels{"repository": repository})).Inc()
+}
+
+// RecordNotification records an issue summary sent by a notifier
+func (m *Metrics) RecordNotification(notifier, status string) {
+	m.notificationsSent.With(m.options.labels(prometheus.Labels{"notifier": notifier, "status": status})).Inc()
+}
+
+// RecordPipelineSuccess records an issue delivered to Slack. The delivery ID
+// is attached as an exemplar so slow deliveries can be traced to a webhook.
+func (m *Metrics) RecordPipelineSuccess(eventType, deliveryID string, receivedAt time.Time) {
+	latency := time.Since(receivedAt)
+	observer := m.issueDeliveryLatency.With(m.options.labels(prometheus.Labels{"event_type": eventType}))
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && deliveryID != "" {
+		exemplarObserver.ObserveWithExemplar(latency.Seconds(), prometheus.Labels{"delivery_id": deliveryID})
+	} else {
+		observer.Observe(latency.Seconds())
+	}
+
+	m.pipelineOutcomes.With(m.options.labels(prometheus.Labels{"outcome": "success", "stage": ""})).Inc()
+	m.slo.RecordSuccess(latency)
+}
+
+// RecordPipelineFailure records an issue that failed at the given stage
+func (m *Metrics) RecordPipelineFailure(stage string) {
+	m.pipelineOutcomes.With(m.options.labels(prometheus.Labels{"outcome": "failure", "stage": stage})).Inc()
+	m.slo.RecordFailure(stage)
+}
+
+// SetSLOTargets sets the objectives reported by SLOSummary
+func (m *Metrics) SetSLOTargets(availabilityTarget float64, latencyTarget time.Duration) {
+	m.slo.SetTargets(availabilityTarget, latencyTarget)
+}
+
+// SLOSummary returns availability over the last 7 days
+func (m *Metrics) SLOSummary() SLOSummary {
+	return m.slo.Summary()
+}
+
+// UsageSummary returns OpenAI usage and estimated cost since startup
+func (m *Metrics) UsageSummary() UsageSummary {
+	return m.usage.Summary()
+}
+
+// CalibrationReport compares summary confidence with human overrides since startup
+func (m *Metrics) CalibrationReport() CalibrationReport {
+	return m.calibration.Report()
+}
+
+// Handler returns the Prometheus metrics handler. OpenMetrics is enabled so
+// exemplars are exposed to scrapers that request it.
 func (m *Metrics) Handler() http.Handler {
-	return promhttp.Handler()
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
 }
 
 // responseWriter wraps http.ResponseWriter to capture status code
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestTenantMetrics(t *testing.T) {
+	// Tenants register the same metrics side by side, told apart by label
+	teamA := NewTenantMetrics("team-a", Options{})
+	teamB := NewTenantMetrics("team-b", Options{})
+	teamA.RecordGitHubWebhook("issues", "opened", "success", time.Second)
+	teamA.RecordGitHubWebhook("issues", "opened", "success", time.Second)
+	teamB.RecordGitHubWebhook("issues", "opened", "success", time.Second)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	counts := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "github_webhooks_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "tenant" {
+					counts[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	if counts["team-a"] != 2 || counts["team-b"] != 1 {
+		t.Errorf("Expected 2 webhooks for team-a and 1 for team-b, got %v", counts)
+	}
+}
+
+func TestMetricsOptions(t *testing.T) {
+	options := Options{
+		Buckets:    map[string][]float64{BucketsOpenAI: {5, 30, 120}},
+		DropLabels: []string{"repository"},
+	}
+	if err := options.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	registry := prometheus.NewRegistry()
+	m := newMetrics(registry, options)
+	m.RecordOpenAIRequest("gpt-4", "success", 10*time.Second)
+	m.RecordIssueProcessed("acme/api", "bug", "success", time.Second)
+	m.RecordIssueProcessed("acme/web", "bug", "success", time.Second)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		switch family.GetName() {
+		case "openai_request_duration_seconds":
+			buckets := family.GetMetric()[0].GetHistogram().GetBucket()
+			if len(buckets) != 3 || buckets[2].GetUpperBound() != 120 || buckets[1].GetCumulativeCount() != 1 {
+				t.Errorf("Expected the custom buckets, got %v", buckets)
+			}
+		case "issues_processed_total":
+			metrics := family.GetMetric()
+			if len(metrics) != 1 || metrics[0].GetCounter().GetValue() != 2 {
+				t.Fatalf("Expected both repositories counted together, got %v", metrics)
+			}
+			for _, label := range metrics[0].GetLabel() {
+				if label.GetName() == "repository" {
+					t.Error("Expected the repository label dropped")
+				}
+			}
+		}
+	}
+}
+
+func TestMetricsOptionsValidate(t *testing.T) {
+	invalid := []Options{
+		{Buckets: map[string][]float64{"database": {1}}},
+		{Buckets: map[string][]float64{BucketsHTTP: {0.1, 0.05}}},
+		{Buckets: map[string][]float64{BucketsSlack: {}}},
+		{DropLabels: []string{"color"}},
+	}
+	for _, options := range invalid {
+		if err := options.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", options)
+		}
+	}
+}
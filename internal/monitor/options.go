@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Histogram groups whose buckets can be overridden
+const (
+	BucketsHTTP       = "http"       // http_request_duration_seconds
+	BucketsGitHub     = "github"     // github_webhook_duration_seconds, github_enrichment_stage_duration_seconds
+	BucketsOpenAI     = "openai"     // openai_request_duration_seconds
+	BucketsSlack      = "slack"      // slack_message_duration_seconds
+	BucketsProcessing = "processing" // issue_processing_duration_seconds
+	BucketsDelivery   = "delivery"   // issue_delivery_latency_seconds
+)
+
+// DefaultBuckets are the histogram buckets of each group in seconds, sized
+// for the latencies seen in practice: milliseconds for the server's own
+// endpoints, up to two minutes for OpenAI completions
+var DefaultBuckets = map[string][]float64{
+	BucketsHTTP:       {0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	BucketsGitHub:     {0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	BucketsOpenAI:     {1, 2, 5, 10, 15, 20, 30, 45, 60, 90, 120},
+	BucketsSlack:      {0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+	BucketsProcessing: {0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	BucketsDelivery:   {1, 2.5, 5, 10, 20, 30, 60, 120, 300, 600},
+}
+
+// Options tune the metrics of a deployment
+type Options struct {
+	Buckets    map[string][]float64 // Histogram buckets by group, replacing DefaultBuckets
+	DropLabels []string             // Labels left off every metric, e.g. repository in large organizations
+}
+
+// Validate checks that bucket overrides name known groups and increase, and
+// that dropped labels are used by some metric
+func (o Options) Validate() error {
+	groups := make([]string, 0, len(o.Buckets))
+	for group := range o.Buckets {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		if _, ok := DefaultBuckets[group]; !ok {
+			return fmt.Errorf("unknown histogram group %q", group)
+		}
+		buckets := o.Buckets[group]
+		if len(buckets) == 0 {
+			return fmt.Errorf("histogram group %s has no buckets", group)
+		}
+		for i, bucket := range buckets {
+			if bucket <= 0 || (i > 0 && bucket <= buckets[i-1]) {
+				return fmt.Errorf("histogram group %s: buckets must be positive and increasing", group)
+			}
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, metric := range catalog {
+		for _, label := range metric.Labels {
+			known[label] = true
+		}
+	}
+	for _, label := range o.DropLabels {
+		if !known[label] {
+			return fmt.Errorf("no metric has a %q label to drop", label)
+		}
+	}
+	return nil
+}
+
+// buckets returns the buckets of a histogram group
+func (o Options) buckets(group string) []float64 {
+	if buckets, ok := o.Buckets[group]; ok {
+		return buckets
+	}
+	return DefaultBuckets[group]
+}
+
+// dropped reports whether a label is left off
+func (o Options) dropped(label string) bool {
+	for _, drop := range o.DropLabels {
+		if drop == label {
+			return true
+		}
+	}
+	return false
+}
+
+// labelNames returns the names of the labels that are kept
+func (o Options) labelNames(names ...string) []string {
+	kept := make([]string, 0, len(names))
+	for _, name := range names {
+		if !o.dropped(name) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// labels removes dropped labels from values
+func (o Options) labels(values prometheus.Labels) prometheus.Labels {
+	for _, drop := range o.DropLabels {
+		delete(values, drop)
+	}
+	return values
+}
@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// Pipeline stages an issue can fail at after the webhook has been accepted,
+// used as the stage label of the SLI and timeout counters
+const (
+	StageEnrich    = "enrich"    // Fetching comments and commits from GitHub
+	StageSummarize = "summarize" // AI summary generation
+	StageNotify    = "notify"    // Slack delivery
+)
+
+// SLOSummary reports availability and latency compliance over the SLO window
+type SLOSummary struct {
+	Window               string           `json:"window"`
+	Since                time.Time        `json:"since"`
+	Total                int64            `json:"total"`
+	Succeeded            int64            `json:"succeeded"`
+	Failed               int64            `json:"failed"`
+	FailuresByStage      map[string]int64 `json:"failures_by_stage"`
+	Availability         float64          `json:"availability"`
+	AvailabilityTarget   float64          `json:"availability_target"`
+	ErrorBudgetRemaining float64          `json:"error_budget_remaining"`
+	LatencyTarget        string           `json:"latency_target"`
+	WithinLatencyTarget  float64          `json:"within_latency_target"`
+}
+
+// sloBucket aggregates outcomes for one hour
+type sloBucket struct {
+	start     time.Time
+	succeeded int64
+	fast      int64 // Successes delivered within the latency target
+	failures  map[string]int64
+}
+
+// SLOTracker keeps hourly outcome counts for the SLO window so availability
+// can be reported without querying Prometheus. Counts are kept in memory and
+// reset on restart.
+type SLOTracker struct {
+	mu                 sync.Mutex
+	window             time.Duration
+	availabilityTarget float64
+	latencyTarget      time.Duration
+	buckets            []*sloBucket
+	now                func() time.Time
+}
+
+// NewSLOTracker creates a tracker for the given window and targets
+func NewSLOTracker(window time.Duration, availabilityTarget float64, latencyTarget time.Duration) *SLOTracker {
+	return &SLOTracker{
+		window:             window,
+		availabilityTarget: availabilityTarget,
+		latencyTarget:      latencyTarget,
+		now:                time.Now,
+	}
+}
+
+// SetTargets updates the availability and latency objectives
+func (t *SLOTracker) SetTargets(availabilityTarget float64, latencyTarget time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.availabilityTarget = availabilityTarget
+	t.latencyTarget = latencyTarget
+}
+
+// RecordSuccess records an issue delivered to Slack after latency
+func (t *SLOTracker) RecordSuccess(latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bucket := t.currentBucket()
+	bucket.succeeded++
+	if t.latencyTarget <= 0 || latency <= t.latencyTarget {
+		bucket.fast++
+	}
+}
+
+// RecordFailure records an issue that failed at stage
+func (t *SLOTracker) RecordFailure(stage string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.currentBucket().failures[stage]++
+}
+
+// Summary aggregates the buckets inside the window
+func (t *SLOTracker) Summary() SLOSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune()
+
+	summary := SLOSummary{
+		Window:             t.window.String(),
+		Since:              t.now().Add(-t.window).UTC(),
+		FailuresByStage:    make(map[string]int64),
+		AvailabilityTarget: t.availabilityTarget,
+		LatencyTarget:      t.latencyTarget.String(),
+	}
+
+	var fast int64
+	for _, bucket := range t.buckets {
+		summary.Succeeded += bucket.succeeded
+		fast += bucket.fast
+		for stage, count := range bucket.failures {
+			summary.FailuresByStage[stage] += count
+			summary.Failed += count
+		}
+	}
+	summary.Total = summary.Succeeded + summary.Failed
+
+	summary.Availability = 1
+	summary.WithinLatencyTarget = 1
+	if summary.Total > 0 {
+		summary.Availability = float64(summary.Succeeded) / float64(summary.Total)
+	}
+	if summary.Succeeded > 0 {
+		summary.WithinLatencyTarget = float64(fast) / float64(summary.Succeeded)
+	}
+
+	// Share of the allowed failures that has not been used yet
+	summary.ErrorBudgetRemaining = 1
+	if allowed := 1 - t.availabilityTarget; allowed > 0 {
+		summary.ErrorBudgetRemaining = 1 - (1-summary.Availability)/allowed
+	}
+
+	return summary
+}
+
+// currentBucket returns the bucket for the current hour. Callers must hold mu.
+func (t *SLOTracker) currentBucket() *sloBucket {
+	start := t.now().Truncate(time.Hour)
+	if n := len(t.buckets); n > 0 && t.buckets[n-1].start.Equal(start) {
+		return t.buckets[n-1]
+	}
+
+	t.prune()
+	bucket := &sloBucket{start: start, failures: make(map[string]int64)}
+	t.buckets = append(t.buckets, bucket)
+	return bucket
+}
+
+// prune drops buckets that have left the window. Callers must hold mu.
+func (t *SLOTracker) prune() {
+	cutoff := t.now().Add(-t.window)
+	i := 0
+	for i < len(t.buckets) && !t.buckets[i].start.Add(time.Hour).After(cutoff) {
+		i++
+	}
+	t.buckets = t.buckets[i:]
+}
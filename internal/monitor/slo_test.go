@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLOTrackerSummary(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 30, 0, 0, time.UTC)
+	tracker := NewSLOTracker(7*24*time.Hour, 0.9, time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < 8; i++ {
+		tracker.RecordSuccess(10 * time.Second)
+	}
+	tracker.RecordSuccess(2 * time.Minute)
+	tracker.RecordFailure(StageNotify)
+
+	summary := tracker.Summary()
+	if summary.Total != 10 || summary.Succeeded != 9 || summary.Failed != 1 {
+		t.Fatalf("Unexpected counts: %+v", summary)
+	}
+	if summary.FailuresByStage[StageNotify] != 1 {
+		t.Errorf("Expected one notify failure, got %v", summary.FailuresByStage)
+	}
+	if summary.Availability != 0.9 {
+		t.Errorf("Expected availability 0.9, got %v", summary.Availability)
+	}
+	if summary.ErrorBudgetRemaining > 1e-9 || summary.ErrorBudgetRemaining < -1e-9 {
+		t.Errorf("Expected error budget to be exhausted, got %v", summary.ErrorBudgetRemaining)
+	}
+	if summary.WithinLatencyTarget != 8.0/9.0 {
+		t.Errorf("Expected 8/9 within latency target, got %v", summary.WithinLatencyTarget)
+	}
+}
+
+func TestSLOTrackerDropsOldBuckets(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewSLOTracker(7*24*time.Hour, 0.99, time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	tracker.RecordFailure(StageSummarize)
+
+	now = now.Add(8 * 24 * time.Hour)
+	tracker.RecordSuccess(time.Second)
+
+	summary := tracker.Summary()
+	if summary.Total != 1 || summary.Failed != 0 {
+		t.Errorf("Expected old failure to leave the window, got %+v", summary)
+	}
+	if summary.Availability != 1 {
+		t.Errorf("Expected full availability, got %v", summary.Availability)
+	}
+}
@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ModelPrice is the list price of a model in US dollars per 1K tokens
+type ModelPrice struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// DefaultModelPrices holds list prices for common OpenAI models. Dated model
+// versions are priced by the longest matching prefix.
+var DefaultModelPrices = map[string]ModelPrice{
+	"gpt-4.1-nano":  {Prompt: 0.0001, Completion: 0.0004},
+	"gpt-4.1-mini":  {Prompt: 0.0004, Completion: 0.0016},
+	"gpt-4.1":       {Prompt: 0.002, Completion: 0.008},
+	"gpt-4o-mini":   {Prompt: 0.00015, Completion: 0.0006},
+	"gpt-4o":        {Prompt: 0.0025, Completion: 0.01},
+	"gpt-4-turbo":   {Prompt: 0.01, Completion: 0.03},
+	"gpt-4-1106":    {Prompt: 0.01, Completion: 0.03},
+	"gpt-4-0125":    {Prompt: 0.01, Completion: 0.03},
+	"gpt-4-32k":     {Prompt: 0.06, Completion: 0.12},
+	"gpt-4":         {Prompt: 0.03, Completion: 0.06},
+	"gpt-3.5-turbo": {Prompt: 0.0005, Completion: 0.0015},
+}
+
+// ModelUsage reports the requests and tokens used by one model
+type ModelUsage struct {
+	Model            string  `json:"model"`
+	Requests         int64   `json:"requests"`
+	Errors           int64   `json:"errors"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost_usd"`
+	Priced           bool    `json:"priced"` // False when the model has no known price
+}
+
+// UsageSummary reports OpenAI usage and its estimated cost since startup
+type UsageSummary struct {
+	Since         time.Time    `json:"since"`
+	Models        []ModelUsage `json:"models"`
+	EstimatedCost float64      `json:"estimated_cost_usd"`
+}
+
+// UsageTracker totals OpenAI requests and tokens per model so cost can be
+// estimated without querying Prometheus. Totals are kept in memory and
+// reset on restart.
+type UsageTracker struct {
+	mu     sync.Mutex
+	since  time.Time
+	prices map[string]ModelPrice
+	models map[string]*ModelUsage
+}
+
+// NewUsageTracker creates a tracker that prices tokens with prices
+func NewUsageTracker(prices map[string]ModelPrice) *UsageTracker {
+	return &UsageTracker{
+		since:  time.Now(),
+		prices: prices,
+		models: make(map[string]*ModelUsage),
+	}
+}
+
+// RecordRequest counts a request and whether it failed
+func (t *UsageTracker) RecordRequest(model, status string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := t.model(model)
+	usage.Requests++
+	if status == "error" {
+		usage.Errors++
+	}
+}
+
+// RecordTokens adds prompt or completion tokens. Totals are ignored, as
+// they are the sum of the other two.
+func (t *UsageTracker) RecordTokens(model, tokenType string, count int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch tokenType {
+	case "prompt":
+		t.model(model).PromptTokens += int64(count)
+	case "completion":
+		t.model(model).CompletionTokens += int64(count)
+	}
+}
+
+// Summary returns the usage of each model, most expensive first
+func (t *UsageTracker) Summary() UsageSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	summary := UsageSummary{Since: t.since, Models: make([]ModelUsage, 0, len(t.models))}
+	for _, usage := range t.models {
+		priced := *usage
+		if price, ok := t.price(usage.Model); ok {
+			priced.Priced = true
+			priced.EstimatedCost = float64(usage.PromptTokens)/1000*price.Prompt +
+				float64(usage.CompletionTokens)/1000*price.Completion
+		}
+		summary.Models = append(summary.Models, priced)
+		summary.EstimatedCost += priced.EstimatedCost
+	}
+	sort.Slice(summary.Models, func(i, j int) bool {
+		if summary.Models[i].EstimatedCost != summary.Models[j].EstimatedCost {
+			return summary.Models[i].EstimatedCost > summary.Models[j].EstimatedCost
+		}
+		return summary.Models[i].Model < summary.Models[j].Model
+	})
+	return summary
+}
+
+func (t *UsageTracker) model(model string) *ModelUsage {
+	usage, ok := t.models[model]
+	if !ok {
+		usage = &ModelUsage{Model: model}
+		t.models[model] = usage
+	}
+	return usage
+}
+
+// price finds the price of the longest model prefix
+func (t *UsageTracker) price(model string) (ModelPrice, bool) {
+	return priceOf(t.prices, model)
+}
+
+// priceOf finds the price of the longest model prefix in prices
+func priceOf(prices map[string]ModelPrice, model string) (ModelPrice, bool) {
+	var best string
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return prices[best], true
+}
+
+// PriceOf returns the default list price of a model
+func PriceOf(model string) (ModelPrice, bool) {
+	return priceOf(DefaultModelPrices, model)
+}
+
+// EstimateCost prices the tokens of one request at the default list prices.
+// It returns false for models without a known price.
+func EstimateCost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := priceOf(DefaultModelPrices, model)
+	if !ok {
+		return 0, false
+	}
+	return float64(promptTokens)/1000*price.Prompt + float64(completionTokens)/1000*price.Completion, true
+}
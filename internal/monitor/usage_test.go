@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUsageTrackerSummary(t *testing.T) {
+	tracker := NewUsageTracker(DefaultModelPrices)
+
+	tracker.RecordRequest("gpt-4o-mini-2024-07-18", "success")
+	tracker.RecordTokens("gpt-4o-mini-2024-07-18", "prompt", 10000)
+	tracker.RecordTokens("gpt-4o-mini-2024-07-18", "completion", 1000)
+	tracker.RecordTokens("gpt-4o-mini-2024-07-18", "total", 11000)
+	tracker.RecordRequest("gpt-4", "success")
+	tracker.RecordRequest("gpt-4", "error")
+	tracker.RecordTokens("gpt-4", "prompt", 1000)
+	tracker.RecordRequest("local-llama", "success")
+	tracker.RecordTokens("local-llama", "prompt", 5000)
+
+	summary := tracker.Summary()
+	if len(summary.Models) != 3 {
+		t.Fatalf("Expected three models, got %+v", summary.Models)
+	}
+
+	gpt4 := summary.Models[0]
+	if gpt4.Model != "gpt-4" || gpt4.Requests != 2 || gpt4.Errors != 1 || math.Abs(gpt4.EstimatedCost-0.03) > 1e-9 {
+		t.Errorf("Expected gpt-4 to be most expensive, got %+v", gpt4)
+	}
+
+	// Dated versions are priced by prefix, and totals are not double counted
+	mini := summary.Models[1]
+	if mini.PromptTokens != 10000 || mini.CompletionTokens != 1000 || math.Abs(mini.EstimatedCost-0.0021) > 1e-9 {
+		t.Errorf("Unexpected gpt-4o-mini usage %+v", mini)
+	}
+
+	if local := summary.Models[2]; local.Priced || local.EstimatedCost != 0 {
+		t.Errorf("Expected an unpriced model, got %+v", local)
+	}
+	if math.Abs(summary.EstimatedCost-0.0321) > 1e-9 {
+		t.Errorf("Expected total cost 0.0321, got %v", summary.EstimatedCost)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	cost, ok := EstimateCost("gpt-4o-2024-08-06", 2000, 500)
+	if !ok || math.Abs(cost-0.01) > 1e-9 {
+		t.Errorf("Expected $0.01 for a dated gpt-4o model, got %v (priced %v)", cost, ok)
+	}
+	if _, ok := EstimateCost("local-llama", 2000, 500); ok {
+		t.Error("Expected no price for an unknown model")
+	}
+}
@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// defaultSMTPPort is used when an email notifier's URL has no port
+const defaultSMTPPort = "25"
+
+// Email mails summaries through an SMTP server. The server is given as
+// smtp://user@host:port, with the user's password as the secret; STARTTLS is
+// used when the server offers it.
+type Email struct {
+	config Config
+	send   func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// validateEmail checks an email notifier's server and addresses
+func validateEmail(cfg Config) error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Scheme != "smtp" || u.Hostname() == "" {
+		return fmt.Errorf("notifier %s: url must be an smtp://host:port URL", cfg.Name)
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return fmt.Errorf("notifier %s: from must be an email address", cfg.Name)
+	}
+	if len(cfg.To) == 0 {
+		return fmt.Errorf("notifier %s: to must list at least one email address", cfg.Name)
+	}
+	for _, to := range cfg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("notifier %s: invalid to address %q", cfg.Name, to)
+		}
+	}
+	return nil
+}
+
+// Name returns the name routing rules use for the notifier
+func (e *Email) Name() string {
+	return e.config.Name
+}
+
+// Send mails the summary. Every call is a new email; sent mail cannot be
+// updated.
+func (e *Email) Send(ctx context.Context, summary pipeline.Summary, meta pipeline.IssueMeta) (pipeline.Delivery, error) {
+	if err := ctx.Err(); err != nil {
+		return pipeline.Delivery{}, err
+	}
+	u, err := url.Parse(e.config.URL)
+	if err != nil {
+		return pipeline.Delivery{}, err
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultSMTPPort
+	}
+	var auth smtp.Auth
+	if username := u.User.Username(); username != "" {
+		auth = smtp.PlainAuth("", username, e.config.Secret, u.Hostname())
+	}
+
+	if err := e.send(net.JoinHostPort(u.Hostname(), port), auth, e.config.From, e.config.To, emailMessage(e.config, summary, meta)); err != nil {
+		return pipeline.Delivery{}, fmt.Errorf("failed to send email: %w", err)
+	}
+	return pipeline.Delivery{}, nil
+}
+
+// emailMessage renders a summary as a plain-text email
+func emailMessage(cfg Config, summary pipeline.Summary, meta pipeline.IssueMeta) []byte {
+	// Titles come from GitHub, so line breaks must not start new headers
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(fmt.Sprintf("[%s#%d] %s", meta.Repository, meta.Number, meta.Title))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	if issue := summary.Issue; issue != nil {
+		fmt.Fprintf(&b, "%s\r\n\r\n", issue.Summary)
+		fmt.Fprintf(&b, "Priority: %s\r\nCategory: %s\r\n", issue.Priority, issue.Category)
+		if len(issue.ActionItems) > 0 {
+			b.WriteString("\r\nAction items:\r\n")
+			for _, item := range issue.ActionItems {
+				fmt.Fprintf(&b, "- %s\r\n", item)
+			}
+		}
+	} else {
+		fmt.Fprintf(&b, "%s\r\n", summary.SkipReason)
+	}
+	if meta.URL != "" {
+		fmt.Fprintf(&b, "\r\n%s\r\n", meta.URL)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,216 @@
+// Package notify implements notifiers that deliver issue summaries outside
+// Slack, by webhook, Teams or email, for routing rules to combine with the
+// Slack notifier.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// Notifier types
+const (
+	TypeWebhook = "webhook" // JSON summary, signed when a secret is set
+	TypeTeams   = "teams"   // Microsoft Teams incoming webhook card
+	TypeEmail   = "email"   // Plain-text email sent over SMTP
+)
+
+// SignatureHeader carries the HMAC-SHA256 of a webhook body, in the same
+// "sha256=<hex>" form GitHub uses
+const SignatureHeader = "X-NotifyOps-Signature-256"
+
+// Config configures one notifier. Notifiers are read from the notifiers key
+// of the config file.
+type Config struct {
+	Name   string `mapstructure:"name" json:"name"`
+	Type   string `mapstructure:"type" json:"type"`
+	URL    string `mapstructure:"url" json:"-"`
+	Secret string `mapstructure:"secret" json:"-"` // Signing secret of webhooks, SMTP password of emails
+
+	From string   `mapstructure:"from" json:"-"` // Sender of emails
+	To   []string `mapstructure:"to" json:"-"`   // Recipients of emails
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Validate checks notifier configs for names routing rules can refer to,
+// known types and usable URLs
+func Validate(configs []Config) error {
+	seen := map[string]bool{pipeline.SlackNotifierName: true}
+	for i, cfg := range configs {
+		if !namePattern.MatchString(cfg.Name) {
+			return fmt.Errorf("notifier %d: name %q must be lowercase letters, digits and dashes", i, cfg.Name)
+		}
+		if seen[cfg.Name] {
+			return fmt.Errorf("notifier %d: duplicate or reserved name %q", i, cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		switch cfg.Type {
+		case TypeWebhook, TypeTeams:
+		case TypeEmail:
+			if err := validateEmail(cfg); err != nil {
+				return err
+			}
+			continue
+		default:
+			return fmt.Errorf("notifier %s: unknown type %q", cfg.Name, cfg.Type)
+		}
+		if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notifier %s: url must be an http or https URL", cfg.Name)
+		}
+	}
+	return nil
+}
+
+// New creates the notifier a config describes
+func New(cfg Config) (pipeline.Notifier, error) {
+	if err := Validate([]Config{cfg}); err != nil {
+		return nil, err
+	}
+	if cfg.Type == TypeEmail {
+		return &Email{config: cfg, send: smtp.SendMail}, nil
+	}
+	return &Webhook{config: cfg, httpClient: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Webhook posts summaries to an HTTP endpoint
+type Webhook struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// Name returns the name routing rules use for the notifier
+func (w *Webhook) Name() string {
+	return w.config.Name
+}
+
+// Send posts the summary. Every call is a new delivery; webhooks have no
+// messages to update.
+func (w *Webhook) Send(ctx context.Context, summary pipeline.Summary, meta pipeline.IssueMeta) (pipeline.Delivery, error) {
+	var payload interface{}
+	if w.config.Type == TypeTeams {
+		payload = teamsCard(summary, meta)
+	} else {
+		payload = webhookPayload(summary, meta)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return pipeline.Delivery{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return pipeline.Delivery{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.config.Secret, body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return pipeline.Delivery{}, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return pipeline.Delivery{}, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return pipeline.Delivery{}, nil
+}
+
+// Sign returns the signature header value of a webhook body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Payload is the JSON body of webhook notifications
+type Payload struct {
+	Repository  string   `json:"repository"`
+	Number      int      `json:"number"`
+	Title       string   `json:"title"`
+	URL         string   `json:"url,omitempty"`
+	State       string   `json:"state,omitempty"`
+	EventType   string   `json:"event_type,omitempty"`
+	Action      string   `json:"action,omitempty"`
+	Rule        string   `json:"rule"`
+	Updated     bool     `json:"updated"` // The issue was notified before
+	Summary     string   `json:"summary,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Confidence  float64  `json:"confidence,omitempty"`
+	Components  []string `json:"components,omitempty"`
+	ActionItems []string `json:"action_items,omitempty"`
+	SkipReason  string   `json:"skip_reason,omitempty"`
+}
+
+func webhookPayload(summary pipeline.Summary, meta pipeline.IssueMeta) Payload {
+	payload := Payload{
+		Repository: meta.Repository,
+		Number:     meta.Number,
+		Title:      meta.Title,
+		URL:        meta.URL,
+		State:      meta.State,
+		EventType:  meta.EventType,
+		Action:     meta.Action,
+		Rule:       meta.Route.Rule,
+		Updated:    meta.Previous.MessageID != "",
+		SkipReason: summary.SkipReason,
+	}
+	if issue := summary.Issue; issue != nil {
+		payload.Summary = issue.Summary
+		payload.Priority = issue.Priority
+		payload.Category = issue.Category
+		payload.Confidence = issue.Confidence
+		payload.Components = issue.Components
+		payload.ActionItems = issue.ActionItems
+	}
+	return payload
+}
+
+// teamsCard renders a summary as a legacy MessageCard, which Teams incoming
+// webhooks accept without an app
+func teamsCard(summary pipeline.Summary, meta pipeline.IssueMeta) map[string]interface{} {
+	title := fmt.Sprintf("%s#%d: %s", meta.Repository, meta.Number, meta.Title)
+	text := summary.SkipReason
+	var facts []map[string]string
+	if issue := summary.Issue; issue != nil {
+		text = issue.Summary
+		facts = []map[string]string{
+			{"name": "Priority", "value": issue.Priority},
+			{"name": "Category", "value": issue.Category},
+		}
+	}
+
+	card := map[string]interface{}{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  title,
+		"title":    title,
+		"text":     text,
+		"sections": []map[string]interface{}{{"facts": facts}},
+	}
+	if meta.URL != "" {
+		card["potentialAction"] = []map[string]interface{}{{
+			"@type":   "OpenUri",
+			"name":    "View issue",
+			"targets": []map[string]string{{"os": "default", "uri": meta.URL}},
+		}}
+	}
+	return card
+}
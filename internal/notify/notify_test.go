@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/routing"
+)
+
+func testMeta() pipeline.IssueMeta {
+	return pipeline.IssueMeta{
+		Repository: "owner/repo",
+		Number:     7,
+		Title:      "Crash on start",
+		URL:        "https://github.com/owner/repo/issues/7",
+		Route:      routing.Route{Rule: "security"},
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+	}))
+	defer server.Close()
+
+	notifier, err := New(Config{Name: "siem", Type: TypeWebhook, URL: server.URL, Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := pipeline.Summary{Issue: &ai.IssueSummary{Summary: "It crashes", Priority: "high", Category: "bug"}}
+	if _, err := notifier.Send(context.Background(), summary, testMeta()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Repository != "owner/repo" || payload.Number != 7 || payload.Priority != "high" || payload.Rule != "security" || payload.Updated {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+	if signature != Sign("s3cret", body) {
+		t.Errorf("Expected the body to be signed, got %q", signature)
+	}
+}
+
+func TestTeamsSend(t *testing.T) {
+	var card map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&card)
+	}))
+	defer server.Close()
+
+	notifier, err := New(Config{Name: "teams", Type: TypeTeams, URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := notifier.Send(context.Background(), pipeline.Summary{SkipReason: "Looks like spam"}, testMeta()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if card["@type"] != "MessageCard" || card["title"] != "owner/repo#7: Crash on start" || card["text"] != "Looks like spam" {
+		t.Errorf("Unexpected card %+v", card)
+	}
+}
+
+func TestWebhookSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	notifier, err := New(Config{Name: "siem", Type: TypeWebhook, URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := notifier.Send(context.Background(), pipeline.Summary{}, testMeta()); err == nil {
+		t.Error("Expected an error for a failed delivery")
+	}
+}
+
+func TestEmailSend(t *testing.T) {
+	notifier, err := New(Config{
+		Name: "mail", Type: TypeEmail, URL: "smtp://bot@mail.example.com:587", Secret: "s3cret",
+		From: "bot@example.com", To: []string{"ops@example.com", "lead@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addr, from string
+	var to []string
+	var message []byte
+	var auth smtp.Auth
+	notifier.(*Email).send = func(a string, au smtp.Auth, f string, recipients []string, msg []byte) error {
+		addr, auth, from, to, message = a, au, f, recipients, msg
+		return nil
+	}
+
+	meta := testMeta()
+	meta.Title = "Crash on start\r\nBcc: someone@example.com"
+	summary := pipeline.Summary{Issue: &ai.IssueSummary{Summary: "It crashes", Priority: "high", Category: "bug", ActionItems: []string{"Add a nil check"}}}
+	if _, err := notifier.Send(context.Background(), summary, meta); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if addr != "mail.example.com:587" || auth == nil || from != "bot@example.com" || len(to) != 2 {
+		t.Errorf("Unexpected delivery to %s from %s to %v", addr, from, to)
+	}
+	text := string(message)
+	for _, want := range []string{
+		"To: ops@example.com, lead@example.com\r\n",
+		"Subject: [owner/repo#7] Crash on start  Bcc: someone@example.com\r\n",
+		"It crashes",
+		"Priority: high",
+		"- Add a nil check",
+		"https://github.com/owner/repo/issues/7",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the email:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "\r\nBcc:") {
+		t.Errorf("Expected line breaks in the title not to start headers:\n%s", text)
+	}
+
+	notifier.(*Email).send = func(string, smtp.Auth, string, []string, []byte) error {
+		return errors.New("connection refused")
+	}
+	if _, err := notifier.Send(context.Background(), summary, testMeta()); err == nil {
+		t.Error("Expected an error for a failed delivery")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []Config
+	}{
+		{"reserved name", []Config{{Name: "slack", Type: TypeWebhook, URL: "https://example.com"}}},
+		{"duplicate name", []Config{{Name: "a", Type: TypeWebhook, URL: "https://example.com"}, {Name: "a", Type: TypeTeams, URL: "https://example.com"}}},
+		{"invalid name", []Config{{Name: "Ops Hook", Type: TypeWebhook, URL: "https://example.com"}}},
+		{"unknown type", []Config{{Name: "pager", Type: "pagerduty", URL: "https://example.com"}}},
+		{"missing url", []Config{{Name: "siem", Type: TypeWebhook}}},
+		{"email over http", []Config{{Name: "mail", Type: TypeEmail, URL: "https://example.com", From: "bot@example.com", To: []string{"ops@example.com"}}}},
+		{"email without sender", []Config{{Name: "mail", Type: TypeEmail, URL: "smtp://mail.example.com", To: []string{"ops@example.com"}}}},
+		{"email without recipients", []Config{{Name: "mail", Type: TypeEmail, URL: "smtp://mail.example.com", From: "bot@example.com"}}},
+		{"invalid recipient", []Config{{Name: "mail", Type: TypeEmail, URL: "smtp://mail.example.com", From: "bot@example.com", To: []string{"ops"}}}},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.configs); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if err := Validate([]Config{
+		{Name: "siem", Type: TypeWebhook, URL: "https://example.com/hook"},
+		{Name: "mail", Type: TypeEmail, URL: "smtp://bot@mail.example.com:587", From: "bot@example.com", To: []string{"ops@example.com"}},
+	}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package onboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github-issue-ai-bot/internal/admin"
+)
+
+// maxRequestBytes bounds the body of an onboarding request
+const maxRequestBytes = 4 << 10
+
+// Request names the repository to check
+type Request struct {
+	Repository string `json:"repo"` // owner/name
+}
+
+// Handler serves onboarding checks. Requests are authorized by the admin
+// token.
+type Handler struct {
+	onboarder  *Onboarder
+	adminToken *admin.Token
+}
+
+// NewHandler creates an onboarding handler
+func NewHandler(onboarder *Onboarder, adminToken *admin.Token) *Handler {
+	return &Handler{onboarder: onboarder, adminToken: adminToken}
+}
+
+// ServeOnboard checks the requested repository and returns its readiness
+// report. The report is returned with 200 whether or not it is ready.
+func (h *Handler) ServeOnboard(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	var request Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, "invalid onboarding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if parts := strings.Split(request.Repository, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "repo must be owner/name", http.StatusBadRequest)
+		return
+	}
+
+	report := h.onboarder.Check(r.Context(), request.Repository)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,146 @@
+// Package onboard checks that a repository is ready for NotifyOps end to
+// end, so adding one is a single call rather than tribal knowledge
+package onboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github-issue-ai-bot/internal/diagnostics"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// GitHub reads a repository's webhooks, permissions and latest issue
+type GitHub interface {
+	FindWebhook(ctx context.Context, repo, url string) (github.Webhook, bool, error)
+	DiagnosePermissions(repository string, needs github.TokenNeeds) diagnostics.Checker
+	LatestIssueNumber(ctx context.Context, repo string) (int, error)
+	FetchEnrichedIssueData(ctx context.Context, repo string, number int) (*github.IssueData, error)
+}
+
+// Processor summarizes and routes an issue without posting it
+type Processor interface {
+	DryRun(ctx context.Context, issueData *github.IssueData) (*pipeline.DryRun, error)
+}
+
+// Router picks the Slack channel of an issue
+type Router interface {
+	Route(issue routing.Issue) routing.Route
+}
+
+// Slack checks that the bot can post to a channel
+type Slack interface {
+	DiagnoseChannel(ctx context.Context, channel string) diagnostics.Check
+}
+
+// Config is what a repository is checked against
+type Config struct {
+	WebhookURL string            // Where GitHub should deliver webhooks, empty to accept any issues webhook
+	Needs      github.TokenNeeds // Permissions the enabled features need
+}
+
+// Report is the readiness of a repository
+type Report struct {
+	Repository string              `json:"repository"`
+	CheckedAt  time.Time           `json:"checked_at"`
+	Ready      bool                `json:"ready"` // No check failed
+	Checks     []diagnostics.Check `json:"checks"`
+	DryRun     *pipeline.DryRun    `json:"dry_run,omitempty"` // What the most recent issue would have posted
+}
+
+// Onboarder checks repositories
+type Onboarder struct {
+	github    GitHub
+	processor Processor
+	router    Router
+	slack     Slack
+	config    Config
+}
+
+// NewOnboarder creates an onboarder
+func NewOnboarder(gh GitHub, processor Processor, router Router, slack Slack, config Config) *Onboarder {
+	return &Onboarder{github: gh, processor: processor, router: router, slack: slack, config: config}
+}
+
+// Check checks that GitHub delivers the repository's webhooks, that the
+// access token has the permissions it needs there, that its issues route to
+// a Slack channel the bot can post to, and dry-runs its most recent issue
+// through the pipeline. Nothing is posted, stored or labeled.
+func (o *Onboarder) Check(ctx context.Context, repository string) Report {
+	checks := []diagnostics.Check{o.checkWebhook(ctx, repository)}
+	checks = append(checks, o.github.DiagnosePermissions(repository, o.config.Needs)(ctx)...)
+
+	dryRun, check := o.dryRun(ctx, repository)
+	checks = append(checks, check)
+	channel := o.router.Route(routing.Issue{Repository: repository, Priority: "low"}).Channel
+	if dryRun != nil {
+		channel = dryRun.Channel
+	}
+	checks = append(checks, o.slack.DiagnoseChannel(ctx, channel))
+
+	report := Report{Repository: repository, CheckedAt: time.Now(), Ready: true, Checks: checks, DryRun: dryRun}
+	for _, check := range checks {
+		if check.Status == diagnostics.StatusError {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+// checkWebhook checks that an active webhook delivers the repository's issue
+// events
+func (o *Onboarder) checkWebhook(ctx context.Context, repository string) diagnostics.Check {
+	webhook, ok, err := o.github.FindWebhook(ctx, repository, o.config.WebhookURL)
+	switch {
+	case err != nil:
+		return diagnostics.Warning("webhook",
+			fmt.Sprintf("Could not list the webhooks of %s (%v); the token needs the Webhooks read permission (admin:repo_hook for classic tokens)", repository, err))
+	case !ok && o.config.WebhookURL == "":
+		return diagnostics.Error("webhook",
+			fmt.Sprintf("No webhook of %s delivers issue events; add one at https://github.com/%s/settings/hooks", repository, repository))
+	case !ok:
+		return diagnostics.Error("webhook",
+			fmt.Sprintf("No webhook of %s delivers to %s; add one at https://github.com/%s/settings/hooks with content type application/json, the GITHUB_WEBHOOK_SECRET and the Issues and Issue comments events", repository, o.config.WebhookURL, repository))
+	case !webhook.Active:
+		return diagnostics.Error("webhook", fmt.Sprintf("The webhook to %s is disabled; activate it", webhook.URL))
+	case !webhook.Delivers("issues"):
+		return diagnostics.Error("webhook",
+			fmt.Sprintf("The webhook to %s only sends %s; add the Issues event", webhook.URL, strings.Join(webhook.Events, ", ")))
+	case !webhook.Delivers("issue_comment"):
+		return diagnostics.Warning("webhook",
+			fmt.Sprintf("The webhook to %s does not send Issue comments, so commands and replies are not seen", webhook.URL))
+	case webhook.LastResponse != 0 && webhook.LastResponse/100 != 2:
+		return diagnostics.Warning("webhook",
+			fmt.Sprintf("The last delivery to %s got HTTP %d; check its recent deliveries, e.g. for a wrong secret", webhook.URL, webhook.LastResponse))
+	}
+	scope := "repository"
+	if webhook.Organization {
+		scope = "organization"
+	}
+	return diagnostics.OK("webhook", fmt.Sprintf("The %s webhook to %s delivers issue events", scope, webhook.URL))
+}
+
+// dryRun summarizes and routes the repository's most recent issue
+func (o *Onboarder) dryRun(ctx context.Context, repository string) (*pipeline.DryRun, diagnostics.Check) {
+	number, err := o.github.LatestIssueNumber(ctx, repository)
+	if err != nil {
+		return nil, diagnostics.Error("dry_run", fmt.Sprintf("Could not read the issues of %s: %v", repository, err))
+	}
+	if number == 0 {
+		return nil, diagnostics.Warning("dry_run", repository+" has no issues to dry-run")
+	}
+	issueData, err := o.github.FetchEnrichedIssueData(ctx, repository, number)
+	if err != nil {
+		return nil, diagnostics.Error("dry_run", fmt.Sprintf("Could not fetch %s#%d: %v", repository, number, err))
+	}
+	dryRun, err := o.processor.DryRun(ctx, issueData)
+	if err != nil {
+		return nil, diagnostics.Error("dry_run", fmt.Sprintf("Could not summarize %s#%d: %v", repository, number, err))
+	}
+	return dryRun, diagnostics.OK("dry_run",
+		fmt.Sprintf("%s#%d was summarized as %s priority %s and routed to %s", repository, number, dryRun.Summary.Priority, dryRun.Summary.Category, dryRun.Channel))
+}
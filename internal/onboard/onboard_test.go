@@ -0,0 +1,167 @@
+package onboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/diagnostics"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/pipeline"
+	"github-issue-ai-bot/internal/routing"
+)
+
+type fakeGitHub struct {
+	webhook github.Webhook
+	found   bool
+	missing []string
+	latest  int
+}
+
+func (f *fakeGitHub) FindWebhook(ctx context.Context, repo, url string) (github.Webhook, bool, error) {
+	return f.webhook, f.found, nil
+}
+
+func (f *fakeGitHub) DiagnosePermissions(repository string, needs github.TokenNeeds) diagnostics.Checker {
+	return func(ctx context.Context) []diagnostics.Check {
+		if len(f.missing) > 0 {
+			return []diagnostics.Check{diagnostics.Error("github_permissions", strings.Join(f.missing, ", "))}
+		}
+		return []diagnostics.Check{diagnostics.OK("github_permissions", "ok")}
+	}
+}
+
+func (f *fakeGitHub) LatestIssueNumber(ctx context.Context, repo string) (int, error) {
+	return f.latest, nil
+}
+
+func (f *fakeGitHub) FetchEnrichedIssueData(ctx context.Context, repo string, number int) (*github.IssueData, error) {
+	return &github.IssueData{
+		Issue:      &gogithub.Issue{Number: gogithub.Int(number), Title: gogithub.String("Crash on start")},
+		Repository: &gogithub.Repository{FullName: gogithub.String(repo)},
+	}, nil
+}
+
+type fakeProcessor struct{ err error }
+
+func (f *fakeProcessor) DryRun(ctx context.Context, issueData *github.IssueData) (*pipeline.DryRun, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &pipeline.DryRun{Number: issueData.Issue.GetNumber(), Summary: &ai.IssueSummary{Priority: "high", Category: "bug"}, Channel: "CAPI"}, nil
+}
+
+type fakeRouter struct{}
+
+func (fakeRouter) Route(issue routing.Issue) routing.Route {
+	return routing.Route{Channel: "CDEFAULT"}
+}
+
+type fakeSlack struct{ channels []string }
+
+func (f *fakeSlack) DiagnoseChannel(ctx context.Context, channel string) diagnostics.Check {
+	f.channels = append(f.channels, channel)
+	return diagnostics.OK("slack_channel:"+channel, "ok")
+}
+
+func TestCheckReady(t *testing.T) {
+	gh := &fakeGitHub{
+		webhook: github.Webhook{URL: "https://notifyops.example.com/webhook/github", Active: true, Events: []string{"issues", "issue_comment"}},
+		found:   true,
+		latest:  42,
+	}
+	slack := &fakeSlack{}
+	onboarder := NewOnboarder(gh, &fakeProcessor{}, fakeRouter{}, slack, Config{WebhookURL: "https://notifyops.example.com/webhook/github"})
+
+	report := onboarder.Check(context.Background(), "o/r")
+	if !report.Ready || len(report.Checks) != 4 {
+		t.Fatalf("Expected a ready repository, got %+v", report)
+	}
+	if report.DryRun == nil || report.DryRun.Number != 42 {
+		t.Errorf("Expected the latest issue dry-run, got %+v", report.DryRun)
+	}
+	// The channel the dry run was routed to is checked
+	if len(slack.channels) != 1 || slack.channels[0] != "CAPI" {
+		t.Errorf("Expected the routed channel checked, got %v", slack.channels)
+	}
+}
+
+func TestCheckNotReady(t *testing.T) {
+	gh := &fakeGitHub{missing: []string{"Issues: write (labels)"}}
+	slack := &fakeSlack{}
+	onboarder := NewOnboarder(gh, &fakeProcessor{err: errors.New("boom")}, fakeRouter{}, slack, Config{WebhookURL: "https://notifyops.example.com/webhook/github"})
+
+	report := onboarder.Check(context.Background(), "o/r")
+	if report.Ready {
+		t.Fatal("Expected a repository without a webhook not ready")
+	}
+	statuses := map[string]string{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	want := map[string]string{
+		"webhook":                diagnostics.StatusError,
+		"github_permissions":     diagnostics.StatusError,
+		"dry_run":                diagnostics.StatusWarning, // No issues yet
+		"slack_channel:CDEFAULT": diagnostics.StatusOK,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %s, got %v", name, status, statuses)
+		}
+	}
+
+	// Webhooks without issue comments work, with a caveat
+	gh.webhook, gh.found = github.Webhook{URL: "https://notifyops.example.com/webhook/github", Active: true, Events: []string{"issues"}}, true
+	if check := onboarder.checkWebhook(context.Background(), "o/r"); check.Status != diagnostics.StatusWarning {
+		t.Errorf("Expected a warning, got %+v", check)
+	}
+	gh.webhook.Active = false
+	if check := onboarder.checkWebhook(context.Background(), "o/r"); check.Status != diagnostics.StatusError {
+		t.Errorf("Expected an error for a disabled webhook, got %+v", check)
+	}
+}
+
+func TestServeOnboard(t *testing.T) {
+	onboarder := NewOnboarder(&fakeGitHub{found: true, webhook: github.Webhook{Active: true, Events: []string{"*"}}}, &fakeProcessor{}, fakeRouter{}, &fakeSlack{}, Config{})
+	handler := NewHandler(onboarder, admin.NewToken("admin-token"))
+
+	tests := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+	}{
+		{"unauthorized", "", `{"repo": "o/r"}`, http.StatusUnauthorized},
+		{"invalid repository", "admin-token", `{"repo": "o"}`, http.StatusBadRequest},
+		{"ready", "admin-token", `{"repo": "o/r"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodPost, "/api/onboard", strings.NewReader(tt.body))
+		if tt.token != "" {
+			request.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeOnboard(recorder, request)
+		if recorder.Code != tt.status {
+			t.Errorf("%s: got status %d, want %d", tt.name, recorder.Code, tt.status)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/onboard", strings.NewReader(`{"repo": "o/r"}`))
+	request.Header.Set("Authorization", "Bearer admin-token")
+	recorder := httptest.NewRecorder()
+	handler.ServeOnboard(recorder, request)
+	var report Report
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil || report.Repository != "o/r" {
+		t.Errorf("Expected the report of o/r, got %+v (%v)", report, err)
+	}
+}
@@ -0,0 +1,148 @@
+package oncall
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	// Embed the timezone database so schedules work in minimal containers
+	_ "time/tzdata"
+)
+
+// Schedule is a rotation of engineers covering a set of repositories. The
+// rotation hands off at HandoffTime in Timezone every RotationDays days,
+// starting with the first member on StartDate.
+type Schedule struct {
+	Name         string   `mapstructure:"name" json:"name"`
+	Repositories []string `mapstructure:"repositories" json:"repositories"` // Glob patterns, empty covers every repository
+	Members      []string `mapstructure:"members" json:"members"`           // Slack user IDs, in rotation order
+	Timezone     string   `mapstructure:"timezone" json:"timezone"`         // IANA name, defaults to UTC
+	StartDate    string   `mapstructure:"start_date" json:"start_date"`     // YYYY-MM-DD, the first member's first shift
+	HandoffTime  string   `mapstructure:"handoff_time" json:"handoff_time"` // HH:MM, defaults to 09:00
+	RotationDays int      `mapstructure:"rotation_days" json:"rotation_days"`
+}
+
+// Shift is who is on call for an issue
+type Shift struct {
+	Schedule string
+	UserID   string
+}
+
+// schedule is a validated Schedule with parsed times
+type schedule struct {
+	Schedule
+	location *time.Location
+	start    time.Time // Civil start date, in UTC
+	handoff  time.Duration
+}
+
+// Scheduler finds the engineer on call for a repository
+type Scheduler struct {
+	schedules []schedule
+}
+
+// NewScheduler validates the schedules and creates a scheduler. Schedules are
+// matched in order and the first one covering a repository wins.
+func NewScheduler(schedules []Schedule) (*Scheduler, error) {
+	s := &Scheduler{}
+	for i, cfg := range schedules {
+		parsed, err := parseSchedule(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("on-call schedule %d (%s): %w", i, cfg.Name, err)
+		}
+		s.schedules = append(s.schedules, parsed)
+	}
+	return s, nil
+}
+
+func parseSchedule(cfg Schedule) (schedule, error) {
+	if len(cfg.Members) == 0 {
+		return schedule{}, fmt.Errorf("no members")
+	}
+	for _, pattern := range cfg.Repositories {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return schedule{}, fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+	}
+
+	if cfg.Timezone == "" {
+		cfg.Timezone = "UTC"
+	}
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return schedule{}, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	start, err := time.Parse("2006-01-02", cfg.StartDate)
+	if err != nil {
+		return schedule{}, fmt.Errorf("invalid start_date: %w", err)
+	}
+
+	if cfg.HandoffTime == "" {
+		cfg.HandoffTime = "09:00"
+	}
+	handoff, err := time.Parse("15:04", cfg.HandoffTime)
+	if err != nil {
+		return schedule{}, fmt.Errorf("invalid handoff_time: %w", err)
+	}
+
+	if cfg.RotationDays <= 0 {
+		cfg.RotationDays = 7
+	}
+
+	return schedule{
+		Schedule: cfg,
+		location: location,
+		start:    start,
+		handoff:  time.Duration(handoff.Hour())*time.Hour + time.Duration(handoff.Minute())*time.Minute,
+	}, nil
+}
+
+// OnCall returns who is on call for a repository at the given time
+func (s *Scheduler) OnCall(repository string, at time.Time) (Shift, bool) {
+	for _, sched := range s.schedules {
+		if !sched.covers(repository) {
+			continue
+		}
+		userID, ok := sched.onCall(at)
+		if !ok {
+			continue
+		}
+		return Shift{Schedule: sched.Name, UserID: userID}, true
+	}
+	return Shift{}, false
+}
+
+func (s schedule) covers(repository string) bool {
+	if len(s.Repositories) == 0 {
+		return true
+	}
+	repository = strings.ToLower(repository)
+	for _, pattern := range s.Repositories {
+		if ok, _ := path.Match(strings.ToLower(pattern), repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// onCall counts rotations in civil days in the schedule's timezone, so
+// handoffs stay at the same local time across daylight saving changes
+func (s schedule) onCall(at time.Time) (string, bool) {
+	local := at.In(s.location)
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+
+	// Before today's handoff, yesterday's shift is still running
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	if clock < s.handoff {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	days := int(day.Sub(s.start).Hours() / 24)
+	if days < 0 {
+		return "", false
+	}
+	rotation := days / s.RotationDays
+	return s.Members[rotation%len(s.Members)], true
+}
@@ -0,0 +1,70 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedulerOnCall(t *testing.T) {
+	scheduler, err := NewScheduler([]Schedule{
+		{
+			Name:         "api",
+			Repositories: []string{"my-org/api"},
+			Members:      []string{"U1", "U2", "U3"},
+			Timezone:     "America/New_York",
+			StartDate:    "2024-03-04",
+			HandoffTime:  "09:00",
+			RotationDays: 7,
+		},
+		{
+			Name:         "everything",
+			Members:      []string{"U9"},
+			StartDate:    "2024-01-01",
+			RotationDays: 1,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	tests := []struct {
+		name       string
+		repository string
+		at         time.Time
+		want       Shift
+		ok         bool
+	}{
+		{"first shift", "my-org/api", time.Date(2024, 3, 4, 9, 0, 0, 0, newYork), Shift{"api", "U1"}, true},
+		// Before the api rotation starts, the catch-all schedule covers it
+		{"before first handoff", "my-org/api", time.Date(2024, 3, 4, 8, 59, 0, 0, newYork), Shift{"everything", "U9"}, true},
+		// Clocks go forward on 2024-03-10; the handoff stays at 09:00 local
+		{"before handoff after dst", "my-org/api", time.Date(2024, 3, 11, 8, 59, 0, 0, newYork), Shift{"api", "U1"}, true},
+		{"at handoff after dst", "my-org/api", time.Date(2024, 3, 11, 9, 0, 0, 0, newYork), Shift{"api", "U2"}, true},
+		{"wraps around", "My-Org/API", time.Date(2024, 3, 25, 12, 0, 0, 0, newYork), Shift{"api", "U1"}, true},
+		{"fallback schedule", "other/repo", time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC), Shift{"everything", "U9"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := scheduler.OnCall(tt.repository, tt.at)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestNewSchedulerValidation(t *testing.T) {
+	invalid := []Schedule{
+		{Name: "no members", StartDate: "2024-01-01"},
+		{Name: "bad timezone", Members: []string{"U1"}, StartDate: "2024-01-01", Timezone: "Mars/Olympus"},
+		{Name: "bad date", Members: []string{"U1"}, StartDate: "01/01/2024"},
+		{Name: "bad handoff", Members: []string{"U1"}, StartDate: "2024-01-01", HandoffTime: "9am"},
+	}
+	for _, schedule := range invalid {
+		if _, err := NewScheduler([]Schedule{schedule}); err == nil {
+			t.Errorf("Expected %q to be rejected", schedule.Name)
+		}
+	}
+}
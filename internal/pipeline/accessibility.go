@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// AccessibilityConfig adapts Slack messages to the channels they are posted
+// in, for teams using screen readers or who find emoji noisy
+type AccessibilityConfig struct {
+	NoEmojiChannels   []string // Slack channel IDs whose messages have emoji removed
+	PlainTextChannels []string // Slack channel IDs that always get the plain layout, which has no emoji
+}
+
+// SetAccessibility sets the per-channel emoji and plain-text settings
+func (p *IssueProcessor) SetAccessibility(config AccessibilityConfig) {
+	p.accessibility = config
+}
+
+// channelLayout is the layout for a message in channel: plain in plain-text
+// channels, otherwise layout
+func (p *IssueProcessor) channelLayout(channel, layout string) string {
+	if containsString(p.accessibility.PlainTextChannels, channel) {
+		return routing.LayoutPlain
+	}
+	return layout
+}
+
+// withoutEmoji removes emoji from a message for channel, if it opted out of
+// them. Mentions, incident links and other sections added after the message
+// was generated may carry emoji even in the plain layout.
+func (p *IssueProcessor) withoutEmoji(message map[string]interface{}, channel string) map[string]interface{} {
+	if !containsString(p.accessibility.NoEmojiChannels, channel) && !containsString(p.accessibility.PlainTextChannels, channel) {
+		return message
+	}
+	stripEmoji(message)
+	return message
+}
+
+// stripEmoji removes emoji from every "text" string of a message and its
+// blocks, which are built-in maps or, from templates, generic JSON values
+func stripEmoji(value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if text, ok := field.(string); ok && key == "text" {
+				value[key] = utils.StripEmoji(text)
+				continue
+			}
+			stripEmoji(field)
+		}
+	case []map[string]interface{}:
+		for _, item := range value {
+			stripEmoji(item)
+		}
+	case []interface{}:
+		for _, item := range value {
+			stripEmoji(item)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+)
+
+func TestProcessIssuePlainTextChannel(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	processor.SetAccessibility(AccessibilityConfig{PlainTextChannels: []string{"C123"}})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if len(notifier.posts) != 1 || notifier.posts[0]["layout"] != routing.LayoutPlain {
+		t.Fatalf("Expected a plain post, got %v", notifier.posts)
+	}
+	if len(notifier.updates) != 1 || notifier.updates[0]["layout"] != routing.LayoutPlain {
+		t.Errorf("Expected a plain update, got %v", notifier.updates)
+	}
+	if record, _ := processor.store.GetIssue("owner/repo", 7); record.Layout != routing.LayoutPlain {
+		t.Errorf("Expected the plain layout stored, got %q", record.Layout)
+	}
+}
+
+func TestWithoutEmoji(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	processor.SetAccessibility(AccessibilityConfig{NoEmojiChannels: []string{"C-QUIET"}})
+	newMessage := func() map[string]interface{} {
+		message := map[string]interface{}{
+			"text": "🔴 High priority bug",
+			"blocks": []interface{}{
+				map[string]interface{}{"type": "section", "text": map[string]interface{}{"type": "mrkdwn", "text": ":zzz: Skipped"}},
+			},
+		}
+		return withOnCallMention(message, "U1")
+	}
+
+	if message := processor.withoutEmoji(newMessage(), "C-LOUD"); message["text"] != "🔴 High priority bug" {
+		t.Errorf("Expected emoji kept in other channels, got %q", message["text"])
+	}
+
+	message := processor.withoutEmoji(newMessage(), "C-QUIET")
+	if message["text"] != "High priority bug" {
+		t.Errorf("Expected emoji removed from the text, got %q", message["text"])
+	}
+	blocks := message["blocks"].([]interface{})
+	for i, want := range []string{"On call: <@U1>", "Skipped"} {
+		text := blocks[i].(map[string]interface{})["text"].(map[string]interface{})["text"]
+		if text != want {
+			t.Errorf("Expected block %d to read %q, got %q", i, want, text)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// IssueReactor adds and removes the bot's reactions on GitHub issues
+type IssueReactor interface {
+	ReactToIssue(ctx context.Context, repo string, number int, reaction string) error
+	RemoveIssueReaction(ctx context.Context, repo string, number int, reaction string) error
+}
+
+// AckConfig marks the issues the bot has triaged on GitHub, so maintainers
+// browsing issues there can tell them apart
+type AckConfig struct {
+	Enabled        bool
+	Reaction       string // Added once an issue is summarized and posted, e.g. "eyes"
+	FailedReaction string // Replaces Reaction when processing the issue again fails, empty to only remove it
+}
+
+// Validate checks that the reactions are ones GitHub accepts
+func (c AckConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if !github.ValidReaction(c.Reaction) {
+		return fmt.Errorf("invalid acknowledgement reaction %q", c.Reaction)
+	}
+	if c.FailedReaction != "" && (!github.ValidReaction(c.FailedReaction) || c.FailedReaction == c.Reaction) {
+		return fmt.Errorf("invalid failed acknowledgement reaction %q", c.FailedReaction)
+	}
+	return nil
+}
+
+// SetAcknowledgement reacts to issues on GitHub once they are summarized and
+// posted
+func (p *IssueProcessor) SetAcknowledgement(reactor IssueReactor, config AckConfig) {
+	p.reactor = reactor
+	p.ackConfig = config
+}
+
+// acknowledgeIssue reacts to an issue that was summarized and posted,
+// removing a failure reaction left earlier, and returns the reaction the bot
+// now has on the issue. Failures are logged; the issue is still processed.
+func (p *IssueProcessor) acknowledgeIssue(ctx context.Context, issueData *github.IssueData, previous *store.IssueRecord) string {
+	var current string
+	if previous != nil {
+		current = previous.Reaction
+	}
+	if p.reactor == nil || issueData.Source != "" || current == p.ackConfig.Reaction {
+		return current
+	}
+
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	if current != "" {
+		if err := p.reactor.RemoveIssueReaction(ctx, repository, number, current); err != nil {
+			p.logger.Warn("Failed to remove issue reaction",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.String("reaction", current),
+				zap.Error(err))
+			return current
+		}
+	}
+	if err := p.reactor.ReactToIssue(ctx, repository, number, p.ackConfig.Reaction); err != nil {
+		p.logger.Warn("Failed to acknowledge issue",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.Error(err))
+		return ""
+	}
+	return p.ackConfig.Reaction
+}
+
+// unacknowledgeIssue removes the acknowledgement from an issue that failed
+// to process again, or replaces it with the failure reaction, so the issue no
+// longer looks triaged with an outdated summary. Issues the bot never
+// acknowledged are left alone.
+func (p *IssueProcessor) unacknowledgeIssue(ctx context.Context, issueData *github.IssueData, previous *store.IssueRecord) {
+	if p.reactor == nil || issueData.Source != "" || previous == nil || previous.Reaction != p.ackConfig.Reaction {
+		return
+	}
+
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	if err := p.reactor.RemoveIssueReaction(ctx, repository, number, previous.Reaction); err != nil {
+		p.logger.Warn("Failed to remove issue reaction",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.String("reaction", previous.Reaction),
+			zap.Error(err))
+		return
+	}
+	reaction := ""
+	if p.ackConfig.FailedReaction != "" {
+		if err := p.reactor.ReactToIssue(ctx, repository, number, p.ackConfig.FailedReaction); err != nil {
+			p.logger.Warn("Failed to mark issue as failed",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.Error(err))
+		} else {
+			reaction = p.ackConfig.FailedReaction
+		}
+	}
+
+	updated := *previous
+	updated.Reaction = reaction
+	p.store.SaveIssue(&updated)
+}
@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/store"
+)
+
+type fakeReactor struct {
+	calls []string
+}
+
+func (f *fakeReactor) ReactToIssue(ctx context.Context, repo string, number int, reaction string) error {
+	f.calls = append(f.calls, "+"+reaction)
+	return nil
+}
+
+func (f *fakeReactor) RemoveIssueReaction(ctx context.Context, repo string, number int, reaction string) error {
+	f.calls = append(f.calls, "-"+reaction)
+	return nil
+}
+
+// failingNotifier fails updates while err is set
+type failingNotifier struct {
+	fakeNotifier
+	err error
+}
+
+func (f *failingNotifier) UpdateIssueSummary(ctx context.Context, channelID, ts string, message map[string]interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.fakeNotifier.UpdateIssueSummary(ctx, channelID, ts, message)
+}
+
+func TestProcessIssueAcknowledgement(t *testing.T) {
+	router, err := routing.NewRouter(nil, "C123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := &failingNotifier{}
+	issues := store.NewMemoryStore()
+	processor := NewIssueProcessor(&fakeSummarizer{}, notifier, router, issues, zap.NewNop(), nopMetrics{})
+	reactor := &fakeReactor{}
+	processor.SetAcknowledgement(reactor, AckConfig{Enabled: true, Reaction: "eyes", FailedReaction: "confused"})
+
+	edit := func(body string) *github.IssueData {
+		issueData := newIssueData("edited", github.BehaviorResummarize, "open", body)
+		issueData.Changes = &gogithub.EditChange{Body: &gogithub.EditBody{From: gogithub.String("It crashes")}}
+		return issueData
+	}
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if !reflect.DeepEqual(reactor.calls, []string{"+eyes"}) {
+		t.Fatalf("Expected the posted issue to be acknowledged, got %v", reactor.calls)
+	}
+
+	// A failed update swaps the acknowledgement for the failure reaction
+	notifier.err = errors.New("slack down")
+	processor.ProcessIssue(context.Background(), edit("It crashes with a nil pointer in main.go"))
+	if !reflect.DeepEqual(reactor.calls, []string{"+eyes", "-eyes", "+confused"}) {
+		t.Fatalf("Expected the acknowledgement replaced, got %v", reactor.calls)
+	}
+	if record, _ := issues.GetIssue("owner/repo", 7); record.Reaction != "confused" || record.MessageTS == "" {
+		t.Errorf("Expected the record to keep its message and note the failure, got %+v", record)
+	}
+
+	// Succeeding again restores it, once
+	notifier.err = nil
+	processor.ProcessIssue(context.Background(), edit("It crashes with a nil pointer in main.go on startup"))
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if !reflect.DeepEqual(reactor.calls, []string{"+eyes", "-eyes", "+confused", "-confused", "+eyes"}) {
+		t.Errorf("Expected the acknowledgement restored once, got %v", reactor.calls)
+	}
+}
+
+func TestAckConfigValidate(t *testing.T) {
+	for _, valid := range []AckConfig{{}, {Enabled: true, Reaction: "eyes"}, {Enabled: true, Reaction: "eyes", FailedReaction: "confused"}} {
+		if err := valid.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", valid, err)
+		}
+	}
+	for _, invalid := range []AckConfig{{Enabled: true, Reaction: "👀"}, {Enabled: true, Reaction: "eyes", FailedReaction: "eyes"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", invalid)
+		}
+	}
+}
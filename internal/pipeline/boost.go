@@ -0,0 +1,209 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/store"
+)
+
+// IssueFetcher fetches the current state of an issue from GitHub, for jobs
+// that revisit posted issues without a webhook
+type IssueFetcher interface {
+	FetchEnrichedIssueData(ctx context.Context, repo string, number int) (*github.IssueData, error)
+}
+
+// ReactionSource reads issues from GitHub to find popular ones. GitHub sends
+// no webhooks for reactions, so posted issues are polled.
+type ReactionSource interface {
+	IssueFetcher
+	IssueThumbsUp(ctx context.Context, repo string, number int) (int, error)
+}
+
+// BoostConfig raises the priority of issues the community upvotes
+type BoostConfig struct {
+	Threshold int           // 👍 reactions that raise an issue's priority by one level, 0 to disable
+	Interval  time.Duration // How often open posted issues are polled for reactions
+	Relabel   bool          // Swap the issue's GitHub priority labels when the priority is raised
+}
+
+// SetReactionBoost raises the priority of issues with at least
+// config.Threshold 👍 reactions by one level, both when an issue is processed
+// and when polling finds that it crossed the threshold. Boosted issues are
+// relabeled through labeler when config.Relabel is set.
+func (p *IssueProcessor) SetReactionBoost(source ReactionSource, labeler Labeler, config BoostConfig) {
+	p.reactions = source
+	p.boostLabeler = labeler
+	p.boostConfig = config
+}
+
+// boost returns a copy of the summary one priority level higher when the issue
+// has enough 👍 reactions. Summaries are raised at most once; a re-summarized
+// issue is raised again from its new AI priority.
+func (p *IssueProcessor) boost(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) (*ai.IssueSummary, bool) {
+	if p.boostConfig.Threshold <= 0 || summary == nil || summary.BoostedFrom != "" || issueData.Activity == nil {
+		return summary, false
+	}
+	if issueData.Activity.ThumbsUp < p.boostConfig.Threshold {
+		return summary, false
+	}
+	raised := p.priorities().Raise(summary.Priority)
+	if raised == summary.Priority {
+		return summary, false
+	}
+
+	boosted := *summary
+	boosted.BoostedFrom, boosted.Priority = summary.Priority, raised
+
+	repository := issueData.Repository.GetFullName()
+	p.metrics.RecordPriorityBoost(repository)
+	p.logger.Info("Raised priority of popular issue",
+		zap.String("repository", repository),
+		zap.Int("issue_number", issueData.Issue.GetNumber()),
+		zap.String("from", summary.Priority),
+		zap.String("to", raised),
+		zap.Int("thumbs_up", issueData.Activity.ThumbsUp))
+
+	if p.boostConfig.Relabel {
+		p.relabel(ctx, issueData, summary.Priority, raised)
+	}
+	return &boosted, true
+}
+
+// boostMemory notes a boost for later prompts
+func boostMemory(summary *ai.IssueSummary, thumbsUp int) memory.Entry {
+	return memory.Entry{
+		Kind: memory.KindSummary,
+		Text: fmt.Sprintf("Priority raised from %s to %s after %d 👍 reactions", summary.BoostedFrom, summary.Priority, thumbsUp),
+		At:   time.Now(),
+	}
+}
+
+// relabel replaces the taxonomy labels of the old priority with those of the
+// new one. Failures are logged; the boost still applies in Slack.
+func (p *IssueProcessor) relabel(ctx context.Context, issueData *github.IssueData, from, to string) {
+	if p.boostLabeler == nil || p.taxonomy == nil {
+		return
+	}
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+
+	existing := make(map[string]bool, len(issueData.Issue.Labels))
+	for _, label := range issueData.Issue.Labels {
+		existing[strings.ToLower(label.GetName())] = true
+	}
+	for _, label := range p.taxonomy.Labels(from, "") {
+		if !existing[strings.ToLower(label)] {
+			continue
+		}
+		if err := p.boostLabeler.RemoveLabel(ctx, repository, number, label); err != nil {
+			p.logger.Warn("Failed to remove priority label",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.String("label", label),
+				zap.Error(err))
+		}
+	}
+	var missing []string
+	for _, label := range p.taxonomy.Labels(to, "") {
+		if !existing[strings.ToLower(label)] {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	if err := p.boostLabeler.AddLabels(ctx, repository, number, missing); err != nil {
+		p.logger.Warn("Failed to add priority labels",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.Strings("labels", missing),
+			zap.Error(err))
+	}
+}
+
+// RunReactionBoost polls open posted issues for reactions until the context
+// is cancelled
+func (p *IssueProcessor) RunReactionBoost(ctx context.Context) {
+	if p.reactions == nil || p.boostConfig.Threshold <= 0 || p.boostConfig.Interval <= 0 {
+		return
+	}
+
+	every(ctx, p.boostConfig.Interval, p.CheckReactions)
+}
+
+// every calls check at each interval until the context is cancelled
+func every(ctx context.Context, interval time.Duration, check func(context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check(ctx)
+		}
+	}
+}
+
+// CheckReactions polls every open posted issue that could still be raised
+// once, and refreshes the Slack message of those that crossed the threshold
+func (p *IssueProcessor) CheckReactions(ctx context.Context) {
+	records, _ := p.store.ListIssues(store.Query{})
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return
+		}
+		if !p.boostCandidate(record) {
+			continue
+		}
+
+		thumbsUp, err := p.reactions.IssueThumbsUp(ctx, record.Repository, record.Number)
+		if err != nil {
+			p.logger.Warn("Failed to check issue reactions",
+				zap.String("repository", record.Repository),
+				zap.Int("issue_number", record.Number),
+				zap.Error(err))
+			continue
+		}
+		if thumbsUp < p.boostConfig.Threshold {
+			continue
+		}
+
+		issueData, err := p.reactions.FetchEnrichedIssueData(ctx, record.Repository, record.Number)
+		if err != nil {
+			p.logger.Warn("Failed to fetch popular issue",
+				zap.String("repository", record.Repository),
+				zap.Int("issue_number", record.Number),
+				zap.Error(err))
+			continue
+		}
+		// Refresh the posted message, which applies the boost
+		issueData.EventType, issueData.Action = "reactions", "threshold"
+		issueData.Behavior = github.BehaviorUpdate
+		p.ProcessIssue(ctx, issueData)
+	}
+}
+
+// boostCandidate reports whether polling a record could raise its priority
+func (p *IssueProcessor) boostCandidate(record store.IssueRecord) bool {
+	return record.State == "open" && record.Source == "" && record.MessageTS != "" && record.Summary != nil &&
+		record.Summary.BoostedFrom == "" && p.priorities().Raise(record.Summary.Priority) != record.Summary.Priority
+}
+
+// priorities returns the taxonomy that orders priorities, the built-in one
+// unless SetTaxonomy was called
+func (p *IssueProcessor) priorities() *ai.Taxonomy {
+	if p.taxonomy == nil {
+		return ai.DefaultTaxonomy()
+	}
+	return p.taxonomy
+}
@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeReactions struct {
+	thumbsUp int
+	checks   int
+	fetches  int
+}
+
+func (f *fakeReactions) IssueThumbsUp(ctx context.Context, repo string, number int) (int, error) {
+	f.checks++
+	return f.thumbsUp, nil
+}
+
+func (f *fakeReactions) FetchEnrichedIssueData(ctx context.Context, repo string, number int) (*github.IssueData, error) {
+	f.fetches++
+	issueData := newIssueData("", "", "open", "It crashes")
+	issueData.Issue.Labels = []*gogithub.Label{{Name: gogithub.String("P1")}}
+	issueData.Activity = &github.IssueActivity{ThumbsUp: f.thumbsUp}
+	return issueData, nil
+}
+
+func TestReactionBoost(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	taxonomy, err := ai.NewTaxonomy(ai.TaxonomyConfig{
+		Priorities: []ai.TaxonomyEntry{{Name: "critical", Labels: []string{"P0"}}, {Name: "high", Labels: []string{"P1"}}, {Name: "low"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.SetTaxonomy(taxonomy)
+	reactions := &fakeReactions{thumbsUp: 2}
+	labeler := &fakeLabeler{}
+	processor.SetReactionBoost(reactions, labeler, BoostConfig{Threshold: 5, Relabel: true})
+
+	// Below the threshold the AI priority stands
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	issueData.Activity = &github.IssueActivity{ThumbsUp: 2}
+	processor.ProcessIssue(context.Background(), issueData)
+	processor.CheckReactions(context.Background())
+	if record, _ := processor.store.GetIssue("owner/repo", 7); record.Summary.Priority != "high" || reactions.fetches != 0 {
+		t.Fatalf("Expected high priority without a boost, got %q after %d fetches", record.Summary.Priority, reactions.fetches)
+	}
+
+	// Crossing it raises the priority, relabels and refreshes the message
+	reactions.thumbsUp = 6
+	processor.CheckReactions(context.Background())
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.Summary.Priority != "critical" || record.Summary.BoostedFrom != "high" {
+		t.Fatalf("Expected a boost from high to critical, got %+v", record.Summary)
+	}
+	if len(notifier.updates) != 1 {
+		t.Errorf("Expected the message to be updated, got %d updates", len(notifier.updates))
+	}
+	if !reflect.DeepEqual(labeler.removed, []string{"P1"}) || !reflect.DeepEqual(labeler.calls, [][]string{{"P0"}}) {
+		t.Errorf("Expected P1 to be swapped for P0, removed %v and added %v", labeler.removed, labeler.calls)
+	}
+	if last := record.Memory[len(record.Memory)-1]; last.Text != "Priority raised from high to critical after 6 👍 reactions" {
+		t.Errorf("Unexpected memory entry %q", last.Text)
+	}
+
+	// Issues are raised once
+	processor.CheckReactions(context.Background())
+	if reactions.fetches != 1 || len(notifier.updates) != 1 {
+		t.Errorf("Expected no second boost, got %d fetches and %d updates", reactions.fetches, len(notifier.updates))
+	}
+}
+
+func TestReactionBoostSkipsClosedIssues(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	reactions := &fakeReactions{thumbsUp: 10}
+	processor.SetReactionBoost(reactions, nil, BoostConfig{Threshold: 5})
+
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorSummarize, "closed", "It crashes"))
+	processor.CheckReactions(context.Background())
+	if reactions.checks != 0 {
+		t.Errorf("Expected closed issues not to be polled, got %d checks", reactions.checks)
+	}
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// SetTaxonomy sets the taxonomy used to read the priority and category of
+// labels humans add to summarized issues, which calibrates summary confidence
+func (p *IssueProcessor) SetTaxonomy(taxonomy *ai.Taxonomy) {
+	p.taxonomy = taxonomy
+}
+
+// issueLabels returns the names of the issue's labels
+func issueLabels(issueData *github.IssueData) []string {
+	labels := make([]string, 0, len(issueData.Issue.Labels))
+	for _, label := range issueData.Issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	return labels
+}
+
+// detectOverride records a human override when labels added since the summary
+// was posted assign a different priority or category. Each summary counts as
+// overridden once, for the fields changed when the override is first seen.
+func (p *IssueProcessor) detectOverride(issueData *github.IssueData, previous *store.IssueRecord) {
+	if p.taxonomy == nil || previous == nil || previous.Summary == nil || len(previous.Overridden) > 0 {
+		return
+	}
+
+	known := make(map[string]bool, len(previous.Labels))
+	for _, label := range previous.Labels {
+		known[strings.ToLower(label)] = true
+	}
+	var added []string
+	for _, label := range issueLabels(issueData) {
+		if !known[strings.ToLower(label)] {
+			added = append(added, label)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+
+	priority, category := p.taxonomy.FromLabels(added)
+	var fields []string
+	if priority != "" && priority != previous.Summary.Priority {
+		fields = append(fields, "priority")
+	}
+	if category != "" && category != previous.Summary.Category {
+		fields = append(fields, "category")
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	previous.Overridden = fields
+	p.store.SaveIssue(previous)
+	p.metrics.RecordSummaryOverride(previous.Summary.Confidence, fields)
+	if p.promptFeedback != nil && previous.Summary.PromptVersion != "" {
+		p.promptFeedback.RecordOverride(previous.Summary.PromptVersion)
+	}
+	p.logger.Info("Summary overridden by labels",
+		zap.String("repository", previous.Repository),
+		zap.Int("issue_number", previous.Number),
+		zap.Strings("fields", fields),
+		zap.Strings("labels", added),
+		zap.Float64("confidence", previous.Summary.Confidence))
+}
+
+// ExplainIssue returns the last summary of an issue, whose reasoning explains
+// its priority and confidence
+func (p *IssueProcessor) ExplainIssue(repository string, number int) (*ai.IssueSummary, bool) {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || record.Summary == nil {
+		return nil, false
+	}
+	return record.Summary, true
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+type calibrationMetrics struct {
+	nopMetrics
+	confidences []float64
+	overrides   [][]string
+}
+
+func (m *calibrationMetrics) RecordSummaryConfidence(confidence float64) {
+	m.confidences = append(m.confidences, confidence)
+}
+
+func (m *calibrationMetrics) RecordSummaryOverride(confidence float64, fields []string) {
+	m.overrides = append(m.overrides, fields)
+}
+
+func labeledIssueData(behavior github.Behavior, labels ...string) *github.IssueData {
+	issueData := newIssueData("labeled", behavior, "open", "It crashes")
+	for _, label := range labels {
+		issueData.Issue.Labels = append(issueData.Issue.Labels, &gogithub.Label{Name: gogithub.String(label)})
+	}
+	return issueData
+}
+
+func TestProcessIssueDetectsOverrides(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	metrics := &calibrationMetrics{}
+	processor.metrics = metrics
+	taxonomy, err := ai.NewTaxonomy(ai.TaxonomyConfig{
+		Priorities: []ai.TaxonomyEntry{{Name: "high", Labels: []string{"P1"}}, {Name: "low", Labels: []string{"P3"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.SetTaxonomy(taxonomy)
+
+	// The fake summarizer answers high priority bug; labels present when the
+	// summary is posted are not overrides
+	processor.ProcessIssue(context.Background(), labeledIssueData(github.BehaviorSummarize, "P3"))
+	if len(metrics.confidences) != 1 {
+		t.Fatalf("Expected the summary's confidence to be recorded, got %v", metrics.confidences)
+	}
+
+	// Labels that agree with the summary are not overrides either
+	processor.ProcessIssue(context.Background(), labeledIssueData(github.BehaviorUpdate, "P3", "P1", "Bug"))
+	if len(metrics.overrides) != 0 {
+		t.Fatalf("Expected no override, got %v", metrics.overrides)
+	}
+
+	processor.ProcessIssue(context.Background(), labeledIssueData(github.BehaviorUpdate, "P3", "P1", "Bug", "documentation"))
+	if len(metrics.overrides) != 1 || len(metrics.overrides[0]) != 1 || metrics.overrides[0][0] != "category" {
+		t.Fatalf("Expected a category override, got %v", metrics.overrides)
+	}
+
+	// A summary is only overridden once, and updates do not record confidence
+	processor.ProcessIssue(context.Background(), labeledIssueData(github.BehaviorUpdate, "P3", "P1", "Bug", "documentation", "enhancement"))
+	if len(metrics.overrides) != 1 || len(metrics.confidences) != 1 {
+		t.Errorf("Expected no further calibration records, got %v and %v", metrics.overrides, metrics.confidences)
+	}
+
+	summary, ok := processor.ExplainIssue("owner/repo", 7)
+	if !ok || summary.Priority != "high" {
+		t.Errorf("Expected the stored summary, got %+v", summary)
+	}
+	if _, ok := processor.ExplainIssue("owner/repo", 8); ok {
+		t.Error("Expected no summary for an unknown issue")
+	}
+}
+
+type fakePromptFeedback struct {
+	overrides []string
+}
+
+func (f *fakePromptFeedback) RecordOverride(version string) {
+	f.overrides = append(f.overrides, version)
+}
+
+func TestProcessIssueReportsPromptOverrides(t *testing.T) {
+	processor, summarizer, _ := newTestProcessor(t)
+	taxonomy, err := ai.NewTaxonomy(ai.TaxonomyConfig{
+		Categories: []ai.TaxonomyEntry{{Name: "documentation", Labels: []string{"documentation"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.SetTaxonomy(taxonomy)
+	feedback := &fakePromptFeedback{}
+	processor.SetPromptFeedback(feedback)
+	summarizer.promptVersion = "canary/2"
+
+	processor.ProcessIssue(context.Background(), labeledIssueData(github.BehaviorSummarize))
+	processor.ProcessIssue(context.Background(), labeledIssueData(github.BehaviorUpdate, "documentation"))
+	if len(feedback.overrides) != 1 || feedback.overrides[0] != "canary/2" {
+		t.Errorf("Expected an override of the canary's summary, got %v", feedback.overrides)
+	}
+}
@@ -0,0 +1,13 @@
+package pipeline
+
+// PromptFeedback learns which summaries humans overrode, to compare the
+// versions of a prompt style under canary
+type PromptFeedback interface {
+	RecordOverride(version string)
+}
+
+// SetPromptFeedback reports overrides of summaries generated during a prompt
+// style canary to feedback
+func (p *IssueProcessor) SetPromptFeedback(feedback PromptFeedback) {
+	p.promptFeedback = feedback
+}
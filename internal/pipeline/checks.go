@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// CheckPublisher shows triage results on the pull requests linked to issues
+type CheckPublisher interface {
+	PublishTriageCheck(ctx context.Context, repo string, number int, check github.TriageCheck) (int, error)
+}
+
+// SetCheckPublisher publishes each new summary, and each change of priority,
+// on the pull requests linked to the issue
+func (p *IssueProcessor) SetCheckPublisher(publisher CheckPublisher) {
+	p.checks = publisher
+}
+
+// publishCheck publishes the summary on the issue's pull requests. Failures
+// are logged; the summary was already posted.
+func (p *IssueProcessor) publishCheck(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) {
+	if p.checks == nil || summary == nil || issueData.Source != "" {
+		return
+	}
+
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	published, err := p.checks.PublishTriageCheck(ctx, repository, number, triageCheck(issueData, summary))
+	if err != nil {
+		p.logger.Warn("Failed to publish triage result",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.Int("published", published),
+			zap.Error(err))
+	}
+}
+
+// triageCheck renders a summary as GitHub markdown
+func triageCheck(issueData *github.IssueData, summary *ai.IssueSummary) github.TriageCheck {
+	title := fmt.Sprintf("%s priority %s", strings.Title(summary.Priority), summary.Category)
+	if summary.Title != "" {
+		title += ": " + summary.Title
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Issue:** #%d %s\n", issueData.Issue.GetNumber(), issueData.Issue.GetTitle())
+	fmt.Fprintf(&b, "**Priority:** %s · **Category:** %s · **Confidence:** %.0f%%\n", summary.Priority, summary.Category, summary.Confidence*100)
+	if summary.Summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", summary.Summary)
+	}
+
+	var text strings.Builder
+	if len(summary.ActionItems) > 0 {
+		text.WriteString("### Action items\n")
+		for _, item := range summary.ActionItems {
+			fmt.Fprintf(&text, "- %s\n", item)
+		}
+	}
+	if fix := strings.TrimSpace(summary.SuggestedFix); fix != "" {
+		if text.Len() > 0 {
+			text.WriteString("\n")
+		}
+		if !strings.Contains(fix, "```") && !ai.FixWithheld(fix) {
+			fix = "```\n" + fix + "\n```"
+		}
+		fmt.Fprintf(&text, "### Suggested fix\n%s\n", fix)
+	}
+
+	return github.TriageCheck{
+		Title:   title,
+		Summary: b.String(),
+		Text:    text.String(),
+		URL:     issueData.Issue.GetHTMLURL(),
+	}
+}
@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeCheckPublisher struct {
+	checks []github.TriageCheck
+}
+
+func (f *fakeCheckPublisher) PublishTriageCheck(ctx context.Context, repo string, number int, check github.TriageCheck) (int, error) {
+	f.checks = append(f.checks, check)
+	return 1, nil
+}
+
+func TestTriageCheck(t *testing.T) {
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	check := triageCheck(issueData, &ai.IssueSummary{
+		Title:        "Startup crash",
+		Summary:      "The server crashes when the config is missing.",
+		Priority:     "high",
+		Category:     "bug",
+		Confidence:   0.8,
+		ActionItems:  []string{"Add a default config"},
+		SuggestedFix: "if cfg == nil { cfg = defaults() }",
+	})
+
+	if check.Title != "High priority bug: Startup crash" {
+		t.Errorf("Unexpected title %q", check.Title)
+	}
+	if !strings.Contains(check.Summary, "**Confidence:** 80%") || !strings.Contains(check.Summary, "config is missing") {
+		t.Errorf("Expected the verdict in the summary, got %q", check.Summary)
+	}
+	if !strings.Contains(check.Text, "- Add a default config") || !strings.Contains(check.Text, "```\nif cfg == nil") {
+		t.Errorf("Expected action items and the fix in the text, got %q", check.Text)
+	}
+}
+
+func TestProcessIssuePublishesChecks(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	publisher := &fakeCheckPublisher{}
+	processor.SetCheckPublisher(publisher)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	// Updates that keep the verdict are not published again
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if len(publisher.checks) != 1 {
+		t.Fatalf("Expected one published check, got %d", len(publisher.checks))
+	}
+
+	escalation := newIssueData("labeled", github.BehaviorEscalate, "open", "It crashes")
+	escalation.Label = "security"
+	processor.ProcessIssue(context.Background(), escalation)
+	if len(publisher.checks) != 2 || !strings.HasPrefix(publisher.checks[1].Title, "Critical") {
+		t.Errorf("Expected the escalation published, got %+v", publisher.checks)
+	}
+}
@@ -0,0 +1,199 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// maxRollupItems caps the issues listed in a rolling message
+const maxRollupItems = 10
+
+// Rollup is the rolling message that stands in for a repository's issues
+// once its rate limit is reached
+type Rollup struct {
+	Channel   string
+	MessageTS string   // Empty until the message has been posted
+	Count     int      // Issues coalesced into the message
+	Items     []string // The most recent issues, oldest first
+}
+
+// repoWindow tracks one repository's messages in the current window
+type repoWindow struct {
+	sent    []time.Time
+	rollups map[string]*Rollup // By channel
+}
+
+// Coalescer limits how many messages each repository posts in a sliding
+// window. Issues beyond the limit are coalesced into one rolling message per
+// channel, so bursts of issues do not flood channels.
+type Coalescer struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	repos  map[string]*repoWindow
+}
+
+// NewCoalescer allows limit messages per repository in each window
+func NewCoalescer(limit int, window time.Duration) *Coalescer {
+	return &Coalescer{
+		limit:  limit,
+		window: window,
+		repos:  make(map[string]*repoWindow),
+	}
+}
+
+// Allow reports whether a repository may post another message, and counts
+// the message if so. Once messages are allowed again, the next burst starts
+// a new rolling message.
+func (c *Coalescer) Allow(repository string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.ToLower(repository)
+	repo, ok := c.repos[key]
+	if !ok {
+		repo = &repoWindow{rollups: make(map[string]*Rollup)}
+		c.repos[key] = repo
+	}
+
+	// Forget messages that have left the window
+	cutoff := now.Add(-c.window)
+	kept := repo.sent[:0]
+	for _, sent := range repo.sent {
+		if sent.After(cutoff) {
+			kept = append(kept, sent)
+		}
+	}
+	repo.sent = kept
+
+	if len(repo.sent) >= c.limit {
+		return false
+	}
+	repo.sent = append(repo.sent, now)
+	repo.rollups = make(map[string]*Rollup)
+	return true
+}
+
+// Add coalesces an issue into the repository's rolling message for a channel
+// and returns a copy of the updated rollup
+func (c *Coalescer) Add(repository, channel, item string) Rollup {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	repo := c.repos[strings.ToLower(repository)]
+	rollup, ok := repo.rollups[channel]
+	if !ok {
+		rollup = &Rollup{Channel: channel}
+		repo.rollups[channel] = rollup
+	}
+	rollup.Count++
+	rollup.Items = append(rollup.Items, item)
+	if len(rollup.Items) > maxRollupItems {
+		rollup.Items = rollup.Items[len(rollup.Items)-maxRollupItems:]
+	}
+
+	copied := *rollup
+	copied.Items = append([]string(nil), rollup.Items...)
+	return copied
+}
+
+// SetMessage records where a rolling message was posted, so later issues
+// update it in place
+func (c *Coalescer) SetMessage(repository, channel, ts string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if repo, ok := c.repos[strings.ToLower(repository)]; ok {
+		if rollup, ok := repo.rollups[channel]; ok {
+			rollup.MessageTS = ts
+		}
+	}
+}
+
+// SetCoalescer rate limits the messages each repository posts, coalescing
+// the rest into a rolling message
+func (p *IssueProcessor) SetCoalescer(coalescer *Coalescer) {
+	p.coalescer = coalescer
+}
+
+// coalesce adds an issue to its repository's rolling message, posting the
+// message for the first issue over the limit and updating it after that
+func (p *IssueProcessor) coalesce(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary, channel string) (string, error) {
+	// Serialize so concurrent issues do not each post a new rolling message
+	p.coalesceMu.Lock()
+	defer p.coalesceMu.Unlock()
+
+	repository := issueData.Repository.GetFullName()
+	title, priority := issueData.Issue.GetTitle(), "not analyzed"
+	if summary != nil {
+		title, priority = summary.Title, summary.Priority
+	}
+	item := fmt.Sprintf("<%s|#%d %s> · %s", issueData.Issue.GetHTMLURL(), issueData.Issue.GetNumber(), title, priority)
+
+	rollup := p.coalescer.Add(repository, channel, item)
+	message := rollupMessage(repository, rollup, p.coalescer.limit, p.coalescer.window)
+	p.metrics.RecordNotificationCoalesced(repository)
+
+	if rollup.MessageTS != "" {
+		return rollup.Channel, p.slack.UpdateIssueSummary(ctx, rollup.Channel, rollup.MessageTS, message)
+	}
+
+	postedChannel, ts, err := p.slack.PostIssueSummary(ctx, channel, message)
+	if err != nil {
+		return "", err
+	}
+	p.coalescer.SetMessage(repository, channel, ts)
+	p.logger.Info("Rate limit reached, coalescing further issues",
+		zap.String("repository", repository),
+		zap.String("channel", postedChannel))
+	return postedChannel, nil
+}
+
+// rollupMessage renders the rolling "N more issues" message
+func rollupMessage(repository string, rollup Rollup, limit int, window time.Duration) map[string]interface{} {
+	noun := "issues"
+	if rollup.Count == 1 {
+		noun = "issue"
+	}
+	lines := []string{fmt.Sprintf(":package: *%d more %s in %s* (limit of %d messages per %s reached)", rollup.Count, noun, repository, limit, formatWindow(window))}
+	if earlier := rollup.Count - len(rollup.Items); earlier > 0 {
+		lines = append(lines, fmt.Sprintf("…and %d earlier", earlier))
+	}
+	for _, item := range rollup.Items {
+		lines = append(lines, "• "+item)
+	}
+
+	return map[string]interface{}{
+		"blocks": []map[string]interface{}{
+			{
+				"type": "section",
+				"text": map[string]interface{}{
+					"type": "mrkdwn",
+					"text": strings.Join(lines, "\n"),
+				},
+			},
+		},
+	}
+}
+
+// formatWindow describes a rate limit window in words, e.g. "hour" or "30 minutes"
+func formatWindow(window time.Duration) string {
+	switch {
+	case window == time.Hour:
+		return "hour"
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%d hours", window/time.Hour)
+	case window%time.Minute == 0:
+		return fmt.Sprintf("%d minutes", window/time.Minute)
+	default:
+		return window.String()
+	}
+}
@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+func TestCoalescerSlidingWindow(t *testing.T) {
+	coalescer := NewCoalescer(2, time.Hour)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if !coalescer.Allow("owner/repo", start) || !coalescer.Allow("Owner/Repo", start.Add(10*time.Minute)) {
+		t.Fatal("Expected the first two messages to be allowed")
+	}
+	if coalescer.Allow("owner/repo", start.Add(20*time.Minute)) {
+		t.Error("Expected the third message in the hour to be coalesced")
+	}
+	if !coalescer.Allow("other/repo", start.Add(20*time.Minute)) {
+		t.Error("Expected other repositories to have their own limit")
+	}
+
+	rollup := coalescer.Add("owner/repo", "C1", "#3")
+	coalescer.SetMessage("owner/repo", "C1", "1.0")
+	rollup = coalescer.Add("owner/repo", "C1", "#4")
+	if rollup.Count != 2 || rollup.MessageTS != "1.0" || len(rollup.Items) != 2 {
+		t.Errorf("Expected both issues in the posted rollup, got %+v", rollup)
+	}
+
+	// The first message leaves the window, which starts a new rollup next time
+	if !coalescer.Allow("owner/repo", start.Add(61*time.Minute)) {
+		t.Fatal("Expected a message once the first one left the window")
+	}
+	if coalescer.Allow("owner/repo", start.Add(62*time.Minute)) {
+		t.Fatal("Expected the limit to apply again")
+	}
+	if rollup := coalescer.Add("owner/repo", "C1", "#6"); rollup.Count != 1 || rollup.MessageTS != "" {
+		t.Errorf("Expected a new rollup after the window reset, got %+v", rollup)
+	}
+}
+
+func TestRollupMessageListsLatestIssues(t *testing.T) {
+	rollup := Rollup{Count: 12}
+	for i := 3; i <= 12; i++ {
+		rollup.Items = append(rollup.Items, "item")
+	}
+	text := rollupMessage("owner/repo", rollup, 10, time.Hour)["blocks"].([]map[string]interface{})[0]["text"].(map[string]interface{})["text"].(string)
+
+	if !strings.HasPrefix(text, ":package: *12 more issues in owner/repo* (limit of 10 messages per hour reached)\n…and 2 earlier\n• item") {
+		t.Errorf("Unexpected rollup text %q", text)
+	}
+	if formatWindow(30*time.Minute) != "30 minutes" || formatWindow(2*time.Hour) != "2 hours" {
+		t.Error("Unexpected window formatting")
+	}
+}
+
+func TestProcessIssueCoalescing(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	processor.SetCoalescer(NewCoalescer(1, time.Hour))
+
+	for number := 1; number <= 3; number++ {
+		issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+		issueData.Issue.Number = gogithub.Int(number)
+		processor.ProcessIssue(context.Background(), issueData)
+	}
+
+	// One normal message, then one rolling message that is updated in place
+	if len(notifier.posts) != 2 || len(notifier.updates) != 1 {
+		t.Fatalf("Expected 2 posts and 1 update, got %d and %d", len(notifier.posts), len(notifier.updates))
+	}
+	text := notifier.updates[0]["blocks"].([]map[string]interface{})[0]["text"].(map[string]interface{})["text"].(string)
+	if !strings.HasPrefix(text, ":package: *2 more issues in owner/repo*") {
+		t.Errorf("Unexpected rolling message %q", text)
+	}
+
+	// Closing a coalesced issue has no message of its own to update
+	closed := newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes")
+	closed.Issue.Number = gogithub.Int(3)
+	processor.ProcessIssue(context.Background(), closed)
+	if len(notifier.posts) != 2 || len(notifier.updates) != 1 {
+		t.Errorf("Expected no message for closing a coalesced issue, got %d posts and %d updates", len(notifier.posts), len(notifier.updates))
+	}
+}
@@ -0,0 +1,141 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// CommandAcknowledger reacts to the comments commands came from
+type CommandAcknowledger interface {
+	ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error
+}
+
+// FixSuggester generates fix suggestions for the fix command
+type FixSuggester interface {
+	StreamSuggestedFix(ctx context.Context, issueData *github.IssueData, onUpdate func(text string)) (string, error)
+}
+
+// SetCommands runs commands from issue comments. Fix suggestions are posted
+// in the issue's Slack thread.
+func (p *IssueProcessor) SetCommands(acknowledger CommandAcknowledger, fixer FixSuggester, replies ThreadNotifier) {
+	p.acknowledger = acknowledger
+	p.fixer = fixer
+	p.commandReplies = replies
+}
+
+// runCommand runs an issue comment's command and reacts to the comment with
+// whether it worked
+func (p *IssueProcessor) runCommand(ctx context.Context, issueData *github.IssueData) {
+	command := issueData.Command
+	var ok bool
+	switch command.Name {
+	case github.CommandSummarize:
+		ok = p.processIssue(ctx, issueData)
+	case github.CommandPriority:
+		ok = p.setPriority(ctx, issueData, command.Args[0], "@"+command.Author) && p.processIssue(ctx, issueData)
+	case github.CommandFix:
+		ok = p.suggestFix(ctx, issueData, command.Author)
+	}
+
+	p.logger.Info("Ran issue comment command",
+		zap.String("repository", issueData.Repository.GetFullName()),
+		zap.Int("issue_number", issueData.Issue.GetNumber()),
+		zap.String("command", command.Name),
+		zap.String("author", command.Author),
+		zap.Bool("ok", ok))
+	if p.acknowledger == nil {
+		return
+	}
+	reaction := github.ReactionDone
+	if !ok {
+		reaction = github.ReactionFailed
+	}
+	if err := p.acknowledger.ReactToComment(ctx, issueData.Repository.GetFullName(), command.CommentID, reaction); err != nil {
+		p.logger.Warn("Failed to acknowledge command", zap.Error(err))
+	}
+}
+
+// setPriority overrides the priority of an issue's last summary, which the
+// update behavior then shows in Slack. by names who set it, e.g. @octocat.
+func (p *IssueProcessor) setPriority(ctx context.Context, issueData *github.IssueData, answer, by string) bool {
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || record.Summary == nil {
+		p.logger.Info("No summary to set the priority of",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number))
+		return false
+	}
+
+	entry, ok := p.priorities().Priority(answer)
+	if !ok {
+		p.logger.Info("Unknown priority in command",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.String("priority", answer))
+		return false
+	}
+
+	summary := *record.Summary
+	from := summary.Priority
+	summary.Priority, summary.BoostedFrom = entry.Name, ""
+	record.Summary = &summary
+	if from != entry.Name && len(record.Overridden) == 0 {
+		record.Overridden = []string{"priority"}
+		p.metrics.RecordSummaryOverride(summary.Confidence, record.Overridden)
+	}
+	record.Memory = record.Memory.Append(p.memoryTokens, memory.Entry{
+		Kind: memory.KindSummary,
+		Text: fmt.Sprintf("Priority set from %s to %s by %s", from, entry.Name, by),
+		At:   time.Now(),
+	})
+	record.UpdatedAt = time.Now()
+	p.store.SaveIssue(record)
+	p.relabel(ctx, issueData, from, entry.Name)
+	if from != entry.Name {
+		p.publishCheck(ctx, issueData, &summary)
+	}
+	return true
+}
+
+// suggestFix posts a fix suggestion in the Slack thread of a posted issue
+func (p *IssueProcessor) suggestFix(ctx context.Context, issueData *github.IssueData, author string) bool {
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	record, ok := p.store.GetIssue(repository, number)
+	if p.fixer == nil || p.commandReplies == nil || !ok || record.MessageTS == "" {
+		p.logger.Info("No posted message to suggest a fix in",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number))
+		return false
+	}
+	issueData.Memory = record.Memory
+
+	aiCtx, done := p.stageContext(ctx, monitor.StageSummarize, p.aiTimeout)
+	fix, err := p.fixer.StreamSuggestedFix(aiCtx, issueData, nil)
+	done()
+	if err != nil {
+		p.logger.Error("Failed to suggest a fix", zap.Error(err))
+		return false
+	}
+
+	slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+	err = p.commandReplies.PostThreadReply(slackCtx, record.Channel, record.MessageTS,
+		fmt.Sprintf("_Requested by @%s on GitHub_\n%s", author, ai.FormatSuggestedFix(fix)))
+	done()
+	if err != nil {
+		p.logger.Error("Failed to post fix suggestion", zap.Error(err))
+		return false
+	}
+	p.RememberFollowUp(repository, number, "Suggest a fix", fix)
+	return true
+}
@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeAcknowledger struct {
+	reactions []string
+}
+
+func (f *fakeAcknowledger) ReactToComment(ctx context.Context, repo string, commentID int64, reaction string) error {
+	f.reactions = append(f.reactions, reaction)
+	return nil
+}
+
+type fakeFixer struct{}
+
+func (fakeFixer) StreamSuggestedFix(ctx context.Context, issueData *github.IssueData, onUpdate func(text string)) (string, error) {
+	return "Check the config path", nil
+}
+
+func newCommandIssueData(behavior github.Behavior, name string, args ...string) *github.IssueData {
+	issueData := newIssueData("created", behavior, "open", "It crashes")
+	issueData.EventType = "issue_comment"
+	issueData.Command = &github.Command{Name: name, Args: args, Author: "octocat", CommentID: 42}
+	return issueData
+}
+
+func TestRunCommandPriority(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	ack := &fakeAcknowledger{}
+	processor.SetCommands(ack, fakeFixer{}, &fakeReplies{})
+
+	// Nothing to override before the issue was summarized
+	processor.ProcessIssue(context.Background(), newCommandIssueData(github.BehaviorUpdate, github.CommandPriority, "low"))
+	if len(ack.reactions) != 1 || ack.reactions[0] != github.ReactionFailed {
+		t.Fatalf("Expected a failed reaction, got %v", ack.reactions)
+	}
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	processor.ProcessIssue(context.Background(), newCommandIssueData(github.BehaviorUpdate, github.CommandPriority, "Low"))
+	if summarizer.calls != 1 || len(notifier.updates) != 1 {
+		t.Fatalf("Expected the message updated without a summary, got %d summaries and %d updates", summarizer.calls, len(notifier.updates))
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.Summary.Priority != "low" || len(record.Overridden) != 1 {
+		t.Errorf("Expected an overridden low priority, got %+v", record)
+	}
+	if len(record.Memory) == 0 || !strings.Contains(record.Memory[len(record.Memory)-1].Text, "@octocat") {
+		t.Errorf("Expected the change in the issue memory, got %+v", record.Memory)
+	}
+	if len(ack.reactions) != 2 || ack.reactions[1] != github.ReactionDone {
+		t.Errorf("Expected a done reaction, got %v", ack.reactions)
+	}
+
+	processor.ProcessIssue(context.Background(), newCommandIssueData(github.BehaviorUpdate, github.CommandPriority, "whenever"))
+	if ack.reactions[2] != github.ReactionFailed {
+		t.Errorf("Expected unknown priorities to fail, got %v", ack.reactions)
+	}
+}
+
+func TestRunCommandSummarize(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	ack := &fakeAcknowledger{}
+	processor.SetCommands(ack, fakeFixer{}, &fakeReplies{})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	// An unchanged issue is summarized again when asked to
+	processor.ProcessIssue(context.Background(), newCommandIssueData(github.BehaviorResummarize, github.CommandSummarize))
+	if summarizer.calls != 2 || len(notifier.updates) != 1 {
+		t.Errorf("Expected a new summary, got %d summaries and %d updates", summarizer.calls, len(notifier.updates))
+	}
+	if len(ack.reactions) != 1 || ack.reactions[0] != github.ReactionDone {
+		t.Errorf("Expected a done reaction, got %v", ack.reactions)
+	}
+}
+
+func TestRunCommandFix(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	ack := &fakeAcknowledger{}
+	replies := &fakeReplies{}
+	processor.SetCommands(ack, fakeFixer{}, replies)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	processor.ProcessIssue(context.Background(), newCommandIssueData(github.BehaviorIgnore, github.CommandFix))
+	if len(replies.replies) != 1 || !strings.Contains(replies.replies[0], "Check the config path") {
+		t.Fatalf("Expected the fix in the thread, got %q", replies.replies)
+	}
+	if len(ack.reactions) != 1 || ack.reactions[0] != github.ReactionDone {
+		t.Errorf("Expected a done reaction, got %v", ack.reactions)
+	}
+}
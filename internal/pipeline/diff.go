@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultChangeThreshold is the significance score at which an edit is re-summarized
+const DefaultChangeThreshold = 0.15
+
+// maxTypoPairs bounds the typo matching work for very large edits
+const maxTypoPairs = 200
+
+var (
+	codeFencePattern = regexp.MustCompile("(?s)```.*?```")
+	stepPattern      = regexp.MustCompile(`(?m)^\s*(?:\d+[.)]|[-*])\s+\S.*$`)
+)
+
+// ChangeSignificance describes how much an issue edit changed
+type ChangeSignificance struct {
+	Score         float64 // Overall significance between 0 and 1
+	ChangedRatio  float64 // Fraction of title and body words added or removed, ignoring typo fixes
+	NewCodeBlocks int     // Fenced code blocks that were not in the previous version
+	NewSteps      int     // Numbered or bulleted lines, e.g. repro steps, that were not in the previous version
+}
+
+// Material reports whether the change is large enough to re-run the AI
+func (c ChangeSignificance) Material(threshold float64) bool {
+	return c.Score >= threshold
+}
+
+// CompareIssue scores an edit of an issue's title and body against the previous version
+func CompareIssue(oldTitle, oldBody, newTitle, newBody string) ChangeSignificance {
+	var change ChangeSignificance
+
+	oldWords := strings.Fields(strings.ToLower(oldTitle + " " + oldBody))
+	newWords := strings.Fields(strings.ToLower(newTitle + " " + newBody))
+	if total := len(oldWords) + len(newWords); total > 0 {
+		change.ChangedRatio = float64(changedWords(oldWords, newWords)) / float64(total)
+	}
+
+	change.NewCodeBlocks = countNew(codeFencePattern.FindAllString(oldBody, -1), codeFencePattern.FindAllString(newBody, -1))
+	change.NewSteps = countNew(stepPattern.FindAllString(oldBody, -1), stepPattern.FindAllString(newBody, -1))
+
+	// Replacing a word counts on both sides, so weight the ratio back up
+	change.Score = change.ChangedRatio * 2
+	if change.NewCodeBlocks > 0 {
+		change.Score += 0.5
+	}
+	if change.NewSteps > 0 {
+		change.Score += 0.25
+	}
+	if change.Score > 1 {
+		change.Score = 1
+	}
+	return change
+}
+
+// changedWords counts words added or removed between two versions. A removed
+// word that is replaced by a close spelling counts as a typo fix, not a change.
+func changedWords(oldWords, newWords []string) int {
+	counts := make(map[string]int, len(oldWords))
+	for _, w := range oldWords {
+		counts[w]++
+	}
+	for _, w := range newWords {
+		counts[w]--
+	}
+
+	var removed, added []string
+	for w, n := range counts {
+		for ; n > 0; n-- {
+			removed = append(removed, w)
+		}
+		for ; n < 0; n++ {
+			added = append(added, w)
+		}
+	}
+
+	changed := len(removed) + len(added)
+	if len(removed) > maxTypoPairs || len(added) > maxTypoPairs {
+		return changed
+	}
+
+	used := make([]bool, len(added))
+	for _, r := range removed {
+		for i, a := range added {
+			if !used[i] && isTypo(r, a) {
+				used[i] = true
+				changed -= 2
+				break
+			}
+		}
+	}
+	return changed
+}
+
+// isTypo reports whether two words differ by a small edit
+func isTypo(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < 4 || len(rb) < 4 {
+		return false
+	}
+	return editDistance(ra, rb) <= 2
+}
+
+// editDistance is the Levenshtein distance between two words
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// countNew counts entries of next that do not appear in prev
+func countNew(prev, next []string) int {
+	seen := make(map[string]int, len(prev))
+	for _, s := range prev {
+		seen[strings.TrimSpace(s)]++
+	}
+	n := 0
+	for _, s := range next {
+		key := strings.TrimSpace(s)
+		if seen[key] > 0 {
+			seen[key]--
+			continue
+		}
+		n++
+	}
+	return n
+}
@@ -0,0 +1,55 @@
+package pipeline
+
+import "testing"
+
+func TestCompareIssue(t *testing.T) {
+	body := "The bot crashes when the webhook payload has no repository field. " +
+		"It happens on every delivery from our staging organisation and the pod restarts."
+
+	tests := []struct {
+		name     string
+		newTitle string
+		newBody  string
+		material bool
+	}{
+		{
+			name:     "unchanged",
+			newTitle: "Webhook crash",
+			newBody:  body,
+			material: false,
+		},
+		{
+			name:     "typo fix",
+			newTitle: "Webhook crash",
+			newBody:  "The bot crashes when the webhook paylaod has no repository feild. " + body[66:],
+			material: false,
+		},
+		{
+			name:     "new code block",
+			newTitle: "Webhook crash",
+			newBody:  body + "\n```\npanic: nil pointer dereference\n```",
+			material: true,
+		},
+		{
+			name:     "new repro steps",
+			newTitle: "Webhook crash",
+			newBody:  body + "\n1. Send a ping event\n2. Watch the logs",
+			material: true,
+		},
+		{
+			name:     "rewritten",
+			newTitle: "Pod restarts on ping deliveries",
+			newBody:  "Ping deliveries have no issue, so enrichment dereferences nil and the process exits.",
+			material: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := CompareIssue("Webhook crash", body, tt.newTitle, tt.newBody)
+			if change.Material(DefaultChangeThreshold) != tt.material {
+				t.Errorf("Expected material=%v, got %+v", tt.material, change)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// SetTriage lets team digests acknowledge, snooze and escalate the issues
+// they list. fetcher reads escalated issues again to update their message.
+func (p *IssueProcessor) SetTriage(fetcher IssueFetcher) {
+	p.triageFetcher = fetcher
+}
+
+// carryTriage keeps whether an issue was acknowledged or snoozed from a
+// digest, and who assigned and closed it, across the record's updates
+func carryTriage(record, previous *store.IssueRecord) {
+	if previous == nil {
+		return
+	}
+	record.TriagedAt, record.TriagedBy = previous.TriagedAt, previous.TriagedBy
+	record.SnoozedUntil = previous.SnoozedUntil
+	record.AssignedAt, record.AssignedBy = previous.AssignedAt, previous.AssignedBy
+	if record.State == "closed" {
+		record.ClosedBy = previous.ClosedBy
+	}
+}
+
+// creditTriage records who first assigned an issue and who closed it, from
+// the GitHub event being processed. Bots are not credited; an issue a bot
+// assigned, e.g. from Slack's "Assign to me", is credited to its assignee.
+func creditTriage(record *store.IssueRecord, issueData *github.IssueData) {
+	if issueData.EventType != "issues" {
+		return
+	}
+	sender := issueData.Sender
+	if strings.HasSuffix(sender, "[bot]") {
+		sender = ""
+	}
+	switch issueData.Action {
+	case "assigned":
+		if !record.AssignedAt.IsZero() {
+			return
+		}
+		if sender == "" && len(issueData.Issue.Assignees) > 0 {
+			sender = issueData.Issue.Assignees[0].GetLogin()
+		}
+		if sender == "" {
+			return
+		}
+		record.AssignedAt, record.AssignedBy = issueData.ReceivedAt, sender
+		if record.AssignedAt.IsZero() {
+			record.AssignedAt = time.Now()
+		}
+	case "closed":
+		if sender != "" && record.State == "closed" {
+			record.ClosedBy = sender
+		}
+	}
+}
+
+// AcknowledgeIssue marks an open issue as triaged by a Slack user, which
+// keeps it out of later digests and stops its engagement escalation. It
+// reports false for issues already acknowledged.
+func (p *IssueProcessor) AcknowledgeIssue(repository string, number int, userID string) bool {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || record.State != "open" || !record.TriagedAt.IsZero() {
+		return false
+	}
+	now := time.Now()
+	record.TriagedAt, record.TriagedBy = now, userID
+	if record.EngagedAt.IsZero() {
+		record.EngagedAt = now
+	}
+	p.store.SaveIssue(record)
+	p.logger.Info("Acknowledged issue from digest",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("user_id", userID))
+	return true
+}
+
+// SnoozeIssue keeps an open issue out of digests and engagement escalation
+// until the given time
+func (p *IssueProcessor) SnoozeIssue(repository string, number int, until time.Time, userID string) bool {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || record.State != "open" {
+		return false
+	}
+	record.SnoozedUntil = until
+	p.store.SaveIssue(record)
+	p.logger.Info("Snoozed issue from digest",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.Time("until", until),
+		zap.String("user_id", userID))
+	return true
+}
+
+// EscalateIssue raises an issue to the highest priority, like the priority
+// command, updates its Slack message and marks it triaged. It returns the
+// new priority.
+func (p *IssueProcessor) EscalateIssue(ctx context.Context, repository string, number int, userID string) (string, error) {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || record.Summary == nil || record.MessageTS == "" {
+		return "", fmt.Errorf("%s#%d has no posted summary", repository, number)
+	}
+	if p.triageFetcher == nil {
+		return "", fmt.Errorf("digest triage is not enabled")
+	}
+	top := p.priorities().Priorities()[0].Name
+	if strings.EqualFold(record.Summary.Priority, top) {
+		p.AcknowledgeIssue(repository, number, userID)
+		return top, nil
+	}
+
+	issueData, err := p.triageFetcher.FetchEnrichedIssueData(ctx, repository, number)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch issue: %w", err)
+	}
+	issueData.EventType, issueData.Action, issueData.Behavior = "issues", "edited", github.BehaviorUpdate
+	ctx, finish := p.trackInFlight(ctx, issueData)
+	defer finish()
+	if !p.setPriority(ctx, issueData, top, fmt.Sprintf("<@%s> from a Slack digest", userID)) || !p.processIssue(ctx, issueData) {
+		return "", fmt.Errorf("failed to escalate %s#%d", repository, number)
+	}
+	p.AcknowledgeIssue(repository, number, userID)
+	return top, nil
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+func TestAcknowledgeIssue(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	if !processor.AcknowledgeIssue("owner/repo", 7, "U999") || processor.AcknowledgeIssue("owner/repo", 7, "U999") {
+		t.Error("Expected the issue acknowledged once")
+	}
+	if processor.AcknowledgeIssue("owner/repo", 8, "U999") {
+		t.Error("Expected an unknown issue not acknowledged")
+	}
+
+	// Acknowledging survives updates and counts as engagement
+	processor.ProcessIssue(context.Background(), newIssueData("edited", github.BehaviorUpdate, "open", "It crashes"))
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.TriagedAt.IsZero() || record.TriagedBy != "U999" || record.EngagedAt.IsZero() {
+		t.Errorf("Expected the issue triaged and engaged by U999, got %+v", record)
+	}
+}
+
+func TestSnoozeIssue(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	processor.SetEngagement(nil, EngagementConfig{Enabled: true, Window: time.Hour, Priority: "high", Channel: "C-ESC"})
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	later := time.Now().Add(2 * time.Hour)
+	if !processor.SnoozeIssue("owner/repo", 7, later.Add(time.Hour), "U999") {
+		t.Fatal("Expected the issue snoozed")
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	config := processor.engagementFor(record.Route)
+	if engagementDue(*record, config, later) {
+		t.Error("Expected a snoozed issue not escalated")
+	}
+	if !engagementDue(*record, config, later.Add(2*time.Hour)) {
+		t.Error("Expected the issue escalated once the snooze is over")
+	}
+}
+
+func TestEscalateIssue(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if _, err := processor.EscalateIssue(context.Background(), "owner/repo", 7, "U999"); err == nil {
+		t.Error("Expected an error without a fetcher")
+	}
+	processor.SetTriage(&fakeFetcher{state: "open"})
+
+	priority, err := processor.EscalateIssue(context.Background(), "owner/repo", 7, "U999")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if priority != "critical" || record.Summary.Priority != "critical" || record.TriagedBy != "U999" {
+		t.Errorf("Expected the issue raised to critical and triaged, got %q and %+v", priority, record)
+	}
+	if len(notifier.updates) != 1 {
+		t.Errorf("Expected the posted message updated, got %d updates", len(notifier.updates))
+	}
+	if entries := record.Memory; len(entries) == 0 || !strings.Contains(entries[len(entries)-1].Text, "by <@U999> from a Slack digest") {
+		t.Errorf("Expected the escalation noted in the issue's memory, got %+v", entries)
+	}
+	if _, err := processor.EscalateIssue(context.Background(), "owner/repo", 8, "U999"); err == nil {
+		t.Error("Expected an error for an issue that was never posted")
+	}
+}
+
+func TestCreditTriage(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	// Assigned by a bot, e.g. from Slack: the assignee is credited
+	assigned := newIssueData("assigned", github.BehaviorUpdate, "open", "It crashes")
+	assigned.Sender = "notifyops[bot]"
+	assigned.Issue.Assignees = []*gogithub.User{{Login: gogithub.String("ana")}}
+	processor.ProcessIssue(context.Background(), assigned)
+	// Only the first assignment counts
+	reassigned := newIssueData("assigned", github.BehaviorUpdate, "open", "It crashes")
+	reassigned.Sender = "bo"
+	processor.ProcessIssue(context.Background(), reassigned)
+
+	closed := newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes")
+	closed.Sender = "bo"
+	closed.Issue.ClosedAt = &gogithub.Timestamp{Time: time.Now()}
+	processor.ProcessIssue(context.Background(), closed)
+	processor.ProcessIssue(context.Background(), newIssueData("labeled", github.BehaviorUpdate, "closed", "It crashes"))
+
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.AssignedBy != "ana" || record.AssignedAt.IsZero() || record.ClosedBy != "bo" {
+		t.Errorf("Expected ana credited with assigning and bo with closing, got %q and %q", record.AssignedBy, record.ClosedBy)
+	}
+
+	processor.ProcessIssue(context.Background(), newIssueData("reopened", github.BehaviorUpdate, "open", "It crashes"))
+	if record, _ := processor.store.GetIssue("owner/repo", 7); record.ClosedBy != "" {
+		t.Errorf("Expected a reopened issue's closer forgotten, got %q", record.ClosedBy)
+	}
+}
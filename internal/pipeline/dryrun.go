@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// DryRun is what processing an issue would post, without posting it
+type DryRun struct {
+	Number  int                    `json:"number"`
+	Title   string                 `json:"title"`
+	Summary *ai.IssueSummary       `json:"summary"`
+	Rule    string                 `json:"rule,omitempty"` // Routing rule that matched, empty for the default route
+	Channel string                 `json:"channel"`
+	Layout  string                 `json:"layout"`
+	Message map[string]interface{} `json:"message"` // Slack message, as it would be posted
+}
+
+// DryRun summarizes and routes an issue like a new issue would be, but
+// stores, posts and labels nothing. Used to check a repository end to end.
+func (p *IssueProcessor) DryRun(ctx context.Context, issueData *github.IssueData) (*DryRun, error) {
+	repository := issueData.Repository.GetFullName()
+	summary, err := p.summarize(ctx, issueData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize issue: %w", err)
+	}
+	summary.Components = p.detectComponents(issueData)
+	summary = p.postProcess(repository, summary)
+
+	route := p.router.Route(routing.Issue{
+		Repository: repository,
+		Title:      issueData.Issue.GetTitle(),
+		Author:     issueData.Issue.GetUser().GetLogin(),
+		Priority:   summary.Priority,
+		Category:   summary.Category,
+		Labels:     issueLabels(issueData),
+		Components: summary.Components,
+	})
+	layout := p.channelLayout(route.Channel, route.Layout)
+
+	var message map[string]interface{}
+	switch layout {
+	case routing.LayoutCompact:
+		message = p.summarizer.GenerateCompactSlackMessage(issueData, summary)
+	case routing.LayoutPlain:
+		message = p.summarizer.GeneratePlainSlackMessage(issueData, summary)
+	default:
+		message = p.summarizer.GenerateSlackMessage(issueData, summary)
+	}
+	message = p.withoutEmoji(message, route.Channel)
+	return &DryRun{
+		Number:  issueData.Issue.GetNumber(),
+		Title:   issueData.Issue.GetTitle(),
+		Summary: summary,
+		Rule:    route.Rule,
+		Channel: route.Channel,
+		Layout:  layout,
+		Message: message,
+	}, nil
+}
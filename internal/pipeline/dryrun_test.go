@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+)
+
+func TestDryRun(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+
+	dryRun, err := processor.DryRun(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dryRun.Number != 7 || dryRun.Summary.Priority != "high" || dryRun.Channel != "C123" || dryRun.Rule != "compact" || dryRun.Layout != routing.LayoutCompact {
+		t.Errorf("Expected the issue summarized and routed, got %+v", dryRun)
+	}
+	if dryRun.Message["layout"] != routing.LayoutCompact {
+		t.Errorf("Expected the compact message, got %v", dryRun.Message)
+	}
+	if _, ok := processor.store.GetIssue("owner/repo", 7); ok || len(notifier.posts) != 0 {
+		t.Error("Expected nothing stored or posted")
+	}
+
+	summarizer.err = errors.New("boom")
+	if _, err := processor.DryRun(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")); err == nil {
+		t.Error("Expected the summary error")
+	}
+}
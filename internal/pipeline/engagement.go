@@ -0,0 +1,235 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/store"
+)
+
+// engagementCheckInterval is how often posted issues are checked for
+// engagement
+const engagementCheckInterval = 10 * time.Minute
+
+// EngagementNotifier reads whether anyone reacted to or replied in the thread
+// of a posted Slack message, and posts escalations
+type EngagementNotifier interface {
+	MessageEngaged(ctx context.Context, channelID, ts string) (bool, error)
+	PostMessage(ctx context.Context, channelID, kind, text string) error
+}
+
+// EngagementConfig escalates issues whose Slack message nobody engaged with.
+// Routing rules may override everything but Enabled for their issues.
+type EngagementConfig struct {
+	Enabled  bool
+	Window   time.Duration // How long after posting a message needs engagement
+	Priority string        // Lowest priority escalated
+	Channel  string        // Secondary channel escalations are posted to, empty for none
+	DM       string        // Slack user ID escalations are sent to directly, empty for none
+}
+
+// Validate checks the escalation window and targets
+func (c EngagementConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("escalation window must be positive")
+	}
+	if !ai.PriorityAtLeast(c.Priority, "low") {
+		return fmt.Errorf("invalid escalation priority %q", c.Priority)
+	}
+	if c.DM != "" && !routing.ValidSlackUser(c.DM) {
+		return fmt.Errorf("escalation DM must be a Slack user ID, got %q", c.DM)
+	}
+	return nil
+}
+
+// SetEngagement escalates open issues at or above config.Priority whose
+// Slack message nobody clicked, reacted or replied to, and that no maintainer
+// responded to on GitHub, within config.Window of posting
+func (p *IssueProcessor) SetEngagement(notifier EngagementNotifier, config EngagementConfig) {
+	p.engagement = notifier
+	p.engagementConfig = config
+}
+
+// engagementFor returns the escalation settings of issues routed by rule
+func (p *IssueProcessor) engagementFor(rule string) EngagementConfig {
+	config := p.engagementConfig
+	overrides := p.router.Escalation(rule)
+	if overrides.AfterHours > 0 {
+		config.Window = time.Duration(overrides.AfterHours) * time.Hour
+	}
+	if overrides.Priority != "" {
+		config.Priority = overrides.Priority
+	}
+	if overrides.Channel != "" {
+		config.Channel = overrides.Channel
+	}
+	if overrides.DM != "" {
+		config.DM = overrides.DM
+	}
+	return config
+}
+
+// carryEngagement keeps when an issue's message was posted, engaged with and
+// escalated across the record's updates. A message posted anew restarts the
+// window but keeps the escalation, so an issue is escalated once.
+func carryEngagement(record, previous *store.IssueRecord) {
+	if record.MessageTS == "" {
+		return
+	}
+	record.PostedAt = time.Now()
+	if previous == nil {
+		return
+	}
+	if previous.MessageTS == record.MessageTS && !previous.PostedAt.IsZero() {
+		record.PostedAt = previous.PostedAt
+	}
+	record.EngagedAt, record.EscalatedAt = previous.EngagedAt, previous.EscalatedAt
+}
+
+// RecordEngagement notes that a Slack user clicked a button on a posted
+// message
+func (p *IssueProcessor) RecordEngagement(channelID, ts, userID string) {
+	records, _ := p.store.ListIssues(store.Query{})
+	for _, record := range records {
+		if record.Channel != channelID || record.MessageTS != ts {
+			continue
+		}
+		if p.markEngaged(record.Repository, record.Number, ts, time.Now()) {
+			p.logger.Info("Recorded engagement with issue message",
+				zap.String("repository", record.Repository),
+				zap.Int("issue_number", record.Number),
+				zap.String("user_id", userID))
+		}
+		return
+	}
+}
+
+// markEngaged saves when the issue's message was first engaged with, unless
+// it was already or the issue has since been posted anew
+func (p *IssueProcessor) markEngaged(repository string, number int, ts string, at time.Time) bool {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || record.MessageTS != ts || !record.EngagedAt.IsZero() {
+		return false
+	}
+	record.EngagedAt = at
+	p.store.SaveIssue(record)
+	return true
+}
+
+// RunEngagement checks posted issues for engagement until the context is
+// cancelled
+func (p *IssueProcessor) RunEngagement(ctx context.Context) {
+	if p.engagement == nil || !p.engagementConfig.Enabled {
+		return
+	}
+	every(ctx, engagementCheckInterval, p.CheckEngagement)
+}
+
+// CheckEngagement escalates every open issue whose message went without
+// engagement for its window. Reactions and replies are read from Slack only
+// once the window is up; failures are retried at the next check.
+func (p *IssueProcessor) CheckEngagement(ctx context.Context) {
+	records, _ := p.store.ListIssues(store.Query{})
+	now := time.Now()
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return
+		}
+		config := p.engagementFor(record.Route)
+		if !engagementDue(record, config, now) {
+			continue
+		}
+		if !record.AcknowledgedAt.IsZero() {
+			p.markEngaged(record.Repository, record.Number, record.MessageTS, record.AcknowledgedAt)
+			continue
+		}
+
+		slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+		engaged, err := p.engagement.MessageEngaged(slackCtx, record.Channel, record.MessageTS)
+		done()
+		if err != nil {
+			p.logger.Warn("Failed to read engagement with issue message",
+				zap.String("repository", record.Repository),
+				zap.Int("issue_number", record.Number),
+				zap.Error(err))
+			continue
+		}
+		if engaged {
+			p.markEngaged(record.Repository, record.Number, record.MessageTS, now)
+			continue
+		}
+		p.escalateUnengaged(ctx, record, config, now)
+	}
+}
+
+// engagementDue reports whether an open posted issue at or above the
+// configured priority has gone without engagement for its window, and is not
+// snoozed
+func engagementDue(record store.IssueRecord, config EngagementConfig, now time.Time) bool {
+	if config.Channel == "" && config.DM == "" {
+		return false
+	}
+	return record.State == "open" && record.Source == "" && record.MessageTS != "" && record.Summary != nil &&
+		ai.PriorityAtLeast(record.Summary.Priority, config.Priority) &&
+		!record.PostedAt.IsZero() && record.EngagedAt.IsZero() && record.EscalatedAt.IsZero() &&
+		now.Sub(record.PostedAt) >= config.Window && !record.SnoozedUntil.After(now)
+}
+
+// escalateUnengaged posts the issue to the secondary channel and messages
+// the repository owner. It is saved as escalated once either succeeds.
+func (p *IssueProcessor) escalateUnengaged(ctx context.Context, record store.IssueRecord, config EngagementConfig, now time.Time) {
+	text := unengagedText(record, now.Sub(record.PostedAt))
+	escalated := false
+	for _, target := range []string{config.Channel, config.DM} {
+		if target == "" || target == record.Channel {
+			continue
+		}
+		slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+		err := p.engagement.PostMessage(slackCtx, target, "escalation", text)
+		done()
+		if err != nil {
+			p.logger.Warn("Failed to escalate issue without engagement",
+				zap.String("repository", record.Repository),
+				zap.Int("issue_number", record.Number),
+				zap.String("target", target),
+				zap.Error(err))
+			continue
+		}
+		p.recordSlackMessage(record.Repository)
+		escalated = true
+	}
+	if !escalated {
+		return
+	}
+
+	current, ok := p.store.GetIssue(record.Repository, record.Number)
+	if !ok || current.MessageTS != record.MessageTS {
+		return
+	}
+	current.EscalatedAt = now
+	p.store.SaveIssue(current)
+	p.logger.Info("Escalated issue without engagement",
+		zap.String("repository", record.Repository),
+		zap.Int("issue_number", record.Number),
+		zap.String("priority", record.Summary.Priority),
+		zap.String("channel", config.Channel),
+		zap.String("dm", config.DM))
+}
+
+// unengagedText describes an issue nobody engaged with
+func unengagedText(record store.IssueRecord, age time.Duration) string {
+	return fmt.Sprintf(":rotating_light: Nobody has responded to this %s priority issue in <#%s> for %s:\n*<https://github.com/%s/issues/%d|%s#%d: %s>*",
+		strings.Title(record.Summary.Priority), record.Channel, github.FormatAge(age),
+		record.Repository, record.Number, record.Repository, record.Number, record.Title)
+}
@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+)
+
+type fakeEngagementNotifier struct {
+	engaged bool
+	checks  int
+	posts   map[string]string
+}
+
+func (f *fakeEngagementNotifier) MessageEngaged(ctx context.Context, channelID, ts string) (bool, error) {
+	f.checks++
+	return f.engaged, nil
+}
+
+func (f *fakeEngagementNotifier) PostMessage(ctx context.Context, channelID, kind, text string) error {
+	f.posts[channelID] = text
+	return nil
+}
+
+func newEngagementProcessor(t *testing.T, config EngagementConfig) (*IssueProcessor, *fakeEngagementNotifier) {
+	t.Helper()
+	processor, _, _ := newTestProcessor(t)
+	router, err := routing.NewRouter([]routing.Rule{
+		{Name: "security", Labels: []string{"security"}, Channel: "CSEC", Escalation: routing.Escalation{AfterHours: 1, DM: "U0OWNER"}},
+	}, "C123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.router = router
+	notifier := &fakeEngagementNotifier{posts: map[string]string{}}
+	processor.SetEngagement(notifier, config)
+	return processor, notifier
+}
+
+// postedAgo backdates when the issue's message was posted
+func postedAgo(p *IssueProcessor, age time.Duration) {
+	record, _ := p.store.GetIssue("owner/repo", 7)
+	record.PostedAt = time.Now().Add(-age)
+	p.store.SaveIssue(record)
+}
+
+func TestCheckEngagementEscalates(t *testing.T) {
+	processor, notifier := newEngagementProcessor(t, EngagementConfig{Enabled: true, Window: 4 * time.Hour, Priority: "high", Channel: "CESC"})
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	// Within the window Slack is not asked
+	postedAgo(processor, time.Hour)
+	processor.CheckEngagement(context.Background())
+	if notifier.checks != 0 || len(notifier.posts) != 0 {
+		t.Fatalf("Expected nothing within the window, got %d checks and %v", notifier.checks, notifier.posts)
+	}
+
+	postedAgo(processor, 5*time.Hour)
+	processor.CheckEngagement(context.Background())
+	if !strings.Contains(notifier.posts["CESC"], "Nobody has responded to this High priority issue in <#C123> for 5 hours") {
+		t.Errorf("Expected the issue escalated to the secondary channel, got %v", notifier.posts)
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.EscalatedAt.IsZero() {
+		t.Error("Expected the escalation saved")
+	}
+
+	// Issues are escalated once
+	processor.CheckEngagement(context.Background())
+	if notifier.checks != 1 {
+		t.Errorf("Expected one engagement check, got %d", notifier.checks)
+	}
+}
+
+func TestCheckEngagementRuleOverrides(t *testing.T) {
+	processor, notifier := newEngagementProcessor(t, EngagementConfig{Enabled: true, Window: 4 * time.Hour, Priority: "high", Channel: "CESC"})
+	processor.ProcessIssue(context.Background(), newLabeledIssueData(github.BehaviorSummarize, "security"))
+
+	postedAgo(processor, 2*time.Hour)
+	processor.CheckEngagement(context.Background())
+	if notifier.posts["U0OWNER"] == "" || notifier.posts["CESC"] == "" {
+		t.Errorf("Expected the rule's shorter window and DM, got %v", notifier.posts)
+	}
+}
+
+func TestCheckEngagementEngaged(t *testing.T) {
+	processor, notifier := newEngagementProcessor(t, EngagementConfig{Enabled: true, Window: time.Hour, Priority: "high", Channel: "CESC"})
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	postedAgo(processor, 2*time.Hour)
+
+	notifier.engaged = true
+	processor.CheckEngagement(context.Background())
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if len(notifier.posts) != 0 || record.EngagedAt.IsZero() {
+		t.Errorf("Expected a reacted to issue recorded as engaged, got %v", notifier.posts)
+	}
+
+	// Lower priorities are not escalated
+	processor.engagementConfig.Priority = "critical"
+	record.EngagedAt = time.Time{}
+	if engagementDue(*record, processor.engagementFor(record.Route), time.Now()) {
+		t.Error("Expected a high priority issue below the critical threshold")
+	}
+}
+
+func TestRecordEngagement(t *testing.T) {
+	processor, notifier := newEngagementProcessor(t, EngagementConfig{Enabled: true, Window: time.Hour, Priority: "high", Channel: "CESC"})
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	postedAgo(processor, 2*time.Hour)
+
+	processor.RecordEngagement("C123", "1700000000.000100", "U0ALICE")
+	processor.CheckEngagement(context.Background())
+	if notifier.checks != 0 || len(notifier.posts) != 0 {
+		t.Errorf("Expected a clicked message not escalated, got %d checks and %v", notifier.checks, notifier.posts)
+	}
+
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.EngagedAt.IsZero() {
+		t.Error("Expected the click recorded")
+	}
+}
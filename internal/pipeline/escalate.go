@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/memory"
+)
+
+// escalate raises a summary to the highest priority after a label such as
+// "security" was added. Routing then runs again on the raised priority and the
+// issue's labels, so the issue can move to another channel.
+func (p *IssueProcessor) escalate(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) (*ai.IssueSummary, bool) {
+	if summary == nil || summary.EscalatedBy != "" {
+		return summary, false
+	}
+	top := p.priorities().Priorities()[0].Name
+
+	escalated := *summary
+	escalated.Priority, escalated.BoostedFrom, escalated.EscalatedBy = top, "", issueData.Label
+	p.logger.Info("Escalated issue after label was added",
+		zap.String("repository", issueData.Repository.GetFullName()),
+		zap.Int("issue_number", issueData.Issue.GetNumber()),
+		zap.String("label", issueData.Label),
+		zap.String("from", summary.Priority),
+		zap.String("to", top))
+
+	if summary.Priority != top {
+		p.relabel(ctx, issueData, summary.Priority, top)
+	}
+	return &escalated, true
+}
+
+// escalationMemory notes an escalation for later prompts
+func escalationMemory(summary *ai.IssueSummary) memory.Entry {
+	return memory.Entry{
+		Kind: memory.KindSummary,
+		Text: fmt.Sprintf("Escalated to %s priority when the %q label was added", summary.Priority, summary.EscalatedBy),
+		At:   time.Now(),
+	}
+}
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+)
+
+func newLabeledIssueData(behavior github.Behavior, label string) *github.IssueData {
+	issueData := newIssueData("labeled", behavior, "open", "It crashes")
+	issueData.Issue.Labels = []*gogithub.Label{{Name: gogithub.String(label)}}
+	issueData.Label = label
+	return issueData
+}
+
+func TestProcessIssueEscalate(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	router, err := routing.NewRouter([]routing.Rule{{Name: "security", Labels: []string{"security"}, Channel: "CSEC"}}, "C123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.router = router
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	processor.ProcessIssue(context.Background(), newLabeledIssueData(github.BehaviorEscalate, "security"))
+	if summarizer.calls != 1 {
+		t.Errorf("Expected no new summary, got %d", summarizer.calls)
+	}
+	// The security route has its own channel, so the issue is posted there
+	if len(notifier.posts) != 2 || len(notifier.updates) != 0 {
+		t.Fatalf("Expected the escalation posted to the security channel, got %d posts and %d updates", len(notifier.posts), len(notifier.updates))
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.Summary.Priority != "critical" || record.Summary.EscalatedBy != "security" {
+		t.Errorf("Expected a critical summary escalated by security, got %+v", record.Summary)
+	}
+	if len(record.Memory) != 2 {
+		t.Errorf("Expected the escalation in the issue memory, got %+v", record.Memory)
+	}
+
+	// Escalating again only refreshes the message
+	processor.ProcessIssue(context.Background(), newLabeledIssueData(github.BehaviorEscalate, "security"))
+	if len(notifier.posts) != 2 || len(notifier.updates) != 1 {
+		t.Errorf("Expected a repeated escalation to update in place, got %d posts and %d updates", len(notifier.posts), len(notifier.updates))
+	}
+}
+
+func TestProcessIssueLabelUpdate(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	processor.ProcessIssue(context.Background(), newLabeledIssueData(github.BehaviorUpdate, "docs"))
+	if summarizer.calls != 1 || len(notifier.updates) != 1 {
+		t.Fatalf("Expected the message updated without a summary, got %d summaries and %d updates", summarizer.calls, len(notifier.updates))
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if record.Summary.Priority != "high" || len(record.Labels) != 1 {
+		t.Errorf("Expected the labels stored without escalating, got %+v", record)
+	}
+}
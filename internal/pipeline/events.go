@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// Event describes how the pipeline handled one issue event
+type Event struct {
+	Time       time.Time     `json:"time"`
+	DeliveryID string        `json:"delivery_id,omitempty"`
+	Repository string        `json:"repository"`
+	Number     int           `json:"number"`
+	EventType  string        `json:"event_type,omitempty"`
+	Action     string        `json:"action,omitempty"`
+	Behavior   string        `json:"behavior,omitempty"`
+	Status     string        `json:"status"`          // success, skipped or error
+	Stage      string        `json:"stage,omitempty"` // Failed stage, for errors
+	Detail     string        `json:"detail,omitempty"`
+	Priority   string        `json:"priority,omitempty"`
+	Channel    string        `json:"channel,omitempty"`
+	Duration   time.Duration `json:"duration_ns"`
+}
+
+// EventRecorder keeps the outcome of each processed event
+type EventRecorder interface {
+	RecordEvent(event Event)
+}
+
+// SetEventRecorder reports the outcome of every processed event to recorder
+func (p *IssueProcessor) SetEventRecorder(recorder EventRecorder) {
+	p.events = recorder
+}
+
+// recordOutcome records processing metrics and, with a recorder, the event
+func (p *IssueProcessor) recordOutcome(issueData *github.IssueData, start time.Time, outcome Event) time.Duration {
+	duration := time.Since(start)
+	p.metrics.RecordIssueProcessed(issueData.Repository.GetFullName(), "issue", outcome.Status, duration)
+	if p.events == nil && outcome.Status != "error" {
+		return duration
+	}
+
+	outcome.Time = start
+	outcome.DeliveryID = issueData.DeliveryID
+	outcome.Repository = issueData.Repository.GetFullName()
+	outcome.Number = issueData.Issue.GetNumber()
+	outcome.EventType = issueData.EventType
+	outcome.Action = issueData.Action
+	outcome.Behavior = string(issueData.Behavior)
+	outcome.Duration = duration
+	if outcome.Status == "error" {
+		p.inFlight.recordError(outcome)
+	}
+	if p.events != nil {
+		p.events.RecordEvent(outcome)
+	}
+	return duration
+}
+
+// summaryPriority is the priority of a summary, or empty without one
+func summaryPriority(summary *ai.IssueSummary) string {
+	if summary == nil {
+		return ""
+	}
+	return summary.Priority
+}
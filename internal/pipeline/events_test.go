@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+type eventLog struct {
+	events []Event
+}
+
+func (l *eventLog) RecordEvent(event Event) {
+	l.events = append(l.events, event)
+}
+
+func TestProcessIssueRecordsEvents(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	log := &eventLog{}
+	processor.SetEventRecorder(log)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "The bot crashes on start"))
+	processor.ProcessIssue(context.Background(), newIssueData("edited", github.BehaviorResummarize, "open", "The bot crashes on start"))
+
+	if len(log.events) != 2 {
+		t.Fatalf("Expected two events, got %+v", log.events)
+	}
+	opened := log.events[0]
+	if opened.Status != "success" || opened.Repository != "owner/repo" || opened.Number != 7 ||
+		opened.Action != "opened" || opened.Priority != "high" || opened.Channel != "C123" {
+		t.Errorf("Unexpected event for the new issue: %+v", opened)
+	}
+	if edited := log.events[1]; edited.Status != "skipped" || edited.Detail != "edit not material" {
+		t.Errorf("Expected the unchanged edit to be skipped, got %+v", edited)
+	}
+}
@@ -0,0 +1,34 @@
+package pipeline
+
+import (
+	"context"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+// IdentityResolver maps GitHub logins to Slack users
+type IdentityResolver interface {
+	SlackUser(ctx context.Context, login string) (string, bool)
+}
+
+// SetIdentities mentions the assignees of issues who are mapped to Slack
+// users, instead of naming their GitHub logins
+func (p *IssueProcessor) SetIdentities(identities IdentityResolver) {
+	p.identities = identities
+}
+
+// resolveMentions looks up the Slack users of the issue's assignees before
+// its message is rendered
+func (p *IssueProcessor) resolveMentions(ctx context.Context, issueData *github.IssueData) {
+	if p.identities == nil || issueData.Issue == nil {
+		return
+	}
+	for _, login := range github.AssigneeLogins(issueData.Issue) {
+		if userID, ok := p.identities.SlackUser(ctx, login); ok {
+			if issueData.Mentions == nil {
+				issueData.Mentions = make(map[string]string)
+			}
+			issueData.Mentions[login] = userID
+		}
+	}
+}
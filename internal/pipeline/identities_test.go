@@ -0,0 +1,32 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeIdentities map[string]string
+
+func (f fakeIdentities) SlackUser(ctx context.Context, login string) (string, bool) {
+	userID, ok := f[login]
+	return userID, ok
+}
+
+func TestProcessIssueResolvesMentions(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	processor.SetIdentities(fakeIdentities{"octocat": "U024BE7LH"})
+
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	issueData.Issue.Assignees = []*gogithub.User{{Login: gogithub.String("octocat")}, {Login: gogithub.String("hubot")}}
+	processor.ProcessIssue(context.Background(), issueData)
+
+	want := map[string]string{"octocat": "U024BE7LH"}
+	if !reflect.DeepEqual(issueData.Mentions, want) {
+		t.Errorf("Expected only the mapped assignee mentioned, got %v", issueData.Mentions)
+	}
+}
@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// maxChannelName is Slack's limit on channel name length
+const maxChannelName = 80
+
+// invalidChannelChars matches characters Slack does not allow in channel names
+var invalidChannelChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// IncidentNotifier creates and archives dedicated Slack channels for incidents
+type IncidentNotifier interface {
+	CreateIncidentChannel(ctx context.Context, name string, responders []string) (string, error)
+	ArchiveIncidentChannel(ctx context.Context, channelID string) error
+}
+
+// IncidentConfig selects which issues get their own Slack channel
+type IncidentConfig struct {
+	Categories []string // Summary categories that open an incident, e.g. "security"
+	Priority   string   // Lowest priority that opens an incident
+	Responders []string // Slack user IDs invited to every incident channel
+	Prefix     string   // Channel name prefix, defaults to "inc"
+}
+
+// triggered reports whether a summary calls for an incident channel
+func (c IncidentConfig) triggered(summary *ai.IssueSummary) bool {
+	if summary == nil || !ai.PriorityAtLeast(summary.Priority, c.Priority) {
+		return false
+	}
+	for _, category := range c.Categories {
+		if strings.EqualFold(category, summary.Category) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetIncidents opens a dedicated Slack channel for issues matching config,
+// and archives it when the issue is closed
+func (p *IssueProcessor) SetIncidents(incidents IncidentNotifier, config IncidentConfig) {
+	if config.Prefix == "" {
+		config.Prefix = "inc"
+	}
+	p.incidents = incidents
+	p.incidentConfig = config
+}
+
+// IncidentChannelName builds a valid Slack channel name for an issue, such
+// as inc-api-issue123 for my-org/api#123
+func IncidentChannelName(prefix, repository string, number int) string {
+	repoName := repository[strings.LastIndex(repository, "/")+1:]
+	name := fmt.Sprintf("%s-%s-issue%d", prefix, repoName, number)
+	name = strings.Trim(invalidChannelChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+
+	// Shorten the repository name rather than losing the issue number
+	if len(name) > maxChannelName {
+		suffix := fmt.Sprintf("-issue%d", number)
+		name = strings.TrimRight(name[:maxChannelName-len(suffix)], "-") + suffix
+	}
+	return name
+}
+
+// openIncident creates the incident channel and posts the summary there. It
+// returns the channel ID, or an empty string if the channel could not be created.
+func (p *IssueProcessor) openIncident(ctx context.Context, issueData *github.IssueData, message map[string]interface{}) string {
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	name := IncidentChannelName(p.incidentConfig.Prefix, repository, number)
+
+	channelID, err := p.incidents.CreateIncidentChannel(ctx, name, p.incidentConfig.Responders)
+	if err != nil {
+		p.logger.Error("Failed to open incident channel",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.String("name", name),
+			zap.Error(err))
+		return ""
+	}
+
+	if _, _, err := p.slack.PostIssueSummary(ctx, channelID, message); err != nil {
+		p.logger.Error("Failed to post summary to incident channel",
+			zap.String("channel", channelID),
+			zap.Error(err))
+	}
+
+	p.logger.Info("Opened incident channel",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("channel", channelID),
+		zap.String("name", name))
+	return channelID
+}
+
+// closeIncident archives the incident channel of a closed issue
+func (p *IssueProcessor) closeIncident(ctx context.Context, repository string, number int, channelID string) bool {
+	if err := p.incidents.ArchiveIncidentChannel(ctx, channelID); err != nil {
+		p.logger.Error("Failed to archive incident channel",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.String("channel", channelID),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// withIncidentLink adds a link to the incident channel to a message
+func withIncidentLink(message map[string]interface{}, channelID string) map[string]interface{} {
+	return prependSection(message, fmt.Sprintf(":fire: Incident channel: <#%s>", channelID))
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeIncidents struct {
+	created  []string
+	archived []string
+}
+
+func (f *fakeIncidents) CreateIncidentChannel(ctx context.Context, name string, responders []string) (string, error) {
+	f.created = append(f.created, name)
+	return "C-INC", nil
+}
+
+func (f *fakeIncidents) ArchiveIncidentChannel(ctx context.Context, channelID string) error {
+	f.archived = append(f.archived, channelID)
+	return nil
+}
+
+func TestIncidentChannelName(t *testing.T) {
+	tests := []struct {
+		prefix     string
+		repository string
+		number     int
+		want       string
+	}{
+		{"inc", "my-org/api", 123, "inc-api-issue123"},
+		{"inc", "My-Org/Web.App", 7, "inc-web-app-issue7"},
+		{"sec", "repo", 1, "sec-repo-issue1"},
+		{"inc", "org/" + strings.Repeat("x", 90), 42, "inc-" + strings.Repeat("x", 68) + "-issue42"},
+	}
+
+	for _, tt := range tests {
+		got := IncidentChannelName(tt.prefix, tt.repository, tt.number)
+		if got != tt.want {
+			t.Errorf("IncidentChannelName(%q, %q, %d) = %q, want %q", tt.prefix, tt.repository, tt.number, got, tt.want)
+		}
+		if len(got) > maxChannelName {
+			t.Errorf("Channel name %q is longer than %d characters", got, maxChannelName)
+		}
+	}
+}
+
+func TestProcessIssueIncidentChannel(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	incidents := &fakeIncidents{}
+	processor.SetIncidents(incidents, IncidentConfig{Categories: []string{"security"}, Priority: "high"})
+
+	// Ordinary bugs stay in the main channel
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(incidents.created) != 0 {
+		t.Fatalf("Expected no incident channel for a bug, got %v", incidents.created)
+	}
+
+	// A high-priority security issue gets a channel with the summary, linked from the main message
+	summarizer.category = "security"
+	processor.ProcessIssue(context.Background(), newIssueData("reopened", github.BehaviorSummarize, "open", "Tokens are logged"))
+	if len(incidents.created) != 1 || incidents.created[0] != "inc-repo-issue7" {
+		t.Fatalf("Expected one incident channel, got %v", incidents.created)
+	}
+	if len(notifier.posts) != 3 {
+		t.Fatalf("Expected the summary in both channels, got %d posts", len(notifier.posts))
+	}
+	blocks := notifier.posts[2]["blocks"].([]interface{})
+	if len(blocks) != 1 || blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"] != ":fire: Incident channel: <#C-INC>" {
+		t.Errorf("Expected a link to the incident channel, got %+v", notifier.posts[2])
+	}
+
+	// Closing the issue archives the channel once
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "Tokens are logged"))
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "Tokens are logged"))
+	if len(incidents.archived) != 1 || incidents.archived[0] != "C-INC" {
+		t.Errorf("Expected the incident channel to be archived once, got %v", incidents.archived)
+	}
+}
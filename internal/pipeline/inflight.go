@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+// maxRecentErrors is how many failed events the processor keeps for debugging
+const maxRecentErrors = 50
+
+// InFlight is an issue being processed, for finding where the pipeline is
+// stuck
+type InFlight struct {
+	Repository   string    `json:"repository"`
+	Number       int       `json:"number"`
+	Action       string    `json:"action,omitempty"`
+	DeliveryID   string    `json:"delivery_id,omitempty"`
+	Stage        string    `json:"stage,omitempty"` // Empty until the first stage starts
+	Started      time.Time `json:"started"`
+	StageStarted time.Time `json:"stage_started,omitempty"`
+	Elapsed      float64   `json:"elapsed_seconds"`
+	StageElapsed float64   `json:"stage_elapsed_seconds,omitempty"`
+}
+
+// inFlightTracker keeps the issues being processed and the most recent
+// failures. The zero value is ready to use.
+type inFlightTracker struct {
+	mu     sync.Mutex
+	next   int
+	issues map[int]*InFlight
+	errors []Event // Oldest first
+}
+
+// inFlightKey is the context key of the tracked issue a stage belongs to
+type inFlightKey struct{}
+
+// trackInFlight registers issueData as being processed until the returned
+// function is called. Stages started with ctx are attributed to it.
+func (p *IssueProcessor) trackInFlight(ctx context.Context, issueData *github.IssueData) (context.Context, func()) {
+	t := &p.inFlight
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.issues == nil {
+		t.issues = make(map[int]*InFlight)
+	}
+	t.next++
+	id := t.next
+	t.issues[id] = &InFlight{
+		Repository: issueData.Repository.GetFullName(),
+		Number:     issueData.Issue.GetNumber(),
+		Action:     issueData.Action,
+		DeliveryID: issueData.DeliveryID,
+		Started:    time.Now(),
+	}
+	return context.WithValue(ctx, inFlightKey{}, id), func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.issues, id)
+	}
+}
+
+// enterStage records that the issue tracked in ctx, if any, reached stage
+func (t *inFlightTracker) enterStage(ctx context.Context, stage string) {
+	id, ok := ctx.Value(inFlightKey{}).(int)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if issue, ok := t.issues[id]; ok {
+		issue.Stage, issue.StageStarted = stage, time.Now()
+	}
+}
+
+// recordError keeps a failed event, dropping the oldest beyond maxRecentErrors
+func (t *inFlightTracker) recordError(event Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errors = append(t.errors, event)
+	if len(t.errors) > maxRecentErrors {
+		t.errors = append(t.errors[:0], t.errors[len(t.errors)-maxRecentErrors:]...)
+	}
+}
+
+// InFlight returns the issues being processed, longest running first
+func (p *IssueProcessor) InFlight() []InFlight {
+	t := &p.inFlight
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	issues := make([]InFlight, 0, len(t.issues))
+	for _, issue := range t.issues {
+		current := *issue
+		current.Elapsed = now.Sub(current.Started).Seconds()
+		if !current.StageStarted.IsZero() {
+			current.StageElapsed = now.Sub(current.StageStarted).Seconds()
+		}
+		issues = append(issues, current)
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].Started.Before(issues[j].Started)
+	})
+	return issues
+}
+
+// RecentErrors returns the most recent failed events, newest first
+func (p *IssueProcessor) RecentErrors() []Event {
+	t := &p.inFlight
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make([]Event, 0, len(t.errors))
+	for i := len(t.errors) - 1; i >= 0; i-- {
+		events = append(events, t.errors[i])
+	}
+	return events
+}
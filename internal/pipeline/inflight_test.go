@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/store"
+)
+
+func TestInFlight(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	ctx, finish := processor.trackInFlight(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	issues := processor.InFlight()
+	if len(issues) != 1 || issues[0].Repository != "owner/repo" || issues[0].Number != 7 || issues[0].Stage != "" {
+		t.Fatalf("Expected issue 7 in flight before any stage, got %+v", issues)
+	}
+	_, done := processor.stageContext(ctx, monitor.StageSummarize, 0)
+	done()
+	if issues := processor.InFlight(); issues[0].Stage != monitor.StageSummarize || issues[0].StageStarted.IsZero() {
+		t.Errorf("Expected issue 7 in the summarize stage, got %+v", issues[0])
+	}
+
+	// Stages of untracked work are ignored
+	_, done = processor.stageContext(context.Background(), monitor.StageNotify, 0)
+	done()
+	finish()
+	if issues := processor.InFlight(); len(issues) != 0 {
+		t.Errorf("Expected nothing in flight, got %+v", issues)
+	}
+}
+
+func TestRecentErrors(t *testing.T) {
+	router, err := routing.NewRouter(nil, "C123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := &failingNotifier{}
+	processor := NewIssueProcessor(&fakeSummarizer{}, notifier, router, store.NewMemoryStore(), zap.NewNop(), nopMetrics{})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	notifier.err = errors.New("slack down")
+	for i := 0; i < maxRecentErrors+1; i++ {
+		processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	}
+
+	events := processor.RecentErrors()
+	if len(events) != maxRecentErrors {
+		t.Fatalf("Expected the last %d errors, got %d", maxRecentErrors, len(events))
+	}
+	if event := events[0]; event.Repository != "owner/repo" || event.Stage != monitor.StageNotify || event.Detail == "" {
+		t.Errorf("Expected a notify failure, got %+v", event)
+	}
+	if issues := processor.InFlight(); len(issues) != 0 {
+		t.Errorf("Expected nothing in flight, got %+v", issues)
+	}
+}
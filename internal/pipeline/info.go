@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// InfoRequester asks an issue's reporter for the details a bug report is
+// missing
+type InfoRequester interface {
+	RequestInfo(ctx context.Context, repo string, number int, reporter string, missing []string) error
+}
+
+// InfoRequestConfig asks the reporters of bug reports the AI finds
+// incomplete, e.g. without a version or steps to reproduce, for the missing
+// details
+type InfoRequestConfig struct {
+	Enabled bool
+	Label   string // Added while waiting for the reporter, e.g. "needs-info"; empty for none
+}
+
+// SetInfoRequests comments on incomplete bug reports to ask for the details
+// the AI listed, once per issue, and mutes notifications for the issue until
+// its reporter edits it or comments
+func (p *IssueProcessor) SetInfoRequests(requester InfoRequester, labeler Labeler, config InfoRequestConfig) {
+	p.infoRequester = requester
+	p.infoLabeler = labeler
+	p.infoConfig = config
+}
+
+// requestInfo asks the reporter of a newly summarized bug report for the
+// details the summary lists as missing, unless they were asked before, and
+// reports whether it did. Failing to label the issue is logged; the request
+// still counts.
+func (p *IssueProcessor) requestInfo(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary, previous *store.IssueRecord) bool {
+	if p.infoRequester == nil || summary == nil || len(summary.MissingInfo) == 0 || issueData.Source != "" || issueData.Issue.GetState() == "closed" {
+		return false
+	}
+	if previous != nil && !previous.InfoRequestedAt.IsZero() {
+		return false
+	}
+
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	reporter := issueData.Issue.GetUser().GetLogin()
+	if err := p.infoRequester.RequestInfo(ctx, repository, number, reporter, summary.MissingInfo); err != nil {
+		p.logger.Warn("Failed to request missing details",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number),
+			zap.Error(err))
+		return false
+	}
+	if p.infoConfig.Label != "" {
+		if err := p.infoLabeler.AddLabels(ctx, repository, number, []string{p.infoConfig.Label}); err != nil {
+			p.logger.Warn("Failed to label issue as needing details",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.String("label", p.infoConfig.Label),
+				zap.Error(err))
+		}
+	}
+	p.logger.Info("Requested missing details from the reporter",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.Strings("missing", summary.MissingInfo))
+	return true
+}
+
+// awaitingInfo reports whether an issue is muted while its reporter is asked
+// for details. Once the reporter edits or comments on the issue, or it is
+// closed, the wait ends: the label comes off and the issue is processed as
+// usual. Commands and escalations are never muted.
+func (p *IssueProcessor) awaitingInfo(ctx context.Context, issueData *github.IssueData, previous *store.IssueRecord) bool {
+	if previous == nil || !previous.AwaitingInfo {
+		return false
+	}
+	reporter := issueData.Issue.GetUser().GetLogin()
+	responded := issueData.Sender != "" && strings.EqualFold(issueData.Sender, reporter)
+	if !responded && issueData.Issue.GetState() != "closed" {
+		return issueData.Command == nil && issueData.Behavior != github.BehaviorEscalate
+	}
+
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	if p.infoLabeler != nil && p.infoConfig.Label != "" {
+		if err := p.infoLabeler.RemoveLabel(ctx, repository, number, p.infoConfig.Label); err != nil {
+			p.logger.Warn("Failed to remove needs details label",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.String("label", p.infoConfig.Label),
+				zap.Error(err))
+		}
+	}
+	previous.AwaitingInfo = false
+	p.store.SaveIssue(previous)
+	return false
+}
+
+// infoRequest returns when the reporter was asked for details and whether
+// the issue still waits for them
+func infoRequest(previous *store.IssueRecord) (time.Time, bool) {
+	if previous == nil {
+		return time.Time{}, false
+	}
+	return previous.InfoRequestedAt, previous.AwaitingInfo
+}
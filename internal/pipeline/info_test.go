@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeInfoRequester struct {
+	reporters []string
+	missing   [][]string
+}
+
+func (f *fakeInfoRequester) RequestInfo(ctx context.Context, repo string, number int, reporter string, missing []string) error {
+	f.reporters = append(f.reporters, reporter)
+	f.missing = append(f.missing, missing)
+	return nil
+}
+
+func TestProcessIssueRequestsInfo(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	summarizer.missingInfo = []string{"version", "steps to reproduce"}
+	requester, labeler := &fakeInfoRequester{}, &fakeLabeler{}
+	processor.SetInfoRequests(requester, labeler, InfoRequestConfig{Enabled: true, Label: "needs-info"})
+
+	event := func(action string, behavior github.Behavior, sender string) *github.IssueData {
+		issueData := newIssueData(action, behavior, "open", "It crashes")
+		issueData.Issue.User = &gogithub.User{Login: gogithub.String("octocat")}
+		issueData.Sender = sender
+		return issueData
+	}
+
+	processor.ProcessIssue(context.Background(), event("opened", github.BehaviorSummarize, "octocat"))
+	if len(notifier.posts) != 1 {
+		t.Fatalf("Expected the issue posted, got %d posts", len(notifier.posts))
+	}
+	if !reflect.DeepEqual(requester.reporters, []string{"octocat"}) || !reflect.DeepEqual(requester.missing[0], summarizer.missingInfo) {
+		t.Fatalf("Expected the reporter asked for the missing details, got %v %v", requester.reporters, requester.missing)
+	}
+	if !reflect.DeepEqual(labeler.calls, [][]string{{"needs-info"}}) {
+		t.Errorf("Expected the needs-info label, got %v", labeler.calls)
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if !record.AwaitingInfo || record.InfoRequestedAt.IsZero() {
+		t.Fatalf("Expected the issue to wait for its reporter, got %+v", record)
+	}
+
+	// Others' activity, including the bot's own label and comment, is muted
+	processor.ProcessIssue(context.Background(), event("labeled", github.BehaviorUpdate, "notifyops[bot]"))
+	processor.ProcessIssue(context.Background(), event("created", github.BehaviorSummarize, "maintainer"))
+	if len(notifier.posts) != 1 || len(notifier.updates) != 0 || summarizer.calls != 1 {
+		t.Fatalf("Expected no notifications while waiting, got %d posts and %d updates", len(notifier.posts), len(notifier.updates))
+	}
+
+	// The reporter's reply ends the wait without asking again
+	processor.ProcessIssue(context.Background(), event("created", github.BehaviorSummarize, "OctoCat"))
+	if len(notifier.posts) != 2 || summarizer.calls != 2 {
+		t.Fatalf("Expected the reply to be processed, got %d posts", len(notifier.posts))
+	}
+	if len(requester.reporters) != 1 || !reflect.DeepEqual(labeler.removed, []string{"needs-info"}) {
+		t.Errorf("Expected the label removed and no second request, got %v and %v", requester.reporters, labeler.removed)
+	}
+	if record, _ := processor.store.GetIssue("owner/repo", 7); record.AwaitingInfo || record.InfoRequestedAt.IsZero() {
+		t.Errorf("Expected the wait over and the request remembered, got %+v", record)
+	}
+}
+
+func TestProcessIssueInfoRequestSkipped(t *testing.T) {
+	tests := map[string]func(*github.IssueData){
+		"closed issue":  func(issueData *github.IssueData) { issueData.Issue.State = gogithub.String("closed") },
+		"support issue": func(issueData *github.IssueData) { issueData.Source = "zendesk" },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			processor, summarizer, _ := newTestProcessor(t)
+			summarizer.missingInfo = []string{"version"}
+			requester := &fakeInfoRequester{}
+			processor.SetInfoRequests(requester, &fakeLabeler{}, InfoRequestConfig{Enabled: true})
+
+			issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+			modify(issueData)
+			processor.ProcessIssue(context.Background(), issueData)
+			if len(requester.reporters) != 0 {
+				t.Errorf("Expected no request, got %v", requester.reporters)
+			}
+		})
+	}
+}
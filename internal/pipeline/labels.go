@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// Labeler adds and removes labels on GitHub issues
+type Labeler interface {
+	AddLabels(ctx context.Context, repo string, number int, labels []string) error
+	RemoveLabel(ctx context.Context, repo string, number int, label string) error
+}
+
+// SetAutoLabeler labels summarized issues with the GitHub labels the taxonomy
+// maps to their priority and category. Labels are only ever added, so a label
+// removed by hand comes back when the issue is summarized again.
+func (p *IssueProcessor) SetAutoLabeler(labeler Labeler, taxonomy *ai.Taxonomy) {
+	p.labeler = labeler
+	p.taxonomy = taxonomy
+}
+
+// applyLabels adds the summary's labels that the issue does not have yet.
+// Failures are logged; the summary is still posted.
+func (p *IssueProcessor) applyLabels(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) {
+	if p.labeler == nil || summary == nil || issueData.Source != "" {
+		return
+	}
+
+	existing := make(map[string]bool, len(issueData.Issue.Labels))
+	for _, label := range issueData.Issue.Labels {
+		existing[strings.ToLower(label.GetName())] = true
+	}
+	var missing []string
+	for _, label := range p.taxonomy.Labels(summary.Priority, summary.Category) {
+		if !existing[strings.ToLower(label)] {
+			existing[strings.ToLower(label)] = true
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	repository := issueData.Repository.GetFullName()
+	if err := p.labeler.AddLabels(ctx, repository, issueData.Issue.GetNumber(), missing); err != nil {
+		p.logger.Warn("Failed to label issue",
+			zap.String("repository", repository),
+			zap.Int("issue_number", issueData.Issue.GetNumber()),
+			zap.Strings("labels", missing),
+			zap.Error(err))
+	}
+}
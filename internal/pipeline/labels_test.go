@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeLabeler struct {
+	calls   [][]string
+	removed []string
+	err     error
+}
+
+func (f *fakeLabeler) AddLabels(ctx context.Context, repo string, number int, labels []string) error {
+	f.calls = append(f.calls, labels)
+	return f.err
+}
+
+func (f *fakeLabeler) RemoveLabel(ctx context.Context, repo string, number int, label string) error {
+	f.removed = append(f.removed, label)
+	return f.err
+}
+
+func TestProcessIssueAutoLabels(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	taxonomy, err := ai.NewTaxonomy(ai.TaxonomyConfig{
+		Priorities: []ai.TaxonomyEntry{{Name: "high", Labels: []string{"P1"}}, {Name: "low", Labels: []string{"P3"}}},
+		Categories: []ai.TaxonomyEntry{{Name: "bug", Labels: []string{"type/bug"}}, {Name: "other"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	labeler := &fakeLabeler{}
+	processor.SetAutoLabeler(labeler, taxonomy)
+
+	// The fake summarizer answers high priority bug; P1 is already set
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	issueData.Issue.Labels = []*gogithub.Label{{Name: gogithub.String("p1")}}
+	processor.ProcessIssue(context.Background(), issueData)
+
+	if len(labeler.calls) != 1 || len(labeler.calls[0]) != 1 || labeler.calls[0][0] != "type/bug" {
+		t.Fatalf("Expected only the missing label to be added, got %v", labeler.calls)
+	}
+
+	// Updates do not summarize, so they do not label either
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if len(labeler.calls) != 1 {
+		t.Errorf("Expected no labels for an update, got %v", labeler.calls)
+	}
+
+	// Labeling failures do not stop the summary from being posted
+	labeler.err = errors.New("forbidden")
+	issueData = newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	issueData.Issue.Number = gogithub.Int(8)
+	processor.ProcessIssue(context.Background(), issueData)
+	if len(notifier.posts) != 2 {
+		t.Errorf("Expected both issues to be posted, got %d posts", len(notifier.posts))
+	}
+}
@@ -0,0 +1,241 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/store"
+)
+
+// Action IDs of the buttons on a moderator's preview
+const (
+	approvePreviewAction = "approve_preview"
+	editPreviewAction    = "edit_preview"
+	discardPreviewAction = "discard_preview"
+)
+
+// ModerationConfig holds the summaries of new issues in some repositories
+// for a moderator to vet before they are posted, e.g. for public community
+// repositories
+type ModerationConfig struct {
+	Repositories []string // owner/name patterns, path.Match syntax, e.g. acme/*
+	Moderator    string   // Slack user ID previews are sent to
+}
+
+// Enabled reports whether any repository is moderated
+func (c ModerationConfig) Enabled() bool {
+	return len(c.Repositories) > 0
+}
+
+// Validate checks the repository patterns and the moderator
+func (c ModerationConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	for _, pattern := range c.Repositories {
+		if _, err := path.Match(pattern, ""); err != nil || !strings.Contains(pattern, "/") {
+			return fmt.Errorf("repositories must be owner/name patterns, got %q", pattern)
+		}
+	}
+	if !routing.ValidSlackUser(c.Moderator) {
+		return fmt.Errorf("moderator must be a Slack user ID, got %q", c.Moderator)
+	}
+	return nil
+}
+
+// moderates reports whether the repository's summaries need approval
+func (c ModerationConfig) moderates(repository string) bool {
+	repository = strings.ToLower(repository)
+	for _, pattern := range c.Repositories {
+		if ok, _ := path.Match(strings.ToLower(pattern), repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SetModeration previews the first summary of each issue in the configured
+// repositories to the moderator, who approves, edits or discards it before
+// it is posted or labeled. fetcher reads approved issues again to post them.
+func (p *IssueProcessor) SetModeration(fetcher IssueFetcher, config ModerationConfig) {
+	p.moderationFetcher = fetcher
+	p.moderation = config
+}
+
+// needsApproval reports whether an issue's summary is held for the
+// moderator. Only the first message of an issue is; once posted, its updates
+// are not.
+func (p *IssueProcessor) needsApproval(issueData *github.IssueData, previous *store.IssueRecord, approved bool) bool {
+	if approved || !p.moderation.moderates(issueData.Repository.GetFullName()) {
+		return false
+	}
+	if previous != nil && previous.MessageTS != "" {
+		return false
+	}
+	return issueData.Behavior == github.BehaviorSummarize || issueData.Behavior == github.BehaviorResummarize
+}
+
+// approvedPreview reports whether the moderator approved the issue's held
+// summary, which is then posted as it was approved
+func approvedPreview(previous *store.IssueRecord) bool {
+	return previous != nil && previous.ApprovedBy != "" && previous.MessageTS == "" && previous.Summary != nil
+}
+
+// holdForApproval sends the message that would have been posted to the
+// moderator, with buttons to approve, edit or discard it, and saves the
+// summary as awaiting approval
+func (p *IssueProcessor) holdForApproval(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary, message map[string]interface{}, route routing.Route, held *store.IssueRecord) error {
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+
+	preview := previewMessage(message, repository, number, route.Channel)
+	slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+	_, _, err := p.slack.PostIssueSummary(slackCtx, p.moderation.Moderator, preview)
+	done()
+	if err != nil {
+		return fmt.Errorf("failed to send preview to moderator: %w", err)
+	}
+
+	held.Summary = summary
+	held.Channel = route.Channel
+	held.Route = route.Rule
+	held.AnalyzedAt = time.Now()
+	held.PreviewedAt = time.Now()
+	held.AwaitingApproval = true
+	p.store.SaveIssue(held)
+	p.logger.Info("Sent summary to moderator for approval",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("moderator", p.moderation.Moderator),
+		zap.String("channel", route.Channel))
+	return nil
+}
+
+// previewMessage turns an issue message into a moderator's preview: a note
+// on where it would be posted on top, and the moderation buttons in place
+// of the message's own, which act on posted messages
+func previewMessage(message map[string]interface{}, repository string, number int, channel string) map[string]interface{} {
+	preview := make(map[string]interface{}, len(message))
+	for key, value := range message {
+		preview[key] = value
+	}
+	value := fmt.Sprintf("%s:%d", repository, number)
+	button := func(actionID, text, style string) map[string]interface{} {
+		button := map[string]interface{}{
+			"type":      "button",
+			"action_id": actionID,
+			"value":     value,
+			"text":      map[string]interface{}{"type": "plain_text", "text": text},
+		}
+		if style != "" {
+			button["style"] = style
+		}
+		return button
+	}
+	actions := map[string]interface{}{
+		"type": "actions",
+		"elements": []interface{}{
+			button(approvePreviewAction, "Approve and post", "primary"),
+			button(editPreviewAction, "Edit…", ""),
+			button(discardPreviewAction, "Discard", "danger"),
+		},
+	}
+
+	// Templates render blocks as generic JSON arrays
+	var blocks []interface{}
+	switch messageBlocks := message["blocks"].(type) {
+	case []map[string]interface{}:
+		for _, block := range messageBlocks {
+			blocks = append(blocks, block)
+		}
+	case []interface{}:
+		blocks = messageBlocks
+	}
+	kept := make([]interface{}, 0, len(blocks)+1)
+	for _, block := range blocks {
+		if block, ok := block.(map[string]interface{}); ok && block["type"] == "actions" {
+			continue
+		}
+		kept = append(kept, block)
+	}
+	preview["blocks"] = append(kept, actions)
+	return prependSection(preview, fmt.Sprintf(":shield: *Preview for moderation.* %s#%d will be posted to <#%s> once you approve it.", repository, number, channel))
+}
+
+// HeldSummary returns the summary of an issue awaiting the moderator's
+// approval
+func (p *IssueProcessor) HeldSummary(repository string, number int) (*ai.IssueSummary, bool) {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || !record.AwaitingApproval || record.Summary == nil {
+		return nil, false
+	}
+	return record.Summary, true
+}
+
+// ApprovePreview posts an issue whose summary was held for approval, with
+// the summary as edited by the moderator, or as it was previewed when edited
+// is nil. It returns the channel the issue was posted to.
+func (p *IssueProcessor) ApprovePreview(ctx context.Context, repository string, number int, edited *ai.IssueSummary, moderator string) (string, error) {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || !record.AwaitingApproval {
+		return "", fmt.Errorf("%s#%d is not awaiting approval", repository, number)
+	}
+	if p.moderationFetcher == nil {
+		return "", fmt.Errorf("moderation is not enabled")
+	}
+	issueData, err := p.moderationFetcher.FetchEnrichedIssueData(ctx, repository, number)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch issue: %w", err)
+	}
+
+	if edited != nil {
+		record.Summary = edited
+	}
+	record.AwaitingApproval = false
+	record.ApprovedBy = moderator
+	p.store.SaveIssue(record)
+
+	issueData.EventType, issueData.Action, issueData.Behavior = "issues", "opened", github.BehaviorSummarize
+	ctx, finish := p.trackInFlight(ctx, issueData)
+	defer finish()
+	if !p.processIssue(ctx, issueData) {
+		// Keep the summary held, so approving can be retried
+		if current, ok := p.store.GetIssue(repository, number); ok && current.MessageTS == "" {
+			current.AwaitingApproval, current.ApprovedBy = true, ""
+			p.store.SaveIssue(current)
+		}
+		return "", fmt.Errorf("failed to post %s#%d", repository, number)
+	}
+	posted, _ := p.store.GetIssue(repository, number)
+	p.logger.Info("Moderator approved issue summary",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("moderator", moderator),
+		zap.Bool("edited", edited != nil))
+	return posted.Channel, nil
+}
+
+// DiscardPreview drops the held summary of an issue, which is then not
+// posted. A later material edit of the issue is previewed again.
+func (p *IssueProcessor) DiscardPreview(repository string, number int, moderator string) bool {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || !record.AwaitingApproval {
+		return false
+	}
+	record.AwaitingApproval = false
+	p.store.SaveIssue(record)
+	p.logger.Info("Moderator discarded issue summary",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("moderator", moderator))
+	return true
+}
@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+func TestModerationConfigValidate(t *testing.T) {
+	valid := ModerationConfig{Repositories: []string{"acme/*"}, Moderator: "U123ABC"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid config, got %v", err)
+	}
+	if err := (ModerationConfig{}).Validate(); err != nil {
+		t.Errorf("Expected no moderation to be valid, got %v", err)
+	}
+	for _, config := range []ModerationConfig{
+		{Repositories: []string{"acme"}, Moderator: "U123ABC"},
+		{Repositories: []string{"acme/[web"}, Moderator: "U123ABC"},
+		{Repositories: []string{"acme/*"}},
+		{Repositories: []string{"acme/*"}, Moderator: "#moderators"},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
+
+func TestModerationApprove(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	processor.SetModeration(&fakeFetcher{state: "open"}, ModerationConfig{Repositories: []string{"Owner/*"}, Moderator: "U123ABC"})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(notifier.posts) != 1 {
+		t.Fatalf("Expected only the preview sent, got %d posts", len(notifier.posts))
+	}
+	blocks := notifier.posts[0]["blocks"].([]interface{})
+	actions := blocks[len(blocks)-1].(map[string]interface{})
+	if actions["type"] != "actions" || len(actions["elements"].([]interface{})) != 3 {
+		t.Errorf("Expected the moderation buttons last, got %v", actions)
+	}
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if !record.AwaitingApproval || record.MessageTS != "" || record.Channel != "C123" {
+		t.Errorf("Expected the summary held for C123, got %+v", record)
+	}
+
+	held, ok := processor.HeldSummary("owner/repo", 7)
+	if !ok {
+		t.Fatal("Expected a held summary")
+	}
+	edited := *held
+	edited.Priority = "low"
+	channel, err := processor.ApprovePreview(context.Background(), "owner/repo", 7, &edited, "U999")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if channel != "C123" || len(notifier.posts) != 2 || summarizer.calls != 1 {
+		t.Errorf("Expected the approved summary posted without summarizing again, got channel %q, %d posts, %d calls", channel, len(notifier.posts), summarizer.calls)
+	}
+	record, _ = processor.store.GetIssue("owner/repo", 7)
+	if record.AwaitingApproval || record.ApprovedBy != "U999" || record.MessageTS == "" || record.Summary.Priority != "low" {
+		t.Errorf("Expected the edited summary posted, got %+v", record)
+	}
+	if _, err := processor.ApprovePreview(context.Background(), "owner/repo", 7, nil, "U999"); err == nil {
+		t.Error("Expected an issue approved twice to fail")
+	}
+
+	// Once posted, updates are not held
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if len(notifier.updates) != 1 || len(notifier.posts) != 2 {
+		t.Errorf("Expected the posted message updated, got %d updates and %d posts", len(notifier.updates), len(notifier.posts))
+	}
+}
+
+func TestModerationDiscard(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	processor.SetModeration(&fakeFetcher{state: "open"}, ModerationConfig{Repositories: []string{"owner/repo"}, Moderator: "U123ABC"})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if !processor.DiscardPreview("owner/repo", 7, "U999") || processor.DiscardPreview("owner/repo", 7, "U999") {
+		t.Error("Expected the preview discarded once")
+	}
+	if _, ok := processor.HeldSummary("owner/repo", 7); ok {
+		t.Error("Expected no held summary after discarding")
+	}
+
+	// A material edit is previewed again
+	processor.ProcessIssue(context.Background(), newIssueData("edited", github.BehaviorResummarize, "open", "It crashes on every start after the upgrade to v2, with a stack trace"))
+	if summarizer.calls != 2 || len(notifier.posts) != 2 {
+		t.Fatalf("Expected a second preview, got %d calls and %d posts", summarizer.calls, len(notifier.posts))
+	}
+	if notifier.posts[1]["blocks"].([]interface{})[0].(map[string]interface{})["type"] != "section" {
+		t.Errorf("Expected the preview note on top, got %v", notifier.posts[1]["blocks"])
+	}
+}
+
+func TestModerationUnmoderatedRepository(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	processor.SetModeration(&fakeFetcher{state: "open"}, ModerationConfig{Repositories: []string{"other/*"}, Moderator: "U123ABC"})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if len(notifier.posts) != 1 || record.AwaitingApproval || record.MessageTS == "" {
+		t.Errorf("Expected the issue posted directly, got %+v", record)
+	}
+}
+
+func TestPreviewMessage(t *testing.T) {
+	message := map[string]interface{}{
+		"text": "New issue",
+		"blocks": []map[string]interface{}{
+			{"type": "header"},
+			{"type": "actions", "elements": []interface{}{}},
+		},
+	}
+	preview := previewMessage(message, "owner/repo", 7, "C123")
+	blocks := preview["blocks"].([]interface{})
+	if len(blocks) != 3 || blocks[1].(map[string]interface{})["type"] != "header" {
+		t.Fatalf("Expected the note, the header and the moderation buttons, got %v", blocks)
+	}
+	elements := blocks[2].(map[string]interface{})["elements"].([]interface{})
+	if button := elements[0].(map[string]interface{}); button["action_id"] != approvePreviewAction || button["value"] != "owner/repo:7" {
+		t.Errorf("Expected the approve button for owner/repo#7, got %v", button)
+	}
+	if len(message["blocks"].([]map[string]interface{})) != 2 {
+		t.Error("Expected the message unchanged")
+	}
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/store"
+)
+
+// SetMoveNotes posts a note in the Slack thread of issues that were
+// transferred or whose repository was renamed
+func (p *IssueProcessor) SetMoveNotes(replies ThreadNotifier) {
+	p.moveReplies = replies
+}
+
+// TransferIssue moves the record of an issue transferred to another
+// repository to its new number, so later events update the same Slack
+// message instead of posting a new one
+func (p *IssueProcessor) TransferIssue(ctx context.Context, transfer github.Transfer) {
+	record, ok := p.store.GetIssue(transfer.FromRepository, transfer.FromNumber)
+	if !ok || !p.store.MoveIssue(transfer.FromRepository, transfer.FromNumber, transfer.ToRepository, transfer.ToNumber) {
+		p.logger.Info("No record of transferred issue",
+			zap.String("repository", transfer.FromRepository),
+			zap.Int("issue_number", transfer.FromNumber))
+		return
+	}
+	if moved, ok := p.store.GetIssue(transfer.ToRepository, transfer.ToNumber); ok {
+		moved.TransferredFrom = fmt.Sprintf("%s#%d", transfer.FromRepository, transfer.FromNumber)
+		p.store.SaveIssue(moved)
+	}
+
+	text := fmt.Sprintf(":truck: Transferred to <%s|%s#%d>", transfer.URL, transfer.ToRepository, transfer.ToNumber)
+	if transfer.URL == "" {
+		text = fmt.Sprintf(":truck: Transferred to %s#%d", transfer.ToRepository, transfer.ToNumber)
+	}
+	if transfer.Sender != "" {
+		text += fmt.Sprintf(" by @%s", transfer.Sender)
+	}
+	p.postMoveNote(ctx, *record, text+". Updates will continue in this thread.")
+}
+
+// RenameRepository moves the records of a renamed or transferred repository's
+// issues to its new name, noting it in the threads of its open issues
+func (p *IssueProcessor) RenameRepository(ctx context.Context, from, to string) {
+	records, _ := p.store.ListIssues(store.Query{Repository: from})
+	moved := p.store.RenameRepository(from, to)
+	p.logger.Info("Moved records of renamed repository",
+		zap.String("repository", from),
+		zap.String("new_repository", to),
+		zap.Int("issues", moved))
+
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return
+		}
+		if record.State != "closed" {
+			p.postMoveNote(ctx, record, fmt.Sprintf(":truck: Repository %s is now *%s*; this is %s#%d.", from, to, to, record.Number))
+		}
+	}
+}
+
+// postMoveNote replies in the thread of an issue's posted message. Failures
+// are logged; the record has moved either way.
+func (p *IssueProcessor) postMoveNote(ctx context.Context, record store.IssueRecord, text string) {
+	if p.moveReplies == nil || record.MessageTS == "" {
+		return
+	}
+	slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+	err := p.moveReplies.PostThreadReply(slackCtx, record.Channel, record.MessageTS, text)
+	done()
+	if err != nil {
+		p.logger.Warn("Failed to post move note",
+			zap.String("repository", record.Repository),
+			zap.Int("issue_number", record.Number),
+			zap.Error(err))
+	}
+}
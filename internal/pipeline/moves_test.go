@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+func TestTransferIssue(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	replies := &fakeReplies{}
+	processor.SetMoveNotes(replies)
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	processor.TransferIssue(context.Background(), github.Transfer{
+		FromRepository: "owner/repo",
+		FromNumber:     7,
+		ToRepository:   "owner/backend",
+		ToNumber:       3,
+		URL:            "https://github.com/owner/backend/issues/3",
+		Sender:         "octocat",
+	})
+	if _, ok := processor.store.GetIssue("owner/repo", 7); ok {
+		t.Error("Expected no record under the old number")
+	}
+	if len(replies.replies) != 1 || !strings.Contains(replies.replies[0], "Transferred to <https://github.com/owner/backend/issues/3|owner/backend#3> by @octocat") {
+		t.Errorf("Expected a transfer note in the thread, got %v", replies.replies)
+	}
+
+	// The issue is opened in its new repository, which updates the posted
+	// message instead of posting a new one
+	opened := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	opened.Issue.Number = gogithub.Int(3)
+	opened.Repository.FullName = gogithub.String("owner/backend")
+	processor.ProcessIssue(context.Background(), opened)
+	if summarizer.calls != 1 || len(notifier.posts) != 1 || len(notifier.updates) != 1 {
+		t.Errorf("Expected the posted message updated, got %d summaries, %d posts and %d updates", summarizer.calls, len(notifier.posts), len(notifier.updates))
+	}
+	if record, ok := processor.store.GetIssue("owner/backend", 3); !ok || record.TransferredFrom != "" || record.MessageTS == "" {
+		t.Errorf("Expected the record processed under its new number, got %+v", record)
+	}
+
+	// Transfers of issues that were never posted are ignored
+	processor.TransferIssue(context.Background(), github.Transfer{FromRepository: "owner/repo", FromNumber: 8, ToRepository: "owner/backend", ToNumber: 4})
+	if len(replies.replies) != 1 {
+		t.Errorf("Expected no note for an unknown issue, got %v", replies.replies)
+	}
+}
+
+func TestRenameRepository(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	replies := &fakeReplies{}
+	processor.SetMoveNotes(replies)
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	closed := newIssueData("opened", github.BehaviorSummarize, "closed", "It hangs")
+	closed.Issue.Number = gogithub.Int(8)
+	processor.ProcessIssue(context.Background(), closed)
+
+	processor.RenameRepository(context.Background(), "owner/repo", "owner/api")
+	for _, number := range []int{7, 8} {
+		if record, ok := processor.store.GetIssue("owner/api", number); !ok || record.Repository != "owner/api" {
+			t.Errorf("Expected issue %d under the new name, got %+v", number, record)
+		}
+	}
+	if len(replies.replies) != 1 || !strings.Contains(replies.replies[0], "Repository owner/repo is now *owner/api*; this is owner/api#7.") {
+		t.Errorf("Expected a note in the open issue's thread only, got %v", replies.replies)
+	}
+}
@@ -0,0 +1,150 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// SlackNotifierName is the name routing rules use for the Slack notifier
+const SlackNotifierName = "slack"
+
+// Notifier delivers issue summaries to one destination, e.g. Slack or a
+// webhook. Routing rules pick notifiers by name.
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, summary Summary, meta IssueMeta) (Delivery, error)
+}
+
+// Summary is what notifiers deliver for an issue
+type Summary struct {
+	Issue      *ai.IssueSummary       // Nil when the AI was skipped
+	SkipReason string                 // Why the AI was skipped
+	Message    map[string]interface{} // Slack rendering in the route's layout
+}
+
+// IssueMeta describes the issue a summary is about and where it was routed
+type IssueMeta struct {
+	Repository string
+	Number     int
+	Title      string
+	URL        string
+	State      string
+	EventType  string
+	Action     string
+	Behavior   github.Behavior
+	Route      routing.Route
+	Previous   Delivery // The Slack message to update in place, if any
+}
+
+// Delivery is where a notifier delivered a summary. Notifiers without
+// messages to update later return an empty delivery.
+type Delivery struct {
+	Channel   string
+	MessageID string
+}
+
+// AddNotifier sends every summary to notifier as well, unless a routing rule
+// names the notifiers for its issues
+func (p *IssueProcessor) AddNotifier(notifier Notifier) {
+	p.notifiers = append(p.notifiers, notifier)
+}
+
+// NotifierNames returns the names of the configured notifiers
+func (p *IssueProcessor) NotifierNames() []string {
+	names := make([]string, len(p.notifiers))
+	for i, notifier := range p.notifiers {
+		names[i] = notifier.Name()
+	}
+	return names
+}
+
+// notify sends a summary to the named notifiers, or to all of them when names
+// is empty. Delivery succeeds if any notifier succeeds; the failures of the
+// others are logged and counted but do not fail the issue.
+func (p *IssueProcessor) notify(ctx context.Context, summary Summary, meta IssueMeta, names []string) (map[string]Delivery, error) {
+	deliveries := make(map[string]Delivery)
+	var errs []error
+	for _, notifier := range p.notifiers {
+		name := notifier.Name()
+		if len(names) > 0 && !containsString(names, name) {
+			continue
+		}
+
+		delivery, err := notifier.Send(ctx, summary, meta)
+		if err != nil {
+			p.metrics.RecordNotification(name, "error")
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		p.metrics.RecordNotification(name, "success")
+		if name == SlackNotifierName {
+			p.recordSlackMessage(meta.Repository)
+		}
+		deliveries[name] = delivery
+	}
+
+	if len(deliveries) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		p.logger.Warn("Notifier failed, delivered to the others",
+			zap.String("repository", meta.Repository),
+			zap.Int("issue_number", meta.Number),
+			zap.Error(err))
+	}
+	return deliveries, nil
+}
+
+// issueMeta describes an issue for notifiers
+func issueMeta(issueData *github.IssueData, route routing.Route) IssueMeta {
+	return IssueMeta{
+		Repository: issueData.Repository.GetFullName(),
+		Number:     issueData.Issue.GetNumber(),
+		Title:      issueData.Issue.GetTitle(),
+		URL:        issueData.Issue.GetHTMLURL(),
+		State:      issueData.Issue.GetState(),
+		EventType:  issueData.EventType,
+		Action:     issueData.Action,
+		Behavior:   issueData.Behavior,
+		Route:      route,
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+// slackNotifier delivers summaries as Slack messages
+type slackNotifier struct {
+	slack SlackNotifier
+}
+
+// NewSlackNotifier delivers summaries to the routed Slack channel, updating
+// the message posted earlier where there is one
+func NewSlackNotifier(slack SlackNotifier) Notifier {
+	return slackNotifier{slack: slack}
+}
+
+func (n slackNotifier) Name() string {
+	return SlackNotifierName
+}
+
+func (n slackNotifier) Send(ctx context.Context, summary Summary, meta IssueMeta) (Delivery, error) {
+	if meta.Previous.MessageID != "" {
+		return meta.Previous, n.slack.UpdateIssueSummary(ctx, meta.Previous.Channel, meta.Previous.MessageID, summary.Message)
+	}
+	channel, ts, err := n.slack.PostIssueSummary(ctx, meta.Route.Channel, summary.Message)
+	return Delivery{Channel: channel, MessageID: ts}, err
+}
@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/routing"
+)
+
+// fakeSink records the summaries sent to it, failing when err is set
+type fakeSink struct {
+	name  string
+	err   error
+	sends []IssueMeta
+}
+
+func (f *fakeSink) Name() string {
+	return f.name
+}
+
+func (f *fakeSink) Send(ctx context.Context, summary Summary, meta IssueMeta) (Delivery, error) {
+	f.sends = append(f.sends, meta)
+	return Delivery{}, f.err
+}
+
+type notificationMetrics struct {
+	nopMetrics
+	notifications map[string]int
+	failures      []string
+}
+
+func (m *notificationMetrics) RecordNotification(notifier, status string) {
+	m.notifications[notifier+"/"+status]++
+}
+
+func (m *notificationMetrics) RecordPipelineFailure(stage string) {
+	m.failures = append(m.failures, stage)
+}
+
+func TestProcessIssueNotifierFanOut(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	metrics := &notificationMetrics{notifications: map[string]int{}}
+	processor.metrics = metrics
+	sink := &fakeSink{name: "siem"}
+	processor.AddNotifier(sink)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(notifier.posts) != 1 || len(sink.sends) != 1 {
+		t.Fatalf("Expected the summary in Slack and the webhook, got %d posts and %d sends", len(notifier.posts), len(sink.sends))
+	}
+	processor.ProcessIssue(context.Background(), newIssueData("edited", github.BehaviorResummarize, "open", "It crashes on every start with a nil pointer"))
+	if len(notifier.updates) != 1 || len(sink.sends) != 2 || sink.sends[1].Previous.MessageID == "" {
+		t.Errorf("Expected the edit to update Slack and be sent again, got %d updates and %+v", len(notifier.updates), sink.sends)
+	}
+	if metrics.notifications["slack/success"] != 2 || metrics.notifications["siem/success"] != 2 {
+		t.Errorf("Expected per-notifier success metrics, got %v", metrics.notifications)
+	}
+	if names := processor.NotifierNames(); len(names) != 2 || names[0] != SlackNotifierName || names[1] != "siem" {
+		t.Errorf("Unexpected notifier names %v", names)
+	}
+}
+
+func TestProcessIssuePartialNotifierFailure(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	metrics := &notificationMetrics{notifications: map[string]int{}}
+	processor.metrics = metrics
+	processor.AddNotifier(&fakeSink{name: "siem", err: errors.New("connection refused")})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(metrics.failures) != 0 {
+		t.Errorf("Expected a failing notifier not to fail the issue, got failures %v", metrics.failures)
+	}
+	if metrics.notifications["siem/error"] != 1 || metrics.notifications["slack/success"] != 1 {
+		t.Errorf("Expected the failure to be counted, got %v", metrics.notifications)
+	}
+	if record, ok := processor.store.GetIssue("owner/repo", 7); !ok || record.MessageTS == "" {
+		t.Errorf("Expected the Slack message to be stored, got %+v", record)
+	}
+
+	// Every notifier failing fails the notify stage
+	processor.notifiers = []Notifier{&fakeSink{name: "siem", err: errors.New("connection refused")}}
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(metrics.failures) != 1 {
+		t.Errorf("Expected a notify failure, got %v", metrics.failures)
+	}
+}
+
+func TestProcessIssueRouteNotifiers(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	router, err := routing.NewRouter([]routing.Rule{{Name: "siem-only", Notifiers: []string{"siem"}}}, "C123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.router = router
+	sink := &fakeSink{name: "siem"}
+	processor.AddNotifier(sink)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(notifier.posts) != 0 || len(sink.sends) != 1 {
+		t.Errorf("Expected only the rule's notifier, got %d posts and %d sends", len(notifier.posts), len(sink.sends))
+	}
+	if sink.sends[0].Route.Rule != "siem-only" {
+		t.Errorf("Expected the route in the metadata, got %+v", sink.sends[0].Route)
+	}
+}
@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+// PostProcessor transforms generated summaries before any notifier renders
+// them, e.g. to enforce a word limit or map categories to an internal
+// taxonomy. Process returns the transformed summary; slices that change
+// must be replaced rather than modified in place.
+type PostProcessor interface {
+	Name() string
+	Process(repository string, summary ai.IssueSummary) (ai.IssueSummary, error)
+}
+
+// AddPostProcessor runs processor on every generated summary, after the
+// post-processors added before it
+func (p *IssueProcessor) AddPostProcessor(processor PostProcessor) {
+	p.postProcessors = append(p.postProcessors, processor)
+}
+
+// postProcess runs the post-processors on a newly generated summary. The
+// result is stored, so updates of the posted message reuse it rather than
+// processing it again. A failing post-processor is logged and skipped.
+func (p *IssueProcessor) postProcess(repository string, summary *ai.IssueSummary) *ai.IssueSummary {
+	if len(p.postProcessors) == 0 {
+		return summary
+	}
+	processed := *summary
+	for _, processor := range p.postProcessors {
+		result, err := processor.Process(repository, processed)
+		if err != nil {
+			p.logger.Warn("Summary post-processor failed, skipping it",
+				zap.String("repository", repository),
+				zap.String("postprocessor", processor.Name()),
+				zap.Error(err))
+			continue
+		}
+		processed = result
+	}
+	return &processed
+}
@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// fakePostProcessor appends its name to summaries, failing when err is set
+type fakePostProcessor struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (f *fakePostProcessor) Name() string {
+	return f.name
+}
+
+func (f *fakePostProcessor) Process(repository string, summary ai.IssueSummary) (ai.IssueSummary, error) {
+	f.calls++
+	if f.err != nil {
+		summary.Category = "broken"
+		return summary, f.err
+	}
+	summary.Summary += "[" + f.name + "]"
+	summary.Category = f.name
+	return summary, nil
+}
+
+// summarySink records the summaries sent to it
+type summarySink struct {
+	summaries []*ai.IssueSummary
+}
+
+func (s *summarySink) Name() string {
+	return "sink"
+}
+
+func (s *summarySink) Send(ctx context.Context, summary Summary, meta IssueMeta) (Delivery, error) {
+	s.summaries = append(s.summaries, summary.Issue)
+	return Delivery{}, nil
+}
+
+func TestProcessIssuePostProcessors(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	first, failing, last := &fakePostProcessor{name: "first"}, &fakePostProcessor{name: "failing", err: errors.New("boom")}, &fakePostProcessor{name: "last"}
+	processor.AddPostProcessor(first)
+	processor.AddPostProcessor(failing)
+	processor.AddPostProcessor(last)
+	sink := &summarySink{}
+	processor.AddNotifier(sink)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(sink.summaries) != 1 {
+		t.Fatalf("Expected one summary sent, got %d", len(sink.summaries))
+	}
+	if summary := sink.summaries[0]; summary.Summary != "[first][last]" || summary.Category != "last" {
+		t.Errorf("Expected the post-processors applied in order, skipping the failing one, got %+v", summary)
+	}
+	if record, _ := processor.store.GetIssue("owner/repo", 7); record.Summary.Summary != "[first][last]" {
+		t.Errorf("Expected the processed summary stored, got %+v", record.Summary)
+	}
+
+	// Refreshing the posted message reuses the processed summary
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if first.calls != 1 || len(sink.summaries) != 2 || sink.summaries[1].Summary != "[first][last]" {
+		t.Errorf("Expected the update not to process the summary again, got %d calls and %+v", first.calls, sink.summaries[1])
+	}
+}
@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+// Prefilter rules, used as the metric label for skipped issues
+const (
+	PrefilterRuleLabel     = "label"
+	PrefilterRuleTitle     = "title"
+	PrefilterRuleEmptyBody = "empty_body"
+)
+
+// trustedAssociations are reporters whose short issues are still worth summarizing
+var trustedAssociations = map[string]bool{
+	"OWNER":        true,
+	"MEMBER":       true,
+	"COLLABORATOR": true,
+	"CONTRIBUTOR":  true,
+}
+
+// PrefilterConfig configures the rule-based pre-filter
+type PrefilterConfig struct {
+	Labels        []string // Issues with any of these labels are skipped
+	TitleKeywords []string // Issues whose whole title is one of these are skipped, e.g. "test"
+	MinBodyLength int      // Shorter bodies from untrusted reporters are skipped
+}
+
+// PrefilterResult explains why an issue was skipped
+type PrefilterResult struct {
+	Rule   string
+	Reason string
+}
+
+// Prefilter is a cheap rule-based classifier that spots obviously low-value
+// issues so they can be noted in Slack without calling OpenAI
+type Prefilter struct {
+	cfg PrefilterConfig
+}
+
+// NewPrefilter creates a pre-filter
+func NewPrefilter(cfg PrefilterConfig) *Prefilter {
+	return &Prefilter{cfg: cfg}
+}
+
+// Check reports whether the issue should skip the AI, and why
+func (f *Prefilter) Check(issueData *github.IssueData) (PrefilterResult, bool) {
+	issue := issueData.Issue
+
+	for _, label := range issue.Labels {
+		for _, skip := range f.cfg.Labels {
+			if strings.EqualFold(label.GetName(), skip) {
+				return PrefilterResult{
+					Rule:   PrefilterRuleLabel,
+					Reason: fmt.Sprintf("labeled %q", label.GetName()),
+				}, true
+			}
+		}
+	}
+
+	title := strings.ToLower(strings.Trim(strings.TrimSpace(issue.GetTitle()), ".!?"))
+	for _, keyword := range f.cfg.TitleKeywords {
+		if title == strings.ToLower(keyword) {
+			return PrefilterResult{
+				Rule:   PrefilterRuleTitle,
+				Reason: fmt.Sprintf("title is %q", issue.GetTitle()),
+			}, true
+		}
+	}
+
+	// Unanswered issue form sections don't count towards the body length
+	body := strings.TrimSpace(issue.GetBody())
+	if issueData.FormFields != nil {
+		var values []string
+		for _, field := range issueData.FormFields {
+			values = append(values, field.Value)
+		}
+		body = strings.Join(values, "\n")
+	}
+	if len([]rune(body)) < f.cfg.MinBodyLength && !trustedAssociations[issue.GetAuthorAssociation()] {
+		reason := "the description is empty"
+		if body != "" {
+			reason = fmt.Sprintf("the description is only %d characters", len([]rune(body)))
+		}
+		return PrefilterResult{Rule: PrefilterRuleEmptyBody, Reason: reason}, true
+	}
+
+	return PrefilterResult{}, false
+}
@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+func TestPrefilterCheck(t *testing.T) {
+	prefilter := NewPrefilter(PrefilterConfig{
+		Labels:        []string{"invalid", "spam"},
+		TitleKeywords: []string{"test", "asdf"},
+		MinBodyLength: 30,
+	})
+	longBody := "Summaries are posted twice when an issue is edited quickly."
+
+	tests := []struct {
+		name        string
+		title       string
+		body        string
+		labels      []string
+		association string
+		rule        string
+	}{
+		{name: "normal", title: "Duplicate Slack posts", body: longBody, association: "NONE"},
+		{name: "label", title: "Duplicate Slack posts", body: longBody, labels: []string{"Spam"}, rule: PrefilterRuleLabel},
+		{name: "test title", title: "Test!", body: longBody, rule: PrefilterRuleTitle},
+		{name: "keyword inside title", title: "Test failures in CI", body: longBody, association: "NONE"},
+		{name: "empty body", title: "Duplicate Slack posts", association: "NONE", rule: PrefilterRuleEmptyBody},
+		{name: "short body from member", title: "Duplicate Slack posts", body: "See logs", association: "MEMBER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := &gogithub.Issue{
+				Title:             gogithub.String(tt.title),
+				Body:              gogithub.String(tt.body),
+				AuthorAssociation: gogithub.String(tt.association),
+			}
+			for _, label := range tt.labels {
+				issue.Labels = append(issue.Labels, &gogithub.Label{Name: gogithub.String(label)})
+			}
+
+			result, trivial := prefilter.Check(&github.IssueData{Issue: issue})
+			if trivial != (tt.rule != "") || result.Rule != tt.rule {
+				t.Errorf("Expected rule %q, got %+v (trivial=%v)", tt.rule, result, trivial)
+			}
+		})
+	}
+}
+
+func TestPrefilterIgnoresUnansweredFormFields(t *testing.T) {
+	prefilter := NewPrefilter(PrefilterConfig{MinBodyLength: 30})
+	body := "### Version\n\n_No response_\n\n### What happened?\n\nIt broke"
+
+	issueData := &github.IssueData{
+		Issue:      &gogithub.Issue{Title: gogithub.String("Broken"), Body: gogithub.String(body)},
+		FormFields: github.ParseIssueForm(body),
+	}
+	if result, trivial := prefilter.Check(issueData); !trivial || result.Rule != PrefilterRuleEmptyBody {
+		t.Errorf("Expected unanswered form to be trivial, got %+v", result)
+	}
+}
@@ -0,0 +1,959 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/components"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/oncall"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/store"
+)
+
+// Summarizer generates AI summaries and renders them as Slack messages
+type Summarizer interface {
+	SummarizeIssue(ctx context.Context, issueData *github.IssueData) (*ai.IssueSummary, error)
+	TriageIssue(ctx context.Context, issueData *github.IssueData) (*ai.IssueSummary, error)
+	GenerateSlackMessage(issueData *github.IssueData, summary *ai.IssueSummary) map[string]interface{}
+	GenerateCompactSlackMessage(issueData *github.IssueData, summary *ai.IssueSummary) map[string]interface{}
+	GeneratePlainSlackMessage(issueData *github.IssueData, summary *ai.IssueSummary) map[string]interface{}
+	GenerateSkippedSlackMessage(issueData *github.IssueData, reason string) map[string]interface{}
+	GenerateDegradedSlackMessage(issueData *github.IssueData, reason string) map[string]interface{}
+	GenerateBuildBreakSlackMessage(issueData *github.IssueData, failure *github.CIFailure) map[string]interface{}
+}
+
+// Translator translates non-English issues before analysis
+type Translator interface {
+	TranslateIssue(ctx context.Context, issueData *github.IssueData, language string) (*ai.Translation, error)
+}
+
+// SlackNotifier posts and updates issue summaries in Slack. Besides
+// delivering summaries it posts incident links and rolling messages, which
+// have no equivalent in other notifiers.
+type SlackNotifier interface {
+	PostIssueSummary(ctx context.Context, channelID string, message map[string]interface{}) (string, string, error)
+	UpdateIssueSummary(ctx context.Context, channelID, ts string, message map[string]interface{}) error
+}
+
+// IssueStore remembers the last summary and Slack message for each issue
+type IssueStore interface {
+	GetIssue(repository string, number int) (*store.IssueRecord, bool)
+	SaveIssue(record *store.IssueRecord)
+	ListIssues(query store.Query) ([]store.IssueRecord, string)
+	MoveIssue(repository string, number int, toRepository string, toNumber int) bool
+	RenameRepository(repository, toRepository string) int
+}
+
+// OnCallResolver finds the engineer on call for a repository
+type OnCallResolver interface {
+	OnCall(repository string, at time.Time) (oncall.Shift, bool)
+}
+
+// MetricsRecorder interface for recording metrics
+type MetricsRecorder interface {
+	RecordIssueProcessed(repository, issueType, status string, duration time.Duration)
+	RecordIssueSummaryGenerated(repository, issueType string)
+	RecordPipelineSuccess(eventType, deliveryID string, receivedAt time.Time)
+	RecordPipelineFailure(stage string)
+	RecordIssuePrefiltered(repository, rule string)
+	RecordIssueTranslated(repository, language string)
+	RecordNotificationCoalesced(repository string)
+	RecordStageTimeout(stage string)
+	RecordSummaryConfidence(confidence float64)
+	RecordSummaryOverride(confidence float64, fields []string)
+	RecordSummaryAssignment(repository, priority, category string)
+	RecordPriorityBoost(repository string)
+	RecordNotification(notifier, status string)
+}
+
+// IssueProcessor handles the processing of GitHub issues
+type IssueProcessor struct {
+	summarizer Summarizer
+	slack      SlackNotifier
+	notifiers  []Notifier
+	router     *routing.Router
+	store      IssueStore
+	logger     *zap.Logger
+	metrics    MetricsRecorder
+
+	changeThreshold  float64
+	prefilter        *Prefilter
+	twoStage         bool
+	deepPriority     string
+	oncall           OnCallResolver
+	mentionPriority  string
+	components       *components.Detector
+	translator       Translator
+	memoryTokens     int
+	incidents        IncidentNotifier
+	incidentConfig   IncidentConfig
+	coalescer        *Coalescer
+	coalesceMu       sync.Mutex
+	events           EventRecorder
+	inFlight         inFlightTracker
+	summaryLog       SummaryLog
+	labeler          Labeler
+	taxonomy         *ai.Taxonomy
+	aiTimeout        time.Duration
+	slackTimeout     time.Duration
+	reactions        ReactionSource
+	boostLabeler     Labeler
+	boostConfig      BoostConfig
+	fetcher          IssueFetcher
+	replies          ThreadNotifier
+	reanalysisConfig ReanalysisConfig
+	acknowledger     CommandAcknowledger
+	fixer            FixSuggester
+	commandReplies   ThreadNotifier
+	urgency          *Urgency
+	accessibility    AccessibilityConfig
+	checks           CheckPublisher
+	reactor          IssueReactor
+	ackConfig        AckConfig
+	linker           TicketLinker
+	identities       IdentityResolver
+	promptFeedback   PromptFeedback
+	moveReplies      ThreadNotifier
+	postProcessors   []PostProcessor
+	usage            UsageRecorder
+	infoRequester    InfoRequester
+	infoLabeler      Labeler
+	infoConfig       InfoRequestConfig
+	engagement       EngagementNotifier
+	engagementConfig EngagementConfig
+
+	moderation        ModerationConfig
+	moderationFetcher IssueFetcher
+
+	triageFetcher IssueFetcher
+}
+
+// NewIssueProcessor creates a new issue processor
+func NewIssueProcessor(
+	summarizer Summarizer,
+	slack SlackNotifier,
+	router *routing.Router,
+	issueStore IssueStore,
+	logger *zap.Logger,
+	metrics MetricsRecorder,
+) *IssueProcessor {
+	return &IssueProcessor{
+		summarizer: summarizer,
+		slack:      slack,
+		notifiers:  []Notifier{NewSlackNotifier(slack)},
+		router:     router,
+		store:      issueStore,
+		logger:     logger,
+		metrics:    metrics,
+
+		changeThreshold: DefaultChangeThreshold,
+		memoryTokens:    memory.DefaultMaxTokens,
+	}
+}
+
+// SetChangeThreshold sets the significance score at which edits are re-summarized
+func (p *IssueProcessor) SetChangeThreshold(threshold float64) {
+	p.changeThreshold = threshold
+}
+
+// SetPrefilter enables skipping the AI for issues the pre-filter finds trivial
+func (p *IssueProcessor) SetPrefilter(prefilter *Prefilter) {
+	p.prefilter = prefilter
+}
+
+// SetTwoStage makes every issue go through a quick triage first, with the deep
+// analysis only for issues at or above deepPriority. Other issues can be
+// analyzed on demand with DeepAnalyze.
+func (p *IssueProcessor) SetTwoStage(deepPriority string) {
+	p.twoStage = true
+	p.deepPriority = deepPriority
+}
+
+// SetOnCall mentions the engineer on call in messages for issues at or above minPriority
+func (p *IssueProcessor) SetOnCall(resolver OnCallResolver, minPriority string) {
+	p.oncall = resolver
+	p.mentionPriority = minPriority
+}
+
+// SetComponentDetector tags summaries with the components an issue affects
+func (p *IssueProcessor) SetComponentDetector(detector *components.Detector) {
+	p.components = detector
+}
+
+// SetTranslator translates non-English issues into English before analysis
+func (p *IssueProcessor) SetTranslator(translator Translator) {
+	p.translator = translator
+}
+
+// SetMemoryLimit sets the token budget for each issue's memory of earlier
+// analyses and follow-ups. Zero disables the memory.
+func (p *IssueProcessor) SetMemoryLimit(maxTokens int) {
+	p.memoryTokens = maxTokens
+}
+
+// ProcessIssue runs the pipeline behavior selected for the issue's action, or
+// the command of an issue comment. Each stage runs within its timeout, and all
+// of them stop when ctx is cancelled.
+func (p *IssueProcessor) ProcessIssue(ctx context.Context, issueData *github.IssueData) {
+	ctx, finish := p.trackInFlight(ctx, issueData)
+	defer finish()
+	if issueData.Command != nil {
+		p.runCommand(ctx, issueData)
+		return
+	}
+	p.processIssue(ctx, issueData)
+}
+
+// ProcessDelivery processes an issue like ProcessIssue, and returns the error
+// that stopped it, classified by apperrors, so a broker can redeliver it.
+// Skipped issues and comment commands return nil.
+func (p *IssueProcessor) ProcessDelivery(ctx context.Context, issueData *github.IssueData) error {
+	ctx, finish := p.trackInFlight(ctx, issueData)
+	defer finish()
+	if issueData.Command != nil {
+		p.runCommand(ctx, issueData)
+		return nil
+	}
+	_, err := p.deliverIssue(ctx, issueData)
+	return err
+}
+
+// processIssue runs the pipeline behavior and reports whether the issue was
+// delivered
+func (p *IssueProcessor) processIssue(ctx context.Context, issueData *github.IssueData) bool {
+	delivered, _ := p.deliverIssue(ctx, issueData)
+	return delivered
+}
+
+// deliverIssue runs the pipeline behavior and reports whether the issue was
+// delivered, or the error that stopped it. Skipped issues are not errors.
+func (p *IssueProcessor) deliverIssue(ctx context.Context, issueData *github.IssueData) (bool, error) {
+	start := time.Now()
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+
+	p.logger.Info("Processing issue",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("action", issueData.Action),
+		zap.String("behavior", string(issueData.Behavior)),
+	)
+
+	previous, _ := p.store.GetIssue(repository, number)
+	if previous != nil {
+		issueData.Memory = previous.Memory
+	}
+	history := issueData.Memory
+	p.detectOverride(issueData, previous)
+
+	// Issues waiting for their reporter stay quiet until the reporter responds
+	if p.awaitingInfo(ctx, issueData, previous) {
+		p.logger.Info("Waiting for the reporter to add missing details, skipping",
+			zap.String("repository", repository),
+			zap.Int("issue_number", number))
+		p.recordOutcome(issueData, start, Event{Status: "skipped", Detail: "waiting for the reporter"})
+		return false, nil
+	}
+
+	// A transferred issue is also opened in its new repository, where its
+	// moved record already has a posted message to update
+	if issueData.Action == "opened" && issueData.Behavior == github.BehaviorSummarize && previous != nil && previous.TransferredFrom != "" && previous.MessageTS != "" {
+		issueData.Behavior = github.BehaviorUpdate
+	}
+
+	// The first summary of an issue in a moderated repository waits for the
+	// moderator's approval, and is posted as approved once it has it
+	approved := approvedPreview(previous)
+	moderated := p.needsApproval(issueData, previous, approved)
+
+	var summary *ai.IssueSummary
+	var skipReason string
+	degraded := false // The AI failed and skipReason says why
+	generated := false
+	refresh := issueData.Behavior == github.BehaviorUpdate || issueData.Behavior == github.BehaviorEscalate
+	// Issues opened by CI get a build break card instead of a summary
+	ciFailure := issueData.CIFailure
+	switch issueData.Behavior {
+	case github.BehaviorUpdate, github.BehaviorEscalate:
+		// Refresh the posted message with the last summary, without calling the AI
+		if previous == nil || previous.MessageTS == "" || (previous.Summary == nil && previous.SkipReason == "" && previous.CIFailure == nil) {
+			p.logger.Info("No posted message to update, skipping",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number))
+			p.recordOutcome(issueData, start, Event{Status: "skipped", Detail: "no posted message to update"})
+			return false, nil
+		}
+		summary, skipReason, degraded = previous.Summary, previous.SkipReason, previous.Degraded
+		ciFailure = previous.CIFailure
+		if issueData.Behavior != github.BehaviorEscalate {
+			break
+		}
+		if escalated, ok := p.escalate(ctx, issueData, summary); ok {
+			summary = escalated
+			history = history.Append(p.memoryTokens, escalationMemory(summary))
+		}
+	case github.BehaviorResummarize:
+		change, known := editSignificance(issueData, previous)
+		if known && !change.Material(p.changeThreshold) && issueData.Command == nil {
+			p.logger.Info("Edit is not material, skipping re-summarization",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.Float64("score", change.Score),
+				zap.Float64("changed_ratio", change.ChangedRatio))
+			p.recordOutcome(issueData, start, Event{Status: "skipped", Detail: "edit not material"})
+			return false, nil
+		}
+	}
+	if approved {
+		summary, generated = previous.Summary, true
+	}
+
+	// Skip the AI for obviously low-value issues
+	if summary == nil && skipReason == "" && ciFailure == nil && p.prefilter != nil {
+		if result, trivial := p.prefilter.Check(issueData); trivial {
+			skipReason = result.Reason
+			p.metrics.RecordIssuePrefiltered(repository, result.Rule)
+			p.logger.Info("Skipping AI for trivial issue",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.String("rule", result.Rule),
+				zap.String("reason", result.Reason))
+		}
+	}
+
+	// Generate AI summary, from an English translation for other languages
+	var language string
+	if refresh || approved {
+		language = previous.Language
+	}
+	var latencies stageLatencies
+	if summary == nil && skipReason == "" && ciFailure == nil {
+		aiStart := time.Now()
+		aiCtx, done := p.stageContext(ctx, monitor.StageSummarize, p.aiTimeout)
+		analyzed, detected, translation := p.translate(aiCtx, issueData)
+		language = detected
+
+		var err error
+		summary, err = p.summarize(aiCtx, analyzed)
+		done()
+		latencies.summarize = time.Since(aiStart)
+		switch {
+		case err == nil:
+		case ctx.Err() == nil && (previous == nil || previous.MessageTS == ""):
+			// Post the issue without a summary rather than not at all. It can
+			// be retried from Slack, and the next edit summarizes it again.
+			p.logger.Error("Failed to generate summary, posting the issue without one",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.Error(err))
+			skipReason, degraded = degradedReason(err), true
+		default:
+			p.logger.Error("Failed to generate summary", zap.Error(err))
+			p.recordOutcome(issueData, start, Event{Status: "error", Stage: monitor.StageSummarize, Detail: err.Error()})
+			p.metrics.RecordPipelineFailure(monitor.StageSummarize)
+			p.unacknowledgeIssue(ctx, issueData, previous)
+			return false, fmt.Errorf("failed to summarize issue: %w", err)
+		}
+		if summary != nil {
+			summary.Components = p.detectComponents(issueData)
+			if translation != nil {
+				summary.Language, summary.Original = translation.Language, translation.Snippet
+			}
+			summary = p.postProcess(repository, summary)
+			history = history.Append(p.memoryTokens, summaryMemory(summary))
+			generated = true
+		}
+	}
+
+	// Issues many people upvote move up a priority level
+	if boosted, ok := p.boost(ctx, issueData, summary); ok {
+		summary = boosted
+		history = history.Append(p.memoryTokens, boostMemory(summary, issueData.Activity.ThumbsUp))
+	}
+	if generated && !moderated {
+		p.applyLabels(ctx, issueData, summary)
+	}
+
+	// Pick the channel and layout for this issue
+	labels := issueLabels(issueData)
+	routeIssue := routing.Issue{
+		Repository: repository,
+		Title:      issueData.Issue.GetTitle(),
+		Author:     issueData.Issue.GetUser().GetLogin(),
+		Priority:   "low",
+		Labels:     labels,
+	}
+	if summary != nil {
+		routeIssue.Priority, routeIssue.Category = summary.Priority, summary.Category
+		routeIssue.Components = summary.Components
+	}
+	route := p.router.Route(routeIssue)
+
+	// Edits and updates keep the layout of the message they replace.
+	// Escalations routed to another channel are posted there instead.
+	replace := previous != nil && previous.MessageTS != "" && issueData.Behavior != github.BehaviorSummarize
+	if replace && issueData.Behavior == github.BehaviorEscalate && route.Channel != previous.Channel {
+		replace = false
+	}
+	layout, target := route.Layout, route.Channel
+	if replace {
+		target = previous.Channel
+		if previous.Layout != "" {
+			layout = previous.Layout
+		}
+	}
+	layout = p.channelLayout(target, layout)
+
+	// Generate Slack message
+	p.resolveMentions(ctx, issueData)
+	var slackMessage map[string]interface{}
+	if ciFailure != nil {
+		slackMessage = p.summarizer.GenerateBuildBreakSlackMessage(issueData, ciFailure)
+	} else if degraded {
+		slackMessage = p.summarizer.GenerateDegradedSlackMessage(issueData, skipReason)
+	} else if skipReason != "" {
+		slackMessage = p.summarizer.GenerateSkippedSlackMessage(issueData, skipReason)
+	} else if layout == routing.LayoutCompact {
+		slackMessage = p.summarizer.GenerateCompactSlackMessage(issueData, summary)
+	} else if layout == routing.LayoutPlain {
+		slackMessage = p.summarizer.GeneratePlainSlackMessage(issueData, summary)
+	} else {
+		slackMessage = p.summarizer.GenerateSlackMessage(issueData, summary)
+	}
+
+	// Mention whoever is on call for high-priority issues. Updates keep the
+	// original mention rather than pinging the next person in the rotation.
+	var onCall string
+	if replace {
+		onCall = previous.OnCall
+	} else if summary != nil && p.oncall != nil && ai.PriorityAtLeast(summary.Priority, p.mentionPriority) {
+		if shift, ok := p.oncall.OnCall(repository, time.Now()); ok {
+			onCall = shift.UserID
+			p.logger.Info("Mentioning on-call engineer",
+				zap.String("repository", repository),
+				zap.Int("issue_number", number),
+				zap.String("schedule", shift.Schedule),
+				zap.String("user", shift.UserID))
+		}
+	}
+	if onCall != "" {
+		slackMessage = withOnCallMention(slackMessage, onCall)
+	}
+	if summary != nil && p.urgency != nil {
+		slackMessage = p.withUrgency(slackMessage, issueData, target, summary.Priority, !replace)
+	}
+
+	if moderated && summary != nil {
+		held := &store.IssueRecord{
+			Repository:  repository,
+			Number:      number,
+			Title:       issueData.Issue.GetTitle(),
+			Body:        issueData.Issue.GetBody(),
+			State:       issueData.Issue.GetState(),
+			Source:      issueData.Source,
+			Environment: issueData.Environment,
+			Layout:      layout,
+			Language:    language,
+			Memory:      history,
+			Labels:      labels,
+			OpenedAt:    issueData.Issue.GetCreatedAt().Time,
+		}
+		if err := p.holdForApproval(ctx, issueData, summary, p.withoutEmoji(slackMessage, target), route, held); err != nil {
+			p.logger.Error("Failed to hold summary for approval", zap.Error(err))
+			p.recordOutcome(issueData, start, Event{Status: "error", Stage: monitor.StageNotify, Detail: err.Error(), Priority: summary.Priority})
+			p.metrics.RecordPipelineFailure(monitor.StageNotify)
+			return false, fmt.Errorf("failed to hold summary for approval: %w", err)
+		}
+		p.recordOutcome(issueData, start, Event{Status: "skipped", Detail: "awaiting moderator approval", Priority: summary.Priority})
+		return false, nil
+	}
+
+	slackStart := time.Now()
+	slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+	defer done()
+
+	// Give incidents a dedicated channel, linked from the main message
+	var incidentChannel string
+	incidentArchived := false
+	if previous != nil {
+		incidentChannel, incidentArchived = previous.IncidentChannel, previous.IncidentArchived
+	}
+	if incidentChannel == "" && p.incidents != nil && issueData.Behavior != github.BehaviorUpdate && p.incidentConfig.triggered(summary) {
+		incidentChannel = p.openIncident(slackCtx, issueData, slackMessage)
+	}
+	if incidentChannel != "" {
+		slackMessage = withIncidentLink(slackMessage, incidentChannel)
+	}
+	slackMessage = p.withoutEmoji(slackMessage, target)
+
+	// Send to the route's notifiers, updating the existing Slack message in
+	// place where possible. Over the repository's rate limit, the issue joins
+	// a rolling message instead and is stored without a message of its own.
+	channel, ts := route.Channel, ""
+	meta := issueMeta(issueData, route)
+	if replace {
+		channel, ts = previous.Channel, previous.MessageTS
+		meta.Previous = Delivery{Channel: channel, MessageID: ts}
+	}
+	var err error
+	if !replace && issueData.Behavior != github.BehaviorEscalate && p.coalescer != nil && !p.coalescer.Allow(repository, time.Now()) {
+		channel, err = p.coalesce(slackCtx, issueData, summary, route.Channel)
+		if err == nil {
+			p.recordSlackMessage(repository)
+		}
+	} else {
+		var deliveries map[string]Delivery
+		deliveries, err = p.notify(slackCtx, Summary{Issue: summary, SkipReason: skipReason, Message: slackMessage}, meta, route.Notifiers)
+		if delivery, ok := deliveries[SlackNotifierName]; ok {
+			channel, ts = delivery.Channel, delivery.MessageID
+		}
+	}
+	if err != nil {
+		p.logger.Error("Failed to send notification", zap.Error(err))
+		p.recordOutcome(issueData, start, Event{Status: "error", Stage: monitor.StageNotify, Detail: err.Error(), Priority: summaryPriority(summary)})
+		p.metrics.RecordPipelineFailure(monitor.StageNotify)
+		p.unacknowledgeIssue(ctx, issueData, previous)
+		return false, fmt.Errorf("failed to send notification: %w", err)
+	}
+	latencies.notify = time.Since(slackStart)
+
+	// Incident channels are archived once the issue is closed
+	if incidentChannel != "" && !incidentArchived && p.incidents != nil && issueData.Issue.GetState() == "closed" {
+		incidentArchived = p.closeIncident(slackCtx, repository, number, incidentChannel)
+	}
+
+	// Mark summarized issues as triaged on GitHub
+	var reaction string
+	if summary != nil {
+		reaction = p.acknowledgeIssue(ctx, issueData, previous)
+	} else if previous != nil {
+		reaction = previous.Reaction
+	}
+
+	// Ask the reporter of an incomplete bug report for the missing details
+	infoRequestedAt, awaitingInfo := infoRequest(previous)
+	if generated && p.requestInfo(ctx, issueData, summary, previous) {
+		infoRequestedAt, awaitingInfo = time.Now(), true
+	}
+
+	// Keep the title and body the summary was generated from, so later edits
+	// are compared against the version that was actually summarized
+	record := &store.IssueRecord{
+		Repository: repository,
+		Number:     number,
+		Title:      issueData.Issue.GetTitle(),
+		Body:       issueData.Issue.GetBody(),
+		State:      issueData.Issue.GetState(),
+		Source:     issueData.Source,
+		Summary:    summary,
+		SkipReason: skipReason,
+		Degraded:   degraded,
+		CIFailure:  ciFailure,
+		Channel:    channel,
+		MessageTS:  ts,
+		Layout:     layout,
+		OnCall:     onCall,
+		Language:   language,
+		Memory:     history,
+		Labels:     labels,
+		Reaction:   reaction,
+
+		Environment: issueData.Environment,
+
+		OpenedAt:       issueData.Issue.GetCreatedAt().Time,
+		AcknowledgedAt: acknowledgedAt(issueData, previous),
+		ClosedAt:       issueData.Issue.GetClosedAt().Time,
+		CloseReason:    closeReason(issueData),
+
+		IncidentChannel:  incidentChannel,
+		IncidentArchived: incidentArchived,
+
+		InfoRequestedAt: infoRequestedAt,
+		AwaitingInfo:    awaitingInfo,
+
+		Route: route.Rule,
+	}
+	carryEngagement(record, previous)
+	carryTriage(record, previous)
+	creditTriage(record, issueData)
+	if previous != nil {
+		record.PreviewedAt, record.ApprovedBy = previous.PreviewedAt, previous.ApprovedBy
+	}
+	if refresh {
+		record.Title, record.Body = previous.Title, previous.Body
+	}
+	// Overrides belong to the summary they corrected, not to a new one
+	if generated {
+		record.AnalyzedAt = time.Now()
+	} else if previous != nil {
+		record.Overridden = previous.Overridden
+		record.AnalyzedAt = previous.AnalyzedAt
+	}
+	p.store.SaveIssue(record)
+
+	// Show new verdicts on the issue's pull requests too
+	if generated || (previous != nil && previous.Summary != nil && summary != nil && previous.Summary.Priority != summary.Priority) {
+		p.publishCheck(ctx, issueData, summary)
+	}
+	if generated {
+		p.linkTicket(ctx, issueData, summary)
+	}
+
+	// Record successful processing
+	duration := p.recordOutcome(issueData, start, Event{Status: "success", Detail: skipReason, Priority: summaryPriority(summary), Channel: channel})
+	if summary != nil && !refresh {
+		p.metrics.RecordIssueSummaryGenerated(repository, "issue")
+	}
+	if generated {
+		p.metrics.RecordSummaryConfidence(summary.Confidence)
+		p.metrics.RecordSummaryAssignment(repository, summary.Priority, summary.Category)
+		p.recordSummaryUsage(repository, summary)
+	}
+
+	receivedAt := issueData.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = start
+	}
+	p.metrics.RecordPipelineSuccess(issueData.EventType, issueData.DeliveryID, receivedAt)
+	latencies.processing, latencies.endToEnd = duration, time.Since(receivedAt)
+	p.logSummary(issueData, summary, generated, skipReason, channel, latencies)
+
+	p.logger.Info("Successfully processed issue",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("priority", routeIssue.Priority),
+		zap.String("category", routeIssue.Category),
+		zap.Bool("ai_skipped", skipReason != ""),
+		zap.String("route", route.Rule),
+		zap.Bool("updated", replace),
+		zap.Duration("processing_time", duration),
+	)
+	return true, nil
+}
+
+// degradedReason explains in a message why the AI could not summarize an issue
+func degradedReason(err error) string {
+	switch apperrors.Classify(err) {
+	case "circuit_open":
+		return "OpenAI is not responding"
+	case "timeout":
+		return "the AI timed out"
+	case "rate_limited", "quota_exceeded":
+		return "the OpenAI rate limit or token quota was reached"
+	default:
+		return "the AI could not summarize the issue"
+	}
+}
+
+// summarize generates the summary for an issue, running only the triage stage
+// for lower-priority issues in two-stage mode
+func (p *IssueProcessor) summarize(ctx context.Context, issueData *github.IssueData) (*ai.IssueSummary, error) {
+	if !p.twoStage {
+		return p.summarizer.SummarizeIssue(ctx, issueData)
+	}
+
+	triage, err := p.summarizer.TriageIssue(ctx, issueData)
+	if err != nil {
+		return nil, err
+	}
+	if !ai.PriorityAtLeast(triage.Priority, p.deepPriority) {
+		return triage, nil
+	}
+
+	summary, err := p.summarizer.SummarizeIssue(ctx, issueData)
+	if err != nil {
+		// The triage result is still worth posting
+		p.logger.Warn("Deep analysis failed, posting triage summary", zap.Error(err))
+		return triage, nil
+	}
+	summary.Usage = append(triage.Usage, summary.Usage...)
+	return summary, nil
+}
+
+// DeepAnalyze runs the full analysis for an issue on demand and replaces its
+// Slack message, e.g. when a user clicks "Deep Analysis" on a triage summary
+func (p *IssueProcessor) DeepAnalyze(ctx context.Context, issueData *github.IssueData, channelID, ts string) error {
+	ctx, finish := p.trackInFlight(ctx, issueData)
+	defer finish()
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+
+	layout, onCall := routing.LayoutDetailed, ""
+	var incidentChannel string
+	incidentArchived := false
+	previous, found := p.store.GetIssue(repository, number)
+	if found {
+		issueData.Memory = previous.Memory
+		incidentChannel, incidentArchived = previous.IncidentChannel, previous.IncidentArchived
+		if previous.MessageTS == ts {
+			if previous.Layout != "" {
+				layout = previous.Layout
+			}
+			onCall = previous.OnCall
+		}
+	}
+
+	aiCtx, done := p.stageContext(ctx, monitor.StageSummarize, p.aiTimeout)
+	analyzed, language, translation := p.translate(aiCtx, issueData)
+	summary, err := p.summarizer.SummarizeIssue(aiCtx, analyzed)
+	done()
+	if err != nil {
+		return err
+	}
+	summary.Components = p.detectComponents(issueData)
+	if translation != nil {
+		summary.Language, summary.Original = translation.Language, translation.Snippet
+	}
+	summary = p.postProcess(repository, summary)
+
+	p.resolveMentions(ctx, issueData)
+	layout = p.channelLayout(channelID, layout)
+	var slackMessage map[string]interface{}
+	if layout == routing.LayoutCompact {
+		slackMessage = p.summarizer.GenerateCompactSlackMessage(issueData, summary)
+	} else if layout == routing.LayoutPlain {
+		slackMessage = p.summarizer.GeneratePlainSlackMessage(issueData, summary)
+	} else {
+		slackMessage = p.summarizer.GenerateSlackMessage(issueData, summary)
+	}
+	if onCall != "" {
+		slackMessage = withOnCallMention(slackMessage, onCall)
+	}
+	if p.urgency != nil {
+		slackMessage = p.withUrgency(slackMessage, issueData, channelID, summary.Priority, false)
+	}
+	if incidentChannel != "" {
+		slackMessage = withIncidentLink(slackMessage, incidentChannel)
+	}
+	slackMessage = p.withoutEmoji(slackMessage, channelID)
+	slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+	err = p.slack.UpdateIssueSummary(slackCtx, channelID, ts, slackMessage)
+	done()
+	if err != nil {
+		return err
+	}
+	p.recordSlackMessage(repository)
+
+	infoRequestedAt, awaitingInfo := infoRequest(previous)
+	record := &store.IssueRecord{
+		Repository: repository,
+		Number:     number,
+		Title:      issueData.Issue.GetTitle(),
+		Body:       issueData.Issue.GetBody(),
+		State:      issueData.Issue.GetState(),
+		Source:     issueData.Source,
+		Summary:    summary,
+		Channel:    channelID,
+		MessageTS:  ts,
+		Layout:     layout,
+		OnCall:     onCall,
+		Language:   language,
+		Memory:     issueData.Memory.Append(p.memoryTokens, summaryMemory(summary)),
+		Labels:     issueLabels(issueData),
+		AnalyzedAt: time.Now(),
+		Reaction:   p.acknowledgeIssue(ctx, issueData, previous),
+
+		Environment: issueData.Environment,
+
+		OpenedAt:       issueData.Issue.GetCreatedAt().Time,
+		AcknowledgedAt: acknowledgedAt(issueData, previous),
+		ClosedAt:       issueData.Issue.GetClosedAt().Time,
+		CloseReason:    closeReason(issueData),
+
+		IncidentChannel:  incidentChannel,
+		IncidentArchived: incidentArchived,
+
+		InfoRequestedAt: infoRequestedAt,
+		AwaitingInfo:    awaitingInfo,
+	}
+	if found {
+		record.Route = previous.Route
+	}
+	carryEngagement(record, previous)
+	carryTriage(record, previous)
+	p.store.SaveIssue(record)
+	p.metrics.RecordIssueSummaryGenerated(repository, "issue")
+	p.metrics.RecordSummaryConfidence(summary.Confidence)
+	p.metrics.RecordSummaryAssignment(repository, summary.Priority, summary.Category)
+	p.recordSummaryUsage(repository, summary)
+
+	p.logger.Info("Completed deep analysis",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("priority", summary.Priority),
+	)
+	return nil
+}
+
+// IssueMemory returns the remembered analyses and follow-ups for an issue
+func (p *IssueProcessor) IssueMemory(repository string, number int) memory.Memory {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok {
+		return nil
+	}
+	return record.Memory
+}
+
+// IssueSummary returns the last summary the bot posted for an issue, shown
+// next to issues that reference it
+func (p *IssueProcessor) IssueSummary(repository string, number int) (string, bool) {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok || record.Summary == nil {
+		return "", false
+	}
+	return record.Summary.Summary, true
+}
+
+// RememberFollowUp adds a follow-up question and the AI's answer to the
+// memory of an issue the bot has already posted about
+func (p *IssueProcessor) RememberFollowUp(repository string, number int, question, answer string) {
+	record, ok := p.store.GetIssue(repository, number)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	record.Memory = record.Memory.Append(p.memoryTokens,
+		memory.Entry{Kind: memory.KindQuestion, Text: question, At: now},
+		memory.Entry{Kind: memory.KindAnswer, Text: answer, At: now},
+	)
+	record.UpdatedAt = now
+	p.store.SaveIssue(record)
+}
+
+// acknowledgedAt returns when a maintainer first responded to an issue. The
+// earliest response seen is kept, as comments beyond the first page, or
+// deleted since, are not in issueData.
+func acknowledgedAt(issueData *github.IssueData, previous *store.IssueRecord) time.Time {
+	first := github.FirstMaintainerResponse(issueData.Issue, issueData.Comments)
+	if previous != nil && !previous.AcknowledgedAt.IsZero() && (first.IsZero() || previous.AcknowledgedAt.Before(first)) {
+		return previous.AcknowledgedAt
+	}
+	return first
+}
+
+// closeReason returns GitHub's state reason for a closed issue, empty while
+// it is open
+func closeReason(issueData *github.IssueData) string {
+	if issueData.Issue.GetState() != "closed" {
+		return ""
+	}
+	return issueData.Issue.GetStateReason()
+}
+
+// summaryMemory condenses a summary into a memory entry for later prompts
+func summaryMemory(summary *ai.IssueSummary) memory.Entry {
+	stage := "Assessed"
+	if summary.Triage {
+		stage = "Triaged"
+	}
+	return memory.Entry{
+		Kind: memory.KindSummary,
+		Text: fmt.Sprintf("%s as %s priority %s: %s", stage, summary.Priority, summary.Category, summary.Summary),
+		At:   time.Now(),
+	}
+}
+
+// translate detects the language of an issue and, with a translator set,
+// returns a copy of the issue in English for the AI to analyze. If the
+// translation fails the original is analyzed instead.
+func (p *IssueProcessor) translate(ctx context.Context, issueData *github.IssueData) (*github.IssueData, string, *ai.Translation) {
+	language := ai.DetectLanguage(issueData.Issue.GetTitle() + "\n\n" + issueData.Issue.GetBody())
+	if p.translator == nil || language == "" || language == ai.LanguageEnglish {
+		return issueData, language, nil
+	}
+
+	repository := issueData.Repository.GetFullName()
+	translation, err := p.translator.TranslateIssue(ctx, issueData, language)
+	if err != nil {
+		p.logger.Warn("Failed to translate issue, analyzing the original",
+			zap.String("repository", repository),
+			zap.Int("issue_number", issueData.Issue.GetNumber()),
+			zap.String("language", language),
+			zap.Error(err))
+		return issueData, language, nil
+	}
+	p.metrics.RecordIssueTranslated(repository, language)
+
+	// The translated body replaces any form fields, which are still in the original language
+	issue := *issueData.Issue
+	issue.Title, issue.Body = &translation.Title, &translation.Body
+	translated := *issueData
+	translated.Issue = &issue
+	translated.FormFields = nil
+	return &translated, language, translation
+}
+
+// detectComponents finds the components touched by the issue's linked commits or labels
+func (p *IssueProcessor) detectComponents(issueData *github.IssueData) []string {
+	if p.components == nil {
+		return nil
+	}
+
+	files := make([]string, 0, len(issueData.Files))
+	for _, file := range issueData.Files {
+		files = append(files, file.GetFilename())
+	}
+	labels := make([]string, 0, len(issueData.Issue.Labels))
+	for _, label := range issueData.Issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	return p.components.Detect(files, labels)
+}
+
+// withOnCallMention puts a mention of the on-call engineer at the top of a message
+func withOnCallMention(message map[string]interface{}, userID string) map[string]interface{} {
+	return prependSection(message, fmt.Sprintf(":rotating_light: On call: <@%s>", userID))
+}
+
+// prependSection puts a text section at the top of a message
+func prependSection(message map[string]interface{}, text string) map[string]interface{} {
+	block := map[string]interface{}{
+		"type": "section",
+		"text": map[string]interface{}{
+			"type": "mrkdwn",
+			"text": text,
+		},
+	}
+
+	// Templates render blocks as generic JSON arrays
+	switch blocks := message["blocks"].(type) {
+	case []map[string]interface{}:
+		message["blocks"] = append([]map[string]interface{}{block}, blocks...)
+	case []interface{}:
+		message["blocks"] = append([]interface{}{block}, blocks...)
+	}
+	return message
+}
+
+// editSignificance compares an edited issue with the stored previous version,
+// falling back to the previous title and body sent with the webhook. known is
+// false when there is no previous version to compare with.
+func editSignificance(issueData *github.IssueData, previous *store.IssueRecord) (ChangeSignificance, bool) {
+	title, body := issueData.Issue.GetTitle(), issueData.Issue.GetBody()
+
+	if previous != nil {
+		return CompareIssue(previous.Title, previous.Body, title, body), true
+	}
+
+	changes := issueData.Changes
+	if changes == nil || (changes.Title == nil && changes.Body == nil) {
+		return ChangeSignificance{}, false
+	}
+	oldTitle, oldBody := title, body
+	if changes.Title != nil {
+		oldTitle = changes.Title.GetFrom()
+	}
+	if changes.Body != nil {
+		oldBody = changes.Body.GetFrom()
+	}
+	return CompareIssue(oldTitle, oldBody, title, body), true
+}
@@ -0,0 +1,468 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/components"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/oncall"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/store"
+)
+
+type fakeSummarizer struct {
+	calls          int
+	triageCalls    int
+	triagePriority string
+	category       string
+	promptVersion  string
+	missingInfo    []string
+	lastMemory     memory.Memory
+	err            error // Returned by SummarizeIssue when set
+}
+
+func (f *fakeSummarizer) SummarizeIssue(ctx context.Context, issueData *github.IssueData) (*ai.IssueSummary, error) {
+	f.calls++
+	f.lastMemory = issueData.Memory
+	if f.err != nil {
+		return nil, f.err
+	}
+	category := f.category
+	if category == "" {
+		category = "bug"
+	}
+	return &ai.IssueSummary{
+		Title:         issueData.Issue.GetTitle(),
+		Priority:      "high",
+		Category:      category,
+		Usage:         []ai.Usage{{Model: "gpt-4", PromptTokens: 1000, CompletionTokens: 100}},
+		PromptVersion: f.promptVersion,
+		MissingInfo:   f.missingInfo,
+	}, nil
+}
+
+func (f *fakeSummarizer) TriageIssue(ctx context.Context, issueData *github.IssueData) (*ai.IssueSummary, error) {
+	f.triageCalls++
+	return &ai.IssueSummary{Title: issueData.Issue.GetTitle(), Priority: f.triagePriority, Category: "bug", Triage: true}, nil
+}
+
+func (f *fakeSummarizer) GenerateSlackMessage(issueData *github.IssueData, summary *ai.IssueSummary) map[string]interface{} {
+	return map[string]interface{}{"state": issueData.Issue.GetState(), "layout": routing.LayoutDetailed, "triage": summary.Triage, "blocks": []interface{}{}}
+}
+
+func (f *fakeSummarizer) GenerateCompactSlackMessage(issueData *github.IssueData, summary *ai.IssueSummary) map[string]interface{} {
+	return map[string]interface{}{"state": issueData.Issue.GetState(), "layout": routing.LayoutCompact, "triage": summary.Triage, "blocks": []interface{}{}}
+}
+
+func (f *fakeSummarizer) GeneratePlainSlackMessage(issueData *github.IssueData, summary *ai.IssueSummary) map[string]interface{} {
+	return map[string]interface{}{"state": issueData.Issue.GetState(), "layout": routing.LayoutPlain, "triage": summary.Triage, "blocks": []interface{}{}}
+}
+
+func (f *fakeSummarizer) GenerateSkippedSlackMessage(issueData *github.IssueData, reason string) map[string]interface{} {
+	return map[string]interface{}{"state": issueData.Issue.GetState(), "skipped": reason}
+}
+
+func (f *fakeSummarizer) GenerateDegradedSlackMessage(issueData *github.IssueData, reason string) map[string]interface{} {
+	return map[string]interface{}{"state": issueData.Issue.GetState(), "degraded": reason}
+}
+
+func (f *fakeSummarizer) GenerateBuildBreakSlackMessage(issueData *github.IssueData, failure *github.CIFailure) map[string]interface{} {
+	return map[string]interface{}{"state": issueData.Issue.GetState(), "build_break": failure.Step}
+}
+
+type fakeNotifier struct {
+	posts   []map[string]interface{}
+	updates []map[string]interface{}
+}
+
+func (f *fakeNotifier) PostIssueSummary(ctx context.Context, channelID string, message map[string]interface{}) (string, string, error) {
+	f.posts = append(f.posts, message)
+	return channelID, "1700000000.000100", nil
+}
+
+func (f *fakeNotifier) UpdateIssueSummary(ctx context.Context, channelID, ts string, message map[string]interface{}) error {
+	f.updates = append(f.updates, message)
+	return nil
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) RecordIssueProcessed(string, string, string, time.Duration) {}
+func (nopMetrics) RecordIssueSummaryGenerated(string, string)                 {}
+func (nopMetrics) RecordPipelineSuccess(string, string, time.Time)            {}
+func (nopMetrics) RecordPipelineFailure(string)                               {}
+func (nopMetrics) RecordIssuePrefiltered(string, string)                      {}
+func (nopMetrics) RecordIssueTranslated(string, string)                       {}
+func (nopMetrics) RecordNotificationCoalesced(string)                         {}
+func (nopMetrics) RecordStageTimeout(string)                                  {}
+func (nopMetrics) RecordSummaryConfidence(float64)                            {}
+func (nopMetrics) RecordSummaryOverride(float64, []string)                    {}
+func (nopMetrics) RecordSummaryAssignment(string, string, string)             {}
+func (nopMetrics) RecordPriorityBoost(string)                                 {}
+func (nopMetrics) RecordNotification(string, string)                          {}
+
+func newTestProcessor(t *testing.T) (*IssueProcessor, *fakeSummarizer, *fakeNotifier) {
+	t.Helper()
+	router, err := routing.NewRouter([]routing.Rule{{Name: "compact", Layout: routing.LayoutCompact}}, "C123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summarizer := &fakeSummarizer{}
+	notifier := &fakeNotifier{}
+	return NewIssueProcessor(summarizer, notifier, router, store.NewMemoryStore(), zap.NewNop(), nopMetrics{}), summarizer, notifier
+}
+
+func newIssueData(action string, behavior github.Behavior, state, body string) *github.IssueData {
+	return &github.IssueData{
+		Issue: &gogithub.Issue{
+			Number: gogithub.Int(7),
+			Title:  gogithub.String("Crash on start"),
+			Body:   gogithub.String(body),
+			State:  gogithub.String(state),
+		},
+		Repository: &gogithub.Repository{FullName: gogithub.String("owner/repo")},
+		EventType:  "issues",
+		Action:     action,
+		Behavior:   behavior,
+	}
+}
+
+func TestProcessIssueBehaviors(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+
+	// Closing an issue that was never posted does nothing
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "body"))
+	if len(notifier.posts)+len(notifier.updates) != 0 {
+		t.Fatal("Expected no Slack calls for an unknown issue")
+	}
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if summarizer.calls != 1 || len(notifier.posts) != 1 {
+		t.Fatalf("Expected one summary and one post, got %d and %d", summarizer.calls, len(notifier.posts))
+	}
+
+	// Whitespace-only edits are not re-summarized
+	edited := newIssueData("edited", github.BehaviorResummarize, "open", "It  crashes\n")
+	edited.Changes = &gogithub.EditChange{Body: &gogithub.EditBody{From: gogithub.String("It crashes")}}
+	processor.ProcessIssue(context.Background(), edited)
+	if summarizer.calls != 1 || len(notifier.updates) != 0 {
+		t.Fatal("Expected whitespace edit to be skipped")
+	}
+
+	// Material edits re-summarize and update the posted message
+	edited = newIssueData("edited", github.BehaviorResummarize, "open", "It crashes with a nil pointer in main.go")
+	edited.Changes = &gogithub.EditChange{Body: &gogithub.EditBody{From: gogithub.String("It crashes")}}
+	processor.ProcessIssue(context.Background(), edited)
+	if summarizer.calls != 2 || len(notifier.updates) != 1 || len(notifier.posts) != 1 {
+		t.Fatalf("Expected edit to update the message, got %d summaries, %d posts, %d updates",
+			summarizer.calls, len(notifier.posts), len(notifier.updates))
+	}
+
+	// Closing updates the message without calling the AI and keeps its layout
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes with a nil pointer in main.go"))
+	if summarizer.calls != 2 || len(notifier.updates) != 2 {
+		t.Fatal("Expected close to update the message without a new summary")
+	}
+	last := notifier.updates[1]
+	if last["state"] != "closed" || last["layout"] != routing.LayoutCompact {
+		t.Errorf("Unexpected update %+v", last)
+	}
+}
+
+func TestProcessIssuePrefilter(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	processor.SetPrefilter(NewPrefilter(PrefilterConfig{TitleKeywords: []string{"test"}, MinBodyLength: 10}))
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", ""))
+	if summarizer.calls != 0 || len(notifier.posts) != 1 || notifier.posts[0]["skipped"] == nil {
+		t.Fatalf("Expected a skipped note without an AI call, got %d calls and posts %+v", summarizer.calls, notifier.posts)
+	}
+
+	// Closing refreshes the note, still without the AI
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", ""))
+	if summarizer.calls != 0 || len(notifier.updates) != 1 || notifier.updates[0]["skipped"] == nil {
+		t.Fatalf("Expected the note to be updated, got %+v", notifier.updates)
+	}
+
+	// Filling in the description turns the note into a full summary
+	edited := newIssueData("edited", github.BehaviorResummarize, "open", "The bot crashes on every ping delivery")
+	processor.ProcessIssue(context.Background(), edited)
+	if summarizer.calls != 1 || len(notifier.updates) != 2 {
+		t.Fatalf("Expected the edit to be summarized, got %d calls", summarizer.calls)
+	}
+}
+
+func TestProcessIssueDegraded(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	summarizer.err = fmt.Errorf("failed to generate summary: openai: %w", apperrors.ErrCircuitOpen)
+
+	// New issues are posted without a summary when the AI fails
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(notifier.posts) != 1 || notifier.posts[0]["degraded"] != "OpenAI is not responding" {
+		t.Fatalf("Expected the raw issue posted, got %+v", notifier.posts)
+	}
+
+	// Closing refreshes the raw issue message
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if len(notifier.updates) != 1 || notifier.updates[0]["degraded"] == nil || notifier.updates[0]["state"] != "closed" {
+		t.Fatalf("Expected the raw issue message updated, got %+v", notifier.updates)
+	}
+
+	// Edits leave the posted message alone while the AI keeps failing
+	edited := newIssueData("edited", github.BehaviorResummarize, "open", "It crashes on every ping delivery")
+	processor.ProcessIssue(context.Background(), edited)
+	if len(notifier.updates) != 1 {
+		t.Fatalf("Expected no update while the AI fails, got %+v", notifier.updates)
+	}
+
+	summarizer.err = nil
+	processor.ProcessIssue(context.Background(), edited)
+	if len(notifier.updates) != 2 || notifier.updates[1]["degraded"] != nil {
+		t.Fatalf("Expected the message replaced with a summary, got %+v", notifier.updates)
+	}
+	if record, _ := processor.store.GetIssue("owner/repo", 7); record.Degraded || record.Summary == nil {
+		t.Errorf("Expected the record to hold the summary, got %+v", record)
+	}
+}
+
+func TestProcessDelivery(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	summarizer.err = fmt.Errorf("failed to generate summary: openai: %w", apperrors.ErrUnavailable)
+
+	// Updates of issues never posted are skipped, not failed
+	if err := processor.ProcessDelivery(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes")); err != nil {
+		t.Errorf("Expected a skipped issue to succeed, got %v", err)
+	}
+
+	// New issues are posted without a summary, so the delivery is handled
+	if err := processor.ProcessDelivery(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")); err != nil || len(notifier.posts) != 1 {
+		t.Fatalf("Expected the raw issue posted, got %v", err)
+	}
+
+	// Edits wait for the AI, so the broker redelivers them
+	err := processor.ProcessDelivery(context.Background(), newIssueData("edited", github.BehaviorResummarize, "open", "It crashes on every ping delivery"))
+	if err == nil || !apperrors.IsRetryable(err) {
+		t.Errorf("Expected a retryable error while OpenAI is down, got %v", err)
+	}
+}
+
+func TestDeepAnalyzeRetriesDegraded(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	summarizer.err = errors.New("malformed response")
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(notifier.posts) != 1 || notifier.posts[0]["degraded"] != "the AI could not summarize the issue" {
+		t.Fatalf("Expected the raw issue posted, got %+v", notifier.posts)
+	}
+
+	// "Retry AI summary" replaces the message once the AI works again
+	summarizer.err = nil
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	issue := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	if err := processor.DeepAnalyze(context.Background(), issue, record.Channel, record.MessageTS); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.updates) != 1 || notifier.updates[0]["degraded"] != nil {
+		t.Fatalf("Expected the message replaced with a summary, got %+v", notifier.updates)
+	}
+	if record, _ := processor.store.GetIssue("owner/repo", 7); record.Degraded {
+		t.Error("Expected the record to no longer be degraded")
+	}
+}
+
+func TestProcessIssueTwoStage(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	processor.SetTwoStage("high")
+
+	// Low-priority issues only get the triage
+	summarizer.triagePriority = "medium"
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if summarizer.triageCalls != 1 || summarizer.calls != 0 || notifier.posts[0]["triage"] != true {
+		t.Fatalf("Expected triage only, got %d triage and %d deep calls", summarizer.triageCalls, summarizer.calls)
+	}
+
+	// Deep analysis on demand replaces the triage summary
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	if err := processor.DeepAnalyze(context.Background(), issueData, "C123", "1700000000.000100"); err != nil {
+		t.Fatal(err)
+	}
+	if summarizer.calls != 1 || len(notifier.updates) != 1 || notifier.updates[0]["triage"] != false {
+		t.Fatalf("Expected deep analysis to update the message, got %+v", notifier.updates)
+	}
+	if notifier.updates[0]["layout"] != routing.LayoutCompact {
+		t.Errorf("Expected deep analysis to keep the compact layout, got %v", notifier.updates[0]["layout"])
+	}
+
+	// High-priority issues get the deep analysis straight away
+	summarizer.triagePriority = "high"
+	processor.ProcessIssue(context.Background(), newIssueData("reopened", github.BehaviorSummarize, "open", "It crashes"))
+	if summarizer.triageCalls != 2 || summarizer.calls != 2 || notifier.posts[1]["triage"] != false {
+		t.Fatalf("Expected triage and deep analysis, got %d triage and %d deep calls", summarizer.triageCalls, summarizer.calls)
+	}
+}
+
+type fixedOnCall struct{}
+
+func (fixedOnCall) OnCall(repository string, at time.Time) (oncall.Shift, bool) {
+	return oncall.Shift{Schedule: "api", UserID: "U42"}, true
+}
+
+func TestProcessIssueOnCallMention(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	processor.SetOnCall(fixedOnCall{}, "high")
+	summarizer.triagePriority = "medium"
+	processor.SetTwoStage("high")
+
+	// Medium priority: no mention
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if blocks := notifier.posts[0]["blocks"].([]interface{}); len(blocks) != 0 {
+		t.Fatalf("Expected no mention for a medium-priority issue, got %+v", notifier.posts[0])
+	}
+
+	// High priority: the on-call engineer is mentioned, and updates keep the mention
+	summarizer.triagePriority = "high"
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	issueData.Issue.Number = gogithub.Int(8)
+	processor.ProcessIssue(context.Background(), issueData)
+	issueData = newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes")
+	issueData.Issue.Number = gogithub.Int(8)
+	processor.ProcessIssue(context.Background(), issueData)
+
+	for _, message := range []map[string]interface{}{notifier.posts[1], notifier.updates[0]} {
+		blocks, ok := message["blocks"].([]interface{})
+		if !ok || len(blocks) != 1 {
+			t.Fatalf("Expected a mention block, got %+v", message)
+		}
+		text := blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"]
+		if text != ":rotating_light: On call: <@U42>" {
+			t.Errorf("Unexpected mention %q", text)
+		}
+	}
+}
+
+func TestProcessIssueComponents(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	detector, err := components.NewDetector([]components.Component{
+		{Name: "notifications", Paths: []string{"internal/slack/**"}},
+		{Name: "ai", Labels: []string{"area/ai"}},
+		{Name: "deploy", Paths: []string{"k8s/**"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.SetComponentDetector(detector)
+
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	issueData.Issue.Labels = []*gogithub.Label{{Name: gogithub.String("area/ai")}}
+	issueData.Files = []*gogithub.CommitFile{{Filename: gogithub.String("internal/slack/notifier.go")}}
+	processor.ProcessIssue(context.Background(), issueData)
+
+	record, ok := processor.store.GetIssue("owner/repo", 7)
+	if !ok {
+		t.Fatal("Expected the issue to be stored")
+	}
+	if got := record.Summary.Components; len(got) != 2 || got[0] != "notifications" || got[1] != "ai" {
+		t.Errorf("Expected components [notifications ai], got %v", got)
+	}
+}
+
+type fakeTranslator struct {
+	languages []string
+}
+
+func (f *fakeTranslator) TranslateIssue(ctx context.Context, issueData *github.IssueData, language string) (*ai.Translation, error) {
+	f.languages = append(f.languages, language)
+	return &ai.Translation{Language: language, Title: "Crash on start", Body: "The app crashes when it starts", Snippet: issueData.Issue.GetBody()}, nil
+}
+
+func TestProcessIssueTranslation(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	translator := &fakeTranslator{}
+	processor.SetTranslator(translator)
+
+	// English issues are analyzed as they are
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "The app crashes when it starts and the logs show nothing"))
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	if len(translator.languages) != 0 || record.Language != "en" || record.Summary.Language != "" {
+		t.Fatalf("Expected an untranslated English issue, got %+v", record)
+	}
+
+	// Other languages are analyzed from the translation, keeping the original snippet
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "La aplicación se cierra cuando la abro y no funciona con el modo oscuro")
+	issueData.Issue.Title = gogithub.String("Se cierra al iniciar")
+	processor.ProcessIssue(context.Background(), issueData)
+
+	record, _ = processor.store.GetIssue("owner/repo", 7)
+	if len(translator.languages) != 1 || translator.languages[0] != "es" {
+		t.Fatalf("Expected one Spanish translation, got %v", translator.languages)
+	}
+	if record.Language != "es" || record.Summary.Language != "es" || record.Summary.Title != "Crash on start" {
+		t.Errorf("Expected a summary of the translation, got %+v", record.Summary)
+	}
+	if record.Summary.Original != issueData.Issue.GetBody() || record.Title != "Se cierra al iniciar" {
+		t.Errorf("Expected the original text to be kept, got %+v", record)
+	}
+}
+
+func TestProcessIssueMemory(t *testing.T) {
+	processor, summarizer, _ := newTestProcessor(t)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(summarizer.lastMemory) != 0 {
+		t.Fatalf("Expected no memory for a new issue, got %+v", summarizer.lastMemory)
+	}
+	processor.RememberFollowUp("owner/repo", 7, "Suggest a fix", "Check for nil")
+
+	history := processor.IssueMemory("owner/repo", 7)
+	if len(history) != 3 || history[0].Kind != memory.KindSummary || history[2].Text != "Check for nil" {
+		t.Fatalf("Expected the summary and the follow-up to be remembered, got %+v", history)
+	}
+	if history[0].Text != "Assessed as high priority bug: " {
+		t.Errorf("Unexpected summary memory %q", history[0].Text)
+	}
+
+	// The next analysis sees the memory, and adds to it
+	processor.ProcessIssue(context.Background(), newIssueData("edited", github.BehaviorResummarize, "open", "It crashes with a nil pointer in main.go"))
+	if len(summarizer.lastMemory) != 3 {
+		t.Errorf("Expected the prompt to include the memory, got %+v", summarizer.lastMemory)
+	}
+	if history := processor.IssueMemory("owner/repo", 7); len(history) != 4 {
+		t.Errorf("Expected the new summary to be remembered, got %+v", history)
+	}
+
+	// The memory stays within its token budget
+	processor.SetMemoryLimit(12)
+	processor.RememberFollowUp("owner/repo", 7, "Is this a regression?", "Yes, since v1.2")
+	if history := processor.IssueMemory("owner/repo", 7); len(history) != 2 || history[0].Kind != memory.KindQuestion {
+		t.Errorf("Expected only the latest exchange to fit, got %+v", history)
+	}
+}
+
+func TestProcessIssueBuildBreak(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "The build failed")
+	issueData.CIFailure = &github.CIFailure{Workflow: "CI", Step: "Run tests"}
+	processor.ProcessIssue(context.Background(), issueData)
+	if summarizer.calls != 0 {
+		t.Errorf("Expected no AI summary for a build break, got %d calls", summarizer.calls)
+	}
+	if len(notifier.posts) != 1 || notifier.posts[0]["build_break"] != "Run tests" {
+		t.Fatalf("Expected a build break card, got %v", notifier.posts)
+	}
+
+	// Updates refresh the card from the stored digest
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "The build failed"))
+	if len(notifier.updates) != 1 || notifier.updates[0]["build_break"] != "Run tests" || notifier.updates[0]["state"] != "closed" {
+		t.Errorf("Expected the card updated, got %v", notifier.updates)
+	}
+}
@@ -0,0 +1,178 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/store"
+)
+
+// reanalysisCheckInterval is how often posted issues are checked for being due
+const reanalysisCheckInterval = time.Hour
+
+// ThreadNotifier replies in the Slack thread of a posted message
+type ThreadNotifier interface {
+	PostThreadReply(ctx context.Context, channelID, threadTS, text string) error
+}
+
+// ReanalysisConfig selects which open issues are re-analyzed on a schedule
+type ReanalysisConfig struct {
+	Every    time.Duration // Time since the last analysis after which an issue is re-analyzed, 0 to disable
+	Priority string        // Lowest priority that is re-analyzed
+}
+
+// SetReanalysis re-runs the analysis of open issues at or above
+// config.Priority once their last analysis is config.Every old, and posts
+// what changed in the thread of the original message
+func (p *IssueProcessor) SetReanalysis(fetcher IssueFetcher, replies ThreadNotifier, config ReanalysisConfig) {
+	p.fetcher = fetcher
+	p.replies = replies
+	p.reanalysisConfig = config
+}
+
+// RunReanalysis re-analyzes due issues until the context is cancelled
+func (p *IssueProcessor) RunReanalysis(ctx context.Context) {
+	if p.fetcher == nil || p.reanalysisConfig.Every <= 0 {
+		return
+	}
+	every(ctx, reanalysisCheckInterval, p.CheckReanalysis)
+}
+
+// CheckReanalysis re-analyzes every open posted issue whose last analysis is
+// due. Issues that fail are retried at the next check.
+func (p *IssueProcessor) CheckReanalysis(ctx context.Context) {
+	records, _ := p.store.ListIssues(store.Query{})
+	now := time.Now()
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return
+		}
+		if !p.reanalysisDue(record, now) {
+			continue
+		}
+		if err := p.reanalyze(ctx, record); err != nil {
+			p.logger.Warn("Failed to re-analyze issue",
+				zap.String("repository", record.Repository),
+				zap.Int("issue_number", record.Number),
+				zap.Error(err))
+		}
+	}
+}
+
+// reanalysisDue reports whether an open posted issue's last analysis is old
+// enough to be redone
+func (p *IssueProcessor) reanalysisDue(record store.IssueRecord, now time.Time) bool {
+	return record.State == "open" && record.Source == "" && record.MessageTS != "" && record.Summary != nil &&
+		ai.PriorityAtLeast(record.Summary.Priority, p.reanalysisConfig.Priority) &&
+		!record.AnalyzedAt.IsZero() && now.Sub(record.AnalyzedAt) >= p.reanalysisConfig.Every
+}
+
+// reanalyze summarizes an issue again with its memory of earlier analyses,
+// posts the delta in the thread and refreshes the original message
+func (p *IssueProcessor) reanalyze(ctx context.Context, record store.IssueRecord) error {
+	issueData, err := p.fetcher.FetchEnrichedIssueData(ctx, record.Repository, record.Number)
+	if err != nil {
+		return err
+	}
+	// Closing is handled by the issue's own webhook
+	if issueData.Issue.GetState() != "open" {
+		return nil
+	}
+	issueData.Memory = record.Memory
+
+	aiCtx, done := p.stageContext(ctx, monitor.StageSummarize, p.aiTimeout)
+	analyzed, language, translation := p.translate(aiCtx, issueData)
+	summary, err := p.summarizer.SummarizeIssue(aiCtx, analyzed)
+	done()
+	if err != nil {
+		return err
+	}
+	summary.Components = p.detectComponents(issueData)
+	if translation != nil {
+		summary.Language, summary.Original = translation.Language, translation.Snippet
+	}
+	summary = p.postProcess(record.Repository, summary)
+	history := record.Memory.Append(p.memoryTokens, summaryMemory(summary))
+	if boosted, ok := p.boost(ctx, issueData, summary); ok {
+		summary = boosted
+		history = history.Append(p.memoryTokens, boostMemory(summary, issueData.Activity.ThumbsUp))
+	}
+
+	delta := reanalysisText(record, summary, newComments(issueData, record.AnalyzedAt), time.Since(record.AnalyzedAt))
+	if p.replies != nil {
+		slackCtx, done := p.stageContext(ctx, monitor.StageNotify, p.slackTimeout)
+		err = p.replies.PostThreadReply(slackCtx, record.Channel, record.MessageTS, delta)
+		done()
+		if err != nil {
+			return err
+		}
+		p.recordSlackMessage(record.Repository)
+	}
+
+	updated := record
+	updated.Title, updated.Body = issueData.Issue.GetTitle(), issueData.Issue.GetBody()
+	updated.Summary = summary
+	updated.Language = language
+	updated.Memory = history
+	updated.Labels = issueLabels(issueData)
+	updated.Overridden = nil
+	updated.AnalyzedAt = time.Now()
+	updated.UpdatedAt = time.Now()
+	p.store.SaveIssue(&updated)
+	p.metrics.RecordIssueSummaryGenerated(record.Repository, "issue")
+	p.metrics.RecordSummaryConfidence(summary.Confidence)
+	p.metrics.RecordSummaryAssignment(record.Repository, summary.Priority, summary.Category)
+	p.recordSummaryUsage(record.Repository, summary)
+
+	p.logger.Info("Re-analyzed issue",
+		zap.String("repository", record.Repository),
+		zap.Int("issue_number", record.Number),
+		zap.String("previous_priority", record.Summary.Priority),
+		zap.String("priority", summary.Priority))
+
+	// Refresh the original message with the new summary
+	issueData.EventType, issueData.Action = "schedule", "reanalyzed"
+	issueData.Behavior = github.BehaviorUpdate
+	p.ProcessIssue(ctx, issueData)
+	return nil
+}
+
+// newComments counts the comments made after since
+func newComments(issueData *github.IssueData, since time.Time) int {
+	count := 0
+	for _, comment := range issueData.Comments {
+		if comment.GetCreatedAt().After(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// reanalysisText describes what changed since the issue was last analyzed
+func reanalysisText(previous store.IssueRecord, summary *ai.IssueSummary, comments int, age time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":repeat: *Re-analysis after %s*\n", github.FormatAge(age))
+	if previous.Summary.Priority != summary.Priority {
+		fmt.Fprintf(&b, "• Priority: %s → %s\n", previous.Summary.Priority, summary.Priority)
+	}
+	if previous.Summary.Category != summary.Category {
+		fmt.Fprintf(&b, "• Category: %s → %s\n", previous.Summary.Category, summary.Category)
+	}
+	switch comments {
+	case 0:
+		b.WriteString("• No new comments since the last analysis\n")
+	case 1:
+		b.WriteString("• 1 new comment since the last analysis\n")
+	default:
+		fmt.Fprintf(&b, "• %d new comments since the last analysis\n", comments)
+	}
+	fmt.Fprintf(&b, "\n%s", summary.Summary)
+	return b.String()
+}
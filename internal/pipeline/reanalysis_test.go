@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeFetcher struct {
+	state    string
+	comments []*gogithub.IssueComment
+}
+
+func (f *fakeFetcher) FetchEnrichedIssueData(ctx context.Context, repo string, number int) (*github.IssueData, error) {
+	issueData := newIssueData("", "", f.state, "It crashes")
+	issueData.Comments = f.comments
+	return issueData, nil
+}
+
+type fakeReplies struct {
+	replies []string
+}
+
+func (f *fakeReplies) PostThreadReply(ctx context.Context, channelID, threadTS, text string) error {
+	f.replies = append(f.replies, text)
+	return nil
+}
+
+func TestReanalysis(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	fetcher := &fakeFetcher{state: "open"}
+	replies := &fakeReplies{}
+	processor.SetReanalysis(fetcher, replies, ReanalysisConfig{Every: 7 * 24 * time.Hour, Priority: "high"})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	// Recently analyzed issues are not due
+	processor.CheckReanalysis(context.Background())
+	if summarizer.calls != 1 || len(replies.replies) != 0 {
+		t.Fatalf("Expected no re-analysis yet, got %d summaries and %d replies", summarizer.calls, len(replies.replies))
+	}
+
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	analyzedAt := time.Now().Add(-8 * 24 * time.Hour)
+	record.AnalyzedAt = analyzedAt
+	processor.store.SaveIssue(record)
+	fetcher.comments = []*gogithub.IssueComment{
+		{CreatedAt: &gogithub.Timestamp{Time: analyzedAt.Add(-time.Hour)}},
+		{CreatedAt: &gogithub.Timestamp{Time: analyzedAt.Add(time.Hour)}},
+	}
+	summarizer.category = "security"
+
+	processor.CheckReanalysis(context.Background())
+	if summarizer.calls != 2 || len(replies.replies) != 1 {
+		t.Fatalf("Expected one re-analysis, got %d summaries and %d replies", summarizer.calls, len(replies.replies))
+	}
+	for _, want := range []string{"Re-analysis after 8 days", "Category: bug → security", "1 new comment since the last analysis"} {
+		if !strings.Contains(replies.replies[0], want) {
+			t.Errorf("Expected the reply to contain %q, got %q", want, replies.replies[0])
+		}
+	}
+	if strings.Contains(replies.replies[0], "Priority:") {
+		t.Errorf("Expected no priority change, got %q", replies.replies[0])
+	}
+	// The AI sees the earlier analysis, and the original message is refreshed
+	if len(summarizer.lastMemory) != 1 {
+		t.Errorf("Expected the earlier analysis in memory, got %v", summarizer.lastMemory)
+	}
+	if len(notifier.updates) != 1 {
+		t.Errorf("Expected the original message to be updated, got %d updates", len(notifier.updates))
+	}
+	record, _ = processor.store.GetIssue("owner/repo", 7)
+	if record.Summary.Category != "security" || time.Since(record.AnalyzedAt) > time.Minute {
+		t.Errorf("Expected the new summary to be stored, got %+v", record)
+	}
+
+	// The next re-analysis is due a full interval later
+	processor.CheckReanalysis(context.Background())
+	if summarizer.calls != 2 {
+		t.Errorf("Expected no second re-analysis, got %d summaries", summarizer.calls)
+	}
+}
+
+func TestReanalysisSkipsLowerPriorities(t *testing.T) {
+	processor, summarizer, _ := newTestProcessor(t)
+	replies := &fakeReplies{}
+	processor.SetReanalysis(&fakeFetcher{state: "open"}, replies, ReanalysisConfig{Every: time.Hour, Priority: "critical"})
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	record, _ := processor.store.GetIssue("owner/repo", 7)
+	record.AnalyzedAt = time.Now().Add(-2 * time.Hour)
+	processor.store.SaveIssue(record)
+
+	processor.CheckReanalysis(context.Background())
+	if summarizer.calls != 1 || len(replies.replies) != 0 {
+		t.Errorf("Expected high-priority issues to be left alone, got %d summaries", summarizer.calls)
+	}
+}
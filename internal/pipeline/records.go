@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// SummaryRecord is the structured record of one processed issue, for SIEM
+// and data warehouse pipelines that ingest JSON logs
+type SummaryRecord struct {
+	Time       time.Time `json:"time"`
+	DeliveryID string    `json:"delivery_id,omitempty"`
+	Repository string    `json:"repository"`
+	Number     int       `json:"number"`
+	URL        string    `json:"url,omitempty"`
+	EventType  string    `json:"event_type,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	Behavior   string    `json:"behavior,omitempty"`
+	Title      string    `json:"title"`
+	Generated  bool      `json:"generated"` // A new summary was generated, rather than the last one reused
+	Summary    string    `json:"summary,omitempty"`
+	Priority   string    `json:"priority,omitempty"`
+	Category   string    `json:"category,omitempty"`
+	Confidence float64   `json:"confidence,omitempty"`
+	Components []string  `json:"components,omitempty"`
+	SkipReason string    `json:"skip_reason,omitempty"`
+	Channel    string    `json:"channel,omitempty"`
+
+	// OpenAI usage of a generated summary, with its cost estimated from list prices
+	Models           []string `json:"models,omitempty"`
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	Cost             float64  `json:"estimated_cost_usd"`
+
+	SummarizeMS  int64 `json:"summarize_ms"`
+	NotifyMS     int64 `json:"notify_ms"`
+	ProcessingMS int64 `json:"processing_ms"`
+	EndToEndMS   int64 `json:"end_to_end_ms"` // From receiving the webhook to posting in Slack
+}
+
+// SummaryLog receives a record for every successfully processed issue
+type SummaryLog interface {
+	RecordSummary(record SummaryRecord)
+}
+
+// SetSummaryLog writes a structured record of every processed issue to log
+func (p *IssueProcessor) SetSummaryLog(log SummaryLog) {
+	p.summaryLog = log
+}
+
+// stageLatencies are how long the stages of processing one issue took
+type stageLatencies struct {
+	summarize  time.Duration
+	notify     time.Duration
+	processing time.Duration
+	endToEnd   time.Duration
+}
+
+// logSummary writes the record of a processed issue to the summary log
+func (p *IssueProcessor) logSummary(issueData *github.IssueData, summary *ai.IssueSummary, generated bool, skipReason, channel string, latencies stageLatencies) {
+	if p.summaryLog == nil {
+		return
+	}
+
+	record := SummaryRecord{
+		Time:         time.Now().UTC(),
+		DeliveryID:   issueData.DeliveryID,
+		Repository:   issueData.Repository.GetFullName(),
+		Number:       issueData.Issue.GetNumber(),
+		URL:          issueData.Issue.GetHTMLURL(),
+		EventType:    issueData.EventType,
+		Action:       issueData.Action,
+		Behavior:     string(issueData.Behavior),
+		Title:        issueData.Issue.GetTitle(),
+		Generated:    generated,
+		SkipReason:   skipReason,
+		Channel:      channel,
+		SummarizeMS:  latencies.summarize.Milliseconds(),
+		NotifyMS:     latencies.notify.Milliseconds(),
+		ProcessingMS: latencies.processing.Milliseconds(),
+		EndToEndMS:   latencies.endToEnd.Milliseconds(),
+	}
+	if summary != nil {
+		record.Summary = summary.Summary
+		record.Priority = summary.Priority
+		record.Category = summary.Category
+		record.Confidence = summary.Confidence
+		record.Components = summary.Components
+	}
+	// Reused summaries were paid for when they were generated
+	if generated {
+		for _, usage := range summary.Usage {
+			record.Models = append(record.Models, usage.Model)
+			record.PromptTokens += usage.PromptTokens
+			record.CompletionTokens += usage.CompletionTokens
+			if cost, ok := monitor.EstimateCost(usage.Model, usage.PromptTokens, usage.CompletionTokens); ok {
+				record.Cost += cost
+			}
+		}
+	}
+	p.summaryLog.RecordSummary(record)
+}
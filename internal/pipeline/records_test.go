@@ -0,0 +1,45 @@
+package pipeline
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+type summaryLog struct {
+	records []SummaryRecord
+}
+
+func (l *summaryLog) RecordSummary(record SummaryRecord) {
+	l.records = append(l.records, record)
+}
+
+func TestProcessIssueLogsSummaries(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	log := &summaryLog{}
+	processor.SetSummaryLog(log)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "The bot crashes on start"))
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "The bot crashes on start"))
+
+	if len(log.records) != 2 {
+		t.Fatalf("Expected a record per processed issue, got %+v", log.records)
+	}
+	opened := log.records[0]
+	if !opened.Generated || opened.Repository != "owner/repo" || opened.Number != 7 || opened.Priority != "high" ||
+		opened.Category != "bug" || opened.Channel != "C123" || opened.Title != "Crash on start" {
+		t.Errorf("Unexpected record for the new issue: %+v", opened)
+	}
+	// 1000 prompt and 100 completion tokens at gpt-4 list prices
+	if opened.PromptTokens != 1000 || opened.CompletionTokens != 100 || math.Abs(opened.Cost-0.036) > 1e-9 {
+		t.Errorf("Expected the summary's usage and cost, got %+v", opened)
+	}
+
+	// Refreshing the message reuses the summary, which costs nothing new
+	closed := log.records[1]
+	if closed.Generated || closed.Priority != "high" || closed.PromptTokens != 0 || closed.Cost != 0 {
+		t.Errorf("Unexpected record for the closed issue: %+v", closed)
+	}
+}
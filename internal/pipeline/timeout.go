@@ -0,0 +1,36 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SetTimeouts limits how long the AI stage (translation and analysis) and
+// the Slack stage (posting, updating and incident channels) of each issue may
+// take. Zero means no limit beyond the context passed to ProcessIssue.
+func (p *IssueProcessor) SetTimeouts(aiTimeout, slackTimeout time.Duration) {
+	p.aiTimeout = aiTimeout
+	p.slackTimeout = slackTimeout
+}
+
+// stageContext derives the context for a pipeline stage, so cancelling the
+// parent also cancels the stage. The returned function releases the context
+// and records the stage if it ran out of time.
+func (p *IssueProcessor) stageContext(parent context.Context, stage string, timeout time.Duration) (context.Context, func()) {
+	p.inFlight.enterStage(parent, stage)
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	return ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			p.metrics.RecordStageTimeout(stage)
+		}
+		cancel()
+	}
+}
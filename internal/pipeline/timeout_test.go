@@ -0,0 +1,76 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// slowSummarizer blocks until the context is done
+type slowSummarizer struct {
+	fakeSummarizer
+}
+
+func (s *slowSummarizer) SummarizeIssue(ctx context.Context, issueData *github.IssueData) (*ai.IssueSummary, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type timeoutMetrics struct {
+	nopMetrics
+	stages []string
+}
+
+func (m *timeoutMetrics) RecordStageTimeout(stage string) {
+	m.stages = append(m.stages, stage)
+}
+
+func TestProcessIssueAITimeout(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	metrics := &timeoutMetrics{}
+	processor.summarizer = &slowSummarizer{}
+	processor.metrics = metrics
+	processor.SetTimeouts(10*time.Millisecond, time.Second)
+	log := &eventLog{}
+	processor.SetEventRecorder(log)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+
+	if len(notifier.posts) != 1 || notifier.posts[0]["degraded"] != "the AI timed out" {
+		t.Errorf("Expected the issue posted without a summary, got %+v", notifier.posts)
+	}
+	if len(metrics.stages) != 1 || metrics.stages[0] != monitor.StageSummarize {
+		t.Errorf("Expected a summarize timeout, got %v", metrics.stages)
+	}
+	if len(log.events) != 1 || log.events[0].Detail != "the AI timed out" {
+		t.Errorf("Expected the event to say why there is no summary, got %+v", log.events)
+	}
+}
+
+func TestProcessIssueParentCancelled(t *testing.T) {
+	processor, _, notifier := newTestProcessor(t)
+	metrics := &timeoutMetrics{}
+	processor.summarizer = &slowSummarizer{}
+	processor.metrics = metrics
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		processor.ProcessIssue(ctx, newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected cancelling the parent to stop processing")
+	}
+	if len(notifier.posts) != 0 || len(metrics.stages) != 0 {
+		t.Errorf("Expected no posts and no timeouts, got %+v and %v", notifier.posts, metrics.stages)
+	}
+}
@@ -0,0 +1,35 @@
+package pipeline
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+// TicketLinker links triaged issues to tickets in an issue tracker, e.g.
+// Linear. It decides which issues are actionable and links each issue once.
+type TicketLinker interface {
+	LinkIssue(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) error
+}
+
+// SetTicketLinker offers every new summary of an open issue to linker
+func (p *IssueProcessor) SetTicketLinker(linker TicketLinker) {
+	p.linker = linker
+}
+
+// linkTicket offers the summary to the ticket linker. Failures are logged;
+// the summary was already posted.
+func (p *IssueProcessor) linkTicket(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) {
+	if p.linker == nil || summary == nil || issueData.Source != "" || issueData.Issue.GetState() == "closed" {
+		return
+	}
+	if err := p.linker.LinkIssue(ctx, issueData, summary); err != nil {
+		p.logger.Warn("Failed to link issue to a tracker ticket",
+			zap.String("repository", issueData.Repository.GetFullName()),
+			zap.Int("issue_number", issueData.Issue.GetNumber()),
+			zap.Error(err))
+	}
+}
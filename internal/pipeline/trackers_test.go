@@ -0,0 +1,38 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeTicketLinker struct {
+	linked []int
+}
+
+func (f *fakeTicketLinker) LinkIssue(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) error {
+	f.linked = append(f.linked, issueData.Issue.GetNumber())
+	return nil
+}
+
+func TestProcessIssueLinksTickets(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	linker := &fakeTicketLinker{}
+	processor.SetTicketLinker(linker)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	// Updates without a new summary are not offered again
+	processor.ProcessIssue(context.Background(), newIssueData("edited", github.BehaviorUpdate, "open", "It crashes"))
+	if len(linker.linked) != 1 {
+		t.Fatalf("Expected the new summary offered once, got %v", linker.linked)
+	}
+
+	ticket := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	ticket.Source = "zendesk"
+	processor.ProcessIssue(context.Background(), ticket)
+	if len(linker.linked) != 1 {
+		t.Errorf("Expected support tickets not offered, got %v", linker.linked)
+	}
+}
@@ -0,0 +1,210 @@
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// Mentions an urgency level can make, besides Slack user and user group IDs
+const (
+	MentionHere    = "here"    // Notify active members of the channel
+	MentionChannel = "channel" // Notify every member of the channel
+)
+
+// slackIDPattern matches Slack user (U…, W…) and user group (S…) IDs
+var slackIDPattern = regexp.MustCompile(`^[UWS][A-Z0-9]{2,}$`)
+
+// colorPattern matches the hex colors Slack accepts for attachment bars
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// UrgencyLevel is how loudly issues of some priorities are announced in some
+// channels. Empty criteria match everything; the first matching level wins.
+type UrgencyLevel struct {
+	Channels   []string `mapstructure:"channels" json:"channels"` // Slack channel IDs
+	Priorities []string `mapstructure:"priorities" json:"priorities"`
+	Mention    string   `mapstructure:"mention" json:"mention"` // here, channel, a user or user group ID, or empty for none
+	Color      string   `mapstructure:"color" json:"color"`     // Attachment bar color, e.g. #E01E5A, or empty for none
+}
+
+// DefaultUrgencyLevels mention the channel for high-priority issues with a
+// red bar and mark medium ones amber. Low-priority issues are posted quietly.
+var DefaultUrgencyLevels = []UrgencyLevel{
+	{Priorities: []string{"critical", "high"}, Mention: MentionHere, Color: "#E01E5A"},
+	{Priorities: []string{"medium"}, Color: "#ECB22E"},
+}
+
+// ChannelTimezone is the timezone of the people in a Slack channel, whose
+// quiet hours are kept in it
+type ChannelTimezone struct {
+	Channel  string `mapstructure:"channel" json:"channel"`   // Slack channel ID
+	Timezone string `mapstructure:"timezone" json:"timezone"` // IANA name, e.g. Europe/Berlin
+}
+
+// UrgencyConfig maps priorities to Slack mentions and colors
+type UrgencyConfig struct {
+	Enabled       bool
+	Levels        []UrgencyLevel // Defaults to DefaultUrgencyLevels
+	QuietHours    string         // HH:MM-HH:MM during which only the highest priority mentions anyone, empty for none
+	QuietTimezone string         // IANA name, defaults to UTC
+
+	// Timezones override QuietTimezone for some channels
+	Timezones []ChannelTimezone
+}
+
+// Urgency picks the mention and color of each Slack message
+type Urgency struct {
+	levels     []UrgencyLevel
+	location   *time.Location
+	locations  map[string]*time.Location // By channel, overriding location
+	quietStart time.Duration             // Offsets from midnight; equal when there are no quiet hours
+	quietEnd   time.Duration
+	now        func() time.Time
+}
+
+// NewUrgency validates the levels and quiet hours
+func NewUrgency(config UrgencyConfig) (*Urgency, error) {
+	levels := config.Levels
+	if len(levels) == 0 {
+		levels = DefaultUrgencyLevels
+	}
+	for i, level := range levels {
+		if level.Mention != "" && level.Mention != MentionHere && level.Mention != MentionChannel && !slackIDPattern.MatchString(level.Mention) {
+			return nil, fmt.Errorf("urgency level %d: invalid mention %q", i, level.Mention)
+		}
+		if level.Color != "" && !colorPattern.MatchString(level.Color) {
+			return nil, fmt.Errorf("urgency level %d: invalid color %q", i, level.Color)
+		}
+	}
+
+	u := &Urgency{levels: levels, location: time.UTC, now: time.Now}
+	if config.QuietTimezone != "" {
+		location, err := time.LoadLocation(config.QuietTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours timezone: %w", err)
+		}
+		u.location = location
+	}
+	for i, zone := range config.Timezones {
+		location, err := time.LoadLocation(zone.Timezone)
+		if zone.Channel == "" || zone.Timezone == "" || err != nil {
+			return nil, fmt.Errorf("channel timezone %d: invalid timezone %q for channel %q", i, zone.Timezone, zone.Channel)
+		}
+		if u.locations == nil {
+			u.locations = make(map[string]*time.Location)
+		}
+		u.locations[zone.Channel] = location
+	}
+	if config.QuietHours != "" {
+		start, end, ok := strings.Cut(config.QuietHours, "-")
+		var err error
+		if ok {
+			if u.quietStart, err = clockOffset(start); err == nil {
+				u.quietEnd, err = clockOffset(end)
+			}
+		}
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", config.QuietHours)
+		}
+	}
+	return u, nil
+}
+
+// clockOffset parses HH:MM into the time since midnight
+func clockOffset(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Level returns the first level matching the channel and priority
+func (u *Urgency) Level(channel, priority string) (UrgencyLevel, bool) {
+	for _, level := range u.levels {
+		if len(level.Channels) > 0 && !containsString(level.Channels, channel) {
+			continue
+		}
+		if len(level.Priorities) > 0 && !utils.ContainsFold(level.Priorities, priority) {
+			continue
+		}
+		return level, true
+	}
+	return UrgencyLevel{}, false
+}
+
+// Quiet reports whether now is within the quiet hours in the channel's
+// timezone. They may span midnight.
+func (u *Urgency) Quiet(channel string, now time.Time) bool {
+	if u.quietStart == u.quietEnd {
+		return false
+	}
+	location, ok := u.locations[channel]
+	if !ok {
+		location = u.location
+	}
+	local := now.In(location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if u.quietStart < u.quietEnd {
+		return offset >= u.quietStart && offset < u.quietEnd
+	}
+	return offset >= u.quietStart || offset < u.quietEnd
+}
+
+// SetUrgency colors Slack messages and mentions people by priority
+func (p *IssueProcessor) SetUrgency(urgency *Urgency) {
+	p.urgency = urgency
+}
+
+// withUrgency applies the urgency level of an issue's priority in channel to
+// its Slack message. Only new messages mention anyone, and during quiet hours
+// only for the highest priority.
+func (p *IssueProcessor) withUrgency(message map[string]interface{}, issueData *github.IssueData, channel, priority string, posting bool) map[string]interface{} {
+	level, ok := p.urgency.Level(channel, priority)
+	if !ok {
+		return message
+	}
+	if level.Color != "" {
+		message["color"] = level.Color
+	}
+	if level.Mention == "" || !posting {
+		return message
+	}
+	if p.urgency.Quiet(channel, p.urgency.now()) && priority != p.priorities().Priorities()[0].Name {
+		p.logger.Info("Suppressed mention during quiet hours",
+			zap.String("repository", issueData.Repository.GetFullName()),
+			zap.Int("issue_number", issueData.Issue.GetNumber()),
+			zap.String("priority", priority))
+		return message
+	}
+
+	text := fmt.Sprintf("%s %s priority issue in %s", slackMention(level.Mention), strings.Title(priority), issueData.Repository.GetFullName())
+	if level.Color != "" {
+		// Slack shows the text above attachments, where the mention notifies.
+		// Keep the issue's fallback text after the mention for previews.
+		if fallback, _ := message["text"].(string); fallback != "" {
+			text = slackMention(level.Mention) + " " + fallback
+		}
+		message["text"] = text
+		return message
+	}
+	return prependSection(message, text)
+}
+
+// slackMention renders a mention in Slack's markup
+func slackMention(mention string) string {
+	switch {
+	case mention == MentionHere || mention == MentionChannel:
+		return "<!" + mention + ">"
+	case strings.HasPrefix(mention, "S"):
+		return "<!subteam^" + mention + ">"
+	default:
+		return "<@" + mention + ">"
+	}
+}
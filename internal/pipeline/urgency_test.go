@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+func TestNewUrgencyValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config UrgencyConfig
+	}{
+		{"mention", UrgencyConfig{Levels: []UrgencyLevel{{Mention: "@team"}}}},
+		{"color", UrgencyConfig{Levels: []UrgencyLevel{{Color: "red"}}}},
+		{"quiet hours", UrgencyConfig{QuietHours: "10pm-7am"}},
+		{"timezone", UrgencyConfig{QuietHours: "22:00-07:00", QuietTimezone: "Mars/Olympus"}},
+	}
+	for _, tt := range tests {
+		if _, err := NewUrgency(tt.config); err == nil {
+			t.Errorf("Expected an invalid %s to be rejected", tt.name)
+		}
+	}
+}
+
+func TestUrgencyLevel(t *testing.T) {
+	urgency, err := NewUrgency(UrgencyConfig{Levels: []UrgencyLevel{
+		{Channels: []string{"C-OPS"}, Priorities: []string{"high"}, Mention: "S0123ABC"},
+		{Priorities: []string{"critical", "high"}, Mention: MentionHere, Color: "#E01E5A"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if level, _ := urgency.Level("C-OPS", "High"); level.Mention != "S0123ABC" {
+		t.Errorf("Expected the channel's own level, got %+v", level)
+	}
+	if level, _ := urgency.Level("C-DEV", "high"); level.Mention != MentionHere {
+		t.Errorf("Expected the shared level, got %+v", level)
+	}
+	if _, ok := urgency.Level("C-DEV", "low"); ok {
+		t.Error("Expected no level for low priorities")
+	}
+}
+
+func TestUrgencyQuiet(t *testing.T) {
+	urgency, err := NewUrgency(UrgencyConfig{QuietHours: "22:00-07:00", QuietTimezone: "Europe/Berlin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Berlin is UTC+2 in summer
+	for hour, want := range map[int]bool{19: false, 20: true, 23: true, 4: true, 5: false} {
+		if got := urgency.Quiet("C-OPS", time.Date(2024, 7, 1, hour, 30, 0, 0, time.UTC)); got != want {
+			t.Errorf("Quiet at %02d:30 UTC = %v, want %v", hour, got, want)
+		}
+	}
+	// Clocks went forward overnight on March 31, so 05:30 UTC moved from
+	// 06:30 to 07:30 in Berlin
+	if !urgency.Quiet("C-OPS", time.Date(2024, 3, 30, 5, 30, 0, 0, time.UTC)) {
+		t.Error("Expected 06:30 in Berlin to be quiet")
+	}
+	if urgency.Quiet("C-OPS", time.Date(2024, 3, 31, 5, 30, 0, 0, time.UTC)) {
+		t.Error("Expected 07:30 in Berlin not to be quiet")
+	}
+}
+
+func TestUrgencyQuietChannelTimezones(t *testing.T) {
+	urgency, err := NewUrgency(UrgencyConfig{
+		QuietHours:    "22:00-07:00",
+		QuietTimezone: "Europe/Berlin",
+		Timezones:     []ChannelTimezone{{Channel: "C-NYC", Timezone: "America/New_York"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 03:00 UTC is 05:00 in Berlin but 23:00 the day before in New York
+	at := time.Date(2024, 7, 1, 3, 0, 0, 0, time.UTC)
+	if !urgency.Quiet("C-OPS", at) || !urgency.Quiet("C-NYC", at) {
+		t.Error("Expected both channels quiet at 03:00 UTC")
+	}
+	// 12:00 UTC is 14:00 in Berlin and 08:00 in New York
+	at = time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	if urgency.Quiet("C-OPS", at) || urgency.Quiet("C-NYC", at) {
+		t.Error("Expected neither channel quiet at 12:00 UTC")
+	}
+	// 08:00 UTC is 10:00 in Berlin but 04:00 in New York
+	at = time.Date(2024, 7, 1, 8, 0, 0, 0, time.UTC)
+	if urgency.Quiet("C-OPS", at) || !urgency.Quiet("C-NYC", at) {
+		t.Error("Expected only New York quiet at 08:00 UTC")
+	}
+
+	if _, err := NewUrgency(UrgencyConfig{Timezones: []ChannelTimezone{{Channel: "C-NYC", Timezone: "Mars/Olympus"}}}); err == nil {
+		t.Error("Expected an error for an unknown channel timezone")
+	}
+}
+
+func TestProcessIssueUrgency(t *testing.T) {
+	processor, summarizer, notifier := newTestProcessor(t)
+	urgency, err := NewUrgency(UrgencyConfig{QuietHours: "22:00-07:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urgency.now = func() time.Time { return time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC) }
+	processor.SetUrgency(urgency)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if len(notifier.posts) != 1 {
+		t.Fatalf("Expected a post, got %d", len(notifier.posts))
+	}
+	post := notifier.posts[0]
+	if post["color"] != "#E01E5A" || !strings.HasPrefix(post["text"].(string), "<!here> High priority issue") {
+		t.Errorf("Expected a red message mentioning the channel, got %v, %q", post["color"], post["text"])
+	}
+
+	// Updates keep the color without notifying again
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if update := notifier.updates[0]; update["color"] != "#E01E5A" || update["text"] != nil {
+		t.Errorf("Expected a red update without a mention, got %v, %q", update["color"], update["text"])
+	}
+
+	// At night only the highest priority mentions anyone
+	urgency.now = func() time.Time { return time.Date(2024, 7, 1, 23, 0, 0, 0, time.UTC) }
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	if post := notifier.posts[1]; post["color"] != "#E01E5A" || post["text"] != nil {
+		t.Errorf("Expected no mention during quiet hours, got %q", post["text"])
+	}
+	if summarizer.calls != 2 {
+		t.Errorf("Expected two summaries, got %d", summarizer.calls)
+	}
+}
+
+func TestWithUrgencyKeepsFallbackText(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	urgency, err := NewUrgency(UrgencyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor.SetUrgency(urgency)
+
+	message := map[string]interface{}{"text": "High priority bug in owner/repo#7: Crash"}
+	issueData := newIssueData("opened", github.BehaviorSummarize, "open", "It crashes")
+	message = processor.withUrgency(message, issueData, "C123", "high", true)
+	if message["text"] != "<!here> High priority bug in owner/repo#7: Crash" {
+		t.Errorf("Expected the mention before the fallback text, got %q", message["text"])
+	}
+}
@@ -0,0 +1,29 @@
+package pipeline
+
+import "github-issue-ai-bot/internal/ai"
+
+// UsageRecorder totals what each repository costs, for usage reports
+type UsageRecorder interface {
+	RecordSummaryUsage(repository, style string, usage []ai.Usage)
+	RecordSlackMessage(repository string)
+}
+
+// SetUsageRecorder reports the OpenAI usage of generated summaries and the
+// Slack messages posted for issues to recorder
+func (p *IssueProcessor) SetUsageRecorder(recorder UsageRecorder) {
+	p.usage = recorder
+}
+
+// recordSummaryUsage reports a newly generated summary. Reused summaries
+// were counted when they were generated.
+func (p *IssueProcessor) recordSummaryUsage(repository string, summary *ai.IssueSummary) {
+	if p.usage != nil {
+		p.usage.RecordSummaryUsage(repository, summary.PromptStyle, summary.Usage)
+	}
+}
+
+func (p *IssueProcessor) recordSlackMessage(repository string) {
+	if p.usage != nil {
+		p.usage.RecordSlackMessage(repository)
+	}
+}
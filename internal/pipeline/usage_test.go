@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+)
+
+type fakeUsage struct {
+	summaries     []int // Prompt tokens of each summary
+	slackMessages map[string]int
+}
+
+func (f *fakeUsage) RecordSummaryUsage(repository, style string, usage []ai.Usage) {
+	for _, u := range usage {
+		f.summaries = append(f.summaries, u.PromptTokens)
+	}
+}
+
+func (f *fakeUsage) RecordSlackMessage(repository string) {
+	f.slackMessages[repository]++
+}
+
+func TestProcessIssueRecordsUsage(t *testing.T) {
+	processor, _, _ := newTestProcessor(t)
+	usage := &fakeUsage{slackMessages: map[string]int{}}
+	processor.SetUsageRecorder(usage)
+
+	processor.ProcessIssue(context.Background(), newIssueData("opened", github.BehaviorSummarize, "open", "It crashes"))
+	processor.ProcessIssue(context.Background(), newIssueData("closed", github.BehaviorUpdate, "closed", "It crashes"))
+	if len(usage.summaries) != 1 || usage.summaries[0] != 1000 {
+		t.Errorf("Expected only the generated summary's usage recorded, got %v", usage.summaries)
+	}
+	if usage.slackMessages["owner/repo"] != 2 {
+		t.Errorf("Expected the post and the update counted, got %v", usage.slackMessages)
+	}
+}
@@ -0,0 +1,131 @@
+package postprocess
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// Built-in post-processor types
+const (
+	TypeWordLimit   = "word_limit"   // Shortens summaries to max_words words
+	TypeCategoryMap = "category_map" // Renames AI categories to the names in categories
+	TypeDisclaimer  = "disclaimer"   // Appends text to every summary
+)
+
+func init() {
+	Register(TypeWordLimit, newWordLimit)
+	Register(TypeCategoryMap, newCategoryMap)
+	Register(TypeDisclaimer, newDisclaimer)
+}
+
+// WordLimit cuts summaries longer than MaxWords words, marking the cut
+// with an ellipsis. Line breaks before the cut are kept.
+type WordLimit struct {
+	MaxWords int `json:"max_words"`
+}
+
+func newWordLimit(options map[string]interface{}) (pipeline.PostProcessor, error) {
+	limit := &WordLimit{}
+	if err := DecodeOptions(options, limit); err != nil {
+		return nil, err
+	}
+	if limit.MaxWords <= 0 {
+		return nil, errors.New("max_words must be positive")
+	}
+	return limit, nil
+}
+
+// Name returns the post-processor's type
+func (l *WordLimit) Name() string {
+	return TypeWordLimit
+}
+
+var wordPattern = regexp.MustCompile(`\S+`)
+
+// Process shortens the summary text
+func (l *WordLimit) Process(repository string, summary ai.IssueSummary) (ai.IssueSummary, error) {
+	words := wordPattern.FindAllStringIndex(summary.Summary, l.MaxWords+1)
+	if len(words) > l.MaxWords {
+		summary.Summary = strings.TrimRight(summary.Summary[:words[l.MaxWords-1][1]], ".,;:") + "…"
+	}
+	return summary, nil
+}
+
+// CategoryMap renames the AI's categories, e.g. "bug" to "defect", matching
+// them ignoring case. Categories it does not name are kept. Routing rules
+// and auto-labels see the renamed category.
+type CategoryMap struct {
+	Categories map[string]string `json:"categories"`
+}
+
+func newCategoryMap(options map[string]interface{}) (pipeline.PostProcessor, error) {
+	mapping := &CategoryMap{}
+	if err := DecodeOptions(options, mapping); err != nil {
+		return nil, err
+	}
+	if len(mapping.Categories) == 0 {
+		return nil, errors.New("categories must map at least one category")
+	}
+	categories := make(map[string]string, len(mapping.Categories))
+	for from, to := range mapping.Categories {
+		if strings.TrimSpace(to) == "" {
+			return nil, errors.New("category " + from + " is mapped to an empty name")
+		}
+		categories[strings.ToLower(from)] = to
+	}
+	mapping.Categories = categories
+	return mapping, nil
+}
+
+// Name returns the post-processor's type
+func (m *CategoryMap) Name() string {
+	return TypeCategoryMap
+}
+
+// Process renames the summary's category
+func (m *CategoryMap) Process(repository string, summary ai.IssueSummary) (ai.IssueSummary, error) {
+	if to, ok := m.Categories[strings.ToLower(summary.Category)]; ok {
+		summary.Category = to
+	}
+	return summary, nil
+}
+
+// Disclaimer appends Text to summaries as a paragraph of its own, e.g. a
+// compliance notice that the summary was generated
+type Disclaimer struct {
+	Text string `json:"text"`
+}
+
+func newDisclaimer(options map[string]interface{}) (pipeline.PostProcessor, error) {
+	disclaimer := &Disclaimer{}
+	if err := DecodeOptions(options, disclaimer); err != nil {
+		return nil, err
+	}
+	disclaimer.Text = strings.TrimSpace(disclaimer.Text)
+	if disclaimer.Text == "" {
+		return nil, errors.New("text is required")
+	}
+	return disclaimer, nil
+}
+
+// Name returns the post-processor's type
+func (d *Disclaimer) Name() string {
+	return TypeDisclaimer
+}
+
+// Process appends the disclaimer to the summary text
+func (d *Disclaimer) Process(repository string, summary ai.IssueSummary) (ai.IssueSummary, error) {
+	if strings.HasSuffix(summary.Summary, d.Text) {
+		return summary, nil
+	}
+	if summary.Summary == "" {
+		summary.Summary = d.Text
+	} else {
+		summary.Summary = strings.TrimRight(summary.Summary, "\n") + "\n\n" + d.Text
+	}
+	return summary, nil
+}
@@ -0,0 +1,86 @@
+// Package postprocess registers the summary post-processors deployments
+// configure, in order, under the postprocessors key of the config file
+package postprocess
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// Config configures one post-processor. Keys besides type are the
+// post-processor's options.
+type Config struct {
+	Type    string                 `mapstructure:"type" json:"type"`
+	Options map[string]interface{} `mapstructure:",remain" json:"options,omitempty"`
+}
+
+// Factory creates a post-processor from its options
+type Factory func(options map[string]interface{}) (pipeline.PostProcessor, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a post-processor type available to the config file. Builds
+// with their own post-processors register them from an init function.
+// Registering a type twice panics.
+func Register(kind string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, ok := factories[kind]; ok {
+		panic("postprocess: type registered twice: " + kind)
+	}
+	factories[kind] = factory
+}
+
+// Types returns the registered post-processor types, sorted
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	kinds := make([]string, 0, len(factories))
+	for kind := range factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// New creates the post-processors configs describe, in the same order
+func New(configs []Config) ([]pipeline.PostProcessor, error) {
+	processors := make([]pipeline.PostProcessor, 0, len(configs))
+	for i, cfg := range configs {
+		factoriesMu.RLock()
+		factory, ok := factories[cfg.Type]
+		factoriesMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("postprocessor %d: unknown type %q, expected one of %v", i, cfg.Type, Types())
+		}
+		processor, err := factory(cfg.Options)
+		if err != nil {
+			return nil, fmt.Errorf("postprocessor %d (%s): %w", i, cfg.Type, err)
+		}
+		processors = append(processors, processor)
+	}
+	return processors, nil
+}
+
+// DecodeOptions reads a post-processor's options into target, a pointer to a
+// struct with json tags. Options target does not have are rejected.
+func DecodeOptions(options map[string]interface{}, target interface{}) error {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
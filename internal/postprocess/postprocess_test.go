@@ -0,0 +1,124 @@
+package postprocess
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+const configFile = `
+postprocessors:
+  - type: category_map
+    categories:
+      Bug: defect
+      feature: enhancement-request
+  - type: word_limit
+    max_words: 5
+  - type: disclaimer
+    text: "_AI-generated, verify before acting._"
+`
+
+func readConfigs(t *testing.T, file string) []Config {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	var configs []Config
+	if err := v.UnmarshalKey("postprocessors", &configs); err != nil {
+		t.Fatal(err)
+	}
+	return configs
+}
+
+func process(t *testing.T, processors []pipeline.PostProcessor, summary ai.IssueSummary) ai.IssueSummary {
+	t.Helper()
+	for _, processor := range processors {
+		var err error
+		if summary, err = processor.Process("o/r", summary); err != nil {
+			t.Fatalf("%s: %v", processor.Name(), err)
+		}
+	}
+	return summary
+}
+
+func TestNewFromConfigFile(t *testing.T) {
+	processors, err := New(readConfigs(t, configFile))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(processors) != 3 || processors[0].Name() != TypeCategoryMap || processors[2].Name() != TypeDisclaimer {
+		t.Fatalf("Expected the post-processors in config order, got %v", processors)
+	}
+
+	summary := process(t, processors, ai.IssueSummary{Category: "bug", Summary: "Saving a file crashes the editor, losing unsaved work."})
+	if summary.Category != "defect" {
+		t.Errorf("Expected the category mapped, got %q", summary.Category)
+	}
+	want := "Saving a file crashes the…\n\n_AI-generated, verify before acting._"
+	if summary.Summary != want {
+		t.Errorf("Expected %q, got %q", want, summary.Summary)
+	}
+
+	// Unmapped categories and short summaries are kept
+	summary = process(t, processors[:2], ai.IssueSummary{Category: "question", Summary: "How do I\nconfigure it?"})
+	if summary.Category != "question" || summary.Summary != "How do I\nconfigure it?" {
+		t.Errorf("Expected the summary unchanged, got %+v", summary)
+	}
+}
+
+func TestNewRejectsInvalidConfigs(t *testing.T) {
+	for name, file := range map[string]string{
+		"unknown type":     "postprocessors:\n  - type: shout\n",
+		"unknown option":   "postprocessors:\n  - type: word_limit\n    max_words: 5\n    min_words: 1\n",
+		"missing limit":    "postprocessors:\n  - type: word_limit\n",
+		"wrong type":       "postprocessors:\n  - type: word_limit\n    max_words: many\n",
+		"empty mapping":    "postprocessors:\n  - type: category_map\n",
+		"empty category":   "postprocessors:\n  - type: category_map\n    categories:\n      bug: \"\"\n",
+		"empty disclaimer": "postprocessors:\n  - type: disclaimer\n    text: \" \"\n",
+	} {
+		if _, err := New(readConfigs(t, file)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+type upperCase struct{}
+
+func (upperCase) Name() string { return "upper_case" }
+
+func (upperCase) Process(repository string, summary ai.IssueSummary) (ai.IssueSummary, error) {
+	summary.Summary = strings.ToUpper(summary.Summary)
+	return summary, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("upper_case", func(options map[string]interface{}) (pipeline.PostProcessor, error) {
+		return upperCase{}, nil
+	})
+	defer func() {
+		factoriesMu.Lock()
+		delete(factories, "upper_case")
+		factoriesMu.Unlock()
+	}()
+
+	processors, err := New([]Config{{Type: "upper_case"}, {Type: TypeDisclaimer, Options: map[string]interface{}{"text": "Generated"}}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if summary := process(t, processors, ai.IssueSummary{Summary: "it crashes"}); summary.Summary != "IT CRASHES\n\nGenerated" {
+		t.Errorf("Expected the registered post-processor to run first, got %q", summary.Summary)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a type twice to panic")
+		}
+	}()
+	Register(TypeDisclaimer, newDisclaimer)
+}
@@ -0,0 +1,246 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Classes of limited endpoints
+const (
+	Webhook = "webhook" // /webhook/*, called by GitHub, Slack and the trackers
+	API     = "api"     // /api/*, called by people and scripts
+)
+
+// Scopes a request can be limited in
+const (
+	ScopeClient = "client" // The limit of the client's address
+	ScopeGlobal = "global" // The limit shared by every client
+)
+
+// maxClients bounds the client buckets kept. Full buckets are dropped first,
+// as a missing bucket starts full anyway.
+const maxClients = 10000
+
+// Limit is a token bucket: requests may arrive at Rate per second on average,
+// and up to Burst at once
+type Limit struct {
+	Rate  float64 // Requests per second, 0 for no limit
+	Burst int
+}
+
+// Enabled reports whether the limit applies
+func (l Limit) Enabled() bool {
+	return l.Rate > 0
+}
+
+// Validate checks the limit
+func (l Limit) Validate() error {
+	if l.Rate < 0 {
+		return fmt.Errorf("rate must not be negative")
+	}
+	if l.Enabled() && l.Burst < 1 {
+		return fmt.Errorf("burst must be at least 1")
+	}
+	return nil
+}
+
+// Config sets the limits of the public endpoints
+type Config struct {
+	Enabled bool
+	Webhook Limit // Per client address. GitHub redelivers failed deliveries in bursts, so its burst is large.
+	API     Limit // Per client address
+	Global  Limit // Across every client, for both classes
+}
+
+// Validate checks the limits
+func (c Config) Validate() error {
+	if err := c.Webhook.Validate(); err != nil {
+		return fmt.Errorf("webhook limit: %w", err)
+	}
+	if err := c.API.Validate(); err != nil {
+		return fmt.Errorf("API limit: %w", err)
+	}
+	if err := c.Global.Validate(); err != nil {
+		return fmt.Errorf("global limit: %w", err)
+	}
+	return nil
+}
+
+// Class returns the class of a request path, empty for paths that are not
+// limited
+func Class(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/webhook/"):
+		return Webhook
+	case strings.HasPrefix(path, "/api/"):
+		return API
+	}
+	return ""
+}
+
+// MetricsRecorder tracks requests turned away
+type MetricsRecorder interface {
+	RecordRateLimited(class, scope string)
+}
+
+// bucket is a token bucket's state
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limited bool // The last request was turned away, so a run of them is logged once
+}
+
+// refill adds the tokens earned since the last update
+func (b *bucket) refill(limit Limit, now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	}
+	b.updated = now
+}
+
+// wait returns how long until the bucket holds a token, 0 if it does
+func (b *bucket) wait(limit Limit) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+}
+
+// Limiter turns away requests to the webhook and API endpoints beyond each
+// client's limit, or beyond the limit shared by every client, so abusive or
+// misconfigured callers cannot run up the OpenAI bill
+type Limiter struct {
+	config   Config
+	clientIP func(r *http.Request) net.IP
+	metrics  MetricsRecorder
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*bucket // By class and address
+	global  *bucket
+}
+
+// New creates a limiter. clientIP returns the address a request came from,
+// nil if it is unknown.
+func New(config Config, clientIP func(r *http.Request) net.IP, metrics MetricsRecorder, logger *zap.Logger) *Limiter {
+	return &Limiter{
+		config:   config,
+		clientIP: clientIP,
+		metrics:  metrics,
+		logger:   logger,
+		now:      time.Now,
+		clients:  make(map[string]*bucket),
+	}
+}
+
+// Check reports whether a request may go through. A request that may not is
+// answered with 429 Too Many Requests and a Retry-After header.
+func (l *Limiter) Check(w http.ResponseWriter, r *http.Request) bool {
+	class := Class(r.URL.Path)
+	if class == "" {
+		return true
+	}
+	address := "unknown"
+	if ip := l.clientIP(r); ip != nil {
+		address = ip.String()
+	}
+
+	scope, wait := l.take(class, address)
+	if scope == "" {
+		return true
+	}
+	l.metrics.RecordRateLimited(class, scope)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+	return false
+}
+
+// take takes a token from the client's bucket and the global one. When
+// either is empty neither is taken from, and it returns the scope of the
+// empty bucket and how long until it holds a token.
+func (l *Limiter) take(class, address string) (string, time.Duration) {
+	limit := l.config.API
+	if class == Webhook {
+		limit = l.config.Webhook
+	}
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var client *bucket
+	if limit.Enabled() {
+		key := class + " " + address
+		client = l.clients[key]
+		if client == nil {
+			l.prune(now)
+			client = &bucket{tokens: float64(limit.Burst), updated: now}
+			l.clients[key] = client
+		}
+		client.refill(limit, now)
+		if wait := client.wait(limit); wait > 0 {
+			if !client.limited {
+				client.limited = true
+				l.logger.Warn("Rate limiting client",
+					zap.String("class", class),
+					zap.String("address", address),
+					zap.Float64("rate", limit.Rate),
+					zap.Int("burst", limit.Burst))
+			}
+			return ScopeClient, wait
+		}
+	}
+	if l.config.Global.Enabled() {
+		if l.global == nil {
+			l.global = &bucket{tokens: float64(l.config.Global.Burst), updated: now}
+		}
+		l.global.refill(l.config.Global, now)
+		if wait := l.global.wait(l.config.Global); wait > 0 {
+			if !l.global.limited {
+				l.global.limited = true
+				l.logger.Warn("Rate limiting every client",
+					zap.Float64("rate", l.config.Global.Rate),
+					zap.Int("burst", l.config.Global.Burst))
+			}
+			return ScopeGlobal, wait
+		}
+		l.global.tokens--
+		l.global.limited = false
+	}
+	if client != nil {
+		client.tokens--
+		client.limited = false
+	}
+	return "", 0
+}
+
+// prune makes room for a new client bucket. The caller holds mu.
+func (l *Limiter) prune(now time.Time) {
+	if len(l.clients) < maxClients {
+		return
+	}
+	for key, b := range l.clients {
+		limit := l.config.API
+		if strings.HasPrefix(key, Webhook+" ") {
+			limit = l.config.Webhook
+		}
+		b.refill(limit, now)
+		if b.tokens >= float64(limit.Burst) {
+			delete(l.clients, key)
+		}
+	}
+	for key := range l.clients {
+		if len(l.clients) < maxClients {
+			break
+		}
+		delete(l.clients, key)
+	}
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type fakeMetrics struct{ limited []string }
+
+func (f *fakeMetrics) RecordRateLimited(class, scope string) {
+	f.limited = append(f.limited, class+" "+scope)
+}
+
+func newTestLimiter(config Config) (*Limiter, *fakeMetrics, *time.Time) {
+	metrics := &fakeMetrics{}
+	clientIP := func(r *http.Request) net.IP {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		return net.ParseIP(host)
+	}
+	l := New(config, clientIP, metrics, zap.NewNop())
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	return l, metrics, &now
+}
+
+func check(l *Limiter, path, address string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, nil)
+	r.RemoteAddr = address + ":4321"
+	w := httptest.NewRecorder()
+	if l.Check(w, r) {
+		w.Code = 0
+	}
+	return w
+}
+
+func TestLimiterPerClient(t *testing.T) {
+	l, metrics, now := newTestLimiter(Config{Enabled: true, Webhook: Limit{Rate: 1, Burst: 3}, API: Limit{Rate: 0.5, Burst: 1}})
+
+	for i := 0; i < 3; i++ {
+		if w := check(l, "/webhook/github", "192.0.2.1"); w.Code != 0 {
+			t.Fatalf("Expected request %d within the burst, got %d", i, w.Code)
+		}
+	}
+	w := check(l, "/webhook/github", "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected 429 with Retry-After 1 past the burst, got %d and %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// Other clients, classes and paths are not affected
+	if w := check(l, "/webhook/github", "192.0.2.2"); w.Code != 0 {
+		t.Errorf("Expected another client let through, got %d", w.Code)
+	}
+	if w := check(l, "/api/issues", "192.0.2.1"); w.Code != 0 {
+		t.Errorf("Expected the API limited separately, got %d", w.Code)
+	}
+	if w := check(l, "/api/issues", "192.0.2.1"); w.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected Retry-After 2 at half a request per second, got %q", w.Header().Get("Retry-After"))
+	}
+	if w := check(l, "/health", "192.0.2.1"); w.Code != 0 {
+		t.Errorf("Expected other paths not limited, got %d", w.Code)
+	}
+
+	*now = now.Add(time.Second)
+	if w := check(l, "/webhook/github", "192.0.2.1"); w.Code != 0 {
+		t.Errorf("Expected a token back after a second, got %d", w.Code)
+	}
+	if len(metrics.limited) != 2 || metrics.limited[0] != "webhook client" || metrics.limited[1] != "api client" {
+		t.Errorf("Expected both rejections recorded, got %v", metrics.limited)
+	}
+}
+
+func TestLimiterGlobal(t *testing.T) {
+	l, metrics, _ := newTestLimiter(Config{Enabled: true, Webhook: Limit{Rate: 1, Burst: 2}, Global: Limit{Rate: 1, Burst: 3}})
+
+	for _, address := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		if w := check(l, "/webhook/github", address); w.Code != 0 {
+			t.Fatalf("Expected %s let through, got %d", address, w.Code)
+		}
+	}
+	if w := check(l, "/api/issues", "192.0.2.4"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the global limit shared by both classes, got %d", w.Code)
+	}
+	if len(metrics.limited) != 1 || metrics.limited[0] != "api global" {
+		t.Errorf("Expected the global rejection recorded, got %v", metrics.limited)
+	}
+
+	// A request turned away globally does not use up the client's burst
+	l.config.Global = Limit{}
+	for i := 0; i < 2; i++ {
+		if w := check(l, "/webhook/github", "192.0.2.4"); w.Code != 0 {
+			t.Errorf("Expected request %d within the client's burst, got %d", i, w.Code)
+		}
+	}
+}
+
+func TestLimiterPrunesClients(t *testing.T) {
+	l, _, now := newTestLimiter(Config{Enabled: true, API: Limit{Rate: 1, Burst: 1}})
+	for i := 0; i < maxClients; i++ {
+		l.take(API, net.IPv4(10, byte(i>>16), byte(i>>8), byte(i)).String())
+	}
+	*now = now.Add(time.Second)
+	check(l, "/api/issues", "192.0.2.1")
+	if len(l.clients) != 1 {
+		t.Errorf("Expected the refilled buckets dropped, got %d", len(l.clients))
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{Enabled: true, Webhook: Limit{Rate: 10, Burst: 100}}).Validate(); err != nil {
+		t.Errorf("Expected a valid config, got %v", err)
+	}
+	for _, config := range []Config{
+		{API: Limit{Rate: -1}},
+		{Global: Limit{Rate: 1}},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package resolution
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ServeReport returns the report of the month given by the month parameter,
+// e.g. ?month=2026-09, or of the month before by default. Issues still open
+// in the store are looked up on GitHub, so a request can take a while.
+func (r *Reporter) ServeReport(w http.ResponseWriter, req *http.Request) {
+	location := r.config.Location()
+	now := r.now().In(location)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location).AddDate(0, -1, 0)
+	if value := req.URL.Query().Get("month"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, location)
+		if err != nil || parsed.After(now) {
+			http.Error(w, "month must be a past or current month, e.g. 2026-09", http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.Build(req.Context(), month))
+}
@@ -0,0 +1,277 @@
+package resolution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/store"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+const (
+	// maxLookups bounds the issues looked up on GitHub for one report, so a
+	// large backlog of open issues does not use up the API rate limit
+	maxLookups = 500
+
+	// highPriority is the lowest priority whose dismissal counts as a
+	// misclassification
+	highPriority = "high"
+
+	// notPlanned is GitHub's state reason for issues closed without a fix,
+	// including duplicates
+	notPlanned = "not_planned"
+)
+
+// Config sets where and when the monthly report is posted
+type Config struct {
+	Channel string // Slack channel for the report on the 1st of each month, empty to serve it from the API only
+	schedule.Schedule
+}
+
+// Validate checks the hour and timezone
+func (c Config) Validate() error {
+	return c.Schedule.Validate("resolution report")
+}
+
+// IssueLister lists the stored issue records
+type IssueLister interface {
+	ListIssues(query store.Query) ([]store.IssueRecord, string)
+}
+
+// IssueResolver looks up whether an issue was closed on GitHub
+type IssueResolver interface {
+	FetchResolution(ctx context.Context, repo string, number int) (time.Time, string, error)
+}
+
+// Poster posts a message to a Slack channel
+type Poster interface {
+	PostMessage(ctx context.Context, channelID, kind, text string) error
+}
+
+// PriorityStats are the issues of one AI-assigned priority resolved in the
+// period
+type PriorityStats struct {
+	Priority    string  `json:"priority"`
+	Resolved    int     `json:"resolved"`
+	MedianHours float64 `json:"median_hours"`
+	Speedup     float64 `json:"speedup"`     // How many times faster than the slowest priority was resolved
+	NotPlanned  int     `json:"not_planned"` // Closed without a fix, e.g. as duplicates or won't fix
+	Overridden  int     `json:"overridden"`  // Priority changed by a human with labels
+}
+
+// Report correlates the priorities the AI assigned with how the issues were
+// actually resolved over one month
+type Report struct {
+	Month       string          `json:"month"` // e.g. 2026-09
+	Since       time.Time       `json:"since"`
+	Until       time.Time       `json:"until"`
+	Resolved    int             `json:"resolved"` // Analyzed issues closed in the month
+	MedianHours float64         `json:"median_hours"`
+	Priorities  []PriorityStats `json:"priorities"` // Fastest resolved first
+	Slowest     string          `json:"slowest_priority,omitempty"`
+
+	// Accuracy of the resolved issues' summaries
+	PriorityOverrides     int     `json:"priority_overrides"`
+	CategoryOverrides     int     `json:"category_overrides"`
+	DismissedHigh         int     `json:"dismissed_high"` // Marked high or above but closed as not planned
+	Misclassified         int     `json:"misclassified"`  // Overridden or dismissed high, counted once each
+	MisclassificationRate float64 `json:"misclassification_rate"`
+
+	Lookups      int `json:"github_lookups"` // Issues whose resolution was read from GitHub
+	LookupErrors int `json:"github_lookup_errors"`
+}
+
+// Reporter builds monthly resolution reports and posts them to Slack
+type Reporter struct {
+	issues   IssueLister
+	resolver IssueResolver
+	poster   Poster
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewReporter creates a reporter. resolver may be nil to rely on the closes
+// seen in webhooks, and poster may be nil when config has no channel.
+func NewReporter(issues IssueLister, resolver IssueResolver, poster Poster, config Config, logger *zap.Logger) *Reporter {
+	return &Reporter{
+		issues:   issues,
+		resolver: resolver,
+		poster:   poster,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Run posts the report of the month before on the 1st of each month until
+// the context is cancelled. A report due before Run started is not posted, so
+// a restart does not post the month's report again.
+func (r *Reporter) Run(ctx context.Context) {
+	if r.config.Channel == "" || r.poster == nil {
+		return
+	}
+	r.config.Run(ctx, r.now, func(due time.Time) {
+		r.PostReport(ctx, due.AddDate(0, -1, 0))
+	})
+}
+
+// PostReport posts the report of the month containing month to the channel
+func (r *Reporter) PostReport(ctx context.Context, month time.Time) {
+	report := r.Build(ctx, month)
+	if err := r.poster.PostMessage(ctx, r.config.Channel, "resolution_report", FormatReport(report)); err != nil {
+		r.logger.Warn("Failed to post resolution report",
+			zap.String("month", report.Month),
+			zap.String("channel", r.config.Channel),
+			zap.Error(err))
+	}
+}
+
+// Build reports on the analyzed issues closed in the month containing month,
+// in the report's timezone. Closes are recorded from webhooks; issues still
+// stored as open are looked up on GitHub, since closes the bot ignores or
+// missed never reach the store.
+func (r *Reporter) Build(ctx context.Context, month time.Time) Report {
+	location := r.config.Location()
+	month = month.In(location)
+	since := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, location)
+	until := since.AddDate(0, 1, 0)
+	report := Report{Month: since.Format("2006-01"), Since: since, Until: until, Priorities: []PriorityStats{}}
+
+	records, _ := r.issues.ListIssues(store.Query{})
+	sort.Slice(records, func(i, j int) bool { return records[i].OpenedAt.After(records[j].OpenedAt) })
+	for i := range records {
+		record := &records[i]
+		if record.Summary == nil || record.OpenedAt.IsZero() || !record.OpenedAt.Before(until) || !record.ClosedAt.IsZero() {
+			continue
+		}
+		if r.resolver == nil || report.Lookups+report.LookupErrors == maxLookups {
+			break
+		}
+		closedAt, reason, err := r.resolver.FetchResolution(ctx, record.Repository, record.Number)
+		if err != nil {
+			report.LookupErrors++
+			r.logger.Debug("Failed to look up issue resolution",
+				zap.String("repository", record.Repository),
+				zap.Int("issue_number", record.Number),
+				zap.Error(err))
+			continue
+		}
+		report.Lookups++
+		record.ClosedAt, record.CloseReason = closedAt, reason
+	}
+	if report.LookupErrors > 0 {
+		r.logger.Warn("Failed to look up some issue resolutions on GitHub",
+			zap.String("month", report.Month),
+			zap.Int("errors", report.LookupErrors))
+	}
+
+	Summarize(&report, records)
+	return report
+}
+
+// Summarize fills in report from the analyzed issues in records closed
+// between report.Since and report.Until
+func Summarize(report *Report, records []store.IssueRecord) {
+	var all []time.Duration
+	byPriority := make(map[string][]time.Duration)
+	stats := make(map[string]*PriorityStats)
+	for _, record := range records {
+		if record.Summary == nil || record.OpenedAt.IsZero() ||
+			record.ClosedAt.Before(report.Since) || !record.ClosedAt.Before(report.Until) {
+			continue
+		}
+		priority := strings.ToLower(record.Summary.Priority)
+		s, ok := stats[priority]
+		if !ok {
+			s = &PriorityStats{Priority: priority}
+			stats[priority] = s
+		}
+
+		elapsed := record.ClosedAt.Sub(record.OpenedAt)
+		all = append(all, elapsed)
+		byPriority[priority] = append(byPriority[priority], elapsed)
+		report.Resolved++
+		s.Resolved++
+
+		dismissed := record.CloseReason == notPlanned
+		if dismissed {
+			s.NotPlanned++
+		}
+		overridden := false
+		for _, field := range record.Overridden {
+			switch field {
+			case "priority":
+				report.PriorityOverrides++
+				s.Overridden++
+				overridden = true
+			case "category":
+				report.CategoryOverrides++
+				overridden = true
+			}
+		}
+		dismissedHigh := dismissed && ai.PriorityAtLeast(priority, highPriority)
+		if dismissedHigh {
+			report.DismissedHigh++
+		}
+		if overridden || dismissedHigh {
+			report.Misclassified++
+		}
+	}
+	if report.Resolved == 0 {
+		return
+	}
+
+	report.MedianHours = utils.MedianDuration(all).Hours()
+	report.MisclassificationRate = float64(report.Misclassified) / float64(report.Resolved)
+	var slowest float64
+	for priority, s := range stats {
+		s.MedianHours = utils.MedianDuration(byPriority[priority]).Hours()
+		if s.MedianHours > slowest || s.MedianHours == slowest && priority < report.Slowest {
+			slowest, report.Slowest = s.MedianHours, priority
+		}
+	}
+	for _, s := range stats {
+		if s.MedianHours > 0 {
+			s.Speedup = slowest / s.MedianHours
+		}
+		report.Priorities = append(report.Priorities, *s)
+	}
+	sort.Slice(report.Priorities, func(i, j int) bool {
+		a, b := report.Priorities[i], report.Priorities[j]
+		return a.MedianHours < b.MedianHours || a.MedianHours == b.MedianHours && a.Priority < b.Priority
+	})
+}
+
+// FormatReport writes a report as a Slack message
+func FormatReport(report Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Issue resolution report for %s*\n", report.Since.Format("January 2006"))
+	if report.Resolved == 0 {
+		b.WriteString("No analyzed issues were closed this month.")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "• Issues resolved: %d, median time to resolution %s\n", report.Resolved, utils.FormatHours(report.MedianHours))
+	for _, s := range report.Priorities {
+		label := s.Priority
+		if label == "" {
+			label = "(none)"
+		}
+		fmt.Fprintf(&b, "• Marked %s: %d resolved in a median %s", label, s.Resolved, utils.FormatHours(s.MedianHours))
+		if s.Priority != report.Slowest && s.Speedup >= 1.05 {
+			fmt.Fprintf(&b, ", %.1fx faster than %s", s.Speedup, report.Slowest)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "• Misclassified: %.0f%% (%d priority and %d category overrides, %d high priority closed as not planned)",
+		report.MisclassificationRate*100, report.PriorityOverrides, report.CategoryOverrides, report.DismissedHigh)
+	return b.String()
+}
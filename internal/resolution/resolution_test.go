@@ -0,0 +1,197 @@
+package resolution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/store"
+)
+
+var september = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+func record(number int, priority string, opened time.Time, resolution time.Duration, reason string, overridden ...string) store.IssueRecord {
+	r := store.IssueRecord{
+		Repository: "acme/api",
+		Number:     number,
+		State:      "open",
+		Summary:    &ai.IssueSummary{Priority: priority},
+		OpenedAt:   opened,
+		Overridden: overridden,
+	}
+	if resolution > 0 {
+		r.State, r.ClosedAt, r.CloseReason = "closed", opened.Add(resolution), reason
+	}
+	return r
+}
+
+type fakeResolver struct {
+	closed  map[int]time.Time
+	fail    map[int]bool
+	lookups []int
+}
+
+func (f *fakeResolver) FetchResolution(ctx context.Context, repo string, number int) (time.Time, string, error) {
+	f.lookups = append(f.lookups, number)
+	if f.fail[number] {
+		return time.Time{}, "", fmt.Errorf("rate limited")
+	}
+	if at, ok := f.closed[number]; ok {
+		return at, "completed", nil
+	}
+	return time.Time{}, "", nil
+}
+
+type fakePoster struct{ posts []string }
+
+func (p *fakePoster) PostMessage(ctx context.Context, channelID, kind, text string) error {
+	p.posts = append(p.posts, channelID+" "+kind+" "+text)
+	return nil
+}
+
+func testReporter() (*Reporter, *fakeResolver, *fakePoster) {
+	issues := store.NewMemoryStore()
+	day := 24 * time.Hour
+	for _, r := range []store.IssueRecord{
+		record(1, "high", september.Add(day), 4*time.Hour, "completed"),
+		record(2, "high", september.Add(2*day), 8*time.Hour, "completed"),
+		record(3, "critical", september.Add(3*day), 2*time.Hour, "not_planned"), // Dismissed
+		record(4, "low", september.Add(4*day), 40*time.Hour, "completed"),
+		record(5, "low", september.Add(5*day), 24*time.Hour, "completed", "priority"),
+		record(6, "medium", september.AddDate(0, 0, -20), 10*day, "completed"),            // Closed in August
+		record(7, "medium", september.Add(6*day), 0, ""),                                  // Closed, but only GitHub knows
+		record(8, "medium", september.Add(7*day), 0, ""),                                  // Still open
+		record(9, "low", september.Add(8*day), 0, ""),                                     // Lookup fails
+		record(10, "high", september.AddDate(0, 1, 2), time.Hour, "completed"),            // Opened in October
+		{Repository: "acme/api", Number: 11, OpenedAt: september.Add(day), State: "open"}, // Never analyzed
+	} {
+		issues.SaveIssue(&r)
+	}
+	resolver := &fakeResolver{
+		closed: map[int]time.Time{7: september.Add(6*day + 12*time.Hour)},
+		fail:   map[int]bool{9: true},
+	}
+	poster := &fakePoster{}
+	reporter := NewReporter(issues, resolver, poster, Config{Channel: "C-REPORTS", Schedule: schedule.Schedule{Hour: 9}}, zap.NewNop())
+	reporter.now = func() time.Time { return september.AddDate(0, 1, 0).Add(10 * time.Hour) }
+	return reporter, resolver, poster
+}
+
+func TestBuild(t *testing.T) {
+	reporter, resolver, _ := testReporter()
+	report := reporter.Build(context.Background(), september.Add(12*24*time.Hour))
+
+	if report.Month != "2026-09" || report.Resolved != 6 {
+		t.Fatalf("Expected 6 issues resolved in 2026-09, got %d in %s", report.Resolved, report.Month)
+	}
+	if len(resolver.lookups) != 3 || report.Lookups != 2 || report.LookupErrors != 1 {
+		t.Errorf("Expected only analyzed issues stored as open to be looked up, got %v", resolver.lookups)
+	}
+
+	want := map[string]PriorityStats{
+		"critical": {Priority: "critical", Resolved: 1, MedianHours: 2, Speedup: 16, NotPlanned: 1},
+		"high":     {Priority: "high", Resolved: 2, MedianHours: 6, Speedup: 32.0 / 6},
+		"medium":   {Priority: "medium", Resolved: 1, MedianHours: 12, Speedup: 32.0 / 12},
+		"low":      {Priority: "low", Resolved: 2, MedianHours: 32, Speedup: 1, Overridden: 1},
+	}
+	for _, s := range report.Priorities {
+		if s != want[s.Priority] {
+			t.Errorf("Expected %+v, got %+v", want[s.Priority], s)
+		}
+	}
+	if len(report.Priorities) != 4 || report.Priorities[0].Priority != "critical" || report.Slowest != "low" {
+		t.Errorf("Expected the fastest priority first and low slowest, got %+v", report.Priorities)
+	}
+	if report.PriorityOverrides != 1 || report.DismissedHigh != 1 || report.Misclassified != 2 || report.MisclassificationRate != 2.0/6 {
+		t.Errorf("Expected an override and a dismissed critical issue, got %+v", report)
+	}
+}
+
+func TestFormatReport(t *testing.T) {
+	reporter, _, _ := testReporter()
+	text := FormatReport(reporter.Build(context.Background(), september))
+	for _, want := range []string{
+		"*Issue resolution report for September 2026*",
+		"Issues resolved: 6, median time to resolution 10.0 hours",
+		"Marked high: 2 resolved in a median 6.0 hours, 5.3x faster than low",
+		"Marked low: 2 resolved in a median 32.0 hours\n",
+		"Misclassified: 33% (1 priority and 0 category overrides, 1 high priority closed as not planned)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in:\n%s", want, text)
+		}
+	}
+
+	empty := FormatReport(Report{Since: september})
+	if !strings.Contains(empty, "No analyzed issues were closed") {
+		t.Errorf("Expected an empty month to say so, got %q", empty)
+	}
+}
+
+func TestPostReport(t *testing.T) {
+	reporter, _, poster := testReporter()
+	if due := reporter.config.Last(reporter.now()); !due.Equal(time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the report due on the 1st at 09:00, got %s", due)
+	}
+	if due := reporter.config.Last(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)); !due.Equal(time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the previous month's slot before the hour, got %s", due)
+	}
+
+	reporter.PostReport(context.Background(), september)
+	if len(poster.posts) != 1 || !strings.HasPrefix(poster.posts[0], "C-REPORTS resolution_report *Issue resolution report for September 2026*") {
+		t.Errorf("Expected the report posted to the channel, got %q", poster.posts)
+	}
+}
+
+func TestServeReport(t *testing.T) {
+	reporter, _, _ := testReporter()
+
+	rec := httptest.NewRecorder()
+	reporter.ServeReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/resolution", nil))
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Month != "2026-09" || report.Resolved != 6 {
+		t.Errorf("Expected last month's report by default, got %+v", report)
+	}
+
+	for _, month := range []string{"september", "2027-01"} {
+		rec = httptest.NewRecorder()
+		reporter.ServeReport(rec, httptest.NewRequest(http.MethodGet, "/api/reports/resolution?month="+month, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected a bad request, got %d", month, rec.Code)
+		}
+	}
+}
+
+func TestReportTimezone(t *testing.T) {
+	reporter, _, _ := testReporter()
+	reporter.config.Timezone = "America/Los_Angeles"
+
+	// 09:00 on the 1st in Los Angeles is 16:00 UTC, in daylight saving time
+	if due := reporter.config.Last(time.Date(2026, 10, 1, 15, 0, 0, 0, time.UTC)); !due.Equal(time.Date(2026, 9, 1, 16, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the previous month's slot before 09:00 local, got %s", due.UTC())
+	}
+	// Months start at local midnight, and November ends in standard time
+	report := reporter.Build(context.Background(), time.Date(2026, 11, 1, 3, 0, 0, 0, time.UTC))
+	if report.Month != "2026-10" || !report.Since.Equal(time.Date(2026, 10, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected October in Los Angeles, got %s from %s", report.Month, report.Since.UTC())
+	}
+	report = reporter.Build(context.Background(), time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC))
+	if !report.Until.Equal(time.Date(2026, 12, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected November to end at 08:00 UTC, got %s", report.Until.UTC())
+	}
+	if err := (Config{Schedule: schedule.Schedule{Hour: 9, Timezone: "Mars/Olympus"}}).Validate(); err == nil {
+		t.Error("Expected an error for an unknown timezone")
+	}
+}
@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"context"
+	"sync"
+)
+
+// Limiter bounds how many tasks run at once, with a limit that can change
+// while tasks wait
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+	wake   chan struct{} // Closed and replaced whenever a slot may have freed
+}
+
+// NewLimiter creates a limiter allowing limit tasks at once
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{limit: max(1, limit), wake: make(chan struct{})}
+}
+
+// Acquire waits for a free slot. It returns the context's error if ctx ends
+// first.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release frees a slot taken with Acquire
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.broadcast()
+}
+
+// Limit returns the current limit
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// SetLimit changes the limit. Tasks already running above a lowered limit
+// finish; new ones wait until the number running drops below it.
+func (l *Limiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = max(1, limit)
+	l.broadcast()
+}
+
+// broadcast wakes every waiting task. The caller holds mu.
+func (l *Limiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
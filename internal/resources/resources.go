@@ -0,0 +1,178 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// recoveryMargin is how far below the pressure threshold memory use must fall
+// before throttled enrichment speeds up again, so the limit does not flap
+const recoveryMargin = 0.1
+
+// Config sets how resources are sampled and when enrichment is throttled
+type Config struct {
+	Interval       time.Duration // How often usage is sampled, 0 to disable the monitor
+	MemoryLimit    uint64        // Heap bytes pressure is measured against, 0 for the runtime's soft limit (GOMEMLIMIT)
+	Pressure       float64       // Share of MemoryLimit at which enrichment is throttled, e.g. 0.8
+	MaxEnrichments int           // Issues enriched at once without memory pressure
+}
+
+// Validate checks the threshold and concurrency
+func (c Config) Validate() error {
+	if c.Interval == 0 {
+		return nil
+	}
+	if c.Interval < 0 {
+		return fmt.Errorf("resource sample interval must not be negative")
+	}
+	if c.Pressure <= 0 || c.Pressure > 1 {
+		return fmt.Errorf("memory pressure threshold must be above 0 and at most 1, got %g", c.Pressure)
+	}
+	if c.MaxEnrichments < 1 {
+		return fmt.Errorf("enrichment concurrency must be positive, got %d", c.MaxEnrichments)
+	}
+	return nil
+}
+
+// Usage is a sample of the process's resources
+type Usage struct {
+	Goroutines int
+	HeapBytes  uint64 // Bytes of heap objects, live or not yet swept
+	Queued     int    // Accepted webhooks that have not finished processing
+}
+
+// Queue reports how much accepted work is waiting, e.g. a tenant's webhook
+// handler
+type Queue interface {
+	QueueDepth() int
+}
+
+// MetricsRecorder records sampled usage
+type MetricsRecorder interface {
+	RecordResourceUsage(goroutines int, heapBytes uint64, queued, enrichmentLimit int)
+}
+
+// Monitor samples goroutines, heap and queued webhooks, and halves the number
+// of issues enriched at once while the heap is close to the memory limit.
+// Enrichment holds comments, commits and attachments in memory, so during a
+// webhook storm it is what pushes small pods into OOM kills. The limit
+// doubles back once memory use has recovered.
+type Monitor struct {
+	config  Config
+	limit   uint64 // Memory limit in bytes, 0 when unknown and nothing is throttled
+	limiter *Limiter
+	queues  []Queue
+	metrics MetricsRecorder
+	logger  *zap.Logger
+	sample  func() Usage
+}
+
+// NewMonitor creates a monitor. Without a memory limit in config or
+// GOMEMLIMIT, usage is still recorded but enrichment is never throttled.
+func NewMonitor(config Config, metrics MetricsRecorder, logger *zap.Logger) *Monitor {
+	limit := config.MemoryLimit
+	if limit == 0 {
+		// A negative input reads the limit without changing it
+		if soft := debug.SetMemoryLimit(-1); soft != math.MaxInt64 {
+			limit = uint64(soft)
+		}
+	}
+	return &Monitor{
+		config:  config,
+		limit:   limit,
+		limiter: NewLimiter(config.MaxEnrichments),
+		metrics: metrics,
+		logger:  logger,
+		sample:  readUsage,
+	}
+}
+
+// Limiter returns the limiter enrichment waits on
+func (m *Monitor) Limiter() *Limiter {
+	return m.limiter
+}
+
+// MemoryLimit returns the memory limit pressure is measured against, 0 if
+// there is none
+func (m *Monitor) MemoryLimit() uint64 {
+	return m.limit
+}
+
+// AddQueue counts queue's waiting work in the sampled usage
+func (m *Monitor) AddQueue(queue Queue) {
+	m.queues = append(m.queues, queue)
+}
+
+// Run samples usage every interval until the context is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check samples usage, records it and adjusts the enrichment limit
+func (m *Monitor) Check() Usage {
+	usage := m.sample()
+	for _, queue := range m.queues {
+		usage.Queued += queue.QueueDepth()
+	}
+
+	current := m.limiter.Limit()
+	next := current
+	if m.limit > 0 {
+		pressure := float64(usage.HeapBytes) / float64(m.limit)
+		switch {
+		case pressure >= m.config.Pressure:
+			next = max(1, current/2)
+		case pressure < m.config.Pressure-recoveryMargin:
+			next = min(m.config.MaxEnrichments, current*2)
+		}
+		if next < current {
+			m.logger.Warn("Memory pressure, throttling enrichment",
+				zap.Uint64("heap_bytes", usage.HeapBytes),
+				zap.Uint64("memory_limit", m.limit),
+				zap.Int("goroutines", usage.Goroutines),
+				zap.Int("queued", usage.Queued),
+				zap.Int("enrichment_limit", next))
+		} else if next > current {
+			m.logger.Info("Memory pressure eased, raising enrichment limit",
+				zap.Uint64("heap_bytes", usage.HeapBytes),
+				zap.Int("enrichment_limit", next))
+		}
+		m.limiter.SetLimit(next)
+	}
+
+	m.metrics.RecordResourceUsage(usage.Goroutines, usage.HeapBytes, usage.Queued, next)
+	return usage
+}
+
+// readUsage reads goroutines and heap size from the runtime without stopping
+// the world, unlike runtime.ReadMemStats
+func readUsage() Usage {
+	samples := []metrics.Sample{
+		{Name: "/sched/goroutines:goroutines"},
+		{Name: "/memory/classes/heap/objects:bytes"},
+	}
+	metrics.Read(samples)
+	var usage Usage
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		usage.Goroutines = int(samples[0].Value.Uint64())
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		usage.HeapBytes = samples[1].Value.Uint64()
+	}
+	return usage
+}
@@ -0,0 +1,106 @@
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type fakeMetrics struct {
+	queued, limit int
+}
+
+func (m *fakeMetrics) RecordResourceUsage(goroutines int, heapBytes uint64, queued, enrichmentLimit int) {
+	m.queued, m.limit = queued, enrichmentLimit
+}
+
+type fakeQueue int
+
+func (q fakeQueue) QueueDepth() int { return int(q) }
+
+func TestCheckThrottlesUnderPressure(t *testing.T) {
+	metrics := &fakeMetrics{}
+	m := NewMonitor(Config{Interval: time.Second, MemoryLimit: 1000, Pressure: 0.8, MaxEnrichments: 8}, metrics, zap.NewNop())
+	m.AddQueue(fakeQueue(3))
+	m.AddQueue(fakeQueue(4))
+
+	var heap uint64
+	m.sample = func() Usage { return Usage{Goroutines: 50, HeapBytes: heap} }
+	for _, step := range []struct {
+		heap uint64
+		want int
+	}{
+		{500, 8}, // No pressure
+		{850, 4}, // Halved under pressure
+		{900, 2},
+		{750, 2}, // Below the threshold but not by the margin
+		{600, 4}, // Recovered, doubled back
+		{100, 8},
+		{100, 8}, // Never above the configured concurrency
+	} {
+		heap = step.heap
+		m.Check()
+		if got := m.Limiter().Limit(); got != step.want {
+			t.Errorf("heap %d: expected a limit of %d, got %d", step.heap, step.want, got)
+		}
+	}
+	if metrics.queued != 7 || metrics.limit != 8 {
+		t.Errorf("Expected 7 queued webhooks and the limit recorded, got %+v", metrics)
+	}
+}
+
+func TestCheckWithoutMemoryLimit(t *testing.T) {
+	m := NewMonitor(Config{Interval: time.Second, Pressure: 0.8, MaxEnrichments: 8}, &fakeMetrics{}, zap.NewNop())
+	m.limit = 0 // As without GOMEMLIMIT
+	m.sample = func() Usage { return Usage{HeapBytes: 1 << 40} }
+	m.Check()
+	if got := m.Limiter().Limit(); got != 8 {
+		t.Errorf("Expected no throttling without a memory limit, got %d", got)
+	}
+	if usage := readUsage(); usage.Goroutines == 0 || usage.HeapBytes == 0 {
+		t.Errorf("Expected the runtime's usage, got %+v", usage)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(1)
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A full limiter makes callers wait until their context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx); err == nil {
+		t.Fatal("Expected to wait for a slot")
+	}
+
+	// Raising the limit lets a waiting caller through
+	acquired := make(chan error, 1)
+	go func() { acquired <- l.Acquire(context.Background()) }()
+	l.SetLimit(2)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the raised limit to admit the waiting caller")
+	}
+
+	// Lowering it holds new callers until enough running tasks finish
+	l.SetLimit(1)
+	l.Release()
+	go func() { acquired <- l.Acquire(context.Background()) }()
+	select {
+	case <-acquired:
+		t.Fatal("Expected to wait while at the lowered limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.Release()
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,256 @@
+package routing
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github-issue-ai-bot/internal/expr"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// Slack layouts a route can select
+const (
+	LayoutDetailed = "detailed" // Full multi-block card
+	LayoutCompact  = "compact"  // Single section with title, priority, one-line summary and link
+	LayoutPlain    = "plain"    // Text-only sections without emoji, for screen readers
+)
+
+// Rule routes matching issues to a Slack channel and notifiers. Empty criteria match
+// everything; within a list any entry may match. Rules are evaluated in
+// order and the first match wins.
+type Rule struct {
+	Name         string   `mapstructure:"name" json:"name"`
+	Repositories []string `mapstructure:"repositories" json:"repositories"` // Glob patterns, e.g. "my-org/*"
+	Priorities   []string `mapstructure:"priorities" json:"priorities"`
+	Categories   []string `mapstructure:"categories" json:"categories"`
+	Labels       []string `mapstructure:"labels" json:"labels"`
+	Components   []string `mapstructure:"components" json:"components"`
+	Channel      string   `mapstructure:"channel" json:"channel"`     // Defaults to the global channel
+	Layout       string   `mapstructure:"layout" json:"layout"`       // Defaults to the global layout
+	Notifiers    []string `mapstructure:"notifiers" json:"notifiers"` // Notifier names, defaults to all notifiers
+
+	// When is a condition the issue must also meet, in the expression
+	// language of package expr, e.g. issue.labels.exists(l, l == "security")
+	// && repo.startsWith("org/payments")
+	When string `mapstructure:"when" json:"when,omitempty"`
+
+	// Escalation overrides how issues routed by the rule are escalated when
+	// nobody engages with their Slack message
+	Escalation Escalation `mapstructure:"escalation" json:"escalation"`
+}
+
+// Escalation says when and where an issue whose Slack message nobody clicked,
+// reacted to or replied to is escalated. Empty fields keep the defaults.
+type Escalation struct {
+	AfterHours int    `mapstructure:"after_hours" json:"after_hours,omitempty"` // Hours after posting without engagement
+	Priority   string `mapstructure:"priority" json:"priority,omitempty"`       // Lowest priority escalated
+	Channel    string `mapstructure:"channel" json:"channel,omitempty"`         // Secondary Slack channel to post to
+	DM         string `mapstructure:"dm" json:"dm,omitempty"`                   // Slack user ID of the repository owner to message
+}
+
+// slackUserPattern matches Slack user IDs
+var slackUserPattern = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// ValidSlackUser reports whether id looks like a Slack user ID
+func ValidSlackUser(id string) bool {
+	return slackUserPattern.MatchString(id)
+}
+
+// conditions compiles the When conditions of routing rules. An issue is
+// repo, and issue with the fields below.
+var conditions = expr.NewCompiler(expr.Variables{
+	"repo": "",
+	"issue": map[string]interface{}{
+		"title":      "",
+		"author":     "",
+		"priority":   "",
+		"category":   "",
+		"labels":     []string{},
+		"components": []string{},
+	},
+})
+
+// Route is the destination chosen for an issue
+type Route struct {
+	Rule      string
+	Channel   string
+	Layout    string
+	Notifiers []string // Empty for all notifiers
+}
+
+// Issue holds the fields rules match against
+type Issue struct {
+	Repository string
+	Title      string
+	Author     string // GitHub login of the reporter
+	Priority   string
+	Category   string
+	Labels     []string
+	Components []string
+}
+
+// variables returns the issue as the variables of a When condition
+func (issue Issue) variables() map[string]interface{} {
+	return map[string]interface{}{
+		"repo": issue.Repository,
+		"issue": map[string]interface{}{
+			"title":      issue.Title,
+			"author":     issue.Author,
+			"priority":   issue.Priority,
+			"category":   issue.Category,
+			"labels":     issue.Labels,
+			"components": issue.Components,
+		},
+	}
+}
+
+// Router picks a Slack channel and layout for each issue
+type Router struct {
+	rules    []Rule
+	when     []*expr.Program // Compiled When condition of each rule, nil for none
+	fallback Route
+}
+
+// NewRouter validates the rules and creates a router. Issues that match no
+// rule go to defaultChannel using defaultLayout.
+func NewRouter(rules []Rule, defaultChannel, defaultLayout string) (*Router, error) {
+	if defaultLayout == "" {
+		defaultLayout = LayoutDetailed
+	}
+	if !validLayout(defaultLayout) {
+		return nil, fmt.Errorf("invalid default layout %q", defaultLayout)
+	}
+
+	when := make([]*expr.Program, len(rules))
+	for i, rule := range rules {
+		if rule.Layout != "" && !validLayout(rule.Layout) {
+			return nil, fmt.Errorf("routing rule %d (%s): invalid layout %q", i, rule.Name, rule.Layout)
+		}
+		for _, pattern := range rule.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("routing rule %d (%s): invalid repository pattern %q: %w", i, rule.Name, pattern, err)
+			}
+		}
+		if rule.Escalation.AfterHours < 0 {
+			return nil, fmt.Errorf("routing rule %d (%s): escalation after_hours must not be negative", i, rule.Name)
+		}
+		if rule.Escalation.DM != "" && !ValidSlackUser(rule.Escalation.DM) {
+			return nil, fmt.Errorf("routing rule %d (%s): escalation dm must be a Slack user ID, got %q", i, rule.Name, rule.Escalation.DM)
+		}
+		if rule.When != "" {
+			program, err := conditions.Compile(rule.When)
+			if err != nil {
+				return nil, fmt.Errorf("routing rule %d (%s): invalid condition: %w", i, rule.Name, err)
+			}
+			when[i] = program
+		}
+	}
+
+	return &Router{
+		rules:    rules,
+		when:     when,
+		fallback: Route{Rule: "default", Channel: defaultChannel, Layout: defaultLayout},
+	}, nil
+}
+
+// Route returns the destination for an issue
+func (r *Router) Route(issue Issue) Route {
+	var variables map[string]interface{}
+	for i, rule := range r.rules {
+		if !rule.matches(issue) {
+			continue
+		}
+		if r.when[i] != nil {
+			if variables == nil {
+				variables = issue.variables()
+			}
+			// Conditions that fail to evaluate, e.g. on an index out of
+			// range, do not match
+			if ok, err := r.when[i].Match(variables); err != nil || !ok {
+				continue
+			}
+		}
+
+		route := r.fallback
+		route.Rule = rule.Name
+		if rule.Channel != "" {
+			route.Channel = rule.Channel
+		}
+		if rule.Layout != "" {
+			route.Layout = rule.Layout
+		}
+		route.Notifiers = rule.Notifiers
+		return route
+	}
+	return r.fallback
+}
+
+// Escalation returns the escalation settings of the named rule, empty for
+// the default route and rules that no longer exist
+func (r *Router) Escalation(rule string) Escalation {
+	for _, candidate := range r.rules {
+		if candidate.Name == rule {
+			return candidate.Escalation
+		}
+	}
+	return Escalation{}
+}
+
+// Channels returns every channel issues can be routed to, the default first
+func (r *Router) Channels() []string {
+	channels := []string{r.fallback.Channel}
+	seen := map[string]bool{r.fallback.Channel: true}
+	for _, rule := range r.rules {
+		if rule.Channel != "" && !seen[rule.Channel] {
+			seen[rule.Channel] = true
+			channels = append(channels, rule.Channel)
+		}
+	}
+	return channels
+}
+
+// matches reports whether every configured criterion matches the issue
+func (rule Rule) matches(issue Issue) bool {
+	if len(rule.Repositories) > 0 && !matchAnyPattern(rule.Repositories, issue.Repository) {
+		return false
+	}
+	if len(rule.Priorities) > 0 && !utils.ContainsFold(rule.Priorities, issue.Priority) {
+		return false
+	}
+	if len(rule.Categories) > 0 && !utils.ContainsFold(rule.Categories, issue.Category) {
+		return false
+	}
+	if len(rule.Labels) > 0 && !containsAnyFold(rule.Labels, issue.Labels) {
+		return false
+	}
+	if len(rule.Components) > 0 && !containsAnyFold(rule.Components, issue.Components) {
+		return false
+	}
+	return true
+}
+
+// containsAnyFold reports whether any of values is in want
+func containsAnyFold(want, values []string) bool {
+	for _, value := range values {
+		if utils.ContainsFold(want, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func validLayout(layout string) bool {
+	return layout == LayoutDetailed || layout == LayoutCompact || layout == LayoutPlain
+}
+
+func matchAnyPattern(patterns []string, value string) bool {
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), value); ok {
+			return true
+		}
+	}
+	return false
+}
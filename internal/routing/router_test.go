@@ -0,0 +1,154 @@
+package routing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRouterFirstMatchWins(t *testing.T) {
+	router, err := NewRouter([]Rule{
+		{Name: "security", Categories: []string{"security"}, Channel: "C-SEC", Notifiers: []string{"slack", "siem"}},
+		{Name: "busy-repo", Repositories: []string{"my-org/monorepo"}, Layout: LayoutCompact},
+		{Name: "notifications", Components: []string{"notifications"}, Channel: "C-NOTIFY"},
+		{Name: "org-bugs", Repositories: []string{"my-org/*"}, Labels: []string{"bug"}, Channel: "C-BUGS"},
+	}, "C-DEFAULT", LayoutDetailed)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		issue Issue
+		want  Route
+	}{
+		{
+			name:  "category match overrides repo rule",
+			issue: Issue{Repository: "my-org/monorepo", Category: "Security"},
+			want:  Route{Rule: "security", Channel: "C-SEC", Layout: LayoutDetailed, Notifiers: []string{"slack", "siem"}},
+		},
+		{
+			name:  "compact layout keeps default channel",
+			issue: Issue{Repository: "my-org/monorepo", Category: "bug"},
+			want:  Route{Rule: "busy-repo", Channel: "C-DEFAULT", Layout: LayoutCompact},
+		},
+		{
+			name:  "component match",
+			issue: Issue{Repository: "my-org/api", Labels: []string{"bug"}, Components: []string{"ai", "Notifications"}},
+			want:  Route{Rule: "notifications", Channel: "C-NOTIFY", Layout: LayoutDetailed},
+		},
+		{
+			name:  "glob and label match",
+			issue: Issue{Repository: "My-Org/api", Labels: []string{"triage", "BUG"}},
+			want:  Route{Rule: "org-bugs", Channel: "C-BUGS", Layout: LayoutDetailed},
+		},
+		{
+			name:  "no match falls back to default",
+			issue: Issue{Repository: "other/repo", Labels: []string{"bug"}},
+			want:  Route{Rule: "default", Channel: "C-DEFAULT", Layout: LayoutDetailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := router.Route(tt.issue); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouterConditions(t *testing.T) {
+	router, err := NewRouter([]Rule{
+		{Name: "payments-security", When: `issue.labels.exists(l, l == "security") && repo.startsWith("org/payments")`, Channel: "C-PAYSEC"},
+		{Name: "bots", Priorities: []string{"low"}, When: `issue.author.endsWith("[bot]")`, Layout: LayoutCompact},
+	}, "C-DEFAULT", LayoutDetailed)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		issue Issue
+		want  string
+	}{
+		{"condition met", Issue{Repository: "org/payments-api", Labels: []string{"bug", "security"}}, "payments-security"},
+		{"other repository", Issue{Repository: "org/docs", Labels: []string{"security"}}, "default"},
+		{"criteria and condition", Issue{Repository: "org/api", Author: "dependabot[bot]", Priority: "low"}, "bots"},
+		{"criteria without condition", Issue{Repository: "org/api", Author: "dependabot[bot]", Priority: "high"}, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := router.Route(tt.issue); got.Rule != tt.want {
+				t.Errorf("Expected rule %q, got %q", tt.want, got.Rule)
+			}
+		})
+	}
+}
+
+func TestNewRouterValidation(t *testing.T) {
+	if _, err := NewRouter(nil, "C1", "fancy"); err == nil {
+		t.Error("Expected error for invalid default layout")
+	}
+	if _, err := NewRouter([]Rule{{Layout: "tiny"}}, "C1", ""); err == nil {
+		t.Error("Expected error for invalid rule layout")
+	}
+	if _, err := NewRouter([]Rule{{Repositories: []string{"["}}}, "C1", ""); err == nil {
+		t.Error("Expected error for invalid repository pattern")
+	}
+	if _, err := NewRouter([]Rule{{When: `issue.priority = "high"`}}, "C1", ""); err == nil {
+		t.Error("Expected error for invalid condition")
+	}
+	if _, err := NewRouter([]Rule{{Escalation: Escalation{DM: "octocat"}}}, "C1", ""); err == nil {
+		t.Error("Expected error for an escalation DM that is not a Slack user ID")
+	}
+	if _, err := NewRouter([]Rule{{Escalation: Escalation{AfterHours: -1}}}, "C1", ""); err == nil {
+		t.Error("Expected error for negative escalation hours")
+	}
+
+	router, err := NewRouter(nil, "C1", "")
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	if got := router.Route(Issue{}); got.Layout != LayoutDetailed {
+		t.Errorf("Expected detailed layout by default, got %q", got.Layout)
+	}
+}
+
+func TestRouterChannels(t *testing.T) {
+	router, err := NewRouter([]Rule{
+		{Name: "security", Channel: "C-SEC"},
+		{Name: "compact", Layout: LayoutCompact},
+		{Name: "more-security", Channel: "C-SEC"},
+		{Name: "bugs", Channel: "C-BUGS"},
+	}, "C-DEFAULT", LayoutDetailed)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+
+	channels := router.Channels()
+	want := []string{"C-DEFAULT", "C-SEC", "C-BUGS"}
+	if len(channels) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, channels)
+	}
+	for i := range want {
+		if channels[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, channels)
+		}
+	}
+}
+
+func TestRouterEscalation(t *testing.T) {
+	router, err := NewRouter([]Rule{
+		{Name: "payments", Escalation: Escalation{AfterHours: 2, Channel: "C-PAY-LEADS", DM: "U0OWNER"}},
+		{Name: "bugs"},
+	}, "C-DEFAULT", LayoutDetailed)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	if got := router.Escalation("payments"); got.AfterHours != 2 || got.DM != "U0OWNER" {
+		t.Errorf("Expected the rule's escalation, got %+v", got)
+	}
+	if got := router.Escalation("default"); got != (Escalation{}) {
+		t.Errorf("Expected no overrides for the default route, got %+v", got)
+	}
+}
@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CheckInterval is how often Run checks whether a slot is due
+const CheckInterval = 10 * time.Minute
+
+// Schedule is when a report is posted: weekly on Weekday, or on the 1st of
+// each month without one, at Hour in Timezone
+type Schedule struct {
+	Weekday  string // e.g. monday; empty for monthly reports
+	Hour     int
+	Timezone string // IANA name, defaults to UTC; weeks and months start at midnight in it
+}
+
+// Validate checks the weekday, hour and timezone. name is what is scheduled,
+// e.g. "resolution report", for the errors.
+func (s Schedule) Validate(name string) error {
+	if _, ok := ParseWeekday(s.Weekday); s.Weekday != "" && !ok {
+		return fmt.Errorf("invalid %s weekday %q", name, s.Weekday)
+	}
+	if s.Hour < 0 || s.Hour > 23 {
+		return fmt.Errorf("%s hour must be between 0 and 23, got %d", name, s.Hour)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid %s timezone: %w", name, err)
+	}
+	return nil
+}
+
+// Location returns the schedule's timezone. An empty name is UTC, and
+// Validate has rejected unknown ones.
+func (s Schedule) Location() *time.Location {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Last returns the latest scheduled time at or before now, in the schedule's
+// timezone. Slots are computed on the local calendar, so they stay at the
+// same local hour across daylight saving changes.
+func (s Schedule) Last(now time.Time) time.Time {
+	location := s.Location()
+	now = now.In(location)
+	if s.Weekday == "" {
+		slot := time.Date(now.Year(), now.Month(), 1, s.Hour, 0, 0, 0, location)
+		if slot.After(now) {
+			slot = slot.AddDate(0, -1, 0)
+		}
+		return slot
+	}
+
+	weekday, _ := ParseWeekday(s.Weekday)
+	slot := time.Date(now.Year(), now.Month(), now.Day(), s.Hour, 0, 0, 0, location)
+	slot = slot.AddDate(0, 0, -((int(now.Weekday()) - int(weekday) + 7) % 7))
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -7)
+	}
+	return slot
+}
+
+// Run calls post with each scheduled time as it falls due, until the context
+// is cancelled. A time due before Run started is skipped, so a restart does
+// not post it again.
+func (s Schedule) Run(ctx context.Context, now func() time.Time, post func(due time.Time)) {
+	posted := s.Last(now())
+	Tick(ctx, func() {
+		if due := s.Last(now()); due.After(posted) {
+			post(due)
+			posted = due
+		}
+	})
+}
+
+// Tick calls check every CheckInterval until the context is cancelled, for
+// reporters that follow several schedules
+func Tick(ctx context.Context, check func()) {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// ParseWeekday parses a weekday name in any case, e.g. Monday
+func ParseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
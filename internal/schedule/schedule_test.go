@@ -0,0 +1,52 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLast(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		now      time.Time
+		want     time.Time
+	}{
+		{"after the hour", Schedule{Hour: 9}, time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC), time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		{"before the hour", Schedule{Hour: 9}, time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)},
+		{"mid-month", Schedule{Hour: 9}, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		// 09:00 on the 1st in Los Angeles is 16:00 UTC in daylight saving time
+		{"timezone", Schedule{Hour: 9, Timezone: "America/Los_Angeles"}, time.Date(2026, 10, 1, 15, 0, 0, 0, time.UTC), time.Date(2026, 9, 1, 16, 0, 0, 0, time.UTC)},
+		// March 4, 2024 is a Monday
+		{"weekly", Schedule{Weekday: "Monday", Hour: 9}, time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)},
+		{"weekly before the hour", Schedule{Weekday: "monday", Hour: 9}, time.Date(2024, 3, 4, 8, 59, 0, 0, time.UTC), time.Date(2024, 2, 26, 9, 0, 0, 0, time.UTC)},
+		{"weekly mid-week", Schedule{Weekday: "monday", Hour: 9}, time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)},
+		// Clocks went forward in New York on Sunday March 10, 2024: 09:00 is
+		// 14:00 UTC the week before and 13:00 UTC after
+		{"weekly after DST starts", Schedule{Weekday: "monday", Hour: 9, Timezone: "America/New_York"}, time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC), time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC)},
+		{"weekly before DST starts", Schedule{Weekday: "monday", Hour: 9, Timezone: "America/New_York"}, time.Date(2024, 3, 11, 12, 59, 0, 0, time.UTC), time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)},
+		// And back on Sunday November 3
+		{"weekly after DST ends", Schedule{Weekday: "monday", Hour: 9, Timezone: "America/New_York"}, time.Date(2024, 11, 4, 13, 30, 0, 0, time.UTC), time.Date(2024, 10, 28, 13, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Last(tt.now); !got.Equal(tt.want) {
+				t.Errorf("Last(%v) = %v, want %v", tt.now, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Schedule{Hour: 23, Timezone: "Europe/Berlin"}).Validate("report"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := (Schedule{Weekday: "Friday", Hour: 17}).Validate("report"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, schedule := range []Schedule{{Hour: 24}, {Hour: -1}, {Timezone: "Mars/Olympus"}, {Weekday: "someday"}} {
+		if err := schedule.Validate("report"); err == nil {
+			t.Errorf("Expected %+v to be invalid", schedule)
+		}
+	}
+}
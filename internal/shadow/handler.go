@@ -0,0 +1,73 @@
+package shadow
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github-issue-ai-bot/internal/admin"
+)
+
+// Limits of the actions listed by a preview request
+const (
+	defaultPreviewLimit = 100
+	maxPreviewLimit     = 1000
+)
+
+// Handler serves the held back actions for review. Requests are authorized
+// by the admin token.
+type Handler struct {
+	store      Store
+	adminToken *admin.Token
+}
+
+// NewHandler creates a preview handler
+func NewHandler(store Store, adminToken *admin.Token) *Handler {
+	return &Handler{store: store, adminToken: adminToken}
+}
+
+// ServePreview lists the most recent held back actions, oldest first,
+// filtered by the service, repository, channel and since (RFC 3339)
+// parameters. limit defaults to 100.
+func (h *Handler) ServePreview(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	params := r.URL.Query()
+	query := Query{
+		Service:    params.Get("service"),
+		Repository: params.Get("repository"),
+		Channel:    params.Get("channel"),
+		Limit:      defaultPreviewLimit,
+	}
+	switch query.Service {
+	case "", ServiceSlack, ServiceGitHub:
+	default:
+		http.Error(w, "service must be slack or github", http.StatusBadRequest)
+		return
+	}
+	if value := params.Get("since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		query.Since = since
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxPreviewLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxPreviewLimit), http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+
+	actions := h.store.ListShadowActions(query)
+	if actions == nil {
+		actions = []Action{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"actions": actions})
+}
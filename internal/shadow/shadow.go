@@ -0,0 +1,263 @@
+// Package shadow records what the bot would send to Slack and GitHub
+// instead of sending it, so a deployment can be evaluated on live traffic
+// before it posts anything
+package shadow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+// Services an action was meant for
+const (
+	ServiceSlack  = "slack"
+	ServiceGitHub = "github"
+)
+
+// maxBodyBytes bounds the request body kept for an action
+const maxBodyBytes = 256 << 10
+
+// Action is a request to Slack or GitHub that shadow mode held back
+type Action struct {
+	ID         int64           `json:"id"`
+	Time       time.Time       `json:"time"`
+	Service    string          `json:"service"`
+	Method     string          `json:"method"`                // Slack API method, e.g. chat.postMessage, or HTTP method for GitHub
+	Path       string          `json:"path,omitempty"`        // GitHub API path
+	Repository string          `json:"repository,omitempty"`  // owner/name of GitHub actions
+	Channel    string          `json:"channel,omitempty"`     // Slack channel of Slack actions
+	Request    json.RawMessage `json:"request,omitempty"`     // Parameters or body, without the token
+	PreviewURL string          `json:"preview_url,omitempty"` // Block Kit Builder link for Slack messages with blocks
+}
+
+// Query selects actions to list. Empty fields match everything.
+type Query struct {
+	Service    string
+	Repository string // Case-insensitive
+	Channel    string
+	Since      time.Time
+	Limit      int // Most recent actions to return, zero or less for every match
+}
+
+// Matches reports whether an action is selected by the query
+func (q Query) Matches(action Action) bool {
+	return (q.Service == "" || q.Service == action.Service) &&
+		(q.Repository == "" || strings.EqualFold(q.Repository, action.Repository)) &&
+		(q.Channel == "" || q.Channel == action.Channel) &&
+		(q.Since.IsZero() || !action.Time.Before(q.Since))
+}
+
+// Store keeps the held back actions
+type Store interface {
+	AppendShadowAction(action Action)
+	ListShadowActions(query Query) []Action
+}
+
+// Recorder holds back the Slack and GitHub requests that would change
+// something, answering them as the APIs would on success, and keeps them in
+// the store. Reads still reach the APIs, so the pipeline sees real issues,
+// users and channels.
+type Recorder struct {
+	store  Store
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	nextID int64
+}
+
+// NewRecorder creates a recorder keeping actions in store
+func NewRecorder(store Store, logger *zap.Logger) *Recorder {
+	return &Recorder{store: store, logger: logger, now: time.Now}
+}
+
+// SlackTransport returns a transport for Slack Web API requests that sends
+// reads through base, http.DefaultTransport if nil, and holds back the rest
+func (r *Recorder) SlackTransport(base http.RoundTripper) http.RoundTripper {
+	return &transport{recorder: r, base: base, service: ServiceSlack}
+}
+
+// GitHubTransport returns a transport for GitHub API requests that sends
+// reads through base, http.DefaultTransport if nil, and holds back the rest
+func (r *Recorder) GitHubTransport(base http.RoundTripper) http.RoundTripper {
+	return &transport{recorder: r, base: base, service: ServiceGitHub}
+}
+
+type transport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+	service  string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	write := githubWrite(req)
+	if t.service == ServiceSlack {
+		write = slackWrite(path.Base(req.URL.Path))
+	}
+	if !write {
+		base := t.base
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		return base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(req.Body, maxBodyBytes))
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shadowed request: %w", err)
+		}
+	}
+	if t.service == ServiceSlack {
+		return t.recorder.holdSlack(req, body), nil
+	}
+	return t.recorder.holdGitHub(req, body), nil
+}
+
+// slackWrite reports whether a Slack API method changes anything. Methods
+// are writes unless known to only read, so new ones are held back.
+func slackWrite(method string) bool {
+	if method == "auth.test" {
+		return false
+	}
+	_, verb, _ := strings.Cut(method, ".")
+	switch verb {
+	case "info", "list", "history", "replies", "members":
+		return false
+	}
+	return !strings.HasPrefix(verb, "get") && !strings.HasPrefix(verb, "lookup")
+}
+
+// githubWrite reports whether a GitHub API request changes anything
+func githubWrite(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func (r *Recorder) record(action Action) Action {
+	r.mu.Lock()
+	r.nextID++
+	action.ID = r.nextID
+	r.mu.Unlock()
+	action.Time = r.now().UTC()
+
+	r.store.AppendShadowAction(action)
+	r.logger.Debug("Held back action in shadow mode",
+		zap.String("service", action.Service),
+		zap.String("method", action.Method),
+		zap.String("path", action.Path),
+		zap.String("channel", action.Channel))
+	return action
+}
+
+// holdSlack records a Slack API call and answers it like Slack would
+func (r *Recorder) holdSlack(req *http.Request, body []byte) *http.Response {
+	method := path.Base(req.URL.Path)
+	params := slackParams(req.Header.Get("Content-Type"), body)
+	channel, _ := params["channel"].(string)
+
+	action := Action{Service: ServiceSlack, Method: method, Channel: channel}
+	if encoded, err := json.Marshal(params); err == nil {
+		action.Request = encoded
+	}
+	if blocks, ok := params["blocks"]; ok {
+		if encoded, err := json.Marshal(map[string]interface{}{"blocks": blocks}); err == nil {
+			action.PreviewURL = "https://app.slack.com/block-kit-builder#" + url.PathEscape(string(encoded))
+		}
+	}
+	action = r.record(action)
+
+	ts, _ := params["ts"].(string)
+	if ts == "" {
+		ts = fmt.Sprintf("%d.%06d", action.Time.Unix(), action.ID%1_000_000)
+	}
+	answer := map[string]interface{}{"ok": true, "channel": channel, "ts": ts, "message_ts": ts}
+	if strings.HasPrefix(method, "conversations.") {
+		// Conversation methods answer with the channel object
+		if channel == "" {
+			channel = fmt.Sprintf("CSHADOW%d", action.ID)
+		}
+		name, _ := params["name"].(string)
+		answer = map[string]interface{}{"ok": true, "channel": map[string]interface{}{"id": channel, "name": name}}
+	}
+	return jsonResponse(req, http.StatusOK, answer)
+}
+
+// slackParams decodes the parameters of a Slack API call, form-encoded or
+// JSON. Parameters holding JSON, such as blocks, are decoded too. The token
+// is left out.
+func slackParams(contentType string, body []byte) map[string]interface{} {
+	params := make(map[string]interface{})
+	if strings.HasPrefix(contentType, "application/json") {
+		json.Unmarshal(body, &params)
+		return params
+	}
+	values, _ := url.ParseQuery(string(body))
+	for key := range values {
+		if key == "token" {
+			continue
+		}
+		value := values.Get(key)
+		var decoded interface{}
+		if trimmed := strings.TrimSpace(value); (strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) &&
+			json.Unmarshal([]byte(trimmed), &decoded) == nil {
+			params[key] = decoded
+			continue
+		}
+		params[key] = value
+	}
+	return params
+}
+
+// holdGitHub records a GitHub API call and answers it with an empty success
+func (r *Recorder) holdGitHub(req *http.Request, body []byte) *http.Response {
+	action := Action{Service: ServiceGitHub, Method: req.Method, Path: req.URL.Path, Repository: github.APIRepository(req.URL.Path)}
+	if json.Valid(body) {
+		action.Request = body
+	}
+	r.record(action)
+
+	switch req.Method {
+	case http.MethodDelete:
+		return jsonResponse(req, http.StatusNoContent, nil)
+	case http.MethodPost:
+		return jsonResponse(req, http.StatusCreated, map[string]interface{}{})
+	}
+	return jsonResponse(req, http.StatusOK, map[string]interface{}{})
+}
+
+func jsonResponse(req *http.Request, status int, body interface{}) *http.Response {
+	var encoded []byte
+	if body != nil {
+		encoded, _ = json.Marshal(body)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(encoded)),
+		ContentLength: int64(len(encoded)),
+		Request:       req,
+	}
+}
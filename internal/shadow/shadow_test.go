@@ -0,0 +1,229 @@
+package shadow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+)
+
+// memoryStore keeps actions in order, like store.MemoryStore
+type memoryStore struct {
+	mu      sync.Mutex
+	actions []Action
+}
+
+func (s *memoryStore) AppendShadowAction(action Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = append(s.actions, action)
+}
+
+func (s *memoryStore) ListShadowActions(query Query) []Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var actions []Action
+	for _, action := range s.actions {
+		if query.Matches(action) {
+			actions = append(actions, action)
+		}
+	}
+	if query.Limit > 0 && len(actions) > query.Limit {
+		actions = actions[len(actions)-query.Limit:]
+	}
+	return actions
+}
+
+// apiServer records the paths it is called with
+func apiServer(t *testing.T, respond func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		respond(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestSlackTransport(t *testing.T) {
+	server, calls := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"user":{"id":"U1","name":"octocat"}}`))
+	})
+	store := &memoryStore{}
+	recorder := NewRecorder(store, zap.NewNop())
+	client := slack.New("xoxb-test",
+		slack.OptionAPIURL(server.URL+"/"),
+		slack.OptionHTTPClient(&http.Client{Transport: recorder.SlackTransport(nil)}))
+	ctx := context.Background()
+
+	user, err := client.GetUserInfoContext(ctx, "U1")
+	if err != nil || user.Name != "octocat" {
+		t.Fatalf("Expected reads to reach Slack, got %+v, %v", user, err)
+	}
+
+	blocks := slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", "*Crash on start*", false, false), nil, nil)
+	channel, ts, err := client.PostMessageContext(ctx, "C123", slack.MsgOptionBlocks(blocks), slack.MsgOptionText("Crash on start", false))
+	if err != nil || channel != "C123" || ts == "" {
+		t.Fatalf("Expected a posted message, got %q, %q, %v", channel, ts, err)
+	}
+	if _, _, _, err := client.UpdateMessageContext(ctx, channel, ts, slack.MsgOptionText("Crash on start (updated)", false)); err != nil {
+		t.Fatalf("Expected the update to succeed, got %v", err)
+	}
+	created, err := client.CreateConversationContext(ctx, slack.CreateConversationParams{ChannelName: "incident-7"})
+	if err != nil || created.ID == "" || created.Name != "incident-7" {
+		t.Fatalf("Expected a created channel, got %+v, %v", created, err)
+	}
+
+	if len(*calls) != 1 {
+		t.Errorf("Expected only the read to reach Slack, got %v", *calls)
+	}
+	actions := store.ListShadowActions(Query{Service: ServiceSlack})
+	if len(actions) != 3 {
+		t.Fatalf("Expected 3 held back actions, got %+v", actions)
+	}
+	post := actions[0]
+	if post.Method != "chat.postMessage" || post.Channel != "C123" || post.ID != 1 {
+		t.Errorf("Expected the message post, got %+v", post)
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(post.Request, &params); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := params["token"]; ok {
+		t.Error("Expected the token left out")
+	}
+	if _, ok := params["blocks"].([]interface{}); !ok {
+		t.Errorf("Expected the blocks decoded, got %v", params["blocks"])
+	}
+	if !strings.HasPrefix(post.PreviewURL, "https://app.slack.com/block-kit-builder#") {
+		t.Errorf("Expected a Block Kit Builder link, got %q", post.PreviewURL)
+	}
+	if actions[1].Method != "chat.update" || !strings.Contains(string(actions[1].Request), ts) {
+		t.Errorf("Expected the update of the posted message, got %+v", actions[1])
+	}
+}
+
+func TestGitHubTransport(t *testing.T) {
+	server, calls := apiServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"number":7,"title":"Crash on start"}`))
+	})
+	store := &memoryStore{}
+	recorder := NewRecorder(store, zap.NewNop())
+	client := gogithub.NewClient(&http.Client{Transport: recorder.GitHubTransport(nil)})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	ctx := context.Background()
+
+	issue, _, err := client.Issues.Get(ctx, "owner", "repo", 7)
+	if err != nil || issue.GetTitle() != "Crash on start" {
+		t.Fatalf("Expected reads to reach GitHub, got %+v, %v", issue, err)
+	}
+	if _, _, err := client.Issues.CreateComment(ctx, "owner", "repo", 7, &gogithub.IssueComment{Body: gogithub.String("Thanks!")}); err != nil {
+		t.Fatalf("Expected the comment to succeed, got %v", err)
+	}
+	if _, err := client.Issues.RemoveLabelForIssue(ctx, "owner", "repo", 7, "triage"); err != nil {
+		t.Fatalf("Expected the label removal to succeed, got %v", err)
+	}
+
+	if len(*calls) != 1 {
+		t.Errorf("Expected only the read to reach GitHub, got %v", *calls)
+	}
+	actions := store.ListShadowActions(Query{Repository: "Owner/Repo"})
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 held back actions, got %+v", actions)
+	}
+	comment := actions[0]
+	if comment.Method != http.MethodPost || comment.Path != "/repos/owner/repo/issues/7/comments" || !strings.Contains(string(comment.Request), "Thanks!") {
+		t.Errorf("Expected the comment, got %+v", comment)
+	}
+	if actions[1].Method != http.MethodDelete {
+		t.Errorf("Expected the label removal, got %+v", actions[1])
+	}
+}
+
+func TestSlackWrite(t *testing.T) {
+	tests := map[string]bool{
+		"chat.postMessage":     true,
+		"chat.update":          true,
+		"reactions.add":        true,
+		"views.open":           true,
+		"conversations.invite": true,
+		"auth.test":            false,
+		"users.info":           false,
+		"users.lookupByEmail":  false,
+		"conversations.info":   false,
+		"conversations.list":   false,
+		"reactions.get":        false,
+	}
+	for method, want := range tests {
+		if got := slackWrite(method); got != want {
+			t.Errorf("slackWrite(%q) = %v, want %v", method, got, want)
+		}
+	}
+}
+
+func TestServePreview(t *testing.T) {
+	store := &memoryStore{}
+	store.AppendShadowAction(Action{ID: 1, Service: ServiceSlack, Method: "chat.postMessage", Channel: "C123"})
+	store.AppendShadowAction(Action{ID: 2, Service: ServiceGitHub, Method: http.MethodPost, Repository: "owner/repo"})
+	handler := NewHandler(store, admin.NewToken("secret"))
+
+	tests := []struct {
+		name   string
+		query  string
+		token  string
+		status int
+		ids    []int64
+	}{
+		{"unauthorized", "", "", http.StatusUnauthorized, nil},
+		{"all actions", "", "secret", http.StatusOK, []int64{1, 2}},
+		{"by service", "?service=github", "secret", http.StatusOK, []int64{2}},
+		{"by channel", "?channel=C123", "secret", http.StatusOK, []int64{1}},
+		{"most recent", "?limit=1", "secret", http.StatusOK, []int64{2}},
+		{"unknown service", "?service=email", "secret", http.StatusBadRequest, nil},
+		{"invalid limit", "?limit=0", "secret", http.StatusBadRequest, nil},
+		{"invalid since", "?since=yesterday", "secret", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/shadow/actions"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			handler.ServePreview(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var body struct {
+				Actions []Action `json:"actions"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			var ids []int64
+			for _, action := range body.Actions {
+				ids = append(ids, action.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("Expected actions %v, got %v", tt.ids, ids)
+			}
+		})
+	}
+}
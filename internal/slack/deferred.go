@@ -0,0 +1,49 @@
+package slack
+
+import (
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// workingMessages are shown, only to the user who clicked, while an action
+// that calls GitHub or OpenAI runs. Its result is posted in the issue thread.
+var workingMessages = map[string]string{
+	"suggest_fix":   ":hourglass_flowing_sand: Working on a fix suggestion… it will be posted in the thread.",
+	"deep_analysis": ":hourglass_flowing_sand: Running the deep analysis… the summary will be updated when it is done.",
+	"close_issue":   ":hourglass_flowing_sand: Checking the issue before closing it…",
+	"retry_summary": ":hourglass_flowing_sand: Retrying the AI summary… the message will be updated when it is done.",
+}
+
+// deferAction runs an action in the background. Slack shows "operation timed
+// out" unless an interaction is acknowledged within three seconds, which
+// GitHub and OpenAI calls regularly exceed, so the caller acknowledges at once
+// and the user is told through the interaction's response URL that the
+// action is underway.
+func (n *Notifier) deferAction(callback slack.InteractionCallback, actionID string, run func()) {
+	go func() {
+		if text, ok := workingMessages[actionID]; ok {
+			n.respondEphemeral(callback, text)
+		}
+		run()
+	}()
+}
+
+// respondEphemeral posts a message only the user who clicked sees, through
+// the interaction's response URL. The original message is left as it is.
+func (n *Notifier) respondEphemeral(callback slack.InteractionCallback, text string) {
+	if callback.ResponseURL == "" {
+		return
+	}
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if err := slack.PostWebhookContext(slackCtx, callback.ResponseURL, &slack.WebhookMessage{
+		Text:         text,
+		ResponseType: slack.ResponseTypeEphemeral,
+	}); err != nil {
+		n.logger.Warn("Failed to respond to Slack interaction", zap.Error(err))
+		n.metrics.RecordSlackError("response_url", apperrors.Classify(classifyError(err)))
+	}
+}
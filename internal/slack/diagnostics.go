@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/slack-go/slack"
+
+	"github-issue-ai-bot/internal/diagnostics"
+)
+
+// RequiredScopes returns the bot token scopes the enabled features need
+func RequiredScopes(incidentChannels bool) []string {
+	scopes := []string{"chat:write", "channels:read"}
+	if incidentChannels {
+		scopes = append(scopes, "channels:manage")
+	}
+	return scopes
+}
+
+// scopeRecorder remembers the scopes Slack lists in the X-OAuth-Scopes
+// header, which slack-go does not expose
+type scopeRecorder struct {
+	mu     sync.Mutex
+	next   http.RoundTripper
+	scopes string
+}
+
+func (r *scopeRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err == nil {
+		if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" {
+			r.mu.Lock()
+			r.scopes = scopes
+			r.mu.Unlock()
+		}
+	}
+	return resp, err
+}
+
+// grantedScopes returns the scopes seen so far and whether Slack reported any
+func (r *scopeRecorder) grantedScopes() ([]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.scopes == "" {
+		return nil, false
+	}
+	var scopes []string
+	for _, scope := range strings.Split(r.scopes, ",") {
+		scopes = append(scopes, strings.TrimSpace(scope))
+	}
+	return scopes, true
+}
+
+// Diagnose checks that the bot token is valid and has the required scopes,
+// and that the bot can post to each channel, so misconfiguration is reported
+// before the first message fails
+func (n *Notifier) Diagnose(ctx context.Context, channels, scopes []string) []diagnostics.Check {
+	n.mu.RLock()
+	token, apiURL := n.botToken, n.apiURL
+	n.mu.RUnlock()
+
+	recorder := &scopeRecorder{next: http.DefaultTransport}
+	client := slack.New(token, slack.OptionAPIURL(apiURL), slack.OptionHTTPClient(&http.Client{Transport: recorder}))
+
+	auth, err := client.AuthTestContext(ctx)
+	if err != nil {
+		return []diagnostics.Check{diagnostics.Error("slack_auth",
+			fmt.Sprintf("Slack rejected SLACK_BOT_TOKEN (%v); use the Bot User OAuth Token (xoxb-) of an installed app", err))}
+	}
+	checks := []diagnostics.Check{diagnostics.OK("slack_auth", fmt.Sprintf("Authenticated as @%s in %s", auth.User, auth.Team))}
+
+	if granted, ok := recorder.grantedScopes(); !ok {
+		checks = append(checks, diagnostics.Warning("slack_scopes", "Slack did not report the bot token's scopes"))
+	} else if missing := diagnostics.MissingScopes(granted, scopes); len(missing) > 0 {
+		checks = append(checks, diagnostics.Error("slack_scopes",
+			fmt.Sprintf("The bot token lacks %s; add the scopes under OAuth & Permissions and reinstall the app", strings.Join(missing, ", "))))
+	} else {
+		checks = append(checks, diagnostics.OK("slack_scopes", "The bot token has "+strings.Join(scopes, ", ")))
+	}
+
+	for _, channel := range channels {
+		checks = append(checks, diagnoseChannel(ctx, client, channel, auth.User))
+	}
+	return checks
+}
+
+// DiagnoseChannel checks that the bot can post to channel
+func (n *Notifier) DiagnoseChannel(ctx context.Context, channel string) diagnostics.Check {
+	client := n.slackClient()
+	auth, err := client.AuthTestContext(ctx)
+	if err != nil {
+		return diagnostics.Error("slack_channel:"+channel, fmt.Sprintf("Slack rejected SLACK_BOT_TOKEN (%v)", err))
+	}
+	return diagnoseChannel(ctx, client, channel, auth.User)
+}
+
+// diagnoseChannel checks that the bot is a member of an active channel
+func diagnoseChannel(ctx context.Context, client *slack.Client, channel, botName string) diagnostics.Check {
+	name := "slack_channel:" + channel
+	info, err := client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channel})
+	switch {
+	case err != nil && err.Error() == "channel_not_found":
+		return diagnostics.Error(name, fmt.Sprintf("Channel %s was not found; check the channel ID, and invite @%s if the channel is private", channel, botName))
+	case err != nil && err.Error() == "missing_scope":
+		return diagnostics.Warning(name, fmt.Sprintf("Cannot check channel %s without the channels:read scope (groups:read for private channels)", channel))
+	case err != nil:
+		return diagnostics.Error(name, fmt.Sprintf("Could not look up channel %s: %v", channel, err))
+	case info.IsArchived:
+		return diagnostics.Error(name, fmt.Sprintf("#%s is archived; unarchive it or route its issues to another channel", info.Name))
+	case !info.IsMember:
+		return diagnostics.Error(name, fmt.Sprintf("Bot not invited to #%s; run /invite @%s in the channel", info.Name, botName))
+	}
+	return diagnostics.OK(name, fmt.Sprintf("Bot is a member of #%s", info.Name))
+}
@@ -0,0 +1,295 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/internal/teams"
+)
+
+// Action IDs of a digest's triage menus and bulk acknowledge button
+const (
+	digestIssueAction  = "digest_issue"
+	digestAckLowAction = "digest_ack_low"
+)
+
+// Options of a digest issue's triage menu. Option values are the option and
+// the issue, e.g. snooze_day|acme/api:12.
+const (
+	digestAssign     = "assign"
+	digestSnoozeDay  = "snooze_day"
+	digestSnoozeWeek = "snooze_week"
+	digestEscalate   = "escalate"
+	digestOpen       = "open"
+)
+
+// Slack limits of a digest's blocks
+const (
+	maxDigestIssues = 20   // Blocks per message are capped at 50
+	maxOptionValue  = 75   // Characters in an overflow option's value
+	maxButtonValue  = 2000 // Characters in a button's value
+	maxDigestTitle  = 120  // Characters of an issue title shown in a digest
+)
+
+// Triage acts on the issues listed in team digests
+type Triage interface {
+	AcknowledgeIssue(repository string, number int, userID string) bool
+	SnoozeIssue(repository string, number int, until time.Time, userID string) bool
+	EscalateIssue(ctx context.Context, repository string, number int, userID string) (string, error)
+}
+
+// SetTriage handles the triage menus and the bulk acknowledge button of team
+// digests
+func (n *Notifier) SetTriage(triage Triage) {
+	n.triage = triage
+}
+
+// PostDigest posts a team's digest: its rollup, then each issue awaiting
+// triage with a menu to assign, snooze, escalate or open it, and a button to
+// acknowledge every low priority one at once
+func (n *Notifier) PostDigest(ctx context.Context, channelID string, digest teams.Digest) error {
+	start := time.Now()
+	_, _, err := n.slackClient().PostMessageContext(
+		ctx,
+		channelID,
+		slack.MsgOptionText(digest.Text, false),
+		slack.MsgOptionBlocks(digestBlocks(digest, time.Now())...),
+	)
+
+	duration := time.Since(start)
+
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackMessage(channelID, "digest", "error", duration)
+		n.metrics.RecordSlackError("send_message", apperrors.Classify(err))
+		return fmt.Errorf("failed to post digest: %w", err)
+	}
+
+	n.metrics.RecordSlackMessage(channelID, "digest", "success", duration)
+	return nil
+}
+
+// digestBlocks lays out a digest. Only the most urgent issues are listed,
+// but the bulk button acknowledges every low priority issue that fits in its
+// value.
+func digestBlocks(digest teams.Digest, now time.Time) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, digest.Text, false, false), nil, nil),
+	}
+	if len(digest.Triage) == 0 {
+		return blocks
+	}
+
+	blocks = append(blocks, slack.NewDividerBlock(), slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+		fmt.Sprintf("*Awaiting triage* (%d)", len(digest.Triage)), false, false), nil, nil))
+	for i, issue := range digest.Triage {
+		if i == maxDigestIssues {
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("…and %d more", len(digest.Triage)-maxDigestIssues), false, false)))
+			break
+		}
+		blocks = append(blocks, digestIssueBlock(issue, now))
+	}
+
+	var low []string
+	length := 0
+	for _, issue := range digest.Triage {
+		if issue.Priority != "low" {
+			continue
+		}
+		ref := fmt.Sprintf("%s:%d", issue.Repository, issue.Number)
+		if length+len(ref)+1 > maxButtonValue {
+			break
+		}
+		low = append(low, ref)
+		length += len(ref) + 1
+	}
+	if len(low) > 0 {
+		label := fmt.Sprintf("Acknowledge all low priority (%d)", len(low))
+		button := slack.NewButtonBlockElement(digestAckLowAction, strings.Join(low, ","), plainText(label)).
+			WithConfirm(slack.NewConfirmationBlockObject(
+				plainText("Acknowledge low priority issues?"),
+				plainText(fmt.Sprintf("%d low priority issues will be marked as triaged and left out of later digests.", len(low))),
+				plainText("Acknowledge"),
+				plainText("Cancel")))
+		blocks = append(blocks, slack.NewActionBlock("digest_actions", button))
+	}
+	return blocks
+}
+
+// digestIssueBlock lists an issue with its triage menu. Issues whose name
+// is too long for a menu option are listed without one.
+func digestIssueBlock(issue teams.TriageIssue, now time.Time) slack.Block {
+	url := fmt.Sprintf("https://github.com/%s/issues/%d", issue.Repository, issue.Number)
+	priority := issue.Priority
+	if priority == "" {
+		priority = "unknown"
+	}
+	text := fmt.Sprintf("*<%s|%s#%d>* %s\n_%s priority, opened %s ago_", url, issue.Repository, issue.Number,
+		truncateRunes(issue.Title, maxDigestTitle), strings.Title(priority), github.FormatAge(now.Sub(issue.OpenedAt)))
+	section := slack.NewTextBlockObject(slack.MarkdownType, text, false, false)
+
+	ref := fmt.Sprintf("%s:%d", issue.Repository, issue.Number)
+	if len(digestSnoozeWeek)+1+len(ref) > maxOptionValue {
+		return slack.NewSectionBlock(section, nil, nil)
+	}
+	option := func(kind, label string) *slack.OptionBlockObject {
+		return slack.NewOptionBlockObject(kind+"|"+ref, plainText(label), nil)
+	}
+	open := option(digestOpen, "Open on GitHub")
+	open.URL = url
+	menu := slack.NewOverflowBlockElement(digestIssueAction,
+		option(digestAssign, "Assign to me"),
+		option(digestSnoozeDay, "Snooze for a day"),
+		option(digestSnoozeWeek, "Snooze for a week"),
+		option(digestEscalate, "Escalate"),
+		open,
+	)
+	return slack.NewSectionBlock(section, nil, slack.NewAccessory(menu))
+}
+
+// parseIssueRef reads an issue from a digest value, e.g. acme/api:12
+func parseIssueRef(value string) (string, int, error) {
+	// Value format: repoName:issueNumber
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid issue %q", value)
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("invalid issue number in %q", value)
+	}
+	return parts[0], number, nil
+}
+
+// handleDigestIssue runs the option picked in a digest issue's triage menu.
+// Every option but opening the issue, which Slack does itself, runs in the
+// background and is written to the audit log.
+func (n *Notifier) handleDigestIssue(callback slack.InteractionCallback, action *slack.BlockAction) {
+	kind, ref, _ := strings.Cut(action.SelectedOption.Value, "|")
+	if kind == digestOpen {
+		return
+	}
+	repo, number, err := parseIssueRef(ref)
+	if err != nil {
+		n.logger.Error("Failed to parse digest option", zap.String("value", action.SelectedOption.Value), zap.Error(err))
+		return
+	}
+
+	n.deferAction(callback, digestIssueAction, func() {
+		switch kind {
+		case digestAssign:
+			if n.handleAssignIssue(callback, ref) {
+				n.triage.AcknowledgeIssue(repo, number, callback.User.ID)
+				n.auditDigestAction(callback, kind, repo, number, auditDone)
+			} else {
+				n.auditDigestAction(callback, kind, repo, number, auditFailed)
+			}
+		case digestSnoozeDay, digestSnoozeWeek:
+			days := 1
+			if kind == digestSnoozeWeek {
+				days = 7
+			}
+			until := time.Now().AddDate(0, 0, days)
+			if !n.triage.SnoozeIssue(repo, number, until, callback.User.ID) {
+				n.auditDigestAction(callback, kind, repo, number, auditFailed)
+				n.respondEphemeral(callback, fmt.Sprintf(":information_source: %s#%d is no longer open.", repo, number))
+				return
+			}
+			n.auditDigestAction(callback, kind, repo, number, auditDone)
+			n.respondEphemeral(callback, fmt.Sprintf(":zzz: Snoozed %s#%d until %s; it is left out of digests and escalations until then.",
+				repo, number, until.UTC().Format("Mon Jan 2 15:04 MST")))
+		case digestEscalate:
+			n.escalateFromDigest(callback, repo, number)
+		default:
+			n.logger.Info("Unhandled digest option", zap.String("option", kind))
+		}
+	})
+}
+
+// escalateFromDigest raises an issue to the highest priority, for users
+// allowed to relabel its repository, and says so in the digest's thread
+func (n *Notifier) escalateFromDigest(callback slack.InteractionCallback, repo string, number int) {
+	ctx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, ok := n.authorizeAction(ctx, callback, callback.Channel.ID, "escalate", repo); !ok {
+		n.auditDigestAction(callback, digestEscalate, repo, number, auditDenied)
+		return
+	}
+
+	priority, err := n.triage.EscalateIssue(n.baseCtx, repo, number, callback.User.ID)
+	if err != nil {
+		n.logger.Error("Failed to escalate issue from digest",
+			zap.String("repository", repo),
+			zap.Int("issue_number", number),
+			zap.Error(err))
+		n.auditDigestAction(callback, digestEscalate, repo, number, auditFailed)
+		n.respondEphemeral(callback, fmt.Sprintf(":warning: Could not escalate %s#%d: %v", repo, number, err))
+		return
+	}
+	n.auditDigestAction(callback, digestEscalate, repo, number, auditDone)
+	n.replyInDigest(callback, fmt.Sprintf(":arrow_double_up: <@%s> escalated %s#%d to %s priority.", callback.User.ID, repo, number, priority))
+}
+
+// handleDigestAckLow acknowledges every low priority issue the digest's
+// bulk button lists, with an audit entry per issue
+func (n *Notifier) handleDigestAckLow(callback slack.InteractionCallback, value string) {
+	n.deferAction(callback, digestAckLowAction, func() {
+		acknowledged := 0
+		for _, ref := range strings.Split(value, ",") {
+			repo, number, err := parseIssueRef(ref)
+			if err != nil {
+				n.logger.Error("Failed to parse digest issue", zap.String("value", ref), zap.Error(err))
+				continue
+			}
+			if !n.triage.AcknowledgeIssue(repo, number, callback.User.ID) {
+				n.auditDigestAction(callback, "acknowledge", repo, number, auditSkipped)
+				continue
+			}
+			n.auditDigestAction(callback, "acknowledge", repo, number, auditDone)
+			acknowledged++
+		}
+		if acknowledged == 0 {
+			n.respondEphemeral(callback, ":information_source: The low priority issues were already acknowledged or closed.")
+			return
+		}
+		n.replyInDigest(callback, fmt.Sprintf(":white_check_mark: <@%s> acknowledged %d low priority issues.", callback.User.ID, acknowledged))
+	})
+}
+
+// replyInDigest posts the outcome of a triage action in the digest's thread
+func (n *Notifier) replyInDigest(callback slack.InteractionCallback, text string) {
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if err := n.PostThreadReply(slackCtx, callback.Channel.ID, callback.Message.Timestamp, text); err != nil {
+		n.logger.Error("Failed to post digest reply", zap.Error(err))
+	}
+}
+
+// Outcomes of a digest action in the audit log
+const (
+	auditDone    = "done"
+	auditFailed  = "failed"
+	auditSkipped = "skipped"
+)
+
+// auditDigestAction records who triaged which issue from a digest, and how
+func (n *Notifier) auditDigestAction(callback slack.InteractionCallback, action, repo string, number int, outcome string) {
+	n.logger.Info("Digest triage action",
+		zap.String("audit", "digest_action"),
+		zap.String("action", action),
+		zap.String("repository", repo),
+		zap.Int("issue_number", number),
+		zap.String("slack_user_id", callback.User.ID),
+		zap.String("slack_user_name", callback.User.Name),
+		zap.String("outcome", outcome))
+}
@@ -0,0 +1,93 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// EngagementScopes are the bot token scopes reading reactions and thread
+// replies needs
+var EngagementScopes = []string{"reactions:read", "channels:history"}
+
+// EngagementRecorder notes button clicks on posted messages
+type EngagementRecorder interface {
+	RecordEngagement(channelID, ts, userID string)
+}
+
+// SetEngagement records every button click on a posted message as
+// engagement with it
+func (n *Notifier) SetEngagement(recorder EngagementRecorder) {
+	n.engagement = recorder
+}
+
+// recordEngagement notes the click of a button on a message
+func (n *Notifier) recordEngagement(callback slack.InteractionCallback) {
+	if n.engagement == nil || callback.Channel.ID == "" || callback.Message.Timestamp == "" {
+		return
+	}
+	n.engagement.RecordEngagement(callback.Channel.ID, callback.Message.Timestamp, callback.User.ID)
+}
+
+// MessageEngaged reports whether anyone but a bot reacted to a posted
+// message or replied in its thread. Needs the EngagementScopes.
+func (n *Notifier) MessageEngaged(ctx context.Context, channelID, ts string) (bool, error) {
+	client := n.slackClient()
+	botUser, err := n.botUserID(ctx, client)
+	if err != nil {
+		return false, err
+	}
+
+	reactions, err := client.GetReactionsContext(ctx, slack.NewRefToMessage(channelID, ts), slack.GetReactionsParameters{Full: true})
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackError("get_reactions", apperrors.Classify(err))
+		return false, fmt.Errorf("failed to read reactions: %w", err)
+	}
+	for _, reaction := range reactions {
+		for _, user := range reaction.Users {
+			if user != botUser {
+				return true, nil
+			}
+		}
+	}
+
+	replies, _, _, err := client.GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: ts})
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackError("get_replies", apperrors.Classify(err))
+		return false, fmt.Errorf("failed to read thread replies: %w", err)
+	}
+	for _, reply := range replies {
+		// The first message is the parent itself
+		if reply.Timestamp != ts && reply.BotID == "" && reply.User != "" && reply.User != botUser {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// botUserID returns the bot's own user ID, whose reactions and replies are
+// not engagement
+func (n *Notifier) botUserID(ctx context.Context, client *slack.Client) (string, error) {
+	n.mu.RLock()
+	botUser := n.botUser
+	n.mu.RUnlock()
+	if botUser != "" {
+		return botUser, nil
+	}
+
+	auth, err := client.AuthTestContext(ctx)
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackError("auth_test", apperrors.Classify(err))
+		return "", fmt.Errorf("failed to look up the bot user: %w", err)
+	}
+	n.mu.Lock()
+	n.botUser = auth.UserID
+	n.mu.Unlock()
+	return auth.UserID, nil
+}
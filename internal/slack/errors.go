@@ -0,0 +1,47 @@
+package slack
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/slack-go/slack"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// classifyError maps slack-go errors onto the shared error classes
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var rateErr *slack.RateLimitedError
+	if errors.As(err, &rateErr) {
+		return apperrors.WrapRetryAfter(apperrors.ErrRateLimited, err, rateErr.RetryAfter)
+	}
+
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.Code == http.StatusTooManyRequests:
+			return apperrors.Wrap(apperrors.ErrRateLimited, err)
+		case statusErr.Code >= http.StatusInternalServerError:
+			return apperrors.Wrap(apperrors.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	// Slack API errors are plain error codes in the response body
+	switch err.Error() {
+	case "channel_not_found", "not_in_channel", "is_archived":
+		return apperrors.Wrap(apperrors.ErrSlackChannelNotFound, err)
+	case "not_authed", "invalid_auth", "account_inactive", "token_revoked", "token_expired", "missing_scope":
+		return apperrors.Wrap(apperrors.ErrAuth, err)
+	case "ratelimited":
+		return apperrors.Wrap(apperrors.ErrRateLimited, err)
+	case "message_not_found", "thread_not_found", "user_not_found", "users_not_found":
+		return apperrors.Wrap(apperrors.ErrNotFound, err)
+	}
+
+	return err
+}
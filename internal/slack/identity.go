@@ -0,0 +1,115 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// Identities maps Slack users to their GitHub accounts
+type Identities interface {
+	GitHubLogin(ctx context.Context, userID string) (string, bool)
+}
+
+// SetIdentities attributes button clicks to the clicker's GitHub account:
+// "Assign to me" assigns it, and closing comments name it
+func (n *Notifier) SetIdentities(identities Identities) {
+	n.identities = identities
+}
+
+// githubLogin returns the GitHub login of the user who clicked a button
+func (n *Notifier) githubLogin(ctx context.Context, callback slack.InteractionCallback) (string, bool) {
+	if n.identities == nil {
+		return "", false
+	}
+	return n.identities.GitHubLogin(ctx, callback.User.ID)
+}
+
+// UserEmail returns the email of a Slack user, which needs the
+// users:read.email scope. Users without one, e.g. bots, have an empty email.
+func (n *Notifier) UserEmail(ctx context.Context, userID string) (string, error) {
+	user, err := n.slackClient().GetUserInfoContext(ctx, userID)
+	if err != nil {
+		err = classifyError(err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return "", nil
+		}
+		n.metrics.RecordSlackError("user_info", apperrors.Classify(err))
+		return "", fmt.Errorf("failed to get Slack user: %w", err)
+	}
+	return user.Profile.Email, nil
+}
+
+// UserIDByEmail finds the Slack user with an email, returning an empty ID
+// if there is none
+func (n *Notifier) UserIDByEmail(ctx context.Context, email string) (string, error) {
+	user, err := n.slackClient().GetUserByEmailContext(ctx, email)
+	if err != nil {
+		err = classifyError(err)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return "", nil
+		}
+		n.metrics.RecordSlackError("user_by_email", apperrors.Classify(err))
+		return "", fmt.Errorf("failed to look up Slack user: %w", err)
+	}
+	if user.Deleted || user.IsBot {
+		return "", nil
+	}
+	return user.ID, nil
+}
+
+// handleAssignIssue assigns an issue to the GitHub account of the user who
+// clicked "Assign to me"
+func (n *Notifier) handleAssignIssue(callback slack.InteractionCallback, value string) bool {
+	// Value format: repoName:issueNumber
+	parts := strings.SplitN(value, ":", 2)
+	number := 0
+	if len(parts) == 2 {
+		number, _ = strconv.Atoi(parts[1])
+	}
+	if number <= 0 {
+		n.logger.Error("Failed to parse assign issue value", zap.String("value", value))
+		n.respondEphemeral(callback, ":warning: Could not parse issue information.")
+		return false
+	}
+	repo := parts[0]
+
+	ctx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	login, ok := n.authorizeAction(ctx, callback, callback.Channel.ID, "assign", repo)
+	if !ok {
+		return false
+	}
+	if login == "" {
+		n.respondEphemeral(callback, ":information_source: Your Slack account is not linked to a GitHub account. "+
+			"Ask an admin to add you to the bot's identities, or make the email on your GitHub profile public and match it to your Slack email.")
+		return false
+	}
+
+	if err := n.githubHandler.AddAssignee(ctx, repo, number, login); err != nil {
+		n.logger.Error("Failed to assign issue",
+			zap.String("repository", repo),
+			zap.Int("issue_number", number),
+			zap.String("assignee", login),
+			zap.Error(err))
+		n.respondEphemeral(callback, fmt.Sprintf(":warning: Could not assign #%d to @%s: %v", number, login, err))
+		return false
+	}
+
+	if _, _, err := n.slackClient().PostMessageContext(ctx,
+		callback.Channel.ID,
+		slack.MsgOptionText(fmt.Sprintf(":raising_hand: <@%s> assigned #%d to themselves (@%s on GitHub).", callback.User.ID, number, login), false),
+		slack.MsgOptionTS(callback.Message.Timestamp),
+	); err != nil {
+		n.logger.Error("Failed to post assign issue reply", zap.Error(err))
+	}
+	return true
+}
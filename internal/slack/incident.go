@@ -0,0 +1,57 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+// CreateIncidentChannel creates a public channel for an incident and invites
+// the responders. It needs the channels:manage scope.
+func (n *Notifier) CreateIncidentChannel(ctx context.Context, name string, responders []string) (string, error) {
+	start := time.Now()
+
+	channel, err := n.slackClient().CreateConversationContext(ctx, slack.CreateConversationParams{ChannelName: name})
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackMessage(name, "incident_channel", "error", time.Since(start))
+		n.metrics.RecordSlackError("create_channel", apperrors.Classify(err))
+		return "", fmt.Errorf("failed to create incident channel %s: %w", name, err)
+	}
+	n.metrics.RecordSlackMessage(channel.ID, "incident_channel", "success", time.Since(start))
+
+	// The channel is still useful without everyone in it, so invite failures are only logged
+	if len(responders) > 0 {
+		if _, err := n.slackClient().InviteUsersToConversationContext(ctx, channel.ID, responders...); err != nil {
+			err = classifyError(err)
+			n.metrics.RecordSlackError("invite_responders", apperrors.Classify(err))
+			n.logger.Warn("Failed to invite responders to incident channel",
+				zap.String("channel", channel.ID),
+				zap.Strings("responders", responders),
+				zap.Error(err))
+		}
+	}
+
+	n.logger.Info("Created incident channel",
+		zap.String("channel", channel.ID),
+		zap.String("name", name),
+	)
+	return channel.ID, nil
+}
+
+// ArchiveIncidentChannel archives an incident channel once the issue is closed
+func (n *Notifier) ArchiveIncidentChannel(ctx context.Context, channelID string) error {
+	if err := n.slackClient().ArchiveConversationContext(ctx, channelID); err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackError("archive_channel", apperrors.Classify(err))
+		return fmt.Errorf("failed to archive incident channel: %w", err)
+	}
+
+	n.logger.Info("Archived incident channel", zap.String("channel", channelID))
+	return nil
+}
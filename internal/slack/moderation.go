@@ -0,0 +1,262 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// moderateCallbackID identifies submissions of the modal that edits a held
+// summary
+const moderateCallbackID = "moderate_preview"
+
+// Block IDs of the moderation modal's inputs. Each input's action ID is its
+// block ID.
+const (
+	moderateTitleBlock    = "title"
+	moderateSummaryBlock  = "summary"
+	moderatePriorityBlock = "priority"
+	moderateActionsBlock  = "action_items"
+)
+
+// Slack limits of the moderation modal's inputs
+const (
+	maxSummaryTitle = 150
+	maxSummaryText  = 3000
+)
+
+// moderationPriorities are offered in the moderation modal, highest first
+var moderationPriorities = []string{"critical", "high", "medium", "low"}
+
+// Moderation holds the summaries of moderated repositories until a moderator
+// approves them
+type Moderation interface {
+	HeldSummary(repository string, number int) (*ai.IssueSummary, bool)
+	ApprovePreview(ctx context.Context, repository string, number int, edited *ai.IssueSummary, moderator string) (string, error)
+	DiscardPreview(repository string, number int, moderator string) bool
+}
+
+// SetModeration handles the approve, edit and discard buttons of the
+// previews sent to moderators
+func (n *Notifier) SetModeration(moderation Moderation) {
+	n.moderation = moderation
+}
+
+// moderationTarget is the held issue and the preview a modal was opened
+// from, kept in the view's private metadata until it is submitted
+type moderationTarget struct {
+	Repository string `json:"repository"`
+	Number     int    `json:"number"`
+	Channel    string `json:"channel"`
+	MessageTS  string `json:"message_ts"`
+}
+
+// parseModerationTarget reads the issue of a preview button and where the
+// preview was posted
+func parseModerationTarget(callback slack.InteractionCallback, value string) (moderationTarget, error) {
+	// Value format: repoName:issueNumber
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return moderationTarget{}, fmt.Errorf("invalid preview value %q", value)
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return moderationTarget{}, fmt.Errorf("invalid issue number in %q", value)
+	}
+	return moderationTarget{
+		Repository: parts[0],
+		Number:     number,
+		Channel:    callback.Channel.ID,
+		MessageTS:  callback.Message.Timestamp,
+	}, nil
+}
+
+// handleModeration handles a click on a preview's buttons. Approving posts
+// the issue, which takes longer than Slack waits, so it runs in the
+// background; editing opens a modal before the click is acknowledged.
+func (n *Notifier) handleModeration(callback slack.InteractionCallback, action *slack.BlockAction) {
+	target, err := parseModerationTarget(callback, action.Value)
+	if err != nil {
+		n.logger.Error("Failed to parse preview value", zap.Error(err))
+		return
+	}
+	switch action.ActionID {
+	case "approve_preview":
+		go n.approvePreview(target, nil, callback.User.ID)
+	case "edit_preview":
+		n.openModerationModal(callback, target)
+	case "discard_preview":
+		if n.moderation.DiscardPreview(target.Repository, target.Number, callback.User.ID) {
+			n.resolvePreview(target, fmt.Sprintf(":wastebasket: <@%s> discarded the summary of %s#%d; it was not posted.", callback.User.ID, target.Repository, target.Number))
+		} else {
+			n.resolvePreview(target, fmt.Sprintf(":information_source: %s#%d is no longer awaiting approval.", target.Repository, target.Number))
+		}
+	}
+}
+
+// approvePreview posts a held issue, with the moderator's edits if any, and
+// replaces the preview with the outcome
+func (n *Notifier) approvePreview(target moderationTarget, edited *ai.IssueSummary, userID string) {
+	channel, err := n.moderation.ApprovePreview(n.baseCtx, target.Repository, target.Number, edited, userID)
+	if err != nil {
+		n.logger.Error("Failed to post approved summary",
+			zap.String("repository", target.Repository),
+			zap.Int("issue_number", target.Number),
+			zap.Error(err))
+		slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+		defer done()
+		if err := n.PostThreadReply(slackCtx, target.Channel, target.MessageTS, fmt.Sprintf(":warning: Could not post %s#%d: %v", target.Repository, target.Number, err)); err != nil {
+			n.logger.Error("Failed to post moderation reply", zap.Error(err))
+		}
+		return
+	}
+	verb := "approved"
+	if edited != nil {
+		verb = "edited and approved"
+	}
+	n.resolvePreview(target, fmt.Sprintf(":white_check_mark: <@%s> %s the summary of %s#%d; it was posted to <#%s>.", userID, verb, target.Repository, target.Number, channel))
+}
+
+// resolvePreview replaces a preview with what became of it, so it cannot be
+// approved twice
+func (n *Notifier) resolvePreview(target moderationTarget, text string) {
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	section := slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+	if _, _, _, err := n.slackClient().UpdateMessageContext(slackCtx, target.Channel, target.MessageTS,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(section),
+		slack.MsgOptionAttachments([]slack.Attachment{}...),
+	); err != nil {
+		n.logger.Error("Failed to update moderation preview", zap.Error(err))
+		n.metrics.RecordSlackError("update_message", apperrors.Classify(classifyError(err)))
+	}
+}
+
+// openModerationModal lets the moderator edit a held summary before it is
+// posted. Trigger IDs expire after three seconds, so the modal is opened
+// before the click is acknowledged.
+func (n *Notifier) openModerationModal(callback slack.InteractionCallback, target moderationTarget) {
+	summary, ok := n.moderation.HeldSummary(target.Repository, target.Number)
+	if !ok {
+		n.resolvePreview(target, fmt.Sprintf(":information_source: %s#%d is no longer awaiting approval.", target.Repository, target.Number))
+		return
+	}
+	view, err := moderationModal(target, summary)
+	if err != nil {
+		n.logger.Error("Failed to build moderation modal", zap.Error(err))
+		return
+	}
+
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, err := n.slackClient().OpenViewContext(slackCtx, callback.TriggerID, view); err != nil {
+		n.logger.Error("Failed to open moderation modal", zap.Error(err))
+		n.metrics.RecordSlackError("open_view", apperrors.Classify(classifyError(err)))
+	}
+}
+
+// moderationModal builds the modal prefilled with the held summary
+func moderationModal(target moderationTarget, summary *ai.IssueSummary) (slack.ModalViewRequest, error) {
+	metadata, err := json.Marshal(target)
+	if err != nil {
+		return slack.ModalViewRequest{}, err
+	}
+
+	titleElement := slack.NewPlainTextInputBlockElement(nil, moderateTitleBlock)
+	titleElement.InitialValue = truncateRunes(summary.Title, maxSummaryTitle)
+	titleElement.MaxLength = maxSummaryTitle
+	titleInput := slack.NewInputBlock(moderateTitleBlock, plainText("Title"), nil, titleElement)
+
+	summaryElement := slack.NewPlainTextInputBlockElement(nil, moderateSummaryBlock)
+	summaryElement.InitialValue = truncateRunes(summary.Summary, maxSummaryText)
+	summaryElement.Multiline = true
+	summaryElement.MaxLength = maxSummaryText
+	summaryInput := slack.NewInputBlock(moderateSummaryBlock, plainText("Summary"), nil, summaryElement)
+
+	priorities := options(moderationPriorities)
+	prioritySelect := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, moderatePriorityBlock, priorities...)
+	for _, option := range priorities {
+		if option.Value == strings.ToLower(summary.Priority) {
+			prioritySelect.InitialOption = option
+		}
+	}
+	priorityInput := slack.NewInputBlock(moderatePriorityBlock, plainText("Priority"), nil, prioritySelect)
+
+	actionsElement := slack.NewPlainTextInputBlockElement(plainText("One per line"), moderateActionsBlock)
+	actionsElement.InitialValue = truncateRunes(strings.Join(summary.ActionItems, "\n"), maxSummaryText)
+	actionsElement.Multiline = true
+	actionsElement.MaxLength = maxSummaryText
+	actionsInput := slack.NewInputBlock(moderateActionsBlock, plainText("Action items"), nil, actionsElement)
+	actionsInput.Optional = true
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      moderateCallbackID,
+		Title:           plainText("Edit summary"),
+		Submit:          plainText("Approve and post"),
+		Close:           plainText("Cancel"),
+		PrivateMetadata: string(metadata),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("Edit the summary of *%s#%d*. It is posted as edited once you submit.", target.Repository, target.Number),
+				false, false), nil, nil),
+			titleInput,
+			summaryInput,
+			priorityInput,
+			actionsInput,
+		}},
+	}, nil
+}
+
+// editedSummary applies the moderator's edits in a submitted modal to a
+// copy of the held summary
+func editedSummary(held *ai.IssueSummary, state *slack.ViewState) *ai.IssueSummary {
+	edited := *held
+	if state == nil {
+		return &edited
+	}
+	value := func(block string) slack.BlockAction {
+		return state.Values[block][block]
+	}
+	if title := strings.TrimSpace(value(moderateTitleBlock).Value); title != "" {
+		edited.Title = title
+	}
+	if summary := strings.TrimSpace(value(moderateSummaryBlock).Value); summary != "" {
+		edited.Summary = summary
+	}
+	if priority := value(moderatePriorityBlock).SelectedOption.Value; priority != "" {
+		edited.Priority = priority
+	}
+	edited.ActionItems = []string{}
+	for _, line := range strings.Split(value(moderateActionsBlock).Value, "\n") {
+		if item := strings.TrimSpace(strings.TrimLeft(line, "-•* ")); item != "" {
+			edited.ActionItems = append(edited.ActionItems, item)
+		}
+	}
+	return &edited
+}
+
+// submitModeration posts a held issue as edited in a submitted modal
+func (n *Notifier) submitModeration(callback slack.InteractionCallback) {
+	var target moderationTarget
+	if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &target); err != nil {
+		n.logger.Error("Failed to parse moderation modal metadata", zap.Error(err))
+		return
+	}
+	held, ok := n.moderation.HeldSummary(target.Repository, target.Number)
+	if !ok {
+		n.resolvePreview(target, fmt.Sprintf(":information_source: %s#%d is no longer awaiting approval.", target.Repository, target.Number))
+		return
+	}
+	n.approvePreview(target, editedSummary(held, callback.View.State), callback.User.ID)
+}
@@ -3,34 +3,101 @@ package slack
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/slack-go/slack"
 	"go.uber.org/zap"
 
 	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/breaker"
 	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/monitor"
 )
 
 // Notifier handles Slack messaging
 type Notifier struct {
+	mu            sync.RWMutex
 	client        *slack.Client
+	botToken      string
+	apiURL        string           // Slack Web API base URL
+	breaker       *breaker.Breaker // Guards API calls, nil for none
+	shadow        Shadow           // Holds back writes in shadow mode, nil for none
 	channelID     string
 	signingSecret string
 	logger        *zap.Logger
 	metrics       MetricsRecorder
 	summarizer    *ai.Summarizer
 	githubHandler *gh.Handler
+	deepAnalyzer  DeepAnalyzer
+	issueMemory   IssueMemory
+	explainer     SummaryExplainer
+	linkedIssues  LinkedIssues
+	identities    Identities
+	baseCtx       context.Context // Parent of background work for interactions, cancelled on shutdown
+	aiTimeout     time.Duration
+	slackTimeout  time.Duration
+
+	// The "Create GitHub issue" message shortcut
+	issueShortcut  IssueShortcutConfig
+	issueProcessor IssueProcessor
+
+	// Whether actions that change GitHub need write access to the repository
+	requireWriteAccess bool
+
+	// Clicks are recorded as engagement with the message; botUser is the
+	// bot's own user ID, looked up once
+	engagement EngagementRecorder
+	botUser    string
+
+	// Approves, edits and discards summaries held for moderation
+	moderation Moderation
+
+	// Acknowledges, snoozes and escalates issues from team digests
+	triage Triage
 }
 
+// DeepAnalyzer runs the full analysis for an issue and replaces its Slack message
+type DeepAnalyzer interface {
+	DeepAnalyze(ctx context.Context, issueData *gh.IssueData, channelID, ts string) error
+}
+
+// IssueMemory keeps each issue's earlier analyses and follow-up exchanges
+type IssueMemory interface {
+	IssueMemory(repository string, number int) memory.Memory
+	RememberFollowUp(repository string, number int, question, answer string)
+}
+
+// SummaryExplainer finds the last summary of an issue, whose reasoning
+// explains its priority and confidence
+type SummaryExplainer interface {
+	ExplainIssue(repository string, number int) (*ai.IssueSummary, bool)
+}
+
+// LinkedIssues finds the cached issues an issue references, without calling
+// GitHub
+type LinkedIssues interface {
+	Linked(repository string, number int) ([]gh.LinkedIssue, bool)
+}
+
+// streamUpdateInterval is how often a streaming reply is edited. Slack rate
+// limits chat.update to roughly one call per second per channel.
+const streamUpdateInterval = 2 * time.Second
+
 // MetricsRecorder interface for recording metrics
 type MetricsRecorder interface {
 	RecordSlackMessage(channel, messageType, status string, duration time.Duration)
 	RecordSlackError(operation, errorType string)
+	RecordStageTimeout(stage string)
 }
 
 // NewNotifier creates a new Slack notifier
@@ -39,52 +106,315 @@ func NewNotifier(botToken, channelID, signingSecret string, logger *zap.Logger,
 
 	return &Notifier{
 		client:        client,
+		botToken:      botToken,
+		apiURL:        slack.APIURL,
 		channelID:     channelID,
 		signingSecret: signingSecret,
 		logger:        logger,
 		metrics:       metrics,
 		summarizer:    summarizer,
 		githubHandler: githubHandler,
+		baseCtx:       context.Background(),
+	}
+}
+
+// SetBaseContext sets the parent context of work started by interactive
+// messages, which outlives the Slack request. Cancelling it, e.g. on
+// shutdown, cancels their in-flight API calls.
+func (n *Notifier) SetBaseContext(ctx context.Context) {
+	n.baseCtx = ctx
+}
+
+// SetTimeouts limits how long a fix suggestion may take to generate and how
+// long each Slack call for it may take. Zero means no limit.
+func (n *Notifier) SetTimeouts(aiTimeout, slackTimeout time.Duration) {
+	n.aiTimeout = aiTimeout
+	n.slackTimeout = slackTimeout
+}
+
+// stageContext derives the context for a stage of an interaction. The
+// returned function releases the context and records the stage if it ran
+// out of time.
+func (n *Notifier) stageContext(parent context.Context, stage string, timeout time.Duration) (context.Context, func()) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	return ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			n.metrics.RecordStageTimeout(stage)
+		}
+		cancel()
+	}
+}
+
+// SetDeepAnalyzer enables the "Deep Analysis" button on triage summaries
+func (n *Notifier) SetDeepAnalyzer(analyzer DeepAnalyzer) {
+	n.deepAnalyzer = analyzer
+}
+
+// SetIssueMemory gives follow-up requests the issue's earlier analyses and
+// remembers their answers
+func (n *Notifier) SetIssueMemory(issueMemory IssueMemory) {
+	n.issueMemory = issueMemory
+}
+
+// SetSummaryExplainer answers the "Why?" button with the reasoning behind a summary
+func (n *Notifier) SetSummaryExplainer(explainer SummaryExplainer) {
+	n.explainer = explainer
+}
+
+// SetLinkedIssues enables the "Linked Issues" button
+func (n *Notifier) SetLinkedIssues(linkedIssues LinkedIssues) {
+	n.linkedIssues = linkedIssues
+}
+
+// SetBotToken replaces the Slack bot token, e.g. after a mounted secret has been rotated
+func (n *Notifier) SetBotToken(botToken string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.botToken = botToken
+	n.client = n.newClient()
+}
+
+// SetAPIURL points the notifier at another Slack Web API base URL, such as a
+// test server. The URL must end with a slash.
+func (n *Notifier) SetAPIURL(apiURL string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.apiURL = apiURL
+	n.client = n.newClient()
+}
+
+// SetBreaker guards Slack API calls with a circuit breaker, so they fail
+// fast while Slack is down
+func (n *Notifier) SetBreaker(b *breaker.Breaker) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.breaker = b
+	n.client = n.newClient()
+}
+
+// newClient creates a client for the current settings. The caller holds the
+// lock.
+func (n *Notifier) newClient() *slack.Client {
+	options := []slack.Option{slack.OptionAPIURL(n.apiURL)}
+	var transport http.RoundTripper
+	if n.shadow != nil {
+		transport = n.shadow.SlackTransport(nil)
+	}
+	if n.breaker != nil {
+		transport = n.breaker.Transport(transport)
+	}
+	if transport != nil {
+		options = append(options, slack.OptionHTTPClient(&http.Client{Transport: transport}))
 	}
+	return slack.New(n.botToken, options...)
+}
+
+// SetSigningSecret replaces the Slack signing secret
+func (n *Notifier) SetSigningSecret(signingSecret string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.signingSecret = signingSecret
 }
 
-// SendIssueSummary sends an issue summary to Slack
+// slackClient returns the current Slack API client
+func (n *Notifier) slackClient() *slack.Client {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.client
+}
+
+// SendIssueSummary sends an issue summary to the default Slack channel
 func (n *Notifier) SendIssueSummary(ctx context.Context, message map[string]interface{}) error {
+	return n.SendIssueSummaryToChannel(ctx, n.channelID, message)
+}
+
+// SendIssueSummaryToChannel sends an issue summary to a specific Slack channel
+func (n *Notifier) SendIssueSummaryToChannel(ctx context.Context, channelID string, message map[string]interface{}) error {
+	_, _, err := n.PostIssueSummary(ctx, channelID, message)
+	return err
+}
+
+// PostIssueSummary sends an issue summary and returns the channel ID and
+// timestamp of the posted message, so it can be updated later
+func (n *Notifier) PostIssueSummary(ctx context.Context, channelID string, message map[string]interface{}) (string, string, error) {
 	start := time.Now()
+	if channelID == "" {
+		channelID = n.channelID
+	}
 
 	// Convert message to Slack blocks
 	blocks, err := n.convertToSlackBlocks(message)
 	if err != nil {
 		n.metrics.RecordSlackError("convert_blocks", "json_error")
 		n.logger.Error("Failed to convert message to Slack blocks", zap.Error(err))
-		return fmt.Errorf("failed to convert message to Slack blocks: %w", err)
+		return "", "", fmt.Errorf("failed to convert message to Slack blocks: %w", err)
 	}
+	blocks, overflow := fitBlocks(blocks, continuedNote)
 
 	// Send message to Slack
-	_, _, err = n.client.PostMessageContext(
+	postedChannel, ts, err := n.slackClient().PostMessageContext(
 		ctx,
-		n.channelID,
-		slack.MsgOptionBlocks(blocks...),
-		slack.MsgOptionText("GitHub Issue Update", false), // Fallback text
+		channelID,
+		summaryOptions(message, blocks, false)...,
 	)
 
 	duration := time.Since(start)
 
 	if err != nil {
-		n.metrics.RecordSlackMessage(n.channelID, "issue_summary", "error", duration)
-		n.metrics.RecordSlackError("send_message", "api_error")
+		err = classifyError(err)
+		n.metrics.RecordSlackMessage(channelID, "issue_summary", "error", duration)
+		n.metrics.RecordSlackError("send_message", apperrors.Classify(err))
 		n.logger.Error("Failed to send Slack message", zap.Error(err))
-		return fmt.Errorf("failed to send Slack message: %w", err)
+		return "", "", fmt.Errorf("failed to send Slack message: %w", err)
 	}
 
-	n.metrics.RecordSlackMessage(n.channelID, "issue_summary", "success", duration)
+	n.metrics.RecordSlackMessage(channelID, "issue_summary", "success", duration)
 	n.logger.Info("Successfully sent issue summary to Slack",
-		zap.String("channel", n.channelID),
+		zap.String("channel", channelID),
+	)
+
+	// Continue what did not fit in the thread rather than dropping it
+	for _, text := range overflow {
+		if err := n.PostThreadReply(ctx, postedChannel, ts, text); err != nil {
+			n.logger.Warn("Failed to post summary overflow in thread",
+				zap.String("channel", postedChannel),
+				zap.Error(err))
+			break
+		}
+	}
+
+	return postedChannel, ts, nil
+}
+
+// UpdateIssueSummary replaces a previously posted issue summary in place
+func (n *Notifier) UpdateIssueSummary(ctx context.Context, channelID, ts string, message map[string]interface{}) error {
+	start := time.Now()
+
+	blocks, err := n.convertToSlackBlocks(message)
+	if err != nil {
+		n.metrics.RecordSlackError("convert_blocks", "json_error")
+		n.logger.Error("Failed to convert message to Slack blocks", zap.Error(err))
+		return fmt.Errorf("failed to convert message to Slack blocks: %w", err)
+	}
+	// The thread already continues the summary as first posted, so an
+	// update is cut short instead
+	blocks, overflow := fitBlocks(blocks, truncatedNote)
+	if len(overflow) > 0 {
+		n.logger.Warn("Truncated updated issue summary to fit Slack's limits",
+			zap.String("channel", channelID),
+			zap.String("ts", ts))
+	}
+
+	_, _, _, err = n.slackClient().UpdateMessageContext(
+		ctx,
+		channelID,
+		ts,
+		summaryOptions(message, blocks, true)...,
+	)
+
+	duration := time.Since(start)
+
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackMessage(channelID, "issue_summary_update", "error", duration)
+		n.metrics.RecordSlackError("update_message", apperrors.Classify(err))
+		n.logger.Error("Failed to update Slack message", zap.Error(err))
+		return fmt.Errorf("failed to update Slack message: %w", err)
+	}
+
+	n.metrics.RecordSlackMessage(channelID, "issue_summary_update", "success", duration)
+	n.logger.Info("Updated issue summary in Slack",
+		zap.String("channel", channelID),
+		zap.String("ts", ts),
+	)
+
+	return nil
+}
+
+// PostThreadReply posts a text reply in the thread of a posted message, e.g.
+// what changed when an issue was re-analyzed
+func (n *Notifier) PostThreadReply(ctx context.Context, channelID, threadTS, text string) error {
+	start := time.Now()
+	_, _, err := n.slackClient().PostMessageContext(
+		ctx,
+		channelID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionTS(threadTS),
+	)
+
+	duration := time.Since(start)
+
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackMessage(channelID, "thread_reply", "error", duration)
+		n.metrics.RecordSlackError("send_message", apperrors.Classify(err))
+		return fmt.Errorf("failed to post thread reply: %w", err)
+	}
+
+	n.metrics.RecordSlackMessage(channelID, "thread_reply", "success", duration)
+	return nil
+}
+
+// PostMessage posts a text message to a channel, e.g. a team's weekly digest.
+// kind labels the message in metrics.
+func (n *Notifier) PostMessage(ctx context.Context, channelID, kind, text string) error {
+	start := time.Now()
+	_, _, err := n.slackClient().PostMessageContext(
+		ctx,
+		channelID,
+		slack.MsgOptionText(text, false),
 	)
 
+	duration := time.Since(start)
+
+	if err != nil {
+		err = classifyError(err)
+		n.metrics.RecordSlackMessage(channelID, kind, "error", duration)
+		n.metrics.RecordSlackError("send_message", apperrors.Classify(err))
+		return fmt.Errorf("failed to post %s: %w", kind, err)
+	}
+
+	n.metrics.RecordSlackMessage(channelID, kind, "success", duration)
 	return nil
 }
 
+// summaryOptions lays out an issue summary. A "color" in the message puts the
+// blocks in an attachment with a colored bar, under the message's "text",
+// which is also the notification text. Updates clear whichever of the two
+// layouts is not used, since Slack would otherwise keep it.
+func summaryOptions(message map[string]interface{}, blocks []slack.Block, update bool) []slack.MsgOption {
+	text, _ := message["text"].(string)
+	if text == "" {
+		text = "GitHub Issue Update" // Fallback text
+	}
+
+	options := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	color, _ := message["color"].(string)
+	if color == "" {
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+		if update {
+			options = append(options, slack.MsgOptionAttachments([]slack.Attachment{}...))
+		}
+		return options
+	}
+
+	options = append(options, slack.MsgOptionAttachments(slack.Attachment{
+		Color:  color,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}))
+	if update {
+		options = append(options, slack.MsgOptionBlocks([]slack.Block{}...))
+	}
+	return options
+}
+
 // convertToSlackBlocks converts a message map to Slack blocks
 func (n *Notifier) convertToSlackBlocks(message map[string]interface{}) ([]slack.Block, error) {
 	blocksData, ok := message["blocks"]
@@ -147,6 +477,10 @@ func (n *Notifier) convertBlock(blockData interface{}) (slack.Block, error) {
 		return n.convertSectionBlock(blockMap)
 	case "actions":
 		return n.convertActionsBlock(blockMap)
+	case "context":
+		return n.convertContextBlock(blockMap)
+	case "divider":
+		return slack.NewDividerBlock(), nil
 	default:
 		return nil, fmt.Errorf("unsupported block type: %s", blockType)
 	}
@@ -271,51 +605,13 @@ func (n *Notifier) convertActionsBlock(blockMap map[string]interface{}) (slack.B
 				continue
 			}
 			if elemMap["type"] == "button" {
-				textMap, _ := elemMap["text"].(map[string]interface{})
-				text := ""
-				if textMap != nil {
-					text, _ = textMap["text"].(string)
-				}
-				style, _ := elemMap["style"].(string)
-				actionID, _ := elemMap["action_id"].(string)
-				value, _ := elemMap["value"].(string)
-				url, _ := elemMap["url"].(string)
-
-				btn := slack.NewButtonBlockElement(actionID, value, slack.NewTextBlockObject("plain_text", text, false, false))
-				if style == "primary" {
-					btn.Style = slack.StylePrimary
-				} else if style == "danger" {
-					btn.Style = slack.StyleDanger
-				}
-				if url != "" {
-					btn.URL = url
-				}
-				elements = append(elements, btn)
+				elements = append(elements, convertButtonElement(elemMap))
 			}
 		}
 	case []map[string]interface{}:
 		for _, elemMap := range v {
 			if elemMap["type"] == "button" {
-				textMap, _ := elemMap["text"].(map[string]interface{})
-				text := ""
-				if textMap != nil {
-					text, _ = textMap["text"].(string)
-				}
-				style, _ := elemMap["style"].(string)
-				actionID, _ := elemMap["action_id"].(string)
-				value, _ := elemMap["value"].(string)
-				url, _ := elemMap["url"].(string)
-
-				btn := slack.NewButtonBlockElement(actionID, value, slack.NewTextBlockObject("plain_text", text, false, false))
-				if style == "primary" {
-					btn.Style = slack.StylePrimary
-				} else if style == "danger" {
-					btn.Style = slack.StyleDanger
-				}
-				if url != "" {
-					btn.URL = url
-				}
-				elements = append(elements, btn)
+				elements = append(elements, convertButtonElement(elemMap))
 			}
 		}
 	}
@@ -330,19 +626,115 @@ func (n *Notifier) convertActionsBlock(blockMap map[string]interface{}) (slack.B
 	return slack.NewActionBlock("actions", elements...), nil
 }
 
+// convertButtonElement converts a button element, including an optional
+// confirmation dialog ({"title", "text", "confirm", "deny"})
+func convertButtonElement(elemMap map[string]interface{}) *slack.ButtonBlockElement {
+	textMap, _ := elemMap["text"].(map[string]interface{})
+	text := ""
+	if textMap != nil {
+		text, _ = textMap["text"].(string)
+	}
+	style, _ := elemMap["style"].(string)
+	actionID, _ := elemMap["action_id"].(string)
+	value, _ := elemMap["value"].(string)
+	url, _ := elemMap["url"].(string)
+
+	btn := slack.NewButtonBlockElement(actionID, value, slack.NewTextBlockObject("plain_text", text, false, false))
+	if style == "primary" {
+		btn.Style = slack.StylePrimary
+	} else if style == "danger" {
+		btn.Style = slack.StyleDanger
+	}
+	if url != "" {
+		btn.URL = url
+	}
+
+	if confirmMap, ok := elemMap["confirm"].(map[string]interface{}); ok {
+		title, _ := confirmMap["title"].(string)
+		body, _ := confirmMap["text"].(string)
+		confirm, _ := confirmMap["confirm"].(string)
+		deny, _ := confirmMap["deny"].(string)
+		btn.Confirm = slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject("plain_text", title, false, false),
+			slack.NewTextBlockObject("mrkdwn", body, false, false),
+			slack.NewTextBlockObject("plain_text", confirm, false, false),
+			slack.NewTextBlockObject("plain_text", deny, false, false),
+		)
+		if style == "danger" {
+			btn.Confirm.WithStyle(slack.StyleDanger)
+		}
+	}
+
+	return btn
+}
+
+// convertContextBlock converts a context block (e.g. footers)
+func (n *Notifier) convertContextBlock(blockMap map[string]interface{}) (slack.Block, error) {
+	elementsData, ok := blockMap["elements"]
+	if !ok {
+		return nil, fmt.Errorf("context block missing elements")
+	}
+
+	var elementMaps []map[string]interface{}
+	switch v := elementsData.(type) {
+	case []interface{}:
+		for _, elem := range v {
+			if elemMap, ok := elem.(map[string]interface{}); ok {
+				elementMaps = append(elementMaps, elemMap)
+			}
+		}
+	case []map[string]interface{}:
+		elementMaps = v
+	}
+
+	var elements []slack.MixedElement
+	for _, elemMap := range elementMaps {
+		text, ok := elemMap["text"].(string)
+		if !ok {
+			continue
+		}
+		textType, _ := elemMap["type"].(string)
+		if textType != "plain_text" {
+			textType = "mrkdwn"
+		}
+		elements = append(elements, slack.NewTextBlockObject(textType, text, false, false))
+	}
+
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("invalid context block: no text elements")
+	}
+
+	return slack.NewContextBlock("", elements...), nil
+}
+
 // TODO: Implement action element conversion with updated Slack SDK
 
-// HandleInteractiveMessage handles Slack interactive messages (button clicks)
+// HandleInteractiveMessage handles Slack interactive messages (button clicks).
+// Requests without a valid Slack signature are rejected with 401.
 func (n *Notifier) HandleInteractiveMessage(w http.ResponseWriter, r *http.Request) {
 	n.logger.Info("Received Slack interactive message request")
 
+	// Interactions close, assign and create issues, so only Slack may send
+	// them
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBody))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := n.verifyRequest(r.Header, body); err != nil {
+		n.logger.Warn("Rejected Slack interaction with an invalid signature", zap.Error(err))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
 	// Parse the payload from Slack
-	if err := r.ParseForm(); err != nil {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
 		n.logger.Error("Failed to parse form", zap.Error(err))
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	payload := r.PostFormValue("payload")
+	payload := form.Get("payload")
 	if payload == "" {
 		n.logger.Error("Missing payload in Slack interactive request")
 		w.WriteHeader(http.StatusBadRequest)
@@ -364,6 +756,32 @@ func (n *Notifier) HandleInteractiveMessage(w http.ResponseWriter, r *http.Reque
 		zap.String("user_id", callback.User.ID),
 		zap.String("message_ts", callback.Message.Timestamp))
 
+	// Modal submissions carry the view's inputs rather than an action
+	if callback.Type == slack.InteractionTypeViewSubmission {
+		n.handleViewSubmission(callback)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Adding or editing a workflow step opens its configuration modal before
+	// it is acknowledged
+	if callback.Type == slack.InteractionTypeWorkflowStepEdit {
+		n.openWorkflowStepModal(callback)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Message shortcuts open a modal before they are acknowledged
+	if callback.Type == slack.InteractionTypeMessageAction {
+		if callback.CallbackID == IssueShortcutCallbackID {
+			n.openCreateIssueModal(callback)
+		} else {
+			n.logger.Info("Unhandled Slack message shortcut", zap.String("callback_id", callback.CallbackID))
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	// Find the action
 	if len(callback.ActionCallback.BlockActions) == 0 {
 		n.logger.Error("No actions in Slack interactive payload")
@@ -371,6 +789,7 @@ func (n *Notifier) HandleInteractiveMessage(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	action := callback.ActionCallback.BlockActions[0]
+	n.recordEngagement(callback)
 
 	n.logger.Info("Processing Slack action",
 		zap.String("action_id", action.ActionID),
@@ -380,17 +799,18 @@ func (n *Notifier) HandleInteractiveMessage(w http.ResponseWriter, r *http.Reque
 	if action.ActionID == "review_issue" {
 		n.logger.Info("Processing review_issue action")
 		// Post a reply in the thread
-		_, _, err := n.client.PostMessage(
-			callback.Channel.ID,
-			slack.MsgOptionText(":mag: Review thread started! (AI insights coming soon)", false),
-			slack.MsgOptionTS(callback.Message.Timestamp),
-		)
-		if err != nil {
-			n.logger.Error("Failed to post review thread reply", zap.Error(err))
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		n.logger.Info("Successfully posted review thread reply")
+		n.deferAction(callback, action.ActionID, func() {
+			_, _, err := n.slackClient().PostMessage(
+				callback.Channel.ID,
+				slack.MsgOptionText(":mag: Review thread started! (AI insights coming soon)", false),
+				slack.MsgOptionTS(callback.Message.Timestamp),
+			)
+			if err != nil {
+				n.logger.Error("Failed to post review thread reply", zap.Error(err))
+				return
+			}
+			n.logger.Info("Successfully posted review thread reply")
+		})
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -403,7 +823,7 @@ func (n *Notifier) HandleInteractiveMessage(w http.ResponseWriter, r *http.Reque
 			n.logger.Error("Failed to parse repo and issue number",
 				zap.String("value", action.Value),
 				zap.Int("parts_count", len(parts)))
-			n.client.PostMessage(
+			n.slackClient().PostMessage(
 				callback.Channel.ID,
 				slack.MsgOptionText(":warning: Could not parse issue information.", false),
 				slack.MsgOptionTS(callback.Message.Timestamp),
@@ -419,7 +839,7 @@ func (n *Notifier) HandleInteractiveMessage(w http.ResponseWriter, r *http.Reque
 				zap.String("value", action.Value),
 				zap.String("number_part", parts[1]),
 				zap.Error(err))
-			n.client.PostMessage(
+			n.slackClient().PostMessage(
 				callback.Channel.ID,
 				slack.MsgOptionText(":warning: Could not parse issue number.", false),
 				slack.MsgOptionTS(callback.Message.Timestamp),
@@ -430,59 +850,338 @@ func (n *Notifier) HandleInteractiveMessage(w http.ResponseWriter, r *http.Reque
 
 		n.logger.Info("Parsed issue info", zap.String("repo", repo), zap.Int("number", number))
 
-		ctx := context.Background()
-		// Fetch enriched issue data
-		n.logger.Info("Fetching enriched issue data")
-		issueData, err := n.githubHandler.FetchEnrichedIssueData(ctx, repo, number)
-		if err != nil {
-			n.logger.Error("Failed to fetch issue data for suggest_fix", zap.Error(err))
-			n.client.PostMessage(
-				callback.Channel.ID,
-				slack.MsgOptionText(":warning: Could not fetch issue data for fix suggestion.", false),
-				slack.MsgOptionTS(callback.Message.Timestamp),
-			)
+		// Slack expects an acknowledgement within three seconds, so the fix is
+		// generated in the background and streamed into the thread
+		n.deferAction(callback, action.ActionID, func() {
+			n.streamSuggestedFix(callback, repo, number)
+		})
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action.ActionID == "deep_analysis" && n.deepAnalyzer != nil {
+		// Analysis takes longer than Slack waits for an acknowledgement
+		n.deferAction(callback, action.ActionID, func() {
+			n.runDeepAnalysis(callback, action.Value, "deep analysis")
+		})
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action.ActionID == "retry_summary" && n.deepAnalyzer != nil {
+		// Messages posted while the AI failed are replaced like triage summaries
+		n.deferAction(callback, action.ActionID, func() {
+			n.runDeepAnalysis(callback, action.Value, "AI summary")
+		})
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action.ActionID == "resummarize" {
+		n.openResummarizeModal(callback, action.Value)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action.ActionID == "assign_issue" {
+		n.deferAction(callback, action.ActionID, func() {
+			n.handleAssignIssue(callback, action.Value)
+		})
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action.ActionID == "close_issue" {
+		// Closing re-checks the issue with GitHub first
+		n.deferAction(callback, action.ActionID, func() {
+			n.handleCloseIssue(callback, action.Value)
+		})
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action.ActionID == "explain_summary" && n.explainer != nil {
+		n.explainSummary(callback, action.Value)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action.ActionID == "linked_issues" && n.linkedIssues != nil {
+		n.showLinkedIssues(callback, action.Value)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch action.ActionID {
+	case "approve_preview", "edit_preview", "discard_preview":
+		if n.moderation != nil {
+			n.handleModeration(callback, action)
 			w.WriteHeader(http.StatusOK)
 			return
 		}
-		n.logger.Info("Successfully fetched issue data")
-
-		// Call AI summarizer
-		n.logger.Info("Calling AI summarizer for fix suggestion")
-		summary, err := n.summarizer.SummarizeIssue(ctx, issueData)
-		if err != nil {
-			n.logger.Error("AI summarizer failed for suggest_fix", zap.Error(err))
-			n.client.PostMessage(
-				callback.Channel.ID,
-				slack.MsgOptionText(":warning: AI could not generate a fix suggestion.", false),
-				slack.MsgOptionTS(callback.Message.Timestamp),
-			)
+	case digestIssueAction:
+		if n.triage != nil {
+			n.handleDigestIssue(callback, action)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	case digestAckLowAction:
+		if n.triage != nil {
+			n.handleDigestAckLow(callback, action.Value)
 			w.WriteHeader(http.StatusOK)
 			return
 		}
-		n.logger.Info("Successfully generated AI summary with fix suggestion")
+	}
 
-		// Extract suggested_fix
-		suggestedFix := summary.SuggestedFix
-		n.logger.Info("Extracted suggested fix", zap.String("fix_length", fmt.Sprintf("%d", len(suggestedFix))))
+	n.logger.Info("Unhandled Slack action", zap.String("action_id", action.ActionID))
+	w.WriteHeader(http.StatusOK)
+}
 
-		// Post the suggested fix in the thread
-		msg := fmt.Sprintf(":wrench: *Suggested Fix:*\n```\n%s\n```", suggestedFix)
-		n.logger.Info("Posting fix suggestion to thread")
-		_, _, err = n.client.PostMessage(
+// streamSuggestedFix posts a placeholder reply in the issue thread and edits it
+// as the fix is generated, finishing with the formatted suggestion
+func (n *Notifier) streamSuggestedFix(callback slack.InteractionCallback, repo string, number int) {
+	ctx := n.baseCtx
+	post := func(text string) (string, string, error) {
+		slackCtx, done := n.stageContext(ctx, monitor.StageNotify, n.slackTimeout)
+		defer done()
+		return n.slackClient().PostMessageContext(slackCtx,
 			callback.Channel.ID,
-			slack.MsgOptionText(msg, false),
+			slack.MsgOptionText(text, false),
 			slack.MsgOptionTS(callback.Message.Timestamp),
 		)
-		if err != nil {
-			n.logger.Error("Failed to post fix suggestion to thread", zap.Error(err))
-			w.WriteHeader(http.StatusInternalServerError)
+	}
+	reply := func(text string) {
+		if _, _, err := post(text); err != nil {
+			n.logger.Error("Failed to post suggest_fix reply", zap.Error(err))
+		}
+	}
+
+	issueData, err := n.githubHandler.FetchEnrichedIssueData(ctx, repo, number)
+	if err != nil {
+		n.logger.Error("Failed to fetch issue data for suggest_fix", zap.Error(err))
+		reply(":warning: Could not fetch issue data for fix suggestion.")
+		return
+	}
+	if n.issueMemory != nil {
+		issueData.Memory = n.issueMemory.IssueMemory(repo, number)
+	}
+
+	channelID, ts, err := post(":wrench: _Generating fix suggestion..._")
+	if err != nil {
+		n.logger.Error("Failed to post fix suggestion placeholder", zap.Error(err))
+		return
+	}
+
+	update := func(text string) error {
+		slackCtx, done := n.stageContext(ctx, monitor.StageNotify, n.slackTimeout)
+		defer done()
+		_, _, _, err := n.slackClient().UpdateMessageContext(slackCtx, channelID, ts, slack.MsgOptionText(text, false))
+		return err
+	}
+
+	var lastUpdate time.Time
+	aiCtx, done := n.stageContext(ctx, monitor.StageSummarize, n.aiTimeout)
+	fix, err := n.summarizer.StreamSuggestedFix(aiCtx, issueData, func(partial string) {
+		if time.Since(lastUpdate) < streamUpdateInterval {
 			return
 		}
-		n.logger.Info("Successfully posted fix suggestion to thread - REPLY SENT")
-		w.WriteHeader(http.StatusOK)
+		lastUpdate = time.Now()
+		if err := update(":wrench: *Suggested Fix* _(generating...)_\n" + partial); err != nil {
+			n.logger.Warn("Failed to update streaming fix suggestion", zap.Error(err))
+		}
+	})
+	done()
+	if err != nil {
+		n.logger.Error("AI summarizer failed for suggest_fix", zap.Error(err))
+		if err := update(":warning: AI could not generate a fix suggestion."); err != nil {
+			n.logger.Error("Failed to update fix suggestion", zap.Error(err))
+		}
 		return
 	}
 
-	n.logger.Info("Unhandled Slack action", zap.String("action_id", action.ActionID))
-	w.WriteHeader(http.StatusOK)
+	if err := update(ai.FormatSuggestedFix(fix)); err != nil {
+		n.logger.Error("Failed to post fix suggestion to thread", zap.Error(err))
+		return
+	}
+	if n.issueMemory != nil {
+		n.issueMemory.RememberFollowUp(repo, number, "Suggest a fix", fix)
+	}
+	n.logger.Info("Posted fix suggestion to thread",
+		zap.String("repo", repo),
+		zap.Int("number", number),
+		zap.Int("fix_length", len(fix)))
+}
+
+// runDeepAnalysis replaces a triage summary, or an issue posted without a
+// summary, with the full analysis. what names the analysis in replies.
+func (n *Notifier) runDeepAnalysis(callback slack.InteractionCallback, value, what string) {
+	reply := func(text string) {
+		if _, _, err := n.slackClient().PostMessage(
+			callback.Channel.ID,
+			slack.MsgOptionText(text, false),
+			slack.MsgOptionTS(callback.Message.Timestamp),
+		); err != nil {
+			n.logger.Error("Failed to post deep analysis reply", zap.Error(err))
+		}
+	}
+
+	// Value format: repoName:issueNumber
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		n.logger.Error("Failed to parse deep analysis value", zap.String("value", value))
+		reply(":warning: Could not parse issue information.")
+		return
+	}
+	repo := parts[0]
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		n.logger.Error("Failed to parse issue number", zap.String("value", value), zap.Error(err))
+		reply(":warning: Could not parse issue number.")
+		return
+	}
+
+	ctx := n.baseCtx
+	issueData, err := n.githubHandler.FetchEnrichedIssueData(ctx, repo, number)
+	if err != nil {
+		n.logger.Error("Failed to fetch issue data for deep_analysis", zap.Error(err))
+		reply(fmt.Sprintf(":warning: Could not fetch issue data for the %s.", what))
+		return
+	}
+
+	if err := n.deepAnalyzer.DeepAnalyze(ctx, issueData, callback.Channel.ID, callback.Message.Timestamp); err != nil {
+		n.logger.Error("Deep analysis failed", zap.String("analysis", what), zap.Error(err))
+		reply(fmt.Sprintf(":warning: AI could not complete the %s.", what))
+		return
+	}
+	n.logger.Info("Replaced issue message with the full analysis",
+		zap.String("analysis", what),
+		zap.String("repo", repo),
+		zap.Int("number", number))
+}
+
+// explainSummary shows the user who clicked "Why?" the signals behind the
+// summary's priority and confidence, without posting to the channel
+func (n *Notifier) explainSummary(callback slack.InteractionCallback, value string) {
+	text := ":warning: Could not parse issue information."
+
+	// Value format: repoName:issueNumber
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 2 {
+		number, err := strconv.Atoi(parts[1])
+		if err != nil {
+			n.logger.Error("Failed to parse issue number", zap.String("value", value), zap.Error(err))
+		} else if summary, ok := n.explainer.ExplainIssue(parts[0], number); ok {
+			text = ai.FormatExplanation(summary)
+		} else {
+			text = fmt.Sprintf(":information_source: The summary of #%d is no longer available.", number)
+		}
+	} else {
+		n.logger.Error("Failed to parse explain value", zap.String("value", value))
+	}
+
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, err := n.slackClient().PostEphemeralContext(slackCtx,
+		callback.Channel.ID,
+		callback.User.ID,
+		slack.MsgOptionText(text, false),
+	); err != nil {
+		n.logger.Error("Failed to post summary explanation", zap.Error(err))
+	}
+}
+
+// showLinkedIssues shows the user who clicked "Linked Issues" the issues the
+// issue references. They were prefetched when the issue was processed, so
+// the reply is posted within Slack's three seconds.
+func (n *Notifier) showLinkedIssues(callback slack.InteractionCallback, value string) {
+	text := ":warning: Could not parse issue information."
+
+	// Value format: repoName:issueNumber
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 2 {
+		number, err := strconv.Atoi(parts[1])
+		if err != nil {
+			n.logger.Error("Failed to parse issue number", zap.String("value", value), zap.Error(err))
+		} else if issues, ok := n.linkedIssues.Linked(parts[0], number); ok {
+			text = ai.FormatLinkedIssues(number, issues)
+		} else {
+			text = fmt.Sprintf(":information_source: The linked issues of #%d are no longer cached.", number)
+		}
+	} else {
+		n.logger.Error("Failed to parse linked issues value", zap.String("value", value))
+	}
+
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, err := n.slackClient().PostEphemeralContext(slackCtx,
+		callback.Channel.ID,
+		callback.User.ID,
+		slack.MsgOptionText(text, false),
+	); err != nil {
+		n.logger.Error("Failed to post linked issues", zap.Error(err))
+	}
+}
+
+// handleCloseIssue closes an issue after the user confirmed a close suggestion.
+// The suggestion is re-checked so the closing comment reflects the current state.
+func (n *Notifier) handleCloseIssue(callback slack.InteractionCallback, value string) {
+	reply := func(text string) {
+		if _, _, err := n.slackClient().PostMessage(
+			callback.Channel.ID,
+			slack.MsgOptionText(text, false),
+			slack.MsgOptionTS(callback.Message.Timestamp),
+		); err != nil {
+			n.logger.Error("Failed to post close issue reply", zap.Error(err))
+		}
+	}
+
+	// Value format: repoName:issueNumber:reason
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 {
+		n.logger.Error("Failed to parse close issue value", zap.String("value", value))
+		reply(":warning: Could not parse issue information.")
+		return
+	}
+	repo, reason := parts[0], parts[2]
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		n.logger.Error("Failed to parse issue number", zap.String("value", value), zap.Error(err))
+		reply(":warning: Could not parse issue number.")
+		return
+	}
+
+	ctx := n.baseCtx
+	login, ok := n.authorizeAction(ctx, callback, callback.Channel.ID, "close", repo)
+	if !ok {
+		return
+	}
+	issueData, err := n.githubHandler.FetchEnrichedIssueData(ctx, repo, number)
+	if err != nil {
+		n.logger.Error("Failed to fetch issue data for close_issue", zap.Error(err))
+		reply(":warning: Could not fetch the issue.")
+		return
+	}
+
+	suggestion := issueData.CloseSuggestion
+	if issueData.Issue.GetState() == "closed" {
+		reply(fmt.Sprintf(":information_source: #%d is already closed.", number))
+		return
+	}
+	if suggestion == nil || suggestion.Reason != reason {
+		reply(fmt.Sprintf(":information_source: #%d no longer looks %s, so it was left open.", number, reason))
+		return
+	}
+
+	// Attribute the close to the user's GitHub account when it is known
+	closedBy := callback.User.Name
+	if login != "" {
+		closedBy = "@" + login
+	}
+	if err := n.githubHandler.CloseIssue(ctx, repo, number, suggestion, closedBy); err != nil {
+		n.logger.Error("Failed to close issue", zap.Error(err))
+		reply(fmt.Sprintf(":warning: Could not close #%d: %v", number, err))
+		return
+	}
+
+	reply(fmt.Sprintf(":white_check_mark: Closed #%d as %s. %s", number, reason, suggestion.Explanation))
 }
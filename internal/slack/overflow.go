@@ -0,0 +1,125 @@
+package slack
+
+import (
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// Slack's limits on the text of blocks. Slack rejects a whole message with
+// any block over them.
+const (
+	maxSectionText = 3000
+	maxFieldText   = 2000
+	maxHeaderText  = 150
+	maxContextText = 3000
+)
+
+// maxSectionParts is how many blocks a long section is split across before
+// the rest of it is moved out of the message
+const maxSectionParts = 3
+
+// Notes ending a section whose rest did not fit in the message
+const (
+	continuedNote = "_… continued in thread_"
+	truncatedNote = "_… truncated_"
+)
+
+// fence opens and closes a code block in mrkdwn
+const fence = "```"
+
+// fitBlocks keeps blocks within Slack's text limits. A long section is split
+// across up to maxSectionParts sections, breaking at paragraphs, lines or
+// words and closing and reopening code blocks around the breaks. The last
+// part of a section that still does not fit ends with note, and the rest is
+// returned as overflow, in pieces that each fit a message. Long headers,
+// fields and context elements are shortened.
+func fitBlocks(blocks []slack.Block, note string) ([]slack.Block, []string) {
+	var fitted []slack.Block
+	var overflow []string
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *slack.HeaderBlock:
+			if b.Text != nil {
+				b.Text.Text = shorten(b.Text.Text, maxHeaderText)
+			}
+		case *slack.ContextBlock:
+			for _, element := range b.ContextElements.Elements {
+				if text, ok := element.(*slack.TextBlockObject); ok {
+					text.Text = shorten(text.Text, maxContextText)
+				}
+			}
+		case *slack.SectionBlock:
+			for _, field := range b.Fields {
+				field.Text = shorten(field.Text, maxFieldText)
+			}
+			if b.Text == nil || len([]rune(b.Text.Text)) <= maxSectionText {
+				break
+			}
+
+			// Leave room for the note after the last part
+			parts, rest := splitText(b.Text.Text, maxSectionText-len([]rune(note))-1, maxSectionParts)
+			if rest != "" {
+				parts[len(parts)-1] += "\n" + note
+				more, _ := splitText(rest, maxSectionText, 0)
+				overflow = append(overflow, more...)
+			}
+			for _, part := range parts {
+				fitted = append(fitted, slack.NewSectionBlock(slack.NewTextBlockObject(b.Text.Type, part, false, false), nil, b.Accessory))
+				b.Accessory = nil // Only on the first part
+			}
+			continue
+		}
+		fitted = append(fitted, block)
+	}
+	return fitted, overflow
+}
+
+// splitText splits text into parts of at most limit characters, up to
+// maxParts parts when maxParts is positive, and returns what is left over
+func splitText(text string, limit, maxParts int) ([]string, string) {
+	var parts []string
+	runes := []rune(text)
+	for len(runes) > limit {
+		if maxParts > 0 && len(parts) == maxParts {
+			return parts, string(runes)
+		}
+		// Leave room to close a code block
+		cut, skip := splitPoint(runes, limit-len(fence)-1)
+		part, rest := string(runes[:cut]), string(runes[cut+skip:])
+		if strings.Count(part, fence)%2 == 1 {
+			part += "\n" + fence
+			rest = fence + "\n" + rest
+		}
+		parts = append(parts, part)
+		runes = []rune(rest)
+	}
+	if maxParts > 0 && len(parts) == maxParts {
+		return parts, string(runes)
+	}
+	return append(parts, string(runes)), ""
+}
+
+// splitPoint finds where to break runes at or before limit: at the last
+// paragraph break, line break or space in the second half, or else at limit.
+// It returns the break and the length of the separator to drop.
+func splitPoint(runes []rune, limit int) (int, int) {
+	text := string(runes[:limit])
+	for _, separator := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(text, separator); i >= 0 {
+			if cut := len([]rune(text[:i])); cut >= limit/2 {
+				return cut, len(separator)
+			}
+		}
+	}
+	return limit, 0
+}
+
+// shorten cuts text to at most limit characters, ending it with an ellipsis
+func shorten(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,94 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// Outcomes of a permission check in the audit log
+const (
+	auditAllowed = "allowed"
+	auditDenied  = "denied"
+	auditError   = "error"
+)
+
+// SetRequireWriteAccess makes buttons and shortcuts that change GitHub, such
+// as assigning, closing or filing issues, check that the clicking user's
+// linked GitHub account has write access to the repository first. Every
+// check is written to the audit log.
+func (n *Notifier) SetRequireWriteAccess(enabled bool) {
+	n.requireWriteAccess = enabled
+}
+
+// authorizeAction checks that the user behind an interaction may run action,
+// e.g. "close", on repo. Users who may not are told why, privately, in
+// channel. The user's GitHub login is returned when it is known; without
+// permission checks every user is allowed.
+func (n *Notifier) authorizeAction(ctx context.Context, callback slack.InteractionCallback, channel, action, repo string) (string, bool) {
+	login, linked := n.githubLogin(ctx, callback)
+	if !n.requireWriteAccess {
+		return login, true
+	}
+
+	if !linked {
+		n.auditAction(callback, action, repo, "", auditDenied, "no linked GitHub account")
+		n.tellUser(callback, channel, fmt.Sprintf(":no_entry: You need a linked GitHub account with write access to %s to %s from Slack. "+
+			"Ask an admin to add you to the bot's identities, or make the email on your GitHub profile public and match it to your Slack email.", repo, actionVerbs[action]))
+		return "", false
+	}
+
+	allowed, err := n.githubHandler.HasWriteAccess(ctx, repo, login)
+	if err != nil {
+		n.auditAction(callback, action, repo, login, auditError, err.Error())
+		n.tellUser(callback, channel, fmt.Sprintf(":warning: Could not check @%s's access to %s, so nothing was changed: %v", login, repo, err))
+		return login, false
+	}
+	if !allowed {
+		n.auditAction(callback, action, repo, login, auditDenied, "no write access")
+		n.tellUser(callback, channel, fmt.Sprintf(":no_entry: @%s does not have write access to %s, so you cannot %s from Slack.", login, repo, actionVerbs[action]))
+		return login, false
+	}
+	n.auditAction(callback, action, repo, login, auditAllowed, "")
+	return login, true
+}
+
+// actionVerbs describe the checked actions in messages to the user
+var actionVerbs = map[string]string{
+	"assign":   "assign its issues",
+	"close":    "close its issues",
+	"create":   "file issues in it",
+	"escalate": "escalate its issues",
+}
+
+// auditAction records who tried to change which repository from Slack, and
+// whether they were allowed to
+func (n *Notifier) auditAction(callback slack.InteractionCallback, action, repo, login, outcome, reason string) {
+	n.logger.Info("Slack action permission check",
+		zap.String("audit", "slack_action"),
+		zap.String("action", action),
+		zap.String("repository", repo),
+		zap.String("slack_user_id", callback.User.ID),
+		zap.String("slack_user_name", callback.User.Name),
+		zap.String("github_login", login),
+		zap.String("outcome", outcome),
+		zap.String("reason", reason))
+}
+
+// tellUser shows text only to the user behind an interaction: through its
+// response URL, or in channel for modal submissions, which have none
+func (n *Notifier) tellUser(callback slack.InteractionCallback, channel, text string) {
+	if callback.ResponseURL != "" || channel == "" {
+		n.respondEphemeral(callback, text)
+		return
+	}
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, err := n.slackClient().PostEphemeralContext(slackCtx, channel, callback.User.ID, slack.MsgOptionText(text, false)); err != nil {
+		n.logger.Warn("Failed to post ephemeral message", zap.Error(err))
+	}
+}
@@ -0,0 +1,246 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// resummarizeCallbackID identifies submissions of the "Re-summarize…" modal
+const resummarizeCallbackID = "resummarize"
+
+// Block IDs of the modal's inputs. Each input's action ID is its block ID.
+const (
+	resummarizeStyleBlock    = "style"
+	resummarizeDetailBlock   = "detail_level"
+	resummarizeLanguageBlock = "language"
+	resummarizeCodeBlock     = "code_context"
+)
+
+// resummarizeTarget is the issue and thread a modal was opened for, kept in
+// the view's private metadata until it is submitted
+type resummarizeTarget struct {
+	Repository string `json:"repository"`
+	Number     int    `json:"number"`
+	Channel    string `json:"channel"`
+	ThreadTS   string `json:"thread_ts"`
+}
+
+// openResummarizeModal asks the user who clicked "Re-summarize…" how the
+// issue should be summarized. Trigger IDs expire after three seconds, so the
+// modal is opened before the click is acknowledged.
+func (n *Notifier) openResummarizeModal(callback slack.InteractionCallback, value string) {
+	// Value format: repoName:issueNumber
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		n.logger.Error("Failed to parse re-summarize value", zap.String("value", value))
+		return
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		n.logger.Error("Failed to parse issue number", zap.String("value", value), zap.Error(err))
+		return
+	}
+
+	view, err := resummarizeModal(resummarizeTarget{
+		Repository: parts[0],
+		Number:     number,
+		Channel:    callback.Channel.ID,
+		ThreadTS:   callback.Message.Timestamp,
+	})
+	if err != nil {
+		n.logger.Error("Failed to build re-summarize modal", zap.Error(err))
+		return
+	}
+
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, err := n.slackClient().OpenViewContext(slackCtx, callback.TriggerID, view); err != nil {
+		n.logger.Error("Failed to open re-summarize modal", zap.Error(err))
+		n.metrics.RecordSlackError("open_view", apperrors.Classify(classifyError(err)))
+	}
+}
+
+// resummarizeModal builds the options modal for an issue
+func resummarizeModal(target resummarizeTarget) (slack.ModalViewRequest, error) {
+	metadata, err := json.Marshal(target)
+	if err != nil {
+		return slack.ModalViewRequest{}, err
+	}
+
+	styles := ai.ListPromptStyles()
+	sort.Strings(styles)
+	styleSelect := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic,
+		plainText("Current style"), resummarizeStyleBlock, options(styles)...)
+	styleInput := slack.NewInputBlock(resummarizeStyleBlock, plainText("Prompt style"), nil, styleSelect)
+	styleInput.Optional = true
+
+	detailSelect := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic,
+		plainText("The style's detail level"), resummarizeDetailBlock, options(ai.DetailLevels)...)
+	detailInput := slack.NewInputBlock(resummarizeDetailBlock, plainText("Detail level"), nil, detailSelect)
+	detailInput.Optional = true
+
+	languageInput := slack.NewInputBlock(resummarizeLanguageBlock, plainText("Language"),
+		plainText("Leave empty for English"),
+		slack.NewPlainTextInputBlockElement(plainText("e.g. Spanish"), resummarizeLanguageBlock))
+	languageInput.Optional = true
+
+	includeCode := slack.NewOptionBlockObject("include", plainText("Include related commits and code changes"), nil)
+	codeCheckbox := slack.NewCheckboxGroupsBlockElement(resummarizeCodeBlock, includeCode)
+	codeCheckbox.InitialOptions = []*slack.OptionBlockObject{includeCode}
+	codeInput := slack.NewInputBlock(resummarizeCodeBlock, plainText("Code context"), nil, codeCheckbox)
+	codeInput.Optional = true
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      resummarizeCallbackID,
+		Title:           plainText("Re-summarize"),
+		Submit:          plainText("Re-summarize"),
+		Close:           plainText("Cancel"),
+		PrivateMetadata: string(metadata),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("Summarize *%s#%d* again with different options. The result is posted in the thread.", target.Repository, target.Number),
+				false, false), nil, nil),
+			styleInput,
+			detailInput,
+			languageInput,
+			codeInput,
+		}},
+	}, nil
+}
+
+func plainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+}
+
+func options(values []string) []*slack.OptionBlockObject {
+	opts := make([]*slack.OptionBlockObject, len(values))
+	for i, value := range values {
+		opts[i] = slack.NewOptionBlockObject(value, plainText(value), nil)
+	}
+	return opts
+}
+
+// resummarizeOptions reads the options chosen in a submitted modal
+func resummarizeOptions(state *slack.ViewState) ai.ResummarizeOptions {
+	var options ai.ResummarizeOptions
+	if state == nil {
+		return options
+	}
+	value := func(block string) slack.BlockAction {
+		return state.Values[block][block]
+	}
+	options.Style = value(resummarizeStyleBlock).SelectedOption.Value
+	options.DetailLevel = value(resummarizeDetailBlock).SelectedOption.Value
+	options.Language = strings.TrimSpace(value(resummarizeLanguageBlock).Value)
+	for _, option := range value(resummarizeCodeBlock).SelectedOptions {
+		if option.Value == "include" {
+			options.CodeContext = true
+		}
+	}
+	return options
+}
+
+// handleViewSubmission handles submitted modals. The modal closes once the
+// submission is acknowledged, so the work runs in the background.
+func (n *Notifier) handleViewSubmission(callback slack.InteractionCallback) {
+	// Workflow step configurations are named by their step's callback ID
+	if callback.View.Type == slack.VTWorkflowStep {
+		go n.saveWorkflowStep(callback)
+		return
+	}
+
+	switch callback.View.CallbackID {
+	case resummarizeCallbackID:
+		var target resummarizeTarget
+		if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &target); err != nil {
+			n.logger.Error("Failed to parse re-summarize modal metadata", zap.Error(err))
+			return
+		}
+		go n.resummarize(target, resummarizeOptions(callback.View.State), callback.User.ID)
+	case createIssueCallbackID:
+		var source createIssueSource
+		if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &source); err != nil {
+			n.logger.Error("Failed to parse create issue modal metadata", zap.Error(err))
+			return
+		}
+		go n.createIssue(source, createIssueInput(callback.View.State), callback)
+	case moderateCallbackID:
+		if n.moderation != nil {
+			go n.submitModeration(callback)
+		}
+	default:
+		n.logger.Info("Unhandled Slack view submission", zap.String("callback_id", callback.View.CallbackID))
+	}
+}
+
+// resummarize summarizes an issue again with the chosen options and replies
+// in the thread of its message
+func (n *Notifier) resummarize(target resummarizeTarget, options ai.ResummarizeOptions, userID string) {
+	ctx := n.baseCtx
+	reply := func(text string) {
+		slackCtx, done := n.stageContext(ctx, monitor.StageNotify, n.slackTimeout)
+		defer done()
+		if err := n.PostThreadReply(slackCtx, target.Channel, target.ThreadTS, text); err != nil {
+			n.logger.Error("Failed to post re-summarize reply", zap.Error(err))
+		}
+	}
+
+	issueData, err := n.githubHandler.FetchEnrichedIssueData(ctx, target.Repository, target.Number)
+	if err != nil {
+		n.logger.Error("Failed to fetch issue data for resummarize", zap.Error(err))
+		reply(":warning: Could not fetch issue data to re-summarize.")
+		return
+	}
+	if n.issueMemory != nil {
+		issueData.Memory = n.issueMemory.IssueMemory(target.Repository, target.Number)
+	}
+
+	aiCtx, done := n.stageContext(ctx, monitor.StageSummarize, n.aiTimeout)
+	summary, err := n.summarizer.ResummarizeIssue(aiCtx, issueData, options)
+	done()
+	if err != nil {
+		n.logger.Error("AI summarizer failed for resummarize", zap.Error(err))
+		reply(":warning: AI could not re-summarize the issue.")
+		return
+	}
+
+	reply(resummaryText(summary, options, userID))
+	if n.issueMemory != nil {
+		n.issueMemory.RememberFollowUp(target.Repository, target.Number, "Re-summarize "+options.Describe(), summary.Summary)
+	}
+	n.logger.Info("Posted re-summarized issue to thread",
+		zap.String("repo", target.Repository),
+		zap.Int("number", target.Number),
+		zap.String("options", options.Describe()))
+}
+
+// resummaryText renders a re-summarized issue as a thread reply
+func resummaryText(summary *ai.IssueSummary, options ai.ResummarizeOptions, userID string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":arrows_counterclockwise: *Re-summarized for <@%s>* _(%s)_\n", userID, options.Describe())
+	fmt.Fprintf(&b, "*%s*\n", summary.Title)
+	fmt.Fprintf(&b, "*Priority:* %s · *Category:* %s\n\n", summary.Priority, summary.Category)
+	b.WriteString(utils.MarkdownToSlack(summary.Summary))
+	if len(summary.ActionItems) > 0 {
+		b.WriteString("\n\n*Action Items:*")
+		for _, item := range summary.ActionItems {
+			b.WriteString("\n• " + utils.MarkdownToSlack(item))
+		}
+	}
+	if options.CodeContext && summary.CodeContext != "" {
+		fmt.Fprintf(&b, "\n\n*Code Context:*\n%s", utils.MarkdownToSlack(summary.CodeContext))
+	}
+	return b.String()
+}
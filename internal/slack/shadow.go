@@ -0,0 +1,17 @@
+package slack
+
+import "net/http"
+
+// Shadow holds back the API calls that would change something, e.g. in
+// shadow mode, and answers them itself
+type Shadow interface {
+	SlackTransport(base http.RoundTripper) http.RoundTripper
+}
+
+// SetShadow sends Slack API calls through shadow, so nothing is posted
+func (n *Notifier) SetShadow(shadow Shadow) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.shadow = shadow
+	n.client = n.newClient()
+}
@@ -0,0 +1,240 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/apperrors"
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// Callback IDs of the "Create GitHub issue" message shortcut and its modal.
+// The shortcut must be registered with this callback ID in the Slack app.
+const (
+	IssueShortcutCallbackID = "create_github_issue"
+	createIssueCallbackID   = "create_issue"
+)
+
+// Block IDs of the create issue modal's inputs. Each input's action ID is its
+// block ID.
+const (
+	createIssueTitleBlock  = "title"
+	createIssueBodyBlock   = "body"
+	createIssueRepoBlock   = "repository"
+	createIssueLabelsBlock = "labels"
+)
+
+// Slack limits of the modal's inputs
+const (
+	maxIssueTitle = 250
+	maxIssueBody  = 3000
+)
+
+// IssueShortcutConfig lists what issues created from Slack messages may be
+// filed with
+type IssueShortcutConfig struct {
+	Repositories []string // owner/name repositories offered, the first preselected
+	Labels       []string // Labels offered
+}
+
+// Enabled reports whether the shortcut has repositories to file issues in
+func (c IssueShortcutConfig) Enabled() bool {
+	return len(c.Repositories) > 0
+}
+
+// Validate checks the repositories
+func (c IssueShortcutConfig) Validate() error {
+	for _, repo := range c.Repositories {
+		if parts := strings.Split(repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("repositories must be owner/name, got %q", repo)
+		}
+	}
+	return nil
+}
+
+// IssueProcessor runs the pipeline for an issue, as if its webhook had
+// arrived
+type IssueProcessor interface {
+	ProcessIssue(ctx context.Context, issueData *gh.IssueData)
+}
+
+// SetIssueShortcut enables the "Create GitHub issue" message shortcut, which
+// files a message as an issue and summarizes it with processor
+func (n *Notifier) SetIssueShortcut(config IssueShortcutConfig, processor IssueProcessor) {
+	n.issueShortcut = config
+	n.issueProcessor = processor
+}
+
+// createIssueSource is the message a create issue modal was opened for,
+// kept in the view's private metadata until it is submitted
+type createIssueSource struct {
+	Channel   string `json:"channel"`
+	MessageTS string `json:"message_ts"`
+}
+
+// openCreateIssueModal asks the user who ran the shortcut on a message how
+// to file it. Trigger IDs expire after three seconds, so the modal is opened
+// before the shortcut is acknowledged.
+func (n *Notifier) openCreateIssueModal(callback slack.InteractionCallback) {
+	if !n.issueShortcut.Enabled() || n.issueProcessor == nil {
+		n.logger.Info("Ignoring create issue shortcut, no repositories are configured")
+		return
+	}
+
+	view, err := createIssueModal(n.issueShortcut, createIssueSource{
+		Channel:   callback.Channel.ID,
+		MessageTS: callback.Message.Timestamp,
+	}, callback.Message.Text)
+	if err != nil {
+		n.logger.Error("Failed to build create issue modal", zap.Error(err))
+		return
+	}
+
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, err := n.slackClient().OpenViewContext(slackCtx, callback.TriggerID, view); err != nil {
+		n.logger.Error("Failed to open create issue modal", zap.Error(err))
+		n.metrics.RecordSlackError("open_view", apperrors.Classify(classifyError(err)))
+	}
+}
+
+// createIssueModal builds the modal prefilled with a message: its first line
+// as the title and the whole text as the body
+func createIssueModal(config IssueShortcutConfig, source createIssueSource, text string) (slack.ModalViewRequest, error) {
+	metadata, err := json.Marshal(source)
+	if err != nil {
+		return slack.ModalViewRequest{}, err
+	}
+
+	title, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	titleElement := slack.NewPlainTextInputBlockElement(plainText("Short summary of the problem"), createIssueTitleBlock)
+	titleElement.InitialValue = truncateRunes(title, maxIssueTitle)
+	titleElement.MaxLength = maxIssueTitle
+	titleInput := slack.NewInputBlock(createIssueTitleBlock, plainText("Title"), nil, titleElement)
+
+	bodyElement := slack.NewPlainTextInputBlockElement(plainText("What happened?"), createIssueBodyBlock)
+	bodyElement.InitialValue = truncateRunes(text, maxIssueBody)
+	bodyElement.Multiline = true
+	bodyElement.MaxLength = maxIssueBody
+	bodyInput := slack.NewInputBlock(createIssueBodyBlock, plainText("Description"), nil, bodyElement)
+	bodyInput.Optional = true
+
+	repos := options(config.Repositories)
+	repoSelect := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic,
+		plainText("Choose a repository"), createIssueRepoBlock, repos...)
+	repoSelect.InitialOption = repos[0]
+	repoInput := slack.NewInputBlock(createIssueRepoBlock, plainText("Repository"), nil, repoSelect)
+
+	blocks := []slack.Block{titleInput, bodyInput, repoInput}
+	if len(config.Labels) > 0 {
+		labelSelect := slack.NewOptionsMultiSelectBlockElement(slack.MultiOptTypeStatic,
+			plainText("Choose labels"), createIssueLabelsBlock, options(config.Labels)...)
+		labelInput := slack.NewInputBlock(createIssueLabelsBlock, plainText("Labels"), nil, labelSelect)
+		labelInput.Optional = true
+		blocks = append(blocks, labelInput)
+	}
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      createIssueCallbackID,
+		Title:           plainText("Create GitHub issue"),
+		Submit:          plainText("Create"),
+		Close:           plainText("Cancel"),
+		PrivateMetadata: string(metadata),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+	}, nil
+}
+
+// truncateRunes shortens text to at most limit characters
+func truncateRunes(text string, limit int) string {
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return text
+}
+
+// newIssue is an issue as entered in a submitted create issue modal
+type newIssue struct {
+	Title      string
+	Body       string
+	Repository string
+	Labels     []string
+}
+
+// createIssueInput reads the issue entered in a submitted modal
+func createIssueInput(state *slack.ViewState) newIssue {
+	var issue newIssue
+	if state == nil {
+		return issue
+	}
+	value := func(block string) slack.BlockAction {
+		return state.Values[block][block]
+	}
+	issue.Title = strings.TrimSpace(value(createIssueTitleBlock).Value)
+	issue.Body = strings.TrimSpace(value(createIssueBodyBlock).Value)
+	issue.Repository = value(createIssueRepoBlock).SelectedOption.Value
+	for _, option := range value(createIssueLabelsBlock).SelectedOptions {
+		issue.Labels = append(issue.Labels, option.Value)
+	}
+	return issue
+}
+
+// createIssue files an issue from a submitted modal, replies in the thread of
+// the message with its link, and runs the pipeline for it. The issue carries
+// a marker so its "opened" webhook is not processed a second time.
+func (n *Notifier) createIssue(source createIssueSource, issue newIssue, callback slack.InteractionCallback) {
+	ctx := n.baseCtx
+	receivedAt := time.Now()
+	reply := func(text string) {
+		slackCtx, done := n.stageContext(ctx, monitor.StageNotify, n.slackTimeout)
+		defer done()
+		if err := n.PostThreadReply(slackCtx, source.Channel, source.MessageTS, text); err != nil {
+			n.logger.Error("Failed to post create issue reply", zap.Error(err))
+		}
+	}
+
+	login, ok := n.authorizeAction(ctx, callback, source.Channel, "create", issue.Repository)
+	if !ok {
+		return
+	}
+	author := callback.User.Name
+	if login != "" {
+		author = "@" + login
+	}
+	var body strings.Builder
+	if issue.Body != "" {
+		body.WriteString(issue.Body + "\n\n")
+	}
+	fmt.Fprintf(&body, "_Created from Slack by %s._\n", author)
+	body.WriteString(gh.SlackMarker(source.Channel, source.MessageTS) + "\n")
+
+	created, repo, err := n.githubHandler.CreateIssue(ctx, issue.Repository, issue.Title, body.String(), issue.Labels)
+	if err != nil {
+		n.logger.Error("Failed to create issue from Slack message",
+			zap.String("repository", issue.Repository),
+			zap.Error(err))
+		reply(fmt.Sprintf(":warning: <@%s> could not create the issue in %s: %v", callback.User.ID, issue.Repository, err))
+		return
+	}
+	n.logger.Info("Created issue from Slack message",
+		zap.String("repository", repo.GetFullName()),
+		zap.Int("issue_number", created.GetNumber()),
+		zap.String("user_id", callback.User.ID))
+	reply(fmt.Sprintf(":memo: <@%s> filed this as <%s|%s#%d>.", callback.User.ID, created.GetHTMLURL(), repo.GetFullName(), created.GetNumber()))
+
+	n.issueProcessor.ProcessIssue(ctx, &gh.IssueData{
+		Issue:      created,
+		Repository: repo,
+		EventType:  "issues",
+		Action:     "opened",
+		Behavior:   gh.BehaviorSummarize,
+		ReceivedAt: receivedAt,
+	})
+}
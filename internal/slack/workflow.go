@@ -0,0 +1,286 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/monitor"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// Callback IDs of the Workflow Builder steps. Each step must be added under
+// Workflow Steps in the Slack app with this callback ID.
+const (
+	SummarizeStepCallbackID = "summarize_issue"
+	FixStepCallbackID       = "suggest_fix"
+)
+
+// WorkflowStepScopes are the bot scopes running workflow steps needs
+var WorkflowStepScopes = []string{"workflow.steps:execute"}
+
+// workflowIssueInput is the step input naming the issue, and the block and
+// action ID of its field in the configuration modal
+const workflowIssueInput = "issue"
+
+// maxEventBody bounds the Events API and interaction requests read
+const maxEventBody = 1 << 20
+
+// workflowStepOutputs are the values each step hands to the steps after it
+var workflowStepOutputs = map[string][]slack.WorkflowStepOutput{
+	SummarizeStepCallbackID: {
+		{Name: "title", Type: "text", Label: "Issue title"},
+		{Name: "url", Type: "text", Label: "Issue link"},
+		{Name: "summary", Type: "text", Label: "Summary"},
+		{Name: "priority", Type: "text", Label: "Priority"},
+		{Name: "category", Type: "text", Label: "Category"},
+	},
+	FixStepCallbackID: {
+		{Name: "title", Type: "text", Label: "Issue title"},
+		{Name: "url", Type: "text", Label: "Issue link"},
+		{Name: "fix", Type: "text", Label: "Suggested fix"},
+	},
+}
+
+// openWorkflowStepModal asks the person adding or editing one of the steps in
+// Workflow Builder which issue it runs on. Trigger IDs expire after three
+// seconds, so the modal is opened before the interaction is acknowledged.
+func (n *Notifier) openWorkflowStepModal(callback slack.InteractionCallback) {
+	if _, ok := workflowStepOutputs[callback.CallbackID]; !ok {
+		n.logger.Info("Unhandled Slack workflow step", zap.String("callback_id", callback.CallbackID))
+		return
+	}
+
+	view := workflowStepModal(callback.CallbackID, callback.WorkflowStep.Inputs)
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if _, err := n.slackClient().OpenViewContext(slackCtx, callback.TriggerID, view); err != nil {
+		n.logger.Error("Failed to open workflow step modal", zap.Error(err))
+		n.metrics.RecordSlackError("open_view", apperrors.Classify(classifyError(err)))
+	}
+}
+
+// workflowStepModal builds the configuration modal of a step, prefilled with
+// the issue it was last saved with. Workflow Builder lets the field take
+// variables, such as a link submitted in an earlier form.
+func workflowStepModal(callbackID string, inputs *slack.WorkflowStepInputs) slack.ModalViewRequest {
+	issueElement := slack.NewPlainTextInputBlockElement(plainText("e.g. acme/api#42 or a link to the issue"), workflowIssueInput)
+	if inputs != nil {
+		issueElement.InitialValue = (*inputs)[workflowIssueInput].Value
+	}
+	issueInput := slack.NewInputBlock(workflowIssueInput, plainText("GitHub issue"), nil, issueElement)
+	issueInput.Hint = plainText("Insert a variable to use a link or reference from an earlier step")
+
+	request := slack.NewConfigurationModalRequest(slack.Blocks{BlockSet: []slack.Block{issueInput}}, "", "")
+	request.CallbackID = callbackID
+	return request.ModalViewRequest
+}
+
+// saveWorkflowStep saves the issue entered in a step's configuration modal,
+// along with the outputs later steps can use
+func (n *Notifier) saveWorkflowStep(callback slack.InteractionCallback) {
+	outputs, ok := workflowStepOutputs[callback.View.CallbackID]
+	if !ok {
+		n.logger.Info("Unhandled Slack workflow step", zap.String("callback_id", callback.View.CallbackID))
+		return
+	}
+	var issue string
+	if callback.View.State != nil {
+		issue = strings.TrimSpace(callback.View.State.Values[workflowIssueInput][workflowIssueInput].Value)
+	}
+	inputs := slack.WorkflowStepInputs{workflowIssueInput: {Value: issue}}
+
+	slackCtx, done := n.stageContext(n.baseCtx, monitor.StageNotify, n.slackTimeout)
+	defer done()
+	if err := n.slackClient().SaveWorkflowStepConfigurationContext(slackCtx, callback.WorkflowStep.WorkflowStepEditID, &inputs, &outputs); err != nil {
+		n.logger.Error("Failed to save workflow step", zap.String("callback_id", callback.View.CallbackID), zap.Error(err))
+		n.metrics.RecordSlackError("workflow_step", apperrors.Classify(classifyError(err)))
+	}
+}
+
+// HandleEvent handles Slack Events API requests. Workflow Builder runs the
+// steps through workflow_step_execute events; Slack retries an event that is
+// not acknowledged within three seconds, so each step runs in the background
+// and reports its outputs, or why it failed, when it is done.
+func (n *Notifier) HandleEvent(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBody))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := n.verifyRequest(r.Header, body); err != nil {
+		n.logger.Warn("Rejected Slack event with an invalid signature", zap.Error(err))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// Events that cannot be parsed are still acknowledged, as Slack would
+	// only retry them
+	event, err := slackevents.ParseEvent(body, slackevents.OptionNoVerifyToken())
+	if err != nil {
+		n.logger.Warn("Failed to parse Slack event", zap.Error(err))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch event.Type {
+	case slackevents.URLVerification:
+		// Slack checks the request URL when it is saved in the app settings
+		var verification slackevents.EventsAPIURLVerificationEvent
+		if err := json.Unmarshal(body, &verification); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, verification.Challenge)
+		return
+	case slackevents.CallbackEvent:
+		if step, ok := event.InnerEvent.Data.(*slackevents.WorkflowStepExecuteEvent); ok {
+			go n.runWorkflowStep(*step)
+		} else {
+			n.logger.Info("Unhandled Slack event", zap.String("type", event.InnerEvent.Type))
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verifyRequest checks a request's signature against the signing secret
+func (n *Notifier) verifyRequest(header http.Header, body []byte) error {
+	n.mu.RLock()
+	secret := n.signingSecret
+	n.mu.RUnlock()
+
+	verifier, err := slack.NewSecretsVerifier(header, secret)
+	if err != nil {
+		return err
+	}
+	if _, err := verifier.Write(body); err != nil {
+		return err
+	}
+	return verifier.Ensure()
+}
+
+// runWorkflowStep runs a step on the issue it was configured with and
+// completes it with its outputs. Failures are reported to Workflow Builder,
+// which shows them to the workflow's owner.
+func (n *Notifier) runWorkflowStep(step slackevents.WorkflowStepExecuteEvent) {
+	id := step.WorkflowStep.WorkflowStepExecuteID
+	outputs, err := n.workflowStepResult(step.CallbackID, step.WorkflowStep.Inputs)
+	if err != nil {
+		n.logger.Warn("Workflow step failed", zap.String("callback_id", step.CallbackID), zap.Error(err))
+		if err := n.slackClient().WorkflowStepFailed(id, err.Error()); err != nil {
+			n.logger.Error("Failed to report workflow step failure", zap.Error(err))
+			n.metrics.RecordSlackError("workflow_step", apperrors.Classify(classifyError(err)))
+		}
+		return
+	}
+
+	if err := n.slackClient().WorkflowStepCompleted(id, slack.WorkflowStepCompletedRequestOptionOutput(outputs)); err != nil {
+		n.logger.Error("Failed to complete workflow step", zap.Error(err))
+		n.metrics.RecordSlackError("workflow_step", apperrors.Classify(classifyError(err)))
+		return
+	}
+	n.logger.Info("Completed workflow step",
+		zap.String("callback_id", step.CallbackID),
+		zap.String("workflow_id", step.WorkflowStep.WorkflowID))
+}
+
+// workflowStepResult runs a step and returns its outputs. Errors are worded
+// for the workflow's owner, who may not be an engineer.
+func (n *Notifier) workflowStepResult(callbackID string, inputs *slack.WorkflowStepInputs) (map[string]string, error) {
+	ref, err := workflowIssue(inputs)
+	if err != nil {
+		return nil, err
+	}
+	outputs := map[string]string{"url": fmt.Sprintf("https://github.com/%s/issues/%d", ref.Repository, ref.Number)}
+
+	switch callbackID {
+	case SummarizeStepCallbackID:
+		// The summary already posted for the issue is reused, so the step
+		// agrees with the channel and costs nothing
+		if n.explainer != nil {
+			if summary, ok := n.explainer.ExplainIssue(ref.Repository, ref.Number); ok {
+				addSummaryOutputs(outputs, summary)
+				return outputs, nil
+			}
+		}
+		issueData, err := n.workflowIssueData(ref)
+		if err != nil {
+			return nil, err
+		}
+		aiCtx, done := n.stageContext(n.baseCtx, monitor.StageSummarize, n.aiTimeout)
+		summary, err := n.summarizer.SummarizeIssue(aiCtx, issueData)
+		done()
+		if err != nil {
+			n.logger.Error("AI summarizer failed for workflow step", zap.Error(err))
+			return nil, fmt.Errorf("the AI could not summarize %s, try again later", ref)
+		}
+		addSummaryOutputs(outputs, summary)
+		outputs["title"] = issueData.Issue.GetTitle()
+	case FixStepCallbackID:
+		issueData, err := n.workflowIssueData(ref)
+		if err != nil {
+			return nil, err
+		}
+		aiCtx, done := n.stageContext(n.baseCtx, monitor.StageSummarize, n.aiTimeout)
+		fix, err := n.summarizer.StreamSuggestedFix(aiCtx, issueData, nil)
+		done()
+		if err != nil {
+			n.logger.Error("AI summarizer failed for workflow step", zap.Error(err))
+			return nil, fmt.Errorf("the AI could not suggest a fix for %s, try again later", ref)
+		}
+		outputs["title"] = issueData.Issue.GetTitle()
+		outputs["fix"] = utils.MarkdownToSlack(strings.TrimSpace(fix))
+		if n.issueMemory != nil {
+			n.issueMemory.RememberFollowUp(ref.Repository, ref.Number, "Suggest a fix", fix)
+		}
+	default:
+		return nil, fmt.Errorf("unknown step %q", callbackID)
+	}
+	return outputs, nil
+}
+
+// workflowIssueData fetches the issue a step runs on, with its earlier
+// analyses
+func (n *Notifier) workflowIssueData(ref gh.IssueReference) (*gh.IssueData, error) {
+	issueData, err := n.githubHandler.FetchEnrichedIssueData(n.baseCtx, ref.Repository, ref.Number)
+	if err != nil {
+		n.logger.Error("Failed to fetch issue data for workflow step", zap.String("issue", ref.String()), zap.Error(err))
+		return nil, fmt.Errorf("could not fetch %s from GitHub", ref)
+	}
+	if n.issueMemory != nil {
+		issueData.Memory = n.issueMemory.IssueMemory(ref.Repository, ref.Number)
+	}
+	return issueData, nil
+}
+
+// addSummaryOutputs adds a summary's fields to a step's outputs
+func addSummaryOutputs(outputs map[string]string, summary *ai.IssueSummary) {
+	outputs["title"] = summary.Title
+	outputs["summary"] = utils.MarkdownToSlack(summary.Summary)
+	outputs["priority"] = summary.Priority
+	outputs["category"] = summary.Category
+}
+
+// workflowIssue reads the issue a step runs on from its input, e.g.
+// acme/api#42 or a link to the issue
+func workflowIssue(inputs *slack.WorkflowStepInputs) (gh.IssueReference, error) {
+	var value string
+	if inputs != nil {
+		value = strings.TrimSpace((*inputs)[workflowIssueInput].Value)
+	}
+	refs := gh.IssueReferences("", value)
+	if len(refs) == 0 {
+		return gh.IssueReference{}, fmt.Errorf("%q is not a GitHub issue, use owner/repo#number or a link to the issue", value)
+	}
+	return refs[0], nil
+}
@@ -0,0 +1,12 @@
+package store_test
+
+import (
+	"testing"
+
+	"github-issue-ai-bot/internal/store"
+	"github-issue-ai-bot/internal/store/storetest"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storetest.Run(t, func() store.Store { return store.NewMemoryStore() })
+}
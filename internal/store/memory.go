@@ -0,0 +1,375 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/identity"
+	"github-issue-ai-bot/internal/memory"
+	"github-issue-ai-bot/internal/shadow"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// IssueRecord is what the bot remembers about an issue it has notified about
+type IssueRecord struct {
+	Repository string
+	Number     int
+	Title      string
+	Body       string
+	State      string
+	Source     string // Where an issue not on GitHub was reported, e.g. zendesk; empty for GitHub issues
+
+	Environment github.Environment // OS, browser and versions the reporter mentioned
+
+	Summary    *ai.IssueSummary // Last AI summary, nil if the AI was skipped
+	SkipReason string           // Why the AI was skipped, if it was
+	Degraded   bool             // The AI failed, so the raw issue was posted and SkipReason says why
+	Channel    string           // Slack channel ID the summary was posted to
+	MessageTS  string           // Timestamp of the posted Slack message
+	Layout     string           // Slack layout used for the message
+	OnCall     string           // Slack user ID mentioned as on call
+	Language   string           // Detected language of the issue, ISO 639-1
+	Memory     memory.Memory    // Rolling history of analyses and follow-ups
+	Labels     []string         // GitHub labels when the summary was last posted or refreshed
+	Overridden []string         // Summary fields a human has since changed with labels, e.g. priority
+	AnalyzedAt time.Time        // When the AI last generated the summary
+	Reaction   string           // Reaction the bot left on the GitHub issue, e.g. "eyes" once it was posted
+
+	// CIFailure is the digest of the failed CI run that was posted as a build
+	// break instead of a summary, nil for other issues
+	CIFailure *github.CIFailure
+
+	OpenedAt       time.Time // When the issue was opened on GitHub
+	AcknowledgedAt time.Time // When a maintainer first responded, zero until one has
+	ClosedAt       time.Time // When the issue was closed on GitHub, zero while open
+	CloseReason    string    // GitHub's state reason for a closed issue, e.g. completed or not_planned
+
+	IncidentChannel  string // Slack channel opened for the issue as an incident
+	IncidentArchived bool   // Whether the incident channel has been archived
+
+	// TransferredFrom is the issue's old repository and number, e.g.
+	// acme/api#12, from its transfer until it is next processed
+	TransferredFrom string
+
+	// ImportedAt is when the record was imported from the issue's history,
+	// without a summary; zero for issues the bot processed
+	ImportedAt time.Time
+
+	// InfoRequestedAt is when the bot asked the reporter for details the
+	// report is missing, zero if it never has. AwaitingInfo is set from then
+	// until the reporter responds, and mutes notifications meanwhile.
+	InfoRequestedAt time.Time
+	AwaitingInfo    bool
+
+	// Route is the routing rule the issue last matched. PostedAt is when its
+	// Slack message was posted; EngagedAt is when someone first clicked,
+	// reacted or replied to it, or a maintainer responded on GitHub; and
+	// EscalatedAt is when the bot escalated it because nobody had. Each is
+	// zero until then.
+	Route       string
+	PostedAt    time.Time
+	EngagedAt   time.Time
+	EscalatedAt time.Time
+
+	// PreviewedAt is when the summary was sent to a moderator to approve
+	// before it was posted, zero if it never was. AwaitingApproval is set
+	// until the moderator approves or discards it, and ApprovedBy is the Slack
+	// user who approved it.
+	PreviewedAt      time.Time
+	AwaitingApproval bool
+	ApprovedBy       string
+
+	// TriagedAt is when someone acknowledged the issue from a team digest,
+	// and TriagedBy the Slack user who did. SnoozedUntil keeps the issue out
+	// of digests and escalations until then.
+	TriagedAt    time.Time
+	TriagedBy    string
+	SnoozedUntil time.Time
+
+	// AssignedAt is when the issue was first assigned on GitHub and
+	// AssignedBy the GitHub user who assigned it; ClosedBy is the GitHub user
+	// who closed it. Together with TriagedBy they credit triagers on the
+	// leaderboard.
+	AssignedAt time.Time
+	AssignedBy string
+	ClosedBy   string
+
+	UpdatedAt time.Time
+}
+
+// shadowCapacity is how many held back shadow mode actions MemoryStore
+// keeps before dropping the oldest
+const shadowCapacity = 5000
+
+// MemoryStore keeps issue records in process memory. Records are lost on
+// restart and are not shared between replicas.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	issues     map[string]IssueRecord
+	cursors    map[string]time.Time // Poll cursors by lowercased repository
+	styles     map[string]ai.PromptStyle
+	identities []identity.Identity
+	shadow     []shadow.Action // Oldest first
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		issues:  make(map[string]IssueRecord),
+		cursors: make(map[string]time.Time),
+		styles:  make(map[string]ai.PromptStyle),
+	}
+}
+
+// GetIssue returns a copy of the stored record for an issue
+func (s *MemoryStore) GetIssue(repository string, number int) (*IssueRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.issues[issueKey(repository, number)]
+	if !ok {
+		return nil, false
+	}
+	return &record, true
+}
+
+// SaveIssue stores a migrated copy of the record, replacing any previous
+// version
+func (s *MemoryStore) SaveIssue(record *IssueRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := MigrateRecord(*record)
+	if saved.UpdatedAt.IsZero() {
+		saved.UpdatedAt = time.Now()
+	}
+	s.issues[issueKey(record.Repository, record.Number)] = saved
+}
+
+// MoveIssue re-keys an issue's record, replacing any record at the
+// destination
+func (s *MemoryStore) MoveIssue(repository string, number int, toRepository string, toNumber int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := issueKey(repository, number)
+	record, ok := s.issues[key]
+	if !ok {
+		return false
+	}
+	delete(s.issues, key)
+	record.Repository, record.Number = toRepository, toNumber
+	record.UpdatedAt = time.Now()
+	s.issues[issueKey(toRepository, toNumber)] = record
+	return true
+}
+
+// RenameRepository re-keys the records of a repository's issues
+func (s *MemoryStore) RenameRepository(repository, toRepository string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var moved []IssueRecord
+	for key, record := range s.issues {
+		if strings.EqualFold(record.Repository, repository) {
+			moved = append(moved, record)
+			delete(s.issues, key)
+		}
+	}
+	for _, record := range moved {
+		record.Repository = toRepository
+		s.issues[issueKey(toRepository, record.Number)] = record
+	}
+	return len(moved)
+}
+
+// PollCursor returns how far a polled repository has been read
+func (s *MemoryStore) PollCursor(repository string) (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cursor, ok := s.cursors[strings.ToLower(repository)]
+	return cursor, ok
+}
+
+// SavePollCursor stores how far a polled repository has been read
+func (s *MemoryStore) SavePollCursor(repository string, cursor time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cursors[strings.ToLower(repository)] = cursor
+}
+
+// PromptStyles returns the custom prompt styles by name
+func (s *MemoryStore) PromptStyles() map[string]ai.PromptStyle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	styles := make(map[string]ai.PromptStyle, len(s.styles))
+	for name, style := range s.styles {
+		styles[name] = style
+	}
+	return styles
+}
+
+// SavePromptStyle stores a custom prompt style, replacing any of the same name
+func (s *MemoryStore) SavePromptStyle(name string, style ai.PromptStyle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.styles[name] = style
+}
+
+// DeletePromptStyle removes a custom prompt style
+func (s *MemoryStore) DeletePromptStyle(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.styles, name)
+}
+
+// Identities returns the identities mapped through the API
+func (s *MemoryStore) Identities() []identity.Identity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]identity.Identity(nil), s.identities...)
+}
+
+// SaveIdentities replaces the identities mapped through the API
+func (s *MemoryStore) SaveIdentities(identities []identity.Identity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.identities = append([]identity.Identity(nil), identities...)
+}
+
+// AppendShadowAction stores an action held back in shadow mode, dropping
+// the oldest once the store holds shadowCapacity
+func (s *MemoryStore) AppendShadowAction(action shadow.Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shadow = append(s.shadow, action)
+	if len(s.shadow) > shadowCapacity {
+		s.shadow = append(s.shadow[:0], s.shadow[len(s.shadow)-shadowCapacity:]...)
+	}
+}
+
+// ListShadowActions returns the held back actions matching the query,
+// oldest first
+func (s *MemoryStore) ListShadowActions(query shadow.Query) []shadow.Action {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var actions []shadow.Action
+	for i := len(s.shadow) - 1; i >= 0; i-- {
+		if query.Limit > 0 && len(actions) == query.Limit {
+			break
+		}
+		if query.Matches(s.shadow[i]) {
+			actions = append(actions, s.shadow[i])
+		}
+	}
+	for i, j := 0, len(actions)-1; i < j; i, j = i+1, j-1 {
+		actions[i], actions[j] = actions[j], actions[i]
+	}
+	return actions
+}
+
+// Query selects issue records to list
+type Query struct {
+	Repository string    // Case-insensitive, empty for every repository
+	Priorities []string  // Summary priorities, empty for any
+	Categories []string  // Summary categories, empty for any
+	Text       string    // Words that must all appear in the title or summary, empty for any
+	Since      time.Time // Earliest UpdatedAt, zero for no bound
+	Until      time.Time // Latest UpdatedAt, zero for no bound
+	After      string    // Cursor returned with the previous page
+	Limit      int       // Page size, zero or less for no limit
+
+	// Environment the issue was reported in, matched by
+	// github.Environment.Matches; empty fields match any
+	Environment github.Environment
+}
+
+// ListIssues returns copies of the records matching the query in a stable
+// order, and a cursor for the next page if there are more
+func (s *MemoryStore) ListIssues(query Query) ([]IssueRecord, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.issues))
+	for key := range s.issues {
+		if key > query.After {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var records []IssueRecord
+	for i, key := range keys {
+		record := s.issues[key]
+		if !query.matches(record) {
+			continue
+		}
+		if query.Limit > 0 && len(records) == query.Limit {
+			return records, keys[i-1]
+		}
+		records = append(records, record)
+	}
+	return records, ""
+}
+
+func (q Query) matches(record IssueRecord) bool {
+	if q.Repository != "" && !strings.EqualFold(q.Repository, record.Repository) {
+		return false
+	}
+	if !q.Since.IsZero() && record.UpdatedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && record.UpdatedAt.After(q.Until) {
+		return false
+	}
+	if len(q.Priorities) > 0 && (record.Summary == nil || !utils.ContainsFold(q.Priorities, record.Summary.Priority)) {
+		return false
+	}
+	if len(q.Categories) > 0 && (record.Summary == nil || !utils.ContainsFold(q.Categories, record.Summary.Category)) {
+		return false
+	}
+	if !record.Environment.Matches(q.Environment) {
+		return false
+	}
+	if q.Text != "" {
+		text := searchText(record)
+		for _, word := range strings.Fields(strings.ToLower(q.Text)) {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// searchText is the lowercased text a record is searched by: its title and
+// the summary's title, text, components and action items
+func searchText(record IssueRecord) string {
+	parts := []string{record.Title}
+	if summary := record.Summary; summary != nil {
+		parts = append(parts, summary.Title, summary.Summary, summary.Category)
+		parts = append(parts, summary.Components...)
+		parts = append(parts, summary.ActionItems...)
+	}
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
+
+func issueKey(repository string, number int) string {
+	return fmt.Sprintf("%s#%d", strings.ToLower(repository), number)
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/shadow"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+
+	if _, ok := s.GetIssue("owner/repo", 1); ok {
+		t.Fatal("Expected empty store")
+	}
+
+	record := &IssueRecord{
+		Repository: "Owner/Repo",
+		Number:     1,
+		Title:      "Crash on start",
+		Summary:    &ai.IssueSummary{Priority: "high"},
+		Channel:    "C123",
+		MessageTS:  "1700000000.000100",
+	}
+	s.SaveIssue(record)
+
+	got, ok := s.GetIssue("owner/repo", 1)
+	if !ok {
+		t.Fatal("Expected stored record")
+	}
+	if got.MessageTS != record.MessageTS || got.Summary.Priority != "high" || got.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected record %+v", got)
+	}
+
+	// Changing the returned copy must not change the stored record
+	got.Title = "changed"
+	if again, _ := s.GetIssue("owner/repo", 1); again.Title != "Crash on start" {
+		t.Errorf("Expected stored title to be unchanged, got %q", again.Title)
+	}
+}
+
+func TestListIssues(t *testing.T) {
+	s := NewMemoryStore()
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, priority := range []string{"high", "low", "high", "medium", "high"} {
+		s.SaveIssue(&IssueRecord{
+			Repository: "owner/repo",
+			Number:     i + 1,
+			Summary:    &ai.IssueSummary{Priority: priority},
+			UpdatedAt:  day.AddDate(0, 0, i),
+		})
+	}
+	s.SaveIssue(&IssueRecord{Repository: "other/repo", Number: 1, SkipReason: "trivial", UpdatedAt: day})
+
+	// Pages follow on from the cursor without repeats
+	var numbers []int
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		records, next := s.ListIssues(Query{Repository: "Owner/Repo", Priorities: []string{"HIGH"}, After: cursor, Limit: 2})
+		for _, record := range records {
+			numbers = append(numbers, record.Number)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(numbers) != 3 || numbers[0] != 1 || numbers[1] != 3 || numbers[2] != 5 {
+		t.Errorf("Expected high-priority issues 1, 3 and 5, got %v", numbers)
+	}
+
+	records, next := s.ListIssues(Query{Since: day.AddDate(0, 0, 1), Until: day.AddDate(0, 0, 3)})
+	if len(records) != 3 || next != "" {
+		t.Errorf("Expected 3 records in the date range, got %d (next %q)", len(records), next)
+	}
+
+	if records, _ := s.ListIssues(Query{}); len(records) != 6 {
+		t.Errorf("Expected all 6 records, got %d", len(records))
+	}
+}
+
+func TestListIssuesSearch(t *testing.T) {
+	s := NewMemoryStore()
+	s.SaveIssue(&IssueRecord{Repository: "owner/repo", Number: 1, Title: "Login fails",
+		Summary: &ai.IssueSummary{Priority: "high", Category: "bug", Summary: "OAuth tokens expire early", Components: []string{"auth"}}})
+	s.SaveIssue(&IssueRecord{Repository: "owner/repo", Number: 2, Title: "Add dark mode",
+		Summary: &ai.IssueSummary{Priority: "low", Category: "feature", Summary: "Theme support"}})
+	s.SaveIssue(&IssueRecord{Repository: "owner/repo", Number: 3, Title: "Auth docs are outdated", SkipReason: "trivial"})
+
+	tests := []struct {
+		query Query
+		want  []int
+	}{
+		{Query{Text: "AUTH"}, []int{1, 3}},
+		{Query{Text: "oauth expire"}, []int{1}},
+		{Query{Text: "oauth theme"}, nil},
+		{Query{Categories: []string{"Feature"}}, []int{2}},
+		{Query{Text: "auth", Categories: []string{"bug"}}, []int{1}},
+	}
+	for _, tt := range tests {
+		records, _ := s.ListIssues(tt.query)
+		var numbers []int
+		for _, record := range records {
+			numbers = append(numbers, record.Number)
+		}
+		if !reflect.DeepEqual(numbers, tt.want) {
+			t.Errorf("ListIssues(%+v) = %v, want %v", tt.query, numbers, tt.want)
+		}
+	}
+}
+
+func TestPollCursor(t *testing.T) {
+	s := NewMemoryStore()
+	if _, ok := s.PollCursor("owner/repo"); ok {
+		t.Fatal("Expected no cursor before the first poll")
+	}
+
+	cursor := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.SavePollCursor("Owner/Repo", cursor)
+	if got, ok := s.PollCursor("owner/repo"); !ok || !got.Equal(cursor) {
+		t.Errorf("Expected cursor %v, got %v, %v", cursor, got, ok)
+	}
+}
+
+func TestShadowActions(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < shadowCapacity+10; i++ {
+		s.AppendShadowAction(shadow.Action{ID: int64(i), Service: shadow.ServiceSlack})
+	}
+	s.AppendShadowAction(shadow.Action{ID: -1, Service: shadow.ServiceGitHub, Repository: "Owner/Repo"})
+
+	actions := s.ListShadowActions(shadow.Query{Service: shadow.ServiceSlack})
+	if len(actions) != shadowCapacity-1 || actions[0].ID != 11 {
+		t.Errorf("Expected the oldest actions dropped, got %d starting at %d", len(actions), actions[0].ID)
+	}
+	actions = s.ListShadowActions(shadow.Query{Limit: 2})
+	if len(actions) != 2 || actions[0].ID != shadowCapacity+9 || actions[1].ID != -1 {
+		t.Errorf("Expected the two most recent actions oldest first, got %+v", actions)
+	}
+	if actions := s.ListShadowActions(shadow.Query{Repository: "owner/repo"}); len(actions) != 1 {
+		t.Errorf("Expected the repository's action, got %+v", actions)
+	}
+}
@@ -0,0 +1,31 @@
+package store
+
+import "github-issue-ai-bot/internal/ai"
+
+// Store keeps the last summary and Slack message of each issue.
+// MemoryStore is the reference implementation, used in tests; other backends
+// must pass the same storetest suite.
+type Store interface {
+	GetIssue(repository string, number int) (*IssueRecord, bool)
+	SaveIssue(record *IssueRecord)
+	ListIssues(query Query) ([]IssueRecord, string)
+
+	// MoveIssue re-keys an issue's record after it was transferred to
+	// another repository, replacing any record at the destination. It reports
+	// whether there was a record to move.
+	MoveIssue(repository string, number int, toRepository string, toNumber int) bool
+	// RenameRepository re-keys the records of a renamed repository's issues
+	// and returns how many moved
+	RenameRepository(repository, toRepository string) int
+}
+
+// MigrateRecord returns a record whose summary is upgraded to the current
+// ai.SummarySchemaVersion. Backends apply it to the records they save and
+// load, so summaries stored by older versions of the bot read like new ones.
+func MigrateRecord(record IssueRecord) IssueRecord {
+	if record.Summary != nil && record.Summary.SchemaVersion < ai.SummarySchemaVersion {
+		migrated := ai.MigrateSummary(*record.Summary)
+		record.Summary = &migrated
+	}
+	return record
+}
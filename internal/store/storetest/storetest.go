@@ -0,0 +1,98 @@
+// Package storetest checks that a store.Store backend behaves like the
+// in-memory reference implementation
+package storetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// Run tests a backend. newStore must return an empty store on every call.
+func Run(t *testing.T, newStore func() store.Store) {
+	t.Run("Summaries", func(t *testing.T) { testSummaries(t, newStore()) })
+}
+
+func testSummaries(t *testing.T, s store.Store) {
+	if _, ok := s.GetIssue("acme/api", 1); ok {
+		t.Fatal("Expected an empty store")
+	}
+
+	s.SaveIssue(&store.IssueRecord{Repository: "Acme/API", Number: 1, Title: "Crash", Summary: &ai.IssueSummary{Priority: "high"}})
+	s.SaveIssue(&store.IssueRecord{Repository: "acme/api", Number: 2, Title: "Typo", Summary: &ai.IssueSummary{Priority: "low"}})
+	s.SaveIssue(&store.IssueRecord{Repository: "acme/web", Number: 1, Title: "Slow", Environment: github.Environment{OS: "Windows 11", Version: "2.3.1"}})
+
+	record, ok := s.GetIssue("acme/api", 1)
+	if !ok || record.Title != "Crash" || record.UpdatedAt.IsZero() {
+		t.Fatalf("Expected the record looked up case-insensitively with UpdatedAt set, got %+v", record)
+	}
+	record.Title = "changed"
+	if again, _ := s.GetIssue("acme/api", 1); again.Title != "Crash" {
+		t.Error("Expected GetIssue to return a copy")
+	}
+
+	s.SaveIssue(&store.IssueRecord{Repository: "acme/api", Number: 1, Title: "Crash on start", Summary: &ai.IssueSummary{Priority: "high"}})
+	if again, _ := s.GetIssue("acme/api", 1); again.Title != "Crash on start" {
+		t.Errorf("Expected SaveIssue to replace the record, got %q", again.Title)
+	}
+
+	records, _ := s.ListIssues(store.Query{Repository: "ACME/api"})
+	if len(records) != 2 {
+		t.Errorf("Expected the repository's 2 records, got %d", len(records))
+	}
+	records, _ = s.ListIssues(store.Query{Priorities: []string{"HIGH"}})
+	if len(records) != 1 || records[0].Number != 1 {
+		t.Errorf("Expected the high-priority record, got %+v", records)
+	}
+	records, _ = s.ListIssues(store.Query{Environment: github.Environment{OS: "windows", Version: "2.3"}})
+	if len(records) != 1 || records[0].Repository != "acme/web" {
+		t.Errorf("Expected the record reported on Windows with 2.3, got %+v", records)
+	}
+
+	// Paging returns every record once
+	seen := make(map[string]bool)
+	query := store.Query{Limit: 2}
+	for page := 0; page < 3; page++ {
+		records, next := s.ListIssues(query)
+		for _, record := range records {
+			key := fmt.Sprintf("%s#%d", record.Repository, record.Number)
+			if seen[key] {
+				t.Errorf("Expected %s listed once", key)
+			}
+			seen[key] = true
+		}
+		if next == "" {
+			break
+		}
+		query.After = next
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected 3 records over the pages, got %d", len(seen))
+	}
+
+	// Summaries stored before versioning are migrated
+	s.SaveIssue(&store.IssueRecord{Repository: "acme/web", Number: 9, Title: "Old", Summary: &ai.IssueSummary{Priority: "low"}})
+	if record, _ := s.GetIssue("acme/web", 9); record.Summary.SchemaVersion != ai.SummarySchemaVersion || record.Summary.Reasoning == nil {
+		t.Errorf("Expected the summary migrated to the current schema, got %+v", record.Summary)
+	}
+
+	// Transfers and renames re-key records
+	if !s.MoveIssue("acme/web", 1, "Acme/API", 3) || s.MoveIssue("acme/web", 1, "acme/api", 3) {
+		t.Error("Expected the record moved once")
+	}
+	if record, ok := s.GetIssue("acme/api", 3); !ok || record.Title != "Slow" || record.Repository != "Acme/API" || record.Number != 3 {
+		t.Errorf("Expected the moved record at its new number, got %+v", record)
+	}
+	if moved := s.RenameRepository("ACME/api", "acme/backend"); moved != 3 {
+		t.Errorf("Expected 3 records renamed, got %d", moved)
+	}
+	if _, ok := s.GetIssue("acme/api", 1); ok {
+		t.Error("Expected no record under the old name")
+	}
+	if record, ok := s.GetIssue("acme/backend", 2); !ok || record.Repository != "acme/backend" || record.Title != "Typo" {
+		t.Errorf("Expected the record under the new name, got %+v", record)
+	}
+}
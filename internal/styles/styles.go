@@ -0,0 +1,159 @@
+package styles
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/ai"
+)
+
+// maxRequestBytes bounds the body of a style request
+const maxRequestBytes = 64 << 10
+
+// Store persists custom prompt styles
+type Store interface {
+	PromptStyles() map[string]ai.PromptStyle
+	SavePromptStyle(name string, style ai.PromptStyle)
+	DeletePromptStyle(name string)
+}
+
+// Request creates or updates a custom prompt style
+type Request struct {
+	Name string `json:"name"` // Required when creating; taken from the path when updating
+	ai.PromptStyle
+}
+
+// Handler creates, updates and deletes custom prompt styles, so teams can
+// iterate on styles at runtime. Requests are authorized by the admin token.
+type Handler struct {
+	store      Store
+	adminToken *admin.Token
+	logger     *zap.Logger
+
+	// Used to check model overrides, see SetModels
+	models    *ai.ModelRegistry
+	model     string
+	maxTokens int
+}
+
+// NewHandler creates a prompt style handler and makes the stored styles
+// available to summarizers
+func NewHandler(store Store, adminToken *admin.Token, logger *zap.Logger) *Handler {
+	h := &Handler{store: store, adminToken: adminToken, logger: logger}
+	for name, style := range store.PromptStyles() {
+		if err := ai.SetCustomPromptStyle(name, style); err != nil {
+			logger.Warn("Skipping stored prompt style", zap.String("style", name), zap.Error(err))
+		}
+	}
+	return h
+}
+
+// SetModels checks the model and token overrides of styles against registry,
+// with the summarizer's model and completion limit where a style has none
+func (h *Handler) SetModels(registry *ai.ModelRegistry, model string, maxTokens int) {
+	h.models = registry
+	h.model = model
+	h.maxTokens = maxTokens
+}
+
+// ServeCreate creates a custom style from a Request
+func (h *Handler) ServeCreate(w http.ResponseWriter, r *http.Request) {
+	request, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := ai.GetPromptStyle(request.Name); exists {
+		http.Error(w, fmt.Sprintf("prompt style %s already exists", request.Name), http.StatusConflict)
+		return
+	}
+	h.save(w, request, http.StatusCreated)
+}
+
+// ServeUpdate replaces the custom style named by the last path segment.
+// Summarizers using the style keep the old version until it is set again.
+func (h *Handler) ServeUpdate(w http.ResponseWriter, r *http.Request) {
+	request, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	request.Name = path.Base(r.URL.Path)
+	if _, exists := ai.CustomPromptStyles()[request.Name]; !exists {
+		http.Error(w, fmt.Sprintf("no custom prompt style %s", request.Name), http.StatusNotFound)
+		return
+	}
+	h.save(w, request, http.StatusOK)
+}
+
+// ServeDelete deletes the custom style named by the last path segment
+func (h *Handler) ServeDelete(w http.ResponseWriter, r *http.Request) {
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return
+	}
+	name := path.Base(r.URL.Path)
+	if !ai.DeleteCustomPromptStyle(name) {
+		http.Error(w, fmt.Sprintf("no custom prompt style %s", name), http.StatusNotFound)
+		return
+	}
+	h.store.DeletePromptStyle(name)
+	h.logger.Info("Deleted prompt style", zap.String("style", name))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decode authorizes a request and reads its body
+func (h *Handler) decode(w http.ResponseWriter, r *http.Request) (Request, bool) {
+	var request Request
+	if !admin.Require(w, r, h.adminToken.Value()) {
+		return request, false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, "invalid prompt style: "+err.Error(), http.StatusBadRequest)
+		return request, false
+	}
+	return request, true
+}
+
+// save validates a style, then makes it available and stores it
+func (h *Handler) save(w http.ResponseWriter, request Request, status int) {
+	if err := h.validate(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ai.SetCustomPromptStyle(request.Name, request.PromptStyle); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.store.SavePromptStyle(request.Name, request.PromptStyle)
+	h.logger.Info("Saved prompt style", zap.String("style", request.Name), zap.String("model", request.Model))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(request); err != nil {
+		h.logger.Error("Failed to write prompt style", zap.Error(err))
+	}
+}
+
+// validate checks a style and whether its model can serve its completions
+func (h *Handler) validate(request Request) error {
+	if err := ai.ValidatePromptStyle(request.Name, request.PromptStyle); err != nil {
+		return err
+	}
+	if h.models == nil || (request.Model == "" && request.MaxTokens == 0) {
+		return nil
+	}
+	model, maxTokens := h.model, h.maxTokens
+	if request.Model != "" {
+		model = request.Model
+	}
+	if request.MaxTokens > 0 {
+		maxTokens = request.MaxTokens
+	}
+	if err := h.models.Validate(model, maxTokens); err != nil {
+		return fmt.Errorf("prompt style %s: %w", request.Name, err)
+	}
+	return nil
+}
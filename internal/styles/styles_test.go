@@ -0,0 +1,117 @@
+package styles
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/store"
+)
+
+func serve(handler http.HandlerFunc, method, target, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestPromptStyleLifecycle(t *testing.T) {
+	issues := store.NewMemoryStore()
+	h := NewHandler(issues, admin.NewToken("admin-token"), zap.NewNop())
+	h.SetModels(ai.DefaultModelRegistry(), "gpt-4", 2000)
+	t.Cleanup(func() { ai.DeleteCustomPromptStyle("sre_oncall") })
+
+	body := `{"name":"sre_oncall","personality":"You are the SRE on call for Acme's payment API.","tone":"concise","detail_level":"concise","model":"gpt-4o-mini","temperature":0.2}`
+	if rec := serve(h.ServeCreate, http.MethodPost, "/api/prompt-styles", body, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without the admin token, got %d", rec.Code)
+	}
+	if rec := serve(h.ServeCreate, http.MethodPost, "/api/prompt-styles", body, "admin-token"); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	style, ok := ai.GetPromptStyle("sre_oncall")
+	if !ok || style.Model != "gpt-4o-mini" || style.Temperature == nil || *style.Temperature != 0.2 {
+		t.Fatalf("Expected the style to be available, got %+v", style)
+	}
+	if _, ok := issues.PromptStyles()["sre_oncall"]; !ok {
+		t.Error("Expected the style to be stored")
+	}
+	if rec := serve(h.ServeCreate, http.MethodPost, "/api/prompt-styles", body, "admin-token"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for an existing style, got %d", rec.Code)
+	}
+
+	update := `{"personality":"You are the SRE on call.","detail_level":"moderate","max_tokens":900}`
+	if rec := serve(h.ServeUpdate, http.MethodPut, "/api/prompt-styles/sre_oncall", update, "admin-token"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if style, _ := ai.GetPromptStyle("sre_oncall"); style.DetailLevel != "moderate" || style.Model != "" || style.MaxTokens != 900 {
+		t.Errorf("Expected the style replaced, got %+v", style)
+	}
+
+	if rec := serve(h.ServeDelete, http.MethodDelete, "/api/prompt-styles/sre_oncall", "", "admin-token"); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	if _, ok := ai.GetPromptStyle("sre_oncall"); ok {
+		t.Error("Expected the style deleted")
+	}
+	if len(issues.PromptStyles()) != 0 {
+		t.Error("Expected the style removed from the store")
+	}
+	if rec := serve(h.ServeDelete, http.MethodDelete, "/api/prompt-styles/sre_oncall", "", "admin-token"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a deleted style, got %d", rec.Code)
+	}
+}
+
+func TestPromptStyleValidation(t *testing.T) {
+	h := NewHandler(store.NewMemoryStore(), admin.NewToken("admin-token"), zap.NewNop())
+	h.SetModels(ai.DefaultModelRegistry(), "gpt-4", 2000)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+		body    string
+		want    int
+	}{
+		{"predefined name", h.ServeCreate, "/api/prompt-styles", `{"name":"master_analyst","personality":"x"}`, http.StatusConflict},
+		{"invalid name", h.ServeCreate, "/api/prompt-styles", `{"name":"SRE On Call","personality":"x"}`, http.StatusBadRequest},
+		{"no personality", h.ServeCreate, "/api/prompt-styles", `{"name":"sre"}`, http.StatusBadRequest},
+		{"unknown detail level", h.ServeCreate, "/api/prompt-styles", `{"name":"sre","personality":"x","detail_level":"verbose"}`, http.StatusBadRequest},
+		{"temperature", h.ServeCreate, "/api/prompt-styles", `{"name":"sre","personality":"x","temperature":3}`, http.StatusBadRequest},
+		{"tokens beyond the model", h.ServeCreate, "/api/prompt-styles", `{"name":"sre","personality":"x","model":"gpt-4o","max_tokens":20000}`, http.StatusBadRequest},
+		{"update predefined", h.ServeUpdate, "/api/prompt-styles/master_analyst", `{"personality":"x"}`, http.StatusNotFound},
+		{"invalid JSON", h.ServeCreate, "/api/prompt-styles", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := serve(tt.handler, http.MethodPost, tt.target, tt.body, "admin-token")
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+	if len(ai.CustomPromptStyles()) != 0 {
+		t.Error("Expected no invalid style to be saved")
+	}
+}
+
+func TestNewHandlerLoadsStoredStyles(t *testing.T) {
+	issues := store.NewMemoryStore()
+	issues.SavePromptStyle("release_manager", ai.PromptStyle{Personality: "You are the release manager.", Tone: "concise"})
+	issues.SavePromptStyle("master_analyst", ai.PromptStyle{Personality: "Replaced"})
+	t.Cleanup(func() { ai.DeleteCustomPromptStyle("release_manager") })
+
+	NewHandler(issues, admin.NewToken("admin-token"), zap.NewNop())
+
+	if style, ok := ai.GetPromptStyle("release_manager"); !ok || style.Tone != "concise" {
+		t.Errorf("Expected the stored style loaded, got %+v", style)
+	}
+	if style, _ := ai.GetPromptStyle("master_analyst"); style.Personality != "MASTER ANALYST" {
+		t.Error("Expected a stored style not to replace a predefined one")
+	}
+}
@@ -0,0 +1,93 @@
+package summarylog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+// Writer writes one JSON line per processed issue, separate from the
+// application logs, so a SIEM or data warehouse can tail it. It is safe for
+// concurrent use.
+type Writer struct {
+	sink   *sink
+	tenant string
+}
+
+// sink is the destination shared by a writer and its tenant writers
+type sink struct {
+	mu     sync.Mutex
+	out    io.Writer
+	closer io.Closer // Nil for stdout and stderr
+	logger *zap.Logger
+}
+
+// record adds the tenant to a summary record
+type record struct {
+	Tenant string `json:"tenant,omitempty"`
+	pipeline.SummaryRecord
+}
+
+// New creates a writer that writes records to out
+func New(out io.Writer, logger *zap.Logger) *Writer {
+	return &Writer{sink: &sink{out: out, logger: logger}}
+}
+
+// Open creates a writer for path: "stdout", "stderr", or a file that
+// records are appended to
+func Open(path string, logger *zap.Logger) (*Writer, error) {
+	switch path {
+	case "stdout":
+		return New(os.Stdout, logger), nil
+	case "stderr":
+		return New(os.Stderr, logger), nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open summary log: %w", err)
+	}
+	writer := New(file, logger)
+	writer.sink.closer = file
+	return writer, nil
+}
+
+// Tenant returns a writer to the same destination that labels records with
+// the tenant's name
+func (w *Writer) Tenant(name string) *Writer {
+	return &Writer{sink: w.sink, tenant: name}
+}
+
+// RecordSummary writes a record as one line. Failures are logged, as
+// processing must not fail because the log cannot be written.
+func (w *Writer) RecordSummary(summary pipeline.SummaryRecord) {
+	line, err := json.Marshal(record{Tenant: w.tenant, SummaryRecord: summary})
+	if err != nil {
+		w.sink.logger.Error("Failed to encode summary record", zap.Error(err))
+		return
+	}
+	line = append(line, '\n')
+
+	w.sink.mu.Lock()
+	defer w.sink.mu.Unlock()
+	if _, err := w.sink.out.Write(line); err != nil {
+		w.sink.logger.Error("Failed to write summary record",
+			zap.String("repository", summary.Repository),
+			zap.Int("issue_number", summary.Number),
+			zap.Error(err))
+	}
+}
+
+// Close closes the log file, if any
+func (w *Writer) Close() error {
+	if w.sink.closer == nil {
+		return nil
+	}
+	return w.sink.closer.Close()
+}
@@ -0,0 +1,67 @@
+package summarylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/pipeline"
+)
+
+func TestWriterWritesJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	writer := New(&out, zap.NewNop())
+	writer.RecordSummary(pipeline.SummaryRecord{Repository: "owner/repo", Number: 7, Priority: "high"})
+	writer.Tenant("payments").RecordSummary(pipeline.SummaryRecord{Repository: "owner/api", Number: 8})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per record, got %q", out.String())
+	}
+
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Expected a JSON record, got %q: %v", lines[0], err)
+	}
+	if first["repository"] != "owner/repo" || first["number"] != float64(7) || first["priority"] != "high" {
+		t.Errorf("Unexpected record %v", first)
+	}
+	if _, ok := first["tenant"]; ok {
+		t.Errorf("Expected no tenant without multi-tenant mode, got %v", first)
+	}
+
+	var second map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("Expected a JSON record, got %q: %v", lines[1], err)
+	}
+	if second["tenant"] != "payments" || second["repository"] != "owner/api" {
+		t.Errorf("Expected the tenant's record, got %v", second)
+	}
+}
+
+func TestOpenAppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summaries.jsonl")
+	for i := 0; i < 2; i++ {
+		writer, err := Open(path, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.RecordSummary(pipeline.SummaryRecord{Repository: "owner/repo", Number: i})
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 2 {
+		t.Errorf("Expected records from both runs, got %q", content)
+	}
+}
@@ -0,0 +1,117 @@
+package support
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// intercomConversationCreated is the topic of conversations started by a
+// customer
+const intercomConversationCreated = "conversation.user.created"
+
+var (
+	// htmlBreakPattern matches the tags that end a line in Intercom's message
+	// HTML
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>`)
+
+	// htmlTagPattern matches any other tag
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// intercomNotification is the part of an Intercom webhook notification the
+// handler reads
+type intercomNotification struct {
+	Topic string `json:"topic"`
+	AppID string `json:"app_id"`
+	Data  struct {
+		Item struct {
+			ID        string `json:"id"`
+			Title     string `json:"title"`
+			CreatedAt int64  `json:"created_at"`
+			Source    struct {
+				Subject string `json:"subject"`
+				Body    string `json:"body"`
+				Author  struct {
+					Name string `json:"name"`
+				} `json:"author"`
+			} `json:"source"`
+			Tags struct {
+				Tags []struct {
+					Name string `json:"name"`
+				} `json:"tags"`
+			} `json:"tags"`
+		} `json:"item"`
+	} `json:"data"`
+}
+
+// verifyIntercom checks the X-Hub-Signature header, the hex HMAC-SHA1 of the
+// body keyed with the app's client secret
+func verifyIntercom(secret string, header http.Header, body []byte) bool {
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature"), "sha1=")
+	if secret == "" || !ok {
+		return false
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// parseIntercom reads a new conversation from an Intercom notification, and
+// returns nil for other topics
+func parseIntercom(body []byte) (*Ticket, error) {
+	var notification intercomNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, fmt.Errorf("failed to parse Intercom notification: %w", err)
+	}
+	if notification.Topic != intercomConversationCreated {
+		return nil, nil
+	}
+	item := notification.Data.Item
+	id, err := parseID(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if notification.AppID == "" {
+		return nil, fmt.Errorf("Intercom notification without an app ID")
+	}
+
+	ticket := &Ticket{
+		Source:      SourceIntercom,
+		ID:          id,
+		Subject:     strings.TrimSpace(item.Title),
+		Description: stripHTML(item.Source.Body),
+		Requester:   item.Source.Author.Name,
+		URL:         fmt.Sprintf("https://app.intercom.com/a/apps/%s/inbox/inbox/conversation/%d", notification.AppID, id),
+	}
+	if item.CreatedAt > 0 {
+		ticket.CreatedAt = time.Unix(item.CreatedAt, 0).UTC()
+	}
+	for _, tag := range item.Tags.Tags {
+		ticket.Tags = append(ticket.Tags, tag.Name)
+	}
+	if ticket.Subject == "" {
+		ticket.Subject = strings.TrimSpace(stripHTML(item.Source.Subject))
+	}
+	if ticket.Subject == "" {
+		ticket.Subject = utils.TruncateText(utils.FirstLine(ticket.Description), maxTitleLength)
+	}
+	return ticket, nil
+}
+
+// stripHTML converts message HTML to plain text, keeping line breaks
+func stripHTML(text string) string {
+	text = htmlBreakPattern.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, ""))
+	return strings.TrimSpace(text)
+}
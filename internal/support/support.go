@@ -0,0 +1,294 @@
+// Package support turns support tickets from Zendesk and Intercom into issues
+// for the pipeline, so customer reports are triaged alongside GitHub issues
+package support
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+// Ticket sources
+const (
+	SourceZendesk  = "zendesk"
+	SourceIntercom = "intercom"
+)
+
+const (
+	// maxPayloadBytes bounds the webhook bodies read
+	maxPayloadBytes = 1 << 20
+
+	// maxTitleLength bounds titles taken from the first line of a message
+	maxTitleLength = 80
+)
+
+// Config sets where support tickets are accepted from and whether they are
+// filed as GitHub issues
+type Config struct {
+	ZendeskSecret  string // Signing secret of the Zendesk webhook, empty to not accept Zendesk tickets
+	IntercomSecret string // Client secret of the Intercom app, empty to not accept Intercom tickets
+
+	// Owner of the repositories unfiled tickets are routed and stored under,
+	// e.g. support for support/zendesk and support/intercom
+	Owner string
+
+	FileRepository string   // owner/name repository tickets are filed in before analysis, empty to analyze them unfiled
+	FileLabels     []string // Labels of the filed issues
+}
+
+// Validate checks the owner and the file repository
+func (c Config) Validate() error {
+	if c.Owner == "" || strings.Contains(c.Owner, "/") {
+		return fmt.Errorf("support repository owner must be a single path segment, got %q", c.Owner)
+	}
+	if c.FileRepository != "" {
+		if parts := strings.Split(c.FileRepository, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("support file repository must be owner/name, got %q", c.FileRepository)
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether tickets are accepted from any source
+func (c Config) Enabled() bool {
+	return c.ZendeskSecret != "" || c.IntercomSecret != ""
+}
+
+// Ticket is a support ticket in the fields the pipeline uses
+type Ticket struct {
+	Source      string
+	ID          int
+	Subject     string
+	Description string // Plain text
+	Requester   string // Name of the customer, empty when the webhook does not carry it
+	Priority    string // Priority set in the support tool, empty for none
+	Tags        []string
+	URL         string // Link to the ticket for support agents
+	CreatedAt   time.Time
+}
+
+// Body writes the ticket as an issue body, with the ticket's details in a
+// footer below the customer's description
+func (t *Ticket) Body() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(t.Description))
+	fmt.Fprintf(&b, "\n\n---\nReported in %s ticket [#%d](%s)", sourceName(t.Source), t.ID, t.URL)
+	if t.Requester != "" {
+		fmt.Fprintf(&b, " by %s", t.Requester)
+	}
+	b.WriteString("\n")
+	if t.Priority != "" {
+		fmt.Fprintf(&b, "Ticket priority: %s\n", t.Priority)
+	}
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(&b, "Tags: %s\n", strings.Join(t.Tags, ", "))
+	}
+	return b.String()
+}
+
+// IssueData converts an unfiled ticket into the common issue model, under
+// the repository owner/source
+func (t *Ticket) IssueData(owner string) *github.IssueData {
+	issue := &gogithub.Issue{
+		Number:  gogithub.Int(t.ID),
+		Title:   gogithub.String(t.Subject),
+		Body:    gogithub.String(t.Body()),
+		State:   gogithub.String("open"),
+		HTMLURL: gogithub.String(t.URL),
+	}
+	if t.Requester != "" {
+		issue.User = &gogithub.User{Login: gogithub.String(t.Requester)}
+	}
+	if !t.CreatedAt.IsZero() {
+		issue.CreatedAt = &gogithub.Timestamp{Time: t.CreatedAt}
+	}
+	return &github.IssueData{
+		Issue: issue,
+		Repository: &gogithub.Repository{
+			FullName: gogithub.String(owner + "/" + t.Source),
+			Owner:    &gogithub.User{Login: gogithub.String(owner)},
+			Name:     gogithub.String(t.Source),
+		},
+		EventType:   t.Source,
+		Action:      "opened",
+		Behavior:    github.BehaviorSummarize,
+		Source:      t.Source,
+		PromptStyle: github.TicketPromptStyle,
+	}
+}
+
+// sourceName returns the product name of a source
+func sourceName(source string) string {
+	switch source {
+	case SourceZendesk:
+		return "Zendesk"
+	case SourceIntercom:
+		return "Intercom"
+	}
+	return source
+}
+
+// parseID reads a ticket ID, which both sources may send as a JSON string
+func parseID(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid ticket ID %q", id)
+	}
+	return n, nil
+}
+
+// IssueProcessor runs the pipeline for an issue
+type IssueProcessor interface {
+	ProcessIssue(ctx context.Context, issueData *github.IssueData)
+}
+
+// IssueFiler opens GitHub issues
+type IssueFiler interface {
+	CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*gogithub.Issue, *gogithub.Repository, error)
+}
+
+// MetricsRecorder records the support tickets received
+type MetricsRecorder interface {
+	RecordSupportTicket(source, status string)
+}
+
+// Handler receives support ticket webhooks
+type Handler struct {
+	mu        sync.RWMutex
+	config    Config
+	processor IssueProcessor
+	filer     IssueFiler
+	logger    *zap.Logger
+	metrics   MetricsRecorder
+	baseCtx   context.Context // Parent of background processing, cancelled on shutdown
+}
+
+// NewHandler creates a handler. filer may be nil when config has no file
+// repository.
+func NewHandler(processor IssueProcessor, filer IssueFiler, config Config, logger *zap.Logger, metrics MetricsRecorder) *Handler {
+	return &Handler{
+		config:    config,
+		processor: processor,
+		filer:     filer,
+		logger:    logger,
+		metrics:   metrics,
+		baseCtx:   context.Background(),
+	}
+}
+
+// SetBaseContext sets the parent context of tickets processed after their
+// webhook has been acknowledged
+func (h *Handler) SetBaseContext(ctx context.Context) {
+	h.baseCtx = ctx
+}
+
+// SetSecret replaces the secret that verifies a source's webhooks
+func (h *Handler) SetSecret(source, secret string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch source {
+	case SourceZendesk:
+		h.config.ZendeskSecret = secret
+	case SourceIntercom:
+		h.config.IntercomSecret = secret
+	}
+}
+
+// ServeZendesk receives Zendesk ticket events. Only ticket.created is
+// processed; other events are acknowledged and skipped.
+func (h *Handler) ServeZendesk(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	secret := h.config.ZendeskSecret
+	h.mu.RUnlock()
+	h.serve(w, r, SourceZendesk, func(body []byte) bool { return verifyZendesk(secret, r.Header, body) }, parseZendesk)
+}
+
+// ServeIntercom receives Intercom notifications. Only
+// conversation.user.created is processed; other topics are acknowledged and
+// skipped.
+func (h *Handler) ServeIntercom(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	secret := h.config.IntercomSecret
+	h.mu.RUnlock()
+	h.serve(w, r, SourceIntercom, func(body []byte) bool { return verifyIntercom(secret, r.Header, body) }, parseIntercom)
+}
+
+// serve verifies and parses a webhook, then processes its ticket in the
+// background. parse returns a nil ticket for events that are not new tickets.
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, source string, verify func([]byte) bool, parse func([]byte) (*Ticket, error)) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadBytes))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if !verify(body) {
+		h.logger.Warn("Invalid support webhook signature", zap.String("source", source))
+		h.metrics.RecordSupportTicket(source, "rejected")
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	ticket, err := parse(body)
+	if err != nil {
+		h.logger.Warn("Invalid support webhook payload", zap.String("source", source), zap.Error(err))
+		h.metrics.RecordSupportTicket(source, "invalid")
+		http.Error(w, "Invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+	if ticket == nil {
+		h.metrics.RecordSupportTicket(source, "skipped")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	receivedAt := time.Now()
+	go h.process(h.baseCtx, ticket, receivedAt)
+}
+
+// process files the ticket when a file repository is configured, then runs
+// the pipeline for the filed issue. Tickets that could not be filed are
+// analyzed unfiled, so they still reach Slack.
+func (h *Handler) process(ctx context.Context, ticket *Ticket, receivedAt time.Time) {
+	logger := h.logger.With(zap.String("source", ticket.Source), zap.Int("ticket_id", ticket.ID))
+
+	if h.config.FileRepository != "" && h.filer != nil {
+		body := ticket.Body() + github.TicketMarker(ticket.Source, strconv.Itoa(ticket.ID)) + "\n"
+		issue, repo, err := h.filer.CreateIssue(ctx, h.config.FileRepository, ticket.Subject, body, h.config.FileLabels)
+		if err == nil {
+			logger.Info("Filed support ticket",
+				zap.String("repository", repo.GetFullName()),
+				zap.Int("issue_number", issue.GetNumber()))
+			h.metrics.RecordSupportTicket(ticket.Source, "filed")
+			h.processor.ProcessIssue(ctx, &github.IssueData{
+				Issue:       issue,
+				Repository:  repo,
+				EventType:   "issues",
+				Action:      "opened",
+				Behavior:    github.BehaviorSummarize,
+				ReceivedAt:  receivedAt,
+				PromptStyle: github.TicketPromptStyle,
+			})
+			return
+		}
+		logger.Error("Failed to file support ticket, analyzing it unfiled",
+			zap.String("repository", h.config.FileRepository),
+			zap.Error(err))
+	}
+
+	logger.Info("Processing support ticket")
+	h.metrics.RecordSupportTicket(ticket.Source, "summarized")
+	issueData := ticket.IssueData(h.config.Owner)
+	issueData.ReceivedAt = receivedAt
+	h.processor.ProcessIssue(ctx, issueData)
+}
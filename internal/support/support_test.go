@@ -0,0 +1,247 @@
+package support
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/github"
+)
+
+const zendeskCreated = `{
+	"type": "zen:event-type:ticket.created",
+	"subdomain": "acme",
+	"detail": {
+		"id": "35436",
+		"subject": "Checkout fails with card declined",
+		"description": "Every card is declined since this morning.",
+		"priority": "HIGH",
+		"tags": ["billing", "checkout"],
+		"created_at": "2026-09-14T08:30:00Z"
+	}
+}`
+
+const intercomCreated = `{
+	"topic": "conversation.user.created",
+	"app_id": "abc123",
+	"data": {"item": {
+		"id": "1911",
+		"title": null,
+		"created_at": 1789374600,
+		"source": {
+			"subject": "",
+			"body": "<p>The export button does nothing.</p><p>Tried Chrome &amp; Firefox.</p>",
+			"author": {"name": "Jane Doe"}
+		},
+		"tags": {"tags": [{"name": "export"}]}
+	}}
+}`
+
+type fakeProcessor struct{ issues chan *github.IssueData }
+
+func (p *fakeProcessor) ProcessIssue(ctx context.Context, issueData *github.IssueData) {
+	p.issues <- issueData
+}
+
+type fakeFiler struct {
+	err    error
+	bodies []string
+}
+
+func (f *fakeFiler) CreateIssue(ctx context.Context, repo, title, body string, labels []string) (*gogithub.Issue, *gogithub.Repository, error) {
+	f.bodies = append(f.bodies, body)
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return &gogithub.Issue{Number: gogithub.Int(42), Title: gogithub.String(title), Body: gogithub.String(body)},
+		&gogithub.Repository{FullName: gogithub.String(repo)}, nil
+}
+
+type fakeMetrics struct{ statuses []string }
+
+func (m *fakeMetrics) RecordSupportTicket(source, status string) {
+	m.statuses = append(m.statuses, source+" "+status)
+}
+
+func testHandler(filer IssueFiler, fileRepository string) (*Handler, *fakeProcessor, *fakeMetrics) {
+	processor := &fakeProcessor{issues: make(chan *github.IssueData, 1)}
+	metrics := &fakeMetrics{}
+	config := Config{ZendeskSecret: "zendesk-secret", IntercomSecret: "intercom-secret", Owner: "support", FileRepository: fileRepository, FileLabels: []string{"support"}}
+	return NewHandler(processor, filer, config, zap.NewNop(), metrics), processor, metrics
+}
+
+func zendeskRequest(secret, body string) *http.Request {
+	timestamp := "2026-09-14T08:30:01Z"
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + body))
+	req := httptest.NewRequest(http.MethodPost, "/webhook/zendesk", strings.NewReader(body))
+	req.Header.Set("X-Zendesk-Webhook-Signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-Zendesk-Webhook-Signature-Timestamp", timestamp)
+	return req
+}
+
+func intercomRequest(secret, body string) *http.Request {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(body))
+	req := httptest.NewRequest(http.MethodPost, "/webhook/intercom", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func received(t *testing.T, processor *fakeProcessor) *github.IssueData {
+	t.Helper()
+	select {
+	case issueData := <-processor.issues:
+		return issueData
+	case <-time.After(time.Second):
+		t.Fatal("Expected the ticket to be processed")
+		return nil
+	}
+}
+
+func TestParseZendesk(t *testing.T) {
+	ticket, err := parseZendesk([]byte(zendeskCreated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID != 35436 || ticket.Subject != "Checkout fails with card declined" || ticket.Priority != "high" ||
+		ticket.URL != "https://acme.zendesk.com/agent/tickets/35436" || len(ticket.Tags) != 2 ||
+		!ticket.CreatedAt.Equal(time.Date(2026, 9, 14, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected ticket %+v", ticket)
+	}
+
+	if ticket, err := parseZendesk([]byte(`{"type": "zen:event-type:ticket.status_changed", "detail": {"id": "1"}}`)); ticket != nil || err != nil {
+		t.Errorf("Expected other events skipped, got %+v, %v", ticket, err)
+	}
+	if _, err := parseZendesk([]byte(`{"type": "zen:event-type:ticket.created", "subdomain": "acme", "detail": {"id": "abc"}}`)); err == nil {
+		t.Error("Expected an invalid ticket ID to fail")
+	}
+}
+
+func TestParseIntercom(t *testing.T) {
+	ticket, err := parseIntercom([]byte(intercomCreated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID != 1911 || ticket.Requester != "Jane Doe" || len(ticket.Tags) != 1 ||
+		ticket.URL != "https://app.intercom.com/a/apps/abc123/inbox/inbox/conversation/1911" {
+		t.Errorf("Unexpected ticket %+v", ticket)
+	}
+	if ticket.Description != "The export button does nothing.\nTried Chrome & Firefox." {
+		t.Errorf("Expected the message as plain text, got %q", ticket.Description)
+	}
+	if ticket.Subject != "The export button does nothing." {
+		t.Errorf("Expected the first line as the subject, got %q", ticket.Subject)
+	}
+
+	if ticket, err := parseIntercom([]byte(`{"topic": "conversation.admin.replied"}`)); ticket != nil || err != nil {
+		t.Errorf("Expected other topics skipped, got %+v, %v", ticket, err)
+	}
+}
+
+func TestServeRejectsInvalidSignatures(t *testing.T) {
+	handler, _, metrics := testHandler(nil, "")
+
+	rec := httptest.NewRecorder()
+	handler.ServeZendesk(rec, zendeskRequest("wrong", zendeskCreated))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a Zendesk webhook with a bad signature rejected, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeIntercom(rec, intercomRequest("wrong", intercomCreated))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an Intercom webhook with a bad signature rejected, got %d", rec.Code)
+	}
+
+	handler.SetSecret(SourceIntercom, "wrong")
+	rec = httptest.NewRecorder()
+	handler.ServeIntercom(rec, intercomRequest("wrong", `{"topic": "ping"}`))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected the rotated secret accepted and the topic skipped, got %d", rec.Code)
+	}
+	if fmt.Sprint(metrics.statuses) != "[zendesk rejected intercom rejected intercom skipped]" {
+		t.Errorf("Unexpected metrics %v", metrics.statuses)
+	}
+}
+
+func TestServeUnfiledTicket(t *testing.T) {
+	handler, processor, metrics := testHandler(nil, "")
+
+	rec := httptest.NewRecorder()
+	handler.ServeZendesk(rec, zendeskRequest("zendesk-secret", zendeskCreated))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected the ticket accepted, got %d", rec.Code)
+	}
+
+	issueData := received(t, processor)
+	if issueData.Repository.GetFullName() != "support/zendesk" || issueData.Issue.GetNumber() != 35436 ||
+		issueData.Source != SourceZendesk || issueData.PromptStyle != github.TicketPromptStyle ||
+		issueData.Behavior != github.BehaviorSummarize {
+		t.Errorf("Expected the ticket as an unfiled issue, got %+v", issueData)
+	}
+	body := issueData.Issue.GetBody()
+	if !strings.HasPrefix(body, "Every card is declined") || !strings.Contains(body, "Ticket priority: high") ||
+		!strings.Contains(body, "Tags: billing, checkout") || github.IsFiledTicket(body) {
+		t.Errorf("Unexpected body %q", body)
+	}
+	if fmt.Sprint(metrics.statuses) != "[zendesk summarized]" {
+		t.Errorf("Unexpected metrics %v", metrics.statuses)
+	}
+}
+
+func TestServeFiledTicket(t *testing.T) {
+	filer := &fakeFiler{}
+	handler, processor, metrics := testHandler(filer, "acme/support")
+
+	rec := httptest.NewRecorder()
+	handler.ServeIntercom(rec, intercomRequest("intercom-secret", intercomCreated))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected the ticket accepted, got %d", rec.Code)
+	}
+
+	issueData := received(t, processor)
+	if issueData.Repository.GetFullName() != "acme/support" || issueData.Issue.GetNumber() != 42 ||
+		issueData.Source != "" || issueData.PromptStyle != github.TicketPromptStyle {
+		t.Errorf("Expected the filed issue analyzed as a ticket, got %+v", issueData)
+	}
+	if len(filer.bodies) != 1 || !github.IsFiledTicket(filer.bodies[0]) || !strings.Contains(filer.bodies[0], "by Jane Doe") {
+		t.Errorf("Expected the issue filed with the ticket marker, got %q", filer.bodies)
+	}
+	if fmt.Sprint(metrics.statuses) != "[intercom filed]" {
+		t.Errorf("Unexpected metrics %v", metrics.statuses)
+	}
+
+	// Tickets that cannot be filed are analyzed unfiled
+	filer.err = fmt.Errorf("forbidden")
+	handler.ServeIntercom(httptest.NewRecorder(), intercomRequest("intercom-secret", intercomCreated))
+	if issueData := received(t, processor); issueData.Source != SourceIntercom || issueData.Repository.GetFullName() != "support/intercom" {
+		t.Errorf("Expected the ticket analyzed unfiled, got %+v", issueData)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, config := range []Config{
+		{Owner: ""},
+		{Owner: "support/tickets"},
+		{Owner: "support", FileRepository: "support"},
+	} {
+		if config.Validate() == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+	if err := (Config{Owner: "support", FileRepository: "acme/support"}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,81 @@
+package support
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// zendeskTicketCreated is the event type of new tickets
+const zendeskTicketCreated = "zen:event-type:ticket.created"
+
+// zendeskEvent is the part of a Zendesk ticket event webhook the handler
+// reads
+type zendeskEvent struct {
+	Type      string `json:"type"`
+	Subdomain string `json:"subdomain"`
+	Detail    struct {
+		ID          string    `json:"id"`
+		Subject     string    `json:"subject"`
+		Description string    `json:"description"`
+		Priority    string    `json:"priority"`
+		Tags        []string  `json:"tags"`
+		CreatedAt   time.Time `json:"created_at"`
+	} `json:"detail"`
+}
+
+// verifyZendesk checks the X-Zendesk-Webhook-Signature header, the base64
+// HMAC-SHA256 of the timestamp header followed by the body
+func verifyZendesk(secret string, header http.Header, body []byte) bool {
+	signature := header.Get("X-Zendesk-Webhook-Signature")
+	timestamp := header.Get("X-Zendesk-Webhook-Signature-Timestamp")
+	if secret == "" || signature == "" || timestamp == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// parseZendesk reads a new ticket from a Zendesk event, and returns nil for
+// other events
+func parseZendesk(body []byte) (*Ticket, error) {
+	var event zendeskEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse Zendesk event: %w", err)
+	}
+	if event.Type != zendeskTicketCreated {
+		return nil, nil
+	}
+	id, err := parseID(event.Detail.ID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Subdomain == "" {
+		return nil, fmt.Errorf("Zendesk event without a subdomain")
+	}
+
+	ticket := &Ticket{
+		Source:      SourceZendesk,
+		ID:          id,
+		Subject:     strings.TrimSpace(event.Detail.Subject),
+		Description: event.Detail.Description,
+		Priority:    strings.ToLower(event.Detail.Priority),
+		Tags:        event.Detail.Tags,
+		URL:         fmt.Sprintf("https://%s.zendesk.com/agent/tickets/%d", event.Subdomain, id),
+		CreatedAt:   event.Detail.CreatedAt,
+	}
+	if ticket.Subject == "" {
+		ticket.Subject = utils.TruncateText(utils.FirstLine(ticket.Description), maxTitleLength)
+	}
+	return ticket, nil
+}
@@ -0,0 +1,213 @@
+package teams
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/store"
+)
+
+// scheduleFor returns the digest schedule in the team's timezone, if it has
+// one
+func scheduleFor(digest schedule.Schedule, team Team) schedule.Schedule {
+	if team.Timezone != "" {
+		digest.Timezone = team.Timezone
+	}
+	return digest
+}
+
+// IssueLister lists the stored issue records
+type IssueLister interface {
+	ListIssues(query store.Query) ([]store.IssueRecord, string)
+}
+
+// Poster posts a team's digest to its Slack channel
+type Poster interface {
+	PostDigest(ctx context.Context, channelID string, digest Digest) error
+}
+
+// Digest is a team's weekly digest: the rollup of the past week, and the
+// issues still waiting for triage, which the digest offers actions on
+type Digest struct {
+	Text   string        // The rollup, as written by FormatDigest
+	Triage []TriageIssue // Most urgent first
+}
+
+// TriageIssue is an open issue nobody has responded to yet
+type TriageIssue struct {
+	Repository string
+	Number     int
+	Title      string
+	Priority   string
+	OpenedAt   time.Time
+}
+
+// Reporter posts each team's rollup of the past week to its channel
+type Reporter struct {
+	directory *Directory
+	issues    IssueLister
+	poster    Poster
+	schedule  schedule.Schedule
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewReporter creates a reporter for the teams in directory
+func NewReporter(directory *Directory, issues IssueLister, poster Poster, digest schedule.Schedule, logger *zap.Logger) *Reporter {
+	return &Reporter{
+		directory: directory,
+		issues:    issues,
+		poster:    poster,
+		schedule:  digest,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Run posts the digests at each scheduled time until the context is
+// cancelled. Each team's digest is due at the schedule's hour in the team's
+// timezone. A digest due before Run started is not posted, so a restart does
+// not post the week's digests again.
+func (r *Reporter) Run(ctx context.Context) {
+	if r.schedule.Weekday == "" {
+		return
+	}
+	posted := make(map[string]time.Time)
+	now := r.now()
+	for _, team := range r.directory.Teams() {
+		posted[team.Name] = scheduleFor(r.schedule, team).Last(now)
+	}
+	schedule.Tick(ctx, func() {
+		r.postDue(ctx, r.now(), posted)
+	})
+}
+
+// postDue posts the digests that fell due since those in posted, and records
+// when they were due
+func (r *Reporter) postDue(ctx context.Context, now time.Time, posted map[string]time.Time) {
+	var records []store.IssueRecord
+	for _, team := range r.directory.Teams() {
+		if team.Channel == "" {
+			continue
+		}
+		due := scheduleFor(r.schedule, team).Last(now)
+		if !due.After(posted[team.Name]) {
+			continue
+		}
+		if records == nil {
+			records, _ = r.issues.ListIssues(store.Query{})
+		}
+		r.postDigest(ctx, team, records, due)
+		posted[team.Name] = due
+	}
+}
+
+// PostDigests posts the rollup of the week before until to the channel of
+// every team that has one
+func (r *Reporter) PostDigests(ctx context.Context, until time.Time) {
+	records, _ := r.issues.ListIssues(store.Query{})
+	for _, team := range r.directory.Teams() {
+		if team.Channel == "" {
+			continue
+		}
+		r.postDigest(ctx, team, records, until)
+	}
+}
+
+// postDigest posts a team's rollup of the week before until, with its dates
+// in the team's timezone
+func (r *Reporter) postDigest(ctx context.Context, team Team, records []store.IssueRecord, until time.Time) {
+	until = until.In(scheduleFor(r.schedule, team).Location())
+	stats := Rollup(team, records, until.AddDate(0, 0, -7), until)
+	digest := Digest{Text: FormatDigest(stats), Triage: Untriaged(team, records, r.now())}
+	if err := r.poster.PostDigest(ctx, team.Channel, digest); err != nil {
+		r.logger.Warn("Failed to post team digest",
+			zap.String("team", team.Name),
+			zap.String("channel", team.Channel),
+			zap.Error(err))
+	}
+}
+
+// Untriaged lists a team's open posted issues that no maintainer responded
+// to and nobody acknowledged from a digest, except those snoozed past now,
+// by priority and then oldest first
+func Untriaged(team Team, records []store.IssueRecord, now time.Time) []TriageIssue {
+	var issues []TriageIssue
+	for _, record := range records {
+		if !team.Owns(record.Repository) || record.State != "open" || record.MessageTS == "" ||
+			!record.AcknowledgedAt.IsZero() || !record.TriagedAt.IsZero() || record.SnoozedUntil.After(now) {
+			continue
+		}
+		issue := TriageIssue{
+			Repository: record.Repository,
+			Number:     record.Number,
+			Title:      record.Title,
+			OpenedAt:   record.OpenedAt,
+		}
+		if issue.OpenedAt.IsZero() {
+			issue.OpenedAt = record.AnalyzedAt
+		}
+		if record.Summary != nil {
+			issue.Priority = strings.ToLower(record.Summary.Priority)
+		}
+		issues = append(issues, issue)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if a, b := priorityRank(issues[i].Priority), priorityRank(issues[j].Priority); a != b {
+			return a < b
+		}
+		return issues[i].OpenedAt.Before(issues[j].OpenedAt)
+	})
+	return issues
+}
+
+// priorityRank orders priorities most urgent first; unknown ones come last
+func priorityRank(priority string) int {
+	ranks := []string{"critical", "high", "medium", "low"}
+	for rank, threshold := range ranks {
+		if ai.PriorityAtLeast(priority, threshold) {
+			return rank
+		}
+	}
+	return len(ranks)
+}
+
+// FormatDigest writes a team's rollup as a Slack message
+func FormatDigest(stats Stats) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Weekly issue digest for %s* (%s to %s)\n",
+		stats.Team, stats.Since.Format("Jan 2"), stats.Until.Format("Jan 2"))
+	fmt.Fprintf(&b, "• Issues opened: %d\n", stats.Issues)
+	fmt.Fprintf(&b, "• High priority: %d (%d still open overall)\n", stats.HighPriority, stats.OpenHighPriority)
+	if stats.Acknowledged > 0 {
+		median := time.Duration(stats.MedianTimeToAckSeconds) * time.Second
+		fmt.Fprintf(&b, "• Median time to acknowledge: %s (%d of %d acknowledged)\n",
+			median.Round(time.Minute), stats.Acknowledged, stats.Issues)
+	} else {
+		b.WriteString("• Median time to acknowledge: none acknowledged\n")
+	}
+
+	priorities := make([]string, 0, len(stats.ByPriority))
+	for priority := range stats.ByPriority {
+		priorities = append(priorities, priority)
+	}
+	sort.Slice(priorities, func(i, j int) bool {
+		return stats.ByPriority[priorities[i]] > stats.ByPriority[priorities[j]] ||
+			stats.ByPriority[priorities[i]] == stats.ByPriority[priorities[j]] && priorities[i] < priorities[j]
+	})
+	if len(priorities) > 0 {
+		counts := make([]string, len(priorities))
+		for i, priority := range priorities {
+			counts[i] = fmt.Sprintf("%s %d", priority, stats.ByPriority[priority])
+		}
+		fmt.Fprintf(&b, "• By priority: %s\n", strings.Join(counts, ", "))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,56 @@
+package teams
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github-issue-ai-bot/internal/store"
+)
+
+// maxStatsDays bounds the period of a stats request
+const maxStatsDays = 90
+
+// Handler serves team rollups. They hold counts only, no issue content.
+type Handler struct {
+	directory *Directory
+	issues    IssueLister
+	now       func() time.Time
+}
+
+// NewHandler creates a handler for the teams in directory
+func NewHandler(directory *Directory, issues IssueLister) *Handler {
+	return &Handler{directory: directory, issues: issues, now: time.Now}
+}
+
+// ServeStats returns the rollup of the team named by the path segment before
+// the last, e.g. /api/teams/payments/stats, over the past week or the number
+// of days given by the days parameter
+func (h *Handler) ServeStats(w http.ResponseWriter, r *http.Request) {
+	name := path.Base(path.Dir(r.URL.Path))
+	team, ok := h.directory.Team(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no team %s", name), http.StatusNotFound)
+		return
+	}
+
+	days := 7
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxStatsDays {
+			http.Error(w, fmt.Sprintf("days must be between 1 and %d", maxStatsDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	until := h.now().UTC()
+	records, _ := h.issues.ListIssues(store.Query{})
+	stats := Rollup(team, records, until.AddDate(0, 0, -days), until)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
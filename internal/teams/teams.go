@@ -0,0 +1,150 @@
+package teams
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/store"
+	"github-issue-ai-bot/pkg/utils"
+)
+
+// highPriority is the lowest priority counted as high in rollups
+const highPriority = "high"
+
+// teamName limits team names to what fits a URL path
+var teamName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Team owns a set of repositories. Teams are read from the teams key of the
+// config file.
+type Team struct {
+	Name         string   `mapstructure:"name" json:"name"`
+	Repositories []string `mapstructure:"repositories" json:"repositories"` // Glob patterns, e.g. "my-org/payments-*"
+	Channel      string   `mapstructure:"channel" json:"channel"`           // Slack channel for the weekly digest, empty for none
+
+	// Timezone is the IANA name of the team's timezone, which its digest is
+	// scheduled and dated in. Empty uses the digest schedule's.
+	Timezone string `mapstructure:"timezone" json:"timezone,omitempty"`
+}
+
+// Owns reports whether a repository belongs to the team
+func (t Team) Owns(repository string) bool {
+	repository = strings.ToLower(repository)
+	for _, pattern := range t.Repositories {
+		if ok, _ := path.Match(strings.ToLower(pattern), repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Directory maps repositories to the teams that own them. A repository may
+// belong to several teams.
+type Directory struct {
+	teams []Team
+}
+
+// NewDirectory validates the teams and creates a directory of them
+func NewDirectory(teams []Team) (*Directory, error) {
+	names := make(map[string]bool, len(teams))
+	for i, team := range teams {
+		if !teamName.MatchString(team.Name) {
+			return nil, fmt.Errorf("team %d: name %q must be lowercase letters, digits, - and _", i, team.Name)
+		}
+		if names[team.Name] {
+			return nil, fmt.Errorf("team %s is defined twice", team.Name)
+		}
+		names[team.Name] = true
+		if team.Timezone != "" {
+			if _, err := time.LoadLocation(team.Timezone); err != nil {
+				return nil, fmt.Errorf("team %s: invalid timezone: %w", team.Name, err)
+			}
+		}
+		if len(team.Repositories) == 0 {
+			return nil, fmt.Errorf("team %s has no repositories", team.Name)
+		}
+		for _, pattern := range team.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("team %s: invalid repository pattern %q: %w", team.Name, pattern, err)
+			}
+		}
+	}
+	return &Directory{teams: teams}, nil
+}
+
+// Teams returns the teams in configuration order
+func (d *Directory) Teams() []Team {
+	return d.teams
+}
+
+// Team finds a team by name
+func (d *Directory) Team(name string) (Team, bool) {
+	for _, team := range d.teams {
+		if team.Name == name {
+			return team, true
+		}
+	}
+	return Team{}, false
+}
+
+// Stats roll up a team's issues over a period
+type Stats struct {
+	Team         string         `json:"team"`
+	Since        time.Time      `json:"since"`
+	Until        time.Time      `json:"until"`
+	Issues       int            `json:"issues"`        // Opened in the period
+	HighPriority int            `json:"high_priority"` // Of those, summarized as high priority or above
+	ByPriority   map[string]int `json:"by_priority"`   // Of those, by summarized priority
+	Acknowledged int            `json:"acknowledged"`  // Of those, responded to by a maintainer
+
+	// Median time from opening to the first maintainer response, of the
+	// acknowledged issues
+	MedianTimeToAckSeconds int64 `json:"median_time_to_ack_seconds"`
+
+	OpenHighPriority int `json:"open_high_priority"` // High priority issues still open, whenever opened
+}
+
+// Rollup computes a team's stats for the issues opened in [since, until).
+// Records from before the opening time was stored count from when they were
+// first analyzed.
+func Rollup(team Team, records []store.IssueRecord, since, until time.Time) Stats {
+	stats := Stats{Team: team.Name, Since: since, Until: until, ByPriority: make(map[string]int)}
+	var toAck []time.Duration
+	for _, record := range records {
+		if !team.Owns(record.Repository) {
+			continue
+		}
+		priority := ""
+		if record.Summary != nil {
+			priority = strings.ToLower(record.Summary.Priority)
+		}
+		high := ai.PriorityAtLeast(priority, highPriority)
+		if high && record.State == "open" {
+			stats.OpenHighPriority++
+		}
+
+		opened := record.OpenedAt
+		if opened.IsZero() {
+			opened = record.AnalyzedAt
+		}
+		if opened.Before(since) || !opened.Before(until) {
+			continue
+		}
+		stats.Issues++
+		if priority != "" {
+			stats.ByPriority[priority]++
+		}
+		if high {
+			stats.HighPriority++
+		}
+		if !record.AcknowledgedAt.IsZero() && !record.OpenedAt.IsZero() {
+			stats.Acknowledged++
+			toAck = append(toAck, record.AcknowledgedAt.Sub(record.OpenedAt))
+		}
+	}
+	stats.MedianTimeToAckSeconds = int64(utils.MedianDuration(toAck).Seconds())
+	return stats
+}
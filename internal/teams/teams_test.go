@@ -0,0 +1,224 @@
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/store"
+)
+
+var week = time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC) // A Monday
+
+func record(repo string, number int, priority, state string, opened time.Time, toAck time.Duration) store.IssueRecord {
+	r := store.IssueRecord{
+		Repository: repo,
+		Number:     number,
+		State:      state,
+		Summary:    &ai.IssueSummary{Priority: priority},
+		OpenedAt:   opened,
+		AnalyzedAt: opened.Add(time.Minute),
+		MessageTS:  "1.1",
+	}
+	if toAck > 0 {
+		r.AcknowledgedAt = opened.Add(toAck)
+	}
+	return r
+}
+
+func testStore() *store.MemoryStore {
+	issues := store.NewMemoryStore()
+	for _, r := range []store.IssueRecord{
+		record("acme/payments-api", 1, "critical", "open", week.AddDate(0, 0, -6), time.Hour),
+		record("acme/payments-web", 2, "high", "closed", week.AddDate(0, 0, -5), 3*time.Hour),
+		record("acme/payments-api", 3, "low", "open", week.AddDate(0, 0, -1), 0),
+		record("Acme/Payments-API", 4, "medium", "open", week.AddDate(0, 0, -2), 2*time.Hour),
+		record("acme/payments-api", 5, "high", "open", week.AddDate(0, 0, -20), time.Hour), // Before the week
+		record("acme/search", 6, "critical", "open", week.AddDate(0, 0, -3), time.Hour),
+	} {
+		issues.SaveIssue(&r)
+	}
+	return issues
+}
+
+func testDirectory(t *testing.T) *Directory {
+	directory, err := NewDirectory([]Team{
+		{Name: "payments", Repositories: []string{"acme/payments-*"}, Channel: "C-PAY"},
+		{Name: "search", Repositories: []string{"acme/search"}},
+	})
+	if err != nil {
+		t.Fatalf("NewDirectory: %v", err)
+	}
+	return directory
+}
+
+func TestRollup(t *testing.T) {
+	team, _ := testDirectory(t).Team("payments")
+	records, _ := testStore().ListIssues(store.Query{})
+
+	stats := Rollup(team, records, week.AddDate(0, 0, -7), week)
+	if stats.Issues != 4 || stats.HighPriority != 2 || stats.Acknowledged != 3 {
+		t.Errorf("Expected 4 issues, 2 high priority and 3 acknowledged, got %+v", stats)
+	}
+	if stats.MedianTimeToAckSeconds != int64((2 * time.Hour).Seconds()) {
+		t.Errorf("Expected a median time to acknowledge of 2h, got %ds", stats.MedianTimeToAckSeconds)
+	}
+	if stats.OpenHighPriority != 2 {
+		t.Errorf("Expected 2 open high priority issues including older ones, got %d", stats.OpenHighPriority)
+	}
+	if stats.ByPriority["low"] != 1 || stats.ByPriority["critical"] != 1 {
+		t.Errorf("Unexpected priorities %v", stats.ByPriority)
+	}
+}
+
+func TestNewDirectoryValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		teams []Team
+	}{
+		{"invalid name", []Team{{Name: "Payments Team", Repositories: []string{"acme/*"}}}},
+		{"duplicate", []Team{{Name: "a", Repositories: []string{"acme/a"}}, {Name: "a", Repositories: []string{"acme/b"}}}},
+		{"no repositories", []Team{{Name: "a"}}},
+		{"invalid pattern", []Team{{Name: "a", Repositories: []string{"acme/[a"}}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewDirectory(tt.teams); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestServeStats(t *testing.T) {
+	h := NewHandler(testDirectory(t), testStore())
+	h.now = func() time.Time { return week }
+
+	rec := httptest.NewRecorder()
+	h.ServeStats(rec, httptest.NewRequest(http.MethodGet, "/api/teams/payments/stats?days=30", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil || stats.Team != "payments" || stats.Issues != 5 {
+		t.Errorf("Expected 5 payments issues in 30 days, got %+v (%v)", stats, err)
+	}
+
+	for target, want := range map[string]int{
+		"/api/teams/billing/stats":          http.StatusNotFound,
+		"/api/teams/payments/stats?days=0":  http.StatusBadRequest,
+		"/api/teams/payments/stats?days=ab": http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeStats(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+}
+
+type fakePoster struct {
+	channels []string
+	texts    []string
+	digests  []Digest
+}
+
+func (p *fakePoster) PostDigest(ctx context.Context, channelID string, digest Digest) error {
+	p.channels = append(p.channels, channelID)
+	p.texts = append(p.texts, digest.Text)
+	p.digests = append(p.digests, digest)
+	return nil
+}
+
+func TestPostDigests(t *testing.T) {
+	poster := &fakePoster{}
+	reporter := NewReporter(testDirectory(t), testStore(), poster, schedule.Schedule{Weekday: "monday", Hour: 9}, zap.NewNop())
+
+	reporter.PostDigests(context.Background(), week)
+	if len(poster.channels) != 1 || poster.channels[0] != "C-PAY" {
+		t.Fatalf("Expected a digest for the team with a channel only, got %v", poster.channels)
+	}
+	for _, want := range []string{"payments", "Issues opened: 4", "High priority: 2", "Median time to acknowledge: 2h0m0s"} {
+		if !strings.Contains(poster.texts[0], want) {
+			t.Errorf("Expected the digest to contain %q:\n%s", want, poster.texts[0])
+		}
+	}
+	if triage := poster.digests[0].Triage; len(triage) != 1 || triage[0].Number != 3 {
+		t.Errorf("Expected only the unacknowledged #3 awaiting triage, got %+v", triage)
+	}
+}
+
+func TestUntriaged(t *testing.T) {
+	team, _ := testDirectory(t).Team("payments")
+	issues := testStore()
+	for _, r := range []store.IssueRecord{
+		record("acme/payments-api", 7, "low", "open", week.AddDate(0, 0, -4), 0),
+		record("acme/payments-api", 8, "critical", "open", week.AddDate(0, 0, -1), 0),
+		record("acme/payments-web", 9, "high", "open", week.AddDate(0, 0, -2), 0),
+		record("acme/payments-web", 10, "high", "open", week.AddDate(0, 0, -3), 0),
+		record("acme/payments-web", 11, "critical", "closed", week.AddDate(0, 0, -3), 0),
+	} {
+		issues.SaveIssue(&r)
+	}
+	triaged := record("acme/payments-web", 12, "critical", "open", week.AddDate(0, 0, -3), 0)
+	triaged.TriagedAt = week
+	snoozed := record("acme/payments-web", 13, "critical", "open", week.AddDate(0, 0, -3), 0)
+	snoozed.SnoozedUntil = week.Add(time.Hour)
+	woken := record("acme/payments-web", 14, "medium", "open", week.AddDate(0, 0, -3), 0)
+	woken.SnoozedUntil = week.Add(-time.Hour)
+	held := record("acme/payments-web", 15, "critical", "open", week.AddDate(0, 0, -3), 0)
+	held.MessageTS = ""
+	for _, r := range []store.IssueRecord{triaged, snoozed, woken, held} {
+		issues.SaveIssue(&r)
+	}
+	records, _ := issues.ListIssues(store.Query{})
+
+	var got []int
+	for _, issue := range Untriaged(team, records, week) {
+		got = append(got, issue.Number)
+	}
+	// #3 and #7 are low priority, #7 opened earlier
+	want := []int{8, 10, 9, 14, 7, 3}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected issues %v awaiting triage, got %v", want, got)
+	}
+}
+
+func TestPostDueInTeamTimezones(t *testing.T) {
+	directory, err := NewDirectory([]Team{
+		{Name: "payments", Repositories: []string{"acme/payments-*"}, Channel: "C-PAY"},
+		{Name: "search", Repositories: []string{"acme/search"}, Channel: "C-SEARCH", Timezone: "Pacific/Auckland"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	poster := &fakePoster{}
+	reporter := NewReporter(directory, testStore(), poster, schedule.Schedule{Weekday: "monday", Hour: 9}, zap.NewNop())
+	posted := map[string]time.Time{"payments": week.AddDate(0, 0, -7), "search": week.AddDate(0, 0, -7).Add(-13 * time.Hour)}
+
+	// 09:00 on Monday in Auckland is 20:00 on Sunday UTC
+	reporter.postDue(context.Background(), week.Add(-13*time.Hour), posted)
+	if len(poster.channels) != 1 || poster.channels[0] != "C-SEARCH" {
+		t.Fatalf("Expected only the Auckland team's digest due, got %v", poster.channels)
+	}
+	if !strings.Contains(poster.texts[0], "(Feb 26 to Mar 4)") {
+		t.Errorf("Expected the digest dated in Auckland time:\n%s", poster.texts[0])
+	}
+
+	reporter.postDue(context.Background(), week.Add(-13*time.Hour+schedule.CheckInterval), posted)
+	reporter.postDue(context.Background(), week, posted)
+	if len(poster.channels) != 2 || poster.channels[1] != "C-PAY" {
+		t.Errorf("Expected each digest posted once, got %v", poster.channels)
+	}
+
+	if _, err := NewDirectory([]Team{{Name: "ops", Repositories: []string{"acme/*"}, Timezone: "Mars/Olympus"}}); err == nil {
+		t.Error("Expected an error for an unknown team timezone")
+	}
+}
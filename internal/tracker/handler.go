@@ -0,0 +1,75 @@
+package tracker
+
+import (
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// maxPayloadBytes bounds the webhook bodies read
+const maxPayloadBytes = 1 << 20
+
+// ServeLinear receives Linear data change webhooks and applies the state
+// changes of linked issues
+func (s *Syncer) ServeLinear(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readWebhook(w, r, TypeLinear, verifyLinear)
+	if !ok {
+		return
+	}
+	change, err := parseLinear(body)
+	if err != nil {
+		http.Error(w, "Invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+	if change != nil {
+		s.apply(r, *change)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// ServeShortcut receives Shortcut outgoing webhooks and applies the workflow
+// state changes of linked stories
+func (s *Syncer) ServeShortcut(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readWebhook(w, r, TypeShortcut, verifyShortcut)
+	if !ok {
+		return
+	}
+	changes, err := parseShortcut(body)
+	if err != nil {
+		http.Error(w, "Invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+	for _, change := range changes {
+		s.apply(r, change)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// readWebhook reads and verifies a webhook body, answering the request if
+// it cannot be used
+func (s *Syncer) readWebhook(w http.ResponseWriter, r *http.Request, tracker string, verify func(string, http.Header, []byte) bool) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadBytes))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	if !verify(s.secret(tracker), r.Header, body) {
+		s.logger.Warn("Invalid tracker webhook signature", zap.String("tracker", tracker))
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return nil, false
+	}
+	return body, true
+}
+
+// apply applies a state change within the request. Failures are logged
+// rather than returned, since the tracker would retry a change that was
+// already partly reported.
+func (s *Syncer) apply(r *http.Request, change StateChange) {
+	if err := s.ApplyStateChange(r.Context(), change); err != nil {
+		s.logger.Warn("Failed to apply tracker state change",
+			zap.String("tracker", change.Tracker),
+			zap.String("ticket_id", change.ID),
+			zap.Error(err))
+	}
+}
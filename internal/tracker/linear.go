@@ -0,0 +1,147 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// linearEndpoint is Linear's GraphQL API
+const linearEndpoint = "https://api.linear.app/graphql"
+
+// linearPriorities maps AI priorities to Linear's, where 1 is urgent
+var linearPriorities = map[string]int{"critical": 1, "high": 2, "medium": 3, "low": 4}
+
+const linearCreateIssue = `mutation IssueCreate($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { id identifier url } }
+}`
+
+// Linear files tickets as Linear issues
+type Linear struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewLinear creates a Linear client authenticated with a personal API key
+func NewLinear(apiKey string) *Linear {
+	return &Linear{apiKey: apiKey, endpoint: linearEndpoint, httpClient: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Name returns linear
+func (l *Linear) Name() string {
+	return TypeLinear
+}
+
+// CreateTicket creates an issue in the team whose ID is project
+func (l *Linear) CreateTicket(ctx context.Context, project string, ticket Ticket) (Link, error) {
+	input := map[string]interface{}{
+		"teamId":      project,
+		"title":       ticket.Title,
+		"description": ticket.Description,
+	}
+	if priority, ok := linearPriorities[ticket.Priority]; ok {
+		input["priority"] = priority
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     linearCreateIssue,
+		"variables": map[string]interface{}{"input": input},
+	})
+	if err != nil {
+		return Link{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Link{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", l.apiKey)
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return Link{}, fmt.Errorf("linear request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data struct {
+			IssueCreate struct {
+				Success bool `json:"success"`
+				Issue   struct {
+					ID         string `json:"id"`
+					Identifier string `json:"identifier"`
+					URL        string `json:"url"`
+				} `json:"issue"`
+			} `json:"issueCreate"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Link{}, fmt.Errorf("linear returned status %d", resp.StatusCode)
+	}
+	if len(result.Errors) > 0 {
+		return Link{}, fmt.Errorf("linear returned an error: %s", result.Errors[0].Message)
+	}
+	created := result.Data.IssueCreate
+	if resp.StatusCode != http.StatusOK || !created.Success || created.Issue.ID == "" {
+		return Link{}, fmt.Errorf("linear did not create the issue (status %d)", resp.StatusCode)
+	}
+	return Link{Tracker: TypeLinear, ID: created.Issue.ID, Key: created.Issue.Identifier, URL: created.Issue.URL}, nil
+}
+
+// linearWebhook is the part of a Linear data change webhook the syncer reads
+type linearWebhook struct {
+	Action string `json:"action"`
+	Type   string `json:"type"`
+	Data   struct {
+		ID    string `json:"id"`
+		State struct {
+			Name string `json:"name"`
+			Type string `json:"type"` // triage, backlog, unstarted, started, completed or canceled
+		} `json:"state"`
+	} `json:"data"`
+	UpdatedFrom map[string]interface{} `json:"updatedFrom"`
+}
+
+// verifyLinear checks the Linear-Signature header, the hex HMAC-SHA256 of
+// the body
+func verifyLinear(secret string, header http.Header, body []byte) bool {
+	signature := header.Get("Linear-Signature")
+	if secret == "" || signature == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
+
+// parseLinear reads the state change from a Linear webhook, and returns nil
+// for other changes
+func parseLinear(body []byte) (*StateChange, error) {
+	var webhook linearWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse Linear webhook: %w", err)
+	}
+	if webhook.Type != "Issue" || webhook.Action != "update" || webhook.Data.ID == "" {
+		return nil, nil
+	}
+	if _, ok := webhook.UpdatedFrom["stateId"]; !ok {
+		return nil, nil
+	}
+	state := webhook.Data.State
+	return &StateChange{
+		Tracker:  TypeLinear,
+		ID:       webhook.Data.ID,
+		State:    state.Name,
+		Done:     state.Type == "completed" || state.Type == "canceled",
+		Canceled: state.Type == "canceled",
+	}, nil
+}
@@ -0,0 +1,146 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// shortcutBaseURL is Shortcut's REST API
+const shortcutBaseURL = "https://api.app.shortcut.com/api/v3"
+
+// Shortcut files tickets as Shortcut stories
+type Shortcut struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewShortcut creates a Shortcut client authenticated with an API token
+func NewShortcut(token string) *Shortcut {
+	return &Shortcut{token: token, baseURL: shortcutBaseURL, httpClient: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Name returns shortcut
+func (s *Shortcut) Name() string {
+	return TypeShortcut
+}
+
+// CreateTicket creates a story in the workflow state whose ID is project
+func (s *Shortcut) CreateTicket(ctx context.Context, project string, ticket Ticket) (Link, error) {
+	stateID, err := strconv.ParseInt(project, 10, 64)
+	if err != nil {
+		return Link{}, fmt.Errorf("invalid Shortcut workflow state ID %q", project)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"name":              ticket.Title,
+		"description":       ticket.Description,
+		"workflow_state_id": stateID,
+		"story_type":        shortcutStoryType(ticket.Category),
+	})
+	if err != nil {
+		return Link{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/stories", bytes.NewReader(body))
+	if err != nil {
+		return Link{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Shortcut-Token", s.token)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return Link{}, fmt.Errorf("shortcut request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return Link{}, fmt.Errorf("shortcut returned status %d", resp.StatusCode)
+	}
+
+	var story struct {
+		ID     int64  `json:"id"`
+		AppURL string `json:"app_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&story); err != nil || story.ID == 0 {
+		return Link{}, fmt.Errorf("shortcut returned an invalid story")
+	}
+	id := strconv.FormatInt(story.ID, 10)
+	return Link{Tracker: TypeShortcut, ID: id, Key: "sc-" + id, URL: story.AppURL}, nil
+}
+
+// shortcutStoryType returns the story type for an AI category
+func shortcutStoryType(category string) string {
+	switch category {
+	case "bug", "security", "performance":
+		return "bug"
+	case "feature", "enhancement":
+		return "feature"
+	}
+	return "chore"
+}
+
+// shortcutWebhook is the part of a Shortcut outgoing webhook the syncer
+// reads
+type shortcutWebhook struct {
+	Actions []struct {
+		ID         int64  `json:"id"`
+		EntityType string `json:"entity_type"`
+		Action     string `json:"action"`
+		Changes    struct {
+			WorkflowStateID *struct {
+				New int64 `json:"new"`
+			} `json:"workflow_state_id"`
+		} `json:"changes"`
+	} `json:"actions"`
+	References []struct {
+		ID         json.Number `json:"id"`
+		EntityType string      `json:"entity_type"`
+		Name       string      `json:"name"`
+		Type       string      `json:"type"` // unstarted, started or done for workflow states
+	} `json:"references"`
+}
+
+// verifyShortcut checks the Payload-Signature header, the hex HMAC-SHA256 of
+// the body
+func verifyShortcut(secret string, header http.Header, body []byte) bool {
+	signature := header.Get("Payload-Signature")
+	if secret == "" || signature == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
+
+// parseShortcut reads the workflow state changes of stories from a Shortcut
+// webhook
+func parseShortcut(body []byte) ([]StateChange, error) {
+	var webhook shortcutWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to parse Shortcut webhook: %w", err)
+	}
+
+	var changes []StateChange
+	for _, action := range webhook.Actions {
+		if action.EntityType != "story" || action.Action != "update" || action.Changes.WorkflowStateID == nil {
+			continue
+		}
+		stateID := strconv.FormatInt(action.Changes.WorkflowStateID.New, 10)
+		change := StateChange{Tracker: TypeShortcut, ID: strconv.FormatInt(action.ID, 10), State: stateID}
+		for _, ref := range webhook.References {
+			if ref.EntityType == "workflow-state" && ref.ID.String() == stateID {
+				change.State, change.Done = ref.Name, ref.Type == "done"
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
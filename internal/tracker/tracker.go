@@ -0,0 +1,359 @@
+// Package tracker links actionable GitHub issues to tickets in Linear or
+// Shortcut, and reports the tickets' state changes back on the issue and in
+// its Slack thread
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+// Trackers
+const (
+	TypeLinear   = "linear"
+	TypeShortcut = "shortcut"
+)
+
+// DefaultMinPriority is the lowest priority linked when a mapping sets none
+const DefaultMinPriority = "medium"
+
+// Mapping links the actionable issues of matching repositories to a project
+// in a tracker. Mappings are read from the trackers key of the config file;
+// the first one matching an issue's repository applies.
+type Mapping struct {
+	Repositories []string `mapstructure:"repositories" json:"repositories,omitempty"` // Glob patterns, e.g. "my-org/*"; empty matches every repository
+	Tracker      string   `mapstructure:"tracker" json:"tracker"`
+	Project      string   `mapstructure:"project" json:"project"`                     // Linear team ID, or ID of the Shortcut workflow state new stories start in
+	MinPriority  string   `mapstructure:"min_priority" json:"min_priority,omitempty"` // Lowest priority that is actionable, medium by default
+	Categories   []string `mapstructure:"categories" json:"categories,omitempty"`     // Categories that are actionable, empty for all
+	CloseIssues  bool     `mapstructure:"close_issues" json:"close_issues"`           // Close the issue when its ticket is completed or canceled
+}
+
+// ValidateMappings checks the trackers, projects, priorities and patterns
+func ValidateMappings(mappings []Mapping) error {
+	for i, m := range mappings {
+		switch m.Tracker {
+		case TypeLinear:
+		case TypeShortcut:
+			if _, err := strconv.ParseInt(m.Project, 10, 64); err != nil {
+				return fmt.Errorf("tracker mapping %d: Shortcut project must be a workflow state ID, got %q", i, m.Project)
+			}
+		default:
+			return fmt.Errorf("tracker mapping %d: unknown tracker %q", i, m.Tracker)
+		}
+		if m.Project == "" {
+			return fmt.Errorf("tracker mapping %d: project is required", i)
+		}
+		if m.MinPriority != "" && !ai.PriorityAtLeast(m.MinPriority, "low") {
+			return fmt.Errorf("tracker mapping %d: min_priority must be critical, high, medium or low, got %q", i, m.MinPriority)
+		}
+		for _, pattern := range m.Repositories {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("tracker mapping %d: invalid repository pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m Mapping) matches(repository string) bool {
+	if len(m.Repositories) == 0 {
+		return true
+	}
+	repository = strings.ToLower(repository)
+	for _, pattern := range m.Repositories {
+		if ok, _ := path.Match(strings.ToLower(pattern), repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// actionable reports whether a summary calls for a ticket
+func (m Mapping) actionable(summary *ai.IssueSummary) bool {
+	minPriority := m.MinPriority
+	if minPriority == "" {
+		minPriority = DefaultMinPriority
+	}
+	if !ai.PriorityAtLeast(summary.Priority, minPriority) {
+		return false
+	}
+	if len(m.Categories) == 0 {
+		return true
+	}
+	for _, category := range m.Categories {
+		if strings.EqualFold(category, summary.Category) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ticket is what is filed in a tracker for an issue
+type Ticket struct {
+	Title       string
+	Description string // Markdown
+	Priority    string // AI priority
+	Category    string // AI category
+}
+
+// Link is a ticket linked to an issue
+type Link struct {
+	Tracker     string `json:"tracker"`
+	ID          string `json:"id"`  // Tracker's ID, as sent in its webhooks
+	Key         string `json:"key"` // Readable reference, e.g. ENG-123
+	URL         string `json:"url"`
+	Repository  string `json:"repository"`
+	Number      int    `json:"number"`
+	State       string `json:"state,omitempty"`
+	CloseIssues bool   `json:"close_issues"`
+}
+
+// Tracker files tickets in an issue tracker
+type Tracker interface {
+	Name() string
+	CreateTicket(ctx context.Context, project string, ticket Ticket) (Link, error)
+}
+
+// StateChange is a linked ticket moving to another state
+type StateChange struct {
+	Tracker  string
+	ID       string
+	State    string
+	Done     bool // Completed or canceled
+	Canceled bool
+}
+
+// IssueLookup finds the Slack message of an issue
+type IssueLookup interface {
+	GetIssue(repository string, number int) (*store.IssueRecord, bool)
+}
+
+// IssueCommenter reports ticket state changes on GitHub
+type IssueCommenter interface {
+	CommentTicketState(ctx context.Context, repo string, number int, update github.TicketUpdate, close bool) error
+}
+
+// ThreadPoster replies in the Slack thread of an issue's summary
+type ThreadPoster interface {
+	PostThreadReply(ctx context.Context, channelID, threadTS, text string) error
+}
+
+// Syncer links issues to tickets and applies the tickets' state changes.
+// Links are kept in memory.
+type Syncer struct {
+	mu        sync.Mutex
+	mappings  []Mapping
+	trackers  map[string]Tracker
+	secrets   map[string]string // Webhook secrets by tracker
+	issues    IssueLookup
+	commenter IssueCommenter
+	replies   ThreadPoster
+	logger    *zap.Logger
+
+	links   map[string]*Link // By tracker and ticket ID
+	byIssue map[string]*Link // By repository and number; nil values are links being created
+}
+
+// NewSyncer creates a syncer for mappings. Every tracker a mapping names
+// must be in trackers.
+func NewSyncer(mappings []Mapping, trackers []Tracker, issues IssueLookup, commenter IssueCommenter, replies ThreadPoster, logger *zap.Logger) (*Syncer, error) {
+	if err := ValidateMappings(mappings); err != nil {
+		return nil, err
+	}
+	s := &Syncer{
+		mappings:  mappings,
+		trackers:  make(map[string]Tracker),
+		secrets:   make(map[string]string),
+		issues:    issues,
+		commenter: commenter,
+		replies:   replies,
+		logger:    logger,
+		links:     make(map[string]*Link),
+		byIssue:   make(map[string]*Link),
+	}
+	for _, t := range trackers {
+		s.trackers[t.Name()] = t
+	}
+	for i, m := range mappings {
+		if s.trackers[m.Tracker] == nil {
+			return nil, fmt.Errorf("tracker mapping %d: %s is not configured", i, m.Tracker)
+		}
+	}
+	return s, nil
+}
+
+// SetWebhookSecret sets the secret that verifies a tracker's webhooks
+func (s *Syncer) SetWebhookSecret(tracker, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[tracker] = secret
+}
+
+func (s *Syncer) secret(tracker string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.secrets[tracker]
+}
+
+// LinkIssue files a ticket for an actionable issue in the project of the
+// first mapping matching its repository, unless the issue already has one,
+// and replies with a link in the issue's Slack thread
+func (s *Syncer) LinkIssue(ctx context.Context, issueData *github.IssueData, summary *ai.IssueSummary) error {
+	repository := issueData.Repository.GetFullName()
+	number := issueData.Issue.GetNumber()
+	var mapping *Mapping
+	for i := range s.mappings {
+		if s.mappings[i].matches(repository) {
+			mapping = &s.mappings[i]
+			break
+		}
+	}
+	if mapping == nil || !mapping.actionable(summary) {
+		return nil
+	}
+
+	key := issueKey(repository, number)
+	s.mu.Lock()
+	if _, ok := s.byIssue[key]; ok {
+		s.mu.Unlock()
+		return nil
+	}
+	s.byIssue[key] = nil
+	s.mu.Unlock()
+
+	link, err := s.trackers[mapping.Tracker].CreateTicket(ctx, mapping.Project, newTicket(issueData, summary))
+	s.mu.Lock()
+	if err != nil {
+		delete(s.byIssue, key)
+		s.mu.Unlock()
+		return fmt.Errorf("failed to create %s ticket: %w", mapping.Tracker, err)
+	}
+	link.Repository, link.Number, link.CloseIssues = repository, number, mapping.CloseIssues
+	s.byIssue[key] = &link
+	s.links[ticketKey(link.Tracker, link.ID)] = &link
+	s.mu.Unlock()
+
+	s.logger.Info("Linked issue to tracker ticket",
+		zap.String("repository", repository),
+		zap.Int("issue_number", number),
+		zap.String("tracker", link.Tracker),
+		zap.String("ticket", link.Key))
+	s.reply(ctx, link, fmt.Sprintf("Linked to %s ticket <%s|%s>", trackerName(link.Tracker), link.URL, link.Key))
+	return nil
+}
+
+// Link returns the ticket linked to an issue
+func (s *Syncer) Link(repository string, number int) (Link, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link := s.byIssue[issueKey(repository, number)]
+	if link == nil {
+		return Link{}, false
+	}
+	return *link, true
+}
+
+// ApplyStateChange reports a linked ticket's new state on GitHub and in
+// Slack, closing the issue when its mapping asks for it. Changes of tickets
+// that are not linked, or that repeat the last known state, are ignored.
+func (s *Syncer) ApplyStateChange(ctx context.Context, change StateChange) error {
+	s.mu.Lock()
+	stored := s.links[ticketKey(change.Tracker, change.ID)]
+	if stored == nil || stored.State == change.State {
+		s.mu.Unlock()
+		return nil
+	}
+	stored.State = change.State
+	link := *stored
+	s.mu.Unlock()
+
+	s.logger.Info("Linked ticket changed state",
+		zap.String("repository", link.Repository),
+		zap.Int("issue_number", link.Number),
+		zap.String("ticket", link.Key),
+		zap.String("state", change.State))
+
+	update := github.TicketUpdate{Tracker: trackerName(link.Tracker), Key: link.Key, URL: link.URL, State: change.State, Canceled: change.Canceled}
+	closeIssue := link.CloseIssues && change.Done
+	text := fmt.Sprintf("%s ticket <%s|%s> moved to *%s*", update.Tracker, link.URL, link.Key, change.State)
+	if closeIssue {
+		text += ", closing the issue"
+	}
+	s.reply(ctx, link, text)
+	if err := s.commenter.CommentTicketState(ctx, link.Repository, link.Number, update, closeIssue); err != nil {
+		return fmt.Errorf("failed to update issue %s#%d: %w", link.Repository, link.Number, err)
+	}
+	return nil
+}
+
+// reply posts text in the Slack thread of the linked issue's summary, if it
+// has one. Failures are logged.
+func (s *Syncer) reply(ctx context.Context, link Link, text string) {
+	if s.replies == nil {
+		return
+	}
+	record, ok := s.issues.GetIssue(link.Repository, link.Number)
+	if !ok || record.MessageTS == "" {
+		return
+	}
+	if err := s.replies.PostThreadReply(ctx, record.Channel, record.MessageTS, text); err != nil {
+		s.logger.Warn("Failed to reply in issue thread",
+			zap.String("repository", link.Repository),
+			zap.Int("issue_number", link.Number),
+			zap.Error(err))
+	}
+}
+
+// newTicket writes the summary of an issue as a ticket linking back to it
+func newTicket(issueData *github.IssueData, summary *ai.IssueSummary) Ticket {
+	var b strings.Builder
+	if summary.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", summary.Summary)
+	}
+	if len(summary.ActionItems) > 0 {
+		b.WriteString("**Action items**\n")
+		for _, item := range summary.ActionItems {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Priority: %s · Category: %s\n\n", summary.Priority, summary.Category)
+	fmt.Fprintf(&b, "GitHub issue: [%s#%d](%s)", issueData.Repository.GetFullName(), issueData.Issue.GetNumber(), issueData.Issue.GetHTMLURL())
+
+	return Ticket{
+		Title:       issueData.Issue.GetTitle(),
+		Description: b.String(),
+		Priority:    strings.ToLower(summary.Priority),
+		Category:    strings.ToLower(summary.Category),
+	}
+}
+
+// trackerName returns the product name of a tracker
+func trackerName(tracker string) string {
+	switch tracker {
+	case TypeLinear:
+		return "Linear"
+	case TypeShortcut:
+		return "Shortcut"
+	}
+	return tracker
+}
+
+func issueKey(repository string, number int) string {
+	return fmt.Sprintf("%s#%d", strings.ToLower(repository), number)
+}
+
+func ticketKey(tracker, id string) string {
+	return tracker + "/" + id
+}
@@ -0,0 +1,258 @@
+package tracker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/store"
+)
+
+type fakeTracker struct {
+	name    string
+	tickets []Ticket
+}
+
+func (f *fakeTracker) Name() string { return f.name }
+
+func (f *fakeTracker) CreateTicket(ctx context.Context, project string, ticket Ticket) (Link, error) {
+	f.tickets = append(f.tickets, ticket)
+	id := fmt.Sprint(len(f.tickets))
+	return Link{Tracker: f.name, ID: "id-" + id, Key: "ENG-" + id, URL: "https://linear.app/acme/issue/ENG-" + id}, nil
+}
+
+type fakeCommenter struct{ updates []string }
+
+func (f *fakeCommenter) CommentTicketState(ctx context.Context, repo string, number int, update github.TicketUpdate, close bool) error {
+	f.updates = append(f.updates, fmt.Sprintf("%s#%d %s %s close=%v", repo, number, update.Key, update.State, close))
+	return nil
+}
+
+type fakeReplies struct{ replies []string }
+
+func (f *fakeReplies) PostThreadReply(ctx context.Context, channelID, threadTS, text string) error {
+	f.replies = append(f.replies, channelID+" "+threadTS+" "+text)
+	return nil
+}
+
+func issueData(repository string, number int) *github.IssueData {
+	return &github.IssueData{
+		Issue: &gogithub.Issue{
+			Number:  gogithub.Int(number),
+			Title:   gogithub.String("Checkout crashes"),
+			HTMLURL: gogithub.String(fmt.Sprintf("https://github.com/%s/issues/%d", repository, number)),
+		},
+		Repository: &gogithub.Repository{FullName: gogithub.String(repository)},
+	}
+}
+
+func testSyncer(t *testing.T) (*Syncer, *fakeTracker, *fakeCommenter, *fakeReplies) {
+	t.Helper()
+	issues := store.NewMemoryStore()
+	issues.SaveIssue(&store.IssueRecord{Repository: "acme/api", Number: 7, Channel: "C1", MessageTS: "111.1"})
+	linear := &fakeTracker{name: TypeLinear}
+	commenter, replies := &fakeCommenter{}, &fakeReplies{}
+	syncer, err := NewSyncer([]Mapping{
+		{Repositories: []string{"acme/docs"}, Tracker: TypeLinear, Project: "team-docs", Categories: []string{"documentation"}},
+		{Repositories: []string{"acme/*"}, Tracker: TypeLinear, Project: "team-eng", MinPriority: "high", CloseIssues: true},
+	}, []Tracker{linear}, issues, commenter, replies, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return syncer, linear, commenter, replies
+}
+
+func TestLinkIssue(t *testing.T) {
+	syncer, linear, _, replies := testSyncer(t)
+	ctx := context.Background()
+
+	// Below the mapping's priority, or outside its categories
+	syncer.LinkIssue(ctx, issueData("acme/api", 7), &ai.IssueSummary{Priority: "medium", Category: "bug"})
+	syncer.LinkIssue(ctx, issueData("acme/docs", 1), &ai.IssueSummary{Priority: "critical", Category: "bug"})
+	syncer.LinkIssue(ctx, issueData("other/api", 1), &ai.IssueSummary{Priority: "critical", Category: "bug"})
+	if len(linear.tickets) != 0 {
+		t.Fatalf("Expected no tickets for issues that are not actionable, got %+v", linear.tickets)
+	}
+
+	summary := &ai.IssueSummary{Summary: "Checkout panics on empty carts.", Priority: "High", Category: "bug", ActionItems: []string{"Guard empty carts"}}
+	if err := syncer.LinkIssue(ctx, issueData("acme/api", 7), summary); err != nil {
+		t.Fatal(err)
+	}
+	syncer.LinkIssue(ctx, issueData("Acme/API", 7), summary) // Linked already
+	if len(linear.tickets) != 1 {
+		t.Fatalf("Expected one ticket, got %d", len(linear.tickets))
+	}
+	ticket := linear.tickets[0]
+	if ticket.Title != "Checkout crashes" || ticket.Priority != "high" ||
+		!strings.Contains(ticket.Description, "- Guard empty carts") ||
+		!strings.Contains(ticket.Description, "[acme/api#7](https://github.com/acme/api/issues/7)") {
+		t.Errorf("Unexpected ticket %+v", ticket)
+	}
+	if link, ok := syncer.Link("acme/api", 7); !ok || link.Key != "ENG-1" || !link.CloseIssues {
+		t.Errorf("Expected the link kept, got %+v", link)
+	}
+	if len(replies.replies) != 1 || replies.replies[0] != "C1 111.1 Linked to Linear ticket <https://linear.app/acme/issue/ENG-1|ENG-1>" {
+		t.Errorf("Expected the link posted in the thread, got %q", replies.replies)
+	}
+}
+
+func TestApplyStateChange(t *testing.T) {
+	syncer, _, commenter, replies := testSyncer(t)
+	ctx := context.Background()
+	syncer.LinkIssue(ctx, issueData("acme/api", 7), &ai.IssueSummary{Priority: "critical", Category: "bug"})
+
+	syncer.ApplyStateChange(ctx, StateChange{Tracker: TypeLinear, ID: "id-1", State: "In Progress"})
+	syncer.ApplyStateChange(ctx, StateChange{Tracker: TypeLinear, ID: "id-1", State: "In Progress"}) // Repeated
+	syncer.ApplyStateChange(ctx, StateChange{Tracker: TypeLinear, ID: "id-9", State: "Done"})        // Not linked
+	syncer.ApplyStateChange(ctx, StateChange{Tracker: TypeLinear, ID: "id-1", State: "Done", Done: true})
+
+	want := []string{"acme/api#7 ENG-1 In Progress close=false", "acme/api#7 ENG-1 Done close=true"}
+	if fmt.Sprint(commenter.updates) != fmt.Sprint(want) {
+		t.Errorf("Expected %q, got %q", want, commenter.updates)
+	}
+	if len(replies.replies) != 3 || !strings.HasSuffix(replies.replies[2], "moved to *Done*, closing the issue") {
+		t.Errorf("Expected the changes posted in the thread, got %q", replies.replies)
+	}
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestServeLinear(t *testing.T) {
+	syncer, _, commenter, _ := testSyncer(t)
+	syncer.SetWebhookSecret(TypeLinear, "linear-secret")
+	syncer.LinkIssue(context.Background(), issueData("acme/api", 7), &ai.IssueSummary{Priority: "critical", Category: "bug"})
+
+	body := `{"action": "update", "type": "Issue", "data": {"id": "id-1", "state": {"name": "Canceled", "type": "canceled"}}, "updatedFrom": {"stateId": "s1"}}`
+	req := httptest.NewRequest(http.MethodPost, "/webhook/linear", strings.NewReader(body))
+	req.Header.Set("Linear-Signature", sign("wrong", body))
+	rec := httptest.NewRecorder()
+	syncer.ServeLinear(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a bad signature rejected, got %d", rec.Code)
+	}
+
+	// Edits that keep the state are not reported
+	for _, body := range []string{
+		`{"action": "update", "type": "Issue", "data": {"id": "id-1", "state": {"name": "Todo"}}, "updatedFrom": {"title": "Old"}}`,
+		body,
+	} {
+		req = httptest.NewRequest(http.MethodPost, "/webhook/linear", strings.NewReader(body))
+		req.Header.Set("Linear-Signature", sign("linear-secret", body))
+		rec = httptest.NewRecorder()
+		syncer.ServeLinear(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected the webhook accepted, got %d", rec.Code)
+		}
+	}
+	if len(commenter.updates) != 1 || commenter.updates[0] != "acme/api#7 ENG-1 Canceled close=true" {
+		t.Errorf("Expected the cancellation reported, got %q", commenter.updates)
+	}
+}
+
+func TestParseShortcut(t *testing.T) {
+	changes, err := parseShortcut([]byte(`{
+		"actions": [
+			{"id": 123, "entity_type": "story", "action": "update", "changes": {"workflow_state_id": {"old": 500, "new": 501}}},
+			{"id": 124, "entity_type": "story", "action": "update", "changes": {"name": {"old": "a", "new": "b"}}},
+			{"id": 9, "entity_type": "story-comment", "action": "create"}
+		],
+		"references": [{"id": 501, "entity_type": "workflow-state", "name": "Done", "type": "done"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0] != (StateChange{Tracker: TypeShortcut, ID: "123", State: "Done", Done: true}) {
+		t.Errorf("Expected the story's move to Done, got %+v", changes)
+	}
+}
+
+func TestLinearCreateTicket(t *testing.T) {
+	var input map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_key" {
+			t.Errorf("Expected the API key, got %q", r.Header.Get("Authorization"))
+		}
+		var request struct {
+			Variables struct {
+				Input map[string]interface{} `json:"input"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		input = request.Variables.Input
+		fmt.Fprint(w, `{"data": {"issueCreate": {"success": true, "issue": {"id": "uuid-1", "identifier": "ENG-42", "url": "https://linear.app/acme/issue/ENG-42"}}}}`)
+	}))
+	defer server.Close()
+
+	linear := NewLinear("lin_api_key")
+	linear.endpoint = server.URL
+	link, err := linear.CreateTicket(context.Background(), "team-1", Ticket{Title: "Crash", Description: "It crashes", Priority: "critical"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != (Link{Tracker: TypeLinear, ID: "uuid-1", Key: "ENG-42", URL: "https://linear.app/acme/issue/ENG-42"}) {
+		t.Errorf("Unexpected link %+v", link)
+	}
+	if input["teamId"] != "team-1" || input["priority"] != float64(1) {
+		t.Errorf("Expected the team and urgent priority, got %+v", input)
+	}
+}
+
+func TestShortcutCreateTicket(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stories" || r.Header.Get("Shortcut-Token") != "sc-token" {
+			t.Errorf("Unexpected request %s with token %q", r.URL.Path, r.Header.Get("Shortcut-Token"))
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 77, "app_url": "https://app.shortcut.com/acme/story/77"}`)
+	}))
+	defer server.Close()
+
+	shortcut := NewShortcut("sc-token")
+	shortcut.baseURL = server.URL
+	link, err := shortcut.CreateTicket(context.Background(), "500", Ticket{Title: "Crash", Category: "security"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.ID != "77" || link.Key != "sc-77" || link.URL != "https://app.shortcut.com/acme/story/77" {
+		t.Errorf("Unexpected link %+v", link)
+	}
+	if request["workflow_state_id"] != float64(500) || request["story_type"] != "bug" {
+		t.Errorf("Expected the workflow state and bug type, got %+v", request)
+	}
+}
+
+func TestValidateMappings(t *testing.T) {
+	for _, mappings := range [][]Mapping{
+		{{Tracker: "jira", Project: "X"}},
+		{{Tracker: TypeLinear}},
+		{{Tracker: TypeShortcut, Project: "backlog"}},
+		{{Tracker: TypeLinear, Project: "team", MinPriority: "urgent"}},
+		{{Tracker: TypeLinear, Project: "team", Repositories: []string{"["}}},
+	} {
+		if ValidateMappings(mappings) == nil {
+			t.Errorf("Expected %+v to be invalid", mappings)
+		}
+	}
+
+	if _, err := NewSyncer([]Mapping{{Tracker: TypeShortcut, Project: "500"}}, nil, nil, nil, nil, zap.NewNop()); err == nil {
+		t.Error("Expected a mapping to an unconfigured tracker to fail")
+	}
+}
@@ -0,0 +1,105 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/schedule"
+)
+
+// maxReportDays bounds the period /api/usage reports on, the days kept
+const maxReportDays = 35
+
+// Config sets where and when the weekly report is posted. The report's dates
+// are in the schedule's timezone.
+type Config struct {
+	Channel string // Slack channel for the weekly report, empty to serve it from the API only
+	schedule.Schedule
+}
+
+// Validate checks the schedule. An empty weekday disables the weekly report.
+func (c Config) Validate() error {
+	if c.Weekday == "" {
+		return nil
+	}
+	return c.Schedule.Validate("usage report")
+}
+
+// Poster posts a message to a Slack channel
+type Poster interface {
+	PostMessage(ctx context.Context, channelID, kind, text string) error
+}
+
+// Reporter posts the week's usage to a Slack channel and serves reports
+type Reporter struct {
+	tracker    *Tracker
+	poster     Poster
+	config     Config
+	adminToken *admin.Token
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewReporter creates a reporter. poster may be nil when config has no
+// channel.
+func NewReporter(tracker *Tracker, poster Poster, config Config, adminToken *admin.Token, logger *zap.Logger) *Reporter {
+	return &Reporter{
+		tracker:    tracker,
+		poster:     poster,
+		config:     config,
+		adminToken: adminToken,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run posts the usage of the week before each scheduled time until the
+// context is cancelled. A report due before Run started is not posted, so a
+// restart does not post the week's report again.
+func (r *Reporter) Run(ctx context.Context) {
+	if r.config.Channel == "" || r.config.Weekday == "" || r.poster == nil {
+		return
+	}
+	r.config.Run(ctx, r.now, func(due time.Time) {
+		r.PostReport(ctx, due)
+	})
+}
+
+// PostReport posts the usage of the week before until to the channel, dated
+// in the report's timezone
+func (r *Reporter) PostReport(ctx context.Context, until time.Time) {
+	until = until.In(r.config.Location())
+	report := r.tracker.Report(until.AddDate(0, 0, -7), until)
+	if err := r.poster.PostMessage(ctx, r.config.Channel, "usage_report", FormatReport(report)); err != nil {
+		r.logger.Warn("Failed to post usage report",
+			zap.String("channel", r.config.Channel),
+			zap.Error(err))
+	}
+}
+
+// ServeReport returns the usage of the last days given by the days
+// parameter, 7 by default. Requests need the admin token.
+func (r *Reporter) ServeReport(w http.ResponseWriter, req *http.Request) {
+	if !admin.Require(w, req, r.adminToken.Value()) {
+		return
+	}
+	days := 7
+	if value := req.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxReportDays {
+			http.Error(w, "days must be between 1 and "+strconv.Itoa(maxReportDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	until := r.now().UTC().Truncate(time.Hour).Add(time.Hour)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(r.tracker.Report(until.AddDate(0, 0, -days), until))
+}
@@ -0,0 +1,322 @@
+// Package usage totals what the bot spends per tenant, repository and prompt
+// style,
+// OpenAI tokens, Slack messages and GitHub API calls, for weekly usage
+// reports
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/monitor"
+)
+
+// retention is how long hourly totals are kept
+const retention = 35 * 24 * time.Hour
+
+// DefaultStyle names summaries made with the built-in default prompt style
+const DefaultStyle = "default"
+
+// Totals are the usage of one repository, prompt style or the whole bot
+type Totals struct {
+	Summaries        int     `json:"summaries"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost_usd"`
+	UnpricedTokens   int64   `json:"unpriced_tokens,omitempty"` // Tokens of models without a known price, left out of the cost
+	SlackMessages    int     `json:"slack_messages"`
+	GitHubCalls      int     `json:"github_api_calls"`
+}
+
+func (t *Totals) add(other Totals) {
+	t.Summaries += other.Summaries
+	t.PromptTokens += other.PromptTokens
+	t.CompletionTokens += other.CompletionTokens
+	t.EstimatedCost += other.EstimatedCost
+	t.UnpricedTokens += other.UnpricedTokens
+	t.SlackMessages += other.SlackMessages
+	t.GitHubCalls += other.GitHubCalls
+}
+
+// key is the tenant, repository and prompt style usage is recorded against.
+// Slack messages and API calls have no style.
+type key struct {
+	tenant     string
+	repository string
+	style      string
+}
+
+// Tracker keeps hourly usage totals for the last five weeks. Totals are kept
+// in memory and reset on restart.
+type Tracker struct {
+	mu    sync.Mutex
+	hours map[time.Time]map[key]*Totals
+	now   func() time.Time
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{hours: make(map[time.Time]map[key]*Totals), now: time.Now}
+}
+
+// Recorder records the usage of one tenant into a tracker
+type Recorder struct {
+	tracker *Tracker
+	tenant  string
+}
+
+// Tenant returns a recorder that labels the usage it records with tenant,
+// empty for the deployment's own pipeline
+func (t *Tracker) Tenant(name string) *Recorder {
+	return &Recorder{tracker: t, tenant: name}
+}
+
+// RecordSummaryUsage adds the OpenAI requests behind a newly generated
+// summary of the deployment's own pipeline
+func (t *Tracker) RecordSummaryUsage(repository, style string, usage []ai.Usage) {
+	t.Tenant("").RecordSummaryUsage(repository, style, usage)
+}
+
+// RecordSlackMessage counts a message the deployment's own pipeline posted
+// or updated for an issue
+func (t *Tracker) RecordSlackMessage(repository string) {
+	t.Tenant("").RecordSlackMessage(repository)
+}
+
+// RecordGitHubCall counts a GitHub API call of the deployment's own pipeline
+func (t *Tracker) RecordGitHubCall(repository string) {
+	t.Tenant("").RecordGitHubCall(repository)
+}
+
+// RecordSummaryUsage adds the OpenAI requests behind a newly generated
+// summary, priced at list prices
+func (r *Recorder) RecordSummaryUsage(repository, style string, usage []ai.Usage) {
+	if style == "" {
+		style = DefaultStyle
+	}
+	r.tracker.record(key{r.tenant, repository, style}, func(totals *Totals) {
+		totals.Summaries++
+		for _, u := range usage {
+			totals.PromptTokens += int64(u.PromptTokens)
+			totals.CompletionTokens += int64(u.CompletionTokens)
+			if cost, ok := monitor.EstimateCost(u.Model, u.PromptTokens, u.CompletionTokens); ok {
+				totals.EstimatedCost += cost
+			} else {
+				totals.UnpricedTokens += int64(u.PromptTokens + u.CompletionTokens)
+			}
+		}
+	})
+}
+
+// RecordSlackMessage counts a message posted or updated for an issue
+func (r *Recorder) RecordSlackMessage(repository string) {
+	r.tracker.record(key{tenant: r.tenant, repository: repository}, func(totals *Totals) { totals.SlackMessages++ })
+}
+
+// RecordGitHubCall counts a GitHub API call. Calls outside a repository,
+// e.g. searches, are counted with an empty repository.
+func (r *Recorder) RecordGitHubCall(repository string) {
+	r.tracker.record(key{tenant: r.tenant, repository: repository}, func(totals *Totals) { totals.GitHubCalls++ })
+}
+
+func (t *Tracker) record(k key, update func(*Totals)) {
+	now := t.now().UTC()
+	hour := now.Truncate(time.Hour)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	totals, ok := t.hours[hour]
+	if !ok {
+		totals = make(map[key]*Totals)
+		t.hours[hour] = totals
+		for h := range t.hours {
+			if now.Sub(h) > retention {
+				delete(t.hours, h)
+			}
+		}
+	}
+	if totals[k] == nil {
+		totals[k] = &Totals{}
+	}
+	update(totals[k])
+}
+
+// RepositoryUsage is the usage of one repository
+type RepositoryUsage struct {
+	Repository string `json:"repository"` // Empty for GitHub API calls outside a repository
+	Totals
+}
+
+// TenantUsage is the usage of one tenant
+type TenantUsage struct {
+	Tenant string `json:"tenant"` // Empty for the deployment's own pipeline
+	Totals
+}
+
+// StyleUsage is the summaries made in one prompt style
+type StyleUsage struct {
+	Style string `json:"style"`
+	Totals
+}
+
+// Report is the usage between two times
+type Report struct {
+	Since        time.Time         `json:"since"`
+	Until        time.Time         `json:"until"`
+	TrackedSince time.Time         `json:"tracked_since,omitempty"` // First hour with usage still kept, e.g. since a restart
+	Total        Totals            `json:"total"`
+	Tenants      []TenantUsage     `json:"tenants,omitempty"` // Most expensive first, only with tenant usage
+	Repositories []RepositoryUsage `json:"repositories"`      // Most expensive first
+	Styles       []StyleUsage      `json:"styles"`            // Most expensive first
+}
+
+// Report totals the usage recorded in the hours starting in [since, until).
+// The report keeps until's timezone, which FormatReport dates it in.
+func (t *Tracker) Report(since, until time.Time) Report {
+	report := Report{Since: since.In(until.Location()), Until: until, Repositories: []RepositoryUsage{}, Styles: []StyleUsage{}}
+	tenants := make(map[string]*Totals)
+	repositories := make(map[string]*Totals)
+	styles := make(map[string]*Totals)
+
+	t.mu.Lock()
+	for hour, totals := range t.hours {
+		if report.TrackedSince.IsZero() || hour.Before(report.TrackedSince) {
+			report.TrackedSince = hour
+		}
+		if hour.Before(since) || !hour.Before(until) {
+			continue
+		}
+		for k, usage := range totals {
+			report.Total.add(*usage)
+			if tenants[k.tenant] == nil {
+				tenants[k.tenant] = &Totals{}
+			}
+			tenants[k.tenant].add(*usage)
+			if repositories[k.repository] == nil {
+				repositories[k.repository] = &Totals{}
+			}
+			repositories[k.repository].add(*usage)
+			if k.style != "" {
+				if styles[k.style] == nil {
+					styles[k.style] = &Totals{}
+				}
+				styles[k.style].add(*usage)
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	// Tenants are listed unless all usage is the deployment's own
+	if _, own := tenants[""]; len(tenants) > 1 || !own {
+		for tenant, totals := range tenants {
+			report.Tenants = append(report.Tenants, TenantUsage{Tenant: tenant, Totals: *totals})
+		}
+		sort.Slice(report.Tenants, func(i, j int) bool {
+			a, b := report.Tenants[i], report.Tenants[j]
+			return moreExpensive(a.Totals, b.Totals, a.Tenant, b.Tenant)
+		})
+	}
+	for repository, totals := range repositories {
+		report.Repositories = append(report.Repositories, RepositoryUsage{Repository: repository, Totals: *totals})
+	}
+	sort.Slice(report.Repositories, func(i, j int) bool {
+		a, b := report.Repositories[i], report.Repositories[j]
+		return moreExpensive(a.Totals, b.Totals, a.Repository, b.Repository)
+	})
+	for style, totals := range styles {
+		report.Styles = append(report.Styles, StyleUsage{Style: style, Totals: *totals})
+	}
+	sort.Slice(report.Styles, func(i, j int) bool {
+		a, b := report.Styles[i], report.Styles[j]
+		return moreExpensive(a.Totals, b.Totals, a.Style, b.Style)
+	})
+	return report
+}
+
+// moreExpensive orders by estimated cost, then tokens, API calls and name
+func moreExpensive(a, b Totals, nameA, nameB string) bool {
+	if a.EstimatedCost != b.EstimatedCost {
+		return a.EstimatedCost > b.EstimatedCost
+	}
+	if tokensA, tokensB := a.PromptTokens+a.CompletionTokens, b.PromptTokens+b.CompletionTokens; tokensA != tokensB {
+		return tokensA > tokensB
+	}
+	if a.GitHubCalls != b.GitHubCalls {
+		return a.GitHubCalls > b.GitHubCalls
+	}
+	return nameA < nameB
+}
+
+// maxReportRows bounds the repositories and styles listed in Slack
+const maxReportRows = 10
+
+// FormatReport writes a report as a Slack message
+func FormatReport(report Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Bot usage report* (%s to %s)\n", report.Since.Format("Jan 2"), report.Until.Format("Jan 2"))
+	total := report.Total
+	fmt.Fprintf(&b, "• OpenAI: %d summaries, %s tokens, estimated $%.2f\n",
+		total.Summaries, formatCount(total.PromptTokens+total.CompletionTokens), total.EstimatedCost)
+	fmt.Fprintf(&b, "• Slack messages: %d\n", total.SlackMessages)
+	fmt.Fprintf(&b, "• GitHub API calls: %d\n", total.GitHubCalls)
+	if total.UnpricedTokens > 0 {
+		fmt.Fprintf(&b, "• %s tokens of models without a known price are not in the estimate\n", formatCount(total.UnpricedTokens))
+	}
+	if report.TrackedSince.After(report.Since) {
+		fmt.Fprintf(&b, "• Usage is only known since %s, e.g. after a restart\n", report.TrackedSince.In(report.Until.Location()).Format("Jan 2 15:04 MST"))
+	}
+
+	if len(report.Tenants) > 0 {
+		b.WriteString("\n*By tenant*\n")
+		for _, tenant := range report.Tenants {
+			name := tenant.Tenant
+			if name == "" {
+				name = "(default)"
+			}
+			fmt.Fprintf(&b, "• %s: $%.2f, %d summaries, %d Slack messages, %d API calls\n",
+				name, tenant.EstimatedCost, tenant.Summaries, tenant.SlackMessages, tenant.GitHubCalls)
+		}
+	}
+	if len(report.Repositories) > 0 {
+		b.WriteString("\n*By repository*\n")
+		for i, repo := range report.Repositories {
+			if i == maxReportRows {
+				fmt.Fprintf(&b, "• …and %d more\n", len(report.Repositories)-maxReportRows)
+				break
+			}
+			name := repo.Repository
+			if name == "" {
+				name = "(outside repositories)"
+			}
+			fmt.Fprintf(&b, "• %s: $%.2f, %d summaries, %d Slack messages, %d API calls\n",
+				name, repo.EstimatedCost, repo.Summaries, repo.SlackMessages, repo.GitHubCalls)
+		}
+	}
+	if len(report.Styles) > 0 {
+		b.WriteString("\n*By prompt style*\n")
+		for i, style := range report.Styles {
+			if i == maxReportRows {
+				fmt.Fprintf(&b, "• …and %d more\n", len(report.Styles)-maxReportRows)
+				break
+			}
+			fmt.Fprintf(&b, "• %s: $%.2f, %d summaries, %s tokens\n",
+				style.Style, style.EstimatedCost, style.Summaries, formatCount(style.PromptTokens+style.CompletionTokens))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatCount writes large counts with a k or M suffix
+func formatCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 10_000:
+		return fmt.Sprintf("%.0fk", float64(n)/1_000)
+	}
+	return fmt.Sprint(n)
+}
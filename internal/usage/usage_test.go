@@ -0,0 +1,222 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/admin"
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/schedule"
+)
+
+type fakePoster struct {
+	channel, kind, text string
+}
+
+func (f *fakePoster) PostMessage(ctx context.Context, channelID, kind, text string) error {
+	f.channel, f.kind, f.text = channelID, kind, text
+	return nil
+}
+
+// Monday, Sept 14 2026, 09:00 UTC
+var monday = time.Date(2026, 9, 14, 9, 0, 0, 0, time.UTC)
+
+func newTestTracker(at *time.Time) *Tracker {
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return *at }
+	return tracker
+}
+
+func TestTrackerReport(t *testing.T) {
+	at := monday.AddDate(0, 0, -10) // Before the week
+	tracker := newTestTracker(&at)
+	tracker.RecordSummaryUsage("acme/api", "security_expert", []ai.Usage{{Model: "gpt-4", PromptTokens: 100000}})
+
+	at = monday.AddDate(0, 0, -3)
+	tracker.RecordSummaryUsage("acme/api", "", []ai.Usage{
+		{Model: "gpt-4o-mini", PromptTokens: 1000, CompletionTokens: 500},
+		{Model: "gpt-4", PromptTokens: 2000, CompletionTokens: 1000},
+	})
+	tracker.RecordSummaryUsage("acme/web", "quick_triage", []ai.Usage{{Model: "local-llama", PromptTokens: 800, CompletionTokens: 200}})
+	tracker.RecordSlackMessage("acme/api")
+	tracker.RecordSlackMessage("acme/web")
+	tracker.RecordSlackMessage("acme/web")
+	for i := 0; i < 3; i++ {
+		tracker.RecordGitHubCall("acme/web")
+	}
+	tracker.RecordGitHubCall("")
+
+	report := tracker.Report(monday.AddDate(0, 0, -7), monday)
+	total := report.Total
+	if total.Summaries != 2 || total.PromptTokens != 3800 || total.CompletionTokens != 1700 ||
+		total.SlackMessages != 3 || total.GitHubCalls != 4 || total.UnpricedTokens != 1000 {
+		t.Errorf("Unexpected totals %+v", total)
+	}
+	// 0.00015 + 0.0003 for gpt-4o-mini, 0.06 + 0.06 for gpt-4
+	if total.EstimatedCost < 0.1204 || total.EstimatedCost > 0.1205 {
+		t.Errorf("Expected an estimated $0.1205, got %f", total.EstimatedCost)
+	}
+
+	if len(report.Repositories) != 3 || report.Repositories[0].Repository != "acme/api" ||
+		report.Repositories[1].Repository != "acme/web" || report.Repositories[1].GitHubCalls != 3 || report.Repositories[2].Repository != "" {
+		t.Errorf("Expected repositories most expensive first, got %+v", report.Repositories)
+	}
+	if len(report.Styles) != 2 || report.Styles[0].Style != DefaultStyle || report.Styles[1].Style != "quick_triage" {
+		t.Errorf("Expected styles most expensive first, got %+v", report.Styles)
+	}
+	if report.Tenants != nil {
+		t.Errorf("Expected no tenants without tenant usage, got %+v", report.Tenants)
+	}
+	if !report.TrackedSince.Equal(monday.AddDate(0, 0, -10)) {
+		t.Errorf("Expected usage tracked since the first record, got %s", report.TrackedSince)
+	}
+
+	text := FormatReport(report)
+	for _, want := range []string{
+		"*Bot usage report* (Sep 7 to Sep 14)",
+		"• OpenAI: 2 summaries, 5500 tokens, estimated $0.12",
+		"• GitHub API calls: 4",
+		"• 1000 tokens of models without a known price",
+		"• acme/api: $0.12, 1 summaries, 1 Slack messages, 0 API calls",
+		"• (outside repositories): $0.00, 0 summaries, 0 Slack messages, 1 API calls",
+		"• quick_triage: $0.00, 1 summaries, 1000 tokens",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the report:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "only known since") {
+		t.Errorf("Expected no note on missing usage when the week was tracked:\n%s", text)
+	}
+
+	// Hours older than five weeks are dropped
+	at = monday.AddDate(0, 0, 30)
+	tracker.RecordSlackMessage("acme/api")
+	if report := tracker.Report(monday.AddDate(0, 0, -14), monday); report.Total.Summaries != 2 || !report.TrackedSince.Equal(monday.AddDate(0, 0, -3)) {
+		t.Errorf("Expected the oldest hour dropped, got %+v since %s", report.Total, report.TrackedSince)
+	}
+}
+
+func TestTrackerTenants(t *testing.T) {
+	at := monday.AddDate(0, 0, -1)
+	tracker := newTestTracker(&at)
+	tracker.RecordSlackMessage("acme/api")
+	partner := tracker.Tenant("partner")
+	partner.RecordSummaryUsage("partner/app", "", []ai.Usage{{Model: "gpt-4", PromptTokens: 1000}})
+	partner.RecordSlackMessage("partner/app")
+	partner.RecordGitHubCall("partner/app")
+
+	report := tracker.Report(monday.AddDate(0, 0, -7), monday)
+	if report.Total.Summaries != 1 || report.Total.SlackMessages != 2 || report.Total.GitHubCalls != 1 {
+		t.Errorf("Expected tenant usage in the totals, got %+v", report.Total)
+	}
+	if len(report.Tenants) != 2 || report.Tenants[0].Tenant != "partner" || report.Tenants[0].Summaries != 1 ||
+		report.Tenants[1].Tenant != "" || report.Tenants[1].SlackMessages != 1 {
+		t.Errorf("Expected tenants most expensive first, got %+v", report.Tenants)
+	}
+	if len(report.Repositories) != 2 || report.Repositories[0].Repository != "partner/app" {
+		t.Errorf("Expected tenant repositories listed, got %+v", report.Repositories)
+	}
+
+	text := FormatReport(report)
+	for _, want := range []string{
+		"• partner: $0.03, 1 summaries, 1 Slack messages, 1 API calls",
+		"• (default): $0.00, 0 summaries, 1 Slack messages, 0 API calls",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the report:\n%s", want, text)
+		}
+	}
+}
+
+func TestReporter(t *testing.T) {
+	at := monday.Add(-time.Hour)
+	tracker := newTestTracker(&at)
+	tracker.RecordSummaryUsage("acme/api", "", []ai.Usage{{Model: "gpt-4", PromptTokens: 1000}})
+
+	poster := &fakePoster{}
+	config := Config{Channel: "C0OPS", Schedule: schedule.Schedule{Weekday: "monday", Hour: 9}}
+	reporter := NewReporter(tracker, poster, config, admin.NewToken("admin-token"), zap.NewNop())
+	reporter.now = func() time.Time { return at }
+	if last := config.Last(monday.Add(time.Minute)); !last.Equal(monday) {
+		t.Errorf("Expected the report due at %s, got %s", monday, last)
+	}
+
+	reporter.PostReport(context.Background(), config.Last(monday.Add(time.Minute)))
+	if poster.channel != "C0OPS" || poster.kind != "usage_report" || !strings.Contains(poster.text, "1 summaries") ||
+		!strings.Contains(poster.text, "only known since Sep 14 08:00 UTC") {
+		t.Errorf("Unexpected report in %s: %q", poster.channel, poster.text)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/usage", nil)
+	recorder := httptest.NewRecorder()
+	reporter.ServeReport(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the report to need the admin token, got %d", recorder.Code)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/api/usage?days=1", nil)
+	request.Header.Set("Authorization", "Bearer admin-token")
+	recorder = httptest.NewRecorder()
+	reporter.ServeReport(recorder, request)
+	var report Report
+	json.NewDecoder(recorder.Body).Decode(&report)
+	if recorder.Code != http.StatusOK || report.Total.Summaries != 1 || !report.Until.Equal(monday) {
+		t.Errorf("Expected the last day's usage up to the current hour, got %d %+v", recorder.Code, report)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/api/usage?days=90", nil)
+	request.Header.Set("Authorization", "Bearer admin-token")
+	recorder = httptest.NewRecorder()
+	reporter.ServeReport(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected more days than are kept rejected, got %d", recorder.Code)
+	}
+}
+
+func TestReporterTimezone(t *testing.T) {
+	config := Config{Channel: "C0OPS", Schedule: schedule.Schedule{Weekday: "monday", Hour: 9, Timezone: "Europe/London"}}
+	// Clocks went back on Sunday October 25, 2026: 09:00 is 08:00 UTC the
+	// week before and 09:00 UTC after
+	sunday := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	if last := config.Last(sunday.Add(32*time.Hour + 30*time.Minute)); !last.Equal(sunday.AddDate(0, 0, -6).Add(8 * time.Hour)) {
+		t.Errorf("Expected the report due at 08:00 UTC the week before, got %s", last.UTC())
+	}
+	if last := config.Last(sunday.Add(33 * time.Hour)); !last.Equal(sunday.Add(33 * time.Hour)) {
+		t.Errorf("Expected the report due at 09:00 UTC, got %s", last.UTC())
+	}
+
+	at := monday.Add(-90 * time.Minute)
+	tracker := newTestTracker(&at)
+	tracker.RecordSlackMessage("acme/api")
+	poster := &fakePoster{}
+	reporter := NewReporter(tracker, poster, config, nil, zap.NewNop())
+	reporter.PostReport(context.Background(), monday.Add(-time.Hour))
+	if !strings.Contains(poster.text, "only known since Sep 14 08:00 BST") {
+		t.Errorf("Expected the report dated in London time, got %q", poster.text)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, config := range []Config{{}, {Schedule: schedule.Schedule{Weekday: "Friday", Hour: 17}}} {
+		if err := config.Validate(); err != nil {
+			t.Errorf("Expected %+v valid, got %v", config, err)
+		}
+	}
+	for _, config := range []Config{
+		{Schedule: schedule.Schedule{Weekday: "someday"}},
+		{Schedule: schedule.Schedule{Weekday: "monday", Hour: 24}},
+		{Schedule: schedule.Schedule{Weekday: "monday", Timezone: "Mars/Olympus"}},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected %+v invalid", config)
+		}
+	}
+}
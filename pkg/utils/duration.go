@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MedianDuration returns the median of durations, or zero for none. It sorts
+// durations in place.
+func MedianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	mid := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[mid-1] + durations[mid]) / 2
+	}
+	return durations[mid]
+}
+
+// FormatHours writes periods of two days or more in days, and shorter ones
+// in hours
+func FormatHours(h float64) string {
+	if h >= 48 {
+		return fmt.Sprintf("%.1f days", h/24)
+	}
+	return fmt.Sprintf("%.1f hours", h)
+}
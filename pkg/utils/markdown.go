@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	headingPattern     = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)(?:\s+#+)?\s*$`)
+	rulePattern        = regexp.MustCompile(`^ {0,3}([-*_])(?:\s*[-*_]){2,}\s*$`)
+	taskPattern        = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	bulletPattern      = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	quotePattern       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	// A table's delimiter row, e.g. |---|:---:|, which must contain a pipe
+	tableDelimiterPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)+\|?\s*$|^\s*\|\s*:?-+:?\s*\|\s*$`)
+
+	codeSpanPattern = regexp.MustCompile("`[^`\n]+`")
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	urlPattern      = regexp.MustCompile(`<[^>\s]+>|https?://[^\s<>]+`)
+	boldPattern     = regexp.MustCompile(`\*\*([^*\n]+?)\*\*|__([^_\n]+?)__`)
+	italicPattern   = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*\n]*[^*\s])?)\*`)
+	strikePattern   = regexp.MustCompile(`~~([^~\n]+?)~~`)
+	protectPattern  = regexp.MustCompile("\x01(\\d+)\x01")
+
+	// inlineMarkers removes bold and code markers from text that cannot be
+	// formatted: link text and table cells
+	inlineMarkers = strings.NewReplacer("**", "", "__", "", "`", "")
+)
+
+// MarkdownToSlack converts GitHub-flavored Markdown, as written in issues and
+// by the AI, into Slack mrkdwn: headings and bold become *bold*, links
+// become <url|text>, bullets become •, code fences lose their language, and
+// tables, which Slack cannot show, become aligned text in a code block.
+// Code is left as written and HTML comments are removed.
+func MarkdownToSlack(text string) string {
+	text = htmlCommentPattern.ReplaceAllString(strings.ReplaceAll(text, "\r\n", "\n"), "")
+	lines := strings.Split(text, "\n")
+
+	out := make([]string, 0, len(lines))
+	fence := "" // The marker of the open code fence, empty outside one
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				out = append(out, "```")
+				fence = ""
+			} else {
+				out = append(out, line)
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			out = append(out, "```")
+			fence = marker
+			continue
+		}
+		if strings.Contains(line, "|") && i+1 < len(lines) && tableDelimiterPattern.MatchString(lines[i+1]) {
+			end := i + 2
+			for end < len(lines) && strings.Contains(lines[end], "|") && strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			out = append(out, tableToText(lines[i], lines[i+2:end])...)
+			i = end - 1
+			continue
+		}
+		out = append(out, markdownLine(line))
+	}
+	if fence != "" {
+		out = append(out, "```")
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// fenceMarker returns the ``` or ~~~ run opening a code fence, or empty if
+// the line does not open one. A line that also closes it is inline code.
+func fenceMarker(trimmed string) string {
+	for _, c := range []string{"`", "~"} {
+		if !strings.HasPrefix(trimmed, c+c+c) {
+			continue
+		}
+		marker := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, c))]
+		if strings.Contains(trimmed[len(marker):], marker) {
+			return ""
+		}
+		return marker
+	}
+	return ""
+}
+
+// markdownLine converts a line outside code fences and tables
+func markdownLine(line string) string {
+	if rulePattern.MatchString(line) {
+		return ""
+	}
+	if m := headingPattern.FindStringSubmatch(line); m != nil {
+		return "*" + strings.ReplaceAll(markdownInline(m[1]), "*", "") + "*"
+	}
+	if m := taskPattern.FindStringSubmatch(line); m != nil {
+		box := "☐"
+		if m[2] != " " {
+			box = "☑"
+		}
+		return m[1] + box + " " + markdownInline(m[3])
+	}
+	if m := bulletPattern.FindStringSubmatch(line); m != nil {
+		return m[1] + "• " + markdownInline(m[2])
+	}
+	if m := quotePattern.FindStringSubmatch(line); m != nil {
+		return "> " + markdownInline(m[1])
+	}
+	return markdownInline(line)
+}
+
+// markdownInline converts emphasis and links. Code spans and URLs are set
+// aside first, so underscores and asterisks in them are kept.
+func markdownInline(text string) string {
+	var protected []string
+	protect := func(s string) string {
+		protected = append(protected, s)
+		return fmt.Sprintf("\x01%d\x01", len(protected)-1)
+	}
+
+	text = codeSpanPattern.ReplaceAllStringFunc(text, protect)
+	text = imagePattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := imagePattern.FindStringSubmatch(s)
+		if m[1] == "" {
+			return protect("<" + m[2] + ">")
+		}
+		return protect("<" + m[2] + "|" + m[1] + ">")
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkPattern.FindStringSubmatch(s)
+		return protect("<" + m[2] + "|" + inlineMarkers.Replace(m[1]) + ">")
+	})
+	text = urlPattern.ReplaceAllStringFunc(text, protect)
+
+	text = boldPattern.ReplaceAllString(text, "\x00$1$2\x00")
+	text = italicPattern.ReplaceAllString(text, "${1}_${2}_")
+	text = strikePattern.ReplaceAllString(text, "~$1~")
+	text = strings.ReplaceAll(text, "\x00", "*")
+
+	return protectPattern.ReplaceAllStringFunc(text, func(s string) string {
+		i, _ := strconv.Atoi(s[1 : len(s)-1])
+		return protected[i]
+	})
+}
+
+// tableToText lays out a Markdown table as aligned columns in a code block
+func tableToText(header string, rows []string) []string {
+	table := [][]string{tableCells(header)}
+	for _, row := range rows {
+		table = append(table, tableCells(row))
+	}
+	var widths []int
+	for _, row := range table {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	format := func(row []string) string {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		return strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+	separators := make([]string, len(widths))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width)
+	}
+
+	out := []string{"```", format(table[0]), strings.Join(separators, "  ")}
+	for _, row := range table[1:] {
+		out = append(out, format(row))
+	}
+	return append(out, "```")
+}
+
+// tableCells splits a table row into its trimmed cells
+func tableCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for i, cell := range cells {
+		cells[i] = inlineMarkers.Replace(strings.TrimSpace(cell))
+	}
+	return cells
+}
@@ -8,12 +8,23 @@ import (
 	"unicode"
 )
 
-// TruncateText truncates text to a maximum length and adds ellipsis if needed
+// TruncateText truncates text to a maximum number of characters and adds ellipsis if needed
 func TruncateText(text string, maxLength int) string {
-	if len(text) <= maxLength {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
 		return text
 	}
-	return text[:maxLength-3] + "..."
+	if maxLength <= 3 {
+		return "..."
+	}
+	return string(runes[:maxLength-3]) + "..."
+}
+
+// FirstLine returns the first non-empty line of text, trimmed, e.g. a commit
+// message's subject
+func FirstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(line)
 }
 
 // CleanText removes extra whitespace and normalizes text
@@ -144,3 +155,36 @@ func ContainsAny(text string, substrings ...string) bool {
 	}
 	return false
 }
+
+// ContainsFold reports whether values contains value, ignoring case
+func ContainsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// Slack emoji shortcodes such as :fire: or :+1:, which need at least one
+	// letter so times like 12:30:45 are kept
+	emojiShortcodePattern = regexp.MustCompile(`:[a-z0-9_+\-]*[a-z][a-z0-9_+\-]*:`)
+	// Pictographs, symbols, flags, and the joiners and selectors that combine them
+	emojiPattern = regexp.MustCompile(`[\x{1F000}-\x{1FAFF}\x{2600}-\x{27BF}\x{2B00}-\x{2BFF}\x{2300}-\x{23FF}\x{FE0F}\x{200D}\x{20E3}]`)
+	// Runs of spaces left behind by removed emoji, not newlines
+	repeatedSpacePattern = regexp.MustCompile(`[ \t]{2,}`)
+)
+
+// StripEmoji removes Unicode emoji and Slack emoji shortcodes from text, for
+// readers who find them noisy, e.g. with a screen reader
+func StripEmoji(text string) string {
+	text = emojiShortcodePattern.ReplaceAllString(text, "")
+	text = emojiPattern.ReplaceAllString(text, "")
+	text = repeatedSpacePattern.ReplaceAllString(text, " ")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+
+	gh "github-issue-ai-bot/internal/github"
+)
+
+func TestActivityContext(t *testing.T) {
+	issueData := testIssueData()
+	issueData.Activity = gh.ComputeActivity(&github.Issue{
+		AuthorAssociation: github.String("NONE"),
+		CreatedAt:         &github.Timestamp{Time: time.Now().Add(-72 * time.Hour)},
+		Reactions:         &github.Reactions{PlusOne: github.Int(7), TotalCount: github.Int(9)},
+	}, nil, time.Now())
+
+	_, prompt := summarizeForPrompt(t, issueData)
+	for _, want := range []string{"## Issue Activity", "- Opened 3 days ago", "- No maintainer response yet", "- Reporter: no previous contributions", "- 👍 7 (9 reactions)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
+
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+	summary, err := summarizeResponse(`{"title": "Crash", "summary": "It crashes", "priority": "high", "category": "bug", "confidence": 0.8}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var context string
+	for _, block := range summarizer.GenerateSlackMessage(issueData, summary)["blocks"].([]map[string]interface{}) {
+		if text, ok := block["text"].(map[string]interface{}); ok && strings.HasPrefix(text["text"].(string), "*Context:*") {
+			context = text["text"].(string)
+		}
+	}
+	if !strings.Contains(context, "Opened 3 days ago · No maintainer response yet") {
+		t.Errorf("Expected a Context section, got %q", context)
+	}
+
+	// Without activity there is no Context section
+	message := summarizer.GenerateSlackMessage(testIssueData(), summary)
+	for _, block := range message["blocks"].([]map[string]interface{}) {
+		if text, ok := block["text"].(map[string]interface{}); ok && strings.HasPrefix(text["text"].(string), "*Context:*") {
+			t.Error("Expected no Context section without activity")
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+func TestSlackMessageAssigneeAndMilestone(t *testing.T) {
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+	issueData := testIssueData()
+	summary := &ai.IssueSummary{Title: "Crash on start", Priority: "critical", Category: "security", EscalatedBy: "security"}
+
+	message, _ := json.Marshal(summarizer.GenerateSlackMessage(issueData, summary))
+	if strings.Contains(string(message), "Assignee:") || strings.Contains(string(message), "Milestone:") {
+		t.Errorf("Expected no assignee or milestone fields, got %s", message)
+	}
+
+	issueData.Issue.Assignees = []*github.User{{Login: github.String("alice")}, {Login: github.String("bob")}}
+	issueData.Issue.Milestone = &github.Milestone{Title: github.String("v2.0")}
+	message, _ = json.Marshal(summarizer.GenerateSlackMessage(issueData, summary))
+	for _, want := range []string{"*Assignee:*\\n@alice, @bob", "*Milestone:*\\nv2.0", "Critical (escalated by `security`)"} {
+		if !strings.Contains(string(message), want) {
+			t.Errorf("Expected the message to contain %q, got %s", want, message)
+		}
+	}
+	if explanation := ai.FormatExplanation(summary); !strings.Contains(explanation, "Escalated when the `security` label was added") {
+		t.Errorf("Expected the escalation in the explanation, got %q", explanation)
+	}
+}
+
+func TestSlackMessageMentionsMappedAssignees(t *testing.T) {
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+	issueData := testIssueData()
+	issueData.Issue.Assignees = []*github.User{{Login: github.String("alice")}, {Login: github.String("bob")}}
+	issueData.Mentions = map[string]string{"alice": "U024BE7LH"}
+	summary := &ai.IssueSummary{Title: "Crash on start", Priority: "high", Category: "bug"}
+
+	message, _ := json.Marshal(summarizer.GenerateSlackMessage(issueData, summary))
+	if !strings.Contains(string(message), "*Assignee:*\\n\\u003c@U024BE7LH\\u003e, @bob") {
+		t.Errorf("Expected alice mentioned in Slack, got %s", message)
+	}
+	if strings.Contains(string(message), "assign_issue") {
+		t.Error("Expected no Assign to Me button unless enabled")
+	}
+
+	summarizer.SetAssignButton(true)
+	message, _ = json.Marshal(summarizer.GenerateSlackMessage(issueData, summary))
+	if !strings.Contains(string(message), `"action_id":"assign_issue"`) {
+		t.Errorf("Expected an Assign to Me button, got %s", message)
+	}
+	issueData.Issue.State = github.String("closed")
+	message, _ = json.Marshal(summarizer.GenerateSlackMessage(issueData, summary))
+	if strings.Contains(string(message), "assign_issue") {
+		t.Error("Expected no Assign to Me button on closed issues")
+	}
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	gh "github-issue-ai-bot/internal/github"
+)
+
+func TestSummaryPromptDiagnostics(t *testing.T) {
+	issueData := testIssueData()
+	issueData.Attachments = []gh.Attachment{
+		{Name: "server.log", Lines: []string{"ERROR failed to connect to db"}, Matched: true, Truncated: true},
+		{Name: "config.json", Lines: []string{`{"port": 8080}`}},
+	}
+
+	_, prompt := summarizeForPrompt(t, issueData)
+	for _, want := range []string{
+		"## Diagnostics",
+		"### Attachment: server.log (truncated)\n<untrusted source=\"attachment\">\n```\nERROR failed to connect to db\n```\n</untrusted>",
+		"### Attachment: config.json (last lines, no errors or warnings found)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
+
+	if _, prompt := summarizeForPrompt(t, testIssueData()); strings.Contains(prompt, "## Diagnostics") {
+		t.Error("Expected no diagnostics section without attachments")
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	gh "github-issue-ai-bot/internal/github"
+)
+
+type fakeStyleSelector struct {
+	style   *ai.PromptStyle
+	results []string
+}
+
+func (f *fakeStyleSelector) SelectStyle(issueData *gh.IssueData) (string, *ai.PromptStyle) {
+	if f.style != nil {
+		return "canary/1", f.style
+	}
+	return "stable/1", nil
+}
+
+func (f *fakeStyleSelector) RecordSummary(version string, parsed bool) {
+	if parsed {
+		f.results = append(f.results, version+" parsed")
+	} else {
+		f.results = append(f.results, version+" unparseable")
+	}
+}
+
+func TestSummarizeIssueWithStyleSelector(t *testing.T) {
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	canaryStyle, _ := ai.GetPromptStyle("security_expert")
+	selector := &fakeStyleSelector{style: &canaryStyle}
+	summarizer.SetStyleSelector(selector)
+
+	summary, err := summarizer.SummarizeIssue(context.Background(), testIssueData())
+	if err != nil {
+		t.Fatalf("SummarizeIssue: %v", err)
+	}
+	if summary.PromptVersion != "canary/1" {
+		t.Errorf("Expected the summary tagged with its version, got %q", summary.PromptVersion)
+	}
+	system, _ := prompts(t, fake.requests[0])
+	if !strings.Contains(system, canaryStyle.Personality) {
+		t.Errorf("Expected the selected style in the system prompt, got %q", system)
+	}
+
+	selector.style = nil
+	fake.content = "not json"
+	if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err == nil {
+		t.Fatal("Expected an error for an unparseable response")
+	}
+
+	// Issues with their own style are not offered to the selector
+	ticket := testIssueData()
+	ticket.PromptStyle = gh.TicketPromptStyle
+	summarizer.SummarizeIssue(context.Background(), ticket)
+
+	want := []string{"canary/1 parsed", "stable/1 unparseable"}
+	if strings.Join(selector.results, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected results %v, got %v", want, selector.results)
+	}
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	gh "github-issue-ai-bot/internal/github"
+)
+
+// completionResponse is a minimal chat completion carrying content
+func completionResponse(content string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":     "chatcmpl-test",
+		"object": "chat.completion",
+		"model":  "gpt-4",
+		"choices": []map[string]interface{}{
+			{"index": 0, "finish_reason": "stop", "message": map[string]string{"role": "assistant", "content": content}},
+		},
+		"usage": map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+	}
+}
+
+func TestSummarizerClientOptionsThroughProxy(t *testing.T) {
+	var got *http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		json.NewEncoder(w).Encode(completionResponse(`{"title": "Crash on start", "summary": "The app crashes", "priority": "high", "category": "bug"}`))
+	}))
+	defer proxy.Close()
+
+	mockMetrics := &MockMetricsRecorder{}
+	mockMetrics.On("RecordOpenAIRequest", mock.Anything, mock.Anything, mock.Anything).Return()
+	mockMetrics.On("RecordOpenAITokens", mock.Anything, mock.Anything, mock.Anything).Return()
+
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), mockMetrics)
+	err := summarizer.SetClientOptions(ai.ClientOptions{
+		OrgID:     "org-123",
+		ProjectID: "proj_456",
+		BaseURL:   "http://openai.internal/v1",
+		ProxyURL:  proxy.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	summary, err := summarizer.SummarizeIssue(context.Background(), &gh.IssueData{
+		Issue:      &github.Issue{Number: github.Int(1), Title: github.String("Crash"), Body: github.String("It crashes")},
+		Repository: &github.Repository{FullName: github.String("owner/repo")},
+	})
+	if err != nil {
+		t.Fatalf("Expected a summary through the proxy, got %v", err)
+	}
+	if summary.Priority != "high" {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	// A forward proxy sees the absolute URL of the configured base URL
+	if got.URL.String() != "http://openai.internal/v1/chat/completions" {
+		t.Errorf("Expected the request to go through the proxy to the base URL, got %s", got.URL)
+	}
+	if got.Header.Get("OpenAI-Organization") != "org-123" || got.Header.Get("OpenAI-Project") != "proj_456" {
+		t.Errorf("Expected organization and project headers, got %v", got.Header)
+	}
+	if got.Header.Get("Authorization") != "Bearer test-api-key" {
+		t.Errorf("Expected the API key, got %q", got.Header.Get("Authorization"))
+	}
+
+	// Rotating the key keeps the options
+	summarizer.SetAPIKey("rotated-key")
+	if _, err := summarizer.SummarizeIssue(context.Background(), &gh.IssueData{
+		Issue:      &github.Issue{Number: github.Int(1), Title: github.String("Crash"), Body: github.String("It crashes")},
+		Repository: &github.Repository{FullName: github.String("owner/repo")},
+	}); err != nil {
+		t.Fatalf("Unexpected error after rotation: %v", err)
+	}
+	if got.Header.Get("Authorization") != "Bearer rotated-key" || got.Header.Get("OpenAI-Project") != "proj_456" {
+		t.Errorf("Expected the rotated key with the same options, got %v", got.Header)
+	}
+}
+
+func TestSummarizerClientOptionsValidation(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	if err := summarizer.SetClientOptions(ai.ClientOptions{ProxyURL: "://proxy"}); err == nil {
+		t.Error("Expected an invalid proxy URL to be rejected")
+	}
+	if err := summarizer.SetClientOptions(ai.ClientOptions{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("Expected a missing CA file to be rejected")
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := summarizer.SetClientOptions(ai.ClientOptions{CACertFile: notPEM}); err == nil {
+		t.Error("Expected a CA file without certificates to be rejected")
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+// actionIDs returns the action IDs of the buttons in a Slack message
+func actionIDs(message map[string]interface{}) []string {
+	var ids []string
+	for _, block := range message["blocks"].([]map[string]interface{}) {
+		if block["type"] != "actions" {
+			continue
+		}
+		for _, element := range block["elements"].([]map[string]interface{}) {
+			ids = append(ids, element["action_id"].(string))
+		}
+	}
+	return ids
+}
+
+func TestSummaryReasoning(t *testing.T) {
+	summary, err := summarizeResponse(`{
+		"title": "Crash on start",
+		"summary": "The app panics on startup",
+		"priority": "high",
+		"category": "bug",
+		"confidence": 0.8,
+		"reasoning": ["Stack trace shows a nil pointer in main.go", "Several users report it"]
+	}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(summary.Reasoning) != 2 {
+		t.Fatalf("Expected two reasoning signals, got %v", summary.Reasoning)
+	}
+
+	explanation := ai.FormatExplanation(summary)
+	for _, want := range []string{"high priority", "80% confidence", "• Stack trace shows a nil pointer in main.go", "• Several users report it"} {
+		if !strings.Contains(explanation, want) {
+			t.Errorf("Expected explanation to contain %q, got %q", want, explanation)
+		}
+	}
+
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+	ids := actionIDs(summarizer.GenerateSlackMessage(testIssueData(), summary))
+	if !strings.Contains(strings.Join(ids, ","), "explain_summary") {
+		t.Errorf("Expected a Why? button, got %v", ids)
+	}
+
+	// Without reasoning there is nothing to explain
+	summary.Reasoning = nil
+	ids = actionIDs(summarizer.GenerateSlackMessage(testIssueData(), summary))
+	if strings.Contains(strings.Join(ids, ","), "explain_summary") {
+		t.Errorf("Expected no Why? button, got %v", ids)
+	}
+	if explanation := ai.FormatExplanation(summary); !strings.Contains(explanation, "no reasoning") {
+		t.Errorf("Expected a note about missing reasoning, got %q", explanation)
+	}
+}
+
+func TestSummaryPromptAsksForReasoning(t *testing.T) {
+	system, _ := summarizeForPrompt(t, testIssueData())
+	if !strings.Contains(system, `"reasoning"`) {
+		t.Error("Expected the system prompt to ask for reasoning")
+	}
+}
+
+func TestBoostedSummary(t *testing.T) {
+	summary, err := summarizeResponse(`{"title": "Crash", "summary": "It crashes", "priority": "high", "category": "bug", "confidence": 0.8}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	summary.Priority, summary.BoostedFrom = "critical", "high"
+
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+	message := summarizer.GenerateSlackMessage(testIssueData(), summary)
+	fields := message["blocks"].([]map[string]interface{})[1]["fields"].([]map[string]interface{})
+	found := false
+	for _, field := range fields {
+		found = found || field["text"] == "*Priority:*\nCritical (raised from High by 👍)"
+	}
+	if !found {
+		t.Errorf("Expected the priority field to note the boost, got %v", fields)
+	}
+	if explanation := ai.FormatExplanation(summary); !strings.Contains(explanation, "Raised from high") {
+		t.Errorf("Expected the explanation to mention the boost, got %q", explanation)
+	}
+}
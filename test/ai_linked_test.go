@@ -0,0 +1,41 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	gh "github-issue-ai-bot/internal/github"
+)
+
+func TestLinkedIssuesButton(t *testing.T) {
+	summary, err := summarizeResponse(`{"title": "Crash", "summary": "It crashes", "priority": "high", "category": "bug"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+
+	issueData := testIssueData()
+	if ids := actionIDs(summarizer.GenerateSlackMessage(issueData, summary)); strings.Contains(strings.Join(ids, ","), "linked_issues") {
+		t.Errorf("Expected no Linked Issues button without references, got %v", ids)
+	}
+	issueData.Links = []gh.IssueReference{{Repository: "acme/api", Number: 12}}
+	if ids := actionIDs(summarizer.GenerateSlackMessage(issueData, summary)); !strings.Contains(strings.Join(ids, ","), "linked_issues") {
+		t.Errorf("Expected a Linked Issues button, got %v", ids)
+	}
+}
+
+func TestFormatLinkedIssues(t *testing.T) {
+	text := ai.FormatLinkedIssues(7, []gh.LinkedIssue{
+		{IssueReference: gh.IssueReference{Repository: "acme/api", Number: 12}, Title: "Login fails", State: "closed", URL: "https://github.com/acme/api/issues/12", Summary: "Sessions expire immediately", Cached: true},
+		{IssueReference: gh.IssueReference{Repository: "acme/api", Number: 13}},
+	})
+	for _, want := range []string{"referenced by #7", ":white_circle: <https://github.com/acme/api/issues/12|acme/api#12>: Login fails", "_Sessions expire immediately_", "acme/api#13 _(still loading"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+	if text := ai.FormatLinkedIssues(7, nil); !strings.Contains(text, "references no other issues") {
+		t.Errorf("Expected a note without references, got %q", text)
+	}
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+func TestModelRegistryLookup(t *testing.T) {
+	registry := ai.DefaultModelRegistry()
+
+	tests := []struct {
+		model   string
+		want    string
+		known   bool
+		context int
+	}{
+		{"gpt-4o-mini", "gpt-4o-mini", true, 128000},
+		{"gpt-4o-2024-08-06", "gpt-4o", true, 128000},
+		{"gpt-4-0613", "gpt-4", true, 8192},
+		{"gpt-4.1-mini-2025-04-14", "gpt-4.1-mini", true, 1047576},
+		{"ft:gpt-4o-mini-2024-07-18:acme::abc123", "gpt-4o-mini", true, 128000},
+		{"gpt-4.5-preview", "", false, 0},
+		{"my-local-llama", "", false, 0},
+	}
+	for _, tt := range tests {
+		info, ok := registry.Lookup(tt.model)
+		if ok != tt.known || info.Name != tt.want || info.ContextWindow != tt.context {
+			t.Errorf("Lookup(%q) = %s (%d), %v, want %s (%d), %v", tt.model, info.Name, info.ContextWindow, ok, tt.want, tt.context, tt.known)
+		}
+	}
+
+	if info, _ := registry.Lookup("gpt-4o-mini"); info.Price == nil || info.Price.Prompt != 0.00015 {
+		t.Errorf("Expected the gpt-4o-mini price, got %+v", info.Price)
+	}
+}
+
+func TestModelRegistryValidate(t *testing.T) {
+	registry, err := ai.NewModelRegistry([]ai.ModelInfo{
+		{Name: "tiny-chat", ContextWindow: 1536, MaxOutputTokens: 1024, Chat: true},
+	})
+	if err != nil {
+		t.Fatalf("NewModelRegistry: %v", err)
+	}
+
+	tests := []struct {
+		model     string
+		maxTokens int
+		wantErr   string
+	}{
+		{"gpt-4", 2000, ""},
+		{"gpt-4o", 16384, ""},
+		{"gpt-4o", 20000, "at most 16384 tokens"},
+		{"gpt-4", 7800, "no room for the prompt"},
+		{"tiny-chat", 1024, "no room for the prompt"},
+		{"text-embedding-3-small", 100, "does not serve chat completions"},
+		{"some-new-model", 100000, ""},
+	}
+	for _, tt := range tests {
+		err := registry.Validate(tt.model, tt.maxTokens)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("Validate(%s, %d): unexpected error %v", tt.model, tt.maxTokens, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("Validate(%s, %d) = %v, want %q", tt.model, tt.maxTokens, err, tt.wantErr)
+		}
+	}
+
+	if _, err := ai.NewModelRegistry([]ai.ModelInfo{{Name: "broken", ContextWindow: 1000, MaxOutputTokens: 2000, Chat: true}}); err == nil {
+		t.Error("Expected an error for output tokens beyond the context window")
+	}
+}
+
+func TestSummarizerFitsPromptToModel(t *testing.T) {
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	issueData := testIssueData()
+	issueData.Issue.Body = github.String("START " + strings.Repeat("stack frame ", 4000) + " END")
+
+	if _, err := summarizer.SummarizeIssue(context.Background(), issueData); err != nil {
+		t.Fatalf("SummarizeIssue: %v", err)
+	}
+	request := fake.requests[0]
+	_, prompt := prompts(t, request)
+	if !strings.Contains(prompt, "characters omitted to fit the gpt-4 context window") {
+		t.Fatal("Expected the prompt shortened for gpt-4's 8K window")
+	}
+	if !strings.Contains(prompt, "START") || !strings.Contains(prompt, "Action: opened") {
+		t.Error("Expected the start of the issue and the event context kept")
+	}
+	if len(prompt)/4 > 8192-2000 {
+		t.Errorf("Expected the prompt to fit next to the completion, got about %d tokens", len(prompt)/4)
+	}
+	if request.ResponseFormat != nil {
+		t.Error("Expected no JSON mode for gpt-4")
+	}
+}
+
+func TestSummarizerUsesJSONMode(t *testing.T) {
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	summarizer.SetTriageModel("gpt-4o-mini")
+	issueData := testIssueData()
+	issueData.Issue.Body = github.String(strings.Repeat("stack frame ", 4000))
+
+	if _, err := summarizer.TriageIssue(context.Background(), issueData); err != nil {
+		t.Fatalf("TriageIssue: %v", err)
+	}
+	request := fake.requests[0]
+	if request.ResponseFormat == nil || request.ResponseFormat.Type != openai.ChatCompletionResponseFormatTypeJSONObject {
+		t.Errorf("Expected JSON mode for gpt-4o-mini, got %+v", request.ResponseFormat)
+	}
+	if _, prompt := prompts(t, request); strings.Contains(prompt, "omitted") {
+		t.Error("Expected the prompt unchanged for a 128K window")
+	}
+}
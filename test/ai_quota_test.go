@@ -0,0 +1,37 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/apperrors"
+)
+
+func TestTokenQuota(t *testing.T) {
+	summarizer, fake := newFakeSummarizer(new(MockMetricsRecorder))
+	quota := ai.NewTokenQuota(200)
+	summarizer.SetTokenQuota(quota)
+
+	// Each fake completion uses 120 tokens; the second overshoots the quota
+	for i := 0; i < 2; i++ {
+		if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err != nil {
+			t.Fatalf("request %d: expected success within the quota, got %v", i+1, err)
+		}
+	}
+	if quota.Used() != 240 {
+		t.Errorf("expected 240 tokens used, got %d", quota.Used())
+	}
+
+	_, err := summarizer.SummarizeIssue(context.Background(), testIssueData())
+	if !errors.Is(err, apperrors.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded, got %v", err)
+	}
+	if apperrors.IsRetryable(err) {
+		t.Error("expected an exhausted quota not to be retried")
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("expected no request over the quota, got %d requests", len(fake.requests))
+	}
+}
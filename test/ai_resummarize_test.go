@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+func TestResummarizeIssue(t *testing.T) {
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	issueData := testIssueData()
+	issueData.Commits = []*github.RepositoryCommit{{
+		SHA:    github.String("0123456789abcdef"),
+		Commit: &github.Commit{Message: github.String("Fix crash on save")},
+	}}
+
+	options := ai.ResummarizeOptions{Style: "security_expert", DetailLevel: "concise", Language: "Spanish"}
+	if _, err := summarizer.ResummarizeIssue(context.Background(), issueData, options); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	system, prompt := prompts(t, fake.requests[0])
+	for _, want := range []string{"SECURITY EXPERT", "Provide concise analysis", "in Spanish", "were left out on request"} {
+		if !strings.Contains(system, want) {
+			t.Errorf("Expected the system prompt to contain %q", want)
+		}
+	}
+	if strings.Contains(prompt, "## Related Commits") {
+		t.Error("Expected the commits to be left out without code context")
+	}
+	if len(issueData.Commits) != 1 {
+		t.Error("Expected the caller's issue data to be left unchanged")
+	}
+
+	// The summarizer's own settings are unaffected
+	if _, err := summarizer.SummarizeIssue(context.Background(), issueData); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	system, prompt = prompts(t, fake.requests[1])
+	if strings.Contains(system, "SECURITY EXPERT") || strings.Contains(system, "Spanish") {
+		t.Error("Expected the overrides to apply to the re-summarized request only")
+	}
+	if !strings.Contains(prompt, "## Related Commits") {
+		t.Error("Expected the commits in a regular summary")
+	}
+
+	options = ai.ResummarizeOptions{CodeContext: true}
+	if _, err := summarizer.ResummarizeIssue(context.Background(), issueData, options); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, prompt := prompts(t, fake.requests[2]); !strings.Contains(prompt, "## Related Commits") {
+		t.Error("Expected the commits with code context")
+	}
+	if got := options.Describe(); got != "with code context" {
+		t.Errorf("Unexpected description %q", got)
+	}
+}
+
+func TestResummarizeIssueUnknownStyle(t *testing.T) {
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+
+	if _, err := summarizer.ResummarizeIssue(context.Background(), testIssueData(), ai.ResummarizeOptions{Style: "pirate"}); err == nil {
+		t.Fatal("Expected an error for an unknown style")
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("Expected no request, got %d", len(fake.requests))
+	}
+}
+
+func TestResummarizeButton(t *testing.T) {
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+	summary, err := summarizeResponse(`{"title": "Crash", "summary": "It crashes", "priority": "high", "category": "bug", "confidence": 0.8}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ids := actionIDs(summarizer.GenerateSlackMessage(testIssueData(), summary))
+	found := false
+	for _, id := range ids {
+		found = found || id == "resummarize"
+	}
+	if !found {
+		t.Errorf("Expected a re-summarize button, got %v", ids)
+	}
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+// streamServer answers each streaming request with the next of parts, sent
+// as one chunk per word and finishing with the part's finish reason
+func streamServer(t *testing.T, parts []string, finishReasons []string) (*httptest.Server, *[]openai.ChatCompletionRequest) {
+	t.Helper()
+	var requests []openai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		part := len(requests) - 1
+		if part >= len(parts) {
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunk := func(content, finishReason string) {
+			choice := map[string]interface{}{"index": 0, "delta": map[string]string{"content": content}}
+			if finishReason != "" {
+				choice["finish_reason"] = finishReason
+			}
+			body, _ := json.Marshal(map[string]interface{}{"id": "chatcmpl-test", "object": "chat.completion.chunk", "choices": []interface{}{choice}})
+			fmt.Fprintf(w, "data: %s\n\n", body)
+		}
+		for _, word := range strings.SplitAfter(parts[part], " ") {
+			chunk(word, "")
+		}
+		chunk("", finishReasons[part])
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newStreamingSummarizer(t *testing.T, server *httptest.Server) *ai.Summarizer {
+	t.Helper()
+	mockMetrics := &MockMetricsRecorder{}
+	mockMetrics.On("RecordOpenAIRequest", mock.Anything, mock.Anything, mock.Anything).Return()
+	mockMetrics.On("RecordOpenAIError", mock.Anything).Return()
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 50, 0.2, zap.NewNop(), mockMetrics)
+	if err := summarizer.SetClientOptions(ai.ClientOptions{BaseURL: server.URL + "/v1"}); err != nil {
+		t.Fatalf("SetClientOptions: %v", err)
+	}
+	return summarizer
+}
+
+func TestStreamSuggestedFixContinuesTruncatedFix(t *testing.T) {
+	server, requests := streamServer(t,
+		[]string{"Root cause: a nil map.\n```go\nm := map[string]int{}\n", "m[\"a\"] = 1\n```"},
+		[]string{"length", "stop"})
+	summarizer := newStreamingSummarizer(t, server)
+
+	var updates []string
+	fix, err := summarizer.StreamSuggestedFix(context.Background(), testIssueData(), func(text string) {
+		updates = append(updates, text)
+	})
+	if err != nil {
+		t.Fatalf("StreamSuggestedFix: %v", err)
+	}
+
+	want := "Root cause: a nil map.\n```go\nm := map[string]int{}\nm[\"a\"] = 1\n```"
+	if fix != want {
+		t.Errorf("Expected the parts stitched together, got %q", fix)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("Expected a continuation request, got %d requests", len(*requests))
+	}
+	continuation := (*requests)[1].Messages
+	if len(continuation) != 4 || continuation[2].Role != openai.ChatMessageRoleAssistant || continuation[2].Content != "Root cause: a nil map.\n```go\nm := map[string]int{}\n" {
+		t.Errorf("Expected the continuation to carry the partial fix, got %+v", continuation)
+	}
+	if len(updates) == 0 || updates[len(updates)-1] != want {
+		t.Errorf("Expected streamed updates to end with the whole fix, got %q", updates)
+	}
+}
+
+func TestStreamSuggestedFixClosesFixStillTruncated(t *testing.T) {
+	server, requests := streamServer(t,
+		[]string{"```sh\nstep one\n", "step two\n", "step three"},
+		[]string{"length", "length", "length"})
+	summarizer := newStreamingSummarizer(t, server)
+
+	fix, err := summarizer.StreamSuggestedFix(context.Background(), testIssueData(), nil)
+	if err != nil {
+		t.Fatalf("StreamSuggestedFix: %v", err)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("Expected two continuations at most, got %d requests", len(*requests))
+	}
+	if strings.Count(fix, "```")%2 != 0 || !strings.Contains(fix, "step three\n```") || !strings.Contains(fix, "cut off") {
+		t.Errorf("Expected the code block closed and the cut noted, got %q", fix)
+	}
+}
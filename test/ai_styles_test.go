@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+func TestPromptStyleOverridesSettings(t *testing.T) {
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err != nil {
+		t.Fatalf("SummarizeIssue: %v", err)
+	}
+	if request := fake.requests[0]; request.Model != "gpt-4" || request.MaxTokens != 2000 {
+		t.Errorf("Expected the summarizer's settings, got %s with %d tokens", request.Model, request.MaxTokens)
+	}
+
+	style, _ := ai.GetPromptStyle("quick_triage")
+	summarizer.SetPromptStyle(style)
+	summary, err := summarizer.SummarizeIssue(context.Background(), testIssueData())
+	if err != nil {
+		t.Fatalf("SummarizeIssue: %v", err)
+	}
+	request := fake.requests[1]
+	if request.Model != "gpt-4o-mini" || request.MaxTokens != 800 || request.Temperature != 0.3 {
+		t.Errorf("Expected quick_triage's settings, got %s with %d tokens at %v", request.Model, request.MaxTokens, request.Temperature)
+	}
+	if len(summary.Usage) != 1 || summary.Usage[0].Model != "gpt-4o-mini" {
+		t.Errorf("Expected usage recorded for the style's model, got %+v", summary.Usage)
+	}
+	if summary.PromptStyle != "quick_triage" {
+		t.Errorf("Expected the summary attributed to quick_triage, got %q", summary.PromptStyle)
+	}
+
+	// A style chosen for one summary brings its model along
+	summarizer.SetPromptStyle(ai.DefaultPromptStyle())
+	if _, err := summarizer.ResummarizeIssue(context.Background(), testIssueData(), ai.ResummarizeOptions{Style: "security_critical"}); err != nil {
+		t.Fatalf("ResummarizeIssue: %v", err)
+	}
+	if request := fake.requests[2]; request.Model != "gpt-4o" || request.MaxTokens != 2000 || request.Temperature != 0.2 {
+		t.Errorf("Expected security_critical's settings, got %s with %d tokens at %v", request.Model, request.MaxTokens, request.Temperature)
+	}
+}
+
+func TestCustomPromptStyle(t *testing.T) {
+	personality := "You are the SRE on call for Acme's payment API. Flag anything touching card data."
+	if err := ai.SetCustomPromptStyle("payments_oncall", ai.PromptStyle{Personality: personality, Tone: "concise"}); err != nil {
+		t.Fatalf("SetCustomPromptStyle: %v", err)
+	}
+	t.Cleanup(func() { ai.DeleteCustomPromptStyle("payments_oncall") })
+
+	found := false
+	for _, name := range ai.ListPromptStyles() {
+		found = found || name == "payments_oncall"
+	}
+	if !found {
+		t.Error("Expected the custom style listed")
+	}
+
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	if _, err := summarizer.ResummarizeIssue(context.Background(), testIssueData(), ai.ResummarizeOptions{Style: "payments_oncall"}); err != nil {
+		t.Fatalf("ResummarizeIssue: %v", err)
+	}
+	if system, _ := prompts(t, fake.requests[0]); !contains(system, personality) {
+		t.Error("Expected the custom personality text in the system prompt")
+	}
+
+	if err := ai.SetCustomPromptStyle("quick_triage", ai.PromptStyle{Personality: "x"}); err == nil {
+		t.Error("Expected predefined styles not to be replaced")
+	}
+}
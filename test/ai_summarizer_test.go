@@ -1,10 +1,16 @@
 package test
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/go-github/v57/github"
+	openai "github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 
@@ -29,6 +35,10 @@ func (m *MockMetricsRecorder) RecordOpenAIError(errorType string) {
 	m.Called(errorType)
 }
 
+func (m *MockMetricsRecorder) RecordPromptInjection(source, kind string) {
+	m.Called(source, kind)
+}
+
 func TestDefaultPromptStyle(t *testing.T) {
 	style := ai.DefaultPromptStyle()
 
@@ -49,23 +59,123 @@ func TestDefaultPromptStyle(t *testing.T) {
 	}
 }
 
+// fakeChatCompleter records chat completion requests and answers them with
+// content
+type fakeChatCompleter struct {
+	requests []openai.ChatCompletionRequest
+	content  string
+	err      error
+}
+
+func (f *fakeChatCompleter) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.requests = append(f.requests, request)
+	if f.err != nil {
+		return openai.ChatCompletionResponse{}, f.err
+	}
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: f.content}}},
+		Usage:   openai.Usage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+	}, nil
+}
+
+func (f *fakeChatCompleter) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	f.requests = append(f.requests, request)
+	return nil, errors.New("streaming is not supported by the fake")
+}
+
+// newFakeSummarizer returns a summarizer whose requests go to a fake that
+// answers with a valid summary
+func newFakeSummarizer(mockMetrics *MockMetricsRecorder) (*ai.Summarizer, *fakeChatCompleter) {
+	fake := &fakeChatCompleter{content: `{"title": "Crash on save", "summary": "Saving crashes the editor", "priority": "high", "category": "bug", "action_items": ["Reproduce"], "confidence": 0.9}`}
+	mockMetrics.On("RecordOpenAIRequest", mock.Anything, mock.Anything, mock.Anything).Return()
+	mockMetrics.On("RecordOpenAITokens", mock.Anything, mock.Anything, mock.Anything).Return()
+	mockMetrics.On("RecordOpenAIError", mock.Anything).Return()
+	mockMetrics.On("RecordPromptInjection", mock.Anything, mock.Anything).Return()
+	return ai.NewSummarizerWithClient(fake, "gpt-4", 2000, 0.7, zap.NewNop(), mockMetrics), fake
+}
+
+// testIssueData is a minimal issue for summarizing
+func testIssueData() *gh.IssueData {
+	return &gh.IssueData{
+		Issue: &github.Issue{
+			Number: github.Int(123),
+			Title:  github.String("Test Issue"),
+			Body:   github.String("This is a test issue body"),
+		},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+		EventType:  "issues",
+		Action:     "opened",
+	}
+}
+
+// prompts returns the system and user prompts of a recorded request
+func prompts(t *testing.T, request openai.ChatCompletionRequest) (string, string) {
+	t.Helper()
+	if len(request.Messages) != 2 {
+		t.Fatalf("Expected a system and a user message, got %d messages", len(request.Messages))
+	}
+	return request.Messages[0].Content, request.Messages[1].Content
+}
+
 func TestNewSummarizer(t *testing.T) {
-	logger := zap.NewNop()
 	mockMetrics := &MockMetricsRecorder{}
+	summarizer, fake := newFakeSummarizer(mockMetrics)
 
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
+	summary, err := summarizer.SummarizeIssue(context.Background(), testIssueData())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-	if summarizer == nil {
-		t.Fatal("Expected summarizer to be created")
+	if summary.Title != "Crash on save" || summary.Priority != "high" || summary.Category != "bug" {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if len(summary.ActionItems) != 1 || summary.Confidence != 0.9 {
+		t.Errorf("Expected action items and confidence from the response, got %+v", summary)
+	}
+
+	if len(fake.requests) != 1 {
+		t.Fatalf("Expected one request, got %d", len(fake.requests))
+	}
+	request := fake.requests[0]
+	if request.Model != "gpt-4" || request.MaxTokens != 2000 || request.Temperature != 0.7 {
+		t.Errorf("Expected the configured model, max tokens and temperature, got %s, %d, %v", request.Model, request.MaxTokens, request.Temperature)
+	}
+	system, _ := prompts(t, request)
+	if !contains(system, "MASTER ANALYST") {
+		t.Error("Expected the default prompt style's personality in the system prompt")
 	}
-	// Note: Fields are unexported, so we can't test them directly
-	// The summarizer creation without error indicates success
+
+	mockMetrics.AssertCalled(t, "RecordOpenAIRequest", "gpt-4", "success", mock.Anything)
+	mockMetrics.AssertCalled(t, "RecordOpenAITokens", "gpt-4", "prompt", 100)
+	mockMetrics.AssertCalled(t, "RecordOpenAITokens", "gpt-4", "completion", 20)
 }
 
-func TestNewSummarizerWithStyle(t *testing.T) {
-	logger := zap.NewNop()
+func TestNewSummarizerAPIError(t *testing.T) {
+	mockMetrics := &MockMetricsRecorder{}
+	summarizer, fake := newFakeSummarizer(mockMetrics)
+	fake.err = &openai.APIError{HTTPStatusCode: 429, Message: "Rate limit reached"}
+
+	if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err == nil {
+		t.Fatal("Expected the API error to be returned")
+	}
+
+	mockMetrics.AssertCalled(t, "RecordOpenAIRequest", "gpt-4", "error", mock.Anything)
+	mockMetrics.AssertCalled(t, "RecordOpenAIError", mock.Anything)
+	mockMetrics.AssertNotCalled(t, "RecordOpenAITokens", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNewSummarizerUnparsableResponse(t *testing.T) {
 	mockMetrics := &MockMetricsRecorder{}
+	summarizer, fake := newFakeSummarizer(mockMetrics)
+	fake.content = "I cannot help with that."
 
+	if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err == nil {
+		t.Fatal("Expected an error for a response without a summary")
+	}
+	mockMetrics.AssertCalled(t, "RecordOpenAIError", "parse_error")
+}
+
+func TestNewSummarizerWithStyle(t *testing.T) {
 	customStyle := ai.PromptStyle{
 		Personality:   "SENIOR DEVELOPER",
 		AnalysisFocus: "technical_impact",
@@ -74,20 +184,35 @@ func TestNewSummarizerWithStyle(t *testing.T) {
 		CustomFields:  map[string]string{"test": "value"},
 	}
 
-	summarizer := ai.NewSummarizerWithStyle("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics, customStyle)
-
+	summarizer := ai.NewSummarizerWithStyle("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{}, customStyle)
 	if summarizer == nil {
 		t.Fatal("Expected summarizer to be created")
 	}
-	// Note: Fields are unexported, so we can't test them directly
-	// The summarizer creation without error indicates success
+
+	mockMetrics := &MockMetricsRecorder{}
+	summarizer, fake := newFakeSummarizer(mockMetrics)
+	summarizer.SetPromptStyle(customStyle)
+
+	if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	system, _ := prompts(t, fake.requests[0])
+	if !contains(system, "SENIOR DEVELOPER") {
+		t.Error("Expected the custom personality in the system prompt")
+	}
+	if contains(system, "MASTER ANALYST") {
+		t.Error("Expected the default personality to be replaced")
+	}
 }
 
 func TestSetPromptStyle(t *testing.T) {
-	logger := zap.NewNop()
 	mockMetrics := &MockMetricsRecorder{}
+	summarizer, fake := newFakeSummarizer(mockMetrics)
 
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
+	if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	newStyle := ai.PromptStyle{
 		Personality:   "SECURITY EXPERT",
@@ -96,20 +221,34 @@ func TestSetPromptStyle(t *testing.T) {
 		DetailLevel:   "comprehensive",
 		CustomFields:  map[string]string{"security_level": "critical"},
 	}
-
 	summarizer.SetPromptStyle(newStyle)
 
-	// Note: Fields are unexported, so we can't test them directly
-	// The method call without error indicates success
-	_ = summarizer // Use the variable to avoid unused variable error
-}
+	if _, err := summarizer.SummarizeIssue(context.Background(), testIssueData()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-func TestBuildPrompt(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
+	before, _ := prompts(t, fake.requests[0])
+	after, _ := prompts(t, fake.requests[1])
+	if before == after {
+		t.Error("Expected the system prompt to change with the prompt style")
+	}
+	if !contains(after, "SECURITY EXPERT") {
+		t.Error("Expected the new personality in the system prompt")
+	}
+}
 
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
+// summarizeForPrompt summarizes issueData with a fake client and returns
+// the prompts that were sent
+func summarizeForPrompt(t *testing.T, issueData *gh.IssueData) (string, string) {
+	t.Helper()
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	if _, err := summarizer.SummarizeIssue(context.Background(), issueData); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return prompts(t, fake.requests[0])
+}
 
+func TestBuildPrompt(t *testing.T) {
 	// Create test issue data
 	issue := &github.Issue{
 		Number: github.Int(123),
@@ -144,18 +283,36 @@ func TestBuildPrompt(t *testing.T) {
 		Action:     "opened",
 	}
 
-	// Note: buildPrompt is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer // Use the variable to avoid unused variable error
-	_ = issueData  // Use the variable to avoid unused variable error
+	_, prompt := summarizeForPrompt(t, issueData)
+	for _, want := range []string{"Repository: test/repo", "Issue #123: Test Issue", "State: open", "Created by: testuser", "Labels: bug, high-priority", "This is a test issue body", "Event Type: issues", "Action: opened"} {
+		if !contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
+	for _, section := range []string{"## Recent Comments", "## Related Commits", "## Code Changes", "## Repository Stack"} {
+		if contains(prompt, section) {
+			t.Errorf("Expected no %q section without data", section)
+		}
+	}
 }
 
-func TestBuildPromptWithComments(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
+func TestBuildPromptWithStack(t *testing.T) {
+	issueData := testIssueData()
+	issueData.Stack = &gh.RepositoryStack{
+		Languages:  []gh.LanguageShare{{Name: "Go", Percent: 91}, {Name: "Shell", Percent: 9}},
+		Tooling:    []string{"Go 1.21 modules", "Docker"},
+		Frameworks: []string{"Gin"},
+	}
 
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
+	_, prompt := summarizeForPrompt(t, issueData)
+	for _, want := range []string{"## Repository Stack", "- Languages: Go (91%), Shell (9%)", "- Tooling: Go 1.21 modules, Docker", "- Frameworks: Gin"} {
+		if !contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
+}
 
+func TestBuildPromptWithComments(t *testing.T) {
 	issue := &github.Issue{
 		Number: github.Int(123),
 		Title:  github.String("Test Issue"),
@@ -202,18 +359,15 @@ func TestBuildPromptWithComments(t *testing.T) {
 		Action:     "opened",
 	}
 
-	// Note: buildPrompt is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer // Use the variable to avoid unused variable error
-	_ = issueData  // Use the variable to avoid unused variable error
+	_, prompt := summarizeForPrompt(t, issueData)
+	for _, want := range []string{"## Recent Comments", "Comment by commenter", "This is a comment", "Comment by another-commenter", "This is another comment"} {
+		if !contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
 }
 
 func TestBuildPromptWithCommits(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
-
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
-
 	issue := &github.Issue{
 		Number: github.Int(123),
 		Title:  github.String("Test Issue"),
@@ -255,18 +409,15 @@ func TestBuildPromptWithCommits(t *testing.T) {
 		Action:     "opened",
 	}
 
-	// Note: buildPrompt is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer // Use the variable to avoid unused variable error
-	_ = issueData  // Use the variable to avoid unused variable error
+	_, prompt := summarizeForPrompt(t, issueData)
+	for _, want := range []string{"## Related Commits", "Commit: abc123de", "Author: Test Author", "Message: Fix issue #123"} {
+		if !contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
 }
 
 func TestBuildPromptWithFiles(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
-
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
-
 	issue := &github.Issue{
 		Number: github.Int(123),
 		Title:  github.String("Test Issue"),
@@ -306,18 +457,23 @@ func TestBuildPromptWithFiles(t *testing.T) {
 		Action:     "opened",
 	}
 
-	// Note: buildPrompt is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer // Use the variable to avoid unused variable error
-	_ = issueData  // Use the variable to avoid unused variable error
+	_, prompt := summarizeForPrompt(t, issueData)
+	for _, want := range []string{"## Code Changes", "File: src/main.go", "Status: modified", "Additions: 10, Deletions: 5", "+func new() {"} {
+		if !contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q", want)
+		}
+	}
 }
 
-func TestParseSummaryResponse(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
-
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
+// summarizeResponse summarizes a test issue with a fake client that answers
+// with response
+func summarizeResponse(response string) (*ai.IssueSummary, error) {
+	summarizer, fake := newFakeSummarizer(&MockMetricsRecorder{})
+	fake.content = response
+	return summarizer.SummarizeIssue(context.Background(), testIssueData())
+}
 
+func TestParseSummaryResponse(t *testing.T) {
 	validJSON := `{
 		"title": "Test Issue Summary",
 		"summary": "This is a test summary",
@@ -329,79 +485,159 @@ func TestParseSummaryResponse(t *testing.T) {
 		"confidence": 0.85
 	}`
 
-	// Note: parseSummaryResponse is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer // Use the variable to avoid unused variable error
-	_ = validJSON  // Use the variable to avoid unused variable error
+	summary, err := summarizeResponse(validJSON)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if summary.Title != "Test Issue Summary" || summary.Summary != "This is a test summary" {
+		t.Errorf("Unexpected title and summary %q, %q", summary.Title, summary.Summary)
+	}
+	if summary.Priority != "high" || summary.Category != "bug" {
+		t.Errorf("Expected high bug, got %s %s", summary.Priority, summary.Category)
+	}
+	if len(summary.ActionItems) != 2 || summary.ActionItems[0] != "Fix the bug" {
+		t.Errorf("Expected the action items, got %v", summary.ActionItems)
+	}
+	if summary.CodeContext != "The issue is in the main function" {
+		t.Errorf("Expected the code context, got %q", summary.CodeContext)
+	}
+	if summary.SuggestedFix != "Replace the problematic code" || summary.Confidence != 0.85 {
+		t.Errorf("Expected the suggested fix and confidence, got %q, %v", summary.SuggestedFix, summary.Confidence)
+	}
 }
 
 func TestParseSummaryResponseWithDefaults(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
-
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
-
 	// Test with minimal JSON (missing optional fields)
 	minimalJSON := `{
 		"title": "Test Issue Summary",
 		"summary": "This is a test summary"
 	}`
 
-	// Note: parseSummaryResponse is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer  // Use the variable to avoid unused variable error
-	_ = minimalJSON // Use the variable to avoid unused variable error
-}
+	summary, err := summarizeResponse(minimalJSON)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-func TestParseSummaryResponseWithMarkdown(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
+	if summary.Priority != "medium" || summary.Category != "other" {
+		t.Errorf("Expected default priority and category, got %s %s", summary.Priority, summary.Category)
+	}
+	if summary.ActionItems == nil || len(summary.ActionItems) != 0 {
+		t.Errorf("Expected empty action items, got %v", summary.ActionItems)
+	}
+	if summary.Confidence != 0.5 {
+		t.Errorf("Expected default confidence 0.5, got %v", summary.Confidence)
+	}
+	if summary.CodeContext == "" || summary.SuggestedFix == "" {
+		t.Error("Expected default code context and suggested fix")
+	}
+}
 
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
+func TestParseSummaryResponseMissingInfo(t *testing.T) {
+	summary, err := summarizeResponse(`{
+		"title": "Crash on start",
+		"summary": "The app crashes",
+		"missing_info": ["version", "  steps\nto reproduce ", "", "OS", "logs", "config", "browser"]
+	}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"version", "steps to reproduce", "OS", "logs", "config"}
+	if !reflect.DeepEqual(summary.MissingInfo, want) {
+		t.Errorf("Expected the first five missing details on one line each, got %q", summary.MissingInfo)
+	}
+}
 
+func TestParseSummaryResponseWithMarkdown(t *testing.T) {
 	// Test with markdown code blocks
 	jsonWithMarkdown := "```json\n{\n  \"title\": \"Test Issue Summary\",\n  \"summary\": \"This is a test summary\"\n}\n```"
 
-	// Note: parseSummaryResponse is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer       // Use the variable to avoid unused variable error
-	_ = jsonWithMarkdown // Use the variable to avoid unused variable error
+	summary, err := summarizeResponse(jsonWithMarkdown)
+	if err != nil {
+		t.Fatalf("Expected the code fence to be stripped, got %v", err)
+	}
+	if summary.Title != "Test Issue Summary" {
+		t.Errorf("Unexpected title %q", summary.Title)
+	}
 }
 
 func TestParseSummaryResponseInvalidJSON(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
-
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
-
 	invalidJSON := `{
 		"title": "Test Issue Summary",
 		"summary": "This is a test summary",
 		"invalid_field": "value"
 	}`
 
-	// Note: parseSummaryResponse is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer  // Use the variable to avoid unused variable error
-	_ = invalidJSON // Use the variable to avoid unused variable error
+	// Unknown fields are ignored
+	if _, err := summarizeResponse(invalidJSON); err != nil {
+		t.Errorf("Expected unknown fields to be ignored, got %v", err)
+	}
+
+	if _, err := summarizeResponse(`{"title": "Test Issue Summary",`); err == nil {
+		t.Error("Expected an error for truncated JSON")
+	}
 }
 
 func TestParseSummaryResponseMissingRequiredFields(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockMetricsRecorder{}
-
-	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, logger, mockMetrics)
-
 	// Test with missing required fields
 	invalidJSON := `{
 		"priority": "high",
 		"category": "bug"
 	}`
 
-	// Note: parseSummaryResponse is unexported, so we can't test it directly
-	// The summarizer creation without error indicates success
-	_ = summarizer  // Use the variable to avoid unused variable error
-	_ = invalidJSON // Use the variable to avoid unused variable error
+	if _, err := summarizeResponse(invalidJSON); err == nil {
+		t.Error("Expected an error for a response without title and summary")
+	}
+}
+
+func TestParseSummaryResponseSchemaErrors(t *testing.T) {
+	_, err := summarizeResponse(`{
+		"Title": "Crash on start",
+		"summary": " ",
+		"confidence": "high",
+		"action_items": "Fix it"
+	}`)
+	var schemaErr *ai.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected a schema error, got %v", err)
+	}
+	want := []string{
+		"summary must not be empty",
+		`action_items must be a list of strings, got "Fix it"`,
+		`confidence must be a number, got "high"`,
+	}
+	if !reflect.DeepEqual(schemaErr.Problems, want) {
+		t.Errorf("Expected every wrong field named, got %q", schemaErr.Problems)
+	}
+
+	// Long values are shortened in the error without splitting a character
+	_, err = summarizeResponse(`{"title": "Crash on start", "summary": "The app crashes", "action_items": "` + strings.Repeat("ü", 50) + `"}`)
+	if !errors.As(err, &schemaErr) || len(schemaErr.Problems) != 1 || !utf8.ValidString(schemaErr.Problems[0]) ||
+		!strings.HasSuffix(schemaErr.Problems[0], "...") {
+		t.Errorf("Expected the long value shortened, got %v", err)
+	}
+
+	summary, err := summarizeResponse(`{"title": "Crash on start", "summary": "The app crashes"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if summary.SchemaVersion != ai.SummarySchemaVersion || summary.Reasoning == nil {
+		t.Errorf("Expected a summary of the current schema, got %+v", summary)
+	}
+}
+
+func TestMigrateSummary(t *testing.T) {
+	old := ai.IssueSummary{Title: "Crash", Priority: "high"}
+	migrated := ai.MigrateSummary(old)
+	if migrated.SchemaVersion != ai.SummarySchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", ai.SummarySchemaVersion, migrated.SchemaVersion)
+	}
+	if migrated.Reasoning == nil || migrated.Components == nil || migrated.MissingInfo == nil || migrated.ActionItems == nil {
+		t.Errorf("Expected the lists added since filled in, got %+v", migrated)
+	}
+	if old.SchemaVersion != 0 || old.Reasoning != nil {
+		t.Error("Expected the original summary unchanged")
+	}
 }
 
 func TestGenerateSlackMessage(t *testing.T) {
@@ -706,3 +942,402 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGenerateCompactSlackMessage(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue: &github.Issue{
+			Number:  github.Int(7),
+			HTMLURL: github.String("https://github.com/test/repo/issues/7"),
+		},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+	}
+	summary := &ai.IssueSummary{
+		Title:    "Login fails",
+		Summary:  "Users cannot log in after the upgrade.\nSecond line with details",
+		Priority: "high",
+		Category: "bug",
+	}
+
+	message := summarizer.GenerateCompactSlackMessage(issueData, summary)
+
+	blocks := message["blocks"].([]map[string]interface{})
+	if len(blocks) != 1 {
+		t.Fatalf("Expected a single block, got %d", len(blocks))
+	}
+
+	text := blocks[0]["text"].(map[string]interface{})["text"].(string)
+	for _, want := range []string{"🔴", "<https://github.com/test/repo/issues/7|test/repo#7: Login fails>", "High", "Users cannot log in after the upgrade."} {
+		if !contains(text, want) {
+			t.Errorf("Expected compact text to contain %q, got %q", want, text)
+		}
+	}
+	if contains(text, "Second line") {
+		t.Errorf("Expected only the first summary line, got %q", text)
+	}
+}
+
+func TestGenerateSlackMessageFallbackText(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue: &github.Issue{
+			Number: github.Int(7),
+			Title:  github.String("Login broken"),
+			State:  github.String("closed"),
+		},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+	}
+	summary := &ai.IssueSummary{
+		Title:    "Login fails",
+		Summary:  "Users cannot log in after the upgrade.\nSecond line with details",
+		Priority: "high",
+		Category: "bug",
+	}
+
+	want := "High priority bug in test/repo#7: Login fails (closed) — Users cannot log in after the upgrade."
+	for name, message := range map[string]map[string]interface{}{
+		"detailed": summarizer.GenerateSlackMessage(issueData, summary),
+		"compact":  summarizer.GenerateCompactSlackMessage(issueData, summary),
+	} {
+		if message["text"] != want {
+			t.Errorf("Expected %s fallback text %q, got %q", name, want, message["text"])
+		}
+	}
+	if text := summarizer.GenerateSkippedSlackMessage(issueData, "it looks like a test")["text"]; text != "Issue in test/repo#7: Login broken (not analyzed)" {
+		t.Errorf("Unexpected skipped fallback text %q", text)
+	}
+
+	tmpl, err := ai.ParseFallbackTemplate("{{upper .Summary.Priority}}: {{.Repository}}#{{.IssueNumber}}\n{{.IssueTitle}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summarizer.SetFallbackTemplate(tmpl)
+	if text := summarizer.GenerateSlackMessage(issueData, summary)["text"]; text != "HIGH: test/repo#7 Login broken" {
+		t.Errorf("Expected the template's fallback text, got %q", text)
+	}
+
+	if _, err := ai.ParseFallbackTemplate("{{.Summary"); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+}
+
+func TestGeneratePlainSlackMessage(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue: &github.Issue{
+			Number:  github.Int(7),
+			HTMLURL: github.String("https://github.com/test/repo/issues/7"),
+		},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+	}
+	summary := &ai.IssueSummary{
+		Title:       "Login fails 🔥",
+		Summary:     "Users cannot log in :sob:",
+		Priority:    "high",
+		Category:    "bug",
+		BoostedFrom: "medium",
+		ActionItems: []string{"Roll back the session change", "Add a login test"},
+	}
+
+	message := summarizer.GeneratePlainSlackMessage(issueData, summary)
+	var texts []string
+	for _, block := range message["blocks"].([]map[string]interface{}) {
+		if block["type"] != "section" {
+			t.Errorf("Expected only text sections, got a %v block", block["type"])
+		}
+		texts = append(texts, block["text"].(map[string]interface{})["text"].(string))
+	}
+	want := []string{
+		"*<https://github.com/test/repo/issues/7|test/repo#7: Login fails>*",
+		"Priority: High, raised from Medium by upvotes. Category: Bug.",
+		"Summary: Users cannot log in",
+		"Action items:\n1. Roll back the session change\n2. Add a login test",
+	}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected sections %q, got %q", want, texts)
+	}
+	if text := message["text"].(string); !strings.HasPrefix(text, "High priority bug in test/repo#7: Login fails —") {
+		t.Errorf("Expected fallback text without emoji, got %q", text)
+	}
+}
+
+func TestGenerateDegradedSlackMessage(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue: &github.Issue{
+			Number:  github.Int(7),
+			Title:   github.String("Login broken"),
+			HTMLURL: github.String("https://github.com/test/repo/issues/7"),
+			User:    &github.User{Login: github.String("octocat")},
+			Labels:  []*github.Label{{Name: github.String("bug")}, {Name: github.String("auth")}},
+		},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+	}
+
+	message := summarizer.GenerateDegradedSlackMessage(issueData, "OpenAI is not responding")
+	blocks := message["blocks"].([]map[string]interface{})
+	if len(blocks) != 3 {
+		t.Fatalf("Expected title, reason and actions blocks, got %+v", blocks)
+	}
+	if text := blocks[0]["text"].(map[string]interface{})["text"]; text != "*<https://github.com/test/repo/issues/7|test/repo#7: Login broken>*\nOpened by @octocat · `bug` `auth`" {
+		t.Errorf("Unexpected issue section %q", text)
+	}
+	if text := blocks[1]["elements"].([]map[string]interface{})[0]["text"]; text != ":warning: No AI summary: OpenAI is not responding." {
+		t.Errorf("Unexpected reason %q", text)
+	}
+	actions := blocks[2]["elements"].([]map[string]interface{})
+	if retry := actions[len(actions)-1]; retry["action_id"] != "retry_summary" || retry["value"] != "test/repo:7" {
+		t.Errorf("Expected a retry button, got %+v", retry)
+	}
+	if message["text"] != "Issue in test/repo#7: Login broken (not analyzed)" {
+		t.Errorf("Unexpected fallback text %q", message["text"])
+	}
+}
+
+func TestGenerateBuildBreakSlackMessage(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue: &github.Issue{
+			Number:  github.Int(12),
+			Title:   github.String("CI failed on main"),
+			HTMLURL: github.String("https://github.com/test/repo/issues/12"),
+		},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+	}
+	failure := &gh.CIFailure{
+		Workflow:  "CI",
+		RunURL:    "https://github.com/test/repo/actions/runs/100",
+		Branch:    "main",
+		Job:       "test",
+		JobURL:    "https://github.com/test/repo/actions/runs/100/job/2",
+		Step:      "Run tests",
+		Errors:    []string{"--- FAIL: TestRefund (0.00s)"},
+		Commit:    gh.CICommit{SHA: "bbbbbbb", URL: "https://github.com/test/repo/commit/bbbbbbb", Author: "bob", Message: "Change refunds"},
+		Suspects:  []gh.CICommit{{SHA: "ccccccc", URL: "https://github.com/test/repo/commit/ccccccc", Message: "Tidy up"}, {SHA: "bbbbbbb", URL: "https://github.com/test/repo/commit/bbbbbbb", Message: "Change refunds"}},
+		LastGreen: "aaaaaaa",
+	}
+
+	message := summarizer.GenerateBuildBreakSlackMessage(issueData, failure)
+	blocks := message["blocks"].([]map[string]interface{})
+	if len(blocks) != 5 {
+		t.Fatalf("Expected header, job, error, commit and suspects blocks, got %+v", blocks)
+	}
+	if text := blocks[0]["text"].(map[string]interface{})["text"]; text != ":rotating_light: *Build break:* <https://github.com/test/repo/actions/runs/100|CI> on `main`\n*<https://github.com/test/repo/issues/12|test/repo#12: CI failed on main>*" {
+		t.Errorf("Unexpected header %q", text)
+	}
+	if text := blocks[2]["text"].(map[string]interface{})["text"]; text != "*Error:*\n```--- FAIL: TestRefund (0.00s)```" {
+		t.Errorf("Unexpected error excerpt %q", text)
+	}
+	if text := blocks[3]["text"].(map[string]interface{})["text"]; text != "*Probable Offending Commit:*\n<https://github.com/test/repo/commit/bbbbbbb|`bbbbbbb`> Change refunds · bob" {
+		t.Errorf("Unexpected commit %q", text)
+	}
+	if text := blocks[4]["elements"].([]map[string]interface{})[0]["text"].(string); !strings.Contains(text, "last green build (`aaaaaaa`)") {
+		t.Errorf("Unexpected suspects %q", text)
+	}
+	if message["text"] != "Build break in test/repo#12: CI failed on main" {
+		t.Errorf("Unexpected fallback text %q", message["text"])
+	}
+}
+
+func TestGenerateSlackMessageRegression(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue:      &github.Issue{Number: github.Int(7)},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+		RegressionHints: []gh.RegressionHint{{
+			Release:    "v1.4.2",
+			ReleaseURL: "https://github.com/test/repo/releases/v1.4.2",
+			Commit:     "abc1234",
+			CommitURL:  "https://github.com/test/repo/commit/abc1234",
+			File:       "internal/auth/auth.go",
+		}},
+	}
+	summary := &ai.IssueSummary{
+		Title:      "Login broken",
+		Summary:    "Users cannot log in",
+		Priority:   "high",
+		Category:   "bug",
+		Regression: "possibly introduced in v1.4.2 (commit abc1234 touched auth.go 3 days before the first report)",
+	}
+
+	var found string
+	for _, block := range summarizer.GenerateSlackMessage(issueData, summary)["blocks"].([]map[string]interface{}) {
+		if text, ok := block["text"].(map[string]interface{}); ok && strings.HasPrefix(text["text"].(string), "*Possible Regression:*") {
+			found = text["text"].(string)
+		}
+	}
+	want := "*Possible Regression:*\npossibly introduced in <https://github.com/test/repo/releases/v1.4.2|v1.4.2> (commit <https://github.com/test/repo/commit/abc1234|abc1234> touched auth.go 3 days before the first report)"
+	if found != want {
+		t.Errorf("Expected %q, got %q", want, found)
+	}
+
+	summary.Regression = ""
+	for _, block := range summarizer.GenerateSlackMessage(issueData, summary)["blocks"].([]map[string]interface{}) {
+		if text, ok := block["text"].(map[string]interface{}); ok && strings.HasPrefix(text["text"].(string), "*Possible Regression:*") {
+			t.Error("Expected no regression section without a hint from the AI")
+		}
+	}
+}
+
+func TestGenerateSlackMessageWithFormFields(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue:      &github.Issue{Number: github.Int(9)},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+		FormFields: gh.ParseIssueForm("### Version\n\nv2.0.1\n\n### OS\n\nUbuntu 22.04"),
+	}
+	summary := &ai.IssueSummary{Title: "Crash", Summary: "It crashes", Priority: "low", Category: "bug"}
+
+	message := summarizer.GenerateSlackMessage(issueData, summary)
+	blocks := message["blocks"].([]map[string]interface{})
+
+	fields, ok := blocks[2]["fields"].([]map[string]interface{})
+	if !ok || len(fields) != 2 {
+		t.Fatalf("Expected form field section as third block, got %+v", blocks[2])
+	}
+	if fields[0]["text"] != "*Version:*\nv2.0.1" || fields[1]["text"] != "*OS:*\nUbuntu 22.04" {
+		t.Errorf("Unexpected form fields %+v", fields)
+	}
+}
+
+func TestGenerateSlackMessageWithEnvironment(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	body := "Export fails since v2.3.1 on Windows 11."
+	issueData := &gh.IssueData{
+		Issue:       &github.Issue{Number: github.Int(9), Body: github.String(body)},
+		Repository:  &github.Repository{FullName: github.String("test/repo")},
+		Environment: gh.ExtractEnvironment(body, nil),
+	}
+	summary := &ai.IssueSummary{Title: "Export fails", Summary: "It fails", Priority: "low", Category: "bug"}
+
+	message := summarizer.GenerateSlackMessage(issueData, summary)
+	blocks := message["blocks"].([]map[string]interface{})
+
+	fields, ok := blocks[2]["fields"].([]map[string]interface{})
+	if !ok || len(fields) != 2 {
+		t.Fatalf("Expected environment section as third block, got %+v", blocks[2])
+	}
+	if fields[0]["text"] != "*OS:*\nWindows 11" || fields[1]["text"] != "*Version:*\n2.3.1" {
+		t.Errorf("Unexpected environment fields %+v", fields)
+	}
+}
+
+func TestFormatSuggestedFix(t *testing.T) {
+	plain := ai.FormatSuggestedFix("  retry the request  ")
+	if plain != ":wrench: *Suggested Fix:*\n```\nretry the request\n```" {
+		t.Errorf("Expected plain fix to be wrapped in a code block, got %q", plain)
+	}
+
+	// Slack shows a fence's language as a line of code, and GitHub's bold as
+	// literal asterisks
+	fenced := "**Nil map write.**\n```go\nm = map[string]int{}\n```"
+	if got := ai.FormatSuggestedFix(fenced); got != ":wrench: *Suggested Fix:*\n*Nil map write.*\n```\nm = map[string]int{}\n```" {
+		t.Errorf("Expected fenced fix converted to Slack markup, got %q", got)
+	}
+}
+
+func TestGenerateSlackMessageTriage(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue:      &github.Issue{Number: github.Int(3)},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+	}
+	summary := &ai.IssueSummary{Title: "Typo in docs", Summary: "README typo", Priority: "low", Category: "documentation", Triage: true}
+
+	message := summarizer.GenerateSlackMessage(issueData, summary)
+	blocks := message["blocks"].([]map[string]interface{})
+
+	for _, block := range blocks {
+		if text, ok := block["text"].(map[string]interface{}); ok && contains(text["text"].(string), "Action Items") {
+			t.Error("Expected triage summary to omit the deep analysis sections")
+		}
+	}
+
+	actions := blocks[len(blocks)-1]["elements"].([]map[string]interface{})
+	if actions[len(actions)-1]["action_id"] != "deep_analysis" || actions[len(actions)-1]["value"] != "test/repo:3" {
+		t.Errorf("Expected a Deep Analysis button, got %+v", actions)
+	}
+}
+
+func TestPriorityAtLeast(t *testing.T) {
+	tests := []struct {
+		priority, threshold string
+		want                bool
+	}{
+		{"high", "high", true},
+		{"critical", "high", true},
+		{"Medium", "high", false},
+		{"medium", "medium", true},
+		{"low", "medium", false},
+		{"unknown", "low", false},
+	}
+
+	for _, tt := range tests {
+		if got := ai.PriorityAtLeast(tt.priority, tt.threshold); got != tt.want {
+			t.Errorf("PriorityAtLeast(%q, %q) = %v, want %v", tt.priority, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The bot crashes when the webhook payload is missing the sender", "en"},
+		{"El bot se cierra cuando el webhook no tiene el remitente y no funciona", "es"},
+		{"Le bot plante quand le webhook est vide et je ne sais pas pourquoi", "fr"},
+		{"Der Bot stürzt ab, wenn der Webhook leer ist und ich weiß nicht warum", "de"},
+		{"Бот падает, когда вебхук пустой", "ru"},
+		{"ボットがクラッシュします", "ja"},
+		{"机器人在启动时崩溃", "zh"},
+		{"웹훅이 비어 있으면 봇이 충돌합니다", "ko"},
+		{"Crash", ""},
+		{"Der Bot stürzt ab:\n```\npanic: runtime error: the index is out of range and it is not handled\n```\nwenn der Webhook leer ist", "de"},
+	}
+
+	for _, tt := range tests {
+		if got := ai.DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateSlackMessageTranslated(t *testing.T) {
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+
+	issueData := &gh.IssueData{
+		Issue:      &github.Issue{Number: github.Int(3), Title: github.String("Se cierra al iniciar")},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+	}
+	summary := &ai.IssueSummary{Title: "Crash on start", Summary: "The app crashes on start", Priority: "high", Category: "bug", Language: "es", Original: "La aplicación se cierra"}
+
+	found := false
+	for _, block := range summarizer.GenerateSlackMessage(issueData, summary)["blocks"].([]map[string]interface{}) {
+		if block["type"] != "context" {
+			continue
+		}
+		text := block["elements"].([]map[string]interface{})[0]["text"].(string)
+		if text == ":globe_with_meridians: Translated from Spanish\n>La aplicación se cierra" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the original-language snippet in the message")
+	}
+
+	compact := summarizer.GenerateCompactSlackMessage(issueData, summary)
+	text := compact["blocks"].([]map[string]interface{})[0]["text"].(map[string]interface{})["text"].(string)
+	if !contains(text, ":globe_with_meridians: Spanish") {
+		t.Errorf("Expected the language in the compact message, got %q", text)
+	}
+}
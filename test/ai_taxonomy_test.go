@@ -0,0 +1,144 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+)
+
+func TestDefaultTaxonomyResolvesAnswers(t *testing.T) {
+	taxonomy := ai.DefaultTaxonomy()
+
+	tests := []struct {
+		answer string
+		want   string
+		known  bool
+	}{
+		{"bug", "bug", true},
+		{"Bug", "bug", true},
+		{"Technical Debt", "technical-debt", true},
+		{"technical_debt", "technical-debt", true},
+		{"docs", "documentation", true},
+		{"perf", "performance", true},
+		{"perfomance", "performance", true},
+		{"security-vulnerability", "security", true},
+		{"bug|feature", "bug", true},
+		{"", "other", false},
+		{"question", "other", false},
+	}
+
+	for _, tt := range tests {
+		entry, known := taxonomy.Category(tt.answer)
+		if entry.Name != tt.want || known != tt.known {
+			t.Errorf("Category(%q) = %s, %v, want %s, %v", tt.answer, entry.Name, known, tt.want, tt.known)
+		}
+	}
+
+	if entry, known := taxonomy.Priority("HIGH"); entry.Name != "high" || !known {
+		t.Errorf("Expected HIGH to resolve to high, got %s", entry.Name)
+	}
+	if entry, known := taxonomy.Priority("urgent"); entry.Name != "medium" || known {
+		t.Errorf("Expected an unknown priority to fall back to medium, got %s", entry.Name)
+	}
+}
+
+func TestNewTaxonomyValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ai.TaxonomyConfig
+	}{
+		{"unknown priority", ai.TaxonomyConfig{Priorities: []ai.TaxonomyEntry{{Name: "p0"}}}},
+		{"missing name", ai.TaxonomyConfig{Categories: []ai.TaxonomyEntry{{Emoji: "🐛"}}}},
+		{"duplicate name", ai.TaxonomyConfig{Categories: []ai.TaxonomyEntry{{Name: "bug"}, {Name: "Bug"}}}},
+		{"alias reused", ai.TaxonomyConfig{Categories: []ai.TaxonomyEntry{{Name: "bug"}, {Name: "defect", Aliases: []string{"bug"}}}}},
+		{"undefined fallback", ai.TaxonomyConfig{Categories: []ai.TaxonomyEntry{{Name: "bug"}}, FallbackCategory: "triage"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := ai.NewTaxonomy(tt.config); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestCustomTaxonomy(t *testing.T) {
+	taxonomy, err := ai.NewTaxonomy(ai.TaxonomyConfig{
+		Priorities: []ai.TaxonomyEntry{
+			{Name: "low", Emoji: "🐢"},
+			{Name: "critical", Emoji: "🔥", Labels: []string{"P0"}},
+		},
+		Categories: []ai.TaxonomyEntry{
+			{Name: "incident", Description: "Production is degraded", Emoji: "🚒", Aliases: []string{"outage"}, Labels: []string{"kind/incident"}},
+			{Name: "billing", Description: "Invoices and payments", Emoji: "💳"},
+			{Name: "triage", Description: "Needs a human to classify"},
+		},
+		FallbackPriority: "low",
+		FallbackCategory: "triage",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if priorities := taxonomy.Priorities(); priorities[0].Name != "critical" || priorities[1].Name != "low" {
+		t.Errorf("Expected priorities ordered highest first, got %v", priorities)
+	}
+	if entry, _ := taxonomy.Category("bug"); entry.Name != "triage" {
+		t.Errorf("Expected the fallback category, got %s", entry.Name)
+	}
+	if entry, _ := taxonomy.Priority("medium"); entry.Name != "low" {
+		t.Errorf("Expected the fallback priority, got %s", entry.Name)
+	}
+	if labels := taxonomy.Labels("critical", "incident"); strings.Join(labels, ",") != "P0,kind/incident" {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+	if priority, category := taxonomy.Emojis("critical", "triage"); priority != "🔥" || category != "📋" {
+		t.Errorf("Unexpected emojis %s %s", priority, category)
+	}
+	// Raising skips priorities the taxonomy does not have
+	if raised := taxonomy.Raise("low"); raised != "critical" {
+		t.Errorf("Expected low to be raised to critical, got %s", raised)
+	}
+	if raised := taxonomy.Raise("critical"); raised != "critical" {
+		t.Errorf("Expected the highest priority to stay, got %s", raised)
+	}
+
+	// The summarizer offers the taxonomy to the AI and maps answers onto it
+	mockMetrics := &MockMetricsRecorder{}
+	summarizer, fake := newFakeSummarizer(mockMetrics)
+	summarizer.SetTaxonomy(taxonomy)
+	fake.content = `{"title": "Checkout down", "summary": "Payments fail", "priority": "Critical", "category": "outage"}`
+
+	summary, err := summarizer.SummarizeIssue(context.Background(), testIssueData())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if summary.Priority != "critical" || summary.Category != "incident" {
+		t.Errorf("Expected critical incident, got %s %s", summary.Priority, summary.Category)
+	}
+
+	system, _ := prompts(t, fake.requests[0])
+	for _, want := range []string{`"priority": "critical|low`, `"category": "incident|billing|triage"`, "- billing: Invoices and payments"} {
+		if !contains(system, want) {
+			t.Errorf("Expected the system prompt to contain %q", want)
+		}
+	}
+
+	// Slack messages use the taxonomy's emojis, or the built-in ones by default
+	if text := compactText(summarizer.GenerateCompactSlackMessage(testIssueData(), summary)); !contains(text, "🔥") {
+		t.Errorf("Expected the taxonomy's priority emoji, got %q", text)
+	}
+	defaults := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), mockMetrics)
+	if text := compactText(defaults.GenerateCompactSlackMessage(testIssueData(), summary)); !contains(text, "🚨") {
+		t.Errorf("Expected the default critical emoji, got %q", text)
+	}
+}
+
+// compactText returns the text of a compact Slack message
+func compactText(message map[string]interface{}) string {
+	blocks := message["blocks"].([]map[string]interface{})
+	return blocks[0]["text"].(map[string]interface{})["text"].(string)
+}
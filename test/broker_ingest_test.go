@@ -0,0 +1,107 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/broker"
+	gh "github-issue-ai-bot/internal/github"
+)
+
+// recordingPublisher captures published messages
+type recordingPublisher struct {
+	messages []broker.Message
+	err      error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, msg broker.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func signedWebhookRequest(payload, eventType string) *http.Request {
+	mac := hmac.New(sha256.New, []byte("test-secret"))
+	mac.Write([]byte(payload))
+
+	req := httptest.NewRequest("POST", "/webhook/github", bytes.NewBufferString(payload))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-GitHub-Event", eventType)
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	return req
+}
+
+func TestHandleWebhookReceiverModePublishes(t *testing.T) {
+	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockMetrics.On("RecordGitHubWebhook", "issues", "", "queued", mock.AnythingOfType("time.Duration")).Return()
+
+	publisher := &recordingPublisher{}
+	handler := gh.NewHandler("test-token", "test-secret", zap.NewNop(), mockMetrics)
+	handler.SetPublisher(publisher)
+
+	payload := `{"action":"opened","issue":{"number":1}}`
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, signedWebhookRequest(payload, "issues"))
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", w.Code)
+	}
+	if len(publisher.messages) != 1 {
+		t.Fatalf("Expected one published message, got %d", len(publisher.messages))
+	}
+	msg := publisher.messages[0]
+	if msg.EventType != "issues" || msg.DeliveryID != "delivery-1" || string(msg.Payload) != payload {
+		t.Errorf("Unexpected published message %+v", msg)
+	}
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestHandleWebhookReceiverModePublishFailure(t *testing.T) {
+	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockMetrics.On("RecordGitHubWebhook", "issues", "", "error", mock.AnythingOfType("time.Duration")).Return()
+
+	handler := gh.NewHandler("test-token", "test-secret", zap.NewNop(), mockMetrics)
+	handler.SetPublisher(&recordingPublisher{err: errors.New("broker down")})
+
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, signedWebhookRequest(`{"action":"opened"}`, "issues"))
+
+	// GitHub retries deliveries that fail with 5xx
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestHandleEventSkippedAction(t *testing.T) {
+	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockMetrics.On("RecordGitHubWebhook", "issues", "", "skipped", mock.AnythingOfType("time.Duration")).Return()
+
+	mockProcessor := &MockIssueProcessor{}
+	handler := gh.NewHandler("test-token", "test-secret", zap.NewNop(), mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
+
+	err := handler.HandleEvent(context.Background(), broker.Message{
+		EventType: "issues",
+		Payload:   []byte(`{"action":"transferred","issue":{"number":1}}`),
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	mockProcessor.AssertNotCalled(t, "ProcessIssue", mock.Anything)
+	mockMetrics.AssertExpectations(t)
+}
@@ -1,11 +1,20 @@
 package test
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github-issue-ai-bot/internal/config"
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/notify"
+	"github-issue-ai-bot/internal/routing"
+	"github-issue-ai-bot/internal/schedule"
+	"github-issue-ai-bot/internal/teams"
 )
 
 func TestConfigDefaults(t *testing.T) {
@@ -337,3 +346,397 @@ func TestConfigInvalidValues(t *testing.T) {
 		t.Errorf("Expected default read timeout 30s, got %v", cfg.Server.ReadTimeout)
 	}
 }
+
+func TestConfigSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	secretsDir := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secretsDir, 0o700); err != nil {
+		t.Fatalf("Failed to create secrets dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(secretsDir, "openai-api-key"), []byte("dir-key"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(secretsDir, "ADMIN_TOKEN"), []byte("dir-admin-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+
+	// Empty values are treated as unset so the file sources are used
+	t.Setenv("GITHUB_ACCESS_TOKEN", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ADMIN_TOKEN", "")
+	t.Setenv("GITHUB_ACCESS_TOKEN_FILE", tokenFile)
+	t.Setenv("SECRETS_DIR", secretsDir)
+	t.Setenv("SLACK_BOT_TOKEN", "env-token")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.GitHub.AccessToken != "file-token" {
+		t.Errorf("Expected access token from file, got %q", cfg.GitHub.AccessToken)
+	}
+	if cfg.OpenAI.APIKey != "dir-key" {
+		t.Errorf("Expected API key from secrets dir, got %q", cfg.OpenAI.APIKey)
+	}
+	if cfg.Slack.BotToken != "env-token" {
+		t.Errorf("Expected env var to take precedence, got %q", cfg.Slack.BotToken)
+	}
+	if cfg.Server.AdminToken != "dir-admin-token" || cfg.Secrets.Files["ADMIN_TOKEN"] == "" {
+		t.Errorf("Expected the admin token from secrets dir and tracked for rotation, got %q", cfg.Server.AdminToken)
+	}
+	if cfg.Secrets.Files["GITHUB_ACCESS_TOKEN"] != tokenFile {
+		t.Errorf("Expected token file to be tracked, got %q", cfg.Secrets.Files["GITHUB_ACCESS_TOKEN"])
+	}
+	if _, ok := cfg.Secrets.Files["SLACK_BOT_TOKEN"]; ok {
+		t.Error("Expected env-provided secret not to be tracked as a file")
+	}
+}
+
+func TestConfigUnreadableSecretFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing-token")
+	t.Setenv("GITHUB_ACCESS_TOKEN", "")
+	t.Setenv("GITHUB_ACCESS_TOKEN_FILE", missing)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "GITHUB_ACCESS_TOKEN file") || !strings.Contains(err.Error(), missing) {
+		t.Errorf("Expected the unreadable token file named, got %v", err)
+	}
+}
+
+func TestSecretWatcherDetectsRotation(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("old"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	changes := make(map[string]string)
+	watcher := config.NewSecretWatcher(map[string]string{"GITHUB_ACCESS_TOKEN": tokenFile}, time.Second, func(key, value string) {
+		changes[key] = value
+	})
+
+	watcher.Check()
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes before rotation, got %v", changes)
+	}
+
+	if err := os.WriteFile(tokenFile, []byte("new\n"), 0o600); err != nil {
+		t.Fatalf("Failed to rotate token file: %v", err)
+	}
+	watcher.Check()
+	if changes["GITHUB_ACCESS_TOKEN"] != "new" {
+		t.Errorf("Expected rotated value 'new', got %q", changes["GITHUB_ACCESS_TOKEN"])
+	}
+
+	// An empty file (e.g. mid-rotation) must not clobber the current value
+	delete(changes, "GITHUB_ACCESS_TOKEN")
+	if err := os.WriteFile(tokenFile, nil, 0o600); err != nil {
+		t.Fatalf("Failed to truncate token file: %v", err)
+	}
+	watcher.Check()
+	if len(changes) != 0 {
+		t.Errorf("Expected empty file to be ignored, got %v", changes)
+	}
+}
+
+func TestConfigValidateIngestModes(t *testing.T) {
+	receiver := &config.Config{
+		GitHub: config.GitHubConfig{WebhookSecret: "test-secret", AccessToken: "test-token"},
+		Ingest: config.IngestConfig{Mode: "receiver"},
+	}
+	if err := receiver.Validate(); err == nil {
+		t.Error("Expected error for receiver mode without broker type")
+	}
+
+	// Receivers do not need OpenAI or Slack credentials
+	receiver.Ingest.Broker.Type = "nats"
+	if err := receiver.Validate(); err != nil {
+		t.Errorf("Expected receiver config to be valid, got %v", err)
+	}
+
+	worker := &config.Config{
+		GitHub: config.GitHubConfig{WebhookSecret: "test-secret", AccessToken: "test-token"},
+		Ingest: config.IngestConfig{Mode: "worker"},
+	}
+	worker.Ingest.Broker.Type = "kafka"
+	if err := worker.Validate(); err == nil {
+		t.Error("Expected worker mode to require OpenAI and Slack credentials")
+	}
+
+	invalid := &config.Config{
+		GitHub: config.GitHubConfig{WebhookSecret: "test-secret", AccessToken: "test-token"},
+		Ingest: config.IngestConfig{Mode: "sidecar"},
+	}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for unknown ingest mode")
+	}
+}
+
+func TestConfigValidateWebhookSources(t *testing.T) {
+	cfg := &config.Config{
+		GitHub: config.GitHubConfig{WebhookSecret: "test-secret", AccessToken: "test-token"},
+		Ingest: config.IngestConfig{Mode: "receiver"},
+	}
+	cfg.Ingest.Broker.Type = "nats"
+	cfg.GitHub.Sources = gh.SourceConfig{Enabled: true, Refresh: time.Hour}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an allowlist with neither /meta nor static ranges")
+	}
+
+	cfg.GitHub.Sources.AllowedCIDRs = []string{"10.0.0.0/8", "github.example.com"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an invalid range")
+	}
+
+	cfg.GitHub.Sources.AllowedCIDRs = []string{"10.0.0.0/8"}
+	cfg.GitHub.Sources.Meta = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected allowlist to be valid, got %v", err)
+	}
+
+	cfg.GitHub.Sources.Refresh = time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a refresh interval under a minute")
+	}
+}
+
+func TestConfigValidateTeams(t *testing.T) {
+	cfg := &config.Config{
+		GitHub: config.GitHubConfig{WebhookSecret: "test-secret", AccessToken: "test-token"},
+		OpenAI: config.OpenAIConfig{APIKey: "test-openai-key"},
+		Slack:  config.SlackConfig{BotToken: "test-slack-token", SigningSecret: "test-signing-secret", ChannelID: "test-channel"},
+	}
+	cfg.Teams.Teams = []teams.Team{{Name: "payments", Repositories: []string{"acme/payments-*"}, Channel: "C-PAY"}}
+	cfg.Teams.Digest = schedule.Schedule{Weekday: "monday", Hour: 9}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected teams to be valid, got %v", err)
+	}
+
+	cfg.Teams.Digest.Weekday = "mondays"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an unknown digest weekday")
+	}
+
+	cfg.Teams.Digest.Weekday = ""
+	cfg.Teams.Teams = append(cfg.Teams.Teams, teams.Team{Name: "payments", Repositories: []string{"acme/billing"}})
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a team defined twice")
+	}
+}
+
+func TestConfigValidateTenants(t *testing.T) {
+	newConfig := func(tenants ...config.TenantConfig) *config.Config {
+		return &config.Config{
+			GitHub:  config.GitHubConfig{WebhookSecret: "test-secret", AccessToken: "test-token"},
+			OpenAI:  config.OpenAIConfig{APIKey: "test-openai-key"},
+			Slack:   config.SlackConfig{BotToken: "test-slack-token", SigningSecret: "test-signing-secret", ChannelID: "test-channel"},
+			Tenants: tenants,
+		}
+	}
+	team := config.TenantConfig{
+		Name:                "team-a",
+		GitHubAccessToken:   "team-token",
+		GitHubWebhookSecret: "team-secret",
+		SlackBotToken:       "team-slack-token",
+		SlackSigningSecret:  "team-signing-secret",
+		SlackChannelID:      "team-channel",
+	}
+
+	if err := newConfig(team).Validate(); err != nil {
+		t.Errorf("Expected tenant config to be valid, got %v", err)
+	}
+
+	invalid := team
+	invalid.Name = "Team A"
+	if err := newConfig(invalid).Validate(); err == nil {
+		t.Error("Expected error for a tenant name that cannot be used in paths")
+	}
+
+	reserved := team
+	reserved.Name = config.DefaultTenant
+	if err := newConfig(reserved).Validate(); err == nil {
+		t.Error("Expected error for a tenant named after the default tenant")
+	}
+
+	if err := newConfig(team, team).Validate(); err == nil {
+		t.Error("Expected error for duplicate tenants")
+	}
+
+	missing := team
+	missing.SlackBotToken = ""
+	if err := newConfig(missing).Validate(); err == nil {
+		t.Error("Expected error for a tenant without a Slack bot token")
+	}
+
+	receiver := newConfig(team)
+	receiver.Ingest = config.IngestConfig{Mode: "receiver"}
+	receiver.Ingest.Broker.Type = "nats"
+	if err := receiver.Validate(); err == nil {
+		t.Error("Expected error for tenants in receiver mode")
+	}
+}
+
+func TestConfigValidateNotifiers(t *testing.T) {
+	cfg := &config.Config{
+		GitHub:    config.GitHubConfig{WebhookSecret: "test-secret", AccessToken: "test-token"},
+		OpenAI:    config.OpenAIConfig{APIKey: "test-openai-key"},
+		Slack:     config.SlackConfig{BotToken: "test-slack-token", SigningSecret: "test-signing-secret", ChannelID: "test-channel"},
+		Notifiers: []notify.Config{{Name: "siem", Type: notify.TypeWebhook, URL: "https://siem.example.com/hook"}},
+		Routing:   config.RoutingConfig{Rules: []routing.Rule{{Name: "security", Notifiers: []string{"slack", "siem"}}}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected notifier config to be valid, got %v", err)
+	}
+
+	cfg.Routing.Rules[0].Notifiers = []string{"pagerduty"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a rule naming an unknown notifier")
+	}
+
+	cfg.Routing.Rules = nil
+	cfg.Notifiers[0].Type = "pagerduty"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an unknown notifier type")
+	}
+
+	// Tenant rules name the tenant's own notifiers, not the deployment's
+	cfg.Notifiers[0].Type = notify.TypeWebhook
+	cfg.Tenants = []config.TenantConfig{{
+		Name:                "team-a",
+		GitHubAccessToken:   "team-token",
+		GitHubWebhookSecret: "team-secret",
+		SlackBotToken:       "team-slack-token",
+		SlackSigningSecret:  "team-signing-secret",
+		SlackChannelID:      "team-channel",
+		Notifiers: []notify.Config{{
+			Name: "mail", Type: notify.TypeEmail, URL: "smtp://mail.example.com:587",
+			From: "bot@example.com", To: []string{"team-a@example.com"},
+		}},
+		RoutingRules: []routing.Rule{{Name: "all", Notifiers: []string{"slack", "mail"}}},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected tenant notifiers to be valid, got %v", err)
+	}
+	cfg.Tenants[0].RoutingRules[0].Notifiers = []string{"siem"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a tenant rule naming the deployment's notifier")
+	}
+	cfg.Tenants[0].RoutingRules = nil
+	cfg.Tenants[0].Notifiers[0].To = nil
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for an invalid tenant notifier")
+	}
+}
+
+func TestConfigSchemaDefaults(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, setting := range config.Schema {
+		if seen[setting.Name] {
+			t.Errorf("%s is declared twice", setting.Name)
+		}
+		seen[setting.Name] = true
+		if setting.Default == "" {
+			continue
+		}
+		var err error
+		switch setting.Kind {
+		case config.KindBool:
+			_, err = strconv.ParseBool(setting.Default)
+		case config.KindInt:
+			_, err = strconv.Atoi(setting.Default)
+		case config.KindFloat:
+			_, err = strconv.ParseFloat(setting.Default, 64)
+		case config.KindDuration:
+			_, err = time.ParseDuration(setting.Default)
+		}
+		if err != nil {
+			t.Errorf("Invalid default of %s: %v", setting.Name, err)
+		}
+	}
+}
+
+func TestConfigReportsEveryInvalidSetting(t *testing.T) {
+	t.Setenv("GITHUB_WEBHOOK_SECRET", "test-secret")
+	t.Setenv("GITHUB_ACCESS_TOKEN", "")
+	t.Setenv("OPENAI_MAX_TOKENS", "lots")
+	t.Setenv("OPENAI_TEMPERATURE", "3")
+	t.Setenv("SERVER_READ_TIMEOUT", "-5s")
+	t.Setenv("SLACK_URGENCY", "yes")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.OpenAI.MaxTokens != 2000 || cfg.Server.ReadTimeout != 30*time.Second || !cfg.Slack.Urgency.Enabled {
+		t.Errorf("Expected invalid values replaced by their defaults, got %d, %v and %v", cfg.OpenAI.MaxTokens, cfg.Server.ReadTimeout, cfg.Slack.Urgency.Enabled)
+	}
+
+	var problems config.SettingErrors
+	if err := cfg.Validate(); !errors.As(err, &problems) {
+		t.Fatalf("Expected the invalid settings reported, got %v", err)
+	}
+	for _, want := range []string{
+		`OPENAI_MAX_TOKENS="lots" is not a whole number`,
+		`OPENAI_TEMPERATURE="3" must be between 0 and 2`,
+		`SERVER_READ_TIMEOUT="-5s" must not be negative`,
+		`SLACK_URGENCY="yes" must be true or false`,
+		`GITHUB_ACCESS_TOKEN is required`,
+	} {
+		if !strings.Contains(problems.Error(), want) {
+			t.Errorf("Expected %q among the problems, got %v", want, problems)
+		}
+	}
+}
+
+func TestConfigSettingSources(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("ghp_0123456789abcdef\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	t.Setenv("GITHUB_ACCESS_TOKEN", "")
+	t.Setenv("GITHUB_ACCESS_TOKEN_FILE", tokenFile)
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-short")
+	t.Setenv("DRIFT_BASELINE", "")
+	t.Setenv("DRIFT_BASELINE_DAYS", "7")
+	t.Setenv("SERVER_PORT", "")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Monitor.Drift.Baseline != 7*24*time.Hour {
+		t.Errorf("Expected the deprecated DRIFT_BASELINE_DAYS applied, got %v", cfg.Monitor.Drift.Baseline)
+	}
+	if warnings := cfg.Warnings(); len(warnings) != 1 || warnings[0] != "DRIFT_BASELINE_DAYS is deprecated, use DRIFT_BASELINE instead" {
+		t.Errorf("Expected a deprecation warning, got %v", warnings)
+	}
+
+	values := make(map[string]config.Value)
+	for _, value := range cfg.Settings() {
+		values[value.Name] = value
+	}
+	if len(values) != len(config.Schema) {
+		t.Errorf("Expected every declared setting read, got %d of %d", len(values), len(config.Schema))
+	}
+	for name, want := range map[string][2]string{
+		"GITHUB_ACCESS_TOKEN": {"****cdef", "file " + tokenFile},
+		"SLACK_BOT_TOKEN":     {"****", "env"},
+		"SERVER_PORT":         {"8080", "default"},
+		"DRIFT_BASELINE_DAYS": {"7", "env"},
+	} {
+		if value := values[name]; value.Display() != want[0] || value.Source != want[1] {
+			t.Errorf("Expected %s to be %q from %s, got %q from %s", name, want[0], want[1], value.Display(), value.Source)
+		}
+	}
+}
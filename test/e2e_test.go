@@ -0,0 +1,24 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github-issue-ai-bot/internal/harness"
+)
+
+// TestEndToEndFixtures replays each recorded scenario through the whole
+// server. Re-record them with NOTIFYOPS_RECORD_FIXTURES=1 after changing the
+// Slack layout on purpose.
+func TestEndToEndFixtures(t *testing.T) {
+	paths, err := filepath.Glob("fixtures/e2e/*.json")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("Expected fixtures, got %v (%v)", paths, err)
+	}
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			harness.Replay(t, path)
+		})
+	}
+}
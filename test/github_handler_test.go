@@ -2,18 +2,26 @@ package test
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-github/v57/github"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 
+	"github-issue-ai-bot/internal/apperrors"
+	"github-issue-ai-bot/internal/broker"
 	gh "github-issue-ai-bot/internal/github"
 )
 
@@ -30,34 +38,161 @@ func (m *MockGitHubMetricsRecorder) RecordGitHubAPIError(operation, errorType st
 	m.Called(operation, errorType)
 }
 
+func (m *MockGitHubMetricsRecorder) RecordStageTimeout(stage string) {
+	m.Called(stage)
+}
+
+func (m *MockGitHubMetricsRecorder) RecordWebhookQueueDepth(depth int) {
+	m.Called(depth)
+}
+
+func (m *MockGitHubMetricsRecorder) RecordWebhookSaturation(outcome string) {
+	m.Called(outcome)
+}
+
+// RecordEnrichmentStage is not mocked, as every enriched issue records its
+// stages
+func (m *MockGitHubMetricsRecorder) RecordEnrichmentStage(stage, status string, duration time.Duration) {
+}
+
 // MockIssueProcessor is a mock implementation of IssueProcessor
 type MockIssueProcessor struct {
 	mock.Mock
 }
 
-func (m *MockIssueProcessor) ProcessIssue(issueData *gh.IssueData) {
+func (m *MockIssueProcessor) ProcessIssue(ctx context.Context, issueData *gh.IssueData) {
 	m.Called(issueData)
 }
 
-func TestNewHandler(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockGitHubMetricsRecorder{}
+// fakeGitHubAPI serves issue test/repo#123 with one comment and one related
+// commit, and records the requests it receives
+type fakeGitHubAPI struct {
+	*httptest.Server
+	mu            sync.Mutex
+	requests      int
+	authorization string
+}
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+func newFakeGitHubAPI(t *testing.T) *fakeGitHubAPI {
+	api := &fakeGitHubAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.requests++
+		api.authorization = r.Header.Get("Authorization")
+		api.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/repos/test/repo/issues/123":
+			json.NewEncoder(w).Encode(github.Issue{
+				Number:        github.Int(123),
+				Title:         github.String("Test Issue"),
+				RepositoryURL: github.String("https://api.github.com/repos/test/repo"),
+			})
+		case "/repos/test/repo/issues/123/comments":
+			json.NewEncoder(w).Encode([]*github.IssueComment{{ID: github.Int64(1), Body: github.String("Same here")}})
+		case "/search/commits":
+			json.NewEncoder(w).Encode(github.CommitsSearchResult{
+				Total:   github.Int(1),
+				Commits: []*github.CommitResult{{SHA: github.String("abc123")}},
+			})
+		case "/repos/test/repo/commits/abc123":
+			json.NewEncoder(w).Encode(github.RepositoryCommit{
+				SHA:   github.String("abc123"),
+				Files: []*github.CommitFile{{Filename: github.String("main.go")}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
 
-	if handler == nil {
-		t.Fatal("Expected handler to be created")
+// stats returns the number of requests and the last Authorization header
+func (api *fakeGitHubAPI) stats() (int, string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.requests, api.authorization
+}
+
+// newTestHandler returns a handler whose GitHub client talks to server
+func newTestHandler(server *fakeGitHubAPI, secret string, metrics gh.MetricsRecorder) *gh.Handler {
+	client := github.NewClient(nil)
+	baseURL, _ := url.Parse(server.URL + "/")
+	client.BaseURL = baseURL
+	return gh.NewHandlerWithClient(client, secret, zap.NewNop(), metrics)
+}
+
+// issuesPayload is an issues event for test/repo#123
+func issuesPayload(action string) []byte {
+	return []byte(`{
+		"action": "` + action + `",
+		"issue": {
+			"number": 123,
+			"title": "Test Issue",
+			"body": "This is a test issue",
+			"state": "open",
+			"user": {"login": "testuser"},
+			"repository": {"full_name": "test/repo", "owner": {"login": "test"}, "name": "repo"}
+		},
+		"repository": {"full_name": "test/repo", "owner": {"login": "test"}, "name": "repo"},
+		"sender": {"login": "testuser"}
+	}`)
+}
+
+// commentPayload is an issue_comment event on test/repo#123
+func commentPayload(action string) []byte {
+	return []byte(`{
+		"action": "` + action + `",
+		"issue": {
+			"number": 123,
+			"title": "Test Issue",
+			"repository": {"full_name": "test/repo", "owner": {"login": "test"}, "name": "repo"}
+		},
+		"comment": {"id": 2, "body": "Any update?"},
+		"repository": {"full_name": "test/repo", "owner": {"login": "test"}, "name": "repo"}
+	}`)
+}
+
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestNewHandler(t *testing.T) {
+	server := newFakeGitHubAPI(t)
+	client := github.NewClient(nil).WithAuthToken("test-token")
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	handler := gh.NewHandlerWithClient(client, "test-secret", zap.NewNop(), &MockGitHubMetricsRecorder{})
+
+	issueData, err := handler.FetchEnrichedIssueData(context.Background(), "test/repo", 123)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if issueData.Issue.GetTitle() != "Test Issue" || len(issueData.Comments) != 1 {
+		t.Errorf("Expected the issue from the injected client, got %+v", issueData)
+	}
+	if _, authorization := server.stats(); authorization != "Bearer test-token" {
+		t.Errorf("Expected the client's token, got %q", authorization)
+	}
+
+	// Rotating the token keeps the injected base URL
+	handler.SetAccessToken("rotated-token")
+	if _, err := handler.FetchEnrichedIssueData(context.Background(), "test/repo", 123); err != nil {
+		t.Fatalf("Unexpected error after rotation: %v", err)
+	}
+	if _, authorization := server.stats(); authorization != "Bearer rotated-token" {
+		t.Errorf("Expected the rotated token, got %q", authorization)
 	}
-	// Note: Fields are unexported, so we can't test them directly
-	// The handler creation without error indicates success
 }
 
 func TestHandleWebhookValidSignature(t *testing.T) {
-	logger := zap.NewNop()
 	mockMetrics := &MockGitHubMetricsRecorder{}
 	mockProcessor := &MockIssueProcessor{}
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	server := newFakeGitHubAPI(t)
+	handler := newTestHandler(server, "test-secret", mockMetrics)
 	handler.SetIssueProcessor(mockProcessor)
 
 	// Create test webhook payload
@@ -100,11 +235,10 @@ func TestHandleWebhookValidSignature(t *testing.T) {
 
 	// Set up mock expectations
 	mockMetrics.On("RecordGitHubWebhook", "issues", "opened", "success", mock.AnythingOfType("time.Duration")).Return()
-	// External API calls may fail during tests; allow optional error recordings
-	mockMetrics.On("RecordGitHubAPIError", "fetch_comments", "api_error").Return().Maybe()
-	mockMetrics.On("RecordGitHubAPIError", "fetch_commits", "api_error").Return().Maybe()
-	mockMetrics.On("RecordGitHubAPIError", "fetch_files", "api_error").Return().Maybe()
-	mockProcessor.On("ProcessIssue", mock.Anything).Return()
+	processed := make(chan *gh.IssueData, 1)
+	mockProcessor.On("ProcessIssue", mock.Anything).Run(func(args mock.Arguments) {
+		processed <- args.Get(0).(*gh.IssueData)
+	}).Return()
 
 	// Handle webhook
 	handler.HandleWebhook(w, req)
@@ -114,6 +248,16 @@ func TestHandleWebhookValidSignature(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 
+	// The issue is processed in the background
+	select {
+	case issueData := <-processed:
+		if issueData.DeliveryID != "test-delivery-id" || len(issueData.Comments) != 1 {
+			t.Errorf("Unexpected issue data %+v", issueData)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the issue to be processed")
+	}
+
 	// Verify mock calls
 	mockMetrics.AssertExpectations(t)
 	mockProcessor.AssertExpectations(t)
@@ -256,131 +400,382 @@ func TestHandleWebhookInvalidJSON(t *testing.T) {
 	mockMetrics.AssertExpectations(t)
 }
 
+func TestHandleWebhookPayloads(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		code      int
+		action    string
+		status    string
+		processed bool
+		repo      string // Repository of the processed issue
+	}{
+		{"empty body", "issues", "", http.StatusBadRequest, "", "invalid", false, ""},
+		{"whitespace body", "issue_comment", " \n", http.StatusBadRequest, "", "invalid", false, ""},
+		{"empty object", "issues", `{}`, http.StatusOK, "", "skipped", false, ""},
+		{"issues event without an issue", "issues", `{"action": "opened", "repository": {"full_name": "test/repo"}}`, http.StatusBadRequest, "", "invalid", false, ""},
+		{"comment event without an issue", "issue_comment", `{"action": "created", "comment": {"id": 2, "body": "Any update?"}}`, http.StatusBadRequest, "", "invalid", false, ""},
+		{"repository from the event", "issues", `{"action": "opened", "issue": {"number": 123, "title": "Test Issue"}, "repository": {"owner": {"login": "test"}, "name": "repo"}}`, http.StatusOK, "opened", "success", true, "test/repo"},
+		{"repository from the issue", "issues", `{"action": "opened", "issue": {"number": 123, "repository": {"full_name": "test/repo", "owner": {"login": "test"}, "name": "repo"}}}`, http.StatusOK, "opened", "success", true, "test/repo"},
+		{"no repository anywhere", "issues", `{"action": "opened", "issue": {"number": 123, "title": "Test Issue"}}`, http.StatusOK, "opened", "success", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockMetrics := &MockGitHubMetricsRecorder{}
+			mockProcessor := &MockIssueProcessor{}
+			server := newFakeGitHubAPI(t)
+			handler := newTestHandler(server, "test-secret", mockMetrics)
+			handler.SetIssueProcessor(mockProcessor)
+
+			mockMetrics.On("RecordGitHubWebhook", tt.eventType, tt.action, tt.status, mock.Anything).Return()
+			processed := make(chan *gh.IssueData, 1)
+			mockProcessor.On("ProcessIssue", mock.Anything).Run(func(args mock.Arguments) {
+				processed <- args.Get(0).(*gh.IssueData)
+			}).Return()
+
+			req := httptest.NewRequest("POST", "/webhook/github", strings.NewReader(tt.payload))
+			req.Header.Set("X-Hub-Signature-256", sign("test-secret", []byte(tt.payload)))
+			req.Header.Set("X-GitHub-Event", tt.eventType)
+			w := httptest.NewRecorder()
+			handler.HandleWebhook(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("Expected status %d, got %d", tt.code, w.Code)
+			}
+			mockMetrics.AssertExpectations(t)
+			if !tt.processed {
+				mockProcessor.AssertNotCalled(t, "ProcessIssue", mock.Anything)
+				return
+			}
+
+			select {
+			case issueData := <-processed:
+				if got := issueData.Repository.GetFullName(); got != tt.repo {
+					t.Errorf("Expected repository %q, got %q", tt.repo, got)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the issue to be processed")
+			}
+		})
+	}
+}
+
 func TestShouldProcessAction(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockGitHubMetricsRecorder{}
+	tests := []struct {
+		eventType string
+		action    string
+		processed bool
+	}{
+		{"issues", "opened", true},
+		{"issues", "reopened", true},
+		{"issues", "edited", true},
+		{"issues", "closed", true},
+		{"issues", "labeled", true},
+		{"issues", "transferred", false},
+		{"issues", "deleted", false},
+		{"issue_comment", "created", true},
+		{"issue_comment", "edited", false},
+	}
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	server := newFakeGitHubAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.eventType+"."+tt.action, func(t *testing.T) {
+			mockMetrics := &MockGitHubMetricsRecorder{}
+			mockProcessor := &MockIssueProcessor{}
+			handler := newTestHandler(server, "test-secret", mockMetrics)
+			handler.SetIssueProcessor(mockProcessor)
+
+			status := "skipped"
+			if tt.processed {
+				status = "success"
+				mockProcessor.On("ProcessIssue", mock.Anything).Return()
+			}
+			mockMetrics.On("RecordGitHubWebhook", tt.eventType, mock.Anything, status, mock.Anything).Return()
+
+			payload := issuesPayload(tt.action)
+			if tt.eventType == "issue_comment" {
+				payload = commentPayload(tt.action)
+			}
+			if err := handler.HandleEvent(context.Background(), broker.Message{EventType: tt.eventType, Payload: payload}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 
-	// Note: shouldProcessAction is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+			mockMetrics.AssertExpectations(t)
+			mockProcessor.AssertExpectations(t)
+		})
+	}
 }
 
 func TestVerifySignature(t *testing.T) {
-	logger := zap.NewNop()
-	mockMetrics := &MockGitHubMetricsRecorder{}
+	payload := []byte(`{"zen": "Keep it logically awesome."}`)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	tests := []struct {
+		name      string
+		signature string
+		want      int
+	}{
+		{"valid", sign("test-secret", payload), http.StatusOK},
+		{"wrong secret", sign("other-secret", payload), http.StatusUnauthorized},
+		{"missing prefix", strings.TrimPrefix(sign("test-secret", payload), "sha256="), http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := gh.NewHandler("test-token", "test-secret", zap.NewNop(), &MockGitHubMetricsRecorder{})
 
-	// Note: verifySignature is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+			req := httptest.NewRequest("POST", "/webhook/github", bytes.NewReader(payload))
+			req.Header.Set("X-Hub-Signature-256", tt.signature)
+			req.Header.Set("X-GitHub-Event", "ping")
+			w := httptest.NewRecorder()
+
+			handler.HandleWebhook(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
 }
 
 func TestHandleIssuesEvent(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockProcessor := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	var issueData *gh.IssueData
+	mockMetrics.On("RecordGitHubWebhook", "issues", "opened", "success", mock.Anything).Return()
+	mockProcessor.On("ProcessIssue", mock.Anything).Run(func(args mock.Arguments) {
+		issueData = args.Get(0).(*gh.IssueData)
+	}).Return()
+
+	err := handler.HandleEvent(context.Background(), broker.Message{
+		EventType:  "issues",
+		DeliveryID: "delivery-1",
+		Payload:    issuesPayload("opened"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if issueData == nil {
+		t.Fatal("Expected the issue to be processed")
+	}
+	if issueData.EventType != "issues" || issueData.Action != "opened" || issueData.Behavior != gh.BehaviorSummarize {
+		t.Errorf("Unexpected event fields %+v", issueData)
+	}
+	if issueData.DeliveryID != "delivery-1" {
+		t.Errorf("Expected delivery ID 'delivery-1', got %q", issueData.DeliveryID)
+	}
+	if issueData.Issue.GetNumber() != 123 || issueData.Repository.GetFullName() != "test/repo" {
+		t.Errorf("Unexpected issue %d in %s", issueData.Issue.GetNumber(), issueData.Repository.GetFullName())
+	}
+	mockMetrics.AssertExpectations(t)
+}
+
+// failingProcessor fails every issue it processes
+type failingProcessor struct{ err error }
+
+func (f failingProcessor) ProcessIssue(ctx context.Context, issueData *gh.IssueData) {}
 
-	// Note: handleIssuesEvent is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+func (f failingProcessor) ProcessDelivery(ctx context.Context, issueData *gh.IssueData) error {
+	return f.err
+}
+
+func TestHandleEventProcessingFailure(t *testing.T) {
+	server := newFakeGitHubAPI(t)
+	mockMetrics := &MockGitHubMetricsRecorder{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(failingProcessor{err: fmt.Errorf("failed to send notification: %w", apperrors.ErrUnavailable)})
+	mockMetrics.On("RecordGitHubWebhook", "issues", "opened", "success", mock.Anything).Return()
+
+	err := handler.HandleEvent(context.Background(), broker.Message{EventType: "issues", DeliveryID: "delivery-1", Payload: issuesPayload("opened")})
+	if !apperrors.IsRetryable(err) {
+		t.Errorf("Expected the retryable processing error returned to the broker, got %v", err)
+	}
 }
 
 func TestHandleIssuesEventSkippedAction(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockProcessor := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	mockMetrics.On("RecordGitHubWebhook", "issues", "", "skipped", mock.Anything).Return()
+
+	if err := handler.HandleEvent(context.Background(), broker.Message{EventType: "issues", Payload: issuesPayload("transferred")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-	// Note: handleIssuesEvent is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+	mockProcessor.AssertNotCalled(t, "ProcessIssue", mock.Anything)
+	if requests, _ := server.stats(); requests != 0 {
+		t.Errorf("Expected no GitHub API calls for a skipped action, got %d", requests)
+	}
+	mockMetrics.AssertExpectations(t)
 }
 
 func TestHandleIssueCommentEvent(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockProcessor := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	var issueData *gh.IssueData
+	mockMetrics.On("RecordGitHubWebhook", "issue_comment", "created", "success", mock.Anything).Return()
+	mockProcessor.On("ProcessIssue", mock.Anything).Run(func(args mock.Arguments) {
+		issueData = args.Get(0).(*gh.IssueData)
+	}).Return()
+
+	if err := handler.HandleEvent(context.Background(), broker.Message{EventType: "issue_comment", Payload: commentPayload("created")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-	// Note: handleIssueCommentEvent is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+	if issueData == nil {
+		t.Fatal("Expected the issue to be processed")
+	}
+	if issueData.EventType != "issue_comment" || issueData.Action != "created" {
+		t.Errorf("Unexpected event fields %+v", issueData)
+	}
+	mockMetrics.AssertExpectations(t)
 }
 
 func TestHandleIssueCommentEventSkippedAction(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockProcessor := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	mockMetrics.On("RecordGitHubWebhook", "issue_comment", "", "skipped", mock.Anything).Return()
+
+	if err := handler.HandleEvent(context.Background(), broker.Message{EventType: "issue_comment", Payload: commentPayload("deleted")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-	// Note: handleIssueCommentEvent is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+	mockProcessor.AssertNotCalled(t, "ProcessIssue", mock.Anything)
+	mockMetrics.AssertExpectations(t)
 }
 
 func TestEnrichIssueData(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockProcessor := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	var issueData *gh.IssueData
+	mockMetrics.On("RecordGitHubWebhook", "issues", "opened", "success", mock.Anything).Return()
+	mockProcessor.On("ProcessIssue", mock.Anything).Run(func(args mock.Arguments) {
+		issueData = args.Get(0).(*gh.IssueData)
+	}).Return()
 
-	// Note: enrichIssueData is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+	if err := handler.HandleEvent(context.Background(), broker.Message{EventType: "issues", Payload: issuesPayload("opened")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(issueData.Comments) != 1 || issueData.Comments[0].GetBody() != "Same here" {
+		t.Errorf("Expected the issue's comment, got %v", issueData.Comments)
+	}
+	if len(issueData.Commits) != 1 || issueData.Commits[0].GetSHA() != "abc123" {
+		t.Errorf("Expected the related commit, got %v", issueData.Commits)
+	}
+	if len(issueData.Files) != 1 || issueData.Files[0].GetFilename() != "main.go" {
+		t.Errorf("Expected the commit's files, got %v", issueData.Files)
+	}
+	mockMetrics.AssertNotCalled(t, "RecordGitHubAPIError", mock.Anything, mock.Anything)
 }
 
 func TestEnrichIssueDataNilIssue(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockProcessor := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	mockMetrics.On("RecordGitHubWebhook", "issues", "", "invalid", mock.Anything).Return()
+
+	// Retrying an event without an issue cannot succeed, so it is dropped
+	err := handler.HandleEvent(context.Background(), broker.Message{
+		EventType: "issues",
+		Payload:   []byte(`{"action": "opened", "repository": {"full_name": "test/repo"}}`),
+	})
+	if err != nil {
+		t.Fatalf("Expected an event without an issue to be dropped, got %v", err)
+	}
 
-	// Note: enrichIssueData is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+	mockProcessor.AssertNotCalled(t, "ProcessIssue", mock.Anything)
+	mockMetrics.AssertExpectations(t)
 }
 
 func TestEnrichIssueDataNilRepository(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	mockProcessor := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+	handler.SetIssueProcessor(mockProcessor)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
+	var issueData *gh.IssueData
+	mockMetrics.On("RecordGitHubWebhook", "issues", "opened", "success", mock.Anything).Return()
+	mockProcessor.On("ProcessIssue", mock.Anything).Run(func(args mock.Arguments) {
+		issueData = args.Get(0).(*gh.IssueData)
+	}).Return()
+
+	// Without a repository object, the repository comes from repository_url
+	err := handler.HandleEvent(context.Background(), broker.Message{
+		EventType: "issues",
+		Payload:   []byte(`{"action": "opened", "issue": {"number": 123, "title": "Test Issue", "repository_url": "https://api.github.com/repos/test/repo"}}`),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-	// Note: enrichIssueData is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+	if issueData.Repository.GetFullName() != "test/repo" || issueData.Repository.GetOwner().GetLogin() != "test" {
+		t.Errorf("Expected the repository from repository_url, got %+v", issueData.Repository)
+	}
+	if len(issueData.Comments) != 1 {
+		t.Errorf("Expected comments to be fetched for the derived repository, got %d", len(issueData.Comments))
+	}
 }
 
 func TestSetIssueProcessor(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
-	mockProcessor := &MockIssueProcessor{}
-
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
-
-	// Set processor
-	handler.SetIssueProcessor(mockProcessor)
+	first := &MockIssueProcessor{}
+	second := &MockIssueProcessor{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
+
+	mockMetrics.On("RecordGitHubWebhook", "issues", "opened", "success", mock.Anything).Return()
+	second.On("ProcessIssue", mock.Anything).Return()
+
+	// The latest processor receives the issues
+	handler.SetIssueProcessor(first)
+	handler.SetIssueProcessor(second)
+	if err := handler.HandleEvent(context.Background(), broker.Message{EventType: "issues", Payload: issuesPayload("opened")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-	// Note: processIssueData is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler       // Use the variable to avoid unused variable error
-	_ = mockProcessor // Use the variable to avoid unused variable error
+	first.AssertNotCalled(t, "ProcessIssue", mock.Anything)
+	second.AssertNumberOfCalls(t, "ProcessIssue", 1)
 }
 
 func TestProcessIssueDataNoProcessor(t *testing.T) {
-	logger := zap.NewNop()
+	server := newFakeGitHubAPI(t)
 	mockMetrics := &MockGitHubMetricsRecorder{}
+	handler := newTestHandler(server, "test-secret", mockMetrics)
 
-	handler := gh.NewHandler("test-token", "test-secret", logger, mockMetrics)
-	// No processor set
+	// Without a processor the event is still handled and counted
+	mockMetrics.On("RecordGitHubWebhook", "issues", "opened", "success", mock.Anything).Return()
 
-	// Note: processIssueData is unexported, so we can't test it directly
-	// The handler creation without error indicates success
-	_ = handler // Use the variable to avoid unused variable error
+	if err := handler.HandleEvent(context.Background(), broker.Message{EventType: "issues", Payload: issuesPayload("opened")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mockMetrics.AssertExpectations(t)
 }
 
 func TestExtractRepositoryInfo_GitHubHandler(t *testing.T) {
@@ -411,19 +806,26 @@ func TestExtractRepositoryInfo_GitHubHandler(t *testing.T) {
 }
 
 func TestGenerateSignature(t *testing.T) {
-	secret := "test-secret"
-	payload := []byte("test payload")
+	// Example from GitHub's webhook validation documentation
+	secret := "It's a Secret to Everybody"
+	payload := []byte("Hello, World!")
+	want := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+
+	signature := sign(secret, payload)
+	if signature != want {
+		t.Fatalf("Expected %s, got %s", want, signature)
+	}
 
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	// The handler accepts GitHub's signature
+	handler := gh.NewHandler("test-token", secret, zap.NewNop(), &MockGitHubMetricsRecorder{})
+	req := httptest.NewRequest("POST", "/webhook/github", bytes.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", signature)
+	req.Header.Set("X-GitHub-Event", "ping")
+	w := httptest.NewRecorder()
 
-	// Verify the signature is valid
-	if !strings.HasPrefix(signature, "sha256=") {
-		t.Error("Expected signature to start with 'sha256='")
-	}
+	handler.HandleWebhook(w, req)
 
-	// Note: verifySignature is unexported, so we can't test it directly
-	// The signature generation without error indicates success
-	_ = signature // Use the variable to avoid unused variable error
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
 }
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github-issue-ai-bot/internal/config"
+)
+
+func TestVaultProviderKVv2(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/notifyops" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_duration": 3600,
+			"data": map[string]interface{}{
+				"data": map[string]interface{}{
+					"github-access-token": "vault-gh",
+					"OPENAI_API_KEY":      "vault-openai",
+				},
+				"metadata": map[string]interface{}{"version": 3},
+			},
+		})
+	}))
+	defer server.Close()
+
+	provider, err := config.NewSecretsProvider(config.SecretsConfig{
+		Provider: "vault",
+		Vault: config.VaultConfig{
+			Address:    server.URL,
+			Token:      "root",
+			SecretPath: "secret/data/notifyops",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	values, lease, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if values["GITHUB_ACCESS_TOKEN"] != "vault-gh" {
+		t.Errorf("Expected normalized GitHub token, got %q", values["GITHUB_ACCESS_TOKEN"])
+	}
+	if values["OPENAI_API_KEY"] != "vault-openai" {
+		t.Errorf("Expected OpenAI key, got %q", values["OPENAI_API_KEY"])
+	}
+	if lease != time.Hour {
+		t.Errorf("Expected lease of 1h, got %v", lease)
+	}
+}
+
+func TestAWSSecretsManagerProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			t.Errorf("Unexpected target %q", r.Header.Get("X-Amz-Target"))
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-east-1/secretsmanager/aws4_request") {
+			t.Errorf("Unexpected authorization header %q", auth)
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"SecretString": `{"SLACK_BOT_TOKEN":"xoxb-aws"}`,
+		})
+	}))
+	defer server.Close()
+
+	provider, err := config.NewSecretsProvider(config.SecretsConfig{
+		Provider: "aws",
+		AWS: config.AWSSecretsConfig{
+			Region:          "us-east-1",
+			SecretID:        "notifyops",
+			Endpoint:        server.URL,
+			AccessKeyID:     "AKID",
+			SecretAccessKey: "secret",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	values, lease, err := provider.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if values["SLACK_BOT_TOKEN"] != "xoxb-aws" {
+		t.Errorf("Expected Slack token, got %q", values["SLACK_BOT_TOKEN"])
+	}
+	if lease != 0 {
+		t.Errorf("Expected no lease, got %v", lease)
+	}
+}
+
+func TestNewSecretsProviderValidation(t *testing.T) {
+	if _, err := config.NewSecretsProvider(config.SecretsConfig{Provider: "vault"}); err == nil {
+		t.Error("Expected error for vault provider without address")
+	}
+	if _, err := config.NewSecretsProvider(config.SecretsConfig{Provider: "unknown"}); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
+
+type staticSecretsProvider struct {
+	values map[string]string
+}
+
+func (p *staticSecretsProvider) Name() string { return "static" }
+
+func (p *staticSecretsProvider) Fetch(ctx context.Context) (map[string]string, time.Duration, error) {
+	return p.values, time.Minute, nil
+}
+
+func TestSecretsRefresherReportsChanges(t *testing.T) {
+	provider := &staticSecretsProvider{values: map[string]string{"OPENAI_API_KEY": "old"}}
+
+	changes := make(map[string]string)
+	refresher := config.NewSecretsRefresher(provider, provider.values, time.Minute, 0, func(key, value string) {
+		changes[key] = value
+	}, nil)
+
+	if err := refresher.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+
+	provider.values = map[string]string{"OPENAI_API_KEY": "new"}
+	if err := refresher.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if changes["OPENAI_API_KEY"] != "new" {
+		t.Errorf("Expected rotated key, got %v", changes)
+	}
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/diagnostics"
+	"github-issue-ai-bot/internal/slack"
+)
+
+// fakeSlackAPI answers auth.test with the given scopes and conversations.info
+// for a joined, an unjoined and an archived channel
+func fakeSlackAPI(t *testing.T, scopes string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth.test":
+			if r.FormValue("token") != "xoxb-test" {
+				w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+				return
+			}
+			w.Header().Set("X-OAuth-Scopes", scopes)
+			w.Write([]byte(`{"ok": true, "user": "notifyops", "team": "Acme", "user_id": "U1"}`))
+		case "/conversations.info":
+			switch r.FormValue("channel") {
+			case "C-JOINED":
+				w.Write([]byte(`{"ok": true, "channel": {"id": "C-JOINED", "name": "issues", "is_member": true}}`))
+			case "C-ALERTS":
+				w.Write([]byte(`{"ok": true, "channel": {"id": "C-ALERTS", "name": "alerts", "is_member": false}}`))
+			case "C-OLD":
+				w.Write([]byte(`{"ok": true, "channel": {"id": "C-OLD", "name": "old", "is_member": true, "is_archived": true}}`))
+			default:
+				w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+			}
+		default:
+			t.Errorf("Unexpected Slack API call %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSlackDiagnose(t *testing.T) {
+	server := fakeSlackAPI(t, "chat:write,channels:read")
+	notifier := slack.NewNotifier("xoxb-test", "C-JOINED", "secret", zap.NewNop(), nil, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	checks := notifier.Diagnose(context.Background(), []string{"C-JOINED", "C-ALERTS", "C-OLD", "C-GONE"}, slack.RequiredScopes(true))
+	want := []struct{ name, status, message string }{
+		{"slack_auth", diagnostics.StatusOK, "Authenticated as @notifyops in Acme"},
+		{"slack_scopes", diagnostics.StatusError, "The bot token lacks channels:manage; add the scopes under OAuth & Permissions and reinstall the app"},
+		{"slack_channel:C-JOINED", diagnostics.StatusOK, "Bot is a member of #issues"},
+		{"slack_channel:C-ALERTS", diagnostics.StatusError, "Bot not invited to #alerts; run /invite @notifyops in the channel"},
+		{"slack_channel:C-OLD", diagnostics.StatusError, "#old is archived; unarchive it or route its issues to another channel"},
+		{"slack_channel:C-GONE", diagnostics.StatusError, "Channel C-GONE was not found; check the channel ID, and invite @notifyops if the channel is private"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("Expected %d checks, got %+v", len(want), checks)
+	}
+	for i, w := range want {
+		if checks[i].Name != w.name || checks[i].Status != w.status || checks[i].Message != w.message {
+			t.Errorf("Check %d: expected %+v, got %+v", i, w, checks[i])
+		}
+	}
+}
+
+func TestSlackDiagnoseInvalidToken(t *testing.T) {
+	server := fakeSlackAPI(t, "")
+	notifier := slack.NewNotifier("xoxb-revoked", "C-JOINED", "secret", zap.NewNop(), nil, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	checks := notifier.Diagnose(context.Background(), []string{"C-JOINED"}, slack.RequiredScopes(false))
+	if len(checks) != 1 || checks[0].Status != diagnostics.StatusError || checks[0].Name != "slack_auth" {
+		t.Fatalf("Expected only a failed auth check, got %+v", checks)
+	}
+
+	// Rotated tokens are used for later checks
+	notifier.SetBotToken("xoxb-test")
+	checks = notifier.Diagnose(context.Background(), []string{"C-JOINED"}, slack.RequiredScopes(false))
+	if len(checks) != 3 || checks[0].Status != diagnostics.StatusOK {
+		t.Errorf("Expected the rotated token to authenticate, got %+v", checks)
+	}
+}
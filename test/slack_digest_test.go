@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/slack"
+	"github-issue-ai-bot/internal/teams"
+)
+
+type fakeTriage struct {
+	mu           sync.Mutex
+	acknowledged []string
+	snoozed      map[string]time.Time
+	done         chan struct{}
+}
+
+func (f *fakeTriage) AcknowledgeIssue(repository string, number int, userID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.acknowledged = append(f.acknowledged, fmt.Sprintf("%s#%d %s", repository, number, userID))
+	return number != 3 // Already acknowledged
+}
+
+func (f *fakeTriage) SnoozeIssue(repository string, number int, until time.Time, userID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.snoozed[fmt.Sprintf("%s#%d", repository, number)] = until
+	f.done <- struct{}{}
+	return true
+}
+
+func (f *fakeTriage) EscalateIssue(ctx context.Context, repository string, number int, userID string) (string, error) {
+	return "critical", nil
+}
+
+func TestSlackPostDigest(t *testing.T) {
+	posted := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		posted <- r.FormValue("blocks")
+		io.WriteString(w, `{"ok": true, "channel": "C-PAY", "ts": "1.1"}`)
+	}))
+	defer server.Close()
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	digest := teams.Digest{Text: "*Weekly issue digest for payments*"}
+	for number := 1; number <= 25; number++ {
+		priority := "high"
+		if number > 22 {
+			priority = "low"
+		}
+		digest.Triage = append(digest.Triage, teams.TriageIssue{
+			Repository: "acme/payments-api",
+			Number:     number,
+			Title:      "Crash",
+			Priority:   priority,
+			OpenedAt:   time.Now().Add(-2 * time.Hour),
+		})
+	}
+	if err := notifier.PostDigest(context.Background(), "C-PAY", digest); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var blocks []map[string]interface{}
+	if err := json.Unmarshal([]byte(<-posted), &blocks); err != nil {
+		t.Fatalf("Failed to decode blocks: %v", err)
+	}
+	// The rollup, a divider, the heading, 20 issues, the rest and the button
+	if len(blocks) != 25 {
+		t.Fatalf("Expected 25 blocks, got %d", len(blocks))
+	}
+	menu := blocks[3]["accessory"].(map[string]interface{})
+	if menu["type"] != "overflow" || menu["action_id"] != "digest_issue" || len(menu["options"].([]interface{})) != 5 {
+		t.Errorf("Expected a triage menu on each issue, got %v", menu)
+	}
+	if more, _ := json.Marshal(blocks[23]); !strings.Contains(string(more), "and 5 more") {
+		t.Errorf("Expected the unlisted issues counted, got %s", more)
+	}
+	button := blocks[24]["elements"].([]interface{})[0].(map[string]interface{})
+	if button["action_id"] != "digest_ack_low" || button["value"] != "acme/payments-api:23,acme/payments-api:24,acme/payments-api:25" {
+		t.Errorf("Expected every low priority issue in the bulk button, got %v", button)
+	}
+}
+
+func TestSlackDigestActions(t *testing.T) {
+	replies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/chat.postMessage" {
+			replies <- r.FormValue("thread_ts") + " " + r.FormValue("text")
+		}
+		io.WriteString(w, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	triage := &fakeTriage{snoozed: map[string]time.Time{}, done: make(chan struct{}, 1)}
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+	notifier.SetTriage(triage)
+
+	postInteraction(t, notifier, map[string]interface{}{
+		"type":    "block_actions",
+		"channel": map[string]string{"id": "C-PAY"},
+		"user":    map[string]string{"id": "U-LEAD"},
+		"message": map[string]string{"ts": "1.1"},
+		"actions": []map[string]string{{"type": "button", "block_id": "digest_actions", "action_id": "digest_ack_low", "value": "acme/web:2,acme/web:3,acme/api:4"}},
+	})
+	select {
+	case reply := <-replies:
+		if reply != "1.1 :white_check_mark: <@U-LEAD> acknowledged 2 low priority issues." {
+			t.Errorf("Unexpected reply %q", reply)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a reply in the digest's thread")
+	}
+	triage.mu.Lock()
+	if strings.Join(triage.acknowledged, ",") != "acme/web#2 U-LEAD,acme/web#3 U-LEAD,acme/api#4 U-LEAD" {
+		t.Errorf("Expected each issue acknowledged, got %v", triage.acknowledged)
+	}
+	triage.mu.Unlock()
+
+	postInteraction(t, notifier, map[string]interface{}{
+		"type":    "block_actions",
+		"channel": map[string]string{"id": "C-PAY"},
+		"user":    map[string]string{"id": "U-LEAD"},
+		"message": map[string]string{"ts": "1.1"},
+		"actions": []map[string]interface{}{{"type": "overflow", "block_id": "b1", "action_id": "digest_issue",
+			"selected_option": map[string]string{"value": "snooze_week|acme/web:5"}}},
+	})
+	select {
+	case <-triage.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the issue snoozed")
+	}
+	triage.mu.Lock()
+	defer triage.mu.Unlock()
+	if until := triage.snoozed["acme/web#5"]; until.Before(time.Now().AddDate(0, 0, 6)) {
+		t.Errorf("Expected acme/web#5 snoozed for a week, got %v", until)
+	}
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/slack"
+)
+
+func TestSlackMessageEngaged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		ts := r.FormValue("timestamp")
+		if r.URL.Path == "/conversations.replies" {
+			ts = r.FormValue("ts")
+		}
+		switch r.URL.Path + " " + ts {
+		case "/auth.test ":
+			io.WriteString(w, `{"ok": true, "user": "notifyops", "user_id": "U-BOT"}`)
+		case "/reactions.get 1.1", "/reactions.get 3.3":
+			io.WriteString(w, `{"ok": true, "type": "message", "message": {"reactions": [{"name": "eyes", "count": 1, "users": ["U-BOT"]}]}}`)
+		case "/reactions.get 2.2":
+			io.WriteString(w, `{"ok": true, "type": "message", "message": {"reactions": [{"name": "eyes", "count": 2, "users": ["U-BOT", "U-ALICE"]}]}}`)
+		case "/conversations.replies 1.1":
+			io.WriteString(w, `{"ok": true, "messages": [{"ts": "1.1", "user": "U-BOT", "bot_id": "B1"}, {"ts": "1.2", "user": "U-BOT", "bot_id": "B1"}, {"ts": "1.3", "bot_id": "B-OTHER"}]}`)
+		case "/conversations.replies 3.3":
+			io.WriteString(w, `{"ok": true, "messages": [{"ts": "3.3", "user": "U-BOT", "bot_id": "B1"}, {"ts": "3.4", "user": "U-ALICE"}]}`)
+		default:
+			t.Errorf("Unexpected Slack API call %s for %q", r.URL.Path, ts)
+			io.WriteString(w, `{"ok": false, "error": "unknown_method"}`)
+		}
+	}))
+	defer server.Close()
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	for ts, want := range map[string]bool{
+		"1.1": false, // Only the bot and other bots
+		"2.2": true,  // A reaction
+		"3.3": true,  // A reply
+	} {
+		engaged, err := notifier.MessageEngaged(context.Background(), "C1", ts)
+		if err != nil {
+			t.Fatalf("MessageEngaged(%s): %v", ts, err)
+		}
+		if engaged != want {
+			t.Errorf("MessageEngaged(%s) = %v, want %v", ts, engaged, want)
+		}
+	}
+}
+
+type fakeEngagementRecorder struct{ clicks []string }
+
+func (f *fakeEngagementRecorder) RecordEngagement(channelID, ts, userID string) {
+	f.clicks = append(f.clicks, channelID+" "+ts+" "+userID)
+}
+
+func TestSlackClicksRecordEngagement(t *testing.T) {
+	recorder := &fakeEngagementRecorder{}
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetEngagement(recorder)
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":    "block_actions",
+		"channel": map[string]string{"id": "C1"},
+		"user":    map[string]string{"id": "U-ALICE"},
+		"message": map[string]string{"ts": "1700000000.000100"},
+		"actions": []map[string]string{{"type": "button", "block_id": "actions", "action_id": "unknown_action"}},
+	})
+	request := interactionRequest("secret", payload)
+	notifier.HandleInteractiveMessage(httptest.NewRecorder(), request)
+
+	if len(recorder.clicks) != 1 || recorder.clicks[0] != "C1 1700000000.000100 U-ALICE" {
+		t.Errorf("Expected the click recorded, got %v", recorder.clicks)
+	}
+}
@@ -0,0 +1,266 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/slack"
+)
+
+func TestSlowInteractionsAreDeferred(t *testing.T) {
+	responses := make(chan map[string]interface{}, 1)
+	replies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/response":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			responses <- body
+		case "/chat.postMessage":
+			r.ParseForm()
+			replies <- r.FormValue("text")
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"ok": true, "channel": "C1", "ts": "1700000000.000200"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"response_url": server.URL + "/response",
+		"channel":      map[string]string{"id": "C1"},
+		"user":         map[string]string{"id": "U1"},
+		"message":      map[string]string{"ts": "1700000000.000100"},
+		"actions":      []map[string]string{{"type": "button", "block_id": "actions", "action_id": "close_issue", "value": "not-an-issue"}},
+	})
+	request := interactionRequest("secret", payload)
+	recorder := httptest.NewRecorder()
+	notifier.HandleInteractiveMessage(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the interaction acknowledged, got %d", recorder.Code)
+	}
+
+	select {
+	case body := <-responses:
+		if body["response_type"] != "ephemeral" || body["replace_original"] != false || !strings.Contains(body["text"].(string), "Checking the issue") {
+			t.Errorf("Expected an ephemeral working message, got %v", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a working message through the response URL")
+	}
+	select {
+	case text := <-replies:
+		if !strings.Contains(text, "Could not parse issue information") {
+			t.Errorf("Expected the result in the thread, got %q", text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the result posted in the thread")
+	}
+}
+
+type fakeIdentities map[string]string
+
+func (f fakeIdentities) GitHubLogin(ctx context.Context, userID string) (string, bool) {
+	login, ok := f[userID]
+	return login, ok
+}
+
+func TestAssignIssueWithoutLinkedAccount(t *testing.T) {
+	responses := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		responses <- body
+	}))
+	defer server.Close()
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+	notifier.SetIdentities(fakeIdentities{"U2": "octocat"})
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"response_url": server.URL + "/response",
+		"channel":      map[string]string{"id": "C1"},
+		"user":         map[string]string{"id": "U1"},
+		"message":      map[string]string{"ts": "1700000000.000100"},
+		"actions":      []map[string]string{{"type": "button", "block_id": "actions", "action_id": "assign_issue", "value": "owner/repo:7"}},
+	})
+	request := interactionRequest("secret", payload)
+	recorder := httptest.NewRecorder()
+	notifier.HandleInteractiveMessage(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the interaction acknowledged, got %d", recorder.Code)
+	}
+
+	select {
+	case body := <-responses:
+		if body["response_type"] != "ephemeral" || !strings.Contains(body["text"].(string), "not linked to a GitHub account") {
+			t.Errorf("Expected guidance for an unlinked account, got %v", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a reply through the response URL")
+	}
+}
+
+func TestInteractionsRequireSignature(t *testing.T) {
+	calls := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls <- r.URL.Path
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	interactions := map[string]map[string]interface{}{
+		"close": {
+			"type":         "block_actions",
+			"response_url": server.URL + "/response",
+			"channel":      map[string]string{"id": "C1"},
+			"user":         map[string]string{"id": "U1"},
+			"actions":      []map[string]string{{"type": "button", "block_id": "actions", "action_id": "close_issue", "value": "owner/repo:7"}},
+		},
+		"assign": {
+			"type":         "block_actions",
+			"response_url": server.URL + "/response",
+			"channel":      map[string]string{"id": "C1"},
+			"user":         map[string]string{"id": "U1"},
+			"actions":      []map[string]string{{"type": "button", "block_id": "actions", "action_id": "assign_issue", "value": "owner/repo:7"}},
+		},
+		"shortcut": {
+			"type":        "message_action",
+			"callback_id": slack.IssueShortcutCallbackID,
+			"trigger_id":  "T1",
+			"user":        map[string]string{"id": "U1"},
+		},
+		"modal": {
+			"type": "view_submission",
+			"user": map[string]string{"id": "U1"},
+			"view": map[string]string{"callback_id": slack.IssueShortcutCallbackID},
+		},
+	}
+	for name, interaction := range interactions {
+		payload, _ := json.Marshal(interaction)
+		for secret, request := range map[string]*http.Request{
+			"unsigned":     interactionRequest("secret", payload),
+			"wrong secret": interactionRequest("forged", payload),
+		} {
+			if secret == "unsigned" {
+				request.Header.Del("X-Slack-Signature")
+			}
+			recorder := httptest.NewRecorder()
+			notifier.HandleInteractiveMessage(recorder, request)
+			if recorder.Code != http.StatusUnauthorized {
+				t.Errorf("Expected the %s %s request rejected, got %d", secret, name, recorder.Code)
+			}
+		}
+	}
+
+	select {
+	case call := <-calls:
+		t.Errorf("Expected rejected interactions not to reach Slack or GitHub, got %s", call)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestActionsRequireWriteAccess(t *testing.T) {
+	messages := make(chan string, 2)
+	slackAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/response":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			messages <- body["text"].(string)
+		case "/chat.postMessage":
+			r.ParseForm()
+			messages <- r.FormValue("text")
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"ok": true, "channel": "C1", "ts": "1700000000.000200"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer slackAPI.Close()
+
+	assigned := make(chan string, 1)
+	githubAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/collaborators/reporter/permission":
+			json.NewEncoder(w).Encode(github.RepositoryPermissionLevel{Permission: github.String("read")})
+		case "/repos/owner/repo/collaborators/octocat/permission":
+			json.NewEncoder(w).Encode(github.RepositoryPermissionLevel{Permission: github.String("write")})
+		case "/repos/owner/repo/issues/7/assignees":
+			var request struct{ Assignees []string }
+			json.NewDecoder(r.Body).Decode(&request)
+			assigned <- request.Assignees[0]
+			json.NewEncoder(w).Encode(github.Issue{Number: github.Int(7), Assignees: []*github.User{{Login: github.String(request.Assignees[0])}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer githubAPI.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(githubAPI.URL + "/")
+	githubHandler := gh.NewHandlerWithClient(client, "secret", zap.NewNop(), &MockGitHubMetricsRecorder{})
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, githubHandler)
+	notifier.SetAPIURL(slackAPI.URL + "/")
+	notifier.SetIdentities(fakeIdentities{"U1": "reporter", "U2": "octocat"})
+	notifier.SetRequireWriteAccess(true)
+
+	assign := func(userID string) {
+		postInteraction(t, notifier, map[string]interface{}{
+			"type":         "block_actions",
+			"response_url": slackAPI.URL + "/response",
+			"channel":      map[string]string{"id": "C1"},
+			"user":         map[string]string{"id": userID},
+			"message":      map[string]string{"ts": "1700000000.000100"},
+			"actions":      []map[string]string{{"type": "button", "block_id": "actions", "action_id": "assign_issue", "value": "owner/repo:7"}},
+		})
+	}
+	receive := func() string {
+		select {
+		case text := <-messages:
+			return text
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected a reply")
+			return ""
+		}
+	}
+
+	assign("U1")
+	if text := receive(); !strings.Contains(text, "@reporter does not have write access to owner/repo") {
+		t.Errorf("Expected the assignment refused, got %q", text)
+	}
+	select {
+	case login := <-assigned:
+		t.Fatalf("Expected no assignment without write access, got %s", login)
+	default:
+	}
+
+	assign("U2")
+	if text := receive(); !strings.Contains(text, "assigned #7 to themselves (@octocat on GitHub)") {
+		t.Errorf("Expected the issue assigned, got %q", text)
+	}
+	if login := <-assigned; login != "octocat" {
+		t.Errorf("Expected octocat assigned, got %s", login)
+	}
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	"github-issue-ai-bot/internal/slack"
+)
+
+type fakeModeration struct {
+	held      *ai.IssueSummary
+	discarded []string
+	approved  chan *ai.IssueSummary
+}
+
+func (f *fakeModeration) HeldSummary(repository string, number int) (*ai.IssueSummary, bool) {
+	return f.held, f.held != nil
+}
+
+func (f *fakeModeration) ApprovePreview(ctx context.Context, repository string, number int, edited *ai.IssueSummary, moderator string) (string, error) {
+	f.approved <- edited
+	return "C-ISSUES", nil
+}
+
+func (f *fakeModeration) DiscardPreview(repository string, number int, moderator string) bool {
+	f.discarded = append(f.discarded, fmt.Sprintf("%s#%d %s", repository, number, moderator))
+	return true
+}
+
+func TestSlackModerationDiscard(t *testing.T) {
+	updates := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/chat.update" {
+			t.Errorf("Unexpected Slack API call %s", r.URL.Path)
+		}
+		updates <- r.FormValue("channel") + " " + r.FormValue("ts") + " " + r.FormValue("text")
+		io.WriteString(w, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	moderation := &fakeModeration{}
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+	notifier.SetModeration(moderation)
+
+	postInteraction(t, notifier, map[string]interface{}{
+		"type":    "block_actions",
+		"channel": map[string]string{"id": "D-MOD"},
+		"user":    map[string]string{"id": "U-MOD"},
+		"message": map[string]string{"ts": "1.1"},
+		"actions": []map[string]string{{"type": "button", "block_id": "actions", "action_id": "discard_preview", "value": "acme/web:7"}},
+	})
+
+	if len(moderation.discarded) != 1 || moderation.discarded[0] != "acme/web#7 U-MOD" {
+		t.Errorf("Expected acme/web#7 discarded by U-MOD, got %v", moderation.discarded)
+	}
+	if update := <-updates; !strings.HasPrefix(update, "D-MOD 1.1 ") || !strings.Contains(update, "discarded the summary of acme/web#7") {
+		t.Errorf("Expected the preview replaced, got %q", update)
+	}
+}
+
+func TestSlackModerationEditSubmission(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	moderation := &fakeModeration{
+		held:     &ai.IssueSummary{Title: "Crash", Summary: "It crashes", Priority: "high", Category: "bug", ActionItems: []string{"Fix it"}},
+		approved: make(chan *ai.IssueSummary, 1),
+	}
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+	notifier.SetModeration(moderation)
+
+	postInteraction(t, notifier, map[string]interface{}{
+		"type": "view_submission",
+		"user": map[string]string{"id": "U-MOD"},
+		"view": map[string]interface{}{
+			"callback_id":      "moderate_preview",
+			"private_metadata": `{"repository":"acme/web","number":7,"channel":"D-MOD","message_ts":"1.1"}`,
+			"state": map[string]interface{}{"values": map[string]interface{}{
+				"title":        map[string]interface{}{"title": map[string]interface{}{"type": "plain_text_input", "value": "Crash on start"}},
+				"summary":      map[string]interface{}{"summary": map[string]interface{}{"type": "plain_text_input", "value": ""}},
+				"priority":     map[string]interface{}{"priority": map[string]interface{}{"type": "static_select", "selected_option": map[string]string{"value": "medium"}}},
+				"action_items": map[string]interface{}{"action_items": map[string]interface{}{"type": "plain_text_input", "value": "- Reproduce\n\n• Add a test"}},
+			}},
+		},
+	})
+
+	select {
+	case edited := <-moderation.approved:
+		if edited.Title != "Crash on start" || edited.Summary != "It crashes" || edited.Priority != "medium" || edited.Category != "bug" ||
+			strings.Join(edited.ActionItems, "|") != "Reproduce|Add a test" {
+			t.Errorf("Expected the moderator's edits applied to the held summary, got %+v", edited)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the edited summary approved")
+	}
+	if moderation.held.Title != "Crash" {
+		t.Error("Expected the held summary unchanged")
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/slack"
+)
+
+func TestSlackSummaryOverflow(t *testing.T) {
+	var forms []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		forms = append(forms, map[string]string{
+			"text":      r.FormValue("text"),
+			"blocks":    r.FormValue("blocks"),
+			"thread_ts": r.FormValue("thread_ts"),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true, "channel": "C1", "ts": "1700000000.000100"}`))
+	}))
+	defer server.Close()
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	// About 15000 characters, with a code block spanning several breaks
+	var paragraphs []string
+	for i := 1; i <= 60; i++ {
+		paragraphs = append(paragraphs, fmt.Sprintf("Paragraph %d: %s", i, strings.Repeat("the handler retries é ", 10)))
+		if i == 20 {
+			paragraphs = append(paragraphs, "```\n"+strings.Repeat("retry(ctx)\n", 400)+"```")
+		}
+	}
+	summary := strings.Join(paragraphs, "\n\n")
+	message := map[string]interface{}{"blocks": []interface{}{
+		map[string]interface{}{"type": "header", "text": map[string]interface{}{"type": "plain_text", "text": strings.Repeat("Long title ", 30)}},
+		map[string]interface{}{"type": "section", "text": map[string]interface{}{"type": "mrkdwn", "text": summary}},
+	}}
+	if _, _, err := notifier.PostIssueSummary(context.Background(), "C1", message); err != nil {
+		t.Fatal(err)
+	}
+
+	var blocks []struct {
+		Type string `json:"type"`
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(forms[0]["blocks"]), &blocks); err != nil {
+		t.Fatalf("Expected blocks, got %q", forms[0]["blocks"])
+	}
+	if len(blocks) != 4 || utf8.RuneCountInString(blocks[0].Text.Text) > 150 {
+		t.Fatalf("Expected a shortened header and the summary split across 3 sections, got %d blocks", len(blocks))
+	}
+	for _, block := range blocks[1:] {
+		if n := utf8.RuneCountInString(block.Text.Text); n > 3000 {
+			t.Errorf("Expected sections of at most 3000 characters, got %d", n)
+		}
+		if strings.Count(block.Text.Text, "```")%2 != 0 {
+			t.Errorf("Expected code blocks closed within each section:\n%s", block.Text.Text)
+		}
+	}
+	if !strings.HasSuffix(blocks[3].Text.Text, "continued in thread_") {
+		t.Errorf("Expected the last section to point to the thread, got %q", blocks[3].Text.Text)
+	}
+
+	replies := forms[1:]
+	if len(replies) == 0 {
+		t.Fatal("Expected the rest of the summary in the thread")
+	}
+	for _, reply := range replies {
+		if reply["thread_ts"] != "1700000000.000100" || utf8.RuneCountInString(reply["text"]) > 3000 {
+			t.Errorf("Expected a thread reply of at most 3000 characters, got %d characters in thread %q", utf8.RuneCountInString(reply["text"]), reply["thread_ts"])
+		}
+	}
+	if !strings.HasPrefix(replies[len(replies)-1]["text"], "Paragraph") || !strings.Contains(replies[len(replies)-1]["text"], "Paragraph 60:") {
+		t.Errorf("Expected the thread to end with the last paragraph, got %q", replies[len(replies)-1]["text"])
+	}
+
+	// Updates are cut short rather than adding to the thread
+	forms = nil
+	if err := notifier.UpdateIssueSummary(context.Background(), "C1", "1700000000.000100", message); err != nil {
+		t.Fatal(err)
+	}
+	if len(forms) != 1 || !strings.Contains(forms[0]["blocks"], "truncated_") {
+		t.Errorf("Expected one truncated update, got %+v", forms)
+	}
+}
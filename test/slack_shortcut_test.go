@@ -0,0 +1,157 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/slack"
+)
+
+type recordingProcessor chan *gh.IssueData
+
+func (r recordingProcessor) ProcessIssue(ctx context.Context, issueData *gh.IssueData) {
+	r <- issueData
+}
+
+// interactionRequest builds an interaction request for payload, signed with
+// secret
+func interactionRequest(secret string, payload []byte) *http.Request {
+	body := url.Values{"payload": {string(payload)}}.Encode()
+	request := httptest.NewRequest(http.MethodPost, "/slack/interactive", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	signSlackRequest(request, secret, []byte(body))
+	return request
+}
+
+// postInteraction sends an interaction payload to the notifier, signed with
+// the "secret" signing secret the tests' notifiers use
+func postInteraction(t *testing.T, notifier *slack.Notifier, payload map[string]interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(payload)
+	request := interactionRequest("secret", body)
+	recorder := httptest.NewRecorder()
+	notifier.HandleInteractiveMessage(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the interaction acknowledged, got %d", recorder.Code)
+	}
+}
+
+func TestCreateIssueShortcut(t *testing.T) {
+	views := make(chan map[string]interface{}, 1)
+	replies := make(chan string, 1)
+	slackAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/views.open":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			views <- body["view"].(map[string]interface{})
+			io.WriteString(w, `{"ok": true}`)
+		case "/chat.postMessage":
+			r.ParseForm()
+			replies <- r.FormValue("text")
+			io.WriteString(w, `{"ok": true, "channel": "C1", "ts": "1700000000.000200"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer slackAPI.Close()
+
+	var created github.IssueRequest
+	githubAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/api/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		json.NewEncoder(w).Encode(github.Issue{
+			Number:  github.Int(42),
+			Title:   created.Title,
+			Body:    created.Body,
+			HTMLURL: github.String("https://github.com/acme/api/issues/42"),
+		})
+	}))
+	defer githubAPI.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(githubAPI.URL + "/")
+	githubHandler := gh.NewHandlerWithClient(client, "secret", zap.NewNop(), &MockGitHubMetricsRecorder{})
+
+	processed := make(recordingProcessor, 1)
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, githubHandler)
+	notifier.SetAPIURL(slackAPI.URL + "/")
+	notifier.SetIdentities(fakeIdentities{"U1": "octocat"})
+	notifier.SetIssueShortcut(slack.IssueShortcutConfig{Repositories: []string{"acme/web", "acme/api"}, Labels: []string{"bug"}}, processed)
+
+	postInteraction(t, notifier, map[string]interface{}{
+		"type":        "message_action",
+		"callback_id": slack.IssueShortcutCallbackID,
+		"trigger_id":  "T1",
+		"channel":     map[string]string{"id": "C1"},
+		"user":        map[string]string{"id": "U1"},
+		"message":     map[string]string{"ts": "1700000000.000100", "text": "Export hangs on large files\nIt spins forever at 99%."},
+	})
+	var view map[string]interface{}
+	select {
+	case view = <-views:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the create issue modal opened")
+	}
+	encoded, _ := json.Marshal(view)
+	if !strings.Contains(string(encoded), `"initial_value":"Export hangs on large files"`) ||
+		!strings.Contains(string(encoded), "acme/api") || !strings.Contains(string(encoded), "multi_static_select") {
+		t.Errorf("Expected the modal prefilled with the message, got %s", encoded)
+	}
+
+	value := func(v interface{}) map[string]interface{} {
+		return map[string]interface{}{"value": v}
+	}
+	postInteraction(t, notifier, map[string]interface{}{
+		"type": "view_submission",
+		"user": map[string]string{"id": "U1", "name": "jdoe"},
+		"view": map[string]interface{}{
+			"callback_id":      view["callback_id"],
+			"private_metadata": view["private_metadata"],
+			"state": map[string]interface{}{"values": map[string]interface{}{
+				"title":      map[string]interface{}{"title": value("Export hangs on large files")},
+				"body":       map[string]interface{}{"body": value("It spins forever at 99%.")},
+				"repository": map[string]interface{}{"repository": map[string]interface{}{"selected_option": value("acme/api")}},
+				"labels":     map[string]interface{}{"labels": map[string]interface{}{"selected_options": []interface{}{value("bug")}}},
+			}},
+		},
+	})
+
+	select {
+	case text := <-replies:
+		if !strings.Contains(text, "https://github.com/acme/api/issues/42") {
+			t.Errorf("Expected the issue linked in the thread, got %q", text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a reply in the message's thread")
+	}
+	select {
+	case issueData := <-processed:
+		if issueData.Issue.GetNumber() != 42 || issueData.Repository.GetFullName() != "acme/api" || issueData.Action != "opened" {
+			t.Errorf("Expected the created issue processed, got %+v", issueData)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the created issue processed")
+	}
+
+	if created.GetTitle() != "Export hangs on large files" || created.Labels == nil || (*created.Labels)[0] != "bug" {
+		t.Errorf("Unexpected issue request %+v", created)
+	}
+	if body := created.GetBody(); !strings.Contains(body, "It spins forever") || !strings.Contains(body, "by @octocat") || !gh.IsFiledFromSlack(body) {
+		t.Errorf("Expected the body attributed and marked, got %q", body)
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	gh "github-issue-ai-bot/internal/github"
+)
+
+func templateTestData() (*gh.IssueData, *ai.IssueSummary) {
+	issueData := &gh.IssueData{
+		Issue: &github.Issue{
+			Number:  github.Int(42),
+			Title:   github.String("Crash on \"save\""),
+			HTMLURL: github.String("https://github.com/test/repo/issues/42"),
+			User:    &github.User{Login: github.String("octocat")},
+		},
+		Repository: &github.Repository{FullName: github.String("test/repo")},
+		EventType:  "issues",
+		Action:     "opened",
+	}
+	summary := &ai.IssueSummary{
+		Title:       "Save crashes with quotes",
+		Summary:     "Saving a file whose name contains \"quotes\" panics",
+		Priority:    "high",
+		Category:    "bug",
+		ActionItems: []string{"Escape file names"},
+		Confidence:  0.9,
+	}
+	return issueData, summary
+}
+
+func TestGenerateSlackMessageWithTemplate(t *testing.T) {
+	tmpl, err := ai.LoadSlackTemplate("../templates/slack_message.json.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to load example template: %v", err)
+	}
+
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+	summarizer.SetSlackTemplate(tmpl)
+
+	issueData, summary := templateTestData()
+	message := summarizer.GenerateSlackMessage(issueData, summary)
+
+	blocks, ok := message["blocks"].([]interface{})
+	if !ok {
+		t.Fatalf("Expected template blocks to be []interface{}, got %T", message["blocks"])
+	}
+
+	header := blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"].(string)
+	if !strings.Contains(header, "🔴") || !strings.Contains(header, "#42") {
+		t.Errorf("Unexpected header text %q", header)
+	}
+
+	footer := blocks[len(blocks)-1].(map[string]interface{})
+	if footer["type"] != "context" {
+		t.Errorf("Expected context footer as last block, got %v", footer["type"])
+	}
+}
+
+func TestSlackTemplateBareBlockArray(t *testing.T) {
+	tmpl, err := ai.ParseSlackTemplate("compact", `[{"type":"section","text":{"type":"mrkdwn","text":{{json .Summary.Summary}}}}]`)
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+	summarizer.SetSlackTemplate(tmpl)
+
+	issueData, summary := templateTestData()
+	message := summarizer.GenerateSlackMessage(issueData, summary)
+
+	blocks := message["blocks"].([]interface{})
+	if len(blocks) != 1 {
+		t.Fatalf("Expected a single block, got %d", len(blocks))
+	}
+	text := blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"]
+	if text != summary.Summary {
+		t.Errorf("Expected escaped summary to round-trip, got %q", text)
+	}
+}
+
+func TestSlackTemplateFallsBackOnError(t *testing.T) {
+	// Produces invalid JSON, so the built-in layout must be used
+	tmpl, err := ai.ParseSlackTemplate("broken", `{"blocks": [{{.Summary.Summary}}]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	summarizer := ai.NewSummarizer("test-api-key", "gpt-4", 2000, 0.7, zap.NewNop(), &MockMetricsRecorder{})
+	summarizer.SetSlackTemplate(tmpl)
+
+	issueData, summary := templateTestData()
+	message := summarizer.GenerateSlackMessage(issueData, summary)
+
+	if _, ok := message["blocks"].([]map[string]interface{}); !ok {
+		t.Errorf("Expected default layout blocks, got %T", message["blocks"])
+	}
+}
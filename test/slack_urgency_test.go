@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/slack"
+)
+
+type nopSlackMetrics struct{}
+
+func (nopSlackMetrics) RecordSlackMessage(channel, messageType, status string, duration time.Duration) {
+}
+func (nopSlackMetrics) RecordSlackError(operation, errorType string) {}
+func (nopSlackMetrics) RecordStageTimeout(stage string)              {}
+
+func TestSlackSummaryColor(t *testing.T) {
+	var forms []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		forms = append(forms, map[string]string{
+			"text":        r.FormValue("text"),
+			"blocks":      r.FormValue("blocks"),
+			"attachments": r.FormValue("attachments"),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true, "channel": "C1", "ts": "1700000000.000100"}`))
+	}))
+	defer server.Close()
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+	blocks := []interface{}{map[string]interface{}{"type": "divider"}}
+
+	message := map[string]interface{}{"blocks": blocks, "color": "#E01E5A", "text": "<!here> High priority issue"}
+	if _, _, err := notifier.PostIssueSummary(context.Background(), "C1", message); err != nil {
+		t.Fatal(err)
+	}
+	var attachments []struct {
+		Color  string            `json:"color"`
+		Blocks []json.RawMessage `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(forms[0]["attachments"]), &attachments); err != nil {
+		t.Fatalf("Expected attachments, got %q", forms[0]["attachments"])
+	}
+	if len(attachments) != 1 || attachments[0].Color != "#E01E5A" || len(attachments[0].Blocks) != 1 {
+		t.Errorf("Expected the blocks in a red attachment, got %+v", attachments)
+	}
+	if forms[0]["text"] != "<!here> High priority issue" || forms[0]["blocks"] != "" {
+		t.Errorf("Expected the mention as the text, got %+v", forms[0])
+	}
+
+	// Dropping the color moves the blocks back out of the attachment
+	if err := notifier.UpdateIssueSummary(context.Background(), "C1", "1700000000.000100", map[string]interface{}{"blocks": blocks}); err != nil {
+		t.Fatal(err)
+	}
+	if forms[1]["attachments"] != "[]" || forms[1]["blocks"] == "" || forms[1]["text"] != "GitHub Issue Update" {
+		t.Errorf("Expected plain blocks clearing the attachment, got %+v", forms[1])
+	}
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github-issue-ai-bot/internal/ai"
+	gh "github-issue-ai-bot/internal/github"
+	"github-issue-ai-bot/internal/slack"
+)
+
+type storedSummaries map[string]*ai.IssueSummary
+
+func (s storedSummaries) ExplainIssue(repository string, number int) (*ai.IssueSummary, bool) {
+	summary, ok := s[fmt.Sprintf("%s#%d", repository, number)]
+	return summary, ok
+}
+
+// workflowSlackAPI records the workflow step calls made to a fake Slack API
+func workflowSlackAPI(t *testing.T) (*httptest.Server, chan string) {
+	calls := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/views.open", "/workflows.updateStep", "/workflows.stepCompleted", "/workflows.stepFailed":
+			body, _ := io.ReadAll(r.Body)
+			calls <- r.URL.Path + " " + string(body)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `{"ok": true}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+func nextCall(t *testing.T, calls chan string) string {
+	t.Helper()
+	select {
+	case call := <-calls:
+		return call
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a call to the Slack API")
+		return ""
+	}
+}
+
+// signSlackRequest signs a request's body with secret, the way Slack does
+func signSlackRequest(request *http.Request, secret string, body []byte) {
+	timestamp := fmt.Sprint(time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+// postEvent sends an Events API request signed with secret
+func postEvent(notifier *slack.Notifier, secret string, event map[string]interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(event)
+	request := httptest.NewRequest(http.MethodPost, "/webhook/slack/events", strings.NewReader(string(body)))
+	signSlackRequest(request, secret, body)
+	recorder := httptest.NewRecorder()
+	notifier.HandleEvent(recorder, request)
+	return recorder
+}
+
+func executeStep(callbackID, issue string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "event_callback",
+		"event": map[string]interface{}{
+			"type":        "workflow_step_execute",
+			"callback_id": callbackID,
+			"workflow_step": map[string]interface{}{
+				"workflow_step_execute_id": "E1",
+				"workflow_id":              "W1",
+				"inputs":                   map[string]interface{}{"issue": map[string]string{"value": issue}},
+			},
+		},
+	}
+}
+
+func TestWorkflowStepConfiguration(t *testing.T) {
+	server, calls := workflowSlackAPI(t)
+	notifier := slack.NewNotifier("xoxb-test", "C1", "secret", zap.NewNop(), nopSlackMetrics{}, nil, nil)
+	notifier.SetAPIURL(server.URL + "/")
+
+	postInteraction(t, notifier, map[string]interface{}{
+		"type":          "workflow_step_edit",
+		"callback_id":   slack.SummarizeStepCallbackID,
+		"trigger_id":    "T1",
+		"user":          map[string]string{"id": "U1"},
+		"workflow_step": map[string]interface{}{"inputs": map[string]interface{}{"issue": map[string]string{"value": "acme/api#7"}}},
+	})
+	view := nextCall(t, calls)
+	for _, want := range []string{`"type":"workflow_step"`, `"callback_id":"summarize_issue"`, `"initial_value":"acme/api#7"`} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the configuration modal to contain %s, got %s", want, view)
+		}
+	}
+
+	postInteraction(t, notifier, map[string]interface{}{
+		"type":          "view_submission",
+		"user":          map[string]string{"id": "U1"},
+		"workflow_step": map[string]string{"workflow_step_edit_id": "EDIT1"},
+		"view": map[string]interface{}{
+			"type":        "workflow_step",
+			"callback_id": slack.SummarizeStepCallbackID,
+			"state": map[string]interface{}{"values": map[string]interface{}{
+				"issue": map[string]interface{}{"issue": map[string]string{"value": " {{form.issue_link}} "}},
+			}},
+		},
+	})
+	saved := nextCall(t, calls)
+	for _, want := range []string{"/workflows.updateStep", `"workflow_step_edit_id":"EDIT1"`, `"value":"{{form.issue_link}}"`, `"name":"summary"`, `"name":"priority"`} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("Expected the saved step to contain %s, got %s", want, saved)
+		}
+	}
+}
+
+func TestWorkflowStepExecution(t *testing.T) {
+	server, calls := workflowSlackAPI(t)
+	githubAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/api/issues/12" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(github.Issue{
+			Number: github.Int(12),
+			Title:  github.String("Export hangs on large files"),
+			Body:   github.String("It spins forever at 99%."),
+		})
+	}))
+	defer githubAPI.Close()
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(githubAPI.URL + "/")
+	githubMetrics := &MockGitHubMetricsRecorder{}
+	githubMetrics.On("RecordGitHubAPIError", mock.Anything, mock.Anything).Return()
+	githubHandler := gh.NewHandlerWithClient(client, "secret", zap.NewNop(), githubMetrics)
+	summarizer, _ := newFakeSummarizer(&MockMetricsRecorder{})
+
+	notifier := slack.NewNotifier("xoxb-test", "C1", "signing-secret", zap.NewNop(), nopSlackMetrics{}, summarizer, githubHandler)
+	notifier.SetAPIURL(server.URL + "/")
+	notifier.SetSummaryExplainer(storedSummaries{"acme/api#7": {Title: "Login fails", Summary: "Tokens expire **at once**", Priority: "high", Category: "bug"}})
+
+	// Slack checks the URL when it is saved, and every request is signed
+	recorder := postEvent(notifier, "signing-secret", map[string]interface{}{"type": "url_verification", "challenge": "c-123"})
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "c-123" {
+		t.Errorf("Expected the challenge echoed, got %d %q", recorder.Code, recorder.Body.String())
+	}
+	if recorder := postEvent(notifier, "wrong-secret", executeStep(slack.SummarizeStepCallbackID, "acme/api#7")); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an unsigned event rejected, got %d", recorder.Code)
+	}
+
+	// The summary already posted is reused
+	if recorder := postEvent(notifier, "signing-secret", executeStep(slack.SummarizeStepCallbackID, "acme/api#7")); recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the event acknowledged, got %d", recorder.Code)
+	}
+	var completed struct {
+		ID      string            `json:"workflow_step_execute_id"`
+		Outputs map[string]string `json:"outputs"`
+	}
+	call := nextCall(t, calls)
+	json.Unmarshal([]byte(strings.TrimPrefix(call, "/workflows.stepCompleted ")), &completed)
+	if completed.ID != "E1" || completed.Outputs["summary"] != "Tokens expire *at once*" || completed.Outputs["priority"] != "high" ||
+		completed.Outputs["url"] != "https://github.com/acme/api/issues/7" {
+		t.Errorf("Expected the stored summary as outputs, got %s", call)
+	}
+
+	// Other issues are fetched and summarized
+	postEvent(notifier, "signing-secret", executeStep(slack.SummarizeStepCallbackID, "https://github.com/acme/api/issues/12"))
+	call = nextCall(t, calls)
+	completed.Outputs = nil
+	json.Unmarshal([]byte(strings.TrimPrefix(call, "/workflows.stepCompleted ")), &completed)
+	if completed.Outputs["title"] != "Export hangs on large files" || completed.Outputs["summary"] != "Saving crashes the editor" {
+		t.Errorf("Expected a new summary as outputs, got %s", call)
+	}
+
+	// Failures are reported to Workflow Builder
+	for _, step := range []struct{ callbackID, issue, want string }{
+		{slack.SummarizeStepCallbackID, "the login bug", "is not a GitHub issue"},
+		{slack.SummarizeStepCallbackID, "acme/api#404", "could not fetch acme/api#404 from GitHub"},
+		{slack.FixStepCallbackID, "acme/api#12", "the AI could not suggest a fix for acme/api#12"},
+	} {
+		postEvent(notifier, "signing-secret", executeStep(step.callbackID, step.issue))
+		if call := nextCall(t, calls); !strings.HasPrefix(call, "/workflows.stepFailed") || !strings.Contains(call, step.want) {
+			t.Errorf("Expected %s on %q to fail with %q, got %s", step.callbackID, step.issue, step.want, call)
+		}
+	}
+}
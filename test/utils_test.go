@@ -2,6 +2,7 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"github-issue-ai-bot/pkg/utils"
 )
@@ -346,6 +347,49 @@ func TestContainsAny(t *testing.T) {
 	}
 }
 
+func TestFirstLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"single line", "Fix login", "Fix login"},
+		{"subject and body", "Fix login\n\nTokens expired at once", "Fix login"},
+		{"leading blank lines", "\n  \n  Fix login  \nmore", "Fix login"},
+		{"empty", " \n ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := utils.FirstLine(tt.text); result != tt.expected {
+				t.Errorf("FirstLine(%q) = %q, want %q", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestContainsFold(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		value    string
+		expected bool
+	}{
+		{"exact", []string{"high", "critical"}, "critical", true},
+		{"case insensitive", []string{"High"}, "HIGH", true},
+		{"missing", []string{"high"}, "low", false},
+		{"no values", nil, "high", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := utils.ContainsFold(tt.values, tt.value); result != tt.expected {
+				t.Errorf("ContainsFold(%v, %q) = %v, want %v", tt.values, tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestTruncateTextEdgeCases(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -459,3 +503,79 @@ func TestExtractIssueNumberEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestStripEmoji(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"unicode", "🔴 🐛 Issue #42: Crash", "Issue #42: Crash"},
+		{"shortcodes", ":rotating_light: On call: <@U1>", "On call: <@U1>"},
+		{"joined sequences", "Fixed 👍🏽 by 👩‍💻", "Fixed by"},
+		{"keeps times and links", "Due 12:30:45 at <https://example.com|docs>", "Due 12:30:45 at <https://example.com|docs>"},
+		{"keeps lines", "*Summary:*\n:zzz: Skipped", "*Summary:*\nSkipped"},
+		{"no emoji", "Plain text", "Plain text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := utils.StripEmoji(tt.text); result != tt.expected {
+				t.Errorf("StripEmoji(%q) = %q, want %q", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMedianDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		expected  time.Duration
+	}{
+		{"none", nil, 0},
+		{"odd", []time.Duration{3 * time.Hour, time.Hour, 2 * time.Hour}, 2 * time.Hour},
+		{"even", []time.Duration{4 * time.Hour, time.Hour, 2 * time.Hour, 3 * time.Hour}, 150 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := utils.MedianDuration(tt.durations); result != tt.expected {
+				t.Errorf("MedianDuration(%v) = %v, want %v", tt.durations, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkdownToSlack(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"heading", "## Steps to **reproduce**", "*Steps to reproduce*"},
+		{"emphasis", "**bold**, *italic*, __also bold__ and ~~gone~~", "*bold*, _italic_, *also bold* and ~gone~"},
+		{"link", "See [the docs](https://example.com/a_b)", "See <https://example.com/a_b|the docs>"},
+		{"image", "![screenshot](https://example.com/s.png)", "<https://example.com/s.png|screenshot>"},
+		{"bare URL kept", "https://github.com/o/r/blob/main/__init__.py", "https://github.com/o/r/blob/main/__init__.py"},
+		{"code span kept", "Run `make **all**` and *wait*", "Run `make **all**` and _wait_"},
+		{"bullets", "- one\n* two\n  + nested", "• one\n• two\n  • nested"},
+		{"task list", "- [x] done\n- [ ] todo", "☑ done\n☐ todo"},
+		{"numbered list", "1. **first**\n2. second", "1. *first*\n2. second"},
+		{"quote", "> **Note** it breaks", "> *Note* it breaks"},
+		{"code fence", "```go\nx := **y**\n```", "```\nx := **y**\n```"},
+		{"unclosed fence", "```\npanic: nil map", "```\npanic: nil map\n```"},
+		{"HTML comment", "<!-- Describe the bug -->\nIt crashes", "It crashes"},
+		{"horizontal rule", "above\n---\nbelow", "above\n\nbelow"},
+		{"multiplication", "2*3*4 and snake_case_name", "2*3*4 and snake_case_name"},
+		{"table", "| Name | **Value** |\n|---|:---:|\n| a | 1 |\n| longer | 22 |", "```\nName    Value\n------  -----\na       1\nlonger  22\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := utils.MarkdownToSlack(tt.input); result != tt.expected {
+				t.Errorf("MarkdownToSlack(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}